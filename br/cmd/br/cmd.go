@@ -15,6 +15,7 @@ import (
 	"github.com/pingcap/log"
 	"github.com/pingcap/tidb/br/pkg/gluetidb"
 	"github.com/pingcap/tidb/br/pkg/redact"
+	"github.com/pingcap/tidb/br/pkg/storage"
 	"github.com/pingcap/tidb/br/pkg/summary"
 	"github.com/pingcap/tidb/br/pkg/task"
 	"github.com/pingcap/tidb/br/pkg/utils"
@@ -173,7 +174,24 @@ func Init(cmd *cobra.Command) (err error) {
 		redact.InitRedact(redactLog || redactInfoLog)
 		err = startPProf(cmd)
 	})
-	return errors.Trace(err)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(printCredentialsSource(cmd))
+}
+
+// printCredentialsSource prints where the credentials of the storage are resolved
+// from and exits if `--print-resolved-credentials-source` is set.
+func printCredentialsSource(cmd *cobra.Command) error {
+	printSource, err := cmd.Flags().GetBool(storage.FlagPrintResolvedCredentialsSource)
+	if err != nil || !printSource {
+		return errors.Trace(err)
+	}
+	if err := task.PrintResolvedCredentialsSource(GetDefaultContext(), cmd.Flags(), cmd.OutOrStdout()); err != nil {
+		return errors.Trace(err)
+	}
+	os.Exit(0)
+	return nil
 }
 
 func startPProf(cmd *cobra.Command) error {
@@ -47,6 +47,7 @@ func NewStreamCommand() *cobra.Command {
 		newStreamStopCommand(),
 		newStreamPauseCommand(),
 		newStreamResumeCommand(),
+		newStreamUpdateFilterCommand(),
 		newStreamStatusCommand(),
 		newStreamTruncateCommand(),
 		newStreamCheckCommand(),
@@ -71,7 +72,7 @@ func newStreamStartCommand() *cobra.Command {
 		},
 	}
 
-	task.DefineFilterFlags(command, acceptAllTables, true)
+	task.DefineFilterFlags(command, acceptAllTables, false)
 	task.DefineStreamStartFlags(command.Flags())
 	return command
 }
@@ -120,6 +121,21 @@ func newStreamResumeCommand() *cobra.Command {
 	return command
 }
 
+func newStreamUpdateFilterCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "update-filter",
+		Short: "update the table filter of a log backup task, which takes effect from its checkpoint",
+		Args:  cobra.NoArgs,
+		RunE: func(command *cobra.Command, _ []string) error {
+			return streamCommand(command, task.StreamUpdateFilter)
+		},
+	}
+
+	task.DefineFilterFlags(command, acceptAllTables, false)
+	task.DefineStreamCommonFlags(command.Flags())
+	return command
+}
+
 func newStreamStatusCommand() *cobra.Command {
 	command := &cobra.Command{
 		Use:   "status",
@@ -21,6 +21,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/carlmjohnson/flagext"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/br/pkg/lightning"
 	"github.com/pingcap/tidb/br/pkg/lightning/backend"
@@ -47,6 +48,8 @@ func run() error {
 		compact, flagFetchMode                      *bool
 		mode                                        *string
 		cpRemove, cpErrIgnore, cpErrDestroy, cpDump *string
+		cpDumpTo, cpRestore                         *string
+		cpChunkRetry                                []string
 		localStoringTables                          *bool
 
 		fsUsage func()
@@ -68,6 +71,10 @@ func run() error {
 		cpErrIgnore = fs.String("checkpoint-error-ignore", "", "ignore errors encoutered previously on the given table (value can be 'all' or '`db`.`table`'); may corrupt this table if used incorrectly")
 		cpErrDestroy = fs.String("checkpoint-error-destroy", "", "deletes imported data with table which has an error before (value can be 'all' or '`db`.`table`')")
 		cpDump = fs.String("checkpoint-dump", "", "dump the checkpoint information as two CSV files in the given folder")
+		cpDumpTo = fs.String("checkpoint-dump-to", "", "export all the checkpoints to the given file in the external storage (value can be like 's3://bucket/path/cp.pb'), so the task can be resumed on another machine")
+		cpRestore = fs.String("checkpoint-restore", "", "import the checkpoints exported by --checkpoint-dump-to from the given file, the configured checkpoints must be empty")
+		flagext.StringsVar(fs, &cpChunkRetry, "checkpoint-chunk-retry", "re-run only the given chunks in the next import instead of the whole table, "+
+			"can be repeated (value can be '`db`.`table`:engine_id' for all chunks of an engine or '`db`.`table`:engine_id:path:offset' for a chunk)")
 
 		localStoringTables = fs.Bool("check-local-storage", false, "show tables that are missing local intermediate files (value can be 'all' or '`db`.`table`')")
 
@@ -114,6 +121,15 @@ func run() error {
 	if len(*cpDump) != 0 {
 		return errors.Trace(checkpointDump(ctx, cfg, *cpDump))
 	}
+	if len(*cpDumpTo) != 0 {
+		return errors.Trace(checkpointDumpTo(ctx, cfg, *cpDumpTo))
+	}
+	if len(*cpRestore) != 0 {
+		return errors.Trace(checkpointRestore(ctx, cfg, *cpRestore))
+	}
+	if len(cpChunkRetry) != 0 {
+		return errors.Trace(checkpointChunkRetry(ctx, cfg, cpChunkRetry))
+	}
 	if *localStoringTables {
 		return errors.Trace(getLocalStoringTables(ctx, cfg))
 	}
@@ -219,6 +235,55 @@ func checkpointErrorDestroy(ctx context.Context, cfg *config.Config, tls *common
 	return errors.Trace(lastErr)
 }
 
+func checkpointChunkRetry(ctx context.Context, cfg *config.Config, chunks []string) error {
+	targets := make([]*checkpoints.ChunkRetryTarget, 0, len(chunks))
+	for _, chunk := range chunks {
+		target, err := checkpoints.ParseChunkRetryTarget(chunk)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		targets = append(targets, target)
+	}
+
+	cpdb, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	//nolint: errcheck
+	defer cpdb.Close()
+
+	retried, err := checkpoints.RetryChunks(ctx, cpdb, targets)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, chunk := range retried {
+		fmt.Fprintln(os.Stderr, "Chunk will be re-run:", chunk)
+	}
+	return nil
+}
+
+func checkpointDumpTo(ctx context.Context, cfg *config.Config, path string) error {
+	cpdb, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	//nolint: errcheck
+	defer cpdb.Close()
+
+	return errors.Trace(checkpoints.ExportCheckpoints(ctx, cpdb, path))
+}
+
+func checkpointRestore(ctx context.Context, cfg *config.Config, path string) error {
+	cpdb, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	//nolint: errcheck
+	defer cpdb.Close()
+
+	return errors.Trace(checkpoints.ImportCheckpoints(ctx, cpdb, path))
+}
+
 func checkpointDump(ctx context.Context, cfg *config.Config, dumpFolder string) error {
 	cpdb, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
 	if err != nil {
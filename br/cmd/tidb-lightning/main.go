@@ -39,6 +39,24 @@ func main() {
 
 	app := lightning.New(globalCfg)
 
+	if globalCfg.App.DryRunPlan {
+		if err := dryRunPlan(globalCfg); err != nil {
+			log.L().Error("failed to print the import plan", zap.Error(err))
+			fmt.Fprintln(os.Stderr, "failed to print the import plan:", err)
+			exit(1)
+		}
+		return
+	}
+
+	if globalCfg.App.PrintCredentialsSource {
+		if err := printCredentialsSource(globalCfg); err != nil {
+			log.L().Error("failed to resolve the credentials", zap.Error(err))
+			fmt.Fprintln(os.Stderr, "failed to resolve the credentials:", err)
+			exit(1)
+		}
+		return
+	}
+
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc,
 		syscall.SIGHUP,
@@ -124,5 +142,21 @@ func main() {
 	}
 }
 
+func dryRunPlan(globalCfg *config.GlobalConfig) error {
+	cfg := config.NewConfig()
+	if err := cfg.LoadFromGlobal(globalCfg); err != nil {
+		return err
+	}
+	return lightning.DryRunPlan(context.Background(), cfg, globalCfg.App.DryRunPlanFormat, os.Stdout)
+}
+
+func printCredentialsSource(globalCfg *config.GlobalConfig) error {
+	cfg := config.NewConfig()
+	if err := cfg.LoadFromGlobal(globalCfg); err != nil {
+		return err
+	}
+	return lightning.PrintResolvedCredentialsSource(context.Background(), cfg, os.Stdout)
+}
+
 // main_test.go override exit to pass unit test.
 var exit = os.Exit
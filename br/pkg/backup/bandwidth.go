@@ -0,0 +1,142 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package backup
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+const (
+	bandwidthKeyPrefix = "/tidb/br/bandwidth"
+	// bandwidthSessionTTL is the TTL (in seconds) of the registration of a BR process, the
+	// share of a crashed process is given back to the others after it expires.
+	bandwidthSessionTTL = 10
+)
+
+// BandwidthCoordinator divides an aggregate bandwidth budget among the BR processes which
+// back up to the same storage endpoint, so that the backups of several clusters don't
+// saturate the storage gateway together. Each process registers itself in the group on
+// etcd, and takes an equal share of the budget among the registered processes.
+type BandwidthCoordinator struct {
+	session *concurrency.Session
+	prefix  string
+	budget  uint64
+
+	participants atomic.Int64
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+// NewBandwidthCoordinator registers the BR process in the group and starts watching the
+// other processes of the group. The budget is in bytes per second.
+func NewBandwidthCoordinator(
+	ctx context.Context,
+	cli *clientv3.Client,
+	group string,
+	budget uint64,
+) (*BandwidthCoordinator, error) {
+	session, err := concurrency.NewSession(cli, concurrency.WithTTL(bandwidthSessionTTL))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	c := &BandwidthCoordinator{
+		session: session,
+		prefix:  path.Join(bandwidthKeyPrefix, group) + "/",
+		budget:  budget,
+	}
+	key := c.prefix + uuid.New().String()
+	resp, err := cli.Put(ctx, key, "", clientv3.WithLease(session.Lease()))
+	if err != nil {
+		_ = session.Close()
+		return nil, errors.Trace(err)
+	}
+	if err := c.refresh(ctx); err != nil {
+		_ = session.Close()
+		return nil, errors.Trace(err)
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.watch(watchCtx, resp.Header.Revision+1)
+	}()
+	log.Info("registered in the bandwidth group",
+		zap.String("group", group), zap.String("key", key),
+		zap.Int64("participants", c.participants.Load()))
+	return c, nil
+}
+
+// refresh counts the registered processes of the group.
+func (c *BandwidthCoordinator) refresh(ctx context.Context) error {
+	resp, err := c.session.Client().Get(ctx, c.prefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	c.participants.Store(resp.Count)
+	return nil
+}
+
+func (c *BandwidthCoordinator) watch(ctx context.Context, rev int64) {
+	cli := c.session.Client()
+	for ctx.Err() == nil {
+		watchCtx, cancel := context.WithCancel(ctx)
+		for resp := range cli.Watch(watchCtx, c.prefix, clientv3.WithPrefix(), clientv3.WithRev(rev)) {
+			if resp.Err() != nil {
+				log.Warn("failed to watch the bandwidth group", zap.Error(resp.Err()))
+				break
+			}
+			rev = resp.Header.Revision + 1
+			if err := c.refresh(ctx); err != nil {
+				log.Warn("failed to count the processes of the bandwidth group", zap.Error(err))
+			}
+		}
+		cancel()
+		// the revision may be compacted, the watch is restarted from the latest one.
+		if err := c.refresh(ctx); err == nil {
+			rev = 0
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// RateLimit returns the rate limit (in bytes per second) of each backup request sent to the
+// stores, which is the share of the budget of this process divided by the stores.
+func (c *BandwidthCoordinator) RateLimit(stores int) uint64 {
+	participants := uint64(c.participants.Load())
+	if participants == 0 {
+		participants = 1
+	}
+	if stores <= 0 {
+		stores = 1
+	}
+	limit := c.budget / participants / uint64(stores)
+	// zero means unlimited for TiKV.
+	if limit == 0 {
+		limit = 1
+	}
+	return limit
+}
+
+// Close unregisters the process from the group, so that its share is given back to the
+// other processes immediately.
+func (c *BandwidthCoordinator) Close() error {
+	c.cancel()
+	c.wg.Wait()
+	return errors.Trace(c.session.Close())
+}
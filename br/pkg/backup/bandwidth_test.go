@@ -0,0 +1,78 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package backup_test
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/br/pkg/backup"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+func randomLocalURL(t *testing.T) url.URL {
+	l, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return url.URL{Scheme: "http", Host: addr}
+}
+
+func startEtcd(t *testing.T) *clientv3.Client {
+	etcdCfg := embed.NewConfig()
+	etcdCfg.Dir = t.TempDir()
+	clientURL := randomLocalURL(t)
+	etcdCfg.LCUrls = []url.URL{clientURL}
+	etcdCfg.ACUrls = []url.URL{clientURL}
+	peerURL := randomLocalURL(t)
+	etcdCfg.LPUrls = []url.URL{peerURL}
+	etcdCfg.APUrls = []url.URL{peerURL}
+	etcdCfg.InitialCluster = etcdCfg.InitialClusterFromName(etcdCfg.Name)
+	etcdCfg.LogLevel = "fatal"
+	etcd, err := embed.StartEtcd(etcdCfg)
+	require.NoError(t, err)
+	t.Cleanup(etcd.Close)
+	<-etcd.Server.ReadyNotify()
+
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{clientURL.Host}})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, cli.Close()) })
+	return cli
+}
+
+func TestBandwidthCoordinator(t *testing.T) {
+	ctx := context.Background()
+	cli := startEtcd(t)
+
+	const budget = 1200
+	c1, err := backup.NewBandwidthCoordinator(ctx, cli, "gateway", budget)
+	require.NoError(t, err)
+	require.EqualValues(t, 400, c1.RateLimit(3))
+
+	c2, err := backup.NewBandwidthCoordinator(ctx, cli, "gateway", budget)
+	require.NoError(t, err)
+	require.EqualValues(t, 300, c2.RateLimit(2))
+	require.Eventually(t, func() bool {
+		return c1.RateLimit(2) == 300
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// the processes of the other groups don't share the budget.
+	c3, err := backup.NewBandwidthCoordinator(ctx, cli, "other", budget)
+	require.NoError(t, err)
+	require.EqualValues(t, budget, c3.RateLimit(1))
+	require.NoError(t, c3.Close())
+
+	// the share is given back after leaving the group.
+	require.NoError(t, c2.Close())
+	require.Eventually(t, func() bool {
+		return c1.RateLimit(1) == budget
+	}, 5*time.Second, 50*time.Millisecond)
+	// the limit is never zero, which means unlimited for TiKV.
+	require.EqualValues(t, 1, c1.RateLimit(budget*2))
+	require.NoError(t, c1.Close())
+}
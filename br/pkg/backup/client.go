@@ -91,6 +91,9 @@ type Client struct {
 	apiVersion kvrpcpb.APIVersion
 
 	gcTTL int64
+	// bandwidth divides the rate limit shared with the other BR processes, nil if the
+	// backup isn't coordinated.
+	bandwidth *BandwidthCoordinator
 }
 
 // NewBackupClient returns a new backup client.
@@ -158,6 +161,12 @@ func (bc *Client) SetGCTTL(ttl int64) {
 	bc.gcTTL = ttl
 }
 
+// SetBandwidthCoordinator sets the coordinator of the bandwidth shared with the other BR
+// processes, which overrides the rate limit of the backup requests.
+func (bc *Client) SetBandwidthCoordinator(c *BandwidthCoordinator) {
+	bc.bandwidth = c
+}
+
 // GetGCTTL get gcTTL for this backup.
 func (bc *Client) GetGCTTL() int64 {
 	return bc.gcTTL
@@ -180,22 +189,26 @@ func (bc *Client) SetStorage(ctx context.Context, backend *backuppb.StorageBacke
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if err = checkBackupStorageIsEmpty(ctx, bc.storage); err != nil {
+		return err
+	}
+	bc.backend = backend
+	return nil
+}
+
+// checkBackupStorageIsEmpty checks that no backup has been written to the storage.
+func checkBackupStorageIsEmpty(ctx context.Context, s storage.ExternalStorage) error {
 	// backupmeta already exists
-	exist, err := bc.storage.FileExists(ctx, metautil.MetaFile)
+	exist, err := s.FileExists(ctx, metautil.MetaFile)
 	if err != nil {
 		return errors.Annotatef(err, "error occurred when checking %s file", metautil.MetaFile)
 	}
 	if exist {
 		return errors.Annotatef(berrors.ErrInvalidArgument, "backup meta file exists in %v, "+
 			"there may be some backup files in the path already, "+
-			"please specify a correct backup directory!", bc.storage.URI()+"/"+metautil.MetaFile)
+			"please specify a correct backup directory!", s.URI()+"/"+metautil.MetaFile)
 	}
-	err = CheckBackupStorageIsLocked(ctx, bc.storage)
-	if err != nil {
-		return err
-	}
-	bc.backend = backend
-	return nil
+	return CheckBackupStorageIsLocked(ctx, s)
 }
 
 // GetClusterID returns the cluster ID of the tidb cluster to backup.
@@ -623,6 +636,11 @@ func (bc *Client) BackupRange(
 		return errors.Trace(err)
 	}
 
+	if bc.bandwidth != nil {
+		req.RateLimit = bc.bandwidth.RateLimit(len(allStores))
+		logutil.CL(ctx).Info("rate limit assigned by the bandwidth group", zap.Uint64("rateLimit", req.RateLimit))
+	}
+
 	logutil.CL(ctx).Info("backup push down started")
 	push := newPushDown(bc.mgr, len(allStores))
 	results, err := push.pushBackup(ctx, req, allStores, progressCallBack)
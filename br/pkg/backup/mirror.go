@@ -0,0 +1,113 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"time"
+
+	"github.com/pingcap/errors"
+	backuppb "github.com/pingcap/kvproto/pkg/brpb"
+	"github.com/pingcap/log"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/br/pkg/utils"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	mirrorRetryTimes       = 8
+	mirrorWaitInterval     = time.Second
+	mirrorMaxWaitInterval  = 30 * time.Second
+	defaultMirrorWorkerNum = 8
+)
+
+// OpenMirrorStorage opens the storage mirroring the backup files. Like the backup storage,
+// the mirror must not contain another backup.
+func OpenMirrorStorage(
+	ctx context.Context,
+	backend *backuppb.StorageBackend,
+	opts *storage.ExternalStorageOptions,
+) (storage.ExternalStorage, error) {
+	s, err := storage.New(ctx, backend, opts)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err = checkBackupStorageIsEmpty(ctx, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// MirrorFiles copies all the files of the backup storage to the mirror storage. TiKV only
+// writes the SST files to the backup storage, so the files are copied after the backup is
+// finished. Each file is retried on its own and verified by reading it back from the mirror,
+// so a flaky mirror doesn't fail the files that are already copied.
+func MirrorFiles(ctx context.Context, src, dst storage.ExternalStorage, concurrency uint) error {
+	if concurrency == 0 {
+		concurrency = defaultMirrorWorkerNum
+	}
+	start := time.Now()
+	var (
+		fileCount atomic.Int64
+		totalSize atomic.Int64
+	)
+	pool := utils.NewWorkerPool(concurrency, "mirror backup")
+	eg, ectx := errgroup.WithContext(ctx)
+	err := src.WalkDir(ectx, &storage.WalkOption{}, func(name string, size int64) error {
+		pool.ApplyOnErrorGroup(eg, func() error {
+			// the storage errors are various, so retry on any of them.
+			bo := utils.InitialRetryState(mirrorRetryTimes, mirrorWaitInterval, mirrorMaxWaitInterval)
+			err := utils.WithRetry(ectx, func() error {
+				return mirrorFile(ectx, src, dst, name)
+			}, &bo)
+			if err != nil {
+				return errors.Annotatef(err, "failed to mirror %s to %s", name, dst.URI())
+			}
+			fileCount.Inc()
+			totalSize.Add(size)
+			return nil
+		})
+		return nil
+	})
+	if err != nil {
+		// wait for the copying files before returning.
+		_ = eg.Wait()
+		return errors.Trace(err)
+	}
+	if err = eg.Wait(); err != nil {
+		return err
+	}
+	log.Info("mirror backup files finished",
+		zap.String("mirror", dst.URI()),
+		zap.Int64("files", fileCount.Load()),
+		zap.Int64("size", totalSize.Load()),
+		zap.Duration("take", time.Since(start)))
+	return nil
+}
+
+// mirrorFile copies one file and checks the copy against the checksum of the original.
+func mirrorFile(ctx context.Context, src, dst storage.ExternalStorage, name string) error {
+	data, err := src.ReadFile(ctx, name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = dst.WriteFile(ctx, name, data); err != nil {
+		return errors.Trace(err)
+	}
+	written, err := dst.ReadFile(ctx, name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	expected, actual := sha256.Sum256(data), sha256.Sum256(written)
+	if !bytes.Equal(expected[:], actual[:]) {
+		log.Warn("the mirrored file doesn't match the backup file, retrying",
+			zap.String("file", name), zap.Int("size", len(data)), zap.Int("mirrored size", len(written)))
+		return errors.Annotatef(berrors.ErrStorageChecksumMismatch, "mirrored file %s", name)
+	}
+	return nil
+}
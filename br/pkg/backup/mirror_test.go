@@ -0,0 +1,69 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// corruptingStorage truncates the first write of each file.
+type corruptingStorage struct {
+	storage.ExternalStorage
+
+	mu      sync.Mutex
+	written map[string]int
+}
+
+func (s *corruptingStorage) WriteFile(ctx context.Context, name string, data []byte) error {
+	s.mu.Lock()
+	s.written[name]++
+	first := s.written[name] == 1
+	s.mu.Unlock()
+	if first && len(data) > 0 {
+		data = data[:len(data)-1]
+	}
+	return s.ExternalStorage.WriteFile(ctx, name, data)
+}
+
+func TestMirrorFiles(t *testing.T) {
+	ctx := context.Background()
+	src, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	files := map[string][]byte{metautil.MetaFile: []byte("meta")}
+	for i := 0; i < 5; i++ {
+		files[fmt.Sprintf("%d_1_1_default.sst", i)] = []byte(fmt.Sprintf("sst content %d", i))
+	}
+	for name, data := range files {
+		require.NoError(t, src.WriteFile(ctx, name, data))
+	}
+
+	mirrorDir := t.TempDir()
+	local, err := storage.NewLocalStorage(mirrorDir)
+	require.NoError(t, err)
+	dst := &corruptingStorage{ExternalStorage: local, written: make(map[string]int)}
+	require.NoError(t, MirrorFiles(ctx, src, dst, 2))
+	for name, data := range files {
+		// the corrupted copies are found by the verification and written again.
+		require.Equal(t, 2, dst.written[name], name)
+		mirrored, err := local.ReadFile(ctx, name)
+		require.NoError(t, err)
+		require.Equal(t, data, mirrored, name)
+	}
+
+	// the mirror must not contain another backup.
+	backend, err := storage.ParseBackend("local://"+mirrorDir, nil)
+	require.NoError(t, err)
+	_, err = OpenMirrorStorage(ctx, backend, &storage.ExternalStorageOptions{})
+	require.ErrorContains(t, err, "backup meta file exists")
+	backend, err = storage.ParseBackend("local://"+t.TempDir(), nil)
+	require.NoError(t, err)
+	_, err = OpenMirrorStorage(ctx, backend, &storage.ExternalStorageOptions{})
+	require.NoError(t, err)
+}
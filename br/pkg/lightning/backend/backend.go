@@ -220,6 +220,14 @@ type AbstractBackend interface {
 	TotalMemoryConsume() int64
 }
 
+// Throttler is implemented by the backends whose speed of writing to the target can be
+// changed at runtime.
+type Throttler interface {
+	// Throttle limits the concurrency of writing to the target to ratio of the configured one,
+	// and the write bandwidth of every TiKV store to bwLimit bytes per second, 0 means unlimited.
+	Throttle(ratio float64, bwLimit int)
+}
+
 // Backend is the delivery target for Lightning
 type Backend struct {
 	abstract AbstractBackend
@@ -293,6 +301,13 @@ func (be Backend) TotalMemoryConsume() int64 {
 	return be.abstract.TotalMemoryConsume()
 }
 
+// Throttle changes the speed of writing to the target if the backend supports it.
+func (be Backend) Throttle(ratio float64, bwLimit int) {
+	if t, ok := be.abstract.(Throttler); ok {
+		t.Throttle(ratio, bwLimit)
+	}
+}
+
 // CheckDiskQuota verifies if the total engine file size is below the given
 // quota. If the quota is exceeded, this method returns an array of engines,
 // which after importing can decrease the total size below quota.
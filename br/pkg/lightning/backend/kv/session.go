@@ -245,6 +245,13 @@ type SessionOptions struct {
 	AutoRandomSeed int64
 	// IndexID is used by the DuplicateManager. Only the key range with the specified index ID is scanned.
 	IndexID int64
+	// ColumnTransforms maps the lower-case column names to the expressions setting their values,
+	// which are evaluated by the tableKVEncoder. See `mydumper.column-transforms`.
+	ColumnTransforms map[string]string
+	// IgnoreUnknownColumns is whether the fields of the data files which aren't mapped to any column
+	// are skipped by the encoder instead of reported as a column count mismatch.
+	// See `mydumper.ignore-unknown-columns`.
+	IgnoreUnknownColumns bool
 }
 
 // NewSession creates a new trimmed down Session matching the options.
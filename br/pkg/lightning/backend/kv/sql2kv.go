@@ -59,9 +59,15 @@ type tableKVEncoder struct {
 	se          *session
 	recordCache []types.Datum
 	genCols     []genCol
+	// transformCols are the columns set by the expressions of `mydumper.column-transforms`,
+	// they are evaluated before the generated columns.
+	transformCols []genCol
+	transformed   []bool
 	// convert auto id for shard rowid or auto random id base on row id generated by lightning
 	autoIDFn autoIDConverter
 	metrics  *metric.Metrics
+	// rowPermutation is the buffer of the column permutation of a row with missing fields.
+	rowPermutation []int
 }
 
 func GetSession4test(encoder Encoder) sessionctx.Context {
@@ -112,16 +118,101 @@ func NewTableKVEncoder(
 	if err != nil {
 		return nil, errors.Annotate(err, "failed to parse generated column expressions")
 	}
+	transformCols, err := collectColumnTransforms(se, meta, cols, options.ColumnTransforms)
+	if err != nil {
+		return nil, errors.Annotate(err, "failed to parse column transform expressions")
+	}
+	var transformed []bool
+	if len(transformCols) > 0 {
+		transformed = make([]bool, len(cols))
+		for _, tc := range transformCols {
+			transformed[tc.index] = true
+		}
+	}
 
 	return &tableKVEncoder{
-		tbl:      tbl,
-		se:       se,
-		genCols:  genCols,
-		autoIDFn: autoIDFn,
-		metrics:  metrics,
+		tbl:           tbl,
+		se:            se,
+		genCols:       genCols,
+		transformCols: transformCols,
+		transformed:   transformed,
+		autoIDFn:      autoIDFn,
+		metrics:       metrics,
 	}, nil
 }
 
+// exprSchema returns the schema and the names of the columns for rewriting the expressions
+// evaluated from the rows of the table.
+func exprSchema(meta *model.TableInfo, cols []*table.Column) (*expression.Schema, types.NameSlice) {
+	// not using TableInfo2SchemaAndNames to avoid parsing all virtual generated columns again.
+	exprColumns := make([]*expression.Column, 0, len(cols))
+	names := make(types.NameSlice, 0, len(cols))
+	for i, col := range cols {
+		names = append(names, &types.FieldName{
+			OrigTblName: meta.Name,
+			OrigColName: col.Name,
+			TblName:     meta.Name,
+			ColName:     col.Name,
+		})
+		exprColumns = append(exprColumns, &expression.Column{
+			RetType:  col.FieldType.Clone(),
+			ID:       col.ID,
+			UniqueID: int64(i),
+			Index:    col.Offset,
+			OrigName: names[i].String(),
+			IsHidden: col.Hidden,
+		})
+	}
+	return expression.NewSchema(exprColumns...), names
+}
+
+// collectColumnTransforms parses the expressions of the transformed columns. The returning
+// slice is in the order of the columns.
+func collectColumnTransforms(se *session, meta *model.TableInfo, cols []*table.Column, transforms map[string]string) ([]genCol, error) {
+	if len(transforms) == 0 {
+		return nil, nil
+	}
+
+	// the expression rewriter requires a non-nil TxnCtx.
+	se.vars.TxnCtx = new(variable.TransactionContext)
+	defer func() {
+		se.vars.TxnCtx = nil
+	}()
+
+	schema, names := exprSchema(meta, cols)
+	transformCols := make([]genCol, 0, len(transforms))
+	found := 0
+	for i, col := range cols {
+		exprStr, ok := transforms[col.Name.L]
+		if !ok {
+			continue
+		}
+		found++
+		if col.IsGenerated() || isAutoIncCol(col.ToInfo()) || (isTableAutoRandom(meta) && isPKCol(col.ToInfo())) {
+			return nil, errors.Errorf("column `%s` can't be transformed since its values are generated", col.Name.O)
+		}
+		exprs, err := expression.ParseSimpleExprsWithNames(se, exprStr, schema, names)
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid expression of column `%s`", col.Name.O)
+		}
+		if len(exprs) != 1 {
+			return nil, errors.Errorf("the expression of column `%s` must be a single expression", col.Name.O)
+		}
+		transformCols = append(transformCols, genCol{
+			index: i,
+			expr:  exprs[0],
+		})
+	}
+	if found < len(transforms) {
+		for name := range transforms {
+			if table.FindColLowerCase(cols, name) == nil {
+				return nil, errors.Errorf("unknown column `%s` in the column transforms", name)
+			}
+		}
+	}
+	return transformCols, nil
+}
+
 // collectGeneratedColumns collects all expressions required to evaluate the
 // results of all generated columns. The returning slice is in evaluation order.
 func collectGeneratedColumns(se *session, meta *model.TableInfo, cols []*table.Column) ([]genCol, error) {
@@ -143,26 +234,7 @@ func collectGeneratedColumns(se *session, meta *model.TableInfo, cols []*table.C
 		se.vars.TxnCtx = nil
 	}()
 
-	// not using TableInfo2SchemaAndNames to avoid parsing all virtual generated columns again.
-	exprColumns := make([]*expression.Column, 0, len(cols))
-	names := make(types.NameSlice, 0, len(cols))
-	for i, col := range cols {
-		names = append(names, &types.FieldName{
-			OrigTblName: meta.Name,
-			OrigColName: col.Name,
-			TblName:     meta.Name,
-			ColName:     col.Name,
-		})
-		exprColumns = append(exprColumns, &expression.Column{
-			RetType:  col.FieldType.Clone(),
-			ID:       col.ID,
-			UniqueID: int64(i),
-			Index:    col.Offset,
-			OrigName: names[i].String(),
-			IsHidden: col.Hidden,
-		})
-	}
-	schema := expression.NewSchema(exprColumns...)
+	schema, names := exprSchema(meta, cols)
 
 	// as long as we have a stored generated column, all columns it referred to must be evaluated as well.
 	// for simplicity we just evaluate all generated columns (virtual or not) before the last stored one.
@@ -273,6 +345,20 @@ func logKVConvertFailed(logger log.Logger, row []types.Datum, j int, colInfo *mo
 	)
 }
 
+func logEvalTransformFailed(logger log.Logger, row []types.Datum, colInfo *model.ColumnInfo, err error) error {
+	logger.Error("kv convert failed: cannot evaluate column transform expression",
+		zap.Array("original", RowArrayMarshaler(row)),
+		zap.String("colName", colInfo.Name.O),
+		log.ShortError(err),
+	)
+
+	return errors.Annotatef(
+		err,
+		"failed to evaluate transform expression for column `%s`",
+		colInfo.Name.O,
+	)
+}
+
 func logEvalGenExprFailed(logger log.Logger, row []types.Datum, colInfo *model.ColumnInfo, err error) error {
 	logger.Error("kv convert failed: cannot evaluate generated column expression",
 		zap.Array("original", RowArrayMarshaler(row)),
@@ -373,6 +459,12 @@ func (kvcodec *tableKVEncoder) Encode(
 		if j >= 0 && j < len(row) {
 			theDatum = &row[j]
 		}
+		if theDatum == nil && kvcodec.transformed != nil && kvcodec.transformed[i] {
+			// the column is set by the transform expression later, inject a dummy value like
+			// the generated columns instead of requiring a default value.
+			record = append(record, types.GetMinValue(&col.FieldType))
+			continue
+		}
 		value, err = kvcodec.getActualDatum(rowID, i, theDatum)
 		if err != nil {
 			return nil, logKVConvertFailed(logger, row, j, col.ToInfo(), err)
@@ -415,6 +507,12 @@ func (kvcodec *tableKVEncoder) Encode(
 		}
 	}
 
+	if len(kvcodec.transformCols) > 0 {
+		if errCol, err := evaluateGeneratedColumns(kvcodec.se, record, cols, kvcodec.transformCols); err != nil {
+			return nil, logEvalTransformFailed(logger, row, errCol, err)
+		}
+	}
+
 	if len(kvcodec.genCols) > 0 {
 		if errCol, err := evaluateGeneratedColumns(kvcodec.se, record, cols, kvcodec.genCols); err != nil {
 			return nil, logEvalGenExprFailed(logger, row, errCol, err)
@@ -465,6 +563,27 @@ func GetActualDatum(encoder Encoder, rowID int64, colIndex int, inputDatum *type
 	return encoder.(*tableKVEncoder).getActualDatum(70, 0, inputDatum)
 }
 
+// EncodeWithMissing implements the MissingFieldsEncoder interface. The columns of the
+// missing fields are taken as absent in the data file.
+func (kvcodec *tableKVEncoder) EncodeWithMissing(
+	logger log.Logger,
+	row []types.Datum,
+	missing []bool,
+	rowID int64,
+	columnPermutation []int,
+	path string,
+	offset int64,
+) (Row, error) {
+	permutation := append(kvcodec.rowPermutation[:0], columnPermutation...)
+	for i, j := range permutation {
+		if j >= 0 && j < len(missing) && missing[j] {
+			permutation[i] = -1
+		}
+	}
+	kvcodec.rowPermutation = permutation
+	return kvcodec.Encode(logger, row, rowID, permutation, path, offset)
+}
+
 func (kvcodec *tableKVEncoder) getActualDatum(rowID int64, colIndex int, inputDatum *types.Datum) (types.Datum, error) {
 	var (
 		value types.Datum
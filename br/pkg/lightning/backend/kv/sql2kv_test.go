@@ -15,6 +15,8 @@
 package kv_test
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"reflect"
@@ -31,6 +33,7 @@ import (
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
+	_ "github.com/pingcap/tidb/planner/core" // to setup expression.RewriteAstExpr for the generated columns and column transforms
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
@@ -364,6 +367,64 @@ func TestEncodeDoubleAutoIncrement(t *testing.T) {
 	require.Equal(t, tbl.Allocators(lkv.GetEncoderSe(encoder)).Get(autoid.AutoIncrementType).Base(), int64(70))
 }
 
+func TestEncodeColumnTransforms(t *testing.T) {
+	tblInfo := mockTableInfo(t, "create table t (id int primary key, email varchar(64), tenant_id int not null, u varchar(64) as (upper(email)) stored);")
+	tbl, err := tables.TableFromMeta(lkv.NewPanickingAllocators(0), tblInfo)
+	require.NoError(t, err)
+
+	logger := log.Logger{Logger: zap.NewNop()}
+	sysVars := map[string]string{"tidb_row_format_version": "2"}
+
+	encoder, err := lkv.NewTableKVEncoder(tbl, &lkv.SessionOptions{
+		SQLMode: mysql.ModeStrictAllTables,
+		SysVars: sysVars,
+		ColumnTransforms: map[string]string{
+			"email":     "SHA2(email, 256)",
+			"tenant_id": "40 + 2",
+		},
+	}, nil, log.L())
+	require.NoError(t, err)
+	// tenant_id is not in the data file, it's set by the transform instead of the default value.
+	pairs, err := encoder.Encode(logger, []types.Datum{
+		types.NewIntDatum(1),
+		types.NewStringDatum("someone@example.com"),
+	}, 1, []int{0, 1, -1, -1, -1}, "1.csv", 0)
+	require.NoError(t, err)
+
+	// the transformed rows are the same as the rows with the transformed values.
+	plainEncoder, err := lkv.NewTableKVEncoder(tbl, &lkv.SessionOptions{
+		SQLMode: mysql.ModeStrictAllTables,
+		SysVars: sysVars,
+	}, nil, log.L())
+	require.NoError(t, err)
+	hash := sha256.Sum256([]byte("someone@example.com"))
+	expected, err := plainEncoder.Encode(logger, []types.Datum{
+		types.NewIntDatum(1),
+		types.NewStringDatum(hex.EncodeToString(hash[:])),
+		types.NewIntDatum(42),
+	}, 1, []int{0, 1, 2, -1, -1}, "1.csv", 0)
+	require.NoError(t, err)
+	require.Equal(t, expected, pairs)
+
+	invalidTransforms := []struct {
+		transforms map[string]string
+		err        string
+	}{
+		{map[string]string{"x": "1"}, "unknown column `x`"},
+		{map[string]string{"u": "'a'"}, "column `u` can't be transformed"},
+		{map[string]string{"email": "SHA2(email"}, "invalid expression of column `email`"},
+		{map[string]string{"email": "1, 2"}, "must be a single expression"},
+	}
+	for _, c := range invalidTransforms {
+		_, err := lkv.NewTableKVEncoder(tbl, &lkv.SessionOptions{
+			SQLMode:          mysql.ModeStrictAllTables,
+			SysVars:          sysVars,
+			ColumnTransforms: c.transforms,
+		}, nil, log.L())
+		require.ErrorContains(t, err, c.err)
+	}
+}
+
 func TestEncodeMissingAutoValue(t *testing.T) {
 	logger := log.Logger{Logger: zap.NewNop()}
 
@@ -430,6 +491,70 @@ func TestEncodeMissingAutoValue(t *testing.T) {
 	}
 }
 
+func TestEncodeMissingFields(t *testing.T) {
+	logger := log.Logger{Logger: zap.NewNop()}
+	tblInfo := mockTableInfo(t, "create table t (id int primary key auto_increment, a int default 7, b int);")
+	tbl, err := tables.TableFromMeta(lkv.NewPanickingAllocators(0), tblInfo)
+	require.NoError(t, err)
+
+	encoder, err := lkv.NewTableKVEncoder(tbl, &lkv.SessionOptions{
+		SQLMode: mysql.ModeStrictAllTables,
+		SysVars: map[string]string{
+			"tidb_row_format_version": "2",
+		},
+	}, nil, log.L())
+	require.NoError(t, err)
+
+	var nullDatum types.Datum
+	nullDatum.SetNull()
+
+	pairsExpect, err := encoder.Encode(logger, []types.Datum{
+		types.NewIntDatum(1),
+		types.NewIntDatum(7),
+		types.NewIntDatum(3),
+	}, 1, []int{0, 1, 2, -1}, "1.json", 1234)
+	require.NoError(t, err)
+
+	// the missing field takes the default value instead of NULL.
+	pairs, err := lkv.EncodeRow(encoder, logger, []types.Datum{
+		types.NewIntDatum(1),
+		nullDatum,
+		types.NewIntDatum(3),
+	}, []bool{false, true, false}, 1, []int{0, 1, 2, -1}, "1.json", 1234)
+	require.NoError(t, err)
+	require.Equal(t, pairsExpect, pairs)
+
+	// a NULL value which is present is still NULL.
+	pairsExpect, err = encoder.Encode(logger, []types.Datum{
+		types.NewIntDatum(1),
+		nullDatum,
+		types.NewIntDatum(3),
+	}, 1, []int{0, 1, 2, -1}, "1.json", 1234)
+	require.NoError(t, err)
+	pairs, err = lkv.EncodeRow(encoder, logger, []types.Datum{
+		types.NewIntDatum(1),
+		nullDatum,
+		types.NewIntDatum(3),
+	}, nil, 1, []int{0, 1, 2, -1}, "1.json", 1234)
+	require.NoError(t, err)
+	require.Equal(t, pairsExpect, pairs)
+
+	// the missing auto-increment column is allocated from the row ID.
+	pairsExpect, err = encoder.Encode(logger, []types.Datum{
+		types.NewIntDatum(lkv.GetEncoderIncrementalID(encoder, 70)),
+		nullDatum,
+		types.NewIntDatum(3),
+	}, 70, []int{0, 1, 2, -1}, "1.json", 1234)
+	require.NoError(t, err)
+	pairs, err = lkv.EncodeRow(encoder, logger, []types.Datum{
+		nullDatum,
+		nullDatum,
+		types.NewIntDatum(3),
+	}, []bool{true, false, false}, 70, []int{0, 1, 2, -1}, "1.json", 1234)
+	require.NoError(t, err)
+	require.Equal(t, pairsExpect, pairs)
+}
+
 func mockTableInfo(t *testing.T, createSQL string) *model.TableInfo {
 	parser := parser.New()
 	node, err := parser.ParseOneStmt(createSQL, "", "")
@@ -25,6 +25,43 @@ type Encoder interface {
 	) (Row, error)
 }
 
+// MissingFieldsEncoder is an Encoder which can fill the columns of the fields missing in
+// a row, e.g. the keys absent in a JSON object, with their default values, like the
+// columns absent in the data file.
+type MissingFieldsEncoder interface {
+	Encoder
+
+	// EncodeWithMissing is like Encode, but the columns of the fields whose `missing`
+	// is true take their default values.
+	EncodeWithMissing(
+		logger log.Logger,
+		row []types.Datum,
+		missing []bool,
+		rowID int64,
+		columnPermutation []int,
+		path string,
+		offset int64,
+	) (Row, error)
+}
+
+// EncodeRow encodes a row with the encoder. The columns of the missing fields take their
+// default values if the encoder is a MissingFieldsEncoder, or NULL otherwise.
+func EncodeRow(
+	enc Encoder,
+	logger log.Logger,
+	row []types.Datum,
+	missing []bool,
+	rowID int64,
+	columnPermutation []int,
+	path string,
+	offset int64,
+) (Row, error) {
+	if e, ok := enc.(MissingFieldsEncoder); ok && len(missing) > 0 {
+		return e.EncodeWithMissing(logger, row, missing, rowID, columnPermutation, path, offset)
+	}
+	return enc.Encode(logger, row, rowID, columnPermutation, path, offset)
+}
+
 // Row represents a single encoded row.
 type Row interface {
 	// ClassifyAndAppend separates the data-like and index-like parts of the
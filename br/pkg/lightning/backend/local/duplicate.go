@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/tidb/br/pkg/lightning/backend/kv"
 	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/pingcap/tidb/br/pkg/lightning/errormanager"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/br/pkg/logutil"
@@ -402,6 +403,7 @@ type DuplicateManager struct {
 	concurrency int
 	hasDupe     *atomic.Bool
 	indexID     int64
+	ignoreRules []*config.DuplicateIgnoreRule
 }
 
 // NewDuplicateManager creates a new DuplicateManager.
@@ -414,6 +416,7 @@ func NewDuplicateManager(
 	sessOpts *kv.SessionOptions,
 	concurrency int,
 	hasDupe *atomic.Bool,
+	ignoreRules []*config.DuplicateIgnoreRule,
 	logger log.Logger,
 ) (*DuplicateManager, error) {
 	logger = logger.With(zap.String("tableName", tableName))
@@ -432,6 +435,7 @@ func NewDuplicateManager(
 		concurrency: concurrency,
 		hasDupe:     hasDupe,
 		indexID:     sessOpts.IndexID,
+		ignoreRules: ignoreRules,
 	}, nil
 }
 
@@ -573,6 +577,10 @@ func (m *DuplicateManager) buildDupTasks() ([]dupTask, error) {
 		if indexInfo.State != model.StatePublic {
 			continue
 		}
+		if m.isIndexIgnored(indexInfo) {
+			m.logger.Info("[detect-dupe] skip the ignored index", zap.String("index", indexInfo.Name.O))
+			continue
+		}
 		keyRanges, err = tableIndexKeyRanges(m.tbl.Meta(), indexInfo)
 		if err != nil {
 			return nil, errors.Trace(err)
@@ -589,6 +597,15 @@ func (m *DuplicateManager) buildDupTasks() ([]dupTask, error) {
 	return tasks, nil
 }
 
+func (m *DuplicateManager) isIndexIgnored(indexInfo *model.IndexInfo) bool {
+	for _, rule := range m.ignoreRules {
+		if rule.IgnoresIndex(m.tableName, indexInfo) {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *DuplicateManager) buildIndexDupTasks() ([]dupTask, error) {
 	for _, indexInfo := range m.tbl.Meta().Indices {
 		if m.indexID != indexInfo.ID {
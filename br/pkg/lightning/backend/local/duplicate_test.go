@@ -20,6 +20,7 @@ import (
 
 	lkv "github.com/pingcap/tidb/br/pkg/lightning/backend/kv"
 	"github.com/pingcap/tidb/br/pkg/lightning/backend/local"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/parser"
@@ -53,7 +54,7 @@ func TestBuildDupTask(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		dupMgr, err := local.NewDuplicateManager(tbl, "t", nil, nil, nil,
-			tc.sessOpt, 4, atomic.NewBool(false), log.FromContext(context.Background()))
+			tc.sessOpt, 4, atomic.NewBool(false), nil, log.FromContext(context.Background()))
 		require.NoError(t, err)
 		tasks, err := local.BuildDuplicateTaskForTest(dupMgr)
 		require.NoError(t, err)
@@ -69,3 +70,52 @@ func TestBuildDupTask(t *testing.T) {
 		require.Equal(t, tc.hasTableRange, hasRecordKey)
 	}
 }
+
+func TestBuildDupTaskWithIgnoreRules(t *testing.T) {
+	p := parser.New()
+	node, _, err := p.ParseSQL("create table t (a int, b int, c int, unique key uk_a(a), unique key uk_bc(b, c));")
+	require.NoError(t, err)
+	info, err := ddl.MockTableInfo(mock.NewContext(), node[0].(*ast.CreateTableStmt), 1)
+	require.NoError(t, err)
+	info.State = model.StatePublic
+	tbl, err := tables.TableFromMeta(lkv.NewPanickingAllocators(0), info)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		rules   []*config.DuplicateIgnoreRule
+		indexes []string
+	}{
+		{nil, []string{"uk_a", "uk_bc"}},
+		{[]*config.DuplicateIgnoreRule{{Schema: "test", Table: "T", Indexes: []string{"UK_A"}}}, []string{"uk_bc"}},
+		{[]*config.DuplicateIgnoreRule{{Schema: "test", Table: "t", Columns: []string{"c"}}}, []string{"uk_a"}},
+		{[]*config.DuplicateIgnoreRule{{Schema: "test", Table: "t2", Columns: []string{"a", "b"}}}, []string{"uk_a", "uk_bc"}},
+	}
+	for _, tc := range testCases {
+		dupMgr, err := local.NewDuplicateManager(tbl, "`test`.`t`", nil, nil, nil,
+			&lkv.SessionOptions{}, 4, atomic.NewBool(false), tc.rules, log.FromContext(context.Background()))
+		require.NoError(t, err)
+		tasks, err := local.BuildDuplicateTaskForTest(dupMgr)
+		require.NoError(t, err)
+		var hasRecordKey bool
+		indexes := make(map[string]struct{})
+		for _, task := range tasks {
+			_, indexID, isRecordKey, err := tablecodec.DecodeKeyHead(task.StartKey)
+			require.NoError(t, err)
+			if isRecordKey {
+				hasRecordKey = true
+				continue
+			}
+			for _, indexInfo := range info.Indices {
+				if indexInfo.ID == indexID {
+					indexes[indexInfo.Name.O] = struct{}{}
+				}
+			}
+		}
+		// the row keys are always checked.
+		require.True(t, hasRecordKey)
+		require.Len(t, indexes, len(tc.indexes))
+		for _, index := range tc.indexes {
+			require.Contains(t, indexes, index)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/storage"
+)
+
+// engineKeyFileName is the file in the sorted-kv-dir storing the data key wrapped by the
+// key ring, so the engines can be decrypted when they're resumed from the checkpoint.
+const engineKeyFileName = "engine-data-key.json"
+
+// encryptedFS encrypts the content of the files by AES-CTR. Each file starts with a random
+// IV of the file, followed by the encrypted content, so the offset of the content is mapped
+// to the counter directly, which allows the random reads of the SST files.
+type encryptedFS struct {
+	vfs.FS
+	block cipher.Block
+}
+
+// newEncryptedFS creates the encrypted FS by the data key, whose length decides the AES-128,
+// AES-192 or AES-256.
+func newEncryptedFS(fs vfs.FS, dataKey []byte) (*encryptedFS, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &encryptedFS{FS: fs, block: block}, nil
+}
+
+// Create implements vfs.FS.
+func (fs *encryptedFS) Create(name string) (vfs.File, error) {
+	f, err := fs.FS.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return fs.initFile(f)
+}
+
+// ReuseForWrite implements vfs.FS.
+func (fs *encryptedFS) ReuseForWrite(oldname, newname string) (vfs.File, error) {
+	f, err := fs.FS.ReuseForWrite(oldname, newname)
+	if err != nil {
+		return nil, err
+	}
+	return fs.initFile(f)
+}
+
+// initFile writes a new IV at the beginning of the file.
+func (fs *encryptedFS) initFile(f vfs.File) (vfs.File, error) {
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		_ = f.Close()
+		return nil, errors.Trace(err)
+	}
+	if _, err := f.Write(append([]byte{}, iv...)); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &encryptedFile{File: f, block: fs.block, iv: iv}, nil
+}
+
+// Open implements vfs.FS.
+func (fs *encryptedFS) Open(name string, opts ...vfs.OpenOption) (vfs.File, error) {
+	f, err := fs.FS.Open(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(f, iv); err != nil {
+		_ = f.Close()
+		return nil, errors.Annotatef(err, "failed to read the IV of encrypted file '%s'", name)
+	}
+	return &encryptedFile{File: f, block: fs.block, iv: iv}, nil
+}
+
+// Stat implements vfs.FS.
+func (fs *encryptedFS) Stat(name string) (os.FileInfo, error) {
+	info, err := fs.FS.Stat(name)
+	if err != nil || info.IsDir() {
+		return info, err
+	}
+	return encryptedFileInfo{FileInfo: info}, nil
+}
+
+type encryptedFile struct {
+	vfs.File
+	block cipher.Block
+	iv    []byte
+	// readOffset and writeOffset are the offsets of the content read by Read and
+	// written by Write, excluding the IV.
+	readOffset  int64
+	writeOffset int64
+	writeBuf    []byte
+}
+
+// xorKeyStream encrypts or decrypts the content at the offset in place.
+func (f *encryptedFile) xorKeyStream(buf []byte, offset int64) {
+	if len(buf) == 0 {
+		return
+	}
+	counter := make([]byte, aes.BlockSize)
+	copy(counter, f.iv)
+	// the IV is added by the block index of the offset as a 128-bit big-endian integer.
+	lo := binary.BigEndian.Uint64(counter[8:])
+	hi := binary.BigEndian.Uint64(counter[:8])
+	newLo := lo + uint64(offset/aes.BlockSize)
+	if newLo < lo {
+		hi++
+	}
+	binary.BigEndian.PutUint64(counter[:8], hi)
+	binary.BigEndian.PutUint64(counter[8:], newLo)
+
+	stream := cipher.NewCTR(f.block, counter)
+	if skip := offset % aes.BlockSize; skip > 0 {
+		var pad [aes.BlockSize]byte
+		stream.XORKeyStream(pad[:skip], pad[:skip])
+	}
+	stream.XORKeyStream(buf, buf)
+}
+
+// Read implements vfs.File.
+func (f *encryptedFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	f.xorKeyStream(p[:n], f.readOffset)
+	f.readOffset += int64(n)
+	return n, err
+}
+
+// ReadAt implements vfs.File.
+func (f *encryptedFile) ReadAt(p []byte, off int64) (int, error) {
+	n, err := f.File.ReadAt(p, off+aes.BlockSize)
+	f.xorKeyStream(p[:n], off)
+	return n, err
+}
+
+// Write implements vfs.File. The content is encrypted in a copy, since the SST writer may
+// reuse p after writing it.
+func (f *encryptedFile) Write(p []byte) (int, error) {
+	if cap(f.writeBuf) < len(p) {
+		f.writeBuf = make([]byte, len(p))
+	}
+	buf := f.writeBuf[:len(p)]
+	copy(buf, p)
+	f.xorKeyStream(buf, f.writeOffset)
+	n, err := f.File.Write(buf)
+	f.writeOffset += int64(n)
+	return n, err
+}
+
+// Stat implements vfs.File.
+func (f *encryptedFile) Stat() (os.FileInfo, error) {
+	info, err := f.File.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return encryptedFileInfo{FileInfo: info}, nil
+}
+
+// encryptedFileInfo excludes the IV from the size of the file.
+type encryptedFileInfo struct {
+	os.FileInfo
+}
+
+func (i encryptedFileInfo) Size() int64 {
+	size := i.FileInfo.Size() - aes.BlockSize
+	if size < 0 {
+		return 0
+	}
+	return size
+}
+
+type wrappedDataKey struct {
+	KeyID      string `json:"key-id"`
+	WrappedKey []byte `json:"wrapped-key"`
+}
+
+// loadEngineDataKey loads the data key of the encrypted engines. If the key ring is used,
+// the data key is unwrapped from the key file in the sorted-kv-dir, or generated and saved
+// there if it doesn't exist.
+func loadEngineDataKey(ctx context.Context, enc *config.EngineEncryption, sortedKVDir string) ([]byte, error) {
+	switch {
+	case len(enc.Key) > 0:
+		return hexDataKey(enc, []byte(enc.Key))
+	case len(enc.KeyFile) > 0:
+		content, err := os.ReadFile(enc.KeyFile)
+		if err != nil {
+			return nil, errors.Annotate(err, "failed to read the key file of the engine encryption")
+		}
+		return hexDataKey(enc, bytes.TrimSpace(content))
+	}
+
+	keyRing, err := storage.ParseKeyRing(enc.KeyRing)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	path := filepath.Join(sortedKVDir, engineKeyFileName)
+	content, err := os.ReadFile(path)
+	if err == nil {
+		var wrapped wrappedDataKey
+		if err := json.Unmarshal(content, &wrapped); err != nil {
+			return nil, errors.Annotatef(err, "failed to parse the engine data key file '%s'", path)
+		}
+		dataKey, err := keyRing.UnwrapKey(ctx, wrapped.KeyID, wrapped.WrappedKey)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(dataKey) != enc.KeyLen() {
+			return nil, common.ErrInvalidConfig.GenWithStack(
+				"the engine data key in '%s' doesn't match the encryption method %s", path, enc.Method)
+		}
+		return dataKey, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, errors.Trace(err)
+	}
+
+	dataKey := make([]byte, enc.KeyLen())
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, errors.Trace(err)
+	}
+	var wrapped wrappedDataKey
+	wrapped.KeyID, wrapped.WrappedKey, err = keyRing.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	content, err = json.Marshal(&wrapped)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return dataKey, nil
+}
+
+func hexDataKey(enc *config.EngineEncryption, content []byte) ([]byte, error) {
+	dataKey := make([]byte, hex.DecodedLen(len(content)))
+	if _, err := hex.Decode(dataKey, content); err != nil || len(dataKey) != enc.KeyLen() {
+		return nil, common.ErrInvalidConfig.GenWithStack(
+			"the engine data key should be a hex-encoded %d-bit key for %s", enc.KeyLen()*8, enc.Method)
+	}
+	return dataKey, nil
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedFS(t *testing.T) {
+	dir := t.TempDir()
+	fs, err := newEncryptedFS(vfs.Default, bytes.Repeat([]byte{0x42}, 32))
+	require.NoError(t, err)
+
+	content := []byte(strings.Repeat("plaintext customer data;", 100))
+	path := filepath.Join(dir, "file")
+	f, err := fs.Create(path)
+	require.NoError(t, err)
+	for _, chunk := range [][]byte{content[:7], content[7:500], content[500:]} {
+		_, err = f.Write(append([]byte{}, chunk...))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Sync())
+	require.NoError(t, f.Close())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Len(t, raw, len(content)+16)
+	require.NotContains(t, string(raw), "customer")
+
+	info, err := fs.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), info.Size())
+
+	f, err = fs.Open(path)
+	require.NoError(t, err)
+	all, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.Equal(t, content, all)
+	for _, off := range []int{0, 3, 16, 17, 1000, len(content) - 5} {
+		buf := make([]byte, 37)
+		n, _ := f.ReadAt(buf, int64(off))
+		require.Equal(t, content[off:off+n], buf[:n], "offset %d", off)
+	}
+	require.NoError(t, f.Close())
+
+	// the pebble DB is readable through the encrypted FS only.
+	dbPath := filepath.Join(dir, "db")
+	db, err := pebble.Open(dbPath, &pebble.Options{FS: fs})
+	require.NoError(t, err)
+	require.NoError(t, db.Set([]byte("key"), content, pebble.Sync))
+	require.NoError(t, db.Flush())
+	require.NoError(t, db.Close())
+	entries, err := os.ReadDir(dbPath)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == "LOCK" {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(dbPath, entry.Name()))
+		require.NoError(t, err)
+		require.NotContains(t, string(raw), "customer", entry.Name())
+	}
+	db, err = pebble.Open(dbPath, &pebble.Options{FS: fs})
+	require.NoError(t, err)
+	val, closer, err := db.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, content, val)
+	require.NoError(t, closer.Close())
+	require.NoError(t, db.Close())
+}
+
+func TestLoadEngineDataKey(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	enc := &config.EngineEncryption{Method: config.EncryptionAES128CTR, Key: "0123456789abcdef0123456789abcdef"}
+	key, err := loadEngineDataKey(ctx, enc, dir)
+	require.NoError(t, err)
+	require.Len(t, key, 16)
+
+	keyFile := filepath.Join(dir, "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("0123456789abcdef\n"), 0o600))
+	enc = &config.EngineEncryption{Method: config.EncryptionAES128CTR, KeyFile: keyFile}
+	_, err = loadEngineDataKey(ctx, enc, dir)
+	require.ErrorContains(t, err, "should be a hex-encoded 128-bit key")
+
+	keyRingFile := filepath.Join(dir, "keyring")
+	require.NoError(t, os.WriteFile(keyRingFile,
+		[]byte("k1 "+strings.Repeat("ab", 32)+"\n"), 0o600))
+	enc = &config.EngineEncryption{Method: config.EncryptionAES256CTR, KeyRing: keyRingFile}
+	key, err = loadEngineDataKey(ctx, enc, dir)
+	require.NoError(t, err)
+	require.Len(t, key, 32)
+	require.FileExists(t, filepath.Join(dir, engineKeyFileName))
+	// the data key is unwrapped from the key file when the engines are resumed.
+	key2, err := loadEngineDataKey(ctx, enc, dir)
+	require.NoError(t, err)
+	require.Equal(t, key, key2)
+}
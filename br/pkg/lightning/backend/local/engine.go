@@ -29,6 +29,7 @@ import (
 
 	"github.com/cockroachdb/pebble"
 	"github.com/cockroachdb/pebble/sstable"
+	"github.com/cockroachdb/pebble/vfs"
 	"github.com/google/btree"
 	"github.com/google/uuid"
 	"github.com/pingcap/errors"
@@ -107,6 +108,8 @@ type Engine struct {
 	ctx            context.Context
 	cancel         context.CancelFunc
 	sstDir         string
+	dataDir        string
+	fs             vfs.FS
 	sstMetasChan   chan metaOrFlush
 	ingestErr      common.OnceError
 	wg             sync.WaitGroup
@@ -387,6 +390,14 @@ func (s *sizeProperties) iter(f func(p *rangeProperty) bool) {
 	})
 }
 
+// iterFrom iterates the properties whose keys are greater than or equal to the key.
+func (s *sizeProperties) iterFrom(key []byte, f func(p *rangeProperty) bool) {
+	s.indexHandles.AscendGreaterOrEqual(&rangeProperty{Key: key}, func(i btree.Item) bool {
+		prop := i.(*rangeProperty)
+		return f(prop)
+	})
+}
+
 func decodeRangeProperties(data []byte, keyAdapter KeyAdapter) (rangeProperties, error) {
 	r := make(rangeProperties, 0, 16)
 	for len(data) > 0 {
@@ -1211,7 +1222,7 @@ func (w *Writer) addSST(ctx context.Context, meta *sstMeta) error {
 
 func (w *Writer) createSSTWriter() (*sstWriter, error) {
 	path := filepath.Join(w.engine.sstDir, uuid.New().String()+".sst")
-	writer, err := newSSTWriter(path)
+	writer, err := newSSTWriter(w.engine.fs, path)
 	if err != nil {
 		return nil, err
 	}
@@ -1227,8 +1238,8 @@ type sstWriter struct {
 	logger log.Logger
 }
 
-func newSSTWriter(path string) (*sstable.Writer, error) {
-	f, err := os.Create(path)
+func newSSTWriter(fs vfs.FS, path string) (*sstable.Writer, error) {
+	f, err := fs.Create(path)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -1383,7 +1394,7 @@ func (i dbSSTIngester) mergeSSTs(metas []*sstMeta, dir string) (*sstMeta, error)
 	}
 
 	for _, p := range metas {
-		f, err := os.Open(p.path)
+		f, err := i.e.fs.Open(p.path)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
@@ -1416,7 +1427,7 @@ func (i dbSSTIngester) mergeSSTs(metas []*sstMeta, dir string) (*sstMeta, error)
 	heap.Init(mergeIter)
 
 	name := filepath.Join(dir, fmt.Sprintf("%s.sst", uuid.New()))
-	writer, err := newSSTWriter(name)
+	writer, err := newSSTWriter(i.e.fs, name)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
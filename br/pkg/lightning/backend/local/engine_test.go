@@ -25,6 +25,7 @@ import (
 
 	"github.com/cockroachdb/pebble"
 	"github.com/cockroachdb/pebble/sstable"
+	"github.com/cockroachdb/pebble/vfs"
 	"github.com/google/uuid"
 	"github.com/pingcap/tidb/br/pkg/lightning/backend"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
@@ -53,6 +54,7 @@ func TestIngestSSTWithClosedEngine(t *testing.T) {
 		db:           db,
 		UUID:         engineUUID,
 		sstDir:       tmpPath,
+		fs:           vfs.Default,
 		ctx:          engineCtx,
 		cancel:       cancel,
 		sstMetasChan: make(chan metaOrFlush, 64),
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
 	"github.com/coreos/go-semver/semver"
 	"github.com/docker/go-units"
 	"github.com/google/uuid"
@@ -54,6 +55,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/utils"
 	"github.com/pingcap/tidb/br/pkg/version"
 	"github.com/pingcap/tidb/infoschema"
+	tidbkv "github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/table"
@@ -69,6 +71,7 @@ import (
 	"go.uber.org/atomic"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
@@ -348,6 +351,11 @@ type local struct {
 	g        glue.Glue
 
 	localStoreDir string
+	// storeDirs are the sorted-kv-dir followed by the spill directories, the engines are
+	// created in the spill directories when the quota of the previous ones is used up.
+	storeDirs []storeDir
+	// fs is the file system of the engines, which encrypts the files if the encryption is enabled.
+	fs vfs.FS
 
 	rangeConcurrency  *worker.Pool
 	ingestConcurrency *worker.Pool
@@ -360,30 +368,53 @@ type local struct {
 	engineMemCacheSize      int
 	localWriterMemCacheSize int64
 	supportMultiIngest      bool
+	ingestPriority          kvrpcpb.CommandPri
 
 	checkTiKVAvaliable  bool
 	duplicateDetection  bool
+	dupeIgnoreRules     []*config.DuplicateIgnoreRule
 	duplicateDB         *pebble.DB
 	keyAdapter          KeyAdapter
 	errorMgr            *errormanager.ErrorManager
 	importClientFactory ImportClientFactory
 
-	bufferPool   *membuf.Pool
-	metrics      *metric.Metrics
-	writeLimiter StoreWriteLimiter
-	logger       log.Logger
+	bufferPool    *membuf.Pool
+	metrics       *metric.Metrics
+	writeLimiter  StoreWriteLimiter
+	ingestLimiter IngestLimiter
+	logger        log.Logger
+	// logBackup records the ingested ranges to the log backup tasks, nil if it's disabled.
+	logBackup *logBackupRegister
 
 	encBuilder       backend.EncodingBuilder
 	targetInfoGetter backend.TargetInfoGetter
 }
 
-func openDuplicateDB(storeDir string) (*pebble.DB, error) {
+// ingestRequestSource tags the ingest requests as the internal import traffic.
+var ingestRequestSource = (&tidbkv.RequestSource{
+	RequestSourceInternal: true,
+	RequestSourceType:     tidbkv.InternalTxnLightning,
+}).GetRequestSource()
+
+func getIngestPriority(priority string) kvrpcpb.CommandPri {
+	switch priority {
+	case config.PriorityLow:
+		return kvrpcpb.CommandPri_Low
+	case config.PriorityHigh:
+		return kvrpcpb.CommandPri_High
+	default:
+		return kvrpcpb.CommandPri_Normal
+	}
+}
+
+func openDuplicateDB(storeDir string, fs vfs.FS) (*pebble.DB, error) {
 	dbPath := filepath.Join(storeDir, duplicateDBName)
 	// TODO: Optimize the opts for better write.
 	opts := &pebble.Options{
 		TablePropertyCollectors: []func() pebble.TablePropertyCollector{
 			newRangePropertiesCollector,
 		},
+		FS: fs,
 	}
 	return pebble.Open(dbPath, opts)
 }
@@ -424,9 +455,30 @@ func NewLocalBackend(
 		}
 	}
 
+	var fs vfs.FS = vfs.Default
+	if enc := &cfg.TikvImporter.Encryption; enc.Enabled() {
+		dataKey, err := loadEngineDataKey(ctx, enc, localFile)
+		if err != nil {
+			return backend.MakeBackend(nil), errors.Annotate(err, "failed to load the data key of the engine encryption")
+		}
+		encFS, err := newEncryptedFS(vfs.Default, dataKey)
+		if err != nil {
+			return backend.MakeBackend(nil), errors.Trace(err)
+		}
+		fs = encFS
+	}
+
+	storeDirs := []storeDir{{path: localFile, quota: int64(cfg.TikvImporter.DiskQuota)}}
+	for _, spillDir := range cfg.TikvImporter.SpillDirs {
+		if err := makeStoreDir(spillDir.Path, cfg.Checkpoint.Enable); err != nil {
+			return backend.MakeBackend(nil), common.ErrInvalidSortedKVDir.Wrap(err).GenWithStackByArgs(spillDir.Path)
+		}
+		storeDirs = append(storeDirs, storeDir{path: spillDir.Path, quota: int64(spillDir.DiskQuota)})
+	}
+
 	var duplicateDB *pebble.DB
 	if cfg.TikvImporter.DuplicateResolution != config.DupeResAlgNone {
-		duplicateDB, err = openDuplicateDB(localFile)
+		duplicateDB, err = openDuplicateDB(localFile, fs)
 		if err != nil {
 			return backend.MakeBackend(nil), common.ErrOpenDuplicateDB.Wrap(err).GenWithStackByArgs()
 		}
@@ -443,14 +495,31 @@ func NewLocalBackend(
 	if err != nil {
 		return backend.MakeBackend(nil), common.ErrCreateKVClient.Wrap(err).GenWithStackByArgs()
 	}
+	var ingestLimiter IngestLimiter = noopIngestLimiter{}
+	dispatchConcurrency := rangeConcurrency
+	if cfg.TikvImporter.AdaptiveConcurrency {
+		// the ranges are dispatched up to the boosted concurrency, and the ingests are
+		// limited by the feedback of TiKV. The other concurrency settings derived from
+		// the range concurrency are not boosted.
+		ingestLimiter = newAdaptiveIngestLimiter(rangeConcurrency, rangeConcurrency*2, log.FromContext(ctx))
+		dispatchConcurrency *= 2
+	}
 	importClientFactory := newImportClientFactoryImpl(splitCli, tls, rangeConcurrency)
 	duplicateDetection := cfg.TikvImporter.DuplicateResolution != config.DupeResAlgNone
 	keyAdapter := KeyAdapter(noopKeyAdapter{})
 	if duplicateDetection {
 		keyAdapter = dupDetectKeyAdapter{}
 	}
+	var logBackup *logBackupRegister
+	if cfg.TikvImporter.RegisterLogBackup {
+		logBackup, err = newLogBackupRegister(cfg.TiDB.PdAddr, tls)
+		if err != nil {
+			return backend.MakeBackend(nil), errors.Annotate(err, "failed to connect to the etcd of the log backup tasks")
+		}
+	}
 	var writeLimiter StoreWriteLimiter
-	if cfg.TikvImporter.StoreWriteBWLimit > 0 {
+	// the limit of the bandwidth may be changed by the throttle schedule at runtime.
+	if cfg.TikvImporter.StoreWriteBWLimit > 0 || len(cfg.App.ThrottleSchedule) > 0 {
 		writeLimiter = newStoreWriteLimiter(int(cfg.TikvImporter.StoreWriteBWLimit))
 	} else {
 		writeLimiter = noopStoreWriteLimiter{}
@@ -465,7 +534,9 @@ func NewLocalBackend(
 		g:        g,
 
 		localStoreDir:     localFile,
-		rangeConcurrency:  worker.NewPool(ctx, rangeConcurrency, "range"),
+		storeDirs:         storeDirs,
+		fs:                fs,
+		rangeConcurrency:  worker.NewPool(ctx, dispatchConcurrency, "range"),
 		ingestConcurrency: worker.NewPool(ctx, rangeConcurrency*2, "ingest"),
 		dupeConcurrency:   rangeConcurrency * 2,
 		batchWriteKVPairs: cfg.TikvImporter.SendKVPairs,
@@ -474,7 +545,9 @@ func NewLocalBackend(
 
 		engineMemCacheSize:      int(cfg.TikvImporter.EngineMemCacheSize),
 		localWriterMemCacheSize: int64(cfg.TikvImporter.LocalWriterMemCacheSize),
+		ingestPriority:          getIngestPriority(cfg.TikvImporter.Priority),
 		duplicateDetection:      duplicateDetection,
+		dupeIgnoreRules:         cfg.TikvImporter.DuplicateIgnore,
 		checkTiKVAvaliable:      cfg.App.CheckRequirements,
 		duplicateDB:             duplicateDB,
 		keyAdapter:              keyAdapter,
@@ -482,7 +555,9 @@ func NewLocalBackend(
 		importClientFactory:     importClientFactory,
 		bufferPool:              membuf.NewPool(membuf.WithAllocator(manual.Allocator{})),
 		writeLimiter:            writeLimiter,
+		ingestLimiter:           ingestLimiter,
 		logger:                  log.FromContext(ctx),
+		logBackup:               logBackup,
 		encBuilder:              NewEncodingBuilder(ctx),
 		targetInfoGetter:        NewTargetInfoGetter(tls, g, cfg.TiDB.PdAddr),
 	}
@@ -508,6 +583,15 @@ func (local *local) TotalMemoryConsume() int64 {
 	return memConsume + local.bufferPool.TotalSize()
 }
 
+// Throttle implements backend.Throttler.
+func (local *local) Throttle(ratio float64, bwLimit int) {
+	capacity := local.rangeConcurrency.Capacity()
+	local.rangeConcurrency.SetLimit(int(math.Ceil(ratio * float64(capacity))))
+	if limiter, ok := local.writeLimiter.(*storeWriteLimiter); ok {
+		limiter.SetLimit(bwLimit)
+	}
+}
+
 func (local *local) checkMultiIngestSupport(ctx context.Context) error {
 	stores, err := local.pdCtl.GetPDClient().GetAllStores(ctx, pd.WithExcludeTombstone())
 	if err != nil {
@@ -637,6 +721,9 @@ func (local *local) Close() {
 
 	local.importClientFactory.Close()
 	local.bufferPool.Destroy()
+	if local.logBackup != nil {
+		local.logBackup.close()
+	}
 
 	if local.duplicateDB != nil {
 		// Check if there are duplicates that are not collected.
@@ -667,10 +754,12 @@ func (local *local) Close() {
 
 	// if checkpoint is disable or we finish load all data successfully, then files in this
 	// dir will be useless, so we clean up this dir and all files in it.
-	if !local.checkpointEnabled || common.IsEmptyDir(local.localStoreDir) {
-		err := os.RemoveAll(local.localStoreDir)
-		if err != nil {
-			local.logger.Warn("remove local db file failed", zap.Error(err))
+	for _, dir := range local.storeDirs {
+		if !local.checkpointEnabled || common.IsEmptyDir(dir.path) {
+			err := os.RemoveAll(dir.path)
+			if err != nil {
+				local.logger.Warn("remove local db file failed", zap.String("dir", dir.path), zap.Error(err))
+			}
 		}
 	}
 	_ = local.tikvCli.Close()
@@ -718,7 +807,7 @@ func (local *local) ShouldPostProcess() bool {
 	return true
 }
 
-func (local *local) openEngineDB(engineUUID uuid.UUID, readOnly bool) (*pebble.DB, error) {
+func (local *local) openEngineDB(dataDir string, engineUUID uuid.UUID, readOnly bool) (*pebble.DB, error) {
 	opt := &pebble.Options{
 		MemTableSize: local.engineMemCacheSize,
 		// the default threshold value may cause write stall.
@@ -734,6 +823,7 @@ func (local *local) openEngineDB(engineUUID uuid.UUID, readOnly bool) (*pebble.D
 		TablePropertyCollectors: []func() pebble.TablePropertyCollector{
 			newRangePropertiesCollector,
 		},
+		FS: local.fs,
 	}
 	// set level target file size to avoid pebble auto triggering compaction that split ingest SST files into small SST.
 	opt.Levels = []pebble.LevelOptions{
@@ -742,7 +832,7 @@ func (local *local) openEngineDB(engineUUID uuid.UUID, readOnly bool) (*pebble.D
 		},
 	}
 
-	dbPath := filepath.Join(local.localStoreDir, engineUUID.String())
+	dbPath := filepath.Join(dataDir, engineUUID.String())
 	db, err := pebble.Open(dbPath, opt)
 	return db, errors.Trace(err)
 }
@@ -753,12 +843,13 @@ func (local *local) OpenEngine(ctx context.Context, cfg *backend.EngineConfig, e
 	if cfg.Local != nil {
 		engineCfg = *cfg.Local
 	}
-	db, err := local.openEngineDB(engineUUID, false)
+	dataDir := local.engineStoreDir(engineUUID)
+	db, err := local.openEngineDB(dataDir, engineUUID, false)
 	if err != nil {
 		return err
 	}
 
-	sstDir := engineSSTDir(local.localStoreDir, engineUUID)
+	sstDir := engineSSTDir(dataDir, engineUUID)
 	if err := os.RemoveAll(sstDir); err != nil {
 		return errors.Trace(err)
 	}
@@ -772,6 +863,8 @@ func (local *local) OpenEngine(ctx context.Context, cfg *backend.EngineConfig, e
 	e, _ := local.engines.LoadOrStore(engineUUID, &Engine{
 		UUID:               engineUUID,
 		sstDir:             sstDir,
+		dataDir:            dataDir,
+		fs:                 local.fs,
 		sstMetasChan:       make(chan metaOrFlush, 64),
 		ctx:                engineCtx,
 		cancel:             cancel,
@@ -817,13 +910,16 @@ func (local *local) CloseEngine(ctx context.Context, cfg *backend.EngineConfig,
 	engineI, ok := local.engines.Load(engineUUID)
 	if !ok {
 		// recovery mode, we should reopen this engine file
-		db, err := local.openEngineDB(engineUUID, true)
+		dataDir := local.engineStoreDir(engineUUID)
+		db, err := local.openEngineDB(dataDir, engineUUID, true)
 		if err != nil {
 			return err
 		}
 		engine := &Engine{
 			UUID:               engineUUID,
 			db:                 db,
+			dataDir:            dataDir,
+			fs:                 local.fs,
 			sstMetasChan:       make(chan metaOrFlush),
 			tableInfo:          cfg.TableInfo,
 			keyAdapter:         local.keyAdapter,
@@ -1111,9 +1207,11 @@ func (local *local) Ingest(ctx context.Context, metas []*sst.SSTMeta, region *sp
 		return nil, err
 	}
 	reqCtx := &kvrpcpb.Context{
-		RegionId:    region.Region.GetId(),
-		RegionEpoch: region.Region.GetRegionEpoch(),
-		Peer:        leader,
+		RegionId:      region.Region.GetId(),
+		RegionEpoch:   region.Region.GetRegionEpoch(),
+		Peer:          leader,
+		Priority:      local.ingestPriority,
+		RequestSource: ingestRequestSource,
 	}
 
 	if !local.supportMultiIngest {
@@ -1142,7 +1240,7 @@ func splitRangeBySizeProps(fullRange Range, sizeProps *sizeProperties, sizeLimit
 	curKeys := uint64(0)
 	curKey := fullRange.start
 
-	sizeProps.iter(func(p *rangeProperty) bool {
+	sizeProps.iterFrom(fullRange.start, func(p *rangeProperty) bool {
 		if bytes.Compare(p.Key, curKey) <= 0 {
 			return true
 		}
@@ -1197,13 +1295,19 @@ func (local *local) readAndSplitIntoRange(ctx context.Context, engine *Engine, r
 	}
 	endKey := nextKey(lastKey)
 
+	// the ranges of a partitioned table never cross the partitions, so that they
+	// align with the regions of the partitions.
+	partRanges, err := splitRangeByPartitions(ctx, engine, Range{start: firstKey, end: endKey})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	engineFileTotalSize := engine.TotalSize.Load()
 	engineFileLength := engine.Length.Load()
 
 	// <= 96MB no need to split into range
 	if engineFileTotalSize <= regionSplitSize && engineFileLength <= regionSplitKeys {
-		ranges := []Range{{start: firstKey, end: endKey}}
-		return ranges, nil
+		return partRanges, nil
 	}
 
 	logger := log.FromContext(ctx).With(zap.Stringer("engine", engine.UUID))
@@ -1212,17 +1316,64 @@ func (local *local) readAndSplitIntoRange(ctx context.Context, engine *Engine, r
 		return nil, errors.Trace(err)
 	}
 
-	ranges := splitRangeBySizeProps(Range{start: firstKey, end: endKey}, sizeProps,
-		regionSplitSize, regionSplitKeys)
+	ranges := make([]Range, 0, len(partRanges))
+	for _, partRange := range partRanges {
+		ranges = append(ranges, splitRangeBySizeProps(partRange, sizeProps, regionSplitSize, regionSplitKeys)...)
+	}
 
 	logger.Info("split engine key ranges",
 		zap.Int64("totalSize", engineFileTotalSize), zap.Int64("totalCount", engineFileLength),
 		logutil.Key("firstKey", firstKey), logutil.Key("lastKey", lastKey),
-		zap.Int("ranges", len(ranges)))
+		zap.Int("partitions", len(partRanges)), zap.Int("ranges", len(ranges)))
 
 	return ranges, nil
 }
 
+// splitRangeByPartitions splits the range at the key boundaries of the partitions
+// if the table of the engine is partitioned, and drops the sub ranges which don't
+// contain any KV pairs in the engine.
+func splitRangeByPartitions(ctx context.Context, engine *Engine, fullRange Range) ([]Range, error) {
+	if engine.tableInfo == nil || engine.tableInfo.Core == nil || engine.tableInfo.Core.Partition == nil {
+		return []Range{fullRange}, nil
+	}
+	defs := engine.tableInfo.Core.Partition.Definitions
+	boundaries := make([][]byte, 0, 2*len(defs))
+	for _, def := range defs {
+		boundaries = append(boundaries, tablecodec.EncodeTablePrefix(def.ID), tablecodec.EncodeTablePrefix(def.ID+1))
+	}
+	slices.SortFunc(boundaries, func(i, j []byte) bool {
+		return bytes.Compare(i, j) < 0
+	})
+
+	ranges := make([]Range, 0, len(defs))
+	appendIfNotEmpty := func(r Range) error {
+		iter := engine.newKVIter(ctx, &pebble.IterOptions{LowerBound: r.start, UpperBound: r.end})
+		//nolint: errcheck
+		defer iter.Close()
+		if iter.First() {
+			ranges = append(ranges, r)
+		}
+		return iter.Error()
+	}
+	start := fullRange.start
+	for _, boundary := range boundaries {
+		if bytes.Compare(boundary, start) <= 0 {
+			continue
+		}
+		if bytes.Compare(boundary, fullRange.end) >= 0 {
+			break
+		}
+		if err := appendIfNotEmpty(Range{start: start, end: boundary}); err != nil {
+			return nil, errors.Trace(err)
+		}
+		start = boundary
+	}
+	if err := appendIfNotEmpty(Range{start: start, end: fullRange.end}); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return ranges, nil
+}
+
 func (local *local) writeAndIngestByRange(
 	ctxt context.Context,
 	engine *Engine,
@@ -1288,7 +1439,12 @@ WriteAndIngest:
 				zap.Binary("end", region.Region.GetEndKey()), zap.Reflect("peers", region.Region.GetPeers()))
 
 			w := local.ingestConcurrency.Apply()
+			if err = local.ingestLimiter.Acquire(ctx); err != nil {
+				local.ingestConcurrency.Recycle(w)
+				return err
+			}
 			err = local.writeAndIngestPairs(ctx, engine, region, pairStart, end, regionSplitSize, regionSplitKeys)
+			local.ingestLimiter.Release(err)
 			local.ingestConcurrency.Recycle(w)
 			if err != nil {
 				if !local.isRetryableImportTiKVError(err) {
@@ -1560,6 +1716,12 @@ func (local *local) ImportEngine(ctx context.Context, engineUUID uuid.UUID, regi
 		return err
 	}
 
+	if local.logBackup != nil {
+		if err := local.logBackup.registerIngest(ctx, engineUUID, lf.TS, ranges); err != nil {
+			return errors.Annotate(err, "failed to register the ingest to the log backup tasks")
+		}
+	}
+
 	if len(ranges) > 0 && local.pdCtl.CanPauseSchedulerByKeyRange() {
 		subCtx, cancel := context.WithCancel(ctx)
 		defer cancel()
@@ -1633,7 +1795,7 @@ func (local *local) CollectLocalDuplicateRows(ctx context.Context, tbl table.Tab
 
 	atomicHasDupe := atomic.NewBool(false)
 	duplicateManager, err := NewDuplicateManager(tbl, tableName, local.splitCli, local.tikvCli,
-		local.errorMgr, opts, local.dupeConcurrency, atomicHasDupe, log.FromContext(ctx))
+		local.errorMgr, opts, local.dupeConcurrency, atomicHasDupe, local.dupeIgnoreRules, log.FromContext(ctx))
 	if err != nil {
 		return false, errors.Trace(err)
 	}
@@ -1651,7 +1813,7 @@ func (local *local) CollectRemoteDuplicateRows(ctx context.Context, tbl table.Ta
 
 	atomicHasDupe := atomic.NewBool(false)
 	duplicateManager, err := NewDuplicateManager(tbl, tableName, local.splitCli, local.tikvCli,
-		local.errorMgr, opts, local.dupeConcurrency, atomicHasDupe, log.FromContext(ctx))
+		local.errorMgr, opts, local.dupeConcurrency, atomicHasDupe, local.dupeIgnoreRules, log.FromContext(ctx))
 	if err != nil {
 		return false, errors.Trace(err)
 	}
@@ -1772,10 +1934,10 @@ func (local *local) ResetEngine(ctx context.Context, engineUUID uuid.UUID) error
 	if err := localEngine.Close(); err != nil {
 		return err
 	}
-	if err := localEngine.Cleanup(local.localStoreDir); err != nil {
+	if err := localEngine.Cleanup(localEngine.dataDir); err != nil {
 		return err
 	}
-	db, err := local.openEngineDB(engineUUID, false)
+	db, err := local.openEngineDB(localEngine.dataDir, engineUUID, false)
 	if err == nil {
 		localEngine.db = db
 		localEngine.engineMeta = engineMeta{}
@@ -1812,7 +1974,7 @@ func (local *local) CleanupEngine(ctx context.Context, engineUUID uuid.UUID) err
 	if err != nil {
 		return err
 	}
-	err = localEngine.Cleanup(local.localStoreDir)
+	err = localEngine.Cleanup(localEngine.dataDir)
 	if err != nil {
 		return err
 	}
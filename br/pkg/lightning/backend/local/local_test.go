@@ -30,6 +30,7 @@ import (
 	"testing"
 
 	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
 	"github.com/coreos/go-semver/semver"
 	"github.com/docker/go-units"
 	"github.com/golang/mock/gomock"
@@ -38,11 +39,14 @@ import (
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/errorpb"
 	sst "github.com/pingcap/kvproto/pkg/import_sstpb"
+	"github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	berrors "github.com/pingcap/tidb/br/pkg/errors"
 	"github.com/pingcap/tidb/br/pkg/lightning/backend"
 	"github.com/pingcap/tidb/br/pkg/lightning/backend/kv"
+	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
 	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
 	"github.com/pingcap/tidb/br/pkg/membuf"
@@ -51,6 +55,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/restore/split"
 	"github.com/pingcap/tidb/br/pkg/utils"
 	tidbkv "github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/types"
@@ -330,6 +335,7 @@ func testLocalWriter(t *testing.T, needSort bool, partitialSort bool) {
 		db:           db,
 		UUID:         engineUUID,
 		sstDir:       tmpPath,
+		fs:           vfs.Default,
 		ctx:          engineCtx,
 		cancel:       cancel,
 		sstMetasChan: make(chan metaOrFlush, 64),
@@ -413,6 +419,42 @@ func testLocalWriter(t *testing.T, needSort bool, partitialSort bool) {
 	f.wg.Wait()
 }
 
+func TestSplitRangeByPartitions(t *testing.T) {
+	db, err := pebble.Open(filepath.Join(t.TempDir(), "test"), &pebble.Options{})
+	require.NoError(t, err)
+	defer db.Close()
+
+	// partition 102 is empty.
+	for _, pid := range []int64{101, 103} {
+		for i := 0; i < 10; i++ {
+			key := tablecodec.EncodeRowKeyWithHandle(pid, tidbkv.IntHandle(i))
+			require.NoError(t, db.Set(key, []byte("value"), pebble.NoSync))
+		}
+	}
+	firstKey := tablecodec.EncodeRowKeyWithHandle(101, tidbkv.IntHandle(0))
+	endKey := nextKey(tablecodec.EncodeRowKeyWithHandle(103, tidbkv.IntHandle(9)))
+	fullRange := Range{start: firstKey, end: endKey}
+
+	f := &Engine{
+		db:        db,
+		tableInfo: &checkpoints.TidbTableInfo{Core: &model.TableInfo{ID: 100}},
+		logger:    log.L(),
+	}
+	ranges, err := splitRangeByPartitions(context.Background(), f, fullRange)
+	require.NoError(t, err)
+	require.Equal(t, []Range{fullRange}, ranges)
+
+	f.tableInfo.Core.Partition = &model.PartitionInfo{
+		Definitions: []model.PartitionDefinition{{ID: 103}, {ID: 101}, {ID: 102}},
+	}
+	ranges, err = splitRangeByPartitions(context.Background(), f, fullRange)
+	require.NoError(t, err)
+	require.Equal(t, []Range{
+		{start: firstKey, end: tablecodec.EncodeTablePrefix(102)},
+		{start: tablecodec.EncodeTablePrefix(103), end: endKey},
+	}, ranges)
+}
+
 func TestLocalWriterWithSort(t *testing.T) {
 	testLocalWriter(t, false, false)
 }
@@ -439,6 +481,14 @@ func (c *mockSplitClient) GetRegion(ctx context.Context, key []byte) (*split.Reg
 	}, nil
 }
 
+func TestGetIngestPriority(t *testing.T) {
+	require.Equal(t, kvrpcpb.CommandPri_Low, getIngestPriority(config.PriorityLow))
+	require.Equal(t, kvrpcpb.CommandPri_Normal, getIngestPriority(config.PriorityNormal))
+	require.Equal(t, kvrpcpb.CommandPri_High, getIngestPriority(config.PriorityHigh))
+	require.Equal(t, kvrpcpb.CommandPri_Normal, getIngestPriority(""))
+	require.Equal(t, "internal_lightning", ingestRequestSource)
+}
+
 func TestIsIngestRetryable(t *testing.T) {
 	local := &local{
 		splitCli: &mockSplitClient{},
@@ -807,6 +857,7 @@ func testMergeSSTs(t *testing.T, kvs [][]common.KvPair, meta *sstMeta) {
 		db:           db,
 		UUID:         engineUUID,
 		sstDir:       tmpPath,
+		fs:           vfs.Default,
 		ctx:          engineCtx,
 		cancel:       cancel,
 		sstMetasChan: make(chan metaOrFlush, 64),
@@ -820,7 +871,7 @@ func testMergeSSTs(t *testing.T, kvs [][]common.KvPair, meta *sstMeta) {
 
 	createSSTWriter := func() (*sstWriter, error) {
 		path := filepath.Join(f.sstDir, uuid.New().String()+".sst")
-		writer, err := newSSTWriter(path)
+		writer, err := newSSTWriter(vfs.Default, path)
 		if err != nil {
 			return nil, err
 		}
@@ -1273,3 +1324,38 @@ func TestLocalIsRetryableTiKVWriteError(t *testing.T) {
 	require.True(t, l.isRetryableImportTiKVError(io.EOF))
 	require.True(t, l.isRetryableImportTiKVError(errors.Trace(io.EOF)))
 }
+
+func TestEngineStoreDir(t *testing.T) {
+	dirs := []string{t.TempDir(), t.TempDir(), t.TempDir()}
+	l := &local{
+		localStoreDir: dirs[0],
+		storeDirs: []storeDir{
+			{path: dirs[0], quota: 100},
+			{path: dirs[1], quota: 50},
+			{path: dirs[2], quota: 10},
+		},
+		logger: log.L(),
+	}
+	addEngine := func(dir string, size int64) {
+		e := &Engine{UUID: uuid.New(), dataDir: dir}
+		e.pendingFileSize.Store(size)
+		l.engines.Store(e.UUID, e)
+	}
+
+	require.Equal(t, dirs[0], l.engineStoreDir(uuid.New()))
+	addEngine(dirs[0], 100)
+	// spill to the next directory when the quota of the sorted-kv-dir is used up.
+	require.Equal(t, dirs[1], l.engineStoreDir(uuid.New()))
+	addEngine(dirs[1], 40)
+	require.Equal(t, dirs[1], l.engineStoreDir(uuid.New()))
+	addEngine(dirs[1], 20)
+	require.Equal(t, dirs[2], l.engineStoreDir(uuid.New()))
+	// all directories are full, choose the one with the least excess.
+	addEngine(dirs[2], 30)
+	require.Equal(t, dirs[0], l.engineStoreDir(uuid.New()))
+
+	// the engine is resumed from the directory containing it.
+	engineUUID := uuid.New()
+	require.NoError(t, os.Mkdir(filepath.Join(dirs[2], engineUUID.String()), 0o700))
+	require.Equal(t, dirs[2], l.engineStoreDir(engineUUID))
+}
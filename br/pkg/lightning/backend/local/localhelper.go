@@ -30,6 +30,7 @@ import (
 	sst "github.com/pingcap/kvproto/pkg/import_sstpb"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
 	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
 	"github.com/pingcap/tidb/br/pkg/lightning/common"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
@@ -59,6 +60,8 @@ var (
 	splitRegionBaseBackOffTime = time.Second
 	// the max retry times to split regions.
 	splitRetryTimes = 8
+	// the minimum interval between two decreases of the adaptive ingest concurrency.
+	ingestLimitDecreaseInterval = 5 * time.Second
 )
 
 // SplitAndScatterRegionInBatches splits&scatter regions in batches.
@@ -607,14 +610,7 @@ type storeWriteLimiter struct {
 }
 
 func newStoreWriteLimiter(limit int) *storeWriteLimiter {
-	var burst int
-	// Allow burst of at most 20% of the limit.
-	if limit <= math.MaxInt-limit/5 {
-		burst = limit + limit/5
-	} else {
-		// If overflowed, set burst to math.MaxInt.
-		burst = math.MaxInt
-	}
+	limit, burst := storeWriteLimitAndBurst(limit)
 	return &storeWriteLimiter{
 		limiters: make(map[uint64]*rate.Limiter),
 		limit:    limit,
@@ -622,6 +618,19 @@ func newStoreWriteLimiter(limit int) *storeWriteLimiter {
 	}
 }
 
+// storeWriteLimitAndBurst returns the limit and burst of the rate limiters, limit <= 0 means unlimited.
+func storeWriteLimitAndBurst(limit int) (int, int) {
+	if limit <= 0 {
+		return math.MaxInt, math.MaxInt
+	}
+	// Allow burst of at most 20% of the limit.
+	if limit <= math.MaxInt-limit/5 {
+		return limit, limit + limit/5
+	}
+	// If overflowed, set burst to math.MaxInt.
+	return limit, math.MaxInt
+}
+
 func (s *storeWriteLimiter) WaitN(ctx context.Context, storeID uint64, n int) error {
 	limiter := s.getLimiter(storeID)
 	// The original WaitN doesn't allow n > burst,
@@ -636,9 +645,24 @@ func (s *storeWriteLimiter) WaitN(ctx context.Context, storeID uint64, n int) er
 }
 
 func (s *storeWriteLimiter) Limit() int {
+	s.rwm.RLock()
+	defer s.rwm.RUnlock()
 	return s.limit
 }
 
+// SetLimit changes the limit of all stores at runtime, limit <= 0 means unlimited.
+func (s *storeWriteLimiter) SetLimit(limit int) {
+	limit, burst := storeWriteLimitAndBurst(limit)
+	s.rwm.Lock()
+	defer s.rwm.Unlock()
+	s.limit = limit
+	s.burst = burst
+	for _, limiter := range s.limiters {
+		limiter.SetLimit(rate.Limit(limit))
+		limiter.SetBurst(burst)
+	}
+}
+
 func (s *storeWriteLimiter) getLimiter(storeID uint64) *rate.Limiter {
 	s.rwm.RLock()
 	limiter, ok := s.limiters[storeID]
@@ -665,3 +689,103 @@ func (noopStoreWriteLimiter) WaitN(ctx context.Context, storeID uint64, n int) e
 func (noopStoreWriteLimiter) Limit() int {
 	return math.MaxInt
 }
+
+// IngestLimiter limits the concurrency of writing and ingesting the regions.
+type IngestLimiter interface {
+	// Acquire blocks until a region can be written and ingested.
+	Acquire(ctx context.Context) error
+	// Release is called after a region is written and ingested with the result, which
+	// is the feedback of TiKV to adjust the concurrency.
+	Release(err error)
+	Limit() int
+}
+
+// adaptiveIngestLimiter adjusts the concurrency of writing and ingesting the regions
+// in the way of AIMD by the feedback of TiKV. The limit is halved if TiKV is busy,
+// e.g. the store encounters write stall or has too many SST files in L0, and it's
+// increased by one after the number of the limit of regions are ingested successfully
+// in a row.
+type adaptiveIngestLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	max     int
+	running int
+	// successes is the number of the successful ingests in a row.
+	successes    int
+	lastDecrease time.Time
+	// changed is closed when a slot is released or the limit is changed.
+	changed chan struct{}
+	logger  log.Logger
+}
+
+func newAdaptiveIngestLimiter(initial, max int, logger log.Logger) *adaptiveIngestLimiter {
+	return &adaptiveIngestLimiter{
+		limit:   initial,
+		max:     max,
+		changed: make(chan struct{}),
+		logger:  logger,
+	}
+}
+
+func (l *adaptiveIngestLimiter) Acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.running < l.limit {
+			l.running++
+			l.mu.Unlock()
+			return nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (l *adaptiveIngestLimiter) Release(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running--
+	switch {
+	case err == nil:
+		l.successes++
+		if l.successes >= l.limit && l.limit < l.max {
+			l.limit++
+			l.successes = 0
+			l.logger.Info("increase ingest concurrency", zap.Int("limit", l.limit))
+		}
+	case berrors.Is(err, common.ErrKVServerIsBusy):
+		l.successes = 0
+		// the concurrent ingests usually fail together when TiKV is busy, only
+		// decrease once for them.
+		if l.limit > 1 && time.Since(l.lastDecrease) >= ingestLimitDecreaseInterval {
+			l.limit /= 2
+			l.lastDecrease = time.Now()
+			l.logger.Warn("decrease ingest concurrency since TiKV is busy",
+				zap.Int("limit", l.limit), log.ShortError(err))
+		}
+	}
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+func (l *adaptiveIngestLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+type noopIngestLimiter struct{}
+
+func (noopIngestLimiter) Acquire(ctx context.Context) error {
+	return nil
+}
+
+func (noopIngestLimiter) Release(err error) {}
+
+func (noopIngestLimiter) Limit() int {
+	return math.MaxInt
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/errors"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
 	"github.com/pingcap/tidb/br/pkg/lightning/glue"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/br/pkg/restore/split"
@@ -815,3 +816,86 @@ func TestStoreWriteLimiter(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestAdaptiveIngestLimiter(t *testing.T) {
+	ctx := context.Background()
+	limiter := newAdaptiveIngestLimiter(4, 6, log.L())
+	busyErr := common.ErrKVServerIsBusy.GenWithStack("too many sst files are ingesting")
+
+	// the limit is increased after the number of the limit of successful ingests in a row.
+	for i := 0; i < 4; i++ {
+		require.NoError(t, limiter.Acquire(ctx))
+	}
+	for i := 0; i < 3; i++ {
+		limiter.Release(nil)
+	}
+	require.Equal(t, 4, limiter.Limit())
+	limiter.Release(nil)
+	require.Equal(t, 5, limiter.Limit())
+	for i := 0; i < 20; i++ {
+		require.NoError(t, limiter.Acquire(ctx))
+		limiter.Release(nil)
+	}
+	require.Equal(t, 6, limiter.Limit())
+
+	// the limit is halved when TiKV is busy, but only once for the concurrent failures.
+	require.NoError(t, limiter.Acquire(ctx))
+	require.NoError(t, limiter.Acquire(ctx))
+	limiter.Release(busyErr)
+	limiter.Release(busyErr)
+	require.Equal(t, 3, limiter.Limit())
+	// other errors are not the feedback of the pressure.
+	require.NoError(t, limiter.Acquire(ctx))
+	limiter.Release(errors.New("epoch not match"))
+	require.Equal(t, 3, limiter.Limit())
+
+	limiter.lastDecrease = time.Time{}
+	require.NoError(t, limiter.Acquire(ctx))
+	limiter.Release(busyErr)
+	require.Equal(t, 1, limiter.Limit())
+	limiter.lastDecrease = time.Time{}
+	require.NoError(t, limiter.Acquire(ctx))
+	limiter.Release(busyErr)
+	require.Equal(t, 1, limiter.Limit())
+
+	// the acquire is blocked until a slot is released.
+	require.NoError(t, limiter.Acquire(ctx))
+	acquired := make(chan struct{})
+	go func() {
+		require.NoError(t, limiter.Acquire(ctx))
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		require.Fail(t, "the acquire should be blocked")
+	case <-time.After(100 * time.Millisecond):
+	}
+	limiter.Release(nil)
+	<-acquired
+	limiter.Release(nil)
+
+	// the acquire can be canceled.
+	require.Equal(t, 2, limiter.Limit())
+	require.NoError(t, limiter.Acquire(ctx))
+	require.NoError(t, limiter.Acquire(ctx))
+	cancelCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, limiter.Acquire(cancelCtx), context.DeadlineExceeded)
+}
+
+func TestStoreWriteLimiterSetLimit(t *testing.T) {
+	limiter := newStoreWriteLimiter(0)
+	require.Equal(t, math.MaxInt, limiter.Limit())
+	require.NoError(t, limiter.WaitN(context.Background(), 1, 1<<30))
+
+	limiter.SetLimit(100)
+	require.Equal(t, 100, limiter.Limit())
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	// the existing limiter of store 1 is limited too
+	require.Error(t, limiter.WaitN(ctx, 1, 120+120))
+
+	limiter.SetLimit(0)
+	require.Equal(t, math.MaxInt, limiter.Limit())
+	require.NoError(t, limiter.WaitN(context.Background(), 1, 1<<30))
+}
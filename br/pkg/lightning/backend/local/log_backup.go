@@ -0,0 +1,110 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/logutil"
+	"github.com/pingcap/tidb/br/pkg/streamhelper"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+const logBackupDialTimeout = 5 * time.Second
+
+// logBackupRegister records the ranges ingested by the local backend to the log backup
+// tasks covering them. The ingested SST files bypass the raft log, so they aren't captured
+// by the log backup, and the records tell that a task needs a new full backup to be
+// restored to a point after the import.
+type logBackupRegister struct {
+	cli *streamhelper.MetaDataClient
+}
+
+// newLogBackupRegister connects to the etcd embedded in PD, which stores the log backup tasks.
+func newLogBackupRegister(pdAddr string, tls *common.TLS) (*logBackupRegister, error) {
+	cli, err := clientv3.New(clientv3.Config{
+		Endpoints:   strings.Split(pdAddr, ","),
+		TLS:         tls.TLSConfig(),
+		DialTimeout: logBackupDialTimeout,
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &logBackupRegister{cli: streamhelper.NewMetaDataClient(cli)}, nil
+}
+
+// registerIngest records the engine to the log backup tasks whose ranges overlap the ranges
+// of the engine. It's called before the engine is ingested, so an interrupted import is
+// recorded as well.
+func (r *logBackupRegister) registerIngest(ctx context.Context, engineUUID uuid.UUID, commitTS uint64, ranges []Range) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+	record := streamhelper.IngestRecord{
+		ID:       engineUUID.String(),
+		CommitTS: commitTS,
+		StartKey: ranges[0].start,
+		EndKey:   ranges[len(ranges)-1].end,
+	}
+	tasks, err := r.cli.GetAllTasks(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for i := range tasks {
+		task := &tasks[i]
+		if task.Info.EndTs > 0 && task.Info.EndTs < commitTS {
+			continue
+		}
+		taskRanges, err := task.Ranges(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !overlapsAnyRange(record.StartKey, record.EndKey, taskRanges) {
+			continue
+		}
+		if err := r.cli.PutIngestRecord(ctx, task.Info.Name, record); err != nil {
+			return errors.Trace(err)
+		}
+		log.FromContext(ctx).Warn("the ingested engine isn't captured by the log backup, "+
+			"a new full backup is needed to restore the task to a point after the import",
+			zap.String("task", task.Info.Name), zap.Stringer("engine", engineUUID), zap.Uint64("commitTS", commitTS),
+			logutil.Key("startKey", record.StartKey), logutil.Key("endKey", record.EndKey))
+	}
+	return nil
+}
+
+func (r *logBackupRegister) close() {
+	_ = r.cli.Close()
+}
+
+// overlapsAnyRange checks whether [startKey, endKey) overlaps any of the ranges. An empty
+// end key means the range is unbounded.
+func overlapsAnyRange(startKey, endKey []byte, ranges streamhelper.Ranges) bool {
+	for _, rg := range ranges {
+		if (len(rg.EndKey) == 0 || bytes.Compare(startKey, rg.EndKey) < 0) &&
+			(len(endKey) == 0 || bytes.Compare(rg.StartKey, endKey) < 0) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb/br/pkg/streamhelper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlapsAnyRange(t *testing.T) {
+	ranges := streamhelper.Ranges{
+		{StartKey: []byte("b"), EndKey: []byte("d")},
+		{StartKey: []byte("f"), EndKey: []byte("h")},
+	}
+	cases := []struct {
+		start, end string
+		overlaps   bool
+	}{
+		{"a", "b", false},
+		{"a", "c", true},
+		{"c", "e", true},
+		{"d", "f", false},
+		{"d", "g", true},
+		{"h", "z", false},
+		{"h", "", false},
+		{"g", "", true},
+		{"", "b", false},
+		{"", "bb", true},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.overlaps, overlapsAnyRange([]byte(c.start), []byte(c.end), ranges), "[%s, %s)", c.start, c.end)
+	}
+
+	unbounded := streamhelper.Ranges{{StartKey: []byte("m")}}
+	require.True(t, overlapsAnyRange([]byte("x"), []byte("y"), unbounded))
+	require.False(t, overlapsAnyRange([]byte("a"), []byte("m"), unbounded))
+}
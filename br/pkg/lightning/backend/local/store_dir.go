@@ -0,0 +1,96 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package local
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"go.uber.org/zap"
+)
+
+// storeDir is a directory storing the sorted KV files of the engines, along with
+// its disk quota.
+type storeDir struct {
+	path  string
+	quota int64
+}
+
+// makeStoreDir creates the store directory. The existing directory is reused
+// if the checkpoint is enabled, since the engines are resumed from it.
+func makeStoreDir(path string, checkpointEnabled bool) error {
+	if checkpointEnabled {
+		info, err := os.Stat(path)
+		if err == nil && info.IsDir() {
+			return nil
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(os.Mkdir(path, 0o700))
+}
+
+// engineStoreDir returns the store directory of the engine. The directory which
+// already contains the engine is used to resume it, otherwise it's the first one
+// whose disk quota is not used up, so the engines spill over to the next
+// directories only when the previous ones are full. If all directories are full,
+// the one with the least excess is used, and the disk quota checker of the
+// restore imports the large engines to free the space.
+func (local *local) engineStoreDir(engineUUID uuid.UUID) string {
+	if len(local.storeDirs) <= 1 {
+		return local.localStoreDir
+	}
+	for _, dir := range local.storeDirs {
+		if common.IsDirExists(filepath.Join(dir.path, engineUUID.String())) {
+			return dir.path
+		}
+	}
+
+	usages := local.storeDirUsages()
+	chosen := local.storeDirs[0].path
+	maxLeft := int64(math.MinInt64)
+	for _, dir := range local.storeDirs {
+		left := dir.quota - usages[dir.path]
+		if left > 0 {
+			chosen = dir.path
+			break
+		}
+		if left > maxLeft {
+			chosen, maxLeft = dir.path, left
+		}
+	}
+	local.logger.Info("choose the store dir of engine",
+		zap.Stringer("engine", engineUUID),
+		zap.String("dir", chosen),
+		zap.Any("usages", usages))
+	return chosen
+}
+
+// storeDirUsages returns the local size of the engines in each store directory.
+func (local *local) storeDirUsages() map[string]int64 {
+	usages := make(map[string]int64, len(local.storeDirs))
+	local.engines.Range(func(_, v interface{}) bool {
+		engine := v.(*Engine)
+		size := engine.getEngineFileSize()
+		usages[engine.dataDir] += size.DiskSize + size.MemSize
+		return true
+	})
+	return usages
+}
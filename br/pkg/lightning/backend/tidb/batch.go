@@ -0,0 +1,129 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidb
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	tmysql "github.com/pingcap/tidb/errno"
+	"go.uber.org/zap"
+)
+
+const (
+	// the initial number of rows in an INSERT statement.
+	defaultBatchRows = 1024
+	// the bounds of the number of rows in an INSERT statement.
+	minBatchRows = 1
+	maxBatchRows = 65536
+	// the initial max size of an INSERT statement.
+	defaultBatchBytes = 1024 * 1024
+	// the max size of an INSERT statement is never decreased below it.
+	minBatchBytes = 4096
+	// the batch grows if the INSERT statement is faster than half of it, and
+	// shrinks if it's slower than twice of it.
+	batchTargetLatency = 200 * time.Millisecond
+)
+
+// batchSizer adjusts the number of rows in an INSERT statement by the observed
+// statement latency. The rows are doubled if a full batch is inserted fast, and
+// halved if a batch is inserted slowly. The max size of the statement is halved
+// when the statement exceeds the max_allowed_packet of the server, it's never
+// increased since the server limit is fixed.
+type batchSizer struct {
+	mu       sync.Mutex
+	rows     int
+	maxBytes int
+}
+
+func newBatchSizer() *batchSizer {
+	return &batchSizer{
+		rows:     defaultBatchRows,
+		maxBytes: defaultBatchBytes,
+	}
+}
+
+// next returns the rows to be inserted by the next statement from the head of rows,
+// the size of the rows doesn't exceed sizeLimit unless there is only one row.
+func (b *batchSizer) next(rows tidbRows, sizeLimit int) tidbRows {
+	b.mu.Lock()
+	maxRows, maxBytes := b.rows, b.maxBytes
+	b.mu.Unlock()
+	if sizeLimit < maxBytes {
+		maxBytes = sizeLimit
+	}
+
+	size := 0
+	for i, row := range rows {
+		if i >= maxRows || (i > 0 && size+len(row.insertStmt) > maxBytes) {
+			return rows[:i]
+		}
+		size += len(row.insertStmt)
+	}
+	return rows
+}
+
+// observe adjusts the batch by the latency of the statement that inserted the rows
+// successfully.
+func (b *batchSizer) observe(logger log.Logger, rows int, latency time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case latency < batchTargetLatency/2 && rows >= b.rows && b.rows < maxBatchRows:
+		b.rows *= 2
+		if b.rows > maxBatchRows {
+			b.rows = maxBatchRows
+		}
+		logger.Debug("increase rows per INSERT statement", zap.Int("rows", b.rows), zap.Duration("latency", latency))
+	case latency > batchTargetLatency*2 && b.rows > minBatchRows:
+		b.rows /= 2
+		logger.Debug("decrease rows per INSERT statement", zap.Int("rows", b.rows), zap.Duration("latency", latency))
+	}
+}
+
+// packetTooLarge shrinks the batch since the statement of the rows exceeds the
+// max_allowed_packet of the server.
+func (b *batchSizer) packetTooLarge(logger log.Logger, rows int, stmtSize int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if stmtSize/2 < b.maxBytes {
+		b.maxBytes = stmtSize / 2
+		if b.maxBytes < minBatchBytes {
+			b.maxBytes = minBatchBytes
+		}
+	}
+	if rows/2 < b.rows {
+		b.rows = rows / 2
+		if b.rows < minBatchRows {
+			b.rows = minBatchRows
+		}
+	}
+	logger.Warn("decrease the size of INSERT statement since it exceeds max_allowed_packet",
+		zap.Int("rows", b.rows), zap.Int("maxBytes", b.maxBytes))
+}
+
+func isPacketTooLargeError(err error) bool {
+	err = errors.Cause(err)
+	if err == mysql.ErrPktTooLarge {
+		return true
+	}
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+		return mysqlErr.Number == tmysql.ErrNetPacketTooLarge
+	}
+	return false
+}
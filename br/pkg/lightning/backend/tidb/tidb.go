@@ -32,6 +32,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/pingcap/tidb/br/pkg/lightning/errormanager"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
 	"github.com/pingcap/tidb/br/pkg/lightning/verification"
 	"github.com/pingcap/tidb/br/pkg/redact"
 	"github.com/pingcap/tidb/br/pkg/utils"
@@ -59,6 +60,9 @@ type tidbRow struct {
 	insertStmt string
 	path       string
 	offset     int64
+	// modifier is the modifier of the INSERT statement in the SQL file, it overrides
+	// the configured action on duplicate if it's not nil.
+	modifier *mydump.InsertModifier
 }
 
 var emptyTiDBRow = tidbRow{
@@ -69,6 +73,35 @@ var emptyTiDBRow = tidbRow{
 
 type tidbRows []tidbRow
 
+// WithInsertModifier returns the row encoded by the TiDB backend that is inserted by the
+// modifier of its INSERT statement instead of the configured action on duplicate.
+func WithInsertModifier(row kv.Row, modifier *mydump.InsertModifier) kv.Row {
+	r, ok := row.(tidbRow)
+	if !ok {
+		return row
+	}
+	r.modifier = modifier
+	return r
+}
+
+func sameModifier(a, b *mydump.InsertModifier) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// sameModifierPrefix returns the number of the rows at the head of rows which are
+// inserted by the same modifier, so they can be inserted by one statement.
+func (rows tidbRows) sameModifierPrefix() int {
+	for i := 1; i < len(rows); i++ {
+		if !sameModifier(rows[0].modifier, rows[i].modifier) {
+			return i
+		}
+	}
+	return len(rows)
+}
+
 // MarshalLogArray implements the zapcore.ArrayMarshaler interface
 func (rows tidbRows) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
 	for _, r := range rows {
@@ -88,6 +121,8 @@ type tidbEncoder struct {
 	// directly check the total column count, so we fall back to only check that
 	// the there are enough columns.
 	columnCnt int
+	// whether the fields beyond columnIdx are skipped instead of reported as a mismatch.
+	ignoreUnknownColumns bool
 }
 
 type encodingBuilder struct{}
@@ -106,7 +141,7 @@ func (b *encodingBuilder) NewEncoder(ctx context.Context, tbl table.Table, optio
 		se.GetSessionVars().SkipASCIICheck = false
 	}
 
-	return &tidbEncoder{mode: options.SQLMode, tbl: tbl, se: se}, nil
+	return &tidbEncoder{mode: options.SQLMode, tbl: tbl, se: se, ignoreUnknownColumns: options.IgnoreUnknownColumns}, nil
 }
 
 // MakeEmptyRows creates an empty KV rows.
@@ -243,9 +278,11 @@ func (b *targetInfoGetter) CheckRequirements(ctx context.Context, _ *backend.Che
 type tidbBackend struct {
 	db               *sql.DB
 	onDuplicate      string
+	mergeColumns     []*config.MergeColumnsRule
 	errorMgr         *errormanager.ErrorManager
 	encBuilder       backend.EncodingBuilder
 	targetInfoGetter backend.TargetInfoGetter
+	batch            *batchSizer
 }
 
 // NewTiDBBackend creates a new TiDB backend using the given database.
@@ -253,8 +290,20 @@ type tidbBackend struct {
 // The backend does not take ownership of `db`. Caller should close `db`
 // manually after the backend expired.
 func NewTiDBBackend(ctx context.Context, db *sql.DB, onDuplicate string, errorMgr *errormanager.ErrorManager) backend.Backend {
+	return NewTiDBBackendWithMergeColumns(ctx, db, onDuplicate, nil, errorMgr)
+}
+
+// NewTiDBBackendWithMergeColumns is like NewTiDBBackend, and the columns in mergeColumns
+// are updated from the incoming rows on duplicate if onDuplicate is `merge`.
+func NewTiDBBackendWithMergeColumns(
+	ctx context.Context,
+	db *sql.DB,
+	onDuplicate string,
+	mergeColumns []*config.MergeColumnsRule,
+	errorMgr *errormanager.ErrorManager,
+) backend.Backend {
 	switch onDuplicate {
-	case config.ReplaceOnDup, config.IgnoreOnDup, config.ErrorOnDup:
+	case config.ReplaceOnDup, config.IgnoreOnDup, config.ErrorOnDup, config.MergeOnDup:
 	default:
 		log.FromContext(ctx).Warn("unsupported action on duplicate, overwrite with `replace`")
 		onDuplicate = config.ReplaceOnDup
@@ -262,9 +311,11 @@ func NewTiDBBackend(ctx context.Context, db *sql.DB, onDuplicate string, errorMg
 	return backend.MakeBackend(&tidbBackend{
 		db:               db,
 		onDuplicate:      onDuplicate,
+		mergeColumns:     mergeColumns,
 		errorMgr:         errorMgr,
 		encBuilder:       NewEncodingBuilder(),
 		targetInfoGetter: NewTargetInfoGetter(db),
+		batch:            newBatchSizer(),
 	})
 }
 
@@ -307,6 +358,15 @@ func (rows tidbRows) SplitIntoChunks(splitSizeInt int) []kv.Rows {
 	return append(res, rows[i:])
 }
 
+// size returns the total size of the values of the rows.
+func (rows tidbRows) size() int {
+	size := 0
+	for _, row := range rows {
+		size += len(row.insertStmt)
+	}
+	return size
+}
+
 func (rows tidbRows) Clear() kv.Rows {
 	return rows[:0]
 }
@@ -442,20 +502,37 @@ func getColumnByIndex(cols []*table.Column, index int) *table.Column {
 }
 
 func (enc *tidbEncoder) Encode(logger log.Logger, row []types.Datum, _ int64, columnPermutation []int, path string, offset int64) (kv.Row, error) {
+	return enc.encode(logger, row, nil, columnPermutation, path, offset)
+}
+
+// EncodeWithMissing implements the kv.MissingFieldsEncoder interface. The missing fields
+// are written as DEFAULT.
+func (enc *tidbEncoder) EncodeWithMissing(logger log.Logger, row []types.Datum, missing []bool, _ int64, columnPermutation []int, path string, offset int64) (kv.Row, error) {
+	return enc.encode(logger, row, missing, columnPermutation, path, offset)
+}
+
+func (enc *tidbEncoder) encode(logger log.Logger, row []types.Datum, missing []bool, columnPermutation []int, path string, offset int64) (kv.Row, error) {
 	cols := enc.tbl.Cols()
 
 	if len(enc.columnIdx) == 0 {
 		columnMaxIdx := -1
+		for _, idx := range columnPermutation {
+			if idx > columnMaxIdx {
+				columnMaxIdx = idx
+			}
+		}
+		// the unknown columns of the data file may come before the known ones, so the fields
+		// can be more than the columns of the table.
 		columnIdx := make([]int, len(columnPermutation))
-		for i := 0; i < len(columnPermutation); i++ {
+		if columnMaxIdx >= len(columnIdx) {
+			columnIdx = make([]int, columnMaxIdx+1)
+		}
+		for i := 0; i < len(columnIdx); i++ {
 			columnIdx[i] = -1
 		}
 		for i, idx := range columnPermutation {
 			if idx >= 0 {
 				columnIdx[idx] = i
-				if idx > columnMaxIdx {
-					columnMaxIdx = idx
-				}
 			}
 		}
 		enc.columnIdx = columnIdx
@@ -473,7 +550,7 @@ func (enc *tidbEncoder) Encode(logger log.Logger, row []types.Datum, _ int64, co
 		return emptyTiDBRow, errors.Errorf("column count mismatch, expected %d, got %d", enc.columnCnt, len(row))
 	}
 
-	if len(row) > len(enc.columnIdx) {
+	if len(row) > len(enc.columnIdx) && !enc.ignoreUnknownColumns {
 		// 2. if len(row) > len(columnIdx): raw row data has more columns than those
 		// in the table
 		logger.Error("column count mismatch", zap.Ints("column_count", enc.columnIdx),
@@ -486,12 +563,17 @@ func (enc *tidbEncoder) Encode(logger log.Logger, row []types.Datum, _ int64, co
 	encoded.WriteByte('(')
 	cnt := 0
 	for i, field := range row {
-		if enc.columnIdx[i] < 0 {
+		if i >= len(enc.columnIdx) || enc.columnIdx[i] < 0 {
 			continue
 		}
 		if cnt > 0 {
 			encoded.WriteByte(',')
 		}
+		if i < len(missing) && missing[i] {
+			encoded.WriteString("DEFAULT")
+			cnt++
+			continue
+		}
 		datum := field
 		if err := enc.appendSQL(&encoded, &datum, getColumnByIndex(cols, enc.columnIdx[i])); err != nil {
 			logger.Error("tidb encode failed",
@@ -588,33 +670,49 @@ func (be *tidbBackend) ImportEngine(context.Context, uuid.UUID, int64, int64) er
 }
 
 func (be *tidbBackend) WriteRows(ctx context.Context, tableName string, columnNames []string, rows kv.Rows) error {
+	// The rows are inserted by the batches sized by the latency of the previous statements,
+	// and the rows of a batch are inserted by the same modifier.
+	pending := rows.(tidbRows)
+	for len(pending) > 0 {
+		r := be.batch.next(pending[:pending.sameModifierPrefix()], be.MaxChunkSize())
+		if err := be.writeBatch(ctx, tableName, columnNames, r); err != nil {
+			return err
+		}
+		pending = pending[len(r):]
+	}
+	return nil
+}
+
+func (be *tidbBackend) writeBatch(ctx context.Context, tableName string, columnNames []string, r tidbRows) error {
 	var err error
-rowLoop:
-	for _, r := range rows.SplitIntoChunks(be.MaxChunkSize()) {
-		for i := 0; i < writeRowsMaxRetryTimes; i++ {
-			// Write in the batch mode first.
-			err = be.WriteBatchRowsToDB(ctx, tableName, columnNames, r)
-			switch {
-			case err == nil:
-				continue rowLoop
-			case common.IsRetryableError(err):
-				// retry next loop
-			case be.errorMgr.TypeErrorsRemain() > 0:
-				// WriteBatchRowsToDB failed in the batch mode and can not be retried,
-				// we need to redo the writing row-by-row to find where the error locates (and skip it correctly in future).
-				if err = be.WriteRowsToDB(ctx, tableName, columnNames, r); err != nil {
-					// If the error is not nil, it means we reach the max error count in the non-batch mode.
-					// For now, we will treat like maxErrorCount is always 0. So we will just return if any error occurs.
-					return errors.Annotatef(err, "[%s] write rows reach max error count %d", tableName, 0)
-				}
-				continue rowLoop
-			default:
-				return err
+	for i := 0; i < writeRowsMaxRetryTimes; i++ {
+		// Write in the batch mode first.
+		start := time.Now()
+		err = be.WriteBatchRowsToDB(ctx, tableName, columnNames, r)
+		switch {
+		case err == nil:
+			be.batch.observe(log.FromContext(ctx), len(r), time.Since(start))
+			return nil
+		case isPacketTooLargeError(err) && len(r) > 1:
+			// The statement exceeds max_allowed_packet, write the rows again by the smaller batches.
+			be.batch.packetTooLarge(log.FromContext(ctx), len(r), r.size())
+			return be.WriteRows(ctx, tableName, columnNames, r)
+		case common.IsRetryableError(err):
+			// retry next loop
+		case be.errorMgr.TypeErrorsRemain() > 0:
+			// WriteBatchRowsToDB failed in the batch mode and can not be retried,
+			// we need to redo the writing row-by-row to find where the error locates (and skip it correctly in future).
+			if err = be.WriteRowsToDB(ctx, tableName, columnNames, r); err != nil {
+				// If the error is not nil, it means we reach the max error count in the non-batch mode.
+				// For now, we will treat like maxErrorCount is always 0. So we will just return if any error occurs.
+				return errors.Annotatef(err, "[%s] write rows reach max error count %d", tableName, 0)
 			}
+			return nil
+		default:
+			return err
 		}
-		return errors.Annotatef(err, "[%s] batch write rows reach max retry %d and still failed", tableName, writeRowsMaxRetryTimes)
 	}
-	return nil
+	return errors.Annotatef(err, "[%s] batch write rows reach max retry %d and still failed", tableName, writeRowsMaxRetryTimes)
 }
 
 func (be *tidbBackend) TotalMemoryConsume() int64 {
@@ -631,7 +729,7 @@ type stmtTask struct {
 //	insert into t1 values (111), (222), (333), (444);
 func (be *tidbBackend) WriteBatchRowsToDB(ctx context.Context, tableName string, columnNames []string, r kv.Rows) error {
 	rows := r.(tidbRows)
-	insertStmt := be.checkAndBuildStmt(rows, tableName, columnNames)
+	insertStmt, onDupClause := be.checkAndBuildStmt(rows, tableName, columnNames)
 	if insertStmt == nil {
 		return nil
 	}
@@ -644,15 +742,16 @@ func (be *tidbBackend) WriteBatchRowsToDB(ctx context.Context, tableName string,
 		}
 		insertStmt.WriteString(row.insertStmt)
 	}
+	insertStmt.WriteString(onDupClause)
 	stmtTasks[0] = stmtTask{rows, insertStmt.String()}
 	return be.execStmts(ctx, stmtTasks, tableName, true)
 }
 
-func (be *tidbBackend) checkAndBuildStmt(rows tidbRows, tableName string, columnNames []string) *strings.Builder {
+func (be *tidbBackend) checkAndBuildStmt(rows tidbRows, tableName string, columnNames []string) (*strings.Builder, string) {
 	if len(rows) == 0 {
-		return nil
+		return nil, ""
 	}
-	return be.buildStmt(tableName, columnNames)
+	return be.buildStmt(tableName, columnNames, rows[0].modifier)
 }
 
 // WriteRowsToDB write rows in row-by-row mode, which will insert multiple rows like this:
@@ -665,7 +764,7 @@ func (be *tidbBackend) checkAndBuildStmt(rows tidbRows, tableName string, column
 // See more details in br#1366: https://github.com/pingcap/br/issues/1366
 func (be *tidbBackend) WriteRowsToDB(ctx context.Context, tableName string, columnNames []string, r kv.Rows) error {
 	rows := r.(tidbRows)
-	insertStmt := be.checkAndBuildStmt(rows, tableName, columnNames)
+	insertStmt, onDupClause := be.checkAndBuildStmt(rows, tableName, columnNames)
 	if insertStmt == nil {
 		return nil
 	}
@@ -675,20 +774,45 @@ func (be *tidbBackend) WriteRowsToDB(ctx context.Context, tableName string, colu
 		var finalInsertStmt strings.Builder
 		finalInsertStmt.WriteString(is)
 		finalInsertStmt.WriteString(row.insertStmt)
+		finalInsertStmt.WriteString(onDupClause)
 		stmtTasks = append(stmtTasks, stmtTask{[]tidbRow{row}, finalInsertStmt.String()})
 	}
 	return be.execStmts(ctx, stmtTasks, tableName, false)
 }
 
-func (be *tidbBackend) buildStmt(tableName string, columnNames []string) *strings.Builder {
+// buildStmt builds the INSERT statement before the values and the ON DUPLICATE KEY UPDATE
+// clause after the values. The modifier of the INSERT statement in the SQL file is used
+// instead of the configured action on duplicate if it's not nil.
+func (be *tidbBackend) buildStmt(tableName string, columnNames []string, modifier *mydump.InsertModifier) (*strings.Builder, string) {
 	var insertStmt strings.Builder
-	switch be.onDuplicate {
-	case config.ReplaceOnDup:
+	var mergedColumns []string
+	var onDupAssignments string
+	switch {
+	case modifier != nil && modifier.Replace:
+		insertStmt.WriteString("REPLACE INTO ")
+	case modifier != nil && modifier.OnDuplicateUpdate != "":
+		if modifier.Ignore {
+			insertStmt.WriteString("INSERT IGNORE INTO ")
+		} else {
+			insertStmt.WriteString("INSERT INTO ")
+		}
+		onDupAssignments = modifier.OnDuplicateUpdate
+	case modifier != nil && modifier.Ignore:
+		insertStmt.WriteString("INSERT IGNORE INTO ")
+	case be.onDuplicate == config.ReplaceOnDup:
 		insertStmt.WriteString("REPLACE INTO ")
-	case config.IgnoreOnDup:
+	case be.onDuplicate == config.IgnoreOnDup:
 		insertStmt.WriteString("INSERT IGNORE INTO ")
-	case config.ErrorOnDup:
+	case be.onDuplicate == config.ErrorOnDup:
 		insertStmt.WriteString("INSERT INTO ")
+	case be.onDuplicate == config.MergeOnDup:
+		mergedColumns = be.mergedColumns(tableName, columnNames)
+		if len(mergedColumns) == 0 {
+			// no column to update, keep the existing rows.
+			insertStmt.WriteString("INSERT IGNORE INTO ")
+		} else {
+			insertStmt.WriteString("INSERT INTO ")
+		}
 	}
 	insertStmt.WriteString(tableName)
 	if len(columnNames) > 0 {
@@ -702,7 +826,49 @@ func (be *tidbBackend) buildStmt(tableName string, columnNames []string) *string
 		insertStmt.WriteByte(')')
 	}
 	insertStmt.WriteString(" VALUES")
-	return &insertStmt
+	if onDupAssignments != "" {
+		return &insertStmt, " ON DUPLICATE KEY UPDATE " + onDupAssignments
+	}
+	if len(mergedColumns) == 0 {
+		return &insertStmt, ""
+	}
+
+	var onDupClause strings.Builder
+	onDupClause.WriteString(" ON DUPLICATE KEY UPDATE ")
+	for i, colName := range mergedColumns {
+		if i != 0 {
+			onDupClause.WriteByte(',')
+		}
+		common.WriteMySQLIdentifier(&onDupClause, colName)
+		onDupClause.WriteString("=VALUES(")
+		common.WriteMySQLIdentifier(&onDupClause, colName)
+		onDupClause.WriteByte(')')
+	}
+	return &insertStmt, onDupClause.String()
+}
+
+// mergedColumns returns the columns of the table updated on duplicate by the merge rules.
+// The columns not being imported are skipped since they don't have incoming values.
+func (be *tidbBackend) mergedColumns(tableName string, columnNames []string) []string {
+	for _, rule := range be.mergeColumns {
+		if !rule.MatchesTable(tableName) {
+			continue
+		}
+		if len(columnNames) == 0 {
+			return rule.Columns
+		}
+		merged := make([]string, 0, len(rule.Columns))
+		for _, col := range rule.Columns {
+			for _, name := range columnNames {
+				if strings.EqualFold(col, name) {
+					merged = append(merged, name)
+					break
+				}
+			}
+		}
+		return merged
+	}
+	return nil
 }
 
 func (be *tidbBackend) execStmts(ctx context.Context, stmtTasks []stmtTask, tableName string, batch bool) error {
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	gmysql "github.com/go-sql-driver/mysql"
 	"github.com/pingcap/tidb/br/pkg/lightning/backend"
 	"github.com/pingcap/tidb/br/pkg/lightning/backend/kv"
 	"github.com/pingcap/tidb/br/pkg/lightning/backend/tidb"
@@ -29,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/pingcap/tidb/br/pkg/lightning/errormanager"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
 	"github.com/pingcap/tidb/br/pkg/lightning/verification"
 	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/model"
@@ -176,6 +178,84 @@ func TestWriteRowsIgnoreOnDup(t *testing.T) {
 	require.Equal(t, "(1,1)", fmt.Sprint(rowWithID))
 }
 
+func TestWriteRowsMergeOnDup(t *testing.T) {
+	s := createMysqlSuite(t)
+	defer s.TearDownTest(t)
+	s.mockDB.
+		ExpectExec("\\QINSERT INTO `foo`.`bar`(`a`) VALUES(1),(2),(3),(4),(5) ON DUPLICATE KEY UPDATE `a`=VALUES(`a`)\\E").
+		WillReturnResult(sqlmock.NewResult(5, 5))
+	// the table without a merge rule keeps the existing rows.
+	s.mockDB.
+		ExpectExec("\\QINSERT IGNORE INTO `foo`.`baz`(`a`) VALUES(1),(2),(3),(4),(5)\\E").
+		WillReturnResult(sqlmock.NewResult(5, 5))
+
+	ctx := context.Background()
+	mergeBackend := tidb.NewTiDBBackendWithMergeColumns(ctx, s.dbHandle, config.MergeOnDup,
+		[]*config.MergeColumnsRule{{Schema: "FOO", Table: "bar", Columns: []string{"x", "A"}}},
+		errormanager.New(nil, config.NewConfig(), log.L()),
+	)
+	dataRows := encodeRowsTiDB(t, mergeBackend, s.tbl)
+	for _, tableName := range []string{"`foo`.`bar`", "`foo`.`baz`"} {
+		engine, err := mergeBackend.OpenEngine(ctx, &backend.EngineConfig{}, tableName, 1)
+		require.NoError(t, err)
+		writer, err := engine.LocalWriter(ctx, nil)
+		require.NoError(t, err)
+		err = writer.WriteRows(ctx, []string{"a"}, dataRows)
+		require.NoError(t, err)
+	}
+}
+
+func TestWriteRowsWithInsertModifier(t *testing.T) {
+	s := createMysqlSuite(t)
+	defer s.TearDownTest(t)
+	s.mockDB.
+		ExpectExec("\\QINSERT IGNORE INTO `foo`.`bar`(`a`) VALUES(1),(2)\\E").
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	s.mockDB.
+		ExpectExec("\\QINSERT INTO `foo`.`bar`(`a`) VALUES(3) ON DUPLICATE KEY UPDATE a = a + 1\\E").
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	// the rows without a modifier are inserted by the configured action on duplicate.
+	s.mockDB.
+		ExpectExec("\\QREPLACE INTO `foo`.`bar`(`a`) VALUES(4),(5)\\E").
+		WillReturnResult(sqlmock.NewResult(5, 2))
+
+	ctx := context.Background()
+	logger := log.L()
+	be := tidb.NewTiDBBackend(ctx, s.dbHandle, config.ReplaceOnDup, errormanager.New(nil, config.NewConfig(), logger))
+	encoder, err := be.NewEncoder(ctx, s.tbl, &kv.SessionOptions{})
+	require.NoError(t, err)
+
+	ignore := &mydump.InsertModifier{Ignore: true}
+	modifiers := []*mydump.InsertModifier{
+		ignore,
+		ignore,
+		{OnDuplicateUpdate: "a = a + 1"},
+		nil,
+		nil,
+	}
+	dataRows := be.MakeEmptyRows()
+	dataChecksum := verification.MakeKVChecksum(0, 0, 0)
+	indexRows := be.MakeEmptyRows()
+	indexChecksum := verification.MakeKVChecksum(0, 0, 0)
+	for i, modifier := range modifiers {
+		row, err := encoder.Encode(logger, []types.Datum{
+			types.NewIntDatum(int64(i + 1)),
+		}, int64(i+1), []int{0, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1}, "1.sql", 0)
+		require.NoError(t, err)
+		if modifier != nil {
+			row = tidb.WithInsertModifier(row, modifier)
+		}
+		row.ClassifyAndAppend(&dataRows, &dataChecksum, &indexRows, &indexChecksum)
+	}
+
+	engine, err := be.OpenEngine(ctx, &backend.EngineConfig{}, "`foo`.`bar`", 1)
+	require.NoError(t, err)
+	writer, err := engine.LocalWriter(ctx, nil)
+	require.NoError(t, err)
+	err = writer.WriteRows(ctx, []string{"a"}, dataRows)
+	require.NoError(t, err)
+}
+
 func TestWriteRowsErrorOnDup(t *testing.T) {
 	s := createMysqlSuite(t)
 	defer s.TearDownTest(t)
@@ -430,6 +510,35 @@ func TestWriteRowsErrorNoRetry(t *testing.T) {
 	require.False(t, common.IsRetryableError(err), "err: %v", err)
 }
 
+func TestWriteRowsPacketTooLarge(t *testing.T) {
+	s := createMysqlSuite(t)
+	defer s.TearDownTest(t)
+
+	// batch insert exceeds max_allowed_packet.
+	s.mockDB.
+		ExpectExec("\\QINSERT INTO `foo`.`bar`(`a`) VALUES(1),(2),(3),(4),(5)\\E").
+		WillReturnError(gmysql.ErrPktTooLarge)
+	// then insert by the smaller batches, which grow again since the statement is fast.
+	s.mockDB.
+		ExpectExec("^\\QINSERT INTO `foo`.`bar`(`a`) VALUES(1),(2)\\E$").
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	s.mockDB.
+		ExpectExec("^\\QINSERT INTO `foo`.`bar`(`a`) VALUES(3),(4),(5)\\E$").
+		WillReturnResult(sqlmock.NewResult(5, 3))
+
+	be := tidb.NewTiDBBackend(context.Background(), s.dbHandle, config.ErrorOnDup,
+		errormanager.New(s.dbHandle, &config.Config{}, log.L()),
+	)
+	dataRows := encodeRowsTiDB(t, be, s.tbl)
+	ctx := context.Background()
+	engine, err := be.OpenEngine(ctx, &backend.EngineConfig{}, "`foo`.`bar`", 1)
+	require.NoError(t, err)
+	writer, err := engine.LocalWriter(ctx, nil)
+	require.NoError(t, err)
+	err = writer.WriteRows(ctx, []string{"a"}, dataRows)
+	require.NoError(t, err)
+}
+
 func TestWriteRowsErrorDowngradingAll(t *testing.T) {
 	nonRetryableError := sql.ErrNoRows
 	s := createMysqlSuite(t)
@@ -608,6 +717,49 @@ func encodeRowsTiDB(t *testing.T, b backend.Backend, tbl table.Table) kv.Rows {
 	return dataRows
 }
 
+func TestEncodeUnknownColumns(t *testing.T) {
+	s := createMysqlSuite(t)
+	defer s.TearDownTest(t)
+	ctx := context.Background()
+	logger := log.L()
+
+	// the unknown columns come before the first column of the table, and there are more fields
+	// than the columns of the table.
+	rawRow := make([]types.Datum, 0, 18)
+	for i := 0; i < 15; i++ {
+		rawRow = append(rawRow, types.NewStringDatum("unknown"))
+	}
+	rawRow = append(rawRow, types.NewIntDatum(5), types.NewStringDatum("unknown"), types.NewStringDatum("unknown"))
+	colPerm := []int{15, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1}
+
+	encoder, err := s.backend.NewEncoder(ctx, s.tbl, &kv.SessionOptions{IgnoreUnknownColumns: true})
+	require.NoError(t, err)
+	row, err := encoder.Encode(logger, rawRow, 1, colPerm, "1.csv", 0)
+	require.NoError(t, err)
+	require.Equal(t, "(5)", fmt.Sprint(row))
+
+	encoder, err = s.backend.NewEncoder(ctx, s.tbl, &kv.SessionOptions{})
+	require.NoError(t, err)
+	_, err = encoder.Encode(logger, rawRow, 1, colPerm, "2.csv", 0)
+	require.ErrorContains(t, err, "column count mismatch, at most 16 but got 18")
+}
+
+func TestEncodeMissingFields(t *testing.T) {
+	s := createMysqlSuite(t)
+	defer s.TearDownTest(t)
+	logger := log.L()
+
+	encoder, err := s.backend.NewEncoder(context.Background(), s.tbl, &kv.SessionOptions{SQLMode: mysql.ModeStrictAllTables})
+	require.NoError(t, err)
+	row, err := kv.EncodeRow(encoder, logger, []types.Datum{
+		types.NewIntDatum(5),
+		types.NewDatum(nil),
+		types.NewDatum(nil),
+	}, []bool{false, true, false}, 1, []int{0, -1, -1, -1, -1, -1, -1, -1, 1, 2, -1, -1, -1, -1}, "1.json", 0)
+	require.NoError(t, err)
+	require.Equal(t, "(5,DEFAULT,NULL)", fmt.Sprint(row))
+}
+
 func TestEncodeRowForRecord(t *testing.T) {
 	s := createMysqlSuite(t)
 
@@ -15,6 +15,7 @@
 package checkpoints
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
@@ -27,6 +28,7 @@ import (
 	"sync"
 
 	"github.com/joho/sqltocsv"
+	"github.com/klauspost/compress/zstd"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints/checkpointspb"
 	"github.com/pingcap/tidb/br/pkg/lightning/common"
@@ -38,6 +40,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/version/build"
 	"github.com/pingcap/tidb/util/mathutil"
 	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 )
 
@@ -60,6 +63,9 @@ const (
 
 const WholeTableEngineID = math.MaxInt32
 
+// indexEngineID is the engine ID of the index engine of a table.
+const indexEngineID = -1
+
 const (
 	// the table names to store each kind of checkpoint in the checkpoint database
 	// remember to increase the version number in case of incompatible change.
@@ -67,6 +73,8 @@ const (
 	CheckpointTableNameTable  = "table_v7"
 	CheckpointTableNameEngine = "engine_v5"
 	CheckpointTableNameChunk  = "chunk_v5"
+	// CheckpointTableNameSchema records the schema objects created by Lightning.
+	CheckpointTableNameSchema = "schema_v1"
 
 	// Some frequently used table name or constants.
 	allTables       = "all"
@@ -137,6 +145,11 @@ const (
 			update_time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
 			PRIMARY KEY(table_name, engine_id, path(500), offset)
 		);`
+	CreateSchemaTableTemplate = `
+		CREATE TABLE IF NOT EXISTS %s.%s (
+			object_name varchar(261) NOT NULL PRIMARY KEY,
+			create_time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`
 	InitTaskTemplate = `
 		REPLACE INTO %s.%s (id, task_id, source_dir, backend, importer_addr, tidb_host, tidb_port, pd_addr, sorted_kv_dir, lightning_ver)
 			VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
@@ -184,11 +197,14 @@ const (
 	UpdateEngineTemplate        = `
 		UPDATE %s.%s SET status = ? WHERE (table_name, engine_id) = (?, ?);`
 	DeleteCheckpointRecordTemplate = "DELETE FROM %s.%s WHERE table_name = ?;"
+	ReadSchemaObjectsTemplate      = "SELECT object_name FROM %s.%s;"
+	InsertSchemaObjectTemplate     = "INSERT IGNORE INTO %s.%s (object_name) VALUES (?);"
 )
 
 func IsCheckpointTable(name string) bool {
 	switch name {
-	case CheckpointTableNameTask, CheckpointTableNameTable, CheckpointTableNameEngine, CheckpointTableNameChunk:
+	case CheckpointTableNameTask, CheckpointTableNameTable, CheckpointTableNameEngine, CheckpointTableNameChunk,
+		CheckpointTableNameSchema:
 		return true
 	default:
 		return false
@@ -503,6 +519,14 @@ type DB interface {
 	// GetLocalStoringTables returns a map containing tables have engine files stored on local disk.
 	// currently only meaningful for local backend
 	GetLocalStoringTables(ctx context.Context) (map[string][]int32, error)
+	// TableNames returns the sorted names of the tables which have checkpoints, like "`db`.`tbl`".
+	TableNames(ctx context.Context) ([]string, error)
+	// CreatedSchemaObjects returns the names of the schema objects created by the previous runs,
+	// like "`db`" and "`db`.`tbl`".
+	CreatedSchemaObjects(ctx context.Context) (map[string]struct{}, error)
+	// AddCreatedSchemaObjects records the schema objects as created, so that they are skipped
+	// when the interrupted schema creation is resumed.
+	AddCreatedSchemaObjects(ctx context.Context, names []string) error
 	IgnoreErrorCheckpoint(ctx context.Context, tableName string) error
 	DestroyErrorCheckpoint(ctx context.Context, tableName string) ([]DestroyedTableCheckpoint, error)
 	DumpTables(ctx context.Context, csv io.Writer) error
@@ -533,8 +557,18 @@ func OpenCheckpointsDB(ctx context.Context, cfg *config.Config) (DB, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		if err := cpdb.SetCompression(cfg.Checkpoint.Compression); err != nil {
+			return nil, errors.Trace(err)
+		}
 		return cpdb, nil
 
+	case config.CheckpointDriverEtcd:
+		cli, err := newEtcdClient(cfg)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return NewEtcdCheckpointsDB(cli, cfg.Checkpoint.Schema), nil
+
 	default:
 		return nil, common.ErrUnknownCheckpointDriver.GenWithStackByArgs(cfg.Checkpoint.Driver)
 	}
@@ -576,6 +610,16 @@ func IsCheckpointsDBExists(ctx context.Context, cfg *config.Config) (bool, error
 		}
 		return result, nil
 
+	case config.CheckpointDriverEtcd:
+		cli, err := newEtcdClient(cfg)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		//nolint: errcheck
+		defer cli.Close()
+		result, err := isEtcdCheckpointsExists(ctx, cli, cfg.Checkpoint.Schema)
+		return result, errors.Trace(err)
+
 	default:
 		return false, common.ErrUnknownCheckpointDriver.GenWithStackByArgs(cfg.Checkpoint.Driver)
 	}
@@ -615,6 +659,14 @@ func (*NullCheckpointsDB) Update(context.Context, map[string]*TableCheckpointDif
 	return nil
 }
 
+func (*NullCheckpointsDB) CreatedSchemaObjects(context.Context) (map[string]struct{}, error) {
+	return map[string]struct{}{}, nil
+}
+
+func (*NullCheckpointsDB) AddCreatedSchemaObjects(context.Context, []string) error {
+	return nil
+}
+
 type MySQLCheckpointsDB struct {
 	db     *sql.DB
 	schema string
@@ -652,6 +704,11 @@ func NewMySQLCheckpointsDB(ctx context.Context, db *sql.DB, schemaName string) (
 		return nil, errors.Trace(err)
 	}
 
+	err = sql.Exec(ctx, "create schema checkpoints table", fmt.Sprintf(CreateSchemaTableTemplate, schema, CheckpointTableNameSchema))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	return &MySQLCheckpointsDB{
 		db:     db,
 		schema: schema,
@@ -967,8 +1024,17 @@ type FileCheckpointsDB struct {
 	path        string
 	fileName    string
 	exStorage   storage.ExternalStorage
+	// encoder is not nil if the checkpoint file is compressed by zstd.
+	encoder *zstd.Encoder
+	// schemaObjects are the created schema objects, which are stored in the file with
+	// SchemaObjectsFileSuffix. It's nil until the file is read.
+	schemaObjects map[string]struct{}
 }
 
+// SchemaObjectsFileSuffix is the suffix of the file storing the created schema objects with
+// the file driver, the names are stored as a JSON array.
+const SchemaObjectsFileSuffix = ".schema"
+
 func newFileCheckpointsDB(
 	ctx context.Context,
 	path string,
@@ -1005,6 +1071,14 @@ func newFileCheckpointsDB(
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	// the checkpoint file may be compressed by a previous run whatever the current
+	// configuration is, so always detect the compression by the content.
+	if bytes.HasPrefix(content, zstdMagic) {
+		content, err = decompressZstd(content)
+		if err != nil {
+			return nil, errors.Annotatef(err, "decompress checkpoint file '%s' failed", path)
+		}
+	}
 	err = cpdb.checkpoints.Unmarshal(content)
 	if err != nil {
 		log.FromContext(ctx).Error("checkpoint file is broken", zap.String("path", path), zap.Error(err))
@@ -1094,11 +1168,53 @@ func separateCompletePath(completePath string) (string, string, error) {
 	return fileName, newPath, nil
 }
 
+// zstdMagic is the magic number at the beginning of a zstd frame.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+func decompressZstd(content []byte) ([]byte, error) {
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer decoder.Close()
+	content, err = decoder.DecodeAll(content, nil)
+	return content, errors.Trace(err)
+}
+
+// SetCompression sets the compression of the checkpoint file, which takes effect
+// from the next save. The supported compressions are "" for no compression and
+// config.CheckpointCompressionZstd.
+func (cpdb *FileCheckpointsDB) SetCompression(compression string) error {
+	cpdb.lock.Lock()
+	defer cpdb.lock.Unlock()
+
+	if cpdb.encoder != nil {
+		_ = cpdb.encoder.Close()
+		cpdb.encoder = nil
+	}
+	switch compression {
+	case "":
+		return nil
+	case config.CheckpointCompressionZstd:
+		encoder, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		cpdb.encoder = encoder
+		return nil
+	default:
+		return errors.Errorf("unsupported checkpoint compression '%s'", compression)
+	}
+}
+
 func (cpdb *FileCheckpointsDB) save() error {
 	serialized, err := cpdb.checkpoints.Marshal()
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if cpdb.encoder != nil {
+		serialized = cpdb.encoder.EncodeAll(serialized, nil)
+	}
 	return cpdb.exStorage.WriteFile(cpdb.ctx, cpdb.fileName, serialized)
 }
 
@@ -1106,17 +1222,7 @@ func (cpdb *FileCheckpointsDB) Initialize(ctx context.Context, cfg *config.Confi
 	cpdb.lock.Lock()
 	defer cpdb.lock.Unlock()
 
-	cpdb.checkpoints.TaskCheckpoint = &checkpointspb.TaskCheckpointModel{
-		TaskId:       cfg.TaskID,
-		SourceDir:    cfg.Mydumper.SourceDir,
-		Backend:      cfg.TikvImporter.Backend,
-		ImporterAddr: cfg.TikvImporter.Addr,
-		TidbHost:     cfg.TiDB.Host,
-		TidbPort:     int32(cfg.TiDB.Port),
-		PdAddr:       cfg.TiDB.PdAddr,
-		SortedKvDir:  cfg.TikvImporter.SortedKVDir,
-		LightningVer: build.ReleaseVersion,
-	}
+	cpdb.checkpoints.TaskCheckpoint = newTaskCheckpointModel(cfg)
 
 	if cpdb.checkpoints.Checkpoints == nil {
 		cpdb.checkpoints.Checkpoints = make(map[string]*checkpointspb.TableCheckpointModel)
@@ -1126,11 +1232,7 @@ func (cpdb *FileCheckpointsDB) Initialize(ctx context.Context, cfg *config.Confi
 		for _, table := range db.Tables {
 			tableName := common.UniqueTable(db.Name, table.Name)
 			if _, ok := cpdb.checkpoints.Checkpoints[tableName]; !ok {
-				cpdb.checkpoints.Checkpoints[tableName] = &checkpointspb.TableCheckpointModel{
-					Status:  uint32(CheckpointStatusLoaded),
-					Engines: map[int32]*checkpointspb.EngineCheckpointModel{},
-					TableID: table.ID,
-				}
+				cpdb.checkpoints.Checkpoints[tableName] = newTableCheckpointModel(table.ID)
 			}
 			// TODO check if hash matches
 		}
@@ -1141,22 +1243,7 @@ func (cpdb *FileCheckpointsDB) Initialize(ctx context.Context, cfg *config.Confi
 
 func (cpdb *FileCheckpointsDB) TaskCheckpoint(_ context.Context) (*TaskCheckpoint, error) {
 	// this method is always called in lock
-	cp := cpdb.checkpoints.TaskCheckpoint
-	if cp == nil || cp.TaskId == 0 {
-		return nil, nil
-	}
-
-	return &TaskCheckpoint{
-		TaskID:       cp.TaskId,
-		SourceDir:    cp.SourceDir,
-		Backend:      cp.Backend,
-		ImporterAddr: cp.ImporterAddr,
-		TiDBHost:     cp.TidbHost,
-		TiDBPort:     int(cp.TidbPort),
-		PdAddr:       cp.PdAddr,
-		SortedKVDir:  cp.SortedKvDir,
-		LightningVer: cp.LightningVer,
-	}, nil
+	return taskCheckpointFromModel(cpdb.checkpoints.TaskCheckpoint), nil
 }
 
 func (cpdb *FileCheckpointsDB) Close() error {
@@ -1175,95 +1262,14 @@ func (cpdb *FileCheckpointsDB) Get(_ context.Context, tableName string) (*TableC
 		return nil, errors.NotFoundf("checkpoint for table %s", tableName)
 	}
 
-	cp := &TableCheckpoint{
-		Status:    CheckpointStatus(tableModel.Status),
-		AllocBase: tableModel.AllocBase,
-		Engines:   make(map[int32]*EngineCheckpoint, len(tableModel.Engines)),
-		TableID:   tableModel.TableID,
-		Checksum:  verify.MakeKVChecksum(tableModel.KvBytes, tableModel.KvKvs, tableModel.KvChecksum),
-	}
-
-	for engineID, engineModel := range tableModel.Engines {
-		engine := &EngineCheckpoint{
-			Status: CheckpointStatus(engineModel.Status),
-			Chunks: make([]*ChunkCheckpoint, 0, len(engineModel.Chunks)),
-		}
-
-		for _, chunkModel := range engineModel.Chunks {
-			colPerm := make([]int, 0, len(chunkModel.ColumnPermutation))
-			for _, c := range chunkModel.ColumnPermutation {
-				colPerm = append(colPerm, int(c))
-			}
-			engine.Chunks = append(engine.Chunks, &ChunkCheckpoint{
-				Key: ChunkCheckpointKey{
-					Path:   chunkModel.Path,
-					Offset: chunkModel.Offset,
-				},
-				FileMeta: mydump.SourceFileMeta{
-					Path:        chunkModel.Path,
-					Type:        mydump.SourceType(chunkModel.Type),
-					Compression: mydump.Compression(chunkModel.Compression),
-					SortKey:     chunkModel.SortKey,
-					FileSize:    chunkModel.FileSize,
-				},
-				ColumnPermutation: colPerm,
-				Chunk: mydump.Chunk{
-					Offset:       chunkModel.Pos,
-					EndOffset:    chunkModel.EndOffset,
-					PrevRowIDMax: chunkModel.PrevRowidMax,
-					RowIDMax:     chunkModel.RowidMax,
-				},
-				Checksum:  verify.MakeKVChecksum(chunkModel.KvcBytes, chunkModel.KvcKvs, chunkModel.KvcChecksum),
-				Timestamp: chunkModel.Timestamp,
-			})
-		}
-
-		slices.SortFunc(engine.Chunks, func(i, j *ChunkCheckpoint) bool {
-			return i.Key.less(&j.Key)
-		})
-
-		cp.Engines[engineID] = engine
-	}
-
-	return cp, nil
+	return tableCheckpointFromModel(tableModel), nil
 }
 
 func (cpdb *FileCheckpointsDB) InsertEngineCheckpoints(_ context.Context, tableName string, checkpoints map[int32]*EngineCheckpoint) error {
 	cpdb.lock.Lock()
 	defer cpdb.lock.Unlock()
 
-	tableModel := cpdb.checkpoints.Checkpoints[tableName]
-	for engineID, engine := range checkpoints {
-		engineModel := &checkpointspb.EngineCheckpointModel{
-			Status: uint32(CheckpointStatusLoaded),
-			Chunks: make(map[string]*checkpointspb.ChunkCheckpointModel),
-		}
-		for _, value := range engine.Chunks {
-			key := value.Key.String()
-			chunk, ok := engineModel.Chunks[key]
-			if !ok {
-				chunk = &checkpointspb.ChunkCheckpointModel{
-					Path:   value.Key.Path,
-					Offset: value.Key.Offset,
-				}
-				engineModel.Chunks[key] = chunk
-			}
-			chunk.Type = int32(value.FileMeta.Type)
-			chunk.Compression = int32(value.FileMeta.Compression)
-			chunk.SortKey = value.FileMeta.SortKey
-			chunk.FileSize = value.FileMeta.FileSize
-			chunk.Pos = value.Chunk.Offset
-			chunk.EndOffset = value.Chunk.EndOffset
-			chunk.PrevRowidMax = value.Chunk.PrevRowIDMax
-			chunk.RowidMax = value.Chunk.RowIDMax
-			chunk.Timestamp = value.Timestamp
-			if len(value.ColumnPermutation) > 0 {
-				chunk.ColumnPermutation = intSlice2Int32Slice(value.ColumnPermutation)
-			}
-		}
-		tableModel.Engines[engineID] = engineModel
-	}
-
+	insertEngineCheckpointModels(cpdb.checkpoints.Checkpoints[tableName], checkpoints)
 	return errors.Trace(cpdb.save())
 }
 
@@ -1272,34 +1278,7 @@ func (cpdb *FileCheckpointsDB) Update(_ context.Context, checkpointDiffs map[str
 	defer cpdb.lock.Unlock()
 
 	for tableName, cpd := range checkpointDiffs {
-		tableModel := cpdb.checkpoints.Checkpoints[tableName]
-		if cpd.hasStatus {
-			tableModel.Status = uint32(cpd.status)
-		}
-		if cpd.hasRebase {
-			tableModel.AllocBase = cpd.allocBase
-		}
-		if cpd.hasChecksum {
-			tableModel.KvBytes = cpd.checksum.SumSize()
-			tableModel.KvKvs = cpd.checksum.SumKVS()
-			tableModel.KvChecksum = cpd.checksum.Sum()
-		}
-		for engineID, engineDiff := range cpd.engines {
-			engineModel := tableModel.Engines[engineID]
-			if engineDiff.hasStatus {
-				engineModel.Status = uint32(engineDiff.status)
-			}
-
-			for key, diff := range engineDiff.chunks {
-				chunkModel := engineModel.Chunks[key.String()]
-				chunkModel.Pos = diff.pos
-				chunkModel.PrevRowidMax = diff.rowID
-				chunkModel.KvcBytes = diff.checksum.SumSize()
-				chunkModel.KvcKvs = diff.checksum.SumKVS()
-				chunkModel.KvcChecksum = diff.checksum.Sum()
-				chunkModel.ColumnPermutation = intSlice2Int32Slice(diff.columnPermutation)
-			}
-		}
+		applyTableCheckpointDiff(cpdb.checkpoints.Checkpoints[tableName], cpd)
 	}
 
 	return cpdb.save()
@@ -1321,6 +1300,10 @@ func (*NullCheckpointsDB) GetLocalStoringTables(context.Context) (map[string][]i
 	return nil, nil
 }
 
+func (*NullCheckpointsDB) TableNames(context.Context) ([]string, error) {
+	return nil, errors.Trace(errCannotManageNullDB)
+}
+
 func (*NullCheckpointsDB) IgnoreErrorCheckpoint(context.Context, string) error {
 	return errors.Trace(errCannotManageNullDB)
 }
@@ -1386,6 +1369,7 @@ func (cpdb *MySQLCheckpointsDB) MoveCheckpoints(ctx context.Context, taskID int6
 	for _, tbl := range []string{
 		CheckpointTableNameChunk, CheckpointTableNameEngine,
 		CheckpointTableNameTable, CheckpointTableNameTask,
+		CheckpointTableNameSchema,
 	} {
 		query := fmt.Sprintf("RENAME TABLE %[1]s.%[3]s TO %[2]s.%[3]s", cpdb.schema, newSchema, tbl)
 		if e := s.Exec(ctx, fmt.Sprintf("move %s checkpoints table", tbl), query); e != nil {
@@ -1446,6 +1430,82 @@ func (cpdb *MySQLCheckpointsDB) GetLocalStoringTables(ctx context.Context) (map[
 	return targetTables, err
 }
 
+func (cpdb *MySQLCheckpointsDB) TableNames(ctx context.Context) ([]string, error) {
+	var tableNames []string
+	query := fmt.Sprintf("SELECT table_name FROM %s.%s ORDER BY table_name;", cpdb.schema, CheckpointTableNameTable)
+	err := common.Retry("get checkpoint table names", log.FromContext(ctx), func() error {
+		tableNames = tableNames[:0]
+		rows, err := cpdb.db.QueryContext(ctx, query) // #nosec G201
+		if err != nil {
+			return errors.Trace(err)
+		}
+		//nolint: errcheck
+		defer rows.Close()
+		for rows.Next() {
+			var tableName string
+			if err := rows.Scan(&tableName); err != nil {
+				return errors.Trace(err)
+			}
+			tableNames = append(tableNames, tableName)
+		}
+		return errors.Trace(rows.Err())
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return tableNames, nil
+}
+
+func (cpdb *MySQLCheckpointsDB) CreatedSchemaObjects(ctx context.Context) (map[string]struct{}, error) {
+	var names map[string]struct{}
+	query := fmt.Sprintf(ReadSchemaObjectsTemplate, cpdb.schema, CheckpointTableNameSchema)
+	err := common.Retry("get created schema objects", log.FromContext(ctx), func() error {
+		names = make(map[string]struct{})
+		rows, err := cpdb.db.QueryContext(ctx, query) // #nosec G201
+		if err != nil {
+			return errors.Trace(err)
+		}
+		//nolint: errcheck
+		defer rows.Close()
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return errors.Trace(err)
+			}
+			names[name] = struct{}{}
+		}
+		return errors.Trace(rows.Err())
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return names, nil
+}
+
+func (cpdb *MySQLCheckpointsDB) AddCreatedSchemaObjects(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	s := common.SQLWithRetry{
+		DB:     cpdb.db,
+		Logger: log.FromContext(ctx).With(zap.Int("count", len(names))),
+	}
+	return s.Transact(ctx, "add created schema objects", func(c context.Context, tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(c, fmt.Sprintf(InsertSchemaObjectTemplate, cpdb.schema, CheckpointTableNameSchema))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		//nolint: errcheck
+		defer stmt.Close()
+		for _, name := range names {
+			if _, err := stmt.ExecContext(c, name); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	})
+}
+
 func (cpdb *MySQLCheckpointsDB) IgnoreErrorCheckpoint(ctx context.Context, tableName string) error {
 	var colName string
 	if tableName == allTables {
@@ -1646,7 +1706,11 @@ func (cpdb *FileCheckpointsDB) RemoveCheckpoint(_ context.Context, tableName str
 
 	if tableName == allTables {
 		cpdb.checkpoints.Reset()
-		return errors.Trace(cpdb.exStorage.DeleteFile(cpdb.ctx, cpdb.fileName))
+		cpdb.schemaObjects = nil
+		if err := cpdb.exStorage.DeleteFile(cpdb.ctx, cpdb.fileName); err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Trace(cpdb.removeSchemaObjectsFile())
 	}
 
 	delete(cpdb.checkpoints.Checkpoints, tableName)
@@ -1658,7 +1722,84 @@ func (cpdb *FileCheckpointsDB) MoveCheckpoints(ctx context.Context, taskID int64
 	defer cpdb.lock.Unlock()
 
 	newFileName := fmt.Sprintf("%s.%d.bak", cpdb.fileName, taskID)
-	return cpdb.exStorage.Rename(cpdb.ctx, cpdb.fileName, newFileName)
+	if err := cpdb.exStorage.Rename(cpdb.ctx, cpdb.fileName, newFileName); err != nil {
+		return errors.Trace(err)
+	}
+	schemaFileName := cpdb.fileName + SchemaObjectsFileSuffix
+	exist, err := cpdb.exStorage.FileExists(cpdb.ctx, schemaFileName)
+	if err != nil || !exist {
+		return errors.Trace(err)
+	}
+	cpdb.schemaObjects = nil
+	return errors.Trace(cpdb.exStorage.Rename(cpdb.ctx, schemaFileName, newFileName+SchemaObjectsFileSuffix))
+}
+
+func (cpdb *FileCheckpointsDB) removeSchemaObjectsFile() error {
+	schemaFileName := cpdb.fileName + SchemaObjectsFileSuffix
+	exist, err := cpdb.exStorage.FileExists(cpdb.ctx, schemaFileName)
+	if err != nil || !exist {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cpdb.exStorage.DeleteFile(cpdb.ctx, schemaFileName))
+}
+
+// loadSchemaObjects reads the created schema objects, this method is always called in lock.
+func (cpdb *FileCheckpointsDB) loadSchemaObjects() error {
+	if cpdb.schemaObjects != nil {
+		return nil
+	}
+	schemaFileName := cpdb.fileName + SchemaObjectsFileSuffix
+	exist, err := cpdb.exStorage.FileExists(cpdb.ctx, schemaFileName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var names []string
+	if exist {
+		content, err := cpdb.exStorage.ReadFile(cpdb.ctx, schemaFileName)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := json.Unmarshal(content, &names); err != nil {
+			return errors.Annotatef(err, "schema checkpoint file '%s' is broken", schemaFileName)
+		}
+	}
+	cpdb.schemaObjects = make(map[string]struct{}, len(names))
+	for _, name := range names {
+		cpdb.schemaObjects[name] = struct{}{}
+	}
+	return nil
+}
+
+func (cpdb *FileCheckpointsDB) CreatedSchemaObjects(_ context.Context) (map[string]struct{}, error) {
+	cpdb.lock.Lock()
+	defer cpdb.lock.Unlock()
+
+	if err := cpdb.loadSchemaObjects(); err != nil {
+		return nil, err
+	}
+	return maps.Clone(cpdb.schemaObjects), nil
+}
+
+func (cpdb *FileCheckpointsDB) AddCreatedSchemaObjects(_ context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	cpdb.lock.Lock()
+	defer cpdb.lock.Unlock()
+
+	if err := cpdb.loadSchemaObjects(); err != nil {
+		return err
+	}
+	for _, name := range names {
+		cpdb.schemaObjects[name] = struct{}{}
+	}
+	allNames := maps.Keys(cpdb.schemaObjects)
+	slices.Sort(allNames)
+	content, err := json.Marshal(allNames)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(cpdb.exStorage.WriteFile(cpdb.ctx, cpdb.fileName+SchemaObjectsFileSuffix, content))
 }
 
 func (cpdb *FileCheckpointsDB) GetLocalStoringTables(_ context.Context) (map[string][]int32, error) {
@@ -1668,28 +1809,26 @@ func (cpdb *FileCheckpointsDB) GetLocalStoringTables(_ context.Context) (map[str
 	targetTables := make(map[string][]int32)
 
 	for tableName, tableModel := range cpdb.checkpoints.Checkpoints {
-		if tableModel.Status <= uint32(CheckpointStatusMaxInvalid) ||
-			tableModel.Status >= uint32(CheckpointStatusIndexImported) {
-			continue
-		}
-		for engineID, engineModel := range tableModel.Engines {
-			if engineModel.Status <= uint32(CheckpointStatusMaxInvalid) ||
-				engineModel.Status >= uint32(CheckpointStatusImported) {
-				continue
-			}
-
-			for _, chunkModel := range engineModel.Chunks {
-				if chunkModel.Pos > chunkModel.Offset {
-					targetTables[tableName] = append(targetTables[tableName], engineID)
-					break
-				}
-			}
+		if engineIDs := localStoringEngines(tableModel); len(engineIDs) > 0 {
+			targetTables[tableName] = engineIDs
 		}
 	}
 
 	return targetTables, nil
 }
 
+func (cpdb *FileCheckpointsDB) TableNames(_ context.Context) ([]string, error) {
+	cpdb.lock.Lock()
+	defer cpdb.lock.Unlock()
+
+	tableNames := make([]string, 0, len(cpdb.checkpoints.Checkpoints))
+	for tableName := range cpdb.checkpoints.Checkpoints {
+		tableNames = append(tableNames, tableName)
+	}
+	slices.Sort(tableNames)
+	return tableNames, nil
+}
+
 func (cpdb *FileCheckpointsDB) IgnoreErrorCheckpoint(_ context.Context, targetTableName string) error {
 	cpdb.lock.Lock()
 	defer cpdb.lock.Unlock()
@@ -1698,14 +1837,7 @@ func (cpdb *FileCheckpointsDB) IgnoreErrorCheckpoint(_ context.Context, targetTa
 		if !(targetTableName == allTables || targetTableName == tableName) {
 			continue
 		}
-		if tableModel.Status <= uint32(CheckpointStatusMaxInvalid) {
-			tableModel.Status = uint32(CheckpointStatusLoaded)
-		}
-		for _, engineModel := range tableModel.Engines {
-			if engineModel.Status <= uint32(CheckpointStatusMaxInvalid) {
-				engineModel.Status = uint32(CheckpointStatusLoaded)
-			}
-		}
+		ignoreErrorModel(tableModel)
 	}
 	return errors.Trace(cpdb.save())
 }
@@ -1722,21 +1854,7 @@ func (cpdb *FileCheckpointsDB) DestroyErrorCheckpoint(_ context.Context, targetT
 			continue
 		}
 		if tableModel.Status <= uint32(CheckpointStatusMaxInvalid) {
-			var minEngineID, maxEngineID int32 = math.MaxInt32, math.MinInt32
-			for engineID := range tableModel.Engines {
-				if engineID < minEngineID {
-					minEngineID = engineID
-				}
-				if engineID > maxEngineID {
-					maxEngineID = engineID
-				}
-			}
-
-			targetTables = append(targetTables, DestroyedTableCheckpoint{
-				TableName:   tableName,
-				MinEngineID: minEngineID,
-				MaxEngineID: maxEngineID,
-			})
+			targetTables = append(targetTables, destroyedTableCheckpoint(tableName, tableModel))
 		}
 	}
 
@@ -1770,3 +1888,223 @@ func intSlice2Int32Slice(s []int) []int32 {
 	}
 	return res
 }
+
+// The following functions operate on the protobuf models, which are shared by
+// the file and etcd checkpoints DB.
+
+func newTaskCheckpointModel(cfg *config.Config) *checkpointspb.TaskCheckpointModel {
+	return &checkpointspb.TaskCheckpointModel{
+		TaskId:       cfg.TaskID,
+		SourceDir:    cfg.Mydumper.SourceDir,
+		Backend:      cfg.TikvImporter.Backend,
+		ImporterAddr: cfg.TikvImporter.Addr,
+		TidbHost:     cfg.TiDB.Host,
+		TidbPort:     int32(cfg.TiDB.Port),
+		PdAddr:       cfg.TiDB.PdAddr,
+		SortedKvDir:  cfg.TikvImporter.SortedKVDir,
+		LightningVer: build.ReleaseVersion,
+	}
+}
+
+func taskCheckpointFromModel(cp *checkpointspb.TaskCheckpointModel) *TaskCheckpoint {
+	if cp == nil || cp.TaskId == 0 {
+		return nil
+	}
+	return &TaskCheckpoint{
+		TaskID:       cp.TaskId,
+		SourceDir:    cp.SourceDir,
+		Backend:      cp.Backend,
+		ImporterAddr: cp.ImporterAddr,
+		TiDBHost:     cp.TidbHost,
+		TiDBPort:     int(cp.TidbPort),
+		PdAddr:       cp.PdAddr,
+		SortedKVDir:  cp.SortedKvDir,
+		LightningVer: cp.LightningVer,
+	}
+}
+
+func newTableCheckpointModel(tableID int64) *checkpointspb.TableCheckpointModel {
+	return &checkpointspb.TableCheckpointModel{
+		Status:  uint32(CheckpointStatusLoaded),
+		Engines: map[int32]*checkpointspb.EngineCheckpointModel{},
+		TableID: tableID,
+	}
+}
+
+func tableCheckpointFromModel(tableModel *checkpointspb.TableCheckpointModel) *TableCheckpoint {
+	cp := &TableCheckpoint{
+		Status:    CheckpointStatus(tableModel.Status),
+		AllocBase: tableModel.AllocBase,
+		Engines:   make(map[int32]*EngineCheckpoint, len(tableModel.Engines)),
+		TableID:   tableModel.TableID,
+		Checksum:  verify.MakeKVChecksum(tableModel.KvBytes, tableModel.KvKvs, tableModel.KvChecksum),
+	}
+
+	for engineID, engineModel := range tableModel.Engines {
+		engine := &EngineCheckpoint{
+			Status: CheckpointStatus(engineModel.Status),
+			Chunks: make([]*ChunkCheckpoint, 0, len(engineModel.Chunks)),
+		}
+
+		for _, chunkModel := range engineModel.Chunks {
+			colPerm := make([]int, 0, len(chunkModel.ColumnPermutation))
+			for _, c := range chunkModel.ColumnPermutation {
+				colPerm = append(colPerm, int(c))
+			}
+			engine.Chunks = append(engine.Chunks, &ChunkCheckpoint{
+				Key: ChunkCheckpointKey{
+					Path:   chunkModel.Path,
+					Offset: chunkModel.Offset,
+				},
+				FileMeta: mydump.SourceFileMeta{
+					Path:        chunkModel.Path,
+					Type:        mydump.SourceType(chunkModel.Type),
+					Compression: mydump.Compression(chunkModel.Compression),
+					SortKey:     chunkModel.SortKey,
+					FileSize:    chunkModel.FileSize,
+				},
+				ColumnPermutation: colPerm,
+				Chunk: mydump.Chunk{
+					Offset:       chunkModel.Pos,
+					EndOffset:    chunkModel.EndOffset,
+					PrevRowIDMax: chunkModel.PrevRowidMax,
+					RowIDMax:     chunkModel.RowidMax,
+				},
+				Checksum:  verify.MakeKVChecksum(chunkModel.KvcBytes, chunkModel.KvcKvs, chunkModel.KvcChecksum),
+				Timestamp: chunkModel.Timestamp,
+			})
+		}
+
+		slices.SortFunc(engine.Chunks, func(i, j *ChunkCheckpoint) bool {
+			return i.Key.less(&j.Key)
+		})
+
+		cp.Engines[engineID] = engine
+	}
+
+	return cp
+}
+
+func insertEngineCheckpointModels(tableModel *checkpointspb.TableCheckpointModel, checkpoints map[int32]*EngineCheckpoint) {
+	for engineID, engine := range checkpoints {
+		engineModel := &checkpointspb.EngineCheckpointModel{
+			Status: uint32(CheckpointStatusLoaded),
+			Chunks: make(map[string]*checkpointspb.ChunkCheckpointModel),
+		}
+		for _, value := range engine.Chunks {
+			key := value.Key.String()
+			chunk, ok := engineModel.Chunks[key]
+			if !ok {
+				chunk = &checkpointspb.ChunkCheckpointModel{
+					Path:   value.Key.Path,
+					Offset: value.Key.Offset,
+				}
+				engineModel.Chunks[key] = chunk
+			}
+			chunk.Type = int32(value.FileMeta.Type)
+			chunk.Compression = int32(value.FileMeta.Compression)
+			chunk.SortKey = value.FileMeta.SortKey
+			chunk.FileSize = value.FileMeta.FileSize
+			chunk.Pos = value.Chunk.Offset
+			chunk.EndOffset = value.Chunk.EndOffset
+			chunk.PrevRowidMax = value.Chunk.PrevRowIDMax
+			chunk.RowidMax = value.Chunk.RowIDMax
+			chunk.Timestamp = value.Timestamp
+			if len(value.ColumnPermutation) > 0 {
+				chunk.ColumnPermutation = intSlice2Int32Slice(value.ColumnPermutation)
+			}
+		}
+		tableModel.Engines[engineID] = engineModel
+	}
+
+}
+
+func applyTableCheckpointDiff(tableModel *checkpointspb.TableCheckpointModel, cpd *TableCheckpointDiff) {
+	applyTableDiff(tableModel, cpd)
+	for engineID, engineDiff := range cpd.engines {
+		engineModel := tableModel.Engines[engineID]
+		if engineDiff.hasStatus {
+			engineModel.Status = uint32(engineDiff.status)
+		}
+
+		for key, diff := range engineDiff.chunks {
+			applyChunkDiff(engineModel.Chunks[key.String()], diff)
+		}
+	}
+}
+
+// applyTableDiff applies the diff of the table itself, without its engines.
+func applyTableDiff(tableModel *checkpointspb.TableCheckpointModel, cpd *TableCheckpointDiff) {
+	if cpd.hasStatus {
+		tableModel.Status = uint32(cpd.status)
+	}
+	if cpd.hasRebase {
+		tableModel.AllocBase = cpd.allocBase
+	}
+	if cpd.hasChecksum {
+		tableModel.KvBytes = cpd.checksum.SumSize()
+		tableModel.KvKvs = cpd.checksum.SumKVS()
+		tableModel.KvChecksum = cpd.checksum.Sum()
+	}
+}
+
+func applyChunkDiff(chunkModel *checkpointspb.ChunkCheckpointModel, diff chunkCheckpointDiff) {
+	chunkModel.Pos = diff.pos
+	chunkModel.PrevRowidMax = diff.rowID
+	chunkModel.KvcBytes = diff.checksum.SumSize()
+	chunkModel.KvcKvs = diff.checksum.SumKVS()
+	chunkModel.KvcChecksum = diff.checksum.Sum()
+	chunkModel.ColumnPermutation = intSlice2Int32Slice(diff.columnPermutation)
+}
+
+// localStoringEngines returns the engines of the table which have been partially
+// written to the local disk.
+func localStoringEngines(tableModel *checkpointspb.TableCheckpointModel) []int32 {
+	if tableModel.Status <= uint32(CheckpointStatusMaxInvalid) ||
+		tableModel.Status >= uint32(CheckpointStatusIndexImported) {
+		return nil
+	}
+	var engineIDs []int32
+	for engineID, engineModel := range tableModel.Engines {
+		if engineModel.Status <= uint32(CheckpointStatusMaxInvalid) ||
+			engineModel.Status >= uint32(CheckpointStatusImported) {
+			continue
+		}
+
+		for _, chunkModel := range engineModel.Chunks {
+			if chunkModel.Pos > chunkModel.Offset {
+				engineIDs = append(engineIDs, engineID)
+				break
+			}
+		}
+	}
+	return engineIDs
+}
+
+func ignoreErrorModel(tableModel *checkpointspb.TableCheckpointModel) {
+	if tableModel.Status <= uint32(CheckpointStatusMaxInvalid) {
+		tableModel.Status = uint32(CheckpointStatusLoaded)
+	}
+	for _, engineModel := range tableModel.Engines {
+		if engineModel.Status <= uint32(CheckpointStatusMaxInvalid) {
+			engineModel.Status = uint32(CheckpointStatusLoaded)
+		}
+	}
+}
+
+func destroyedTableCheckpoint(tableName string, tableModel *checkpointspb.TableCheckpointModel) DestroyedTableCheckpoint {
+	var minEngineID, maxEngineID int32 = math.MaxInt32, math.MinInt32
+	for engineID := range tableModel.Engines {
+		if engineID < minEngineID {
+			minEngineID = engineID
+		}
+		if engineID > maxEngineID {
+			maxEngineID = engineID
+		}
+	}
+	return DestroyedTableCheckpoint{
+		TableName:   tableName,
+		MinEngineID: minEngineID,
+		MaxEngineID: maxEngineID,
+	}
+}
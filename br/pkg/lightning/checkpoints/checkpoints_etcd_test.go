@@ -0,0 +1,404 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkpoints_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/lightning/verification"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+func randomLocalURL(t *testing.T) url.URL {
+	l, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+	return url.URL{Scheme: "http", Host: addr}
+}
+
+// newEtcdConfig starts an embedded etcd and returns the config using it to store
+// the checkpoints.
+func newEtcdConfig(t *testing.T) *config.Config {
+	etcdCfg := embed.NewConfig()
+	etcdCfg.Dir = t.TempDir()
+	clientURL := randomLocalURL(t)
+	etcdCfg.LCUrls = []url.URL{clientURL}
+	etcdCfg.ACUrls = []url.URL{clientURL}
+	peerURL := randomLocalURL(t)
+	etcdCfg.LPUrls = []url.URL{peerURL}
+	etcdCfg.APUrls = []url.URL{peerURL}
+	etcdCfg.InitialCluster = etcdCfg.InitialClusterFromName(etcdCfg.Name)
+	etcdCfg.LogLevel = "fatal"
+	etcd, err := embed.StartEtcd(etcdCfg)
+	require.NoError(t, err)
+	t.Cleanup(etcd.Close)
+	<-etcd.Server.ReadyNotify()
+
+	cfg := newTestConfig()
+	cfg.Checkpoint.Enable = true
+	cfg.Checkpoint.Schema = "test_cp"
+	cfg.Checkpoint.Driver = config.CheckpointDriverEtcd
+	cfg.Checkpoint.DSN = clientURL.Host
+	return cfg
+}
+
+func TestEtcdCheckpointsDB(t *testing.T) {
+	ctx := context.Background()
+	cfg := newEtcdConfig(t)
+
+	exists, err := checkpoints.IsCheckpointsDBExists(ctx, cfg)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	cpdb, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
+	require.NoError(t, err)
+	defer cpdb.Close()
+
+	taskCp, err := cpdb.TaskCheckpoint(ctx)
+	require.NoError(t, err)
+	require.Nil(t, taskCp)
+
+	dbInfo := map[string]*checkpoints.TidbDBInfo{
+		"db1": {
+			Name: "db1",
+			Tables: map[string]*checkpoints.TidbTableInfo{
+				"t1": {Name: "t1", ID: 1},
+				"t2": {Name: "t2", ID: 2},
+			},
+		},
+	}
+	require.NoError(t, cpdb.Initialize(ctx, cfg, dbInfo))
+	exists, err = checkpoints.IsCheckpointsDBExists(ctx, cfg)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	taskCp, err = cpdb.TaskCheckpoint(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(123), taskCp.TaskID)
+	require.Equal(t, "/data", taskCp.SourceDir)
+	require.Equal(t, "127.0.0.1:2379", taskCp.PdAddr)
+
+	err = cpdb.InsertEngineCheckpoints(ctx, "`db1`.`t2`", map[int32]*checkpoints.EngineCheckpoint{
+		0: {
+			Status: checkpoints.CheckpointStatusLoaded,
+			Chunks: []*checkpoints.ChunkCheckpoint{{
+				Key: checkpoints.ChunkCheckpointKey{Path: "/tmp/path/1.sql"},
+				FileMeta: mydump.SourceFileMeta{
+					Path:     "/tmp/path/1.sql",
+					Type:     mydump.SourceTypeSQL,
+					FileSize: 12345,
+				},
+				Chunk: mydump.Chunk{
+					Offset:       12,
+					EndOffset:    102400,
+					PrevRowIDMax: 1,
+					RowIDMax:     5000,
+				},
+			}},
+		},
+		-1: {Status: checkpoints.CheckpointStatusLoaded},
+	})
+	require.NoError(t, err)
+
+	// the tables initialized before are kept.
+	require.NoError(t, cpdb.Initialize(ctx, cfg, dbInfo))
+
+	cpd := checkpoints.NewTableCheckpointDiff()
+	scm := checkpoints.StatusCheckpointMerger{EngineID: checkpoints.WholeTableEngineID, Status: checkpoints.CheckpointStatusAllWritten}
+	scm.MergeInto(cpd)
+	rcm := checkpoints.RebaseCheckpointMerger{AllocBase: 132861}
+	rcm.MergeInto(cpd)
+	ccm := checkpoints.ChunkCheckpointMerger{
+		EngineID: 0,
+		Key:      checkpoints.ChunkCheckpointKey{Path: "/tmp/path/1.sql"},
+		Checksum: verification.MakeKVChecksum(4491, 586, 486070148917),
+		Pos:      55904,
+		RowID:    681,
+	}
+	ccm.MergeInto(cpd)
+	require.NoError(t, cpdb.Update(ctx, map[string]*checkpoints.TableCheckpointDiff{"`db1`.`t2`": cpd}))
+
+	cp, err := cpdb.Get(ctx, "`db1`.`t2`")
+	require.NoError(t, err)
+	require.Equal(t, &checkpoints.TableCheckpoint{
+		Status:    checkpoints.CheckpointStatusAllWritten,
+		AllocBase: 132861,
+		TableID:   2,
+		Engines: map[int32]*checkpoints.EngineCheckpoint{
+			-1: {
+				Status: checkpoints.CheckpointStatusLoaded,
+				Chunks: []*checkpoints.ChunkCheckpoint{},
+			},
+			0: {
+				Status: checkpoints.CheckpointStatusLoaded,
+				Chunks: []*checkpoints.ChunkCheckpoint{{
+					Key: checkpoints.ChunkCheckpointKey{Path: "/tmp/path/1.sql"},
+					FileMeta: mydump.SourceFileMeta{
+						Path:     "/tmp/path/1.sql",
+						Type:     mydump.SourceTypeSQL,
+						FileSize: 12345,
+					},
+					ColumnPermutation: []int{},
+					Chunk: mydump.Chunk{
+						Offset:       55904,
+						EndOffset:    102400,
+						PrevRowIDMax: 681,
+						RowIDMax:     5000,
+					},
+					Checksum: verification.MakeKVChecksum(4491, 586, 486070148917),
+				}},
+			},
+		},
+	}, cp)
+	_, err = cpdb.Get(ctx, "`db1`.`t3`")
+	require.True(t, errors.IsNotFound(err))
+
+	tables, err := cpdb.GetLocalStoringTables(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]int32{"`db1`.`t2`": {0}}, tables)
+
+	// make the table failed, then ignore the error.
+	cpd = checkpoints.NewTableCheckpointDiff()
+	scm = checkpoints.StatusCheckpointMerger{EngineID: checkpoints.WholeTableEngineID, Status: checkpoints.CheckpointStatusAllWritten}
+	scm.SetInvalid()
+	scm.MergeInto(cpd)
+	require.NoError(t, cpdb.Update(ctx, map[string]*checkpoints.TableCheckpointDiff{"`db1`.`t2`": cpd}))
+	cp, err = cpdb.Get(ctx, "`db1`.`t2`")
+	require.NoError(t, err)
+	require.LessOrEqual(t, cp.Status, checkpoints.CheckpointStatusMaxInvalid)
+	require.NoError(t, cpdb.IgnoreErrorCheckpoint(ctx, "all"))
+	cp, err = cpdb.Get(ctx, "`db1`.`t2`")
+	require.NoError(t, err)
+	require.Equal(t, checkpoints.CheckpointStatusLoaded, cp.Status)
+
+	// make the table failed again, then destroy it.
+	require.NoError(t, cpdb.Update(ctx, map[string]*checkpoints.TableCheckpointDiff{"`db1`.`t2`": cpd}))
+	destroyed, err := cpdb.DestroyErrorCheckpoint(ctx, "all")
+	require.NoError(t, err)
+	require.Equal(t, []checkpoints.DestroyedTableCheckpoint{{TableName: "`db1`.`t2`", MinEngineID: -1, MaxEngineID: 0}}, destroyed)
+	_, err = cpdb.Get(ctx, "`db1`.`t2`")
+	require.True(t, errors.IsNotFound(err))
+
+	require.NoError(t, cpdb.MoveCheckpoints(ctx, 123))
+	exists, err = checkpoints.IsCheckpointsDBExists(ctx, cfg)
+	require.NoError(t, err)
+	require.False(t, exists)
+	cfg.Checkpoint.Schema = "test_cp.123.bak"
+	backup, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
+	require.NoError(t, err)
+	defer backup.Close()
+	cp, err = backup.Get(ctx, "`db1`.`t1`")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), cp.TableID)
+	taskCp, err = backup.TaskCheckpoint(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(123), taskCp.TaskID)
+
+	require.NoError(t, backup.RemoveCheckpoint(ctx, "`db1`.`t1`"))
+	_, err = backup.Get(ctx, "`db1`.`t1`")
+	require.True(t, errors.IsNotFound(err))
+	require.NoError(t, backup.RemoveCheckpoint(ctx, "all"))
+	exists, err = checkpoints.IsCheckpointsDBExists(ctx, cfg)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestEtcdCheckpointsDBConcurrentUpdate(t *testing.T) {
+	ctx := context.Background()
+	cfg := newEtcdConfig(t)
+
+	// every instance has its own client.
+	const concurrency = 8
+	cpdbs := make([]checkpoints.DB, 0, concurrency)
+	for i := 0; i < concurrency; i++ {
+		cpdb, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
+		require.NoError(t, err)
+		defer cpdb.Close()
+		cpdbs = append(cpdbs, cpdb)
+	}
+	err := cpdbs[0].Initialize(ctx, cfg, map[string]*checkpoints.TidbDBInfo{
+		"db": {Name: "db", Tables: map[string]*checkpoints.TidbTableInfo{"t": {Name: "t"}}},
+	})
+	require.NoError(t, err)
+	engines := make(map[int32]*checkpoints.EngineCheckpoint, concurrency)
+	for i := 0; i < concurrency; i++ {
+		engines[int32(i)] = &checkpoints.EngineCheckpoint{Status: checkpoints.CheckpointStatusLoaded}
+	}
+	require.NoError(t, cpdbs[0].InsertEngineCheckpoints(ctx, "`db`.`t`", engines))
+
+	var wg sync.WaitGroup
+	for i, cpdb := range cpdbs {
+		wg.Add(1)
+		go func(engineID int32, cpdb checkpoints.DB) {
+			defer wg.Done()
+			cpd := checkpoints.NewTableCheckpointDiff()
+			scm := checkpoints.StatusCheckpointMerger{EngineID: engineID, Status: checkpoints.CheckpointStatusImported}
+			scm.MergeInto(cpd)
+			require.NoError(t, cpdb.Update(ctx, map[string]*checkpoints.TableCheckpointDiff{"`db`.`t`": cpd}))
+		}(int32(i), cpdb)
+	}
+	wg.Wait()
+
+	cp, err := cpdbs[0].Get(ctx, "`db`.`t`")
+	require.NoError(t, err)
+	for engineID, engine := range cp.Engines {
+		require.Equal(t, checkpoints.CheckpointStatusImported, engine.Status, engineID)
+	}
+}
+
+func TestEtcdCheckpointsDBManyChunks(t *testing.T) {
+	ctx := context.Background()
+	cfg := newEtcdConfig(t)
+	cpdb, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
+	require.NoError(t, err)
+	defer cpdb.Close()
+	err = cpdb.Initialize(ctx, cfg, map[string]*checkpoints.TidbDBInfo{
+		"db": {Name: "db", Tables: map[string]*checkpoints.TidbTableInfo{"t": {Name: "t"}}},
+	})
+	require.NoError(t, err)
+
+	// the chunks are more than the operations allowed in an etcd transaction.
+	const chunkCount = 200
+	chunks := make([]*checkpoints.ChunkCheckpoint, 0, chunkCount)
+	for i := 0; i < chunkCount; i++ {
+		path := fmt.Sprintf("/tmp/path/%03d.csv", i)
+		chunks = append(chunks, &checkpoints.ChunkCheckpoint{
+			Key:      checkpoints.ChunkCheckpointKey{Path: path},
+			FileMeta: mydump.SourceFileMeta{Path: path, Type: mydump.SourceTypeCSV, FileSize: 100},
+			Chunk:    mydump.Chunk{EndOffset: 100},
+		})
+	}
+	err = cpdb.InsertEngineCheckpoints(ctx, "`db`.`t`", map[int32]*checkpoints.EngineCheckpoint{
+		0: {Status: checkpoints.CheckpointStatusLoaded, Chunks: chunks},
+	})
+	require.NoError(t, err)
+	err = cpdb.InsertEngineCheckpoints(ctx, "`db`.`t2`", map[int32]*checkpoints.EngineCheckpoint{
+		0: {Status: checkpoints.CheckpointStatusLoaded},
+	})
+	require.True(t, errors.IsNotFound(err))
+
+	cpd := checkpoints.NewTableCheckpointDiff()
+	for _, chunk := range chunks {
+		ccm := checkpoints.ChunkCheckpointMerger{EngineID: 0, Key: chunk.Key, Pos: 100}
+		ccm.MergeInto(cpd)
+	}
+	scm := checkpoints.StatusCheckpointMerger{EngineID: 0, Status: checkpoints.CheckpointStatusClosed}
+	scm.MergeInto(cpd)
+	require.NoError(t, cpdb.Update(ctx, map[string]*checkpoints.TableCheckpointDiff{"`db`.`t`": cpd}))
+
+	cp, err := cpdb.Get(ctx, "`db`.`t`")
+	require.NoError(t, err)
+	require.Equal(t, checkpoints.CheckpointStatusLoaded, cp.Status)
+	require.Len(t, cp.Engines, 1)
+	require.Equal(t, checkpoints.CheckpointStatusClosed, cp.Engines[0].Status)
+	require.Len(t, cp.Engines[0].Chunks, chunkCount)
+	for i, chunk := range cp.Engines[0].Chunks {
+		require.Equal(t, chunks[i].Key, chunk.Key)
+		require.Equal(t, int64(100), chunk.Chunk.Offset)
+	}
+
+	// the engines and chunks are stored in their own keys.
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{cfg.Checkpoint.DSN}})
+	require.NoError(t, err)
+	defer cli.Close()
+	prefix := checkpoints.EtcdCheckpointKeyPrefix + cfg.Checkpoint.Schema + "/"
+	resp, err := cli.Get(ctx, prefix+"tables/`db`.`t`")
+	require.NoError(t, err)
+	require.Len(t, resp.Kvs, 1)
+	require.Less(t, len(resp.Kvs[0].Value), 100)
+	resp, err = cli.Get(ctx, prefix+"chunks/`db`.`t`/0/", clientv3.WithPrefix(), clientv3.WithCountOnly())
+	require.NoError(t, err)
+	require.Equal(t, int64(chunkCount), resp.Count)
+
+	require.NoError(t, cpdb.RemoveCheckpoint(ctx, "`db`.`t`"))
+	resp, err = cli.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	require.NoError(t, err)
+	for _, kv := range resp.Kvs {
+		require.NotContains(t, string(kv.Key), "`db`.`t`")
+	}
+}
+
+func TestEtcdImportedFilesDB(t *testing.T) {
+	ctx := context.Background()
+	cfg := newEtcdConfig(t)
+
+	ifdb, err := checkpoints.OpenImportedFilesDB(ctx, cfg)
+	require.NoError(t, err)
+	defer ifdb.Close()
+
+	files := make(map[string]int64)
+	for i := 0; i < 100; i++ {
+		files[fmt.Sprintf("db.t.%d.csv", i)] = int64(i)
+	}
+	require.NoError(t, ifdb.AddImportedFiles(ctx, files))
+	require.NoError(t, ifdb.AddImportedFiles(ctx, map[string]int64{"db.t.0.csv": 1000}))
+	files["db.t.0.csv"] = 1000
+
+	imported, err := ifdb.ImportedFiles(ctx)
+	require.NoError(t, err)
+	require.Equal(t, files, imported)
+
+	// the imported files are kept after the checkpoints are removed.
+	cpdb, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
+	require.NoError(t, err)
+	defer cpdb.Close()
+	require.NoError(t, cpdb.RemoveCheckpoint(ctx, "all"))
+	imported, err = ifdb.ImportedFiles(ctx)
+	require.NoError(t, err)
+	require.Len(t, imported, 100)
+}
+
+func TestEtcdCreatedSchemaObjects(t *testing.T) {
+	ctx := context.Background()
+	cfg := newEtcdConfig(t)
+
+	cpdb, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
+	require.NoError(t, err)
+	defer cpdb.Close()
+
+	expected := make(map[string]struct{})
+	names := make([]string, 0, 100)
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("`db`.`t%d`", i)
+		names = append(names, name)
+		expected[name] = struct{}{}
+	}
+	require.NoError(t, cpdb.AddCreatedSchemaObjects(ctx, names))
+	created, err := cpdb.CreatedSchemaObjects(ctx)
+	require.NoError(t, err)
+	require.Equal(t, expected, created)
+
+	// the schema objects aren't mistaken for the table checkpoints.
+	tableNames, err := cpdb.TableNames(ctx)
+	require.NoError(t, err)
+	require.Empty(t, tableNames)
+
+	require.NoError(t, cpdb.RemoveCheckpoint(ctx, "all"))
+	created, err = cpdb.CreatedSchemaObjects(ctx)
+	require.NoError(t, err)
+	require.Empty(t, created)
+}
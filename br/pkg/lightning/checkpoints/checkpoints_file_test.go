@@ -2,6 +2,7 @@ package checkpoints_test
 
 import (
 	"context"
+	"os"
 	"path/filepath"
 	"sort"
 	"testing"
@@ -328,3 +329,102 @@ func TestDestroyOneErrorCheckpoint(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, checkpoints.CheckpointStatusAllWritten/10, cp.Status)
 }
+
+func TestCompressedFileCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cp.pb")
+	cfg := newTestConfig()
+
+	cpdb, err := checkpoints.NewFileCheckpointsDB(ctx, path)
+	require.NoError(t, err)
+	require.Regexp(t, "unsupported checkpoint compression 'gzip'", cpdb.SetCompression("gzip"))
+	require.NoError(t, cpdb.SetCompression(config.CheckpointCompressionZstd))
+	err = cpdb.Initialize(ctx, cfg, map[string]*checkpoints.TidbDBInfo{
+		"db1": {Name: "db1", Tables: map[string]*checkpoints.TidbTableInfo{"t1": {Name: "t1"}}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, cpdb.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x28, 0xb5, 0x2f, 0xfd}, content[:4])
+
+	// the compressed file can be read without setting the compression.
+	cpdb, err = checkpoints.NewFileCheckpointsDB(ctx, path)
+	require.NoError(t, err)
+	taskCp, err := cpdb.TaskCheckpoint(ctx)
+	require.NoError(t, err)
+	require.Equal(t, cfg.TaskID, taskCp.TaskID)
+	cp, err := cpdb.Get(ctx, "`db1`.`t1`")
+	require.NoError(t, err)
+	require.Equal(t, checkpoints.CheckpointStatusLoaded, cp.Status)
+	require.NoError(t, cpdb.Close())
+
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotEqual(t, []byte{0x28, 0xb5, 0x2f, 0xfd}, content[:4])
+}
+
+func TestFileImportedFilesDB(t *testing.T) {
+	ctx := context.Background()
+	cfg := newTestConfig()
+	cfg.Checkpoint.Enable = true
+	cfg.Checkpoint.Driver = config.CheckpointDriverFile
+	cfg.Checkpoint.DSN = filepath.Join(t.TempDir(), "cp.pb")
+
+	ifdb, err := checkpoints.OpenImportedFilesDB(ctx, cfg)
+	require.NoError(t, err)
+	files, err := ifdb.ImportedFiles(ctx)
+	require.NoError(t, err)
+	require.Empty(t, files)
+	require.NoError(t, ifdb.AddImportedFiles(ctx, map[string]int64{"db.t.1.csv": 10, "db.t.2.csv": 20}))
+	// the size of the grown file is updated.
+	require.NoError(t, ifdb.AddImportedFiles(ctx, map[string]int64{"db.t.2.csv": 25, "db.t.3.csv": 30}))
+	require.NoError(t, ifdb.Close())
+
+	// the imported files survive the removal of the checkpoints.
+	cpdb, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
+	require.NoError(t, err)
+	require.NoError(t, cpdb.Initialize(ctx, cfg, map[string]*checkpoints.TidbDBInfo{}))
+	require.NoError(t, cpdb.RemoveCheckpoint(ctx, "all"))
+	require.NoError(t, cpdb.Close())
+
+	ifdb, err = checkpoints.OpenImportedFilesDB(ctx, cfg)
+	require.NoError(t, err)
+	files, err = ifdb.ImportedFiles(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{
+		"db.t.1.csv": 10,
+		"db.t.2.csv": 25,
+		"db.t.3.csv": 30,
+	}, files)
+	require.NoError(t, ifdb.Close())
+}
+
+func TestFileCreatedSchemaObjects(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cp.pb")
+	cpdb, err := checkpoints.NewFileCheckpointsDB(ctx, path)
+	require.NoError(t, err)
+	names, err := cpdb.CreatedSchemaObjects(ctx)
+	require.NoError(t, err)
+	require.Empty(t, names)
+	require.NoError(t, cpdb.AddCreatedSchemaObjects(ctx, []string{"`db1`", "`db1`.`t1`"}))
+	require.NoError(t, cpdb.AddCreatedSchemaObjects(ctx, []string{"`db1`.`v1`"}))
+	require.NoError(t, cpdb.Close())
+
+	// the schema objects are kept for the next run until the checkpoints are removed.
+	cpdb, err = checkpoints.NewFileCheckpointsDB(ctx, path)
+	require.NoError(t, err)
+	names, err = cpdb.CreatedSchemaObjects(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"`db1`": {}, "`db1`.`t1`": {}, "`db1`.`v1`": {}}, names)
+	require.NoError(t, cpdb.Initialize(ctx, newTestConfig(), map[string]*checkpoints.TidbDBInfo{}))
+	require.NoError(t, cpdb.RemoveCheckpoint(ctx, "all"))
+	names, err = cpdb.CreatedSchemaObjects(ctx)
+	require.NoError(t, err)
+	require.Empty(t, names)
+	_, err = os.Stat(path + checkpoints.SchemaObjectsFileSuffix)
+	require.True(t, os.IsNotExist(err))
+	require.NoError(t, cpdb.Close())
+}
@@ -45,6 +45,9 @@ func newCPSQLSuite(t *testing.T) *cpSQLSuite {
 	s.mock.
 		ExpectExec("CREATE TABLE IF NOT EXISTS `mock-schema`\\.chunk_v\\d+ .+").
 		WillReturnResult(sqlmock.NewResult(5, 1))
+	s.mock.
+		ExpectExec("CREATE TABLE IF NOT EXISTS `mock-schema`\\.schema_v\\d+ .+").
+		WillReturnResult(sqlmock.NewResult(6, 1))
 
 	cpdb, err := checkpoints.NewMySQLCheckpointsDB(context.Background(), s.db, "mock-schema")
 	require.NoError(t, err)
@@ -520,6 +523,26 @@ func TestDump(t *testing.T) {
 	)
 }
 
+func TestCreatedSchemaObjects(t *testing.T) {
+	ctx := context.Background()
+	s := newCPSQLSuite(t)
+
+	s.mock.ExpectBegin()
+	insertStmt := s.mock.ExpectPrepare("INSERT IGNORE INTO `mock-schema`\\.schema_v\\d+ .+")
+	insertStmt.ExpectExec().WithArgs("`db1`").WillReturnResult(sqlmock.NewResult(1, 1))
+	insertStmt.ExpectExec().WithArgs("`db1`.`t1`").WillReturnResult(sqlmock.NewResult(2, 1))
+	s.mock.ExpectCommit()
+	require.NoError(t, s.cpdb.AddCreatedSchemaObjects(ctx, []string{"`db1`", "`db1`.`t1`"}))
+
+	s.mock.
+		ExpectQuery("SELECT object_name FROM `mock-schema`\\.schema_v\\d+").
+		WillReturnRows(sqlmock.NewRows([]string{"object_name"}).AddRow("`db1`").AddRow("`db1`.`t1`"))
+	names, err := s.cpdb.CreatedSchemaObjects(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]struct{}{"`db1`": {}, "`db1`.`t1`": {}}, names)
+	require.Nil(t, s.mock.ExpectationsWereMet())
+}
+
 func TestMoveCheckpoints(t *testing.T) {
 	ctx := context.Background()
 	s := newCPSQLSuite(t)
@@ -539,7 +562,43 @@ func TestMoveCheckpoints(t *testing.T) {
 	s.mock.
 		ExpectExec("RENAME TABLE `mock-schema`\\.task_v\\d+ TO `mock-schema\\.12345678\\.bak`\\.task_v\\d+").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.
+		ExpectExec("RENAME TABLE `mock-schema`\\.schema_v\\d+ TO `mock-schema\\.12345678\\.bak`\\.schema_v\\d+").
+		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	err := s.cpdb.MoveCheckpoints(ctx, 12345678)
 	require.NoError(t, err)
 }
+
+func TestMySQLImportedFilesDB(t *testing.T) {
+	ctx := context.Background()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	mock.ExpectExec("CREATE DATABASE IF NOT EXISTS `mock-schema_watch`").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS `mock-schema_watch`\\.imported_files_v\\d+ .+").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	ifdb, err := checkpoints.NewMySQLImportedFilesDB(ctx, db, "mock-schema"+checkpoints.ImportedFilesSchemaSuffix)
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	stmt := mock.ExpectPrepare("INSERT INTO `mock-schema_watch`\\.imported_files_v\\d+ \\(path, size\\) VALUES \\(\\?, \\?\\)\\s+" +
+		"ON DUPLICATE KEY UPDATE size = VALUES\\(size\\)")
+	stmt.ExpectExec().WithArgs("db.t.1.csv", 10).WillReturnResult(sqlmock.NewResult(3, 1))
+	stmt.ExpectExec().WithArgs("db.t.2.csv", 20).WillReturnResult(sqlmock.NewResult(4, 1))
+	mock.ExpectCommit()
+	require.NoError(t, ifdb.AddImportedFiles(ctx, map[string]int64{"db.t.1.csv": 10, "db.t.2.csv": 20}))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT path, size FROM `mock-schema_watch`\\.imported_files_v\\d+").
+		WillReturnRows(sqlmock.NewRows([]string{"path", "size"}).AddRow("db.t.1.csv", 10).AddRow("db.t.2.csv", 20))
+	mock.ExpectCommit()
+	files, err := ifdb.ImportedFiles(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"db.t.1.csv": 10, "db.t.2.csv": 20}, files)
+
+	mock.ExpectClose()
+	require.NoError(t, ifdb.Close())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,213 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkpoints
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/verification"
+)
+
+// ChunkRetryTarget identifies the chunks to be re-run by the next import.
+type ChunkRetryTarget struct {
+	// TableName is the quoted name of the table, e.g. "`db`.`tbl`".
+	TableName string
+	EngineID  int32
+	// Chunk is the chunk to be re-run, or nil to re-run all chunks of the engine.
+	Chunk *ChunkCheckpointKey
+}
+
+// String returns the target in the format accepted by ParseChunkRetryTarget.
+func (t *ChunkRetryTarget) String() string {
+	if t.Chunk == nil {
+		return fmt.Sprintf("%s:%d", t.TableName, t.EngineID)
+	}
+	return fmt.Sprintf("%s:%d:%s", t.TableName, t.EngineID, t.Chunk)
+}
+
+// ParseChunkRetryTarget parses the target in the format of
+// "`db`.`tbl`:engine_id" or "`db`.`tbl`:engine_id:path:offset", as shown in the
+// `checkpoint-dump` output.
+func ParseChunkRetryTarget(s string) (*ChunkRetryTarget, error) {
+	n, err := quotedTableNameLen(s)
+	if err != nil {
+		return nil, errors.Annotatef(err, "invalid chunk '%s'", s)
+	}
+	if n == len(s) || s[n] != ':' {
+		return nil, errors.Errorf("invalid chunk '%s', the engine ID is missing", s)
+	}
+	target := &ChunkRetryTarget{TableName: s[:n]}
+
+	rest := s[n+1:]
+	engineStr, chunkStr, hasChunk := strings.Cut(rest, ":")
+	engineID, err := strconv.ParseInt(engineStr, 10, 32)
+	if err != nil {
+		return nil, errors.Errorf("invalid chunk '%s', the engine ID '%s' is not a number", s, engineStr)
+	}
+	if engineID < 0 {
+		return nil, errors.Errorf("invalid chunk '%s', the index engine has no chunks", s)
+	}
+	target.EngineID = int32(engineID)
+	if !hasChunk {
+		return target, nil
+	}
+
+	sep := strings.LastIndexByte(chunkStr, ':')
+	if sep <= 0 {
+		return nil, errors.Errorf("invalid chunk '%s', the chunk should be 'path:offset'", s)
+	}
+	offset, err := strconv.ParseInt(chunkStr[sep+1:], 10, 64)
+	if err != nil {
+		return nil, errors.Errorf("invalid chunk '%s', the offset '%s' is not a number", s, chunkStr[sep+1:])
+	}
+	target.Chunk = &ChunkCheckpointKey{Path: chunkStr[:sep], Offset: offset}
+	return target, nil
+}
+
+// quotedTableNameLen returns the length of the "`db`.`tbl`" prefix of s.
+func quotedTableNameLen(s string) (int, error) {
+	i := 0
+	for part := 0; part < 2; part++ {
+		if part == 1 {
+			if i >= len(s) || s[i] != '.' {
+				return 0, errors.New("the table name should be '`db`.`tbl`'")
+			}
+			i++
+		}
+		if i >= len(s) || s[i] != '`' {
+			return 0, errors.New("the table name should be '`db`.`tbl`'")
+		}
+		i++
+		for {
+			end := strings.IndexByte(s[i:], '`')
+			if end < 0 {
+				return 0, errors.New("unterminated quoted name")
+			}
+			i += end + 1
+			// a doubled backquote is an escaped backquote in the name.
+			if i < len(s) && s[i] == '`' {
+				i++
+				continue
+			}
+			break
+		}
+	}
+	return i, nil
+}
+
+// RetryChunks resets the checkpoints of the target chunks to their start, so
+// the next import re-parses just those file ranges instead of the whole tables.
+// The engines containing the chunks, the index engines and the tables are reset
+// to the loaded status, which also clears the errors of the tables. It returns
+// the chunks which are reset.
+func RetryChunks(ctx context.Context, cpdb DB, targets []*ChunkRetryTarget) ([]*ChunkRetryTarget, error) {
+	tableTargets := make(map[string][]*ChunkRetryTarget)
+	var tableNames []string
+	for _, target := range targets {
+		if _, ok := tableTargets[target.TableName]; !ok {
+			tableNames = append(tableNames, target.TableName)
+		}
+		tableTargets[target.TableName] = append(tableTargets[target.TableName], target)
+	}
+
+	var retried []*ChunkRetryTarget
+	diffs := make(map[string]*TableCheckpointDiff, len(tableNames))
+	for _, tableName := range tableNames {
+		cp, err := cpdb.Get(ctx, tableName)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		cpd := NewTableCheckpointDiff()
+		for _, target := range tableTargets[tableName] {
+			engine, ok := cp.Engines[target.EngineID]
+			if !ok {
+				return nil, errors.NotFoundf("checkpoint for engine %d of table %s", target.EngineID, tableName)
+			}
+			found := false
+			for _, chunk := range engine.Chunks {
+				if target.Chunk != nil && chunk.Key != *target.Chunk {
+					continue
+				}
+				found = true
+				rowID, err := chunkStartRowID(cp, chunk)
+				if err != nil {
+					return nil, errors.Annotatef(err, "cannot retry chunk %s of table %s", &chunk.Key, tableName)
+				}
+				merger := &ChunkCheckpointMerger{
+					EngineID:          target.EngineID,
+					Key:               chunk.Key,
+					Checksum:          verification.MakeKVChecksum(0, 0, 0),
+					Pos:               chunk.Key.Offset,
+					RowID:             rowID,
+					ColumnPermutation: chunk.ColumnPermutation,
+				}
+				merger.MergeInto(cpd)
+				key := chunk.Key
+				retried = append(retried, &ChunkRetryTarget{TableName: tableName, EngineID: target.EngineID, Chunk: &key})
+			}
+			if !found {
+				return nil, errors.NotFoundf("checkpoint for chunk %s", target)
+			}
+			engineMerger := &StatusCheckpointMerger{EngineID: target.EngineID, Status: CheckpointStatusLoaded}
+			engineMerger.MergeInto(cpd)
+		}
+		// the index KVs of the chunks are written to the index engine again.
+		if _, ok := cp.Engines[indexEngineID]; ok {
+			indexMerger := &StatusCheckpointMerger{EngineID: indexEngineID, Status: CheckpointStatusLoaded}
+			indexMerger.MergeInto(cpd)
+		}
+		tableMerger := &StatusCheckpointMerger{EngineID: WholeTableEngineID, Status: CheckpointStatusLoaded}
+		tableMerger.MergeInto(cpd)
+		diffs[tableName] = cpd
+	}
+
+	if err := cpdb.Update(ctx, diffs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return retried, nil
+}
+
+// chunkStartRowID returns the row ID before the first row of the chunk, which
+// is not saved in the checkpoint once the chunk is partially imported. The row
+// IDs of the chunks of a table are allocated contiguously, so it's the RowIDMax
+// of the previous chunk. The rows must get the same row IDs when re-parsed, or
+// they are imported twice into the tables without a primary key.
+func chunkStartRowID(cp *TableCheckpoint, chunk *ChunkCheckpoint) (int64, error) {
+	if chunk.Chunk.Offset == chunk.Key.Offset {
+		return chunk.Chunk.PrevRowIDMax, nil
+	}
+	found := false
+	var rowID int64
+	for _, engine := range cp.Engines {
+		for _, other := range engine.Chunks {
+			if other == chunk || other.Chunk.RowIDMax > chunk.Chunk.PrevRowIDMax || other.Chunk.RowIDMax >= chunk.Chunk.RowIDMax {
+				continue
+			}
+			if !found || other.Chunk.RowIDMax > rowID {
+				rowID = other.Chunk.RowIDMax
+				found = true
+			}
+		}
+	}
+	if !found {
+		return 0, errors.New("the row ID of the first chunk of the table is unknown after it's partially imported, " +
+			"please use `--checkpoint-error-destroy` to re-import the whole table")
+	}
+	return rowID, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkpoints_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/lightning/verification"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChunkRetryTarget(t *testing.T) {
+	target, err := checkpoints.ParseChunkRetryTarget("`db`.`tbl`:1")
+	require.NoError(t, err)
+	require.Equal(t, &checkpoints.ChunkRetryTarget{TableName: "`db`.`tbl`", EngineID: 1}, target)
+	require.Equal(t, "`db`.`tbl`:1", target.String())
+
+	target, err = checkpoints.ParseChunkRetryTarget("`d:b`.`t``b.l`:0:s3://bucket/db.tbl.01.csv:1024")
+	require.NoError(t, err)
+	require.Equal(t, &checkpoints.ChunkRetryTarget{
+		TableName: "`d:b`.`t``b.l`",
+		EngineID:  0,
+		Chunk:     &checkpoints.ChunkCheckpointKey{Path: "s3://bucket/db.tbl.01.csv", Offset: 1024},
+	}, target)
+	require.Equal(t, "`d:b`.`t``b.l`:0:s3://bucket/db.tbl.01.csv:1024", target.String())
+
+	for _, s := range []string{
+		"db.tbl:1",
+		"`db`.`tbl`",
+		"`db`.`tbl:1",
+		"`db`.`tbl`:x",
+		"`db`.`tbl`:-1",
+		"`db`.`tbl`:1:/data/db.tbl.sql",
+		"`db`.`tbl`:1:/data/db.tbl.sql:x",
+	} {
+		_, err := checkpoints.ParseChunkRetryTarget(s)
+		require.Error(t, err, s)
+	}
+}
+
+func newChunkCheckpoint(path string, offset, endOffset, prevRowIDMax, rowIDMax int64) *checkpoints.ChunkCheckpoint {
+	return &checkpoints.ChunkCheckpoint{
+		Key:               checkpoints.ChunkCheckpointKey{Path: path, Offset: offset},
+		FileMeta:          mydump.SourceFileMeta{Path: path, Type: mydump.SourceTypeCSV, FileSize: 300},
+		ColumnPermutation: []int{0, 1, -1},
+		Chunk: mydump.Chunk{
+			Offset:       offset,
+			EndOffset:    endOffset,
+			PrevRowIDMax: prevRowIDMax,
+			RowIDMax:     rowIDMax,
+		},
+	}
+}
+
+func TestRetryChunks(t *testing.T) {
+	ctx := context.Background()
+	cpdb, err := checkpoints.NewFileCheckpointsDB(ctx, filepath.Join(t.TempDir(), "cp.pb"))
+	require.NoError(t, err)
+	defer cpdb.Close()
+
+	err = cpdb.Initialize(ctx, newTestConfig(), map[string]*checkpoints.TidbDBInfo{
+		"db": {Name: "db", Tables: map[string]*checkpoints.TidbTableInfo{"t": {Name: "t"}}},
+	})
+	require.NoError(t, err)
+	err = cpdb.InsertEngineCheckpoints(ctx, "`db`.`t`", map[int32]*checkpoints.EngineCheckpoint{
+		0: {
+			Status: checkpoints.CheckpointStatusLoaded,
+			Chunks: []*checkpoints.ChunkCheckpoint{
+				newChunkCheckpoint("/data/db.t.1.csv", 0, 100, 10, 20),
+				newChunkCheckpoint("/data/db.t.1.csv", 100, 200, 20, 30),
+			},
+		},
+		1: {
+			Status: checkpoints.CheckpointStatusLoaded,
+			Chunks: []*checkpoints.ChunkCheckpoint{
+				newChunkCheckpoint("/data/db.t.2.csv", 0, 300, 30, 60),
+			},
+		},
+		-1: {Status: checkpoints.CheckpointStatusLoaded},
+	})
+	require.NoError(t, err)
+
+	// all chunks are imported, and the table fails afterwards.
+	cpd := checkpoints.NewTableCheckpointDiff()
+	for _, m := range []checkpoints.TableCheckpointMerger{
+		&checkpoints.ChunkCheckpointMerger{
+			EngineID: 0, Key: checkpoints.ChunkCheckpointKey{Path: "/data/db.t.1.csv", Offset: 0},
+			Checksum: verification.MakeKVChecksum(1, 2, 3), Pos: 100, RowID: 18, ColumnPermutation: []int{0, 1, -1},
+		},
+		&checkpoints.ChunkCheckpointMerger{
+			EngineID: 0, Key: checkpoints.ChunkCheckpointKey{Path: "/data/db.t.1.csv", Offset: 100},
+			Checksum: verification.MakeKVChecksum(4, 5, 6), Pos: 200, RowID: 29, ColumnPermutation: []int{0, 1, -1},
+		},
+		&checkpoints.ChunkCheckpointMerger{
+			EngineID: 1, Key: checkpoints.ChunkCheckpointKey{Path: "/data/db.t.2.csv", Offset: 0},
+			Checksum: verification.MakeKVChecksum(7, 8, 9), Pos: 150, RowID: 45, ColumnPermutation: []int{0, 1, -1},
+		},
+		&checkpoints.StatusCheckpointMerger{EngineID: 0, Status: checkpoints.CheckpointStatusImported},
+		&checkpoints.StatusCheckpointMerger{EngineID: 1, Status: checkpoints.CheckpointStatusImported},
+		&checkpoints.StatusCheckpointMerger{EngineID: -1, Status: checkpoints.CheckpointStatusImported},
+		&checkpoints.StatusCheckpointMerger{EngineID: checkpoints.WholeTableEngineID, Status: checkpoints.CheckpointStatusChecksummed / 10},
+	} {
+		m.MergeInto(cpd)
+	}
+	require.NoError(t, cpdb.Update(ctx, map[string]*checkpoints.TableCheckpointDiff{"`db`.`t`": cpd}))
+
+	// the first chunk of the table can't be retried once it's partially imported.
+	_, err = checkpoints.RetryChunks(ctx, cpdb, []*checkpoints.ChunkRetryTarget{{TableName: "`db`.`t`", EngineID: 0}})
+	require.ErrorContains(t, err, "the row ID of the first chunk of the table is unknown")
+	_, err = checkpoints.RetryChunks(ctx, cpdb, []*checkpoints.ChunkRetryTarget{{TableName: "`db`.`t`", EngineID: 2}})
+	require.True(t, errors.IsNotFound(err))
+	_, err = checkpoints.RetryChunks(ctx, cpdb, []*checkpoints.ChunkRetryTarget{{
+		TableName: "`db`.`t`", EngineID: 0, Chunk: &checkpoints.ChunkCheckpointKey{Path: "/data/db.t.1.csv", Offset: 50},
+	}})
+	require.True(t, errors.IsNotFound(err))
+
+	retried, err := checkpoints.RetryChunks(ctx, cpdb, []*checkpoints.ChunkRetryTarget{
+		{TableName: "`db`.`t`", EngineID: 0, Chunk: &checkpoints.ChunkCheckpointKey{Path: "/data/db.t.1.csv", Offset: 100}},
+		{TableName: "`db`.`t`", EngineID: 1},
+	})
+	require.NoError(t, err)
+	require.Len(t, retried, 2)
+	require.Equal(t, "`db`.`t`:0:/data/db.t.1.csv:100", retried[0].String())
+	require.Equal(t, "`db`.`t`:1:/data/db.t.2.csv:0", retried[1].String())
+
+	cp, err := cpdb.Get(ctx, "`db`.`t`")
+	require.NoError(t, err)
+	require.Equal(t, checkpoints.CheckpointStatusLoaded, cp.Status)
+	require.Equal(t, checkpoints.CheckpointStatusLoaded, cp.Engines[-1].Status)
+	require.Equal(t, checkpoints.CheckpointStatusLoaded, cp.Engines[1].Status)
+	require.Equal(t, checkpoints.CheckpointStatusLoaded, cp.Engines[0].Status)
+
+	// the retried chunks start over with the same row IDs.
+	chunk := cp.Engines[0].Chunks[1]
+	require.Equal(t, int64(100), chunk.Chunk.Offset)
+	require.Equal(t, int64(20), chunk.Chunk.PrevRowIDMax)
+	require.Equal(t, verification.MakeKVChecksum(0, 0, 0), chunk.Checksum)
+	require.Equal(t, []int{0, 1, -1}, chunk.ColumnPermutation)
+	chunk = cp.Engines[1].Chunks[0]
+	require.Equal(t, int64(0), chunk.Chunk.Offset)
+	require.Equal(t, int64(30), chunk.Chunk.PrevRowIDMax)
+	require.Equal(t, verification.MakeKVChecksum(0, 0, 0), chunk.Checksum)
+
+	// the other chunks are kept.
+	chunk = cp.Engines[0].Chunks[0]
+	require.Equal(t, int64(100), chunk.Chunk.Offset)
+	require.Equal(t, int64(18), chunk.Chunk.PrevRowIDMax)
+	require.Equal(t, verification.MakeKVChecksum(1, 2, 3), chunk.Checksum)
+}
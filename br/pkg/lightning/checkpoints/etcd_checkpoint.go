@@ -0,0 +1,713 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkpoints
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints/checkpointspb"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// EtcdCheckpointKeyPrefix is the prefix of the keys storing the checkpoints
+	// with the etcd driver. The checkpoints of a schema are stored under
+	// `<prefix><schema>/`.
+	EtcdCheckpointKeyPrefix = "/tidb/lightning/checkpoints/"
+
+	etcdTaskKey             = "task"
+	etcdTablesPrefix        = "tables/"
+	etcdEnginesPrefix       = "engines/"
+	etcdChunksPrefix        = "chunks/"
+	etcdSchemaObjectsPrefix = "schema/"
+	// etcdTxnOpsLimit is the maximum number of operations in a transaction, which
+	// is lower than the default `max-txn-ops` (128) of etcd.
+	etcdTxnOpsLimit = 64
+	etcdDialTimeout = 5 * time.Second
+)
+
+// newEtcdClient connects to the etcd in the checkpoint DSN, which is a comma
+// separated list of the endpoints. The etcd embedded in PD is used if the DSN
+// is empty.
+func newEtcdClient(cfg *config.Config) (*clientv3.Client, error) {
+	endpoints := cfg.TiDB.PdAddr
+	if len(cfg.Checkpoint.DSN) > 0 {
+		endpoints = cfg.Checkpoint.DSN
+	}
+	if len(endpoints) == 0 {
+		return nil, errors.New("the etcd endpoints of the checkpoints are unknown, please set `checkpoint.dsn` or `tidb.pd-addr`")
+	}
+	tls, err := cfg.ToTLS()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cli, err := clientv3.New(clientv3.Config{
+		Endpoints:   strings.Split(endpoints, ","),
+		TLS:         tls.TLSConfig(),
+		DialTimeout: etcdDialTimeout,
+	})
+	return cli, errors.Trace(err)
+}
+
+func etcdSchemaPrefix(schema string) string {
+	return EtcdCheckpointKeyPrefix + schema + "/"
+}
+
+func isEtcdCheckpointsExists(ctx context.Context, cli *clientv3.Client, schema string) (bool, error) {
+	resp, err := cli.Get(ctx, etcdSchemaPrefix(schema), clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return resp.Count > 0, nil
+}
+
+// EtcdCheckpointsDB stores the checkpoints in etcd, so that the Lightning
+// instances of a distributed import can share them. The task checkpoint, the
+// checkpoint of every table and the checkpoints of its engines and chunks are
+// stored in their own keys, so no value grows with the number of the chunks
+// beyond the request size limit of etcd. The checkpoints are updated by
+// compare-and-swap since they may be updated by several instances at the same
+// time.
+//
+// The keys of a table `<table>` are:
+//   - `tables/<table>`: the table checkpoint without the engines.
+//   - `engines/<table>/<engineID>`: the engine checkpoint without the chunks.
+//   - `chunks/<table>/<engineID>/<chunk key>`: the chunk checkpoint.
+type EtcdCheckpointsDB struct {
+	cli    *clientv3.Client
+	schema string
+	prefix string
+}
+
+func NewEtcdCheckpointsDB(cli *clientv3.Client, schema string) *EtcdCheckpointsDB {
+	return &EtcdCheckpointsDB{
+		cli:    cli,
+		schema: schema,
+		prefix: etcdSchemaPrefix(schema),
+	}
+}
+
+func (cpdb *EtcdCheckpointsDB) taskKey() string {
+	return cpdb.prefix + etcdTaskKey
+}
+
+func (cpdb *EtcdCheckpointsDB) tablesPrefix() string {
+	return cpdb.prefix + etcdTablesPrefix
+}
+
+func (cpdb *EtcdCheckpointsDB) schemaObjectsPrefix() string {
+	return cpdb.prefix + etcdSchemaObjectsPrefix
+}
+
+func (cpdb *EtcdCheckpointsDB) tableKey(tableName string) string {
+	return cpdb.tablesPrefix() + tableName
+}
+
+// enginesPrefix returns the prefix of the keys of the engines of the table. The table
+// name is quoted, so the prefix of a table is never the prefix of another one's.
+func (cpdb *EtcdCheckpointsDB) enginesPrefix(tableName string) string {
+	return cpdb.prefix + etcdEnginesPrefix + tableName + "/"
+}
+
+func (cpdb *EtcdCheckpointsDB) engineKey(tableName string, engineID int32) string {
+	return cpdb.enginesPrefix(tableName) + strconv.Itoa(int(engineID))
+}
+
+func (cpdb *EtcdCheckpointsDB) chunksPrefix(tableName string) string {
+	return cpdb.prefix + etcdChunksPrefix + tableName + "/"
+}
+
+func (cpdb *EtcdCheckpointsDB) engineChunksPrefix(tableName string, engineID int32) string {
+	return cpdb.chunksPrefix(tableName) + strconv.Itoa(int(engineID)) + "/"
+}
+
+func (cpdb *EtcdCheckpointsDB) chunkKey(tableName string, engineID int32, key string) string {
+	return cpdb.engineChunksPrefix(tableName, engineID) + key
+}
+
+func parseEngineID(s string) (int32, error) {
+	engineID, err := strconv.ParseInt(s, 10, 32)
+	return int32(engineID), errors.Trace(err)
+}
+
+func unmarshalTableModel(data []byte) (*checkpointspb.TableCheckpointModel, error) {
+	tableModel := &checkpointspb.TableCheckpointModel{}
+	if err := tableModel.Unmarshal(data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// the empty maps become nil after the round trip.
+	if tableModel.Engines == nil {
+		tableModel.Engines = map[int32]*checkpointspb.EngineCheckpointModel{}
+	}
+	return tableModel, nil
+}
+
+func unmarshalEngineModel(data []byte) (*checkpointspb.EngineCheckpointModel, error) {
+	engineModel := &checkpointspb.EngineCheckpointModel{}
+	if err := engineModel.Unmarshal(data); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if engineModel.Chunks == nil {
+		engineModel.Chunks = map[string]*checkpointspb.ChunkCheckpointModel{}
+	}
+	return engineModel, nil
+}
+
+func (cpdb *EtcdCheckpointsDB) Initialize(ctx context.Context, cfg *config.Config, dbInfo map[string]*TidbDBInfo) error {
+	taskModel, err := newTaskCheckpointModel(cfg).Marshal()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := cpdb.cli.Put(ctx, cpdb.taskKey(), string(taskModel)); err != nil {
+		return errors.Trace(err)
+	}
+
+	for _, db := range dbInfo {
+		for _, table := range db.Tables {
+			tableModel, err := newTableCheckpointModel(table.ID).Marshal()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			// the table may have been initialized by another instance.
+			key := cpdb.tableKey(common.UniqueTable(db.Name, table.Name))
+			_, err = cpdb.cli.Txn(ctx).
+				If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+				Then(clientv3.OpPut(key, string(tableModel))).
+				Commit()
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return nil
+}
+
+func (cpdb *EtcdCheckpointsDB) TaskCheckpoint(ctx context.Context) (*TaskCheckpoint, error) {
+	resp, err := cpdb.cli.Get(ctx, cpdb.taskKey())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+	taskModel := &checkpointspb.TaskCheckpointModel{}
+	if err := taskModel.Unmarshal(resp.Kvs[0].Value); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return taskCheckpointFromModel(taskModel), nil
+}
+
+func (cpdb *EtcdCheckpointsDB) Close() error {
+	return errors.Trace(cpdb.cli.Close())
+}
+
+// loadTableModel loads the checkpoint of the table along with the checkpoints of its
+// engines and chunks, which are all read at the same revision.
+func (cpdb *EtcdCheckpointsDB) loadTableModel(ctx context.Context, tableName string) (*checkpointspb.TableCheckpointModel, error) {
+	enginesPrefix, chunksPrefix := cpdb.enginesPrefix(tableName), cpdb.chunksPrefix(tableName)
+	resp, err := cpdb.cli.Txn(ctx).Then(
+		clientv3.OpGet(cpdb.tableKey(tableName)),
+		clientv3.OpGet(enginesPrefix, clientv3.WithPrefix()),
+		clientv3.OpGet(chunksPrefix, clientv3.WithPrefix()),
+	).Commit()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tableKVs := resp.Responses[0].GetResponseRange().Kvs
+	if len(tableKVs) == 0 {
+		return nil, errors.NotFoundf("checkpoint for table %s", tableName)
+	}
+	tableModel, err := unmarshalTableModel(tableKVs[0].Value)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, kv := range resp.Responses[1].GetResponseRange().Kvs {
+		engineID, err := parseEngineID(strings.TrimPrefix(string(kv.Key), enginesPrefix))
+		if err != nil {
+			return nil, errors.Annotatef(err, "checkpoint %s is broken", kv.Key)
+		}
+		engineModel, err := unmarshalEngineModel(kv.Value)
+		if err != nil {
+			return nil, errors.Annotatef(err, "checkpoint %s is broken", kv.Key)
+		}
+		tableModel.Engines[engineID] = engineModel
+	}
+	for _, kv := range resp.Responses[2].GetResponseRange().Kvs {
+		engineIDStr, key, ok := strings.Cut(strings.TrimPrefix(string(kv.Key), chunksPrefix), "/")
+		if !ok {
+			return nil, errors.Errorf("checkpoint %s is broken", kv.Key)
+		}
+		engineID, err := parseEngineID(engineIDStr)
+		if err != nil {
+			return nil, errors.Annotatef(err, "checkpoint %s is broken", kv.Key)
+		}
+		engineModel, ok := tableModel.Engines[engineID]
+		if !ok {
+			// the chunks are inserted before their engine, skip the ones whose engine
+			// failed to be inserted.
+			continue
+		}
+		chunkModel := &checkpointspb.ChunkCheckpointModel{}
+		if err := chunkModel.Unmarshal(kv.Value); err != nil {
+			return nil, errors.Annotatef(err, "checkpoint %s is broken", kv.Key)
+		}
+		engineModel.Chunks[key] = chunkModel
+	}
+	return tableModel, nil
+}
+
+func (cpdb *EtcdCheckpointsDB) Get(ctx context.Context, tableName string) (*TableCheckpoint, error) {
+	tableModel, err := cpdb.loadTableModel(ctx, tableName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return tableCheckpointFromModel(tableModel), nil
+}
+
+// engineIDs returns the IDs of the engines of the table.
+func (cpdb *EtcdCheckpointsDB) engineIDs(ctx context.Context, tableName string) ([]int32, error) {
+	prefix := cpdb.enginesPrefix(tableName)
+	resp, err := cpdb.cli.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	engineIDs := make([]int32, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		engineID, err := parseEngineID(strings.TrimPrefix(string(kv.Key), prefix))
+		if err != nil {
+			return nil, errors.Annotatef(err, "checkpoint %s is broken", kv.Key)
+		}
+		engineIDs = append(engineIDs, engineID)
+	}
+	return engineIDs, nil
+}
+
+// casUpdate applies the update to the values of the keys, and retries if any of
+// them is modified by others concurrently. The keys are updated in batches, each
+// of which is updated atomically.
+func (cpdb *EtcdCheckpointsDB) casUpdate(
+	ctx context.Context,
+	keys []string,
+	update func(key string, value []byte) ([]byte, error),
+) error {
+	for i := 0; i < len(keys); i += etcdTxnOpsLimit {
+		end := i + etcdTxnOpsLimit
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if err := cpdb.casUpdateBatch(ctx, keys[i:end], update); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (cpdb *EtcdCheckpointsDB) casUpdateBatch(
+	ctx context.Context,
+	keys []string,
+	update func(key string, value []byte) ([]byte, error),
+) error {
+	gets := make([]clientv3.Op, 0, len(keys))
+	for _, key := range keys {
+		gets = append(gets, clientv3.OpGet(key))
+	}
+	for {
+		resp, err := cpdb.cli.Txn(ctx).Then(gets...).Commit()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		cmps := make([]clientv3.Cmp, 0, len(keys))
+		puts := make([]clientv3.Op, 0, len(keys))
+		for i, key := range keys {
+			kvs := resp.Responses[i].GetResponseRange().Kvs
+			if len(kvs) == 0 {
+				return errors.NotFoundf("checkpoint %s", key)
+			}
+			value, err := update(key, kvs[0].Value)
+			if err != nil {
+				return errors.Annotatef(err, "checkpoint %s is broken", key)
+			}
+			cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(key), "=", kvs[0].ModRevision))
+			puts = append(puts, clientv3.OpPut(key, string(value)))
+		}
+		txnResp, err := cpdb.cli.Txn(ctx).If(cmps...).Then(puts...).Commit()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if txnResp.Succeeded {
+			return nil
+		}
+		log.FromContext(ctx).Debug("checkpoints are modified concurrently, retrying", zap.Strings("keys", keys))
+	}
+}
+
+// updateTable applies the update to the checkpoint of the table, which has no
+// engines.
+func (cpdb *EtcdCheckpointsDB) updateTable(
+	ctx context.Context,
+	tableName string,
+	update func(*checkpointspb.TableCheckpointModel),
+) error {
+	return cpdb.casUpdate(ctx, []string{cpdb.tableKey(tableName)}, func(_ string, value []byte) ([]byte, error) {
+		tableModel, err := unmarshalTableModel(value)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		update(tableModel)
+		return tableModel.Marshal()
+	})
+}
+
+// updateEngines applies the update to the checkpoints of the engines of the table,
+// which have no chunks.
+func (cpdb *EtcdCheckpointsDB) updateEngines(
+	ctx context.Context,
+	tableName string,
+	engineIDs []int32,
+	update func(engineID int32, engineModel *checkpointspb.EngineCheckpointModel),
+) error {
+	keys := make([]string, 0, len(engineIDs))
+	idByKey := make(map[string]int32, len(engineIDs))
+	for _, engineID := range engineIDs {
+		key := cpdb.engineKey(tableName, engineID)
+		keys = append(keys, key)
+		idByKey[key] = engineID
+	}
+	return cpdb.casUpdate(ctx, keys, func(key string, value []byte) ([]byte, error) {
+		engineModel, err := unmarshalEngineModel(value)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		update(idByKey[key], engineModel)
+		return engineModel.Marshal()
+	})
+}
+
+// InsertEngineCheckpoints implements the DB interface. The chunks of an engine are
+// inserted before the engine itself, so the engine is never seen with only a part of
+// its chunks if the insertion is interrupted.
+func (cpdb *EtcdCheckpointsDB) InsertEngineCheckpoints(ctx context.Context, tableName string, checkpoints map[int32]*EngineCheckpoint) error {
+	tableKey := cpdb.tableKey(tableName)
+	commit := func(ops []clientv3.Op) error {
+		resp, err := cpdb.cli.Txn(ctx).
+			If(clientv3.Compare(clientv3.CreateRevision(tableKey), "!=", 0)).
+			Then(ops...).
+			Commit()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !resp.Succeeded {
+			return errors.NotFoundf("checkpoint for table %s", tableName)
+		}
+		return nil
+	}
+
+	tableModel := &checkpointspb.TableCheckpointModel{Engines: map[int32]*checkpointspb.EngineCheckpointModel{}}
+	insertEngineCheckpointModels(tableModel, checkpoints)
+	for engineID, engineModel := range tableModel.Engines {
+		// remove the chunks left by an interrupted insertion, etcd rejects deleting
+		// and putting the same key in a transaction.
+		ops := []clientv3.Op{clientv3.OpDelete(cpdb.engineChunksPrefix(tableName, engineID), clientv3.WithPrefix())}
+		if err := commit(ops); err != nil {
+			return errors.Trace(err)
+		}
+		ops = ops[:0]
+		for key, chunkModel := range engineModel.Chunks {
+			data, err := chunkModel.Marshal()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			ops = append(ops, clientv3.OpPut(cpdb.chunkKey(tableName, engineID, key), string(data)))
+			if len(ops) == etcdTxnOpsLimit {
+				if err := commit(ops); err != nil {
+					return errors.Trace(err)
+				}
+				ops = ops[:0]
+			}
+		}
+		engineModel.Chunks = nil
+		data, err := engineModel.Marshal()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ops = append(ops, clientv3.OpPut(cpdb.engineKey(tableName, engineID), string(data)))
+		if err := commit(ops); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (cpdb *EtcdCheckpointsDB) Update(taskCtx context.Context, checkpointDiffs map[string]*TableCheckpointDiff) error {
+	for tableName, cpd := range checkpointDiffs {
+		if err := cpdb.updateTableDiff(taskCtx, tableName, cpd); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// updateTableDiff applies the diff to the checkpoints of the chunks, the engines and
+// then the table, so the status of an engine or the table is never ahead of the
+// progress of the chunks.
+func (cpdb *EtcdCheckpointsDB) updateTableDiff(ctx context.Context, tableName string, cpd *TableCheckpointDiff) error {
+	var chunkKeys []string
+	chunkDiffs := make(map[string]chunkCheckpointDiff)
+	var engineIDs []int32
+	for engineID, engineDiff := range cpd.engines {
+		if engineDiff.hasStatus {
+			engineIDs = append(engineIDs, engineID)
+		}
+		for chunkKey, diff := range engineDiff.chunks {
+			key := cpdb.chunkKey(tableName, engineID, chunkKey.String())
+			chunkKeys = append(chunkKeys, key)
+			chunkDiffs[key] = diff
+		}
+	}
+
+	err := cpdb.casUpdate(ctx, chunkKeys, func(key string, value []byte) ([]byte, error) {
+		chunkModel := &checkpointspb.ChunkCheckpointModel{}
+		if err := chunkModel.Unmarshal(value); err != nil {
+			return nil, errors.Trace(err)
+		}
+		applyChunkDiff(chunkModel, chunkDiffs[key])
+		return chunkModel.Marshal()
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = cpdb.updateEngines(ctx, tableName, engineIDs, func(engineID int32, engineModel *checkpointspb.EngineCheckpointModel) {
+		engineModel.Status = uint32(cpd.engines[engineID].status)
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !cpd.hasStatus && !cpd.hasRebase && !cpd.hasChecksum {
+		return nil
+	}
+	return cpdb.updateTable(ctx, tableName, func(tableModel *checkpointspb.TableCheckpointModel) {
+		applyTableDiff(tableModel, cpd)
+	})
+}
+
+// tableModels returns the checkpoints of all tables by the table names, the engines
+// of the tables are not loaded.
+func (cpdb *EtcdCheckpointsDB) tableModels(ctx context.Context) (map[string]*checkpointspb.TableCheckpointModel, error) {
+	prefix := cpdb.tablesPrefix()
+	resp, err := cpdb.cli.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tableModels := make(map[string]*checkpointspb.TableCheckpointModel, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		tableModel, err := unmarshalTableModel(kv.Value)
+		if err != nil {
+			return nil, errors.Annotatef(err, "checkpoint %s is broken", kv.Key)
+		}
+		tableModels[strings.TrimPrefix(string(kv.Key), prefix)] = tableModel
+	}
+	return tableModels, nil
+}
+
+func (cpdb *EtcdCheckpointsDB) RemoveCheckpoint(ctx context.Context, tableName string) error {
+	var err error
+	if tableName == allTables {
+		_, err = cpdb.cli.Delete(ctx, cpdb.prefix, clientv3.WithPrefix())
+	} else {
+		_, err = cpdb.cli.Txn(ctx).Then(
+			clientv3.OpDelete(cpdb.tableKey(tableName)),
+			clientv3.OpDelete(cpdb.enginesPrefix(tableName), clientv3.WithPrefix()),
+			clientv3.OpDelete(cpdb.chunksPrefix(tableName), clientv3.WithPrefix()),
+		).Commit()
+	}
+	return errors.Trace(err)
+}
+
+// MoveCheckpoints copies the checkpoints to the schema `<schema>.<taskID>.bak`
+// and then removes them.
+func (cpdb *EtcdCheckpointsDB) MoveCheckpoints(ctx context.Context, taskID int64) error {
+	resp, err := cpdb.cli.Get(ctx, cpdb.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	newPrefix := etcdSchemaPrefix(fmt.Sprintf("%s.%d.bak", cpdb.schema, taskID))
+	for i := 0; i < len(resp.Kvs); i += etcdTxnOpsLimit {
+		end := i + etcdTxnOpsLimit
+		if end > len(resp.Kvs) {
+			end = len(resp.Kvs)
+		}
+		ops := make([]clientv3.Op, 0, end-i)
+		for _, kv := range resp.Kvs[i:end] {
+			key := newPrefix + strings.TrimPrefix(string(kv.Key), cpdb.prefix)
+			ops = append(ops, clientv3.OpPut(key, string(kv.Value)))
+		}
+		if _, err := cpdb.cli.Txn(ctx).Then(ops...).Commit(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return cpdb.RemoveCheckpoint(ctx, allTables)
+}
+
+func (cpdb *EtcdCheckpointsDB) GetLocalStoringTables(ctx context.Context) (map[string][]int32, error) {
+	tableModels, err := cpdb.tableModels(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	targetTables := make(map[string][]int32)
+	for tableName, tableModel := range tableModels {
+		// only load the engines and chunks of the tables which may be stored locally.
+		if tableModel.Status <= uint32(CheckpointStatusMaxInvalid) ||
+			tableModel.Status >= uint32(CheckpointStatusIndexImported) {
+			continue
+		}
+		tableModel, err = cpdb.loadTableModel(ctx, tableName)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if engineIDs := localStoringEngines(tableModel); len(engineIDs) > 0 {
+			targetTables[tableName] = engineIDs
+		}
+	}
+	return targetTables, nil
+}
+
+func (cpdb *EtcdCheckpointsDB) TableNames(ctx context.Context) ([]string, error) {
+	tableModels, err := cpdb.tableModels(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tableNames := maps.Keys(tableModels)
+	slices.Sort(tableNames)
+	return tableNames, nil
+}
+
+func (cpdb *EtcdCheckpointsDB) CreatedSchemaObjects(ctx context.Context) (map[string]struct{}, error) {
+	prefix := cpdb.schemaObjectsPrefix()
+	resp, err := cpdb.cli.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	names := make(map[string]struct{}, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		names[strings.TrimPrefix(string(kv.Key), prefix)] = struct{}{}
+	}
+	return names, nil
+}
+
+func (cpdb *EtcdCheckpointsDB) AddCreatedSchemaObjects(ctx context.Context, names []string) error {
+	prefix := cpdb.schemaObjectsPrefix()
+	for i := 0; i < len(names); i += etcdTxnOpsLimit {
+		end := i + etcdTxnOpsLimit
+		if end > len(names) {
+			end = len(names)
+		}
+		ops := make([]clientv3.Op, 0, end-i)
+		for _, name := range names[i:end] {
+			ops = append(ops, clientv3.OpPut(prefix+name, ""))
+		}
+		if _, err := cpdb.cli.Txn(ctx).Then(ops...).Commit(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (cpdb *EtcdCheckpointsDB) IgnoreErrorCheckpoint(ctx context.Context, targetTableName string) error {
+	tableNames := []string{targetTableName}
+	if targetTableName == allTables {
+		tableModels, err := cpdb.tableModels(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		tableNames = maps.Keys(tableModels)
+	}
+	for _, tableName := range tableNames {
+		err := cpdb.updateTable(ctx, tableName, ignoreErrorModel)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return errors.Trace(err)
+		}
+		engineIDs, err := cpdb.engineIDs(ctx, tableName)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		err = cpdb.updateEngines(ctx, tableName, engineIDs, func(_ int32, engineModel *checkpointspb.EngineCheckpointModel) {
+			if engineModel.Status <= uint32(CheckpointStatusMaxInvalid) {
+				engineModel.Status = uint32(CheckpointStatusLoaded)
+			}
+		})
+		if err != nil && !errors.IsNotFound(err) {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (cpdb *EtcdCheckpointsDB) DestroyErrorCheckpoint(ctx context.Context, targetTableName string) ([]DestroyedTableCheckpoint, error) {
+	tableModels, err := cpdb.tableModels(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var targetTables []DestroyedTableCheckpoint
+	for tableName, tableModel := range tableModels {
+		if !(targetTableName == allTables || targetTableName == tableName) {
+			continue
+		}
+		if tableModel.Status <= uint32(CheckpointStatusMaxInvalid) {
+			engineIDs, err := cpdb.engineIDs(ctx, tableName)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			for _, engineID := range engineIDs {
+				tableModel.Engines[engineID] = &checkpointspb.EngineCheckpointModel{}
+			}
+			targetTables = append(targetTables, destroyedTableCheckpoint(tableName, tableModel))
+		}
+	}
+
+	for _, dtcp := range targetTables {
+		if err := cpdb.RemoveCheckpoint(ctx, dtcp.TableName); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return targetTables, nil
+}
+
+func (cpdb *EtcdCheckpointsDB) DumpTables(context.Context, io.Writer) error {
+	return errors.Errorf("dumping etcd checkpoint into CSV is unsupported, you may read the keys under %s instead", cpdb.prefix)
+}
+
+func (cpdb *EtcdCheckpointsDB) DumpEngines(context.Context, io.Writer) error {
+	return errors.Errorf("dumping etcd checkpoint into CSV is unsupported, you may read the keys under %s instead", cpdb.prefix)
+}
+
+func (cpdb *EtcdCheckpointsDB) DumpChunks(context.Context, io.Writer) error {
+	return errors.Errorf("dumping etcd checkpoint into CSV is unsupported, you may read the keys under %s instead", cpdb.prefix)
+}
@@ -116,6 +116,15 @@ func NewGlueCheckpointsDB(ctx context.Context, se Session, f func() (Session, er
 		return nil, errors.Trace(err)
 	}
 
+	sql = fmt.Sprintf(CreateSchemaTableTemplate, schema, CheckpointTableNameSchema)
+	err = common.Retry("create schema checkpoints table", logger, func() error {
+		_, err := se.Execute(ctx, sql)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
 	return &GlueCheckpointsDB{
 		getSessionFunc: f,
 		schema:         schema,
@@ -560,6 +569,7 @@ func (g GlueCheckpointsDB) MoveCheckpoints(ctx context.Context, taskID int64) er
 	for _, tbl := range []string{
 		CheckpointTableNameChunk, CheckpointTableNameEngine,
 		CheckpointTableNameTable, CheckpointTableNameTask,
+		CheckpointTableNameSchema,
 	} {
 		query := fmt.Sprintf("RENAME TABLE %[1]s.%[3]s TO %[2]s.%[3]s", g.schema, newSchema, tbl)
 		err := common.Retry(fmt.Sprintf("move %s checkpoints table", tbl), logger, func() error {
@@ -623,6 +633,88 @@ func (g GlueCheckpointsDB) GetLocalStoringTables(ctx context.Context) (map[strin
 	return targetTables, err
 }
 
+func (g GlueCheckpointsDB) TableNames(ctx context.Context) ([]string, error) {
+	se, err := g.getSessionFunc()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer se.Close()
+
+	var tableNames []string
+	query := fmt.Sprintf("SELECT table_name FROM %s.%s ORDER BY table_name;", g.schema, CheckpointTableNameTable)
+	err = common.Retry("get checkpoint table names", log.FromContext(ctx), func() error {
+		rs, err := se.Execute(ctx, query)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		rows, err := drainFirstRecordSet(ctx, rs)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		tableNames = make([]string, 0, len(rows))
+		for _, row := range rows {
+			tableNames = append(tableNames, row.GetString(0))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return tableNames, nil
+}
+
+func (g GlueCheckpointsDB) CreatedSchemaObjects(ctx context.Context) (map[string]struct{}, error) {
+	se, err := g.getSessionFunc()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer se.Close()
+
+	var names map[string]struct{}
+	query := fmt.Sprintf(ReadSchemaObjectsTemplate, g.schema, CheckpointTableNameSchema)
+	err = common.Retry("get created schema objects", log.FromContext(ctx), func() error {
+		rs, err := se.Execute(ctx, query)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		rows, err := drainFirstRecordSet(ctx, rs)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		names = make(map[string]struct{}, len(rows))
+		for _, row := range rows {
+			names[row.GetString(0)] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return names, nil
+}
+
+func (g GlueCheckpointsDB) AddCreatedSchemaObjects(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	logger := log.FromContext(ctx).With(zap.Int("count", len(names)))
+	se, err := g.getSessionFunc()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer se.Close()
+
+	query := fmt.Sprintf(InsertSchemaObjectTemplate, g.schema, CheckpointTableNameSchema)
+	return errors.Trace(Transact(ctx, "add created schema objects", se, logger, func(c context.Context, s Session) error {
+		for _, name := range names {
+			if _, e := s.Execute(c, strings.ReplaceAll(query, "?", common.InterpolateMySQLString(name))); e != nil {
+				return e
+			}
+		}
+		return nil
+	}))
+}
+
 func (g GlueCheckpointsDB) IgnoreErrorCheckpoint(ctx context.Context, tableName string) error {
 	logger := log.FromContext(ctx).With(zap.String("table", tableName))
 	se, err := g.getSessionFunc()
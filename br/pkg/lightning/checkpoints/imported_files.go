@@ -0,0 +1,331 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkpoints
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// ImportedFilesSchemaSuffix is the suffix of the schema storing the imported files
+	// with the MySQL and etcd drivers. The imported files are kept in a separated schema since
+	// the checkpoints schema is dropped after every successful import.
+	ImportedFilesSchemaSuffix = "_watch"
+	// CheckpointTableNameImportedFiles is the table name of the imported files.
+	CheckpointTableNameImportedFiles = "imported_files_v2"
+	// ImportedFilesFileSuffix is the suffix of the file storing the imported files
+	// with the file driver.
+	ImportedFilesFileSuffix = ".imported"
+
+	CreateImportedFilesTableTemplate = `
+		CREATE TABLE IF NOT EXISTS %s.%s (
+			path varchar(2048) NOT NULL,
+			size bigint NOT NULL,
+			create_time timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY(path(500))
+		);`
+	ReadImportedFilesTemplate   = "SELECT path, size FROM %s.%s;"
+	InsertImportedFilesTemplate = `
+		INSERT INTO %s.%s (path, size) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE size = VALUES(size);`
+)
+
+// ImportedFilesDB records the source files which have been imported along with
+// their imported sizes. It is used by the watch mode to find out the newly arrived
+// files and the data appended to the imported files.
+type ImportedFilesDB interface {
+	// ImportedFiles returns the imported size of the imported files by path.
+	ImportedFiles(ctx context.Context) (map[string]int64, error)
+	// AddImportedFiles records the files as imported up to the given sizes.
+	AddImportedFiles(ctx context.Context, files map[string]int64) error
+	Close() error
+}
+
+// OpenImportedFilesDB opens the imported files DB along with the checkpoints.
+func OpenImportedFilesDB(ctx context.Context, cfg *config.Config) (ImportedFilesDB, error) {
+	if !cfg.Checkpoint.Enable {
+		return NewNullImportedFilesDB(), nil
+	}
+
+	switch cfg.Checkpoint.Driver {
+	case config.CheckpointDriverMySQL:
+		db, err := common.ConnectMySQL(cfg.Checkpoint.DSN)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ifdb, err := NewMySQLImportedFilesDB(ctx, db, cfg.Checkpoint.Schema+ImportedFilesSchemaSuffix)
+		if err != nil {
+			_ = db.Close()
+			return nil, errors.Trace(err)
+		}
+		return ifdb, nil
+
+	case config.CheckpointDriverFile:
+		s, fileName, err := createExstorageByCompletePath(ctx, cfg.Checkpoint.DSN)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if fileName == "" {
+			return nil, errors.Errorf("the checkpoint DSN '%s' must not be a directory", cfg.Checkpoint.DSN)
+		}
+		return NewFileImportedFilesDB(ctx, s, fileName+ImportedFilesFileSuffix)
+
+	case config.CheckpointDriverEtcd:
+		cli, err := newEtcdClient(cfg)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return NewEtcdImportedFilesDB(cli, cfg.Checkpoint.Schema+ImportedFilesSchemaSuffix), nil
+
+	default:
+		return nil, common.ErrUnknownCheckpointDriver.GenWithStackByArgs(cfg.Checkpoint.Driver)
+	}
+}
+
+// NullImportedFilesDB keeps the imported files in memory.
+type NullImportedFilesDB struct {
+	lock  sync.Mutex
+	files map[string]int64
+}
+
+func NewNullImportedFilesDB() *NullImportedFilesDB {
+	return &NullImportedFilesDB{files: make(map[string]int64)}
+}
+
+func (ifdb *NullImportedFilesDB) ImportedFiles(context.Context) (map[string]int64, error) {
+	ifdb.lock.Lock()
+	defer ifdb.lock.Unlock()
+	return maps.Clone(ifdb.files), nil
+}
+
+func (ifdb *NullImportedFilesDB) AddImportedFiles(_ context.Context, files map[string]int64) error {
+	ifdb.lock.Lock()
+	defer ifdb.lock.Unlock()
+	maps.Copy(ifdb.files, files)
+	return nil
+}
+
+func (*NullImportedFilesDB) Close() error {
+	return nil
+}
+
+type MySQLImportedFilesDB struct {
+	db     *sql.DB
+	schema string
+}
+
+func NewMySQLImportedFilesDB(ctx context.Context, db *sql.DB, schemaName string) (*MySQLImportedFilesDB, error) {
+	schema := common.EscapeIdentifier(schemaName)
+	sql := common.SQLWithRetry{
+		DB:           db,
+		Logger:       log.FromContext(ctx).With(zap.String("schema", schemaName)),
+		HideQueryLog: true,
+	}
+	err := sql.Exec(ctx, "create imported files database", fmt.Sprintf(CreateDBTemplate, schema))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = sql.Exec(ctx, "create imported files table", fmt.Sprintf(CreateImportedFilesTableTemplate, schema, CheckpointTableNameImportedFiles))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &MySQLImportedFilesDB{
+		db:     db,
+		schema: schema,
+	}, nil
+}
+
+func (ifdb *MySQLImportedFilesDB) ImportedFiles(ctx context.Context) (map[string]int64, error) {
+	s := common.SQLWithRetry{
+		DB:     ifdb.db,
+		Logger: log.FromContext(ctx),
+	}
+	files := make(map[string]int64)
+	err := s.Transact(ctx, "read imported files", func(c context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(c, fmt.Sprintf(ReadImportedFilesTemplate, ifdb.schema, CheckpointTableNameImportedFiles))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		//nolint: errcheck
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				path string
+				size int64
+			)
+			if err := rows.Scan(&path, &size); err != nil {
+				return errors.Trace(err)
+			}
+			files[path] = size
+		}
+		return errors.Trace(rows.Err())
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return files, nil
+}
+
+func (ifdb *MySQLImportedFilesDB) AddImportedFiles(ctx context.Context, files map[string]int64) error {
+	s := common.SQLWithRetry{
+		DB:     ifdb.db,
+		Logger: log.FromContext(ctx).With(zap.Int("count", len(files))),
+	}
+	paths := maps.Keys(files)
+	slices.Sort(paths)
+	return s.Transact(ctx, "add imported files", func(c context.Context, tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(c, fmt.Sprintf(InsertImportedFilesTemplate, ifdb.schema, CheckpointTableNameImportedFiles))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		//nolint: errcheck
+		defer stmt.Close()
+		for _, path := range paths {
+			if _, err := stmt.ExecContext(c, path, files[path]); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	})
+}
+
+func (ifdb *MySQLImportedFilesDB) Close() error {
+	return errors.Trace(ifdb.db.Close())
+}
+
+// FileImportedFilesDB stores the imported sizes of the imported files as a JSON
+// object in a file next to the checkpoints file.
+type FileImportedFilesDB struct {
+	lock      sync.Mutex
+	ctx       context.Context
+	exStorage storage.ExternalStorage
+	fileName  string
+	files     map[string]int64
+}
+
+func NewFileImportedFilesDB(ctx context.Context, s storage.ExternalStorage, fileName string) (*FileImportedFilesDB, error) {
+	ifdb := &FileImportedFilesDB{
+		ctx:       ctx,
+		exStorage: s,
+		fileName:  fileName,
+		files:     make(map[string]int64),
+	}
+	exist, err := s.FileExists(ctx, fileName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !exist {
+		return ifdb, nil
+	}
+	content, err := s.ReadFile(ctx, fileName)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := json.Unmarshal(content, &ifdb.files); err != nil {
+		return nil, errors.Annotatef(err, "imported files file '%s' is broken", fileName)
+	}
+	return ifdb, nil
+}
+
+func (ifdb *FileImportedFilesDB) ImportedFiles(context.Context) (map[string]int64, error) {
+	ifdb.lock.Lock()
+	defer ifdb.lock.Unlock()
+	return maps.Clone(ifdb.files), nil
+}
+
+func (ifdb *FileImportedFilesDB) AddImportedFiles(_ context.Context, files map[string]int64) error {
+	ifdb.lock.Lock()
+	defer ifdb.lock.Unlock()
+	maps.Copy(ifdb.files, files)
+	// the keys of the map are sorted by json.Marshal.
+	content, err := json.Marshal(ifdb.files)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(ifdb.exStorage.WriteFile(ifdb.ctx, ifdb.fileName, content))
+}
+
+func (*FileImportedFilesDB) Close() error {
+	return nil
+}
+
+// EtcdImportedFilesDB stores the imported size of every imported file in the key
+// of its path.
+type EtcdImportedFilesDB struct {
+	cli    *clientv3.Client
+	prefix string
+}
+
+func NewEtcdImportedFilesDB(cli *clientv3.Client, schemaName string) *EtcdImportedFilesDB {
+	return &EtcdImportedFilesDB{
+		cli:    cli,
+		prefix: etcdSchemaPrefix(schemaName),
+	}
+}
+
+func (ifdb *EtcdImportedFilesDB) ImportedFiles(ctx context.Context) (map[string]int64, error) {
+	resp, err := ifdb.cli.Get(ctx, ifdb.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	files := make(map[string]int64, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		size, err := strconv.ParseInt(string(kv.Value), 10, 64)
+		if err != nil {
+			return nil, errors.Annotatef(err, "imported file %s is broken", kv.Key)
+		}
+		files[strings.TrimPrefix(string(kv.Key), ifdb.prefix)] = size
+	}
+	return files, nil
+}
+
+func (ifdb *EtcdImportedFilesDB) AddImportedFiles(ctx context.Context, files map[string]int64) error {
+	paths := maps.Keys(files)
+	slices.Sort(paths)
+	for i := 0; i < len(paths); i += etcdTxnOpsLimit {
+		end := i + etcdTxnOpsLimit
+		if end > len(paths) {
+			end = len(paths)
+		}
+		ops := make([]clientv3.Op, 0, end-i)
+		for _, path := range paths[i:end] {
+			ops = append(ops, clientv3.OpPut(ifdb.prefix+path, strconv.FormatInt(files[path], 10)))
+		}
+		if _, err := ifdb.cli.Txn(ctx).Then(ops...).Commit(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (ifdb *EtcdImportedFilesDB) Close() error {
+	return errors.Trace(ifdb.cli.Close())
+}
@@ -0,0 +1,269 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkpoints
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints/checkpointspb"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+)
+
+// ExportCheckpoints writes the task checkpoint and the checkpoints of all the tables,
+// engines and chunks in cpdb to the file at path, which can be in any external storage
+// like "s3://bucket/prefix/cp.pb". The file is in the format of the file checkpoints,
+// so it can be imported to the checkpoints of any driver on another machine to resume
+// the task there.
+func ExportCheckpoints(ctx context.Context, cpdb DB, path string) error {
+	content, err := MarshalCheckpoints(ctx, cpdb)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s, fileName, err := createExstorageByCompletePath(ctx, path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if fileName == "" {
+		return errors.Errorf("the checkpoint path '%s' must not be a directory", path)
+	}
+	return errors.Trace(s.WriteFile(ctx, fileName, content))
+}
+
+// ImportCheckpoints reads the checkpoints exported by ExportCheckpoints from the file at
+// path, and writes them to cpdb which must not have any checkpoint.
+func ImportCheckpoints(ctx context.Context, cpdb DB, path string) error {
+	s, fileName, err := createExstorageByCompletePath(ctx, path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if fileName == "" {
+		return errors.Errorf("the checkpoint path '%s' must not be a directory", path)
+	}
+	content, err := s.ReadFile(ctx, fileName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(UnmarshalCheckpoints(ctx, cpdb, content))
+}
+
+// MarshalCheckpoints serializes all the checkpoints in cpdb like the file checkpoints.
+func MarshalCheckpoints(ctx context.Context, cpdb DB) ([]byte, error) {
+	taskCp, err := cpdb.TaskCheckpoint(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if taskCp == nil {
+		return nil, errors.New("there is no task checkpoint to export")
+	}
+	tableNames, err := cpdb.TableNames(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	model := checkpointspb.CheckpointsModel{
+		TaskCheckpoint: taskCheckpointToModel(taskCp),
+		Checkpoints:    make(map[string]*checkpointspb.TableCheckpointModel, len(tableNames)),
+	}
+	for _, tableName := range tableNames {
+		cp, err := cpdb.Get(ctx, tableName)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		model.Checkpoints[tableName] = tableCheckpointToModel(cp)
+	}
+	content, err := model.Marshal()
+	return content, errors.Trace(err)
+}
+
+// UnmarshalCheckpoints writes the checkpoints serialized by MarshalCheckpoints, or the
+// content of a checkpoint file, to cpdb which must not have any checkpoint.
+func UnmarshalCheckpoints(ctx context.Context, cpdb DB, content []byte) error {
+	if bytes.HasPrefix(content, zstdMagic) {
+		var err error
+		if content, err = decompressZstd(content); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	var model checkpointspb.CheckpointsModel
+	if err := model.Unmarshal(content); err != nil {
+		return errors.Annotate(err, "the checkpoints to import are broken")
+	}
+	taskCp := taskCheckpointFromModel(model.TaskCheckpoint)
+	if taskCp == nil {
+		return errors.New("there is no task checkpoint to import")
+	}
+
+	existing, err := cpdb.TableNames(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(existing) > 0 {
+		return errors.Errorf("there are checkpoints of %d tables already, please remove them by `--checkpoint-remove=all` first", len(existing))
+	}
+
+	// the task checkpoint is initialized from the config.
+	cfg := config.NewConfig()
+	cfg.TaskID = taskCp.TaskID
+	cfg.Mydumper.SourceDir = taskCp.SourceDir
+	cfg.TikvImporter.Backend = taskCp.Backend
+	cfg.TikvImporter.Addr = taskCp.ImporterAddr
+	cfg.TiDB.Host = taskCp.TiDBHost
+	cfg.TiDB.Port = taskCp.TiDBPort
+	cfg.TiDB.PdAddr = taskCp.PdAddr
+	cfg.TikvImporter.SortedKVDir = taskCp.SortedKVDir
+
+	dbInfos := make(map[string]*TidbDBInfo)
+	for tableName, tableModel := range model.Checkpoints {
+		schema, table, err := splitUniqueTable(tableName)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		dbInfo, ok := dbInfos[schema]
+		if !ok {
+			dbInfo = &TidbDBInfo{Name: schema, Tables: make(map[string]*TidbTableInfo)}
+			dbInfos[schema] = dbInfo
+		}
+		dbInfo.Tables[table] = &TidbTableInfo{ID: tableModel.TableID, DB: schema, Name: table}
+	}
+	if err := cpdb.Initialize(ctx, cfg, dbInfos); err != nil {
+		return errors.Trace(err)
+	}
+
+	diffs := make(map[string]*TableCheckpointDiff, len(model.Checkpoints))
+	for tableName, tableModel := range model.Checkpoints {
+		cp := tableCheckpointFromModel(tableModel)
+		if len(cp.Engines) > 0 {
+			if err := cpdb.InsertEngineCheckpoints(ctx, tableName, cp.Engines); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		diffs[tableName] = tableCheckpointDiffFrom(cp)
+	}
+	return errors.Trace(cpdb.Update(ctx, diffs))
+}
+
+func taskCheckpointToModel(cp *TaskCheckpoint) *checkpointspb.TaskCheckpointModel {
+	return &checkpointspb.TaskCheckpointModel{
+		TaskId:       cp.TaskID,
+		SourceDir:    cp.SourceDir,
+		Backend:      cp.Backend,
+		ImporterAddr: cp.ImporterAddr,
+		TidbHost:     cp.TiDBHost,
+		TidbPort:     int32(cp.TiDBPort),
+		PdAddr:       cp.PdAddr,
+		SortedKvDir:  cp.SortedKVDir,
+		LightningVer: cp.LightningVer,
+	}
+}
+
+func tableCheckpointToModel(cp *TableCheckpoint) *checkpointspb.TableCheckpointModel {
+	tableModel := &checkpointspb.TableCheckpointModel{
+		Status:     uint32(cp.Status),
+		AllocBase:  cp.AllocBase,
+		Engines:    make(map[int32]*checkpointspb.EngineCheckpointModel, len(cp.Engines)),
+		TableID:    cp.TableID,
+		KvBytes:    cp.Checksum.SumSize(),
+		KvKvs:      cp.Checksum.SumKVS(),
+		KvChecksum: cp.Checksum.Sum(),
+	}
+	for engineID, engine := range cp.Engines {
+		engineModel := &checkpointspb.EngineCheckpointModel{
+			Status: uint32(engine.Status),
+			Chunks: make(map[string]*checkpointspb.ChunkCheckpointModel, len(engine.Chunks)),
+		}
+		for _, chunk := range engine.Chunks {
+			engineModel.Chunks[chunk.Key.String()] = &checkpointspb.ChunkCheckpointModel{
+				Path:              chunk.Key.Path,
+				Offset:            chunk.Key.Offset,
+				ColumnPermutation: intSlice2Int32Slice(chunk.ColumnPermutation),
+				EndOffset:         chunk.Chunk.EndOffset,
+				Pos:               chunk.Chunk.Offset,
+				PrevRowidMax:      chunk.Chunk.PrevRowIDMax,
+				RowidMax:          chunk.Chunk.RowIDMax,
+				KvcBytes:          chunk.Checksum.SumSize(),
+				KvcKvs:            chunk.Checksum.SumKVS(),
+				KvcChecksum:       chunk.Checksum.Sum(),
+				Type:              int32(chunk.FileMeta.Type),
+				Compression:       int32(chunk.FileMeta.Compression),
+				SortKey:           chunk.FileMeta.SortKey,
+				FileSize:          chunk.FileMeta.FileSize,
+				Timestamp:         chunk.Timestamp,
+			}
+		}
+		tableModel.Engines[engineID] = engineModel
+	}
+	return tableModel
+}
+
+// tableCheckpointDiffFrom returns the diff which updates the inserted engine checkpoints
+// of the table to cp.
+func tableCheckpointDiffFrom(cp *TableCheckpoint) *TableCheckpointDiff {
+	cpd := NewTableCheckpointDiff()
+	for engineID, engine := range cp.Engines {
+		for _, chunk := range engine.Chunks {
+			chunkMerger := &ChunkCheckpointMerger{
+				EngineID:          engineID,
+				Key:               chunk.Key,
+				Checksum:          chunk.Checksum,
+				Pos:               chunk.Chunk.Offset,
+				RowID:             chunk.Chunk.PrevRowIDMax,
+				ColumnPermutation: chunk.ColumnPermutation,
+			}
+			chunkMerger.MergeInto(cpd)
+		}
+		engineMerger := &StatusCheckpointMerger{EngineID: engineID, Status: engine.Status}
+		engineMerger.MergeInto(cpd)
+	}
+	rebaseMerger := &RebaseCheckpointMerger{AllocBase: cp.AllocBase}
+	rebaseMerger.MergeInto(cpd)
+	checksumMerger := &TableChecksumMerger{Checksum: cp.Checksum}
+	checksumMerger.MergeInto(cpd)
+	// the table status is merged at last since an invalid engine status overrides it.
+	tableMerger := &StatusCheckpointMerger{EngineID: WholeTableEngineID, Status: cp.Status}
+	tableMerger.MergeInto(cpd)
+	return cpd
+}
+
+// splitUniqueTable splits the table name like "`db`.`tbl`" into the unquoted schema
+// and table names.
+func splitUniqueTable(tableName string) (string, string, error) {
+	n, err := quotedTableNameLen(tableName)
+	if err != nil || n != len(tableName) {
+		return "", "", errors.Errorf("invalid table name '%s' in the checkpoints", tableName)
+	}
+	schema, rest := unquoteName(tableName)
+	table, _ := unquoteName(rest[1:])
+	return schema, table, nil
+}
+
+// unquoteName unquotes the quoted name at the beginning of s, and returns the rest of s.
+func unquoteName(s string) (string, string) {
+	var name strings.Builder
+	i := 1
+	for {
+		end := strings.IndexByte(s[i:], '`')
+		name.WriteString(s[i : i+end])
+		i += end + 1
+		if i < len(s) && s[i] == '`' {
+			name.WriteByte('`')
+			i++
+			continue
+		}
+		return name.String(), s[i:]
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checkpoints_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	src := newFileCheckpointsDB(t)
+	setInvalidStatus(src)
+
+	path := filepath.Join(t.TempDir(), "exported.pb")
+	require.NoError(t, checkpoints.ExportCheckpoints(ctx, src, path))
+
+	dst, err := checkpoints.NewFileCheckpointsDB(ctx, filepath.Join(t.TempDir(), "cp.pb"))
+	require.NoError(t, err)
+	defer dst.Close()
+	require.NoError(t, checkpoints.ImportCheckpoints(ctx, dst, path))
+
+	srcTask, err := src.TaskCheckpoint(ctx)
+	require.NoError(t, err)
+	dstTask, err := dst.TaskCheckpoint(ctx)
+	require.NoError(t, err)
+	require.Equal(t, srcTask, dstTask)
+
+	tableNames, err := dst.TableNames(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"`db1`.`t1`", "`db1`.`t2`", "`db2`.`t3`"}, tableNames)
+	for _, tableName := range tableNames {
+		expected, err := src.Get(ctx, tableName)
+		require.NoError(t, err)
+		actual, err := dst.Get(ctx, tableName)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual, tableName)
+	}
+
+	// the checkpoints can't be imported again.
+	err = checkpoints.ImportCheckpoints(ctx, dst, path)
+	require.ErrorContains(t, err, "--checkpoint-remove=all")
+}
+
+func TestExportEmptyCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	cpdb, err := checkpoints.NewFileCheckpointsDB(ctx, filepath.Join(t.TempDir(), "cp.pb"))
+	require.NoError(t, err)
+	defer cpdb.Close()
+
+	err = checkpoints.ExportCheckpoints(ctx, cpdb, filepath.Join(t.TempDir(), "exported.pb"))
+	require.ErrorContains(t, err, "no task checkpoint")
+}
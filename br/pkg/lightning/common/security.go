@@ -85,6 +85,27 @@ func ToTLSConfig(caPath, certPath, keyPath string) (*tls.Config, error) {
 	return tlsConfig, nil
 }
 
+// fipsCipherSuites are the cipher suites of TLS 1.2 approved by FIPS 140-2.
+var fipsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// RestrictTLSToFIPS restricts the TLS config to the protocol version, cipher suites and
+// curves approved by FIPS 140-2. TLS 1.3 is disabled since its cipher suites can't be
+// configured. It does nothing if the TLS config is nil.
+func RestrictTLSToFIPS(tlsConfig *tls.Config) {
+	if tlsConfig == nil {
+		return
+	}
+	tlsConfig.MinVersion = tls.VersionTLS12
+	tlsConfig.MaxVersion = tls.VersionTLS12
+	tlsConfig.CipherSuites = fipsCipherSuites
+	tlsConfig.CurvePreferences = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+}
+
 // NewTLS constructs a new HTTP client with TLS configured with the CA,
 // certificate and key paths.
 //
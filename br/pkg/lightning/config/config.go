@@ -16,6 +16,7 @@ package config
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -23,9 +24,11 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 	"unicode/utf8"
 
@@ -36,6 +39,8 @@ import (
 	"github.com/pingcap/tidb/br/pkg/lightning/common"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	tidbcfg "github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/parser/charset"
+	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	filter "github.com/pingcap/tidb/util/table-filter"
 	router "github.com/pingcap/tidb/util/table-router"
@@ -59,6 +64,10 @@ const (
 	CheckpointDriverMySQL = "mysql"
 	// CheckpointDriverFile is a constant for choosing the "File" checkpoint driver in the configuration.
 	CheckpointDriverFile = "file"
+	// CheckpointDriverEtcd is a constant for choosing the "etcd" checkpoint driver in the configuration.
+	CheckpointDriverEtcd = "etcd"
+	// CheckpointCompressionZstd is a constant for compressing the "File" checkpoints by zstd.
+	CheckpointCompressionZstd = "zstd"
 
 	// ReplaceOnDup indicates using REPLACE INTO to insert data
 	ReplaceOnDup = "replace"
@@ -66,6 +75,58 @@ const (
 	IgnoreOnDup = "ignore"
 	// ErrorOnDup indicates using INSERT INTO to insert data, which would violate PK or UNIQUE constraint
 	ErrorOnDup = "error"
+	// MergeOnDup indicates using INSERT INTO ... ON DUPLICATE KEY UPDATE to insert data, which updates
+	// the columns listed in `tikv-importer.merge-columns` of the existing row on PK or UNIQUE conflict
+	MergeOnDup = "merge"
+
+	// PriorityLow makes TiKV schedule the ingest requests after the other ones,
+	// and makes the server mode run the task after the queued tasks of higher priorities.
+	PriorityLow = "low"
+	// PriorityNormal makes TiKV schedule the ingest requests as the foreground requests.
+	PriorityNormal = "normal"
+	// PriorityHigh makes TiKV schedule the ingest requests before the other ones,
+	// and makes the server mode run the task before the queued tasks of lower priorities.
+	PriorityHigh = "high"
+
+	// ForeignKeyModeOff imports the tables regardless of their foreign keys.
+	ForeignKeyModeOff = "off"
+	// ForeignKeyModeOrdered creates the tables without their foreign keys, imports the
+	// referenced tables before the referencing ones, and adds the foreign keys back after
+	// validating the imported rows.
+	ForeignKeyModeOrdered = "ordered"
+
+	// EncryptionPlaintext leaves the sorted KV files of the local backend unencrypted.
+	EncryptionPlaintext = "plaintext"
+	// EncryptionAES128CTR encrypts the sorted KV files by AES-128 in CTR mode.
+	EncryptionAES128CTR = "aes128-ctr"
+	// EncryptionAES192CTR encrypts the sorted KV files by AES-192 in CTR mode.
+	EncryptionAES192CTR = "aes192-ctr"
+	// EncryptionAES256CTR encrypts the sorted KV files by AES-256 in CTR mode.
+	EncryptionAES256CTR = "aes256-ctr"
+
+	// InvalidCharReplace replaces the invalid bytes in the source data files with `data-invalid-char-replace`.
+	InvalidCharReplace = "replace"
+	// InvalidCharError fails the import on the invalid bytes in the source data files.
+	InvalidCharError = "error"
+	// InvalidCharSkip drops the invalid bytes in the source data files.
+	InvalidCharSkip = "skip"
+
+	// NotificationWebhook posts the events as JSON to a generic webhook.
+	NotificationWebhook = "webhook"
+	// NotificationSlack posts the events as messages to a Slack incoming webhook.
+	NotificationSlack = "slack"
+	// NotificationPagerDuty triggers PagerDuty incidents through the Events API v2.
+	NotificationPagerDuty = "pagerduty"
+
+	// EventTaskCompleted is fired when the task is completed, successfully or not.
+	EventTaskCompleted = "task-completed"
+	// EventTableFailed is fired when a table fails to be imported.
+	EventTableFailed = "table-failed"
+	// EventChecksumMismatch is fired when the checksum of a table is mismatched.
+	EventChecksumMismatch = "checksum-mismatch"
+
+	defaultPagerDutyURL        = "https://events.pagerduty.com/v2/enqueue"
+	defaultNotificationTimeout = 10 * time.Second
 
 	defaultDistSQLScanConcurrency     = 15
 	defaultBuildStatsConcurrency      = 20
@@ -105,6 +166,9 @@ var (
 		"!METRICS_SCHEMA.*",
 		"!INSPECTION_SCHEMA.*",
 	}
+
+	sessionVarNameRegexp    = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	schemaRewriteNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
 )
 
 type DBStore struct {
@@ -141,6 +205,11 @@ type Config struct {
 	Cron         Cron                `toml:"cron" json:"cron"`
 	Routes       []*router.TableRule `toml:"routes" json:"routes"`
 	Security     Security            `toml:"security" json:"security"`
+	// Notifications are the hooks notified of the events of the task.
+	Notifications []*Notification `toml:"notification" json:"notification"`
+	// RouteOverrides are the table routes given by the command line, which take
+	// precedence over Routes.
+	RouteOverrides []*router.TableRule `toml:"-" json:"route-overrides"`
 
 	BWList filter.MySQLReplicationRules `toml:"black-white-list" json:"black-white-list"`
 }
@@ -155,7 +224,14 @@ func (cfg *Config) String() string {
 
 func (cfg *Config) ToTLS() (*common.TLS, error) {
 	hostPort := net.JoinHostPort(cfg.TiDB.Host, strconv.Itoa(cfg.TiDB.StatusPort))
-	return common.NewTLS(cfg.Security.CAPath, cfg.Security.CertPath, cfg.Security.KeyPath, hostPort)
+	tls, err := common.NewTLS(cfg.Security.CAPath, cfg.Security.CertPath, cfg.Security.KeyPath, hostPort)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Security.FIPSMode {
+		common.RestrictTLSToFIPS(tls.TLSConfig())
+	}
+	return tls, nil
 }
 
 type Lightning struct {
@@ -168,6 +244,59 @@ type Lightning struct {
 
 	MaxError           MaxError `toml:"max-error" json:"max-error"`
 	TaskInfoSchemaName string   `toml:"task-info-schema-name" json:"task-info-schema-name"`
+	// TaskPriority is the priority of the task in the queue of the server mode.
+	TaskPriority string `toml:"task-priority" json:"task-priority"`
+	// ForeignKeyMode is how the foreign keys of the tables are handled during the import.
+	ForeignKeyMode string `toml:"foreign-key-mode" json:"foreign-key-mode"`
+	// ThrottleSchedule are the time windows of the day in which the import is throttled,
+	// the import runs at full speed out of the windows.
+	ThrottleSchedule []*ThrottleWindow `toml:"throttle-schedule" json:"throttle-schedule"`
+}
+
+// ThrottleWindow is a time window of the day in which the import is throttled.
+type ThrottleWindow struct {
+	// Start and End are the local time of the day like "09:00". The window wraps to
+	// the next day if End is not later than Start.
+	Start string `toml:"start" json:"start"`
+	End   string `toml:"end" json:"end"`
+	// Ratio is the fraction of `region-concurrency` and `tikv-importer.range-concurrency`
+	// used in the window.
+	Ratio float64 `toml:"ratio" json:"ratio"`
+	// StoreWriteBWLimit overrides `tikv-importer.store-write-bwlimit` of the local backend
+	// in the window, 0 means not overriding.
+	StoreWriteBWLimit ByteSize `toml:"store-write-bwlimit" json:"store-write-bwlimit"`
+
+	// start and end are the offsets from the midnight.
+	start, end time.Duration
+}
+
+// contains returns whether the offset from the midnight is in the window.
+func (w *ThrottleWindow) contains(offset time.Duration) bool {
+	if w.start < w.end {
+		return w.start <= offset && offset < w.end
+	}
+	return offset >= w.start || offset < w.end
+}
+
+// ThrottleWindowAt returns the first throttle window containing t, or nil if the import
+// should run at full speed.
+func (cfg *Lightning) ThrottleWindowAt(t time.Time) *ThrottleWindow {
+	hour, minute, sec := t.Clock()
+	offset := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(sec)*time.Second
+	for _, w := range cfg.ThrottleSchedule {
+		if w.contains(offset) {
+			return w
+		}
+	}
+	return nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
 }
 
 type PostOpLevel int
@@ -431,6 +560,43 @@ type CSVConfig struct {
 	TrimLastSep     bool   `toml:"trim-last-separator" json:"trim-last-separator"`
 	NotNull         bool   `toml:"not-null" json:"not-null"`
 	BackslashEscape bool   `toml:"backslash-escape" json:"backslash-escape"`
+	// ColumnNulls overrides the NULL representation of some columns. They only take effect
+	// when the column names are known, i.e. the file has a header or the columns are restored.
+	ColumnNulls []*CSVColumnNull `toml:"column-null" json:"column-null"`
+	// InferSchema infers the schema of the tables without schema files from the header and
+	// the sampled rows of their CSV files, so it requires `header = true`.
+	InferSchema bool `toml:"infer-schema" json:"infer-schema"`
+	// InferSchemaSampleRows is the max number of rows sampled from the CSV files of a table
+	// to infer its schema.
+	InferSchemaSampleRows int `toml:"infer-schema-sample-rows" json:"infer-schema-sample-rows"`
+	// RejectedRowsDir is the URL of the external storage to write the malformed rows to. When
+	// set, a row with syntax errors, the wrong number of fields or un-parseable values is
+	// written to a rejected rows file there along with its line number and the error, instead
+	// of aborting the whole chunk.
+	RejectedRowsDir string `toml:"rejected-rows-dir" json:"rejected-rows-dir"`
+	// MaxRejectedRows is the maximum number of rows rejected in the whole task, the import
+	// fails once it's exceeded. 0 means no limit.
+	MaxRejectedRows int64 `toml:"max-rejected-rows" json:"max-rejected-rows"`
+}
+
+// CSVColumnNull is the NULL representation of a CSV column.
+type CSVColumnNull struct {
+	Column string `toml:"column" json:"column"`
+	// Null lists the strings treated as NULL in this column, it replaces `mydumper.csv.null`
+	// and `mydumper.csv.not-null` for this column. Leave it empty to keep the global setting.
+	Null []string `toml:"null" json:"null"`
+	// UnquotedEmptyAsNull treats an unquoted empty field as NULL, while a quoted empty
+	// field ("") is always an empty string. This is how PostgreSQL exports CSV.
+	UnquotedEmptyAsNull bool `toml:"unquoted-empty-as-null" json:"unquoted-empty-as-null"`
+}
+
+// JSONConfig is the config of JSON Lines data files.
+type JSONConfig struct {
+	// StrictColumns controls how the keys of the JSON objects are matched with the columns
+	// of the target table. In loose mode (the default), unknown keys are ignored and missing
+	// keys take the default values of the columns. In strict mode, both cases are reported
+	// as errors.
+	StrictColumns bool `toml:"strict-columns" json:"strict-columns"`
 }
 
 type MydumperRuntime struct {
@@ -440,6 +606,7 @@ type MydumperRuntime struct {
 	SourceDir        string           `toml:"data-source-dir" json:"data-source-dir"`
 	CharacterSet     string           `toml:"character-set" json:"character-set"`
 	CSV              CSVConfig        `toml:"csv" json:"csv"`
+	JSON             JSONConfig       `toml:"json" json:"json"`
 	MaxRegionSize    ByteSize         `toml:"max-region-size" json:"max-region-size"`
 	Filter           []string         `toml:"filter" json:"filter"`
 	FileRouters      []*FileRouteRule `toml:"files" json:"files"`
@@ -449,7 +616,18 @@ type MydumperRuntime struct {
 	StrictFormat     bool             `toml:"strict-format" json:"strict-format"`
 	DefaultFileRules bool             `toml:"default-file-rules" json:"default-file-rules"`
 	IgnoreColumns    AllIgnoreColumns `toml:"ignore-data-columns" json:"ignore-data-columns"`
-	// DataCharacterSet is the character set of the source file. Only CSV files are supported now. The following options are supported.
+	// IgnoreUnknownColumns ignores the columns of the data files which don't exist in the target
+	// table instead of failing, e.g. the columns dropped after the data is exported. It can be
+	// overridden for some tables by `ignore-data-columns`.
+	IgnoreUnknownColumns bool `toml:"ignore-unknown-columns" json:"ignore-unknown-columns"`
+	// ColumnTransforms set the values of the columns by the expressions evaluated from the
+	// imported rows, which can be used to mask or enrich the data. Only the local backend
+	// supports them.
+	ColumnTransforms AllColumnTransforms `toml:"column-transforms" json:"column-transforms"`
+	// SchemaRewrite replaces the character sets, collations and storage engines of the schema
+	// files which are not supported by TiDB before the tables are created.
+	SchemaRewrite SchemaRewrite `toml:"schema-rewrite" json:"schema-rewrite"`
+	// DataCharacterSet is the character set of the source CSV and SQL files. The following options are supported.
 	//   - utf8mb4
 	//   - GB18030
 	//   - GBK: an extension of the GB2312 character set and is also known as Code Page 936.
@@ -459,6 +637,45 @@ type MydumperRuntime struct {
 	// DataInvalidCharReplace is the replacement characters for non-compatible characters, which shouldn't duplicate with the separators or line breaks.
 	// Changing the default value will result in increased parsing time. Non-compatible characters do not cause an increase in error.
 	DataInvalidCharReplace string `toml:"data-invalid-char-replace" json:"data-invalid-char-replace"`
+	// DataInvalidCharAction is how to handle the bytes which are invalid in DataCharacterSet,
+	// either "replace" (by DataInvalidCharReplace), "error" or "skip".
+	DataInvalidCharAction string `toml:"data-invalid-char-action" json:"data-invalid-char-action"`
+	// VerifyChecksum enables verifying the data files against the checksums in the sidecar files
+	// `{data file}.sha256`, `{data file}.md5` or `{data file}.crc64` while reading them.
+	VerifyChecksum bool `toml:"verify-checksum" json:"verify-checksum"`
+	// ChecksumAlgorithm restricts the checksum sidecar files to the ones of the algorithm, which
+	// is one of "sha256", "md5" and "crc64". All the sidecar files are used if it's empty.
+	ChecksumAlgorithm string `toml:"checksum-algorithm" json:"checksum-algorithm"`
+	// RowFilters only import the rows of the matched tables satisfying the filters. Only Parquet files
+	// are supported now.
+	RowFilters AllRowFilters `toml:"row-filters" json:"row-filters"`
+	// WatchInterval makes Lightning re-scan the data source periodically after the import is finished,
+	// and import the newly arrived data files into the append-only tables. Zero disables the watch mode.
+	WatchInterval Duration `toml:"watch-interval" json:"watch-interval"`
+	// WatchGrowingFiles makes the watch mode import the data appended to the imported files, which is
+	// read from the recorded size of the file up to the last line feed. Only the uncompressed CSV files
+	// whose rows never span lines are supported.
+	WatchGrowingFiles bool `toml:"watch-growing-files" json:"watch-growing-files"`
+	// PreSplit splits the large uncompressed CSV files into regions while scanning the data source, so
+	// they needn't be opened again when making the table regions. It requires StrictFormat.
+	PreSplit bool `toml:"pre-split" json:"pre-split"`
+	// SourceInclude restricts the scanned files to the ones under the path prefixes or matching the globs
+	// (with their parent directories), and only the directories covering them are walked.
+	SourceInclude []string `toml:"source-include" json:"source-include"`
+	// VirtualShard makes this instance only import one shard of the regions, so that multiple instances can
+	// import the same data source, including the regions of a huge file, in parallel.
+	VirtualShard VirtualShard `toml:"virtual-shard" json:"virtual-shard"`
+	// ReadBandwidthLimit is the bytes per second read from the data source by all the parsers together.
+	// Zero means unlimited.
+	ReadBandwidthLimit ByteSize `toml:"read-bandwidth-limit" json:"read-bandwidth-limit"`
+}
+
+// VirtualShard splits the regions of every table into disjoint shards deterministically.
+type VirtualShard struct {
+	// Index is the index of the shard imported by this instance, in [0, Count).
+	Index int `toml:"index" json:"index"`
+	// Count is the number of the shards. Zero or one disables the sharding.
+	Count int `toml:"count" json:"count"`
 }
 
 type AllIgnoreColumns []*IgnoreColumns
@@ -468,6 +685,17 @@ type IgnoreColumns struct {
 	Table       string   `toml:"table" json:"table"`
 	TableFilter []string `toml:"table-filter" json:"table-filter"`
 	Columns     []string `toml:"columns" json:"columns"`
+	// IgnoreUnknownColumns overrides `mydumper.ignore-unknown-columns` for the matched tables if it's set.
+	IgnoreUnknownColumns *bool `toml:"ignore-unknown-columns" json:"ignore-unknown-columns"`
+}
+
+// UnknownColumnsIgnored returns whether the columns of the data files which don't exist in the
+// table are ignored, defaultValue is `mydumper.ignore-unknown-columns`.
+func (ic *IgnoreColumns) UnknownColumnsIgnored(defaultValue bool) bool {
+	if ic.IgnoreUnknownColumns != nil {
+		return *ic.IgnoreUnknownColumns
+	}
+	return defaultValue
 }
 
 func (ic *IgnoreColumns) ColumnsMap() map[string]struct{} {
@@ -478,6 +706,98 @@ func (ic *IgnoreColumns) ColumnsMap() map[string]struct{} {
 	return columnMap
 }
 
+// AllColumnTransforms is the list of `[[mydumper.column-transforms]]`.
+type AllColumnTransforms []*ColumnTransform
+
+// ColumnTransform sets the value of a column of the matched tables by an expression in the
+// TiDB expression syntax, e.g. `SHA2(email, 256)` or `'tenant-1'`. The expression is
+// evaluated from the row to import, after the fields of the data file are converted to
+// the column types, and it can refer to the other columns of the table by their names.
+type ColumnTransform struct {
+	DB          string   `toml:"db" json:"db"`
+	Table       string   `toml:"table" json:"table"`
+	TableFilter []string `toml:"table-filter" json:"table-filter"`
+	Column      string   `toml:"column" json:"column"`
+	Expr        string   `toml:"expr" json:"expr"`
+}
+
+// AllRowFilters is the list of `[[mydumper.row-filters]]`.
+type AllRowFilters []*RowFilterRule
+
+// RowFilterRule only imports the rows of the matched tables satisfying the filter, which is the
+// conjunction of the comparisons between a column and a constant in the WHERE clause syntax, e.g.
+// `id >= 100 AND create_time BETWEEN '2022-01-01' AND '2022-12-31'`.
+type RowFilterRule struct {
+	DB          string   `toml:"db" json:"db"`
+	Table       string   `toml:"table" json:"table"`
+	TableFilter []string `toml:"table-filter" json:"table-filter"`
+	Filter      string   `toml:"filter" json:"filter"`
+}
+
+// SchemaRewrite is the `[mydumper.schema-rewrite]` rules, which map the names in the schema
+// files to the ones supported by TiDB, e.g. `utf8mb3_unicode_ci = "utf8mb4_unicode_ci"`. The
+// keys are case-insensitive.
+type SchemaRewrite struct {
+	Charsets   map[string]string `toml:"charsets" json:"charsets"`
+	Collations map[string]string `toml:"collations" json:"collations"`
+	Engines    map[string]string `toml:"engines" json:"engines"`
+}
+
+// IsEmpty returns whether there is no rule to rewrite the schema.
+func (r *SchemaRewrite) IsEmpty() bool {
+	return len(r.Charsets) == 0 && len(r.Collations) == 0 && len(r.Engines) == 0
+}
+
+// GetColumnTransforms gets the expressions of the columns of the table, the keys are the
+// lower-case column names. If more than one rule sets the same column, the first one is used.
+func (transforms AllColumnTransforms) GetColumnTransforms(db string, table string, caseSensitive bool) (map[string]string, error) {
+	if !caseSensitive {
+		db = strings.ToLower(db)
+		table = strings.ToLower(table)
+	}
+	var exprs map[string]string
+	for _, t := range transforms {
+		if t.DB != db || t.Table != table {
+			f, err := filter.Parse(t.TableFilter)
+			if err != nil {
+				return nil, common.ErrInvalidConfig.GenWithStack("invalid table filter %s in column transforms", strings.Join(t.TableFilter, ","))
+			}
+			if !f.MatchTable(db, table) {
+				continue
+			}
+		}
+		if exprs == nil {
+			exprs = make(map[string]string)
+		}
+		if _, ok := exprs[t.Column]; !ok {
+			exprs[t.Column] = t.Expr
+		}
+	}
+	return exprs, nil
+}
+
+// GetRowFilter gets the row filter of the table. If more than one rule matches the table, the
+// first one is used. It returns nil if no rule matches the table.
+func (filters AllRowFilters) GetRowFilter(db string, table string, caseSensitive bool) (*RowFilter, error) {
+	if !caseSensitive {
+		db = strings.ToLower(db)
+		table = strings.ToLower(table)
+	}
+	for _, rf := range filters {
+		if rf.DB != db || rf.Table != table {
+			f, err := filter.Parse(rf.TableFilter)
+			if err != nil {
+				return nil, common.ErrInvalidConfig.GenWithStack("invalid table filter %s in row filters", strings.Join(rf.TableFilter, ","))
+			}
+			if !f.MatchTable(db, table) {
+				continue
+			}
+		}
+		return ParseRowFilter(rf.Filter)
+	}
+	return nil, nil
+}
+
 // GetIgnoreColumns gets Ignore config by schema name/regex and table name/regex.
 func (igCols AllIgnoreColumns) GetIgnoreColumns(db string, table string, caseSensitive bool) (*IgnoreColumns, error) {
 	if !caseSensitive {
@@ -507,6 +827,28 @@ type FileRouteRule struct {
 	Type        string `json:"type" toml:"type" yaml:"type"`
 	Key         string `json:"key" toml:"key" yaml:"key"`
 	Compression string `json:"compression" toml:"compression" yaml:"compression"`
+	// MinSize and MaxSize restrict the rule to the files whose sizes are in the range.
+	// Zero means no limit.
+	MinSize ByteSize `json:"min-size" toml:"min-size" yaml:"min-size"`
+	MaxSize ByteSize `json:"max-size" toml:"max-size" yaml:"max-size"`
+	// ModifiedAfter and ModifiedBefore restrict the rule to the files last modified
+	// in the time range, which are in RFC 3339 format like "2022-08-01T00:00:00Z".
+	// The rule doesn't match any file if the storage can't provide the modified time.
+	ModifiedAfter  string `json:"modified-after" toml:"modified-after" yaml:"modified-after"`
+	ModifiedBefore string `json:"modified-before" toml:"modified-before" yaml:"modified-before"`
+	// CharacterSet overrides `mydumper.character-set` for the schema files matched by
+	// the rule, so the tables dumped in different character sets can be imported together.
+	CharacterSet string `json:"character-set" toml:"character-set" yaml:"character-set"`
+	// Columns are the column names of the CSV files matched by the rule in the order of the
+	// fields, so the fields of the files without header don't need to be in the order of
+	// the columns of the target table. The fields named "-" are skipped, and the columns not
+	// listed are filled with their default values. It requires `mydumper.csv.header = false`.
+	Columns []string `json:"columns" toml:"columns" yaml:"columns"`
+	// SkipLines and SkipFooterLines are the numbers of the leading and the trailing lines of
+	// the CSV files matched by the rule which are not data, e.g. the report headers and the
+	// summary lines of the exported files. The CSV header is the line after the skipped ones.
+	SkipLines       int `json:"skip-lines" toml:"skip-lines" yaml:"skip-lines"`
+	SkipFooterLines int `json:"skip-footer-lines" toml:"skip-footer-lines" yaml:"skip-footer-lines"`
 	// unescape the schema/table name only used in lightning's internal logic now.
 	Unescape bool `json:"-" toml:"-" yaml:"-"`
 	// TODO: DataCharacterSet here can override the same field in [mydumper.csv] with a higher level.
@@ -528,19 +870,153 @@ type TikvImporter struct {
 	DiskQuota           ByteSize                     `toml:"disk-quota" json:"disk-quota"`
 	RangeConcurrency    int                          `toml:"range-concurrency" json:"range-concurrency"`
 	DuplicateResolution DuplicateResolutionAlgorithm `toml:"duplicate-resolution" json:"duplicate-resolution"`
-	IncrementalImport   bool                         `toml:"incremental-import" json:"incremental-import"`
+	// DuplicateIgnore excludes the unique indexes from the duplicate detection,
+	// e.g. the noisy ones which will be rebuilt after importing.
+	DuplicateIgnore   []*DuplicateIgnoreRule `toml:"duplicate-ignore" json:"duplicate-ignore"`
+	IncrementalImport bool                   `toml:"incremental-import" json:"incremental-import"`
+	// MergeColumns are the columns updated from the incoming row on duplicate when the
+	// on-duplicate is "merge". The conflicting rows of the tables without a rule are ignored.
+	MergeColumns []*MergeColumnsRule `toml:"merge-columns" json:"merge-columns"`
+	// SessionVars are the session variables of the connections writing the rows in the TiDB
+	// backend, which override the ones set by Lightning, e.g. `foreign_key_checks` or `sql_mode`.
+	SessionVars map[string]string `toml:"session-vars" json:"session-vars"`
+	// AdaptiveConcurrency makes the local backend adjust the concurrency of ingesting the regions
+	// between 1 and 2 * RangeConcurrency, by halving it when TiKV reports it's busy and
+	// increasing it gradually when the ingests succeed.
+	AdaptiveConcurrency bool `toml:"adaptive-concurrency" json:"adaptive-concurrency"`
+	// Priority is the priority class of the requests ingesting the SST files, by which the scheduler of TiKV
+	// throttles the import traffic against the other background tasks like DDL reorganization.
+	Priority string `toml:"priority" json:"priority"`
+	// SpillDirs are the secondary directories of the sorted KV files in the local backend.
+	// The engines are created in the next directory when the disk quota of the previous
+	// ones is used up, so that several smaller disks can be used together.
+	SpillDirs []SpillDir `toml:"spill-dirs" json:"spill-dirs"`
+	// Encryption encrypts the sorted KV files of the local backend at rest.
+	Encryption EngineEncryption `toml:"encryption" json:"encryption"`
+	// RegisterLogBackup records the ranges ingested by the local backend to the overlapping
+	// log backup tasks, since the ingested SST files aren't captured by the log backup.
+	RegisterLogBackup bool `toml:"register-log-backup" json:"register-log-backup"`
 
 	EngineMemCacheSize      ByteSize `toml:"engine-mem-cache-size" json:"engine-mem-cache-size"`
 	LocalWriterMemCacheSize ByteSize `toml:"local-writer-mem-cache-size" json:"local-writer-mem-cache-size"`
 	StoreWriteBWLimit       ByteSize `toml:"store-write-bwlimit" json:"store-write-bwlimit"`
 }
 
+// SpillDir is a secondary directory of the sorted KV files along with its disk quota.
+type SpillDir struct {
+	Path      string   `toml:"path" json:"path"`
+	DiskQuota ByteSize `toml:"disk-quota" json:"disk-quota"`
+}
+
+// EngineEncryption is the encryption of the sorted KV files of the local backend. The data key
+// is either given by the key or the key file directly, or generated randomly and wrapped by the
+// key ring, which is a key ring file or an AWS KMS key, see `storage.ParseKeyRing`.
+type EngineEncryption struct {
+	Method  string `toml:"method" json:"method"`
+	Key     string `toml:"key" json:"-"`
+	KeyFile string `toml:"key-file" json:"key-file"`
+	KeyRing string `toml:"key-ring" json:"key-ring"`
+}
+
+// Enabled returns whether the sorted KV files are encrypted.
+func (e *EngineEncryption) Enabled() bool {
+	return e.KeyLen() > 0
+}
+
+// KeyLen returns the length of the data key of the method, 0 for plaintext or unknown methods.
+func (e *EngineEncryption) KeyLen() int {
+	switch strings.ToLower(e.Method) {
+	case EncryptionAES128CTR:
+		return 16
+	case EncryptionAES192CTR:
+		return 24
+	case EncryptionAES256CTR:
+		return 32
+	default:
+		return 0
+	}
+}
+
+// SortedKVDirs returns the sorted-kv-dir followed by the spill directories.
+func (t *TikvImporter) SortedKVDirs() []string {
+	dirs := make([]string, 0, 1+len(t.SpillDirs))
+	dirs = append(dirs, t.SortedKVDir)
+	for _, dir := range t.SpillDirs {
+		dirs = append(dirs, dir.Path)
+	}
+	return dirs
+}
+
+// TotalDiskQuota returns the sum of the disk quota of the sorted-kv-dir and the
+// spill directories.
+func (t *TikvImporter) TotalDiskQuota() ByteSize {
+	total := t.DiskQuota
+	for _, dir := range t.SpillDirs {
+		if total > ByteSize(math.MaxInt64)-dir.DiskQuota {
+			return ByteSize(math.MaxInt64)
+		}
+		total += dir.DiskQuota
+	}
+	return total
+}
+
+// DuplicateIgnoreRule excludes the unique indexes of a table from the duplicate
+// detection. An index is excluded if it's listed in Indexes, or if it contains
+// any column listed in Columns. The row keys of the table are always checked.
+type DuplicateIgnoreRule struct {
+	Schema  string   `toml:"schema" json:"schema"`
+	Table   string   `toml:"table" json:"table"`
+	Indexes []string `toml:"indexes" json:"indexes"`
+	Columns []string `toml:"columns" json:"columns"`
+}
+
+// IgnoresIndex returns whether the index of the table is excluded from the
+// duplicate detection by the rule. The tableName is quoted like "`db`.`tbl`",
+// and the names are compared case-insensitively.
+func (r *DuplicateIgnoreRule) IgnoresIndex(tableName string, index *model.IndexInfo) bool {
+	if !strings.EqualFold(common.UniqueTable(r.Schema, r.Table), tableName) {
+		return false
+	}
+	for _, name := range r.Indexes {
+		if strings.EqualFold(name, index.Name.O) {
+			return true
+		}
+	}
+	for _, name := range r.Columns {
+		for _, col := range index.Columns {
+			if strings.EqualFold(name, col.Name.O) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MergeColumnsRule lists the columns of a table that are updated from the incoming
+// row when it conflicts with an existing row, the other columns are kept as they are.
+type MergeColumnsRule struct {
+	Schema  string   `toml:"schema" json:"schema"`
+	Table   string   `toml:"table" json:"table"`
+	Columns []string `toml:"columns" json:"columns"`
+}
+
+// MatchesTable returns whether the rule applies to the table. The tableName is
+// quoted like "`db`.`tbl`", and the names are compared case-insensitively.
+func (r *MergeColumnsRule) MatchesTable(tableName string) bool {
+	return strings.EqualFold(common.UniqueTable(r.Schema, r.Table), tableName)
+}
+
 type Checkpoint struct {
 	Schema           string                 `toml:"schema" json:"schema"`
 	DSN              string                 `toml:"dsn" json:"-"` // DSN may contain password, don't expose this to JSON.
 	Driver           string                 `toml:"driver" json:"driver"`
 	Enable           bool                   `toml:"enable" json:"enable"`
 	KeepAfterSuccess CheckpointKeepStrategy `toml:"keep-after-success" json:"keep-after-success"`
+	// Compression is the compression of the checkpoint file, only used by the "File" driver.
+	Compression string `toml:"compression" json:"compression"`
+	// UpdateInterval is the minimum interval between two checkpoint updates. The
+	// changes in the interval are merged into one update.
+	UpdateInterval Duration `toml:"update-interval" json:"update-interval"`
 }
 
 type Cron struct {
@@ -549,12 +1025,33 @@ type Cron struct {
 	CheckDiskQuota Duration `toml:"check-disk-quota" json:"check-disk-quota"`
 }
 
+// Notification is a hook notified of the events of the task, so the unattended
+// imports don't fail silently.
+type Notification struct {
+	// Type is one of "webhook", "slack" and "pagerduty".
+	Type string `toml:"type" json:"type"`
+	// URL is the endpoint the notifications are posted to. It defaults to the
+	// Events API v2 endpoint for PagerDuty.
+	URL string `toml:"url" json:"-"`
+	// RoutingKey is the integration key of the PagerDuty service.
+	RoutingKey string `toml:"routing-key" json:"-"`
+	// Events are the events to notify, or all the events if empty.
+	Events []string `toml:"events" json:"events"`
+	// Template is a Go text/template executed with the event to make the request body
+	// of the webhook, the message of Slack or the summary of PagerDuty.
+	Template string   `toml:"template" json:"template"`
+	Timeout  Duration `toml:"timeout" json:"timeout"`
+}
+
 type Security struct {
 	CAPath   string `toml:"ca-path" json:"ca-path"`
 	CertPath string `toml:"cert-path" json:"cert-path"`
 	KeyPath  string `toml:"key-path" json:"key-path"`
 	// RedactInfoLog indicates that whether enabling redact log
 	RedactInfoLog bool `toml:"redact-info-log" json:"redact-info-log"`
+	// FIPSMode restricts the TLS connections and the checksums of the data files to the
+	// algorithms approved by FIPS 140-2.
+	FIPSMode bool `toml:"fips-mode" json:"fips-mode"`
 
 	// TLSConfigName is used to set tls config for lightning in DM, so we don't expose this field to user
 	// DM may running many lightning instances at same time, so we need to set different tls config name for each lightning
@@ -571,6 +1068,9 @@ func (sec *Security) RegisterMySQL() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if sec.FIPSMode {
+		common.RestrictTLSToFIPS(tlsConfig)
+	}
 	if tlsConfig != nil {
 		// error happens only when the key coincides with the built-in names.
 		_ = gomysql.RegisterTLSConfig(sec.TLSConfigName, tlsConfig)
@@ -661,6 +1161,8 @@ func NewConfig() *Config {
 				Conflict: *atomic.NewInt64(math.MaxInt64),
 			},
 			TaskInfoSchemaName: defaultTaskInfoSchemaName,
+			TaskPriority:       PriorityNormal,
+			ForeignKeyMode:     ForeignKeyModeOff,
 		},
 		Checkpoint: Checkpoint{
 			Enable: true,
@@ -691,12 +1193,15 @@ func NewConfig() *Config {
 				Null:            `\N`,
 				BackslashEscape: true,
 				TrimLastSep:     false,
+
+				InferSchemaSampleRows: InferSchemaSampleRows,
 			},
 			StrictFormat:           false,
 			MaxRegionSize:          MaxRegionSize,
 			Filter:                 DefaultFilter,
 			DataCharacterSet:       defaultCSVDataCharacterSet,
 			DataInvalidCharReplace: string(defaultCSVDataInvalidCharReplace),
+			DataInvalidCharAction:  InvalidCharReplace,
 		},
 		TikvImporter: TikvImporter{
 			Backend:             "",
@@ -706,6 +1211,8 @@ func NewConfig() *Config {
 			RegionSplitSize:     0,
 			DiskQuota:           ByteSize(math.MaxInt64),
 			DuplicateResolution: DupeResAlgNone,
+			Priority:            PriorityNormal,
+			RegisterLogBackup:   true,
 		},
 		PostRestore: PostRestore{
 			Checksum:          OpLevelRequired,
@@ -738,6 +1245,7 @@ func (cfg *Config) LoadFromGlobal(global *GlobalConfig) error {
 	cfg.App.CheckRequirements = global.App.CheckRequirements
 	cfg.Security = global.Security
 	cfg.Mydumper.IgnoreColumns = global.Mydumper.IgnoreColumns
+	cfg.RouteOverrides = global.RouteOverrides
 	return nil
 }
 
@@ -825,6 +1333,30 @@ func (cfg *Config) Adjust(ctx context.Context) error {
 		}
 	}
 
+	if csv.InferSchema && !csv.Header {
+		return common.ErrInvalidConfig.GenWithStack("`mydumper.csv.infer-schema` requires `mydumper.csv.header` to be true")
+	}
+	if csv.InferSchema && csv.InferSchemaSampleRows <= 0 {
+		return common.ErrInvalidConfig.GenWithStack("`mydumper.csv.infer-schema-sample-rows` must be positive")
+	}
+
+	if csv.MaxRejectedRows < 0 {
+		return common.ErrInvalidConfig.GenWithStack("`mydumper.csv.max-rejected-rows` must not be negative")
+	}
+
+	columnNulls := make(map[string]struct{}, len(csv.ColumnNulls))
+	for _, columnNull := range csv.ColumnNulls {
+		if len(columnNull.Column) == 0 {
+			return common.ErrInvalidConfig.GenWithStack("`mydumper.csv.column-null.column` must not be empty")
+		}
+		// column names from the data files are always in lower-case.
+		columnNull.Column = strings.ToLower(columnNull.Column)
+		if _, ok := columnNulls[columnNull.Column]; ok {
+			return common.ErrInvalidConfig.GenWithStack("duplicated column '%s' in `mydumper.csv.column-null`", columnNull.Column)
+		}
+		columnNulls[columnNull.Column] = struct{}{}
+	}
+
 	// adjust file routing
 	for _, rule := range cfg.Mydumper.FileRouters {
 		if filepath.IsAbs(rule.Path) {
@@ -840,6 +1372,10 @@ func (cfg *Config) Adjust(ctx context.Context) error {
 			}
 			rule.Path = relPath
 		}
+		if len(rule.Columns) > 0 && cfg.Mydumper.CSV.Header {
+			return common.ErrInvalidConfig.GenWithStack(
+				"`columns` of the file route rule can't be set when `mydumper.csv.header` is true, the columns are read from the header")
+		}
 	}
 
 	// enable default file route rule if no rules are set
@@ -854,7 +1390,16 @@ func (cfg *Config) Adjust(ctx context.Context) error {
 	if err1 != nil {
 		return common.ErrInvalidConfig.Wrap(err1).GenWithStack("invalid `mydumper.data-character-set`")
 	}
-	if charset == GBK || charset == GB18030 {
+	cfg.Mydumper.DataInvalidCharAction = strings.ToLower(cfg.Mydumper.DataInvalidCharAction)
+	switch cfg.Mydumper.DataInvalidCharAction {
+	case "":
+		cfg.Mydumper.DataInvalidCharAction = InvalidCharReplace
+	case InvalidCharReplace, InvalidCharError, InvalidCharSkip:
+	default:
+		return common.ErrInvalidConfig.GenWithStack(
+			"unsupported `mydumper.data-invalid-char-action` (%s)", cfg.Mydumper.DataInvalidCharAction)
+	}
+	if (charset == GBK || charset == GB18030) && cfg.Mydumper.DataInvalidCharAction == InvalidCharReplace {
 		log.L().Warn(
 			"incompatible strings may be encountered during the transcoding process and will be replaced, please be aware of the risk of not being able to retain the original information",
 			zap.String("source-character-set", charset.String()),
@@ -882,15 +1427,149 @@ func (cfg *Config) Adjust(ctx context.Context) error {
 			return common.ErrInvalidConfig.Wrap(err).GenWithStack("file route rule is invalid")
 		}
 	}
+	for _, rule := range cfg.RouteOverrides {
+		if !cfg.Mydumper.CaseSensitive {
+			rule.ToLower()
+		}
+		if err := rule.Valid(); err != nil {
+			return common.ErrInvalidConfig.Wrap(err).GenWithStack("route given by the command line is invalid")
+		}
+	}
+
+	if cfg.Mydumper.PreSplit && !cfg.Mydumper.StrictFormat {
+		return common.ErrInvalidConfig.GenWithStack("`mydumper.pre-split` requires `mydumper.strict-format` to be true")
+	}
+
+	if err := cfg.checkWatchMode(); err != nil {
+		return err
+	}
+	if err := cfg.checkColumnTransforms(); err != nil {
+		return err
+	}
+	if err := cfg.checkRowFilters(); err != nil {
+		return err
+	}
+	if err := cfg.checkChecksumAlgorithm(); err != nil {
+		return err
+	}
+	if err := cfg.checkSchemaRewrite(); err != nil {
+		return err
+	}
+	if err := cfg.checkVirtualShard(); err != nil {
+		return err
+	}
+	if err := cfg.checkNotifications(); err != nil {
+		return err
+	}
 
 	if err := cfg.CheckAndAdjustTiDBPort(ctx, mustHaveInternalConnections); err != nil {
 		return err
 	}
 	cfg.AdjustMydumper()
 	cfg.AdjustCheckPoint()
+	if err := cfg.checkCheckpoint(); err != nil {
+		return err
+	}
 	return cfg.CheckAndAdjustFilePath()
 }
 
+func (cfg *Config) checkCheckpoint() error {
+	cfg.Checkpoint.Compression = strings.ToLower(cfg.Checkpoint.Compression)
+	switch cfg.Checkpoint.Compression {
+	case "":
+	case CheckpointCompressionZstd:
+		if cfg.Checkpoint.Driver != CheckpointDriverFile {
+			return common.ErrInvalidConfig.GenWithStack("`checkpoint.compression` is only supported by the \"file\" driver")
+		}
+	default:
+		return common.ErrInvalidConfig.GenWithStack("unsupported `checkpoint.compression` (%s)", cfg.Checkpoint.Compression)
+	}
+	if cfg.Checkpoint.UpdateInterval.Duration < 0 {
+		return common.ErrInvalidConfig.GenWithStack("`checkpoint.update-interval` must not be negative")
+	}
+	return nil
+}
+
+// checkWatchMode checks the configurations required by the watch mode, in which
+// the newly arrived files are imported into the non-empty tables and the imported
+// files are tracked by the checkpoints.
+func (cfg *Config) checkWatchMode() error {
+	if cfg.Mydumper.WatchInterval.Duration < 0 {
+		return common.ErrInvalidConfig.GenWithStack("`mydumper.watch-interval` must not be negative")
+	}
+	if cfg.Mydumper.WatchInterval.Duration == 0 {
+		if cfg.Mydumper.WatchGrowingFiles {
+			return common.ErrInvalidConfig.GenWithStack("`mydumper.watch-growing-files` requires `mydumper.watch-interval` to be positive")
+		}
+		return nil
+	}
+	if !cfg.Checkpoint.Enable {
+		return common.ErrInvalidConfig.GenWithStack("`mydumper.watch-interval` requires `checkpoint.enable` to be true")
+	}
+	if cfg.Checkpoint.KeepAfterSuccess == CheckpointOrigin {
+		return common.ErrInvalidConfig.GenWithStack("`mydumper.watch-interval` can't be used with `checkpoint.keep-after-success = \"origin\"`")
+	}
+	if cfg.TikvImporter.Backend == BackendLocal && !cfg.TikvImporter.IncrementalImport {
+		return common.ErrInvalidConfig.GenWithStack("`mydumper.watch-interval` requires `tikv-importer.incremental-import` to be true for the local backend")
+	}
+	return nil
+}
+
+// checkNotifications checks the notification hooks and fills in their defaults.
+func (cfg *Config) checkNotifications() error {
+	for i, n := range cfg.Notifications {
+		n.Type = strings.ToLower(n.Type)
+		switch n.Type {
+		case NotificationWebhook, NotificationSlack:
+			if len(n.URL) == 0 {
+				return common.ErrInvalidConfig.GenWithStack("`notification.url` is required for the %s notification #%d", n.Type, i+1)
+			}
+		case NotificationPagerDuty:
+			if len(n.RoutingKey) == 0 {
+				return common.ErrInvalidConfig.GenWithStack("`notification.routing-key` is required for the pagerduty notification #%d", i+1)
+			}
+			if len(n.URL) == 0 {
+				n.URL = defaultPagerDutyURL
+			}
+		default:
+			return common.ErrInvalidConfig.GenWithStack("unsupported `notification.type` (%s)", n.Type)
+		}
+		for _, event := range n.Events {
+			switch event {
+			case EventTaskCompleted, EventTableFailed, EventChecksumMismatch:
+			default:
+				return common.ErrInvalidConfig.GenWithStack("unsupported event '%s' in `notification.events`", event)
+			}
+		}
+		if _, err := template.New("notification").Parse(n.Template); err != nil {
+			return common.ErrInvalidConfig.Wrap(err).GenWithStack("invalid `notification.template` of notification #%d", i+1)
+		}
+		if n.Timeout.Duration <= 0 {
+			n.Timeout.Duration = defaultNotificationTimeout
+		}
+	}
+	return nil
+}
+
+// checkVirtualShard checks the virtual shard, whose instances import into the same
+// tables in parallel.
+func (cfg *Config) checkVirtualShard() error {
+	shard := cfg.Mydumper.VirtualShard
+	if shard.Count < 0 {
+		return common.ErrInvalidConfig.GenWithStack("`mydumper.virtual-shard.count` must not be negative")
+	}
+	if shard.Count <= 1 {
+		return nil
+	}
+	if shard.Index < 0 || shard.Index >= shard.Count {
+		return common.ErrInvalidConfig.GenWithStack("`mydumper.virtual-shard.index` must be in [0, %d)", shard.Count)
+	}
+	if cfg.TikvImporter.Backend == BackendLocal && !cfg.TikvImporter.IncrementalImport {
+		return common.ErrInvalidConfig.GenWithStack("`mydumper.virtual-shard` requires `tikv-importer.incremental-import` to be true for the local backend")
+	}
+	return nil
+}
+
 func (cfg *Config) AdjustCommon() (bool, error) {
 	if cfg.TikvImporter.Backend == "" {
 		return false, common.ErrInvalidConfig.GenWithStack("tikv-importer.backend must not be empty!")
@@ -923,6 +1602,38 @@ func (cfg *Config) AdjustCommon() (bool, error) {
 		cfg.TikvImporter.LocalWriterMemCacheSize = defaultLocalWriterMemCacheSize
 	}
 
+	cfg.TikvImporter.Priority = strings.ToLower(cfg.TikvImporter.Priority)
+	switch cfg.TikvImporter.Priority {
+	case "":
+		cfg.TikvImporter.Priority = PriorityNormal
+	case PriorityLow, PriorityNormal, PriorityHigh:
+	default:
+		return mustHaveInternalConnections, common.ErrInvalidConfig.GenWithStack(
+			"unsupported `tikv-importer.priority` (%s)", cfg.TikvImporter.Priority)
+	}
+	cfg.App.TaskPriority = strings.ToLower(cfg.App.TaskPriority)
+	switch cfg.App.TaskPriority {
+	case "":
+		cfg.App.TaskPriority = PriorityNormal
+	case PriorityLow, PriorityNormal, PriorityHigh:
+	default:
+		return mustHaveInternalConnections, common.ErrInvalidConfig.GenWithStack(
+			"unsupported `lightning.task-priority` (%s)", cfg.App.TaskPriority)
+	}
+	cfg.App.ForeignKeyMode = strings.ToLower(cfg.App.ForeignKeyMode)
+	switch cfg.App.ForeignKeyMode {
+	case "":
+		cfg.App.ForeignKeyMode = ForeignKeyModeOff
+	case ForeignKeyModeOff, ForeignKeyModeOrdered:
+	default:
+		return mustHaveInternalConnections, common.ErrInvalidConfig.GenWithStack(
+			"unsupported `lightning.foreign-key-mode` (%s)", cfg.App.ForeignKeyMode)
+	}
+
+	if err := cfg.checkThrottleSchedule(); err != nil {
+		return mustHaveInternalConnections, err
+	}
+
 	if cfg.TikvImporter.Backend == BackendLocal {
 		if err := cfg.CheckAndAdjustForLocalBackend(); err != nil {
 			return mustHaveInternalConnections, err
@@ -934,11 +1645,17 @@ func (cfg *Config) AdjustCommon() (bool, error) {
 	if cfg.TikvImporter.Backend == BackendTiDB {
 		cfg.TikvImporter.OnDuplicate = strings.ToLower(cfg.TikvImporter.OnDuplicate)
 		switch cfg.TikvImporter.OnDuplicate {
-		case ReplaceOnDup, IgnoreOnDup, ErrorOnDup:
+		case ReplaceOnDup, IgnoreOnDup, ErrorOnDup, MergeOnDup:
 		default:
 			return mustHaveInternalConnections, common.ErrInvalidConfig.GenWithStack(
 				"unsupported `tikv-importer.on-duplicate` (%s)", cfg.TikvImporter.OnDuplicate)
 		}
+		if err := cfg.checkMergeColumns(); err != nil {
+			return mustHaveInternalConnections, err
+		}
+	}
+	if err := cfg.checkSessionVars(); err != nil {
+		return mustHaveInternalConnections, err
 	}
 
 	var err error
@@ -953,11 +1670,205 @@ func (cfg *Config) AdjustCommon() (bool, error) {
 	return mustHaveInternalConnections, err
 }
 
+func (cfg *Config) checkThrottleSchedule() error {
+	for _, w := range cfg.App.ThrottleSchedule {
+		var err error
+		if w.start, err = parseTimeOfDay(w.Start); err != nil {
+			return common.ErrInvalidConfig.Wrap(err).GenWithStack(
+				"invalid `lightning.throttle-schedule.start` (%s), the format should be like \"09:00\"", w.Start)
+		}
+		if w.end, err = parseTimeOfDay(w.End); err != nil {
+			return common.ErrInvalidConfig.Wrap(err).GenWithStack(
+				"invalid `lightning.throttle-schedule.end` (%s), the format should be like \"18:00\"", w.End)
+		}
+		if w.Ratio == 0 {
+			w.Ratio = 1
+		}
+		if w.Ratio < 0 || w.Ratio > 1 {
+			return common.ErrInvalidConfig.GenWithStack(
+				"`lightning.throttle-schedule.ratio` (%v) must be in (0, 1]", w.Ratio)
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) checkMergeColumns() error {
+	if len(cfg.TikvImporter.MergeColumns) > 0 && cfg.TikvImporter.OnDuplicate != MergeOnDup {
+		return common.ErrInvalidConfig.GenWithStack(
+			"`tikv-importer.merge-columns` can only be used when `tikv-importer.on-duplicate` is \"merge\"")
+	}
+	for _, rule := range cfg.TikvImporter.MergeColumns {
+		if len(rule.Schema) == 0 || len(rule.Table) == 0 {
+			return common.ErrInvalidConfig.GenWithStack("`tikv-importer.merge-columns.schema` and `tikv-importer.merge-columns.table` must not be empty")
+		}
+		if len(rule.Columns) == 0 {
+			return common.ErrInvalidConfig.GenWithStack(
+				"`tikv-importer.merge-columns` of `%s`.`%s` must have at least one column", rule.Schema, rule.Table)
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) checkSessionVars() error {
+	if len(cfg.TikvImporter.SessionVars) == 0 {
+		return nil
+	}
+	if cfg.TikvImporter.Backend != BackendTiDB {
+		return common.ErrInvalidConfig.GenWithStack(
+			"`tikv-importer.session-vars` is only supported by the tidb backend")
+	}
+	vars := make(map[string]string, len(cfg.TikvImporter.SessionVars))
+	for name, value := range cfg.TikvImporter.SessionVars {
+		// the names are put into the SET statements and the DSN as they are.
+		if !sessionVarNameRegexp.MatchString(name) {
+			return common.ErrInvalidConfig.GenWithStack("invalid session variable `%s` in `tikv-importer.session-vars`", name)
+		}
+		// the values are quoted by single quotes.
+		if strings.ContainsAny(value, `'\`) {
+			return common.ErrInvalidConfig.GenWithStack("the value of session variable `%s` in `tikv-importer.session-vars` must not contain quotes or backslashes", name)
+		}
+		name = strings.ToLower(name)
+		if name == "sql_mode" {
+			if _, err := mysql.GetSQLMode(value); err != nil {
+				return common.ErrInvalidConfig.Wrap(err).GenWithStack("`tikv-importer.session-vars.sql_mode` must be a valid SQL_MODE")
+			}
+		}
+		vars[name] = value
+	}
+	cfg.TikvImporter.SessionVars = vars
+	return nil
+}
+
+// checkChecksumAlgorithm checks the algorithm of the checksum sidecar files, which must be
+// approved by FIPS in the FIPS mode.
+func (cfg *Config) checkChecksumAlgorithm() error {
+	cfg.Mydumper.ChecksumAlgorithm = strings.ToLower(cfg.Mydumper.ChecksumAlgorithm)
+	switch cfg.Mydumper.ChecksumAlgorithm {
+	case "":
+		if cfg.Security.FIPSMode {
+			cfg.Mydumper.ChecksumAlgorithm = "sha256"
+		}
+	case "sha256", "crc64":
+	case "md5":
+		if cfg.Security.FIPSMode {
+			return common.ErrInvalidConfig.GenWithStack(
+				"`mydumper.checksum-algorithm` (md5) is not allowed in the FIPS mode")
+		}
+	default:
+		return common.ErrInvalidConfig.GenWithStack(
+			"unsupported `mydumper.checksum-algorithm` (%s)", cfg.Mydumper.ChecksumAlgorithm)
+	}
+	return nil
+}
+
+func (cfg *Config) checkColumnTransforms() error {
+	if len(cfg.Mydumper.ColumnTransforms) == 0 {
+		return nil
+	}
+	// the expressions are evaluated by the KV encoder, the TiDB backend encodes the rows as SQL.
+	if cfg.TikvImporter.Backend != BackendLocal {
+		return common.ErrInvalidConfig.GenWithStack(
+			"`mydumper.column-transforms` is only supported by the local backend")
+	}
+	for _, t := range cfg.Mydumper.ColumnTransforms {
+		if len(t.Column) == 0 || len(strings.TrimSpace(t.Expr)) == 0 {
+			return common.ErrInvalidConfig.GenWithStack(
+				"`mydumper.column-transforms.column` and `mydumper.column-transforms.expr` must not be empty")
+		}
+		// column names are compared by Name.L in TiDB.
+		t.Column = strings.ToLower(t.Column)
+	}
+	return nil
+}
+
+// checkRowFilters checks the rules of `mydumper.row-filters`. The columns in the filters
+// are checked against the source files when they are read, since each table has its own.
+func (cfg *Config) checkRowFilters() error {
+	for _, rf := range cfg.Mydumper.RowFilters {
+		if len(strings.TrimSpace(rf.Filter)) == 0 {
+			return common.ErrInvalidConfig.GenWithStack("`mydumper.row-filters.filter` must not be empty")
+		}
+		if len(rf.TableFilter) > 0 {
+			if _, err := filter.Parse(rf.TableFilter); err != nil {
+				return common.ErrInvalidConfig.Wrap(err).GenWithStack(
+					"invalid table filter %s in row filters", strings.Join(rf.TableFilter, ","))
+			}
+		}
+		if _, err := ParseRowFilter(rf.Filter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSchemaRewrite checks the rules of `mydumper.schema-rewrite`. The charsets and
+// collations are replaced in the text of the schema files, since the parser rejects the
+// unknown ones, so the names must be plain words and the replacements must be supported.
+func (cfg *Config) checkSchemaRewrite() error {
+	rewrite := &cfg.Mydumper.SchemaRewrite
+	var err error
+	rewrite.Charsets, err = adjustSchemaRewriteRules(rewrite.Charsets, "charsets", func(name string) error {
+		_, err := charset.GetCharsetInfo(name)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	rewrite.Collations, err = adjustSchemaRewriteRules(rewrite.Collations, "collations", func(name string) error {
+		_, err := charset.GetCollationByName(name)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// TiDB accepts any storage engine, the engines are replaced in the parsed statements.
+	rewrite.Engines, err = adjustSchemaRewriteRules(rewrite.Engines, "engines", nil)
+	return err
+}
+
+func adjustSchemaRewriteRules(rules map[string]string, kind string, check func(name string) error) (map[string]string, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	res := make(map[string]string, len(rules))
+	for from, to := range rules {
+		if !schemaRewriteNameRegexp.MatchString(from) || !schemaRewriteNameRegexp.MatchString(to) {
+			return nil, common.ErrInvalidConfig.GenWithStack(
+				"invalid rule `%s = \"%s\"` in `mydumper.schema-rewrite.%s`", from, to, kind)
+		}
+		if check != nil {
+			if err := check(to); err != nil {
+				return nil, common.ErrInvalidConfig.Wrap(err).GenWithStack(
+					"`%s` in `mydumper.schema-rewrite.%s` is not supported by TiDB", to, kind)
+			}
+		}
+		res[strings.ToLower(from)] = to
+	}
+	return res, nil
+}
+
 func (cfg *Config) CheckAndAdjustForLocalBackend() error {
 	if len(cfg.TikvImporter.SortedKVDir) == 0 {
 		return common.ErrInvalidConfig.GenWithStack("tikv-importer.sorted-kv-dir must not be empty!")
 	}
 
+	for _, rule := range cfg.TikvImporter.DuplicateIgnore {
+		if len(rule.Schema) == 0 || len(rule.Table) == 0 {
+			return common.ErrInvalidConfig.GenWithStack("`tikv-importer.duplicate-ignore.schema` and `tikv-importer.duplicate-ignore.table` must not be empty")
+		}
+		if len(rule.Indexes) == 0 && len(rule.Columns) == 0 {
+			return common.ErrInvalidConfig.GenWithStack(
+				"`tikv-importer.duplicate-ignore` of `%s`.`%s` must have at least one of `indexes` and `columns`", rule.Schema, rule.Table)
+		}
+	}
+
+	if err := cfg.checkSpillDirs(); err != nil {
+		return err
+	}
+	if err := cfg.checkEncryption(); err != nil {
+		return err
+	}
+
 	storageSizeDir := filepath.Clean(cfg.TikvImporter.SortedKVDir)
 	sortedKVDirInfo, err := os.Stat(storageSizeDir)
 
@@ -976,6 +1887,66 @@ func (cfg *Config) CheckAndAdjustForLocalBackend() error {
 	return nil
 }
 
+// checkSpillDirs checks the spill directories are distinct from each other and the
+// sorted-kv-dir, and each of them has a positive disk quota.
+func (cfg *Config) checkSpillDirs() error {
+	seen := map[string]struct{}{filepath.Clean(cfg.TikvImporter.SortedKVDir): {}}
+	for i := range cfg.TikvImporter.SpillDirs {
+		dir := &cfg.TikvImporter.SpillDirs[i]
+		if len(dir.Path) == 0 {
+			return common.ErrInvalidConfig.GenWithStack("`tikv-importer.spill-dirs.path` must not be empty")
+		}
+		dir.Path = filepath.Clean(dir.Path)
+		if _, ok := seen[dir.Path]; ok {
+			return common.ErrInvalidConfig.GenWithStack(
+				"`tikv-importer.spill-dirs.path` ('%s') is duplicated with the sorted-kv-dir or another spill dir", dir.Path)
+		}
+		seen[dir.Path] = struct{}{}
+		if dir.DiskQuota <= 0 {
+			return common.ErrInvalidConfig.GenWithStack(
+				"`tikv-importer.spill-dirs.disk-quota` of '%s' must be positive", dir.Path)
+		}
+		if info, err := os.Stat(dir.Path); err == nil && !info.IsDir() {
+			return common.ErrInvalidConfig.
+				GenWithStack("`tikv-importer.spill-dirs.path` ('%s') is not a directory", dir.Path)
+		}
+	}
+	return nil
+}
+
+// checkEncryption checks the encryption method of the sorted KV files, and exactly one of
+// the key, the key file and the key ring is set if it's encrypted.
+func (cfg *Config) checkEncryption() error {
+	enc := &cfg.TikvImporter.Encryption
+	enc.Method = strings.ToLower(enc.Method)
+	switch enc.Method {
+	case "", EncryptionPlaintext:
+		return nil
+	case EncryptionAES128CTR, EncryptionAES192CTR, EncryptionAES256CTR:
+	default:
+		return common.ErrInvalidConfig.GenWithStack(
+			"unsupported `tikv-importer.encryption.method` (%s), should be one of plaintext, aes128-ctr, aes192-ctr and aes256-ctr", enc.Method)
+	}
+	keySources := 0
+	for _, s := range []string{enc.Key, enc.KeyFile, enc.KeyRing} {
+		if len(s) > 0 {
+			keySources++
+		}
+	}
+	if keySources != 1 {
+		return common.ErrInvalidConfig.GenWithStack(
+			"exactly one of `tikv-importer.encryption.key`, `key-file` and `key-ring` should be set")
+	}
+	if len(enc.Key) > 0 {
+		key, err := hex.DecodeString(enc.Key)
+		if err != nil || len(key) != enc.KeyLen() {
+			return common.ErrInvalidConfig.GenWithStack(
+				"`tikv-importer.encryption.key` should be a hex-encoded %d-bit key for %s", enc.KeyLen()*8, enc.Method)
+		}
+	}
+	return nil
+}
+
 func (cfg *Config) DefaultVarsForTiDBBackend() {
 	if cfg.App.TableConcurrency == 0 {
 		cfg.App.TableConcurrency = cfg.App.RegionConcurrency
@@ -19,6 +19,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -31,7 +32,9 @@ import (
 
 	"github.com/BurntSushi/toml"
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
+	router "github.com/pingcap/tidb/util/table-router"
 	"github.com/stretchr/testify/require"
 )
 
@@ -211,6 +214,24 @@ func TestAdjustFileRoutePath(t *testing.T) {
 	require.Equal(t, relPath, cfg.Mydumper.FileRouters[0].Path)
 }
 
+func TestAdjustFileRouteColumns(t *testing.T) {
+	cfg := config.NewConfig()
+	assignMinimalLegalValue(cfg)
+
+	ctx := context.Background()
+	cfg.Mydumper.SourceDir = t.TempDir()
+	cfg.Mydumper.FileRouters = []*config.FileRouteRule{
+		{Pattern: `^db\.tbl\.csv$`, Type: "csv", Schema: "db", Table: "tbl", Columns: []string{"b", "a"}},
+	}
+	cfg.TiDB.DistSQLScanConcurrency = 1
+	cfg.Mydumper.CSV.Header = true
+	err := cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "`columns` of the file route rule can't be set when `mydumper.csv.header` is true")
+
+	cfg.Mydumper.CSV.Header = false
+	require.NoError(t, cfg.Adjust(ctx))
+}
+
 func TestDecodeError(t *testing.T) {
 	ts, host, port := startMockServer(t, http.StatusOK, "invalid-string")
 	defer ts.Close()
@@ -464,6 +485,71 @@ func TestInvalidCSV(t *testing.T) {
 			`,
 			err: "[Lightning:Config:ErrInvalidConfig]cannot use '\\' as CSV delimiter when `mydumper.csv.backslash-escape` is true",
 		},
+		{
+			input: `
+				[[mydumper.csv.column-null]]
+				null = ['']
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`mydumper.csv.column-null.column` must not be empty",
+		},
+		{
+			input: `
+				[[mydumper.csv.column-null]]
+				column = 'a'
+				null = ['']
+				[[mydumper.csv.column-null]]
+				column = 'A'
+				unquoted-empty-as-null = true
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]duplicated column 'a' in `mydumper.csv.column-null`",
+		},
+		{
+			input: `
+				[mydumper.csv]
+				header = false
+				infer-schema = true
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`mydumper.csv.infer-schema` requires `mydumper.csv.header` to be true",
+		},
+		{
+			input: `
+				[mydumper.csv]
+				infer-schema = true
+				infer-schema-sample-rows = 0
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`mydumper.csv.infer-schema-sample-rows` must be positive",
+		},
+		{
+			input: `
+				[mydumper.csv]
+				rejected-rows-dir = "/tmp/rejected"
+				max-rejected-rows = -1
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`mydumper.csv.max-rejected-rows` must not be negative",
+		},
+		{
+			input: `
+				[mydumper]
+				pre-split = true
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`mydumper.pre-split` requires `mydumper.strict-format` to be true",
+		},
+		{
+			input: `
+				[mydumper.virtual-shard]
+				index = 2
+				count = 2
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`mydumper.virtual-shard.index` must be in [0, 2)",
+		},
+		{
+			input: `
+				[mydumper.virtual-shard]
+				index = 1
+				count = 2
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`mydumper.virtual-shard` requires `tikv-importer.incremental-import` to be true for the local backend",
+		},
 		{
 			input: `
 				[tidb]
@@ -510,6 +596,239 @@ func TestInvalidCSV(t *testing.T) {
 	}
 }
 
+func TestWatchMode(t *testing.T) {
+	testCases := []struct {
+		input string
+		err   string
+	}{
+		{
+			input: `
+				[mydumper]
+				watch-interval = "1m"
+				[checkpoint]
+				enable = false
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`mydumper.watch-interval` requires `checkpoint.enable` to be true",
+		},
+		{
+			input: `
+				[mydumper]
+				watch-interval = "1m"
+				[checkpoint]
+				keep-after-success = "origin"
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`mydumper.watch-interval` can't be used with `checkpoint.keep-after-success = \"origin\"`",
+		},
+		{
+			input: `
+				[mydumper]
+				watch-interval = "1m"
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`mydumper.watch-interval` requires `tikv-importer.incremental-import` to be true for the local backend",
+		},
+		{
+			input: `
+				[mydumper]
+				watch-interval = "1m"
+				[tikv-importer]
+				incremental-import = true
+			`,
+		},
+		{
+			input: `
+				[mydumper]
+				watch-interval = "1m"
+				[tikv-importer]
+				backend = "tidb"
+			`,
+		},
+		{
+			input: `
+				[mydumper]
+				watch-interval = "1m"
+				watch-growing-files = true
+				[tikv-importer]
+				backend = "tidb"
+			`,
+		},
+		{
+			input: `
+				[mydumper]
+				watch-growing-files = true
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`mydumper.watch-growing-files` requires `mydumper.watch-interval` to be positive",
+		},
+	}
+
+	for _, tc := range testCases {
+		comment := fmt.Sprintf("input = %s", tc.input)
+		cfg := config.NewConfig()
+		cfg.Mydumper.SourceDir = "file://."
+		cfg.TiDB.Port = 4000
+		cfg.TiDB.PdAddr = "test.invalid:2379"
+		cfg.TikvImporter.Backend = config.BackendLocal
+		cfg.TikvImporter.SortedKVDir = "."
+		cfg.TiDB.DistSQLScanConcurrency = 1
+		err := cfg.LoadFromTOML([]byte(tc.input))
+		require.NoError(t, err)
+
+		err = cfg.Adjust(context.Background())
+		if tc.err != "" {
+			require.EqualError(t, err, tc.err, comment)
+		} else {
+			require.NoError(t, err, comment)
+			require.Equal(t, time.Minute, cfg.Mydumper.WatchInterval.Duration)
+		}
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	testCases := []struct {
+		input string
+		err   string
+	}{
+		{
+			input: `
+				[[notification]]
+				type = "email"
+				url = "mailto:dba@example.com"
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]unsupported `notification.type` (email)",
+		},
+		{
+			input: `
+				[[notification]]
+				type = "slack"
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`notification.url` is required for the slack notification #1",
+		},
+		{
+			input: `
+				[[notification]]
+				type = "pagerduty"
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`notification.routing-key` is required for the pagerduty notification #1",
+		},
+		{
+			input: `
+				[[notification]]
+				type = "webhook"
+				url = "http://hooks.example.com/hook"
+				events = ["table-failed", "task-started"]
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]unsupported event 'task-started' in `notification.events`",
+		},
+		{
+			input: `
+				[[notification]]
+				type = "webhook"
+				url = "http://hooks.example.com/hook"
+				template = "{{.Table"
+			`,
+			err: "invalid `notification.template` of notification #1",
+		},
+		{
+			input: `
+				[[notification]]
+				type = "Webhook"
+				url = "http://hooks.example.com/hook"
+				events = ["task-completed"]
+				template = '{"text": "{{.Summary}}"}'
+				[[notification]]
+				type = "pagerduty"
+				routing-key = "key"
+				timeout = "3s"
+			`,
+		},
+	}
+
+	for _, tc := range testCases {
+		comment := fmt.Sprintf("input = %s", tc.input)
+		cfg := config.NewConfig()
+		cfg.Mydumper.SourceDir = "file://."
+		cfg.TiDB.Port = 4000
+		cfg.TiDB.PdAddr = "test.invalid:2379"
+		cfg.TikvImporter.Backend = config.BackendLocal
+		cfg.TikvImporter.SortedKVDir = "."
+		cfg.TiDB.DistSQLScanConcurrency = 1
+		require.NoError(t, cfg.LoadFromTOML([]byte(tc.input)))
+
+		err := cfg.Adjust(context.Background())
+		if tc.err != "" {
+			require.ErrorContains(t, err, tc.err, comment)
+			continue
+		}
+		require.NoError(t, err, comment)
+		require.Len(t, cfg.Notifications, 2)
+		require.Equal(t, config.NotificationWebhook, cfg.Notifications[0].Type)
+		require.Equal(t, 10*time.Second, cfg.Notifications[0].Timeout.Duration)
+		require.Equal(t, "https://events.pagerduty.com/v2/enqueue", cfg.Notifications[1].URL)
+		require.Equal(t, 3*time.Second, cfg.Notifications[1].Timeout.Duration)
+		// the secrets are not logged.
+		require.NotContains(t, cfg.String(), "hooks.example.com")
+		require.NotContains(t, cfg.String(), `"key"`)
+	}
+}
+
+func TestCheckpointCompression(t *testing.T) {
+	testCases := []struct {
+		input string
+		err   string
+	}{
+		{
+			input: `
+				[checkpoint]
+				compression = "ZSTD"
+				update-interval = "5s"
+			`,
+		},
+		{
+			input: `
+				[checkpoint]
+				driver = "mysql"
+				compression = "zstd"
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`checkpoint.compression` is only supported by the \"file\" driver",
+		},
+		{
+			input: `
+				[checkpoint]
+				compression = "gzip"
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]unsupported `checkpoint.compression` (gzip)",
+		},
+		{
+			input: `
+				[checkpoint]
+				update-interval = "-1s"
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`checkpoint.update-interval` must not be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		comment := fmt.Sprintf("input = %s", tc.input)
+		cfg := config.NewConfig()
+		cfg.Mydumper.SourceDir = "file://."
+		cfg.TiDB.Port = 4000
+		cfg.TiDB.PdAddr = "test.invalid:2379"
+		cfg.TikvImporter.Backend = config.BackendLocal
+		cfg.TikvImporter.SortedKVDir = "."
+		cfg.TiDB.DistSQLScanConcurrency = 1
+		err := cfg.LoadFromTOML([]byte(tc.input))
+		require.NoError(t, err)
+
+		err = cfg.Adjust(context.Background())
+		if tc.err != "" {
+			require.EqualError(t, err, tc.err, comment)
+		} else {
+			require.NoError(t, err, comment)
+			require.Equal(t, config.CheckpointCompressionZstd, cfg.Checkpoint.Compression)
+			require.Equal(t, 5*time.Second, cfg.Checkpoint.UpdateInterval.Duration)
+		}
+	}
+}
+
 func TestInvalidTOML(t *testing.T) {
 	cfg := &config.Config{}
 	err := cfg.LoadFromTOML([]byte(`
@@ -633,6 +952,250 @@ func TestLoadConfig(t *testing.T) {
 	require.Equal(t, "-", cfg.App.Config.File)
 }
 
+func TestLoadRouteOverrides(t *testing.T) {
+	cfg, err := config.LoadGlobalConfig([]string{
+		"--route", "src.*->dst.*",
+		"--route", "a*.t1→b.t2",
+		"--route", "c.t*->d",
+		"--route", "e -> f.*",
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []*router.TableRule{
+		{SchemaPattern: "src", TargetSchema: "dst"},
+		{SchemaPattern: "a*", TablePattern: "t1", TargetSchema: "b", TargetTable: "t2"},
+		{SchemaPattern: "c", TablePattern: "t*", TargetSchema: "d"},
+		{SchemaPattern: "e", TargetSchema: "f"},
+	}, cfg.RouteOverrides)
+
+	taskCfg := config.NewConfig()
+	require.NoError(t, taskCfg.LoadFromGlobal(cfg))
+	require.Equal(t, cfg.RouteOverrides, taskCfg.RouteOverrides)
+
+	for _, route := range []string{"src.*", "src.*->*.*", "->dst", ".t->dst"} {
+		_, err = config.LoadGlobalConfig([]string{"--route", route}, nil)
+		require.Regexp(t, "invalid route", err)
+	}
+}
+
+func TestLoadDryRunPlan(t *testing.T) {
+	cfg, err := config.LoadGlobalConfig([]string{"--dry-run-plan"}, nil)
+	require.NoError(t, err)
+	require.True(t, cfg.App.DryRunPlan)
+	require.Equal(t, "table", cfg.App.DryRunPlanFormat)
+
+	cfg, err = config.LoadGlobalConfig([]string{"--dry-run-plan", "--dry-run-plan-format", "json"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "json", cfg.App.DryRunPlanFormat)
+
+	_, err = config.LoadGlobalConfig([]string{"--dry-run-plan", "--dry-run-plan-format", "yaml"}, nil)
+	require.Error(t, err)
+	_, err = config.LoadGlobalConfig([]string{"--dry-run-plan", "--server-mode", "--status-addr", ":8289"}, nil)
+	require.ErrorContains(t, err, "dry-run-plan can't be used in server mode")
+}
+
+func TestAdjustDuplicateIgnore(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.TikvImporter.Backend = config.BackendLocal
+	cfg.TikvImporter.SortedKVDir = "."
+	cfg.TikvImporter.DuplicateIgnore = []*config.DuplicateIgnoreRule{{Schema: "db", Indexes: []string{"uk"}}}
+	require.ErrorContains(t, cfg.CheckAndAdjustForLocalBackend(), "must not be empty")
+
+	cfg.TikvImporter.DuplicateIgnore = []*config.DuplicateIgnoreRule{{Schema: "db", Table: "tbl"}}
+	require.ErrorContains(t, cfg.CheckAndAdjustForLocalBackend(), "`db`.`tbl` must have at least one of `indexes` and `columns`")
+
+	cfg.TikvImporter.DuplicateIgnore = []*config.DuplicateIgnoreRule{{Schema: "db", Table: "tbl", Columns: []string{"a"}}}
+	require.NoError(t, cfg.CheckAndAdjustForLocalBackend())
+}
+
+func TestDuplicateIgnoreRule(t *testing.T) {
+	index := &model.IndexInfo{
+		Name:    model.NewCIStr("uk_ab"),
+		Columns: []*model.IndexColumn{{Name: model.NewCIStr("a")}, {Name: model.NewCIStr("B")}},
+	}
+	rule := &config.DuplicateIgnoreRule{Schema: "db", Table: "tbl", Indexes: []string{"UK_AB"}}
+	require.True(t, rule.IgnoresIndex("`db`.`tbl`", index))
+	require.True(t, rule.IgnoresIndex("`DB`.`Tbl`", index))
+	require.False(t, rule.IgnoresIndex("`db`.`tbl2`", index))
+
+	rule = &config.DuplicateIgnoreRule{Schema: "db", Table: "tbl", Columns: []string{"c", "b"}}
+	require.True(t, rule.IgnoresIndex("`db`.`tbl`", index))
+	rule = &config.DuplicateIgnoreRule{Schema: "db", Table: "tbl", Indexes: []string{"uk_c"}, Columns: []string{"c"}}
+	require.False(t, rule.IgnoresIndex("`db`.`tbl`", index))
+}
+
+func TestAdjustMergeColumns(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.TikvImporter.Backend = config.BackendTiDB
+	cfg.TikvImporter.OnDuplicate = config.ReplaceOnDup
+	cfg.TikvImporter.MergeColumns = []*config.MergeColumnsRule{{Schema: "db", Table: "tbl", Columns: []string{"a"}}}
+	_, err := cfg.AdjustCommon()
+	require.ErrorContains(t, err, "can only be used when `tikv-importer.on-duplicate` is \"merge\"")
+
+	cfg.TikvImporter.OnDuplicate = config.MergeOnDup
+	cfg.TikvImporter.MergeColumns = []*config.MergeColumnsRule{{Schema: "db", Table: "tbl"}}
+	_, err = cfg.AdjustCommon()
+	require.ErrorContains(t, err, "`db`.`tbl` must have at least one column")
+
+	cfg.TikvImporter.MergeColumns = []*config.MergeColumnsRule{{Schema: "db", Table: "tbl", Columns: []string{"a"}}}
+	_, err = cfg.AdjustCommon()
+	require.NoError(t, err)
+	require.True(t, cfg.TikvImporter.MergeColumns[0].MatchesTable("`DB`.`Tbl`"))
+	require.False(t, cfg.TikvImporter.MergeColumns[0].MatchesTable("`db`.`tbl2`"))
+}
+
+func TestAdjustSessionVars(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.NewConfig()
+	assignMinimalLegalValue(cfg)
+	cfg.TiDB.DistSQLScanConcurrency = 1
+	cfg.TikvImporter.SessionVars = map[string]string{"Foreign_Key_Checks": "0"}
+	err := cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "`tikv-importer.session-vars` is only supported by the tidb backend")
+
+	cfg.TikvImporter.Backend = config.BackendTiDB
+	cfg.TikvImporter.SessionVars = map[string]string{"Foreign_Key_Checks": "0", "tidb_dml_batch_size": "100"}
+	require.NoError(t, cfg.Adjust(ctx))
+	require.Equal(t, map[string]string{"foreign_key_checks": "0", "tidb_dml_batch_size": "100"}, cfg.TikvImporter.SessionVars)
+
+	cfg.TikvImporter.SessionVars = map[string]string{"sql_mode = ''; DROP TABLE t": "0"}
+	err = cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "invalid session variable")
+	cfg.TikvImporter.SessionVars = map[string]string{"time_zone": "'+08:00'"}
+	err = cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "must not contain quotes or backslashes")
+	cfg.TikvImporter.SessionVars = map[string]string{"sql_mode": "NOT_A_MODE"}
+	err = cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "`tikv-importer.session-vars.sql_mode` must be a valid SQL_MODE")
+}
+
+func TestAdjustSchemaRewrite(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.NewConfig()
+	assignMinimalLegalValue(cfg)
+	cfg.TiDB.DistSQLScanConcurrency = 1
+	cfg.Mydumper.SchemaRewrite = config.SchemaRewrite{
+		Charsets:   map[string]string{"UTF8MB3": "utf8mb4"},
+		Collations: map[string]string{"utf8mb3_General_CI": "utf8mb4_general_ci"},
+		Engines:    map[string]string{"MyISAM": "InnoDB"},
+	}
+	require.NoError(t, cfg.Adjust(ctx))
+	require.Equal(t, config.SchemaRewrite{
+		Charsets:   map[string]string{"utf8mb3": "utf8mb4"},
+		Collations: map[string]string{"utf8mb3_general_ci": "utf8mb4_general_ci"},
+		Engines:    map[string]string{"myisam": "InnoDB"},
+	}, cfg.Mydumper.SchemaRewrite)
+
+	cfg.Mydumper.SchemaRewrite = config.SchemaRewrite{Charsets: map[string]string{"utf8mb3": "ucs2"}}
+	err := cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "`ucs2` in `mydumper.schema-rewrite.charsets` is not supported by TiDB")
+	cfg.Mydumper.SchemaRewrite = config.SchemaRewrite{Collations: map[string]string{"utf8mb3_bin": "utf8mb4_foo"}}
+	err = cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "`utf8mb4_foo` in `mydumper.schema-rewrite.collations` is not supported by TiDB")
+	cfg.Mydumper.SchemaRewrite = config.SchemaRewrite{Engines: map[string]string{"My ISAM": "InnoDB"}}
+	err = cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "invalid rule `My ISAM = \"InnoDB\"` in `mydumper.schema-rewrite.engines`")
+}
+
+func TestAdjustChecksumAlgorithm(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.NewConfig()
+	assignMinimalLegalValue(cfg)
+	cfg.TiDB.DistSQLScanConcurrency = 1
+	cfg.Mydumper.ChecksumAlgorithm = "CRC64"
+	require.NoError(t, cfg.Adjust(ctx))
+	require.Equal(t, "crc64", cfg.Mydumper.ChecksumAlgorithm)
+
+	cfg.Mydumper.ChecksumAlgorithm = "sha1"
+	err := cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "unsupported `mydumper.checksum-algorithm` (sha1)")
+
+	// the checksums are restricted to the FIPS-approved algorithms in the FIPS mode.
+	cfg.Security.FIPSMode = true
+	cfg.Mydumper.ChecksumAlgorithm = ""
+	require.NoError(t, cfg.Adjust(ctx))
+	require.Equal(t, "sha256", cfg.Mydumper.ChecksumAlgorithm)
+	cfg.Mydumper.ChecksumAlgorithm = "md5"
+	err = cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "`mydumper.checksum-algorithm` (md5) is not allowed in the FIPS mode")
+}
+
+func TestAdjustColumnTransforms(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.NewConfig()
+	assignMinimalLegalValue(cfg)
+	cfg.TiDB.DistSQLScanConcurrency = 1
+	cfg.Mydumper.ColumnTransforms = config.AllColumnTransforms{
+		{DB: "db", Table: "tbl", Column: "Email", Expr: "SHA2(email, 256)"},
+		{TableFilter: []string{"db.*"}, Column: "tenant_id", Expr: "42"},
+		{TableFilter: []string{"db.*"}, Column: "email", Expr: "NULL"},
+	}
+	cfg.TikvImporter.Backend = config.BackendTiDB
+	err := cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "`mydumper.column-transforms` is only supported by the local backend")
+
+	cfg.TikvImporter.Backend = config.BackendLocal
+	require.NoError(t, cfg.Adjust(ctx))
+
+	transforms, err := cfg.Mydumper.ColumnTransforms.GetColumnTransforms("DB", "Tbl", false)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"email": "SHA2(email, 256)", "tenant_id": "42"}, transforms)
+	transforms, err = cfg.Mydumper.ColumnTransforms.GetColumnTransforms("db", "tbl2", false)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"email": "NULL", "tenant_id": "42"}, transforms)
+	transforms, err = cfg.Mydumper.ColumnTransforms.GetColumnTransforms("db2", "tbl", false)
+	require.NoError(t, err)
+	require.Nil(t, transforms)
+
+	cfg.Mydumper.ColumnTransforms = config.AllColumnTransforms{{DB: "db", Table: "tbl", Column: "email"}}
+	err = cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "`mydumper.column-transforms.column` and `mydumper.column-transforms.expr` must not be empty")
+}
+
+func TestAdjustRowFilters(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.NewConfig()
+	assignMinimalLegalValue(cfg)
+	cfg.TiDB.DistSQLScanConcurrency = 1
+	cfg.Mydumper.RowFilters = config.AllRowFilters{
+		{DB: "db", Table: "tbl", Filter: "id >= 100"},
+		{TableFilter: []string{"db.*"}, Filter: "create_time BETWEEN '2022-01-01' AND '2022-12-31'"},
+	}
+	require.NoError(t, cfg.Adjust(ctx))
+
+	f, err := cfg.Mydumper.RowFilters.GetRowFilter("DB", "Tbl", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"id"}, f.Columns())
+	f, err = cfg.Mydumper.RowFilters.GetRowFilter("db", "tbl2", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"create_time"}, f.Columns())
+	f, err = cfg.Mydumper.RowFilters.GetRowFilter("db2", "tbl", false)
+	require.NoError(t, err)
+	require.Nil(t, f)
+
+	cfg.Mydumper.RowFilters = config.AllRowFilters{{DB: "db", Table: "tbl"}}
+	err = cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "`mydumper.row-filters.filter` must not be empty")
+	cfg.Mydumper.RowFilters = config.AllRowFilters{{DB: "db", Table: "tbl", Filter: "id > 1 OR id < 0"}}
+	err = cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "invalid row filter 'id > 1 OR id < 0'")
+	cfg.Mydumper.RowFilters = config.AllRowFilters{{TableFilter: []string{"db.["}, Filter: "id > 1"}}
+	err = cfg.Adjust(ctx)
+	require.ErrorContains(t, err, "invalid table filter db.[ in row filters")
+}
+
+func TestLoadPrintCredentialsSource(t *testing.T) {
+	cfg, err := config.LoadGlobalConfig([]string{}, nil)
+	require.NoError(t, err)
+	require.False(t, cfg.App.PrintCredentialsSource)
+
+	cfg, err = config.LoadGlobalConfig([]string{"--print-resolved-credentials-source"}, nil)
+	require.NoError(t, err)
+	require.True(t, cfg.App.PrintCredentialsSource)
+
+	_, err = config.LoadGlobalConfig([]string{"--print-resolved-credentials-source", "--server-mode", "--status-addr", ":8289"}, nil)
+	require.ErrorContains(t, err, "print-resolved-credentials-source can't be used in server mode")
+}
+
 func TestDefaultImporterBackendValue(t *testing.T) {
 	cfg := config.NewConfig()
 	assignMinimalLegalValue(cfg)
@@ -837,6 +1400,75 @@ func TestDataCharacterSet(t *testing.T) {
 			`,
 			err: "",
 		},
+		{
+			input: `
+				[mydumper]
+				data-character-set = 'gbk'
+				data-invalid-char-action = 'Error'
+			`,
+			err: "",
+		},
+		{
+			input: `
+				[mydumper]
+				data-invalid-char-action = 'skip'
+			`,
+			err: "",
+		},
+		{
+			input: `
+				[mydumper]
+				data-invalid-char-action = 'ignore'
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]unsupported `mydumper.data-invalid-char-action` (ignore)",
+		},
+		{
+			input: `
+				[tikv-importer]
+				priority = 'Low'
+			`,
+			err: "",
+		},
+		{
+			input: `
+				[tikv-importer]
+				priority = 'urgent'
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]unsupported `tikv-importer.priority` (urgent)",
+		},
+		{
+			input: `
+				[lightning]
+				foreign-key-mode = 'Ordered'
+			`,
+			err: "",
+		},
+		{
+			input: `
+				[lightning]
+				foreign-key-mode = 'deferred'
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]unsupported `lightning.foreign-key-mode` (deferred)",
+		},
+		{
+			input: `
+				[[lightning.throttle-schedule]]
+				start = "09:00"
+				end = "18:00"
+				ratio = 0.3
+				store-write-bwlimit = "64MiB"
+			`,
+			err: "",
+		},
+		{
+			input: `
+				[[lightning.throttle-schedule]]
+				start = "09:00"
+				end = "18:00"
+				ratio = 1.5
+			`,
+			err: "[Lightning:Config:ErrInvalidConfig]`lightning.throttle-schedule.ratio` (1.5) must be in (0, 1]",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -859,6 +1491,46 @@ func TestDataCharacterSet(t *testing.T) {
 	}
 }
 
+func TestThrottleWindowAt(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Mydumper.SourceDir = "file://."
+	cfg.TiDB.Port = 4000
+	cfg.TiDB.PdAddr = "test.invalid:2379"
+	cfg.TikvImporter.Backend = config.BackendLocal
+	cfg.TikvImporter.SortedKVDir = "."
+	cfg.TiDB.DistSQLScanConcurrency = 1
+	err := cfg.LoadFromTOML([]byte(`
+		[[lightning.throttle-schedule]]
+		start = "09:00"
+		end = "18:00"
+		ratio = 0.3
+		[[lightning.throttle-schedule]]
+		start = "22:00"
+		end = "02:00"
+		store-write-bwlimit = "1MiB"
+	`))
+	require.NoError(t, err)
+	require.NoError(t, cfg.Adjust(context.Background()))
+
+	at := func(clock string) *config.ThrottleWindow {
+		tm, err := time.ParseInLocation("15:04", clock, time.Local)
+		require.NoError(t, err)
+		return cfg.App.ThrottleWindowAt(tm)
+	}
+	require.Nil(t, at("08:59"))
+	require.Equal(t, 0.3, at("09:00").Ratio)
+	require.Equal(t, 0.3, at("17:59").Ratio)
+	require.Nil(t, at("18:00"))
+	w := at("23:00")
+	require.Equal(t, 1.0, w.Ratio)
+	require.Equal(t, config.ByteSize(1<<20), w.StoreWriteBWLimit)
+	require.Equal(t, w, at("01:00"))
+	require.Nil(t, at("02:00"))
+
+	cfg.App.ThrottleSchedule[0].Start = "9am"
+	require.ErrorContains(t, cfg.Adjust(context.Background()), "invalid `lightning.throttle-schedule.start` (9am)")
+}
+
 func TestCheckpointKeepStrategy(t *testing.T) {
 	tomlCases := map[interface{}]config.CheckpointKeepStrategy{
 		true:     config.CheckpointRename,
@@ -949,4 +1621,35 @@ func TestCheckAndAdjustForLocalBackend(t *testing.T) {
 	// legal dir
 	cfg.TikvImporter.SortedKVDir = base
 	require.NoError(t, cfg.CheckAndAdjustForLocalBackend())
+
+	cfg.TikvImporter.DiskQuota = 100
+	cfg.TikvImporter.SpillDirs = []config.SpillDir{{Path: base + "/", DiskQuota: 50}}
+	require.EqualError(t, cfg.CheckAndAdjustForLocalBackend(), "[Lightning:Config:ErrInvalidConfig]"+
+		"`tikv-importer.spill-dirs.path` ('"+base+"') is duplicated with the sorted-kv-dir or another spill dir")
+	cfg.TikvImporter.SpillDirs = []config.SpillDir{{Path: filepath.Join(base, "spill")}}
+	require.ErrorContains(t, cfg.CheckAndAdjustForLocalBackend(), "must be positive")
+	cfg.TikvImporter.SpillDirs = []config.SpillDir{{Path: file, DiskQuota: 50}}
+	require.Regexp(t, "tikv-importer.spill-dirs.path.* is not a directory", cfg.CheckAndAdjustForLocalBackend().Error())
+
+	cfg.TikvImporter.SpillDirs = []config.SpillDir{{Path: filepath.Join(base, "spill"), DiskQuota: 50}}
+	require.NoError(t, cfg.CheckAndAdjustForLocalBackend())
+	require.Equal(t, []string{base, filepath.Join(base, "spill")}, cfg.TikvImporter.SortedKVDirs())
+	require.Equal(t, config.ByteSize(150), cfg.TikvImporter.TotalDiskQuota())
+	cfg.TikvImporter.DiskQuota = config.ByteSize(math.MaxInt64)
+	require.Equal(t, config.ByteSize(math.MaxInt64), cfg.TikvImporter.TotalDiskQuota())
+
+	cfg.TikvImporter.Encryption = config.EngineEncryption{Method: "AES256-CTR"}
+	require.ErrorContains(t, cfg.CheckAndAdjustForLocalBackend(), "exactly one of `tikv-importer.encryption.key`")
+	cfg.TikvImporter.Encryption.Key = "0123456789abcdef0123456789abcdef"
+	require.ErrorContains(t, cfg.CheckAndAdjustForLocalBackend(), "should be a hex-encoded 256-bit key for aes256-ctr")
+	cfg.TikvImporter.Encryption.Method = "aes128-ctr"
+	require.NoError(t, cfg.CheckAndAdjustForLocalBackend())
+	require.True(t, cfg.TikvImporter.Encryption.Enabled())
+	cfg.TikvImporter.Encryption.KeyRing = "aws-kms://key-id"
+	require.ErrorContains(t, cfg.CheckAndAdjustForLocalBackend(), "exactly one of `tikv-importer.encryption.key`")
+	cfg.TikvImporter.Encryption = config.EngineEncryption{Method: "aes-gcm"}
+	require.ErrorContains(t, cfg.CheckAndAdjustForLocalBackend(), "unsupported `tikv-importer.encryption.method` (aes-gcm)")
+	cfg.TikvImporter.Encryption = config.EngineEncryption{Method: "plaintext"}
+	require.NoError(t, cfg.CheckAndAdjustForLocalBackend())
+	require.False(t, cfg.TikvImporter.Encryption.Enabled())
 }
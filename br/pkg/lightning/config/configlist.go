@@ -21,8 +21,9 @@ import (
 	"time"
 )
 
-// List is a goroutine-safe FIFO list of *Config, which supports removal
-// from the middle. The list is not expected to be very long.
+// List is a goroutine-safe list of *Config ordered by the task priority and
+// then FIFO, which supports removal from the middle. The list is not expected
+// to be very long.
 type List struct {
 	cond      *sync.Cond
 	taskIDMap map[int64]*list.Element
@@ -44,8 +45,20 @@ func NewConfigList() *List {
 	}
 }
 
-// Push adds a configuration to the end of the list. The field `cfg.TaskID` will
-// be modified to include a unique ID to identify this task.
+func priorityRank(priority string) int {
+	switch priority {
+	case PriorityHigh:
+		return 2
+	case PriorityLow:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// Push adds a configuration after the last task of the same or higher priority
+// in the list. The field `cfg.TaskID` will be modified to include a unique ID
+// to identify this task.
 func (cl *List) Push(cfg *Config) {
 	id := time.Now().UnixNano()
 	cl.cond.L.Lock()
@@ -55,7 +68,17 @@ func (cl *List) Push(cfg *Config) {
 	}
 	cfg.TaskID = id
 	cl.lastID = id
-	cl.taskIDMap[id] = cl.nodes.PushBack(cfg)
+
+	rank := priorityRank(cfg.App.TaskPriority)
+	element := cl.nodes.Back()
+	for element != nil && priorityRank(element.Value.(*Config).App.TaskPriority) < rank {
+		element = element.Prev()
+	}
+	if element == nil {
+		cl.taskIDMap[id] = cl.nodes.PushFront(cfg)
+	} else {
+		cl.taskIDMap[id] = cl.nodes.InsertAfter(cfg, element)
+	}
 	cl.cond.Broadcast()
 }
 
@@ -65,18 +88,31 @@ func (cl *List) Push(cfg *Config) {
 //
 // If the context expired, the error field will contain the error from context.
 func (cl *List) Pop(ctx context.Context) (*Config, error) {
+	return cl.PopFunc(ctx, nil)
+}
+
+// PopFunc removes the first configuration in the list which `canPop` returns
+// true for. A nil `canPop` accepts any configuration. If there is no such
+// configuration, this method will block until either the list is changed, or
+// Wakeup() is called, or the input context expired.
+//
+// `canPop` is called with the list locked, so it must not access the list.
+func (cl *List) PopFunc(ctx context.Context, canPop func(*Config) bool) (*Config, error) {
 	res := make(chan *Config)
 
 	go func() {
 		cl.cond.L.Lock()
 		defer cl.cond.L.Unlock()
 		for {
-			if front := cl.nodes.Front(); front != nil {
-				cfg := front.Value.(*Config)
+			for element := cl.nodes.Front(); element != nil; element = element.Next() {
+				cfg := element.Value.(*Config)
+				if canPop != nil && !canPop(cfg) {
+					continue
+				}
 				delete(cl.taskIDMap, cfg.TaskID)
-				cl.nodes.Remove(front)
+				cl.nodes.Remove(element)
 				res <- cfg
-				break
+				return
 			}
 			cl.cond.Wait()
 		}
@@ -90,6 +126,14 @@ func (cl *List) Pop(ctx context.Context) (*Config, error) {
 	}
 }
 
+// Wakeup makes the blocking PopFunc() check the configurations again, which
+// should be called when the condition of `canPop` is changed.
+func (cl *List) Wakeup() {
+	cl.cond.L.Lock()
+	defer cl.cond.L.Unlock()
+	cl.cond.Broadcast()
+}
+
 // Remove removes a task from the list given its task ID. Returns true if a task
 // is successfully removed, false if the task ID did not exist.
 func (cl *List) Remove(taskID int64) bool {
@@ -16,6 +16,7 @@ package config_test
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -128,3 +129,55 @@ func TestMoveFrontBack(t *testing.T) {
 	require.False(t, cl.MoveToBack(123456))
 	require.Equal(t, []int64{cfg1.TaskID, cfg3.TaskID, cfg2.TaskID}, cl.AllIDs())
 }
+
+func TestPushWithPriority(t *testing.T) {
+	cl := config.NewConfigList()
+
+	newCfg := func(priority string) *config.Config {
+		return &config.Config{App: config.Lightning{TaskPriority: priority}}
+	}
+	cfg1 := newCfg(config.PriorityNormal)
+	cl.Push(cfg1)
+	cfg2 := newCfg(config.PriorityLow)
+	cl.Push(cfg2)
+	cfg3 := newCfg(config.PriorityHigh)
+	cl.Push(cfg3)
+	cfg4 := newCfg(config.PriorityNormal)
+	cl.Push(cfg4)
+	cfg5 := newCfg(config.PriorityHigh)
+	cl.Push(cfg5)
+
+	require.Equal(t, []int64{cfg3.TaskID, cfg5.TaskID, cfg1.TaskID, cfg4.TaskID, cfg2.TaskID}, cl.AllIDs())
+}
+
+func TestPopFunc(t *testing.T) {
+	cl := config.NewConfigList()
+
+	cfg1 := &config.Config{TikvImporter: config.TikvImporter{SortedKVDir: "/tmp/sorted1"}}
+	cl.Push(cfg1)
+	cfg2 := &config.Config{TikvImporter: config.TikvImporter{SortedKVDir: "/tmp/sorted2"}}
+	cl.Push(cfg2)
+
+	cfg, err := cl.PopFunc(context.Background(), func(cfg *config.Config) bool {
+		return cfg.TikvImporter.SortedKVDir != "/tmp/sorted1"
+	})
+	require.NoError(t, err)
+	require.Equal(t, cfg2, cfg)
+	require.Equal(t, []int64{cfg1.TaskID}, cl.AllIDs())
+
+	var canPop atomic.Bool
+	go func() {
+		time.Sleep(400 * time.Millisecond)
+		canPop.Store(true)
+		cl.Wakeup()
+	}()
+
+	startTime := time.Now()
+	cfg, err = cl.PopFunc(context.Background(), func(*config.Config) bool {
+		return canPop.Load()
+	}) // this should block for ≥400ms
+	require.GreaterOrEqual(t, time.Since(startTime), 400*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, cfg1, cfg)
+	require.Empty(t, cl.AllIDs())
+}
@@ -22,6 +22,8 @@ const (
 	// mydumper
 	ReadBlockSize ByteSize = 64 * units.KiB
 	MaxRegionSize ByteSize = 256 * units.MiB
+	// InferSchemaSampleRows is the default number of rows sampled to infer the schema of a table.
+	InferSchemaSampleRows = 1000
 	// See: https://github.com/tikv/tikv/blob/e030a0aae9622f3774df89c62f21b2171a72a69e/etc/config-template.toml#L360
 	// lower the max-key-count to avoid tikv trigger region auto split
 	SplitRegionSize         ByteSize = 96 * units.MiB
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/BurntSushi/toml"
@@ -27,6 +28,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/lightning/common"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/br/pkg/version/build"
+	router "github.com/pingcap/tidb/util/table-router"
 )
 
 type GlobalLightning struct {
@@ -35,6 +37,21 @@ type GlobalLightning struct {
 	ServerMode        bool   `toml:"server-mode" json:"server-mode"`
 	CheckRequirements bool   `toml:"check-requirements" json:"check-requirements"`
 
+	// MaxConcurrentTasks is the maximum number of tasks the server mode runs at
+	// the same time. The tasks only run concurrently if their target tables are disjoint.
+	MaxConcurrentTasks int `toml:"max-concurrent-tasks" json:"max-concurrent-tasks"`
+	// ServerDiskQuota is the total disk quota of the sorted-kv-dir shared by the
+	// concurrent tasks of the server mode, 0 means unlimited.
+	ServerDiskQuota ByteSize `toml:"server-disk-quota" json:"server-disk-quota"`
+
+	// DryRunPlan means only printing the import plan of the data source in
+	// DryRunPlanFormat instead of importing, which can only be set by the command line.
+	DryRunPlan       bool   `toml:"-" json:"-"`
+	DryRunPlanFormat string `toml:"-" json:"-"`
+	// PrintCredentialsSource means only printing where the credentials of the
+	// data source are resolved from, which can only be set by the command line.
+	PrintCredentialsSource bool `toml:"-" json:"-"`
+
 	// The legacy alias for setting "status-addr". The value should always the
 	// same as StatusAddr, and will not be published in the JSON encoding.
 	PProfPort int `toml:"pprof-port" json:"-"`
@@ -72,6 +89,9 @@ type GlobalConfig struct {
 	PostRestore  GlobalPostRestore `toml:"post-restore" json:"post-restore"`
 	Security     Security          `toml:"security" json:"security"`
 
+	// RouteOverrides are the table routes given by the command line.
+	RouteOverrides []*router.TableRule `toml:"-" json:"-"`
+
 	ConfigFileContent []byte
 }
 
@@ -87,8 +107,9 @@ type GlobalPostRestore struct {
 func NewGlobalConfig() *GlobalConfig {
 	return &GlobalConfig{
 		App: GlobalLightning{
-			ServerMode:        false,
-			CheckRequirements: true,
+			ServerMode:         false,
+			CheckRequirements:  true,
+			MaxConcurrentTasks: 1,
 		},
 		Checkpoint: GlobalCheckpoint{
 			Enable: true,
@@ -166,9 +187,16 @@ func LoadGlobalConfig(args []string, extraFlags func(*flag.FlagSet)) (*GlobalCon
 
 	statusAddr := fs.String("status-addr", "", "the Lightning server address")
 	serverMode := fs.Bool("server-mode", false, "start Lightning in server mode, wait for multiple tasks instead of starting immediately")
+	dryRunPlan := fs.Bool("dry-run-plan", false, "print the databases, tables and data files to import and the estimated engines without importing")
+	dryRunPlanFormat := flagext.ChoiceVar(fs, "dry-run-plan-format", "table", "format of the import plan: table, json", "table", "json")
+	printCredentialsSource := fs.Bool("print-resolved-credentials-source", false,
+		"print where the credentials of the data source are resolved from (config, env, shared-file, credential-process or imds) and exit")
 
 	var filter []string
 	flagext.StringsVar(fs, &filter, "f", "select tables to import")
+	var routes []string
+	flagext.StringsVar(fs, &routes, "route", "route tables to another database or table, e.g. 'src_db.*->dst_db.*', "+
+		"which takes precedence over the [[routes]] in the config file")
 
 	if extraFlags != nil {
 		extraFlags(fs)
@@ -229,6 +257,13 @@ func LoadGlobalConfig(args []string, extraFlags func(*flag.FlagSet)) (*GlobalCon
 	if *statusAddr != "" {
 		cfg.App.StatusAddr = *statusAddr
 	}
+	if *dryRunPlan {
+		cfg.App.DryRunPlan = true
+		cfg.App.DryRunPlanFormat = *dryRunPlanFormat
+	}
+	if *printCredentialsSource {
+		cfg.App.PrintCredentialsSource = true
+	}
 	if *backend != "" {
 		cfg.TikvImporter.Backend = *backend
 	}
@@ -268,11 +303,64 @@ func LoadGlobalConfig(args []string, extraFlags func(*flag.FlagSet)) (*GlobalCon
 	if len(filter) > 0 {
 		cfg.Mydumper.Filter = filter
 	}
+	for _, route := range routes {
+		rule, err := parseRouteOverride(route)
+		if err != nil {
+			return nil, common.ErrInvalidArgument.Wrap(err).GenWithStackByArgs()
+		}
+		cfg.RouteOverrides = append(cfg.RouteOverrides, rule)
+	}
 
+	if cfg.App.DryRunPlan && cfg.App.ServerMode {
+		return nil, common.ErrInvalidConfig.GenWithStack("dry-run-plan can't be used in server mode")
+	}
+	if cfg.App.PrintCredentialsSource && cfg.App.ServerMode {
+		return nil, common.ErrInvalidConfig.GenWithStack("print-resolved-credentials-source can't be used in server mode")
+	}
 	if cfg.App.StatusAddr == "" && cfg.App.ServerMode {
 		return nil, common.ErrInvalidConfig.GenWithStack("If server-mode is enabled, the status-addr must be a valid listen address")
 	}
+	if cfg.App.MaxConcurrentTasks <= 0 {
+		cfg.App.MaxConcurrentTasks = 1
+	}
+	if cfg.App.ServerDiskQuota < 0 {
+		return nil, common.ErrInvalidConfig.GenWithStack("invalid `lightning.server-disk-quota` (%d)", cfg.App.ServerDiskQuota)
+	}
 
 	cfg.App.Config.Adjust()
 	return cfg, nil
 }
+
+// parseRouteOverride parses the route given by the command line in the form of
+// `{schema pattern}[.{table pattern}]->{target schema}[.{target table}]`, where
+// `→` can be used in place of `->`. A table pattern and a target table of `*`
+// routes all the tables of the schema and keeps their names.
+func parseRouteOverride(route string) (*router.TableRule, error) {
+	src, dst, ok := strings.Cut(route, "->")
+	if !ok {
+		src, dst, ok = strings.Cut(route, "→")
+	}
+	if !ok {
+		return nil, errors.Errorf("invalid route '%s', should be in the form of 'src_db.src_table->dst_db.dst_table'", route)
+	}
+	srcSchema, srcTable, _ := strings.Cut(strings.TrimSpace(src), ".")
+	dstSchema, dstTable, _ := strings.Cut(strings.TrimSpace(dst), ".")
+	if len(srcSchema) == 0 || len(dstSchema) == 0 || dstSchema == "*" {
+		return nil, errors.Errorf("invalid route '%s', both source and target schemas should be specified", route)
+	}
+	if dstTable == "*" {
+		dstTable = ""
+	}
+	rule := &router.TableRule{
+		SchemaPattern: srcSchema,
+		TargetSchema:  dstSchema,
+	}
+	if (len(srcTable) > 0 && srcTable != "*") || len(dstTable) > 0 {
+		rule.TablePattern = srcTable
+		if len(rule.TablePattern) == 0 {
+			rule.TablePattern = "*"
+		}
+		rule.TargetTable = dstTable
+	}
+	return rule, nil
+}
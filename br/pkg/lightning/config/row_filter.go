@@ -0,0 +1,160 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/types"
+	driver "github.com/pingcap/tidb/types/parser_driver"
+	"golang.org/x/exp/slices"
+)
+
+// RowFilter is a filter of the rows in the source files, which is the
+// conjunction of the conditions comparing a column with a constant, e.g.
+// `id >= 100 AND create_time BETWEEN '2022-01-01' AND '2022-12-31'`.
+type RowFilter struct {
+	conds []RowFilterCond
+	// loc is the time zone to convert the values in, UTC if nil.
+	loc *time.Location
+}
+
+// RowFilterCond is a condition of the row filter, `Column Op Value`.
+type RowFilterCond struct {
+	// Column is the lower-case column name.
+	Column string
+	Op     opcode.Op
+	Value  types.Datum
+}
+
+// ParseRowFilter parses the row filter in the WHERE clause syntax. It returns nil
+// if the filter is empty.
+func ParseRowFilter(filter string) (*RowFilter, error) {
+	if len(filter) == 0 {
+		return nil, nil
+	}
+	stmt, err := parser.New().ParseOneStmt("SELECT * FROM t WHERE "+filter, "", "")
+	if err != nil {
+		return nil, common.ErrInvalidConfig.Wrap(err).GenWithStack("invalid row filter '%s'", filter)
+	}
+	where := stmt.(*ast.SelectStmt).Where
+	f := &RowFilter{}
+	if err := f.addConds(where); err != nil {
+		return nil, common.ErrInvalidConfig.Wrap(err).GenWithStack("invalid row filter '%s'", filter)
+	}
+	return f, nil
+}
+
+// NewRowFilter creates a row filter of the conditions, all of which must be
+// satisfied. The values are converted to the types of the columns in `loc`.
+func NewRowFilter(conds []RowFilterCond, loc *time.Location) *RowFilter {
+	return &RowFilter{conds: conds, loc: loc}
+}
+
+func (f *RowFilter) addConds(expr ast.ExprNode) error {
+	switch x := expr.(type) {
+	case *ast.ParenthesesExpr:
+		return f.addConds(x.Expr)
+	case *ast.BinaryOperationExpr:
+		switch x.Op {
+		case opcode.LogicAnd:
+			if err := f.addConds(x.L); err != nil {
+				return err
+			}
+			return f.addConds(x.R)
+		case opcode.EQ, opcode.NE, opcode.LT, opcode.LE, opcode.GT, opcode.GE:
+			return f.addCompare(x.L, x.R, x.Op)
+		}
+	case *ast.BetweenExpr:
+		if !x.Not {
+			if err := f.addCompare(x.Expr, x.Left, opcode.GE); err != nil {
+				return err
+			}
+			return f.addCompare(x.Expr, x.Right, opcode.LE)
+		}
+	}
+	return errors.Errorf("unsupported expression '%s'", exprText(expr))
+}
+
+func (f *RowFilter) addCompare(l, r ast.ExprNode, op opcode.Op) error {
+	col, ok := l.(*ast.ColumnNameExpr)
+	val, ok2 := r.(*driver.ValueExpr)
+	if !ok || !ok2 {
+		// try `constant op column`
+		col, ok = r.(*ast.ColumnNameExpr)
+		val, ok2 = l.(*driver.ValueExpr)
+		if !ok || !ok2 {
+			return errors.Errorf("only comparisons between a column and a constant are supported, got '%s'", exprText(l)+" "+op.String()+" "+exprText(r))
+		}
+		op = reverseCompareOp(op)
+	}
+	f.conds = append(f.conds, RowFilterCond{
+		Column: col.Name.Name.L,
+		Op:     op,
+		Value:  val.Datum,
+	})
+	return nil
+}
+
+func reverseCompareOp(op opcode.Op) opcode.Op {
+	switch op {
+	case opcode.LT:
+		return opcode.GT
+	case opcode.LE:
+		return opcode.GE
+	case opcode.GT:
+		return opcode.LT
+	case opcode.GE:
+		return opcode.LE
+	default:
+		return op
+	}
+}
+
+func exprText(expr ast.ExprNode) string {
+	if text := expr.OriginalText(); len(text) > 0 {
+		return text
+	}
+	return expr.Text()
+}
+
+// Columns returns the lower-case names of the columns referred by the filter.
+func (f *RowFilter) Columns() []string {
+	columns := make([]string, 0, len(f.conds))
+	for _, cond := range f.conds {
+		if !slices.Contains(columns, cond.Column) {
+			columns = append(columns, cond.Column)
+		}
+	}
+	return columns
+}
+
+// Conds returns the conditions of the filter.
+func (f *RowFilter) Conds() []RowFilterCond {
+	return f.conds
+}
+
+// TimeZone returns the time zone to convert the values in.
+func (f *RowFilter) TimeZone() *time.Location {
+	if f.loc == nil {
+		return time.UTC
+	}
+	return f.loc
+}
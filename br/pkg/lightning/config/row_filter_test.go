@@ -0,0 +1,53 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRowFilter(t *testing.T) {
+	f, err := config.ParseRowFilter("")
+	require.NoError(t, err)
+	require.Nil(t, f)
+
+	f, err = config.ParseRowFilter("(a > 1 AND 'x' <= B) AND c BETWEEN 1.5 AND 2 AND a != 3")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c"}, f.Columns())
+	require.Equal(t, []config.RowFilterCond{
+		{Column: "a", Op: opcode.GT, Value: types.NewIntDatum(1)},
+		{Column: "b", Op: opcode.GE, Value: types.NewStringDatum("x")},
+		{Column: "c", Op: opcode.GE, Value: types.NewDecimalDatum(types.NewDecFromStringForTest("1.5"))},
+		{Column: "c", Op: opcode.LE, Value: types.NewIntDatum(2)},
+		{Column: "a", Op: opcode.NE, Value: types.NewIntDatum(3)},
+	}, f.Conds())
+
+	for _, filter := range []string{
+		"a > ",
+		"a > 1 OR b < 2",
+		"a + 1 > 2",
+		"a > b",
+		"a NOT BETWEEN 1 AND 2",
+		"a IN (1, 2)",
+	} {
+		_, err := config.ParseRowFilter(filter)
+		require.ErrorContains(t, err, "invalid row filter", filter)
+	}
+}
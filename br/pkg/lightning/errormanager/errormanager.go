@@ -29,7 +29,9 @@ import (
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/br/pkg/redact"
+	"github.com/pingcap/tidb/br/pkg/storage"
 	"github.com/pingcap/tidb/br/pkg/utils"
+	"go.uber.org/atomic"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
@@ -122,6 +124,13 @@ type ErrorManager struct {
 	remainingError config.MaxError
 	dupResolution  config.DuplicateResolutionAlgorithm
 	logger         log.Logger
+
+	// the rows rejected by the CSV strict-mode validation are written to the
+	// files in rejectedStore, which is opened from rejectedRowsDir.
+	rejectedRowsDir string
+	rejectedStore   storage.ExternalStorage
+	maxRejectedRows int64
+	rejectedCount   atomic.Int64
 }
 
 func (em *ErrorManager) TypeErrorsRemain() int64 {
@@ -136,6 +145,9 @@ func New(db *sql.DB, cfg *config.Config, logger log.Logger) *ErrorManager {
 		remainingError: cfg.App.MaxError,
 		dupResolution:  cfg.TikvImporter.DuplicateResolution,
 		logger:         logger,
+
+		rejectedRowsDir: cfg.Mydumper.CSV.RejectedRowsDir,
+		maxRejectedRows: cfg.Mydumper.CSV.MaxRejectedRows,
 	}
 	if len(cfg.App.TaskInfoSchemaName) != 0 {
 		em.db = db
@@ -146,6 +158,9 @@ func New(db *sql.DB, cfg *config.Config, logger log.Logger) *ErrorManager {
 
 // Init creates the schemas and tables to store the task information.
 func (em *ErrorManager) Init(ctx context.Context) error {
+	if err := em.initRejectedRows(ctx); err != nil {
+		return err
+	}
 	if em.db == nil || (em.remainingError.Type.Load() == 0 && em.dupResolution == config.DupeResAlgNone) {
 		return nil
 	}
@@ -448,7 +463,7 @@ func (em *ErrorManager) charsetError() int64 {
 
 func (em *ErrorManager) HasError() bool {
 	return em.typeErrors() > 0 || em.syntaxError() > 0 ||
-		em.charsetError() > 0 || em.conflictError() > 0
+		em.charsetError() > 0 || em.conflictError() > 0 || em.rejectedRows() > 0
 }
 
 // GenErrorLogFields return a slice of zap.Field for each error type
@@ -470,6 +485,10 @@ func (em *ErrorManager) LogErrorDetails() {
 	if errCnt := em.conflictError(); errCnt > 0 {
 		em.logger.Warn(fmtErrMsg(errCnt, "data type", conflictErrorTableName))
 	}
+	if cnt := em.rejectedRows(); cnt > 0 {
+		em.logger.Warn(fmt.Sprintf("Detect %d rejected rows in total, please refer to the files in %s for more details",
+			cnt, em.rejectedStore.URI()))
+	}
 }
 
 func (em *ErrorManager) fmtTableName(t string) string {
@@ -513,6 +532,10 @@ func (em *ErrorManager) Output() string {
 		count++
 		t.AppendRow(table.Row{count, "Unique Key Conflict", errCnt, em.fmtTableName(conflictErrorTableName)})
 	}
+	if cnt := em.rejectedRows(); cnt > 0 {
+		count++
+		t.AppendRow(table.Row{count, "Rejected Rows", cnt, em.rejectedStore.URI()})
+	}
 
 	res := "\nImport Data Error Summary: \n"
 	res += t.Render()
@@ -0,0 +1,122 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errormanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/storage"
+)
+
+// rejectedRowsHeader is the header of the rejected rows files.
+var rejectedRowsHeader = []string{"path", "line", "offset", "error", "row"}
+
+// initRejectedRows opens the external storage to write the rejected rows to.
+func (em *ErrorManager) initRejectedRows(ctx context.Context) error {
+	if len(em.rejectedRowsDir) == 0 {
+		return nil
+	}
+	backend, err := storage.ParseBackend(em.rejectedRowsDir, nil)
+	if err != nil {
+		return errors.Annotate(err, "invalid `mydumper.csv.rejected-rows-dir`")
+	}
+	em.rejectedStore, err = storage.New(ctx, backend, &storage.ExternalStorageOptions{})
+	return errors.Trace(err)
+}
+
+// RejectsRows returns whether the malformed CSV rows are written to the
+// rejected rows files instead of failing the import.
+func (em *ErrorManager) RejectsRows() bool {
+	return em.rejectedStore != nil
+}
+
+func (em *ErrorManager) rejectedRows() int64 {
+	// the rows beyond the threshold are counted, but not recorded.
+	cnt := em.rejectedCount.Load()
+	if em.maxRejectedRows > 0 && cnt > em.maxRejectedRows {
+		cnt = em.maxRejectedRows
+	}
+	return cnt
+}
+
+// NewRejectedRowsWriter creates the writer of the rows rejected in a chunk,
+// which starts reading the file at path from offset. Its rows are written to
+// the file named after the table, the path and the offset, so a chunk resumed
+// from a checkpoint doesn't overwrite the rows rejected before. The file is
+// only created when the first row is rejected.
+func (em *ErrorManager) NewRejectedRowsWriter(tableName string, path string, offset int64) *RejectedRowsWriter {
+	fileName := fmt.Sprintf("%s.%s.%d.rejected.csv", tableName, strings.ReplaceAll(path, "/", "."), offset)
+	return &RejectedRowsWriter{em: em, path: path, fileName: fileName}
+}
+
+// RejectedRowsWriter writes the rejected rows of a chunk to a CSV file, along
+// with their locations in the data file and the reasons.
+type RejectedRowsWriter struct {
+	em       *ErrorManager
+	path     string
+	fileName string
+	writer   storage.ExternalFileWriter
+	buf      bytes.Buffer
+}
+
+// Record records a rejected row, which begins at the line (1-based) and the
+// offset of the data file, and whose raw content is row. If the number of
+// the rejected rows exceeds the `mydumper.csv.max-rejected-rows` threshold,
+// returns `rejectErr` directly.
+func (w *RejectedRowsWriter) Record(ctx context.Context, line int64, offset int64, row []byte, rejectErr error) error {
+	count := w.em.rejectedCount.Inc()
+	if threshold := w.em.maxRejectedRows; threshold > 0 && count > threshold {
+		return errors.Annotatef(rejectErr, "meet rejected rows exceed the max-rejected-rows threshold '%d'", threshold)
+	}
+
+	w.buf.Reset()
+	csvWriter := csv.NewWriter(&w.buf)
+	if w.writer == nil {
+		writer, err := w.em.rejectedStore.Create(ctx, w.fileName)
+		if err != nil {
+			return errors.Annotate(err, "create rejected rows file failed")
+		}
+		w.writer = writer
+		_ = csvWriter.Write(rejectedRowsHeader)
+	}
+	_ = csvWriter.Write([]string{
+		w.path,
+		strconv.FormatInt(line, 10),
+		strconv.FormatInt(offset, 10),
+		rejectErr.Error(),
+		string(row),
+	})
+	csvWriter.Flush()
+	if _, err := w.writer.Write(ctx, w.buf.Bytes()); err != nil {
+		return errors.Annotate(err, "write rejected rows file failed")
+	}
+	return nil
+}
+
+// Close closes the rejected rows file if it's created.
+func (w *RejectedRowsWriter) Close(ctx context.Context) error {
+	if w.writer == nil {
+		return nil
+	}
+	err := w.writer.Close(ctx)
+	w.writer = nil
+	return errors.Trace(err)
+}
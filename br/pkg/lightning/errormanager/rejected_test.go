@@ -0,0 +1,69 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errormanager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRejectedRows(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	cfg := config.NewConfig()
+	em := New(nil, cfg, log.L())
+	require.NoError(t, em.Init(ctx))
+	require.False(t, em.RejectsRows())
+
+	cfg.Mydumper.CSV.RejectedRowsDir = "local://" + dir
+	cfg.Mydumper.CSV.MaxRejectedRows = 2
+	em = New(nil, cfg, log.L())
+	require.NoError(t, em.Init(ctx))
+	require.True(t, em.RejectsRows())
+	require.False(t, em.HasError())
+
+	// the file isn't created if no rows are rejected.
+	w := em.NewRejectedRowsWriter("db.t", "sub/db.t.0.csv", 0)
+	require.NoError(t, w.Close(ctx))
+	require.NoFileExists(t, filepath.Join(dir, "db.t.sub.db.t.0.csv.0.rejected.csv"))
+
+	w = em.NewRejectedRowsWriter("db.t", "sub/db.t.0.csv", 100)
+	require.NoError(t, w.Record(ctx, 3, 100, []byte("1,2"), errors.New("column count mismatch, expected 3, got 2")))
+	require.NoError(t, w.Record(ctx, 5, 120, []byte("\"a\nb\",3,4"), errors.New("invalid value")))
+	err := w.Record(ctx, 7, 140, []byte("5"), errors.New("column count mismatch, expected 3, got 1"))
+	require.EqualError(t, err, "meet rejected rows exceed the max-rejected-rows threshold '2': column count mismatch, expected 3, got 1")
+	require.NoError(t, w.Close(ctx))
+
+	data, err := os.ReadFile(filepath.Join(dir, "db.t.sub.db.t.0.csv.100.rejected.csv"))
+	require.NoError(t, err)
+	require.Equal(t, "path,line,offset,error,row\n"+
+		"sub/db.t.0.csv,3,100,\"column count mismatch, expected 3, got 2\",\"1,2\"\n"+
+		"sub/db.t.0.csv,5,120,invalid value,\"\"\"a\nb\"\",3,4\"\n", string(data))
+
+	require.True(t, em.HasError())
+	require.Equal(t, int64(2), em.rejectedRows())
+	require.Contains(t, em.Output(), "Rejected Rows")
+
+	cfg.Mydumper.CSV.RejectedRowsDir = "unknown://a"
+	em = New(nil, cfg, log.L())
+	require.ErrorContains(t, em.Init(ctx), "invalid `mydumper.csv.rejected-rows-dir`")
+}
@@ -0,0 +1,281 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package insertingest ingests the rows of INSERT ... SELECT through the local backend
+// of lightning, it's registered to the executor by tidb-server.
+package insertingest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/backend"
+	lkv "github.com/pingcap/tidb/br/pkg/lightning/backend/kv"
+	"github.com/pingcap/tidb/br/pkg/lightning/backend/local"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	lconfig "github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/errormanager"
+	"github.com/pingcap/tidb/br/pkg/lightning/glue"
+	llog "github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/lightning/verification"
+	"github.com/pingcap/tidb/br/pkg/utils"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta/autoid"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/logutil"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+const (
+	ingestEngineMemCacheSize      = 256 << 20
+	ingestLocalWriterMemCacheSize = 64 << 20
+
+	// cdcKeyPrefix is the prefix of the etcd keys of TiCDC, the changefeeds are stored under
+	// `/tidb/cdc/changefeed/info/` before v6.2 and `/tidb/cdc/<cluster>/<namespace>/changefeed/info/` since.
+	cdcKeyPrefix        = "/tidb/cdc/"
+	cdcChangefeedInfoID = "/changefeed/info/"
+)
+
+// insertIngester ingests the rows of INSERT ... SELECT into the table as sorted KV files
+// through the local backend of lightning, instead of writing them in the transaction.
+type insertIngester struct {
+	backend     backend.Backend
+	engine      *backend.OpenedEngine
+	writer      *backend.LocalEngineWriter
+	encoder     lkv.Encoder
+	sortedKVDir string
+	logger      llog.Logger
+
+	// rowIDAlloc allocates the _tidb_rowid of the rows, it's nil if the table has its own handle.
+	rowIDAlloc autoid.Allocator
+	perm       []int
+	dataKVs    lkv.Rows
+	indexKVs   lkv.Rows
+}
+
+// Register registers the ingester of INSERT ... SELECT to the executor.
+func Register() {
+	executor.RegisterInsertIngesterBuilder(newInsertIngester)
+}
+
+// newInsertIngester creates the ingester of the table. It returns nil if the store is
+// not backed by PD, or the changes of the cluster are captured by log backup or TiCDC,
+// which never see the ingested rows, in which case the rows are inserted in the transaction.
+func newInsertIngester(ctx context.Context, sctx sessionctx.Context, tbl table.Table) (executor.InsertIngester, error) {
+	etcd, ok := sctx.GetStore().(kv.EtcdBackend)
+	if !ok {
+		return nil, nil
+	}
+	pdAddrs, err := etcd.EtcdAddrs()
+	if err != nil {
+		return nil, err
+	}
+	if len(pdAddrs) == 0 {
+		return nil, nil
+	}
+	if utils.CheckLogBackupEnabled(sctx) {
+		return nil, nil
+	}
+	if hasChangefeed, err := hasChangefeed(ctx, domain.GetDomain(sctx).GetEtcdClient()); err != nil || hasChangefeed {
+		return nil, err
+	}
+
+	sessVars := sctx.GetSessionVars()
+	globalCfg := config.GetGlobalConfig()
+	if err := os.MkdirAll(globalCfg.TempStoragePath, 0o700); err != nil {
+		return nil, errors.Trace(err)
+	}
+	sortedKVDir := filepath.Join(globalCfg.TempStoragePath,
+		fmt.Sprintf("insert-ingest-%d-%d", sessVars.ConnectionID, tbl.Meta().ID))
+	if err := os.RemoveAll(sortedKVDir); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	cfg := lconfig.NewConfig()
+	cfg.TikvImporter.Backend = lconfig.BackendLocal
+	cfg.TikvImporter.SortedKVDir = sortedKVDir
+	cfg.TikvImporter.EngineMemCacheSize = ingestEngineMemCacheSize
+	cfg.TikvImporter.LocalWriterMemCacheSize = ingestLocalWriterMemCacheSize
+	cfg.TiDB.PdAddr = strings.Join(pdAddrs, ",")
+	cfg.Checkpoint.Enable = false
+	cfg.DefaultVarsForImporterAndLocalBackend()
+
+	logger := llog.Logger{Logger: logutil.Logger(ctx).With(
+		zap.Uint64("conn", sessVars.ConnectionID), zap.String("table", tbl.Meta().Name.O))}
+	ctx = llog.NewContext(ctx, logger)
+	sec := globalCfg.Security
+	tls, err := common.NewTLS(sec.ClusterSSLCA, sec.ClusterSSLCert, sec.ClusterSSLKey, pdAddrs[0])
+	if err != nil {
+		return nil, err
+	}
+	// the glue has no database, since the regions are split without the table region stats.
+	g := glue.NewExternalTiDBGlue(nil, sessVars.SQLMode)
+	// the max open files falls back to the lower threshold of the local backend.
+	be, err := local.NewLocalBackend(ctx, tls, cfg, g, 0, errormanager.New(nil, cfg, logger))
+	if err != nil {
+		_ = os.RemoveAll(sortedKVDir)
+		return nil, err
+	}
+
+	ingester := &insertIngester{
+		backend:     be,
+		sortedKVDir: sortedKVDir,
+		logger:      logger,
+		dataKVs:     be.MakeEmptyRows(),
+		indexKVs:    be.MakeEmptyRows(),
+	}
+	if err := ingester.open(ctx, sctx, tbl); err != nil {
+		ingester.Close(ctx)
+		return nil, err
+	}
+	return ingester, nil
+}
+
+// hasChangefeed checks whether there are changefeeds of TiCDC in the cluster, they aren't
+// filtered by the tables, since the table filters of the changefeeds can't be checked here.
+func hasChangefeed(ctx context.Context, cli *clientv3.Client) (bool, error) {
+	if cli == nil {
+		return false, nil
+	}
+	resp, err := cli.Get(ctx, cdcKeyPrefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	for _, kv := range resp.Kvs {
+		if strings.Contains(string(kv.Key), cdcChangefeedInfoID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (ing *insertIngester) open(ctx context.Context, sctx sessionctx.Context, tbl table.Table) error {
+	sessVars := sctx.GetSessionVars()
+	meta := tbl.Meta()
+	// the encoder rebases the allocators with the encoded rows, which must not touch the
+	// allocators of the table, since the auto IDs are already allocated by the statement.
+	encodeTbl, err := tables.TableFromMeta(lkv.NewPanickingAllocators(0), meta)
+	if err != nil {
+		return err
+	}
+	sysVars := make(map[string]string, 2)
+	for _, name := range []string{variable.TimeZone, variable.TiDBRowFormatVersion} {
+		if val, ok := sessVars.GetSystemVar(name); ok {
+			sysVars[name] = val
+		}
+	}
+	ing.encoder, err = ing.backend.NewEncoder(ctx, encodeTbl, &lkv.SessionOptions{
+		SQLMode:        sessVars.SQLMode,
+		Timestamp:      time.Now().Unix(),
+		SysVars:        sysVars,
+		AutoRandomSeed: meta.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	cols := tbl.Cols()
+	ing.perm = make([]int, len(cols)+1)
+	for i := range cols {
+		ing.perm[i] = i
+	}
+	ing.perm[len(cols)] = -1
+	if common.TableHasAutoRowID(meta) {
+		ing.rowIDAlloc = tbl.Allocators(sctx).Get(autoid.RowIDAllocType)
+	}
+
+	ing.engine, err = ing.backend.OpenEngine(ctx, &backend.EngineConfig{}, meta.Name.O, 0)
+	if err != nil {
+		return err
+	}
+	ing.writer, err = ing.engine.LocalWriter(ctx, &backend.LocalWriterConfig{})
+	return err
+}
+
+// Write encodes the rows, which are in the order of the table columns, and writes them
+// into the engine.
+func (ing *insertIngester) Write(ctx context.Context, rows [][]types.Datum) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	var rowID int64
+	if ing.rowIDAlloc != nil {
+		base, _, err := ing.rowIDAlloc.Alloc(ctx, uint64(len(rows)), 1, 1)
+		if err != nil {
+			return err
+		}
+		rowID = base
+	}
+	var dataChecksum, indexChecksum verification.KVChecksum
+	for _, row := range rows {
+		rowID++
+		kvs, err := ing.encoder.Encode(ing.logger, row, rowID, ing.perm, "", 0)
+		if err != nil {
+			return err
+		}
+		kvs.ClassifyAndAppend(&ing.dataKVs, &dataChecksum, &ing.indexKVs, &indexChecksum)
+	}
+	if err := ing.writer.WriteRows(ctx, nil, ing.dataKVs); err != nil {
+		return err
+	}
+	if err := ing.writer.WriteRows(ctx, nil, ing.indexKVs); err != nil {
+		return err
+	}
+	ing.dataKVs = ing.dataKVs.Clear()
+	ing.indexKVs = ing.indexKVs.Clear()
+	return nil
+}
+
+// Finish imports the written rows into TiKV.
+func (ing *insertIngester) Finish(ctx context.Context) error {
+	ctx = llog.NewContext(ctx, ing.logger)
+	if _, err := ing.writer.Close(ctx); err != nil {
+		return err
+	}
+	ing.writer = nil
+	closed, err := ing.engine.Close(ctx, &backend.EngineConfig{})
+	if err != nil {
+		return err
+	}
+	if err := closed.Import(ctx, int64(lconfig.SplitRegionSize), int64(lconfig.SplitRegionKeys)); err != nil {
+		return err
+	}
+	return closed.Cleanup(ctx)
+}
+
+// Close releases the resources of the ingester, the rows not imported are discarded.
+func (ing *insertIngester) Close(ctx context.Context) {
+	if ing.writer != nil {
+		if _, err := ing.writer.Close(llog.NewContext(ctx, ing.logger)); err != nil {
+			ing.logger.Warn("close the writer of the ingest failed", zap.Error(err))
+		}
+	}
+	ing.backend.Close()
+	if err := os.RemoveAll(ing.sortedKVDir); err != nil {
+		ing.logger.Warn("remove the sorted kv dir of the ingest failed", zap.String("dir", ing.sortedKVDir), zap.Error(err))
+	}
+}
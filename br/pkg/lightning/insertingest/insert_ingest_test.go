@@ -0,0 +1,194 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package insertingest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pingcap/tidb/br/pkg/lightning/backend"
+	lkv "github.com/pingcap/tidb/br/pkg/lightning/backend/kv"
+	llog "github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/mock"
+	"github.com/pingcap/tidb/ddl"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/types"
+	tmock "github.com/pingcap/tidb/util/mock"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/tests/v3/integration"
+)
+
+func mockTable(t *testing.T, sql string) table.Table {
+	node, err := parser.New().ParseOneStmt(sql, "", "")
+	require.NoError(t, err)
+	tblInfo, err := ddl.BuildTableInfoFromAST(node.(*ast.CreateTableStmt))
+	require.NoError(t, err)
+	tblInfo.State = model.StatePublic
+	tbl, err := tables.TableFromMeta(lkv.NewPanickingAllocators(0), tblInfo)
+	require.NoError(t, err)
+	return tbl
+}
+
+func TestNewInsertIngesterWithoutPD(t *testing.T) {
+	tbl := mockTable(t, "create table t (a int primary key, b int)")
+	ing, err := newInsertIngester(context.Background(), tmock.NewContext(), tbl)
+	require.NoError(t, err)
+	require.Nil(t, ing)
+}
+
+func TestInsertIngester(t *testing.T) {
+	ctx := context.Background()
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockBackend := mock.NewMockBackend(controller)
+	mockWriter := mock.NewMockEngineWriter(controller)
+
+	mockBackend.EXPECT().MakeEmptyRows().DoAndReturn(func() lkv.Rows {
+		return lkv.MakeRowsFromKvPairs(nil)
+	}).Times(2)
+	mockBackend.EXPECT().NewEncoder(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, tbl table.Table, options *lkv.SessionOptions) (lkv.Encoder, error) {
+			return lkv.NewTableKVEncoder(tbl, options, nil, llog.L())
+		})
+	mockBackend.EXPECT().OpenEngine(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockBackend.EXPECT().LocalWriter(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockWriter, nil)
+	var dataKeys, indexKeys int
+	mockWriter.EXPECT().AppendRows(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, _ []string, rows lkv.Rows) error {
+			for _, pair := range lkv.KvPairsFromRows(rows) {
+				if tablecodec.IsRecordKey(pair.Key) {
+					dataKeys++
+				} else {
+					indexKeys++
+				}
+			}
+			return nil
+		}).Times(4)
+	mockWriter.EXPECT().Close(gomock.Any()).Return(nil, nil)
+	mockBackend.EXPECT().CloseEngine(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockBackend.EXPECT().ImportEngine(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockBackend.EXPECT().CleanupEngine(gomock.Any(), gomock.Any()).Return(nil)
+	mockBackend.EXPECT().Close()
+
+	sortedKVDir := filepath.Join(t.TempDir(), "insert-ingest")
+	require.NoError(t, os.Mkdir(sortedKVDir, 0o700))
+	be := backend.MakeBackend(mockBackend)
+	ing := &insertIngester{
+		backend:     be,
+		sortedKVDir: sortedKVDir,
+		logger:      llog.L(),
+		dataKVs:     be.MakeEmptyRows(),
+		indexKVs:    be.MakeEmptyRows(),
+	}
+	tbl := mockTable(t, "create table t (a int primary key, b int, key(b))")
+	require.NoError(t, ing.open(ctx, tmock.NewContext(), tbl))
+	// the table has its own handle, so no row ID is allocated.
+	require.Nil(t, ing.rowIDAlloc)
+
+	require.NoError(t, ing.Write(ctx, nil))
+	require.NoError(t, ing.Write(ctx, [][]types.Datum{
+		types.MakeDatums(1, 10),
+		types.MakeDatums(2, 20),
+	}))
+	require.NoError(t, ing.Write(ctx, [][]types.Datum{
+		types.MakeDatums(3, 30),
+	}))
+	require.Equal(t, 3, dataKeys)
+	require.Equal(t, 3, indexKeys)
+	require.NoError(t, ing.Finish(ctx))
+	ing.Close(ctx)
+	_, err := os.Stat(sortedKVDir)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestInsertIngesterCloseWithoutFinish(t *testing.T) {
+	ctx := context.Background()
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockBackend := mock.NewMockBackend(controller)
+	mockWriter := mock.NewMockEngineWriter(controller)
+
+	mockBackend.EXPECT().NewEncoder(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, tbl table.Table, options *lkv.SessionOptions) (lkv.Encoder, error) {
+			return lkv.NewTableKVEncoder(tbl, options, nil, llog.L())
+		})
+	mockBackend.EXPECT().OpenEngine(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockBackend.EXPECT().LocalWriter(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockWriter, nil)
+	// the written rows are discarded without being imported.
+	mockWriter.EXPECT().Close(gomock.Any()).Return(nil, nil)
+	mockBackend.EXPECT().Close()
+
+	sortedKVDir := filepath.Join(t.TempDir(), "insert-ingest")
+	require.NoError(t, os.Mkdir(sortedKVDir, 0o700))
+	ing := &insertIngester{
+		backend:     backend.MakeBackend(mockBackend),
+		sortedKVDir: sortedKVDir,
+		logger:      llog.L(),
+	}
+	tbl := mockTable(t, "create table t (a int primary key, b int)")
+	require.NoError(t, ing.open(ctx, tmock.NewContext(), tbl))
+	ing.Close(ctx)
+	_, err := os.Stat(sortedKVDir)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestHasChangefeed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("integration.NewClusterV3 will create file contains a colon which is not allowed on Windows")
+	}
+	integration.BeforeTestExternal(t)
+	cluster := integration.NewClusterV3(t, &integration.ClusterConfig{Size: 1})
+	defer cluster.Terminate(t)
+	ctx := context.Background()
+	cli := cluster.RandClient()
+
+	has, err := hasChangefeed(ctx, nil)
+	require.NoError(t, err)
+	require.False(t, has)
+	has, err = hasChangefeed(ctx, cli)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// the captures and the owner aren't changefeeds.
+	_, err = cli.Put(ctx, "/tidb/cdc/default/__cdc_meta__/capture/1", "")
+	require.NoError(t, err)
+	has, err = hasChangefeed(ctx, cli)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	_, err = cli.Put(ctx, "/tidb/cdc/default/default/changefeed/info/cf", "")
+	require.NoError(t, err)
+	has, err = hasChangefeed(ctx, cli)
+	require.NoError(t, err)
+	require.True(t, has)
+	_, err = cli.Delete(ctx, "/tidb/cdc/default/default/changefeed/info/cf")
+	require.NoError(t, err)
+
+	// the changefeeds of the versions before v6.2.
+	_, err = cli.Put(ctx, "/tidb/cdc/changefeed/info/cf", "")
+	require.NoError(t, err)
+	has, err = hasChangefeed(ctx, cli)
+	require.NoError(t, err)
+	require.True(t, has)
+}
@@ -44,6 +44,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/br/pkg/lightning/metric"
 	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/lightning/notify"
 	"github.com/pingcap/tidb/br/pkg/lightning/restore"
 	"github.com/pingcap/tidb/br/pkg/lightning/tikv"
 	"github.com/pingcap/tidb/br/pkg/lightning/web"
@@ -79,8 +80,14 @@ type Lightning struct {
 	promRegistry promutil.Registry
 
 	cancelLock sync.Mutex
-	curTask    *config.Config
-	cancel     context.CancelFunc // for per task context, which maybe different from lightning context
+	// runningTasks are the tasks being run in the order of starting, whose
+	// cancel functions are for per task context, which maybe different from
+	// lightning context.
+	runningTasks []*runningTask
+	// queuedTables are the target tables of the queued tasks, which are only
+	// resolved if the server mode runs the tasks concurrently.
+	queuedTables      map[*config.Config]map[string]struct{}
+	reservedDiskQuota int64
 }
 
 func initEnv(cfg *config.GlobalConfig) error {
@@ -100,6 +107,9 @@ func New(globalCfg *config.GlobalConfig) *Lightning {
 	if err != nil {
 		log.L().Fatal("failed to load TLS certificates", zap.Error(err))
 	}
+	if globalCfg.Security.FIPSMode {
+		common.RestrictTLSToFIPS(tls.TLSConfig())
+	}
 
 	redact.InitRedact(globalCfg.Security.RedactInfoLog)
 
@@ -113,6 +123,7 @@ func New(globalCfg *config.GlobalConfig) *Lightning {
 		shutdown:     shutdown,
 		promFactory:  promFactory,
 		promRegistry: promRegistry,
+		queuedTables: make(map[*config.Config]map[string]struct{}),
 	}
 }
 
@@ -224,6 +235,7 @@ func (l *Lightning) goServe(statusAddr string, realAddrWriter io.Writer) error {
 	mux.Handle("/tasks/", httpHandleWrapper(handleTasks.ServeHTTP))
 	mux.HandleFunc("/progress/task", httpHandleWrapper(handleProgressTask))
 	mux.HandleFunc("/progress/table", httpHandleWrapper(handleProgressTable))
+	mux.HandleFunc("/api/v1/progress", httpHandleWrapper(handleProgressAPI))
 	mux.HandleFunc("/pause", httpHandleWrapper(handlePause))
 	mux.HandleFunc("/resume", httpHandleWrapper(handleResume))
 	mux.HandleFunc("/loglevel", httpHandleWrapper(handleLogLevel))
@@ -292,20 +304,43 @@ func (l *Lightning) RunServer() error {
 	)
 
 	for {
-		task, err := l.taskCfgs.Pop(l.ctx)
+		task, err := l.taskCfgs.PopFunc(l.ctx, l.tryReserveTask)
 		if err != nil {
 			return err
 		}
+		if !l.concurrentTasksEnabled() {
+			l.runServerTask(task)
+			continue
+		}
+		go l.runServerTask(task)
+	}
+}
+
+// runServerTask runs a task taken out of the queue of the server mode.
+func (l *Lightning) runServerTask(task *config.Config) {
+	l.cancelLock.Lock()
+	if reserved := l.getRunningTaskLocked(task.TaskID); reserved != nil && reserved.diskQuota > 0 {
+		task.TikvImporter.DiskQuota = config.ByteSize(reserved.diskQuota)
+	}
+	l.cancelLock.Unlock()
+
+	var err error
+	if l.concurrentTasksEnabled() {
+		err = isolateTask(task)
+	}
+	if err == nil {
 		o := &options{
 			promFactory:  l.promFactory,
-			promRegistry: l.promRegistry,
-			logger:       log.L(),
+			promRegistry: l.taskRegistry(task),
+			logger:       log.L().With(zap.Int64("taskID", task.TaskID)),
 		}
 		err = l.run(context.Background(), task, o)
-		if err != nil && !common.IsContextCanceledError(err) {
-			restore.DeliverPauser.Pause() // force pause the progress on error
-			log.L().Error("tidb lightning encountered error", zap.Error(err))
-		}
+	} else {
+		l.finishTask(task.TaskID)
+	}
+	if err != nil && !common.IsContextCanceledError(err) {
+		restore.DeliverPauser.Pause() // force pause the progress on error
+		log.L().Error("tidb lightning encountered error", zap.Int64("taskID", task.TaskID), zap.Error(err))
 	}
 }
 
@@ -381,21 +416,29 @@ func (l *Lightning) run(taskCtx context.Context, taskCfg *config.Config, o *opti
 		metrics.UnregisterFrom(o.promRegistry)
 	}()
 
+	notifier, err := notify.NewNotifier(taskCfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	ctx := metric.NewContext(taskCtx, metrics)
 	ctx = log.NewContext(ctx, o.logger)
+	ctx = notify.NewContext(ctx, notifier)
 	ctx, cancel := context.WithCancel(ctx)
-	l.cancelLock.Lock()
-	l.cancel = cancel
-	l.curTask = taskCfg
-	l.cancelLock.Unlock()
+	l.startTask(taskCfg, cancel)
 	web.BroadcastStartTask()
 
 	defer func() {
 		cancel()
-		l.cancelLock.Lock()
-		l.cancel = nil
-		l.cancelLock.Unlock()
+		l.finishTask(taskCfg.TaskID)
 		web.BroadcastEndTask(err)
+
+		event := &notify.Event{Type: config.EventTaskCompleted}
+		if err != nil {
+			event.Error = err.Error()
+		}
+		// the task context is canceled, but the completion should still be notified.
+		notifier.Notify(log.NewContext(context.Background(), o.logger), event)
 	}()
 
 	failpoint.Inject("SkipRunTask", func() {
@@ -430,10 +473,7 @@ func (l *Lightning) run(taskCtx context.Context, taskCfg *config.Config, o *opti
 	}
 	defer func() {
 		// deregister TLS config with name "cluster"
-		if taskCfg.TiDB.Security == nil {
-			return
-		}
-		taskCfg.TiDB.Security.DeregisterMySQL()
+		l.deregisterTLSConfig(taskCfg)
 	}()
 
 	// initiation of default glue should be after RegisterMySQL, which is ready to be called after taskCfg.Adjust
@@ -458,6 +498,8 @@ func (l *Lightning) run(taskCtx context.Context, taskCfg *config.Config, o *opti
 			return common.NormalizeError(err)
 		}
 	}
+	// all the parsers read the data files from s, so they share the bandwidth limit.
+	s = storage.WithReadBandwidthLimit(s, int64(taskCfg.Mydumper.ReadBandwidthLimit))
 
 	// return expectedErr means at least meet one file
 	expectedErr := errors.New("Stop Iter")
@@ -472,26 +514,71 @@ func (l *Lightning) run(taskCtx context.Context, taskCfg *config.Config, o *opti
 		return common.NormalizeOrWrapErr(common.ErrStorageUnknown, walkErr)
 	}
 
+	// the versions are checked before loading the data source which may take a long time.
+	if taskCfg.App.CheckRequirements {
+		if err := restore.CheckClusterFeatures(ctx, taskCfg, g); err != nil {
+			o.logger.Error("check cluster features failed", zap.Error(err))
+			return common.NormalizeError(err)
+		}
+	}
+
+	if taskCfg.Mydumper.WatchInterval.Duration > 0 {
+		return errors.Trace(l.watch(ctx, taskCfg, o, g, s))
+	}
+	_, err = l.importSource(ctx, taskCfg, o, g, s, nil)
+	return errors.Trace(err)
+}
+
+// importSource imports the data source. If `importedDB` is not nil, the data
+// files recorded in it are skipped, and the offsets up to which the newly
+// imported data files are imported are recorded into it before the checkpoints
+// are cleaned and returned by path.
+func (l *Lightning) importSource(
+	ctx context.Context,
+	taskCfg *config.Config,
+	o *options,
+	g glue.Glue,
+	s storage.ExternalStorage,
+	importedDB checkpoints.ImportedFilesDB,
+) (newFiles map[string]int64, err error) {
 	loadTask := o.logger.Begin(zap.InfoLevel, "load data source")
 	var mdl *mydump.MDLoader
 	mdl, err = mydump.NewMyDumpLoaderWithStore(ctx, taskCfg, s)
 	loadTask.End(zap.ErrorLevel, err)
 	if err != nil {
-		return errors.Trace(err)
+		return nil, errors.Trace(err)
 	}
 	err = checkSystemRequirement(taskCfg, mdl.GetDatabases())
 	if err != nil {
 		o.logger.Error("check system requirements failed", zap.Error(err))
-		return common.ErrSystemRequirementNotMet.Wrap(err).GenWithStackByArgs()
+		return nil, common.ErrSystemRequirementNotMet.Wrap(err).GenWithStackByArgs()
 	}
 	// check table schema conflicts
 	err = checkSchemaConflict(taskCfg, mdl.GetDatabases())
 	if err != nil {
 		o.logger.Error("checkpoint schema conflicts with data files", zap.Error(err))
-		return errors.Trace(err)
+		return nil, errors.Trace(err)
 	}
 
 	dbMetas := mdl.GetDatabases()
+	if importedDB != nil {
+		var imported map[string]int64
+		imported, err = importedDB.ImportedFiles(ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		dbMetas, err = excludeImportedFiles(ctx, taskCfg, s, dbMetas, imported)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		newFiles, err = restrictToCheckpoints(ctx, taskCfg, dbMetas)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(newFiles) == 0 {
+			return nil, nil
+		}
+	}
 	web.BroadcastInitProgress(dbMetas)
 
 	var procedure *restore.Controller
@@ -505,22 +592,29 @@ func (l *Lightning) run(taskCtx context.Context, taskCfg *config.Config, o *opti
 		CheckpointStorage: o.checkpointStorage,
 		CheckpointName:    o.checkpointName,
 	}
+	if importedDB != nil {
+		param.OnImported = func(ctx context.Context) error {
+			return errors.Trace(importedDB.AddImportedFiles(ctx, newFiles))
+		}
+	}
 
 	procedure, err = restore.NewRestoreController(ctx, taskCfg, param)
 	if err != nil {
 		o.logger.Error("restore failed", log.ShortError(err))
-		return errors.Trace(err)
+		return nil, errors.Trace(err)
 	}
 	defer procedure.Close()
 
 	err = procedure.Run(ctx)
-	return errors.Trace(err)
+	return newFiles, errors.Trace(err)
 }
 
 func (l *Lightning) Stop() {
 	l.cancelLock.Lock()
-	if l.cancel != nil {
-		l.cancel()
+	for _, task := range l.runningTasks {
+		if task.cancel != nil {
+			task.cancel()
+		}
 	}
 	l.cancelLock.Unlock()
 	if err := l.server.Shutdown(l.ctx); err != nil {
@@ -598,8 +692,9 @@ func (l *Lightning) handleTask(w http.ResponseWriter, req *http.Request) {
 
 func (l *Lightning) handleGetTask(w http.ResponseWriter) {
 	var response struct {
-		Current   *int64  `json:"current"`
-		QueuedIDs []int64 `json:"queue"`
+		Current    *int64  `json:"current"`
+		RunningIDs []int64 `json:"running"`
+		QueuedIDs  []int64 `json:"queue"`
 	}
 	l.serverLock.Lock()
 	if l.taskCfgs != nil {
@@ -609,10 +704,17 @@ func (l *Lightning) handleGetTask(w http.ResponseWriter) {
 	}
 	l.serverLock.Unlock()
 
+	response.RunningIDs = []int64{}
 	l.cancelLock.Lock()
-	if l.cancel != nil && l.curTask != nil {
-		response.Current = new(int64)
-		*response.Current = l.curTask.TaskID
+	for _, task := range l.runningTasks {
+		if task.cancel == nil {
+			continue
+		}
+		if response.Current == nil {
+			response.Current = new(int64)
+			*response.Current = task.cfg.TaskID
+		}
+		response.RunningIDs = append(response.RunningIDs, task.cfg.TaskID)
 	}
 	l.cancelLock.Unlock()
 
@@ -624,8 +726,8 @@ func (l *Lightning) handleGetOneTask(w http.ResponseWriter, req *http.Request, t
 	var task *config.Config
 
 	l.cancelLock.Lock()
-	if l.curTask != nil && l.curTask.TaskID == taskID {
-		task = l.curTask
+	if running := l.getRunningTaskLocked(taskID); running != nil {
+		task = running.cfg
 	}
 	l.cancelLock.Unlock()
 
@@ -683,6 +785,16 @@ func (l *Lightning) handlePostTask(w http.ResponseWriter, req *http.Request) {
 		writeJSONError(w, http.StatusBadRequest, "invalid task configuration", err)
 		return
 	}
+	if l.concurrentTasksEnabled() {
+		tables, err := resolveTargetTables(l.ctx, cfg)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "cannot resolve the target tables", err)
+			return
+		}
+		l.cancelLock.Lock()
+		l.queuedTables[cfg] = tables
+		l.cancelLock.Unlock()
+	}
 
 	l.taskCfgs.Push(cfg)
 	w.WriteHeader(http.StatusOK)
@@ -702,9 +814,9 @@ func (l *Lightning) handleDeleteOneTask(w http.ResponseWriter, req *http.Request
 	cancelSuccess := false
 
 	l.cancelLock.Lock()
-	if l.cancel != nil && l.curTask != nil && l.curTask.TaskID == taskID {
-		cancel = l.cancel
-		l.cancel = nil
+	if task := l.getRunningTaskLocked(taskID); task != nil && task.cancel != nil {
+		cancel = task.cancel
+		task.cancel = nil
 	}
 	l.cancelLock.Unlock()
 
@@ -712,7 +824,13 @@ func (l *Lightning) handleDeleteOneTask(w http.ResponseWriter, req *http.Request
 		cancel()
 		cancelSuccess = true
 	} else if l.taskCfgs != nil {
-		cancelSuccess = l.taskCfgs.Remove(taskID)
+		cfg, ok := l.taskCfgs.Get(taskID)
+		cancelSuccess = ok && l.taskCfgs.Remove(taskID)
+		if cancelSuccess {
+			l.cancelLock.Lock()
+			delete(l.queuedTables, cfg)
+			l.cancelLock.Unlock()
+		}
 	}
 
 	log.L().Info("canceled task", zap.Int64("taskID", taskID), zap.Bool("success", cancelSuccess))
@@ -796,6 +914,28 @@ func handleProgressTable(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// handleProgressAPI reports the structured progress of the current task, which
+// is polled by the orchestration systems.
+func handleProgressAPI(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "only GET is allowed", nil)
+		return
+	}
+	progress, err := web.GetTaskProgress()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to get the progress", err)
+		return
+	}
+	res, err := json.Marshal(progress)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to marshal the progress", err)
+		return
+	}
+	writeBytesCompressed(w, req, res)
+}
+
 func handlePause(w http.ResponseWriter, req *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -911,6 +1051,38 @@ func checkSchemaConflict(cfg *config.Config, dbsMeta []*mydump.MDDatabaseMeta) e
 	}
 	return nil
 }
+
+// DryRunPlan loads the data source and writes its import plan to w in the given format
+// without importing anything.
+func DryRunPlan(ctx context.Context, taskCfg *config.Config, format string, w io.Writer) error {
+	if err := taskCfg.Adjust(ctx); err != nil {
+		return err
+	}
+	u, err := storage.ParseBackend(taskCfg.Mydumper.SourceDir, nil)
+	if err != nil {
+		return common.NormalizeError(err)
+	}
+	s, err := storage.New(ctx, u, &storage.ExternalStorageOptions{})
+	if err != nil {
+		return common.NormalizeError(err)
+	}
+	mdl, err := mydump.NewMyDumpLoaderWithStore(ctx, taskCfg, s)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return mdl.Plan(taskCfg).Write(w, format)
+}
+
+// PrintResolvedCredentialsSource prints where the credentials of the data source
+// are resolved from, without reading any data file.
+func PrintResolvedCredentialsSource(ctx context.Context, taskCfg *config.Config, w io.Writer) error {
+	u, err := storage.ParseBackend(taskCfg.Mydumper.SourceDir, nil)
+	if err != nil {
+		return common.NormalizeError(err)
+	}
+	return storage.PrintResolvedCredentialsSource(ctx, w, u, &storage.ExternalStorageOptions{})
+}
+
 func CheckpointRemove(ctx context.Context, cfg *config.Config, tableName string) error {
 	cpdb, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
 	if err != nil {
@@ -15,9 +15,13 @@
 package lightning
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/docker/go-units"
@@ -27,6 +31,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/lightning/glue"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/storage"
 	"github.com/stretchr/testify/require"
 )
 
@@ -95,6 +100,33 @@ func TestRun(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestDryRunPlan(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db-schema-create.sql"), []byte("CREATE DATABASE db;"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.t-schema.sql"), []byte("CREATE TABLE t (a INT);"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.t.1.csv"), []byte("1\n2\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.t.1.csv"), []byte("1\n"), 0o644))
+
+	globalConfig := config.NewGlobalConfig()
+	globalConfig.TiDB.Port = 4000
+	globalConfig.Mydumper.SourceDir = dir
+	globalConfig.Mydumper.Filter = []string{"db.*"}
+	globalConfig.TikvImporter.Backend = config.BackendTiDB
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadFromGlobal(globalConfig))
+
+	var buf bytes.Buffer
+	require.NoError(t, DryRunPlan(context.Background(), cfg, mydump.PlanFormatJSON, &buf))
+	var plan mydump.ImportPlan
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &plan))
+	require.Equal(t, 1, plan.TotalFiles)
+	require.Len(t, plan.Databases, 1)
+	require.Equal(t, "db", plan.Databases[0].Name)
+	require.Len(t, plan.Databases[0].Tables, 1)
+	require.Equal(t, "db.t-schema.sql", plan.Databases[0].Tables[0].SchemaFile)
+	require.Equal(t, []mydump.FilePlan{{Path: "db.t.1.csv", Type: "csv", SortKey: "1", Size: 4}}, plan.Databases[0].Tables[0].Files)
+}
+
 func TestCheckSystemRequirement(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("Local-backend is not supported on Windows")
@@ -221,3 +253,158 @@ func TestCheckSchemaConflict(t *testing.T) {
 	err = checkSchemaConflict(cfg, dbMetas)
 	require.NoError(t, err)
 }
+
+func TestWatchNewFiles(t *testing.T) {
+	ctx := context.Background()
+	dataFile := func(path string) mydump.FileInfo {
+		return mydump.FileInfo{FileMeta: mydump.SourceFileMeta{Path: path, FileSize: 10}}
+	}
+	newDBMetas := func() []*mydump.MDDatabaseMeta {
+		return []*mydump.MDDatabaseMeta{
+			{
+				Name: "db1",
+				Tables: []*mydump.MDTableMeta{
+					{DB: "db1", Name: "t1", DataFiles: []mydump.FileInfo{dataFile("db1.t1.1.csv"), dataFile("db1.t1.2.csv")}},
+					{DB: "db1", Name: "t2", DataFiles: []mydump.FileInfo{dataFile("db1.t2.1.csv")}},
+					{DB: "db1", Name: "t3"},
+				},
+			},
+			{
+				Name: "db2",
+				Tables: []*mydump.MDTableMeta{
+					{DB: "db2", Name: "t1", DataFiles: []mydump.FileInfo{dataFile("db2.t1.1.csv")}},
+				},
+			},
+		}
+	}
+
+	cfg := config.NewConfig()
+	cfg.Checkpoint.Enable = true
+	cfg.Checkpoint.Driver = config.CheckpointDriverFile
+	cfg.Checkpoint.DSN = filepath.Join(t.TempDir(), "cp.pb")
+
+	dbMetas, err := excludeImportedFiles(ctx, cfg, nil, newDBMetas(), map[string]int64{
+		"db1.t1.1.csv": 10,
+		"db1.t2.1.csv": 10,
+		"db2.t1.1.csv": 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, dbMetas, 1)
+	require.Len(t, dbMetas[0].Tables, 2)
+	require.Equal(t, "t1", dbMetas[0].Tables[0].Name)
+	require.Equal(t, []mydump.FileInfo{dataFile("db1.t1.2.csv")}, dbMetas[0].Tables[0].DataFiles)
+	require.Equal(t, int64(10), dbMetas[0].Tables[0].TotalSize)
+	require.Equal(t, "t3", dbMetas[0].Tables[1].Name)
+
+	// without checkpoints, all the new files are imported.
+	dbMetas, err = excludeImportedFiles(ctx, cfg, nil, newDBMetas(), map[string]int64{"db1.t1.1.csv": 10})
+	require.NoError(t, err)
+	newFiles, err := restrictToCheckpoints(ctx, cfg, dbMetas)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"db1.t1.2.csv": 10, "db1.t2.1.csv": 10, "db2.t1.1.csv": 10}, newFiles)
+
+	// an interrupted round only imports the files in the checkpoints.
+	cpdb, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
+	require.NoError(t, err)
+	require.NoError(t, cpdb.Initialize(ctx, cfg, map[string]*checkpoints.TidbDBInfo{
+		"db1": {Name: "db1", Tables: map[string]*checkpoints.TidbTableInfo{"t1": {Name: "t1"}, "t2": {Name: "t2"}}},
+	}))
+	require.NoError(t, cpdb.InsertEngineCheckpoints(ctx, "`db1`.`t1`", map[int32]*checkpoints.EngineCheckpoint{
+		0: {
+			Status: checkpoints.CheckpointStatusLoaded,
+			Chunks: []*checkpoints.ChunkCheckpoint{{
+				Key:      checkpoints.ChunkCheckpointKey{Path: "db1.t1.2.csv"},
+				FileMeta: mydump.SourceFileMeta{Path: "db1.t1.2.csv", FileSize: 8},
+				Chunk:    mydump.Chunk{EndOffset: 8},
+			}},
+		},
+	}))
+	require.NoError(t, cpdb.Close())
+	dbMetas, err = excludeImportedFiles(ctx, cfg, nil, newDBMetas(), map[string]int64{})
+	require.NoError(t, err)
+	newFiles, err = restrictToCheckpoints(ctx, cfg, dbMetas)
+	require.NoError(t, err)
+	// the imported size of the file in the checkpoints is taken from its chunks.
+	require.Equal(t, map[string]int64{"db1.t1.2.csv": 8, "db1.t2.1.csv": 10, "db2.t1.1.csv": 10}, newFiles)
+}
+
+func TestWatchGrowingFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+	cfg := config.NewConfig()
+	cfg.Mydumper.WatchGrowingFiles = true
+	cfg.Mydumper.StrictFormat = true
+	cfg.Mydumper.CSV.Header = true
+
+	dataFile := func(path string, content string) mydump.FileInfo {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644))
+		return mydump.FileInfo{FileMeta: mydump.SourceFileMeta{
+			Path:     path,
+			Type:     mydump.SourceTypeCSV,
+			FileSize: int64(len(content)),
+		}}
+	}
+	excludeImported := func(file mydump.FileInfo, imported map[string]int64) []mydump.FileInfo {
+		dbMetas, err := excludeImportedFiles(ctx, cfg, store, []*mydump.MDDatabaseMeta{{
+			Name:   "db",
+			Tables: []*mydump.MDTableMeta{{DB: "db", Name: "t", DataFiles: []mydump.FileInfo{file}}},
+		}}, imported)
+		require.NoError(t, err)
+		if len(dbMetas) == 0 {
+			return nil
+		}
+		return dbMetas[0].Tables[0].DataFiles
+	}
+
+	// the incomplete row at the end is left to the next round.
+	file := dataFile("db.t.csv", "a,b\n1,2\n3,")
+	files := excludeImported(file, map[string]int64{})
+	require.Len(t, files, 1)
+	require.Equal(t, []mydump.FileRegion{{Offset: 0, EndOffset: 8}}, files[0].Regions)
+	require.Equal(t, int64(8), importedEnd(files[0]))
+
+	// only the appended rows are imported with the columns in the header.
+	file = dataFile("db.t.csv", "a,b\n1,2\n3,4\n5,6\n")
+	files = excludeImported(file, map[string]int64{"db.t.csv": 8})
+	require.Len(t, files, 1)
+	require.Equal(t, []mydump.FileRegion{{Offset: 8, EndOffset: 16, Columns: []string{"a", "b"}}}, files[0].Regions)
+	require.Equal(t, int64(16), importedEnd(files[0]))
+	require.Empty(t, excludeImported(file, map[string]int64{"db.t.csv": 16}))
+
+	// the appended data without a line feed isn't imported.
+	file = dataFile("db.t.csv", "a,b\n1,2\n3,4\n5,6\n7")
+	require.Empty(t, excludeImported(file, map[string]int64{"db.t.csv": 16}))
+
+	// the complete new file is imported as a whole.
+	file = dataFile("db.t.csv", "a,b\n1,2\n")
+	files = excludeImported(file, map[string]int64{})
+	require.Equal(t, []mydump.FileInfo{file}, files)
+
+	// the appended data of the compressed files isn't imported.
+	file.FileMeta.Compression = mydump.CompressionGZ
+	require.Empty(t, excludeImported(file, map[string]int64{"db.t.csv": 4}))
+	require.Equal(t, []mydump.FileInfo{file}, excludeImported(file, map[string]int64{}))
+
+	// the appended data of the CSV files whose quoted fields may contain line feeds isn't imported.
+	file = dataFile("db.t.csv", "a,b\n1,2\n3,4\n")
+	cfg.Mydumper.StrictFormat = false
+	require.Empty(t, excludeImported(file, map[string]int64{"db.t.csv": 8}))
+	cfg.Mydumper.CSV.Delimiter = ""
+	files = excludeImported(file, map[string]int64{"db.t.csv": 8})
+	require.Len(t, files, 1)
+	require.Equal(t, []mydump.FileRegion{{Offset: 8, EndOffset: 12, Columns: []string{"a", "b"}}}, files[0].Regions)
+
+	// neither is the one of the SQL files, whose statements may span lines.
+	file = dataFile("db.t.sql", "INSERT INTO t VALUES\n(1,2),\n(3,4);\n")
+	file.FileMeta.Type = mydump.SourceTypeSQL
+	require.Empty(t, excludeImported(file, map[string]int64{"db.t.sql": 28}))
+	require.Equal(t, []mydump.FileInfo{file}, excludeImported(file, map[string]int64{}))
+
+	// the line feed is searched across the blocks.
+	content := "a,b\n" + strings.Repeat("1,2\n", lineEndSearchBlockSize/4) + strings.Repeat("3", lineEndSearchBlockSize+10)
+	end, err := lastLineEnd(ctx, store, dataFile("db.t.csv", content).FileMeta.Path, 4, int64(len(content)))
+	require.NoError(t, err)
+	require.Equal(t, int64(4+lineEndSearchBlockSize), end)
+}
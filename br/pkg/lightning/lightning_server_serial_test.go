@@ -23,6 +23,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -301,6 +303,114 @@ func TestGetDeleteTask(t *testing.T) {
 	}, getAllTasks())
 }
 
+func TestConcurrentTasks(t *testing.T) {
+	s := createSuite(t)
+	s.lightning.globalCfg.App.MaxConcurrentTasks = 3
+	s.lightning.globalCfg.App.ServerDiskQuota = 100
+
+	url := "http://" + s.lightning.serverAddr.String() + "/tasks"
+
+	type getAllResultType struct {
+		Current int64
+		Running []int64
+		Queue   []int64
+	}
+
+	getAllTasks := func() (result getAllResultType) {
+		resp, err := http.Get(url)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		require.NoError(t, resp.Body.Close())
+		require.NoError(t, err)
+		return
+	}
+
+	postTask := func(table string, diskQuota int, priority string) int64 {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "db."+table+".sql"), []byte("INSERT INTO t VALUES (1);"), 0o644))
+		resp, err := http.Post(url, "application/toml", strings.NewReader(fmt.Sprintf(`
+			[lightning]
+			task-priority = '%s'
+			[mydumper]
+			data-source-dir = 'file://%s'
+			[tikv-importer]
+			disk-quota = %d
+		`, priority, filepath.ToSlash(dir), diskQuota)))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var result struct{ ID int64 }
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		require.NoError(t, resp.Body.Close())
+		require.NoError(t, err)
+		return result.ID
+	}
+
+	cancelTask := func(id int64) {
+		req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/%d", url, id), nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	go func() {
+		_ = s.lightning.RunServer()
+	}()
+	time.Sleep(500 * time.Millisecond)
+
+	first := postTask("t1", 60, config.PriorityNormal)
+	// conflicts with the first task.
+	second := postTask("t1", 10, config.PriorityLow)
+	// exceeds the server disk quota along with the first task.
+	third := postTask("t2", 70, config.PriorityHigh)
+	fourth := postTask("t3", 40, config.PriorityNormal)
+
+	require.Eventually(t, func() bool {
+		return len(getAllTasks().Running) == 2
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Equal(t, getAllResultType{
+		Current: first,
+		Running: []int64{first, fourth},
+		Queue:   []int64{third, second},
+	}, getAllTasks())
+
+	// each task runs with its own sorted-kv-dir.
+	for _, id := range []int64{first, fourth} {
+		resp, err := http.Get(fmt.Sprintf("%s/%d", url, id))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var taskCfg config.Config
+		err = json.NewDecoder(resp.Body).Decode(&taskCfg)
+		require.NoError(t, resp.Body.Close())
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("task_%d", id), filepath.Base(taskCfg.TikvImporter.SortedKVDir))
+	}
+
+	// the second task starts after the conflicting first task finishes, but the
+	// third task still waits for the disk quota.
+	cancelTask(first)
+	require.Eventually(t, func() bool {
+		return len(getAllTasks().Queue) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Equal(t, getAllResultType{
+		Current: fourth,
+		Running: []int64{fourth, second},
+		Queue:   []int64{third},
+	}, getAllTasks())
+
+	cancelTask(fourth)
+	require.Eventually(t, func() bool {
+		return len(getAllTasks().Queue) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Equal(t, getAllResultType{
+		Current: second,
+		Running: []int64{second, third},
+		Queue:   []int64{},
+	}, getAllTasks())
+}
+
 func TestHTTPAPIOutsideServerMode(t *testing.T) {
 	s := createSuite(t)
 
@@ -376,3 +486,24 @@ func TestHTTPAPIOutsideServerMode(t *testing.T) {
 	// ... and the task should be canceled now.
 	require.Equal(t, context.Canceled, <-errCh)
 }
+
+func TestProgressAPI(t *testing.T) {
+	s := createSuite(t)
+
+	url := "http://" + s.lightning.serverAddr.String() + "/api/v1/progress"
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var progress web.TaskProgress
+	err = json.NewDecoder(resp.Body).Decode(&progress)
+	require.NoError(t, resp.Body.Close())
+	require.NoError(t, err)
+	require.NotEmpty(t, progress.Status)
+
+	resp, err = http.Post(url, "application/json", nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+}
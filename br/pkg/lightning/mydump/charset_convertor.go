@@ -31,20 +31,33 @@ type CharsetConvertor struct {
 	sourceCharacterSet config.Charset
 	// invalidCharReplacement is the default replacement character bytes for the invalid content, e.g "\ufffd".
 	invalidCharReplacement string
+	// invalidCharAction is how to handle the invalid content, see `config.InvalidChar*`.
+	invalidCharAction string
 
 	decoder *encoding.Decoder
 	encoder *encoding.Encoder
 }
 
-// NewCharsetConvertor creates a new CharsetConvertor.
-func NewCharsetConvertor(dataCharacterSet, dataInvalidCharReplace string) (*CharsetConvertor, error) {
+// NewCharsetConvertor creates a new CharsetConvertor. An empty dataInvalidCharAction means
+// replacing the invalid content with dataInvalidCharReplace.
+func NewCharsetConvertor(dataCharacterSet, dataInvalidCharReplace, dataInvalidCharAction string) (*CharsetConvertor, error) {
 	sourceCharacterSet, err := config.ParseCharset(dataCharacterSet)
 	if err != nil {
 		return nil, err
 	}
+	switch dataInvalidCharAction {
+	case "":
+		dataInvalidCharAction = config.InvalidCharReplace
+	case config.InvalidCharReplace, config.InvalidCharError:
+	case config.InvalidCharSkip:
+		dataInvalidCharReplace = ""
+	default:
+		return nil, errors.Errorf("unsupported invalid char action %s", dataInvalidCharAction)
+	}
 	cc := &CharsetConvertor{
 		sourceCharacterSet,
 		dataInvalidCharReplace,
+		dataInvalidCharAction,
 		nil, nil,
 	}
 	err = cc.initDecoder()
@@ -99,6 +112,12 @@ func (cc *CharsetConvertor) Decode(src string) (string, error) {
 	if err != nil {
 		return res, err
 	}
+	if !strings.ContainsRune(res, utf8.RuneError) {
+		return res, nil
+	}
+	if cc.invalidCharAction == config.InvalidCharError {
+		return "", errors.Errorf("invalid %s content: %q", cc.sourceCharacterSet, src)
+	}
 	return strings.ReplaceAll(res, string(utf8.RuneError), cc.invalidCharReplacement), nil
 }
 
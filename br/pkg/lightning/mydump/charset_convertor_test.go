@@ -19,6 +19,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -44,7 +45,7 @@ func TestCharsetConvertor(t *testing.T) {
 	gbkData, err := io.ReadAll(gbkReader)
 	require.NoError(t, err)
 
-	cc, err := NewCharsetConvertor("gb18030", "\ufffd")
+	cc, err := NewCharsetConvertor("gb18030", "\ufffd", "")
 	require.NoError(t, err)
 	gbkToUTF8Data, err := cc.Decode(string(gbkData))
 	require.NoError(t, err)
@@ -72,9 +73,37 @@ func TestInvalidCharReplace(t *testing.T) {
 	require.NoError(t, err)
 	gbkData, err := io.ReadAll(gbkReader)
 	require.NoError(t, err)
-	cc, err := NewCharsetConvertor("gb18030", dataInvalidCharReplace)
+	cc, err := NewCharsetConvertor("gb18030", dataInvalidCharReplace, "")
 	require.NoError(t, err)
 	gbkToUTF8Data, err := cc.Decode(string(gbkData))
 	require.NoError(t, err)
 	require.Equal(t, string(expectedData), gbkToUTF8Data)
 }
+
+func TestInvalidCharAction(t *testing.T) {
+	// Input: 你好invalid char你好
+	inputData := append(append(append([]byte{}, normalCharGB18030...), invalidChar...), normalCharGB18030...)
+
+	cc, err := NewCharsetConvertor("gb18030", "?", config.InvalidCharReplace)
+	require.NoError(t, err)
+	res, err := cc.Decode(string(inputData))
+	require.NoError(t, err)
+	require.Equal(t, string(normalCharUTF8MB4)+"?"+string(normalCharUTF8MB4), res)
+
+	cc, err = NewCharsetConvertor("gb18030", "?", config.InvalidCharSkip)
+	require.NoError(t, err)
+	res, err = cc.Decode(string(inputData))
+	require.NoError(t, err)
+	require.Equal(t, string(normalCharUTF8MB4)+string(normalCharUTF8MB4), res)
+
+	cc, err = NewCharsetConvertor("gb18030", "?", config.InvalidCharError)
+	require.NoError(t, err)
+	res, err = cc.Decode(string(normalCharGB18030))
+	require.NoError(t, err)
+	require.Equal(t, string(normalCharUTF8MB4), res)
+	_, err = cc.Decode(string(inputData))
+	require.ErrorContains(t, err, "invalid gb18030 content")
+
+	_, err = NewCharsetConvertor("gb18030", "?", "ignore")
+	require.ErrorContains(t, err, "unsupported invalid char action ignore")
+}
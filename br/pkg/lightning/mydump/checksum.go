@@ -0,0 +1,175 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" // #nosec G501
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"hash/crc64"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/storage"
+)
+
+// checksumSidecarExts maps the extensions of the sidecar files to the hash
+// algorithms. A sidecar file `{data file}.sha256` contains the digest of the
+// data file in the format of the `sha256sum` command.
+var checksumSidecarExts = map[string]string{
+	".sha256": ChecksumSHA256,
+	".md5":    ChecksumMD5,
+	".crc64":  ChecksumCRC64,
+}
+
+const (
+	// ChecksumSHA256 is the name of the SHA-256 algorithm.
+	ChecksumSHA256 = "sha256"
+	// ChecksumMD5 is the name of the MD5 algorithm.
+	ChecksumMD5 = "md5"
+	// ChecksumCRC64 is the name of the CRC-64 algorithm with the ECMA polynomial, whose
+	// digest is in big-endian.
+	ChecksumCRC64 = "crc64"
+)
+
+// FileChecksum is the expected checksum of a source file discovered from the data source.
+type FileChecksum struct {
+	// Algorithm is the hash algorithm, it is empty if the checksum is unknown.
+	Algorithm string
+	// Digest is the hex-encoded digest in lower case.
+	Digest string
+}
+
+// IsEmpty returns whether the checksum is unknown.
+func (c FileChecksum) IsEmpty() bool {
+	return len(c.Algorithm) == 0
+}
+
+func newHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case ChecksumSHA256:
+		return sha256.New(), nil
+	case ChecksumMD5:
+		return md5.New(), nil // #nosec G401
+	case ChecksumCRC64:
+		return crc64.New(crc64.MakeTable(crc64.ECMA)), nil
+	default:
+		return nil, errors.Errorf("unknown checksum algorithm '%s'", algorithm)
+	}
+}
+
+// checksumSidecarOf returns the data file and the hash algorithm of a checksum sidecar file.
+func checksumSidecarOf(path string) (dataPath string, algorithm string, ok bool) {
+	ext := filepath.Ext(path)
+	algorithm, ok = checksumSidecarExts[strings.ToLower(ext)]
+	if !ok {
+		return "", "", false
+	}
+	return strings.TrimSuffix(path, ext), algorithm, true
+}
+
+// readChecksumSidecar reads the digest from a checksum sidecar file, whose first
+// field is the hex-encoded digest.
+func readChecksumSidecar(ctx context.Context, store storage.ExternalStorage, path string, algorithm string) (FileChecksum, error) {
+	content, err := store.ReadFile(ctx, path)
+	if err != nil {
+		return FileChecksum{}, errors.Trace(err)
+	}
+	fields := bytes.Fields(content)
+	if len(fields) == 0 {
+		return FileChecksum{}, errors.Errorf("checksum file '%s' is empty", path)
+	}
+	h, err := newHash(algorithm)
+	if err != nil {
+		return FileChecksum{}, err
+	}
+	digest, err := hex.DecodeString(string(fields[0]))
+	if err != nil || len(digest) != h.Size() {
+		return FileChecksum{}, errors.Errorf("checksum file '%s' contains invalid %s digest '%s'", path, algorithm, fields[0])
+	}
+	return FileChecksum{Algorithm: algorithm, Digest: hex.EncodeToString(digest)}, nil
+}
+
+// checksumReader computes the checksum of the source file while it is being read
+// sequentially, and returns an error once the whole file is read if the checksum
+// is mismatched. The verification is skipped if the file is not read from the start
+// to the end.
+type checksumReader struct {
+	storage.ReadSeekCloser
+	meta SourceFileMeta
+	hash hash.Hash
+	read int64
+}
+
+// NewChecksumReader wraps the reader of a source file to verify the checksum
+// in the file meta. The reader is returned as is if the checksum is unknown.
+func NewChecksumReader(reader storage.ReadSeekCloser, meta SourceFileMeta) (storage.ReadSeekCloser, error) {
+	if meta.Checksum.IsEmpty() {
+		return reader, nil
+	}
+	h, err := newHash(meta.Checksum.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &checksumReader{ReadSeekCloser: reader, meta: meta, hash: h}, nil
+}
+
+// Read implements io.Reader.
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.ReadSeekCloser.Read(p)
+	if r.hash == nil {
+		return n, err
+	}
+	_, _ = r.hash.Write(p[:n])
+	r.read += int64(n)
+	if r.read >= r.meta.FileSize || err == io.EOF {
+		if verr := r.verify(); verr != nil {
+			return n, verr
+		}
+	}
+	return n, err
+}
+
+// Seek implements io.Seeker.
+func (r *checksumReader) Seek(offset int64, whence int) (int64, error) {
+	pos, err := r.ReadSeekCloser.Seek(offset, whence)
+	if err != nil || r.hash == nil || pos == r.read {
+		return pos, err
+	}
+	if pos == 0 {
+		r.hash.Reset()
+		r.read = 0
+	} else {
+		// the file isn't read sequentially, so the checksum can't be verified.
+		r.hash = nil
+	}
+	return pos, nil
+}
+
+func (r *checksumReader) verify() error {
+	actual := hex.EncodeToString(r.hash.Sum(nil))
+	r.hash = nil
+	if actual != r.meta.Checksum.Digest {
+		return common.ErrSourceFileChecksumMismatch.GenWithStackByArgs(
+			r.meta.Path, r.meta.Checksum.Algorithm, r.meta.Checksum.Digest, actual)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/crc64"
+	"io"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/worker"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadChecksumSidecar(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemStorage()
+	digest := sha256.Sum256([]byte("1,2\n"))
+	hexDigest := hex.EncodeToString(digest[:])
+
+	require.NoError(t, store.WriteFile(ctx, "/a.csv.sha256", []byte(hexDigest+"  a.csv\n")))
+	checksum, err := readChecksumSidecar(ctx, store, "/a.csv.sha256", ChecksumSHA256)
+	require.NoError(t, err)
+	require.Equal(t, FileChecksum{Algorithm: ChecksumSHA256, Digest: hexDigest}, checksum)
+
+	require.NoError(t, store.WriteFile(ctx, "/b.csv.md5", []byte(hexDigest)))
+	_, err = readChecksumSidecar(ctx, store, "/b.csv.md5", ChecksumMD5)
+	require.Regexp(t, "contains invalid md5 digest", err)
+	require.NoError(t, store.WriteFile(ctx, "/c.csv.sha256", []byte("\n")))
+	_, err = readChecksumSidecar(ctx, store, "/c.csv.sha256", ChecksumSHA256)
+	require.Regexp(t, "is empty", err)
+
+	require.NoError(t, store.WriteFile(ctx, "/d.csv.crc64", []byte("d1d0ec9d4c2b0a0f d.csv\n")))
+	checksum, err = readChecksumSidecar(ctx, store, "/d.csv.crc64", ChecksumCRC64)
+	require.NoError(t, err)
+	require.Equal(t, FileChecksum{Algorithm: ChecksumCRC64, Digest: "d1d0ec9d4c2b0a0f"}, checksum)
+
+	dataPath, algorithm, ok := checksumSidecarOf("db.tbl.csv.SHA256")
+	require.True(t, ok)
+	require.Equal(t, "db.tbl.csv", dataPath)
+	require.Equal(t, ChecksumSHA256, algorithm)
+	_, _, ok = checksumSidecarOf("db.tbl.csv")
+	require.False(t, ok)
+}
+
+func TestChecksumReader(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemStorage()
+	content := []byte("1,2\n3,4\n5,6\n")
+	require.NoError(t, store.WriteFile(ctx, "/a.csv", content))
+	digest := sha256.Sum256(content)
+
+	meta := SourceFileMeta{
+		Path:     "/a.csv",
+		Type:     SourceTypeCSV,
+		FileSize: int64(len(content)),
+		Checksum: FileChecksum{Algorithm: ChecksumSHA256, Digest: hex.EncodeToString(digest[:])},
+	}
+	readAll := func(meta SourceFileMeta) ([][]string, error) {
+		reader, err := store.Open(ctx, meta.Path)
+		require.NoError(t, err)
+		reader, err = NewChecksumReader(reader, meta)
+		require.NoError(t, err)
+		cfg := config.CSVConfig{Separator: ",", Delimiter: `"`}
+		parser, err := NewCSVParser(ctx, &cfg, reader, 4, worker.NewPool(ctx, 1, "io"), false, nil)
+		require.NoError(t, err)
+		defer parser.Close()
+		require.NoError(t, parser.SetPos(0, 0))
+		var rows [][]string
+		for {
+			if err := parser.ReadRow(); err != nil {
+				if errors.Cause(err) == io.EOF {
+					return rows, nil
+				}
+				return rows, err
+			}
+			row := make([]string, 0, 2)
+			for _, d := range parser.LastRow().Row {
+				row = append(row, d.GetString())
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	rows, err := readAll(meta)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"1", "2"}, {"3", "4"}, {"5", "6"}}, rows)
+
+	crcHash := crc64.New(crc64.MakeTable(crc64.ECMA))
+	_, _ = crcHash.Write(content)
+	crcMeta := meta
+	crcMeta.Checksum = FileChecksum{Algorithm: ChecksumCRC64, Digest: hex.EncodeToString(crcHash.Sum(nil))}
+	rows, err = readAll(crcMeta)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	corrupted := meta
+	corrupted.Checksum.Digest = hex.EncodeToString(make([]byte, sha256.Size))
+	_, err = readAll(corrupted)
+	require.True(t, common.ErrSourceFileChecksumMismatch.Equal(err))
+
+	// the checksum is not verified if the file isn't read from the start.
+	reader, err := store.Open(ctx, meta.Path)
+	require.NoError(t, err)
+	reader, err = NewChecksumReader(reader, corrupted)
+	require.NoError(t, err)
+	_, err = reader.Seek(4, io.SeekStart)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, content[4:], data)
+}
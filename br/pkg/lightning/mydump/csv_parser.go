@@ -67,14 +67,45 @@ type CSVParser struct {
 	// fieldIndexes is an index of fields inside recordBuffer.
 	// The i'th field ends at offset fieldIndexes[i] in recordBuffer.
 	fieldIndexes []int
+	// quotedFields records whether the i'th field is quoted.
+	quotedFields []bool
+
+	// columnNulls is the NULL representation of the i'th field, or nil if the
+	// field follows the global setting. It is built once the columns are known.
+	columnNulls []*config.CSVColumnNull
 
 	lastRecord []string
 
 	escFlavor backslashEscapeFlavor
 	// if set to true, csv parser will treat the first non-empty line as header line
 	shouldParseHeader bool
+
+	// trackRecords is set when the malformed rows are rejected, so that the
+	// line number and the raw content of the last record are kept.
+	trackRecords bool
+	// lines is the number of '\n' consumed since the parser starts reading.
+	lines int64
+	// recordLine is the line number where the last record begins.
+	recordLine int64
+	// rawRecord is the raw content of the last record.
+	rawRecord []byte
+	// malformed records why the last ReadRow failed, if it's caused by a
+	// malformed record.
+	malformed malformedRecord
 }
 
+type malformedRecord uint8
+
+const (
+	malformedRecordNone malformedRecord = iota
+	// malformedRecordSyntax means the record has syntax errors, the rest of it
+	// is unread.
+	malformedRecordSyntax
+	// malformedRecordValue means some fields of the record can't be decoded,
+	// the record is read completely.
+	malformedRecordValue
+)
+
 // NewCSVParser creates a CSV parser.
 func NewCSVParser(
 	ctx context.Context,
@@ -136,6 +167,7 @@ func NewCSVParser(
 		unquoteByteSet:    makeByteSet(unquoteStopSet),
 		newLineByteSet:    makeByteSet(newLineStopSet),
 		shouldParseHeader: shouldParseHeader,
+		trackRecords:      len(cfg.RejectedRowsDir) > 0,
 	}, nil
 }
 
@@ -160,6 +192,49 @@ func encodeSpecialSymbols(cfg *config.CSVConfig, cc *CharsetConvertor) (separato
 	return
 }
 
+// unescapeColumnString is like unescapeString, but determines NULL by the
+// representation configured for the column.
+func (parser *CSVParser) unescapeColumnString(input string, quoted bool, columnNull *config.CSVColumnNull) (unescaped string, isNull bool, err error) {
+	if columnNull.UnquotedEmptyAsNull && len(input) == 0 {
+		return input, !quoted, nil
+	}
+	if len(columnNull.Null) == 0 {
+		return parser.unescapeString(input)
+	}
+	if input, err = parser.charsetConvertor.Decode(input); err != nil {
+		return
+	}
+	unescaped = unescape(input, "", parser.escFlavor)
+	for _, null := range columnNull.Null {
+		// compare with the raw input as well, so `\N` works when backslash-escape is on.
+		if input == null || unescaped == null {
+			return unescaped, true, nil
+		}
+	}
+	return unescaped, false, nil
+}
+
+// SetColumns sets the restored column names to the parser.
+func (parser *CSVParser) SetColumns(columns []string) {
+	parser.columns = columns
+	parser.buildColumnNulls()
+}
+
+func (parser *CSVParser) buildColumnNulls() {
+	parser.columnNulls = nil
+	if len(parser.cfg.ColumnNulls) == 0 || len(parser.columns) == 0 {
+		return
+	}
+	parser.columnNulls = make([]*config.CSVColumnNull, len(parser.columns))
+	for _, columnNull := range parser.cfg.ColumnNulls {
+		for i, col := range parser.columns {
+			if col == columnNull.Column {
+				parser.columnNulls[i] = columnNull
+			}
+		}
+	}
+}
+
 func (parser *CSVParser) unescapeString(input string) (unescaped string, isNull bool, err error) {
 	// Convert the input from another charset to utf8mb4 before we return the string.
 	if input, err = parser.charsetConvertor.Decode(input); err != nil {
@@ -204,6 +279,7 @@ func (parser *CSVParser) readByte() (byte, error) {
 		return 0, io.EOF
 	}
 	b := parser.buf[0]
+	parser.consume(parser.buf[:1])
 	parser.buf = parser.buf[1:]
 	parser.pos++
 	return b, nil
@@ -223,6 +299,7 @@ func (parser *CSVParser) peekBytes(cnt int) ([]byte, error) {
 }
 
 func (parser *CSVParser) skipBytes(n int) {
+	parser.consume(parser.buf[:n])
 	parser.buf = parser.buf[n:]
 	parser.pos += int64(n)
 }
@@ -321,12 +398,28 @@ func (parser *CSVParser) appendCSVTokenToRecordBuffer(token csvToken) {
 	parser.recordBuffer = append(parser.recordBuffer, byte(token))
 }
 
+// consume tracks the content of the record being read.
+func (parser *CSVParser) consume(content []byte) {
+	if !parser.trackRecords {
+		return
+	}
+	parser.lines += int64(bytes.Count(content, []byte{'\n'}))
+	parser.rawRecord = append(parser.rawRecord, content...)
+}
+
+// beginRecord starts tracking a new record.
+func (parser *CSVParser) beginRecord() {
+	parser.recordLine = parser.lines + 1
+	parser.rawRecord = parser.rawRecord[:0]
+}
+
 // readUntil reads the buffer until any character from the `chars` set is found.
 // that character is excluded from the final buffer.
 func (parser *CSVParser) readUntil(chars *byteSet) ([]byte, byte, error) {
 	index := IndexAnyByte(parser.buf, chars)
 	if index >= 0 {
 		ret := parser.buf[:index]
+		parser.consume(ret)
 		parser.buf = parser.buf[index:]
 		parser.pos += int64(index)
 		return ret, parser.buf[0], nil
@@ -341,6 +434,7 @@ func (parser *CSVParser) readUntil(chars *byteSet) ([]byte, byte, error) {
 			if err == nil {
 				err = io.EOF
 			}
+			parser.consume(buf)
 			parser.pos += int64(len(buf))
 			return buf, 0, errors.Trace(err)
 		}
@@ -348,6 +442,7 @@ func (parser *CSVParser) readUntil(chars *byteSet) ([]byte, byte, error) {
 		if index >= 0 {
 			buf = append(buf, parser.buf[:index]...)
 			parser.buf = parser.buf[index:]
+			parser.consume(buf)
 			parser.pos += int64(len(buf))
 			return buf, parser.buf[0], nil
 		}
@@ -357,11 +452,14 @@ func (parser *CSVParser) readUntil(chars *byteSet) ([]byte, byte, error) {
 func (parser *CSVParser) readRecord(dst []string) ([]string, error) {
 	parser.recordBuffer = parser.recordBuffer[:0]
 	parser.fieldIndexes = parser.fieldIndexes[:0]
+	parser.quotedFields = parser.quotedFields[:0]
 
 	isEmptyLine := true
 	whitespaceLine := true
+	quoted := false
 	prevToken := csvTokenNewLine
 	var firstToken csvToken
+	parser.beginRecord()
 
 outside:
 	for {
@@ -395,6 +493,8 @@ outside:
 		case csvTokenComma:
 			whitespaceLine = false
 			parser.fieldIndexes = append(parser.fieldIndexes, len(parser.recordBuffer))
+			parser.quotedFields = append(parser.quotedFields, quoted)
+			quoted = false
 		case csvTokenDelimiter:
 			if prevToken != csvTokenComma && prevToken != csvTokenNewLine {
 				parser.logSyntaxError()
@@ -404,18 +504,22 @@ outside:
 				return nil, err
 			}
 			whitespaceLine = false
+			quoted = true
 		case csvTokenNewLine:
 			// new line = end of record (ignore empty lines)
 			prevToken = firstToken
 			if isEmptyLine {
+				parser.beginRecord()
 				continue
 			}
 			// skip lines only contain whitespaces
 			if err == nil && whitespaceLine && len(bytes.TrimSpace(parser.recordBuffer)) == 0 {
 				parser.recordBuffer = parser.recordBuffer[:0]
+				parser.beginRecord()
 				continue
 			}
 			parser.fieldIndexes = append(parser.fieldIndexes, len(parser.recordBuffer))
+			parser.quotedFields = append(parser.quotedFields, quoted)
 			break outside
 		default:
 			if prevToken == csvTokenDelimiter {
@@ -496,6 +600,7 @@ func (parser *CSVParser) ReadRow() error {
 	row := &parser.lastRow
 	row.Length = 0
 	row.RowID++
+	parser.malformed = malformedRecordNone
 
 	// skip the header first
 	if parser.shouldParseHeader {
@@ -508,6 +613,10 @@ func (parser *CSVParser) ReadRow() error {
 
 	records, err := parser.readRecord(parser.lastRecord)
 	if err != nil {
+		switch errors.Cause(err) {
+		case errUnterminatedQuotedField, errDanglingBackslash, errUnexpectedQuoteField:
+			parser.malformed = malformedRecordSyntax
+		}
 		return errors.Trace(err)
 	}
 	parser.lastRecord = records
@@ -527,8 +636,17 @@ func (parser *CSVParser) ReadRow() error {
 	}
 	for i, record := range records {
 		row.Length += len(record)
-		unescaped, isNull, err := parser.unescapeString(record)
+		var (
+			unescaped string
+			isNull    bool
+		)
+		if i < len(parser.columnNulls) && parser.columnNulls[i] != nil {
+			unescaped, isNull, err = parser.unescapeColumnString(record, parser.quotedFields[i], parser.columnNulls[i])
+		} else {
+			unescaped, isNull, err = parser.unescapeString(record)
+		}
 		if err != nil {
+			parser.malformed = malformedRecordValue
 			return errors.Trace(err)
 		}
 		if isNull {
@@ -541,6 +659,40 @@ func (parser *CSVParser) ReadRow() error {
 	return nil
 }
 
+// SkipMalformedRecord skips the rest of the record which the last ReadRow
+// failed to read, so that the next ReadRow reads the record after it. It
+// returns false if the failure isn't caused by a malformed record, e.g. an I/O
+// error, where the record can't be skipped.
+func (parser *CSVParser) SkipMalformedRecord() (bool, error) {
+	switch parser.malformed {
+	case malformedRecordValue:
+		return true, nil
+	case malformedRecordSyntax:
+		// a syntax error may stop reading at anywhere, skip to the next line.
+		if _, err := parser.ReadUntilTerminator(); err != nil && errors.Cause(err) != io.EOF {
+			return false, err
+		}
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// LastRecord returns the line number and the raw content of the last record,
+// or the one the last ReadRow failed to read, without the trailing terminator.
+// They're only tracked when `mydumper.csv.rejected-rows-dir` is set. The line
+// number is counted from where the parser starts reading, so it's relative to
+// the chunk if the chunk doesn't start from the beginning of the file.
+func (parser *CSVParser) LastRecord() (line int64, raw []byte) {
+	raw = parser.rawRecord
+	if len(parser.newLine) > 0 {
+		raw = bytes.TrimSuffix(raw, parser.newLine)
+	} else {
+		raw = bytes.TrimRight(raw, "\r\n")
+	}
+	return parser.recordLine, raw
+}
+
 // ReadColumns reads the columns of this CSV file.
 func (parser *CSVParser) ReadColumns() error {
 	columns, err := parser.readRecord(nil)
@@ -555,6 +707,7 @@ func (parser *CSVParser) ReadColumns() error {
 		}
 		parser.columns = append(parser.columns, strings.ToLower(colName))
 	}
+	parser.buildColumnNulls()
 	return nil
 }
 
@@ -39,7 +39,7 @@ type testCase struct {
 
 func runTestCasesCSV(t *testing.T, cfg *config.MydumperRuntime, blockBufSize int64, cases []testCase) {
 	for _, tc := range cases {
-		charsetConvertor, err := mydump.NewCharsetConvertor(cfg.DataCharacterSet, cfg.DataInvalidCharReplace)
+		charsetConvertor, err := mydump.NewCharsetConvertor(cfg.DataCharacterSet, cfg.DataInvalidCharReplace, cfg.DataInvalidCharAction)
 		assert.NoError(t, err)
 		parser, err := mydump.NewCSVParser(context.Background(), &cfg.CSV, mydump.NewStringReader(tc.input), blockBufSize, ioWorkers, false, charsetConvertor)
 		assert.NoError(t, err)
@@ -56,7 +56,7 @@ func runTestCasesCSV(t *testing.T, cfg *config.MydumperRuntime, blockBufSize int
 
 func runFailingTestCasesCSV(t *testing.T, cfg *config.MydumperRuntime, blockBufSize int64, cases []string) {
 	for _, tc := range cases {
-		charsetConvertor, err := mydump.NewCharsetConvertor(cfg.DataCharacterSet, cfg.DataInvalidCharReplace)
+		charsetConvertor, err := mydump.NewCharsetConvertor(cfg.DataCharacterSet, cfg.DataInvalidCharReplace, cfg.DataInvalidCharAction)
 		assert.NoError(t, err)
 		parser, err := mydump.NewCSVParser(context.Background(), &cfg.CSV, mydump.NewStringReader(tc), blockBufSize, ioWorkers, false, charsetConvertor)
 		require.NoError(t, err)
@@ -422,6 +422,59 @@ func TestMySQL(t *testing.T) {
 	require.ErrorIs(t, errors.Cause(parser.ReadRow()), io.EOF)
 }
 
+func TestColumnNulls(t *testing.T) {
+	cfg := config.CSVConfig{
+		Separator:       ",",
+		Delimiter:       `"`,
+		BackslashEscape: true,
+		Null:            `\N`,
+		ColumnNulls: []*config.CSVColumnNull{
+			{Column: "a", UnquotedEmptyAsNull: true},
+			{Column: "b", Null: []string{"", "NULL"}},
+			{Column: "c", Null: []string{"-"}, UnquotedEmptyAsNull: true},
+		},
+	}
+
+	parser, err := mydump.NewCSVParser(context.Background(), &cfg, mydump.NewStringReader(`a,b,c,d
+,"",,\N
+"",NULL,"-",
+\N,x,"",""
+`), int64(config.ReadBlockSize), ioWorkers, true, nil)
+	require.NoError(t, err)
+
+	require.Nil(t, parser.ReadRow())
+	require.Equal(t, []string{"a", "b", "c", "d"}, parser.Columns())
+	requireNullableStrings(t, parser.LastRow().Row, nil, nil, nil, nil)
+
+	require.Nil(t, parser.ReadRow())
+	requireNullableStrings(t, parser.LastRow().Row, "", nil, nil, "")
+
+	require.Nil(t, parser.ReadRow())
+	requireNullableStrings(t, parser.LastRow().Row, nil, "x", "", "")
+
+	require.ErrorIs(t, errors.Cause(parser.ReadRow()), io.EOF)
+
+	// the per-column settings follow the restored columns.
+	parser, err = mydump.NewCSVParser(context.Background(), &cfg, mydump.NewStringReader(`"",,NULL`), int64(config.ReadBlockSize), ioWorkers, false, nil)
+	require.NoError(t, err)
+	parser.SetColumns([]string{"d", "a", "b"})
+	require.Nil(t, parser.ReadRow())
+	requireNullableStrings(t, parser.LastRow().Row, "", nil, nil)
+}
+
+// requireNullableStrings checks the row against the expected strings, where nil stands for NULL.
+func requireNullableStrings(t *testing.T, row []types.Datum, expected ...interface{}) {
+	require.Len(t, row, len(expected))
+	for i, e := range expected {
+		if e == nil {
+			require.True(t, row[i].IsNull(), "column %d", i)
+		} else {
+			require.False(t, row[i].IsNull(), "column %d", i)
+			require.Equal(t, e, row[i].GetString(), "column %d", i)
+		}
+	}
+}
+
 func TestSyntaxErrorCSV(t *testing.T) {
 	cfg := config.MydumperRuntime{
 		CSV: config.CSVConfig{
@@ -449,6 +502,54 @@ func TestSyntaxErrorCSV(t *testing.T) {
 	runFailingTestCasesCSV(t, &cfg, int64(config.ReadBlockSize), []string{`"\`})
 }
 
+func TestSkipMalformedRecord(t *testing.T) {
+	cfg := config.CSVConfig{
+		Separator:       ",",
+		Delimiter:       `"`,
+		BackslashEscape: true,
+		Header:          true,
+		RejectedRowsDir: "rejected",
+	}
+	input := "a,b\n1,2\n3,\"4\"5,6\n\n  \n\"7\n8\",9\n10,\"11"
+	parser, err := mydump.NewCSVParser(context.Background(), &cfg, mydump.NewStringReader(input), 8, ioWorkers, true, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, parser.ReadRow())
+	line, raw := parser.LastRecord()
+	require.Equal(t, int64(2), line)
+	require.Equal(t, "1,2", string(raw))
+
+	// the rest of the line is skipped after a syntax error.
+	require.ErrorContains(t, parser.ReadRow(), "syntax error: cannot have consecutive fields without separator")
+	skipped, err := parser.SkipMalformedRecord()
+	require.NoError(t, err)
+	require.True(t, skipped)
+	line, raw = parser.LastRecord()
+	require.Equal(t, int64(3), line)
+	require.Equal(t, `3,"4"5,6`, string(raw))
+
+	// the empty lines are not a part of the record.
+	require.NoError(t, parser.ReadRow())
+	line, raw = parser.LastRecord()
+	require.Equal(t, int64(6), line)
+	require.Equal(t, "\"7\n8\",9", string(raw))
+	require.Equal(t, []types.Datum{types.NewStringDatum("7\n8"), types.NewStringDatum("9")}, parser.LastRow().Row)
+
+	require.ErrorContains(t, parser.ReadRow(), "syntax error: unterminated quoted field")
+	skipped, err = parser.SkipMalformedRecord()
+	require.NoError(t, err)
+	require.True(t, skipped)
+	line, raw = parser.LastRecord()
+	require.Equal(t, int64(8), line)
+	require.Equal(t, `10,"11`, string(raw))
+	require.Equal(t, io.EOF, errors.Cause(parser.ReadRow()))
+
+	// the record can't be skipped if reading it doesn't fail.
+	skipped, err = parser.SkipMalformedRecord()
+	require.NoError(t, err)
+	require.False(t, skipped)
+}
+
 func TestTSV(t *testing.T) {
 	cfg := config.CSVConfig{
 		Separator:       "\t",
@@ -920,7 +1021,7 @@ func TestCharsetConversion(t *testing.T) {
 		DataCharacterSet:       "gb18030",
 		DataInvalidCharReplace: string(utf8.RuneError),
 	}
-	charsetConvertor, err := mydump.NewCharsetConvertor(cfg.DataCharacterSet, cfg.DataInvalidCharReplace)
+	charsetConvertor, err := mydump.NewCharsetConvertor(cfg.DataCharacterSet, cfg.DataInvalidCharReplace, cfg.DataInvalidCharAction)
 	require.NoError(t, err)
 	originalInputPart1 := `不要温驯地走进那个良夜，老年应当在日暮时燃烧咆哮，怒斥，怒斥光明的消逝。
 `
@@ -0,0 +1,296 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/lightning/metric"
+	"github.com/pingcap/tidb/br/pkg/lightning/worker"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/slice"
+	"go.uber.org/zap"
+)
+
+// JSONParser is a parser of JSON Lines (a.k.a. NDJSON) data files, in which
+// every non-blank line is a JSON object mapping column names to values.
+//
+// The columns are set by SetColumns, usually to the columns of the target table.
+// Otherwise they are taken from the keys of the first object, and the keys only
+// appearing in the later objects are ignored with a warning in loose mode. Every
+// row has the same layout no matter how the keys are ordered in the objects.
+type JSONParser struct {
+	blockParser
+	cfg *config.JSONConfig
+
+	// columnIndex maps the lower-case column names to their offsets in a row.
+	columnIndex map[string]int
+	// filled records which columns of the current row have got a value.
+	filled []bool
+	// missing is the buffer of Row.Missing.
+	missing []bool
+	// inferredColumns is true if the columns are taken from the first object.
+	inferredColumns bool
+	// ignoredKeys records the keys which have been warned to be ignored.
+	ignoredKeys map[string]struct{}
+}
+
+// NewJSONParser creates a JSON Lines parser.
+func NewJSONParser(
+	ctx context.Context,
+	cfg *config.JSONConfig,
+	reader ReadSeekCloser,
+	blockBufSize int64,
+	ioWorkers *worker.Pool,
+) *JSONParser {
+	metrics, _ := metric.FromContext(ctx)
+	return &JSONParser{
+		blockParser: makeBlockParser(reader, blockBufSize, ioWorkers, metrics, log.FromContext(ctx)),
+		cfg:         cfg,
+	}
+}
+
+// SetColumns sets the restored column names to the parser. The keys are matched
+// with them case-insensitively.
+func (parser *JSONParser) SetColumns(columns []string) {
+	parser.columns = columns
+	parser.columnIndex = nil
+	parser.inferredColumns = false
+}
+
+// readLine reads until the next '\n' and consumes it. The returned line doesn't
+// contain the '\n'. io.EOF is returned together with the last line if the file
+// isn't terminated by a '\n'.
+func (parser *JSONParser) readLine() ([]byte, error) {
+	var line []byte
+	for {
+		if index := bytes.IndexByte(parser.buf, '\n'); index >= 0 {
+			if line == nil {
+				line = parser.buf[:index]
+			} else {
+				line = append(line, parser.buf[:index]...)
+			}
+			parser.buf = parser.buf[index+1:]
+			parser.pos += int64(index + 1)
+			return line, nil
+		}
+		line = append(line, parser.buf...)
+		parser.pos += int64(len(parser.buf))
+		parser.buf = nil
+		if err := parser.readBlock(); err != nil {
+			return line, errors.Trace(err)
+		}
+		if len(parser.buf) == 0 {
+			return line, io.EOF
+		}
+	}
+}
+
+// ReadRow reads a row from the datafile.
+func (parser *JSONParser) ReadRow() error {
+	var line []byte
+	for {
+		content, err := parser.readLine()
+		if len(bytes.TrimSpace(content)) > 0 {
+			line = content
+			break
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	row := &parser.lastRow
+	row.RowID++
+	row.Length = len(line)
+	row.Row = parser.acquireDatumSlice()
+	if err := parser.parseObject(line, row); err != nil {
+		parser.logSyntaxError()
+		return errors.Annotatef(err, "syntax error at offset %d", parser.pos)
+	}
+	return nil
+}
+
+func (parser *JSONParser) parseObject(line []byte, row *Row) error {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+	if tok, err := decoder.Token(); err != nil {
+		return errors.Trace(err)
+	} else if tok != json.Delim('{') {
+		return errors.Errorf("expecting a JSON object, got %v", tok)
+	}
+
+	initColumns := parser.columns == nil
+	if !initColumns && parser.columnIndex == nil {
+		parser.columnIndex = make(map[string]int, len(parser.columns))
+		for i, col := range parser.columns {
+			parser.columnIndex[strings.ToLower(col)] = i
+		}
+	}
+	if initColumns {
+		parser.columnIndex = make(map[string]int)
+		parser.inferredColumns = true
+	}
+
+	// values are appended in the order of the keys, and moved to the column
+	// offsets afterwards because the number of columns may be unknown yet.
+	var (
+		offsets []int
+		values  []types.Datum
+	)
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		key := strings.ToLower(tok.(string))
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return errors.Trace(err)
+		}
+
+		offset, ok := parser.columnIndex[key]
+		switch {
+		case ok:
+			if slice.AnyOf(offsets, func(i int) bool { return offsets[i] == offset }) {
+				return errors.Errorf("duplicated key '%s'", key)
+			}
+		case initColumns:
+			offset = len(parser.columns)
+			parser.columns = append(parser.columns, key)
+			parser.columnIndex[key] = offset
+		case parser.cfg.StrictColumns:
+			return errors.Errorf("unknown key '%s', it is not found in the columns %v", key, parser.columns)
+		default:
+			// ignore the keys which don't match any column in loose mode.
+			if parser.inferredColumns {
+				parser.warnIgnoredKey(key)
+			}
+			continue
+		}
+
+		value, err := parseJSONValue(raw)
+		if err != nil {
+			return errors.Annotatef(err, "invalid value of key '%s'", key)
+		}
+		offsets = append(offsets, offset)
+		values = append(values, value)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return errors.New("unexpected content after the JSON object")
+	}
+
+	columnCount := len(parser.columns)
+	if cap(row.Row) >= columnCount {
+		row.Row = row.Row[:columnCount]
+	} else {
+		row.Row = make([]types.Datum, columnCount)
+	}
+	if cap(parser.filled) >= columnCount {
+		parser.filled = parser.filled[:columnCount]
+	} else {
+		parser.filled = make([]bool, columnCount)
+	}
+	for i := range parser.filled {
+		parser.filled[i] = false
+	}
+	for i, offset := range offsets {
+		row.Row[offset] = values[i]
+		parser.filled[offset] = true
+	}
+	row.Missing = nil
+	for i, filled := range parser.filled {
+		if filled {
+			continue
+		}
+		if parser.cfg.StrictColumns {
+			return errors.Errorf("missing key '%s'", parser.columns[i])
+		}
+		// the missing keys take the default values of the columns, like the columns
+		// absent in the header of a CSV file.
+		if row.Missing == nil {
+			if cap(parser.missing) >= columnCount {
+				parser.missing = parser.missing[:columnCount]
+				for j := range parser.missing {
+					parser.missing[j] = false
+				}
+			} else {
+				parser.missing = make([]bool, columnCount)
+			}
+			row.Missing = parser.missing
+		}
+		row.Missing[i] = true
+		row.Row[i].SetNull()
+	}
+	return nil
+}
+
+// warnIgnoredKey warns once for each key which is ignored because it's absent in
+// the first object, from which the columns are taken.
+func (parser *JSONParser) warnIgnoredKey(key string) {
+	if _, ok := parser.ignoredKeys[key]; ok {
+		return
+	}
+	if parser.ignoredKeys == nil {
+		parser.ignoredKeys = make(map[string]struct{})
+	}
+	parser.ignoredKeys[key] = struct{}{}
+	parser.Logger.Warn("the key is absent in the first JSON object which the columns are taken from, its values are ignored",
+		zap.String("key", key), zap.Int64("pos", parser.pos), zap.Strings("columns", parser.columns))
+}
+
+// parseJSONValue converts a JSON value to a datum. Numbers keep their literal
+// text unless they fit into a 64-bit integer, and nested objects and arrays are
+// kept as JSON text so that they can be imported into JSON columns.
+func parseJSONValue(raw json.RawMessage) (types.Datum, error) {
+	var value types.Datum
+	switch raw[0] {
+	case 'n':
+		value.SetNull()
+	case 't':
+		value.SetInt64(1)
+	case 'f':
+		value.SetInt64(0)
+	case '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return value, errors.Trace(err)
+		}
+		value.SetString(s, "utf8mb4_bin")
+	case '{', '[':
+		value.SetString(string(raw), "utf8mb4_bin")
+	default:
+		literal := string(raw)
+		if i, err := strconv.ParseInt(literal, 10, 64); err == nil {
+			value.SetInt64(i)
+		} else if u, err := strconv.ParseUint(literal, 10, 64); err == nil {
+			value.SetUint64(u)
+		} else {
+			value.SetString(literal, "utf8mb4_bin")
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONParserLoose(t *testing.T) {
+	input := `{"id": 1, "Name": "a\"b", "score": 1.50, "tags": ["x", "y"]}
+
+{"name": "c", "id": 18446744073709551615, "extra": true}
+{"id": -2, "name": null, "score": 3, "tags": {"k": false}}`
+
+	parser := mydump.NewJSONParser(context.Background(), &config.JSONConfig{}, mydump.NewStringReader(input), 7, ioWorkers)
+	logger, buffer := log.MakeTestLogger()
+	parser.SetLogger(logger)
+
+	require.NoError(t, parser.ReadRow())
+	require.Equal(t, []string{"id", "name", "score", "tags"}, parser.Columns())
+	require.Equal(t, mydump.Row{
+		RowID: 1,
+		Row: []types.Datum{
+			types.NewIntDatum(1),
+			types.NewStringDatum(`a"b`),
+			types.NewStringDatum("1.50"),
+			types.NewStringDatum(`["x", "y"]`),
+		},
+		Length: 60,
+	}, parser.LastRow())
+	assertPosEqual(t, parser, 61, 1)
+
+	// the unknown key is ignored and the missing keys take the default values.
+	require.NoError(t, parser.ReadRow())
+	require.Equal(t, mydump.Row{
+		RowID: 2,
+		Row: []types.Datum{
+			types.NewUintDatum(18446744073709551615),
+			types.NewStringDatum("c"),
+			nullDatum,
+			nullDatum,
+		},
+		Length:  56,
+		Missing: []bool{false, false, true, true},
+	}, parser.LastRow())
+	assertPosEqual(t, parser, 119, 2)
+	require.NoError(t, logger.Sync())
+	require.Equal(t,
+		`{"$lvl":"WARN","$msg":"the key is absent in the first JSON object which the columns are taken from, its values are ignored","key":"extra","pos":119,"columns":["id","name","score","tags"]}`,
+		buffer.Stripped())
+
+	require.NoError(t, parser.ReadRow())
+	require.Equal(t, []types.Datum{
+		types.NewIntDatum(-2),
+		nullDatum,
+		types.NewIntDatum(3),
+		types.NewStringDatum(`{"k": false}`),
+	}, parser.LastRow().Row)
+	require.Nil(t, parser.LastRow().Missing)
+	assertPosEqual(t, parser, int64(len(input)), 3)
+
+	require.ErrorIs(t, errors.Cause(parser.ReadRow()), io.EOF)
+}
+
+func TestJSONParserSetColumns(t *testing.T) {
+	input := `{"b": 2, "a": 1}` + "\n" + `{"a": 3, "b": 4}` + "\n"
+	parser := mydump.NewJSONParser(context.Background(), &config.JSONConfig{StrictColumns: true}, mydump.NewStringReader(input), 64, ioWorkers)
+	// the keys are matched with the columns case-insensitively.
+	parser.SetColumns([]string{"A", "b"})
+
+	require.NoError(t, parser.SetPos(17, 1))
+	require.NoError(t, parser.ReadRow())
+	require.Equal(t, []types.Datum{types.NewIntDatum(3), types.NewIntDatum(4)}, parser.LastRow().Row)
+	assertPosEqual(t, parser, 34, 2)
+	require.ErrorIs(t, errors.Cause(parser.ReadRow()), io.EOF)
+}
+
+func TestJSONParserStrict(t *testing.T) {
+	cfg := &config.JSONConfig{StrictColumns: true}
+	failingCases := []string{
+		`{"a": 1}` + "\n" + `{"a": 1, "b": 2}`,
+		`{"a": 1, "b": 2}` + "\n" + `{"a": 1}`,
+		`{"a": 1, "A": 2}`,
+		`[1, 2]`,
+		`{"a": 1} {"a": 2}`,
+		`{"a": 1`,
+	}
+	for _, tc := range failingCases {
+		parser := mydump.NewJSONParser(context.Background(), cfg, mydump.NewStringReader(tc), 64, ioWorkers)
+		var err error
+		for err == nil {
+			err = parser.ReadRow()
+		}
+		require.Regexp(t, "syntax error.*", err.Error(), "input = %q", tc)
+	}
+}
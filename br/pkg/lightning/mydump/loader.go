@@ -16,18 +16,23 @@ package mydump
 
 import (
 	"context"
+	"encoding/base64"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/br/pkg/lightning/common"
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/lightning/worker"
 	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/util/mathutil"
 	regexprrouter "github.com/pingcap/tidb/util/regexpr-router"
 	filter "github.com/pingcap/tidb/util/table-filter"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
 // MDDatabaseMeta contains some parsed metadata for a database in the source by MyDumper Loader.
@@ -35,7 +40,12 @@ type MDDatabaseMeta struct {
 	Name       string
 	SchemaFile FileInfo
 	Tables     []*MDTableMeta
-	Views      []*MDTableMeta
+	// Views are sorted so each view is placed after the views it references.
+	Views     []*MDTableMeta
+	Sequences []*MDTableMeta
+	// Procedures are the stored procedures, functions and events.
+	Procedures []*MDTableMeta
+	Triggers   []*MDTableMeta
 	charSet    string
 }
 
@@ -73,6 +83,11 @@ type MDTableMeta struct {
 	TotalSize    int64
 	IndexRatio   float64
 	IsRowOrdered bool
+	// inferSchemaCfg is set if the schema of the table can be inferred from its CSV files
+	// when the schema file is missing.
+	inferSchemaCfg *config.MydumperRuntime
+	// viewDeps are the lower-case names of the tables and views referenced by the view.
+	viewDeps []filter.Table
 }
 
 // SourceFileMeta contains some analyzed metadata for a source file by MyDumper Loader.
@@ -82,6 +97,18 @@ type SourceFileMeta struct {
 	Compression Compression
 	SortKey     string
 	FileSize    int64
+	// Checksum is discovered from the sidecar file if `mydumper.verify-checksum` is enabled.
+	Checksum FileChecksum
+	// CharacterSet is the character set of the schema file set by the file routing rule,
+	// empty if it's `mydumper.character-set`.
+	CharacterSet string
+	// Columns are the column names of the headerless CSV file set by the file routing rule,
+	// empty if the fields are in the order of the table columns.
+	Columns []string
+	// SkipLines and SkipFooterLines are the numbers of the leading and the trailing lines of
+	// the CSV file skipped by the file routing rule.
+	SkipLines       int
+	SkipFooterLines int
 }
 
 // NewMDTableMeta creates an Mydumper table meta with specified character set.
@@ -91,10 +118,19 @@ func NewMDTableMeta(charSet string) *MDTableMeta {
 	}
 }
 
+// HasSchema returns whether the table-creating SQL of the table is available, either
+// from its schema file or inferred from its data files.
+func (m *MDTableMeta) HasSchema() bool {
+	return len(m.SchemaFile.FileMeta.Path) > 0 || m.inferSchemaFiles() != nil
+}
+
 // GetSchema gets the table-creating SQL for a source table.
 func (m *MDTableMeta) GetSchema(ctx context.Context, store storage.ExternalStorage) (string, error) {
 	schemaFilePath := m.SchemaFile.FileMeta.Path
 	if len(schemaFilePath) <= 0 {
+		if files := m.inferSchemaFiles(); files != nil {
+			return m.inferSchema(ctx, store, files)
+		}
 		return "", errors.Errorf("schema file is missing for the table '%s.%s'", m.DB, m.Name)
 	}
 	fileExists, err := store.FileExists(ctx, schemaFilePath)
@@ -115,12 +151,22 @@ func (m *MDTableMeta) GetSchema(ctx context.Context, store storage.ExternalStora
 	return string(schema), nil
 }
 
+// GetRoutineStatements gets the statements in the schema file of the stored routines or
+// triggers.
+func (m *MDTableMeta) GetRoutineStatements(ctx context.Context, store storage.ExternalStorage) ([]string, error) {
+	return ExportRoutineStatements(ctx, store, m.SchemaFile, m.charSet)
+}
+
 // MDLoaderSetupConfig stores the configs when setting up a MDLoader.
 // This can control the behavior when constructing an MDLoader.
 type MDLoaderSetupConfig struct {
 	// MaxScanFiles specifies the maximum number of files to scan.
 	// If the value is <= 0, it means the number of data source files will be scanned as many as possible.
 	MaxScanFiles int
+	// ScanContinuationToken is the token returned by `MDLoader.ContinuationToken` of a previous loader
+	// stopped by MaxScanFiles. If it is not empty, the scanning starts right after the last file walked
+	// by that loader, so that a gigantic data source can be imported in several batches.
+	ScanContinuationToken string
 }
 
 // DefaultMDLoaderSetupConfig generates a default MDLoaderSetupConfig.
@@ -140,6 +186,26 @@ func WithMaxScanFiles(maxScanFiles int) MDLoaderSetupOption {
 	}
 }
 
+// WithScanContinuationToken generates an option that resumes the scanning from the position recorded
+// in the token when setting up a MDLoader.
+func WithScanContinuationToken(token string) MDLoaderSetupOption {
+	return func(cfg *MDLoaderSetupConfig) {
+		cfg.ScanContinuationToken = token
+	}
+}
+
+func encodeScanContinuationToken(lastPath string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastPath))
+}
+
+func decodeScanContinuationToken(token string) (string, error) {
+	lastPath, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || (len(token) > 0 && len(lastPath) == 0) {
+		return "", common.ErrInvalidConfig.GenWithStack("invalid scan continuation token '%s'", token)
+	}
+	return string(lastPath), nil
+}
+
 // MDLoader is for 'Mydumper File Loader', which loads the files in the data source and generates a set of metadata.
 type MDLoader struct {
 	store  storage.ExternalStorage
@@ -149,17 +215,41 @@ type MDLoader struct {
 	router     *regexprrouter.RouteTable
 	fileRouter FileRouter
 	charSet    string
+	// inferSchemaCfg is not nil if the schemas of the tables are allowed to be inferred.
+	inferSchemaCfg *config.MydumperRuntime
+	// continuationToken is not empty if the scanning is stopped by MaxScanFiles.
+	continuationToken string
+	verifyChecksum    bool
+	// checksumAlgorithm restricts the checksum sidecar files to the ones of the algorithm if
+	// it's not empty.
+	checksumAlgorithm string
+	// routeOverrides are the routes given by the command line, which take precedence over router.
+	routeOverrides *regexprrouter.RouteTable
+	// sourceMeta is nil if there is no metadata file in the data source.
+	sourceMeta *MDSourceMeta
+	// preSplitCfg is not nil if the large data files are split while scanning the data source.
+	preSplitCfg *config.Config
+	// sourceInclude is nil if all the files in the data source are scanned.
+	sourceInclude *sourceInclude
+	// views are the views of all the databases sorted by their dependencies.
+	views []*MDTableMeta
 }
 
 type mdLoaderSetup struct {
-	loader        *MDLoader
-	dbSchemas     []FileInfo
-	tableSchemas  []FileInfo
-	viewSchemas   []FileInfo
+	loader       *MDLoader
+	dbSchemas    []FileInfo
+	tableSchemas []FileInfo
+	viewSchemas  []FileInfo
+	// objectSchemas are the schema files of sequences, procedures and triggers.
+	objectSchemas []FileInfo
 	tableDatas    []FileInfo
 	dbIndexMap    map[string]int
 	tableIndexMap map[filter.Table]int
 	setupCfg      *MDLoaderSetupConfig
+	// resumeAfter is the path of the last file walked by the previous loader.
+	resumeAfter string
+	// checksumSidecars maps the data file paths to the paths of their checksum sidecar files.
+	checksumSidecars map[string]string
 }
 
 // NewMyDumpLoader constructs a MyDumper loader that scanns the data source and constructs a set of metadatas.
@@ -196,6 +286,13 @@ func NewMyDumpLoaderWithStore(ctx context.Context, cfg *config.Config, store sto
 			return nil, common.ErrInvalidConfig.Wrap(err).GenWithStack("invalid table route rule")
 		}
 	}
+	var routeOverrides *regexprrouter.RouteTable
+	if len(cfg.RouteOverrides) > 0 {
+		routeOverrides, err = regexprrouter.NewRegExprRouter(cfg.Mydumper.CaseSensitive, cfg.RouteOverrides)
+		if err != nil {
+			return nil, common.ErrInvalidConfig.Wrap(err).GenWithStack("invalid table route given by the command line")
+		}
+	}
 
 	// use the legacy black-white-list if defined. otherwise use the new filter.
 	var f filter.Filter
@@ -221,12 +318,33 @@ func NewMyDumpLoaderWithStore(ctx context.Context, cfg *config.Config, store sto
 		return nil, common.ErrInvalidConfig.Wrap(err).GenWithStack("parse file routing rule failed")
 	}
 
+	sourceInclude, err := parseSourceInclude(cfg.Mydumper.SourceInclude)
+	if err != nil {
+		return nil, err
+	}
+
 	mdl := &MDLoader{
 		store:      store,
 		filter:     f,
 		router:     r,
 		charSet:    cfg.Mydumper.CharacterSet,
 		fileRouter: fileRouter,
+
+		verifyChecksum:    cfg.Mydumper.VerifyChecksum,
+		checksumAlgorithm: cfg.Mydumper.ChecksumAlgorithm,
+		routeOverrides:    routeOverrides,
+		sourceInclude:     sourceInclude,
+	}
+	if cfg.Mydumper.CSV.InferSchema {
+		mdl.inferSchemaCfg = &cfg.Mydumper
+	}
+	if cfg.Mydumper.PreSplit {
+		mdl.preSplitCfg = cfg
+	}
+
+	resumeAfter, err := decodeScanContinuationToken(mdLoaderSetupCfg.ScanContinuationToken)
+	if err != nil {
+		return nil, err
 	}
 
 	setup := mdLoaderSetup{
@@ -234,6 +352,9 @@ func NewMyDumpLoaderWithStore(ctx context.Context, cfg *config.Config, store sto
 		dbIndexMap:    make(map[string]int),
 		tableIndexMap: make(map[filter.Table]int),
 		setupCfg:      mdLoaderSetupCfg,
+		resumeAfter:   resumeAfter,
+
+		checksumSidecars: make(map[string]string),
 	}
 
 	if err := setup.setup(ctx, mdl.store); err != nil {
@@ -272,6 +393,17 @@ func (ftype fileType) String() string {
 type FileInfo struct {
 	TableName filter.Table
 	FileMeta  SourceFileMeta
+	// Regions are the regions of the data file if it's split while scanning
+	// the data source, or nil otherwise.
+	Regions []FileRegion
+}
+
+// FileRegion is an offset range of a data file which contains complete rows.
+type FileRegion struct {
+	Offset    int64
+	EndOffset int64
+	// Columns are the columns in the header of the CSV file, or nil if there is no header.
+	Columns []string
 }
 
 // setup the `s.loader.dbs` slice by scanning all *.sql files inside `dir`.
@@ -295,22 +427,38 @@ func (s *mdLoaderSetup) setup(ctx context.Context, store storage.ExternalStorage
 			table —— {db}.{table}-schema.sql
 			sql   —— {db}.{table}.{part}.sql / {db}.{table}.sql
 	*/
+	sourceMeta, err := readSourceMeta(ctx, store)
+	if err != nil {
+		// the metadata file is optional, so don't fail the import if it's broken.
+		log.FromContext(ctx).Warn("[loader] failed to read the metadata file of the data source", zap.Error(err))
+	}
+	s.loader.sourceMeta = sourceMeta
+
 	var gerr error
 	if err := s.listFiles(ctx, store); err != nil {
-		if errors.ErrorEqual(err, common.ErrTooManySourceFiles) {
+		switch {
+		case errors.ErrorEqual(err, common.ErrTooManySourceFiles):
 			gerr = err
-		} else {
+		case errors.ErrorEqual(err, common.ErrInvalidConfig):
+			return err
+		default:
 			return common.ErrStorageUnknown.Wrap(err).GenWithStack("list file failed")
 		}
 	}
+	if err := s.discoverChecksums(ctx, store); err != nil {
+		return errors.Trace(err)
+	}
 	if err := s.route(); err != nil {
 		return common.ErrTableRoute.Wrap(err).GenWithStackByArgs()
 	}
+	if err := s.preSplitLargeFiles(ctx, store); err != nil {
+		return errors.Trace(err)
+	}
 
 	// setup database schema
 	if len(s.dbSchemas) != 0 {
 		for _, fileInfo := range s.dbSchemas {
-			if _, dbExists := s.insertDB(fileInfo); dbExists && s.loader.router == nil {
+			if _, dbExists := s.insertDB(fileInfo); dbExists && !s.loader.hasRoutes() {
 				return common.ErrInvalidSchemaFile.GenWithStack("invalid database schema file, duplicated item - %s", fileInfo.FileMeta.Path)
 			}
 		}
@@ -319,7 +467,7 @@ func (s *mdLoaderSetup) setup(ctx context.Context, store storage.ExternalStorage
 	if len(s.tableSchemas) != 0 {
 		// setup table schema
 		for _, fileInfo := range s.tableSchemas {
-			if _, _, tableExists := s.insertTable(fileInfo); tableExists && s.loader.router == nil {
+			if _, _, tableExists := s.insertTable(fileInfo); tableExists && !s.loader.hasRoutes() {
 				return common.ErrInvalidSchemaFile.GenWithStack("invalid table schema file, duplicated item - %s", fileInfo.FileMeta.Path)
 			}
 		}
@@ -328,15 +476,19 @@ func (s *mdLoaderSetup) setup(ctx context.Context, store storage.ExternalStorage
 	if len(s.viewSchemas) != 0 {
 		// setup view schema
 		for _, fileInfo := range s.viewSchemas {
-			_, tableExists := s.insertView(fileInfo)
-			if !tableExists {
-				// we are not expect the user only has view schema without table schema when user use dumpling to get view.
-				// remove the last `-view.sql` from path as the relate table schema file path
-				return common.ErrInvalidSchemaFile.GenWithStack("invalid view schema file, miss host table schema for view '%s'", fileInfo.TableName.Name)
-			}
+			s.insertView(fileInfo)
+		}
+		// views may reference the views in other databases, so sort all of them together.
+		if err := s.sortViews(ctx, store); err != nil {
+			return errors.Trace(err)
 		}
 	}
 
+	// setup sequence, procedure and trigger schema
+	for _, fileInfo := range s.objectSchemas {
+		s.insertSchemaObject(fileInfo)
+	}
+
 	// Sql file for restore data
 	for _, fileInfo := range s.tableDatas {
 		// set a dummy `FileInfo` here without file meta because we needn't restore the table schema
@@ -370,13 +522,32 @@ func (s *mdLoaderSetup) listFiles(ctx context.Context, store storage.ExternalSto
 	// meaning the file and chunk orders will be the same everytime it is called
 	// (as long as the source is immutable).
 	totalScannedFileCount := 0
-	err := store.WalkDir(ctx, &storage.WalkOption{}, func(path string, size int64) error {
+	// when resuming from a continuation token, the files up to the last one
+	// walked by the previous loader are skipped.
+	resumed := len(s.resumeAfter) == 0
+	lastPath := ""
+	walkFn := func(path string, size int64, modTime time.Time) error {
+		if !resumed {
+			resumed = path == s.resumeAfter
+			return nil
+		}
+		if s.loader.sourceInclude != nil && !s.loader.sourceInclude.match(filepath.ToSlash(path)) {
+			return nil
+		}
 		logger := log.FromContext(ctx).With(zap.String("path", path))
 		totalScannedFileCount++
 		if s.setupCfg.MaxScanFiles > 0 && totalScannedFileCount > s.setupCfg.MaxScanFiles {
+			s.loader.continuationToken = encodeScanContinuationToken(lastPath)
 			return common.ErrTooManySourceFiles
 		}
-		res, err := s.loader.fileRouter.Route(filepath.ToSlash(path))
+		lastPath = path
+		if s.loader.verifyChecksum {
+			dataPath, algorithm, ok := checksumSidecarOf(path)
+			if ok && (len(s.loader.checksumAlgorithm) == 0 || algorithm == s.loader.checksumAlgorithm) {
+				s.checksumSidecars[dataPath] = path
+			}
+		}
+		res, err := s.loader.fileRouter.Route(filepath.ToSlash(path), RouteFileMeta{Size: size, ModTime: modTime})
 		if err != nil {
 			return errors.Annotatef(err, "apply file routing on file '%s' failed", path)
 		}
@@ -387,7 +558,17 @@ func (s *mdLoaderSetup) listFiles(ctx context.Context, store storage.ExternalSto
 
 		info := FileInfo{
 			TableName: filter.Table{Schema: res.Schema, Name: res.Name},
-			FileMeta:  SourceFileMeta{Path: path, Type: res.Type, Compression: res.Compression, SortKey: res.Key, FileSize: size},
+			FileMeta: SourceFileMeta{
+				Path:            path,
+				Type:            res.Type,
+				Compression:     res.Compression,
+				SortKey:         res.Key,
+				FileSize:        size,
+				CharacterSet:    res.CharacterSet,
+				Columns:         res.Columns,
+				SkipLines:       res.SkipLines,
+				SkipFooterLines: res.SkipFooterLines,
+			},
 		}
 
 		if s.loader.shouldSkip(&info.TableName) {
@@ -403,7 +584,9 @@ func (s *mdLoaderSetup) listFiles(ctx context.Context, store storage.ExternalSto
 			s.tableSchemas = append(s.tableSchemas, info)
 		case SourceTypeViewSchema:
 			s.viewSchemas = append(s.viewSchemas, info)
-		case SourceTypeSQL, SourceTypeCSV, SourceTypeParquet:
+		case SourceTypeSequenceSchema, SourceTypeProcedure, SourceTypeTrigger:
+			s.objectSchemas = append(s.objectSchemas, info)
+		case SourceTypeSQL, SourceTypeCSV, SourceTypeParquet, SourceTypeJSON:
 			s.tableDatas = append(s.tableDatas, info)
 		}
 
@@ -411,11 +594,100 @@ func (s *mdLoaderSetup) listFiles(ctx context.Context, store storage.ExternalSto
 			zap.String("table", res.Name), zap.Stringer("type", res.Type))
 
 		return nil
-	})
+	}
+	// only walk the directories covering `mydumper.source-include`, so the unrelated
+	// files in the data source are not listed at all.
+	subDirs := []string{""}
+	if s.loader.sourceInclude != nil {
+		subDirs = s.loader.sourceInclude.subDirs
+	}
+	var err error
+	for _, subDir := range subDirs {
+		if err = storage.WalkDirWithModTime(ctx, store, &storage.WalkOption{SubDir: subDir}, walkFn); err != nil {
+			break
+		}
+	}
+	if err == nil && !resumed {
+		return common.ErrInvalidConfig.GenWithStack("file '%s' in the scan continuation token is not found", s.resumeAfter)
+	}
 
 	return errors.Trace(err)
 }
 
+// discoverChecksums fills the checksums of the data files which have sidecar files.
+func (s *mdLoaderSetup) discoverChecksums(ctx context.Context, store storage.ExternalStorage) error {
+	if len(s.checksumSidecars) == 0 {
+		return nil
+	}
+	for i := range s.tableDatas {
+		fileMeta := &s.tableDatas[i].FileMeta
+		sidecar, ok := s.checksumSidecars[fileMeta.Path]
+		if !ok {
+			continue
+		}
+		_, algorithm, _ := checksumSidecarOf(sidecar)
+		checksum, err := readChecksumSidecar(ctx, store, sidecar, algorithm)
+		if err != nil {
+			return errors.Annotatef(err, "read checksum of file '%s' failed", fileMeta.Path)
+		}
+		fileMeta.Checksum = checksum
+	}
+	return nil
+}
+
+// preSplitLargeFiles splits the large data files into regions concurrently, so that
+// they needn't be opened again when making the table regions.
+func (s *mdLoaderSetup) preSplitLargeFiles(ctx context.Context, store storage.ExternalStorage) error {
+	cfg := s.loader.preSplitCfg
+	if cfg == nil {
+		return nil
+	}
+	start := time.Now()
+	concurrency := mathutil.Max(cfg.App.RegionConcurrency, 2)
+	ioWorkers := worker.NewPool(ctx, mathutil.Max(cfg.App.IOConcurrency, 1), "pre-split")
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(concurrency)
+	splitCount := 0
+	for i := range s.tableDatas {
+		fileInfo := &s.tableDatas[i]
+		// the compressed files can't be split since they can't be read from the middle.
+		if fileInfo.FileMeta.Compression != CompressionNone || !shouldSplitLargeFile(cfg, *fileInfo) {
+			continue
+		}
+		splitCount++
+		eg.Go(func() error {
+			regions, err := preSplitLargeFile(egCtx, cfg, *fileInfo, ioWorkers, store)
+			if err != nil {
+				return errors.Annotatef(err, "pre-split file '%s' failed", fileInfo.FileMeta.Path)
+			}
+			fileInfo.Regions = regions
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("[loader] pre-split large data files",
+		zap.Int("count", splitCount), zap.Duration("cost", time.Since(start)))
+	return nil
+}
+
+func (l *MDLoader) hasRoutes() bool {
+	return l.router != nil || l.routeOverrides != nil
+}
+
+// routeTable routes the table by the routes given by the command line first, and
+// then by the routes in the config file if none of the former matches.
+func (l *MDLoader) routeTable(schema, table string) (targetSchema, targetTable string, err error) {
+	if l.routeOverrides != nil && l.routeOverrides.Match(schema, table) {
+		return l.routeOverrides.Route(schema, table)
+	}
+	if l.router != nil {
+		return l.router.Route(schema, table)
+	}
+	return schema, table, nil
+}
+
 func (l *MDLoader) shouldSkip(table *filter.Table) bool {
 	if len(table.Name) == 0 {
 		return !l.filter.MatchSchema(table.Schema)
@@ -424,8 +696,7 @@ func (l *MDLoader) shouldSkip(table *filter.Table) bool {
 }
 
 func (s *mdLoaderSetup) route() error {
-	r := s.loader.router
-	if r == nil {
+	if !s.loader.hasRoutes() {
 		return nil
 	}
 
@@ -447,6 +718,9 @@ func (s *mdLoaderSetup) route() error {
 	for _, info := range s.viewSchemas {
 		knownDBNames[info.TableName.Schema].count++
 	}
+	for _, info := range s.objectSchemas {
+		knownDBNames[info.TableName.Schema].count++
+	}
 	for _, info := range s.tableDatas {
 		knownDBNames[info.TableName.Schema].count++
 	}
@@ -454,7 +728,7 @@ func (s *mdLoaderSetup) route() error {
 	runRoute := func(arr []FileInfo) error {
 		for i, info := range arr {
 			rawDB, rawTable := info.TableName.Schema, info.TableName.Name
-			targetDB, targetTable, err := r.Route(rawDB, rawTable)
+			targetDB, targetTable, err := s.loader.routeTable(rawDB, rawTable)
 			if err != nil {
 				return errors.Trace(err)
 			}
@@ -487,6 +761,9 @@ func (s *mdLoaderSetup) route() error {
 	if err := runRoute(s.viewSchemas); err != nil {
 		return errors.Trace(err)
 	}
+	if err := runRoute(s.objectSchemas); err != nil {
+		return errors.Trace(err)
+	}
 	if err := runRoute(s.tableDatas); err != nil {
 		return errors.Trace(err)
 	}
@@ -514,7 +791,7 @@ func (s *mdLoaderSetup) insertDB(f FileInfo) (*MDDatabaseMeta, bool) {
 	ptr := &MDDatabaseMeta{
 		Name:       f.TableName.Schema,
 		SchemaFile: f,
-		charSet:    s.loader.charSet,
+		charSet:    s.charSetOf(f),
 	}
 	s.loader.dbs = append(s.loader.dbs, ptr)
 	return ptr, false
@@ -534,39 +811,76 @@ func (s *mdLoaderSetup) insertTable(fileInfo FileInfo) (tblMeta *MDTableMeta, db
 	}
 	s.tableIndexMap[fileInfo.TableName] = len(dbMeta.Tables)
 	ptr := &MDTableMeta{
+		DB:             fileInfo.TableName.Schema,
+		Name:           fileInfo.TableName.Name,
+		SchemaFile:     fileInfo,
+		DataFiles:      make([]FileInfo, 0, 16),
+		charSet:        s.charSetOf(fileInfo),
+		IndexRatio:     0.0,
+		IsRowOrdered:   s.isRowOrdered(),
+		inferSchemaCfg: s.loader.inferSchemaCfg,
+	}
+	dbMeta.Tables = append(dbMeta.Tables, ptr)
+	return ptr, dbExists, false
+}
+
+func (s *mdLoaderSetup) insertView(fileInfo FileInfo) {
+	dbFileInfo := FileInfo{
+		TableName: filter.Table{
+			Schema: fileInfo.TableName.Schema,
+		},
+		FileMeta: SourceFileMeta{Type: SourceTypeSchemaSchema},
+	}
+	dbMeta, _ := s.insertDB(dbFileInfo)
+	meta := &MDTableMeta{
 		DB:           fileInfo.TableName.Schema,
 		Name:         fileInfo.TableName.Name,
 		SchemaFile:   fileInfo,
-		DataFiles:    make([]FileInfo, 0, 16),
-		charSet:      s.loader.charSet,
+		charSet:      s.charSetOf(fileInfo),
 		IndexRatio:   0.0,
-		IsRowOrdered: true,
+		IsRowOrdered: s.isRowOrdered(),
 	}
-	dbMeta.Tables = append(dbMeta.Tables, ptr)
-	return ptr, dbExists, false
+	dbMeta.Views = append(dbMeta.Views, meta)
 }
 
-func (s *mdLoaderSetup) insertView(fileInfo FileInfo) (dbExists bool, tableExists bool) {
+// insertSchemaObject inserts the schema file of a sequence, procedure or trigger to
+// its database.
+func (s *mdLoaderSetup) insertSchemaObject(fileInfo FileInfo) {
 	dbFileInfo := FileInfo{
 		TableName: filter.Table{
 			Schema: fileInfo.TableName.Schema,
 		},
 		FileMeta: SourceFileMeta{Type: SourceTypeSchemaSchema},
 	}
-	dbMeta, dbExists := s.insertDB(dbFileInfo)
-	_, ok := s.tableIndexMap[fileInfo.TableName]
-	if ok {
-		meta := &MDTableMeta{
-			DB:           fileInfo.TableName.Schema,
-			Name:         fileInfo.TableName.Name,
-			SchemaFile:   fileInfo,
-			charSet:      s.loader.charSet,
-			IndexRatio:   0.0,
-			IsRowOrdered: true,
-		}
-		dbMeta.Views = append(dbMeta.Views, meta)
+	dbMeta, _ := s.insertDB(dbFileInfo)
+	meta := &MDTableMeta{
+		DB:         fileInfo.TableName.Schema,
+		Name:       fileInfo.TableName.Name,
+		SchemaFile: fileInfo,
+		charSet:    s.charSetOf(fileInfo),
+	}
+	switch fileInfo.FileMeta.Type {
+	case SourceTypeSequenceSchema:
+		dbMeta.Sequences = append(dbMeta.Sequences, meta)
+	case SourceTypeProcedure:
+		dbMeta.Procedures = append(dbMeta.Procedures, meta)
+	case SourceTypeTrigger:
+		dbMeta.Triggers = append(dbMeta.Triggers, meta)
 	}
-	return dbExists, ok
+}
+
+// charSetOf returns the character set of the schema file, which is `mydumper.character-set`
+// unless it's overridden by the file routing rule.
+func (s *mdLoaderSetup) charSetOf(fileInfo FileInfo) string {
+	if len(fileInfo.FileMeta.CharacterSet) > 0 {
+		return fileInfo.FileMeta.CharacterSet
+	}
+	return s.loader.charSet
+}
+
+// isRowOrdered returns whether the rows in the data files are ordered by the primary key.
+func (s *mdLoaderSetup) isRowOrdered() bool {
+	return s.loader.sourceMeta == nil || s.loader.sourceMeta.IsRowOrdered
 }
 
 // GetDatabases gets the list of scanned MDDatabaseMeta for the loader.
@@ -574,6 +888,25 @@ func (l *MDLoader) GetDatabases() []*MDDatabaseMeta {
 	return l.dbs
 }
 
+// GetViews gets the views of all the databases, which are sorted so each view is placed
+// after the views it references.
+func (l *MDLoader) GetViews() []*MDTableMeta {
+	return l.views
+}
+
+// ContinuationToken returns an opaque token of the last file walked by the loader if the scanning is
+// stopped by MaxScanFiles, or an empty string if all the files are scanned. Pass it to
+// WithScanContinuationToken to resume the scanning in a new loader.
+func (l *MDLoader) ContinuationToken() string {
+	return l.continuationToken
+}
+
+// GetSourceMeta gets the metadata of the data source written by Dumpling, or nil if
+// there is no metadata file.
+func (l *MDLoader) GetSourceMeta() *MDSourceMeta {
+	return l.sourceMeta
+}
+
 // GetStore gets the external storage used by the loader.
 func (l *MDLoader) GetStore() storage.ExternalStorage {
 	return l.store
@@ -16,15 +16,19 @@ package mydump_test
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pingcap/tidb/br/pkg/lightning/common"
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	md "github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/lightning/worker"
 	"github.com/pingcap/tidb/br/pkg/storage"
 	filter "github.com/pingcap/tidb/util/table-filter"
 	router "github.com/pingcap/tidb/util/table-router"
@@ -281,8 +285,13 @@ func TestViewNoHostDB(t *testing.T) {
 	s.touch(t, "notdb-schema-create.sql")
 	s.touch(t, "db.tbl-schema-view.sql")
 
-	_, err := md.NewMyDumpLoader(context.Background(), s.cfg)
-	require.Contains(t, err.Error(), `invalid view schema file, miss host table schema for view 'tbl'`)
+	mdl, err := md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	dbs := mdl.GetDatabases()
+	require.Len(t, dbs, 2)
+	require.Equal(t, "db", dbs[1].Name)
+	require.Len(t, dbs[1].Views, 1)
+	require.Equal(t, "tbl", dbs[1].Views[0].Name)
 }
 
 func TestViewNoHostTable(t *testing.T) {
@@ -296,8 +305,110 @@ func TestViewNoHostTable(t *testing.T) {
 	s.touch(t, "db-schema-create.sql")
 	s.touch(t, "db.tbl-schema-view.sql")
 
-	_, err := md.NewMyDumpLoader(context.Background(), s.cfg)
-	require.Contains(t, err.Error(), `invalid view schema file, miss host table schema for view 'tbl'`)
+	mdl, err := md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	dbs := mdl.GetDatabases()
+	require.Len(t, dbs, 1)
+	require.Len(t, dbs[0].Tables, 0)
+	require.Len(t, dbs[0].Views, 1)
+	require.Equal(t, "tbl", dbs[0].Views[0].Name)
+}
+
+func TestViewDependencies(t *testing.T) {
+	s := newTestMydumpLoaderSuite(t)
+	s.cfg.Mydumper.CharacterSet = "auto"
+
+	writeView := func(db, name, sel string) {
+		content := "/*!40101 SET NAMES binary*/;\n" +
+			"DROP TABLE IF EXISTS `" + name + "`;\n" +
+			"DROP VIEW IF EXISTS `" + name + "`;\n" +
+			"CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW `" + name + "` AS " + sel + ";\n"
+		err := os.WriteFile(filepath.Join(s.sourceDir, db+"."+name+"-schema-view.sql"), []byte(content), 0o644)
+		require.NoError(t, err)
+	}
+	s.touch(t, "a-schema-create.sql")
+	s.touch(t, "b-schema-create.sql")
+	s.touch(t, "b.t-schema.sql")
+	// a.v1 -> b.v3 -> a.v2 -> b.t, b.v4 -> a.v1 and a.v2
+	writeView("a", "v1", "SELECT * FROM `b`.`v3`")
+	writeView("a", "v2", "SELECT * FROM `b`.`t`")
+	writeView("b", "v3", "SELECT `x`.* FROM `a`.`v2` AS `x` JOIN `t` ON 1")
+	writeView("b", "v4", "SELECT * FROM `a`.`V1` UNION SELECT * FROM (SELECT * FROM `a`.`v2`) AS `y`")
+
+	mdl, err := md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+
+	names := func(views []*md.MDTableMeta) []string {
+		res := make([]string, 0, len(views))
+		for _, v := range views {
+			res = append(res, v.DB+"."+v.Name)
+		}
+		return res
+	}
+	require.Equal(t, []string{"a.v2", "b.v3", "a.v1", "b.v4"}, names(mdl.GetViews()))
+	dbs := mdl.GetDatabases()
+	require.Len(t, dbs, 2)
+	require.Equal(t, []string{"a.v2", "a.v1"}, names(dbs[0].Views))
+	require.Equal(t, []string{"b.v3", "b.v4"}, names(dbs[1].Views))
+
+	// the independent views are grouped into the same level.
+	writeView("a", "v5", "SELECT * FROM `b`.`t`")
+	mdl, err = md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	levels := md.GroupViewsByLevel(mdl.GetViews())
+	require.Len(t, levels, 4)
+	require.Equal(t, []string{"a.v2", "a.v5"}, names(levels[0]))
+	require.Equal(t, []string{"b.v3"}, names(levels[1]))
+	require.Equal(t, []string{"a.v1"}, names(levels[2]))
+	require.Equal(t, []string{"b.v4"}, names(levels[3]))
+
+	// circular dependency is rejected.
+	writeView("a", "v2", "SELECT * FROM `a`.`v1`")
+	_, err = md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.ErrorContains(t, err, "circular view dependency")
+}
+
+func TestSchemaObjects(t *testing.T) {
+	/*
+		Path/
+			db-schema-create.sql
+			db-schema-post.sql
+			db.seq-schema-sequence.sql
+			db.tbl-schema.sql
+			db.tbl-schema-triggers.sql
+			db.proc-schema-post.sql
+			other.seq-schema-sequence.sql
+	*/
+	s := newTestMydumpLoaderSuite(t)
+
+	s.touch(t, "db-schema-create.sql")
+	s.touch(t, "db-schema-post.sql")
+	s.touch(t, "db.seq-schema-sequence.sql")
+	s.touch(t, "db.tbl-schema.sql")
+	s.touch(t, "db.tbl-schema-triggers.sql")
+	s.touch(t, "db.proc-schema-post.sql")
+	s.touch(t, "other.seq-schema-sequence.sql")
+
+	mdl, err := md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	dbs := mdl.GetDatabases()
+	require.Len(t, dbs, 2)
+
+	require.Equal(t, "db", dbs[0].Name)
+	require.Len(t, dbs[0].Tables, 1)
+	require.Len(t, dbs[0].Sequences, 1)
+	require.Equal(t, "seq", dbs[0].Sequences[0].Name)
+	require.Len(t, dbs[0].Triggers, 1)
+	require.Equal(t, "tbl", dbs[0].Triggers[0].Name)
+	require.Equal(t, "db.tbl-schema-triggers.sql", dbs[0].Triggers[0].SchemaFile.FileMeta.Path)
+	require.Len(t, dbs[0].Procedures, 2)
+	require.ElementsMatch(t, []string{"db-schema-post.sql", "db.proc-schema-post.sql"},
+		[]string{dbs[0].Procedures[0].SchemaFile.FileMeta.Path, dbs[0].Procedures[1].SchemaFile.FileMeta.Path})
+
+	// the database of a sequence without the schema file is created automatically.
+	require.Equal(t, "other", dbs[1].Name)
+	require.Len(t, dbs[1].Sequences, 1)
+	require.Equal(t, "seq", dbs[1].Sequences[0].Name)
 }
 
 func TestDataWithoutSchema(t *testing.T) {
@@ -330,6 +441,121 @@ func TestDataWithoutSchema(t *testing.T) {
 	}}, mdl.GetDatabases())
 }
 
+func TestInferSchema(t *testing.T) {
+	s := newTestMydumpLoaderSuite(t)
+	s.cfg.Mydumper.CharacterSet = "auto"
+	s.cfg.Mydumper.ReadBlockSize = config.ReadBlockSize
+	s.cfg.Mydumper.CSV = config.CSVConfig{
+		Separator:   ",",
+		Delimiter:   `"`,
+		Header:      true,
+		Null:        `\N`,
+		InferSchema: true,
+
+		InferSchemaSampleRows: config.InferSchemaSampleRows,
+	}
+
+	dir := s.sourceDir
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.tbl.1.csv"), []byte(
+		"id,Price,zip,day,ts,u,note,empty\n"+
+			"1,1.5,00123,2022-01-02,2022-01-02 10:00:00,18446744073709551615,a,\\N\n"+
+			"-20,-123.25,10001,2022-12-31,2022-01-02T10:00:00.123,1,\"x,y\",\\N\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.tbl.2.csv"), []byte(
+		"id,Price,zip,day,ts,u,note,empty\n"+
+			"3,7,10002,\\N,2022-01-03,2,,\\N\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.tbl2.csv"), []byte("a,b\n1,2\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.tbl2-schema.sql"), []byte("CREATE TABLE tbl2 (a int, b text);"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.tbl3.csv"), []byte("a,A\n1,2\n"), 0o644))
+
+	ctx := context.Background()
+	mdl, err := md.NewMyDumpLoader(ctx, s.cfg)
+	require.NoError(t, err)
+	tables := mdl.GetDatabases()[0].Tables
+	require.Len(t, tables, 3)
+	for _, tbl := range tables {
+		require.True(t, tbl.HasSchema())
+		schema, err := tbl.GetSchema(ctx, mdl.GetStore())
+		switch tbl.Name {
+		case "tbl":
+			require.NoError(t, err)
+			require.Equal(t, "CREATE TABLE `tbl` (\n"+
+				"  `id` BIGINT,\n"+
+				"  `price` DECIMAL(5,2),\n"+
+				"  `zip` VARCHAR(255),\n"+
+				"  `day` DATE,\n"+
+				"  `ts` DATETIME(3),\n"+
+				"  `u` BIGINT UNSIGNED,\n"+
+				"  `note` VARCHAR(255),\n"+
+				"  `empty` VARCHAR(255)\n"+
+				");", schema)
+		case "tbl2":
+			require.NoError(t, err)
+			require.Equal(t, "CREATE TABLE tbl2 (a int, b text);", schema)
+		case "tbl3":
+			require.ErrorContains(t, err, "duplicated column 'a'")
+		}
+	}
+
+	// the schema isn't inferred unless it is enabled.
+	s.cfg.Mydumper.CSV.InferSchema = false
+	mdl, err = md.NewMyDumpLoader(ctx, s.cfg)
+	require.NoError(t, err)
+	for _, tbl := range mdl.GetDatabases()[0].Tables {
+		require.Equal(t, tbl.Name == "tbl2", tbl.HasSchema())
+		if tbl.Name != "tbl2" {
+			_, err = tbl.GetSchema(ctx, mdl.GetStore())
+			require.ErrorContains(t, err, "schema file is missing")
+		}
+	}
+}
+
+func TestInferSchemaSampling(t *testing.T) {
+	s := newTestMydumpLoaderSuite(t)
+	s.cfg.Mydumper.CharacterSet = "auto"
+	s.cfg.Mydumper.ReadBlockSize = config.ReadBlockSize
+	s.cfg.Mydumper.CSV = config.CSVConfig{
+		Separator:   ",",
+		Delimiter:   `"`,
+		Header:      true,
+		Null:        `\N`,
+		InferSchema: true,
+
+		InferSchemaSampleRows: 4,
+	}
+
+	// the values in the second half of the file are decimals.
+	var sb strings.Builder
+	sb.WriteString("a,b\n")
+	for i := 0; i < 100; i++ {
+		if i < 50 {
+			fmt.Fprintf(&sb, "%d,%d\n", i, i)
+		} else {
+			fmt.Fprintf(&sb, "%d.5,%d\n", i, i)
+		}
+	}
+	dir := s.sourceDir
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.tbl.1.csv"), []byte(sb.String()), 0o644))
+	// the rows are also sampled from the other files.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.tbl.2.csv"), []byte("a,b\n1,x\n"), 0o644))
+
+	ctx := context.Background()
+	inferSchema := func() string {
+		mdl, err := md.NewMyDumpLoader(ctx, s.cfg)
+		require.NoError(t, err)
+		schema, err := mdl.GetDatabases()[0].Tables[0].GetSchema(ctx, mdl.GetStore())
+		require.NoError(t, err)
+		return schema
+	}
+	// only the first rows of the file are sampled without strict-format.
+	require.Equal(t, "CREATE TABLE `tbl` (\n  `a` BIGINT,\n  `b` VARCHAR(255)\n);", inferSchema())
+
+	s.cfg.Mydumper.StrictFormat = true
+	require.Equal(t, "CREATE TABLE `tbl` (\n  `a` DECIMAL(3,1),\n  `b` VARCHAR(255)\n);", inferSchema())
+
+	s.cfg.Mydumper.CSV.InferSchemaSampleRows = 1
+	require.Equal(t, "CREATE TABLE `tbl` (\n  `a` BIGINT,\n  `b` BIGINT\n);", inferSchema())
+}
+
 func TestTablesWithDots(t *testing.T) {
 	s := newTestMydumpLoaderSuite(t)
 
@@ -339,9 +565,10 @@ func TestTablesWithDots(t *testing.T) {
 	s.touch(t, "db.0002-schema.sql")
 	s.touch(t, "db.0002.sql")
 
-	// insert some tables with file name structures which we're going to ignore.
 	s.touch(t, "db.v-schema-trigger.sql")
 	s.touch(t, "db.v-schema-post.sql")
+
+	// insert some tables with file name structures which we're going to ignore.
 	s.touch(t, "db.sql")
 	s.touch(t, "db-schema.sql")
 
@@ -368,6 +595,16 @@ func TestTablesWithDots(t *testing.T) {
 				IndexRatio:   0.0,
 			},
 		},
+		Procedures: []*md.MDTableMeta{{
+			DB:         "db",
+			Name:       "v",
+			SchemaFile: md.FileInfo{TableName: filter.Table{Schema: "db", Name: "v"}, FileMeta: md.SourceFileMeta{Path: "db.v-schema-post.sql", Type: md.SourceTypeProcedure}},
+		}},
+		Triggers: []*md.MDTableMeta{{
+			DB:         "db",
+			Name:       "v",
+			SchemaFile: md.FileInfo{TableName: filter.Table{Schema: "db", Name: "v"}, FileMeta: md.SourceFileMeta{Path: "db.v-schema-trigger.sql", Type: md.SourceTypeTrigger}},
+		}},
 	}}, mdl.GetDatabases())
 }
 
@@ -700,6 +937,41 @@ func TestBadRouterRule(t *testing.T) {
 	require.Regexp(t, `.*pattern a\*b not valid`, err.Error())
 }
 
+func TestRouteOverrides(t *testing.T) {
+	s := newTestMydumpLoaderSuite(t)
+
+	s.cfg.Routes = []*router.TableRule{{
+		SchemaPattern: "a*",
+		TargetSchema:  "cfg",
+	}}
+	s.cfg.RouteOverrides = []*router.TableRule{{
+		SchemaPattern: "a1",
+		TablePattern:  "*",
+		TargetSchema:  "cli",
+	}}
+
+	s.touch(t, "a0-schema-create.sql")
+	s.touch(t, "a0.t-schema.sql")
+	s.touch(t, "a1-schema-create.sql")
+	s.touch(t, "a1.t-schema.sql")
+	s.touch(t, "b-schema-create.sql")
+	s.touch(t, "b.t-schema.sql")
+
+	mdl, err := md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	tables := make(map[string][]string)
+	for _, db := range mdl.GetDatabases() {
+		for _, tbl := range db.Tables {
+			tables[db.Name] = append(tables[db.Name], tbl.Name)
+		}
+	}
+	require.Equal(t, map[string][]string{
+		"b":   {"t"},
+		"cfg": {"t"},
+		"cli": {"t"},
+	}, tables)
+}
+
 func TestFileRouting(t *testing.T) {
 	s := newTestMydumpLoaderSuite(t)
 
@@ -990,3 +1262,304 @@ func TestMaxScanFilesOption(t *testing.T) {
 	tbl = dbMeta.Tables[0]
 	require.Equal(t, maxScanFilesCount-2, len(tbl.DataFiles))
 }
+
+func TestScanContinuationToken(t *testing.T) {
+	ctx := context.Background()
+	memStore := storage.NewMemStorage()
+	require.NoError(t, memStore.WriteFile(ctx, "/test-src/db1.tbl1-schema.sql",
+		[]byte("CREATE TABLE db1.tbl1 ( id INTEGER, val VARCHAR(255) );"),
+	))
+	require.NoError(t, memStore.WriteFile(ctx, "/test-src/db1-schema-create.sql",
+		[]byte("CREATE DATABASE db1;"),
+	))
+	const dataFilesCount = 10
+	for i := 0; i < dataFilesCount; i++ {
+		require.NoError(t, memStore.WriteFile(ctx, fmt.Sprintf("/test-src/db1.tbl1.%d.sql", i),
+			[]byte(fmt.Sprintf("INSERT INTO db1.tbl1 (id, val) VALUES (%d, 'aaa%d');", i, i)),
+		))
+	}
+	cfg := newConfigWithSourceDir("/test-src")
+
+	mdl, err := md.NewMyDumpLoaderWithStore(ctx, cfg, memStore)
+	require.NoError(t, err)
+	require.Empty(t, mdl.ContinuationToken())
+
+	dataFiles := make(map[string]struct{})
+	token := ""
+	batches := 0
+	for {
+		mdl, err = md.NewMyDumpLoaderWithStore(ctx, cfg, memStore,
+			md.WithMaxScanFiles(4),
+			md.WithScanContinuationToken(token),
+		)
+		batches++
+		for _, dbMeta := range mdl.GetDatabases() {
+			for _, tblMeta := range dbMeta.Tables {
+				for _, f := range tblMeta.DataFiles {
+					require.NotContains(t, dataFiles, f.FileMeta.Path)
+					dataFiles[f.FileMeta.Path] = struct{}{}
+				}
+			}
+		}
+		token = mdl.ContinuationToken()
+		if len(token) == 0 {
+			require.NoError(t, err)
+			break
+		}
+		require.EqualError(t, err, common.ErrTooManySourceFiles.Error())
+	}
+	require.Equal(t, 3, batches)
+	require.Len(t, dataFiles, dataFilesCount)
+
+	_, err = md.NewMyDumpLoaderWithStore(ctx, cfg, memStore, md.WithScanContinuationToken("!invalid"))
+	require.Regexp(t, "invalid scan continuation token", err)
+	_, err = md.NewMyDumpLoaderWithStore(ctx, cfg, memStore,
+		md.WithScanContinuationToken(base64.RawURLEncoding.EncodeToString([]byte("db1.tbl1.10.sql"))))
+	require.Regexp(t, "file 'db1.tbl1.10.sql' in the scan continuation token is not found", err)
+}
+
+func TestChecksumDiscovery(t *testing.T) {
+	s := newTestMydumpLoaderSuite(t)
+	s.cfg.Mydumper.VerifyChecksum = true
+
+	s.touch(t, "db.tbl-schema.sql")
+	s.touch(t, "db.tbl.1.csv")
+	s.touch(t, "db.tbl.2.csv")
+	digest := strings.Repeat("ab", 32)
+	require.NoError(t, os.WriteFile(filepath.Join(s.sourceDir, "db.tbl.1.csv.sha256"), []byte(digest+"  db.tbl.1.csv\n"), 0o644))
+
+	mdl, err := md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	dataFiles := mdl.GetDatabases()[0].Tables[0].DataFiles
+	require.Len(t, dataFiles, 2)
+	require.Equal(t, md.FileChecksum{Algorithm: md.ChecksumSHA256, Digest: digest}, dataFiles[0].FileMeta.Checksum)
+	require.True(t, dataFiles[1].FileMeta.Checksum.IsEmpty())
+
+	s.cfg.Mydumper.VerifyChecksum = false
+	mdl, err = md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	require.True(t, mdl.GetDatabases()[0].Tables[0].DataFiles[0].FileMeta.Checksum.IsEmpty())
+
+	s.cfg.Mydumper.VerifyChecksum = true
+	require.NoError(t, os.WriteFile(filepath.Join(s.sourceDir, "db.tbl.2.csv.md5"), []byte("xyz"), 0o644))
+	_, err = md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.Regexp(t, "read checksum of file 'db.tbl.2.csv' failed", err)
+
+	// only the sidecar files of the checksum algorithm are used.
+	s.cfg.Mydumper.ChecksumAlgorithm = md.ChecksumCRC64
+	require.NoError(t, os.WriteFile(filepath.Join(s.sourceDir, "db.tbl.2.csv.crc64"), []byte("0123456789abcdef\n"), 0o644))
+	mdl, err = md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	dataFiles = mdl.GetDatabases()[0].Tables[0].DataFiles
+	require.True(t, dataFiles[0].FileMeta.Checksum.IsEmpty())
+	require.Equal(t, md.FileChecksum{Algorithm: md.ChecksumCRC64, Digest: "0123456789abcdef"}, dataFiles[1].FileMeta.Checksum)
+}
+
+func TestFileRoutingByModTime(t *testing.T) {
+	s := newTestMydumpLoaderSuite(t)
+
+	s.cfg.Mydumper.FileRouters = []*config.FileRouteRule{
+		{
+			Pattern:        `^db\.tbl\.[0-9]+\.sql$`,
+			Type:           "ignore",
+			ModifiedBefore: "2022-08-01T00:00:00Z",
+		},
+	}
+
+	s.touch(t, "db-schema-create.sql")
+	s.touch(t, "db.tbl-schema.sql")
+	modTime := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	for i, tm := range []time.Time{modTime.Add(-time.Second), modTime, modTime.Add(time.Hour)} {
+		name := fmt.Sprintf("db.tbl.%d.sql", i)
+		s.touch(t, name)
+		require.NoError(t, os.Chtimes(filepath.Join(s.sourceDir, name), tm, tm))
+	}
+
+	mdl, err := md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	dbMetas := mdl.GetDatabases()
+	require.Len(t, dbMetas, 1)
+	require.Len(t, dbMetas[0].Tables, 1)
+	dataFiles := dbMetas[0].Tables[0].DataFiles
+	require.Len(t, dataFiles, 2)
+	require.Equal(t, "db.tbl.1.sql", dataFiles[0].FileMeta.Path)
+	require.Equal(t, "db.tbl.2.sql", dataFiles[1].FileMeta.Path)
+}
+
+func TestFileRoutingWithCharacterSet(t *testing.T) {
+	s := newTestMydumpLoaderSuite(t)
+
+	s.cfg.Mydumper.CharacterSet = "utf8mb4"
+	s.cfg.Mydumper.FileRouters = []*config.FileRouteRule{
+		{
+			Pattern:      `^db\.legacy-schema\.sql$`,
+			Schema:       "db",
+			Table:        "legacy",
+			Type:         "table-schema",
+			CharacterSet: "latin1",
+		},
+	}
+
+	s.touch(t, "db-schema-create.sql")
+	// "caf\xe9" is "café" in latin1, and an invalid UTF-8 sequence.
+	require.NoError(t, os.WriteFile(filepath.Join(s.sourceDir, "db.legacy-schema.sql"),
+		[]byte("CREATE TABLE legacy (a varchar(10) DEFAULT 'caf\xe9');"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(s.sourceDir, "db.modern-schema.sql"),
+		[]byte("CREATE TABLE modern (a varchar(10) DEFAULT 'café');"), 0o644))
+
+	ctx := context.Background()
+	store, err := storage.NewLocalStorage(s.sourceDir)
+	require.NoError(t, err)
+
+	mdl, err := md.NewMyDumpLoader(ctx, s.cfg)
+	require.NoError(t, err)
+	dbMetas := mdl.GetDatabases()
+	require.Len(t, dbMetas, 1)
+	tables := dbMetas[0].Tables
+	require.Len(t, tables, 2)
+	require.Equal(t, "legacy", tables[0].Name)
+	require.Equal(t, "latin1", tables[0].SchemaFile.FileMeta.CharacterSet)
+	require.Equal(t, "modern", tables[1].Name)
+	require.Equal(t, "", tables[1].SchemaFile.FileMeta.CharacterSet)
+
+	for _, tblMeta := range tables {
+		sql, err := tblMeta.GetSchema(ctx, store)
+		require.NoError(t, err)
+		require.Contains(t, sql, "DEFAULT 'café'")
+	}
+}
+
+func TestSourceMeta(t *testing.T) {
+	s := newTestMydumpLoaderSuite(t)
+	s.touch(t, "db.tbl-schema.sql")
+	s.touch(t, "db.tbl.0.sql")
+
+	mdl, err := md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	require.Nil(t, mdl.GetSourceMeta())
+	require.True(t, mdl.GetDatabases()[0].Tables[0].IsRowOrdered)
+
+	metadata := "Started dump at: 2022-06-01 10:00:00\n" +
+		"Order by primary key: false\n" +
+		"SHOW MASTER STATUS:\n" +
+		"\tLog: tidb-binlog\n" +
+		"\tPos: 433875441152901121\n" +
+		"\tGTID:\n\n" +
+		"SHOW MASTER STATUS: /* AFTER CONNECTION POOL ESTABLISHED */\n" +
+		"\tLog: tidb-binlog\n" +
+		"\tPos: 433875441152901122\n" +
+		"\tGTID:\n\n" +
+		"Finished dump at: 2022-06-01 10:05:00\n"
+	require.NoError(t, os.WriteFile(filepath.Join(s.sourceDir, "metadata"), []byte(metadata), 0o644))
+	mdl, err = md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	require.Equal(t, &md.MDSourceMeta{
+		StartTime:    time.Date(2022, 6, 1, 10, 0, 0, 0, time.Local),
+		FinishTime:   time.Date(2022, 6, 1, 10, 5, 0, 0, time.Local),
+		BinlogName:   "tidb-binlog",
+		BinlogPos:    433875441152901121,
+		SnapshotTS:   433875441152901121,
+		IsRowOrdered: false,
+	}, mdl.GetSourceMeta())
+	require.False(t, mdl.GetDatabases()[0].Tables[0].IsRowOrdered)
+
+	metadata = "Started dump at: 2022-06-01 10:00:00\n" +
+		"SHOW MASTER STATUS:\n" +
+		"\tLog: mysql-bin.000003\n" +
+		"\tPos: 7502\n" +
+		"\tGTID:6ce40be3-e359-11e9-87e0-36933cb0ca5a:1-29\n\n" +
+		"SHOW SLAVE STATUS:\n" +
+		"\tHost: 192.168.1.100\n" +
+		"\tLog: mysql-bin.000001\n" +
+		"\tPos: 4\n" +
+		"\tGTID:\n\n" +
+		"Finished dump at: 2022-06-01 10:05:00\n"
+	require.NoError(t, os.WriteFile(filepath.Join(s.sourceDir, "metadata"), []byte(metadata), 0o644))
+	mdl, err = md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	sourceMeta := mdl.GetSourceMeta()
+	require.Equal(t, "mysql-bin.000003", sourceMeta.BinlogName)
+	require.Equal(t, uint64(7502), sourceMeta.BinlogPos)
+	require.Equal(t, "6ce40be3-e359-11e9-87e0-36933cb0ca5a:1-29", sourceMeta.BinlogGTID)
+	require.Zero(t, sourceMeta.SnapshotTS)
+	require.True(t, sourceMeta.IsRowOrdered)
+	require.True(t, mdl.GetDatabases()[0].Tables[0].IsRowOrdered)
+
+	// a broken metadata file is ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(s.sourceDir, "metadata"), []byte("Order by primary key: maybe\n"), 0o644))
+	mdl, err = md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	require.Nil(t, mdl.GetSourceMeta())
+	require.True(t, mdl.GetDatabases()[0].Tables[0].IsRowOrdered)
+}
+
+func TestPreSplitLargeFiles(t *testing.T) {
+	s := newTestMydumpLoaderSuite(t)
+	s.cfg.Mydumper.StrictFormat = true
+	s.cfg.Mydumper.MaxRegionSize = 12
+	s.cfg.Mydumper.ReadBlockSize = config.ReadBlockSize
+	s.cfg.Mydumper.CSV = config.CSVConfig{Separator: ",", Header: true, Null: `\N`, Terminator: "\n"}
+	s.cfg.App.RegionConcurrency = 2
+	s.cfg.App.IOConcurrency = 2
+
+	s.touch(t, "db.tbl-schema.sql")
+	require.NoError(t, os.WriteFile(filepath.Join(s.sourceDir, "db.tbl.1.csv"), []byte("a,b,c\n1,2,3\n4,5,6\n7,8,9\n10,11,12\n13,14,15\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(s.sourceDir, "db.tbl.2.csv"), []byte("a,b,c\n1,2,3\n"), 0o644))
+
+	ctx := context.Background()
+	mdl, err := md.NewMyDumpLoader(ctx, s.cfg)
+	require.NoError(t, err)
+	tableMeta := mdl.GetDatabases()[0].Tables[0]
+	require.Nil(t, tableMeta.DataFiles[0].Regions)
+	ioWorkers := worker.NewPool(ctx, 2, "io")
+	expected, err := md.MakeTableRegions(ctx, tableMeta, 3, s.cfg, ioWorkers, mdl.GetStore())
+	require.NoError(t, err)
+	require.Len(t, expected, 3)
+
+	s.cfg.Mydumper.PreSplit = true
+	mdl, err = md.NewMyDumpLoader(ctx, s.cfg)
+	require.NoError(t, err)
+	tableMeta = mdl.GetDatabases()[0].Tables[0]
+	require.Equal(t, []md.FileRegion{
+		{Offset: 6, EndOffset: 24, Columns: []string{"a", "b", "c"}},
+		{Offset: 24, EndOffset: 42, Columns: []string{"a", "b", "c"}},
+	}, tableMeta.DataFiles[0].Regions)
+	require.Nil(t, tableMeta.DataFiles[1].Regions)
+
+	// the pre-split file needn't be opened again.
+	require.NoError(t, os.Remove(filepath.Join(s.sourceDir, "db.tbl.1.csv")))
+	regions, err := md.MakeTableRegions(ctx, tableMeta, 3, s.cfg, ioWorkers, mdl.GetStore())
+	require.NoError(t, err)
+	require.Len(t, regions, len(expected))
+	for i, region := range regions {
+		require.Equal(t, expected[i].FileMeta, region.FileMeta)
+		require.Equal(t, expected[i].Chunk.Offset, region.Chunk.Offset)
+		require.Equal(t, expected[i].Chunk.EndOffset, region.Chunk.EndOffset)
+		require.Equal(t, expected[i].Chunk.Columns, region.Chunk.Columns)
+		// the row IDs are estimated by the actual sizes of the regions.
+		require.Equal(t, (region.Chunk.EndOffset-region.Chunk.Offset)/3, region.Chunk.RowIDMax-region.Chunk.PrevRowIDMax)
+	}
+}
+
+func TestSourceInclude(t *testing.T) {
+	s := newTestMydumpLoaderSuite(t)
+	s.mkdir(t, "dump1")
+	s.mkdir(t, "dump2")
+	s.mkdir(t, "logs")
+	s.touch(t, "dump1", "db.tbl-schema.sql")
+	s.touch(t, "dump1", "db.tbl.1.sql")
+	s.touch(t, "dump2", "db.tbl.2.sql")
+	s.touch(t, "logs", "db.tbl.3.sql")
+
+	s.cfg.Mydumper.SourceInclude = []string{"dump1/", "dump2*"}
+	mdl, err := md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	dataFiles := mdl.GetDatabases()[0].Tables[0].DataFiles
+	require.Len(t, dataFiles, 2)
+	require.Equal(t, filepath.Join("dump1", "db.tbl.1.sql"), dataFiles[0].FileMeta.Path)
+	require.Equal(t, filepath.Join("dump2", "db.tbl.2.sql"), dataFiles[1].FileMeta.Path)
+
+	s.cfg.Mydumper.SourceInclude = []string{"logs/["}
+	_, err = md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.Regexp(t, "invalid glob", err)
+}
@@ -3,21 +3,28 @@ package mydump
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/types"
+	pcommon "github.com/xitongsys/parquet-go/common"
 	"github.com/xitongsys/parquet-go/parquet"
 	preader "github.com/xitongsys/parquet-go/reader"
 	"github.com/xitongsys/parquet-go/source"
 	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
 )
 
 const (
@@ -34,12 +41,20 @@ type ParquetParser struct {
 	Reader      *preader.ParquetReader
 	columns     []string
 	columnMetas []*parquet.SchemaElement
+	columnPaths []string
+	// skipColumns marks the columns which are not read, see SetReadColumns.
+	skipColumns []bool
 	rows        []interface{}
 	readRows    int64
 	curStart    int64
 	curIndex    int
 	lastRow     Row
 	logger      log.Logger
+
+	// rowFilter filters the rows and the row groups, see SetRowFilter.
+	rowFilter *boundRowFilter
+	// rowGroupStarts is the index of the first row of each row group.
+	rowGroupStarts []int64
 }
 
 // readerWrapper is a used for implement `source.ParquetFile`
@@ -191,10 +206,12 @@ func NewParquetParser(
 
 	columns := make([]string, 0, len(reader.Footer.Schema)-1)
 	columnMetas := make([]*parquet.SchemaElement, 0, len(reader.Footer.Schema)-1)
+	columnPaths := make([]string, 0, len(reader.Footer.Schema)-1)
 	for i, c := range reader.SchemaHandler.SchemaElements {
 		if c.GetNumChildren() == 0 {
 			// we need to use the raw name, SchemaElement.Name might be prefixed with PARGO_PERFIX_
 			columns = append(columns, strings.ToLower(reader.SchemaHandler.GetExName(i)))
+			columnPaths = append(columnPaths, reader.SchemaHandler.IndexMap[int32(i)])
 			// transfer old ConvertedType to LogicalType
 			columnMeta := c
 			if c.ConvertedType != nil && c.LogicalType == nil {
@@ -212,10 +229,124 @@ func NewParquetParser(
 		Reader:      reader,
 		columns:     columns,
 		columnMetas: columnMetas,
+		columnPaths: columnPaths,
 		logger:      log.FromContext(ctx),
 	}, nil
 }
 
+// SetReadColumns makes the parser only read the given columns from the file,
+// the values of the other columns are NULL in the parsed rows. It must be
+// called before reading any rows.
+func (pp *ParquetParser) SetReadColumns(columns []string) {
+	pp.skipColumns = make([]bool, len(pp.columns))
+	for i, col := range pp.columns {
+		if !slices.Contains(columns, col) {
+			pp.skipColumns[i] = true
+		}
+	}
+	pp.dropSkippedColumnBuffers()
+}
+
+// dropSkippedColumnBuffers removes the column buffers of the skipped columns, so
+// the reader won't read them.
+func (pp *ParquetParser) dropSkippedColumnBuffers() {
+	for i, skip := range pp.skipColumns {
+		if !skip {
+			continue
+		}
+		if cb, ok := pp.Reader.ColumnBuffers[pp.columnPaths[i]]; ok {
+			_ = cb.PFile.Close()
+			delete(pp.Reader.ColumnBuffers, pp.columnPaths[i])
+		}
+	}
+}
+
+// SetRowFilter makes the parser only return the rows satisfying the filter. The
+// row groups are skipped without being read if their min/max statistics show
+// that no rows can satisfy the filter. It must be called before reading any rows.
+func (pp *ParquetParser) SetRowFilter(filter *config.RowFilter) error {
+	if filter == nil {
+		pp.rowFilter = nil
+		return nil
+	}
+	rowFilter, err := bindRowFilter(filter, pp.columns, pp.ColumnTypes())
+	if err != nil {
+		return err
+	}
+	// the columns in the filter must be read.
+	for _, cond := range rowFilter.conds {
+		if pp.skipColumns == nil || !pp.skipColumns[cond.colIdx] {
+			continue
+		}
+		path := pp.columnPaths[cond.colIdx]
+		cb, err := preader.NewColumnBuffer(pp.Reader.PFile, pp.Reader.Footer, pp.Reader.SchemaHandler, path)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		pp.Reader.ColumnBuffers[path] = cb
+		pp.skipColumns[cond.colIdx] = false
+	}
+	pp.rowFilter = rowFilter
+	rowGroups := pp.Reader.Footer.GetRowGroups()
+	pp.rowGroupStarts = make([]int64, 0, len(rowGroups))
+	var start int64
+	for _, rg := range rowGroups {
+		pp.rowGroupStarts = append(pp.rowGroupStarts, start)
+		start += rg.GetNumRows()
+	}
+	return nil
+}
+
+// ColumnTypes returns the types of the columns, which are able to hold the
+// values parsed from the file.
+func (pp *ParquetParser) ColumnTypes() []*types.FieldType {
+	fts := make([]*types.FieldType, 0, len(pp.columnMetas))
+	for _, meta := range pp.columnMetas {
+		fts = append(fts, parquetColumnType(meta))
+	}
+	return fts
+}
+
+func parquetColumnType(meta *parquet.SchemaElement) *types.FieldType {
+	var ft *types.FieldType
+	logicalType := meta.LogicalType
+	switch {
+	case logicalType != nil && logicalType.DECIMAL != nil:
+		ft = types.NewFieldType(mysql.TypeNewDecimal)
+		ft.SetFlen(int(logicalType.DECIMAL.Precision))
+		ft.SetDecimal(int(logicalType.DECIMAL.Scale))
+	case logicalType != nil && logicalType.DATE != nil:
+		ft = types.NewFieldType(mysql.TypeDate)
+	case logicalType != nil && logicalType.TIMESTAMP != nil:
+		ft = types.NewFieldType(mysql.TypeDatetime)
+		ft.SetFlen(mysql.MaxDatetimeWidthWithFsp)
+		ft.SetDecimal(types.MaxFsp)
+	case logicalType != nil && logicalType.TIME != nil:
+		ft = types.NewFieldType(mysql.TypeDuration)
+		ft.SetFlen(mysql.MaxDurationWidthWithFsp)
+		ft.SetDecimal(types.MaxFsp)
+	case logicalType != nil && (logicalType.STRING != nil || logicalType.JSON != nil || logicalType.ENUM != nil):
+		ft = types.NewFieldTypeWithCollation(mysql.TypeLongBlob, mysql.UTF8MB4DefaultCollation, mysql.MaxLongBlobWidth)
+	default:
+		switch meta.GetType() {
+		case parquet.Type_BOOLEAN:
+			ft = types.NewFieldType(mysql.TypeTiny)
+		case parquet.Type_INT32, parquet.Type_INT64:
+			ft = types.NewFieldType(mysql.TypeLonglong)
+			if logicalType != nil && logicalType.INTEGER != nil && !logicalType.INTEGER.IsSigned {
+				ft.AddFlag(mysql.UnsignedFlag)
+			}
+		case parquet.Type_FLOAT:
+			ft = types.NewFieldType(mysql.TypeFloat)
+		case parquet.Type_DOUBLE:
+			ft = types.NewFieldType(mysql.TypeDouble)
+		default:
+			ft = types.NewFieldType(mysql.TypeLongBlob)
+		}
+	}
+	return ft
+}
+
 func convertToLogicType(se *parquet.SchemaElement) error {
 	logicalType := &parquet.LogicalType{}
 	switch *se.ConvertedType {
@@ -333,6 +464,8 @@ func (pp *ParquetParser) SetPos(pos int64, rowID int64) error {
 		if err := pp.Reader.SkipRows(pos - pp.curStart - int64(len(pp.rows))); err != nil {
 			return errors.Trace(err)
 		}
+		// SkipRows creates the buffers of all columns.
+		pp.dropSkippedColumnBuffers()
 	}
 	pp.curStart = pos
 	pp.readRows = pos
@@ -355,8 +488,28 @@ func (pp *ParquetParser) Close() error {
 // It implements the Parser interface.
 func (pp *ParquetParser) ReadRow() error {
 	pp.lastRow.RowID++
+	for {
+		if err := pp.readRow(); err != nil {
+			return err
+		}
+		if pp.rowFilter == nil {
+			return nil
+		}
+		matched, err := pp.rowFilter.matchRow(pp.lastRow.Row)
+		if err != nil || matched {
+			return err
+		}
+	}
+}
+
+func (pp *ParquetParser) readRow() error {
 	pp.lastRow.Length = 0
 	if pp.curIndex >= len(pp.rows) {
+		if pp.rowFilter != nil {
+			if err := pp.skipRowGroups(); err != nil {
+				return err
+			}
+		}
 		if pp.readRows >= pp.Reader.GetNumRows() {
 			return io.EOF
 		}
@@ -364,6 +517,12 @@ func (pp *ParquetParser) ReadRow() error {
 		if pp.Reader.GetNumRows()-pp.readRows < int64(count) {
 			count = int(pp.Reader.GetNumRows() - pp.readRows)
 		}
+		// don't read across the row groups, so the next row group can be skipped.
+		if pp.rowFilter != nil {
+			if end := pp.rowGroupEnd(pp.readRows); end-pp.readRows < int64(count) {
+				count = int(end - pp.readRows)
+			}
+		}
 
 		var err error
 		pp.rows, err = pp.Reader.ReadByNumber(count)
@@ -386,6 +545,10 @@ func (pp *ParquetParser) ReadRow() error {
 		pp.lastRow.Row = pp.lastRow.Row[:length]
 	}
 	for i := 0; i < length; i++ {
+		if pp.skipColumns != nil && pp.skipColumns[i] {
+			pp.lastRow.Row[i].SetNull()
+			continue
+		}
 		pp.lastRow.Length += getDatumLen(v.Field(i))
 		if err := setDatumValue(&pp.lastRow.Row[i], v.Field(i), pp.columnMetas[i], pp.logger); err != nil {
 			return err
@@ -394,6 +557,167 @@ func (pp *ParquetParser) ReadRow() error {
 	return nil
 }
 
+// rowGroupEnd returns the end of the row group containing the row.
+func (pp *ParquetParser) rowGroupEnd(row int64) int64 {
+	idx := sort.Search(len(pp.rowGroupStarts), func(i int) bool {
+		return pp.rowGroupStarts[i] > row
+	})
+	if idx >= len(pp.rowGroupStarts) {
+		return pp.Reader.GetNumRows()
+	}
+	return pp.rowGroupStarts[idx]
+}
+
+// skipRowGroups skips the row groups which can't contain any rows satisfying the
+// row filter if the reader is at the start of a row group.
+func (pp *ParquetParser) skipRowGroups() error {
+	idx, found := slices.BinarySearch(pp.rowGroupStarts, pp.readRows)
+	if !found {
+		return nil
+	}
+	rowGroups := pp.Reader.Footer.GetRowGroups()
+	next := idx
+	for ; next < len(rowGroups); next++ {
+		mayMatch, err := pp.rowGroupMayMatch(rowGroups[next])
+		if err != nil {
+			return err
+		}
+		if mayMatch {
+			break
+		}
+	}
+	if next == idx {
+		return nil
+	}
+	pp.logger.Debug("skip parquet row groups by row filter",
+		zap.Int("from", idx), zap.Int("to", next))
+
+	pp.rows = pp.rows[:0]
+	pp.curIndex = 0
+	if next >= len(rowGroups) {
+		pp.curStart = pp.Reader.GetNumRows()
+		pp.readRows = pp.curStart
+		return nil
+	}
+	// reset the column buffers as if they are newly created at the row group.
+	for _, cb := range pp.Reader.ColumnBuffers {
+		cb.RowGroupIndex = int64(next)
+		cb.DataTable = nil
+		cb.DataTableNumRows = -1
+		if err := cb.NextRowGroup(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	pp.curStart = pp.rowGroupStarts[next]
+	pp.readRows = pp.curStart
+	return nil
+}
+
+// rowGroupMayMatch checks the min/max statistics of the row group against the
+// row filter. It returns true if the statistics are unavailable.
+func (pp *ParquetParser) rowGroupMayMatch(rg *parquet.RowGroup) (bool, error) {
+	for _, chunk := range rg.GetColumns() {
+		colIdx := pp.columnIndexOfChunk(chunk)
+		if colIdx < 0 || !pp.rowFilter.hasCondOn(colIdx) {
+			continue
+		}
+		stats := chunk.GetMetaData().GetStatistics()
+		if stats == nil {
+			continue
+		}
+		// a NULL value never satisfies the conditions.
+		if stats.NullCount != nil && *stats.NullCount >= rg.GetNumRows() {
+			return false, nil
+		}
+		min, max, ok := pp.statisticsRange(colIdx, stats)
+		if !ok {
+			continue
+		}
+		mayMatch, err := pp.rowFilter.mayMatchRange(colIdx, &min, &max)
+		if err != nil || !mayMatch {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (pp *ParquetParser) columnIndexOfChunk(chunk *parquet.ColumnChunk) int {
+	path := make([]string, 0, len(chunk.GetMetaData().GetPathInSchema())+1)
+	path = append(path, pp.Reader.SchemaHandler.GetRootInName())
+	path = append(path, chunk.GetMetaData().GetPathInSchema()...)
+	return slices.Index(pp.columnPaths, pcommon.PathToStr(path))
+}
+
+// statisticsRange decodes the min/max values in the statistics of the column to
+// the datums parsed from the file. ok is false if the statistics can't be used.
+func (pp *ParquetParser) statisticsRange(colIdx int, stats *parquet.Statistics) (min, max types.Datum, ok bool) {
+	meta := pp.columnMetas[colIdx]
+	logicalType := meta.LogicalType
+	minBytes, maxBytes := stats.MinValue, stats.MaxValue
+	// the deprecated min/max are compared as signed values.
+	signed := logicalType == nil || logicalType.INTEGER == nil || logicalType.INTEGER.IsSigned
+	if (minBytes == nil || maxBytes == nil) && signed && meta.GetType() != parquet.Type_BYTE_ARRAY {
+		minBytes, maxBytes = stats.Min, stats.Max
+	}
+	if minBytes == nil || maxBytes == nil {
+		return min, max, false
+	}
+	if !pp.decodeStatisticsValue(&min, minBytes, meta, signed) ||
+		!pp.decodeStatisticsValue(&max, maxBytes, meta, signed) {
+		return min, max, false
+	}
+	return min, max, true
+}
+
+// decodeStatisticsValue decodes a plain encoded value in the statistics. Only
+// the types whose order of the values in the statistics are the same as the
+// order of the parsed values are supported.
+func (pp *ParquetParser) decodeStatisticsValue(d *types.Datum, b []byte, meta *parquet.SchemaElement, signed bool) bool {
+	logicalType := meta.LogicalType
+	var v reflect.Value
+	switch meta.GetType() {
+	case parquet.Type_INT32, parquet.Type_INT64:
+		if logicalType != nil && logicalType.INTEGER == nil && logicalType.DATE == nil && logicalType.DECIMAL == nil {
+			return false
+		}
+		var i uint64
+		switch len(b) {
+		case 4:
+			i = uint64(binary.LittleEndian.Uint32(b))
+			if signed {
+				i = uint64(int64(int32(i)))
+			}
+		case 8:
+			i = binary.LittleEndian.Uint64(b)
+		default:
+			return false
+		}
+		if signed {
+			v = reflect.ValueOf(int64(i))
+		} else {
+			v = reflect.ValueOf(i)
+		}
+	case parquet.Type_FLOAT:
+		if logicalType != nil || len(b) != 4 {
+			return false
+		}
+		v = reflect.ValueOf(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case parquet.Type_DOUBLE:
+		if logicalType != nil || len(b) != 8 {
+			return false
+		}
+		v = reflect.ValueOf(math.Float64frombits(binary.LittleEndian.Uint64(b)))
+	case parquet.Type_BYTE_ARRAY:
+		if logicalType == nil || (logicalType.STRING == nil && logicalType.ENUM == nil && logicalType.JSON == nil) {
+			return false
+		}
+		v = reflect.ValueOf(string(b))
+	default:
+		return false
+	}
+	return setDatumValue(d, v, meta, pp.logger) == nil
+}
+
 func getDatumLen(v reflect.Value) int {
 	if v.Kind() == reflect.Ptr {
 		if v.IsNil() {
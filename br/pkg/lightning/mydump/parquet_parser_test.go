@@ -2,11 +2,14 @@ package mydump
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"path/filepath"
 	"strconv"
 	"testing"
 
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/pingcap/tidb/br/pkg/storage"
 	"github.com/pingcap/tidb/types"
 	"github.com/stretchr/testify/assert"
@@ -231,6 +234,168 @@ func TestParquetVariousTypes(t *testing.T) {
 	assert.Equal(t, uint64(1), reader.lastRow.Row[0].GetValue())
 }
 
+func TestParquetReadColumns(t *testing.T) {
+	type Test struct {
+		S  string  `parquet:"name=s, type=UTF8, encoding=PLAIN_DICTIONARY"`
+		A  int32   `parquet:"name=a, type=INT32"`
+		U  uint32  `parquet:"name=u, type=UINT_32"`
+		D  int64   `parquet:"name=d, type=DECIMAL, scale=2, precision=18, basetype=INT64"`
+		F  float64 `parquet:"name=f, type=DOUBLE"`
+		TS int64   `parquet:"name=ts, type=TIMESTAMP_MICROS"`
+	}
+
+	dir := t.TempDir()
+	name := "test.parquet"
+	pf, err := local.NewLocalFileWriter(filepath.Join(dir, name))
+	require.NoError(t, err)
+	writer, err := writer2.NewParquetWriter(pf, new(Test), 2)
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, writer.Write(&Test{S: strconv.Itoa(i), A: int32(i), U: uint32(i), D: int64(i), F: float64(i), TS: int64(i)}))
+	}
+	require.NoError(t, writer.WriteStop())
+	require.NoError(t, pf.Close())
+
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+	r, err := store.Open(context.TODO(), name)
+	require.NoError(t, err)
+	reader, err := NewParquetParser(context.TODO(), store, r, name)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	fts := reader.ColumnTypes()
+	require.Len(t, fts, 6)
+	require.Equal(t, "longtext", fts[0].InfoSchemaStr())
+	require.Equal(t, "bigint(20)", fts[1].InfoSchemaStr())
+	require.Equal(t, "bigint(20) unsigned", fts[2].InfoSchemaStr())
+	require.Equal(t, "decimal(18,2)", fts[3].InfoSchemaStr())
+	require.Equal(t, "double", fts[4].InfoSchemaStr())
+	require.Equal(t, "datetime(6)", fts[5].InfoSchemaStr())
+
+	reader.SetReadColumns([]string{"a", "f"})
+	for i := 0; i < 100; i++ {
+		require.NoError(t, reader.ReadRow())
+		row := reader.LastRow().Row
+		require.Len(t, row, 6)
+		for j, d := range row {
+			switch j {
+			case 1:
+				require.Equal(t, int64(i), d.GetInt64())
+			case 4:
+				require.Equal(t, float64(i), d.GetFloat64())
+			default:
+				require.True(t, d.IsNull())
+			}
+		}
+	}
+	require.ErrorIs(t, reader.ReadRow(), io.EOF)
+}
+
+func TestParquetRowFilter(t *testing.T) {
+	type Test struct {
+		S string `parquet:"name=s, type=UTF8, encoding=PLAIN_DICTIONARY"`
+		A int32  `parquet:"name=a, type=INT32"`
+		D int32  `parquet:"name=d, type=DATE"`
+		N *int64 `parquet:"name=n, type=INT64, repetitiontype=OPTIONAL"`
+	}
+
+	dir := t.TempDir()
+	name := "test.parquet"
+	pf, err := local.NewLocalFileWriter(filepath.Join(dir, name))
+	require.NoError(t, err)
+	writer, err := writer2.NewParquetWriter(pf, new(Test), 2)
+	require.NoError(t, err)
+	// 10 row groups with 10 rows each
+	for i := 0; i < 100; i++ {
+		test := &Test{S: fmt.Sprintf("%03d", i), A: int32(i), D: int32(i)}
+		if i >= 50 {
+			n := int64(i)
+			test.N = &n
+		}
+		require.NoError(t, writer.Write(test))
+		if i%10 == 9 {
+			require.NoError(t, writer.Flush(true))
+		}
+	}
+	require.NoError(t, writer.WriteStop())
+	require.NoError(t, pf.Close())
+
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+
+	cases := []struct {
+		filter    string
+		rows      []int
+		rowGroups []int
+	}{
+		{"a >= 75 AND a < 85", []int{75, 76, 77, 78, 79, 80, 81, 82, 83, 84}, []int{7, 8}},
+		{"s = '042'", []int{42}, []int{4}},
+		{"d BETWEEN '1970-01-31' AND '1970-02-01'", []int{30, 31}, []int{3}},
+		{"a > 10 AND (n < 52 OR n > 98)", nil, nil},
+		{"95 < a", []int{96, 97, 98, 99}, []int{9}},
+		// the writer doesn't write the statistics of the row groups whose values are all NULL
+		{"n <= 50", []int{50}, []int{0, 1, 2, 3, 4, 5}},
+		{"a != 1", nil, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+	}
+	for _, c := range cases {
+		rowFilter, err := config.ParseRowFilter(c.filter)
+		if c.rows == nil && c.rowGroups == nil {
+			require.ErrorContains(t, err, "invalid row filter", c.filter)
+			continue
+		}
+		require.NoError(t, err, c.filter)
+
+		r, err := store.Open(context.TODO(), name)
+		require.NoError(t, err)
+		reader, err := NewParquetParser(context.TODO(), store, r, name)
+		require.NoError(t, err)
+		// the filter columns are read even if they are skipped
+		reader.SetReadColumns([]string{"a"})
+		require.NoError(t, reader.SetRowFilter(rowFilter))
+
+		rowGroups := make([]int, 0)
+		for i, rg := range reader.Reader.Footer.GetRowGroups() {
+			mayMatch, err := reader.rowGroupMayMatch(rg)
+			require.NoError(t, err)
+			if mayMatch {
+				rowGroups = append(rowGroups, i)
+			}
+		}
+		require.Equal(t, c.rowGroups, rowGroups, c.filter)
+
+		rows := make([]int, 0)
+		for {
+			err := reader.ReadRow()
+			if errors.Cause(err) == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			rows = append(rows, int(reader.LastRow().Row[1].GetInt64()))
+		}
+		if c.filter != "a != 1" {
+			require.Equal(t, c.rows, rows, c.filter)
+		} else {
+			require.Len(t, rows, 99)
+		}
+		pos, _ := reader.Pos()
+		require.Equal(t, int64(100), pos)
+		require.NoError(t, reader.Close())
+	}
+
+	r, err := store.Open(context.TODO(), name)
+	require.NoError(t, err)
+	reader, err := NewParquetParser(context.TODO(), store, r, name)
+	require.NoError(t, err)
+	defer reader.Close()
+	rowFilter, err := config.ParseRowFilter("x > 1")
+	require.NoError(t, err)
+	require.ErrorContains(t, reader.SetRowFilter(rowFilter), "unknown column 'x' in row filter")
+	rowFilter, err = config.ParseRowFilter("a > 'abc'")
+	require.NoError(t, err)
+	require.ErrorContains(t, reader.SetRowFilter(rowFilter), "cannot convert the value")
+}
+
 func TestParquetAurora(t *testing.T) {
 	store, err := storage.NewLocalStorage("examples")
 	require.NoError(t, err)
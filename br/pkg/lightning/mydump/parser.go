@@ -87,9 +87,42 @@ func makeBlockParser(
 type ChunkParser struct {
 	blockParser
 
-	escFlavor backslashEscapeFlavor
+	escFlavor        backslashEscapeFlavor
+	charsetConvertor *CharsetConvertor
+
+	// the modifier of the current INSERT statement, it's nil until the first row of a
+	// statement is read.
+	modifier *InsertModifier
+	// the raw content consumed by the lexer is captured while reading the assignments
+	// of ON DUPLICATE KEY UPDATE.
+	capturing bool
+	captured  []byte
+
+	// the rows of the current INSERT statement are read ahead if the modifiers are
+	// enabled, since ON DUPLICATE KEY UPDATE is after the rows.
+	withModifiers bool
+	ahead         []aheadRow
+	// the number of the rows at the head of ahead whose statements are fully read.
+	aheadReady int
+	aheadBytes int
+	aheadRowID int64
+	// the position and the columns of the last returned row.
+	rowPos      int64
+	lastColumns []string
 }
 
+// aheadRow is a row read ahead by the parser.
+type aheadRow struct {
+	row      Row
+	pos      int64
+	columns  []string
+	modifier *InsertModifier
+}
+
+// maxAheadBytes is the max size of the rows read ahead, the rows of a larger INSERT
+// statement are returned before its ON DUPLICATE KEY UPDATE is read.
+const maxAheadBytes = 64 * 1024 * 1024
+
 // Chunk represents a portion of the data file.
 type Chunk struct {
 	Offset       int64
@@ -104,6 +137,24 @@ type Row struct {
 	RowID  int64
 	Row    []types.Datum
 	Length int
+	// Modifier is the modifier of the INSERT statement of the row, it's only set by
+	// the ChunkParser with the modifiers enabled, and nil if there is no modifier.
+	Modifier *InsertModifier
+	// Missing records the fields absent in the row, whose columns take the default
+	// values. It's only set by the JSONParser, and nil if no field is absent. It's
+	// reused by the next row.
+	Missing []bool
+}
+
+// InsertModifier is how the INSERT statement of a row in the SQL data file handles the
+// conflict with the existing rows.
+type InsertModifier struct {
+	// Ignore is set by INSERT IGNORE.
+	Ignore bool
+	// Replace is set by REPLACE INTO.
+	Replace bool
+	// OnDuplicateUpdate is the assignments of ON DUPLICATE KEY UPDATE.
+	OnDuplicateUpdate string
 }
 
 // MarshalLogArray implements the zapcore.ArrayMarshaler interface
@@ -147,6 +198,7 @@ func NewChunkParser(
 	reader ReadSeekCloser,
 	blockBufSize int64,
 	ioWorkers *worker.Pool,
+	charsetConvertor *CharsetConvertor,
 ) *ChunkParser {
 	escFlavor := backslashEscapeFlavorMySQL
 	if sqlMode.HasNoBackslashEscapesMode() {
@@ -154,9 +206,55 @@ func NewChunkParser(
 	}
 	metrics, _ := metric.FromContext(ctx)
 	return &ChunkParser{
-		blockParser: makeBlockParser(reader, blockBufSize, ioWorkers, metrics, log.FromContext(ctx)),
-		escFlavor:   escFlavor,
+		blockParser:      makeBlockParser(reader, blockBufSize, ioWorkers, metrics, log.FromContext(ctx)),
+		escFlavor:        escFlavor,
+		charsetConvertor: charsetConvertor,
+	}
+}
+
+// EnableInsertModifiers makes the parsed rows carry the modifiers of their INSERT
+// statements, i.e. IGNORE, REPLACE and ON DUPLICATE KEY UPDATE. Since ON DUPLICATE
+// KEY UPDATE is after the rows, the rows of a statement are read ahead until the end
+// of the statement.
+func (parser *ChunkParser) EnableInsertModifiers() {
+	parser.withModifiers = true
+	parser.rowPos, parser.aheadRowID = parser.pos, parser.lastRow.RowID
+	parser.lastColumns = parser.columns
+}
+
+// SetPos changes the reported position and row ID.
+func (parser *ChunkParser) SetPos(pos int64, rowID int64) error {
+	if err := parser.blockParser.SetPos(pos, rowID); err != nil {
+		return err
+	}
+	// the statement at pos is unknown.
+	parser.modifier = nil
+	parser.capturing = false
+	parser.ahead, parser.aheadReady, parser.aheadBytes = nil, 0, 0
+	parser.rowPos, parser.aheadRowID = pos, rowID
+	return nil
+}
+
+// Pos returns the file offset after the last returned row.
+func (parser *ChunkParser) Pos() (pos int64, lastRowID int64) {
+	if parser.withModifiers {
+		return parser.rowPos, parser.lastRow.RowID
+	}
+	return parser.blockParser.Pos()
+}
+
+// Columns returns the column names of the INSERT statement of the last returned row.
+func (parser *ChunkParser) Columns() []string {
+	if parser.withModifiers {
+		return parser.lastColumns
 	}
+	return parser.columns
+}
+
+// SetColumns set restored column names to parser
+func (parser *ChunkParser) SetColumns(columns []string) {
+	parser.columns = columns
+	parser.lastColumns = columns
 }
 
 // SetPos changes the reported position and row ID.
@@ -317,29 +415,91 @@ func unescape(
 	return input
 }
 
-func (parser *ChunkParser) unescapeString(input string) string {
+func (parser *ChunkParser) unescapeString(input string) (string, error) {
+	// Convert the input from another charset to utf8mb4 before unescaping it.
+	input, err := parser.charsetConvertor.Decode(input)
+	if err != nil {
+		return "", errors.Annotatef(err, "at offset %d", parser.pos)
+	}
 	if len(input) >= 2 {
 		switch input[0] {
 		case '\'', '"':
-			return unescape(input[1:len(input)-1], input[:1], parser.escFlavor)
+			return unescape(input[1:len(input)-1], input[:1], parser.escFlavor), nil
 		case '`':
-			return unescape(input[1:len(input)-1], "`", backslashEscapeFlavorNone)
+			return unescape(input[1:len(input)-1], "`", backslashEscapeFlavorNone), nil
 		}
 	}
-	return input
+	return input, nil
 }
 
 // ReadRow reads a row from the datafile.
 func (parser *ChunkParser) ReadRow() error {
+	if !parser.withModifiers {
+		return parser.readRow()
+	}
+
+	for parser.aheadReady == 0 {
+		parser.lastRow.RowID = parser.aheadRowID
+		err := parser.readRow()
+		parser.aheadRowID = parser.lastRow.RowID
+		if err != nil {
+			if errors.Cause(err) != io.EOF || len(parser.ahead) == 0 {
+				return err
+			}
+			// the last statement ends at EOF.
+			parser.aheadReady = len(parser.ahead)
+			break
+		}
+		r := aheadRow{
+			row:      parser.lastRow,
+			pos:      parser.pos,
+			columns:  parser.columns,
+			modifier: parser.modifier,
+		}
+		if n := len(parser.ahead); n > 0 && parser.ahead[n-1].modifier != r.modifier {
+			// the row is the first one of the next statement.
+			parser.aheadReady = n
+		}
+		parser.ahead = append(parser.ahead, r)
+		parser.aheadBytes += r.row.Length
+		if parser.aheadReady == 0 && parser.aheadBytes >= maxAheadBytes {
+			parser.Logger.Warn("INSERT statement is too large to read ahead, its ON DUPLICATE KEY UPDATE is not applied to the leading rows",
+				zap.Int64("pos", parser.pos))
+			parser.aheadReady = len(parser.ahead)
+		}
+	}
+
+	r := parser.ahead[0]
+	parser.ahead[0] = aheadRow{}
+	parser.ahead = parser.ahead[1:]
+	parser.aheadReady--
+	parser.aheadBytes -= r.row.Length
+
+	parser.lastRow = r.row
+	parser.lastRow.Modifier = nil
+	if *r.modifier != (InsertModifier{}) {
+		parser.lastRow.Modifier = r.modifier
+	}
+	parser.rowPos = r.pos
+	parser.lastColumns = r.columns
+	return nil
+}
+
+func (parser *ChunkParser) readRow() error {
 	// This parser will recognize contents like:
 	//
 	// 		`tableName` (...) VALUES (...) (...) (...)
 	//
-	// Keywords like INSERT, INTO and separators like ',' and ';' are treated
+	// Keywords like INTO and separators like ',' and ';' are treated
 	// like comments and ignored. Therefore, this parser will accept some
 	// nonsense input. The advantage is the parser becomes extremely simple,
 	// suitable for us where we just want to quickly and accurately split the
 	// file apart, not to validate the content.
+	//
+	// The statements other than INSERT and REPLACE, like the SET, LOCK TABLES
+	// and DDL statements emitted by mysqldump, are skipped by their leading
+	// keywords. So is the ON DUPLICATE KEY UPDATE clause, whose content is
+	// kept in the modifier of the statement.
 
 	type state byte
 
@@ -355,6 +515,15 @@ func (parser *ChunkParser) ReadRow() error {
 
 		// the state while reading row values
 		stateRow
+
+		// the state after "INSERT" or "REPLACE" before the table name
+		stateInsert
+
+		// the state while skipping a statement
+		stateSkip
+
+		// the state while reading the assignments of "ON DUPLICATE KEY UPDATE"
+		stateOnDuplicate
 	)
 
 	// Dry-run sample of the state machine, first row:
@@ -429,16 +598,81 @@ func (parser *ChunkParser) ReadRow() error {
 	st := stateValues
 	row.Length = 0
 
+	var (
+		// "INSERT" and "REPLACE" may be the functions in the skipped content, they are
+		// only known to start a statement by the next token.
+		insertFrom state
+		insertMod  *InsertModifier
+		// the length of the captured assignments before "INSERT" or "REPLACE".
+		onDupEnd int
+	)
+
 	for {
 		tok, content, err := parser.lex()
 		if err != nil {
-			if err == io.EOF && st != stateValues {
+			if err == io.EOF {
+				switch st {
+				case stateValues, stateSkip:
+					return errors.Trace(err)
+				case stateOnDuplicate:
+					if err := parser.endOnDuplicate(len(parser.captured)); err != nil {
+						return err
+					}
+					return errors.Trace(io.EOF)
+				}
 				return errors.Errorf("syntax error: premature EOF at offset %d", parser.pos)
 			}
 			return errors.Trace(err)
 		}
 		row.Length += len(content)
 		switch st {
+		case stateInsert:
+			switch {
+			case tok == tokRowBegin && insertFrom != stateValues:
+				// it's a function call.
+				st = insertFrom
+			case tok == tokUnquoted && isInsertOption(content):
+				insertMod.Ignore = insertMod.Ignore || bytes.EqualFold(content, []byte("IGNORE"))
+			default:
+				if insertFrom == stateOnDuplicate {
+					if err := parser.endOnDuplicate(onDupEnd); err != nil {
+						return err
+					}
+				}
+				parser.columns = nil
+				parser.modifier = insertMod
+				switch tok {
+				case tokRowBegin:
+					st = stateColumns
+				case tokValues:
+					st = stateValues
+				case tokUnquoted, tokDoubleQuoted, tokBackQuoted:
+					st = stateTableName
+				default:
+					return errors.Errorf(
+						"syntax error: unexpected %s (%s) at offset %d, expecting %s",
+						tok, content, parser.pos, "table name",
+					)
+				}
+			}
+		case stateSkip:
+			if tok == tokUnquoted && isInsertKeyword(content) {
+				insertFrom, insertMod = st, newInsertModifier(content)
+				st = stateInsert
+			}
+		case stateOnDuplicate:
+			switch {
+			case tok != tokUnquoted:
+			case isInsertKeyword(content):
+				onDupEnd = len(parser.captured) - len(content)
+				insertFrom, insertMod = st, newInsertModifier(content)
+				st = stateInsert
+			case isSkippedStatement(content):
+				if err := parser.endOnDuplicate(len(parser.captured) - len(content)); err != nil {
+					return err
+				}
+				st = stateSkip
+			}
 		case stateTableName:
 			switch tok {
 			case tokRowBegin:
@@ -457,8 +691,11 @@ func (parser *ChunkParser) ReadRow() error {
 			case tokRowEnd:
 				st = stateValues
 			case tokUnquoted, tokDoubleQuoted, tokBackQuoted:
-				columnName := strings.ToLower(parser.unescapeString(string(content)))
-				parser.columns = append(parser.columns, columnName)
+				columnName, err := parser.unescapeString(string(content))
+				if err != nil {
+					return errors.Trace(err)
+				}
+				parser.columns = append(parser.columns, strings.ToLower(columnName))
 			default:
 				return errors.Errorf(
 					"syntax error: unexpected %s (%s) at offset %d, expecting %s",
@@ -468,11 +705,30 @@ func (parser *ChunkParser) ReadRow() error {
 		case stateValues:
 			switch tok {
 			case tokRowBegin:
+				if parser.modifier == nil {
+					parser.modifier = &InsertModifier{}
+				}
 				row.RowID++
 				row.Row = parser.acquireDatumSlice()
 				st = stateRow
-			case tokUnquoted, tokDoubleQuoted, tokBackQuoted:
+			case tokUnquoted:
+				switch {
+				case isInsertKeyword(content):
+					insertFrom, insertMod = st, newInsertModifier(content)
+					st = stateInsert
+				case bytes.EqualFold(content, []byte("ON")):
+					parser.capturing, parser.captured = true, parser.captured[:0]
+					st = stateOnDuplicate
+				case isSkippedStatement(content):
+					st = stateSkip
+				default:
+					parser.columns = nil
+					parser.modifier = nil
+					st = stateTableName
+				}
+			case tokDoubleQuoted, tokBackQuoted:
 				parser.columns = nil
+				parser.modifier = nil
 				st = stateTableName
 			case tokValues:
 			default:
@@ -512,7 +768,11 @@ func (parser *ChunkParser) ReadRow() error {
 				// can't handle integers more than 64 bits anyway)
 				fallthrough
 			case tokUnquoted, tokSingleQuoted, tokDoubleQuoted:
-				value.SetString(parser.unescapeString(string(content)), "utf8mb4_bin")
+				str, err := parser.unescapeString(string(content))
+				if err != nil {
+					return errors.Trace(err)
+				}
+				value.SetString(str, "utf8mb4_bin")
 			case tokHexString:
 				hexLit, err := types.ParseHexStr(string(content))
 				if err != nil {
@@ -536,6 +796,91 @@ func (parser *ChunkParser) ReadRow() error {
 	}
 }
 
+// skippedStatements are the leading keywords of the statements skipped by the parser.
+var skippedStatements = map[string]struct{}{
+	"ALTER":    {},
+	"BEGIN":    {},
+	"COMMIT":   {},
+	"CREATE":   {},
+	"DELETE":   {},
+	"DROP":     {},
+	"LOCK":     {},
+	"ROLLBACK": {},
+	"SET":      {},
+	"START":    {},
+	"TRUNCATE": {},
+	"UNLOCK":   {},
+	"USE":      {},
+}
+
+func isSkippedStatement(content []byte) bool {
+	_, ok := skippedStatements[strings.ToUpper(string(content))]
+	return ok
+}
+
+func isInsertKeyword(content []byte) bool {
+	return bytes.EqualFold(content, []byte("INSERT")) || bytes.EqualFold(content, []byte("REPLACE"))
+}
+
+func newInsertModifier(keyword []byte) *InsertModifier {
+	return &InsertModifier{Replace: bytes.EqualFold(keyword, []byte("REPLACE"))}
+}
+
+func isInsertOption(content []byte) bool {
+	for _, option := range []string{"IGNORE", "LOW_PRIORITY", "DELAYED", "HIGH_PRIORITY"} {
+		if bytes.EqualFold(content, []byte(option)) {
+			return true
+		}
+	}
+	return false
+}
+
+var onDuplicateRegexp = regexp.MustCompile(`(?is)^\s*DUPLICATE\s+KEY\s+UPDATE\b(.*)$`)
+
+// endOnDuplicate stops capturing the content after "ON", and saves the assignments in
+// the first end bytes of the captured content to the modifier of the statement.
+func (parser *ChunkParser) endOnDuplicate(end int) error {
+	parser.capturing = false
+	clause, err := parser.charsetConvertor.Decode(string(parser.captured[:end]))
+	if err != nil {
+		return errors.Annotatef(err, "at offset %d", parser.pos)
+	}
+	m := onDuplicateRegexp.FindStringSubmatch(clause)
+	if m == nil {
+		return errors.Errorf("syntax error: unexpected ON at offset %d, expecting ON DUPLICATE KEY UPDATE", parser.pos)
+	}
+	assignments := strings.TrimSpace(m[1][:statementEnd(m[1], parser.escFlavor)])
+	if len(assignments) == 0 {
+		return errors.Errorf("syntax error: empty ON DUPLICATE KEY UPDATE at offset %d", parser.pos)
+	}
+	if parser.modifier == nil {
+		parser.modifier = &InsertModifier{}
+	}
+	parser.modifier.OnDuplicateUpdate = assignments
+	return nil
+}
+
+// statementEnd returns the offset of the first unquoted ';' in s, or len(s) if there is none.
+func statementEnd(s string, escFlavor backslashEscapeFlavor) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' && escFlavor != backslashEscapeFlavorNone {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == ';':
+			return i
+		}
+	}
+	return len(s)
+}
+
 // LastRow is the copy of the row parsed by the last call to ReadRow().
 func (parser *blockParser) LastRow() Row {
 	return parser.lastRow
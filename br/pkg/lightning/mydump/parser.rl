@@ -161,6 +161,9 @@ func (parser *ChunkParser) lex() (token, []byte, error) {
 
 		if consumedToken != tokNil {
 			result := data[ts:te]
+			if parser.capturing {
+				parser.captured = append(parser.captured, data[:te]...)
+			}
 			parser.buf = data[te:]
 			parser.pos += int64(te)
 			return consumedToken, result, nil
@@ -174,6 +177,9 @@ func (parser *ChunkParser) lex() (token, []byte, error) {
 			}
 		}
 
+		if parser.capturing {
+			parser.captured = append(parser.captured, parser.buf[:ts]...)
+		}
 		parser.buf = parser.buf[ts:]
 		parser.pos += int64(ts)
 		p -= ts
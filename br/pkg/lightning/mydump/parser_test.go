@@ -31,7 +31,7 @@ import (
 
 func runTestCases(t *testing.T, mode mysql.SQLMode, blockBufSize int64, cases []testCase) {
 	for _, tc := range cases {
-		parser := mydump.NewChunkParser(context.Background(), mode, mydump.NewStringReader(tc.input), blockBufSize, ioWorkers)
+		parser := mydump.NewChunkParser(context.Background(), mode, mydump.NewStringReader(tc.input), blockBufSize, ioWorkers, nil)
 		for i, row := range tc.expected {
 			e := parser.ReadRow()
 			comment := fmt.Sprintf("input = %q, row = %d, err = %s", tc.input, i+1, errors.ErrorStack(e))
@@ -45,11 +45,28 @@ func runTestCases(t *testing.T, mode mysql.SQLMode, blockBufSize int64, cases []
 
 func runFailingTestCases(t *testing.T, mode mysql.SQLMode, blockBufSize int64, cases []string) {
 	for _, tc := range cases {
-		parser := mydump.NewChunkParser(context.Background(), mode, mydump.NewStringReader(tc), blockBufSize, ioWorkers)
+		parser := mydump.NewChunkParser(context.Background(), mode, mydump.NewStringReader(tc), blockBufSize, ioWorkers, nil)
 		assert.Regexpf(t, "syntax error.*", parser.ReadRow().Error(), "input = %q", tc)
 	}
 }
 
+func TestReadRowWithCharset(t *testing.T) {
+	// "INSERT INTO t (`名`) VALUES ('你好', x'01'), ('\xff');" in gb18030
+	reader := mydump.NewStringReader("INSERT INTO t (`\xc3\xfb`) VALUES ('\xc4\xe3\xba\xc3', x'01'), ('\xff');")
+	charsetConvertor, err := mydump.NewCharsetConvertor("gb18030", "?", config.InvalidCharError)
+	require.NoError(t, err)
+	parser := mydump.NewChunkParser(context.Background(), mysql.ModeNone, reader, int64(config.ReadBlockSize), ioWorkers, charsetConvertor)
+
+	require.NoError(t, parser.ReadRow())
+	require.Equal(t, []string{"名"}, parser.Columns())
+	require.Equal(t, []types.Datum{
+		types.NewStringDatum("你好"),
+		types.NewBinaryLiteralDatum([]byte{1}),
+	}, parser.LastRow().Row)
+
+	require.ErrorContains(t, parser.ReadRow(), "invalid gb18030 content")
+}
+
 func TestReadRow(t *testing.T) {
 	reader := mydump.NewStringReader(
 		"/* whatever pragmas */;" +
@@ -58,7 +75,7 @@ func TestReadRow(t *testing.T) {
 			"insert another_table values (10,11e1,12, '(13)', '(', 14, ')');",
 	)
 
-	parser := mydump.NewChunkParser(context.Background(), mysql.ModeNone, reader, int64(config.ReadBlockSize), ioWorkers)
+	parser := mydump.NewChunkParser(context.Background(), mysql.ModeNone, reader, int64(config.ReadBlockSize), ioWorkers, nil)
 
 	require.NoError(t, parser.ReadRow())
 	require.Equal(t, mydump.Row{
@@ -127,6 +144,69 @@ func TestReadRow(t *testing.T) {
 	require.ErrorIs(t, errors.Cause(parser.ReadRow()), io.EOF)
 }
 
+func TestReadRowWithInsertModifiers(t *testing.T) {
+	reader := mydump.NewStringReader(
+		"SET NAMES utf8mb4;\n" +
+			"DROP TABLE IF EXISTS `t`;\n" +
+			"CREATE TABLE `t` (a int);\n" +
+			"LOCK TABLES `t` WRITE;\n" +
+			"INSERT IGNORE INTO `t` VALUES (1),(2);\n" +
+			"UNLOCK TABLES;\n" +
+			"INSERT INTO t (a) VALUES (3) ON DUPLICATE KEY UPDATE a = VALUES(a) + 1, b = 'x;y';\n" +
+			"REPLACE INTO t VALUES (4);\n" +
+			"INSERT INTO t VALUES (5);\n" +
+			"COMMIT;",
+	)
+
+	parser := mydump.NewChunkParser(context.Background(), mysql.ModeNone, reader, 1, ioWorkers, nil)
+	parser.EnableInsertModifiers()
+
+	expected := []struct {
+		row      mydump.Row
+		columns  []string
+		offset   int64
+		modifier *mydump.InsertModifier
+	}{
+		{
+			row:      mydump.Row{RowID: 1, Row: []types.Datum{types.NewUintDatum(1)}, Length: 101},
+			offset:   127,
+			modifier: &mydump.InsertModifier{Ignore: true},
+		},
+		{
+			row:      mydump.Row{RowID: 2, Row: []types.Datum{types.NewUintDatum(2)}, Length: 3},
+			offset:   131,
+			modifier: &mydump.InsertModifier{Ignore: true},
+		},
+		{
+			row:      mydump.Row{RowID: 3, Row: []types.Datum{types.NewUintDatum(3)}, Length: 35},
+			columns:  []string{"a"},
+			offset:   176,
+			modifier: &mydump.InsertModifier{OnDuplicateUpdate: "a = VALUES(a) + 1, b = 'x;y'"},
+		},
+		{
+			row:      mydump.Row{RowID: 4, Row: []types.Datum{types.NewUintDatum(4)}, Length: 61},
+			offset:   256,
+			modifier: &mydump.InsertModifier{Replace: true},
+		},
+		{
+			row:    mydump.Row{RowID: 5, Row: []types.Datum{types.NewUintDatum(5)}, Length: 20},
+			offset: 282,
+		},
+	}
+	for _, e := range expected {
+		require.NoError(t, parser.ReadRow())
+		lastRow := parser.LastRow()
+		require.Equal(t, e.modifier, lastRow.Modifier)
+		lastRow.Modifier = nil
+		require.Equal(t, e.row, lastRow)
+		require.Equal(t, e.columns, parser.Columns())
+		offset, rowID := parser.Pos()
+		require.Equal(t, e.offset, offset)
+		require.Equal(t, e.row.RowID, rowID)
+	}
+	require.ErrorIs(t, errors.Cause(parser.ReadRow()), io.EOF)
+}
+
 func TestReadChunks(t *testing.T) {
 	reader := mydump.NewStringReader(`
 		INSERT foo VALUES (1,2,3,4),(5,6,7,8),(9,10,11,12);
@@ -134,7 +214,7 @@ func TestReadChunks(t *testing.T) {
 		INSERT foo VALUES (29,30,31,32),(33,34,35,36);
 	`)
 
-	parser := mydump.NewChunkParser(context.Background(), mysql.ModeNone, reader, int64(config.ReadBlockSize), ioWorkers)
+	parser := mydump.NewChunkParser(context.Background(), mysql.ModeNone, reader, int64(config.ReadBlockSize), ioWorkers, nil)
 
 	chunks, err := mydump.ReadChunks(parser, 32)
 	require.NoError(t, err)
@@ -180,7 +260,7 @@ func TestNestedRow(t *testing.T) {
 		("789",CONVERT("[]" USING UTF8MB4));
 	`)
 
-	parser := mydump.NewChunkParser(context.Background(), mysql.ModeNone, reader, int64(config.ReadBlockSize), ioWorkers)
+	parser := mydump.NewChunkParser(context.Background(), mysql.ModeNone, reader, int64(config.ReadBlockSize), ioWorkers, nil)
 	chunks, err := mydump.ReadChunks(parser, 96)
 
 	require.NoError(t, err)
@@ -413,7 +493,7 @@ func TestPseudoKeywords(t *testing.T) {
 		) VALUES ();
 	`)
 
-	parser := mydump.NewChunkParser(context.Background(), mysql.ModeNone, reader, int64(config.ReadBlockSize), ioWorkers)
+	parser := mydump.NewChunkParser(context.Background(), mysql.ModeNone, reader, int64(config.ReadBlockSize), ioWorkers, nil)
 	require.NoError(t, parser.ReadRow())
 	require.Equal(t, []string{
 		"c", "c",
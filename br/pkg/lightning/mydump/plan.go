@@ -0,0 +1,192 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+
+	"github.com/docker/go-units"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+)
+
+const (
+	// PlanFormatTable renders the import plan as human readable tables.
+	PlanFormatTable = "table"
+	// PlanFormatJSON renders the import plan as JSON.
+	PlanFormatJSON = "json"
+)
+
+// ImportPlan is what would be imported from the data source, rendered before the import
+// to validate the routing and filters.
+type ImportPlan struct {
+	Databases    []*DatabasePlan `json:"databases"`
+	TotalFiles   int             `json:"total-files"`
+	TotalSize    int64           `json:"total-size"`
+	TotalEngines int             `json:"total-engines"`
+}
+
+// DatabasePlan is the import plan of a database.
+type DatabasePlan struct {
+	Name       string       `json:"name"`
+	SchemaFile string       `json:"schema-file,omitempty"`
+	Tables     []*TablePlan `json:"tables"`
+	Views      []string     `json:"views,omitempty"`
+}
+
+// TablePlan is the import plan of a table.
+type TablePlan struct {
+	Name       string `json:"name"`
+	SchemaFile string `json:"schema-file,omitempty"`
+	FileCount  int    `json:"file-count"`
+	TotalSize  int64  `json:"total-size"`
+	// Engines is the estimated count of the engines, including the index engine.
+	Engines int        `json:"engines"`
+	Files   []FilePlan `json:"files"`
+}
+
+// FilePlan is the routing result of a data file.
+type FilePlan struct {
+	Path        string `json:"path"`
+	Type        string `json:"type"`
+	Compression string `json:"compression,omitempty"`
+	SortKey     string `json:"sort-key,omitempty"`
+	Size        int64  `json:"size"`
+}
+
+// Plan returns the import plan of the loaded data source. The engine counts are estimated
+// from the sizes of the data files without reading them, so they may differ from the actual
+// ones if the large files are split into regions.
+func (l *MDLoader) Plan(cfg *config.Config) *ImportPlan {
+	plan := &ImportPlan{Databases: make([]*DatabasePlan, 0, len(l.dbs))}
+	for _, dbMeta := range l.dbs {
+		dbPlan := &DatabasePlan{
+			Name:       dbMeta.Name,
+			SchemaFile: dbMeta.SchemaFile.FileMeta.Path,
+			Tables:     make([]*TablePlan, 0, len(dbMeta.Tables)),
+		}
+		for _, tblMeta := range dbMeta.Tables {
+			tblPlan := &TablePlan{
+				Name:       tblMeta.Name,
+				SchemaFile: tblMeta.SchemaFile.FileMeta.Path,
+				FileCount:  len(tblMeta.DataFiles),
+				TotalSize:  tblMeta.TotalSize,
+				Engines:    estimateEngineCount(cfg, tblMeta),
+				Files:      make([]FilePlan, 0, len(tblMeta.DataFiles)),
+			}
+			for _, file := range tblMeta.DataFiles {
+				tblPlan.Files = append(tblPlan.Files, FilePlan{
+					Path:        file.FileMeta.Path,
+					Type:        file.FileMeta.Type.String(),
+					Compression: file.FileMeta.Compression.String(),
+					SortKey:     file.FileMeta.SortKey,
+					Size:        file.FileMeta.FileSize,
+				})
+			}
+			dbPlan.Tables = append(dbPlan.Tables, tblPlan)
+			plan.TotalFiles += tblPlan.FileCount
+			plan.TotalSize += tblPlan.TotalSize
+			plan.TotalEngines += tblPlan.Engines
+		}
+		for _, view := range dbMeta.Views {
+			dbPlan.Views = append(dbPlan.Views, view.Name)
+		}
+		plan.Databases = append(plan.Databases, dbPlan)
+	}
+	return plan
+}
+
+// estimateEngineCount estimates the engines of the table in the same way as MakeTableRegions,
+// assuming each data file is a region.
+func estimateEngineCount(cfg *config.Config, meta *MDTableMeta) int {
+	if len(meta.DataFiles) == 0 {
+		return 0
+	}
+	regions := make([]*TableRegion, 0, len(meta.DataFiles))
+	sizes := make([]float64, 0, len(meta.DataFiles))
+	for _, file := range meta.DataFiles {
+		regions = append(regions, &TableRegion{})
+		sizes = append(sizes, float64(file.FileMeta.FileSize))
+	}
+	batchSize := float64(cfg.Mydumper.BatchSize)
+	if cfg.Mydumper.BatchSize <= 0 {
+		if meta.IsRowOrdered {
+			batchSize = float64(config.DefaultBatchSize)
+		} else {
+			batchSize = math.Max(float64(config.DefaultBatchSize), float64(meta.TotalSize))
+		}
+	}
+	AllocateEngineIDs(regions, sizes, batchSize, cfg.Mydumper.BatchImportRatio, float64(cfg.App.TableConcurrency))
+	// the data engines and the index engine.
+	return int(regions[len(regions)-1].EngineID) + 2
+}
+
+// Write renders the import plan in the given format.
+func (p *ImportPlan) Write(w io.Writer, format string) error {
+	switch format {
+	case PlanFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return errors.Trace(encoder.Encode(p))
+	case PlanFormatTable, "":
+		return errors.Trace(p.writeTable(w))
+	default:
+		return common.ErrInvalidArgument.GenWithStack("unsupported format '%s' of the import plan, should be one of '%s' and '%s'",
+			format, PlanFormatTable, PlanFormatJSON)
+	}
+}
+
+func (p *ImportPlan) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATABASE\tTABLE\tFILES\tSIZE\tENGINES\tSCHEMA FILE")
+	for _, db := range p.Databases {
+		for _, tbl := range db.Tables {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d\t%s\n", db.Name, tbl.Name, tbl.FileCount,
+				units.BytesSize(float64(tbl.TotalSize)), tbl.Engines, orDash(tbl.SchemaFile))
+		}
+		for _, view := range db.Views {
+			fmt.Fprintf(tw, "%s\t%s (view)\t-\t-\t-\t-\n", db.Name, view)
+		}
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t%d\t%s\t%d\t\n", p.TotalFiles, units.BytesSize(float64(p.TotalSize)), p.TotalEngines)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tTARGET\tTYPE\tCOMPRESSION\tKEY\tSIZE")
+	for _, db := range p.Databases {
+		for _, tbl := range db.Tables {
+			for _, file := range tbl.Files {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", file.Path, common.UniqueTable(db.Name, tbl.Name), file.Type,
+					orDash(file.Compression), orDash(file.SortKey), units.BytesSize(float64(file.Size)))
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+func orDash(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	return s
+}
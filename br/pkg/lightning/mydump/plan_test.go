@@ -0,0 +1,98 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	md "github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportPlan(t *testing.T) {
+	s := newTestMydumpLoaderSuite(t)
+	s.cfg.Mydumper.BatchSize = 100
+	s.cfg.Mydumper.BatchImportRatio = 0.75
+	s.cfg.App.TableConcurrency = 4
+
+	writeFile := func(name string, size int) {
+		err := os.WriteFile(filepath.Join(s.sourceDir, name), bytes.Repeat([]byte{'a'}, size), 0o644)
+		require.NoError(t, err)
+	}
+	s.touch(t, "db-schema-create.sql")
+	s.touch(t, "db.t1-schema.sql")
+	writeFile("db.t1.000.csv", 60)
+	writeFile("db.t1.001.csv", 60)
+	writeFile("db.t1.002.csv", 60)
+	s.touch(t, "db.t2-schema.sql")
+	writeFile("db.t2.sql", 10)
+	s.touch(t, "db.v-schema-view.sql")
+
+	mdl, err := md.NewMyDumpLoader(context.Background(), s.cfg)
+	require.NoError(t, err)
+	plan := mdl.Plan(s.cfg)
+
+	require.Equal(t, 4, plan.TotalFiles)
+	require.Equal(t, int64(190), plan.TotalSize)
+	require.Len(t, plan.Databases, 1)
+	db := plan.Databases[0]
+	require.Equal(t, "db", db.Name)
+	require.Equal(t, "db-schema-create.sql", db.SchemaFile)
+	require.Equal(t, []string{"v"}, db.Views)
+	require.Len(t, db.Tables, 2)
+
+	tables := make(map[string]*md.TablePlan)
+	for _, tbl := range db.Tables {
+		tables[tbl.Name] = tbl
+	}
+	t1 := tables["t1"]
+	require.Equal(t, "t1", t1.Name)
+	require.Equal(t, "db.t1-schema.sql", t1.SchemaFile)
+	require.Equal(t, 3, t1.FileCount)
+	require.Equal(t, int64(180), t1.TotalSize)
+	require.Greater(t, t1.Engines, 2)
+	require.Equal(t, md.FilePlan{Path: "db.t1.001.csv", Type: "csv", SortKey: "001", Size: 60}, t1.Files[1])
+	// one data engine and one index engine.
+	require.Equal(t, 2, tables["t2"].Engines)
+	require.Equal(t, t1.Engines+2, plan.TotalEngines)
+
+	var buf bytes.Buffer
+	require.NoError(t, plan.Write(&buf, md.PlanFormatJSON))
+	var decoded md.ImportPlan
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, plan, &decoded)
+
+	buf.Reset()
+	require.NoError(t, plan.Write(&buf, md.PlanFormatTable))
+	lines := strings.Split(buf.String(), "\n")
+	require.Equal(t, []string{"DATABASE", "TABLE", "FILES", "SIZE", "ENGINES", "SCHEMA", "FILE"}, strings.Fields(lines[0]))
+	rows := make(map[string][]string)
+	for _, line := range lines[1:] {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			rows[fields[0]+"/"+fields[1]] = fields
+		}
+	}
+	require.Equal(t, []string{"db", "t1", "3", "180B", "3", "db.t1-schema.sql"}, rows["db/t1"])
+	require.Equal(t, []string{"TOTAL", "4", "190B", "5"}, rows["TOTAL/4"])
+	require.Equal(t, []string{"db.t2.sql", "`db`.`t2`", "sql", "-", "-", "10B"}, rows["db.t2.sql/`db`.`t2`"])
+
+	require.ErrorContains(t, plan.Write(&buf, "yaml"), "unsupported format 'yaml'")
+}
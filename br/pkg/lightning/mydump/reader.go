@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/lightning/worker"
 	"github.com/pingcap/tidb/br/pkg/storage"
 	"go.uber.org/zap"
+	"golang.org/x/text/encoding/charmap"
 	"golang.org/x/text/encoding/simplifiedchinese"
 )
 
@@ -51,7 +52,8 @@ func decodeCharacterSet(data []byte, characterSet string) ([]byte, error) {
 		// if we support too many encodings, consider switching strategy to
 		// perform `chardet` first.
 		fallthrough
-	case "gb18030":
+	case "gb18030", "gbk":
+		// GB18030 is a superset of GBK, so the GBK files can be decoded as GB18030 as well.
 		decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
 		if err != nil {
 			return nil, errors.Trace(err)
@@ -62,6 +64,13 @@ func decodeCharacterSet(data []byte, characterSet string) ([]byte, error) {
 			return nil, errInvalidSchemaEncoding
 		}
 		data = decoded
+	case "latin1":
+		// MySQL's latin1 is actually cp1252, in which every byte is valid.
+		decoded, err := charmap.Windows1252.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		data = decoded
 	default:
 		return nil, errors.Errorf("Unsupported encoding %s", characterSet)
 	}
@@ -118,6 +127,59 @@ func ExportStatement(ctx context.Context, store storage.ExternalStorage, sqlFile
 	return data, nil
 }
 
+// ExportRoutineStatements exports the SQL statements in the schema file of the stored
+// routines or triggers. Unlike ExportStatement, the statements are split by the delimiter
+// which can be changed by the `DELIMITER` command of the mysql client, since the body of
+// a routine may contain ';'.
+func ExportRoutineStatements(ctx context.Context, store storage.ExternalStorage, sqlFile FileInfo, characterSet string) ([]string, error) {
+	content, err := store.ReadFile(ctx, sqlFile.FileMeta.Path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	content, err = decodeCharacterSet(content, characterSet)
+	if err != nil {
+		log.FromContext(ctx).Error("cannot decode input file, please convert to target encoding manually",
+			zap.String("encoding", characterSet),
+			zap.String("Path", sqlFile.FileMeta.Path),
+		)
+		return nil, errors.Annotatef(err, "failed to decode %s as %s", sqlFile.FileMeta.Path, characterSet)
+	}
+
+	var (
+		stmts     []string
+		buffer    strings.Builder
+		delimiter = ";"
+	)
+	for _, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if buffer.Len() == 0 {
+			if len(trimmed) == 0 {
+				continue
+			}
+			if fields := strings.Fields(trimmed); len(fields) == 2 && strings.EqualFold(fields[0], "DELIMITER") {
+				delimiter = fields[1]
+				continue
+			}
+		}
+		buffer.WriteString(line)
+		buffer.WriteByte('\n')
+		stmt := strings.TrimSpace(buffer.String())
+		if !strings.HasSuffix(stmt, delimiter) {
+			continue
+		}
+		buffer.Reset()
+		stmt = strings.TrimSpace(strings.TrimSuffix(stmt, delimiter))
+		if len(stmt) == 0 || (strings.HasPrefix(stmt, "/*") && strings.HasSuffix(stmt, "*/")) {
+			continue
+		}
+		stmts = append(stmts, stmt)
+	}
+	if stmt := strings.TrimSpace(buffer.String()); len(stmt) > 0 {
+		stmts = append(stmts, stmt)
+	}
+	return stmts, nil
+}
+
 // ReadSeekCloser = Reader + Seeker + Closer
 type ReadSeekCloser interface {
 	io.Reader
@@ -96,6 +96,32 @@ func exportStatmentShouldBe(t *testing.T, stmt string, expected string) {
 	require.Equal(t, []byte(expected), data)
 }
 
+func TestExportRoutineStatements(t *testing.T) {
+	store := storage.NewMemStorage()
+	content := "/*!40101 SET NAMES binary*/;\n" +
+		"DROP PROCEDURE IF EXISTS `p`;\n" +
+		"DELIMITER ;;\n" +
+		"CREATE PROCEDURE `p`()\n" +
+		"BEGIN\n" +
+		"  SELECT 1;\n" +
+		"END ;;\n" +
+		"DELIMITER ;\n" +
+		"\n" +
+		"CREATE FUNCTION `f`() RETURNS INT RETURN 1;\n" +
+		"CREATE EVENT `e` ON SCHEDULE EVERY 1 DAY DO SELECT 1"
+	require.NoError(t, store.WriteFile(context.Background(), "/db-schema-post.sql", []byte(content)))
+
+	f := FileInfo{FileMeta: SourceFileMeta{Path: "/db-schema-post.sql", FileSize: int64(len(content))}}
+	stmts, err := ExportRoutineStatements(context.Background(), store, f, "auto")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"DROP PROCEDURE IF EXISTS `p`",
+		"CREATE PROCEDURE `p`()\nBEGIN\n  SELECT 1;\nEND",
+		"CREATE FUNCTION `f`() RETURNS INT RETURN 1",
+		"CREATE EVENT `e` ON SCHEDULE EVERY 1 DAY DO SELECT 1",
+	}, stmts)
+}
+
 func TestExportStatementGBK(t *testing.T) {
 	dir := t.TempDir()
 	file, err := os.Create(filepath.Join(dir, "tidb_lightning_test_reader"))
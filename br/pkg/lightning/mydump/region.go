@@ -15,6 +15,7 @@
 package mydump
 
 import (
+	"bufio"
 	"context"
 	"io"
 	"math"
@@ -237,6 +238,10 @@ func MakeTableRegions(
 		prevRowIDMax = fileRegionsRes.regions[len(fileRegionsRes.regions)-1].Chunk.RowIDMax
 	}
 
+	if shard := cfg.Mydumper.VirtualShard; shard.Count > 1 {
+		filesRegions, dataFileSizes = ShardTableRegions(filesRegions, dataFileSizes, shard.Index, shard.Count)
+	}
+
 	batchSize := float64(cfg.Mydumper.BatchSize)
 	if cfg.Mydumper.BatchSize <= 0 {
 		if meta.IsRowOrdered {
@@ -272,31 +277,53 @@ func makeSourceFileRegion(
 		return []*TableRegion{region}, []float64{float64(fi.FileMeta.FileSize)}, nil
 	}
 
-	dataFileSize := fi.FileMeta.FileSize
 	divisor := int64(columns)
 	isCsvFile := fi.FileMeta.Type == SourceTypeCSV
 	if !isCsvFile {
 		divisor += 2
 	}
-	// If a csv file is overlarge, we need to split it into multiple regions.
-	// Note: We can only split a csv file whose format is strict.
-	// We increase the check threshold by 1/10 of the `max-region-size` because the source file size dumped by tools
-	// like dumpling might be slight exceed the threshold when it is equal `max-region-size`, so we can
-	// avoid split a lot of small chunks.
-	if isCsvFile && cfg.Mydumper.StrictFormat && dataFileSize > int64(cfg.Mydumper.MaxRegionSize+cfg.Mydumper.MaxRegionSize/largeCSVLowerThresholdRation) {
+	// the file has been split while scanning the data source.
+	if len(fi.Regions) > 0 {
+		regions, subFileSizes := makePreSplitFileRegions(meta, fi, divisor)
+		return regions, subFileSizes, nil
+	}
+	if shouldSplitLargeFile(cfg, fi) {
 		_, regions, subFileSizes, err := SplitLargeFile(ctx, meta, cfg, fi, divisor, 0, ioWorkers, store)
 		return regions, subFileSizes, err
 	}
 
+	offset, endOffset := int64(0), fi.FileMeta.FileSize
+	var csvColumns []string
+	if isCsvFile {
+		var err error
+		if offset, endOffset, err = DataRange(ctx, store, fi.FileMeta); err != nil {
+			return nil, nil, err
+		}
+		csvColumns = fi.FileMeta.Columns
+		switch {
+		case !cfg.Mydumper.CSV.Header:
+		case fi.FileMeta.SkipLines > 0:
+			// the header isn't at the beginning of the file, so it's read here instead of
+			// by the chunk restore.
+			csvColumns, offset, err = readCSVHeader(ctx, cfg, store, ioWorkers, fi.FileMeta.Path, offset)
+			if err != nil {
+				return nil, nil, err
+			}
+		default:
+			csvColumns = nil
+		}
+	}
+
 	tableRegion := &TableRegion{
 		DB:       meta.DB,
 		Table:    meta.Name,
 		FileMeta: fi.FileMeta,
 		Chunk: Chunk{
-			Offset:       0,
-			EndOffset:    fi.FileMeta.FileSize,
+			Offset:       offset,
+			EndOffset:    endOffset,
 			PrevRowIDMax: 0,
-			RowIDMax:     fi.FileMeta.FileSize / divisor,
+			RowIDMax:     (endOffset - offset) / divisor,
+			Columns:      csvColumns,
 		},
 	}
 
@@ -304,9 +331,71 @@ func makeSourceFileRegion(
 		log.FromContext(ctx).Warn(
 			"file is too big to be processed efficiently; we suggest splitting it at 256 MB each",
 			zap.String("file", fi.FileMeta.Path),
-			zap.Int64("size", dataFileSize))
+			zap.Int64("size", fi.FileMeta.FileSize))
+	}
+	return []*TableRegion{tableRegion}, []float64{float64(endOffset - offset)}, nil
+}
+
+// shouldSplitLargeFile returns whether the data file is overlarge and need to be split into multiple regions.
+// Note: We can only split a csv file whose format is strict.
+// We increase the check threshold by 1/10 of the `max-region-size` because the source file size dumped by tools
+// like dumpling might be slight exceed the threshold when it is equal `max-region-size`, so we can
+// avoid split a lot of small chunks.
+func shouldSplitLargeFile(cfg *config.Config, fi FileInfo) bool {
+	return fi.FileMeta.Type == SourceTypeCSV && cfg.Mydumper.StrictFormat &&
+		fi.FileMeta.FileSize > int64(cfg.Mydumper.MaxRegionSize+cfg.Mydumper.MaxRegionSize/largeCSVLowerThresholdRation)
+}
+
+// makePreSplitFileRegions makes the table regions of the data file from the regions found
+// when it's pre-split, without opening the file again.
+func makePreSplitFileRegions(meta *MDTableMeta, fi FileInfo, divisor int64) ([]*TableRegion, []float64) {
+	regions := make([]*TableRegion, 0, len(fi.Regions))
+	dataFileSizes := make([]float64, 0, len(fi.Regions))
+	prevRowIDMax := int64(0)
+	for _, r := range fi.Regions {
+		rowIDMax := prevRowIDMax + (r.EndOffset-r.Offset)/divisor
+		regions = append(regions, &TableRegion{
+			DB:       meta.DB,
+			Table:    meta.Name,
+			FileMeta: fi.FileMeta,
+			Chunk: Chunk{
+				Offset:       r.Offset,
+				EndOffset:    r.EndOffset,
+				PrevRowIDMax: prevRowIDMax,
+				RowIDMax:     rowIDMax,
+				Columns:      r.Columns,
+			},
+		})
+		dataFileSizes = append(dataFileSizes, float64(r.EndOffset-r.Offset))
+		prevRowIDMax = rowIDMax
+	}
+	return regions, dataFileSizes
+}
+
+// preSplitLargeFile splits the large data file into regions in the same way as SplitLargeFile
+// while scanning the data source, so the regions can be reused when making the table regions.
+func preSplitLargeFile(
+	ctx context.Context,
+	cfg *config.Config,
+	fi FileInfo,
+	ioWorkers *worker.Pool,
+	store storage.ExternalStorage,
+) ([]FileRegion, error) {
+	meta := &MDTableMeta{DB: fi.TableName.Schema, Name: fi.TableName.Name}
+	// the row IDs are allocated when making the table regions, so the divisor doesn't matter.
+	_, tableRegions, _, err := SplitLargeFile(ctx, meta, cfg, fi, 1, 0, ioWorkers, store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	regions := make([]FileRegion, 0, len(tableRegions))
+	for _, r := range tableRegions {
+		regions = append(regions, FileRegion{
+			Offset:    r.Chunk.Offset,
+			EndOffset: r.Chunk.EndOffset,
+			Columns:   r.Chunk.Columns,
+		})
 	}
-	return []*TableRegion{tableRegion}, []float64{float64(fi.FileMeta.FileSize)}, nil
+	return regions, nil
 }
 
 // because parquet files can't seek efficiently, there is no benefit in split.
@@ -341,6 +430,21 @@ func makeParquetFileRegion(
 	return rowIDMax, region, nil
 }
 
+// ShardTableRegions returns the regions of the shard `index` in `count` shards along with their sizes.
+// The regions are assigned to the shards in the round-robin way, so the regions of a huge file are
+// spread across the shards. The assignment is deterministic as long as the regions are made from
+// the same data source with the same configurations. Since the row IDs of the regions are kept,
+// the shards never allocate the same row ID.
+func ShardTableRegions(regions []*TableRegion, sizes []float64, index int, count int) ([]*TableRegion, []float64) {
+	shardRegions := make([]*TableRegion, 0, len(regions)/count+1)
+	shardSizes := make([]float64, 0, len(regions)/count+1)
+	for i := index; i < len(regions); i += count {
+		shardRegions = append(shardRegions, regions[i])
+		shardSizes = append(shardSizes, sizes[i])
+	}
+	return shardRegions, shardSizes
+}
+
 // SplitLargeFile splits a large csv file into multiple regions, the size of
 // each regions is specified by `config.MaxRegionSize`.
 // Note: We split the file coarsely, thus the format of csv file is needed to be
@@ -360,42 +464,33 @@ func SplitLargeFile(
 ) (prevRowIDMax int64, regions []*TableRegion, dataFileSizes []float64, err error) {
 	maxRegionSize := int64(cfg.Mydumper.MaxRegionSize)
 	dataFileSizes = make([]float64, 0, dataFile.FileMeta.FileSize/maxRegionSize+1)
-	startOffset, endOffset := int64(0), maxRegionSize
-	var columns []string
+	// the skipped lines of the file are excluded from the regions.
+	startOffset, dataEndOffset, err := DataRange(ctx, store, dataFile.FileMeta)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	// the columns of the headerless file are set by the file routing rule.
+	columns := dataFile.FileMeta.Columns
 	if cfg.Mydumper.CSV.Header {
-		r, err := store.Open(ctx, dataFile.FileMeta.Path)
-		if err != nil {
-			return 0, nil, nil, err
-		}
-		// Create a utf8mb4 convertor to encode and decode data with the charset of CSV files.
-		charsetConvertor, err := NewCharsetConvertor(cfg.Mydumper.DataCharacterSet, cfg.Mydumper.DataInvalidCharReplace)
+		columns, startOffset, err = readCSVHeader(ctx, cfg, store, ioWorker, dataFile.FileMeta.Path, startOffset)
 		if err != nil {
 			return 0, nil, nil, err
 		}
-		parser, err := NewCSVParser(ctx, &cfg.Mydumper.CSV, r, int64(cfg.Mydumper.ReadBlockSize), ioWorker, true, charsetConvertor)
-		if err != nil {
-			return 0, nil, nil, err
-		}
-		if err = parser.ReadColumns(); err != nil {
-			return 0, nil, nil, err
-		}
-		columns = parser.Columns()
-		startOffset, _ = parser.Pos()
-		endOffset = startOffset + maxRegionSize
-		if endOffset > dataFile.FileMeta.FileSize {
-			endOffset = dataFile.FileMeta.FileSize
-		}
+	}
+	endOffset := startOffset + maxRegionSize
+	if endOffset > dataEndOffset {
+		endOffset = dataEndOffset
 	}
 	for {
 		curRowsCnt := (endOffset - startOffset) / divisor
 		rowIDMax := prevRowIdxMax + curRowsCnt
-		if endOffset != dataFile.FileMeta.FileSize {
+		if endOffset != dataEndOffset {
 			r, err := store.Open(ctx, dataFile.FileMeta.Path)
 			if err != nil {
 				return 0, nil, nil, err
 			}
 			// Create a utf8mb4 convertor to encode and decode data with the charset of CSV files.
-			charsetConvertor, err := NewCharsetConvertor(cfg.Mydumper.DataCharacterSet, cfg.Mydumper.DataInvalidCharReplace)
+			charsetConvertor, err := NewCharsetConvertor(cfg.Mydumper.DataCharacterSet, cfg.Mydumper.DataInvalidCharReplace, cfg.Mydumper.DataInvalidCharAction)
 			if err != nil {
 				return 0, nil, nil, err
 			}
@@ -414,7 +509,11 @@ func SplitLargeFile(
 				log.FromContext(ctx).Warn("file contains no terminator at end",
 					zap.String("path", dataFile.FileMeta.Path),
 					zap.String("terminator", cfg.Mydumper.CSV.Terminator))
-				pos = dataFile.FileMeta.FileSize
+				pos = dataEndOffset
+			}
+			// the terminator may be in the skipped trailing lines.
+			if pos > dataEndOffset {
+				pos = dataEndOffset
 			}
 			endOffset = pos
 			parser.Close()
@@ -434,13 +533,124 @@ func SplitLargeFile(
 			})
 		dataFileSizes = append(dataFileSizes, float64(endOffset-startOffset))
 		prevRowIdxMax = rowIDMax
-		if endOffset == dataFile.FileMeta.FileSize {
+		if endOffset == dataEndOffset {
 			break
 		}
 		startOffset = endOffset
-		if endOffset += maxRegionSize; endOffset > dataFile.FileMeta.FileSize {
-			endOffset = dataFile.FileMeta.FileSize
+		if endOffset += maxRegionSize; endOffset > dataEndOffset {
+			endOffset = dataEndOffset
 		}
 	}
 	return prevRowIdxMax, regions, dataFileSizes, nil
 }
+
+// readCSVHeader reads the header of the CSV file starting at the offset, returns the columns
+// in the header and the offset of the first row.
+func readCSVHeader(
+	ctx context.Context,
+	cfg *config.Config,
+	store storage.ExternalStorage,
+	ioWorker *worker.Pool,
+	path string,
+	offset int64,
+) ([]string, int64, error) {
+	r, err := store.Open(ctx, path)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Create a utf8mb4 convertor to encode and decode data with the charset of CSV files.
+	charsetConvertor, err := NewCharsetConvertor(cfg.Mydumper.DataCharacterSet, cfg.Mydumper.DataInvalidCharReplace, cfg.Mydumper.DataInvalidCharAction)
+	if err != nil {
+		_ = r.Close()
+		return nil, 0, err
+	}
+	parser, err := NewCSVParser(ctx, &cfg.Mydumper.CSV, r, int64(cfg.Mydumper.ReadBlockSize), ioWorker, true, charsetConvertor)
+	if err != nil {
+		_ = r.Close()
+		return nil, 0, err
+	}
+	//nolint: errcheck
+	defer parser.Close()
+	if err = parser.SetPos(offset, 0); err != nil {
+		return nil, 0, err
+	}
+	if err = parser.ReadColumns(); err != nil {
+		return nil, 0, err
+	}
+	pos, _ := parser.Pos()
+	return parser.Columns(), pos, nil
+}
+
+// DataRange returns the range [start, end) of the data in the CSV file, which excludes the
+// leading and the trailing lines skipped by the file routing rule. The lines are separated
+// by '\n', and the range of the other files is always the whole file. The lines of the
+// compressed files can't be skipped.
+func DataRange(ctx context.Context, store storage.ExternalStorage, fileMeta SourceFileMeta) (start int64, end int64, err error) {
+	start, end = 0, fileMeta.FileSize
+	if fileMeta.Type != SourceTypeCSV || (fileMeta.SkipLines == 0 && fileMeta.SkipFooterLines == 0) {
+		return start, end, nil
+	}
+	// the offsets of the compressed file don't match the decompressed data.
+	if fileMeta.Compression != CompressionNone {
+		return 0, 0, errors.Errorf("skipping the lines of the compressed file '%s' is not supported", fileMeta.Path)
+	}
+	r, err := store.Open(ctx, fileMeta.Path)
+	if err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	//nolint: errcheck
+	defer r.Close()
+
+	reader := bufio.NewReader(r)
+	for i := 0; i < fileMeta.SkipLines && start < end; i++ {
+		for {
+			line, err := reader.ReadSlice('\n')
+			start += int64(len(line))
+			if err == nil {
+				break
+			}
+			if err == io.EOF {
+				return end, end, nil
+			}
+			if err != bufio.ErrBufferFull {
+				return 0, 0, errors.Trace(err)
+			}
+		}
+	}
+	if fileMeta.SkipFooterLines > 0 {
+		if end, err = footerOffset(r, start, end, fileMeta.SkipFooterLines); err != nil {
+			return 0, 0, errors.Trace(err)
+		}
+	}
+	return start, end, nil
+}
+
+// footerOffset returns the offset of the first one of the last n lines in [start, end) by
+// searching the line separators backwards. The separator at the end of file doesn't start
+// a new line.
+func footerOffset(r io.ReadSeeker, start int64, end int64, n int) (int64, error) {
+	const blockSize = 64 * 1024
+	buf := make([]byte, blockSize)
+	for pos := end; pos > start; {
+		size := int64(blockSize)
+		if pos-start < size {
+			size = pos - start
+		}
+		pos -= size
+		if _, err := r.Seek(pos, io.SeekStart); err != nil {
+			return 0, err
+		}
+		if _, err := io.ReadFull(r, buf[:size]); err != nil {
+			return 0, err
+		}
+		for i := size - 1; i >= 0; i-- {
+			if buf[i] != '\n' || pos+i == end-1 {
+				continue
+			}
+			if n--; n == 0 {
+				return pos + i + 1, nil
+			}
+		}
+	}
+	return start, nil
+}
@@ -278,6 +278,91 @@ func TestSplitLargeFileNoNewLineAtEOF(t *testing.T) {
 	}
 }
 
+func TestTableRegionWithRouteColumns(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Mydumper.CSV.Header = false
+	meta := &MDTableMeta{
+		DB:   "csv",
+		Name: "headerless",
+		DataFiles: []FileInfo{
+			{FileMeta: SourceFileMeta{Path: "test.csv", Type: SourceTypeCSV, FileSize: 16, Columns: []string{"b", "a"}}},
+		},
+	}
+	regions, err := MakeTableRegions(context.Background(), meta, 2, cfg, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, regions, 1)
+	require.Equal(t, []string{"b", "a"}, regions[0].Chunk.Columns)
+
+	// the columns are read from the header of the files.
+	cfg.Mydumper.CSV.Header = true
+	regions, err = MakeTableRegions(context.Background(), meta, 2, cfg, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, regions, 1)
+	require.Nil(t, regions[0].Chunk.Columns)
+}
+
+func TestTableRegionWithSkippedLines(t *testing.T) {
+	dir := t.TempDir()
+	content := []byte("REPORT 2022\nGenerated by ERP\na,b\n1,2\n3,4\nTOTAL,2\nEND\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.csv"), content, 0o644))
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+	ioWorker := worker.NewPool(context.Background(), 4, "io")
+
+	fileMeta := SourceFileMeta{
+		Path:            "test.csv",
+		Type:            SourceTypeCSV,
+		FileSize:        int64(len(content)),
+		SkipLines:       2,
+		SkipFooterLines: 2,
+	}
+	start, end, err := DataRange(context.Background(), store, fileMeta)
+	require.NoError(t, err)
+	require.Equal(t, int64(29), start)
+	require.Equal(t, int64(41), end)
+
+	// the header is read after the skipped lines.
+	cfg := config.NewConfig()
+	cfg.Mydumper.CSV.Header = true
+	meta := &MDTableMeta{DB: "csv", Name: "report", DataFiles: []FileInfo{{FileMeta: fileMeta}}}
+	regions, err := MakeTableRegions(context.Background(), meta, 2, cfg, ioWorker, store)
+	require.NoError(t, err)
+	require.Len(t, regions, 1)
+	require.Equal(t, int64(33), regions[0].Chunk.Offset)
+	require.Equal(t, int64(41), regions[0].Chunk.EndOffset)
+	require.Equal(t, []string{"a", "b"}, regions[0].Chunk.Columns)
+
+	cfg.Mydumper.MaxRegionSize = 1
+	cfg.Mydumper.StrictFormat = true
+	_, regions, _, err = SplitLargeFile(context.Background(), meta, cfg, meta.DataFiles[0], 2, 0, ioWorker, store)
+	require.NoError(t, err)
+	require.Len(t, regions, 2)
+	require.Equal(t, []int64{33, 37}, []int64{regions[0].Chunk.Offset, regions[0].Chunk.EndOffset})
+	require.Equal(t, []int64{37, 41}, []int64{regions[1].Chunk.Offset, regions[1].Chunk.EndOffset})
+	require.Equal(t, []string{"a", "b"}, regions[1].Chunk.Columns)
+
+	// the last line without the terminator is also a line.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.csv"), content[:len(content)-1], 0o644))
+	fileMeta.FileSize--
+	fileMeta.SkipLines = 3
+	start, end, err = DataRange(context.Background(), store, fileMeta)
+	require.NoError(t, err)
+	require.Equal(t, int64(33), start)
+	require.Equal(t, int64(41), end)
+
+	// all the lines are skipped.
+	fileMeta.SkipLines = 10
+	start, end, err = DataRange(context.Background(), store, fileMeta)
+	require.NoError(t, err)
+	require.Equal(t, fileMeta.FileSize, start)
+	require.Equal(t, fileMeta.FileSize, end)
+
+	// the lines of the compressed file can't be skipped.
+	fileMeta.Compression = CompressionGZ
+	_, _, err = DataRange(context.Background(), store, fileMeta)
+	require.ErrorContains(t, err, "skipping the lines of the compressed file")
+}
+
 func TestSplitLargeFileWithCustomTerminator(t *testing.T) {
 	meta := &MDTableMeta{
 		DB:   "csv",
@@ -382,3 +467,59 @@ func TestSplitLargeFileOnlyOneChunk(t *testing.T) {
 		require.Equal(t, columns, regions[i].Chunk.Columns)
 	}
 }
+
+func TestShardTableRegions(t *testing.T) {
+	filePath := "./csv/split_large_file.csv"
+	dataFileInfo, err := os.Stat(filePath)
+	require.NoError(t, err)
+	meta := &MDTableMeta{
+		DB:   "csv",
+		Name: "large_csv_file",
+		DataFiles: []FileInfo{{
+			FileMeta: SourceFileMeta{Path: filePath, Type: SourceTypeCSV, FileSize: dataFileInfo.Size()},
+		}},
+		IsRowOrdered: true,
+	}
+	cfg := &config.Config{
+		Mydumper: config.MydumperRuntime{
+			ReadBlockSize: config.ReadBlockSize,
+			CSV: config.CSVConfig{
+				Separator:       ",",
+				Header:          true,
+				Null:            "NULL",
+				BackslashEscape: true,
+			},
+			StrictFormat:  true,
+			MaxRegionSize: 1,
+		},
+		App: config.Lightning{
+			RegionConcurrency: 2,
+			TableConcurrency:  1,
+		},
+	}
+	ioWorkers := worker.NewPool(context.Background(), 4, "io")
+	store, err := storage.NewLocalStorage(".")
+	require.NoError(t, err)
+
+	all, err := MakeTableRegions(context.Background(), meta, 3, cfg, ioWorkers, store)
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+
+	// the shards are disjoint, and they cover all the regions with the same row IDs.
+	cfg.Mydumper.VirtualShard.Count = 3
+	var shardRegions []*TableRegion
+	for i := 0; i < 3; i++ {
+		cfg.Mydumper.VirtualShard.Index = i
+		regions, err := MakeTableRegions(context.Background(), meta, 3, cfg, ioWorkers, store)
+		require.NoError(t, err)
+		for _, region := range regions {
+			region.EngineID = 0
+		}
+		shardRegions = append(shardRegions, regions...)
+	}
+	for _, region := range all {
+		region.EngineID = 0
+	}
+	require.ElementsMatch(t, all, shardRegions)
+	require.Equal(t, []*TableRegion{all[1]}, shardRegions[2:3])
+}
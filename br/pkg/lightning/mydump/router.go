@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
@@ -32,6 +33,15 @@ const (
 	SourceTypeParquet
 	// SourceTypeViewSchema means this source file is a schema file for the view.
 	SourceTypeViewSchema
+	// SourceTypeJSON means this source file is a JSON Lines data file.
+	SourceTypeJSON
+	// SourceTypeSequenceSchema means this source file is a schema file for the sequence.
+	SourceTypeSequenceSchema
+	// SourceTypeProcedure means this source file is a schema file for the stored
+	// procedures, functions or events.
+	SourceTypeProcedure
+	// SourceTypeTrigger means this source file is a schema file for the triggers.
+	SourceTypeTrigger
 )
 
 const (
@@ -41,12 +51,20 @@ const (
 	TableSchema = "table-schema"
 	// ViewSchema is the source type value for schema file for view.
 	ViewSchema = "view-schema"
+	// SequenceSchema is the source type value for schema file for sequence.
+	SequenceSchema = "sequence-schema"
+	// TypeProcedure is the source type value for schema file for stored procedures, functions or events.
+	TypeProcedure = "procedure"
+	// TypeTrigger is the source type value for schema file for triggers.
+	TypeTrigger = "trigger"
 	// TypeSQL is the source type value for sql data file.
 	TypeSQL = "sql"
 	// TypeCSV is the source type value for csv data file.
 	TypeCSV = "csv"
 	// TypeParquet is the source type value for parquet data file.
 	TypeParquet = "parquet"
+	// TypeJSON is the source type value for JSON Lines data file.
+	TypeJSON = "json"
 	// TypeIgnore is the source type value for a ignored data file.
 	TypeIgnore = "ignore"
 )
@@ -79,10 +97,18 @@ func parseSourceType(t string) (SourceType, error) {
 		return SourceTypeCSV, nil
 	case TypeParquet:
 		return SourceTypeParquet, nil
+	case TypeJSON, "jsonl", "ndjson":
+		return SourceTypeJSON, nil
 	case TypeIgnore:
 		return SourceTypeIgnore, nil
 	case ViewSchema:
 		return SourceTypeViewSchema, nil
+	case SequenceSchema:
+		return SourceTypeSequenceSchema, nil
+	case TypeProcedure:
+		return SourceTypeProcedure, nil
+	case TypeTrigger:
+		return SourceTypeTrigger, nil
 	default:
 		return SourceTypeIgnore, errors.Errorf("unknown source type '%s'", t)
 	}
@@ -100,8 +126,16 @@ func (s SourceType) String() string {
 		return TypeSQL
 	case SourceTypeParquet:
 		return TypeParquet
+	case SourceTypeJSON:
+		return TypeJSON
 	case SourceTypeViewSchema:
 		return ViewSchema
+	case SourceTypeSequenceSchema:
+		return SequenceSchema
+	case SourceTypeProcedure:
+		return TypeProcedure
+	case SourceTypeTrigger:
+		return TypeTrigger
 	default:
 		return TypeIgnore
 	}
@@ -124,34 +158,64 @@ func parseCompressionType(t string) (Compression, error) {
 	}
 }
 
+func (c Compression) String() string {
+	switch c {
+	case CompressionGZ:
+		return "gz"
+	case CompressionLZ4:
+		return "lz4"
+	case CompressionZStd:
+		return "zstd"
+	case CompressionXZ:
+		return "xz"
+	default:
+		return ""
+	}
+}
+
 var expandVariablePattern = regexp.MustCompile(`\$(?:\$|[\pL\p{Nd}_]+|\{[\pL\p{Nd}_]+\})`)
 
 var defaultFileRouteRules = []*config.FileRouteRule{
-	// ignore *-schema-trigger.sql, *-schema-post.sql files
-	{Pattern: `(?i).*(-schema-trigger|-schema-post)\.sql$`, Type: "ignore"},
+	// triggers create file pattern, matches files like '{schema}.{table}-schema-triggers.sql'
+	{Pattern: `(?i)^(?:[^/]*/)*([^/.]+)\.(.*?)-schema-triggers?\.sql$`, Schema: "$1", Table: "$2", Type: TypeTrigger, Unescape: true},
+	// stored procedures, functions and events create file pattern, matches files like
+	// '{schema}.{name}-schema-post.sql' and '{schema}-schema-post.sql'
+	{Pattern: `(?i)^(?:[^/]*/)*([^/.]+)(?:\.(.*?))?-schema-post\.sql$`, Schema: "$1", Table: "$2", Type: TypeProcedure, Unescape: true},
 	// db schema create file pattern, matches files like '{schema}-schema-create.sql'
 	{Pattern: `(?i)^(?:[^/]*/)*([^/.]+)-schema-create\.sql$`, Schema: "$1", Table: "", Type: SchemaSchema, Unescape: true},
 	// table schema create file pattern, matches files like '{schema}.{table}-schema.sql'
 	{Pattern: `(?i)^(?:[^/]*/)*([^/.]+)\.(.*?)-schema\.sql$`, Schema: "$1", Table: "$2", Type: TableSchema, Unescape: true},
 	// view schema create file pattern, matches files like '{schema}.{table}-schema-view.sql'
 	{Pattern: `(?i)^(?:[^/]*/)*([^/.]+)\.(.*?)-schema-view\.sql$`, Schema: "$1", Table: "$2", Type: ViewSchema, Unescape: true},
+	// sequence schema create file pattern, matches files like '{schema}.{sequence}-schema-sequence.sql'
+	{Pattern: `(?i)^(?:[^/]*/)*([^/.]+)\.(.*?)-schema-sequence\.sql$`, Schema: "$1", Table: "$2", Type: SequenceSchema, Unescape: true},
 	// source file pattern, matches files like '{schema}.{table}.0001.{sql|csv}'
 	{Pattern: `(?i)^(?:[^/]*/)*([^/.]+)\.(.*?)(?:\.([0-9]+))?\.(sql|csv|parquet)$`, Schema: "$1", Table: "$2", Type: "$4", Key: "$3", Unescape: true},
+	// JSON Lines source file pattern, matches files like '{schema}.{table}.0001.{jsonl|ndjson}'
+	{Pattern: `(?i)^(?:[^/]*/)*([^/.]+)\.(.*?)(?:\.([0-9]+))?\.(?:jsonl|ndjson)$`, Schema: "$1", Table: "$2", Type: TypeJSON, Key: "$3", Unescape: true},
+}
+
+// RouteFileMeta is the metadata of a file provided by the storage when walking the source dir,
+// which is used by the route rules restricting the file size or the modified time.
+type RouteFileMeta struct {
+	Size int64
+	// ModTime is zero if the storage can't provide the modified time.
+	ModTime time.Time
 }
 
 // FileRouter provides some operations to apply a rule to route file path to target schema/table
 type FileRouter interface {
-	// Route apply rule to path. Return nil if path doesn't match route rule;
+	// Route apply rule to path. Return nil if path or the file meta doesn't match route rule;
 	// return error if path match route rule but the captured value for field is invalid
-	Route(path string) (*RouteResult, error)
+	Route(path string, meta RouteFileMeta) (*RouteResult, error)
 }
 
 // chainRouters aggregates multi `FileRouter` as a router
 type chainRouters []FileRouter
 
-func (c chainRouters) Route(path string) (*RouteResult, error) {
+func (c chainRouters) Route(path string, meta RouteFileMeta) (*RouteResult, error) {
 	for _, r := range c {
-		res, err := r.Route(path)
+		res, err := r.Route(path, meta)
 		if err != nil {
 			return nil, err
 		}
@@ -180,17 +244,33 @@ func NewFileRouter(cfg []*config.FileRouteRule, logger log.Logger) (FileRouter,
 // if regex pattern match, then each extractors with capture the matched regexp pattern and
 // set value to target field in `RouteResult`
 type RegexRouter struct {
-	pattern    *regexp.Regexp
-	extractors []patExpander
+	pattern      *regexp.Regexp
+	extractors   []patExpander
+	predicates   []func(meta RouteFileMeta) bool
+	characterSet string
+	columns      []string
+	// skipLines and skipFooterLines are the numbers of the leading and trailing lines to skip.
+	skipLines       int
+	skipFooterLines int
 }
 
 // Route routes a file path to a source file type.
-func (r *RegexRouter) Route(path string) (*RouteResult, error) {
+func (r *RegexRouter) Route(path string, meta RouteFileMeta) (*RouteResult, error) {
 	indexes := r.pattern.FindStringSubmatchIndex(path)
 	if len(indexes) == 0 {
 		return nil, nil
 	}
-	result := &RouteResult{}
+	for _, pred := range r.predicates {
+		if !pred(meta) {
+			return nil, nil
+		}
+	}
+	result := &RouteResult{
+		CharacterSet:    r.characterSet,
+		Columns:         r.columns,
+		SkipLines:       r.skipLines,
+		SkipFooterLines: r.skipFooterLines,
+	}
 	for _, e := range r.extractors {
 		err := e.Expand(r.pattern, path, indexes, result)
 		if err != nil {
@@ -227,6 +307,10 @@ func (p regexRouterParser) Parse(r *config.FileRouteRule, logger log.Logger) (*R
 	}
 	rule.pattern = pattern
 
+	if err := p.parsePredicates(rule, r); err != nil {
+		return nil, err
+	}
+
 	err = p.parseFieldExtractor(rule, "type", r.Type, func(result *RouteResult, value string) error {
 		ty, err := parseSourceType(value)
 		if err != nil {
@@ -303,9 +387,98 @@ func (p regexRouterParser) Parse(r *config.FileRouteRule, logger log.Logger) (*R
 		}
 	}
 
+	if len(r.CharacterSet) > 0 {
+		if _, err := decodeCharacterSet(nil, r.CharacterSet); err != nil {
+			return nil, errors.Annotate(err, "invalid `character-set` in [[mydumper.files]]")
+		}
+		rule.characterSet = r.CharacterSet
+	}
+
+	if len(r.Columns) > 0 {
+		seen := make(map[string]struct{}, len(r.Columns))
+		rule.columns = make([]string, 0, len(r.Columns))
+		for _, col := range r.Columns {
+			// column names from the data files are always in lower-case.
+			col = strings.ToLower(col)
+			if len(col) == 0 {
+				return nil, errors.New("`columns` must not contain empty column name in [[mydumper.files]]")
+			}
+			if col == SkippedColumn {
+				rule.columns = append(rule.columns, col)
+				continue
+			}
+			if _, ok := seen[col]; ok {
+				return nil, errors.Errorf("duplicated column '%s' in `columns` of [[mydumper.files]]", col)
+			}
+			seen[col] = struct{}{}
+			rule.columns = append(rule.columns, col)
+		}
+	}
+
+	if r.SkipLines < 0 || r.SkipFooterLines < 0 {
+		return nil, errors.New("`skip-lines` and `skip-footer-lines` must not be negative in [[mydumper.files]]")
+	}
+	rule.skipLines = r.SkipLines
+	rule.skipFooterLines = r.SkipFooterLines
+
 	return rule, nil
 }
 
+// parsePredicates parses the restrictions on the file size and the modified time.
+func (regexRouterParser) parsePredicates(rule *RegexRouter, r *config.FileRouteRule) error {
+	if r.MinSize < 0 || r.MaxSize < 0 {
+		return errors.New("`min-size` and `max-size` must not be negative in [[mydumper.files]]")
+	}
+	if r.MaxSize > 0 && r.MinSize > r.MaxSize {
+		return errors.New("`min-size` must not be larger than `max-size` in [[mydumper.files]]")
+	}
+	if r.MinSize > 0 {
+		minSize := int64(r.MinSize)
+		rule.predicates = append(rule.predicates, func(meta RouteFileMeta) bool {
+			return meta.Size >= minSize
+		})
+	}
+	if r.MaxSize > 0 {
+		maxSize := int64(r.MaxSize)
+		rule.predicates = append(rule.predicates, func(meta RouteFileMeta) bool {
+			return meta.Size <= maxSize
+		})
+	}
+
+	parseTime := func(field, value string) (time.Time, error) {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return t, errors.Annotatef(err, "invalid `%s` '%s' in [[mydumper.files]], it should be in RFC 3339 format", field, value)
+		}
+		return t, nil
+	}
+	var after, before time.Time
+	if len(r.ModifiedAfter) > 0 {
+		t, err := parseTime("modified-after", r.ModifiedAfter)
+		if err != nil {
+			return err
+		}
+		after = t
+		rule.predicates = append(rule.predicates, func(meta RouteFileMeta) bool {
+			return !meta.ModTime.IsZero() && meta.ModTime.After(after)
+		})
+	}
+	if len(r.ModifiedBefore) > 0 {
+		t, err := parseTime("modified-before", r.ModifiedBefore)
+		if err != nil {
+			return err
+		}
+		before = t
+		rule.predicates = append(rule.predicates, func(meta RouteFileMeta) bool {
+			return !meta.ModTime.IsZero() && meta.ModTime.Before(before)
+		})
+	}
+	if !after.IsZero() && !before.IsZero() && !after.Before(before) {
+		return errors.New("`modified-after` must be earlier than `modified-before` in [[mydumper.files]]")
+	}
+	return nil
+}
+
 // parse each field extractor in `p.r` and set them to p.rule
 func (p regexRouterParser) parseFieldExtractor(
 	rule *RegexRouter,
@@ -367,10 +540,22 @@ func (p *patExpander) Expand(pattern *regexp.Regexp, path string, matchIndex []i
 	return p.applyFn(result, string(value))
 }
 
+// SkippedColumn is the placeholder in the `columns` of the file routing rule for the fields
+// which are not imported.
+const SkippedColumn = "-"
+
 // RouteResult contains the information for a file routing.
 type RouteResult struct {
 	filter.Table
-	Key         string
-	Compression Compression
-	Type        SourceType
+	Key          string
+	Compression  Compression
+	Type         SourceType
+	CharacterSet string
+	// Columns are the lower-case column names of the headerless CSV file set by the rule,
+	// the fields named SkippedColumn are not imported.
+	Columns []string
+	// SkipLines and SkipFooterLines are the numbers of the leading and the trailing lines
+	// of the CSV file which are not data.
+	SkipLines       int
+	SkipFooterLines int
 }
@@ -3,6 +3,7 @@ package mydump
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
@@ -84,13 +85,13 @@ func TestSingleRouteRule(t *testing.T) {
 		"my_schema.my_table.0001.sql":      {"my_schema", "my_table", "0001", "", "sql"},
 	}
 	for path, fields := range inputOutputMap {
-		res, err := r.Route(path)
+		res, err := r.Route(path, RouteFileMeta{})
 		assert.NoError(t, err)
 		compress, e := parseCompressionType(fields[3])
 		assert.NoError(t, e)
 		ty, e := parseSourceType(fields[4])
 		assert.NoError(t, e)
-		exp := &RouteResult{filter.Table{Schema: fields[0], Name: fields[1]}, fields[2], compress, ty}
+		exp := &RouteResult{filter.Table{Schema: fields[0], Name: fields[1]}, fields[2], compress, ty, "", nil, 0, 0}
 		assert.Equal(t, exp, res)
 	}
 
@@ -102,7 +103,7 @@ func TestSingleRouteRule(t *testing.T) {
 		"my_schema.my_table.0001-002.sql",
 	}
 	for _, p := range notMatchPaths {
-		res, err := r.Route(p)
+		res, err := r.Route(p, RouteFileMeta{})
 		assert.Nil(t, res)
 		assert.NoError(t, err)
 	}
@@ -117,7 +118,7 @@ func TestSingleRouteRule(t *testing.T) {
 		"my_schema.my_table.txt",
 	}
 	for _, p := range invalidMatchPaths {
-		res, err := r.Route(p)
+		res, err := r.Route(p, RouteFileMeta{})
 		assert.Nil(t, res)
 		assert.Error(t, err)
 	}
@@ -146,7 +147,7 @@ func TestMultiRouteRule(t *testing.T) {
 		// "my_schema.my_table.0001.sql.gz":      {"my_schema", "my_table", "0001", "gz", "sql"},
 	}
 	for path, fields := range inputOutputMap {
-		res, err := r.Route(path)
+		res, err := r.Route(path, RouteFileMeta{})
 		assert.NoError(t, err)
 		if len(fields) == 0 {
 			assert.Nil(t, res)
@@ -155,7 +156,7 @@ func TestMultiRouteRule(t *testing.T) {
 			assert.NoError(t, e)
 			ty, e := parseSourceType(fields[4])
 			assert.NoError(t, e)
-			exp := &RouteResult{filter.Table{Schema: fields[0], Name: fields[1]}, fields[2], compress, ty}
+			exp := &RouteResult{filter.Table{Schema: fields[0], Name: fields[1]}, fields[2], compress, ty, "", nil, 0, 0}
 			assert.Equal(t, exp, res)
 		}
 	}
@@ -167,7 +168,7 @@ func TestMultiRouteRule(t *testing.T) {
 	r, err = NewFileRouter(rules, log.L())
 	require.NoError(t, err)
 	for path, fields := range inputOutputMap {
-		res, err := r.Route(path)
+		res, err := r.Route(path, RouteFileMeta{})
 		assert.NoError(t, err)
 		if len(fields) == 0 {
 			assert.Nil(t, res)
@@ -176,7 +177,7 @@ func TestMultiRouteRule(t *testing.T) {
 			assert.NoError(t, e)
 			ty, e := parseSourceType(fields[4])
 			assert.NoError(t, e)
-			exp := &RouteResult{filter.Table{Schema: fields[0], Name: fields[1]}, fields[2], compress, ty}
+			exp := &RouteResult{filter.Table{Schema: fields[0], Name: fields[1]}, fields[2], compress, ty, "", nil, 0, 0}
 			assert.Equal(t, exp, res)
 		}
 	}
@@ -212,7 +213,7 @@ func TestRouteExpanding(t *testing.T) {
 		rule.Table = pat
 		router, err := NewFileRouter([]*config.FileRouteRule{rule}, log.L())
 		assert.NoError(t, err)
-		res, err := router.Route(path)
+		res, err := router.Route(path, RouteFileMeta{})
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
 		assert.Equal(t, value, res.Name)
@@ -238,7 +239,7 @@ func TestRouteWithPath(t *testing.T) {
 	r := *rule
 	router, err := NewFileRouter([]*config.FileRouteRule{&r}, log.L())
 	require.NoError(t, err)
-	res, err := router.Route(fileName)
+	res, err := router.Route(fileName, RouteFileMeta{})
 	require.NoError(t, err)
 	require.NotNil(t, res)
 	require.Equal(t, rule.Schema, res.Schema)
@@ -248,7 +249,142 @@ func TestRouteWithPath(t *testing.T) {
 	require.Equal(t, rule.Key, res.Key)
 
 	// replace all '.' by '-', if with plain regex pattern, will still match
-	res, err = router.Route(strings.ReplaceAll(fileName, ".", "-"))
+	res, err = router.Route(strings.ReplaceAll(fileName, ".", "-"), RouteFileMeta{})
 	require.NoError(t, err)
 	require.Nil(t, res)
 }
+
+func TestDefaultRouteJSONLines(t *testing.T) {
+	r, err := NewFileRouter(defaultFileRouteRules, log.L())
+	require.NoError(t, err)
+
+	inputOutputMap := map[string][]string{
+		"my_schema.my_table.jsonl":         {"my_schema", "my_table", "", TypeJSON},
+		"my_schema.my_table.0001.ndjson":   {"my_schema", "my_table", "0001", TypeJSON},
+		"dir/my_schema.my_table.01.JSONL":  {"my_schema", "my_table", "01", TypeJSON},
+		"my_schema.my_table.000.csv":       {"my_schema", "my_table", "000", TypeCSV},
+		"my_schema.my_table.json.schema":   nil,
+		"my_schema.my_table.0001.ndjsonxx": nil,
+	}
+	for path, fields := range inputOutputMap {
+		res, err := r.Route(path, RouteFileMeta{})
+		require.NoError(t, err)
+		if len(fields) == 0 {
+			require.Nil(t, res, path)
+			continue
+		}
+		require.NotNil(t, res, path)
+		require.Equal(t, fields, []string{res.Schema, res.Name, res.Key, res.Type.String()}, path)
+	}
+}
+
+func TestDefaultRouteSchemaObjects(t *testing.T) {
+	r, err := NewFileRouter(defaultFileRouteRules, log.L())
+	require.NoError(t, err)
+
+	inputOutputMap := map[string][]string{
+		"my_schema.my_seq-schema-sequence.sql":      {"my_schema", "my_seq", SequenceSchema},
+		"my_schema.my_table-schema-triggers.sql":    {"my_schema", "my_table", TypeTrigger},
+		"dir/my_schema.my_table-schema-trigger.sql": {"my_schema", "my_table", TypeTrigger},
+		"my_schema.my_proc-schema-post.sql":         {"my_schema", "my_proc", TypeProcedure},
+		"my_schema-schema-post.sql":                 {"my_schema", "", TypeProcedure},
+		"my_schema.my_view-schema-view.sql":         {"my_schema", "my_view", ViewSchema},
+	}
+	for path, fields := range inputOutputMap {
+		res, err := r.Route(path, RouteFileMeta{})
+		require.NoError(t, err)
+		require.NotNil(t, res, path)
+		require.Equal(t, fields, []string{res.Schema, res.Name, res.Type.String()}, path)
+	}
+}
+
+func TestRouteByFileMeta(t *testing.T) {
+	pattern := `^(?:[^/]*/)*([^/.]+)\.([^./]+)\.(csv|sql)$`
+	modTime := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	rules := []*config.FileRouteRule{
+		{Pattern: pattern, Schema: "$1", Table: "$2", Type: "ignore", MaxSize: 10},
+		{Pattern: pattern, Schema: "$1", Table: "$2", Type: "$3", ModifiedAfter: "2022-07-01T00:00:00Z", ModifiedBefore: "2022-09-01T08:00:00+08:00"},
+		{Pattern: pattern, Schema: "old", Table: "$2", Type: "$3", MinSize: 100},
+	}
+	r, err := NewFileRouter(rules, log.L())
+	require.NoError(t, err)
+
+	res, err := r.Route("db.tbl.csv", RouteFileMeta{Size: 10, ModTime: modTime})
+	require.NoError(t, err)
+	require.Equal(t, SourceTypeIgnore, res.Type)
+
+	res, err = r.Route("db.tbl.csv", RouteFileMeta{Size: 11, ModTime: modTime})
+	require.NoError(t, err)
+	require.Equal(t, &RouteResult{filter.Table{Schema: "db", Name: "tbl"}, "", CompressionNone, SourceTypeCSV, "", nil, 0, 0}, res)
+
+	for _, tm := range []time.Time{{}, modTime.AddDate(0, -2, 0), modTime.AddDate(0, 1, 0)} {
+		res, err = r.Route("db.tbl.sql", RouteFileMeta{Size: 100, ModTime: tm})
+		require.NoError(t, err)
+		require.Equal(t, &RouteResult{filter.Table{Schema: "old", Name: "tbl"}, "", CompressionNone, SourceTypeSQL, "", nil, 0, 0}, res)
+
+		res, err = r.Route("db.tbl.sql", RouteFileMeta{Size: 99, ModTime: tm})
+		require.NoError(t, err)
+		require.Nil(t, res)
+	}
+
+	invalidRules := []struct {
+		rule config.FileRouteRule
+		err  string
+	}{
+		{config.FileRouteRule{MinSize: -1}, "`min-size` and `max-size` must not be negative"},
+		{config.FileRouteRule{MinSize: 10, MaxSize: 9}, "`min-size` must not be larger than `max-size`"},
+		{config.FileRouteRule{ModifiedAfter: "2022-08-01"}, "invalid `modified-after` '2022-08-01'"},
+		{config.FileRouteRule{ModifiedBefore: "yesterday"}, "invalid `modified-before` 'yesterday'"},
+		{config.FileRouteRule{ModifiedAfter: "2022-08-01T00:00:00Z", ModifiedBefore: "2022-08-01T08:00:00+08:00"}, "`modified-after` must be earlier than `modified-before`"},
+		{config.FileRouteRule{CharacterSet: "ebcdic"}, "invalid `character-set` in [[mydumper.files]]"},
+		{config.FileRouteRule{Columns: []string{"a", ""}}, "`columns` must not contain empty column name"},
+		{config.FileRouteRule{Columns: []string{"a", "b", "A"}}, "duplicated column 'a' in `columns`"},
+		{config.FileRouteRule{SkipFooterLines: -1}, "`skip-lines` and `skip-footer-lines` must not be negative"},
+	}
+	for _, c := range invalidRules {
+		rule := c.rule
+		rule.Pattern, rule.Schema, rule.Table, rule.Type = pattern, "$1", "$2", "$3"
+		_, err := NewFileRouter([]*config.FileRouteRule{&rule}, log.L())
+		require.ErrorContains(t, err, c.err)
+	}
+}
+
+func TestRouteWithCharacterSet(t *testing.T) {
+	rules := []*config.FileRouteRule{
+		{Pattern: `^(?:[^/]*/)*old\.([^./]+)-schema\.sql$`, Schema: "old", Table: "$1", Type: "table-schema", CharacterSet: "latin1"},
+		{Pattern: `^(?:[^/]*/)*([^/.]+)\.([^./]+)-schema\.sql$`, Schema: "$1", Table: "$2", Type: "table-schema"},
+	}
+	r, err := NewFileRouter(rules, log.L())
+	require.NoError(t, err)
+
+	res, err := r.Route("old.tbl-schema.sql", RouteFileMeta{})
+	require.NoError(t, err)
+	require.Equal(t, &RouteResult{filter.Table{Schema: "old", Name: "tbl"}, "", CompressionNone, SourceTypeTableSchema, "latin1", nil, 0, 0}, res)
+
+	res, err = r.Route("new.tbl-schema.sql", RouteFileMeta{})
+	require.NoError(t, err)
+	require.Equal(t, &RouteResult{filter.Table{Schema: "new", Name: "tbl"}, "", CompressionNone, SourceTypeTableSchema, "", nil, 0, 0}, res)
+}
+
+func TestRouteWithColumns(t *testing.T) {
+	rules := []*config.FileRouteRule{
+		{Pattern: `^(?:[^/]*/)*old\.([^./]+)\.csv$`, Schema: "old", Table: "$1", Type: "csv", Columns: []string{"ID", "name"}},
+		{Path: "erp.tbl.csv", Schema: "erp", Table: "tbl", Type: "csv", Columns: []string{"-", "Name", "-", "id"}},
+		{Pattern: `^(?:[^/]*/)*([^/.]+)\.([^./]+)\.csv$`, Schema: "$1", Table: "$2", Type: "csv"},
+	}
+	r, err := NewFileRouter(rules, log.L())
+	require.NoError(t, err)
+
+	res, err := r.Route("old.tbl.csv", RouteFileMeta{})
+	require.NoError(t, err)
+	require.Equal(t, &RouteResult{filter.Table{Schema: "old", Name: "tbl"}, "", CompressionNone, SourceTypeCSV, "", []string{"id", "name"}, 0, 0}, res)
+
+	// the fields of the placeholder are skipped.
+	res, err = r.Route("erp.tbl.csv", RouteFileMeta{})
+	require.NoError(t, err)
+	require.Equal(t, []string{SkippedColumn, "name", SkippedColumn, "id"}, res.Columns)
+
+	res, err = r.Route("new.tbl.csv", RouteFileMeta{})
+	require.NoError(t, err)
+	require.Equal(t, &RouteResult{filter.Table{Schema: "new", Name: "tbl"}, "", CompressionNone, SourceTypeCSV, "", nil, 0, 0}, res)
+}
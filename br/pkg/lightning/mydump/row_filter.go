@@ -0,0 +1,152 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/collate"
+	"golang.org/x/exp/slices"
+)
+
+// boundRowFilterCond is a filter condition bound to a column of the source file.
+type boundRowFilterCond struct {
+	colIdx int
+	op     opcode.Op
+	// value is converted to the type of the column.
+	value types.Datum
+}
+
+type boundRowFilter struct {
+	conds []boundRowFilterCond
+	sc    *stmtctx.StatementContext
+}
+
+// bindRowFilter binds the conditions of the filter to the columns of the source file,
+// whose types are given in `fts`.
+func bindRowFilter(f *config.RowFilter, columns []string, fts []*types.FieldType) (*boundRowFilter, error) {
+	sc := &stmtctx.StatementContext{TimeZone: f.TimeZone()}
+	conds := f.Conds()
+	bf := &boundRowFilter{conds: make([]boundRowFilterCond, 0, len(conds)), sc: sc}
+	for _, cond := range conds {
+		colIdx := slices.Index(columns, cond.Column)
+		if colIdx < 0 {
+			return nil, common.ErrInvalidConfig.GenWithStack("unknown column '%s' in row filter", cond.Column)
+		}
+		value, err := cond.Value.ConvertTo(sc, fts[colIdx])
+		if err != nil {
+			return nil, common.ErrInvalidConfig.Wrap(err).GenWithStack(
+				"cannot convert the value '%s' of column '%s' in row filter", cond.Value.String(), cond.Column)
+		}
+		bf.conds = append(bf.conds, boundRowFilterCond{colIdx: colIdx, op: cond.Op, value: value})
+	}
+	return bf, nil
+}
+
+// matchRow returns whether the row satisfies all the conditions. A row whose
+// value is NULL never satisfies the condition on the column.
+func (f *boundRowFilter) matchRow(row []types.Datum) (bool, error) {
+	for _, cond := range f.conds {
+		d := &row[cond.colIdx]
+		if d.IsNull() {
+			return false, nil
+		}
+		// the value is parsed from the source file, so compare in the reversed order
+		// to convert it into the type of the constant.
+		cmp, err := cond.value.Compare(f.sc, d, collate.GetBinaryCollator())
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if !compareResultMatches(-cmp, cond.op) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// mayMatchRange returns whether the rows whose value of column `colIdx` are in
+// [min, max] may satisfy the conditions on the column.
+func (f *boundRowFilter) mayMatchRange(colIdx int, min, max *types.Datum) (bool, error) {
+	collator := collate.GetBinaryCollator()
+	for _, cond := range f.conds {
+		if cond.colIdx != colIdx {
+			continue
+		}
+		// cmpMin and cmpMax are the results of comparing the min and max value with the constant.
+		cmpMin, err := cond.value.Compare(f.sc, min, collator)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		cmpMin = -cmpMin
+		cmpMax, err := cond.value.Compare(f.sc, max, collator)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		cmpMax = -cmpMax
+
+		var mayMatch bool
+		switch cond.op {
+		case opcode.EQ:
+			mayMatch = cmpMin <= 0 && cmpMax >= 0
+		case opcode.NE:
+			mayMatch = cmpMin != 0 || cmpMax != 0
+		case opcode.LT:
+			mayMatch = cmpMin < 0
+		case opcode.LE:
+			mayMatch = cmpMin <= 0
+		case opcode.GT:
+			mayMatch = cmpMax > 0
+		case opcode.GE:
+			mayMatch = cmpMax >= 0
+		}
+		if !mayMatch {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// hasCondOn returns whether there is any condition on the column.
+func (f *boundRowFilter) hasCondOn(colIdx int) bool {
+	for _, cond := range f.conds {
+		if cond.colIdx == colIdx {
+			return true
+		}
+	}
+	return false
+}
+
+func compareResultMatches(cmp int, op opcode.Op) bool {
+	switch op {
+	case opcode.EQ:
+		return cmp == 0
+	case opcode.NE:
+		return cmp != 0
+	case opcode.LT:
+		return cmp < 0
+	case opcode.LE:
+		return cmp <= 0
+	case opcode.GT:
+		return cmp > 0
+	case opcode.GE:
+		return cmp >= 0
+	default:
+		return false
+	}
+}
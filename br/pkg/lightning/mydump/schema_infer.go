@@ -0,0 +1,428 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/lightning/worker"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/util/mathutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// inferSchemaSampleFiles is the max number of files sampled to infer the schema of a table.
+	inferSchemaSampleFiles = 16
+	// inferSchemaSampleSegments is the number of the evenly spaced segments of a file the rows
+	// are sampled from, if the rows can be located from an arbitrary offset by `strict-format`.
+	inferSchemaSampleSegments = 8
+)
+
+const (
+	inferredVarcharLength = 255
+	// maxInferredTextLength is the max length of TEXT in characters of utf8mb4.
+	maxInferredTextLength = 16383
+)
+
+// inferSchemaFiles returns the data files used to infer the schema of the table, or nil
+// if the schema can't be inferred.
+func (m *MDTableMeta) inferSchemaFiles() []FileInfo {
+	if m.inferSchemaCfg == nil {
+		return nil
+	}
+	var files []FileInfo
+	for _, file := range m.DataFiles {
+		if file.FileMeta.Type == SourceTypeCSV && file.FileMeta.Compression == CompressionNone {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// inferSchema samples the rows of the CSV files and synthesizes the table-creating SQL. The
+// rows are sampled evenly from the files, so that the values not in the first rows are also
+// taken into account.
+func (m *MDTableMeta) inferSchema(ctx context.Context, store storage.ExternalStorage, files []FileInfo) (string, error) {
+	var (
+		columns   []string
+		inferrers []*columnTypeInferrer
+		sampled   int
+	)
+	if len(files) > inferSchemaSampleFiles {
+		picked := make([]FileInfo, 0, inferSchemaSampleFiles)
+		for i := 0; i < inferSchemaSampleFiles; i++ {
+			picked = append(picked, files[i*len(files)/inferSchemaSampleFiles])
+		}
+		files = picked
+	}
+	sampleRows := m.inferSchemaCfg.CSV.InferSchemaSampleRows
+	ioWorkers := worker.NewPool(ctx, 1, "infer-schema")
+	for i, file := range files {
+		if sampled >= sampleRows {
+			break
+		}
+		// the rows not sampled from the small files are sampled from the following ones.
+		n := divideCeil(sampleRows-sampled, len(files)-i)
+		err := m.sampleCSVFile(ctx, store, file, ioWorkers, n, func(row []string, isNull []bool) {
+			for i, value := range row {
+				if !isNull[i] {
+					inferrers[i].sample(value)
+				}
+			}
+			sampled++
+		}, func(fileColumns []string) error {
+			if columns == nil {
+				if err := checkInferredColumns(fileColumns); err != nil {
+					return err
+				}
+				columns = fileColumns
+				inferrers = make([]*columnTypeInferrer, 0, len(columns))
+				for range columns {
+					inferrers = append(inferrers, newColumnTypeInferrer())
+				}
+				return nil
+			}
+			if strings.Join(fileColumns, ",") != strings.Join(columns, ",") {
+				return errors.Errorf("the columns %v are different from the columns %v of the other files", fileColumns, columns)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", errors.Annotatef(err, "failed to infer the schema of the table '%s.%s' from file '%s'", m.DB, m.Name, file.FileMeta.Path)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE ")
+	sb.WriteString(common.EscapeIdentifier(m.Name))
+	sb.WriteString(" (")
+	for i, column := range columns {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n  ")
+		sb.WriteString(common.EscapeIdentifier(column))
+		sb.WriteString(" ")
+		sb.WriteString(inferrers[i].columnType())
+	}
+	sb.WriteString("\n);")
+	schema := sb.String()
+	log.FromContext(ctx).Info("inferred the table schema from the data files",
+		zap.String("table", common.UniqueTable(m.DB, m.Name)),
+		zap.Int("sampledRows", sampled),
+		zap.String("schema", schema),
+	)
+	return schema, nil
+}
+
+func divideCeil(a, b int) int {
+	return (a + b - 1) / b
+}
+
+// sampleCSVFile reads at most n rows from the CSV file, the columns of the file are passed
+// to onColumns before any row is passed to onRow. If `strict-format` is set, the rows are
+// read from the evenly spaced segments of the file, otherwise from the beginning of it.
+func (m *MDTableMeta) sampleCSVFile(
+	ctx context.Context,
+	store storage.ExternalStorage,
+	file FileInfo,
+	ioWorkers *worker.Pool,
+	n int,
+	onRow func(row []string, isNull []bool),
+	onColumns func(columns []string) error,
+) error {
+	start, end, err := DataRange(ctx, store, file.FileMeta)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	parser, err := m.openCSVParser(ctx, store, file, ioWorkers, start)
+	if err != nil {
+		return err
+	}
+	if err := parser.ReadColumns(); err != nil {
+		_ = parser.Close()
+		return errors.Trace(err)
+	}
+	columns := parser.Columns()
+	if err := onColumns(columns); err != nil {
+		_ = parser.Close()
+		return err
+	}
+	start, _ = parser.Pos()
+
+	segments := 1
+	if m.inferSchemaCfg.StrictFormat {
+		segments = mathutil.Min(inferSchemaSampleSegments, n)
+	}
+	segmentSize := (end - start) / int64(segments)
+	if segmentSize == 0 {
+		segments, segmentSize = 1, end-start
+	}
+	row := make([]string, len(columns))
+	isNull := make([]bool, len(columns))
+	for i := 0; i < segments && n > 0; i++ {
+		segmentEnd := start + int64(i+1)*segmentSize
+		if i == segments-1 {
+			segmentEnd = end
+		}
+		if i > 0 {
+			// the segment starts from the row following its first terminator, like the
+			// regions of the large files split by SplitLargeFile.
+			if parser, err = m.openCSVParser(ctx, store, file, ioWorkers, start+int64(i)*segmentSize); err != nil {
+				return err
+			}
+			if _, err := parser.ReadUntilTerminator(); err != nil {
+				_ = parser.Close()
+				if errors.Cause(err) == io.EOF {
+					break
+				}
+				return errors.Trace(err)
+			}
+		}
+		read, err := sampleCSVRows(parser, segmentEnd, divideCeil(n, segments-i), row, isNull, onRow)
+		_ = parser.Close()
+		if err != nil {
+			return err
+		}
+		n -= read
+	}
+	return nil
+}
+
+func (m *MDTableMeta) openCSVParser(
+	ctx context.Context,
+	store storage.ExternalStorage,
+	file FileInfo,
+	ioWorkers *worker.Pool,
+	pos int64,
+) (*CSVParser, error) {
+	cfg := m.inferSchemaCfg
+	reader, err := store.Open(ctx, file.FileMeta.Path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	charsetConvertor, err := NewCharsetConvertor(cfg.DataCharacterSet, cfg.DataInvalidCharReplace, cfg.DataInvalidCharAction)
+	if err != nil {
+		_ = reader.Close()
+		return nil, errors.Trace(err)
+	}
+	parser, err := NewCSVParser(ctx, &cfg.CSV, reader, int64(cfg.ReadBlockSize), ioWorkers, false, charsetConvertor)
+	if err != nil {
+		_ = reader.Close()
+		return nil, errors.Trace(err)
+	}
+	if err := parser.SetPos(pos, 0); err != nil {
+		_ = parser.Close()
+		return nil, errors.Trace(err)
+	}
+	return parser, nil
+}
+
+// sampleCSVRows reads at most n rows which start before end, and returns the number of them.
+func sampleCSVRows(parser *CSVParser, end int64, n int, row []string, isNull []bool, onRow func(row []string, isNull []bool)) (int, error) {
+	for i := 0; i < n; i++ {
+		if pos, _ := parser.Pos(); pos >= end {
+			return i, nil
+		}
+		err := parser.ReadRow()
+		if errors.Cause(err) == io.EOF {
+			return i, nil
+		}
+		if err != nil {
+			return i, errors.Trace(err)
+		}
+		lastRow := parser.LastRow()
+		if len(lastRow.Row) != len(row) {
+			return i, errors.Errorf("row %d has %d fields, but the header has %d columns", lastRow.RowID, len(lastRow.Row), len(row))
+		}
+		for j, datum := range lastRow.Row {
+			isNull[j] = datum.IsNull()
+			row[j] = ""
+			if !isNull[j] {
+				row[j] = datum.GetString()
+			}
+		}
+		onRow(row, isNull)
+		parser.RecycleRow(lastRow)
+	}
+	return n, nil
+}
+
+func checkInferredColumns(columns []string) error {
+	if len(columns) == 0 {
+		return errors.New("the header is empty")
+	}
+	names := make(map[string]struct{}, len(columns))
+	for _, column := range columns {
+		if len(column) == 0 {
+			return errors.New("the header contains an empty column name")
+		}
+		if _, ok := names[column]; ok {
+			return errors.Errorf("the header contains duplicated column '%s'", column)
+		}
+		names[column] = struct{}{}
+	}
+	return nil
+}
+
+// columnTypeInferrer narrows down the type of a column by the sampled non-NULL values.
+// The candidates are tried in the order of BIGINT, BIGINT UNSIGNED, DECIMAL, DATE,
+// DATETIME and VARCHAR/TEXT.
+type columnTypeInferrer struct {
+	sampled bool
+
+	maybeInt      bool
+	maybeUint     bool
+	maybeDecimal  bool
+	intDigits     int
+	fracDigits    int
+	maybeDate     bool
+	maybeDatetime bool
+	fsp           int
+	// maxLength is the max length of the values in characters.
+	maxLength int
+}
+
+func newColumnTypeInferrer() *columnTypeInferrer {
+	return &columnTypeInferrer{
+		maybeInt:      true,
+		maybeUint:     true,
+		maybeDecimal:  true,
+		maybeDate:     true,
+		maybeDatetime: true,
+	}
+}
+
+func (c *columnTypeInferrer) sample(value string) {
+	c.sampled = true
+	if length := utf8.RuneCountInString(value); length > c.maxLength {
+		c.maxLength = length
+	}
+
+	intDigits, fracDigits, isDecimal := parseDecimalDigits(value)
+	if c.maybeDecimal {
+		c.maybeDecimal = isDecimal
+		c.intDigits = mathutil.Max(c.intDigits, intDigits)
+		c.fracDigits = mathutil.Max(c.fracDigits, fracDigits)
+		if c.intDigits+c.fracDigits > mysql.MaxDecimalWidth || c.fracDigits > mysql.MaxDecimalScale {
+			c.maybeDecimal = false
+		}
+	}
+	if c.maybeInt {
+		_, err := strconv.ParseInt(value, 10, 64)
+		c.maybeInt = isDecimal && err == nil
+	}
+	if c.maybeUint {
+		_, err := strconv.ParseUint(value, 10, 64)
+		c.maybeUint = isDecimal && err == nil
+	}
+	if c.maybeDate {
+		_, err := time.Parse("2006-01-02", value)
+		c.maybeDate = err == nil
+	}
+	if c.maybeDatetime {
+		c.maybeDatetime = c.sampleDatetime(value)
+	}
+}
+
+func (c *columnTypeInferrer) sampleDatetime(value string) bool {
+	if _, err := time.Parse("2006-01-02", value); err == nil {
+		return true
+	}
+	layout := "2006-01-02 15:04:05"
+	if strings.IndexByte(value, 'T') >= 0 {
+		layout = "2006-01-02T15:04:05"
+	}
+	// fractional seconds are accepted by time.Parse even if the layout doesn't have them.
+	if _, err := time.Parse(layout, value); err != nil {
+		return false
+	}
+	if idx := strings.IndexByte(value, '.'); idx >= 0 {
+		fsp := len(value) - idx - 1
+		if fsp > 6 {
+			return false
+		}
+		c.fsp = mathutil.Max(c.fsp, fsp)
+	}
+	return true
+}
+
+func (c *columnTypeInferrer) columnType() string {
+	switch {
+	case !c.sampled:
+		return fmt.Sprintf("VARCHAR(%d)", inferredVarcharLength)
+	case c.maybeInt:
+		return "BIGINT"
+	case c.maybeUint:
+		return "BIGINT UNSIGNED"
+	case c.maybeDecimal:
+		return fmt.Sprintf("DECIMAL(%d,%d)", mathutil.Max(c.intDigits+c.fracDigits, 1), c.fracDigits)
+	case c.maybeDate:
+		return "DATE"
+	case c.maybeDatetime && c.fsp > 0:
+		return fmt.Sprintf("DATETIME(%d)", c.fsp)
+	case c.maybeDatetime:
+		return "DATETIME"
+	case c.maxLength <= inferredVarcharLength:
+		return fmt.Sprintf("VARCHAR(%d)", inferredVarcharLength)
+	case c.maxLength <= maxInferredTextLength:
+		return "TEXT"
+	default:
+		return "LONGTEXT"
+	}
+}
+
+// parseDecimalDigits parses a decimal literal like "-12.345" and returns the number of
+// digits before and after the decimal point. The numbers with redundant leading zeros,
+// e.g. zip codes, are not regarded as decimals so that the zeros are kept.
+func parseDecimalDigits(value string) (intDigits int, fracDigits int, ok bool) {
+	s := strings.TrimPrefix(strings.TrimPrefix(value, "-"), "+")
+	if len(s) < len(value)-1 {
+		return 0, 0, false
+	}
+	intPart, fracPart, hasPoint := strings.Cut(s, ".")
+	if len(intPart) == 0 && len(fracPart) == 0 {
+		return 0, 0, false
+	}
+	if hasPoint && len(fracPart) == 0 {
+		return 0, 0, false
+	}
+	if len(intPart) > 1 && intPart[0] == '0' {
+		return 0, 0, false
+	}
+	for _, part := range []string{intPart, fracPart} {
+		for i := 0; i < len(part); i++ {
+			if part[i] < '0' || part[i] > '9' {
+				return 0, 0, false
+			}
+		}
+	}
+	intDigits = len(intPart)
+	if intPart == "0" {
+		intDigits = 0
+	}
+	return intDigits, len(fracPart), true
+}
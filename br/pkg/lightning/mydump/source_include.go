@@ -0,0 +1,109 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+)
+
+const globMetaChars = "*?[\\"
+
+// sourceInclude restricts the scanned files in the data source to the ones under
+// the given path prefixes or matching the given globs.
+type sourceInclude struct {
+	prefixes []string
+	globs    []string
+	// subDirs are the directories to walk, which cover all the prefixes and globs.
+	// An empty string means the whole data source.
+	subDirs []string
+}
+
+// parseSourceInclude parses `mydumper.source-include`. It returns nil if there is
+// no entry, i.e. all the files are included.
+//
+// An entry without the glob meta characters is a path prefix, e.g. "dump/db1." includes
+// "dump/db1.t1.sql" and "dump/db1.t2.csv". Otherwise it's a glob matching the files
+// or their parent directories, e.g. "dump-2022*" includes every file under the
+// directories "dump-20221001" and "dump-20221002".
+func parseSourceInclude(includes []string) (*sourceInclude, error) {
+	if len(includes) == 0 {
+		return nil, nil
+	}
+	si := &sourceInclude{}
+	subDirs := make([]string, 0, len(includes))
+	for _, include := range includes {
+		include = strings.TrimPrefix(strings.TrimPrefix(include, "./"), "/")
+		if len(include) == 0 {
+			return nil, common.ErrInvalidConfig.GenWithStack("`mydumper.source-include` must not contain empty entries")
+		}
+		literal := include
+		if idx := strings.IndexAny(include, globMetaChars); idx >= 0 {
+			if _, err := path.Match(include, ""); err != nil {
+				return nil, common.ErrInvalidConfig.Wrap(err).GenWithStack("invalid glob '%s' in `mydumper.source-include`", include)
+			}
+			si.globs = append(si.globs, include)
+			literal = include[:idx]
+		} else {
+			si.prefixes = append(si.prefixes, include)
+		}
+		subDir := ""
+		if idx := strings.LastIndexByte(literal, '/'); idx >= 0 {
+			subDir = literal[:idx]
+		}
+		subDirs = append(subDirs, subDir)
+	}
+
+	// remove the directories nested in another one, so no file is walked twice.
+	sort.Strings(subDirs)
+	for _, subDir := range subDirs {
+		if !si.coveredBySubDirs(subDir) {
+			si.subDirs = append(si.subDirs, subDir)
+		}
+	}
+	return si, nil
+}
+
+func (si *sourceInclude) coveredBySubDirs(dir string) bool {
+	for _, subDir := range si.subDirs {
+		if subDir == "" || dir == subDir || strings.HasPrefix(dir, subDir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns whether the file of the slash-separated path is included.
+func (si *sourceInclude) match(filePath string) bool {
+	for _, prefix := range si.prefixes {
+		if strings.HasPrefix(filePath, prefix) {
+			return true
+		}
+	}
+	for _, glob := range si.globs {
+		for p := filePath; ; p = path.Dir(p) {
+			if matched, _ := path.Match(glob, p); matched {
+				return true
+			}
+			if !strings.Contains(p, "/") {
+				break
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSourceInclude(t *testing.T) {
+	si, err := parseSourceInclude(nil)
+	require.NoError(t, err)
+	require.Nil(t, si)
+
+	si, err = parseSourceInclude([]string{"./a/b/db1.", "a/b/c/", "/a/d*/x", "e/f/g*", "a-b/"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a/b/db1.", "a/b/c/", "a-b/"}, si.prefixes)
+	require.Equal(t, []string{"a/d*/x", "e/f/g*"}, si.globs)
+	require.Equal(t, []string{"a", "a-b", "e/f"}, si.subDirs)
+
+	for _, tc := range []struct {
+		path     string
+		included bool
+	}{
+		{"a/b/db1.t1.sql", true},
+		{"a/b/db2.t1.sql", false},
+		{"a/b/c/db2.t1.sql", true},
+		{"a/dump/x/db.t.sql", true},
+		{"a/dump/y/db.t.sql", false},
+		{"e/f/g1.sql", true},
+		{"e/f/g1/db.t.sql", true},
+		{"e/f/h/db.t.sql", false},
+		{"a-b/db.t.sql", true},
+	} {
+		require.Equal(t, tc.included, si.match(tc.path), tc.path)
+	}
+
+	si, err = parseSourceInclude([]string{"a/", "*.sql"})
+	require.NoError(t, err)
+	require.Equal(t, []string{""}, si.subDirs)
+
+	_, err = parseSourceInclude([]string{"a/["})
+	require.Regexp(t, "invalid glob 'a/\\[' in `mydumper.source-include`", err)
+	_, err = parseSourceInclude([]string{""})
+	require.Regexp(t, "must not contain empty entries", err)
+}
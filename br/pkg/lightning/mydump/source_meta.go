@@ -0,0 +1,150 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/storage"
+)
+
+const (
+	// sourceMetaPath is the path of the metadata file written by Dumpling or Mydumper
+	// in the root of the data source.
+	sourceMetaPath = "metadata"
+
+	sourceMetaTimeLayout = "2006-01-02 15:04:05"
+	// tidbBinlogName is the binlog name of the master status of TiDB, whose binlog
+	// position is the snapshot TSO.
+	tidbBinlogName = "tidb-binlog"
+)
+
+// MDSourceMeta is the metadata of the data source, which is parsed from the
+// `metadata` file written by Dumpling.
+type MDSourceMeta struct {
+	// StartTime and FinishTime are the time the dump started and finished, in
+	// the local time zone. They are zero if not recorded.
+	StartTime  time.Time
+	FinishTime time.Time
+	// BinlogName, BinlogPos and BinlogGTID are the master status of the upstream
+	// when the dump started.
+	BinlogName string
+	BinlogPos  uint64
+	BinlogGTID string
+	// SnapshotTS is the TSO of the snapshot the data is dumped from if the upstream
+	// is TiDB, or 0 otherwise.
+	SnapshotTS uint64
+	// IsRowOrdered is whether the rows in the data files are ordered by the primary
+	// key. It is true if not recorded since it's the default of Dumpling.
+	IsRowOrdered bool
+}
+
+// readSourceMeta reads the metadata file in the root of the data source. It
+// returns nil if the file does not exist.
+func readSourceMeta(ctx context.Context, store storage.ExternalStorage) (*MDSourceMeta, error) {
+	exists, err := store.FileExists(ctx, sourceMetaPath)
+	if err != nil || !exists {
+		return nil, errors.Trace(err)
+	}
+	content, err := store.ReadFile(ctx, sourceMetaPath)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return parseSourceMeta(content)
+}
+
+// parseSourceMeta parses the content of the metadata file, which looks like
+//
+//	Started dump at: 2022-01-01 00:00:00
+//	Order by primary key: true
+//	SHOW MASTER STATUS:
+//		Log: tidb-binlog
+//		Pos: 430224789014528001
+//		GTID:
+//
+//	Finished dump at: 2022-01-01 00:01:00
+//
+// Only the master status recorded when the dump started is used, the one recorded
+// after the connection pool established and the follower status are ignored.
+func parseSourceMeta(content []byte) (*MDSourceMeta, error) {
+	meta := &MDSourceMeta{IsRowOrdered: true}
+	// section is the header of the section being parsed.
+	section := ""
+	masterStatusParsed := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(line, "\t") {
+			key, value, _ := strings.Cut(line, ":")
+			value = strings.TrimSpace(value)
+			section = ""
+			var err error
+			switch key {
+			case "Started dump at":
+				meta.StartTime, err = time.ParseInLocation(sourceMetaTimeLayout, value, time.Local)
+			case "Finished dump at":
+				meta.FinishTime, err = time.ParseInLocation(sourceMetaTimeLayout, value, time.Local)
+			case "Order by primary key":
+				meta.IsRowOrdered, err = strconv.ParseBool(value)
+			case "SHOW MASTER STATUS":
+				// skip the status recorded after the connection pool established.
+				if !masterStatusParsed && len(value) == 0 {
+					section = key
+					masterStatusParsed = true
+				}
+			}
+			if err != nil {
+				return nil, errors.Annotatef(err, "invalid line '%s' in metadata file", line)
+			}
+			continue
+		}
+		if section != "SHOW MASTER STATUS" {
+			continue
+		}
+		key, value, _ := strings.Cut(strings.TrimSpace(line), ":")
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Log":
+			meta.BinlogName = value
+		case "Pos":
+			if len(value) == 0 {
+				continue
+			}
+			pos, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return nil, errors.Annotatef(err, "invalid line '%s' in metadata file", line)
+			}
+			meta.BinlogPos = pos
+		case "GTID":
+			meta.BinlogGTID = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if meta.BinlogName == tidbBinlogName {
+		meta.SnapshotTS = meta.BinlogPos
+	}
+	return meta, nil
+}
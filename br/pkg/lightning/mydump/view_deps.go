@@ -0,0 +1,173 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mydump
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/ast"
+	filter "github.com/pingcap/tidb/util/table-filter"
+	"go.uber.org/zap"
+)
+
+// parseViewDependencies returns the lower-case names of the tables and views referenced by
+// the CREATE VIEW statement in the schema SQL of a view. The unqualified names are resolved
+// in defaultDB.
+func parseViewDependencies(sql string, defaultDB string) ([]filter.Table, error) {
+	stmts, _, err := parser.New().ParseSQL(sql)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	collector := &tableNameCollector{defaultDB: strings.ToLower(defaultDB)}
+	for _, stmt := range stmts {
+		if createView, ok := stmt.(*ast.CreateViewStmt); ok && createView.Select != nil {
+			createView.Select.Accept(collector)
+		}
+	}
+	return collector.tables, nil
+}
+
+type tableNameCollector struct {
+	defaultDB string
+	tables    []filter.Table
+}
+
+// Enter implements ast.Visitor interface.
+func (c *tableNameCollector) Enter(n ast.Node) (ast.Node, bool) {
+	if tn, ok := n.(*ast.TableName); ok {
+		table := filter.Table{Schema: tn.Schema.L, Name: tn.Name.L}
+		if len(table.Schema) == 0 {
+			table.Schema = c.defaultDB
+		}
+		c.tables = append(c.tables, table)
+	}
+	return n, false
+}
+
+// Leave implements ast.Visitor interface.
+func (c *tableNameCollector) Leave(n ast.Node) (ast.Node, bool) {
+	return n, true
+}
+
+// sortViews parses the dependencies of the views and sorts them so each view is placed after
+// the views it references, even if they are in other databases.
+func (s *mdLoaderSetup) sortViews(ctx context.Context, store storage.ExternalStorage) error {
+	for _, dbMeta := range s.loader.dbs {
+		for _, view := range dbMeta.Views {
+			sql, err := view.GetSchema(ctx, store)
+			if err == nil {
+				view.viewDeps, err = parseViewDependencies(sql, view.DB)
+			}
+			if err != nil {
+				// the view will fail to be created later if its schema is broken, here just
+				// treat it as an independent view.
+				log.FromContext(ctx).Warn("[loader] failed to parse the dependencies of view",
+					zap.String("path", view.SchemaFile.FileMeta.Path), log.ShortError(err))
+			}
+		}
+	}
+
+	sorted, err := SortViews(s.loader.dbs)
+	if err != nil {
+		return err
+	}
+	for _, dbMeta := range s.loader.dbs {
+		dbMeta.Views = dbMeta.Views[:0]
+	}
+	for _, view := range sorted {
+		dbMeta := s.loader.dbs[s.dbIndexMap[view.DB]]
+		dbMeta.Views = append(dbMeta.Views, view)
+	}
+	s.loader.views = sorted
+	return nil
+}
+
+// SortViews returns the views of all the databases sorted so each view is placed after the
+// views it references, even if they are in other databases. The relative order of the
+// independent views is kept.
+func SortViews(dbMetas []*MDDatabaseMeta) ([]*MDTableMeta, error) {
+	views := make([]*MDTableMeta, 0)
+	viewIndex := make(map[filter.Table]int)
+	for _, dbMeta := range dbMetas {
+		for _, view := range dbMeta.Views {
+			viewIndex[filter.Table{Schema: strings.ToLower(view.DB), Name: strings.ToLower(view.Name)}] = len(views)
+			views = append(views, view)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make([]int, len(views))
+	sorted := make([]*MDTableMeta, 0, len(views))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch states[i] {
+		case visiting:
+			return common.ErrInvalidSchemaFile.GenWithStack("circular view dependency found at view '%s'",
+				common.UniqueTable(views[i].DB, views[i].Name))
+		case visited:
+			return nil
+		}
+		states[i] = visiting
+		for _, dep := range views[i].viewDeps {
+			if j, ok := viewIndex[dep]; ok && j != i {
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		states[i] = visited
+		sorted = append(sorted, views[i])
+		return nil
+	}
+	for i := range views {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// GroupViewsByLevel groups the views sorted by SortViews into levels, each view only references
+// the views in the previous levels, so the views in the same level can be created concurrently.
+func GroupViewsByLevel(sorted []*MDTableMeta) [][]*MDTableMeta {
+	levelOf := make(map[filter.Table]int, len(sorted))
+	var levels [][]*MDTableMeta
+	for _, view := range sorted {
+		name := filter.Table{Schema: strings.ToLower(view.DB), Name: strings.ToLower(view.Name)}
+		level := 0
+		for _, dep := range view.viewDeps {
+			// the referenced views are always placed before the view itself.
+			if depLevel, ok := levelOf[dep]; ok && dep != name && depLevel+1 > level {
+				level = depLevel + 1
+			}
+		}
+		levelOf[name] = level
+		if level == len(levels) {
+			levels = append(levels, nil)
+		}
+		levels[level] = append(levels[level], view)
+	}
+	return levels
+}
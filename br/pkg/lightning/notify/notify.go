@@ -0,0 +1,216 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"go.uber.org/zap"
+)
+
+const pagerDutySource = "tidb-lightning"
+
+// Event is an event of the task sent to the notification hooks. It's also the
+// data of the templates.
+type Event struct {
+	Type   string    `json:"event"`
+	TaskID int64     `json:"task-id"`
+	Time   time.Time `json:"time"`
+	// Table is the unique name of the table for the table events.
+	Table string `json:"table,omitempty"`
+	// Error is the error message, or empty if the task is successful.
+	Error string `json:"error,omitempty"`
+}
+
+// Summary returns a one-line description of the event.
+func (e *Event) Summary() string {
+	switch e.Type {
+	case config.EventTaskCompleted:
+		if len(e.Error) == 0 {
+			return fmt.Sprintf("TiDB Lightning task %d completed", e.TaskID)
+		}
+		return fmt.Sprintf("TiDB Lightning task %d failed: %s", e.TaskID, e.Error)
+	case config.EventTableFailed:
+		return fmt.Sprintf("TiDB Lightning task %d failed to import table %s: %s", e.TaskID, e.Table, e.Error)
+	case config.EventChecksumMismatch:
+		return fmt.Sprintf("TiDB Lightning task %d found checksum mismatched for table %s: %s", e.TaskID, e.Table, e.Error)
+	default:
+		return fmt.Sprintf("TiDB Lightning task %d: %s", e.TaskID, e.Type)
+	}
+}
+
+type hook struct {
+	cfg      *config.Notification
+	template *template.Template
+	events   map[string]struct{}
+}
+
+// Notifier sends the events of the task to the hooks in `[[notification]]`. The
+// failures of the notifications are only logged, so they never fail the task.
+type Notifier struct {
+	taskID int64
+	hooks  []*hook
+	client *http.Client
+}
+
+// NewNotifier creates a Notifier from the notification hooks of the task config,
+// which should have been adjusted.
+func NewNotifier(cfg *config.Config) (*Notifier, error) {
+	n := &Notifier{
+		taskID: cfg.TaskID,
+		hooks:  make([]*hook, 0, len(cfg.Notifications)),
+		client: &http.Client{},
+	}
+	for _, c := range cfg.Notifications {
+		h := &hook{cfg: c}
+		if len(c.Template) > 0 {
+			tmpl, err := template.New(c.Type).Parse(c.Template)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			h.template = tmpl
+		}
+		if len(c.Events) > 0 {
+			h.events = make(map[string]struct{}, len(c.Events))
+			for _, event := range c.Events {
+				h.events[event] = struct{}{}
+			}
+		}
+		n.hooks = append(n.hooks, h)
+	}
+	return n, nil
+}
+
+// Notify sends the event to the hooks subscribing to it. It blocks until all the
+// hooks are notified or timed out.
+func (n *Notifier) Notify(ctx context.Context, event *Event) {
+	event.TaskID = n.taskID
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	logger := log.FromContext(ctx).With(zap.String("event", event.Type), zap.String("table", event.Table))
+	for _, h := range n.hooks {
+		if h.events != nil {
+			if _, ok := h.events[event.Type]; !ok {
+				continue
+			}
+		}
+		if err := n.send(ctx, h, event); err != nil {
+			logger.Warn("failed to send notification", zap.String("type", h.cfg.Type), log.ShortError(err))
+		}
+	}
+}
+
+func (n *Notifier) send(ctx context.Context, h *hook, event *Event) error {
+	body, err := h.payload(event)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.cfg.Timeout.Duration)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	//nolint: errcheck
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("unexpected status %s: %s", resp.Status, msg)
+	}
+	return nil
+}
+
+// message returns the template executed with the event, or the default message if
+// there is no template.
+func (h *hook) message(event *Event, defaultMessage func() ([]byte, error)) ([]byte, error) {
+	if h.template == nil {
+		return defaultMessage()
+	}
+	var buf bytes.Buffer
+	if err := h.template.Execute(&buf, event); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// payload returns the request body for the event.
+func (h *hook) payload(event *Event) ([]byte, error) {
+	summary := func() ([]byte, error) {
+		return []byte(event.Summary()), nil
+	}
+	switch h.cfg.Type {
+	case config.NotificationSlack:
+		text, err := h.message(event, summary)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]string{"text": string(text)})
+	case config.NotificationPagerDuty:
+		text, err := h.message(event, summary)
+		if err != nil {
+			return nil, err
+		}
+		severity := "error"
+		if event.Type == config.EventTaskCompleted && len(event.Error) == 0 {
+			severity = "info"
+		}
+		return json.Marshal(map[string]interface{}{
+			"routing_key":  h.cfg.RoutingKey,
+			"event_action": "trigger",
+			"payload": map[string]interface{}{
+				"summary":        string(text),
+				"source":         pagerDutySource,
+				"severity":       severity,
+				"timestamp":      event.Time.Format(time.RFC3339),
+				"custom_details": event,
+			},
+		})
+	default:
+		return h.message(event, func() ([]byte, error) {
+			return json.Marshal(event)
+		})
+	}
+}
+
+type ctxKeyType struct{}
+
+var ctxKey ctxKeyType
+
+// NewContext returns a new context with the provided notifier.
+func NewContext(ctx context.Context, notifier *Notifier) context.Context {
+	return context.WithValue(ctx, ctxKey, notifier)
+}
+
+// FromContext returns the notifier stored in the context.
+func FromContext(ctx context.Context) (*Notifier, bool) {
+	n, ok := ctx.Value(ctxKey).(*Notifier)
+	return n, ok
+}
@@ -0,0 +1,115 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/notify"
+	"github.com/stretchr/testify/require"
+)
+
+type receiver struct {
+	mu     sync.Mutex
+	bodies map[string][]string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	r.bodies[req.URL.Path] = append(r.bodies[req.URL.Path], string(body))
+	r.mu.Unlock()
+	if req.URL.Path == "/broken" {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	r := &receiver{bodies: make(map[string][]string)}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	timeout := config.Duration{Duration: time.Second}
+	cfg := config.NewConfig()
+	cfg.TaskID = 123
+	cfg.Notifications = []*config.Notification{
+		{Type: config.NotificationWebhook, URL: server.URL + "/webhook", Timeout: timeout},
+		{
+			Type:     config.NotificationSlack,
+			URL:      server.URL + "/slack",
+			Events:   []string{config.EventTableFailed, config.EventChecksumMismatch},
+			Template: "{{.Table}} failed in task {{.TaskID}}",
+			Timeout:  timeout,
+		},
+		{Type: config.NotificationPagerDuty, URL: server.URL + "/pagerduty", RoutingKey: "key", Timeout: timeout},
+		{Type: config.NotificationWebhook, URL: server.URL + "/broken", Timeout: timeout},
+	}
+	n, err := notify.NewNotifier(cfg)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	n.Notify(ctx, &notify.Event{Type: config.EventTableFailed, Table: "`db`.`t`", Error: "oops"})
+	n.Notify(ctx, &notify.Event{Type: config.EventTaskCompleted})
+
+	require.Len(t, r.bodies["/webhook"], 2)
+	var event notify.Event
+	require.NoError(t, json.Unmarshal([]byte(r.bodies["/webhook"][0]), &event))
+	require.Equal(t, config.EventTableFailed, event.Type)
+	require.Equal(t, int64(123), event.TaskID)
+	require.Equal(t, "`db`.`t`", event.Table)
+	require.Equal(t, "oops", event.Error)
+
+	// the slack hook only subscribes to the table events.
+	require.Equal(t, []string{`{"text":"` + "`db`.`t`" + ` failed in task 123"}`}, r.bodies["/slack"])
+
+	require.Len(t, r.bodies["/pagerduty"], 2)
+	var incident struct {
+		RoutingKey string `json:"routing_key"`
+		Payload    struct {
+			Summary  string `json:"summary"`
+			Severity string `json:"severity"`
+		} `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(r.bodies["/pagerduty"][0]), &incident))
+	require.Equal(t, "key", incident.RoutingKey)
+	require.Equal(t, "TiDB Lightning task 123 failed to import table `db`.`t`: oops", incident.Payload.Summary)
+	require.Equal(t, "error", incident.Payload.Severity)
+	require.NoError(t, json.Unmarshal([]byte(r.bodies["/pagerduty"][1]), &incident))
+	require.Equal(t, "TiDB Lightning task 123 completed", incident.Payload.Summary)
+	require.Equal(t, "info", incident.Payload.Severity)
+
+	// the failed hook doesn't affect the others.
+	require.Len(t, r.bodies["/broken"], 2)
+}
+
+func TestNotifierContext(t *testing.T) {
+	_, ok := notify.FromContext(context.Background())
+	require.False(t, ok)
+
+	n, err := notify.NewNotifier(config.NewConfig())
+	require.NoError(t, err)
+	ctx := notify.NewContext(context.Background(), n)
+	n2, ok := notify.FromContext(ctx)
+	require.True(t, ok)
+	require.Same(t, n, n2)
+}
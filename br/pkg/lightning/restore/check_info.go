@@ -163,3 +163,13 @@ func (rc *Controller) checkSourceSchema(ctx context.Context) error {
 	}
 	return rc.doPreCheckOnItem(ctx, CheckSourceSchemaValid)
 }
+
+// checkCustomItems runs the custom precheck items registered by RegisterPrecheckItem.
+func (rc *Controller) checkCustomItems(ctx context.Context) error {
+	for _, checkItemID := range RegisteredPrecheckItems() {
+		if err := rc.doPreCheckOnItem(ctx, checkItemID); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
@@ -433,6 +433,71 @@ func (s *chunkRestoreSuite) TestEncodeLoopColumnsMismatch() {
 	require.Len(s.T(), kvsCh, 0)
 }
 
+func (s *chunkRestoreSuite) TestEncodeLoopRejectedRows() {
+	dir := s.T().TempDir()
+	rejectedDir := s.T().TempDir()
+
+	fileName := "db.table.000.csv"
+	err := os.WriteFile(filepath.Join(dir, fileName), []byte("1,2,3\n4,5\n7,\"8\"9,10\n\n11,12,13\n"), 0o644)
+	require.NoError(s.T(), err)
+
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(s.T(), err)
+
+	ctx := context.Background()
+	encodeLoop := func(maxRejectedRows int64) error {
+		cfg := config.NewConfig()
+		cfg.Mydumper.CSV.RejectedRowsDir = rejectedDir
+		cfg.Mydumper.CSV.MaxRejectedRows = maxRejectedRows
+		errorMgr := errormanager.New(nil, cfg, log.L())
+		require.NoError(s.T(), errorMgr.Init(ctx))
+		rc := &Controller{pauser: DeliverPauser, cfg: cfg, errorMgr: errorMgr}
+
+		reader, err := store.Open(ctx, fileName)
+		require.NoError(s.T(), err)
+		w := worker.NewPool(ctx, 5, "io")
+		p, err := mydump.NewCSVParser(ctx, &cfg.Mydumper.CSV, reader, 111, w, false, nil)
+		require.NoError(s.T(), err)
+		require.NoError(s.T(), s.cr.parser.Close())
+		s.cr.parser = p
+
+		kvsCh := make(chan []deliveredKVs, 2)
+		deliverCompleteCh := make(chan deliverResult)
+		kvEncoder, err := tidb.NewTiDBBackend(ctx, nil, config.ReplaceOnDup, errorMgr).NewEncoder(
+			ctx,
+			s.tr.encTable,
+			&kv.SessionOptions{
+				SQLMode:   s.cfg.TiDB.SQLMode,
+				Timestamp: 1234567895,
+			})
+		require.NoError(s.T(), err)
+		defer kvEncoder.Close()
+
+		_, _, err = s.cr.encodeLoop(ctx, kvsCh, s.tr, s.tr.logger, kvEncoder, deliverCompleteCh, rc)
+		if err != nil {
+			return err
+		}
+		require.Len(s.T(), kvsCh, 2)
+		kvs := <-kvsCh
+		require.Len(s.T(), kvs, 2)
+		require.Equal(s.T(), int64(6), kvs[0].offset)
+		require.Equal(s.T(), int64(30), kvs[1].offset)
+		require.Equal(s.T(), int64(4), kvs[1].rowID)
+		require.True(s.T(), errorMgr.HasError())
+		return nil
+	}
+
+	require.NoError(s.T(), encodeLoop(0))
+	data, err := os.ReadFile(filepath.Join(rejectedDir, "db.table.db.table.2.sql.0.rejected.csv"))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), "path,line,offset,error,row\n"+
+		"db.table.2.sql,2,6,\"column count mismatch, expected 3, got 2\",\"4,5\"\n"+
+		"db.table.2.sql,3,10,syntax error: cannot have consecutive fields without separator,\"7,\"\"8\"\"9,10\"\n", string(data))
+
+	err = encodeLoop(1)
+	require.ErrorContains(s.T(), err, "meet rejected rows exceed the max-rejected-rows threshold '1': syntax error")
+}
+
 func (s *chunkRestoreSuite) TestEncodeLoopIgnoreColumnsCSV() {
 	cases := []struct {
 		s             string
@@ -544,6 +609,34 @@ func (s *chunkRestoreSuite) testEncodeLoopIgnoreColumnsCSV(
 	require.Equal(s.T(), s.cr.chunk.Chunk.EndOffset, kvs[0].offset)
 }
 
+func (s *chunkRestoreSuite) TestNewChunkRestoreJSON() {
+	dir := s.T().TempDir()
+	fileName := "db.table.000.jsonl"
+	content := `{"A": 1}` + "\n" + `{"a": 2, "c": 3, "x": 4}` + "\n"
+	require.NoError(s.T(), os.WriteFile(filepath.Join(dir, fileName), []byte(content), 0o644))
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(s.T(), err)
+
+	ctx := context.Background()
+	chunk := checkpoints.ChunkCheckpoint{
+		Key:      checkpoints.ChunkCheckpointKey{Path: fileName},
+		FileMeta: mydump.SourceFileMeta{Path: fileName, Type: mydump.SourceTypeJSON, FileSize: int64(len(content))},
+		Chunk:    mydump.Chunk{EndOffset: int64(len(content)), RowIDMax: 2},
+	}
+	cr, err := newChunkRestore(ctx, 1, config.NewConfig(), &chunk, worker.NewPool(ctx, 1, "io"), store, s.tableInfo)
+	require.NoError(s.T(), err)
+	defer cr.close()
+
+	// the keys are matched with the columns of the table rather than the first object.
+	require.NoError(s.T(), cr.parser.ReadRow())
+	require.Equal(s.T(), []string{"a", "b", "c"}, cr.parser.Columns())
+	require.Equal(s.T(), []types.Datum{types.NewIntDatum(1), types.NewDatum(nil), types.NewDatum(nil)}, cr.parser.LastRow().Row)
+	require.Equal(s.T(), []bool{false, true, true}, cr.parser.LastRow().Missing)
+	require.NoError(s.T(), cr.parser.ReadRow())
+	require.Equal(s.T(), []types.Datum{types.NewIntDatum(2), types.NewDatum(nil), types.NewIntDatum(3)}, cr.parser.LastRow().Row)
+	require.Equal(s.T(), []bool{false, true, false}, cr.parser.LastRow().Missing)
+}
+
 type mockEncoder struct{}
 
 func (mockEncoder) Encode(_ log.Logger, _ []types.Datum, rowID int64, _ []int, _ string, _ int64) (kv.Row, error) {
@@ -0,0 +1,124 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/backend"
+	"github.com/pingcap/tidb/br/pkg/lightning/backend/local"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/glue"
+	"github.com/pingcap/tidb/br/pkg/lightning/tikv"
+)
+
+// clusterFeature is a feature of the import that needs the support of the target cluster.
+type clusterFeature struct {
+	name    string
+	enabled func(cfg *config.Config) bool
+	// check reports whether the target cluster supports the feature. The version checks
+	// reuse the requirements of the components already defined by the backends.
+	check func(ctx context.Context, cfg *config.Config, tls *common.TLS, g glue.Glue) error
+}
+
+// clusterFeatures is the matrix of the features and the checks of the target cluster
+// supporting them. Pausing the schedulers by the region labels isn't listed since pdutil
+// falls back to pausing them globally on the old PD.
+var clusterFeatures = []clusterFeature{
+	{
+		// it also covers switching TiKV to the import mode, which has the same requirements.
+		name:    "physical import (tikv-importer.backend = \"local\")",
+		enabled: isLocalBackend,
+		check: func(ctx context.Context, cfg *config.Config, tls *common.TLS, g glue.Glue) error {
+			return local.NewTargetInfoGetter(tls, g, cfg.TiDB.PdAddr).CheckRequirements(ctx, &backend.CheckCtx{})
+		},
+	},
+	{
+		// the keys written by the local backend don't have the keyspace prefix.
+		name:    "physical import (tikv-importer.backend = \"local\") into a keyspace",
+		enabled: isLocalBackend,
+		check:   checkTiKVAPIVersion,
+	},
+	{
+		name: "duplicate detection (tikv-importer.duplicate-resolution)",
+		enabled: func(cfg *config.Config) bool {
+			return isLocalBackend(cfg) && cfg.TikvImporter.DuplicateResolution != config.DupeResAlgNone
+		},
+		check: func(ctx context.Context, cfg *config.Config, tls *common.TLS, _ glue.Glue) error {
+			return tikv.CheckTiKVVersion(ctx, tls, cfg.TiDB.PdAddr,
+				minTiKVVersionForDuplicateResolution, maxTiKVVersionForDuplicateResolution)
+		},
+	},
+}
+
+// CheckClusterFeatures checks whether the target cluster supports all the features required
+// by the configured import. It only requests the versions and the configs of the components,
+// so it's done at startup to fail fast before the data source is loaded.
+func CheckClusterFeatures(ctx context.Context, cfg *config.Config, g glue.Glue) error {
+	features := enabledClusterFeatures(cfg)
+	if len(features) == 0 {
+		return nil
+	}
+	tls, err := cfg.ToTLS()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, f := range features {
+		if err := f.check(ctx, cfg, tls, g); err != nil {
+			return errors.Annotatef(err, "%s is not supported by the target cluster", f.name)
+		}
+	}
+	return nil
+}
+
+func enabledClusterFeatures(cfg *config.Config) []*clusterFeature {
+	var features []*clusterFeature
+	for i := range clusterFeatures {
+		if clusterFeatures[i].enabled(cfg) {
+			features = append(features, &clusterFeatures[i])
+		}
+	}
+	return features
+}
+
+// tikvConfig is the part of the TiKV config used by the checks.
+type tikvConfig struct {
+	Storage struct {
+		APIVersion int `json:"api-version"`
+	} `json:"storage"`
+}
+
+// checkTiKVAPIVersion checks that no TiKV enables the API V2, which is required by the keyspace.
+func checkTiKVAPIVersion(ctx context.Context, cfg *config.Config, tls *common.TLS, _ glue.Glue) error {
+	return tikv.ForAllStores(
+		ctx,
+		tls.WithHost(cfg.TiDB.PdAddr),
+		tikv.StoreStateDown,
+		func(c context.Context, store *tikv.Store) error {
+			var tikvCfg tikvConfig
+			if err := tls.WithHost(store.StatusAddress).GetJSON(c, "/config", &tikvCfg); err != nil {
+				return errors.Annotatef(err, "fetch config of TiKV (at %s)", store.Address)
+			}
+			if tikvCfg.Storage.APIVersion == 2 {
+				return errors.Annotatef(berrors.ErrVersionMismatch,
+					"TiKV (at %s) enables storage.api-version = 2", store.Address)
+			}
+			return nil
+		},
+	)
+}
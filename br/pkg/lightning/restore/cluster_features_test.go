@@ -0,0 +1,75 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnabledClusterFeatures(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.TikvImporter.Backend = config.BackendLocal
+	cfg.TikvImporter.DuplicateResolution = config.DupeResAlgRecord
+	require.Len(t, enabledClusterFeatures(cfg), 3)
+
+	cfg.TikvImporter.DuplicateResolution = config.DupeResAlgNone
+	require.Len(t, enabledClusterFeatures(cfg), 2)
+}
+
+func TestCheckTiKVAPIVersion(t *testing.T) {
+	apiVersion := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/pd/api/v1/stores":
+			_, _ = fmt.Fprintf(w, `{"stores":[{"store":{"address":"tikv-0:20160","status_address":%q,"state_name":"Up"}}]}`,
+				req.Host)
+		case "/config":
+			_, _ = fmt.Fprintf(w, `{"storage":{"api-version":%d}}`, apiVersion)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig()
+	cfg.TiDB.PdAddr = strings.TrimPrefix(server.URL, "http://")
+	tls, err := cfg.ToTLS()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, checkTiKVAPIVersion(ctx, cfg, tls, nil))
+
+	apiVersion = 2
+	err = checkTiKVAPIVersion(ctx, cfg, tls, nil)
+	require.True(t, berrors.Is(err, berrors.ErrVersionMismatch))
+	require.ErrorContains(t, err, "TiKV (at tikv-0:20160) enables storage.api-version = 2")
+}
+
+func TestCheckClusterFeaturesTiDBBackend(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.TikvImporter.Backend = config.BackendTiDB
+	// no feature is required, so the cluster is never accessed.
+	require.Empty(t, enabledClusterFeatures(cfg))
+	require.NoError(t, CheckClusterFeatures(context.Background(), cfg, nil))
+}
@@ -0,0 +1,329 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/format"
+	"github.com/pingcap/tidb/parser/model"
+	filter "github.com/pingcap/tidb/util/table-filter"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// foreignKey is a foreign key in the schema file of a table.
+type foreignKey struct {
+	db       string
+	table    string
+	name     string
+	cols     []string
+	refDB    string
+	refTable string
+	refCols  []string
+	// definition is the `CONSTRAINT ... FOREIGN KEY ... REFERENCES ...` clause.
+	definition string
+}
+
+// refName returns the lower-case name of the referenced table.
+func (fk *foreignKey) refName() filter.Table {
+	return filter.Table{Schema: strings.ToLower(fk.refDB), Name: strings.ToLower(fk.refTable)}
+}
+
+// validateSQL returns the statement counting the rows violating the foreign key. Like
+// MySQL, the rows with NULL in any of the columns don't violate the foreign key.
+func (fk *foreignKey) validateSQL() string {
+	var sb strings.Builder
+	sb.WriteString("SELECT COUNT(*) FROM ")
+	sb.WriteString(common.UniqueTable(fk.db, fk.table))
+	sb.WriteString(" AS c WHERE ")
+	for _, col := range fk.cols {
+		fmt.Fprintf(&sb, "c.%s IS NOT NULL AND ", common.EscapeIdentifier(col))
+	}
+	sb.WriteString("NOT EXISTS (SELECT 1 FROM ")
+	sb.WriteString(common.UniqueTable(fk.refDB, fk.refTable))
+	sb.WriteString(" AS p WHERE ")
+	for i, col := range fk.cols {
+		if i > 0 {
+			sb.WriteString(" AND ")
+		}
+		fmt.Fprintf(&sb, "p.%s = c.%s", common.EscapeIdentifier(fk.refCols[i]), common.EscapeIdentifier(col))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// addSQL returns the statement adding the foreign key to the table.
+func (fk *foreignKey) addSQL() string {
+	return fmt.Sprintf("ALTER TABLE %s ADD %s", common.UniqueTable(fk.db, fk.table), fk.definition)
+}
+
+// parseForeignKeys returns the foreign keys of the CREATE TABLE statements. The unqualified
+// referenced tables are resolved in dbName, and the unnamed foreign keys are named like MySQL,
+// so that the same names are given if the statements are parsed again.
+func parseForeignKeys(p *parser.Parser, stmts []string, dbName, tblName string) ([]*foreignKey, error) {
+	var fks []*foreignKey
+	for _, sqlStr := range stmts {
+		node, err := p.ParseOneStmt(sqlStr, "", "")
+		if err != nil {
+			return nil, common.ErrInvalidSchemaStmt.Wrap(err).GenWithStackByArgs(sqlStr)
+		}
+		createTable, ok := node.(*ast.CreateTableStmt)
+		if !ok {
+			continue
+		}
+		for _, constraint := range createTable.Constraints {
+			if constraint.Tp != ast.ConstraintForeignKey || constraint.Refer == nil {
+				continue
+			}
+			fk := &foreignKey{
+				db:       dbName,
+				table:    tblName,
+				name:     constraint.Name,
+				refDB:    constraint.Refer.Table.Schema.O,
+				refTable: constraint.Refer.Table.Name.O,
+			}
+			if len(fk.name) == 0 {
+				fk.name = fmt.Sprintf("%s_ibfk_%d", tblName, len(fks)+1)
+			}
+			if len(fk.refDB) == 0 {
+				fk.refDB = dbName
+			}
+			for _, key := range constraint.Keys {
+				fk.cols = append(fk.cols, key.Column.Name.O)
+			}
+			for _, key := range constraint.Refer.IndexPartSpecifications {
+				fk.refCols = append(fk.refCols, key.Column.Name.O)
+			}
+			if len(fk.cols) != len(fk.refCols) {
+				return nil, common.ErrInvalidSchemaStmt.GenWithStackByArgs(sqlStr)
+			}
+
+			// the referenced table is qualified since the statement is not run in dbName.
+			refTable := *constraint.Refer.Table
+			refTable.Schema = model.NewCIStr(fk.refDB)
+			refer := *constraint.Refer
+			refer.Table = &refTable
+			def := *constraint
+			def.Name = fk.name
+			def.Refer = &refer
+			var sb strings.Builder
+			if err := def.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+				return nil, common.ErrInvalidSchemaStmt.Wrap(err).GenWithStackByArgs(sqlStr)
+			}
+			fk.definition = sb.String()
+			fks = append(fks, fk)
+		}
+	}
+	return fks, nil
+}
+
+// removeForeignKeys removes the foreign keys from the CREATE TABLE statements, so that the
+// tables can be imported in any order.
+func removeForeignKeys(p *parser.Parser, stmts []string) ([]string, error) {
+	var res strings.Builder
+	ctx := format.NewRestoreCtx(format.DefaultRestoreFlags|format.RestoreTiDBSpecialComment, &res)
+	retStmts := make([]string, 0, len(stmts))
+	for _, sqlStr := range stmts {
+		node, err := p.ParseOneStmt(sqlStr, "", "")
+		if err != nil {
+			return nil, common.ErrInvalidSchemaStmt.Wrap(err).GenWithStackByArgs(sqlStr)
+		}
+		createTable, ok := node.(*ast.CreateTableStmt)
+		if !ok {
+			retStmts = append(retStmts, sqlStr)
+			continue
+		}
+		constraints := createTable.Constraints[:0]
+		for _, constraint := range createTable.Constraints {
+			if constraint.Tp != ast.ConstraintForeignKey {
+				constraints = append(constraints, constraint)
+			}
+		}
+		createTable.Constraints = constraints
+		if err := createTable.Restore(ctx); err != nil {
+			return nil, common.ErrInvalidSchemaStmt.Wrap(err).GenWithStackByArgs(sqlStr)
+		}
+		ctx.WritePlain(";")
+		retStmts = append(retStmts, res.String())
+		res.Reset()
+	}
+	return retStmts, nil
+}
+
+// foreignKeyLevels returns the levels of the tables by their foreign keys, a table only
+// references the tables of the lower levels or the tables out of the list. refs[i] is the
+// lower-case names of the tables referenced by tables[i]. The circular references are ignored.
+func foreignKeyLevels(logger log.Logger, tables []filter.Table, refs [][]filter.Table) []int {
+	tableIndex := make(map[filter.Table]int, len(tables))
+	for i, table := range tables {
+		tableIndex[filter.Table{Schema: strings.ToLower(table.Schema), Name: strings.ToLower(table.Name)}] = i
+	}
+
+	const (
+		unvisited = -2
+		visiting  = -1
+	)
+	levels := make([]int, len(tables))
+	for i := range levels {
+		levels[i] = unvisited
+	}
+	var visit func(i int) int
+	visit = func(i int) int {
+		if levels[i] >= 0 {
+			return levels[i]
+		}
+		levels[i] = visiting
+		level := 0
+		for _, ref := range refs[i] {
+			j, ok := tableIndex[ref]
+			if !ok || j == i {
+				continue
+			}
+			if levels[j] == visiting {
+				logger.Warn("circular foreign key reference found, ignored",
+					zap.String("table", common.UniqueTable(tables[i].Schema, tables[i].Name)),
+					zap.String("reference", common.UniqueTable(tables[j].Schema, tables[j].Name)))
+				continue
+			}
+			if l := visit(j) + 1; l > level {
+				level = l
+			}
+		}
+		levels[i] = level
+		return level
+	}
+	for i := range tables {
+		visit(i)
+	}
+	return levels
+}
+
+// loadForeignKeys parses the foreign keys in the schema files of all the tables, whether the
+// tables are created by this run or not.
+func (rc *Controller) loadForeignKeys(ctx context.Context) (map[filter.Table][]*foreignKey, error) {
+	if rc.foreignKeys != nil {
+		return rc.foreignKeys, nil
+	}
+	p := rc.tidbGlue.GetParser()
+	rewriter := newSchemaRewriter(&rc.cfg.Mydumper.SchemaRewrite)
+	foreignKeys := make(map[filter.Table][]*foreignKey)
+	for _, dbMeta := range rc.dbMetas {
+		for _, tblMeta := range dbMeta.Tables {
+			if !tblMeta.HasSchema() {
+				continue
+			}
+			sqlStr, err := tblMeta.GetSchema(ctx, rc.store)
+			if err != nil {
+				return nil, err
+			}
+			if sqlStr == "" {
+				continue
+			}
+			stmts, err := createIfNotExistsStmt(p, rewriter, sqlStr, dbMeta.Name, tblMeta.Name)
+			if err != nil {
+				return nil, err
+			}
+			fks, err := parseForeignKeys(p, stmts, dbMeta.Name, tblMeta.Name)
+			if err != nil {
+				return nil, err
+			}
+			if len(fks) > 0 {
+				name := filter.Table{Schema: strings.ToLower(dbMeta.Name), Name: strings.ToLower(tblMeta.Name)}
+				foreignKeys[name] = fks
+			}
+		}
+	}
+	rc.foreignKeys = foreignKeys
+	return foreignKeys, nil
+}
+
+// addForeignKeys adds the foreign keys removed by the "ordered" foreign key mode back to the
+// tables after all the tables are imported. The rows of each table are validated first, and
+// the foreign keys violated by the imported rows are reported and not added.
+func (rc *Controller) addForeignKeys(ctx context.Context) error {
+	if rc.cfg.App.ForeignKeyMode != config.ForeignKeyModeOrdered {
+		return nil
+	}
+	foreignKeys, err := rc.loadForeignKeys(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(foreignKeys) == 0 {
+		return nil
+	}
+
+	logTask := log.FromContext(ctx).Begin(zap.InfoLevel, "validate and add foreign keys")
+	db, err := rc.tidbGlue.GetDB()
+	if err != nil {
+		logTask.End(zap.ErrorLevel, err)
+		return errors.Trace(err)
+	}
+	exec := common.SQLWithRetry{DB: db, Logger: logTask.Logger}
+
+	var violations error
+	for _, dbMeta := range rc.dbMetas {
+		// the foreign keys added by the previous runs are skipped.
+		tables, err := rc.preInfoGetter.FetchRemoteTableModels(ctx, dbMeta.Name)
+		if err != nil {
+			logTask.End(zap.ErrorLevel, err)
+			return errors.Trace(err)
+		}
+		existing := make(map[string]map[string]struct{}, len(tables))
+		for _, table := range tables {
+			names := make(map[string]struct{}, len(table.ForeignKeys))
+			for _, fk := range table.ForeignKeys {
+				names[fk.Name.L] = struct{}{}
+			}
+			existing[table.Name.L] = names
+		}
+
+		for _, tblMeta := range dbMeta.Tables {
+			name := filter.Table{Schema: strings.ToLower(dbMeta.Name), Name: strings.ToLower(tblMeta.Name)}
+			for _, fk := range foreignKeys[name] {
+				if _, ok := existing[name.Name][strings.ToLower(fk.name)]; ok {
+					continue
+				}
+				var count int64
+				if err := exec.QueryRow(ctx, "validate foreign key", fk.validateSQL(), &count); err != nil {
+					logTask.End(zap.ErrorLevel, err)
+					return errors.Trace(err)
+				}
+				if count > 0 {
+					logTask.Error("the imported rows violate the foreign key",
+						zap.String("table", common.UniqueTable(fk.db, fk.table)),
+						zap.String("foreignKey", fk.name), zap.Int64("rows", count))
+					violations = multierr.Append(violations, common.ErrForeignKeyViolation.GenWithStackByArgs(
+						fk.name, common.UniqueTable(fk.db, fk.table), count, common.UniqueTable(fk.refDB, fk.refTable)))
+					continue
+				}
+				if err := exec.Exec(ctx, "add foreign key", fk.addSQL()); err != nil {
+					logTask.End(zap.ErrorLevel, err)
+					return errors.Trace(err)
+				}
+			}
+		}
+	}
+	logTask.End(zap.ErrorLevel, violations)
+	return violations
+}
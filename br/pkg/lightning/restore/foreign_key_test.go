@@ -0,0 +1,74 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/parser"
+	filter "github.com/pingcap/tidb/util/table-filter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseForeignKeys(t *testing.T) {
+	p := parser.New()
+	stmts, err := createIfNotExistsStmt(p, nil,
+		"CREATE TABLE `orders` (`id` INT PRIMARY KEY, `user_id` INT, `shop` INT, `item` INT, "+
+			"FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE, "+
+			"CONSTRAINT `fk_item` FOREIGN KEY (`shop`, `item`) REFERENCES `catalog`.`items` (`shop_id`, `id`));",
+		"db", "orders")
+	require.NoError(t, err)
+	fks, err := parseForeignKeys(p, stmts, "db", "orders")
+	require.NoError(t, err)
+	require.Len(t, fks, 2)
+
+	require.Equal(t, "orders_ibfk_1", fks[0].name)
+	require.Equal(t, filter.Table{Schema: "db", Name: "users"}, fks[0].refName())
+	require.Equal(t, "ALTER TABLE `db`.`orders` ADD CONSTRAINT `orders_ibfk_1` FOREIGN KEY (`user_id`) REFERENCES `db`.`users`(`id`) ON DELETE CASCADE",
+		fks[0].addSQL())
+	require.Equal(t, "SELECT COUNT(*) FROM `db`.`orders` AS c WHERE c.`user_id` IS NOT NULL AND "+
+		"NOT EXISTS (SELECT 1 FROM `db`.`users` AS p WHERE p.`id` = c.`user_id`)", fks[0].validateSQL())
+
+	require.Equal(t, "fk_item", fks[1].name)
+	require.Equal(t, filter.Table{Schema: "catalog", Name: "items"}, fks[1].refName())
+	require.Equal(t, "SELECT COUNT(*) FROM `db`.`orders` AS c WHERE c.`shop` IS NOT NULL AND c.`item` IS NOT NULL AND "+
+		"NOT EXISTS (SELECT 1 FROM `catalog`.`items` AS p WHERE p.`shop_id` = c.`shop` AND p.`id` = c.`item`)", fks[1].validateSQL())
+
+	stmts, err = removeForeignKeys(p, stmts)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"CREATE TABLE IF NOT EXISTS `db`.`orders` (`id` INT PRIMARY KEY,`user_id` INT,`shop` INT,`item` INT);",
+	}, stmts)
+	fks, err = parseForeignKeys(p, stmts, "db", "orders")
+	require.NoError(t, err)
+	require.Empty(t, fks)
+}
+
+func TestForeignKeyLevels(t *testing.T) {
+	tables := []filter.Table{
+		{Schema: "db", Name: "orders"},
+		{Schema: "db", Name: "Users"},
+		{Schema: "db", Name: "a"},
+		{Schema: "db", Name: "b"},
+	}
+	refs := [][]filter.Table{
+		{{Schema: "db", Name: "users"}, {Schema: "db", Name: "orders"}},
+		{{Schema: "other", Name: "t"}},
+		{{Schema: "db", Name: "b"}},
+		{{Schema: "db", Name: "a"}},
+	}
+	require.Equal(t, []int{1, 0, 1, 0}, foreignKeyLevels(log.L(), tables, refs))
+}
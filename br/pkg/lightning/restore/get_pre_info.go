@@ -19,9 +19,11 @@ import (
 	"database/sql"
 	"fmt"
 	"io"
+	"math"
 	"strings"
 
 	mysql_sql_driver "github.com/go-sql-driver/mysql"
+	"github.com/klauspost/compress/zstd"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tidb/br/pkg/lightning/backend"
@@ -61,6 +63,9 @@ type EstimateSourceDataSizeResult struct {
 	SizeWithoutIndex int64
 	// HasUnsortedBigTables indicates whether the source data has unsorted big tables or not.
 	HasUnsortedBigTables bool
+	// CompressionRatio is the ratio of the compressed size to the size with the index,
+	// learned from sampling the source data. Zero means unknown.
+	CompressionRatio float64
 }
 
 // PreRestoreInfoGetter defines the operations to get information from sources and target.
@@ -469,11 +474,13 @@ func (p *PreRestoreInfoGetterImpl) ReadFirstNRowsByFileMeta(ctx context.Context,
 
 	var parser mydump.Parser
 	blockBufSize := int64(p.cfg.Mydumper.ReadBlockSize)
+	// the rows after dataEnd are the trailing lines skipped by the file routing rule.
+	dataEnd := int64(math.MaxInt64)
 	switch dataFileMeta.Type {
 	case mydump.SourceTypeCSV:
 		hasHeader := p.cfg.Mydumper.CSV.Header
 		// Create a utf8mb4 convertor to encode and decode data with the charset of CSV files.
-		charsetConvertor, err := mydump.NewCharsetConvertor(p.cfg.Mydumper.DataCharacterSet, p.cfg.Mydumper.DataInvalidCharReplace)
+		charsetConvertor, err := mydump.NewCharsetConvertor(p.cfg.Mydumper.DataCharacterSet, p.cfg.Mydumper.DataInvalidCharReplace, p.cfg.Mydumper.DataInvalidCharAction)
 		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
@@ -481,8 +488,29 @@ func (p *PreRestoreInfoGetterImpl) ReadFirstNRowsByFileMeta(ctx context.Context,
 		if err != nil {
 			return nil, nil, errors.Trace(err)
 		}
+		if !hasHeader && len(dataFileMeta.Columns) > 0 {
+			parser.SetColumns(dataFileMeta.Columns)
+		}
+		if dataFileMeta.SkipLines > 0 || dataFileMeta.SkipFooterLines > 0 {
+			var dataStart int64
+			if dataStart, dataEnd, err = mydump.DataRange(ctx, p.srcStorage, dataFileMeta); err != nil {
+				_ = parser.Close()
+				return nil, nil, errors.Trace(err)
+			}
+			if err = parser.SetPos(dataStart, 0); err != nil {
+				_ = parser.Close()
+				return nil, nil, errors.Trace(err)
+			}
+		}
 	case mydump.SourceTypeSQL:
-		parser = mydump.NewChunkParser(ctx, p.cfg.TiDB.SQLMode, reader, blockBufSize, p.ioWorkers)
+		// Create a utf8mb4 convertor to decode the string literals of SQL files.
+		charsetConvertor, err := mydump.NewCharsetConvertor(p.cfg.Mydumper.DataCharacterSet, p.cfg.Mydumper.DataInvalidCharReplace, p.cfg.Mydumper.DataInvalidCharAction)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		parser = mydump.NewChunkParser(ctx, p.cfg.TiDB.SQLMode, reader, blockBufSize, p.ioWorkers, charsetConvertor)
+	case mydump.SourceTypeJSON:
+		parser = mydump.NewJSONParser(ctx, &p.cfg.Mydumper.JSON, reader, blockBufSize, p.ioWorkers)
 	case mydump.SourceTypeParquet:
 		parser, err = mydump.NewParquetParser(ctx, p.srcStorage, reader, dataFileMeta.Path)
 		if err != nil {
@@ -496,6 +524,9 @@ func (p *PreRestoreInfoGetterImpl) ReadFirstNRowsByFileMeta(ctx context.Context,
 
 	rows := [][]types.Datum{}
 	for i := 0; i < n; i++ {
+		if pos, _ := parser.Pos(); pos >= dataEnd {
+			break
+		}
 		err := parser.ReadRow()
 		if err != nil {
 			if errors.Cause(err) != io.EOF {
@@ -522,6 +553,7 @@ func (p *PreRestoreInfoGetterImpl) EstimateSourceDataSize(ctx context.Context) (
 		return result, nil
 	}
 	sizeWithIndex := int64(0)
+	compressedSize := float64(0)
 	sourceTotalSize := int64(0)
 	tableCount := 0
 	unSortedBigTableCount := 0
@@ -544,22 +576,24 @@ func (p *PreRestoreInfoGetterImpl) EstimateSourceDataSize(ctx context.Context) (
 				// time to sample data for all of them.
 				if isTiDBBackend(p.cfg) || tbl.TotalSize < int64(config.SplitRegionSize) {
 					sizeWithIndex += tbl.TotalSize
+					compressedSize += float64(tbl.TotalSize)
 					tbl.IndexRatio = 1.0
 					tbl.IsRowOrdered = false
 				} else {
-					sampledIndexRatio, isRowOrderedFromSample, err := p.sampleDataFromTable(ctx, db.Name, tbl, tableInfo.Core, errMgr, sysVars)
+					sampledIndexRatio, sampledCompressionRatio, isRowOrderedFromSample, err := p.sampleDataFromTable(ctx, db.Name, tbl, tableInfo.Core, errMgr, sysVars)
 					if err != nil {
 						return nil, errors.Trace(err)
 					}
 					tbl.IndexRatio = sampledIndexRatio
 					tbl.IsRowOrdered = isRowOrderedFromSample
 
+					tblSizeWithIndex := tbl.TotalSize
 					if tbl.IndexRatio > 0 {
-						sizeWithIndex += int64(float64(tbl.TotalSize) * tbl.IndexRatio)
-					} else {
-						// if sample data failed due to max-error, fallback to use source size
-						sizeWithIndex += tbl.TotalSize
+						tblSizeWithIndex = int64(float64(tbl.TotalSize) * tbl.IndexRatio)
 					}
+					// if sample data failed due to max-error, fallback to use source size
+					sizeWithIndex += tblSizeWithIndex
+					compressedSize += float64(tblSizeWithIndex) * sampledCompressionRatio
 
 					if tbl.TotalSize > int64(config.DefaultBatchSize)*2 && !tbl.IsRowOrdered {
 						unSortedBigTableCount++
@@ -575,12 +609,16 @@ func (p *PreRestoreInfoGetterImpl) EstimateSourceDataSize(ctx context.Context) (
 		SizeWithoutIndex:     sourceTotalSize,
 		HasUnsortedBigTables: (unSortedBigTableCount > 0),
 	}
+	if sizeWithIndex > 0 {
+		result.CompressionRatio = compressedSize / float64(sizeWithIndex)
+	}
 	return result, nil
 }
 
 // sampleDataFromTable samples the source data file to get the extra data ratio for the index
 // It returns:
 // * the extra data ratio with index size accounted
+// * the ratio of the compressed size to the size of the encoded KV pairs
 // * is the sample data ordered by row
 func (p *PreRestoreInfoGetterImpl) sampleDataFromTable(
 	ctx context.Context,
@@ -589,11 +627,12 @@ func (p *PreRestoreInfoGetterImpl) sampleDataFromTable(
 	tableInfo *model.TableInfo,
 	errMgr *errormanager.ErrorManager,
 	sysVars map[string]string,
-) (float64, bool, error) {
+) (float64, float64, bool, error) {
 	resultIndexRatio := 1.0
+	resultCompressionRatio := 1.0
 	isRowOrdered := false
 	if len(tableMeta.DataFiles) == 0 {
-		return resultIndexRatio, isRowOrdered, nil
+		return resultIndexRatio, resultCompressionRatio, isRowOrdered, nil
 	}
 	sampleFile := tableMeta.DataFiles[0].FileMeta
 	var reader storage.ReadSeekCloser
@@ -604,43 +643,73 @@ func (p *PreRestoreInfoGetterImpl) sampleDataFromTable(
 		reader, err = p.srcStorage.Open(ctx, sampleFile.Path)
 	}
 	if err != nil {
-		return 0.0, false, errors.Trace(err)
+		return 0.0, 0.0, false, errors.Trace(err)
 	}
 	idAlloc := kv.NewPanickingAllocators(0)
 	tbl, err := tables.TableFromMeta(idAlloc, tableInfo)
 	if err != nil {
-		return 0.0, false, errors.Trace(err)
+		return 0.0, 0.0, false, errors.Trace(err)
+	}
+	transforms, err := p.cfg.Mydumper.ColumnTransforms.GetColumnTransforms(dbName, tableMeta.Name, p.cfg.Mydumper.CaseSensitive)
+	if err != nil {
+		return 0.0, 0.0, false, errors.Trace(err)
 	}
 	kvEncoder, err := p.encBuilder.NewEncoder(ctx, tbl, &kv.SessionOptions{
-		SQLMode:        p.cfg.TiDB.SQLMode,
-		Timestamp:      0,
-		SysVars:        sysVars,
-		AutoRandomSeed: 0,
+		SQLMode:          p.cfg.TiDB.SQLMode,
+		Timestamp:        0,
+		SysVars:          sysVars,
+		AutoRandomSeed:   0,
+		ColumnTransforms: transforms,
 	})
 	if err != nil {
-		return 0.0, false, errors.Trace(err)
+		return 0.0, 0.0, false, errors.Trace(err)
 	}
 	blockBufSize := int64(p.cfg.Mydumper.ReadBlockSize)
 
 	var parser mydump.Parser
+	// the rows after dataEnd are the trailing lines skipped by the file routing rule.
+	dataEnd := int64(math.MaxInt64)
 	switch tableMeta.DataFiles[0].FileMeta.Type {
 	case mydump.SourceTypeCSV:
 		hasHeader := p.cfg.Mydumper.CSV.Header
 		// Create a utf8mb4 convertor to encode and decode data with the charset of CSV files.
-		charsetConvertor, err := mydump.NewCharsetConvertor(p.cfg.Mydumper.DataCharacterSet, p.cfg.Mydumper.DataInvalidCharReplace)
+		charsetConvertor, err := mydump.NewCharsetConvertor(p.cfg.Mydumper.DataCharacterSet, p.cfg.Mydumper.DataInvalidCharReplace, p.cfg.Mydumper.DataInvalidCharAction)
 		if err != nil {
-			return 0.0, false, errors.Trace(err)
+			return 0.0, 0.0, false, errors.Trace(err)
 		}
 		parser, err = mydump.NewCSVParser(ctx, &p.cfg.Mydumper.CSV, reader, blockBufSize, p.ioWorkers, hasHeader, charsetConvertor)
 		if err != nil {
-			return 0.0, false, errors.Trace(err)
+			return 0.0, 0.0, false, errors.Trace(err)
+		}
+		if !hasHeader && len(sampleFile.Columns) > 0 {
+			parser.SetColumns(sampleFile.Columns)
+		}
+		if sampleFile.SkipLines > 0 || sampleFile.SkipFooterLines > 0 {
+			var dataStart int64
+			if dataStart, dataEnd, err = mydump.DataRange(ctx, p.srcStorage, sampleFile); err != nil {
+				_ = parser.Close()
+				return 0.0, 0.0, false, errors.Trace(err)
+			}
+			if err = parser.SetPos(dataStart, 0); err != nil {
+				_ = parser.Close()
+				return 0.0, 0.0, false, errors.Trace(err)
+			}
 		}
 	case mydump.SourceTypeSQL:
-		parser = mydump.NewChunkParser(ctx, p.cfg.TiDB.SQLMode, reader, blockBufSize, p.ioWorkers)
+		// Create a utf8mb4 convertor to decode the string literals of SQL files.
+		charsetConvertor, err := mydump.NewCharsetConvertor(p.cfg.Mydumper.DataCharacterSet, p.cfg.Mydumper.DataInvalidCharReplace, p.cfg.Mydumper.DataInvalidCharAction)
+		if err != nil {
+			return 0.0, 0.0, false, errors.Trace(err)
+		}
+		parser = mydump.NewChunkParser(ctx, p.cfg.TiDB.SQLMode, reader, blockBufSize, p.ioWorkers, charsetConvertor)
+	case mydump.SourceTypeJSON:
+		jsonParser := mydump.NewJSONParser(ctx, &p.cfg.Mydumper.JSON, reader, blockBufSize, p.ioWorkers)
+		jsonParser.SetColumns(jsonColumnNames(tableInfo))
+		parser = jsonParser
 	case mydump.SourceTypeParquet:
 		parser, err = mydump.NewParquetParser(ctx, p.srcStorage, reader, sampleFile.Path)
 		if err != nil {
-			return 0.0, false, errors.Trace(err)
+			return 0.0, 0.0, false, errors.Trace(err)
 		}
 	default:
 		panic(fmt.Sprintf("file '%s' with unknown source type '%s'", sampleFile.Path, sampleFile.Type.String()))
@@ -650,7 +719,7 @@ func (p *PreRestoreInfoGetterImpl) sampleDataFromTable(
 	logTask := log.FromContext(ctx).With(zap.String("table", tableMeta.Name)).Begin(zap.InfoLevel, "sample file")
 	igCols, err := p.cfg.Mydumper.IgnoreColumns.GetIgnoreColumns(dbName, tableMeta.Name, p.cfg.Mydumper.CaseSensitive)
 	if err != nil {
-		return 0.0, false, errors.Trace(err)
+		return 0.0, 0.0, false, errors.Trace(err)
 	}
 
 	initializedColumns := false
@@ -661,10 +730,14 @@ func (p *PreRestoreInfoGetterImpl) sampleDataFromTable(
 	dataKVs := p.encBuilder.MakeEmptyRows()
 	indexKVs := p.encBuilder.MakeEmptyRows()
 	lastKey := make([]byte, 0)
+	sampledKVs := make([]byte, 0)
 	isRowOrdered = true
 outloop:
 	for {
 		offset, _ := parser.Pos()
+		if offset >= dataEnd {
+			break
+		}
 		err = parser.ReadRow()
 		columnNames := parser.Columns()
 
@@ -675,10 +748,11 @@ outloop:
 					columnPermutation, err = createColumnPermutation(
 						columnNames,
 						igCols.ColumnsMap(),
+						igCols.UnknownColumnsIgnored(p.cfg.Mydumper.IgnoreUnknownColumns),
 						tableInfo,
 						log.FromContext(ctx))
 					if err != nil {
-						return 0.0, false, errors.Trace(err)
+						return 0.0, 0.0, false, errors.Trace(err)
 					}
 				}
 				initializedColumns = true
@@ -687,37 +761,40 @@ outloop:
 			break outloop
 		default:
 			err = errors.Annotatef(err, "in file offset %d", offset)
-			return 0.0, false, errors.Trace(err)
+			return 0.0, 0.0, false, errors.Trace(err)
 		}
 		lastRow := parser.LastRow()
 		rowCount++
 
 		var dataChecksum, indexChecksum verification.KVChecksum
-		kvs, encodeErr := kvEncoder.Encode(logTask.Logger, lastRow.Row, lastRow.RowID, columnPermutation, sampleFile.Path, offset)
+		kvs, encodeErr := kv.EncodeRow(kvEncoder, logTask.Logger, lastRow.Row, lastRow.Missing, lastRow.RowID, columnPermutation, sampleFile.Path, offset)
 		if encodeErr != nil {
 			encodeErr = errMgr.RecordTypeError(ctx, log.FromContext(ctx), tableInfo.Name.O, sampleFile.Path, offset,
 				"" /* use a empty string here because we don't actually record */, encodeErr)
 			if encodeErr != nil {
-				return 0.0, false, errors.Annotatef(encodeErr, "in file at offset %d", offset)
+				return 0.0, 0.0, false, errors.Annotatef(encodeErr, "in file at offset %d", offset)
 			}
 			if rowCount < maxSampleRowCount {
 				continue
 			}
 			break
 		}
-		if isRowOrdered {
-			kvs.ClassifyAndAppend(&dataKVs, &dataChecksum, &indexKVs, &indexChecksum)
-			for _, kv := range kv.KvPairsFromRows(dataKVs) {
+		kvs.ClassifyAndAppend(&dataKVs, &dataChecksum, &indexKVs, &indexChecksum)
+		for _, pair := range kv.KvPairsFromRows(dataKVs) {
+			if isRowOrdered {
 				if len(lastKey) == 0 {
-					lastKey = kv.Key
-				} else if bytes.Compare(lastKey, kv.Key) > 0 {
+					lastKey = pair.Key
+				} else if bytes.Compare(lastKey, pair.Key) > 0 {
 					isRowOrdered = false
-					break
 				}
 			}
-			dataKVs = dataKVs.Clear()
-			indexKVs = indexKVs.Clear()
+			sampledKVs = append(append(sampledKVs, pair.Key...), pair.Val...)
+		}
+		for _, pair := range kv.KvPairsFromRows(indexKVs) {
+			sampledKVs = append(append(sampledKVs, pair.Key...), pair.Val...)
 		}
+		dataKVs = dataKVs.Clear()
+		indexKVs = indexKVs.Clear()
 		kvSize += kvs.Size()
 		rowSize += uint64(lastRow.Length)
 		parser.RecycleRow(lastRow)
@@ -733,8 +810,21 @@ outloop:
 	if rowSize > 0 && kvSize > rowSize {
 		resultIndexRatio = float64(kvSize) / float64(rowSize)
 	}
-	log.FromContext(ctx).Info("Sample source data", zap.String("table", tableMeta.Name), zap.Float64("IndexRatio", tableMeta.IndexRatio), zap.Bool("IsSourceOrder", tableMeta.IsRowOrdered))
-	return resultIndexRatio, isRowOrdered, nil
+	if len(sampledKVs) > 0 {
+		// TiKV compresses the SST files by zstd in the bottommost level, which holds most of the data.
+		encoder, err := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return 0.0, 0.0, false, errors.Trace(err)
+		}
+		compressed := encoder.EncodeAll(sampledKVs, nil)
+		_ = encoder.Close()
+		if len(compressed) < len(sampledKVs) {
+			resultCompressionRatio = float64(len(compressed)) / float64(len(sampledKVs))
+		}
+	}
+	log.FromContext(ctx).Info("Sample source data", zap.String("table", tableMeta.Name), zap.Float64("IndexRatio", tableMeta.IndexRatio),
+		zap.Float64("CompressionRatio", resultCompressionRatio), zap.Bool("IsSourceOrder", tableMeta.IsRowOrdered))
+	return resultIndexRatio, resultCompressionRatio, isRowOrdered, nil
 }
 
 // GetReplicationConfig gets the replication config on the target.
@@ -443,10 +443,12 @@ func TestGetPreInfoSampleSource(t *testing.T) {
 	}
 	for _, subTest := range subTests {
 		require.NoError(t, mockSrc.GetStorage().WriteFile(ctx, dataFileName, subTest.Data))
-		sampledIndexRatio, isRowOrderedFromSample, err := ig.sampleDataFromTable(ctx, "db01", mdTblMeta, dbInfos["db01"].Tables["tbl01"].Core, nil, defaultImportantVariables)
+		sampledIndexRatio, sampledCompressionRatio, isRowOrderedFromSample, err := ig.sampleDataFromTable(ctx, "db01", mdTblMeta, dbInfos["db01"].Tables["tbl01"].Core, nil, defaultImportantVariables)
 		require.NoError(t, err)
-		t.Logf("%v, %v", sampledIndexRatio, isRowOrderedFromSample)
+		t.Logf("%v, %v, %v", sampledIndexRatio, sampledCompressionRatio, isRowOrderedFromSample)
 		require.Greater(t, sampledIndexRatio, 1.0)
+		require.Greater(t, sampledCompressionRatio, 0.0)
+		require.LessOrEqual(t, sampledCompressionRatio, 1.0)
 		require.Equal(t, subTest.ExpectIsOrdered, isRowOrderedFromSample)
 	}
 }
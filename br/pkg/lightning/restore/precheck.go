@@ -2,6 +2,7 @@ package restore
 
 import (
 	"context"
+	"sync"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
@@ -27,11 +28,29 @@ const (
 	CheckLocalTempKVDir           CheckItemID = "CHECK_LOCAL_TEMP_KV_DIR"
 )
 
+var builtinCheckItems = map[CheckItemID]struct{}{
+	CheckLargeDataFile:            {},
+	CheckSourcePermission:         {},
+	CheckTargetTableEmpty:         {},
+	CheckSourceSchemaValid:        {},
+	CheckCheckpoints:              {},
+	CheckCSVHeader:                {},
+	CheckTargetClusterSize:        {},
+	CheckTargetClusterEmptyRegion: {},
+	CheckTargetClusterRegionDist:  {},
+	CheckTargetClusterVersion:     {},
+	CheckLocalDiskPlacement:       {},
+	CheckLocalTempKVDir:           {},
+}
+
 type CheckResult struct {
 	Item     CheckItemID
 	Severity CheckType
 	Passed   bool
 	Message  string
+	// Details is the computation details of the check if any,
+	// e.g. *ClusterResourceDetails for CheckTargetClusterSize.
+	Details any
 }
 
 type PrecheckItem interface {
@@ -41,6 +60,54 @@ type PrecheckItem interface {
 	GetCheckItemID() CheckItemID
 }
 
+// PrecheckItemFactory creates a custom precheck item for an import task.
+// The source data is described by dbMetas, and the target is accessed by preInfoGetter.
+type PrecheckItemFactory func(
+	cfg *config.Config,
+	preInfoGetter PreRestoreInfoGetter,
+	dbMetas []*mydump.MDDatabaseMeta,
+) (PrecheckItem, error)
+
+var customPrecheckItems = struct {
+	sync.RWMutex
+	ids       []CheckItemID
+	factories map[CheckItemID]PrecheckItemFactory
+}{
+	factories: make(map[CheckItemID]PrecheckItemFactory),
+}
+
+// RegisterPrecheckItem registers a custom precheck item, such as checking the naming conventions or
+// forbidding some collations. The registered items run along with the built-in ones in the order of registration
+// when `check-requirements` is enabled, and their results are aggregated into the same precheck report.
+// It's expected to be called in the `init` function of the package providing the item.
+func RegisterPrecheckItem(checkID CheckItemID, factory PrecheckItemFactory) error {
+	if _, ok := builtinCheckItems[checkID]; ok {
+		return errors.Errorf("check item %v is a built-in one", checkID)
+	}
+	customPrecheckItems.Lock()
+	defer customPrecheckItems.Unlock()
+	if _, ok := customPrecheckItems.factories[checkID]; ok {
+		return errors.Errorf("check item %v is already registered", checkID)
+	}
+	customPrecheckItems.ids = append(customPrecheckItems.ids, checkID)
+	customPrecheckItems.factories[checkID] = factory
+	return nil
+}
+
+// RegisteredPrecheckItems returns the IDs of the custom precheck items in the order of registration.
+func RegisteredPrecheckItems() []CheckItemID {
+	customPrecheckItems.RLock()
+	defer customPrecheckItems.RUnlock()
+	return append([]CheckItemID(nil), customPrecheckItems.ids...)
+}
+
+func getCustomPrecheckItemFactory(checkID CheckItemID) (PrecheckItemFactory, bool) {
+	customPrecheckItems.RLock()
+	defer customPrecheckItems.RUnlock()
+	factory, ok := customPrecheckItems.factories[checkID]
+	return factory, ok
+}
+
 type precheckContextKey string
 
 const taskManagerKey precheckContextKey = "PRECHECK/TASK_MANAGER"
@@ -177,6 +244,10 @@ func (b *PrecheckItemBuilder) BuildPrecheckItem(checkID CheckItemID) (PrecheckIt
 	case CheckLocalTempKVDir:
 		return NewLocalTempKVDirCheckItem(b.cfg, b.preInfoGetter), nil
 	default:
+		if factory, ok := getCustomPrecheckItemFactory(checkID); ok {
+			theChecker, err := factory(b.cfg, b.preInfoGetter, b.dbMetas)
+			return theChecker, errors.Trace(err)
+		}
 		return nil, errors.Errorf("unsupported check item: %v", checkID)
 	}
 }
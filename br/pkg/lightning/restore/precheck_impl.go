@@ -58,16 +58,83 @@ func (ci *clusterResourceCheckItem) GetCheckItemID() CheckItemID {
 	return CheckTargetClusterSize
 }
 
-func (ci *clusterResourceCheckItem) getClusterAvail(ctx context.Context) (uint64, error) {
+const (
+	// compactionOverheadRatio is the space amplification of the RocksDB level compaction in TiKV,
+	// the imported SST files and the compacted ones may coexist before the obsolete files are deleted.
+	compactionOverheadRatio = 1.1
+	// regionOverheadBytes is the space taken by the raft logs and the meta of a region on one replica.
+	regionOverheadBytes = 64 * units.KiB
+	// pdLowSpaceRatio is the default `low-space-ratio` of PD, a store whose used space is over this
+	// ratio of its capacity is regarded as in low space and may reject the written data.
+	pdLowSpaceRatio = 0.8
+)
+
+// ClusterResourceDetails is the computation details of the cluster resource check.
+type ClusterResourceDetails struct {
+	// SourceSize is the size of the KV pairs encoded from the source data.
+	SourceSize uint64
+	// CompressionRatio is the ratio of the compressed size to the KV size learned from sampling the source files.
+	CompressionRatio float64
+	// ReplicaCount is the replica count of the target cluster.
+	ReplicaCount uint64
+	// RegionCount is the estimated count of the regions holding the imported data.
+	RegionCount uint64
+	// RegionOverhead is the space taken by the meta of the imported regions of all the replicas.
+	RegionOverhead uint64
+	// CompactionOverheadRatio is the space amplification of compaction in TiKV.
+	CompactionOverheadRatio float64
+	// EstimatedSize is the space needed by the import task.
+	EstimatedSize uint64
+	// ClusterCapacity is the total capacity of the stores.
+	ClusterCapacity uint64
+	// ClusterUsed is the space already used in the stores.
+	ClusterUsed uint64
+	// ClusterAvailable is the available space reported by the stores.
+	ClusterAvailable uint64
+	// ClusterUsable is the space which can be used by the import task before the stores run in low space.
+	ClusterUsable uint64
+}
+
+func (d *ClusterResourceDetails) String() string {
+	return fmt.Sprintf("source size %s, compression ratio %.2f, replica count %d, region overhead %s for %d regions, "+
+		"compaction overhead ratio %.2f; cluster capacity %s, used %s, available %s, usable %s",
+		units.BytesSize(float64(d.SourceSize)), d.CompressionRatio, d.ReplicaCount,
+		units.BytesSize(float64(d.RegionOverhead)), d.RegionCount, d.CompactionOverheadRatio,
+		units.BytesSize(float64(d.ClusterCapacity)), units.BytesSize(float64(d.ClusterUsed)),
+		units.BytesSize(float64(d.ClusterAvailable)), units.BytesSize(float64(d.ClusterUsable)))
+}
+
+// estimate computes the space needed by the import task and the space the cluster can provide.
+func (d *ClusterResourceDetails) estimate() {
+	compressedSize := float64(d.SourceSize) * d.CompressionRatio
+	d.RegionCount = uint64(compressedSize) / uint64(config.SplitRegionSize)
+	d.RegionOverhead = d.RegionCount * d.ReplicaCount * regionOverheadBytes
+	d.EstimatedSize = uint64(compressedSize*float64(d.ReplicaCount)*d.CompactionOverheadRatio) + d.RegionOverhead
+
+	d.ClusterUsable = d.ClusterAvailable
+	// Keep the stores away from the low space, only if PD reports the capacity.
+	if d.ClusterCapacity > 0 {
+		lowSpaceLimit := uint64(float64(d.ClusterCapacity) * pdLowSpaceRatio)
+		if lowSpaceLimit <= d.ClusterUsed {
+			d.ClusterUsable = 0
+		} else if lowSpaceLimit-d.ClusterUsed < d.ClusterUsable {
+			d.ClusterUsable = lowSpaceLimit - d.ClusterUsed
+		}
+	}
+}
+
+func (ci *clusterResourceCheckItem) getClusterSpace(ctx context.Context, details *ClusterResourceDetails) error {
 	storeInfo, err := ci.preInfoGetter.GetStorageInfo(ctx)
 	if err != nil {
-		return 0, errors.Trace(err)
+		return errors.Trace(err)
 	}
-	clusterAvail := uint64(0)
+	details.ClusterCapacity, details.ClusterUsed, details.ClusterAvailable = 0, 0, 0
 	for _, store := range storeInfo.Stores {
-		clusterAvail += uint64(store.Status.Available)
+		details.ClusterCapacity += uint64(store.Status.Capacity)
+		details.ClusterUsed += uint64(store.Status.UsedSize)
+		details.ClusterAvailable += uint64(store.Status.Available)
 	}
-	return clusterAvail, nil
+	return nil
 }
 
 func (ci *clusterResourceCheckItem) getReplicaCount(ctx context.Context) (uint64, error) {
@@ -87,11 +154,13 @@ func (ci *clusterResourceCheckItem) Check(ctx context.Context) (*CheckResult, er
 	}
 
 	var (
-		err           error
-		clusterAvail  uint64
-		clusterSource uint64
-		taskMgr       taskMetaMgr
+		err     error
+		taskMgr taskMetaMgr
 	)
+	details := &ClusterResourceDetails{
+		CompressionRatio:        1.0,
+		CompactionOverheadRatio: compactionOverheadRatio,
+	}
 	taskMgrVal := ctx.Value(taskManagerKey)
 	if taskMgrVal != nil {
 		if mgr, ok := taskMgrVal.(taskMetaMgr); ok {
@@ -104,36 +173,39 @@ func (ci *clusterResourceCheckItem) Check(ctx context.Context) (*CheckResult, er
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		clusterSource = uint64(estimatedDataSizeResult.SizeWithIndex)
-		clusterAvail, err = ci.getClusterAvail(ctx)
-		if err != nil {
+		details.SourceSize = uint64(estimatedDataSizeResult.SizeWithIndex)
+		if estimatedDataSizeResult.CompressionRatio > 0 {
+			details.CompressionRatio = estimatedDataSizeResult.CompressionRatio
+		}
+		if err = ci.getClusterSpace(ctx, details); err != nil {
 			return nil, errors.Trace(err)
 		}
 	} else {
+		// The source data of the parallel import tasks are not sampled here, so they are regarded as incompressible.
 		if err := taskMgr.CheckTasksExclusively(ctx, func(tasks []taskMeta) ([]taskMeta, error) {
-			clusterAvail = 0
-			clusterSource = 0
+			clusterAvail := uint64(0)
+			details.SourceSize = 0
 			restoreStarted := false
 			for _, task := range tasks {
 				if task.status > taskMetaStatusInitial {
 					restoreStarted = true
 				}
-				clusterSource += task.sourceBytes
+				details.SourceSize += task.sourceBytes
 				if task.clusterAvail > 0 {
 					clusterAvail = task.clusterAvail
 				}
 			}
 			if restoreStarted || clusterAvail > 0 {
+				details.ClusterAvailable = clusterAvail
 				return nil, nil
 			}
 
-			clusterAvail, err = ci.getClusterAvail(ctx)
-			if err != nil {
+			if err = ci.getClusterSpace(ctx, details); err != nil {
 				return nil, errors.Trace(err)
 			}
 			newTasks := append([]taskMeta(nil), tasks...)
 			for i := 0; i < len(newTasks); i++ {
-				newTasks[i].clusterAvail = clusterAvail
+				newTasks[i].clusterAvail = details.ClusterAvailable
 			}
 			return newTasks, nil
 		}); err != nil {
@@ -141,18 +213,19 @@ func (ci *clusterResourceCheckItem) Check(ctx context.Context) (*CheckResult, er
 		}
 	}
 
-	replicaCount, err := ci.getReplicaCount(ctx)
+	details.ReplicaCount, err = ci.getReplicaCount(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	estimateSize := clusterSource * replicaCount
-	if estimateSize > clusterAvail {
+	details.estimate()
+	theResult.Details = details
+	if details.EstimatedSize > details.ClusterUsable {
 		theResult.Passed = false
-		theResult.Message = fmt.Sprintf("Cluster doesn't have enough space, available is %s, but we need %s",
-			units.BytesSize(float64(clusterAvail)), units.BytesSize(float64(estimateSize)))
+		theResult.Message = fmt.Sprintf("Cluster doesn't have enough space, available is %s, but we need %s (%s)",
+			units.BytesSize(float64(details.ClusterUsable)), units.BytesSize(float64(details.EstimatedSize)), details)
 	} else {
-		theResult.Message = fmt.Sprintf("Cluster available is rich, available is %s, we need %s",
-			units.BytesSize(float64(clusterAvail)), units.BytesSize(float64(estimateSize)))
+		theResult.Message = fmt.Sprintf("Cluster available is rich, available is %s, we need %s (%s)",
+			units.BytesSize(float64(details.ClusterUsable)), units.BytesSize(float64(details.EstimatedSize)), details)
 	}
 	return theResult, nil
 }
@@ -495,11 +568,16 @@ func (ci *localTempKVDirCheckItem) Check(ctx context.Context) (*CheckResult, err
 		Item:     ci.GetCheckItemID(),
 		Severity: Critical,
 	}
-	storageSize, err := common.GetStorageSize(ci.cfg.TikvImporter.SortedKVDir)
-	if err != nil {
-		return nil, errors.Trace(err)
+	// the spill dirs are expected to be on the other disks.
+	var localAvailable int64
+	for _, dir := range ci.cfg.TikvImporter.SortedKVDirs() {
+		storageSize, err := common.GetStorageSize(dir)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		localAvailable += int64(storageSize.Available)
 	}
-	localAvailable := int64(storageSize.Available)
+	diskQuota := ci.cfg.TikvImporter.TotalDiskQuota()
 	estimatedDataSizeResult, err := ci.preInfoGetter.EstimateSourceDataSize(ctx)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -511,7 +589,7 @@ func (ci *localTempKVDirCheckItem) Check(ctx context.Context) (*CheckResult, err
 		theResult.Message = fmt.Sprintf("local disk resources are rich, estimate sorted data size %s, local available is %s",
 			units.BytesSize(float64(estimatedDataSizeWithIndex)), units.BytesSize(float64(localAvailable)))
 		theResult.Passed = true
-	case int64(ci.cfg.TikvImporter.DiskQuota) > localAvailable:
+	case int64(diskQuota) > localAvailable:
 		theResult.Message = fmt.Sprintf("local disk space may not enough to finish import, estimate sorted data size is %s,"+
 			" but local available is %s, please set `tikv-importer.disk-quota` to a smaller value than %s"+
 			" or change `mydumper.sorted-kv-dir` to another disk with enough space to finish imports",
@@ -524,7 +602,7 @@ func (ci *localTempKVDirCheckItem) Check(ctx context.Context) (*CheckResult, err
 			"estimate sorted data size is %s, but local available is %s,"+
 			"we will use disk-quota (size: %s) to finish imports, which may slow down import",
 			units.BytesSize(float64(estimatedDataSizeWithIndex)),
-			units.BytesSize(float64(localAvailable)), units.BytesSize(float64(ci.cfg.TikvImporter.DiskQuota)))
+			units.BytesSize(float64(localAvailable)), units.BytesSize(float64(diskQuota)))
 		theResult.Passed = true
 		log.FromContext(ctx).Warn(theResult.Message)
 	}
@@ -658,7 +736,12 @@ func (ci *checkpointCheckItem) checkpointIsValid(ctx context.Context, tableInfo
 	}
 	info := dbInfos[tableInfo.DB].Tables[tableInfo.Name]
 	if info != nil {
-		permFromTiDB, err := parseColumnPermutations(info.Core, columns, nil, log.FromContext(ctx))
+		igCols, err := ci.cfg.Mydumper.IgnoreColumns.GetIgnoreColumns(tableInfo.DB, tableInfo.Name, ci.cfg.Mydumper.CaseSensitive)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		ignoreUnknownColumns := igCols.UnknownColumnsIgnored(ci.cfg.Mydumper.IgnoreUnknownColumns)
+		permFromTiDB, err := parseColumnPermutations(info.Core, columns, nil, ignoreUnknownColumns, log.FromContext(ctx))
 		if err != nil {
 			msgs = append(msgs, fmt.Sprintf("failed to calculate columns %s, table %s's info has changed,"+
 				"consider remove this checkpoint, and start import again.", err.Error(), uniqueName))
@@ -748,6 +831,7 @@ func (ci *schemaCheckItem) SchemaIsValid(ctx context.Context, tableInfo *mydump.
 		return nil, errors.Trace(err)
 	}
 	igCols := igCol.ColumnsMap()
+	ignoreUnknownColumns := igCol.UnknownColumnsIgnored(ci.cfg.Mydumper.IgnoreUnknownColumns)
 
 	colCountFromTiDB := len(info.Core.Columns)
 	core := info.Core
@@ -768,7 +852,7 @@ func (ci *schemaCheckItem) SchemaIsValid(ctx context.Context, tableInfo *mydump.
 	// get columns name from data file.
 	dataFileMeta := dataFile.FileMeta
 
-	if tp := dataFileMeta.Type; tp != mydump.SourceTypeCSV && tp != mydump.SourceTypeSQL && tp != mydump.SourceTypeParquet {
+	if tp := dataFileMeta.Type; tp != mydump.SourceTypeCSV && tp != mydump.SourceTypeSQL && tp != mydump.SourceTypeParquet && tp != mydump.SourceTypeJSON {
 		msgs = append(msgs, fmt.Sprintf("file '%s' with unknown source type '%s'", dataFileMeta.Path, dataFileMeta.Type.String()))
 		return msgs, nil
 	}
@@ -823,6 +907,9 @@ func (ci *schemaCheckItem) SchemaIsValid(ctx context.Context, tableInfo *mydump.
 	colMap[model.ExtraHandleName.String()] = struct{}{}
 	for _, col := range colsFromDataFile {
 		if _, ok := colMap[col]; !ok {
+			if ignoreUnknownColumns {
+				continue
+			}
 			checkMsg := "please check table schema"
 			if dataFileMeta.Type == mydump.SourceTypeCSV && ci.cfg.Mydumper.CSV.Header {
 				checkMsg += " and csv file header"
@@ -155,6 +155,38 @@ func (s *precheckImplSuite) TestClusterResourceCheckBasic() {
 	s.Require().Equal(CheckTargetClusterSize, result.Item)
 	s.T().Logf("check result message: %s", result.Message)
 	s.Require().True(result.Passed)
+	details, ok := result.Details.(*ClusterResourceDetails)
+	s.Require().True(ok)
+	s.Require().Equal(uint64(1000), details.ClusterCapacity)
+	s.Require().Equal(uint64(900), details.ClusterAvailable)
+	// the used space is limited by the low space ratio of PD.
+	s.Require().Equal(uint64(700), details.ClusterUsable)
+	s.Require().Greater(details.EstimatedSize, details.SourceSize*details.ReplicaCount)
+
+	// the stores are already in low space.
+	s.mockTarget.StorageInfos[0].UsedSize = 850
+	s.mockTarget.StorageInfos[0].AvailableSize = 150
+	result, err = ci.Check(ctx)
+	s.Require().NoError(err)
+	s.T().Logf("check result message: %s", result.Message)
+	s.Require().False(result.Passed)
+}
+
+func (s *precheckImplSuite) TestClusterResourceDetailsEstimate() {
+	details := &ClusterResourceDetails{
+		SourceSize:              10 * uint64(config.SplitRegionSize),
+		CompressionRatio:        0.5,
+		ReplicaCount:            3,
+		CompactionOverheadRatio: compactionOverheadRatio,
+		ClusterCapacity:         100 * uint64(config.SplitRegionSize),
+		ClusterUsed:             30 * uint64(config.SplitRegionSize),
+		ClusterAvailable:        70 * uint64(config.SplitRegionSize),
+	}
+	details.estimate()
+	s.Require().Equal(uint64(5), details.RegionCount)
+	s.Require().Equal(uint64(5*3*regionOverheadBytes), details.RegionOverhead)
+	s.Require().Equal(uint64(5*3*1.1*float64(config.SplitRegionSize))+details.RegionOverhead, details.EstimatedSize)
+	s.Require().Equal(50*uint64(config.SplitRegionSize), details.ClusterUsable)
 }
 
 func (s *precheckImplSuite) TestClusterVersionCheckBasic() {
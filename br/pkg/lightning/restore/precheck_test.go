@@ -14,9 +14,12 @@
 package restore
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
 	"github.com/pingcap/tidb/br/pkg/lightning/restore/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -51,3 +54,77 @@ func TestPrecheckBuilderBasic(t *testing.T) {
 		require.Equal(t, checkItemID, theChecker.GetCheckItemID())
 	}
 }
+
+type tableNameCheckItem struct {
+	dbMetas []*mydump.MDDatabaseMeta
+}
+
+func (ci *tableNameCheckItem) GetCheckItemID() CheckItemID {
+	return "CHECK_TABLE_NAME_PREFIX"
+}
+
+func (ci *tableNameCheckItem) Check(ctx context.Context) (*CheckResult, error) {
+	theResult := &CheckResult{
+		Item:     ci.GetCheckItemID(),
+		Severity: Critical,
+		Passed:   true,
+		Message:  "all the table names start with 't_'",
+	}
+	for _, db := range ci.dbMetas {
+		for _, tbl := range db.Tables {
+			if !strings.HasPrefix(tbl.Name, "t_") {
+				theResult.Passed = false
+				theResult.Message = "table " + tbl.Name + " doesn't start with 't_'"
+			}
+		}
+	}
+	return theResult, nil
+}
+
+func TestRegisterPrecheckItem(t *testing.T) {
+	const checkItemID CheckItemID = "CHECK_TABLE_NAME_PREFIX"
+	defer func() {
+		customPrecheckItems.Lock()
+		customPrecheckItems.ids = nil
+		delete(customPrecheckItems.factories, checkItemID)
+		customPrecheckItems.Unlock()
+	}()
+	factory := func(cfg *config.Config, preInfoGetter PreRestoreInfoGetter, dbMetas []*mydump.MDDatabaseMeta) (PrecheckItem, error) {
+		return &tableNameCheckItem{dbMetas: dbMetas}, nil
+	}
+	require.NoError(t, RegisterPrecheckItem(checkItemID, factory))
+	require.ErrorContains(t, RegisterPrecheckItem(checkItemID, factory), "already registered")
+	require.ErrorContains(t, RegisterPrecheckItem(CheckTargetClusterSize, factory), "built-in")
+	require.Equal(t, []CheckItemID{checkItemID}, RegisteredPrecheckItems())
+
+	mockSrc, err := mock.NewMockImportSource(map[string]*mock.MockDBSourceData{
+		"db01": {
+			Name: "db01",
+			Tables: map[string]*mock.MockTableSourceData{
+				"tbl01": {
+					DBName:    "db01",
+					TableName: "tbl01",
+					SchemaFile: &mock.MockSourceFile{
+						FileName: "/db01/tbl01/tbl01.schema.sql",
+						Data:     []byte("CREATE TABLE db01.tbl01 (id INTEGER PRIMARY KEY);"),
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	cfg := config.NewConfig()
+	cfg.TikvImporter.Backend = config.BackendLocal
+	preInfoGetter, err := NewPreRestoreInfoGetter(cfg, mockSrc.GetAllDBFileMetas(), mockSrc.GetStorage(), mock.NewMockTargetInfo(), nil, nil)
+	require.NoError(t, err)
+	template := NewSimpleTemplate()
+	rc := &Controller{
+		cfg:                 cfg,
+		checkTemplate:       template,
+		precheckItemBuilder: NewPrecheckItemBuilder(cfg, mockSrc.GetAllDBFileMetas(), preInfoGetter, nil),
+	}
+	require.NoError(t, rc.checkCustomItems(context.Background()))
+	require.False(t, template.Success())
+	require.Equal(t, 1, template.FailedCount(Critical))
+	require.Contains(t, template.FailedMsg(), "table tbl01 doesn't start with 't_'")
+}
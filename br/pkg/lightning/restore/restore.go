@@ -44,6 +44,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/br/pkg/lightning/metric"
 	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/lightning/notify"
 	"github.com/pingcap/tidb/br/pkg/lightning/tikv"
 	verify "github.com/pingcap/tidb/br/pkg/lightning/verification"
 	"github.com/pingcap/tidb/br/pkg/lightning/web"
@@ -59,6 +60,7 @@ import (
 	"github.com/pingcap/tidb/store/driver"
 	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/mathutil"
+	filter "github.com/pingcap/tidb/util/table-filter"
 	pd "github.com/tikv/pd/client"
 	"go.uber.org/atomic"
 	"go.uber.org/multierr"
@@ -199,7 +201,10 @@ type Controller struct {
 	checksumWorks *worker.Pool
 	pauser        *common.Pauser
 	backend       backend.Backend
-	tidbGlue      glue.Glue
+	// backendDB is the connections of the TiDB backend with the session variables, nil if
+	// the backend shares the connections of tidbGlue.
+	backendDB *sql.DB
+	tidbGlue  glue.Glue
 
 	alterTableLock sync.Mutex
 	sysVars        map[string]string
@@ -226,6 +231,11 @@ type Controller struct {
 
 	preInfoGetter       PreRestoreInfoGetter
 	precheckItemBuilder *PrecheckItemBuilder
+
+	// foreignKeys is the foreign keys in the schema files, loaded in the "ordered" foreign key mode.
+	foreignKeys map[filter.Table][]*foreignKey
+
+	onImported func(context.Context) error
 }
 
 type LightningStatus struct {
@@ -251,6 +261,9 @@ type ControllerParam struct {
 	CheckpointStorage storage.ExternalStorage
 	// when CheckpointStorage is not nil, save file checkpoint to it with this name
 	CheckpointName string
+	// called after all the tables are imported and before the checkpoints are cleaned,
+	// so nothing done in it is lost if lightning exits between the two steps
+	OnImported func(context.Context) error
 }
 
 func NewRestoreController(
@@ -275,10 +288,14 @@ func NewRestoreControllerWithPauser(
 	var cpdb checkpoints.DB
 	// if CheckpointStorage is set, we should use given ExternalStorage to create checkpoints.
 	if p.CheckpointStorage != nil {
-		cpdb, err = checkpoints.NewFileCheckpointsDBWithExstorageFileName(ctx, p.CheckpointStorage.URI(), p.CheckpointStorage, p.CheckpointName)
+		fileCpdb, err := checkpoints.NewFileCheckpointsDBWithExstorageFileName(ctx, p.CheckpointStorage.URI(), p.CheckpointStorage, p.CheckpointName)
 		if err != nil {
 			return nil, common.ErrOpenCheckpoint.Wrap(err).GenWithStackByArgs()
 		}
+		if err := fileCpdb.SetCompression(cfg.Checkpoint.Compression); err != nil {
+			return nil, common.ErrOpenCheckpoint.Wrap(err).GenWithStackByArgs()
+		}
+		cpdb = fileCpdb
 	} else {
 		cpdb, err = p.Glue.OpenCheckpointsDB(ctx, cfg)
 		if err != nil {
@@ -312,9 +329,19 @@ func NewRestoreControllerWithPauser(
 	}
 
 	var backend backend.Backend
+	var backendDB *sql.DB
 	switch cfg.TikvImporter.Backend {
 	case config.BackendTiDB:
-		backend = tidb.NewTiDBBackend(ctx, db, cfg.TikvImporter.OnDuplicate, errorMgr)
+		rowsDB := db
+		// the session variables only apply to the connections writing the rows.
+		if len(cfg.TikvImporter.SessionVars) > 0 {
+			backendDB, err = dbWithSessionVars(ctx, cfg.TiDB, cfg.TikvImporter.SessionVars)
+			if err != nil {
+				return nil, common.ErrDBConnect.Wrap(err)
+			}
+			rowsDB = backendDB
+		}
+		backend = tidb.NewTiDBBackendWithMergeColumns(ctx, rowsDB, cfg.TikvImporter.OnDuplicate, cfg.TikvImporter.MergeColumns, errorMgr)
 	case config.BackendLocal:
 		var rLimit local.Rlim_t
 		rLimit, err = local.GetSystemRLimit()
@@ -342,7 +369,7 @@ func NewRestoreControllerWithPauser(
 		if err != nil {
 			return nil, common.NormalizeOrWrapErr(common.ErrUnknown, err)
 		}
-		err = verifyLocalFile(ctx, cpdb, cfg.TikvImporter.SortedKVDir)
+		err = verifyLocalFile(ctx, cpdb, cfg.TikvImporter.SortedKVDirs())
 		if err != nil {
 			return nil, err
 		}
@@ -401,6 +428,7 @@ func NewRestoreControllerWithPauser(
 		checksumWorks: worker.NewPool(ctx, cfg.TiDB.ChecksumTableConcurrency, "checksum"),
 		pauser:        p.Pauser,
 		backend:       backend,
+		backendDB:     backendDB,
 		tidbGlue:      p.Glue,
 		sysVars:       defaultImportantVariables,
 		tls:           tls,
@@ -417,6 +445,7 @@ func NewRestoreControllerWithPauser(
 		errorMgr:       errorMgr,
 		status:         p.Status,
 		taskMgr:        nil,
+		onImported:     p.OnImported,
 
 		preInfoGetter:       preInfoGetter,
 		precheckItemBuilder: preCheckBuilder,
@@ -427,6 +456,9 @@ func NewRestoreControllerWithPauser(
 
 func (rc *Controller) Close() {
 	rc.backend.Close()
+	if rc.backendDB != nil {
+		_ = rc.backendDB.Close()
+	}
 	rc.tidbGlue.GetSQLExecutor().Close()
 }
 
@@ -437,6 +469,7 @@ func (rc *Controller) Run(ctx context.Context) error {
 		rc.preCheckRequirements,
 		rc.initCheckpoint,
 		rc.restoreTables,
+		rc.addForeignKeys,
 		rc.fullCompact,
 		rc.cleanCheckpoints,
 	}
@@ -486,6 +519,12 @@ func (stmtType schemaStmtType) String() string {
 		return "restore table schema"
 	case schemaCreateView:
 		return "restore view schema"
+	case schemaCreateSequence:
+		return "restore sequence schema"
+	case schemaCreateRoutine:
+		return "restore stored routines"
+	case schemaCreateTrigger:
+		return "restore triggers"
 	}
 	return "unknown statement of schema"
 }
@@ -494,6 +533,9 @@ const (
 	schemaCreateDatabase schemaStmtType = iota
 	schemaCreateTable
 	schemaCreateView
+	schemaCreateSequence
+	schemaCreateRoutine
+	schemaCreateTrigger
 )
 
 type schemaJob struct {
@@ -501,6 +543,18 @@ type schemaJob struct {
 	tblName  string // empty for create db jobs
 	stmtType schemaStmtType
 	stmts    []string
+	// warnOnError means the failure of the job is only logged instead of failing the
+	// import, since TiDB doesn't support some objects such as stored routines yet.
+	warnOnError bool
+}
+
+// objectName is the name of the object created by the job, which is recorded in the
+// checkpoints after the object is created.
+func (job *schemaJob) objectName() string {
+	if job.tblName == "" {
+		return common.EscapeIdentifier(job.dbName)
+	}
+	return common.UniqueTable(job.dbName, job.tblName)
 }
 
 type restoreSchemaWorker struct {
@@ -512,14 +566,42 @@ type restoreSchemaWorker struct {
 	wg     sync.WaitGroup
 	glue   glue.Glue
 	store  storage.ExternalStorage
+	// rewriter rewrites the unsupported charsets, collations and engines of the schemas.
+	rewriter *schemaRewriter
+	// deferForeignKeys creates the tables without their foreign keys, which are added after
+	// the tables are imported.
+	deferForeignKeys bool
+
+	// created is the objects created by the previous runs, which are skipped, and the
+	// objects created by this run are saved to cpdb after each stage.
+	cpdb        checkpoints.DB
+	created     map[string]struct{}
+	newCreated  []string
+	newCreateMu sync.Mutex
 }
 
-func (worker *restoreSchemaWorker) addJob(sqlStr string, job *schemaJob) error {
-	stmts, err := createIfNotExistsStmt(worker.glue.GetParser(), sqlStr, job.dbName, job.tblName)
+// isCreated checks whether the object of the job has been created by the previous runs.
+func (worker *restoreSchemaWorker) isCreated(job *schemaJob) bool {
+	_, ok := worker.created[job.objectName()]
+	return ok
+}
+
+func (worker *restoreSchemaWorker) prepareJob(sqlStr string, job *schemaJob) error {
+	stmts, err := createIfNotExistsStmt(worker.glue.GetParser(), worker.rewriter, sqlStr, job.dbName, job.tblName)
 	if err != nil {
 		return err
 	}
 	job.stmts = stmts
+	return nil
+}
+
+func (worker *restoreSchemaWorker) addJob(sqlStr string, job *schemaJob) error {
+	if worker.isCreated(job) {
+		return nil
+	}
+	if err := worker.prepareJob(sqlStr, job); err != nil {
+		return err
+	}
 	return worker.appendJob(job)
 }
 
@@ -548,7 +630,33 @@ func (worker *restoreSchemaWorker) makeJobs(
 	if err != nil {
 		return err
 	}
-	// 2. restore tables, execute statements concurrency
+	// 2. restore sequences, which may be used by the default values of the tables.
+	for _, dbMeta := range dbMetas {
+		for _, seqMeta := range dbMeta.Sequences {
+			sql, err := seqMeta.GetSchema(worker.ctx, worker.store)
+			if err != nil {
+				return err
+			}
+			if sql != "" {
+				err = worker.addJob(sql, &schemaJob{
+					dbName:   dbMeta.Name,
+					tblName:  seqMeta.Name,
+					stmtType: schemaCreateSequence,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	err = worker.wait()
+	if err != nil {
+		return err
+	}
+	// 3. restore tables, execute statements concurrency. The tables referenced by the foreign
+	// keys are created before the referencing tables.
+	tableJobs := make([]*schemaJob, 0)
+	tableRefs := make([][]filter.Table, 0)
 	for _, dbMeta := range dbMetas {
 		// we can ignore error here, and let check failed later if schema not match
 		tables, _ := getTables(worker.ctx, dbMeta.Name)
@@ -561,54 +669,113 @@ func (worker *restoreSchemaWorker) makeJobs(
 				// we already has this table in TiDB.
 				// we should skip ddl job and let SchemaValid check.
 				continue
-			} else if tblMeta.SchemaFile.FileMeta.Path == "" {
+			} else if !tblMeta.HasSchema() {
 				return common.ErrSchemaNotExists.GenWithStackByArgs(dbMeta.Name, tblMeta.Name)
 			}
+			job := &schemaJob{
+				dbName:   dbMeta.Name,
+				tblName:  tblMeta.Name,
+				stmtType: schemaCreateTable,
+			}
+			if worker.isCreated(job) {
+				continue
+			}
 			sql, err := tblMeta.GetSchema(worker.ctx, worker.store)
 			if err != nil {
 				return err
 			}
-			if sql != "" {
-				err = worker.addJob(sql, &schemaJob{
-					dbName:   dbMeta.Name,
-					tblName:  tblMeta.Name,
-					stmtType: schemaCreateTable,
-				})
-				if err != nil {
+			if sql == "" {
+				continue
+			}
+			if err = worker.prepareJob(sql, job); err != nil {
+				return err
+			}
+			refs, err := foreignKeyReferences(worker.glue.GetParser(), job.stmts, dbMeta.Name)
+			if err != nil {
+				return err
+			}
+			if worker.deferForeignKeys {
+				if job.stmts, err = removeForeignKeys(worker.glue.GetParser(), job.stmts); err != nil {
 					return err
 				}
 			}
+			tableJobs = append(tableJobs, job)
+			tableRefs = append(tableRefs, refs)
 		}
 	}
-	err = worker.wait()
+	for _, jobs := range groupTableJobsByForeignKeys(worker.logger, tableJobs, tableRefs) {
+		for _, job := range jobs {
+			if err = worker.appendJob(job); err != nil {
+				return err
+			}
+		}
+		err = worker.wait()
+		if err != nil {
+			return err
+		}
+	}
+	// 4. restore views. Since views can cross database we must restore views after all table schemas are restored,
+	// and in the order of their dependencies. The views in the same level don't reference each other, so they
+	// are restored concurrently.
+	views, err := mydump.SortViews(dbMetas)
 	if err != nil {
 		return err
 	}
-	// 3. restore views. Since views can cross database we must restore views after all table schemas are restored.
-	for _, dbMeta := range dbMetas {
-		for _, viewMeta := range dbMeta.Views {
+	for _, level := range mydump.GroupViewsByLevel(views) {
+		for _, viewMeta := range level {
 			sql, err := viewMeta.GetSchema(worker.ctx, worker.store)
-			if sql != "" {
-				err = worker.addJob(sql, &schemaJob{
-					dbName:   dbMeta.Name,
-					tblName:  viewMeta.Name,
-					stmtType: schemaCreateView,
-				})
+			if err != nil {
+				return err
+			}
+			if sql == "" {
+				continue
+			}
+			err = worker.addJob(sql, &schemaJob{
+				dbName:   viewMeta.DB,
+				tblName:  viewMeta.Name,
+				stmtType: schemaCreateView,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		err = worker.wait()
+		if err != nil {
+			return err
+		}
+	}
+	// 5. restore stored routines and triggers after all tables and views exist. The statements
+	// are executed as is since the parser doesn't support them.
+	for _, dbMeta := range dbMetas {
+		for _, job := range []struct {
+			metas    []*mydump.MDTableMeta
+			stmtType schemaStmtType
+		}{
+			{dbMeta.Procedures, schemaCreateRoutine},
+			{dbMeta.Triggers, schemaCreateTrigger},
+		} {
+			for _, meta := range job.metas {
+				stmts, err := meta.GetRoutineStatements(worker.ctx, worker.store)
 				if err != nil {
 					return err
 				}
-				// we don't support restore views concurrency, cauz it maybe will raise a error
-				err = worker.wait()
+				if len(stmts) == 0 {
+					continue
+				}
+				err = worker.appendJob(&schemaJob{
+					dbName:      dbMeta.Name,
+					tblName:     meta.Name,
+					stmtType:    job.stmtType,
+					stmts:       append([]string{"USE " + common.EscapeIdentifier(dbMeta.Name)}, stmts...),
+					warnOnError: true,
+				})
 				if err != nil {
 					return err
 				}
 			}
-			if err != nil {
-				return err
-			}
 		}
 	}
-	return nil
+	return worker.wait()
 }
 
 func (worker *restoreSchemaWorker) doJob() {
@@ -657,6 +824,11 @@ loop:
 				task := logger.Begin(zap.DebugLevel, fmt.Sprintf("execute SQL: %s", stmt))
 				err = sqlWithRetry.Exec(worker.ctx, "run create schema job", stmt)
 				task.End(zap.ErrorLevel, err)
+				if err != nil && job.warnOnError {
+					logger.Warn("failed to restore schema, skipped",
+						zap.Stringer("type", job.stmtType), zap.String("sql", stmt), log.ShortError(err))
+					break
+				}
 				if err != nil {
 					err = common.ErrCreateSchema.Wrap(err).GenWithStackByArgs(common.UniqueTable(job.dbName, job.tblName), job.stmtType.String())
 					worker.wg.Done()
@@ -665,6 +837,12 @@ loop:
 					break loop
 				}
 			}
+			// the stored routines and triggers are not recorded since their failures are skipped.
+			if !job.warnOnError {
+				worker.newCreateMu.Lock()
+				worker.newCreated = append(worker.newCreated, job.objectName())
+				worker.newCreateMu.Unlock()
+			}
 			worker.wg.Done()
 		}
 	}
@@ -691,8 +869,21 @@ func (worker *restoreSchemaWorker) wait() error {
 	case <-worker.ctx.Done():
 		return worker.ctx.Err()
 	case <-waitCh:
+		return worker.saveCreated()
+	}
+}
+
+// saveCreated records the objects created since the last call in the checkpoints, so
+// that they are skipped if the schemas are restored again after an interruption.
+func (worker *restoreSchemaWorker) saveCreated() error {
+	worker.newCreateMu.Lock()
+	names := worker.newCreated
+	worker.newCreated = nil
+	worker.newCreateMu.Unlock()
+	if len(names) == 0 {
 		return nil
 	}
+	return errors.Trace(worker.cpdb.AddCreatedSchemaObjects(worker.ctx, names))
 }
 
 func (worker *restoreSchemaWorker) throw(err error) {
@@ -721,26 +912,57 @@ func (worker *restoreSchemaWorker) appendJob(job *schemaJob) error {
 	}
 }
 
+// groupTableJobsByForeignKeys groups the jobs creating tables into levels, the tables of each
+// level only reference the tables of the previous levels or the tables out of the jobs by their
+// foreign keys. refs[i] is the tables referenced by jobs[i]. The circular references are ignored.
+func groupTableJobsByForeignKeys(logger log.Logger, jobs []*schemaJob, refs [][]filter.Table) [][]*schemaJob {
+	tables := make([]filter.Table, 0, len(jobs))
+	for _, job := range jobs {
+		tables = append(tables, filter.Table{Schema: job.dbName, Name: job.tblName})
+	}
+	var groups [][]*schemaJob
+	for i, level := range foreignKeyLevels(logger, tables, refs) {
+		for len(groups) <= level {
+			groups = append(groups, nil)
+		}
+		groups[level] = append(groups[level], jobs[i])
+	}
+	return groups
+}
+
 func (rc *Controller) restoreSchema(ctx context.Context) error {
 	// create table with schema file
 	// we can handle the duplicated created with createIfNotExist statement
 	// and we will check the schema in TiDB is valid with the datafile in DataCheck later.
 	logTask := log.FromContext(ctx).Begin(zap.InfoLevel, "restore all schema")
 	concurrency := mathutil.Min(rc.cfg.App.RegionConcurrency, 8)
+	created, err := rc.checkpointsDB.CreatedSchemaObjects(ctx)
+	if err != nil {
+		logTask.End(zap.ErrorLevel, err)
+		return errors.Trace(err)
+	}
+	if len(created) > 0 {
+		logTask.Info("skip the schema objects created by the previous run", zap.Int("count", len(created)))
+	}
 	childCtx, cancel := context.WithCancel(ctx)
 	worker := restoreSchemaWorker{
-		ctx:    childCtx,
-		quit:   cancel,
-		logger: log.FromContext(ctx),
-		jobCh:  make(chan *schemaJob, concurrency),
-		errCh:  make(chan error),
-		glue:   rc.tidbGlue,
-		store:  rc.store,
+		ctx:      childCtx,
+		quit:     cancel,
+		logger:   log.FromContext(ctx),
+		jobCh:    make(chan *schemaJob, concurrency),
+		errCh:    make(chan error),
+		glue:     rc.tidbGlue,
+		store:    rc.store,
+		rewriter: newSchemaRewriter(&rc.cfg.Mydumper.SchemaRewrite),
+		cpdb:     rc.checkpointsDB,
+		created:  created,
+
+		deferForeignKeys: rc.cfg.App.ForeignKeyMode == config.ForeignKeyModeOrdered,
 	}
 	for i := 0; i < concurrency; i++ {
 		go worker.doJob()
 	}
-	err := worker.makeJobs(rc.dbMetas, rc.preInfoGetter.FetchRemoteTableModels)
+	err = worker.makeJobs(rc.dbMetas, rc.preInfoGetter.FetchRemoteTableModels)
 	logTask.End(zap.ErrorLevel, err)
 	if err != nil {
 		return err
@@ -842,16 +1064,23 @@ func verifyCheckpoint(cfg *config.Config, taskCp *checkpoints.TaskCheckpoint) er
 }
 
 // for local backend, we should check if local SST exists in disk, otherwise we'll lost data
-func verifyLocalFile(ctx context.Context, cpdb checkpoints.DB, dir string) error {
+// verifyLocalFile checks the engines of the unfinished tables exist in one of the dirs,
+// i.e. the sorted-kv-dir and the spill dirs.
+func verifyLocalFile(ctx context.Context, cpdb checkpoints.DB, dirs []string) error {
 	targetTables, err := cpdb.GetLocalStoringTables(ctx)
 	if err != nil {
 		return errors.Trace(err)
 	}
+	dir := strings.Join(dirs, ",")
 	for tableName, engineIDs := range targetTables {
 		for _, engineID := range engineIDs {
 			_, eID := backend.MakeUUID(tableName, engineID)
 			file := local.Engine{UUID: eID}
-			err := file.Exist(dir)
+			for _, d := range dirs {
+				if err = file.Exist(d); err == nil || !os.IsNotExist(err) {
+					break
+				}
+			}
 			if err != nil {
 				log.FromContext(ctx).Error("can't find local file",
 					zap.String("table name", tableName),
@@ -992,7 +1221,18 @@ func (rc *Controller) listenCheckpointUpdates(logger log.Logger) {
 	defer close(hasCheckpoint)
 
 	go func() {
+		var lastUpdate time.Time
 		for range hasCheckpoint {
+			// wait until the interval has passed since the last update before taking
+			// the changes, so the changes in the meantime are merged and the checkpoints
+			// DB won't be updated too frequently when there are a huge number of chunks.
+			if wait := rc.cfg.Checkpoint.UpdateInterval.Duration - time.Since(lastUpdate); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-rc.taskCtx.Done():
+				}
+			}
+
 			lock.Lock()
 			cpd := coalesed
 			coalesed = make(map[string]*checkpoints.TableCheckpointDiff)
@@ -1009,6 +1249,7 @@ func (rc *Controller) listenCheckpointUpdates(logger log.Logger) {
 					w <- common.NormalizeOrWrapErr(common.ErrUpdateCheckpoint, err)
 				}
 				web.BroadcastCheckpointDiff(cpd)
+				lastUpdate = time.Now()
 			}
 			rc.checkpointsWg.Done()
 		}
@@ -1376,6 +1617,59 @@ func (rc *Controller) keepPauseGCForDupeRes(ctx context.Context) (<-chan struct{
 	return exitCh, nil
 }
 
+const throttleScheduleInterval = time.Minute
+
+// keepThrottleSchedule applies the throttle window of the current time to the region workers
+// and the backend until ctx is done, so the import slows down in the configured time windows
+// without restarting the task.
+func (rc *Controller) keepThrottleSchedule(ctx context.Context) <-chan struct{} {
+	exitCh := make(chan struct{})
+	go func() {
+		defer close(exitCh)
+		var current *config.ThrottleWindow
+		apply := func(w *config.ThrottleWindow) {
+			ratio := 1.0
+			bwLimit := int(rc.cfg.TikvImporter.StoreWriteBWLimit)
+			if w != nil {
+				ratio = w.Ratio
+				if w.StoreWriteBWLimit > 0 {
+					bwLimit = int(w.StoreWriteBWLimit)
+				}
+			}
+			regionConcurrency := int(math.Ceil(ratio * float64(rc.regionWorkers.Capacity())))
+			rc.regionWorkers.SetLimit(regionConcurrency)
+			rc.backend.Throttle(ratio, bwLimit)
+			logger := log.FromContext(ctx).With(
+				zap.Int("region-concurrency", regionConcurrency),
+				zap.Float64("ratio", ratio),
+				zap.Int("store-write-bwlimit", bwLimit))
+			if w != nil {
+				logger.Info("enter throttle window", zap.String("start", w.Start), zap.String("end", w.End))
+			} else {
+				logger.Info("leave throttle window, import at full speed")
+			}
+		}
+
+		if current = rc.cfg.App.ThrottleWindowAt(time.Now()); current != nil {
+			apply(current)
+		}
+		ticker := time.NewTicker(throttleScheduleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if w := rc.cfg.App.ThrottleWindowAt(time.Now()); w != current {
+					current = w
+					apply(current)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return exitCh
+}
+
 func (rc *Controller) restoreTables(ctx context.Context) (finalErr error) {
 	// output error summary
 	defer rc.outpuErrorSummary()
@@ -1393,6 +1687,15 @@ func (rc *Controller) restoreTables(ctx context.Context) (finalErr error) {
 		}()
 	}
 
+	if len(rc.cfg.App.ThrottleSchedule) > 0 {
+		subCtx, cancel := context.WithCancel(ctx)
+		exitCh := rc.keepThrottleSchedule(subCtx)
+		defer func() {
+			cancel()
+			<-exitCh
+		}()
+	}
+
 	logTask := log.FromContext(ctx).Begin(zap.InfoLevel, "restore all tables data")
 	if rc.tableWorkers == nil {
 		rc.tableWorkers = worker.NewPool(ctx, rc.cfg.App.TableConcurrency, "table")
@@ -1534,6 +1837,7 @@ func (rc *Controller) restoreTables(ctx context.Context) (finalErr error) {
 				err = common.NormalizeOrWrapErr(common.ErrRestoreTable, err, task.tr.tableName)
 				tableLogTask.End(zap.ErrorLevel, err)
 				web.BroadcastError(task.tr.tableName, err)
+				notifyTableError(ctx, task.tr.tableName, err)
 				if m, ok := metric.FromContext(ctx); ok {
 					m.RecordTableCount(metric.TableStateCompleted, err)
 				}
@@ -1568,6 +1872,7 @@ func (rc *Controller) restoreTables(ctx context.Context) (finalErr error) {
 			if err != nil {
 				return errors.Trace(err)
 			}
+			tr.ignoreUnknownColumns = igCols.UnknownColumnsIgnored(rc.cfg.Mydumper.IgnoreUnknownColumns)
 
 			allTasks = append(allTasks, task{tr: tr, cp: cp})
 
@@ -1589,12 +1894,49 @@ func (rc *Controller) restoreTables(ctx context.Context) (finalErr error) {
 		m.BytesCounter.WithLabelValues(metric.BytesStateTotalRestore).Add(float64(totalDataSizeToRestore))
 	}
 
-	for i := range allTasks {
-		wg.Add(1)
-		select {
-		case taskCh <- allTasks[i]:
-		case <-ctx.Done():
-			return ctx.Err()
+	// in the "ordered" foreign key mode, the referenced tables are imported before the
+	// referencing ones, and a level of tables starts after the previous levels finished.
+	taskLevels := [][]task{allTasks}
+	if rc.cfg.App.ForeignKeyMode == config.ForeignKeyModeOrdered {
+		foreignKeys, err := rc.loadForeignKeys(ctx)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		tables := make([]filter.Table, 0, len(allTasks))
+		refs := make([][]filter.Table, 0, len(allTasks))
+		for _, t := range allTasks {
+			table := filter.Table{Schema: t.tr.dbInfo.Name, Name: t.tr.tableInfo.Name}
+			tables = append(tables, table)
+			var tableRefs []filter.Table
+			for _, fk := range foreignKeys[filter.Table{Schema: strings.ToLower(table.Schema), Name: strings.ToLower(table.Name)}] {
+				tableRefs = append(tableRefs, fk.refName())
+			}
+			refs = append(refs, tableRefs)
+		}
+		taskLevels = nil
+		for i, level := range foreignKeyLevels(logTask.Logger, tables, refs) {
+			for len(taskLevels) <= level {
+				taskLevels = append(taskLevels, nil)
+			}
+			taskLevels[level] = append(taskLevels[level], allTasks[i])
+		}
+	}
+
+	for i, tasks := range taskLevels {
+		if i > 0 {
+			wg.Wait()
+			// the referencing tables are not imported if any referenced table failed.
+			if restoreErr.Get() != nil {
+				break
+			}
+		}
+		for j := range tasks {
+			wg.Add(1)
+			select {
+			case taskCh <- tasks[j]:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 		}
 	}
 
@@ -1622,6 +1964,7 @@ func (rc *Controller) restoreTables(ctx context.Context) (finalErr error) {
 					metaMgr := rc.metaMgrBuilder.TableMetaMgr(task.tr)
 					// force all the remain post-process tasks to be executed
 					_, err2 := task.tr.postProcess(ctx, rc, task.cp, true, metaMgr)
+					notifyTableError(ctx, task.tr.tableName, err2)
 					restoreErr.Set(err2)
 				}
 			}()
@@ -1633,6 +1976,23 @@ func (rc *Controller) restoreTables(ctx context.Context) (finalErr error) {
 	return nil
 }
 
+// notifyTableError notifies the failure of the table. A checksum mismatch is
+// notified as its own event so it can be routed separately.
+func notifyTableError(ctx context.Context, tableName string, err error) {
+	if err == nil || common.IsContextCanceledError(err) {
+		return
+	}
+	notifier, ok := notify.FromContext(ctx)
+	if !ok {
+		return
+	}
+	event := &notify.Event{Type: config.EventTableFailed, Table: tableName, Error: err.Error()}
+	if berrors.Is(err, common.ErrChecksumMismatch) {
+		event.Type = config.EventChecksumMismatch
+	}
+	notifier.Notify(ctx, event)
+}
+
 func (tr *TableRestore) restoreTable(
 	ctx context.Context,
 	rc *Controller,
@@ -1845,7 +2205,7 @@ func (rc *Controller) enforceDiskQuota(ctx context.Context) {
 				isRetrying = true
 			}
 
-			quota := int64(rc.cfg.TikvImporter.DiskQuota)
+			quota := int64(rc.cfg.TikvImporter.TotalDiskQuota())
 			largeEngines, inProgressLargeEngines, totalDiskSize, totalMemSize := rc.backend.CheckDiskQuota(quota)
 			if m, ok := metric.FromContext(ctx); ok {
 				m.LocalStorageUsageBytesGauge.WithLabelValues("disk").Set(float64(totalDiskSize))
@@ -1927,6 +2287,12 @@ func (rc *Controller) waitCheckpointFinish() {
 func (rc *Controller) cleanCheckpoints(ctx context.Context) error {
 	rc.waitCheckpointFinish()
 
+	if rc.onImported != nil {
+		if err := rc.onImported(ctx); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
 	if !rc.cfg.Checkpoint.Enable {
 		return nil
 	}
@@ -1982,6 +2348,10 @@ func (rc *Controller) preCheckRequirements(ctx context.Context) error {
 				return errors.Trace(err)
 			}
 		}
+
+		if err := rc.checkCustomItems(ctx); err != nil {
+			return errors.Trace(err)
+		}
 	}
 
 	if err := rc.metaMgrBuilder.Init(ctx); err != nil {
@@ -2124,13 +2494,23 @@ func newChunkRestore(
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	// the checksum can only be verified if the chunk covers the whole file.
+	if chunk.FileMeta.Type != mydump.SourceTypeParquet &&
+		chunk.Chunk.Offset == 0 && chunk.Chunk.EndOffset >= chunk.FileMeta.FileSize {
+		checksumReader, err := mydump.NewChecksumReader(reader, chunk.FileMeta)
+		if err != nil {
+			_ = reader.Close()
+			return nil, errors.Trace(err)
+		}
+		reader = checksumReader
+	}
 
 	var parser mydump.Parser
 	switch chunk.FileMeta.Type {
 	case mydump.SourceTypeCSV:
 		hasHeader := cfg.Mydumper.CSV.Header && chunk.Chunk.Offset == 0
 		// Create a utf8mb4 convertor to encode and decode data with the charset of CSV files.
-		charsetConvertor, err := mydump.NewCharsetConvertor(cfg.Mydumper.DataCharacterSet, cfg.Mydumper.DataInvalidCharReplace)
+		charsetConvertor, err := mydump.NewCharsetConvertor(cfg.Mydumper.DataCharacterSet, cfg.Mydumper.DataInvalidCharReplace, cfg.Mydumper.DataInvalidCharAction)
 		if err != nil {
 			return nil, err
 		}
@@ -2139,12 +2519,34 @@ func newChunkRestore(
 			return nil, errors.Trace(err)
 		}
 	case mydump.SourceTypeSQL:
-		parser = mydump.NewChunkParser(ctx, cfg.TiDB.SQLMode, reader, blockBufSize, ioWorkers)
+		// Create a utf8mb4 convertor to decode the string literals of SQL files.
+		charsetConvertor, err := mydump.NewCharsetConvertor(cfg.Mydumper.DataCharacterSet, cfg.Mydumper.DataInvalidCharReplace, cfg.Mydumper.DataInvalidCharAction)
+		if err != nil {
+			return nil, err
+		}
+		chunkParser := mydump.NewChunkParser(ctx, cfg.TiDB.SQLMode, reader, blockBufSize, ioWorkers, charsetConvertor)
+		// the TiDB backend inserts the rows by the INSERT IGNORE, REPLACE or ON DUPLICATE KEY UPDATE
+		// statements of the data files like they are executed.
+		if cfg.TikvImporter.Backend == config.BackendTiDB {
+			chunkParser.EnableInsertModifiers()
+		}
+		parser = chunkParser
+	case mydump.SourceTypeJSON:
+		jsonParser := mydump.NewJSONParser(ctx, &cfg.Mydumper.JSON, reader, blockBufSize, ioWorkers)
+		if tableInfo != nil {
+			jsonParser.SetColumns(jsonColumnNames(tableInfo.Core))
+		}
+		parser = jsonParser
 	case mydump.SourceTypeParquet:
-		parser, err = mydump.NewParquetParser(ctx, store, reader, chunk.FileMeta.Path)
+		parquetParser, err := mydump.NewParquetParser(ctx, store, reader, chunk.FileMeta.Path)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		parser = parquetParser
+		if err = setupParquetParser(parquetParser, cfg, tableInfo); err != nil {
+			_ = parser.Close()
+			return nil, errors.Trace(err)
+		}
 	default:
 		panic(fmt.Sprintf("file '%s' with unknown source type '%s'", chunk.Key.Path, chunk.FileMeta.Type.String()))
 	}
@@ -2163,10 +2565,51 @@ func newChunkRestore(
 	}, nil
 }
 
+// setupParquetParser makes the parquet parser only read the columns of the target
+// table and skip the rows not satisfying the row filter.
+func setupParquetParser(parser *mydump.ParquetParser, cfg *config.Config, tableInfo *checkpoints.TidbTableInfo) error {
+	if tableInfo == nil {
+		return nil
+	}
+	rowFilter, err := cfg.Mydumper.RowFilters.GetRowFilter(tableInfo.DB, tableInfo.Name, cfg.Mydumper.CaseSensitive)
+	if err != nil {
+		return err
+	}
+	ignoreColumns, err := cfg.Mydumper.IgnoreColumns.GetIgnoreColumns(tableInfo.DB, tableInfo.Name, cfg.Mydumper.CaseSensitive)
+	if err != nil {
+		return err
+	}
+	ignored := make(map[string]struct{}, len(ignoreColumns.Columns))
+	for _, col := range ignoreColumns.Columns {
+		ignored[strings.ToLower(col)] = struct{}{}
+	}
+	readColumns := make([]string, 0, len(tableInfo.Core.Columns)+1)
+	for _, col := range tableInfo.Core.Columns {
+		if _, ok := ignored[col.Name.L]; !ok {
+			readColumns = append(readColumns, col.Name.L)
+		}
+	}
+	readColumns = append(readColumns, model.ExtraHandleName.L)
+	parser.SetReadColumns(readColumns)
+	return parser.SetRowFilter(rowFilter)
+}
+
 func (cr *chunkRestore) close() {
 	_ = cr.parser.Close()
 }
 
+// jsonColumnNames returns the columns which the keys of the JSON objects are matched
+// with, i.e. the non-generated columns of the table.
+func jsonColumnNames(tableInfo *model.TableInfo) []string {
+	names := make([]string, 0, len(tableInfo.Columns))
+	for _, col := range tableInfo.Columns {
+		if !col.IsGenerated() {
+			names = append(names, col.Name.O)
+		}
+	}
+	return names
+}
+
 func getColumnNames(tableInfo *model.TableInfo, permutation []int) []string {
 	colIndexes := make([]int, 0, len(permutation))
 	for i := 0; i < len(permutation); i++ {
@@ -2452,6 +2895,23 @@ func (cr *chunkRestore) encodeLoop(
 		err = err1
 		return
 	}
+	// in CSV strict mode, the malformed rows are rejected instead of failing
+	// the chunk, and expectedColumns is the number of fields in a row.
+	var (
+		csvParser       *mydump.CSVParser
+		rejected        *errormanager.RejectedRowsWriter
+		expectedColumns int
+	)
+	if p, ok := cr.parser.(*mydump.CSVParser); ok && rc.errorMgr != nil && rc.errorMgr.RejectsRows() {
+		csvParser = p
+		rejected = rc.errorMgr.NewRejectedRowsWriter(
+			t.dbInfo.Name+"."+t.tableInfo.Core.Name.O, cr.chunk.Key.Path, cr.chunk.Chunk.Offset)
+		defer func() {
+			if closeErr := rejected.Close(ctx); err == nil {
+				err = closeErr
+			}
+		}()
+	}
 	for !reachEOF {
 		if err = pauser.Wait(ctx); err != nil {
 			return
@@ -2483,22 +2943,36 @@ func (cr *chunkRestore) encodeLoop(
 						}
 					}
 					filteredColumns = columnNames
-					if ignoreColumns != nil && len(ignoreColumns.Columns) > 0 {
+					if len(columnNames) > 0 {
+						// the TiDB backend only inserts the values of the columns of the table.
 						filteredColumns = make([]string, 0, len(columnNames))
 						ignoreColsMap := ignoreColumns.ColumnsMap()
-						if len(columnNames) > 0 {
-							for _, c := range columnNames {
-								if _, ok := ignoreColsMap[c]; !ok {
-									filteredColumns = append(filteredColumns, c)
-								}
+						for _, c := range columnNames {
+							if _, ok := ignoreColsMap[c]; ok {
+								continue
 							}
-						} else {
-							// init column names by table schema
-							// after filtered out some columns, we must explicitly set the columns for TiDB backend
-							for _, col := range t.tableInfo.Core.Columns {
-								if _, ok := ignoreColsMap[col.Name.L]; !col.Hidden && !ok {
-									filteredColumns = append(filteredColumns, col.Name.O)
-								}
+							if c != model.ExtraHandleName.L && model.FindColumnInfo(t.tableInfo.Core.Columns, c) == nil {
+								continue
+							}
+							filteredColumns = append(filteredColumns, c)
+						}
+					} else if len(ignoreColumns.Columns) > 0 {
+						// init column names by table schema
+						// after filtered out some columns, we must explicitly set the columns for TiDB backend
+						filteredColumns = make([]string, 0, len(t.tableInfo.Core.Columns))
+						ignoreColsMap := ignoreColumns.ColumnsMap()
+						for _, col := range t.tableInfo.Core.Columns {
+							if _, ok := ignoreColsMap[col.Name.L]; !col.Hidden && !ok {
+								filteredColumns = append(filteredColumns, col.Name.O)
+							}
+						}
+					}
+					if len(columnNames) > 0 {
+						expectedColumns = len(columnNames)
+					} else {
+						for _, col := range t.tableInfo.Core.Columns {
+							if !col.Hidden {
+								expectedColumns++
 							}
 						}
 					}
@@ -2508,20 +2982,54 @@ func (cr *chunkRestore) encodeLoop(
 				reachEOF = true
 				break outLoop
 			default:
-				err = common.ErrEncodeKV.Wrap(err).GenWithStackByArgs(&cr.chunk.Key, newOffset)
-				return
+				if rejected == nil {
+					err = common.ErrEncodeKV.Wrap(err).GenWithStackByArgs(&cr.chunk.Key, newOffset)
+					return
+				}
+				readErr := err
+				skipped, skipErr := csvParser.SkipMalformedRecord()
+				if !skipped || skipErr != nil {
+					err = common.ErrEncodeKV.Wrap(firstErr(skipErr, readErr)).GenWithStackByArgs(&cr.chunk.Key, newOffset)
+					return
+				}
+				line, raw := csvParser.LastRecord()
+				if err = rejected.Record(ctx, line, curOffset, raw, readErr); err != nil {
+					err = common.ErrEncodeKV.Wrap(err).GenWithStackByArgs(&cr.chunk.Key, newOffset)
+					return
+				}
+				readDur += time.Since(readDurStart)
+				newOffset, rowID = cr.parser.Pos()
+				curOffset = newOffset
+				if newOffset >= cr.chunk.Chunk.EndOffset {
+					break outLoop
+				}
+				continue
 			}
 			readDur += time.Since(readDurStart)
 			encodeDurStart := time.Now()
 			lastRow := cr.parser.LastRow()
 			// sql -> kv
-			kvs, encodeErr := kvEncoder.Encode(logger, lastRow.Row, lastRow.RowID, cr.chunk.ColumnPermutation, cr.chunk.Key.Path, curOffset)
+			var kvs kv.Row
+			var encodeErr error
+			if rejected != nil && len(lastRow.Row) != expectedColumns {
+				encodeErr = errors.Errorf("column count mismatch, expected %d, got %d", expectedColumns, len(lastRow.Row))
+			} else {
+				kvs, encodeErr = kv.EncodeRow(kvEncoder, logger, lastRow.Row, lastRow.Missing, lastRow.RowID, cr.chunk.ColumnPermutation, cr.chunk.Key.Path, curOffset)
+				if encodeErr == nil && lastRow.Modifier != nil {
+					kvs = tidb.WithInsertModifier(kvs, lastRow.Modifier)
+				}
+			}
 			encodeDur += time.Since(encodeDurStart)
 
 			hasIgnoredEncodeErr := false
 			if encodeErr != nil {
-				rowText := tidb.EncodeRowForRecord(ctx, t.encTable, rc.cfg.TiDB.SQLMode, lastRow.Row, cr.chunk.ColumnPermutation)
-				encodeErr = rc.errorMgr.RecordTypeError(ctx, logger, t.tableName, cr.chunk.Key.Path, newOffset, rowText, encodeErr)
+				if rejected != nil {
+					line, raw := csvParser.LastRecord()
+					encodeErr = rejected.Record(ctx, line, curOffset, raw, encodeErr)
+				} else {
+					rowText := tidb.EncodeRowForRecord(ctx, t.encTable, rc.cfg.TiDB.SQLMode, lastRow.Row, cr.chunk.ColumnPermutation)
+					encodeErr = rc.errorMgr.RecordTypeError(ctx, logger, t.tableName, cr.chunk.Key.Path, newOffset, rowText, encodeErr)
+				}
 				if encodeErr != nil {
 					err = common.ErrEncodeKV.Wrap(encodeErr).GenWithStackByArgs(&cr.chunk.Key, newOffset)
 				}
@@ -2580,13 +3088,19 @@ func (cr *chunkRestore) restore(
 	dataEngine, indexEngine *backend.LocalEngineWriter,
 	rc *Controller,
 ) error {
+	transforms, err := rc.cfg.Mydumper.ColumnTransforms.GetColumnTransforms(t.dbInfo.Name, t.tableInfo.Core.Name.O, rc.cfg.Mydumper.CaseSensitive)
+	if err != nil {
+		return err
+	}
 	// Create the encoder.
 	kvEncoder, err := rc.backend.NewEncoder(ctx, t.encTable, &kv.SessionOptions{
 		SQLMode:   rc.cfg.TiDB.SQLMode,
 		Timestamp: cr.chunk.Timestamp,
 		SysVars:   rc.sysVars,
 		// use chunk.PrevRowIDMax as the auto random seed, so it can stay the same value after recover from checkpoint.
-		AutoRandomSeed: cr.chunk.Chunk.PrevRowIDMax,
+		AutoRandomSeed:       cr.chunk.Chunk.PrevRowIDMax,
+		ColumnTransforms:     transforms,
+		IgnoreUnknownColumns: t.ignoreUnknownColumns,
 	})
 	if err != nil {
 		return err
@@ -18,6 +18,7 @@ import (
 	"context"
 	stderrors "errors"
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -26,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/lightning/backend"
 	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
 	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
 	"github.com/pingcap/tidb/br/pkg/mock"
 	"github.com/pingcap/tidb/br/pkg/storage"
@@ -296,3 +298,74 @@ func (s *restoreSchemaSuite) TestRestoreSchemaContextCancel() {
 	require.Error(s.T(), err)
 	require.Equal(s.T(), childCtx.Err(), err)
 }
+
+func (s *restoreSchemaSuite) TestRestoreSchemaSkipCreated() {
+	cpdb, err := checkpoints.NewFileCheckpointsDB(s.ctx, filepath.Join(s.T().TempDir(), "cp.pb"))
+	s.Require().NoError(err)
+	names := []string{"`fakedb`"}
+	for i := 1; i <= 8; i++ {
+		names = append(names, fmt.Sprintf("`fakedb`.`tbl%d`", i))
+	}
+	s.Require().NoError(cpdb.AddCreatedSchemaObjects(s.ctx, names))
+
+	// no statement is expected since all the objects have been created.
+	mockDB, sqlMock, err := sqlmock.New()
+	s.Require().NoError(err)
+	mockTiDBGlue := mock.NewMockGlue(s.controller)
+	mockTiDBGlue.EXPECT().GetDB().AnyTimes().Return(mockDB, nil)
+	mockTiDBGlue.EXPECT().OwnsSQLExecutor().AnyTimes().Return(true)
+	mockTiDBGlue.EXPECT().GetParser().AnyTimes().Return(parser.New())
+	exec := mock.NewMockSQLExecutor(s.controller)
+	exec.EXPECT().
+		QueryStringsWithLog(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return([][]string{{"time_zone", "SYSTEM"}}, nil)
+	mockTiDBGlue.EXPECT().GetSQLExecutor().AnyTimes().Return(exec)
+	s.rc.tidbGlue = mockTiDBGlue
+	s.targetInfoGetter.targetDBGlue = mockTiDBGlue
+
+	s.rc.checkpointsDB = cpdb
+	defer func() {
+		s.rc.checkpointsDB = &checkpoints.NullCheckpointsDB{}
+	}()
+	s.Require().NoError(s.rc.restoreSchema(s.ctx))
+	s.Require().NoError(sqlMock.ExpectationsWereMet())
+}
+
+func TestGroupTableJobsByForeignKeys(t *testing.T) {
+	newJob := func(db, tbl string) *schemaJob {
+		return &schemaJob{dbName: db, tblName: tbl, stmtType: schemaCreateTable}
+	}
+	jobs := []*schemaJob{
+		newJob("db", "orders"),
+		newJob("db", "users"),
+		newJob("db", "items"),
+		newJob("db", "Products"),
+		newJob("db", "a"),
+		newJob("db", "b"),
+	}
+	refs := [][]filter.Table{
+		{{Schema: "db", Name: "users"}, {Schema: "db", Name: "items"}},
+		nil,
+		// the tables out of the jobs and the self references are ignored.
+		{{Schema: "db", Name: "products"}, {Schema: "other", Name: "t"}, {Schema: "db", Name: "items"}},
+		nil,
+		// the circular references are ignored.
+		{{Schema: "db", Name: "b"}},
+		{{Schema: "db", Name: "a"}},
+	}
+	groups := groupTableJobsByForeignKeys(log.L(), jobs, refs)
+	names := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		groupNames := make([]string, 0, len(group))
+		for _, job := range group {
+			groupNames = append(groupNames, job.tblName)
+		}
+		names = append(names, groupNames)
+	}
+	require.Equal(t, [][]string{
+		{"users", "Products", "b"},
+		{"items", "a"},
+		{"orders"},
+	}, names)
+}
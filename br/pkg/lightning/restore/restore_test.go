@@ -16,10 +16,14 @@ package restore
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"path/filepath"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/pingcap/errors"
@@ -30,6 +34,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/lightning/glue"
 	"github.com/pingcap/tidb/br/pkg/lightning/log"
 	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/lightning/notify"
 	"github.com/pingcap/tidb/br/pkg/version/build"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/parser"
@@ -188,6 +193,51 @@ func TestVerifyCheckpoint(t *testing.T) {
 	}
 }
 
+func TestCleanCheckpointsAfterOnImported(t *testing.T) {
+	ctx := context.Background()
+	cpdb, err := checkpoints.NewFileCheckpointsDB(ctx, filepath.Join(t.TempDir(), "cp.pb"))
+	require.NoError(t, err)
+	defer cpdb.Close()
+
+	cfg := config.NewConfig()
+	cfg.TaskID = 123
+	cfg.Checkpoint.Enable = true
+	cfg.Checkpoint.KeepAfterSuccess = config.CheckpointRemove
+	require.NoError(t, cpdb.Initialize(ctx, cfg, map[string]*checkpoints.TidbDBInfo{}))
+
+	newController := func(onImported func(context.Context) error) *Controller {
+		return &Controller{
+			cfg:           cfg,
+			checkpointsDB: cpdb,
+			saveCpCh:      make(chan saveCp),
+			onImported:    onImported,
+		}
+	}
+
+	// the checkpoints are kept if the imported data can't be recorded.
+	rc := newController(func(context.Context) error {
+		return errors.New("mock record failure")
+	})
+	require.ErrorContains(t, rc.cleanCheckpoints(ctx), "mock record failure")
+	taskCp, err := cpdb.TaskCheckpoint(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, taskCp)
+
+	called := false
+	rc = newController(func(ctx context.Context) error {
+		called = true
+		taskCp, err := cpdb.TaskCheckpoint(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, taskCp)
+		return nil
+	})
+	require.NoError(t, rc.cleanCheckpoints(ctx))
+	require.True(t, called)
+	taskCp, err = cpdb.TaskCheckpoint(ctx)
+	require.NoError(t, err)
+	require.Nil(t, taskCp)
+}
+
 // failMetaMgrBuilder mocks meta manager init failure
 type failMetaMgrBuilder struct {
 	metaMgrBuilder
@@ -205,6 +255,10 @@ func (cp panicCheckpointDB) Initialize(context.Context, *config.Config, map[stri
 	panic("should not reach here")
 }
 
+func (cp panicCheckpointDB) CreatedSchemaObjects(context.Context) (map[string]struct{}, error) {
+	return nil, nil
+}
+
 func TestPreCheckFailed(t *testing.T) {
 	cfg := config.NewConfig()
 	cfg.TikvImporter.Backend = config.BackendTiDB
@@ -258,3 +312,35 @@ func TestPreCheckFailed(t *testing.T) {
 	require.Equal(t, err.Error(), err1.Error())
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestNotifyTableError(t *testing.T) {
+	var events []notify.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var event notify.Event
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&event))
+		events = append(events, event)
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig()
+	cfg.Notifications = []*config.Notification{{
+		Type:    config.NotificationWebhook,
+		URL:     server.URL,
+		Timeout: config.Duration{Duration: time.Second},
+	}}
+	notifier, err := notify.NewNotifier(cfg)
+	require.NoError(t, err)
+	ctx := notify.NewContext(context.Background(), notifier)
+
+	notifyTableError(ctx, "`db`.`t1`", nil)
+	notifyTableError(ctx, "`db`.`t1`", context.Canceled)
+	notifyTableError(ctx, "`db`.`t1`", common.NormalizeOrWrapErr(common.ErrRestoreTable, errors.New("oops"), "`db`.`t1`"))
+	checksumErr := common.ErrChecksumMismatch.GenWithStackByArgs(1, 2, 3, 4, 5, 6)
+	notifyTableError(ctx, "`db`.`t2`", common.NormalizeOrWrapErr(common.ErrRestoreTable, checksumErr, "`db`.`t2`"))
+
+	require.Len(t, events, 2)
+	require.Equal(t, config.EventTableFailed, events[0].Type)
+	require.Equal(t, "`db`.`t1`", events[0].Table)
+	require.Equal(t, config.EventChecksumMismatch, events[1].Type)
+	require.Equal(t, "`db`.`t2`", events[1].Table)
+}
@@ -58,6 +58,9 @@ type TableRestore struct {
 	kvStore   tidbkv.Storage
 
 	ignoreColumns map[string]struct{}
+	// ignoreUnknownColumns is whether the columns of the data files which don't exist in the
+	// table are ignored.
+	ignoreUnknownColumns bool
 }
 
 func NewTableRestore(
@@ -125,6 +128,7 @@ func (tr *TableRestore) populateChunks(ctx context.Context, rc *Controller, cp *
 					tr.tableInfo.Core,
 					chunk.Chunk.Columns,
 					tr.ignoreColumns,
+					tr.ignoreUnknownColumns,
 					log.FromContext(ctx))
 				if err != nil {
 					return errors.Trace(err)
@@ -170,7 +174,7 @@ func (tr *TableRestore) RebaseChunkRowIDs(cp *checkpoints.TableCheckpoint, rowID
 //
 // The argument `columns` _must_ be in lower case.
 func (tr *TableRestore) initializeColumns(columns []string, ccp *checkpoints.ChunkCheckpoint) error {
-	colPerm, err := createColumnPermutation(columns, tr.ignoreColumns, tr.tableInfo.Core, tr.logger)
+	colPerm, err := createColumnPermutation(columns, tr.ignoreColumns, tr.ignoreUnknownColumns, tr.tableInfo.Core, tr.logger)
 	if err != nil {
 		return err
 	}
@@ -181,6 +185,7 @@ func (tr *TableRestore) initializeColumns(columns []string, ccp *checkpoints.Chu
 func createColumnPermutation(
 	columns []string,
 	ignoreColumns map[string]struct{},
+	ignoreUnknownColumns bool,
 	tableInfo *model.TableInfo,
 	logger log.Logger,
 ) ([]int, error) {
@@ -204,7 +209,7 @@ func createColumnPermutation(
 		}
 	} else {
 		var err error
-		colPerm, err = parseColumnPermutations(tableInfo, columns, ignoreColumns, logger)
+		colPerm, err = parseColumnPermutations(tableInfo, columns, ignoreColumns, ignoreUnknownColumns, logger)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
@@ -871,6 +876,7 @@ func parseColumnPermutations(
 	tableInfo *model.TableInfo,
 	columns []string,
 	ignoreColumns map[string]struct{},
+	ignoreUnknownColumns bool,
 	logger log.Logger,
 ) ([]int, error) {
 	colPerm := make([]int, 0, len(tableInfo.Columns)+1)
@@ -888,7 +894,7 @@ func parseColumnPermutations(
 	// check if there are some unknown columns
 	var unknownCols []string
 	for _, c := range columns {
-		if _, ok := tableColumnMap[c]; !ok && c != model.ExtraHandleName.L {
+		if _, ok := tableColumnMap[c]; !ok && c != model.ExtraHandleName.L && c != mydump.SkippedColumn {
 			if _, ignore := ignoreColumns[c]; !ignore {
 				unknownCols = append(unknownCols, c)
 			}
@@ -896,7 +902,13 @@ func parseColumnPermutations(
 	}
 
 	if len(unknownCols) > 0 {
-		return colPerm, common.ErrUnknownColumns.GenWithStackByArgs(strings.Join(unknownCols, ","), tableInfo.Name)
+		if !ignoreUnknownColumns {
+			return colPerm, common.ErrUnknownColumns.GenWithStackByArgs(strings.Join(unknownCols, ","), tableInfo.Name)
+		}
+		logger.Warn("the unknown columns of the data file are ignored",
+			zap.Stringer("table", tableInfo.Name),
+			zap.Strings("columns", unknownCols),
+		)
 	}
 
 	for _, colInfo := range tableInfo.Columns {
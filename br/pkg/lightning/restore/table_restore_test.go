@@ -614,6 +614,7 @@ func (s *tableRestoreSuite) TestInitializeColumns() {
 
 	defer func() {
 		s.tr.ignoreColumns = nil
+		s.tr.ignoreUnknownColumns = false
 	}()
 
 	cases := []struct {
@@ -664,6 +665,12 @@ func (s *tableRestoreSuite) TestInitializeColumns() {
 			[]int{2, -1, 3, -1},
 			"",
 		},
+		{
+			[]string{"-", "c", "-", "a"},
+			nil,
+			[]int{3, -1, 1, -1},
+			"",
+		},
 		{
 			[]string{"_tidb_rowid", "b", "a", "c", "d"},
 			nil,
@@ -689,6 +696,13 @@ func (s *tableRestoreSuite) TestInitializeColumns() {
 			require.Equal(s.T(), testCase.expectedPermutation, ccp.ColumnPermutation)
 		}
 	}
+
+	// the unknown columns are ignored if it's enabled.
+	ccp.ColumnPermutation = nil
+	s.tr.ignoreColumns = nil
+	s.tr.ignoreUnknownColumns = true
+	require.NoError(s.T(), s.tr.initializeColumns([]string{"e", "b", "c", "d"}, ccp))
+	require.Equal(s.T(), []int{-1, 1, 2, -1}, ccp.ColumnPermutation)
 }
 func (s *tableRestoreSuite) TestInitializeColumnsGenerated() {
 	p := parser.New()
@@ -1000,6 +1014,7 @@ func (s *tableRestoreSuite) TestSaveStatusCheckpoint() {
 	saveCpCh := make(chan saveCp)
 
 	rc := &Controller{
+		cfg:           config.NewConfig(),
 		saveCpCh:      saveCpCh,
 		checkpointsDB: checkpoints.NewNullCheckpointsDB(),
 	}
@@ -1876,7 +1891,7 @@ func (s *tableRestoreSuite) TestGBKEncodedSchemaIsValid() {
 			IgnoreColumns: nil,
 		},
 	}
-	charsetConvertor, err := mydump.NewCharsetConvertor(cfg.Mydumper.DataCharacterSet, cfg.Mydumper.DataInvalidCharReplace)
+	charsetConvertor, err := mydump.NewCharsetConvertor(cfg.Mydumper.DataCharacterSet, cfg.Mydumper.DataInvalidCharReplace, cfg.Mydumper.DataInvalidCharAction)
 	require.NoError(s.T(), err)
 	dir := s.T().TempDir()
 	mockStore, err := storage.NewLocalStorage(dir)
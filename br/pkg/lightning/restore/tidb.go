@@ -19,6 +19,8 @@ import (
 	"database/sql"
 	"fmt"
 	"math"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -35,6 +37,7 @@ import (
 	"github.com/pingcap/tidb/parser/format"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
+	filter "github.com/pingcap/tidb/util/table-filter"
 	"go.uber.org/zap"
 	"golang.org/x/exp/maps"
 )
@@ -116,6 +119,23 @@ func DBFromConfig(ctx context.Context, dsn config.DBStore) (*sql.DB, error) {
 	return db, errors.Trace(err)
 }
 
+// dbWithSessionVars connects to the target database like DBFromConfig, with the session
+// variables overriding the ones set by Lightning.
+func dbWithSessionVars(ctx context.Context, dsn config.DBStore, sessionVars map[string]string) (*sql.DB, error) {
+	vars := make(map[string]string, len(dsn.Vars)+len(sessionVars))
+	maps.Copy(vars, dsn.Vars)
+	for name, value := range sessionVars {
+		// the SQL mode is set in the DSN.
+		if name == "sql_mode" {
+			dsn.StrSQLMode = value
+			continue
+		}
+		vars[name] = value
+	}
+	dsn.Vars = vars
+	return DBFromConfig(ctx, dsn)
+}
+
 func NewTiDBManager(ctx context.Context, dsn config.DBStore, tls *common.TLS) (*TiDBManager, error) {
 	db, err := DBFromConfig(ctx, dsn)
 	if err != nil {
@@ -159,7 +179,7 @@ loopCreate:
 	for tbl, sqlCreateTable := range tablesSchema {
 		task.Debug("create table", zap.String("schema", sqlCreateTable))
 
-		sqlCreateStmts, err = createIfNotExistsStmt(g.GetParser(), sqlCreateTable, database, tbl)
+		sqlCreateStmts, err = createIfNotExistsStmt(g.GetParser(), nil, sqlCreateTable, database, tbl)
 		if err != nil {
 			break
 		}
@@ -182,7 +202,68 @@ loopCreate:
 	return errors.Trace(err)
 }
 
-func createIfNotExistsStmt(p *parser.Parser, createTable, dbName, tblName string) ([]string, error) {
+// schemaRewriter rewrites the schema statements by the `mydumper.schema-rewrite` rules. A
+// nil rewriter keeps the statements unchanged.
+type schemaRewriter struct {
+	// names maps the lower-case charsets and collations to their replacements.
+	names   map[string]string
+	pattern *regexp.Regexp
+	engines map[string]string
+}
+
+func newSchemaRewriter(rules *config.SchemaRewrite) *schemaRewriter {
+	if rules == nil || rules.IsEmpty() {
+		return nil
+	}
+	r := &schemaRewriter{
+		names:   make(map[string]string, len(rules.Charsets)+len(rules.Collations)),
+		engines: rules.Engines,
+	}
+	maps.Copy(r.names, rules.Charsets)
+	maps.Copy(r.names, rules.Collations)
+	if len(r.names) > 0 {
+		words := make([]string, 0, len(r.names))
+		for name := range r.names {
+			words = append(words, regexp.QuoteMeta(name))
+		}
+		sort.Strings(words)
+		// the quoted identifiers are matched too, so that they can be skipped.
+		r.pattern = regexp.MustCompile("`?\\b(?i:" + strings.Join(words, "|") + ")\\b")
+	}
+	return r
+}
+
+// rewriteSQL replaces the charsets and collations in the SQL text, since the parser rejects
+// the unknown ones. The quoted identifiers are kept.
+func (r *schemaRewriter) rewriteSQL(sql string) string {
+	if r == nil || r.pattern == nil {
+		return sql
+	}
+	return r.pattern.ReplaceAllStringFunc(sql, func(word string) string {
+		if word[0] == '`' {
+			return word
+		}
+		return r.names[strings.ToLower(word)]
+	})
+}
+
+// rewriteEngine replaces the storage engine of the table.
+func (r *schemaRewriter) rewriteEngine(node *ast.CreateTableStmt) {
+	if r == nil || len(r.engines) == 0 {
+		return
+	}
+	for _, opt := range node.Options {
+		if opt.Tp != ast.TableOptionEngine {
+			continue
+		}
+		if engine, ok := r.engines[strings.ToLower(opt.StrValue)]; ok {
+			opt.StrValue = engine
+		}
+	}
+}
+
+func createIfNotExistsStmt(p *parser.Parser, rewriter *schemaRewriter, createTable, dbName, tblName string) ([]string, error) {
+	createTable = rewriter.rewriteSQL(createTable)
 	stmts, _, err := p.ParseSQL(createTable)
 	if err != nil {
 		return []string{}, common.ErrInvalidSchemaStmt.Wrap(err).GenWithStackByArgs(createTable)
@@ -201,6 +282,7 @@ func createIfNotExistsStmt(p *parser.Parser, createTable, dbName, tblName string
 			node.Table.Schema = model.NewCIStr(dbName)
 			node.Table.Name = model.NewCIStr(tblName)
 			node.IfNotExists = true
+			rewriter.rewriteEngine(node)
 		case *ast.CreateViewStmt:
 			node.ViewName.Schema = model.NewCIStr(dbName)
 			node.ViewName.Name = model.NewCIStr(tblName)
@@ -208,6 +290,22 @@ func createIfNotExistsStmt(p *parser.Parser, createTable, dbName, tblName string
 			node.Tables[0].Schema = model.NewCIStr(dbName)
 			node.Tables[0].Name = model.NewCIStr(tblName)
 			node.IfExists = true
+		case *ast.CreateSequenceStmt:
+			node.Name.Schema = model.NewCIStr(dbName)
+			node.Name.Name = model.NewCIStr(tblName)
+			node.IfNotExists = true
+		case *ast.SelectStmt:
+			// `SELECT SETVAL(seq, n)` restores the current value of the sequence.
+			if node.Fields != nil {
+				for _, field := range node.Fields.Fields {
+					if fn, ok := field.Expr.(*ast.FuncCallExpr); ok && fn.FnName.L == ast.SetVal {
+						if seq, ok := fn.Args[0].(*ast.TableNameExpr); ok {
+							seq.Name.Schema = model.NewCIStr(dbName)
+							seq.Name.Name = model.NewCIStr(tblName)
+						}
+					}
+				}
+			}
 		}
 		if err := stmt.Restore(ctx); err != nil {
 			return []string{}, common.ErrInvalidSchemaStmt.Wrap(err).GenWithStackByArgs(createTable)
@@ -220,6 +318,20 @@ func createIfNotExistsStmt(p *parser.Parser, createTable, dbName, tblName string
 	return retStmts, nil
 }
 
+// foreignKeyReferences returns the lower-case names of the tables referenced by the foreign
+// keys of the CREATE TABLE statements. The unqualified names are resolved in dbName.
+func foreignKeyReferences(p *parser.Parser, stmts []string, dbName string) ([]filter.Table, error) {
+	fks, err := parseForeignKeys(p, stmts, dbName, "")
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]filter.Table, 0, len(fks))
+	for _, fk := range fks {
+		refs = append(refs, fk.refName())
+	}
+	return refs, nil
+}
+
 func (timgr *TiDBManager) DropTable(ctx context.Context, tableName string) error {
 	sql := common.SQLWithRetry{
 		DB:     timgr.db,
@@ -24,16 +24,19 @@ import (
 	"github.com/go-sql-driver/mysql"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
 	"github.com/pingcap/tidb/br/pkg/lightning/glue"
 	"github.com/pingcap/tidb/br/pkg/lightning/metric"
 	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/errno"
+	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
 	tmysql "github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/promutil"
+	filter "github.com/pingcap/tidb/util/table-filter"
 	"github.com/stretchr/testify/require"
 )
 
@@ -66,7 +69,7 @@ func TestCreateTableIfNotExistsStmt(t *testing.T) {
 
 	dbName := "testdb"
 	createSQLIfNotExistsStmt := func(createTable, tableName string) []string {
-		res, err := createIfNotExistsStmt(s.tiGlue.GetParser(), createTable, dbName, tableName)
+		res, err := createIfNotExistsStmt(s.tiGlue.GetParser(), nil, createTable, dbName, tableName)
 		require.NoError(t, err)
 		return res
 	}
@@ -163,6 +166,58 @@ func TestCreateTableIfNotExistsStmt(t *testing.T) {
 			SET character_set_results = @PREV_CHARACTER_SET_RESULTS;
 			SET collation_connection = @PREV_COLLATION_CONNECTION;
 		`, "m"))
+
+	// sequence
+	require.Equal(t, []string{
+		"CREATE SEQUENCE IF NOT EXISTS `testdb`.`s` START WITH 1 INCREMENT BY 2 CACHE 1000 NOCYCLE ENGINE = InnoDB;",
+		"SELECT SETVAL(`testdb`.`s`, 1001);",
+	},
+		createSQLIfNotExistsStmt("CREATE SEQUENCE `seq` start with 1 increment by 2 cache 1000 nocycle ENGINE=InnoDB;\n"+
+			"SELECT SETVAL(`seq`,1001);", "s"))
+}
+
+func TestCreateTableIfNotExistsStmtWithRewrite(t *testing.T) {
+	s := newTiDBSuite(t)
+
+	rewriter := newSchemaRewriter(&config.SchemaRewrite{
+		Charsets:   map[string]string{"utf8mb3": "utf8mb4"},
+		Collations: map[string]string{"utf8mb3_unicode_ci": "utf8mb4_unicode_ci"},
+		Engines:    map[string]string{"myisam": "InnoDB"},
+	})
+	res, err := createIfNotExistsStmt(s.tiGlue.GetParser(), rewriter,
+		"CREATE TABLE `foo` (`utf8mb3` INT) ENGINE=MyISAM DEFAULT CHARSET=UTF8MB3 COLLATE=utf8mb3_unicode_ci;", "testdb", "foo")
+	require.NoError(t, err)
+	// the quoted identifiers are not rewritten.
+	require.Equal(t, []string{
+		"CREATE TABLE IF NOT EXISTS `testdb`.`foo` (`utf8mb3` INT) ENGINE = InnoDB DEFAULT CHARACTER SET = UTF8MB4 DEFAULT COLLATE = UTF8MB4_UNICODE_CI;",
+	}, res)
+
+	// the unknown charsets are rejected without the rules.
+	_, err = createIfNotExistsStmt(s.tiGlue.GetParser(), nil,
+		"CREATE TABLE `foo` (`bar` INT) DEFAULT CHARSET=utf8mb3;", "testdb", "foo")
+	require.Error(t, err)
+
+	require.Nil(t, newSchemaRewriter(&config.SchemaRewrite{}))
+}
+
+func TestForeignKeyReferences(t *testing.T) {
+	p := parser.New()
+	refs, err := foreignKeyReferences(p, []string{
+		"CREATE TABLE IF NOT EXISTS `db`.`child` (`a` INT, `b` INT, `c` INT, " +
+			"FOREIGN KEY (`a`) REFERENCES `Parent` (`id`), " +
+			"CONSTRAINT `fk_b` FOREIGN KEY (`b`) REFERENCES `other`.`t` (`id`), " +
+			"FOREIGN KEY (`c`) REFERENCES `child` (`a`));",
+	}, "DB")
+	require.NoError(t, err)
+	require.Equal(t, []filter.Table{
+		{Schema: "db", Name: "parent"},
+		{Schema: "other", Name: "t"},
+		{Schema: "db", Name: "child"},
+	}, refs)
+
+	refs, err = foreignKeyReferences(p, []string{"CREATE TABLE `t` (`a` INT PRIMARY KEY);"}, "db")
+	require.NoError(t, err)
+	require.Empty(t, refs)
 }
 
 func TestInitSchema(t *testing.T) {
@@ -0,0 +1,214 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lightning
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/util/mathutil"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// runningTask is a task which is taken out of the queue to run.
+type runningTask struct {
+	cfg *config.Config
+	// cancel is nil before the task starts and after the task is canceled.
+	cancel context.CancelFunc
+	// tables are the target tables of the task, which are only resolved if the
+	// server mode runs the tasks concurrently.
+	tables map[string]struct{}
+	// diskQuota is the disk quota reserved from the server disk quota.
+	diskQuota int64
+}
+
+// resolveTargetTables returns the lower-cased unique names of the tables
+// imported by the task.
+func resolveTargetTables(ctx context.Context, cfg *config.Config) (map[string]struct{}, error) {
+	mdl, err := mydump.NewMyDumpLoader(ctx, cfg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tables := make(map[string]struct{})
+	for _, db := range mdl.GetDatabases() {
+		for _, tbl := range db.Tables {
+			tables[strings.ToLower(common.UniqueTable(db.Name, tbl.Name))] = struct{}{}
+		}
+	}
+	return tables, nil
+}
+
+// concurrentTasksEnabled returns whether the server mode runs several tasks at
+// the same time.
+func (l *Lightning) concurrentTasksEnabled() bool {
+	return l.globalCfg.App.MaxConcurrentTasks > 1
+}
+
+// taskDiskQuota returns the disk quota the task reserves from the server disk
+// quota. Only the local backend uses the disk, and a task without the disk
+// quota reserves the whole server disk quota.
+func (l *Lightning) taskDiskQuota(cfg *config.Config) int64 {
+	serverQuota := int64(l.globalCfg.App.ServerDiskQuota)
+	if serverQuota <= 0 || cfg.TikvImporter.Backend != config.BackendLocal {
+		return 0
+	}
+	return mathutil.Min(int64(cfg.TikvImporter.DiskQuota), serverQuota)
+}
+
+// tryReserveTask checks whether the task can run along with the running tasks,
+// and reserves the resources for it if so. It is called by the queue of the
+// server mode to pick the next task.
+func (l *Lightning) tryReserveTask(cfg *config.Config) bool {
+	l.cancelLock.Lock()
+	defer l.cancelLock.Unlock()
+
+	if len(l.runningTasks) >= l.globalCfg.App.MaxConcurrentTasks {
+		return false
+	}
+	tables := l.queuedTables[cfg]
+	for _, task := range l.runningTasks {
+		for table := range tables {
+			if _, ok := task.tables[table]; ok {
+				return false
+			}
+		}
+	}
+	diskQuota := l.taskDiskQuota(cfg)
+	serverQuota := int64(l.globalCfg.App.ServerDiskQuota)
+	if serverQuota > 0 && l.reservedDiskQuota+diskQuota > serverQuota {
+		return false
+	}
+
+	delete(l.queuedTables, cfg)
+	l.runningTasks = append(l.runningTasks, &runningTask{
+		cfg:       cfg,
+		tables:    tables,
+		diskQuota: diskQuota,
+	})
+	l.reservedDiskQuota += diskQuota
+	return true
+}
+
+// startTask records the cancel function of the task, and registers the task
+// if it is not reserved by tryReserveTask.
+func (l *Lightning) startTask(cfg *config.Config, cancel context.CancelFunc) {
+	l.cancelLock.Lock()
+	defer l.cancelLock.Unlock()
+	task := l.getRunningTaskLocked(cfg.TaskID)
+	if task == nil {
+		task = &runningTask{cfg: cfg}
+		l.runningTasks = append(l.runningTasks, task)
+	}
+	task.cancel = cancel
+}
+
+// finishTask releases the resources reserved by the task, and wakes up the
+// queue to pick the next tasks.
+func (l *Lightning) finishTask(taskID int64) {
+	l.cancelLock.Lock()
+	for i, task := range l.runningTasks {
+		if task.cfg.TaskID == taskID {
+			l.reservedDiskQuota -= task.diskQuota
+			l.runningTasks = append(l.runningTasks[:i], l.runningTasks[i+1:]...)
+			break
+		}
+	}
+	l.cancelLock.Unlock()
+
+	l.serverLock.Lock()
+	taskCfgs := l.taskCfgs
+	l.serverLock.Unlock()
+	if taskCfgs != nil {
+		taskCfgs.Wakeup()
+	}
+}
+
+func (l *Lightning) getRunningTaskLocked(taskID int64) *runningTask {
+	for _, task := range l.runningTasks {
+		if task.cfg.TaskID == taskID {
+			return task
+		}
+	}
+	return nil
+}
+
+// deregisterTLSConfig deregisters the TLS config of the finished task, unless
+// it is still used by another running task.
+func (l *Lightning) deregisterTLSConfig(taskCfg *config.Config) {
+	sec := taskCfg.TiDB.Security
+	if sec == nil {
+		return
+	}
+	l.cancelLock.Lock()
+	defer l.cancelLock.Unlock()
+	for _, task := range l.runningTasks {
+		if task.cfg.TaskID == taskCfg.TaskID {
+			continue
+		}
+		if other := task.cfg.TiDB.Security; other != nil && other.TLSConfigName == sec.TLSConfigName {
+			return
+		}
+	}
+	sec.DeregisterMySQL()
+}
+
+// isolateTask makes the task use its own sorted-kv-dir and checkpoint, so that
+// it does not interfere with the other tasks running at the same time.
+func isolateTask(cfg *config.Config) error {
+	suffix := fmt.Sprintf("task_%d", cfg.TaskID)
+	if cfg.TikvImporter.Backend == config.BackendLocal {
+		// the local backend creates the sorted-kv-dir but not its parent.
+		if err := os.MkdirAll(cfg.TikvImporter.SortedKVDir, 0o700); err != nil {
+			return common.ErrInvalidSortedKVDir.Wrap(err).GenWithStackByArgs(cfg.TikvImporter.SortedKVDir)
+		}
+		cfg.TikvImporter.SortedKVDir = filepath.Join(cfg.TikvImporter.SortedKVDir, suffix)
+		spillDirs := make([]config.SpillDir, 0, len(cfg.TikvImporter.SpillDirs))
+		for _, dir := range cfg.TikvImporter.SpillDirs {
+			if err := os.MkdirAll(dir.Path, 0o700); err != nil {
+				return common.ErrInvalidSortedKVDir.Wrap(err).GenWithStackByArgs(dir.Path)
+			}
+			dir.Path = filepath.Join(dir.Path, suffix)
+			spillDirs = append(spillDirs, dir)
+		}
+		cfg.TikvImporter.SpillDirs = spillDirs
+	}
+	if cfg.Checkpoint.Enable {
+		switch cfg.Checkpoint.Driver {
+		case config.CheckpointDriverMySQL:
+			cfg.Checkpoint.Schema += "_" + suffix
+		case config.CheckpointDriverFile:
+			ext := filepath.Ext(cfg.Checkpoint.DSN)
+			cfg.Checkpoint.DSN = strings.TrimSuffix(cfg.Checkpoint.DSN, ext) + "." + suffix + ext
+		}
+	}
+	return nil
+}
+
+// taskRegistry returns the registry of the metrics of the task, which labels
+// the metrics by the task ID if the tasks run concurrently.
+func (l *Lightning) taskRegistry(cfg *config.Config) prometheus.Registerer {
+	if !l.concurrentTasksEnabled() {
+		return l.promRegistry
+	}
+	return prometheus.WrapRegistererWith(prometheus.Labels{"task_id": strconv.FormatInt(cfg.TaskID, 10)}, l.promRegistry)
+}
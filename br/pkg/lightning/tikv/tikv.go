@@ -79,9 +79,10 @@ func (ss *StoreState) UnmarshalJSON(content []byte) error {
 
 // Store contains metadata about a TiKV store.
 type Store struct {
-	Address string
-	Version string
-	State   StoreState `json:"state_name"`
+	Address       string
+	Version       string
+	StatusAddress string     `json:"status_address"`
+	State         StoreState `json:"state_name"`
 }
 
 func withTiKVConnection(ctx context.Context, tls *common.TLS, tikvAddr string, action func(import_sstpb.ImportSSTClient) error) error {
@@ -0,0 +1,347 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lightning
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
+	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/glue"
+	"github.com/pingcap/tidb/br/pkg/lightning/log"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/lightning/worker"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/util/mathutil"
+	"go.uber.org/zap"
+)
+
+// lineEndSearchBlockSize is the size of the blocks read backward to find the last
+// line feed of a growing data file.
+const lineEndSearchBlockSize = 64 * 1024
+
+// watch runs the import in the watch mode. In every round, the data source is
+// re-scanned and the data files which haven't been imported are imported into
+// the tables, then the files are recorded as imported along with the imported
+// sizes before the checkpoints of the round are cleaned. It returns when the
+// task is stopped or any round fails.
+func (l *Lightning) watch(
+	ctx context.Context,
+	taskCfg *config.Config,
+	o *options,
+	g glue.Glue,
+	s storage.ExternalStorage,
+) error {
+	importedDB, err := checkpoints.OpenImportedFilesDB(ctx, taskCfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	//nolint: errcheck
+	defer importedDB.Close()
+
+	interval := taskCfg.Mydumper.WatchInterval.Duration
+	roundCfg := taskCfg
+	for round := 0; ; round++ {
+		logger := o.logger.With(zap.Int("round", round))
+		if round > 0 {
+			// every round is a separated task, so the checkpoints of the last round
+			// won't be reused.
+			cfg := *taskCfg
+			cfg.TaskID = time.Now().UnixNano()
+			roundCfg = &cfg
+		}
+
+		newFiles, err := l.importSource(ctx, roundCfg, o, g, s, importedDB)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(newFiles) > 0 {
+			logger.Info("imported new data files", zap.Int("count", len(newFiles)))
+		} else {
+			logger.Info("no new data files")
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("watch mode stopped")
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// excludeImportedFiles removes the imported data files from the databases. The
+// tables whose data files are all imported are removed too. If
+// `mydumper.watch-growing-files` is enabled, the imported files which have grown
+// are kept with only the appended data.
+func excludeImportedFiles(
+	ctx context.Context,
+	cfg *config.Config,
+	store storage.ExternalStorage,
+	dbMetas []*mydump.MDDatabaseMeta,
+	imported map[string]int64,
+) ([]*mydump.MDDatabaseMeta, error) {
+	res := make([]*mydump.MDDatabaseMeta, 0, len(dbMetas))
+	for _, dbMeta := range dbMetas {
+		tables := make([]*mydump.MDTableMeta, 0, len(dbMeta.Tables))
+		for _, tableMeta := range dbMeta.Tables {
+			dataFiles := make([]mydump.FileInfo, 0, len(tableMeta.DataFiles))
+			var totalSize int64
+			for _, file := range tableMeta.DataFiles {
+				var hasNewData bool
+				if cfg.Mydumper.WatchGrowingFiles {
+					var err error
+					file, hasNewData, err = newDataOfFile(ctx, cfg, store, file, imported)
+					if err != nil {
+						return nil, errors.Trace(err)
+					}
+				} else {
+					_, ok := imported[file.FileMeta.Path]
+					hasNewData = !ok
+				}
+				if !hasNewData {
+					continue
+				}
+				dataFiles = append(dataFiles, file)
+				totalSize += dataSize(file)
+			}
+			if len(dataFiles) == 0 && len(tableMeta.DataFiles) > 0 {
+				continue
+			}
+			newTableMeta := *tableMeta
+			newTableMeta.DataFiles = dataFiles
+			newTableMeta.TotalSize = totalSize
+			tables = append(tables, &newTableMeta)
+		}
+		if len(tables) == 0 {
+			continue
+		}
+		newDBMeta := *dbMeta
+		newDBMeta.Tables = tables
+		res = append(res, &newDBMeta)
+	}
+	return res, nil
+}
+
+// newDataOfFile returns the data file with only the data which hasn't been imported,
+// i.e. the data from the imported size up to the last line feed, so an incomplete
+// row being written is left to the next round. It returns false if there is no such
+// data. Only the files whose rows never span lines are cut at the line feeds, the
+// other files are imported as a whole once they appear.
+func newDataOfFile(
+	ctx context.Context,
+	cfg *config.Config,
+	store storage.ExternalStorage,
+	file mydump.FileInfo,
+	imported map[string]int64,
+) (mydump.FileInfo, bool, error) {
+	importedSize, ok := imported[file.FileMeta.Path]
+	fileSize := file.FileMeta.FileSize
+	if ok && importedSize >= fileSize {
+		return file, false, nil
+	}
+	if !canImportAppendedData(cfg, file) {
+		if ok {
+			log.FromContext(ctx).Warn("the appended data of the file is not imported since its rows may span lines",
+				zap.String("path", file.FileMeta.Path),
+				zap.Int64("importedSize", importedSize),
+				zap.Int64("fileSize", fileSize))
+		}
+		return file, !ok, nil
+	}
+
+	end, err := lastLineEnd(ctx, store, file.FileMeta.Path, importedSize, fileSize)
+	if err != nil {
+		return file, false, errors.Trace(err)
+	}
+	if end == importedSize {
+		return file, false, nil
+	}
+	if importedSize == 0 && end == fileSize {
+		// the whole file is new and complete, keep the regions split while scanning.
+		return file, true, nil
+	}
+	region := mydump.FileRegion{Offset: importedSize, EndOffset: end}
+	if importedSize > 0 && file.FileMeta.Type == mydump.SourceTypeCSV && cfg.Mydumper.CSV.Header {
+		region.Columns, err = readCSVHeader(ctx, cfg, store, file.FileMeta.Path)
+		if err != nil {
+			return file, false, errors.Trace(err)
+		}
+	}
+	file.Regions = []mydump.FileRegion{region}
+	return file, true, nil
+}
+
+// canImportAppendedData returns whether the data appended to the file can be imported
+// separately. The appended data of the compressed files and the parquet files can't be
+// read without the beginning of the files. The statements of the SQL files and the
+// quoted fields of the CSV files may contain line feeds, so the files can't be cut at
+// a line feed, unless the CSV fields are never quoted or `mydumper.strict-format`
+// promises that they don't contain line feeds.
+func canImportAppendedData(cfg *config.Config, file mydump.FileInfo) bool {
+	if file.FileMeta.Compression != mydump.CompressionNone || file.FileMeta.Type != mydump.SourceTypeCSV {
+		return false
+	}
+	return cfg.Mydumper.CSV.Delimiter == "" || cfg.Mydumper.StrictFormat
+}
+
+// lastLineEnd returns the offset after the last line feed in the range [start, end)
+// of the file, or start if there is no line feed.
+func lastLineEnd(ctx context.Context, store storage.ExternalStorage, path string, start, end int64) (int64, error) {
+	reader, err := store.Open(ctx, path)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	//nolint: errcheck
+	defer reader.Close()
+
+	buf := make([]byte, lineEndSearchBlockSize)
+	for end > start {
+		blockStart := mathutil.Max(end-lineEndSearchBlockSize, start)
+		block := buf[:end-blockStart]
+		if _, err := reader.Seek(blockStart, io.SeekStart); err != nil {
+			return 0, errors.Trace(err)
+		}
+		if _, err := io.ReadFull(reader, block); err != nil {
+			return 0, errors.Trace(err)
+		}
+		if i := bytes.LastIndexByte(block, '\n'); i >= 0 {
+			return blockStart + int64(i) + 1, nil
+		}
+		end = blockStart
+	}
+	return start, nil
+}
+
+// readCSVHeader reads the columns in the header of the CSV file.
+func readCSVHeader(ctx context.Context, cfg *config.Config, store storage.ExternalStorage, path string) ([]string, error) {
+	reader, err := store.Open(ctx, path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	charsetConvertor, err := mydump.NewCharsetConvertor(cfg.Mydumper.DataCharacterSet, cfg.Mydumper.DataInvalidCharReplace, cfg.Mydumper.DataInvalidCharAction)
+	if err != nil {
+		_ = reader.Close()
+		return nil, errors.Trace(err)
+	}
+	ioWorkers := worker.NewPool(ctx, 1, "read-csv-header")
+	parser, err := mydump.NewCSVParser(ctx, &cfg.Mydumper.CSV, reader, int64(cfg.Mydumper.ReadBlockSize), ioWorkers, true, charsetConvertor)
+	if err != nil {
+		_ = reader.Close()
+		return nil, errors.Trace(err)
+	}
+	//nolint: errcheck
+	defer parser.Close()
+	if err := parser.ReadColumns(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return parser.Columns(), nil
+}
+
+// dataSize returns the size of the data to import in the data file.
+func dataSize(file mydump.FileInfo) int64 {
+	if len(file.Regions) == 0 {
+		return file.FileMeta.FileSize
+	}
+	var size int64
+	for _, region := range file.Regions {
+		size += region.EndOffset - region.Offset
+	}
+	return size
+}
+
+// importedEnd returns the offset up to which the data file is imported.
+func importedEnd(file mydump.FileInfo) int64 {
+	if len(file.Regions) == 0 {
+		return file.FileMeta.FileSize
+	}
+	return file.Regions[len(file.Regions)-1].EndOffset
+}
+
+// restrictToCheckpoints makes the tables whose chunks have been populated in
+// the checkpoints of an interrupted round only contain the data files in the
+// checkpoints, so the files arrived after the round started are left to the
+// next round. It returns the offsets up to which the remaining data files are
+// imported by path, which are taken from the chunks for the populated tables.
+func restrictToCheckpoints(ctx context.Context, cfg *config.Config, dbMetas []*mydump.MDDatabaseMeta) (map[string]int64, error) {
+	exist, err := checkpoints.IsCheckpointsDBExists(ctx, cfg)
+	if err != nil || !exist {
+		return collectDataFiles(dbMetas), errors.Trace(err)
+	}
+	cpdb, err := checkpoints.OpenCheckpointsDB(ctx, cfg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	//nolint: errcheck
+	defer cpdb.Close()
+
+	populated := make(map[string]int64)
+	for _, dbMeta := range dbMetas {
+		for _, tableMeta := range dbMeta.Tables {
+			cp, err := cpdb.Get(ctx, common.UniqueTable(tableMeta.DB, tableMeta.Name))
+			if err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return nil, errors.Trace(err)
+			}
+			if len(cp.Engines) == 0 {
+				continue
+			}
+			for _, engine := range cp.Engines {
+				for _, chunk := range engine.Chunks {
+					end := chunk.Chunk.EndOffset
+					// the offsets of the compressed files and the parquet files aren't
+					// in bytes of the files.
+					if chunk.FileMeta.Compression != mydump.CompressionNone || chunk.FileMeta.Type == mydump.SourceTypeParquet {
+						end = chunk.FileMeta.FileSize
+					}
+					populated[chunk.Key.Path] = mathutil.Max(populated[chunk.Key.Path], end)
+				}
+			}
+			dataFiles := tableMeta.DataFiles[:0]
+			for _, file := range tableMeta.DataFiles {
+				if _, ok := populated[file.FileMeta.Path]; ok {
+					dataFiles = append(dataFiles, file)
+				}
+			}
+			tableMeta.DataFiles = dataFiles
+		}
+	}
+	files := collectDataFiles(dbMetas)
+	for path := range files {
+		if end, ok := populated[path]; ok {
+			files[path] = end
+		}
+	}
+	return files, nil
+}
+
+func collectDataFiles(dbMetas []*mydump.MDDatabaseMeta) map[string]int64 {
+	files := make(map[string]int64)
+	for _, dbMeta := range dbMetas {
+		for _, tableMeta := range dbMeta.Tables {
+			for _, file := range tableMeta.DataFiles {
+				files[file.FileMeta.Path] = importedEnd(file)
+			}
+		}
+	}
+	return files
+}
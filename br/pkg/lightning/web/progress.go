@@ -3,12 +3,14 @@ package web
 import (
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
 	"github.com/pingcap/tidb/br/pkg/lightning/common"
 	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
 	"go.uber.org/atomic"
+	"golang.org/x/exp/slices"
 )
 
 // checkpointsMap is a concurrent map (table name → checkpoints).
@@ -39,40 +41,70 @@ func (cpm *checkpointsMap) clear() {
 	cpm.mu.Unlock()
 }
 
-func (cpm *checkpointsMap) insert(key string, cp *checkpoints.TableCheckpoint) {
+func (cpm *checkpointsMap) insert(key string, cp *checkpoints.TableCheckpoint) tableStats {
 	cpm.mu.Lock()
 	cpm.checkpoints[key] = cp
 	cpm.mu.Unlock()
+	return makeTableStats(key, cp)
 }
 
-type totalWritten struct {
+// tableStats is the progress of a table summarized from its checkpoint.
+type tableStats struct {
 	key          string
 	totalWritten int64
+	// rowsEncoded is the number of rows encoded since the last update.
+	rowsEncoded int64
+	kvEncoded   uint64
+	kvIngested  uint64
+	status      checkpoints.CheckpointStatus
 }
 
-func (cpm *checkpointsMap) update(diffs map[string]*checkpoints.TableCheckpointDiff) []totalWritten {
-	totalWrittens := make([]totalWritten, 0, len(diffs))
+func makeTableStats(key string, cp *checkpoints.TableCheckpoint) tableStats {
+	stats := tableStats{key: key, status: cp.Status}
+	for _, engine := range cp.Engines {
+		for _, chunk := range engine.Chunks {
+			if engine.Status >= checkpoints.CheckpointStatusAllWritten {
+				stats.totalWritten += chunk.Chunk.EndOffset - chunk.Key.Offset
+			} else {
+				stats.totalWritten += chunk.Chunk.Offset - chunk.Key.Offset
+			}
+			stats.kvEncoded += chunk.Checksum.SumSize()
+			if engine.Status >= checkpoints.CheckpointStatusImported {
+				stats.kvIngested += chunk.Checksum.SumSize()
+			}
+		}
+	}
+	return stats
+}
+
+// sumRowIDs returns the sum of the last allocated row IDs of the chunks, which
+// grows by one for every row read.
+func sumRowIDs(cp *checkpoints.TableCheckpoint) int64 {
+	sum := int64(0)
+	for _, engine := range cp.Engines {
+		for _, chunk := range engine.Chunks {
+			sum += chunk.Chunk.PrevRowIDMax
+		}
+	}
+	return sum
+}
+
+func (cpm *checkpointsMap) update(diffs map[string]*checkpoints.TableCheckpointDiff) []tableStats {
+	allStats := make([]tableStats, 0, len(diffs))
 
 	cpm.mu.Lock()
 	defer cpm.mu.Unlock()
 
 	for key, diff := range diffs {
 		cp := cpm.checkpoints[key]
+		rowIDs := sumRowIDs(cp)
 		cp.Apply(diff)
 
-		tw := int64(0)
-		for _, engine := range cp.Engines {
-			for _, chunk := range engine.Chunks {
-				if engine.Status >= checkpoints.CheckpointStatusAllWritten {
-					tw += chunk.Chunk.EndOffset - chunk.Key.Offset
-				} else {
-					tw += chunk.Chunk.Offset - chunk.Key.Offset
-				}
-			}
-		}
-		totalWrittens = append(totalWrittens, totalWritten{key: key, totalWritten: tw})
+		stats := makeTableStats(key, cp)
+		stats.rowsEncoded = sumRowIDs(cp) - rowIDs
+		allStats = append(allStats, stats)
 	}
-	return totalWrittens
+	return allStats
 }
 
 func (cpm *checkpointsMap) marshal(key string) ([]byte, error) {
@@ -97,6 +129,13 @@ type tableInfo struct {
 	TotalSize    int64      `json:"z"`
 	Status       taskStatus `json:"s"`
 	Message      string     `json:"m,omitempty"`
+
+	// the following fields are only reported by the progress API.
+	rowsEncoded int64
+	kvEncoded   uint64
+	kvIngested  uint64
+	cpStatus    checkpoints.CheckpointStatus
+	readRate    rollingRate
 }
 
 type taskProgress struct {
@@ -105,6 +144,11 @@ type taskProgress struct {
 	Status  taskStatus            `json:"s"`
 	Message string                `json:"m,omitempty"`
 
+	// bytesRead is the number of bytes read by this task, excluding the bytes
+	// read before the task is resumed from the checkpoints.
+	bytesRead int64
+	readRate  rollingRate
+
 	// The contents have their own mutex for protection
 	checkpoints checkpointsMap
 }
@@ -113,6 +157,8 @@ var (
 	currentProgress *taskProgress
 	// whether progress is enabled
 	progressEnabled = atomic.NewBool(false)
+	// now is replaced in the tests.
+	now = time.Now
 )
 
 // EnableCurrentProgress init current progress struct on demand.
@@ -130,6 +176,9 @@ func BroadcastStartTask() {
 	}
 	currentProgress.mu.Lock()
 	currentProgress.Status = taskStatusRunning
+	currentProgress.Message = ""
+	currentProgress.bytesRead = 0
+	currentProgress.readRate = rollingRate{}
 	currentProgress.mu.Unlock()
 
 	currentProgress.checkpoints.clear()
@@ -162,6 +211,7 @@ func BroadcastInitProgress(databases []*mydump.MDDatabaseMeta) {
 
 	currentProgress.mu.Lock()
 	currentProgress.Tables = tables
+	currentProgress.readRate.add(now(), currentProgress.bytesRead)
 	currentProgress.mu.Unlock()
 }
 
@@ -169,24 +219,39 @@ func BroadcastTableCheckpoint(tableName string, cp *checkpoints.TableCheckpoint)
 	if !progressEnabled.Load() {
 		return
 	}
+	// create a deep copy to avoid false sharing
+	stats := currentProgress.checkpoints.insert(tableName, cp.DeepCopy())
+
 	currentProgress.mu.Lock()
-	currentProgress.Tables[tableName].Status = taskStatusRunning
+	tbl := currentProgress.Tables[tableName]
+	tbl.Status = taskStatusRunning
+	tbl.TotalWritten = stats.totalWritten
+	tbl.kvEncoded = stats.kvEncoded
+	tbl.kvIngested = stats.kvIngested
+	tbl.cpStatus = stats.status
+	tbl.readRate.add(now(), stats.totalWritten)
 	currentProgress.mu.Unlock()
-
-	// create a deep copy to avoid false sharing
-	currentProgress.checkpoints.insert(tableName, cp.DeepCopy())
 }
 
 func BroadcastCheckpointDiff(diffs map[string]*checkpoints.TableCheckpointDiff) {
 	if !progressEnabled.Load() {
 		return
 	}
-	totalWrittens := currentProgress.checkpoints.update(diffs)
+	allStats := currentProgress.checkpoints.update(diffs)
 
+	at := now()
 	currentProgress.mu.Lock()
-	for _, tw := range totalWrittens {
-		currentProgress.Tables[tw.key].TotalWritten = tw.totalWritten
+	for _, stats := range allStats {
+		tbl := currentProgress.Tables[stats.key]
+		currentProgress.bytesRead += stats.totalWritten - tbl.TotalWritten
+		tbl.TotalWritten = stats.totalWritten
+		tbl.rowsEncoded += stats.rowsEncoded
+		tbl.kvEncoded = stats.kvEncoded
+		tbl.kvIngested = stats.kvIngested
+		tbl.cpStatus = stats.status
+		tbl.readRate.add(at, stats.totalWritten)
 	}
+	currentProgress.readRate.add(at, currentProgress.bytesRead)
 	currentProgress.mu.Unlock()
 }
 
@@ -219,3 +284,161 @@ func MarshalTableCheckpoints(tableName string) ([]byte, error) {
 	}
 	return currentProgress.checkpoints.marshal(tableName)
 }
+
+// throughputWindow is the window of the rolling throughput used by the ETAs.
+const throughputWindow = time.Minute
+
+type rateSample struct {
+	at    time.Time
+	value int64
+}
+
+// rollingRate is the growth rate of a counter over the last throughputWindow.
+type rollingRate struct {
+	samples []rateSample
+}
+
+func (r *rollingRate) add(at time.Time, value int64) {
+	r.samples = append(r.samples, rateSample{at: at, value: value})
+	// keep the last sample out of the window as the start of the window.
+	i := 0
+	for i+1 < len(r.samples) && at.Sub(r.samples[i+1].at) >= throughputWindow {
+		i++
+	}
+	r.samples = r.samples[i:]
+}
+
+// perSecond returns the rate until the given time, or 0 if it's unknown. The
+// rate drops when the counter stops growing.
+func (r *rollingRate) perSecond(at time.Time) float64 {
+	if len(r.samples) < 2 {
+		return 0
+	}
+	first, last := r.samples[0], r.samples[len(r.samples)-1]
+	elapsed := at.Sub(first.at).Seconds()
+	if elapsed <= 0 || last.value <= first.value {
+		return 0
+	}
+	return float64(last.value-first.value) / elapsed
+}
+
+// estimateSeconds returns the seconds to finish the remaining work at the
+// rate, or nil if the rate is unknown.
+func estimateSeconds(remaining int64, rate float64) *float64 {
+	if remaining <= 0 {
+		eta := 0.0
+		return &eta
+	}
+	if rate <= 0 {
+		return nil
+	}
+	eta := float64(remaining) / rate
+	return &eta
+}
+
+// TableProgress is the progress of a table reported by the progress API.
+type TableProgress struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	TotalBytes int64  `json:"total_bytes"`
+	BytesRead  int64  `json:"bytes_read"`
+	// RowsEncoded is the number of rows encoded since the table is started in
+	// this task, so the rows encoded before resuming from the checkpoints are
+	// not included.
+	RowsEncoded     int64  `json:"rows_encoded"`
+	KVEncodedBytes  uint64 `json:"kv_encoded_bytes"`
+	KVIngestedBytes uint64 `json:"kv_ingested_bytes"`
+	ChecksumState   string `json:"checksum_state"`
+	// ETASeconds is the estimated seconds to read the rest of the table by the
+	// throughput of the last minute, or nil if it's unknown.
+	ETASeconds *float64 `json:"eta_seconds"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// TaskProgress is the progress of the current task reported by the progress
+// API.
+type TaskProgress struct {
+	Status     string `json:"status"`
+	TotalBytes int64  `json:"total_bytes"`
+	BytesRead  int64  `json:"bytes_read"`
+	// ETASeconds is the estimated seconds to read the rest of the data source
+	// by the throughput of the last minute, or nil if it's unknown.
+	ETASeconds *float64         `json:"eta_seconds"`
+	Error      string           `json:"error,omitempty"`
+	Tables     []*TableProgress `json:"tables"`
+}
+
+func progressStatus(status taskStatus, message string) string {
+	switch {
+	case status == taskStatusRunning:
+		return "running"
+	case status == taskStatusCompleted && message != "":
+		return "failed"
+	case status == taskStatusCompleted:
+		return "completed"
+	default:
+		return "pending"
+	}
+}
+
+func checksumState(tbl *tableInfo) string {
+	switch status := tbl.cpStatus; {
+	case status == checkpoints.CheckpointStatusChecksummed/10:
+		return "failed"
+	case status >= checkpoints.CheckpointStatusChecksummed:
+		return "passed"
+	case status == checkpoints.CheckpointStatusChecksumSkipped:
+		return "skipped"
+	case status == checkpoints.CheckpointStatusAlteredAutoInc && tbl.Status == taskStatusRunning:
+		return "running"
+	default:
+		return "pending"
+	}
+}
+
+// GetTaskProgress returns the progress of the current task with the tables
+// sorted by name.
+func GetTaskProgress() (*TaskProgress, error) {
+	if !progressEnabled.Load() {
+		return nil, errors.New("progress is not enabled")
+	}
+	at := now()
+	currentProgress.mu.RLock()
+	defer currentProgress.mu.RUnlock()
+
+	res := &TaskProgress{
+		Status: progressStatus(currentProgress.Status, currentProgress.Message),
+		Error:  currentProgress.Message,
+		Tables: make([]*TableProgress, 0, len(currentProgress.Tables)),
+	}
+	remaining := int64(0)
+	for name, tbl := range currentProgress.Tables {
+		res.TotalBytes += tbl.TotalSize
+		res.BytesRead += tbl.TotalWritten
+		if tbl.Status != taskStatusCompleted && tbl.TotalSize > tbl.TotalWritten {
+			remaining += tbl.TotalSize - tbl.TotalWritten
+		}
+		tp := &TableProgress{
+			Name:            name,
+			Status:          progressStatus(tbl.Status, tbl.Message),
+			TotalBytes:      tbl.TotalSize,
+			BytesRead:       tbl.TotalWritten,
+			RowsEncoded:     tbl.rowsEncoded,
+			KVEncodedBytes:  tbl.kvEncoded,
+			KVIngestedBytes: tbl.kvIngested,
+			ChecksumState:   checksumState(tbl),
+			Error:           tbl.Message,
+		}
+		if tbl.Status != taskStatusCompleted {
+			tp.ETASeconds = estimateSeconds(tbl.TotalSize-tbl.TotalWritten, tbl.readRate.perSecond(at))
+		}
+		res.Tables = append(res.Tables, tp)
+	}
+	slices.SortFunc(res.Tables, func(i, j *TableProgress) bool {
+		return i.Name < j.Name
+	})
+	if currentProgress.Status == taskStatusRunning {
+		res.ETASeconds = estimateSeconds(remaining, currentProgress.readRate.perSecond(at))
+	}
+	return res, nil
+}
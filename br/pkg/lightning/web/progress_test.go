@@ -0,0 +1,131 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/checkpoints"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/lightning/verification"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollingRate(t *testing.T) {
+	start := time.Unix(1000, 0)
+	var r rollingRate
+	require.Zero(t, r.perSecond(start))
+	r.add(start, 0)
+	require.Zero(t, r.perSecond(start.Add(time.Second)))
+	r.add(start.Add(10*time.Second), 100)
+	require.Equal(t, 10.0, r.perSecond(start.Add(10*time.Second)))
+	// the rate drops when the counter stops growing.
+	require.Equal(t, 5.0, r.perSecond(start.Add(20*time.Second)))
+
+	// the samples out of the window are dropped, except the start of the window.
+	r.add(start.Add(70*time.Second), 700)
+	r.add(start.Add(80*time.Second), 1000)
+	require.Len(t, r.samples, 3)
+	require.Equal(t, 900.0/70, r.perSecond(start.Add(80*time.Second)))
+}
+
+func TestGetTaskProgress(t *testing.T) {
+	clock := time.Unix(1000, 0)
+	now = func() time.Time { return clock }
+	defer func() { now = time.Now }()
+
+	EnableCurrentProgress()
+	defer progressEnabled.Store(false)
+
+	BroadcastStartTask()
+	BroadcastInitProgress([]*mydump.MDDatabaseMeta{{
+		Name: "db",
+		Tables: []*mydump.MDTableMeta{
+			{DB: "db", Name: "t1", TotalSize: 1000},
+			{DB: "db", Name: "t2", TotalSize: 500},
+		},
+	}})
+	progress, err := GetTaskProgress()
+	require.NoError(t, err)
+	require.Equal(t, "running", progress.Status)
+	require.Equal(t, int64(1500), progress.TotalBytes)
+	require.Nil(t, progress.ETASeconds)
+	require.Len(t, progress.Tables, 2)
+	require.Equal(t, &TableProgress{Name: "`db`.`t1`", Status: "pending", TotalBytes: 1000, ChecksumState: "pending"}, progress.Tables[0])
+
+	key := checkpoints.ChunkCheckpointKey{Path: "/data/db.t1.csv"}
+	BroadcastTableCheckpoint("`db`.`t1`", &checkpoints.TableCheckpoint{
+		Status: checkpoints.CheckpointStatusLoaded,
+		Engines: map[int32]*checkpoints.EngineCheckpoint{
+			0: {
+				Status: checkpoints.CheckpointStatusLoaded,
+				Chunks: []*checkpoints.ChunkCheckpoint{{
+					Key:   key,
+					Chunk: mydump.Chunk{EndOffset: 1000, PrevRowIDMax: 0, RowIDMax: 100},
+				}},
+			},
+		},
+	})
+
+	clock = clock.Add(10 * time.Second)
+	cpd := checkpoints.NewTableCheckpointDiff()
+	merger := &checkpoints.ChunkCheckpointMerger{EngineID: 0, Key: key, Checksum: verification.MakeKVChecksum(800, 40, 1), Pos: 400, RowID: 40}
+	merger.MergeInto(cpd)
+	BroadcastCheckpointDiff(map[string]*checkpoints.TableCheckpointDiff{"`db`.`t1`": cpd})
+
+	progress, err = GetTaskProgress()
+	require.NoError(t, err)
+	require.Equal(t, int64(400), progress.BytesRead)
+	// 1100 bytes are left at 40 bytes per second.
+	require.Equal(t, 27.5, *progress.ETASeconds)
+	tp := progress.Tables[0]
+	require.Equal(t, "running", tp.Status)
+	require.Equal(t, int64(400), tp.BytesRead)
+	require.Equal(t, int64(40), tp.RowsEncoded)
+	require.Equal(t, uint64(800), tp.KVEncodedBytes)
+	require.Equal(t, uint64(0), tp.KVIngestedBytes)
+	require.Equal(t, 15.0, *tp.ETASeconds)
+
+	cpd = checkpoints.NewTableCheckpointDiff()
+	merger = &checkpoints.ChunkCheckpointMerger{EngineID: 0, Key: key, Checksum: verification.MakeKVChecksum(2000, 100, 2), Pos: 1000, RowID: 100}
+	merger.MergeInto(cpd)
+	for _, m := range []*checkpoints.StatusCheckpointMerger{
+		{EngineID: 0, Status: checkpoints.CheckpointStatusImported},
+		{EngineID: checkpoints.WholeTableEngineID, Status: checkpoints.CheckpointStatusChecksummed},
+	} {
+		m.MergeInto(cpd)
+	}
+	BroadcastCheckpointDiff(map[string]*checkpoints.TableCheckpointDiff{"`db`.`t1`": cpd})
+
+	progress, err = GetTaskProgress()
+	require.NoError(t, err)
+	tp = progress.Tables[0]
+	require.Equal(t, int64(1000), tp.BytesRead)
+	require.Equal(t, int64(100), tp.RowsEncoded)
+	require.Equal(t, uint64(2000), tp.KVIngestedBytes)
+	require.Equal(t, "passed", tp.ChecksumState)
+	require.Equal(t, 0.0, *tp.ETASeconds)
+
+	BroadcastError("`db`.`t2`", errors.New("injected error"))
+	BroadcastEndTask(errors.New("injected error"))
+	progress, err = GetTaskProgress()
+	require.NoError(t, err)
+	require.Equal(t, "failed", progress.Status)
+	require.Nil(t, progress.ETASeconds)
+	require.Equal(t, "failed", progress.Tables[1].Status)
+	require.Contains(t, progress.Tables[1].Error, "injected error")
+}
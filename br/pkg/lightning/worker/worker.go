@@ -16,6 +16,7 @@ package worker
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/pingcap/tidb/br/pkg/lightning/metric"
@@ -27,6 +28,12 @@ type Pool struct {
 	workers chan *Worker
 	name    string
 	metrics *metric.Metrics
+
+	mu sync.Mutex
+	// withheld are the workers taken out of the pool by SetLimit.
+	withheld []*Worker
+	// toWithhold is the number of the applied workers which will be withheld when they are recycled.
+	toWithhold int
 }
 
 // Worker is the worker struct.
@@ -69,12 +76,59 @@ func (pool *Pool) Recycle(worker *Worker) {
 	if worker == nil {
 		panic("invalid restore worker")
 	}
+	pool.mu.Lock()
+	if pool.toWithhold > 0 {
+		pool.toWithhold--
+		pool.withheld = append(pool.withheld, worker)
+		pool.mu.Unlock()
+		return
+	}
+	pool.mu.Unlock()
 	pool.workers <- worker
 	if pool.metrics != nil {
 		pool.metrics.IdleWorkersGauge.WithLabelValues(pool.name).Set(float64(len(pool.workers)))
 	}
 }
 
+// Capacity returns the number of the workers the pool is created with.
+func (pool *Pool) Capacity() int {
+	return pool.limit
+}
+
+// SetLimit changes the number of the workers which can be applied at the same time to limit,
+// which is adjusted into [1, Capacity()]. The applied workers are not interrupted, the pool
+// shrinks when they are recycled.
+func (pool *Pool) SetLimit(limit int) {
+	if limit < 1 {
+		limit = 1
+	} else if limit > pool.limit {
+		limit = pool.limit
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	target := pool.limit - limit
+	for current := len(pool.withheld) + pool.toWithhold; current < target; current++ {
+		select {
+		case worker := <-pool.workers:
+			pool.withheld = append(pool.withheld, worker)
+		default:
+			pool.toWithhold++
+		}
+	}
+	for current := len(pool.withheld) + pool.toWithhold; current > target; current-- {
+		if pool.toWithhold > 0 {
+			pool.toWithhold--
+			continue
+		}
+		last := len(pool.withheld) - 1
+		pool.workers <- pool.withheld[last]
+		pool.withheld = pool.withheld[:last]
+	}
+	if pool.metrics != nil {
+		pool.metrics.IdleWorkersGauge.WithLabelValues(pool.name).Set(float64(len(pool.workers)))
+	}
+}
+
 // HasWorker returns whether the pool has worker.
 func (pool *Pool) HasWorker() bool {
 	return len(pool.workers) > 0
@@ -43,3 +43,27 @@ func TestApplyRecycle(t *testing.T) {
 
 	require.PanicsWithValue(t, "invalid restore worker", func() { pool.Recycle(nil) })
 }
+
+func TestSetLimit(t *testing.T) {
+	pool := worker.NewPool(context.Background(), 3, "test")
+	require.Equal(t, 3, pool.Capacity())
+
+	w1 := pool.Apply()
+	// an idle worker is withheld immediately
+	pool.SetLimit(2)
+	w2 := pool.Apply()
+	require.False(t, pool.HasWorker())
+
+	// w1 is withheld when it's recycled
+	pool.SetLimit(0)
+	pool.Recycle(w1)
+	require.False(t, pool.HasWorker())
+	pool.Recycle(w2)
+	require.Equal(t, w2, pool.Apply())
+	require.False(t, pool.HasWorker())
+
+	pool.SetLimit(10)
+	pool.Apply()
+	pool.Apply()
+	require.False(t, pool.HasWorker())
+}
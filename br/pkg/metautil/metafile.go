@@ -37,6 +37,9 @@ const (
 	MetaFile = "backupmeta"
 	// MetaJSONFile represents backup meta json file name
 	MetaJSONFile = "backupmeta.json"
+	// DataKeyFile represents the file name of the generated key to encrypt the SST files,
+	// which only exists if the backup is encrypted by a key ring.
+	DataKeyFile = "backupdatakey"
 	// MaxBatchSize represents the internal channel buffer size of MetaWriter and MetaReader.
 	MaxBatchSize = 1024
 
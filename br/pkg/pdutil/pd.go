@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/coreos/go-semver/semver"
@@ -26,6 +27,7 @@ import (
 	berrors "github.com/pingcap/tidb/br/pkg/errors"
 	"github.com/pingcap/tidb/br/pkg/httputil"
 	"github.com/pingcap/tidb/br/pkg/lightning/common"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/pdtypes"
 	"github.com/pingcap/tidb/util/codec"
 	pd "github.com/tikv/pd/client"
@@ -846,6 +848,115 @@ func (p *PdController) pauseSchedulerByKeyRangeWithTTL(ctx context.Context, star
 	return done, nil
 }
 
+// KeyRangePauser pauses schedulers for regions in a growing set of key ranges. All the key
+// ranges share one region label rule with a TTL, which is renewed periodically until the context
+// is done and then deleted. If the process exits unexpectedly, PD removes the rule after the TTL
+// expires, so the schedulers are always resumed.
+type KeyRangePauser struct {
+	pd   *PdController
+	ttl  time.Duration
+	done chan struct{}
+
+	mu     sync.Mutex
+	rule   LabelRule
+	ranges []KeyRangeRule
+}
+
+// NewKeyRangePauser creates a KeyRangePauser and spawns a goroutine to keep pausing the added key
+// ranges until the context is done. No key range is paused before AddKeyRanges is called.
+func (p *PdController) NewKeyRangePauser(ctx context.Context) *KeyRangePauser {
+	return p.newKeyRangePauserWithTTL(ctx, pauseTimeout)
+}
+
+func (p *PdController) newKeyRangePauserWithTTL(ctx context.Context, ttl time.Duration) *KeyRangePauser {
+	kp := &KeyRangePauser{
+		pd:   p,
+		ttl:  ttl,
+		done: make(chan struct{}),
+		rule: LabelRule{
+			ID: uuid.New().String(),
+			Labels: []RegionLabel{{
+				Key:   "schedule",
+				Value: "deny",
+				TTL:   ttl.String(),
+			}},
+			RuleType: "key-range",
+		},
+	}
+	go kp.keepPausing(ctx)
+	return kp
+}
+
+// AddKeyRanges pauses schedulers for regions in the key ranges as well, the keys should be
+// encoded. It updates the rule in PD before returning.
+func (kp *KeyRangePauser) AddKeyRanges(ctx context.Context, keyRanges ...kv.KeyRange) error {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	ranges := kp.ranges
+	for _, r := range keyRanges {
+		ranges = append(ranges, KeyRangeRule{
+			StartKeyHex: hex.EncodeToString(r.StartKey),
+			EndKeyHex:   hex.EncodeToString(r.EndKey),
+		})
+	}
+	rule := kp.rule
+	rule.Data = ranges
+	if err := kp.pd.CreateOrUpdateRegionLabelRule(ctx, rule); err != nil {
+		return errors.Trace(err)
+	}
+	kp.ranges = ranges
+	return nil
+}
+
+// Done returns a channel which is closed after the rule is removed and the background goroutine
+// exits.
+func (kp *KeyRangePauser) Done() <-chan struct{} {
+	return kp.done
+}
+
+// currentRule returns the rule of the added key ranges, or false if no key range is added.
+func (kp *KeyRangePauser) currentRule() (LabelRule, bool) {
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	rule := kp.rule
+	rule.Data = kp.ranges
+	return rule, len(kp.ranges) > 0
+}
+
+func (kp *KeyRangePauser) keepPausing(ctx context.Context) {
+	defer close(kp.done)
+	ticker := time.NewTicker(kp.ttl / 3)
+	defer ticker.Stop()
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			rule, ok := kp.currentRule()
+			if !ok {
+				continue
+			}
+			if err := kp.pd.CreateOrUpdateRegionLabelRule(ctx, rule); err != nil {
+				if berrors.IsContextCanceled(err) {
+					break loop
+				}
+				log.Warn("pause scheduler by key ranges failed, ignore it and wait next time pause", zap.Error(err))
+			}
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	if _, ok := kp.currentRule(); !ok {
+		return
+	}
+	// Use a new context to avoid the context is canceled by the caller.
+	recoverCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := kp.pd.DeleteRegionLabelRule(recoverCtx, kp.rule.ID); err != nil {
+		log.Warn("failed to delete region label rule, the rule will be removed after ttl expires",
+			zap.String("rule-id", kp.rule.ID), zap.Duration("ttl", kp.ttl), zap.Error(err))
+	}
+}
+
 // CanPauseSchedulerByKeyRange returns whether the scheduler can be paused by key range.
 func (p *PdController) CanPauseSchedulerByKeyRange() bool {
 	// We need ttl feature to ensure scheduler can recover from pause automatically.
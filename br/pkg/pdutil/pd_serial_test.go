@@ -20,6 +20,7 @@ import (
 	"github.com/coreos/go-semver/semver"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/pdtypes"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/stretchr/testify/require"
@@ -288,3 +289,68 @@ func TestPauseSchedulersByKeyRange(t *testing.T) {
 	<-done
 	require.Len(t, labelExpires, 0)
 }
+
+func TestKeyRangePauser(t *testing.T) {
+	const ttl = time.Second
+
+	var (
+		mu          sync.Mutex
+		rules       = make(map[string][]KeyRangeRule)
+		updateCount int
+	)
+	httpSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodDelete {
+			ruleID := strings.TrimPrefix(r.URL.Path, "/"+regionLabelPrefix+"/")
+			delete(rules, ruleID)
+			return
+		}
+		var labelRule struct {
+			LabelRule
+			Data []KeyRangeRule `json:"data"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&labelRule)
+		require.NoError(t, err)
+		require.Len(t, labelRule.Labels, 1)
+		require.Equal(t, ttl.String(), labelRule.Labels[0].TTL)
+		rules[labelRule.ID] = labelRule.Data
+		updateCount++
+	}))
+	defer httpSrv.Close()
+
+	pdController := &PdController{addrs: []string{httpSrv.URL}, cli: http.DefaultClient}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pauser := pdController.newKeyRangePauserWithTTL(ctx, ttl)
+	time.Sleep(ttl / 2)
+	mu.Lock()
+	// nothing is paused before any key range is added.
+	require.Len(t, rules, 0)
+	mu.Unlock()
+
+	require.NoError(t, pauser.AddKeyRanges(ctx, kv.KeyRange{StartKey: []byte{1}, EndKey: []byte{2}}))
+	require.NoError(t, pauser.AddKeyRanges(ctx, kv.KeyRange{StartKey: []byte{3}, EndKey: []byte{4}}))
+	mu.Lock()
+	require.Len(t, rules, 1)
+	for _, ranges := range rules {
+		require.Equal(t, []KeyRangeRule{
+			{StartKeyHex: "01", EndKeyHex: "02"},
+			{StartKeyHex: "03", EndKeyHex: "04"},
+		}, ranges)
+	}
+	require.Equal(t, 2, updateCount)
+	mu.Unlock()
+
+	// the lease is renewed.
+	time.Sleep(ttl)
+	mu.Lock()
+	require.Greater(t, updateCount, 2)
+	mu.Unlock()
+
+	cancel()
+	<-pauser.Done()
+	mu.Lock()
+	require.Len(t, rules, 0)
+	mu.Unlock()
+}
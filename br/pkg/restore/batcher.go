@@ -54,6 +54,9 @@ type Batcher struct {
 	manager            ContextManager
 	batchSizeThreshold int
 	size               int32
+	// priorities, if set, orders the cached tables by their weights and drains their ranges
+	// by the weights.
+	priorities *TablePriorities
 }
 
 // Len calculate the current size of this batcher.
@@ -264,6 +267,10 @@ func (b *Batcher) drainRanges() DrainResult {
 	b.cachedTablesMu.Lock()
 	defer b.cachedTablesMu.Unlock()
 
+	if b.priorities != nil {
+		return b.drainRangesByWeight(result)
+	}
+
 	for offset, thisTable := range b.cachedTables {
 		thisTableLen := len(thisTable.Range)
 		collected := len(result.Ranges)
@@ -310,6 +317,48 @@ func (b *Batcher) drainRanges() DrainResult {
 	return result
 }
 
+// drainRangesByWeight 'drains' ranges from all the current tables, every table takes a share of the
+// batch proportional to its weight, see weightedQuotas. The caller must hold cachedTablesMu.
+func (b *Batcher) drainRangesByWeight(result DrainResult) DrainResult {
+	lens := make([]int, 0, len(b.cachedTables))
+	weights := make([]int, 0, len(b.cachedTables))
+	for _, thisTable := range b.cachedTables {
+		lens = append(lens, len(thisTable.Range))
+		weights = append(weights, b.priorities.weightOfTable(thisTable.OldTable))
+	}
+	quotas := weightedQuotas(lens, weights, b.batchSizeThreshold)
+
+	remained := b.cachedTables[:0]
+	for offset, thisTable := range b.cachedTables {
+		// the empty tables take no quota, but they are sent to leave the restore mode.
+		if quotas[offset] == 0 && len(thisTable.Range) > 0 {
+			remained = append(remained, thisTable)
+			continue
+		}
+		result.RewriteRules.Append(*thisTable.RewriteRule)
+		result.TablesToSend = append(result.TablesToSend, thisTable.CreatedTable)
+
+		drained := thisTable.Range[:quotas[offset]]
+		thisTable.Range = thisTable.Range[quotas[offset]:]
+		result.Ranges = append(result.Ranges, drained...)
+		atomic.AddInt32(&b.size, -int32(len(drained)))
+		log.Debug("draining table to batch by weight",
+			zap.Stringer("db", thisTable.OldTable.DB.Name),
+			zap.Stringer("table", thisTable.Table.Name),
+			zap.Int("weight", weights[offset]),
+			zap.Int("drained", len(drained)),
+			zap.Int("remained", len(thisTable.Range)),
+		)
+		if len(thisTable.Range) == 0 {
+			result.BlankTablesAfterSend = append(result.BlankTablesAfterSend, thisTable.CreatedTable)
+		} else {
+			remained = append(remained, thisTable)
+		}
+	}
+	b.cachedTables = remained
+	return result
+}
+
 // Send sends all pending requests in the batcher.
 // returns tables sent FULLY in the current batch.
 func (b *Batcher) Send(ctx context.Context) {
@@ -349,7 +398,7 @@ func (b *Batcher) Add(tbs TableWithRange) {
 		zap.Int("table size", len(tbs.Range)),
 		zap.Int("batch size", b.Len()),
 	)
-	b.cachedTables = append(b.cachedTables, tbs)
+	b.addToCache(tbs)
 	b.rewriteRules.Append(*tbs.RewriteRule)
 	atomic.AddInt32(&b.size, int32(len(tbs.Range)))
 	b.cachedTablesMu.Unlock()
@@ -357,6 +406,23 @@ func (b *Batcher) Add(tbs TableWithRange) {
 	b.sendIfFull()
 }
 
+// addToCache adds the table to the cached tables, the tables are kept in the descending order of
+// their weights if the priorities are set. The caller must hold cachedTablesMu.
+func (b *Batcher) addToCache(tbs TableWithRange) {
+	if b.priorities == nil {
+		b.cachedTables = append(b.cachedTables, tbs)
+		return
+	}
+	weight := b.priorities.weightOfTable(tbs.OldTable)
+	pos := len(b.cachedTables)
+	for pos > 0 && b.priorities.weightOfTable(b.cachedTables[pos-1].OldTable) < weight {
+		pos--
+	}
+	b.cachedTables = append(b.cachedTables, TableWithRange{})
+	copy(b.cachedTables[pos+1:], b.cachedTables[pos:])
+	b.cachedTables[pos] = tbs
+}
+
 // Close closes the batcher, sending all pending requests, close updateCh.
 func (b *Batcher) Close() {
 	log.Info("sending batch lastly on close", zap.Int("size", b.Len()))
@@ -372,3 +438,9 @@ func (b *Batcher) Close() {
 func (b *Batcher) SetThreshold(newThreshold int) {
 	b.batchSizeThreshold = newThreshold
 }
+
+// SetTablePriorities makes the batcher restore the ranges of the tables by their weights.
+// like SetThreshold, set the priorities before anything starts, please.
+func (b *Batcher) SetTablePriorities(priorities *TablePriorities) {
+	b.priorities = priorities
+}
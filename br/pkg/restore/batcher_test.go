@@ -382,3 +382,43 @@ func TestBatcherLen(t *testing.T) {
 	default:
 	}
 }
+
+func TestBatcherTablePriorities(t *testing.T) {
+	ctx := context.Background()
+	errCh := make(chan error, 8)
+	sender := newDrySender()
+	manager := newMockManager()
+	batcher, _ := restore.NewBatcher(ctx, sender, manager, errCh)
+	batcher.SetThreshold(9)
+	priorities, err := restore.ParseTablePriorities(map[string]string{"db.critical": "3"})
+	require.NoError(t, err)
+	batcher.SetTablePriorities(priorities)
+
+	bigTable := fakeTableWithRange(1, []rtree.Range{
+		fakeRange("baa", "bab"), fakeRange("bac", "bad"),
+		fakeRange("bae", "baf"), fakeRange("bag", "bai"),
+		fakeRange("baj", "bak"), fakeRange("bal", "bam"),
+		fakeRange("ban", "bao"), fakeRange("bap", "baq"),
+	})
+	bigTable.OldTable.DB.Name = model.NewCIStr("db")
+	bigTable.OldTable.Info.Name = model.NewCIStr("big")
+	criticalTable := fakeTableWithRange(2, []rtree.Range{
+		fakeRange("caa", "cab"), fakeRange("cac", "cad"),
+		fakeRange("cae", "caf"), fakeRange("cag", "cai"),
+	})
+	criticalTable.OldTable.DB.Name = model.NewCIStr("db")
+	criticalTable.OldTable.Info.Name = model.NewCIStr("critical")
+
+	// the critical table is restored first although it's added later.
+	batcher.Add(bigTable)
+	batcher.Add(criticalTable)
+	batcher.Close()
+	require.Equal(t, 2, sender.BatchCount())
+	require.Equal(t, append(append([]rtree.Range{}, criticalTable.Range...), bigTable.Range...), sender.Ranges())
+
+	select {
+	case err := <-errCh:
+		t.Fatal(errors.Trace(err))
+	default:
+	}
+}
@@ -44,6 +44,7 @@ import (
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/statistics/handle"
@@ -134,6 +135,9 @@ type Client struct {
 
 	supportPolicy bool
 
+	// ingestManifest skips the files ingested by the previous runs of the restore, nil if disabled.
+	ingestManifest *IngestManifest
+
 	// startTS and restoreTS are used for kv file restore.
 	// TiKV will filter the key space that don't belong to [startTS, restoreTS].
 	startTS   uint64
@@ -169,6 +173,13 @@ type Client struct {
 
 	// see RestoreCommonConfig.WithSysTable
 	withSysTable bool
+	// see RestoreCommonConfig.WithPlanBindings
+	withPlanBindings bool
+
+	// charsetMapping and collationMapping replace the charsets and collations of the
+	// databases created in restore, the keys are lower-case.
+	charsetMapping   map[string]string
+	collationMapping map[string]string
 }
 
 // NewRestoreClient returns a new RestoreClient.
@@ -246,6 +257,11 @@ func (rc *Client) SetPlacementPolicyMode(withPlacementPolicy string) {
 	log.Info("set placement policy mode", zap.String("mode", rc.policyMode))
 }
 
+// SetIngestManifest sets the manifest recording the ingested files.
+func (rc *Client) SetIngestManifest(m *IngestManifest) {
+	rc.ingestManifest = m
+}
+
 // SetRateLimit to set rateLimit.
 func (rc *Client) SetRateLimit(rateLimit uint64) {
 	rc.rateLimit = rateLimit
@@ -593,9 +609,81 @@ func (rc *Client) CreateDatabase(ctx context.Context, db *model.DBInfo) error {
 		}
 	}
 
+	db, err := rc.mapDBCharset(db)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	return rc.db.CreateDatabase(ctx, db)
 }
 
+// mapDBCharset replaces the charset and collation of the database by the mapping rules.
+// The charset is derived from the collation if the collation is replaced, so that they are
+// always consistent.
+func (rc *Client) mapDBCharset(db *model.DBInfo) (*model.DBInfo, error) {
+	toCharset, charsetMapped := rc.charsetMapping[strings.ToLower(db.Charset)]
+	toCollate, collateMapped := rc.collationMapping[strings.ToLower(db.Collate)]
+	if !charsetMapped && !collateMapped {
+		return db, nil
+	}
+
+	newDB := db.Clone()
+	if charsetMapped && len(db.Charset) > 0 {
+		cs, err := charset.GetCharsetInfo(toCharset)
+		if err != nil {
+			return nil, errors.Annotatef(berrors.ErrRestoreInvalidRewrite,
+				"charset %s of database %s is not supported: %v", toCharset, db.Name, err)
+		}
+		newDB.Charset = cs.Name
+		// the original collation doesn't belong to the new charset.
+		if !collateMapped {
+			newDB.Collate = cs.DefaultCollation
+		}
+	}
+	if collateMapped && len(db.Collate) > 0 {
+		coll, err := charset.GetCollationByName(toCollate)
+		if err == nil {
+			_, err = charset.GetCharsetInfo(coll.CharsetName)
+		}
+		if err != nil {
+			return nil, errors.Annotatef(berrors.ErrRestoreInvalidRewrite,
+				"collation %s of database %s is not supported: %v", toCollate, db.Name, err)
+		}
+		newDB.Charset = coll.CharsetName
+		newDB.Collate = coll.Name
+	}
+	log.Info("map the charset of database",
+		zap.Stringer("name", db.Name),
+		zap.String("charset", db.Charset), zap.String("new-charset", newDB.Charset),
+		zap.String("collation", db.Collate), zap.String("new-collation", newDB.Collate))
+	return newDB, nil
+}
+
+// createMissingDatabase creates the database of the ddl job if it doesn't exist in the
+// target cluster, otherwise the jobs of its tables fail. The database info of the backup
+// is used if there is one.
+func (rc *Client) createMissingDatabase(ctx context.Context, job *model.Job) error {
+	switch job.Type {
+	case model.ActionCreateSchema, model.ActionDropSchema:
+		return nil
+	}
+	if len(job.SchemaName) == 0 || rc.dom == nil {
+		return nil
+	}
+	dbName := model.NewCIStr(job.SchemaName)
+	if _, exists := rc.GetDBSchema(rc.dom, dbName); exists {
+		return nil
+	}
+
+	dbInfo := &model.DBInfo{Name: dbName}
+	if db := rc.GetDatabase(job.SchemaName); db != nil && db.Info != nil {
+		dbInfo = db.Info
+	}
+	log.Info("create the missing database of ddl job",
+		zap.Stringer("name", dbName),
+		zap.String("query", job.Query))
+	return rc.CreateDatabase(ctx, dbInfo)
+}
+
 // CreateTables creates multiple tables, and returns their rewrite rules.
 func (rc *Client) CreateTables(
 	dom *domain.Domain,
@@ -959,6 +1047,9 @@ func (rc *Client) ExecDDLs(ctx context.Context, ddlJobs []*model.Job) error {
 	})
 
 	for _, job := range ddlJobs {
+		if err := rc.createMissingDatabase(ctx, job); err != nil {
+			return errors.Trace(err)
+		}
 		err := rc.db.ExecDDL(ctx, job)
 		if err != nil {
 			return errors.Trace(err)
@@ -1096,6 +1187,11 @@ func (rc *Client) RestoreSSTFiles(
 	var leftFiles []*backuppb.File
 	for rangeFiles, leftFiles = drainFilesByRange(files, rc.fileImporter.supportMultiIngest); len(rangeFiles) != 0; rangeFiles, leftFiles = drainFilesByRange(leftFiles, rc.fileImporter.supportMultiIngest) {
 		filesReplica := rangeFiles
+		if rc.ingestManifest != nil && rc.ingestManifest.allIngested(filesReplica, rewriteRules) {
+			log.Info("skip the files ingested by the previous restore", logutil.Files(filesReplica))
+			updateCh.Inc()
+			continue
+		}
 		rc.workerPool.ApplyOnErrorGroup(eg,
 			func() error {
 				fileStart := time.Now()
@@ -1104,7 +1200,15 @@ func (rc *Client) RestoreSSTFiles(
 						zap.Duration("take", time.Since(fileStart)))
 					updateCh.Inc()
 				}()
-				return rc.fileImporter.ImportSSTFiles(ectx, filesReplica, rewriteRules, rc.cipher, rc.backupMeta.ApiVersion)
+				err := rc.fileImporter.ImportSSTFiles(ectx, filesReplica, rewriteRules, rc.cipher, rc.backupMeta.ApiVersion)
+				if err != nil || rc.ingestManifest == nil {
+					return err
+				}
+				if err := rc.ingestManifest.recordIngested(ectx, filesReplica, rewriteRules); err != nil {
+					// the files are ingested again if the restore is retried, which is slower but correct.
+					log.Warn("failed to record the ingested files", logutil.Files(filesReplica), logutil.ShortError(err))
+				}
+				return nil
 			})
 	}
 
@@ -2509,6 +2613,29 @@ func (rc *Client) SetWithSysTable(withSysTable bool) {
 	rc.withSysTable = withSysTable
 }
 
+// SetWithPlanBindings sets whether to restore the SQL bindings and the plan-related global variables.
+func (rc *Client) SetWithPlanBindings(withPlanBindings bool) {
+	rc.withPlanBindings = withPlanBindings
+}
+
+// SetCharsetMapping sets the rules to replace the charsets and collations of the databases
+// created in restore, e.g. the deprecated ones which aren't supported by the target cluster.
+func (rc *Client) SetCharsetMapping(charsets, collations map[string]string) {
+	rc.charsetMapping = lowerCaseKeys(charsets)
+	rc.collationMapping = lowerCaseKeys(collations)
+}
+
+func lowerCaseKeys(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	res := make(map[string]string, len(m))
+	for k, v := range m {
+		res[strings.ToLower(k)] = v
+	}
+	return res
+}
+
 // MockClient create a fake client used to test.
 func MockClient(dbs map[string]*utils.Database) *Client {
 	return &Client{databases: dbs}
@@ -112,6 +112,75 @@ func TestIsOnline(t *testing.T) {
 	require.True(t, client.IsOnline())
 }
 
+func TestCreateDatabaseWithCharsetMapping(t *testing.T) {
+	m := mc
+	g := gluetidb.New()
+	client := restore.NewRestoreClient(m.PDClient, nil, defaultKeepaliveCfg, false)
+	err := client.Init(g, m.Storage)
+	require.NoError(t, err)
+	client.SetCharsetMapping(
+		map[string]string{"UTF8MB3": "utf8"},
+		map[string]string{"utf8mb3_unicode_ci": "utf8mb4_unicode_ci"},
+	)
+	ctx := context.Background()
+
+	cases := []struct {
+		db      *model.DBInfo
+		charset string
+		collate string
+	}{
+		{
+			db:      &model.DBInfo{Name: model.NewCIStr("mapped_charset"), Charset: "utf8mb3", Collate: "utf8mb3_general_ci"},
+			charset: "utf8",
+			collate: "utf8_bin",
+		},
+		{
+			db:      &model.DBInfo{Name: model.NewCIStr("mapped_collation"), Charset: "utf8mb3", Collate: "utf8mb3_unicode_ci"},
+			charset: "utf8mb4",
+			collate: "utf8mb4_unicode_ci",
+		},
+		{
+			db:      &model.DBInfo{Name: model.NewCIStr("not_mapped"), Charset: "utf8mb4", Collate: "utf8mb4_bin"},
+			charset: "utf8mb4",
+			collate: "utf8mb4_bin",
+		},
+	}
+	for _, ca := range cases {
+		require.NoError(t, client.CreateDatabase(ctx, ca.db))
+		db, ok := client.GetDBSchema(m.Domain, ca.db.Name)
+		require.True(t, ok)
+		require.Equal(t, ca.charset, db.Charset)
+		require.Equal(t, ca.collate, db.Collate)
+	}
+
+	// the database of the ddl job is created if it's missing.
+	intField := types.NewFieldType(mysql.TypeLong)
+	intField.SetCharset("binary")
+	job := &model.Job{
+		Type:       model.ActionCreateTable,
+		SchemaName: "missing_db",
+		Query:      "CREATE TABLE `t` (`id` INT)",
+		BinlogInfo: &model.HistoryInfo{
+			SchemaVersion: 1,
+			TableInfo: &model.TableInfo{
+				ID:   1,
+				Name: model.NewCIStr("t"),
+				Columns: []*model.ColumnInfo{{
+					ID:        1,
+					Name:      model.NewCIStr("id"),
+					FieldType: *intField,
+					State:     model.StatePublic,
+				}},
+				Charset: "utf8mb4",
+				Collate: "utf8mb4_bin",
+			},
+		},
+	}
+	require.NoError(t, client.ExecDDLs(ctx, []*model.Job{job}))
+	_, err = client.GetTableSchema(m.Domain, model.NewCIStr("missing_db"), model.NewCIStr("t"))
+	require.NoError(t, err)
+}
+
 func getStartedMockedCluster(t *testing.T) *mock.Cluster {
 	t.Helper()
 	cluster, err := mock.NewCluster()
@@ -0,0 +1,104 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package restore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+
+	"github.com/pingcap/errors"
+	backuppb "github.com/pingcap/kvproto/pkg/brpb"
+	"github.com/pingcap/log"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+const ingestManifestPrefix = "/tidb/br/restore/ingested"
+
+// IngestManifest records the SST files ingested by a restore in the etcd of PD, so a retried
+// restore of the same backup skips the files which have been ingested before the failure.
+//
+// A file is identified by its checksum and its range rewritten to the restored table. The
+// tables created again, e.g. after being dropped, have new table IDs, so their files are
+// never skipped.
+type IngestManifest struct {
+	cli    *clientv3.Client
+	prefix string
+	// ingested is loaded when the restore starts, and isn't updated by the restore.
+	ingested map[string]struct{}
+}
+
+// NewIngestManifest loads the files ingested by the previous runs of the restore of the backup.
+func NewIngestManifest(ctx context.Context, cli *clientv3.Client, backupMeta *backuppb.BackupMeta) (*IngestManifest, error) {
+	m := &IngestManifest{
+		cli: cli,
+		prefix: path.Join(ingestManifestPrefix, fmt.Sprint(backupMeta.GetClusterId()),
+			fmt.Sprintf("%d-%d", backupMeta.GetStartVersion(), backupMeta.GetEndVersion())) + "/",
+		ingested: make(map[string]struct{}),
+	}
+	resp, err := cli.Get(ctx, m.prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, errors.Annotate(err, "failed to load the ingest manifest")
+	}
+	for _, kv := range resp.Kvs {
+		m.ingested[string(kv.Key)] = struct{}{}
+	}
+	if len(m.ingested) > 0 {
+		log.Info("found the files ingested by the previous restore, they will be skipped",
+			zap.String("manifest", m.prefix), zap.Int("files", len(m.ingested)))
+	}
+	return m, nil
+}
+
+func (m *IngestManifest) fileKey(file *backuppb.File, rewriteRules *RewriteRules) (string, error) {
+	startKey, endKey, err := GetRewriteRawKeys(file, rewriteRules)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	h := sha256.New()
+	h.Write(file.GetSha256())
+	h.Write([]byte(file.GetName()))
+	h.Write(startKey)
+	h.Write(endKey)
+	return m.prefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// allIngested checks whether all the files have been ingested by the previous restore.
+func (m *IngestManifest) allIngested(files []*backuppb.File, rewriteRules *RewriteRules) bool {
+	if len(m.ingested) == 0 {
+		return false
+	}
+	for _, file := range files {
+		key, err := m.fileKey(file, rewriteRules)
+		if err != nil {
+			return false
+		}
+		if _, ok := m.ingested[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// recordIngested records the ingested files.
+func (m *IngestManifest) recordIngested(ctx context.Context, files []*backuppb.File, rewriteRules *RewriteRules) error {
+	ops := make([]clientv3.Op, 0, len(files))
+	for _, file := range files {
+		key, err := m.fileKey(file, rewriteRules)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ops = append(ops, clientv3.OpPut(key, ""))
+	}
+	_, err := m.cli.Txn(ctx).Then(ops...).Commit()
+	return errors.Trace(err)
+}
+
+// Clear removes the manifest after the restore is finished.
+func (m *IngestManifest) Clear(ctx context.Context) error {
+	_, err := m.cli.Delete(ctx, m.prefix, clientv3.WithPrefix())
+	return errors.Trace(err)
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package restore
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+
+	backuppb "github.com/pingcap/kvproto/pkg/brpb"
+	"github.com/pingcap/kvproto/pkg/import_sstpb"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+func startEtcd(t *testing.T) *clientv3.Client {
+	randomLocalURL := func() url.URL {
+		l, err := net.Listen("tcp", "127.0.0.1:")
+		require.NoError(t, err)
+		addr := l.Addr().String()
+		require.NoError(t, l.Close())
+		return url.URL{Scheme: "http", Host: addr}
+	}
+	etcdCfg := embed.NewConfig()
+	etcdCfg.Dir = t.TempDir()
+	clientURL := randomLocalURL()
+	etcdCfg.LCUrls = []url.URL{clientURL}
+	etcdCfg.ACUrls = []url.URL{clientURL}
+	peerURL := randomLocalURL()
+	etcdCfg.LPUrls = []url.URL{peerURL}
+	etcdCfg.APUrls = []url.URL{peerURL}
+	etcdCfg.InitialCluster = etcdCfg.InitialClusterFromName(etcdCfg.Name)
+	etcdCfg.LogLevel = "fatal"
+	etcd, err := embed.StartEtcd(etcdCfg)
+	require.NoError(t, err)
+	t.Cleanup(etcd.Close)
+	<-etcd.Server.ReadyNotify()
+
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{clientURL.Host}})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, cli.Close()) })
+	return cli
+}
+
+func rewriteRulesOf(oldID, newID int64) *RewriteRules {
+	return &RewriteRules{
+		Data: []*import_sstpb.RewriteRule{{
+			OldKeyPrefix: tablecodec.EncodeTablePrefix(oldID),
+			NewKeyPrefix: tablecodec.EncodeTablePrefix(newID),
+		}},
+	}
+}
+
+func TestIngestManifest(t *testing.T) {
+	ctx := context.Background()
+	cli := startEtcd(t)
+	backupMeta := &backuppb.BackupMeta{ClusterId: 1, EndVersion: 100}
+	files := []*backuppb.File{
+		{
+			Name:     "1_default.sst",
+			Sha256:   []byte("default"),
+			StartKey: tablecodec.EncodeRowKeyWithHandle(1, kv.IntHandle(1)),
+			EndKey:   tablecodec.EncodeRowKeyWithHandle(1, kv.IntHandle(100)),
+		},
+		{
+			Name:     "1_write.sst",
+			Sha256:   []byte("write"),
+			StartKey: tablecodec.EncodeRowKeyWithHandle(1, kv.IntHandle(1)),
+			EndKey:   tablecodec.EncodeRowKeyWithHandle(1, kv.IntHandle(100)),
+		},
+	}
+	rules := rewriteRulesOf(1, 10)
+
+	m, err := NewIngestManifest(ctx, cli, backupMeta)
+	require.NoError(t, err)
+	require.False(t, m.allIngested(files, rules))
+	require.NoError(t, m.recordIngested(ctx, files[:1], rules))
+
+	// the retried restore only skips the files ingested before.
+	m, err = NewIngestManifest(ctx, cli, backupMeta)
+	require.NoError(t, err)
+	require.True(t, m.allIngested(files[:1], rules))
+	require.False(t, m.allIngested(files, rules))
+	// the files restored to a table created again are ingested again.
+	require.False(t, m.allIngested(files[:1], rewriteRulesOf(1, 11)))
+	// so are the files of another backup.
+	other, err := NewIngestManifest(ctx, cli, &backuppb.BackupMeta{ClusterId: 1, EndVersion: 200})
+	require.NoError(t, err)
+	require.False(t, other.allIngested(files[:1], rules))
+
+	require.NoError(t, m.Clear(ctx))
+	m, err = NewIngestManifest(ctx, cli, backupMeta)
+	require.NoError(t, err)
+	require.False(t, m.allIngested(files[:1], rules))
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package restore
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/pingcap/errors"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	filter "github.com/pingcap/tidb/util/table-filter"
+)
+
+// DefaultTableWeight is the weight of the tables which aren't matched by any priority rule.
+const DefaultTableWeight = 1
+
+// TablePriorities are the weights of the tables to restore. The tables with larger weights are
+// created and restored first, and when the ranges of several tables are waiting in the batcher,
+// every table takes a share of the batch proportional to its weight, so the critical tables
+// aren't blocked behind the huge ones.
+type TablePriorities struct {
+	rules []tablePriorityRule
+}
+
+type tablePriorityRule struct {
+	filter filter.Filter
+	weight int
+}
+
+// ParseTablePriorities parses the weights of the tables. The keys are table filter rules, e.g.
+// `db.*`, and the values are positive integers. A table matched by several rules takes the
+// largest weight. It returns nil if there is no weight.
+func ParseTablePriorities(weights map[string]string) (*TablePriorities, error) {
+	if len(weights) == 0 {
+		return nil, nil
+	}
+	patterns := make([]string, 0, len(weights))
+	for pattern := range weights {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	p := &TablePriorities{rules: make([]tablePriorityRule, 0, len(patterns))}
+	for _, pattern := range patterns {
+		weight, err := strconv.Atoi(weights[pattern])
+		if err != nil || weight <= 0 {
+			return nil, errors.Annotatef(berrors.ErrInvalidArgument,
+				"the weight of the tables %s must be a positive integer, got %s", pattern, weights[pattern])
+		}
+		f, err := filter.Parse([]string{pattern})
+		if err != nil {
+			return nil, errors.Annotatef(berrors.ErrInvalidArgument,
+				"invalid table filter rule %s: %s", pattern, err.Error())
+		}
+		p.rules = append(p.rules, tablePriorityRule{filter: filter.CaseInsensitive(f), weight: weight})
+	}
+	return p, nil
+}
+
+// WeightOf returns the weight of the table.
+func (p *TablePriorities) WeightOf(db, table string) int {
+	weight := 0
+	for _, rule := range p.rules {
+		if rule.weight > weight && rule.filter.MatchTable(db, table) {
+			weight = rule.weight
+		}
+	}
+	if weight == 0 {
+		return DefaultTableWeight
+	}
+	return weight
+}
+
+func (p *TablePriorities) weightOfTable(t *metautil.Table) int {
+	return p.WeightOf(t.DB.Name.O, t.Info.Name.O)
+}
+
+// SortTables sorts the tables by their weights in descending order, the tables of the same weight
+// keep their original order.
+func (p *TablePriorities) SortTables(tables []*metautil.Table) {
+	sort.SliceStable(tables, func(i, j int) bool {
+		return p.weightOfTable(tables[i]) > p.weightOfTable(tables[j])
+	})
+}
+
+// weightedQuotas splits the batch of size threshold to the tables by their weights. Every table
+// takes at least one range of the batch while there is room, so the tables of small weights
+// aren't starved, and the room left by the tables having fewer ranges than their shares is split
+// again to the others.
+func weightedQuotas(lens []int, weights []int, threshold int) []int {
+	quotas := make([]int, len(lens))
+	left := threshold
+	for i := range lens {
+		if left > 0 && lens[i] > 0 {
+			quotas[i] = 1
+			left--
+		}
+	}
+	for left > 0 {
+		totalWeight := 0
+		for i := range lens {
+			if quotas[i] < lens[i] {
+				totalWeight += weights[i]
+			}
+		}
+		if totalWeight == 0 {
+			break
+		}
+		roundSize := left
+		for i := range lens {
+			if quotas[i] >= lens[i] || left == 0 {
+				continue
+			}
+			share := roundSize * weights[i] / totalWeight
+			// make sure every round makes progress.
+			if share < 1 {
+				share = 1
+			}
+			if share > lens[i]-quotas[i] {
+				share = lens[i] - quotas[i]
+			}
+			if share > left {
+				share = left
+			}
+			quotas[i] += share
+			left -= share
+		}
+	}
+	return quotas
+}
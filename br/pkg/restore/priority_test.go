@@ -0,0 +1,55 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package restore
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTablePriorities(t *testing.T) {
+	p, err := ParseTablePriorities(nil)
+	require.NoError(t, err)
+	require.Nil(t, p)
+	_, err = ParseTablePriorities(map[string]string{"db.*": "0"})
+	require.Error(t, err)
+	_, err = ParseTablePriorities(map[string]string{"db.*": "high"})
+	require.Error(t, err)
+
+	p, err = ParseTablePriorities(map[string]string{"db.*": "5", "db.orders": "10"})
+	require.NoError(t, err)
+	require.Equal(t, 10, p.WeightOf("db", "orders"))
+	require.Equal(t, 10, p.WeightOf("DB", "Orders"))
+	require.Equal(t, 5, p.WeightOf("db", "items"))
+	require.Equal(t, DefaultTableWeight, p.WeightOf("test", "orders"))
+
+	table := func(db, name string) *metautil.Table {
+		return &metautil.Table{
+			DB:   &model.DBInfo{Name: model.NewCIStr(db)},
+			Info: &model.TableInfo{Name: model.NewCIStr(name)},
+		}
+	}
+	tables := []*metautil.Table{table("test", "a"), table("db", "items"), table("test", "b"), table("db", "orders")}
+	p.SortTables(tables)
+	names := make([]string, 0, len(tables))
+	for _, tbl := range tables {
+		names = append(names, tbl.DB.Name.O+"."+tbl.Info.Name.O)
+	}
+	require.Equal(t, []string{"db.orders", "db.items", "test.a", "test.b"}, names)
+}
+
+func TestWeightedQuotas(t *testing.T) {
+	// the batch is split by the weights.
+	require.Equal(t, []int{6, 2, 2}, weightedQuotas([]int{100, 100, 100}, []int{3, 1, 1}, 10))
+	// the room left by the small tables is split to the others.
+	require.Equal(t, []int{2, 8}, weightedQuotas([]int{2, 100}, []int{3, 1}, 10))
+	// every table takes at least one range while there is room.
+	require.Equal(t, []int{2, 1, 1}, weightedQuotas([]int{100, 100, 100}, []int{100, 1, 1}, 4))
+	require.Equal(t, []int{1, 1}, weightedQuotas([]int{100, 100}, []int{100, 1}, 2))
+	require.Equal(t, []int{1, 0}, weightedQuotas([]int{100, 100}, []int{100, 1}, 1))
+	// all the ranges are drained if the batch is large enough.
+	require.Equal(t, []int{2, 3}, weightedQuotas([]int{2, 3}, []int{1, 1}, 10))
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/log"
+	"github.com/pingcap/tidb/bindinfo"
 	berrors "github.com/pingcap/tidb/br/pkg/errors"
 	"github.com/pingcap/tidb/br/pkg/logutil"
 	"github.com/pingcap/tidb/br/pkg/utils"
@@ -44,6 +45,20 @@ var unRecoverableTable = map[string]struct{}{
 	"schema_index_usage": {},
 }
 
+// tables in this map hold the configuration of the plan stability and are
+// restored when withPlanBindings=true
+var planBindingTables = map[string]struct{}{
+	"bind_info":        {},
+	"global_variables": {},
+}
+
+// planRelatedVariablesFilter is the filter in SQL where clause which selects the
+// global variables affecting the plan stability, including the optimizer switches
+// and fix controls (tidb_opt_*).
+const planRelatedVariablesFilter = "variable_name LIKE 'tidb\\_opt\\_%' OR variable_name IN (" +
+	"'tidb_use_plan_baselines', 'tidb_capture_plan_baselines', 'tidb_evolve_plan_baselines', " +
+	"'tidb_enable_index_merge', 'tidb_cost_model_version', 'tidb_enable_prepared_plan_cache')"
+
 // tables in this map is restored when fullClusterRestore=true
 // the value part is the filter in SQL where clause which is used to
 // skip clearing or restoring 'cloud_admin'@'%' which is a special
@@ -64,6 +79,34 @@ func isUnrecoverableTable(tableName string) bool {
 	return ok
 }
 
+func isPlanBindingTable(tableName string) bool {
+	_, ok := planBindingTables[tableName]
+	return ok
+}
+
+// WithPlanBindingTables extends the filter to match the system tables holding the
+// SQL bindings and the plan-related global variables.
+func WithPlanBindingTables(f filter.Filter) filter.Filter {
+	return planBindingTablesFilter{Filter: f}
+}
+
+type planBindingTablesFilter struct {
+	filter.Filter
+}
+
+// MatchTable implements filter.Filter.
+func (f planBindingTablesFilter) MatchTable(schema string, table string) bool {
+	if strings.EqualFold(schema, mysql.SystemDB) && isPlanBindingTable(strings.ToLower(table)) {
+		return true
+	}
+	return f.Filter.MatchTable(schema, table)
+}
+
+// MatchSchema implements filter.Filter.
+func (f planBindingTablesFilter) MatchSchema(schema string) bool {
+	return strings.EqualFold(schema, mysql.SystemDB) || f.Filter.MatchSchema(schema)
+}
+
 func isStatsTable(tableName string) bool {
 	_, ok := statsTables[tableName]
 	return ok
@@ -77,7 +120,7 @@ func (rc *Client) RestoreSystemSchemas(ctx context.Context, f filter.Filter) {
 	temporaryDB := utils.TemporaryDBName(sysDB)
 	defer rc.cleanTemporaryDatabase(ctx, sysDB)
 
-	if !f.MatchSchema(sysDB) || !rc.withSysTable {
+	if !f.MatchSchema(sysDB) || (!rc.withSysTable && !rc.withPlanBindings) {
 		log.Debug("system database filtered out", zap.String("database", sysDB))
 		return
 	}
@@ -96,6 +139,9 @@ func (rc *Client) RestoreSystemSchemas(ctx context.Context, f filter.Filter) {
 	tablesRestored := make([]string, 0, len(originDatabase.Tables))
 	for _, table := range originDatabase.Tables {
 		tableName := table.Info.Name
+		if !rc.withSysTable && !isPlanBindingTable(tableName.L) {
+			continue
+		}
 		if f.MatchTable(sysDB, tableName.O) {
 			if err := rc.replaceTemporaryTableToSystable(ctx, table.Info, db); err != nil {
 				log.Warn("error during merging temporary tables into system tables",
@@ -156,6 +202,13 @@ func (rc *Client) afterSystemTablesReplaced(tables []string) error {
 				err = multierr.Append(err, errors.Annotatef(berrors.ErrUnsupportedSystemTable,
 					"restored user info may not take effect, until you should execute `FLUSH PRIVILEGES` manually"))
 			}
+		case table == "bind_info" && rc.withPlanBindings:
+			// other TiDB instances load the bindings incrementally by their update time.
+			if h := rc.dom.BindHandle(); h != nil {
+				err = multierr.Append(err, h.ReloadBindings())
+			}
+		case table == "global_variables" && rc.withPlanBindings:
+			rc.dom.NotifyUpdateSysVarCache()
 		}
 	}
 	return err
@@ -196,6 +249,41 @@ func (rc *Client) replaceTemporaryTableToSystable(ctx context.Context, ti *model
 			"the table ID is out-of-date and may corrupt existing statistics")
 	}
 
+	// target column order may different with source cluster
+	columnNames := make([]string, 0, len(ti.Columns))
+	for _, col := range ti.Columns {
+		columnNames = append(columnNames, utils.EncloseName(col.Name.L))
+	}
+	colListStr := strings.Join(columnNames, ",")
+
+	if rc.withPlanBindings && isPlanBindingTable(tableName) && db.ExistingTables[tableName] != nil {
+		switch tableName {
+		case "bind_info":
+			// mysql.bind_info has no unique key, so the existing bindings of the restored
+			// statements are deleted first. The builtin record used as a lock is left as is.
+			deleteSQL := fmt.Sprintf("DELETE FROM %s WHERE source != '%s' AND (original_sql, default_db) IN "+
+				"(SELECT original_sql, default_db FROM %s WHERE source != '%s');",
+				utils.EncloseDBAndTable(db.Name.L, tableName), bindinfo.Builtin,
+				utils.EncloseDBAndTable(db.TemporaryName.L, tableName), bindinfo.Builtin)
+			if err := execSQL(deleteSQL); err != nil {
+				return err
+			}
+			// refresh the update time so that the bindings are loaded by the other TiDB instances.
+			selectList := strings.Replace(colListStr, utils.EncloseName("update_time"), "NOW(3)", 1)
+			insertSQL := fmt.Sprintf("INSERT INTO %s(%s) SELECT %s FROM %s WHERE source != '%s';",
+				utils.EncloseDBAndTable(db.Name.L, tableName), colListStr, selectList,
+				utils.EncloseDBAndTable(db.TemporaryName.L, tableName), bindinfo.Builtin)
+			return execSQL(insertSQL)
+		case "global_variables":
+			replaceIntoSQL := fmt.Sprintf("REPLACE INTO %s(%s) SELECT %s FROM %s WHERE %s;",
+				utils.EncloseDBAndTable(db.Name.L, tableName),
+				colListStr, colListStr,
+				utils.EncloseDBAndTable(db.TemporaryName.L, tableName),
+				planRelatedVariablesFilter)
+			return execSQL(replaceIntoSQL)
+		}
+	}
+
 	if isUnrecoverableTable(tableName) {
 		return berrors.ErrUnsupportedSystemTable.GenWithStack("restoring unsupported `mysql` schema table")
 	}
@@ -216,12 +304,6 @@ func (rc *Client) replaceTemporaryTableToSystable(ctx context.Context, ti *model
 		log.Info("replace into existing table",
 			zap.String("table", tableName),
 			zap.Stringer("schema", db.Name))
-		// target column order may different with source cluster
-		columnNames := make([]string, 0, len(ti.Columns))
-		for _, col := range ti.Columns {
-			columnNames = append(columnNames, utils.EncloseName(col.Name.L))
-		}
-		colListStr := strings.Join(columnNames, ",")
 		replaceIntoSQL := fmt.Sprintf("REPLACE INTO %s(%s) SELECT %s FROM %s %s;",
 			utils.EncloseDBAndTable(db.Name.L, tableName),
 			colListStr, colListStr,
@@ -0,0 +1,27 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package restore_test
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb/br/pkg/restore"
+	filter "github.com/pingcap/tidb/util/table-filter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPlanBindingTables(t *testing.T) {
+	f, err := filter.Parse([]string{"db.*", "!mysql.*"})
+	require.NoError(t, err)
+	f = restore.WithPlanBindingTables(filter.CaseInsensitive(f))
+
+	require.True(t, f.MatchSchema("db"))
+	require.True(t, f.MatchTable("db", "t"))
+	require.True(t, f.MatchSchema("mysql"))
+	require.True(t, f.MatchTable("mysql", "bind_info"))
+	require.True(t, f.MatchTable("MySQL", "Global_Variables"))
+	require.False(t, f.MatchTable("mysql", "user"))
+	require.False(t, f.MatchTable("mysql", "tidb"))
+	require.False(t, f.MatchSchema("test"))
+	require.False(t, f.MatchTable("test", "bind_info"))
+}
@@ -6,12 +6,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"strings"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/google/uuid"
@@ -28,14 +34,39 @@ const (
 	azblobAccessTierOption = "azblob.access-tier"
 	azblobAccountName      = "azblob.account-name"
 	azblobAccountKey       = "azblob.account-key"
+	azblobSASToken         = "azblob.sas-token"
+	azblobManagedIdentity  = "azblob.use-managed-identity"
+	azblobManagedClientID  = "azblob.managed-identity-client-id"
+	azblobWorkloadIdentity = "azblob.use-workload-identity"
 )
 
+// AzureCredentialOptions are the ways to access Azure Blob storage without
+// the account key.
+type AzureCredentialOptions struct {
+	// SASToken is the shared access signature appended to the service URL.
+	SASToken string
+	// UseManagedIdentity authenticates with the managed identity of the Azure
+	// VM or AKS node.
+	UseManagedIdentity bool
+	// ManagedIdentityClientID is the client ID of the user-assigned managed
+	// identity. The system-assigned one is used if it's empty.
+	ManagedIdentityClientID string
+	// UseWorkloadIdentity authenticates with the federated token of Azure AD
+	// workload identity, which is located by $AZURE_FEDERATED_TOKEN_FILE.
+	UseWorkloadIdentity bool
+}
+
 // AzblobBackendOptions is the options for Azure Blob storage.
 type AzblobBackendOptions struct {
 	Endpoint    string `json:"endpoint" toml:"endpoint"`
 	AccountName string `json:"account-name" toml:"account-name"`
 	AccountKey  string `json:"account-key" toml:"account-key"`
 	AccessTier  string `json:"access-tier" toml:"access-tier"`
+
+	SASToken                string `json:"sas-token" toml:"sas-token"`
+	UseManagedIdentity      bool   `json:"use-managed-identity" toml:"use-managed-identity"`
+	ManagedIdentityClientID string `json:"managed-identity-client-id" toml:"managed-identity-client-id"`
+	UseWorkloadIdentity     bool   `json:"use-workload-identity" toml:"use-workload-identity"`
 }
 
 func (options *AzblobBackendOptions) apply(azblob *backuppb.AzureBlobStorage) error {
@@ -43,14 +74,42 @@ func (options *AzblobBackendOptions) apply(azblob *backuppb.AzureBlobStorage) er
 	azblob.StorageClass = options.AccessTier
 	azblob.AccountName = options.AccountName
 	azblob.SharedKey = options.AccountKey
+	if len(options.SASToken) > 0 {
+		// the proto has no field for the SAS token, so it's carried as a SAS URL
+		// of the service, which is accepted by the azure SDK as is.
+		if len(azblob.Endpoint) == 0 {
+			if len(azblob.AccountName) == 0 {
+				return errors.Annotate(berrors.ErrStorageInvalidConfig, "account name or endpoint must be set to use the azure SAS token")
+			}
+			azblob.Endpoint = defaultAzblobServiceURL(azblob.AccountName)
+		}
+		azblob.Endpoint = withAzureSASToken(azblob.Endpoint, options.SASToken)
+	}
 	return nil
 }
 
+// CredentialOptions returns the Azure credential options which can't be
+// carried by the storage backend. It returns nil if none is set.
+func (options *AzblobBackendOptions) CredentialOptions() *AzureCredentialOptions {
+	if !options.UseManagedIdentity && !options.UseWorkloadIdentity {
+		return nil
+	}
+	return &AzureCredentialOptions{
+		UseManagedIdentity:      options.UseManagedIdentity,
+		ManagedIdentityClientID: options.ManagedIdentityClientID,
+		UseWorkloadIdentity:     options.UseWorkloadIdentity,
+	}
+}
+
 func defineAzblobFlags(flags *pflag.FlagSet) {
 	flags.String(azblobEndpointOption, "", "(experimental) Set the Azblob endpoint URL")
 	flags.String(azblobAccessTierOption, "", "Specify the storage class for azblob")
 	flags.String(azblobAccountName, "", "Specify the account name for azblob")
 	flags.String(azblobAccountKey, "", "Specify the account key for azblob")
+	flags.String(azblobSASToken, "", "Specify the SAS token for azblob")
+	flags.Bool(azblobManagedIdentity, false, "Use the Azure managed identity to access azblob")
+	flags.String(azblobManagedClientID, "", "Specify the client ID of the user-assigned managed identity for azblob")
+	flags.Bool(azblobWorkloadIdentity, false, "Use the Azure AD workload identity to access azblob")
 }
 
 func hiddenAzblobFlags(flags *pflag.FlagSet) {
@@ -58,6 +117,10 @@ func hiddenAzblobFlags(flags *pflag.FlagSet) {
 	_ = flags.MarkHidden(azblobAccessTierOption)
 	_ = flags.MarkHidden(azblobAccountName)
 	_ = flags.MarkHidden(azblobAccountKey)
+	_ = flags.MarkHidden(azblobSASToken)
+	_ = flags.MarkHidden(azblobManagedIdentity)
+	_ = flags.MarkHidden(azblobManagedClientID)
+	_ = flags.MarkHidden(azblobWorkloadIdentity)
 }
 
 func (options *AzblobBackendOptions) parseFromFlags(flags *pflag.FlagSet) error {
@@ -81,6 +144,26 @@ func (options *AzblobBackendOptions) parseFromFlags(flags *pflag.FlagSet) error
 	if err != nil {
 		return errors.Trace(err)
 	}
+
+	options.SASToken, err = flags.GetString(azblobSASToken)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	options.UseManagedIdentity, err = flags.GetBool(azblobManagedIdentity)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	options.ManagedIdentityClientID, err = flags.GetString(azblobManagedClientID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	options.UseWorkloadIdentity, err = flags.GetBool(azblobWorkloadIdentity)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	return nil
 }
 
@@ -108,7 +191,7 @@ func (b *sharedKeyClientBuilder) GetAccountName() string {
 
 // use token to access azure blob storage
 type tokenClientBuilder struct {
-	cred        *azidentity.ClientSecretCredential
+	cred        azcore.TokenCredential
 	accountName string
 	serviceURL  string
 }
@@ -121,6 +204,118 @@ func (b *tokenClientBuilder) GetAccountName() string {
 	return b.accountName
 }
 
+// use SAS token to access azure blob storage
+type sasClientBuilder struct {
+	accountName string
+	// serviceURL contains the SAS token as its query
+	serviceURL string
+}
+
+func (b *sasClientBuilder) GetServiceClient() (azblob.ServiceClient, error) {
+	return azblob.NewServiceClientWithNoCredential(b.serviceURL, nil)
+}
+
+func (b *sasClientBuilder) GetAccountName() string {
+	return b.accountName
+}
+
+func defaultAzblobServiceURL(accountName string) string {
+	return fmt.Sprintf("https://%s.blob.core.windows.net", accountName)
+}
+
+func withAzureSASToken(serviceURL, sasToken string) string {
+	return serviceURL + "?" + strings.TrimPrefix(sasToken, "?")
+}
+
+// splitAzureSASToken splits the SAS token off the SAS URL of the service.
+func splitAzureSASToken(serviceURL string) (string, string) {
+	if i := strings.IndexByte(serviceURL, '?'); i >= 0 {
+		return serviceURL[:i], serviceURL[i+1:]
+	}
+	return serviceURL, ""
+}
+
+// workloadIdentityCredential exchanges the federated token projected by Azure
+// AD workload identity for the access token, see
+// https://azure.github.io/azure-workload-identity/docs/.
+type workloadIdentityCredential struct {
+	clientID      string
+	tenantID      string
+	tokenFile     string
+	authorityHost string
+	httpClient    *http.Client
+}
+
+func newWorkloadIdentityCredential(httpClient *http.Client) (*workloadIdentityCredential, error) {
+	clientID, tenantID, tokenFile := os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_FEDERATED_TOKEN_FILE")
+	if len(clientID) == 0 || len(tenantID) == 0 || len(tokenFile) == 0 {
+		return nil, errors.New("$AZURE_CLIENT_ID, $AZURE_TENANT_ID and $AZURE_FEDERATED_TOKEN_FILE are required to use azure workload identity")
+	}
+	authorityHost := os.Getenv("AZURE_AUTHORITY_HOST")
+	if len(authorityHost) == 0 {
+		authorityHost = string(azidentity.AzurePublicCloud)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &workloadIdentityCredential{
+		clientID:      clientID,
+		tenantID:      tenantID,
+		tokenFile:     tokenFile,
+		authorityHost: strings.TrimSuffix(authorityHost, "/"),
+		httpClient:    httpClient,
+	}, nil
+}
+
+// GetToken implements azcore.TokenCredential.
+func (c *workloadIdentityCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (*azcore.AccessToken, error) {
+	// the federated token is rotated by kubelet, so read it for every request.
+	assertion, err := os.ReadFile(c.tokenFile)
+	if err != nil {
+		return nil, errors.Annotate(err, "Failed to read the azure federated token")
+	}
+	tenantID := c.tenantID
+	if len(options.TenantID) > 0 {
+		tenantID = options.TenantID
+	}
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {c.clientID},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {strings.TrimSpace(string(assertion))},
+		"scope":                 {strings.Join(options.Scopes, " ")},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/%s/oauth2/v2.0/token", c.authorityHost, tenantID), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Annotate(err, "Failed to request the azure workload identity token")
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("Failed to get the azure workload identity token, status: %s, response: %s", resp.Status, body)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, errors.Annotate(err, "Failed to parse the azure workload identity token")
+	}
+	return &azcore.AccessToken{
+		Token:     token.AccessToken,
+		ExpiresOn: time.Now().Add(time.Duration(token.ExpiresIn) * time.Second),
+	}, nil
+}
+
 func getAuthorizerFromEnvironment() (clientID, tenantID, clientSecret string) {
 	return os.Getenv("AZURE_CLIENT_ID"),
 		os.Getenv("AZURE_TENANT_ID"),
@@ -129,38 +324,91 @@ func getAuthorizerFromEnvironment() (clientID, tenantID, clientSecret string) {
 
 // get azure service client from options and environment
 func getAzureServiceClientBuilder(options *backuppb.AzureBlobStorage, opts *ExternalStorageOptions) (ClientBuilder, error) {
+	builder, _, err := resolveAzureCredentials(options, opts)
+	return builder, err
+}
+
+// resolveAzureCredentials returns the client builder along with where its
+// credentials are resolved from.
+func resolveAzureCredentials(options *backuppb.AzureBlobStorage, opts *ExternalStorageOptions) (ClientBuilder, *ResolvedCredentials, error) {
 	if len(options.Bucket) == 0 {
-		return nil, errors.New("bucket(container) cannot be empty to access azure blob storage")
+		return nil, nil, errors.New("bucket(container) cannot be empty to access azure blob storage")
 	}
 
-	if len(options.AccountName) > 0 && len(options.SharedKey) > 0 {
-		serviceURL := options.Endpoint
-		if len(serviceURL) == 0 {
-			serviceURL = fmt.Sprintf("https://%s.blob.core.windows.net", options.AccountName)
+	var credOpts AzureCredentialOptions
+	if opts != nil && opts.Azure != nil {
+		credOpts = *opts.Azure
+	}
+	endpoint, sasToken := splitAzureSASToken(options.Endpoint)
+	if len(credOpts.SASToken) > 0 {
+		sasToken = credOpts.SASToken
+	}
+
+	accountName := options.AccountName
+	if len(accountName) == 0 {
+		accountName = os.Getenv("AZURE_STORAGE_ACCOUNT")
+	}
+	serviceURL := endpoint
+	if len(serviceURL) == 0 {
+		if len(accountName) == 0 {
+			return nil, nil, errors.New("account name cannot be empty to access azure blob storage")
 		}
+		serviceURL = defaultAzblobServiceURL(accountName)
+	}
+
+	if len(sasToken) > 0 {
+		// send the SAS token to TiKV within the endpoint
+		if len(credOpts.SASToken) > 0 && opts.SendCredentials {
+			options.Endpoint = withAzureSASToken(serviceURL, sasToken)
+		}
+		return &sasClientBuilder{
+			accountName,
+			withAzureSASToken(serviceURL, sasToken),
+		}, &ResolvedCredentials{Storage: "azblob", Source: CredentialsSourceConfig, Provider: azureSASTokenProvider}, nil
+	}
+
+	if credOpts.UseWorkloadIdentity {
+		cred, err := newWorkloadIdentityCredential(opts.HTTPClient)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		return &tokenClientBuilder{
+			cred,
+			accountName,
+			serviceURL,
+		}, &ResolvedCredentials{Storage: "azblob", Source: CredentialsSourceEnv, Provider: azureWorkloadIdentityProvider}, nil
+	}
+
+	if credOpts.UseManagedIdentity {
+		miOpts := &azidentity.ManagedIdentityCredentialOptions{}
+		if len(credOpts.ManagedIdentityClientID) > 0 {
+			miOpts.ID = azidentity.ClientID(credOpts.ManagedIdentityClientID)
+		}
+		cred, err := azidentity.NewManagedIdentityCredential(miOpts)
+		if err != nil {
+			return nil, nil, errors.Annotate(err, "Failed to get azure managed identity credential")
+		}
+		return &tokenClientBuilder{
+			cred,
+			accountName,
+			serviceURL,
+		}, &ResolvedCredentials{Storage: "azblob", Source: CredentialsSourceInstanceMetadata, Provider: azureManagedIdentityProvider}, nil
+	}
+
+	if len(options.AccountName) > 0 && len(options.SharedKey) > 0 {
 		cred, err := azblob.NewSharedKeyCredential(options.AccountName, options.SharedKey)
 		if err != nil {
-			return nil, errors.Annotate(err, "Failed to get azure sharedKey credential")
+			return nil, nil, errors.Annotate(err, "Failed to get azure sharedKey credential")
 		}
 		return &sharedKeyClientBuilder{
 			cred,
 			options.AccountName,
 			serviceURL,
-		}, nil
+		}, &ResolvedCredentials{Storage: "azblob", Source: CredentialsSourceConfig, Provider: azureSharedKeyProvider}, nil
 	}
 
-	accountName := options.AccountName
 	if len(accountName) == 0 {
-		val := os.Getenv("AZURE_STORAGE_ACCOUNT")
-		if len(val) <= 0 {
-			return nil, errors.New("account name cannot be empty to access azure blob storage")
-		}
-		accountName = val
-	}
-
-	serviceURL := options.Endpoint
-	if len(serviceURL) == 0 {
-		serviceURL = fmt.Sprintf("https://%s.blob.core.windows.net", accountName)
+		return nil, nil, errors.New("account name cannot be empty to access azure blob storage")
 	}
 
 	if clientID, tenantID, clientSecret := getAuthorizerFromEnvironment(); len(clientID) > 0 && len(tenantID) > 0 && len(clientSecret) > 0 {
@@ -174,7 +422,7 @@ func getAzureServiceClientBuilder(options *backuppb.AzureBlobStorage, opts *Exte
 				cred,
 				accountName,
 				serviceURL,
-			}, nil
+			}, &ResolvedCredentials{Storage: "azblob", Source: CredentialsSourceEnv, Provider: azureClientSecretProvider}, nil
 		}
 		log.Warn("Failed to get azure token credential but environment variables exist, try to use shared key.", zap.String("tenantId", tenantID), zap.String("clientId", clientID), zap.String("clientSecret", "?"))
 	}
@@ -182,14 +430,14 @@ func getAzureServiceClientBuilder(options *backuppb.AzureBlobStorage, opts *Exte
 	var sharedKey string
 	val := os.Getenv("AZURE_STORAGE_KEY")
 	if len(val) <= 0 {
-		return nil, errors.New("cannot find any credential info to access azure blob storage")
+		return nil, nil, errors.New("cannot find any credential info to access azure blob storage")
 	}
 	log.Info("Get azure sharedKey from environment variable $AZURE_STORAGE_KEY")
 	sharedKey = val
 
 	cred, err := azblob.NewSharedKeyCredential(accountName, sharedKey)
 	if err != nil {
-		return nil, errors.Annotate(err, "Failed to get azure sharedKey credential")
+		return nil, nil, errors.Annotate(err, "Failed to get azure sharedKey credential")
 	}
 	// if BR can only get credential info from environment variable `sharedKey`,
 	// BR will send it to TiKV so that there is no need to set environment variable for TiKV.
@@ -201,7 +449,7 @@ func getAzureServiceClientBuilder(options *backuppb.AzureBlobStorage, opts *Exte
 		cred,
 		accountName,
 		serviceURL,
-	}, nil
+	}, &ResolvedCredentials{Storage: "azblob", Source: CredentialsSourceEnv, Provider: azureSharedKeyProvider}, nil
 }
 
 // AzureBlobStorage is a storage engine that stores data in Azure Blob Storage.
@@ -332,6 +580,13 @@ func (s *AzureBlobStorage) Open(ctx context.Context, name string) (ExternalFileR
 
 // WalkDir implements the StorageReader interface.
 func (s *AzureBlobStorage) WalkDir(ctx context.Context, opt *WalkOption, fn func(path string, size int64) error) error {
+	return s.WalkDirWithModTime(ctx, opt, func(path string, size int64, _ time.Time) error {
+		return fn(path, size)
+	})
+}
+
+// WalkDirWithModTime implements ModTimeWalker.
+func (s *AzureBlobStorage) WalkDirWithModTime(ctx context.Context, opt *WalkOption, fn func(path string, size int64, modTime time.Time) error) error {
 	if opt == nil {
 		opt = &WalkOption{}
 	}
@@ -363,7 +618,11 @@ func (s *AzureBlobStorage) WalkDir(ctx context.Context, opt *WalkOption, fn func
 		}
 
 		for _, blob := range respIter.PageResponse().Segment.BlobItems {
-			if err := fn((*blob.Name)[prefixLength:], *blob.Properties.ContentLength); err != nil {
+			var modTime time.Time
+			if blob.Properties.LastModified != nil {
+				modTime = *blob.Properties.LastModified
+			}
+			if err := fn((*blob.Name)[prefixLength:], *blob.Properties.ContentLength, modTime); err != nil {
 				return errors.Trace(err)
 			}
 		}
@@ -5,10 +5,16 @@ package storage
 import (
 	"context"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	backuppb "github.com/pingcap/kvproto/pkg/brpb"
 	"github.com/stretchr/testify/require"
@@ -298,3 +304,102 @@ func TestNewAzblobStorage(t *testing.T) {
 		require.Equal(t, "http://127.0.0.1:1000", b.serviceURL)
 	}
 }
+
+func TestNewAzblobStorageWithoutAccountKey(t *testing.T) {
+	t.Setenv("AZURE_STORAGE_ACCOUNT", "")
+	t.Setenv("AZURE_STORAGE_KEY", "")
+	t.Setenv("AZURE_CLIENT_SECRET", "")
+
+	// the SAS token is carried by the endpoint
+	backend, err := ParseBackend("azure://test/a/b?account-name=user&sas-token="+url.QueryEscape("sv=2020-08-04&sig=abc"), nil)
+	require.NoError(t, err)
+	options := backend.GetAzureBlobStorage()
+	require.Equal(t, "https://user.blob.core.windows.net?sv=2020-08-04&sig=abc", options.Endpoint)
+	builder, err := getAzureServiceClientBuilder(options, &ExternalStorageOptions{})
+	require.NoError(t, err)
+	sas, ok := builder.(*sasClientBuilder)
+	require.True(t, ok)
+	require.Equal(t, "user", sas.GetAccountName())
+	require.Equal(t, "https://user.blob.core.windows.net?sv=2020-08-04&sig=abc", sas.serviceURL)
+	_, err = ParseBackend("azure://test/a/b?sas-token=sig%3Dabc", nil)
+	require.Error(t, err)
+
+	// the SAS token of the options overrides the one of the endpoint
+	options = &backuppb.AzureBlobStorage{
+		Endpoint: "http://127.0.0.1:1000?sig=abc",
+		Bucket:   "test",
+	}
+	builder, err = getAzureServiceClientBuilder(options, &ExternalStorageOptions{
+		SendCredentials: true,
+		Azure:           &AzureCredentialOptions{SASToken: "?sig=def"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "http://127.0.0.1:1000?sig=def", builder.(*sasClientBuilder).serviceURL)
+	require.Equal(t, "http://127.0.0.1:1000?sig=def", options.Endpoint)
+
+	backend, err = ParseBackend("azure://test/a/b?account-name=user&use-managed-identity=true&managed-identity-client-id=123", nil)
+	require.NoError(t, err)
+	require.Empty(t, backend.GetAzureBlobStorage().Endpoint)
+	backendOpts := &BackendOptions{}
+	require.Nil(t, backendOpts.Azblob.CredentialOptions())
+	_, err = ParseBackend("azure://test/a/b?account-name=user&use-managed-identity=true&managed-identity-client-id=123", backendOpts)
+	require.NoError(t, err)
+	require.Equal(t, &AzureCredentialOptions{UseManagedIdentity: true, ManagedIdentityClientID: "123"}, backendOpts.Azblob.CredentialOptions())
+
+	options = &backuppb.AzureBlobStorage{
+		Bucket:      "test",
+		AccountName: "user",
+		SharedKey:   "cGFzc3dk",
+	}
+	t.Setenv("AZURE_CLIENT_ID", "")
+	_, err = getAzureServiceClientBuilder(options, &ExternalStorageOptions{
+		Azure: &AzureCredentialOptions{UseWorkloadIdentity: true},
+	})
+	require.Error(t, err)
+	t.Setenv("AZURE_CLIENT_ID", "321")
+	t.Setenv("AZURE_TENANT_ID", "tenant")
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", "/var/run/secrets/token")
+	builder, err = getAzureServiceClientBuilder(options, &ExternalStorageOptions{
+		Azure: &AzureCredentialOptions{UseWorkloadIdentity: true},
+	})
+	require.NoError(t, err)
+	b, ok := builder.(*tokenClientBuilder)
+	require.True(t, ok)
+	require.Equal(t, "user", b.GetAccountName())
+	require.Equal(t, "https://user.blob.core.windows.net", b.serviceURL)
+	_, ok = b.cred.(*workloadIdentityCredential)
+	require.True(t, ok)
+}
+
+func TestWorkloadIdentityCredential(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("federated-token\n"), 0o600))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tenant/oauth2/v2.0/token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		require.Equal(t, "321", r.PostForm.Get("client_id"))
+		require.Equal(t, "federated-token", r.PostForm.Get("client_assertion"))
+		require.Equal(t, "https://storage.azure.com/.default", r.PostForm.Get("scope"))
+		_, _ = w.Write([]byte(`{"token_type":"Bearer","access_token":"access-token","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("AZURE_CLIENT_ID", "321")
+	t.Setenv("AZURE_TENANT_ID", "tenant")
+	t.Setenv("AZURE_FEDERATED_TOKEN_FILE", tokenFile)
+	t.Setenv("AZURE_AUTHORITY_HOST", server.URL+"/")
+	cred, err := newWorkloadIdentityCredential(nil)
+	require.NoError(t, err)
+	token, err := cred.GetToken(context.Background(), policy.TokenRequestOptions{Scopes: []string{"https://storage.azure.com/.default"}})
+	require.NoError(t, err)
+	require.Equal(t, "access-token", token.Token)
+	require.True(t, token.ExpiresOn.After(time.Now().Add(time.Hour-time.Minute)))
+
+	_, err = cred.GetToken(context.Background(), policy.TokenRequestOptions{TenantID: "other"})
+	require.Error(t, err)
+}
@@ -0,0 +1,200 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go/aws/credentials/endpointcreds"
+	"github.com/aws/aws-sdk-go/aws/credentials/processcreds"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pingcap/errors"
+	backuppb "github.com/pingcap/kvproto/pkg/brpb"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+)
+
+// CredentialsSource is where the credentials of an external storage are resolved
+// from. The sources are tried in the order of config, env, shared-file,
+// credential-process and imds, and the first one having the credentials is used
+// by all of BR, Dumpling and Lightning.
+type CredentialsSource string
+
+const (
+	// CredentialsSourceNone means no credentials are needed, e.g. the local
+	// storage, or the credentials are disabled by `--no-credentials`.
+	CredentialsSourceNone CredentialsSource = "none"
+	// CredentialsSourceConfig means the credentials are given in the storage URL,
+	// the command line flags or the config file.
+	CredentialsSourceConfig CredentialsSource = "config"
+	// CredentialsSourceEnv means the credentials are given in the environment
+	// variables, e.g. `AWS_ACCESS_KEY_ID` or `GOOGLE_APPLICATION_CREDENTIALS`.
+	CredentialsSourceEnv CredentialsSource = "env"
+	// CredentialsSourceSharedFile means the credentials are read from the shared
+	// files of the cloud SDKs, e.g. `~/.aws/credentials` or the application
+	// default credentials of gcloud.
+	CredentialsSourceSharedFile CredentialsSource = "shared-file"
+	// CredentialsSourceProcess means the credentials are returned by an external
+	// process configured in the shared files.
+	CredentialsSourceProcess CredentialsSource = "credential-process"
+	// CredentialsSourceInstanceMetadata means the credentials are fetched from the
+	// instance metadata service of the cloud, including the workload identity of
+	// Kubernetes.
+	CredentialsSourceInstanceMetadata CredentialsSource = "imds"
+	// CredentialsSourceUnknown means the credentials are resolved by the cloud SDK
+	// from a source not listed above.
+	CredentialsSourceUnknown CredentialsSource = "unknown"
+)
+
+const (
+	gcsCredentialsEnv   = "GOOGLE_APPLICATION_CREDENTIALS"
+	gcsMetadataProvider = "metadata server"
+
+	azureSharedKeyProvider        = "shared key"
+	azureClientSecretProvider     = "client secret"
+	azureSASTokenProvider         = "SAS token"
+	azureManagedIdentityProvider  = "managed identity"
+	azureWorkloadIdentityProvider = "workload identity"
+)
+
+// ResolvedCredentials describes where the credentials of an external storage are
+// resolved from.
+type ResolvedCredentials struct {
+	// Storage is the type of the storage, e.g. "s3".
+	Storage string
+	Source  CredentialsSource
+	// Provider is the provider of the credentials in the cloud SDK, which tells
+	// more details of the source.
+	Provider string
+}
+
+// String implements fmt.Stringer.
+func (r *ResolvedCredentials) String() string {
+	if len(r.Provider) == 0 {
+		return fmt.Sprintf("storage: %s, credentials source: %s", r.Storage, r.Source)
+	}
+	return fmt.Sprintf("storage: %s, credentials source: %s, provider: %s", r.Storage, r.Source, r.Provider)
+}
+
+// ResolveCredentials returns where the credentials of the storage are resolved
+// from, without accessing the storage. The credentials may be fetched from the
+// instance metadata service or an external process to find out the source.
+func ResolveCredentials(ctx context.Context, backend *backuppb.StorageBackend, opts *ExternalStorageOptions) (*ResolvedCredentials, error) {
+	if opts == nil {
+		opts = &ExternalStorageOptions{}
+	}
+	switch backend := backend.Backend.(type) {
+	case *backuppb.StorageBackend_S3:
+		if backend.S3 == nil {
+			return nil, errors.Annotate(berrors.ErrStorageInvalidConfig, "s3 config not found")
+		}
+		return resolveS3Credentials(ctx, backend.S3, opts)
+	case *backuppb.StorageBackend_Gcs:
+		if backend.Gcs == nil {
+			return nil, errors.Annotate(berrors.ErrStorageInvalidConfig, "GCS config not found")
+		}
+		_, resolved, err := resolveGCSCredentials(ctx, backend.Gcs, opts)
+		return resolved, errors.Trace(err)
+	case *backuppb.StorageBackend_AzureBlobStorage:
+		if backend.AzureBlobStorage == nil {
+			return nil, errors.Annotate(berrors.ErrStorageInvalidConfig, "azure blob storage config not found")
+		}
+		// the options are filled in when the credentials are sent to TiKV.
+		options := *backend.AzureBlobStorage
+		_, resolved, err := resolveAzureCredentials(&options, opts)
+		return resolved, errors.Trace(err)
+	case *backuppb.StorageBackend_Local:
+		return &ResolvedCredentials{Storage: "local", Source: CredentialsSourceNone}, nil
+	case *backuppb.StorageBackend_Hdfs:
+		return &ResolvedCredentials{Storage: "hdfs", Source: CredentialsSourceNone}, nil
+	case *backuppb.StorageBackend_Noop:
+		return &ResolvedCredentials{Storage: "noop", Source: CredentialsSourceNone}, nil
+	default:
+		return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "storage %T is not supported yet", backend)
+	}
+}
+
+// PrintResolvedCredentialsSource prints where the credentials of the storage are
+// resolved from, which is used by `--print-resolved-credentials-source`.
+func PrintResolvedCredentialsSource(ctx context.Context, w io.Writer, backend *backuppb.StorageBackend, opts *ExternalStorageOptions) error {
+	resolved, err := ResolveCredentials(ctx, backend, opts)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = fmt.Fprintln(w, resolved)
+	return errors.Trace(err)
+}
+
+func resolveS3Credentials(ctx context.Context, backend *backuppb.S3, opts *ExternalStorageOptions) (*ResolvedCredentials, error) {
+	qs := *backend
+	ses, source, err := newS3Session(&qs, opts)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	v, err := ses.Config.Credentials.GetWithContext(ctx)
+	if err != nil {
+		return nil, errors.Annotate(err, "failed to resolve the credentials of s3")
+	}
+	if len(source) == 0 {
+		source = s3CredentialsSource(v.ProviderName)
+	}
+	resolved := &ResolvedCredentials{Storage: "s3", Source: source, Provider: v.ProviderName}
+	if len(qs.RoleArn) > 0 {
+		resolved.Provider += ", assuming role " + qs.RoleArn
+	}
+	return resolved, nil
+}
+
+// s3CredentialsSource maps the provider resolved by the AWS SDK to the source.
+func s3CredentialsSource(providerName string) CredentialsSource {
+	switch {
+	case providerName == session.EnvProviderName || providerName == credentials.EnvProviderName:
+		return CredentialsSourceEnv
+	case strings.HasPrefix(providerName, "SharedConfigCredentials"),
+		providerName == credentials.SharedCredsProviderName,
+		providerName == stscreds.ProviderName:
+		return CredentialsSourceSharedFile
+	case providerName == processcreds.ProviderName:
+		return CredentialsSourceProcess
+	case providerName == stscreds.WebIdentityProviderName,
+		providerName == ec2rolecreds.ProviderName,
+		providerName == endpointcreds.ProviderName:
+		return CredentialsSourceInstanceMetadata
+	case providerName == credentials.StaticProviderName:
+		return CredentialsSourceConfig
+	default:
+		return CredentialsSourceUnknown
+	}
+}
+
+type gcsCredentialsJSON struct {
+	Type             string `json:"type"`
+	CredentialSource struct {
+		Executable json.RawMessage `json:"executable"`
+	} `json:"credential_source"`
+}
+
+// gcsCredentialsType returns the type of the GCS credentials file, e.g.
+// "service_account".
+func gcsCredentialsType(content []byte) string {
+	var f gcsCredentialsJSON
+	_ = json.Unmarshal(content, &f)
+	return f.Type
+}
+
+// gcsCredentialsSource returns the source of the GCS credentials file found in
+// the given source. The external account credentials may be returned by an
+// executable.
+func gcsCredentialsSource(fileSource CredentialsSource, content []byte) CredentialsSource {
+	var f gcsCredentialsJSON
+	if err := json.Unmarshal(content, &f); err == nil && len(f.CredentialSource.Executable) > 0 {
+		return CredentialsSourceProcess
+	}
+	return fileSource
+}
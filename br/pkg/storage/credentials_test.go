@@ -0,0 +1,159 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	backuppb "github.com/pingcap/kvproto/pkg/brpb"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAWSEnv isolates the test from the AWS credentials of the environment.
+func setupAWSEnv(t *testing.T, config, credentials string) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	credentialsPath := filepath.Join(dir, "credentials")
+	require.NoError(t, os.WriteFile(configPath, []byte(config), 0o600))
+	require.NoError(t, os.WriteFile(credentialsPath, []byte(credentials), 0o600))
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsPath)
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_WEB_IDENTITY_TOKEN_FILE"} {
+		// t.Setenv restores the variables after the test.
+		t.Setenv(key, "")
+		require.NoError(t, os.Unsetenv(key))
+	}
+}
+
+func TestResolveS3Credentials(t *testing.T) {
+	ctx := context.Background()
+	backend := &backuppb.StorageBackend{Backend: &backuppb.StorageBackend_S3{S3: &backuppb.S3{Bucket: "bucket"}}}
+
+	process := filepath.Join(t.TempDir(), "credential-process.sh")
+	require.NoError(t, os.WriteFile(process, []byte(`#!/bin/sh
+echo '{"Version":1,"AccessKeyId":"process-ak","SecretAccessKey":"process-sk"}'
+`), 0o700))
+	processConfig := "[default]\ncredential_process = " + process + "\n"
+
+	// the credential process in the shared config is used.
+	setupAWSEnv(t, processConfig, "")
+	resolved, err := ResolveCredentials(ctx, backend, nil)
+	require.NoError(t, err)
+	require.Equal(t, CredentialsSourceProcess, resolved.Source)
+
+	// the shared credentials file takes precedence over the credential process.
+	setupAWSEnv(t, processConfig, `[default]
+aws_access_key_id = file-ak
+aws_secret_access_key = file-sk
+`)
+	resolved, err = ResolveCredentials(ctx, backend, nil)
+	require.NoError(t, err)
+	require.Equal(t, CredentialsSourceSharedFile, resolved.Source)
+
+	// the environment variables take precedence over the shared files.
+	t.Setenv("AWS_ACCESS_KEY_ID", "env-ak")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-sk")
+	resolved, err = ResolveCredentials(ctx, backend, nil)
+	require.NoError(t, err)
+	require.Equal(t, CredentialsSourceEnv, resolved.Source)
+
+	// the config takes precedence over all of them.
+	backend.GetS3().AccessKey = "config-ak"
+	backend.GetS3().SecretAccessKey = "config-sk"
+	backend.GetS3().RoleArn = "arn:aws:iam::123456789012:role/backup"
+	resolved, err = ResolveCredentials(ctx, backend, nil)
+	require.NoError(t, err)
+	require.Equal(t, &ResolvedCredentials{
+		Storage:  "s3",
+		Source:   CredentialsSourceConfig,
+		Provider: "StaticProvider, assuming role arn:aws:iam::123456789012:role/backup",
+	}, resolved)
+	// the backend isn't changed.
+	require.Equal(t, "config-ak", backend.GetS3().AccessKey)
+
+	// no credentials at all.
+	setupAWSEnv(t, "", "")
+	_, err = ResolveCredentials(ctx, &backuppb.StorageBackend{Backend: &backuppb.StorageBackend_S3{S3: &backuppb.S3{Bucket: "bucket"}}}, nil)
+	require.ErrorContains(t, err, "failed to resolve the credentials of s3")
+}
+
+func TestS3CredentialsSource(t *testing.T) {
+	for provider, source := range map[string]CredentialsSource{
+		"EnvConfigCredentials":                            CredentialsSourceEnv,
+		"SharedConfigCredentials: /root/.aws/credentials": CredentialsSourceSharedFile,
+		"AssumeRoleProvider":                              CredentialsSourceSharedFile,
+		"ProcessProvider":                                 CredentialsSourceProcess,
+		"WebIdentityCredentials":                          CredentialsSourceInstanceMetadata,
+		"EC2RoleProvider":                                 CredentialsSourceInstanceMetadata,
+		"CredentialsEndpointProvider":                     CredentialsSourceInstanceMetadata,
+		"SomeProvider":                                    CredentialsSourceUnknown,
+	} {
+		require.Equal(t, source, s3CredentialsSource(provider), provider)
+	}
+}
+
+func TestResolveGCSCredentials(t *testing.T) {
+	ctx := context.Background()
+	userCredentials := `{"type":"authorized_user","client_id":"id","client_secret":"secret","refresh_token":"token"}`
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	require.NoError(t, os.WriteFile(path, []byte(userCredentials), 0o600))
+	t.Setenv(gcsCredentialsEnv, path)
+
+	backend := &backuppb.StorageBackend{Backend: &backuppb.StorageBackend_Gcs{Gcs: &backuppb.GCS{Bucket: "bucket"}}}
+	resolved, err := ResolveCredentials(ctx, backend, nil)
+	require.NoError(t, err)
+	require.Equal(t, &ResolvedCredentials{Storage: "gcs", Source: CredentialsSourceEnv, Provider: "authorized_user"}, resolved)
+
+	backend.GetGcs().CredentialsBlob = `{"type":"external_account","credential_source":{"executable":{"command":"/bin/token"}}}`
+	resolved, err = ResolveCredentials(ctx, backend, nil)
+	require.NoError(t, err)
+	require.Equal(t, &ResolvedCredentials{Storage: "gcs", Source: CredentialsSourceProcess, Provider: "external_account"}, resolved)
+
+	backend.GetGcs().CredentialsBlob = userCredentials
+	resolved, err = ResolveCredentials(ctx, backend, nil)
+	require.NoError(t, err)
+	require.Equal(t, CredentialsSourceConfig, resolved.Source)
+
+	resolved, err = ResolveCredentials(ctx, backend, &ExternalStorageOptions{NoCredentials: true})
+	require.NoError(t, err)
+	require.Equal(t, CredentialsSourceNone, resolved.Source)
+}
+
+func TestResolveAzureCredentials(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("AZURE_CLIENT_ID", "")
+	t.Setenv("AZURE_STORAGE_KEY", "cGFzc3dk")
+
+	backend := &backuppb.StorageBackend{Backend: &backuppb.StorageBackend_AzureBlobStorage{
+		AzureBlobStorage: &backuppb.AzureBlobStorage{Bucket: "test", AccountName: "user"},
+	}}
+	resolved, err := ResolveCredentials(ctx, backend, &ExternalStorageOptions{SendCredentials: true})
+	require.NoError(t, err)
+	require.Equal(t, &ResolvedCredentials{Storage: "azblob", Source: CredentialsSourceEnv, Provider: azureSharedKeyProvider}, resolved)
+	// the shared key isn't filled in to the backend.
+	require.Empty(t, backend.GetAzureBlobStorage().SharedKey)
+
+	backend.GetAzureBlobStorage().SharedKey = "cGFzc3dk"
+	resolved, err = ResolveCredentials(ctx, backend, nil)
+	require.NoError(t, err)
+	require.Equal(t, CredentialsSourceConfig, resolved.Source)
+
+	resolved, err = ResolveCredentials(ctx, backend, &ExternalStorageOptions{
+		Azure: &AzureCredentialOptions{SASToken: "sig=abc"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &ResolvedCredentials{Storage: "azblob", Source: CredentialsSourceConfig, Provider: azureSASTokenProvider}, resolved)
+}
+
+func TestPrintResolvedCredentialsSource(t *testing.T) {
+	backend, err := ParseBackend(t.TempDir(), nil)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, PrintResolvedCredentialsSource(context.Background(), &buf, backend, nil))
+	require.Equal(t, "storage: local, credentials source: none\n", buf.String())
+}
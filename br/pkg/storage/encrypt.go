@@ -0,0 +1,379 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"github.com/pingcap/errors"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+)
+
+// The layout of an encrypted object is
+//
+//	magic | uint16 length of key ID | key ID | uint16 length of wrapped key | wrapped key | segments
+//
+// The content is split into segments of encryptSegmentSize bytes, each segment is sealed
+// by AES-256-GCM with the data key of the object. The nonce of a segment is its index and
+// whether it's the last segment, and the header is used as the additional data, so the
+// segments can't be reordered, truncated or moved to another object.
+const (
+	encryptMagic       = "BRE\x01"
+	encryptSegmentSize = 64 * 1024
+	encryptDataKeyLen  = 32
+	encryptNonceLen    = 12
+	encryptTagLen      = 16
+)
+
+// KeyRing wraps the data keys of the encrypted objects by the master keys.
+type KeyRing interface {
+	// WrapKey encrypts the data key by the current master key, and returns the ID of
+	// the master key and the wrapped key.
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrappedKey []byte, err error)
+	// UnwrapKey decrypts the wrapped data key by the master key of the ID.
+	UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error)
+}
+
+type withEncryption struct {
+	ExternalStorage
+	keyRing KeyRing
+}
+
+// WithEncryption returns an ExternalStorage which encrypts every object written to the
+// inner storage by a random data key, and stores the data key wrapped by the key ring
+// along with the object. The sizes reported by WalkDir are the sizes of the encrypted
+// objects.
+func WithEncryption(inner ExternalStorage, keyRing KeyRing) ExternalStorage {
+	if keyRing == nil {
+		return inner
+	}
+	return &withEncryption{ExternalStorage: inner, keyRing: keyRing}
+}
+
+// newObjectCipher generates a data key for a new object, and returns the AEAD of the
+// data key and the header of the object.
+func (w *withEncryption) newObjectCipher(ctx context.Context) (cipher.AEAD, []byte, error) {
+	dataKey := make([]byte, encryptDataKeyLen)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	keyID, wrappedKey, err := w.keyRing.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "failed to wrap the data key")
+	}
+	aead, err := newAESGCM(dataKey)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	header := make([]byte, 0, len(encryptMagic)+4+len(keyID)+len(wrappedKey))
+	header = append(header, encryptMagic...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(keyID)))
+	header = append(header, keyID...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrappedKey)))
+	header = append(header, wrappedKey...)
+	return aead, header, nil
+}
+
+// readObjectCipher reads the header of an encrypted object, and returns the AEAD of the
+// data key and the header.
+func (w *withEncryption) readObjectCipher(ctx context.Context, name string, r io.Reader) (cipher.AEAD, []byte, error) {
+	header := make([]byte, len(encryptMagic)+2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, nil, errors.Annotatef(berrors.ErrStorageUnknown, "failed to read the encryption header of '%s': %v", name, err)
+	}
+	if string(header[:len(encryptMagic)]) != encryptMagic {
+		return nil, nil, errors.Annotatef(berrors.ErrStorageUnknown, "'%s' is not encrypted", name)
+	}
+	readField := func() ([]byte, error) {
+		fieldLen := int(binary.BigEndian.Uint16(header[len(header)-2:]))
+		header = append(header, make([]byte, fieldLen)...)
+		if _, err := io.ReadFull(r, header[len(header)-fieldLen:]); err != nil {
+			return nil, errors.Annotatef(berrors.ErrStorageUnknown, "failed to read the encryption header of '%s': %v", name, err)
+		}
+		return header[len(header)-fieldLen:], nil
+	}
+	keyID, err := readField()
+	if err != nil {
+		return nil, nil, err
+	}
+	keyID = append([]byte{}, keyID...)
+	header = append(header, 0, 0)
+	if _, err := io.ReadFull(r, header[len(header)-2:]); err != nil {
+		return nil, nil, errors.Annotatef(berrors.ErrStorageUnknown, "failed to read the encryption header of '%s': %v", name, err)
+	}
+	wrappedKey, err := readField()
+	if err != nil {
+		return nil, nil, err
+	}
+	dataKey, err := w.keyRing.UnwrapKey(ctx, string(keyID), wrappedKey)
+	if err != nil {
+		return nil, nil, errors.Annotatef(err, "failed to unwrap the data key of '%s'", name)
+	}
+	aead, err := newAESGCM(dataKey)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return aead, header, nil
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	return aead, errors.Trace(err)
+}
+
+func segmentNonce(index int64, last bool) []byte {
+	nonce := make([]byte, encryptNonceLen)
+	binary.BigEndian.PutUint64(nonce, uint64(index))
+	if last {
+		nonce[encryptNonceLen-1] = 1
+	}
+	return nonce
+}
+
+func (w *withEncryption) Create(ctx context.Context, name string) (ExternalFileWriter, error) {
+	aead, header, err := w.newObjectCipher(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	writer, err := w.ExternalStorage.Create(ctx, name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &encryptedWriter{inner: writer, aead: aead, header: header}, nil
+}
+
+func (w *withEncryption) WriteFile(ctx context.Context, name string, data []byte) error {
+	aead, header, err := w.newObjectCipher(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	buf := &bufferWriter{}
+	buf.Grow(len(header) + len(data) + (len(data)/encryptSegmentSize+1)*encryptTagLen)
+	writer := &encryptedWriter{inner: buf, aead: aead, header: header}
+	if _, err := writer.Write(ctx, data); err != nil {
+		return errors.Trace(err)
+	}
+	if err := writer.Close(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	return w.ExternalStorage.WriteFile(ctx, name, buf.Bytes())
+}
+
+func (w *withEncryption) Open(ctx context.Context, path string) (ExternalFileReader, error) {
+	fileReader, err := w.ExternalStorage.Open(ctx, path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	reader, err := w.newEncryptedReader(ctx, path, fileReader)
+	if err != nil {
+		_ = fileReader.Close()
+		return nil, errors.Trace(err)
+	}
+	return reader, nil
+}
+
+func (w *withEncryption) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	data, err := w.ExternalStorage.ReadFile(ctx, name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	reader, err := w.newEncryptedReader(ctx, name, &memFileReader{br: bytes.NewReader(data)})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return io.ReadAll(reader)
+}
+
+// bufferWriter is an in-memory ExternalFileWriter.
+type bufferWriter struct {
+	bytes.Buffer
+}
+
+func (b *bufferWriter) Write(_ context.Context, p []byte) (int, error) {
+	return b.Buffer.Write(p)
+}
+
+func (*bufferWriter) Close(context.Context) error {
+	return nil
+}
+
+type encryptedWriter struct {
+	inner  ExternalFileWriter
+	aead   cipher.AEAD
+	header []byte
+	// index is the index of the next segment.
+	index         int64
+	headerWritten bool
+	// buf is the plaintext of the next segment.
+	buf []byte
+	out []byte
+}
+
+func (w *encryptedWriter) writeSegment(ctx context.Context, last bool) error {
+	if !w.headerWritten {
+		if _, err := w.inner.Write(ctx, w.header); err != nil {
+			return errors.Trace(err)
+		}
+		w.headerWritten = true
+	}
+	w.out = w.aead.Seal(w.out[:0], segmentNonce(w.index, last), w.buf, w.header)
+	if _, err := w.inner.Write(ctx, w.out); err != nil {
+		return errors.Trace(err)
+	}
+	w.index++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+func (w *encryptedWriter) Write(ctx context.Context, p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		// a full segment is sealed only if there is more data, since the last segment
+		// must be sealed as the last one in Close.
+		if len(w.buf) == encryptSegmentSize {
+			if err := w.writeSegment(ctx, false); err != nil {
+				return 0, errors.Trace(err)
+			}
+		}
+		if w.buf == nil {
+			w.buf = make([]byte, 0, encryptSegmentSize)
+		}
+		size := encryptSegmentSize - len(w.buf)
+		if size > len(p) {
+			size = len(p)
+		}
+		w.buf = append(w.buf, p[:size]...)
+		p = p[size:]
+	}
+	return n, nil
+}
+
+func (w *encryptedWriter) Close(ctx context.Context) error {
+	if err := w.writeSegment(ctx, true); err != nil {
+		return errors.Trace(err)
+	}
+	return w.inner.Close(ctx)
+}
+
+type encryptedReader struct {
+	inner  ExternalFileReader
+	name   string
+	aead   cipher.AEAD
+	header []byte
+	// bodySize is the size of the segments, and segCount is the number of segments.
+	bodySize int64
+	segCount int64
+	// size is the size of the plaintext, and pos is the position to read.
+	size int64
+	pos  int64
+	// segIndex is the index of the segment decrypted in buf, or -1 if there is none.
+	segIndex int64
+	buf      []byte
+	sealed   []byte
+}
+
+func (w *withEncryption) newEncryptedReader(ctx context.Context, name string, fileReader ExternalFileReader) (*encryptedReader, error) {
+	aead, header, err := w.readObjectCipher(ctx, name, fileReader)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	fileSize, err := fileReader.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	bodySize := fileSize - int64(len(header))
+	if bodySize < encryptTagLen {
+		return nil, errors.Annotatef(berrors.ErrStorageUnknown, "the encrypted object '%s' is truncated", name)
+	}
+	sealedSegmentSize := int64(encryptSegmentSize + encryptTagLen)
+	segCount := (bodySize + sealedSegmentSize - 1) / sealedSegmentSize
+	lastSize := bodySize - (segCount-1)*sealedSegmentSize
+	if lastSize < encryptTagLen {
+		return nil, errors.Annotatef(berrors.ErrStorageUnknown, "the encrypted object '%s' is truncated", name)
+	}
+	return &encryptedReader{
+		inner:    fileReader,
+		name:     name,
+		aead:     aead,
+		header:   header,
+		bodySize: bodySize,
+		segCount: segCount,
+		size:     bodySize - segCount*encryptTagLen,
+		segIndex: -1,
+	}, nil
+}
+
+func (r *encryptedReader) loadSegment(index int64) error {
+	sealedSegmentSize := int64(encryptSegmentSize + encryptTagLen)
+	offset := index * sealedSegmentSize
+	size := sealedSegmentSize
+	if offset+size > r.bodySize {
+		size = r.bodySize - offset
+	}
+	if _, err := r.inner.Seek(int64(len(r.header))+offset, io.SeekStart); err != nil {
+		return errors.Trace(err)
+	}
+	if int64(cap(r.sealed)) < size {
+		r.sealed = make([]byte, sealedSegmentSize)
+	}
+	r.sealed = r.sealed[:size]
+	if _, err := io.ReadFull(r.inner, r.sealed); err != nil {
+		return errors.Trace(err)
+	}
+	buf, err := r.aead.Open(r.buf[:0], segmentNonce(index, index == r.segCount-1), r.sealed, r.header)
+	if err != nil {
+		r.segIndex = -1
+		return errors.Annotatef(berrors.ErrStorageUnknown, "failed to decrypt '%s', the object may be corrupted: %v", r.name, err)
+	}
+	r.buf = buf
+	r.segIndex = index
+	return nil
+}
+
+func (r *encryptedReader) Read(p []byte) (int, error) {
+	if r.pos >= r.size {
+		return 0, io.EOF
+	}
+	index := r.pos / encryptSegmentSize
+	if index != r.segIndex {
+		if err := r.loadSegment(index); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf[r.pos-index*encryptSegmentSize:])
+	r.pos += int64(n)
+	return n, nil
+}
+
+func (r *encryptedReader) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = r.pos + offset
+	case io.SeekEnd:
+		pos = r.size + offset
+	default:
+		return 0, errors.Annotatef(berrors.ErrStorageUnknown, "Seek: invalid whence '%d'", whence)
+	}
+	if pos < 0 {
+		return 0, errors.Annotatef(berrors.ErrStorageUnknown, "Seek in '%s': invalid offset to seek '%d'.", r.name, pos)
+	}
+	r.pos = pos
+	return pos, nil
+}
+
+func (r *encryptedReader) Close() error {
+	return r.inner.Close()
+}
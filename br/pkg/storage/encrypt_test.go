@@ -0,0 +1,209 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyRing(t *testing.T, keys ...string) *fileKeyRing {
+	var content bytes.Buffer
+	for _, id := range keys {
+		key := make([]byte, encryptDataKeyLen)
+		copy(key, id)
+		content.WriteString(id + " " + hex.EncodeToString(key) + "\n")
+	}
+	keyRing, err := parseFileKeyRing(content.Bytes())
+	require.NoError(t, err)
+	return keyRing
+}
+
+func TestEncryptedReadWrite(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemStorage()
+	s := WithEncryption(inner, newTestKeyRing(t, "k1"))
+
+	for _, size := range []int{0, 1, encryptSegmentSize - 1, encryptSegmentSize, encryptSegmentSize + 1, 3*encryptSegmentSize + 5} {
+		content := make([]byte, size)
+		rand.Read(content)
+
+		require.NoError(t, s.WriteFile(ctx, "/a", content))
+		raw, err := inner.ReadFile(ctx, "/a")
+		require.NoError(t, err)
+		if size > 10 {
+			require.False(t, bytes.Contains(raw, content))
+		}
+		data, err := s.ReadFile(ctx, "/a")
+		require.NoError(t, err)
+		require.Equal(t, content, data)
+
+		// write in small pieces.
+		w, err := s.Create(ctx, "/b")
+		require.NoError(t, err)
+		for p := content; len(p) > 0; {
+			n := rand.Intn(1000) + 1
+			if n > len(p) {
+				n = len(p)
+			}
+			_, err = w.Write(ctx, p[:n])
+			require.NoError(t, err)
+			p = p[n:]
+		}
+		require.NoError(t, w.Close(ctx))
+		data, err = s.ReadFile(ctx, "/b")
+		require.NoError(t, err)
+		require.Equal(t, content, data)
+
+		r, err := s.Open(ctx, "/b")
+		require.NoError(t, err)
+		data, err = io.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, content, data)
+		if size > 10 {
+			pos, err := r.Seek(-10, io.SeekEnd)
+			require.NoError(t, err)
+			require.Equal(t, int64(size-10), pos)
+			data, err = io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, content[size-10:], data)
+
+			_, err = r.Seek(int64(size/2), io.SeekStart)
+			require.NoError(t, err)
+			data = make([]byte, 5)
+			_, err = io.ReadFull(r, data)
+			require.NoError(t, err)
+			require.Equal(t, content[size/2:size/2+5], data)
+		}
+		require.NoError(t, r.Close())
+		require.NoError(t, s.DeleteFile(ctx, "/b"))
+	}
+}
+
+func TestEncryptedTampered(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemStorage()
+	s := WithEncryption(inner, newTestKeyRing(t, "k1"))
+
+	content := make([]byte, 2*encryptSegmentSize+100)
+	rand.Read(content)
+	require.NoError(t, s.WriteFile(ctx, "/a", content))
+	raw, err := inner.ReadFile(ctx, "/a")
+	require.NoError(t, err)
+
+	// flip a bit.
+	tampered := append([]byte{}, raw...)
+	tampered[len(tampered)-100] ^= 1
+	require.NoError(t, inner.WriteFile(ctx, "/b", tampered))
+	_, err = s.ReadFile(ctx, "/b")
+	require.ErrorContains(t, err, "failed to decrypt")
+
+	// truncate at the segment boundary.
+	sealedSegmentSize := encryptSegmentSize + encryptTagLen
+	require.NoError(t, inner.WriteFile(ctx, "/c", raw[:len(raw)-100-encryptTagLen]))
+	_, err = s.ReadFile(ctx, "/c")
+	require.ErrorContains(t, err, "failed to decrypt")
+	headerLen := len(raw) - 2*sealedSegmentSize - 100 - encryptTagLen
+	require.NoError(t, inner.WriteFile(ctx, "/d", raw[:headerLen+sealedSegmentSize]))
+	_, err = s.ReadFile(ctx, "/d")
+	require.ErrorContains(t, err, "failed to decrypt")
+
+	// plaintext objects are rejected.
+	require.NoError(t, inner.WriteFile(ctx, "/e", content))
+	_, err = s.ReadFile(ctx, "/e")
+	require.ErrorContains(t, err, "is not encrypted")
+}
+
+func TestFileKeyRing(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemStorage()
+
+	// rotate the master key, the old objects can still be read.
+	require.NoError(t, WithEncryption(inner, newTestKeyRing(t, "k1")).WriteFile(ctx, "/a", []byte("old")))
+	s := WithEncryption(inner, newTestKeyRing(t, "k2", "k1"))
+	require.NoError(t, s.WriteFile(ctx, "/b", []byte("new")))
+	data, err := s.ReadFile(ctx, "/a")
+	require.NoError(t, err)
+	require.Equal(t, []byte("old"), data)
+	data, err = s.ReadFile(ctx, "/b")
+	require.NoError(t, err)
+	require.Equal(t, []byte("new"), data)
+
+	_, err = WithEncryption(inner, newTestKeyRing(t, "k1")).ReadFile(ctx, "/b")
+	require.ErrorContains(t, err, "the master key 'k2' is not in the key ring")
+
+	// a different key with the same ID can't unwrap the data key.
+	other := newTestKeyRing(t, "k2")
+	key := make([]byte, encryptDataKeyLen)
+	other.keys["k2"], err = newAESGCM(key)
+	require.NoError(t, err)
+	_, err = WithEncryption(inner, other).ReadFile(ctx, "/b")
+	require.ErrorContains(t, err, "failed to unwrap the data key")
+
+	// load from file.
+	path := filepath.Join(t.TempDir(), "keyring")
+	content := "# comment\n\nk2 " + hex.EncodeToString(append([]byte("k2"), make([]byte, encryptDataKeyLen-2)...)) + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	keyRing, err := ParseKeyRing(path)
+	require.NoError(t, err)
+	data, err = WithEncryption(inner, keyRing).ReadFile(ctx, "/b")
+	require.NoError(t, err)
+	require.Equal(t, []byte("new"), data)
+
+	for _, content := range []string{"", "k1", "k1 0011", "k1 zz", "k1 " + hex.EncodeToString(key) + "\nk1 " + hex.EncodeToString(key)} {
+		_, err = parseFileKeyRing([]byte(content))
+		require.Error(t, err, content)
+	}
+}
+
+type mockKMS struct {
+	kmsiface.KMSAPI
+	keys map[string]byte
+}
+
+func (m *mockKMS) EncryptWithContext(_ aws.Context, input *kms.EncryptInput, _ ...request.Option) (*kms.EncryptOutput, error) {
+	blob := append([]byte{}, input.Plaintext...)
+	for i := range blob {
+		blob[i] ^= m.keys[*input.KeyId]
+	}
+	return &kms.EncryptOutput{KeyId: aws.String("arn:" + *input.KeyId), CiphertextBlob: blob}, nil
+}
+
+func (m *mockKMS) DecryptWithContext(_ aws.Context, input *kms.DecryptInput, _ ...request.Option) (*kms.DecryptOutput, error) {
+	plaintext := append([]byte{}, input.CiphertextBlob...)
+	for i := range plaintext {
+		plaintext[i] ^= m.keys[(*input.KeyId)[len("arn:"):]]
+	}
+	return &kms.DecryptOutput{KeyId: input.KeyId, Plaintext: plaintext}, nil
+}
+
+func TestAWSKMSKeyRing(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemStorage()
+	client := &mockKMS{keys: map[string]byte{"key-1": 0x5a}}
+	s := WithEncryption(inner, &awsKMSKeyRing{client: client, keyID: "key-1"})
+
+	require.NoError(t, s.WriteFile(ctx, "/a", []byte("hello")))
+	data, err := s.ReadFile(ctx, "/a")
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), data)
+	raw, err := inner.ReadFile(ctx, "/a")
+	require.NoError(t, err)
+	require.True(t, bytes.Contains(raw, []byte("arn:key-1")))
+
+	keyRing, err := ParseKeyRing("aws-kms://alias/backup?region=us-west-2")
+	require.NoError(t, err)
+	require.Equal(t, "alias/backup", keyRing.(*awsKMSKeyRing).keyID)
+}
@@ -7,17 +7,25 @@ import (
 	"github.com/spf13/pflag"
 )
 
+// FlagPrintResolvedCredentialsSource is the name of the flag to print where the
+// credentials of the storage are resolved from and exit.
+const FlagPrintResolvedCredentialsSource = "print-resolved-credentials-source"
+
 // DefineFlags adds flags to the flag set corresponding to all backend options.
 func DefineFlags(flags *pflag.FlagSet) {
 	defineS3Flags(flags)
 	defineGCSFlags(flags)
 	defineAzblobFlags(flags)
+	defineSFTPFlags(flags)
+	flags.Bool(FlagPrintResolvedCredentialsSource, false,
+		"Print where the credentials of the storage are resolved from (config, env, shared-file, credential-process or imds) and exit")
 }
 
 // HiddenFlagsForStream hidden flags for stream cmd.
 func HiddenFlagsForStream(flags *pflag.FlagSet) {
 	hiddenGCSFlags(flags)
 	hiddenAzblobFlags(flags)
+	hiddenSFTPFlags(flags)
 }
 
 // ParseFromFlags obtains the backend options from the flag set.
@@ -31,5 +39,8 @@ func (options *BackendOptions) ParseFromFlags(flags *pflag.FlagSet) error {
 	if err := options.Azblob.parseFromFlags(flags); err != nil {
 		return errors.Trace(err)
 	}
+	if err := options.SFTP.parseFromFlags(flags); err != nil {
+		return errors.Trace(err)
+	}
 	return nil
 }
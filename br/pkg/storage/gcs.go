@@ -4,10 +4,16 @@ package storage
 
 import (
 	"context"
+	stderrors "errors"
+	"hash/crc32"
 	"io"
+	"net"
+	"net/http"
 	"os"
 	"path"
 	"strings"
+	"syscall"
+	"time"
 
 	"cloud.google.com/go/storage"
 	"github.com/pingcap/errors"
@@ -17,6 +23,7 @@ import (
 	"github.com/spf13/pflag"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
@@ -28,6 +35,19 @@ const (
 	gcsCredentialsFile    = "gcs.credentials-file"
 )
 
+const (
+	// gcsChunkSize is the size of the chunks of the resumable uploads. A failed
+	// chunk is retried on its own instead of the whole object.
+	gcsChunkSize = 16 * 1024 * 1024
+	// gcsChunkRetryDeadline is the deadline to retry uploading a chunk.
+	gcsChunkRetryDeadline = 2 * time.Minute
+	// gcsMaxReadRetries is the max times in a row to resume reading an object
+	// from where it fails on the transient errors.
+	gcsMaxReadRetries = 5
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
 // GCSBackendOptions are options for configuration the GCS storage.
 type GCSBackendOptions struct {
 	Endpoint        string `json:"endpoint" toml:"endpoint"`
@@ -106,12 +126,29 @@ func (s *gcsStorage) objectName(name string) string {
 	return path.Join(s.gcs.Prefix, name)
 }
 
+// objectHandle returns the handle of the object whose requests are retried on
+// the transient errors. The uploads are retried as well, which is safe since
+// each chunk of a resumable upload is written at its own offset.
+func (s *gcsStorage) objectHandle(object string) *storage.ObjectHandle {
+	return s.bucket.Object(object).Retryer(storage.WithPolicy(storage.RetryAlways))
+}
+
+func (s *gcsStorage) newWriter(ctx context.Context, object string) *storage.Writer {
+	wc := s.objectHandle(object).NewWriter(ctx)
+	wc.StorageClass = s.gcs.StorageClass
+	wc.PredefinedACL = s.gcs.PredefinedAcl
+	wc.ChunkSize = gcsChunkSize
+	wc.ChunkRetryDeadline = gcsChunkRetryDeadline
+	return wc
+}
+
 // WriteFile writes data to a file to storage.
 func (s *gcsStorage) WriteFile(ctx context.Context, name string, data []byte) error {
 	object := s.objectName(name)
-	wc := s.bucket.Object(object).NewWriter(ctx)
-	wc.StorageClass = s.gcs.StorageClass
-	wc.PredefinedACL = s.gcs.PredefinedAcl
+	wc := s.newWriter(ctx, object)
+	// GCS rejects the object if the CRC32C mismatches.
+	wc.CRC32C = crc32.Checksum(data, crc32cTable)
+	wc.SendCRC32C = true
 	_, err := wc.Write(data)
 	if err != nil {
 		return errors.Trace(err)
@@ -122,7 +159,41 @@ func (s *gcsStorage) WriteFile(ctx context.Context, name string, data []byte) er
 // ReadFile reads the file from the storage and returns the contents.
 func (s *gcsStorage) ReadFile(ctx context.Context, name string) ([]byte, error) {
 	object := s.objectName(name)
-	rc, err := s.bucket.Object(object).NewReader(ctx)
+	handle := s.objectHandle(object)
+	attrs, err := handle.Attrs(ctx)
+	if err != nil {
+		return nil, errors.Annotatef(err,
+			"failed to read gcs file, file info: input.bucket='%s', input.key='%s'",
+			s.gcs.Bucket, object)
+	}
+	if attrs.ContentEncoding == "gzip" {
+		// the object is served decompressed, whose size and CRC32C are unknown.
+		return s.readWholeFile(ctx, handle, object)
+	}
+
+	// resume from the same generation of the object if the read fails.
+	r := &gcsObjectReader{
+		storage:   s,
+		name:      name,
+		objHandle: handle.Generation(attrs.Generation),
+		ctx:       ctx,
+	}
+	defer r.Close()
+	b := make([]byte, attrs.Size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// GCS may not provide the CRC32C, e.g. for the fake-gcs-server in test.
+	if crc := crc32.Checksum(b, crc32cTable); attrs.CRC32C != 0 && crc != attrs.CRC32C {
+		return nil, errors.Annotatef(berrors.ErrStorageChecksumMismatch,
+			"gcs file is corrupted, file info: input.bucket='%s', input.key='%s', crc32c: %d, expected: %d",
+			s.gcs.Bucket, object, crc, attrs.CRC32C)
+	}
+	return b, nil
+}
+
+func (s *gcsStorage) readWholeFile(ctx context.Context, handle *storage.ObjectHandle, object string) ([]byte, error) {
+	rc, err := handle.NewReader(ctx)
 	if err != nil {
 		return nil, errors.Annotatef(err,
 			"failed to read gcs file, file info: input.bucket='%s', input.key='%s'",
@@ -142,6 +213,20 @@ func (s *gcsStorage) ReadFile(ctx context.Context, name string) ([]byte, error)
 	return b, errors.Trace(err)
 }
 
+// isRetryableGCSError returns whether the error is transient, see
+// https://cloud.google.com/storage/docs/retry-strategy.
+func isRetryableGCSError(err error) bool {
+	if stderrors.Is(err, io.ErrUnexpectedEOF) || stderrors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var apiErr *googleapi.Error
+	if stderrors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusRequestTimeout || apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return stderrors.As(err, &netErr) && netErr.Timeout()
+}
+
 // FileExists return true if file exists.
 func (s *gcsStorage) FileExists(ctx context.Context, name string) (bool, error) {
 	object := s.objectName(name)
@@ -158,7 +243,7 @@ func (s *gcsStorage) FileExists(ctx context.Context, name string) (bool, error)
 // Open a Reader by file path.
 func (s *gcsStorage) Open(ctx context.Context, path string) (ExternalFileReader, error) {
 	object := s.objectName(path)
-	handle := s.bucket.Object(object)
+	handle := s.objectHandle(object)
 
 	rc, err := handle.NewRangeReader(ctx, 0, -1)
 	if err != nil {
@@ -183,6 +268,13 @@ func (s *gcsStorage) Open(ctx context.Context, path string) (ExternalFileReader,
 // function; the second argument is the size in byte of the file determined
 // by path.
 func (s *gcsStorage) WalkDir(ctx context.Context, opt *WalkOption, fn func(string, int64) error) error {
+	return s.WalkDirWithModTime(ctx, opt, func(path string, size int64, _ time.Time) error {
+		return fn(path, size)
+	})
+}
+
+// WalkDirWithModTime implements ModTimeWalker.
+func (s *gcsStorage) WalkDirWithModTime(ctx context.Context, opt *WalkOption, fn func(string, int64, time.Time) error) error {
 	if opt == nil {
 		opt = &WalkOption{}
 	}
@@ -194,8 +286,8 @@ func (s *gcsStorage) WalkDir(ctx context.Context, opt *WalkOption, fn func(strin
 		prefix += "/"
 	}
 	query := &storage.Query{Prefix: prefix}
-	// only need each object's name and size
-	err := query.SetAttrSelection([]string{"Name", "Size"})
+	// only need each object's name, size and modified time
+	err := query.SetAttrSelection([]string{"Name", "Size", "Updated"})
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -214,7 +306,7 @@ func (s *gcsStorage) WalkDir(ctx context.Context, opt *WalkOption, fn func(strin
 		path := strings.TrimPrefix(attrs.Name, s.gcs.Prefix)
 		// trim the prefix '/' to ensure that the path returned is consistent with the local storage
 		path = strings.TrimPrefix(path, "/")
-		if err = fn(path, attrs.Size); err != nil {
+		if err = fn(path, attrs.Size, attrs.Updated); err != nil {
 			return errors.Trace(err)
 		}
 	}
@@ -228,12 +320,38 @@ func (s *gcsStorage) URI() string {
 // Create implements ExternalStorage interface.
 func (s *gcsStorage) Create(ctx context.Context, name string) (ExternalFileWriter, error) {
 	object := s.objectName(name)
-	wc := s.bucket.Object(object).NewWriter(ctx)
-	wc.StorageClass = s.gcs.StorageClass
-	wc.PredefinedACL = s.gcs.PredefinedAcl
+	wc := &gcsChecksumWriter{storage: s, writer: s.newWriter(ctx, object)}
 	return newFlushStorageWriter(wc, &emptyFlusher{}, wc), nil
 }
 
+// gcsChecksumWriter computes the CRC32C of the object while it's streamed, and
+// verifies it with the one computed by GCS after the upload.
+type gcsChecksumWriter struct {
+	storage *gcsStorage
+	writer  *storage.Writer
+	crc     uint32
+}
+
+// Write implements the io.Writer interface.
+func (w *gcsChecksumWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.crc = crc32.Update(w.crc, crc32cTable, p[:n])
+	return n, err
+}
+
+// Close implements the io.Closer interface.
+func (w *gcsChecksumWriter) Close() error {
+	if err := w.writer.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	if crc := w.writer.Attrs().CRC32C; crc != 0 && crc != w.crc {
+		return errors.Annotatef(berrors.ErrStorageChecksumMismatch,
+			"uploaded gcs file is corrupted, file info: input.bucket='%s', input.key='%s', crc32c: %d, expected: %d",
+			w.storage.gcs.Bucket, w.writer.Name, crc, w.crc)
+	}
+	return nil
+}
+
 // Rename file name from oldFileName to newFileName.
 func (s *gcsStorage) Rename(ctx context.Context, oldFileName, newFileName string) error {
 	data, err := s.ReadFile(ctx, oldFileName)
@@ -247,29 +365,61 @@ func (s *gcsStorage) Rename(ctx context.Context, oldFileName, newFileName string
 	return s.DeleteFile(ctx, oldFileName)
 }
 
+// resolveGCSCredentials returns where the credentials of the GCS backend are
+// resolved from. The default credentials are returned if the credentials file
+// is not given.
+func resolveGCSCredentials(ctx context.Context, gcs *backuppb.GCS, opts *ExternalStorageOptions) (*google.Credentials, *ResolvedCredentials, error) {
+	if opts.NoCredentials {
+		return nil, &ResolvedCredentials{Storage: "gcs", Source: CredentialsSourceNone}, nil
+	}
+	if gcs.CredentialsBlob != "" {
+		return nil, &ResolvedCredentials{
+			Storage:  "gcs",
+			Source:   gcsCredentialsSource(CredentialsSourceConfig, []byte(gcs.CredentialsBlob)),
+			Provider: gcsCredentialsType([]byte(gcs.CredentialsBlob)),
+		}, nil
+	}
+	creds, err := google.FindDefaultCredentials(ctx, storage.ScopeReadWrite)
+	if err != nil {
+		return nil, nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "%v Or you should provide '--gcs.credentials_file'", err)
+	}
+	// the default credentials are looked up in the environment variable, the
+	// well-known file of gcloud and the metadata server in order.
+	resolved := &ResolvedCredentials{Storage: "gcs"}
+	switch {
+	case len(creds.JSON) == 0:
+		resolved.Source = CredentialsSourceInstanceMetadata
+		resolved.Provider = gcsMetadataProvider
+	case len(os.Getenv(gcsCredentialsEnv)) > 0:
+		resolved.Source = gcsCredentialsSource(CredentialsSourceEnv, creds.JSON)
+		resolved.Provider = gcsCredentialsType(creds.JSON)
+	default:
+		resolved.Source = gcsCredentialsSource(CredentialsSourceSharedFile, creds.JSON)
+		resolved.Provider = gcsCredentialsType(creds.JSON)
+	}
+	return creds, resolved, nil
+}
+
 func newGCSStorage(ctx context.Context, gcs *backuppb.GCS, opts *ExternalStorageOptions) (*gcsStorage, error) {
 	var clientOps []option.ClientOption
-	if opts.NoCredentials {
+	creds, _, err := resolveGCSCredentials(ctx, gcs, opts)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	switch {
+	case opts.NoCredentials:
 		clientOps = append(clientOps, option.WithoutAuthentication())
-	} else {
-		if gcs.CredentialsBlob == "" {
-			creds, err := google.FindDefaultCredentials(ctx, storage.ScopeReadWrite)
-			if err != nil {
-				return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "%v Or you should provide '--gcs.credentials_file'", err)
-			}
-			if opts.SendCredentials {
-				if len(creds.JSON) <= 0 {
-					return nil, errors.Annotate(berrors.ErrStorageInvalidConfig,
-						"You should provide '--gcs.credentials_file' when '--send-credentials-to-tikv' is true")
-				}
-				gcs.CredentialsBlob = string(creds.JSON)
+	case gcs.CredentialsBlob == "":
+		if opts.SendCredentials {
+			if len(creds.JSON) <= 0 {
+				return nil, errors.Annotate(berrors.ErrStorageInvalidConfig,
+					"You should provide '--gcs.credentials_file' when '--send-credentials-to-tikv' is true")
 			}
-			if creds != nil {
-				clientOps = append(clientOps, option.WithCredentials(creds))
-			}
-		} else {
-			clientOps = append(clientOps, option.WithCredentialsJSON([]byte(gcs.GetCredentialsBlob())))
+			gcs.CredentialsBlob = string(creds.JSON)
 		}
+		clientOps = append(clientOps, option.WithCredentials(creds))
+	default:
+		clientOps = append(clientOps, option.WithCredentialsJSON([]byte(gcs.GetCredentialsBlob())))
 	}
 
 	if gcs.Endpoint != "" {
@@ -338,20 +488,37 @@ type gcsObjectReader struct {
 	ctx context.Context
 }
 
-// Read implement the io.Reader interface.
+// Read implement the io.Reader interface. The object is reopened from the
+// current position on the transient errors.
 func (r *gcsObjectReader) Read(p []byte) (n int, err error) {
-	if r.reader == nil {
-		rc, err := r.objHandle.NewRangeReader(r.ctx, r.pos, -1)
-		if err != nil {
-			return 0, errors.Annotatef(err,
-				"failed to read gcs file, file info: input.bucket='%s', input.key='%s'",
-				r.storage.gcs.Bucket, r.name)
+	for retry := 0; ; retry++ {
+		if r.reader == nil {
+			rc, err := r.objHandle.NewRangeReader(r.ctx, r.pos, -1)
+			if err != nil {
+				return 0, errors.Annotatef(err,
+					"failed to read gcs file, file info: input.bucket='%s', input.key='%s'",
+					r.storage.gcs.Bucket, r.name)
+			}
+			r.reader = rc
+		}
+		n, err = r.reader.Read(p)
+		r.pos += int64(n)
+		if err == nil || err == io.EOF || retry >= gcsMaxReadRetries || !isRetryableGCSError(err) { // nolint:errorlint
+			return n, err
+		}
+		log.Warn("failed to read gcs file, resuming", zap.String("key", r.name),
+			zap.Int64("offset", r.pos), zap.Int("retry", retry), zap.Error(err))
+		_ = r.reader.Close()
+		r.reader = nil
+		if n > 0 {
+			return n, nil
+		}
+		select {
+		case <-r.ctx.Done():
+			return 0, errors.Trace(r.ctx.Err())
+		case <-time.After(time.Duration(retry+1) * 100 * time.Millisecond):
 		}
-		r.reader = rc
 	}
-	n, err = r.reader.Read(p)
-	r.pos += int64(n)
-	return n, err
 }
 
 // Close implement the io.Closer interface.
@@ -392,7 +559,9 @@ func (r *gcsObjectReader) Seek(offset int64, whence int) (int64, error) {
 		return realOffset, nil
 	}
 
-	_ = r.reader.Close()
+	if r.reader != nil {
+		_ = r.reader.Close()
+	}
 	r.pos = realOffset
 	rc, err := r.objHandle.NewRangeReader(r.ctx, r.pos, -1)
 	if err != nil {
@@ -3,11 +3,16 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"testing/iotest"
 
 	"github.com/fsouza/fake-gcs-server/fakestorage"
 	backuppb "github.com/pingcap/kvproto/pkg/brpb"
@@ -378,3 +383,93 @@ func TestNewGCSStorage(t *testing.T) {
 		require.Equal(t, "a/b/x", s.objectName("x"))
 	}
 }
+
+// faultyGCSTransport injects the faults into the responses of the fake GCS
+// server.
+type faultyGCSTransport struct {
+	http.RoundTripper
+	// failUploads is the number of uploads to be failed with 503.
+	failUploads int32
+	// breakReads is the number of downloads to be broken in the middle.
+	breakReads int32
+	// corruptReads corrupts the downloaded data.
+	corruptReads bool
+}
+
+func (t *faultyGCSTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	isUpload := req.Method == http.MethodPost || req.Method == http.MethodPut
+	if isUpload && atomic.AddInt32(&t.failUploads, -1) >= 0 {
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       io.NopCloser(strings.NewReader("")),
+			Header:     make(http.Header),
+			Request:    req,
+		}, nil
+	}
+	resp, err := t.RoundTripper.RoundTrip(req)
+	if err != nil || req.Method != http.MethodGet || resp.StatusCode >= 300 || !strings.Contains(req.URL.String(), "testbucket/a/b/") {
+		return resp, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	var body io.Reader = bytes.NewReader(data)
+	if t.corruptReads && len(data) > 0 {
+		data[0] ^= 0xff
+	} else if len(data) > 1 && atomic.AddInt32(&t.breakReads, -1) >= 0 {
+		body = io.MultiReader(bytes.NewReader(data[:len(data)/2]), iotest.ErrReader(io.ErrUnexpectedEOF))
+	}
+	resp.Body = io.NopCloser(body)
+	return resp, nil
+}
+
+func TestGCSRetryAndChecksum(t *testing.T) {
+	ctx := context.Background()
+	server, err := fakestorage.NewServerWithOptions(fakestorage.Options{NoListener: true})
+	require.NoError(t, err)
+	server.CreateBucketWithOpts(fakestorage.CreateBucketOpts{Name: "testbucket"})
+	transport := &faultyGCSTransport{RoundTripper: server.HTTPClient().Transport}
+	stg, err := newGCSStorage(ctx, &backuppb.GCS{
+		Bucket:          "testbucket",
+		Prefix:          "a/b/",
+		CredentialsBlob: "Fake Credentials",
+	}, &ExternalStorageOptions{HTTPClient: &http.Client{Transport: transport}})
+	require.NoError(t, err)
+
+	data := make([]byte, 100*1024)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	// the uploads are retried.
+	transport.failUploads = 1
+	require.NoError(t, stg.WriteFile(ctx, "key", data))
+	transport.failUploads = 1
+	w, err := stg.Create(ctx, "key2")
+	require.NoError(t, err)
+	_, err = w.Write(ctx, data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close(ctx))
+
+	// the downloads are resumed from where they are broken.
+	transport.breakReads = 2
+	d, err := stg.ReadFile(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, data, d)
+	require.Equal(t, int32(-1), transport.breakReads)
+	transport.breakReads = 2
+	r, err := stg.Open(ctx, "key2")
+	require.NoError(t, err)
+	d, err = io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, data, d)
+	require.NoError(t, r.Close())
+
+	// the downloads are verified with the CRC32C.
+	transport.breakReads = 0
+	transport.corruptReads = true
+	_, err = stg.ReadFile(ctx, "key")
+	require.Error(t, err)
+	require.Regexp(t, "CRC|checksum", err.Error())
+}
@@ -75,7 +75,7 @@ func (s *HDFSStorage) WriteFile(_ context.Context, name string, data []byte) err
 
 // ReadFile reads a complete file from storage, similar to os.ReadFile
 func (*HDFSStorage) ReadFile(_ context.Context, _ string) ([]byte, error) {
-	return nil, errors.Annotatef(berrors.ErrUnsupportedOperation, "currently HDFS backend only support rawkv backup")
+	return nil, errors.Annotatef(berrors.ErrUnsupportedOperation, "currently HDFS backend only support rawkv backup, please use webhdfs:// instead")
 }
 
 // FileExists return true if file exists
@@ -99,12 +99,12 @@ func (s *HDFSStorage) FileExists(_ context.Context, name string) (bool, error) {
 
 // DeleteFile delete the file in storage
 func (*HDFSStorage) DeleteFile(_ context.Context, _ string) error {
-	return errors.Annotatef(berrors.ErrUnsupportedOperation, "currently HDFS backend only support rawkv backup")
+	return errors.Annotatef(berrors.ErrUnsupportedOperation, "currently HDFS backend only support rawkv backup, please use webhdfs:// instead")
 }
 
 // Open a Reader by file path. path is relative path to storage base path
 func (*HDFSStorage) Open(_ context.Context, _ string) (ExternalFileReader, error) {
-	return nil, errors.Annotatef(berrors.ErrUnsupportedOperation, "currently HDFS backend only support rawkv backup")
+	return nil, errors.Annotatef(berrors.ErrUnsupportedOperation, "currently HDFS backend only support rawkv backup, please use webhdfs:// instead")
 }
 
 // WalkDir traverse all the files in a dir.
@@ -114,7 +114,7 @@ func (*HDFSStorage) Open(_ context.Context, _ string) (ExternalFileReader, error
 // function; the argument `size` is the size in byte of the file determined
 // by path.
 func (*HDFSStorage) WalkDir(_ context.Context, _ *WalkOption, _ func(path string, size int64) error) error {
-	return errors.Annotatef(berrors.ErrUnsupportedOperation, "currently HDFS backend only support rawkv backup")
+	return errors.Annotatef(berrors.ErrUnsupportedOperation, "currently HDFS backend only support rawkv backup, please use webhdfs:// instead")
 }
 
 // URI returns the base path as a URI
@@ -124,10 +124,10 @@ func (s *HDFSStorage) URI() string {
 
 // Create opens a file writer by path. path is relative path to storage base path
 func (*HDFSStorage) Create(_ context.Context, _ string) (ExternalFileWriter, error) {
-	return nil, errors.Annotatef(berrors.ErrUnsupportedOperation, "currently HDFS backend only support rawkv backup")
+	return nil, errors.Annotatef(berrors.ErrUnsupportedOperation, "currently HDFS backend only support rawkv backup, please use webhdfs:// instead")
 }
 
 // Rename a file name from oldFileName to newFileName.
 func (*HDFSStorage) Rename(_ context.Context, _, _ string) error {
-	return errors.Annotatef(berrors.ErrUnsupportedOperation, "currently HDFS backend only support rawkv backup")
+	return errors.Annotatef(berrors.ErrUnsupportedOperation, "currently HDFS backend only support rawkv backup, please use webhdfs:// instead")
 }
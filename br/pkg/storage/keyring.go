@@ -0,0 +1,173 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/pingcap/errors"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+)
+
+const awsKMSScheme = "aws-kms"
+
+// ParseKeyRing parses the key ring of the client-side encryption. The key ring is either
+// a path of a key ring file (see NewFileKeyRing), or an AWS KMS key in the form of
+// `aws-kms://<key-id>?region=<region>&endpoint=<endpoint>`.
+func ParseKeyRing(keyRing string) (KeyRing, error) {
+	if strings.HasPrefix(keyRing, awsKMSScheme+"://") {
+		u, err := url.Parse(keyRing)
+		if err != nil {
+			return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "invalid key ring '%s': %v", keyRing, err)
+		}
+		keyID := u.Host + u.Path
+		if len(keyID) == 0 {
+			return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "the key ID is missing in key ring '%s'", keyRing)
+		}
+		return NewAWSKMSKeyRing(keyID, u.Query().Get("region"), u.Query().Get("endpoint"))
+	}
+	return NewFileKeyRing(keyRing)
+}
+
+type fileKeyRing struct {
+	// currentKeyID is the ID of the master key to wrap the new data keys.
+	currentKeyID string
+	keys         map[string]cipher.AEAD
+}
+
+// NewFileKeyRing loads the master keys from a local file. Each non-empty line of the file
+// not starting with '#' is a master key in the form of `<key-id> <hex-encoded 256-bit key>`.
+// The first key wraps the new data keys, and the others are only used to unwrap the data
+// keys of the existing objects, so the master key can be rotated by adding a new key at
+// the beginning.
+func NewFileKeyRing(path string) (KeyRing, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "failed to read key ring file '%s': %v", path, err)
+	}
+	return parseFileKeyRing(content)
+}
+
+func parseFileKeyRing(content []byte) (*fileKeyRing, error) {
+	k := &fileKeyRing{keys: make(map[string]cipher.AEAD)}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "invalid line in key ring file, expect '<key-id> <key>'")
+		}
+		keyID := fields[0]
+		if _, ok := k.keys[keyID]; ok {
+			return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "duplicated key '%s' in key ring file", keyID)
+		}
+		key, err := hex.DecodeString(fields[1])
+		if err != nil || len(key) != encryptDataKeyLen {
+			return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig,
+				"the key '%s' in key ring file should be a hex-encoded 256-bit key", keyID)
+		}
+		aead, err := newAESGCM(key)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(k.currentKeyID) == 0 {
+			k.currentKeyID = keyID
+		}
+		k.keys[keyID] = aead
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(k.currentKeyID) == 0 {
+		return nil, errors.Annotate(berrors.ErrStorageInvalidConfig, "no key found in key ring file")
+	}
+	return k, nil
+}
+
+// WrapKey implements KeyRing.
+func (k *fileKeyRing) WrapKey(_ context.Context, dataKey []byte) (string, []byte, error) {
+	nonce := make([]byte, encryptNonceLen, encryptNonceLen+len(dataKey)+encryptTagLen)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	return k.currentKeyID, k.keys[k.currentKeyID].Seal(nonce, nonce, dataKey, []byte(k.currentKeyID)), nil
+}
+
+// UnwrapKey implements KeyRing.
+func (k *fileKeyRing) UnwrapKey(_ context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+	aead, ok := k.keys[keyID]
+	if !ok {
+		return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "the master key '%s' is not in the key ring", keyID)
+	}
+	if len(wrappedKey) < encryptNonceLen {
+		return nil, errors.Annotate(berrors.ErrStorageUnknown, "the wrapped data key is truncated")
+	}
+	dataKey, err := aead.Open(nil, wrappedKey[:encryptNonceLen], wrappedKey[encryptNonceLen:], []byte(keyID))
+	if err != nil {
+		return nil, errors.Annotatef(berrors.ErrStorageUnknown, "failed to unwrap the data key by master key '%s': %v", keyID, err)
+	}
+	return dataKey, nil
+}
+
+type awsKMSKeyRing struct {
+	client kmsiface.KMSAPI
+	keyID  string
+}
+
+// NewAWSKMSKeyRing creates a KeyRing which wraps the data keys by the AWS KMS key. The
+// credentials are loaded in the same way as S3.
+func NewAWSKMSKeyRing(keyID, region, endpoint string) (KeyRing, error) {
+	awsConfig := aws.NewConfig().WithCredentialsChainVerboseErrors(true)
+	if len(region) == 0 {
+		awsConfig.WithRegion(defaultRegion)
+	} else {
+		awsConfig.WithRegion(region)
+	}
+	if len(endpoint) > 0 {
+		awsConfig.WithEndpoint(endpoint)
+	}
+	ses, err := session.NewSessionWithOptions(session.Options{Config: *awsConfig})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &awsKMSKeyRing{client: kms.New(ses), keyID: keyID}, nil
+}
+
+// WrapKey implements KeyRing.
+func (k *awsKMSKeyRing) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	output, err := k.client.EncryptWithContext(ctx, &kms.EncryptInput{
+		KeyId:     aws.String(k.keyID),
+		Plaintext: dataKey,
+	})
+	if err != nil {
+		return "", nil, errors.Trace(err)
+	}
+	return aws.StringValue(output.KeyId), output.CiphertextBlob, nil
+}
+
+// UnwrapKey implements KeyRing.
+func (k *awsKMSKeyRing) UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+	output, err := k.client.DecryptWithContext(ctx, &kms.DecryptInput{
+		KeyId:          aws.String(keyID),
+		CiphertextBlob: wrappedKey,
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return output.Plaintext, nil
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/pingcap/errors"
 )
@@ -64,7 +65,14 @@ func (l *LocalStorage) FileExists(_ context.Context, name string) (bool, error)
 // The first argument is the file path that can be used in `Open`
 // function; the second argument is the size in byte of the file determined
 // by path.
-func (l *LocalStorage) WalkDir(_ context.Context, opt *WalkOption, fn func(string, int64) error) error {
+func (l *LocalStorage) WalkDir(ctx context.Context, opt *WalkOption, fn func(string, int64) error) error {
+	return l.WalkDirWithModTime(ctx, opt, func(path string, size int64, _ time.Time) error {
+		return fn(path, size)
+	})
+}
+
+// WalkDirWithModTime implements ModTimeWalker.
+func (l *LocalStorage) WalkDirWithModTime(_ context.Context, opt *WalkOption, fn func(string, int64, time.Time) error) error {
 	if opt == nil {
 		opt = &WalkOption{}
 	}
@@ -89,16 +97,16 @@ func (l *LocalStorage) WalkDir(_ context.Context, opt *WalkOption, fn func(strin
 			return nil
 		}
 
-		size := f.Size()
+		size, modTime := f.Size(), f.ModTime()
 		// if not a regular file, we need to use os.stat to get the real file size
 		if !f.Mode().IsRegular() {
 			stat, err := os.Stat(filepath.Join(l.base, path))
 			if err != nil {
 				return errors.Trace(err)
 			}
-			size = stat.Size()
+			size, modTime = stat.Size(), stat.ModTime()
 		}
-		return fn(path, size)
+		return fn(path, size, modTime)
 	})
 }
 
@@ -115,7 +123,12 @@ func (l *LocalStorage) Open(_ context.Context, path string) (ExternalFileReader,
 
 // Create implements ExternalStorage interface.
 func (l *LocalStorage) Create(_ context.Context, name string) (ExternalFileWriter, error) {
-	file, err := os.Create(filepath.Join(l.base, name))
+	path := filepath.Join(l.base, name)
+	// like the object storages, the parent directories are created if not exist.
+	if err := os.MkdirAll(filepath.Dir(path), localDirPerm); err != nil {
+		return nil, errors.Trace(err)
+	}
+	file, err := os.Create(path)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -31,6 +32,14 @@ func TestDeleteFile(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, true, ret)
 
+	// the parent directories are created automatically.
+	w, err := store.Create(context.Background(), "a/b/"+name)
+	require.NoError(t, err)
+	require.NoError(t, w.Close(context.Background()))
+	ret, err = store.FileExists(context.Background(), "a/b/"+name)
+	require.NoError(t, err)
+	require.Equal(t, true, ret)
+
 	err = store.DeleteFile(context.Background(), name)
 	require.NoError(t, err)
 
@@ -100,3 +109,38 @@ func TestWalkDirWithSoftLinkFile(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 1, i)
 }
+
+func TestWalkDirWithModTime(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	path := filepath.Join(dir, "test.csv")
+	require.NoError(t, os.WriteFile(path, []byte("a,b"), 0o644))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	store, err := NewLocalStorage(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+	i := 0
+	err = WalkDirWithModTime(ctx, store, &WalkOption{}, func(path string, size int64, tm time.Time) error {
+		i++
+		require.Equal(t, "test.csv", path)
+		require.Equal(t, int64(3), size)
+		require.True(t, modTime.Equal(tm))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, i)
+
+	// the storages which don't provide the modified time
+	memStore := NewMemStorage()
+	require.NoError(t, memStore.WriteFile(ctx, "/test.csv", []byte("a,b")))
+	i = 0
+	err = WalkDirWithModTime(ctx, memStore, &WalkOption{}, func(path string, size int64, tm time.Time) error {
+		i++
+		require.Equal(t, int64(3), size)
+		require.True(t, tm.IsZero())
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, i)
+}
@@ -20,6 +20,7 @@ type BackendOptions struct {
 	S3     S3BackendOptions     `json:"s3" toml:"s3"`
 	GCS    GCSBackendOptions    `json:"gcs" toml:"gcs"`
 	Azblob AzblobBackendOptions `json:"azblob" toml:"azblob"`
+	SFTP   SFTPBackendOptions   `json:"sftp" toml:"sftp"`
 }
 
 // ParseRawURL parse raw url to url object.
@@ -58,7 +59,7 @@ func ParseBackend(rawURL string, options *BackendOptions) (*backuppb.StorageBack
 		local := &backuppb.Local{Path: u.Path}
 		return &backuppb.StorageBackend{Backend: &backuppb.StorageBackend_Local{Local: local}}, nil
 
-	case "hdfs":
+	case "hdfs", webhdfsScheme, secureWebhdfsScheme:
 		hdfs := &backuppb.HDFS{Remote: rawURL}
 		return &backuppb.StorageBackend{Backend: &backuppb.StorageBackend_Hdfs{Hdfs: hdfs}}, nil
 
@@ -110,6 +111,20 @@ func ParseBackend(rawURL string, options *BackendOptions) (*backuppb.StorageBack
 			return nil, errors.Trace(err)
 		}
 		return &backuppb.StorageBackend{Backend: &backuppb.StorageBackend_AzureBlobStorage{AzureBlobStorage: azblob}}, nil
+
+	case sftpScheme:
+		if u.Host == "" {
+			return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "please specify the host for sftp in %s", rawURL)
+		}
+		if options == nil {
+			options = &BackendOptions{}
+		}
+		ExtractQueryParameters(u, &options.SFTP)
+		sftp, err := options.SFTP.apply(u)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return &backuppb.StorageBackend{Backend: &backuppb.StorageBackend_CloudDynamic{CloudDynamic: sftp}}, nil
 	default:
 		return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "storage %s not support yet", u.Scheme)
 	}
@@ -118,7 +133,7 @@ func ParseBackend(rawURL string, options *BackendOptions) (*backuppb.StorageBack
 // ExtractQueryParameters moves the query parameters of the URL into the options
 // using reflection.
 //
-// The options must be a pointer to a struct which contains only string or bool
+// The options must be a pointer to a struct which contains only string, bool or int
 // fields (more types will be supported in the future), and tagged for JSON
 // serialization.
 //
@@ -156,6 +171,10 @@ func ExtractQueryParameters(u *url.URL, options interface{}) {
 				}
 			case reflect.String:
 				field.SetString(param)
+			case reflect.Int:
+				if v, e := strconv.Atoi(param); e == nil {
+					field.SetInt(int64(v))
+				}
 			default:
 				panic("BackendOption introduced an unsupported kind, please handle it! " + f.kind.String())
 			}
@@ -189,6 +208,17 @@ func FormatBackendURL(backend *backuppb.StorageBackend) (u url.URL) {
 		u.Scheme = "azure"
 		u.Host = b.AzureBlobStorage.Bucket
 		u.Path = b.AzureBlobStorage.Prefix
+	case *backuppb.StorageBackend_CloudDynamic:
+		if isSFTP(b.CloudDynamic) {
+			u.Scheme = sftpScheme
+			if userName := b.CloudDynamic.Attrs[sftpAttrUser]; userName != "" {
+				u.User = url.User(userName)
+			}
+			if b.CloudDynamic.Bucket != nil {
+				u.Host = b.CloudDynamic.Bucket.Endpoint
+				u.Path = b.CloudDynamic.Bucket.Prefix
+			}
+		}
 	}
 	return
 }
@@ -0,0 +1,98 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package storage
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/pingcap/errors"
+	"golang.org/x/time/rate"
+)
+
+// withReadBandwidthLimit limits the bytes per second read from the storage by
+// all the readers together.
+type withReadBandwidthLimit struct {
+	ExternalStorage
+	limiter *rate.Limiter
+}
+
+// WithReadBandwidthLimit wraps the storage so that the files read from it,
+// including the ones being read concurrently, take at most bytesPerSec bytes
+// per second in total. It returns the storage as is if bytesPerSec is not
+// positive.
+func WithReadBandwidthLimit(inner ExternalStorage, bytesPerSec int64) ExternalStorage {
+	if bytesPerSec <= 0 {
+		return inner
+	}
+	burst := bytesPerSec
+	if burst > math.MaxInt32 {
+		burst = math.MaxInt32
+	}
+	return &withReadBandwidthLimit{
+		ExternalStorage: inner,
+		limiter:         rate.NewLimiter(rate.Limit(bytesPerSec), int(burst)),
+	}
+}
+
+// wait waits until n bytes can be read, the bytes more than the burst are
+// waited for in turn.
+func (w *withReadBandwidthLimit) wait(ctx context.Context, n int) error {
+	for n > 0 {
+		m := n
+		if m > w.limiter.Burst() {
+			m = w.limiter.Burst()
+		}
+		if err := w.limiter.WaitN(ctx, m); err != nil {
+			return errors.Trace(err)
+		}
+		n -= m
+	}
+	return nil
+}
+
+// ReadFile implements ExternalStorage.ReadFile.
+func (w *withReadBandwidthLimit) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	data, err := w.ExternalStorage.ReadFile(ctx, name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := w.wait(ctx, len(data)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Open implements ExternalStorage.Open.
+func (w *withReadBandwidthLimit) Open(ctx context.Context, path string) (ExternalFileReader, error) {
+	reader, err := w.ExternalStorage.Open(ctx, path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &rateLimitedReader{ExternalFileReader: reader, ctx: ctx, w: w}, nil
+}
+
+// WalkDirWithModTime implements ModTimeWalker.
+func (w *withReadBandwidthLimit) WalkDirWithModTime(ctx context.Context, opt *WalkOption, fn func(string, int64, time.Time) error) error {
+	return WalkDirWithModTime(ctx, w.ExternalStorage, opt, fn)
+}
+
+type rateLimitedReader struct {
+	ExternalFileReader
+	ctx context.Context
+	w   *withReadBandwidthLimit
+}
+
+// Read implements io.Reader. It reads at most the burst at a time, so that a
+// large buffer doesn't take the budget of the other readers for long.
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if burst := r.w.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := r.ExternalFileReader.Read(p)
+	if waitErr := r.w.wait(r.ctx, n); waitErr != nil {
+		return n, waitErr
+	}
+	return n, err
+}
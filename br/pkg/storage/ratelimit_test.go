@@ -0,0 +1,58 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithReadBandwidthLimit(t *testing.T) {
+	ctx := context.Background()
+	inner := NewMemStorage()
+	require.Same(t, inner, WithReadBandwidthLimit(inner, 0))
+
+	data := bytes.Repeat([]byte("0123456789"), 5000)
+	require.NoError(t, inner.WriteFile(ctx, "/a", data))
+	require.NoError(t, inner.WriteFile(ctx, "/b", data))
+	s := WithReadBandwidthLimit(inner, 50000)
+
+	// the burst is read at once, the rest of both files takes 1 second.
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, name := range []string{"/a", "/b"} {
+		name := name
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reader, err := s.Open(ctx, name)
+			require.NoError(t, err)
+			read, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, data, read)
+			require.NoError(t, reader.Close())
+		}()
+	}
+	wg.Wait()
+	require.Greater(t, time.Since(start), 900*time.Millisecond)
+
+	// the reads are canceled with the context.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := s.ReadFile(cancelCtx, "/a")
+	require.Error(t, err)
+
+	var files []string
+	err = WalkDirWithModTime(ctx, s, nil, func(path string, size int64, modTime time.Time) error {
+		files = append(files, path)
+		return nil
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"/a", "/b"}, files)
+}
@@ -260,22 +260,24 @@ func NewS3Storage( // revive:disable-line:flag-parameter
 	})
 }
 
-// auto access without ak / sk.
-func autoNewCred(qs *backuppb.S3) (cred *credentials.Credentials, err error) {
+// auto access without ak / sk. The source of the credentials is returned along
+// with them, the credentials are nil if they should be resolved by the AWS SDK.
+func autoNewCred(qs *backuppb.S3) (cred *credentials.Credentials, source CredentialsSource, err error) {
 	if qs.AccessKey != "" && qs.SecretAccessKey != "" {
-		return credentials.NewStaticCredentials(qs.AccessKey, qs.SecretAccessKey, ""), nil
+		return credentials.NewStaticCredentials(qs.AccessKey, qs.SecretAccessKey, ""), CredentialsSourceConfig, nil
 	}
 	endpoint := qs.Endpoint
 	// if endpoint is empty,return no error and run default(aws) follow.
 	if endpoint == "" {
-		return nil, nil
+		return nil, "", nil
 	}
 	// if it Contains 'aliyuncs', fetch the sts token.
 	if strings.Contains(endpoint, domainAliyun) {
-		return createOssRAMCred()
+		cred, err := createOssRAMCred()
+		return cred, CredentialsSourceInstanceMetadata, err
 	}
 	// other case ,return no error and run default(aws) follow.
-	return nil, nil
+	return nil, "", nil
 }
 
 func createOssRAMCred() (*credentials.Credentials, error) {
@@ -287,8 +289,9 @@ func createOssRAMCred() (*credentials.Credentials, error) {
 	return credentials.NewStaticCredentials(ncred.AccessKeyId, ncred.AccessKeySecret, ncred.AccessKeyStsToken), nil
 }
 
-func newS3Storage(backend *backuppb.S3, opts *ExternalStorageOptions) (obj *S3Storage, errRet error) {
-	qs := *backend
+// newS3Session creates the AWS session of the S3 backend. The source of the
+// credentials is empty if they are resolved by the AWS SDK.
+func newS3Session(qs *backuppb.S3, opts *ExternalStorageOptions) (*session.Session, CredentialsSource, error) {
 	awsConfig := aws.NewConfig().
 		WithS3ForcePathStyle(qs.ForcePathStyle).
 		WithCredentialsChainVerboseErrors(true)
@@ -304,9 +307,9 @@ func newS3Storage(backend *backuppb.S3, opts *ExternalStorageOptions) (obj *S3St
 	if opts.HTTPClient != nil {
 		awsConfig.WithHTTPClient(opts.HTTPClient)
 	}
-	cred, err := autoNewCred(&qs)
+	cred, source, err := autoNewCred(qs)
 	if err != nil {
-		return nil, errors.Trace(err)
+		return nil, "", errors.Trace(err)
 	}
 	if cred != nil {
 		awsConfig.WithCredentials(cred)
@@ -314,8 +317,20 @@ func newS3Storage(backend *backuppb.S3, opts *ExternalStorageOptions) (obj *S3St
 	// awsConfig.WithLogLevel(aws.LogDebugWithSigning)
 	awsSessionOpts := session.Options{
 		Config: *awsConfig,
+		// load `~/.aws/config` as well, so that the `credential_process` and the
+		// roles in the profiles are used without setting `AWS_SDK_LOAD_CONFIG`.
+		SharedConfigState: session.SharedConfigEnable,
 	}
 	ses, err := session.NewSessionWithOptions(awsSessionOpts)
+	if err != nil {
+		return nil, "", errors.Trace(err)
+	}
+	return ses, source, nil
+}
+
+func newS3Storage(backend *backuppb.S3, opts *ExternalStorageOptions) (obj *S3Storage, errRet error) {
+	qs := *backend
+	ses, _, err := newS3Session(&qs, opts)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -526,6 +541,13 @@ func (rs *S3Storage) FileExists(ctx context.Context, file string) (bool, error)
 // function; the second argument is the size in byte of the file determined
 // by path.
 func (rs *S3Storage) WalkDir(ctx context.Context, opt *WalkOption, fn func(string, int64) error) error {
+	return rs.WalkDirWithModTime(ctx, opt, func(path string, size int64, _ time.Time) error {
+		return fn(path, size)
+	})
+}
+
+// WalkDirWithModTime implements ModTimeWalker.
+func (rs *S3Storage) WalkDirWithModTime(ctx context.Context, opt *WalkOption, fn func(string, int64, time.Time) error) error {
 	if opt == nil {
 		opt = &WalkOption{}
 	}
@@ -581,7 +603,7 @@ func (rs *S3Storage) WalkDir(ctx context.Context, opt *WalkOption, fn func(strin
 				log.Info("this path is an empty directory and cannot be opened in S3.  Skip it", zap.String("path", path))
 				continue
 			}
-			if err = fn(path, itemSize); err != nil {
+			if err = fn(path, itemSize, aws.TimeValue(r.LastModified)); err != nil {
 				return errors.Trace(err)
 			}
 		}
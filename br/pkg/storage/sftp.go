@@ -0,0 +1,551 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package storage
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	backuppb "github.com/pingcap/kvproto/pkg/brpb"
+	"github.com/pingcap/log"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"github.com/pkg/sftp"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	sftpScheme               = "sftp"
+	sftpDefaultPort          = "22"
+	sftpDefaultMaxConns      = 4
+	sftpDialTimeout          = 30 * time.Second
+	sftpWriteBufferSize      = 1024 * 1024
+	sftpPosixRenameExtension = "posix-rename@openssh.com"
+
+	sftpAttrUser             = "user"
+	sftpAttrPassword         = "password"
+	sftpAttrPrivateKey       = "private-key"
+	sftpAttrKnownHosts       = "known-hosts"
+	sftpAttrSkipHostKeyCheck = "skip-host-key-check"
+	sftpAttrMaxConnections   = "max-connections"
+
+	sftpPasswordOption         = "sftp.password"
+	sftpPrivateKeyOption       = "sftp.private-key"
+	sftpKnownHostsOption       = "sftp.known-hosts"
+	sftpSkipHostKeyCheckOption = "sftp.skip-host-key-check"
+	sftpMaxConnectionsOption   = "sftp.max-connections"
+)
+
+// SFTPBackendOptions contains options for the SFTP storage.
+type SFTPBackendOptions struct {
+	Password         string `json:"password" toml:"password"`
+	PrivateKey       string `json:"private-key" toml:"private-key"`
+	KnownHosts       string `json:"known-hosts" toml:"known-hosts"`
+	SkipHostKeyCheck bool   `json:"skip-host-key-check" toml:"skip-host-key-check"`
+	MaxConnections   int    `json:"max-connections" toml:"max-connections"`
+}
+
+// apply builds the backend of the SFTP storage at u. The proto has no message
+// for SFTP, so it's carried as a CloudDynamic backend.
+func (options *SFTPBackendOptions) apply(u *url.URL) (*backuppb.CloudDynamic, error) {
+	if options.MaxConnections < 0 {
+		return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "invalid sftp max-connections %d", options.MaxConnections)
+	}
+	attrs := map[string]string{
+		sftpAttrPassword:   options.Password,
+		sftpAttrPrivateKey: options.PrivateKey,
+		sftpAttrKnownHosts: options.KnownHosts,
+	}
+	if u.User != nil {
+		attrs[sftpAttrUser] = u.User.Username()
+		if password, ok := u.User.Password(); ok {
+			attrs[sftpAttrPassword] = password
+		}
+	}
+	if options.SkipHostKeyCheck {
+		attrs[sftpAttrSkipHostKeyCheck] = "true"
+	}
+	if options.MaxConnections > 0 {
+		attrs[sftpAttrMaxConnections] = strconv.Itoa(options.MaxConnections)
+	}
+	for k, v := range attrs {
+		if v == "" {
+			delete(attrs, k)
+		}
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), sftpDefaultPort)
+	}
+	return &backuppb.CloudDynamic{
+		ProviderName: sftpScheme,
+		Bucket:       &backuppb.Bucket{Endpoint: host, Prefix: u.Path},
+		Attrs:        attrs,
+	}, nil
+}
+
+func defineSFTPFlags(flags *pflag.FlagSet) {
+	flags.String(sftpPasswordOption, "", "Specify the password for sftp")
+	flags.String(sftpPrivateKeyOption, "", "Specify the path of the private key for sftp, the default keys in ~/.ssh are tried if neither it nor the password is set")
+	flags.String(sftpKnownHostsOption, "", "Specify the path of the known hosts file to verify the sftp server, ~/.ssh/known_hosts by default")
+	flags.Bool(sftpSkipHostKeyCheckOption, false, "Don't verify the host key of the sftp server, it's insecure")
+	flags.Int(sftpMaxConnectionsOption, sftpDefaultMaxConns, "Specify the max number of the connections to the sftp server")
+}
+
+func hiddenSFTPFlags(flags *pflag.FlagSet) {
+	_ = flags.MarkHidden(sftpPasswordOption)
+	_ = flags.MarkHidden(sftpPrivateKeyOption)
+	_ = flags.MarkHidden(sftpKnownHostsOption)
+	_ = flags.MarkHidden(sftpSkipHostKeyCheckOption)
+	_ = flags.MarkHidden(sftpMaxConnectionsOption)
+}
+
+func (options *SFTPBackendOptions) parseFromFlags(flags *pflag.FlagSet) error {
+	var err error
+	options.Password, err = flags.GetString(sftpPasswordOption)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	options.PrivateKey, err = flags.GetString(sftpPrivateKeyOption)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	options.KnownHosts, err = flags.GetString(sftpKnownHostsOption)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	options.SkipHostKeyCheck, err = flags.GetBool(sftpSkipHostKeyCheckOption)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	options.MaxConnections, err = flags.GetInt(sftpMaxConnectionsOption)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+func isSFTP(backend *backuppb.CloudDynamic) bool {
+	return backend != nil && backend.ProviderName == sftpScheme
+}
+
+// sftpConn is a connection to the SFTP server.
+type sftpConn struct {
+	*sftp.Client
+	closer io.Closer
+
+	mu     sync.Mutex
+	broken bool
+}
+
+func (c *sftpConn) isBroken() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.broken
+}
+
+func (c *sftpConn) close() {
+	_ = c.Client.Close()
+	if c.closer != nil {
+		_ = c.closer.Close()
+	}
+}
+
+// sftpPool holds at most maxConns connections to the SFTP server. They are
+// dialed lazily and shared by the requests in turn, since every connection
+// serves the requests concurrently.
+type sftpPool struct {
+	dial     func(ctx context.Context) (*sftp.Client, io.Closer, error)
+	maxConns int
+
+	mu    sync.Mutex
+	conns []*sftpConn
+	next  int
+}
+
+func (p *sftpPool) get(ctx context.Context) (*sftpConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	alive := p.conns[:0]
+	for _, c := range p.conns {
+		if !c.isBroken() {
+			alive = append(alive, c)
+		}
+	}
+	p.conns = alive
+	if len(p.conns) < p.maxConns {
+		client, closer, err := p.dial(ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		c := &sftpConn{Client: client, closer: closer}
+		go func() {
+			err := client.Wait()
+			c.mu.Lock()
+			c.broken = true
+			c.mu.Unlock()
+			if err != nil && err != io.EOF {
+				log.Info("sftp connection is closed", zap.Error(err))
+			}
+		}()
+		p.conns = append(p.conns, c)
+		return c, nil
+	}
+	p.next = (p.next + 1) % len(p.conns)
+	return p.conns[p.next], nil
+}
+
+func (p *sftpPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, c := range p.conns {
+		c.close()
+	}
+	p.conns = nil
+}
+
+// SFTPStorage represents a directory on an SFTP server. Its URL looks like
+// "sftp://user@host:22/path", or "sftp://user@host/~/path" for a path relative
+// to the home directory. The server is verified by the known hosts, and the
+// user is authenticated by the password or the private keys.
+type SFTPStorage struct {
+	uri  string
+	base string
+	pool *sftpPool
+}
+
+func newSFTPStorage(ctx context.Context, backend *backuppb.CloudDynamic, opts *ExternalStorageOptions) (*SFTPStorage, error) {
+	if backend.Bucket == nil || backend.Bucket.Endpoint == "" {
+		return nil, errors.Annotate(berrors.ErrStorageInvalidConfig, "please specify the host for sftp")
+	}
+	attrs := backend.Attrs
+	userName := attrs[sftpAttrUser]
+	if userName == "" {
+		current, err := user.Current()
+		if err != nil {
+			return nil, errors.Annotate(berrors.ErrStorageInvalidConfig, "please specify the user for sftp")
+		}
+		userName = current.Username
+	}
+	auth, err := sftpAuthMethods(attrs[sftpAttrPassword], attrs[sftpAttrPrivateKey])
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	hostKeyCallback := ssh.InsecureIgnoreHostKey() //nolint:gosec
+	if skip, _ := strconv.ParseBool(attrs[sftpAttrSkipHostKeyCheck]); !skip {
+		knownHosts := attrs[sftpAttrKnownHosts]
+		if knownHosts == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			knownHosts = filepath.Join(home, ".ssh", "known_hosts")
+		}
+		if hostKeyCallback, err = knownhosts.New(knownHosts); err != nil {
+			return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "failed to load the known hosts of sftp: %s", err)
+		}
+	}
+	maxConns := sftpDefaultMaxConns
+	if v := attrs[sftpAttrMaxConnections]; v != "" {
+		if maxConns, err = strconv.Atoi(v); err != nil || maxConns <= 0 {
+			return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "invalid sftp max-connections %s", v)
+		}
+	}
+	if !opts.SendCredentials {
+		delete(attrs, sftpAttrPassword)
+	}
+
+	config := &ssh.ClientConfig{
+		User:            userName,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sftpDialTimeout,
+	}
+	addr := backend.Bucket.Endpoint
+	dial := func(ctx context.Context) (*sftp.Client, io.Closer, error) {
+		dialer := &net.Dialer{Timeout: sftpDialTimeout}
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+		if err != nil {
+			_ = conn.Close()
+			return nil, nil, errors.Annotatef(err, "failed to connect to the sftp server %s", addr)
+		}
+		sshClient := ssh.NewClient(sshConn, chans, reqs)
+		client, err := sftp.NewClient(sshClient)
+		if err != nil {
+			_ = sshClient.Close()
+			return nil, nil, errors.Annotatef(err, "failed to start the sftp session on %s", addr)
+		}
+		return client, sshClient, nil
+	}
+	uri := url.URL{Scheme: sftpScheme, User: url.User(userName), Host: addr, Path: backend.Bucket.Prefix}
+	s := newSFTPStorageWithDialer(uri.String(), backend.Bucket.Prefix, dial, maxConns)
+	// connect once to fail fast on the wrong config.
+	if _, err := s.pool.get(ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return s, nil
+}
+
+func newSFTPStorageWithDialer(
+	uri, base string,
+	dial func(ctx context.Context) (*sftp.Client, io.Closer, error),
+	maxConns int,
+) *SFTPStorage {
+	base = path.Clean("/" + base)
+	if base == "/~" || strings.HasPrefix(base, "/~/") {
+		base = path.Clean("." + base[2:])
+	}
+	return &SFTPStorage{
+		uri:  uri,
+		base: base,
+		pool: &sftpPool{dial: dial, maxConns: maxConns},
+	}
+}
+
+func sftpAuthMethods(password, privateKey string) ([]ssh.AuthMethod, error) {
+	var auth []ssh.AuthMethod
+	if password != "" {
+		auth = append(auth, ssh.Password(password))
+	}
+	keyFiles := []string{privateKey}
+	if privateKey == "" {
+		if password != "" {
+			return auth, nil
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		keyFiles = keyFiles[:0]
+		for _, name := range []string{"id_rsa", "id_ecdsa", "id_ed25519"} {
+			keyFiles = append(keyFiles, filepath.Join(home, ".ssh", name))
+		}
+	}
+	var signers []ssh.Signer
+	for _, keyFile := range keyFiles {
+		key, err := os.ReadFile(keyFile)
+		if os.IsNotExist(err) && privateKey == "" {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "failed to read the private key of sftp: %s", err)
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "failed to parse the private key %s of sftp: %s", keyFile, err)
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) > 0 {
+		auth = append(auth, ssh.PublicKeys(signers...))
+	}
+	if len(auth) == 0 {
+		return nil, errors.Annotate(berrors.ErrStorageInvalidConfig, "please specify the password or the private key for sftp")
+	}
+	return auth, nil
+}
+
+func (s *SFTPStorage) filePath(name string) string {
+	return path.Join(s.base, name)
+}
+
+// relPath is the reverse of filePath.
+func (s *SFTPStorage) relPath(filePath string) string {
+	if s.base == "." {
+		return filePath
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(filePath, s.base), "/")
+}
+
+// rename renames the file, and replaces newPath if it exists.
+func (*SFTPStorage) rename(c *sftpConn, oldPath, newPath string) error {
+	if _, ok := c.HasExtension(sftpPosixRenameExtension); ok {
+		return errors.Trace(c.PosixRename(oldPath, newPath))
+	}
+	if err := c.Remove(newPath); err != nil && !os.IsNotExist(err) {
+		return errors.Trace(err)
+	}
+	return errors.Trace(c.Rename(oldPath, newPath))
+}
+
+// create creates the file and the parent directories if not exist.
+func (*SFTPStorage) create(c *sftpConn, filePath string) (*sftp.File, error) {
+	if err := c.MkdirAll(path.Dir(filePath)); err != nil {
+		return nil, errors.Trace(err)
+	}
+	f, err := c.Create(filePath)
+	return f, errors.Trace(err)
+}
+
+// WriteFile writes data to a file to storage.
+func (s *SFTPStorage) WriteFile(ctx context.Context, name string, data []byte) error {
+	c, err := s.pool.get(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// write to a temporary file and rename it, so the file is never partial.
+	filePath := s.filePath(name)
+	tmpPath := filePath + ".tmp"
+	f, err := s.create(c, tmpPath)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return errors.Trace(err)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	return s.rename(c, tmpPath, filePath)
+}
+
+// ReadFile reads the file from the storage and returns the contents.
+func (s *SFTPStorage) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	c, err := s.pool.get(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	f, err := c.Open(s.filePath(name))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	//nolint: errcheck
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	return data, errors.Trace(err)
+}
+
+// FileExists checks if the file exists on the storage.
+func (s *SFTPStorage) FileExists(ctx context.Context, name string) (bool, error) {
+	c, err := s.pool.get(ctx)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	info, err := c.Stat(s.filePath(name))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return !info.IsDir(), nil
+}
+
+// DeleteFile deletes the file in the storage.
+func (s *SFTPStorage) DeleteFile(ctx context.Context, name string) error {
+	c, err := s.pool.get(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(c.Remove(s.filePath(name)))
+}
+
+// Open opens a reader of the file. It reads the file by ranges from the
+// current offset, which can be changed by Seek.
+func (s *SFTPStorage) Open(ctx context.Context, name string) (ExternalFileReader, error) {
+	c, err := s.pool.get(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	f, err := c.Open(s.filePath(name))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return f, nil
+}
+
+// WalkDir traverses all the files in a dir.
+func (s *SFTPStorage) WalkDir(ctx context.Context, opt *WalkOption, fn func(path string, size int64) error) error {
+	return s.WalkDirWithModTime(ctx, opt, func(path string, size int64, _ time.Time) error {
+		return fn(path, size)
+	})
+}
+
+// WalkDirWithModTime implements ModTimeWalker.
+func (s *SFTPStorage) WalkDirWithModTime(ctx context.Context, opt *WalkOption, fn func(string, int64, time.Time) error) error {
+	if opt == nil {
+		opt = &WalkOption{}
+	}
+	c, err := s.pool.get(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	walker := c.Walk(s.filePath(opt.SubDir))
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			// like the local storage, a missing dir has no file.
+			if os.IsNotExist(err) {
+				continue
+			}
+			return errors.Trace(err)
+		}
+		info := walker.Stat()
+		if info.IsDir() {
+			continue
+		}
+		filePath := s.relPath(walker.Path())
+		if !strings.HasPrefix(filePath, opt.ObjPrefix) {
+			continue
+		}
+		if err := fn(filePath, info.Size(), info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// URI returns the base path as a URI, without the password.
+func (s *SFTPStorage) URI() string {
+	return s.uri
+}
+
+// Create opens a file writer by path.
+func (s *SFTPStorage) Create(ctx context.Context, name string) (ExternalFileWriter, error) {
+	c, err := s.pool.get(ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	f, err := s.create(c, s.filePath(name))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	buf := bufio.NewWriterSize(f, sftpWriteBufferSize)
+	return newFlushStorageWriter(buf, buf, f), nil
+}
+
+// Rename renames the file, the new file is replaced if it exists.
+func (s *SFTPStorage) Rename(ctx context.Context, oldFileName, newFileName string) error {
+	c, err := s.pool.get(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return s.rename(c, s.filePath(oldFileName), s.filePath(newFileName))
+}
+
+// Close closes the connections to the SFTP server.
+func (s *SFTPStorage) Close() {
+	s.pool.close()
+}
@@ -0,0 +1,273 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package storage
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+type pipeConn struct {
+	io.Reader
+	io.WriteCloser
+}
+
+// dialSFTPPipe starts an SFTP server in process, and connects to it by pipes.
+func dialSFTPPipe(context.Context) (*sftp.Client, io.Closer, error) {
+	serverRead, clientWrite := io.Pipe()
+	clientRead, serverWrite := io.Pipe()
+	server, err := sftp.NewServer(pipeConn{serverRead, serverWrite})
+	if err != nil {
+		return nil, nil, err
+	}
+	go func() {
+		_ = server.Serve()
+		_ = server.Close()
+	}()
+	client, err := sftp.NewClientPipe(clientRead, clientWrite)
+	return client, nil, err
+}
+
+func TestSFTPStorage(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	var dialed int
+	var mu sync.Mutex
+	dial := func(ctx context.Context) (*sftp.Client, io.Closer, error) {
+		mu.Lock()
+		dialed++
+		mu.Unlock()
+		return dialSFTPPipe(ctx)
+	}
+	s := newSFTPStorageWithDialer("sftp://dumper@127.0.0.1:22"+dir, dir, dial, 2)
+	defer s.Close()
+
+	// write and read the files.
+	require.NoError(t, s.WriteFile(ctx, "db.t1.0.csv", []byte("1,2\n3,4\n")))
+	require.NoError(t, s.WriteFile(ctx, "sub/db.t2.0.csv", []byte("5,6\n")))
+	data, err := os.ReadFile(filepath.Join(dir, "db.t1.0.csv"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1,2\n3,4\n"), data)
+	require.NoFileExists(t, filepath.Join(dir, "db.t1.0.csv.tmp"))
+	require.NoError(t, s.WriteFile(ctx, "db.t1.0.csv", []byte("1,2\n3,4\n")))
+
+	data, err = s.ReadFile(ctx, "sub/db.t2.0.csv")
+	require.NoError(t, err)
+	require.Equal(t, []byte("5,6\n"), data)
+	_, err = s.ReadFile(ctx, "missing.csv")
+	require.Error(t, err)
+
+	exists, err := s.FileExists(ctx, "db.t1.0.csv")
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = s.FileExists(ctx, "missing.csv")
+	require.NoError(t, err)
+	require.False(t, exists)
+	exists, err = s.FileExists(ctx, "sub")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// read the file by ranges.
+	reader, err := s.Open(ctx, "db.t1.0.csv")
+	require.NoError(t, err)
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(reader, buf)
+	require.NoError(t, err)
+	require.Equal(t, "1,2", string(buf))
+	pos, err := reader.Seek(-3, io.SeekEnd)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), pos)
+	rest, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, ",4\n", string(rest))
+	_, err = reader.Seek(4, io.SeekStart)
+	require.NoError(t, err)
+	_, err = io.ReadFull(reader, buf)
+	require.NoError(t, err)
+	require.Equal(t, "3,4", string(buf))
+	require.NoError(t, reader.Close())
+
+	// walk the files.
+	var files []string
+	err = WalkDirWithModTime(ctx, s, &WalkOption{}, func(path string, size int64, modTime time.Time) error {
+		files = append(files, fmt.Sprintf("%s:%d", path, size))
+		require.False(t, modTime.IsZero())
+		return nil
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"db.t1.0.csv:8", "sub/db.t2.0.csv:4"}, files)
+	files = files[:0]
+	err = s.WalkDir(ctx, &WalkOption{SubDir: "sub", ObjPrefix: "sub/db.t2"}, func(path string, size int64) error {
+		files = append(files, path)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"sub/db.t2.0.csv"}, files)
+	err = s.WalkDir(ctx, &WalkOption{SubDir: "missing"}, func(string, int64) error {
+		t.Fatal("unexpected file")
+		return nil
+	})
+	require.NoError(t, err)
+
+	// create, rename and delete the files.
+	w, err := s.Create(ctx, "sub2/db.t3.0.csv")
+	require.NoError(t, err)
+	_, err = w.Write(ctx, []byte("7,8\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close(ctx))
+	require.NoError(t, s.Rename(ctx, "sub2/db.t3.0.csv", "db.t1.0.csv"))
+	data, err = os.ReadFile(filepath.Join(dir, "db.t1.0.csv"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("7,8\n"), data)
+	require.NoFileExists(t, filepath.Join(dir, "sub2", "db.t3.0.csv"))
+	require.NoError(t, s.DeleteFile(ctx, "db.t1.0.csv"))
+	require.NoFileExists(t, filepath.Join(dir, "db.t1.0.csv"))
+
+	// the connections are reused, and the broken ones are replaced.
+	require.Equal(t, 2, dialed)
+	c, err := s.pool.get(ctx)
+	require.NoError(t, err)
+	c.close()
+	require.Eventually(t, c.isBroken, 5*time.Second, 10*time.Millisecond)
+	for i := 0; i < 4; i++ {
+		_, err = s.FileExists(ctx, "sub/db.t2.0.csv")
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, dialed)
+}
+
+func TestSFTPStorageHomeDir(t *testing.T) {
+	s := newSFTPStorageWithDialer("", "/~/dumps/", dialSFTPPipe, 1)
+	require.Equal(t, "dumps/a.csv", s.filePath("a.csv"))
+	require.Equal(t, "a.csv", s.relPath("dumps/a.csv"))
+	s = newSFTPStorageWithDialer("", "/~", dialSFTPPipe, 1)
+	require.Equal(t, "a.csv", s.filePath("a.csv"))
+	require.Equal(t, ".a.csv", s.relPath(".a.csv"))
+	s = newSFTPStorageWithDialer("", "", dialSFTPPipe, 1)
+	require.Equal(t, "/a.csv", s.filePath("a.csv"))
+	require.Equal(t, "a.csv", s.relPath("/a.csv"))
+}
+
+// startSSHServer starts an SSH server serving SFTP, which accepts the user
+// "dumper" with the password "passwd". It returns the address and the known
+// hosts file of the server.
+func startSSHServer(t *testing.T) (string, string) {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	require.NoError(t, err)
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if meta.User() == "dumper" && string(password) == "passwd" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("password rejected for %s", meta.User())
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSHConn(conn, config)
+		}
+	}()
+
+	addr := listener.Addr().String()
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, signer.PublicKey())
+	require.NoError(t, os.WriteFile(knownHosts, []byte(line+"\n"), 0o600))
+	return addr, knownHosts
+}
+
+func serveSSHConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				// the payload of the subsystem request is the length-prefixed name.
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if ok {
+					server, err := sftp.NewServer(channel)
+					if err != nil {
+						return
+					}
+					_ = server.Serve()
+					_ = server.Close()
+				}
+			}
+		}()
+	}
+}
+
+func TestNewSFTPStorage(t *testing.T) {
+	ctx := context.Background()
+	addr, knownHosts := startSSHServer(t)
+	dir := t.TempDir()
+
+	backend, err := ParseBackend("sftp://dumper:passwd@"+addr+dir+"?known-hosts="+knownHosts, nil)
+	require.NoError(t, err)
+	s, err := New(ctx, backend, &ExternalStorageOptions{})
+	require.NoError(t, err)
+	require.IsType(t, &SFTPStorage{}, s)
+	require.Equal(t, "sftp://dumper@"+addr+dir, s.URI())
+	require.NotContains(t, backend.GetCloudDynamic().Attrs, "password")
+	defer s.(*SFTPStorage).Close()
+
+	require.NoError(t, s.WriteFile(ctx, "db.t1.0.csv", []byte("1,2\n")))
+	data, err := s.ReadFile(ctx, "db.t1.0.csv")
+	require.NoError(t, err)
+	require.Equal(t, []byte("1,2\n"), data)
+
+	// the password is wrong.
+	backend, err = ParseBackend("sftp://dumper:wrong@"+addr+dir+"?known-hosts="+knownHosts, nil)
+	require.NoError(t, err)
+	_, err = New(ctx, backend, &ExternalStorageOptions{})
+	require.ErrorContains(t, err, "unable to authenticate")
+
+	// the host is unknown.
+	emptyKnownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	require.NoError(t, os.WriteFile(emptyKnownHosts, nil, 0o600))
+	backend, err = ParseBackend("sftp://dumper:passwd@"+addr+dir+"?known-hosts="+emptyKnownHosts, nil)
+	require.NoError(t, err)
+	_, err = New(ctx, backend, &ExternalStorageOptions{})
+	require.ErrorContains(t, err, "key is unknown")
+	backend, err = ParseBackend("sftp://dumper:passwd@"+addr+dir+"?skip-host-key-check=true", nil)
+	require.NoError(t, err)
+	s, err = New(ctx, backend, &ExternalStorageOptions{})
+	require.NoError(t, err)
+	s.(*SFTPStorage).Close()
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/pingcap/errors"
 	backuppb "github.com/pingcap/kvproto/pkg/brpb"
@@ -103,6 +104,31 @@ type ExternalStorage interface {
 	Rename(ctx context.Context, oldFileName, newFileName string) error
 }
 
+// ModTimeWalker is implemented by the storages which can provide the last
+// modified time of the files while traversing a dir.
+type ModTimeWalker interface {
+	// WalkDirWithModTime is like WalkDir, and fn also receives the last
+	// modified time of the file.
+	WalkDirWithModTime(ctx context.Context, opt *WalkOption, fn func(path string, size int64, modTime time.Time) error) error
+}
+
+// WalkDirWithModTime traverses all the files in a dir of the storage with
+// their last modified time. The modified time is zero if the storage doesn't
+// implement ModTimeWalker.
+func WalkDirWithModTime(
+	ctx context.Context,
+	s ExternalStorage,
+	opt *WalkOption,
+	fn func(path string, size int64, modTime time.Time) error,
+) error {
+	if w, ok := s.(ModTimeWalker); ok {
+		return w.WalkDirWithModTime(ctx, opt, fn)
+	}
+	return s.WalkDir(ctx, opt, func(path string, size int64) error {
+		return fn(path, size, time.Time{})
+	})
+}
+
 // ExternalFileReader represents the streaming external file reader.
 type ExternalFileReader interface {
 	io.ReadCloser
@@ -135,6 +161,14 @@ type ExternalStorageOptions struct {
 	// CheckPermissions check the given permission in New() function.
 	// make sure we can access the storage correctly before execute tasks.
 	CheckPermissions []Permission
+
+	// KeyRing wraps the data keys of the client-side encryption. The objects are
+	// written and read as plaintext if it's nil.
+	KeyRing KeyRing
+
+	// Azure selects how to access Azure Blob storage without the account key.
+	// It takes precedence over the credentials in the storage backend.
+	Azure *AzureCredentialOptions
 }
 
 // Create creates ExternalStorage.
@@ -149,6 +183,14 @@ func Create(ctx context.Context, backend *backuppb.StorageBackend, sendCreds boo
 
 // New creates an ExternalStorage with options.
 func New(ctx context.Context, backend *backuppb.StorageBackend, opts *ExternalStorageOptions) (ExternalStorage, error) {
+	s, err := newStorage(ctx, backend, opts)
+	if err != nil || opts.KeyRing == nil {
+		return s, err
+	}
+	return WithEncryption(s, opts.KeyRing), nil
+}
+
+func newStorage(ctx context.Context, backend *backuppb.StorageBackend, opts *ExternalStorageOptions) (ExternalStorage, error) {
 	switch backend := backend.Backend.(type) {
 	case *backuppb.StorageBackend_Local:
 		if backend.Local == nil {
@@ -159,6 +201,9 @@ func New(ctx context.Context, backend *backuppb.StorageBackend, opts *ExternalSt
 		if backend.Hdfs == nil {
 			return nil, errors.Annotate(berrors.ErrStorageInvalidConfig, "hdfs config not found")
 		}
+		if isWebHDFS(backend.Hdfs.Remote) {
+			return NewWebHDFSStorage(backend.Hdfs.Remote, opts.HTTPClient)
+		}
 		return NewHDFSStorage(backend.Hdfs.Remote), nil
 	case *backuppb.StorageBackend_S3:
 		if backend.S3 == nil {
@@ -174,6 +219,11 @@ func New(ctx context.Context, backend *backuppb.StorageBackend, opts *ExternalSt
 		return newGCSStorage(ctx, backend.Gcs, opts)
 	case *backuppb.StorageBackend_AzureBlobStorage:
 		return newAzureBlobStorage(ctx, backend.AzureBlobStorage, opts)
+	case *backuppb.StorageBackend_CloudDynamic:
+		if !isSFTP(backend.CloudDynamic) {
+			return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "cloud dynamic storage is not supported yet")
+		}
+		return newSFTPStorage(ctx, backend.CloudDynamic, opts)
 	default:
 		return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "storage %T is not supported yet", backend)
 	}
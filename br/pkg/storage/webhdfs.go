@@ -0,0 +1,461 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	berrors "github.com/pingcap/tidb/br/pkg/errors"
+	"github.com/pingcap/tidb/br/pkg/logutil"
+)
+
+const (
+	webhdfsScheme       = "webhdfs"
+	secureWebhdfsScheme = "swebhdfs"
+	webhdfsPathPrefix   = "/webhdfs/v1"
+	webhdfsChunkSize    = 8 * 1024 * 1024
+	webhdfsTypeDir      = "DIRECTORY"
+)
+
+// WebHDFSStorage represents an HDFS accessed by the WebHDFS REST API. Its URL
+// looks like "webhdfs://namenode:9870/path", or "swebhdfs://..." for HTTPS.
+// The user is taken from the URL or the HADOOP_USER_NAME environment variable.
+type WebHDFSStorage struct {
+	remote   string
+	endpoint string
+	base     string
+	user     string
+	client   *http.Client
+	// noRedirectClient returns the redirections to the data nodes, since the
+	// body of the CREATE and APPEND requests should be sent to them only.
+	noRedirectClient *http.Client
+}
+
+// webhdfsFileStatus is the FileStatus object of the WebHDFS API.
+type webhdfsFileStatus struct {
+	PathSuffix       string `json:"pathSuffix"`
+	Type             string `json:"type"`
+	Length           int64  `json:"length"`
+	ModificationTime int64  `json:"modificationTime"`
+}
+
+// webhdfsRemoteException is the error returned by the WebHDFS API.
+type webhdfsRemoteException struct {
+	RemoteException struct {
+		Exception string `json:"exception"`
+		Message   string `json:"message"`
+	} `json:"RemoteException"`
+}
+
+func isWebHDFS(remote string) bool {
+	return strings.HasPrefix(remote, webhdfsScheme+"://") || strings.HasPrefix(remote, secureWebhdfsScheme+"://")
+}
+
+// NewWebHDFSStorage creates a new WebHDFS storage.
+func NewWebHDFSStorage(remote string, client *http.Client) (*WebHDFSStorage, error) {
+	u, err := ParseRawURL(remote)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var httpScheme string
+	switch u.Scheme {
+	case webhdfsScheme:
+		httpScheme = "http"
+	case secureWebhdfsScheme:
+		httpScheme = "https"
+	default:
+		return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "invalid webhdfs url %s", remote)
+	}
+	if u.Host == "" {
+		return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "please specify the name node for webhdfs in %s", remote)
+	}
+	user := os.Getenv("HADOOP_USER_NAME")
+	if u.User != nil {
+		user = u.User.Username()
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	noRedirectClient := *client
+	noRedirectClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &WebHDFSStorage{
+		remote:           remote,
+		endpoint:         httpScheme + "://" + u.Host,
+		base:             "/" + strings.Trim(u.Path, "/"),
+		user:             user,
+		client:           client,
+		noRedirectClient: &noRedirectClient,
+	}, nil
+}
+
+func (s *WebHDFSStorage) filePath(name string) string {
+	return path.Join(s.base, name)
+}
+
+func (s *WebHDFSStorage) opURL(filePath string, op string, params url.Values) string {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("op", op)
+	if s.user != "" {
+		params.Set("user.name", s.user)
+	}
+	return s.endpoint + webhdfsPathPrefix + (&url.URL{Path: filePath}).EscapedPath() + "?" + params.Encode()
+}
+
+func (s *WebHDFSStorage) request(
+	ctx context.Context,
+	client *http.Client,
+	method, target string,
+	body io.Reader,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	resp, err := client.Do(req)
+	return resp, errors.Trace(err)
+}
+
+// call sends a request to the name node, and decodes the JSON response into
+// result if it's not nil.
+func (s *WebHDFSStorage) call(ctx context.Context, method, filePath, op string, params url.Values, result interface{}) error {
+	resp, err := s.request(ctx, s.client, method, s.opURL(filePath, op, params), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return webhdfsError(resp, op, filePath)
+	}
+	if result == nil {
+		return nil
+	}
+	return errors.Trace(json.NewDecoder(resp.Body).Decode(result))
+}
+
+// upload sends data to the data node which the name node redirects to.
+func (s *WebHDFSStorage) upload(ctx context.Context, method, filePath, op string, params url.Values, data []byte, expected int) error {
+	resp, err := s.request(ctx, s.noRedirectClient, method, s.opURL(filePath, op, params), nil)
+	if err != nil {
+		return err
+	}
+	location := resp.Header.Get("Location")
+	if resp.StatusCode != http.StatusTemporaryRedirect || location == "" {
+		defer resp.Body.Close()
+		return webhdfsError(resp, op, filePath)
+	}
+	_ = resp.Body.Close()
+
+	resp, err = s.request(ctx, s.client, method, location, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != expected {
+		return webhdfsError(resp, op, filePath)
+	}
+	return nil
+}
+
+func webhdfsError(resp *http.Response, op string, filePath string) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var e webhdfsRemoteException
+	if err := json.Unmarshal(body, &e); err == nil && e.RemoteException.Exception != "" {
+		return errors.Annotatef(berrors.ErrStorageUnknown, "webhdfs %s %s failed with status %d: %s: %s",
+			op, filePath, resp.StatusCode, e.RemoteException.Exception, e.RemoteException.Message)
+	}
+	return errors.Annotatef(berrors.ErrStorageUnknown, "webhdfs %s %s failed with status %d: %s",
+		op, filePath, resp.StatusCode, string(body))
+}
+
+// getFileStatus returns the status of the file, or nil if it doesn't exist.
+func (s *WebHDFSStorage) getFileStatus(ctx context.Context, filePath string) (*webhdfsFileStatus, error) {
+	resp, err := s.request(ctx, s.client, http.MethodGet, s.opURL(filePath, "GETFILESTATUS", nil), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, webhdfsError(resp, "GETFILESTATUS", filePath)
+	}
+	var result struct {
+		FileStatus webhdfsFileStatus `json:"FileStatus"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &result.FileStatus, nil
+}
+
+// WriteFile writes a complete file to storage, similar to os.WriteFile
+func (s *WebHDFSStorage) WriteFile(ctx context.Context, name string, data []byte) error {
+	params := url.Values{"overwrite": []string{"true"}}
+	return s.upload(ctx, http.MethodPut, s.filePath(name), "CREATE", params, data, http.StatusCreated)
+}
+
+// ReadFile reads a complete file from storage, similar to os.ReadFile
+func (s *WebHDFSStorage) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	reader, err := s.open(ctx, s.filePath(name), 0)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	return data, errors.Trace(err)
+}
+
+// FileExists return true if file exists
+func (s *WebHDFSStorage) FileExists(ctx context.Context, name string) (bool, error) {
+	status, err := s.getFileStatus(ctx, s.filePath(name))
+	if err != nil {
+		return false, err
+	}
+	return status != nil && status.Type != webhdfsTypeDir, nil
+}
+
+// DeleteFile delete the file in storage
+func (s *WebHDFSStorage) DeleteFile(ctx context.Context, name string) error {
+	return s.call(ctx, http.MethodDelete, s.filePath(name), "DELETE", nil, nil)
+}
+
+// open opens the file from offset.
+func (s *WebHDFSStorage) open(ctx context.Context, filePath string, offset int64) (io.ReadCloser, error) {
+	params := url.Values{"offset": []string{strconv.FormatInt(offset, 10)}}
+	resp, err := s.request(ctx, s.client, http.MethodGet, s.opURL(filePath, "OPEN", params), nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, webhdfsError(resp, "OPEN", filePath)
+	}
+	return resp.Body, nil
+}
+
+// Open a Reader by file path. path is relative path to storage base path
+func (s *WebHDFSStorage) Open(ctx context.Context, name string) (ExternalFileReader, error) {
+	filePath := s.filePath(name)
+	status, err := s.getFileStatus(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if status == nil || status.Type == webhdfsTypeDir {
+		return nil, errors.Annotatef(berrors.ErrStorageInvalidConfig, "webhdfs file %s not found", filePath)
+	}
+	return &webhdfsObjectReader{ctx: ctx, storage: s, filePath: filePath, size: status.Length}, nil
+}
+
+// WalkDir traverse all the files in a dir.
+//
+// fn is the function called for each regular file visited by WalkDir.
+// The argument `path` is the file path that can be used in `Open`
+// function; the argument `size` is the size in byte of the file determined
+// by path.
+func (s *WebHDFSStorage) WalkDir(ctx context.Context, opt *WalkOption, fn func(path string, size int64) error) error {
+	return s.WalkDirWithModTime(ctx, opt, func(path string, size int64, _ time.Time) error {
+		return fn(path, size)
+	})
+}
+
+// WalkDirWithModTime implements ModTimeWalker.
+func (s *WebHDFSStorage) WalkDirWithModTime(ctx context.Context, opt *WalkOption, fn func(string, int64, time.Time) error) error {
+	if opt == nil {
+		opt = &WalkOption{}
+	}
+	root := s.filePath(opt.SubDir)
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		var result struct {
+			FileStatuses struct {
+				FileStatus []webhdfsFileStatus `json:"FileStatus"`
+			} `json:"FileStatuses"`
+		}
+		resp, err := s.request(ctx, s.client, http.MethodGet, s.opURL(dir, "LISTSTATUS", nil), nil)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			// if path not exists, we should return nil to continue.
+			_ = resp.Body.Close()
+			return nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+			return webhdfsError(resp, "LISTSTATUS", dir)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		_ = resp.Body.Close()
+		if err != nil {
+			return errors.Trace(err)
+		}
+
+		for _, status := range result.FileStatuses.FileStatus {
+			filePath := path.Join(dir, status.PathSuffix)
+			if status.Type == webhdfsTypeDir {
+				if err := walk(filePath); err != nil {
+					return err
+				}
+				continue
+			}
+			if !strings.HasPrefix(strings.TrimPrefix(strings.TrimPrefix(filePath, root), "/"), opt.ObjPrefix) {
+				continue
+			}
+			name := strings.TrimPrefix(strings.TrimPrefix(filePath, s.base), "/")
+			if err := fn(name, status.Length, time.UnixMilli(status.ModificationTime)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(root)
+}
+
+// URI returns the base path as a URI
+func (s *WebHDFSStorage) URI() string {
+	return s.remote
+}
+
+// Create opens a file writer by path. path is relative path to storage base path
+func (s *WebHDFSStorage) Create(_ context.Context, name string) (ExternalFileWriter, error) {
+	uploader := &webhdfsUploader{storage: s, filePath: s.filePath(name)}
+	return newBufferedWriter(uploader, webhdfsChunkSize, NoCompression), nil
+}
+
+// Rename a file name from oldFileName to newFileName.
+func (s *WebHDFSStorage) Rename(ctx context.Context, oldFileName, newFileName string) error {
+	// HDFS doesn't overwrite the destination like os.Rename does.
+	if err := s.DeleteFile(ctx, newFileName); err != nil {
+		return err
+	}
+	var result struct {
+		Boolean bool `json:"boolean"`
+	}
+	params := url.Values{"destination": []string{s.filePath(newFileName)}}
+	if err := s.call(ctx, http.MethodPut, s.filePath(oldFileName), "RENAME", params, &result); err != nil {
+		return err
+	}
+	if !result.Boolean {
+		return errors.Annotatef(berrors.ErrStorageUnknown, "webhdfs failed to rename %s to %s", oldFileName, newFileName)
+	}
+	return nil
+}
+
+// webhdfsUploader creates the file with the first chunk and appends the
+// following chunks to it.
+type webhdfsUploader struct {
+	storage  *WebHDFSStorage
+	filePath string
+	created  bool
+}
+
+// Write implements the ExternalFileWriter interface.
+func (u *webhdfsUploader) Write(ctx context.Context, p []byte) (int, error) {
+	var err error
+	if u.created {
+		err = u.storage.upload(ctx, http.MethodPost, u.filePath, "APPEND", nil, p, http.StatusOK)
+	} else {
+		params := url.Values{"overwrite": []string{"true"}}
+		err = u.storage.upload(ctx, http.MethodPut, u.filePath, "CREATE", params, p, http.StatusCreated)
+	}
+	if err != nil {
+		return 0, err
+	}
+	u.created = true
+	return len(p), nil
+}
+
+// Close implements the ExternalFileWriter interface.
+func (u *webhdfsUploader) Close(ctx context.Context) error {
+	if u.created {
+		return nil
+	}
+	// create the empty file.
+	_, err := u.Write(ctx, nil)
+	return err
+}
+
+// webhdfsObjectReader reads the file from the position by the OPEN operation,
+// and reopens it after seeking.
+type webhdfsObjectReader struct {
+	ctx      context.Context
+	storage  *WebHDFSStorage
+	filePath string
+	reader   io.ReadCloser
+	pos      int64
+	size     int64
+}
+
+// Read implement the io.Reader interface.
+func (r *webhdfsObjectReader) Read(p []byte) (n int, err error) {
+	if r.pos >= r.size {
+		return 0, io.EOF
+	}
+	if r.reader == nil {
+		r.reader, err = r.storage.open(r.ctx, r.filePath, r.pos)
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err = r.reader.Read(p)
+	r.pos += int64(n)
+	return n, err
+}
+
+// Close implement the io.Closer interface.
+func (r *webhdfsObjectReader) Close() error {
+	if r.reader == nil {
+		return nil
+	}
+	return r.reader.Close()
+}
+
+// Seek implement the io.Seeker interface.
+func (r *webhdfsObjectReader) Seek(offset int64, whence int) (int64, error) {
+	var realOffset int64
+	switch whence {
+	case io.SeekStart:
+		realOffset = offset
+	case io.SeekCurrent:
+		realOffset = r.pos + offset
+	case io.SeekEnd:
+		realOffset = r.size + offset
+	default:
+		return 0, errors.Annotatef(berrors.ErrStorageUnknown, "Seek: invalid whence '%d'", whence)
+	}
+	if realOffset < 0 {
+		return 0, errors.Annotatef(berrors.ErrStorageUnknown, "Seek in '%s': invalid offset to seek '%d'.", r.filePath, realOffset)
+	}
+	if realOffset == r.pos {
+		return realOffset, nil
+	}
+	if r.reader != nil {
+		if err := r.reader.Close(); err != nil {
+			log.L().Warn("close webhdfs reader failed, will ignore this error", logutil.ShortError(err))
+		}
+		r.reader = nil
+	}
+	r.pos = realOffset
+	return realOffset, nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWebHDFS serves the WebHDFS API with the files in memory. It's both the
+// name node and the data node.
+type fakeWebHDFS struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	users []string
+	url   string
+}
+
+func (f *fakeWebHDFS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := strings.TrimPrefix(r.URL.Path, webhdfsPathPrefix)
+	q := r.URL.Query()
+	f.users = append(f.users, q.Get("user.name"))
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"RemoteException":{"exception":"FileNotFoundException","message":"File %s does not exist."}}`, p)
+	}
+	switch op := q.Get("op"); op {
+	case "CREATE", "APPEND":
+		if q.Get("datanode") == "" {
+			w.Header().Set("Location", f.url+r.URL.Path+"?"+r.URL.RawQuery+"&datanode=true")
+			w.WriteHeader(http.StatusTemporaryRedirect)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		if op == "CREATE" {
+			f.files[p] = data
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		if _, ok := f.files[p]; !ok {
+			notFound()
+			return
+		}
+		f.files[p] = append(f.files[p], data...)
+	case "OPEN":
+		data, ok := f.files[p]
+		if !ok {
+			notFound()
+			return
+		}
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		_, _ = w.Write(data[offset:])
+	case "GETFILESTATUS":
+		if data, ok := f.files[p]; ok {
+			fmt.Fprintf(w, `{"FileStatus":{"pathSuffix":"","type":"FILE","length":%d,"modificationTime":1660000000000}}`, len(data))
+			return
+		}
+		for name := range f.files {
+			if strings.HasPrefix(name, p+"/") {
+				fmt.Fprint(w, `{"FileStatus":{"pathSuffix":"","type":"DIRECTORY","length":0}}`)
+				return
+			}
+		}
+		notFound()
+	case "LISTSTATUS":
+		entries := map[string]string{}
+		for name, data := range f.files {
+			if !strings.HasPrefix(name, p+"/") {
+				continue
+			}
+			suffix := strings.TrimPrefix(name, p+"/")
+			if i := strings.Index(suffix, "/"); i >= 0 {
+				entries[suffix[:i]] = fmt.Sprintf(`{"pathSuffix":"%s","type":"DIRECTORY","length":0}`, suffix[:i])
+			} else {
+				entries[suffix] = fmt.Sprintf(`{"pathSuffix":"%s","type":"FILE","length":%d,"modificationTime":1660000000000}`, suffix, len(data))
+			}
+		}
+		if len(entries) == 0 {
+			notFound()
+			return
+		}
+		names := make([]string, 0, len(entries))
+		for name := range entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		statuses := make([]string, 0, len(names))
+		for _, name := range names {
+			statuses = append(statuses, entries[name])
+		}
+		fmt.Fprintf(w, `{"FileStatuses":{"FileStatus":[%s]}}`, strings.Join(statuses, ","))
+	case "DELETE":
+		_, ok := f.files[p]
+		delete(f.files, p)
+		fmt.Fprintf(w, `{"boolean":%t}`, ok)
+	case "RENAME":
+		data, ok := f.files[p]
+		dest := q.Get("destination")
+		if _, exists := f.files[dest]; !ok || exists {
+			fmt.Fprint(w, `{"boolean":false}`)
+			return
+		}
+		delete(f.files, p)
+		f.files[dest] = data
+		fmt.Fprint(w, `{"boolean":true}`)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestWebHDFSStorage(t *testing.T) {
+	fake := &fakeWebHDFS{files: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	fake.url = server.URL
+
+	ctx := context.Background()
+	remote := "webhdfs://hadoop@" + strings.TrimPrefix(server.URL, "http://") + "/dumps"
+	backend, err := ParseBackend(remote, nil)
+	require.NoError(t, err)
+	s, err := New(ctx, backend, &ExternalStorageOptions{})
+	require.NoError(t, err)
+	require.IsType(t, &WebHDFSStorage{}, s)
+	require.Equal(t, remote, s.URI())
+
+	// write and read the files.
+	require.NoError(t, s.WriteFile(ctx, "db.t1.0.csv", []byte("1,2\n3,4\n")))
+	require.NoError(t, s.WriteFile(ctx, "sub/db.t2.0.csv", []byte("5,6\n")))
+	require.Equal(t, []byte("1,2\n3,4\n"), fake.files["/dumps/db.t1.0.csv"])
+	require.Equal(t, "hadoop", fake.users[0])
+
+	data, err := s.ReadFile(ctx, "sub/db.t2.0.csv")
+	require.NoError(t, err)
+	require.Equal(t, []byte("5,6\n"), data)
+	_, err = s.ReadFile(ctx, "missing.csv")
+	require.ErrorContains(t, err, "FileNotFoundException")
+
+	exists, err := s.FileExists(ctx, "db.t1.0.csv")
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = s.FileExists(ctx, "missing.csv")
+	require.NoError(t, err)
+	require.False(t, exists)
+	exists, err = s.FileExists(ctx, "sub")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// open and seek the file.
+	reader, err := s.Open(ctx, "db.t1.0.csv")
+	require.NoError(t, err)
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(reader, buf)
+	require.NoError(t, err)
+	require.Equal(t, "1,2", string(buf))
+	pos, err := reader.Seek(-3, io.SeekEnd)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), pos)
+	rest, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, ",4\n", string(rest))
+	_, err = reader.Seek(4, io.SeekStart)
+	require.NoError(t, err)
+	_, err = io.ReadFull(reader, buf)
+	require.NoError(t, err)
+	require.Equal(t, "3,4", string(buf))
+	require.NoError(t, reader.Close())
+	_, err = s.Open(ctx, "missing.csv")
+	require.Error(t, err)
+
+	// walk the files.
+	var files []string
+	err = WalkDirWithModTime(ctx, s, &WalkOption{}, func(path string, size int64, modTime time.Time) error {
+		files = append(files, fmt.Sprintf("%s:%d", path, size))
+		require.True(t, time.UnixMilli(1660000000000).Equal(modTime))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"db.t1.0.csv:8", "sub/db.t2.0.csv:4"}, files)
+	files = files[:0]
+	err = s.WalkDir(ctx, &WalkOption{SubDir: "sub", ObjPrefix: "db.t2"}, func(path string, size int64) error {
+		files = append(files, path)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"sub/db.t2.0.csv"}, files)
+	err = s.WalkDir(ctx, &WalkOption{SubDir: "missing"}, func(string, int64) error {
+		t.Fatal("unexpected file")
+		return nil
+	})
+	require.NoError(t, err)
+
+	// create the file by chunks.
+	w, err := s.Create(ctx, "db.t3.0.csv")
+	require.NoError(t, err)
+	_, err = w.Write(ctx, []byte("7,8\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close(ctx))
+	require.Equal(t, []byte("7,8\n"), fake.files["/dumps/db.t3.0.csv"])
+	uploader := &webhdfsUploader{storage: s.(*WebHDFSStorage), filePath: "/dumps/db.t4.0.csv"}
+	for _, chunk := range []string{"a", "b", "c"} {
+		_, err = uploader.Write(ctx, []byte(chunk))
+		require.NoError(t, err)
+	}
+	require.NoError(t, uploader.Close(ctx))
+	require.Equal(t, []byte("abc"), fake.files["/dumps/db.t4.0.csv"])
+
+	// rename and delete the files.
+	require.NoError(t, s.Rename(ctx, "db.t3.0.csv", "db.t1.0.csv"))
+	require.Equal(t, []byte("7,8\n"), fake.files["/dumps/db.t1.0.csv"])
+	require.NotContains(t, fake.files, "/dumps/db.t3.0.csv")
+	require.NoError(t, s.DeleteFile(ctx, "db.t1.0.csv"))
+	require.NotContains(t, fake.files, "/dumps/db.t1.0.csv")
+}
+
+func TestNewWebHDFSStorage(t *testing.T) {
+	t.Setenv("HADOOP_USER_NAME", "env-user")
+	s, err := NewWebHDFSStorage("swebhdfs://namenode:9871/a/b/", nil)
+	require.NoError(t, err)
+	require.Equal(t, "https://namenode:9871", s.endpoint)
+	require.Equal(t, "/a/b", s.base)
+	require.Equal(t, "env-user", s.user)
+	require.Equal(t, "https://namenode:9871/webhdfs/v1/a/b/c%20d.csv?op=OPEN&user.name=env-user",
+		s.opURL(s.filePath("c d.csv"), "OPEN", nil))
+
+	_, err = NewWebHDFSStorage("webhdfs:///a", nil)
+	require.ErrorContains(t, err, "please specify the name node")
+}
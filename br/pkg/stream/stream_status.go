@@ -44,6 +44,8 @@ type TaskStatus struct {
 	QPS float64
 	// Last error reported by the store.
 	LastErrors map[uint64]backuppb.StreamBackupError
+	// Ingests are the ranges ingested by the physical import, which aren't captured by the task.
+	Ingests []IngestRecord
 }
 
 type TaskPrinter interface {
@@ -145,6 +147,10 @@ func (p *printByTable) AddTask(task TaskStatus) {
 		table.Add(fmt.Sprintf("error-happen-at[store=%d]", store), formatTS(oracle.ComposeTS(int64(e.HappenAt), 0)))
 		table.Add(fmt.Sprintf("error-message[store=%d]", store), e.ErrorMessage)
 	}
+	for _, r := range task.Ingests {
+		table.Add(fmt.Sprintf("ingest[%s]", r.ID), color.YellowString("not captured, need a full backup after %s",
+			FormatDate(oracle.GetTimeFromTS(r.CommitTS))))
+	}
 	p.pendingTables = append(p.pendingTables, table)
 }
 
@@ -200,6 +206,7 @@ func (p *printByJSON) PrintTasks() {
 		CheckpointTS uint64           `json:"checkpoint"`
 		EstQPS       float64          `json:"estimate_qps"`
 		LastErrors   []storeLastError `json:"last_errors"`
+		Ingests      []IngestRecord   `json:"ingests,omitempty"`
 	}
 	taskToJSON := func(t TaskStatus) jsonTask {
 		s := storage.FormatBackendURL(t.Info.GetStorage())
@@ -229,6 +236,7 @@ func (p *printByJSON) PrintTasks() {
 			CheckpointTS: t.globalCheckpoint,
 			EstQPS:       t.QPS,
 			LastErrors:   se,
+			Ingests:      t.Ingests,
 		}
 	}
 	mustMarshal := func(i interface{}) string {
@@ -366,6 +374,10 @@ func (ctl *StatusController) fillTask(ctx context.Context, task Task) (TaskStatu
 		return s, err
 	}
 
+	if s.Ingests, err = task.IngestRecords(ctx); err != nil {
+		return s, err
+	}
+
 	s.QPS, err = MaybeQPS(ctx, ctl.mgr)
 	if err != nil {
 		return s, errors.Annotatef(err, "failed to get QPS of task %s", s.Info.Name)
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"encoding/json"
 	"strconv"
 	"strings"
 
@@ -163,6 +164,7 @@ func (c *MetaDataClient) DeleteTask(ctx context.Context, taskName string) error
 			clientv3.OpDelete(CheckPointsOf(taskName), clientv3.WithPrefix()),
 			clientv3.OpDelete(Pause(taskName)),
 			clientv3.OpDelete(LastErrorPrefixOf(taskName), clientv3.WithPrefix()),
+			clientv3.OpDelete(IngestsOf(taskName), clientv3.WithPrefix()),
 		).
 		Commit()
 	if err != nil {
@@ -171,6 +173,19 @@ func (c *MetaDataClient) DeleteTask(ctx context.Context, taskName string) error
 	return nil
 }
 
+// PutIngestRecord records the data ingested into the ranges of the task.
+func (c *MetaDataClient) PutIngestRecord(ctx context.Context, taskName string, record IngestRecord) error {
+	data, err := json.Marshal(&record)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = c.KV.Put(ctx, IngestKeyOf(taskName, record.ID), string(data))
+	if err != nil {
+		return errors.Annotatef(err, "failed to put the ingest record %s of task %s", record.ID, taskName)
+	}
+	return nil
+}
+
 func (c *MetaDataClient) PauseTask(ctx context.Context, taskName string) error {
 	_, err := c.KV.Put(ctx, Pause(taskName), "")
 	if err != nil {
@@ -187,6 +202,62 @@ func (c *MetaDataClient) ResumeTask(ctx context.Context, taskName string) error
 	return nil
 }
 
+// UpdateTaskFilter replaces the table filter and the observed ranges of a paused task.
+// The task is re-registered from its global checkpoint with the new ranges once it is
+// resumed, so the change takes effect at the checkpoint. It fails if the task has been
+// resumed or modified since it was fetched.
+func (c *MetaDataClient) UpdateTaskFilter(ctx context.Context, task *Task, tableFilter []string, ranges Ranges) error {
+	name := task.Info.Name
+	oldData, err := task.Info.Marshal()
+	if err != nil {
+		return errors.Annotatef(err, "failed to marshal task %s", name)
+	}
+	oldRanges, err := task.Ranges(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	newInfo := task.Info
+	newInfo.TableFilter = tableFilter
+	data, err := newInfo.Marshal()
+	if err != nil {
+		return errors.Annotatef(err, "failed to marshal task %s", name)
+	}
+
+	ops := make([]clientv3.Op, 0, 1+len(oldRanges)+len(ranges))
+	ops = append(ops, clientv3.OpPut(TaskOf(name), string(data)))
+	newStartKeys := make(map[string]struct{}, len(ranges))
+	for _, r := range ranges {
+		key := RangeKeyOf(name, r.StartKey)
+		newStartKeys[key] = struct{}{}
+		ops = append(ops, clientv3.OpPut(key, string(r.EndKey)))
+	}
+	// etcd rejects the transactions putting the keys inside a deleted range, so the
+	// stale ranges are deleted one by one.
+	for _, r := range oldRanges {
+		key := RangeKeyOf(name, r.StartKey)
+		if _, ok := newStartKeys[key]; !ok {
+			ops = append(ops, clientv3.OpDelete(key))
+		}
+	}
+
+	resp, err := c.KV.Txn(ctx).
+		If(
+			clientv3.Compare(clientv3.Value(TaskOf(name)), "=", string(oldData)),
+			clientv3.Compare(clientv3.CreateRevision(Pause(name)), ">", 0),
+		).
+		Then(ops...).
+		Commit()
+	if err != nil {
+		return errors.Annotatef(err, "failed to commit the change for task %s", name)
+	}
+	if !resp.Succeeded {
+		return errors.Annotatef(berrors.ErrPiTRInvalidTaskInfo,
+			"the task %s has been resumed or modified during updating the table filter", name)
+	}
+	task.Info = newInfo
+	return nil
+}
+
 func (c *MetaDataClient) CleanLastErrorOfTask(ctx context.Context, taskName string) error {
 	_, err := c.KV.Delete(ctx, LastErrorPrefixOf(taskName), clientv3.WithPrefix())
 	if err != nil {
@@ -452,3 +523,20 @@ func (t *Task) LastError(ctx context.Context) (map[uint64]backuppb.StreamBackupE
 	}
 	return storeToError, nil
 }
+
+// IngestRecords fetches the records of the data ingested into the ranges of the task.
+func (t *Task) IngestRecords(ctx context.Context) ([]IngestRecord, error) {
+	kvs, err := scanEtcdPrefix(t.cli.Client, IngestsOf(t.Info.Name)).AllPages(ctx, 64)
+	if err != nil {
+		return nil, errors.Annotatef(err, "failed to fetch the ingest records of task %s", t.Info.Name)
+	}
+	records := make([]IngestRecord, 0, len(kvs))
+	for _, kvp := range kvs {
+		var record IngestRecord
+		if err := json.Unmarshal(kvp.Value, &record); err != nil {
+			return nil, errors.Annotatef(err, "failed to parse the ingest record %s", kvp.Key)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -141,6 +141,8 @@ func TestIntegration(t *testing.T) {
 	t.Run("testGetStorageCheckpoint", func(t *testing.T) { testGetStorageCheckpoint(t, metaCli, etcd) })
 	t.Run("TestStreamListening", func(t *testing.T) { testStreamListening(t, streamhelper.AdvancerExt{MetaDataClient: metaCli}) })
 	t.Run("TestStreamCheckpoint", func(t *testing.T) { testStreamCheckpoint(t, streamhelper.AdvancerExt{MetaDataClient: metaCli}) })
+	t.Run("TestUpdateTaskFilter", func(t *testing.T) { testUpdateTaskFilter(t, metaCli, etcd) })
+	t.Run("TestIngestRecords", func(t *testing.T) { testIngestRecords(t, metaCli, etcd) })
 }
 
 func TestChecking(t *testing.T) {
@@ -208,6 +210,62 @@ func testBasic(t *testing.T, metaCli streamhelper.MetaDataClient, etcd *embed.Et
 	rangeIsEmpty(t, []byte(streamhelper.RangesOf(taskName)), etcd)
 }
 
+func testUpdateTaskFilter(t *testing.T, metaCli streamhelper.MetaDataClient, etcd *embed.Etcd) {
+	ctx := context.Background()
+	taskName := "filtered_tables"
+	require.NoError(t, metaCli.PutTask(ctx, simpleTask(taskName, 3)))
+	defer func() {
+		require.NoError(t, metaCli.DeleteTask(ctx, taskName))
+	}()
+
+	task, err := metaCli.GetTask(ctx, taskName)
+	require.NoError(t, err)
+	newRanges := simpleRanges(4)[1:]
+	// the running task can't be updated.
+	err = metaCli.UpdateTaskFilter(ctx, task, []string{"*.*", "!test.scratch_*"}, newRanges)
+	require.ErrorIs(t, errors.Cause(err), berrors.ErrPiTRInvalidTaskInfo)
+
+	require.NoError(t, task.Pause(ctx))
+	require.NoError(t, metaCli.UpdateTaskFilter(ctx, task, []string{"*.*", "!test.scratch_*"}, newRanges))
+	remoteTask, err := metaCli.GetTask(ctx, taskName)
+	require.NoError(t, err)
+	require.Equal(t, []string{"*.*", "!test.scratch_*"}, remoteTask.Info.TableFilter)
+	rs, err := remoteTask.Ranges(ctx)
+	require.NoError(t, err)
+	require.Equal(t, newRanges, rs)
+
+	// the task fetched before the update is stale.
+	stale := streamhelper.NewTask(&metaCli, simpleTask(taskName, 3).PBInfo)
+	err = metaCli.UpdateTaskFilter(ctx, stale, []string{"*.*"}, simpleRanges(3))
+	require.ErrorIs(t, errors.Cause(err), berrors.ErrPiTRInvalidTaskInfo)
+}
+
+func testIngestRecords(t *testing.T, metaCli streamhelper.MetaDataClient, etcd *embed.Etcd) {
+	ctx := context.Background()
+	taskName := "ingest"
+	require.NoError(t, metaCli.PutTask(ctx, simpleTask(taskName, 2)))
+	task, err := metaCli.GetTask(ctx, taskName)
+	require.NoError(t, err)
+	records, err := task.IngestRecords(ctx)
+	require.NoError(t, err)
+	require.Empty(t, records)
+
+	record := streamhelper.IngestRecord{
+		ID:       "engine",
+		CommitTS: 42,
+		StartKey: tablecodec.EncodeTablePrefix(1),
+		EndKey:   tablecodec.EncodeTablePrefix(2),
+	}
+	require.NoError(t, metaCli.PutIngestRecord(ctx, taskName, record))
+	keyExists(t, []byte(streamhelper.IngestKeyOf(taskName, "engine")), etcd)
+	records, err = task.IngestRecords(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []streamhelper.IngestRecord{record}, records)
+
+	require.NoError(t, metaCli.DeleteTask(ctx, taskName))
+	rangeIsEmpty(t, []byte(streamhelper.IngestsOf(taskName)), etcd)
+}
+
 func testForwardProgress(t *testing.T, metaCli streamhelper.MetaDataClient, etcd *embed.Etcd) {
 	ctx := context.Background()
 	taskName := "many_tables"
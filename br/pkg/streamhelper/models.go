@@ -26,6 +26,7 @@ const (
 	taskRangesPath       = "/ranges"
 	taskPausePath        = "/pause"
 	taskLastErrorPath    = "/last-error"
+	taskIngestPath       = "/ingest"
 	checkpointTypeGlobal = "central_global"
 	checkpointTypeRegion = "region"
 	checkpointTypeStore  = "store"
@@ -113,6 +114,31 @@ func LastErrorPrefixOf(task string) string {
 	return strings.TrimSuffix(path.Join(streamKeyPrefix, taskLastErrorPath, task), "/") + "/"
 }
 
+// IngestsOf returns the path prefix of the ingest records of some task.
+// Normally it would be <prefix>/ingest/<task-name>/.
+func IngestsOf(task string) string {
+	return strings.TrimSuffix(path.Join(streamKeyPrefix, taskIngestPath, task), "/") + "/"
+}
+
+// IngestKeyOf returns the path of an ingest record of some task.
+// Normally it would be <prefix>/ingest/<task-name>/<ingest-id> -> <record(json)>.
+func IngestKeyOf(task string, id string) string {
+	return IngestsOf(task) + id
+}
+
+// IngestRecord records a range of the task ingested by the physical import, like the local
+// backend of TiDB Lightning. The ingested SST files bypass the raft log, so their data isn't
+// captured by the log backup, and the task needs a new full backup after CommitTS to be
+// restored to a point after the import.
+type IngestRecord struct {
+	// ID identifies the ingest, e.g. the engine of TiDB Lightning.
+	ID string `json:"id"`
+	// CommitTS is the commit ts of the ingested data.
+	CommitTS uint64 `json:"commit-ts"`
+	StartKey []byte `json:"start-key"`
+	EndKey   []byte `json:"end-key"`
+}
+
 // Ranges is a vector of [start_key, end_key) pairs.
 type Ranges = []Range
 type Range = kv.KeyRange
@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/glue"
 	"github.com/pingcap/tidb/br/pkg/logutil"
 	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/br/pkg/restore"
 	"github.com/pingcap/tidb/br/pkg/storage"
 	"github.com/pingcap/tidb/br/pkg/summary"
 	"github.com/pingcap/tidb/br/pkg/utils"
@@ -45,6 +46,11 @@ const (
 	flagRemoveSchedulers = "remove-schedulers"
 	flagIgnoreStats      = "ignore-stats"
 	flagUseBackupMetaV2  = "use-backupmeta-v2"
+	flagStorageMirror    = "storage-mirror"
+
+	flagBandwidthCoordinator = "bandwidth-coordinator"
+	flagBandwidthBudget      = "bandwidth-budget"
+	flagBandwidthGroup       = "bandwidth-group"
 
 	flagGCTTL = "gcttl"
 
@@ -76,6 +82,16 @@ type BackupConfig struct {
 	RemoveSchedulers bool          `json:"remove-schedulers" toml:"remove-schedulers"`
 	IgnoreStats      bool          `json:"ignore-stats" toml:"ignore-stats"`
 	UseBackupMetaV2  bool          `json:"use-backupmeta-v2"`
+	WithPlanBindings bool          `json:"with-plan-bindings" toml:"with-plan-bindings"`
+	// BandwidthCoordinator is the etcd endpoints of the token service shared by the BR processes
+	// backing up to the same storage, which divides BandwidthBudget (in bytes per second) among
+	// the processes of the same BandwidthGroup.
+	BandwidthCoordinator []string `json:"bandwidth-coordinator" toml:"bandwidth-coordinator"`
+	BandwidthBudget      uint64   `json:"bandwidth-budget" toml:"bandwidth-budget"`
+	BandwidthGroup       string   `json:"bandwidth-group" toml:"bandwidth-group"`
+	// StorageMirror is the URL of the second storage to which the backup files are copied
+	// after the backup is finished, e.g. an offsite copy of a backup to the local NFS.
+	StorageMirror string `json:"storage-mirror" toml:"storage-mirror"`
 	CompressionConfig
 }
 
@@ -108,6 +124,20 @@ func DefineBackupFlags(flags *pflag.FlagSet) {
 	// This flag is used for test. we should backup stats all the time.
 	_ = flags.MarkHidden(flagIgnoreStats)
 
+	flags.Bool(flagWithPlanBindings, false,
+		"back up the SQL bindings and the plan-related global variables even if the filter excludes them")
+
+	flags.StringSlice(flagBandwidthCoordinator, nil,
+		"(experimental) the etcd endpoints coordinating the bandwidth of the BR processes backing up to the same storage")
+	flags.Uint64(flagBandwidthBudget, unlimited,
+		"the aggregate rate limit of all BR processes of the bandwidth group, MB/s, requires --bandwidth-coordinator")
+	flags.String(flagBandwidthGroup, "default",
+		"the bandwidth group sharing --bandwidth-budget, e.g. the name of the storage gateway")
+
+	flags.String(flagStorageMirror, "",
+		"the URL of a second storage to which the backup files are copied and verified, "+
+			"e.g. \"s3://bucket/path/to/backup\" while backing up to \"local:///nfs/backup\"")
+
 	flags.Bool(flagUseBackupMetaV2, false,
 		"use backup meta v2 to store meta info")
 	// This flag will change the structure of backupmeta.
@@ -165,10 +195,62 @@ func (cfg *BackupConfig) ParseFromFlags(flags *pflag.FlagSet) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	cfg.WithPlanBindings, err = flags.GetBool(flagWithPlanBindings)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = cfg.parseBandwidthFlags(flags); err != nil {
+		return errors.Trace(err)
+	}
+	cfg.StorageMirror, err = flags.GetString(flagStorageMirror)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if cfg.StorageMirror != "" && cfg.StorageMirror == cfg.Storage {
+		return errors.Annotate(berrors.ErrInvalidArgument, "the storage mirror should differ from the storage")
+	}
+	if cfg.WithPlanBindings {
+		cfg.TableFilter = restore.WithPlanBindingTables(cfg.TableFilter)
+	}
 	cfg.UseBackupMetaV2, err = flags.GetBool(flagUseBackupMetaV2)
 	return errors.Trace(err)
 }
 
+// parseBandwidthFlags parses the flags of the bandwidth coordination from the flag set.
+func (cfg *BackupConfig) parseBandwidthFlags(flags *pflag.FlagSet) error {
+	var err error
+	cfg.BandwidthCoordinator, err = flags.GetStringSlice(flagBandwidthCoordinator)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	budget, err := flags.GetUint64(flagBandwidthBudget)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	rateLimitUnit, err := flags.GetUint64(flagRateLimitUnit)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	cfg.BandwidthBudget = budget * rateLimitUnit
+	cfg.BandwidthGroup, err = flags.GetString(flagBandwidthGroup)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(cfg.BandwidthCoordinator) == 0 {
+		return nil
+	}
+	if cfg.BandwidthBudget == unlimited {
+		return errors.Annotatef(berrors.ErrInvalidArgument, "--%s requires --%s", flagBandwidthCoordinator, flagBandwidthBudget)
+	}
+	if cfg.RateLimit != unlimited {
+		return errors.Annotatef(berrors.ErrInvalidArgument, "--%s and --%s can't be set at the same time", flagBandwidthCoordinator, flagRateLimit)
+	}
+	if cfg.BandwidthGroup == "" {
+		return errors.Annotatef(berrors.ErrInvalidArgument, "--%s must not be empty", flagBandwidthGroup)
+	}
+	return nil
+}
+
 // parseCompressionFlags parses the backup-related flags from the flag set.
 func parseCompressionFlags(flags *pflag.FlagSet) (*CompressionConfig, error) {
 	compressionStr, err := flags.GetString(flagCompressionType)
@@ -203,7 +285,7 @@ func (cfg *BackupConfig) adjustBackupConfig() {
 	if cfg.Config.Concurrency > maxBackupConcurrency {
 		cfg.Config.Concurrency = maxBackupConcurrency
 	}
-	if cfg.RateLimit != unlimited {
+	if cfg.RateLimit != unlimited || len(cfg.BandwidthCoordinator) > 0 {
 		// TiKV limits the upload rate by each backup request.
 		// When the backup requests are sent concurrently,
 		// the ratelimit couldn't work as intended.
@@ -284,14 +366,30 @@ func RunBackup(c context.Context, g glue.Glue, cmdName string, cfg *BackupConfig
 	opts := storage.ExternalStorageOptions{
 		NoCredentials:   cfg.NoCreds,
 		SendCredentials: cfg.SendCreds,
+		Azure:           cfg.Azblob.CredentialOptions(),
+		KeyRing:         cfg.KeyRing,
 	}
 	if err = client.SetStorage(ctx, u, &opts); err != nil {
 		return errors.Trace(err)
 	}
+	var mirror storage.ExternalStorage
+	if cfg.StorageMirror != "" {
+		mirrorBackend, err := storage.ParseBackend(cfg.StorageMirror, &cfg.BackendOptions)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// open the mirror before backing up, so a misconfigured mirror fails the backup early.
+		if mirror, err = backup.OpenMirrorStorage(ctx, mirrorBackend, &opts); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	err = client.SetLockFile(ctx)
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if err = cfg.generateDataKey(ctx, client.GetStorage()); err != nil {
+		return errors.Trace(err)
+	}
 	client.SetGCTTL(cfg.GCTTL)
 
 	backupTS, err := client.GetTS(ctx, cfg.TimeAgo, cfg.BackupTS)
@@ -384,6 +482,9 @@ func RunBackup(c context.Context, g glue.Glue, cmdName string, cfg *BackupConfig
 			zap.String("PD address", pdAddress))
 
 		err = metawriter.FlushBackupMeta(ctx)
+		if err == nil && mirror != nil {
+			err = backup.MirrorFiles(ctx, client.GetStorage(), mirror, uint(cfg.Concurrency))
+		}
 		if err == nil {
 			summary.SetSuccessStatus(true)
 		}
@@ -459,6 +560,23 @@ func RunBackup(c context.Context, g glue.Glue, cmdName string, cfg *BackupConfig
 			})
 		}
 	}
+	if len(cfg.BandwidthCoordinator) > 0 {
+		etcdCLI, err := dialEtcd(ctx, cfg.Config, cfg.BandwidthCoordinator)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer etcdCLI.Close()
+		coordinator, err := backup.NewBandwidthCoordinator(ctx, etcdCLI, cfg.BandwidthGroup, cfg.BandwidthBudget)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer func() {
+			if err := coordinator.Close(); err != nil {
+				log.Warn("failed to leave the bandwidth group", zap.Error(err))
+			}
+		}()
+		client.SetBandwidthCoordinator(coordinator)
+	}
 	metawriter.StartWriteMetasAsync(ctx, metautil.AppendDataFile)
 	err = client.BackupRanges(ctx, ranges, req, uint(cfg.Concurrency), metawriter, progressCallBack)
 	if err != nil {
@@ -508,6 +626,11 @@ func RunBackup(c context.Context, g glue.Glue, cmdName string, cfg *BackupConfig
 			return errors.Trace(err)
 		}
 	}
+	if mirror != nil {
+		if err = backup.MirrorFiles(ctx, client.GetStorage(), mirror, uint(cfg.Concurrency)); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	archiveSize := metawriter.ArchiveSize()
 	g.Record(summary.BackupDataSize, archiveSize)
 	//backup from tidb will fetch a general Size issue https://github.com/pingcap/tidb/issues/27247
@@ -151,10 +151,15 @@ func RunBackupRaw(c context.Context, g glue.Glue, cmdName string, cfg *RawKvConf
 	opts := storage.ExternalStorageOptions{
 		NoCredentials:   cfg.NoCreds,
 		SendCredentials: cfg.SendCreds,
+		Azure:           cfg.Azblob.CredentialOptions(),
+		KeyRing:         cfg.KeyRing,
 	}
 	if err = client.SetStorage(ctx, u, &opts); err != nil {
 		return errors.Trace(err)
 	}
+	if err = cfg.generateDataKey(ctx, client.GetStorage()); err != nil {
+		return errors.Trace(err)
+	}
 
 	backupRange := rtree.Range{StartKey: cfg.StartKey, EndKey: cfg.EndKey}
 
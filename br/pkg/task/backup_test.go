@@ -6,7 +6,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/docker/go-units"
 	backup "github.com/pingcap/kvproto/pkg/brpb"
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
 )
 
@@ -74,3 +76,26 @@ func TestParseCompressionType(t *testing.T) {
 	require.Regexp(t, "invalid compression.*", err.Error())
 	require.Zero(t, ct)
 }
+
+func TestParseBandwidthFlags(t *testing.T) {
+	flags := &pflag.FlagSet{}
+	DefineCommonFlags(flags)
+	DefineBackupFlags(flags)
+	cfg := &BackupConfig{}
+	require.NoError(t, cfg.parseBandwidthFlags(flags))
+	require.Empty(t, cfg.BandwidthCoordinator)
+
+	require.NoError(t, flags.Set(flagBandwidthCoordinator, "127.0.0.1:2379"))
+	err := cfg.parseBandwidthFlags(flags)
+	require.ErrorContains(t, err, "--bandwidth-coordinator requires --bandwidth-budget")
+
+	require.NoError(t, flags.Set(flagBandwidthBudget, "100"))
+	require.NoError(t, cfg.parseBandwidthFlags(flags))
+	require.Equal(t, []string{"127.0.0.1:2379"}, cfg.BandwidthCoordinator)
+	require.EqualValues(t, 100*units.MiB, cfg.BandwidthBudget)
+	require.Equal(t, "default", cfg.BandwidthGroup)
+
+	cfg.RateLimit = 10 * units.MiB
+	err = cfg.parseBandwidthFlags(flags)
+	require.ErrorContains(t, err, "can't be set at the same time")
+}
@@ -5,8 +5,10 @@ package task
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"encoding/hex"
+	"io"
 	"net/url"
 	"os"
 	"path"
@@ -76,6 +78,8 @@ const (
 	flagEnableOpenTracing = "enable-opentracing"
 	flagSkipCheckPath     = "skip-check-path"
 	flagWithSysTable      = "with-sys-table"
+	flagWithPlanBindings  = "with-plan-bindings"
+	flagPauseByKeyRange   = "pause-by-key-range"
 
 	defaultSwitchInterval       = 5 * time.Minute
 	defaultGRPCKeepaliveTime    = 10 * time.Second
@@ -84,6 +88,7 @@ const (
 	flagCipherType    = "crypter.method"
 	flagCipherKey     = "crypter.key"
 	flagCipherKeyFile = "crypter.key-file"
+	flagKeyRing       = "keyring"
 
 	unlimited           = 0
 	crypterAES128KeyLen = 16
@@ -126,6 +131,11 @@ func (tls *TLSConfig) ParseFromFlags(flags *pflag.FlagSet) (err error) {
 }
 
 func dialEtcdWithCfg(ctx context.Context, cfg Config) (*clientv3.Client, error) {
+	return dialEtcd(ctx, cfg, cfg.PD)
+}
+
+// dialEtcd connects to the etcd at the endpoints by the TLS and keepalive settings of the task.
+func dialEtcd(ctx context.Context, cfg Config, endpoints []string) (*clientv3.Client, error) {
 	var (
 		tlsConfig *tls.Config
 		err       error
@@ -137,10 +147,10 @@ func dialEtcdWithCfg(ctx context.Context, cfg Config) (*clientv3.Client, error)
 			return nil, errors.Trace(err)
 		}
 	}
-	log.Info("trying to connect to etcd", zap.Strings("addr", cfg.PD))
+	log.Info("trying to connect to etcd", zap.Strings("addr", endpoints))
 	etcdCLI, err := clientv3.New(clientv3.Config{
 		TLS:              tlsConfig,
-		Endpoints:        cfg.PD,
+		Endpoints:        endpoints,
 		AutoSyncInterval: 30 * time.Second,
 		DialTimeout:      5 * time.Second,
 		DialOptions: []grpc.DialOption{
@@ -210,6 +220,8 @@ type Config struct {
 	GRPCKeepaliveTimeout time.Duration `json:"grpc-keepalive-timeout" toml:"grpc-keepalive-timeout"`
 
 	CipherInfo backuppb.CipherInfo `json:"-" toml:"-"`
+	// KeyRing is not nil if the objects written by BR are encrypted on the client side.
+	KeyRing storage.KeyRing `json:"-" toml:"-"`
 
 	// whether there's explicit filter
 	ExplicitFilter bool `json:"-" toml:"-"`
@@ -267,6 +279,9 @@ func DefineCommonFlags(flags *pflag.FlagSet) {
 		"aes-crypter key, used to encrypt/decrypt the data "+
 			"by the hexadecimal string, eg: \"0123456789abcdef0123456789abcdef\"")
 	flags.String(flagCipherKeyFile, "", "FilePath, its content is used as the cipher-key")
+	flags.String(flagKeyRing, "", "(experimental) the key ring to encrypt the backup on the client side "+
+		"by a random key of each file, either a path of the key ring file, "+
+		"or an AWS KMS key like \"aws-kms://<key-id>?region=<region>\"")
 
 	storage.DefineFlags(flags)
 }
@@ -281,6 +296,7 @@ func HiddenFlagsForStream(flags *pflag.FlagSet) {
 	_ = flags.MarkHidden(flagCipherType)
 	_ = flags.MarkHidden(flagCipherKey)
 	_ = flags.MarkHidden(flagCipherKeyFile)
+	_ = flags.MarkHidden(flagKeyRing)
 	_ = flags.MarkHidden(flagSwitchModeInterval)
 
 	storage.HiddenFlagsForStream(flags)
@@ -557,6 +573,15 @@ func (cfg *Config) ParseFromFlags(flags *pflag.FlagSet) error {
 	if err = cfg.parseCipherInfo(flags); err != nil {
 		return errors.Trace(err)
 	}
+	keyRing, err := flags.GetString(flagKeyRing)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(keyRing) > 0 {
+		if cfg.KeyRing, err = storage.ParseKeyRing(keyRing); err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	return cfg.normalizePDURLs()
 }
@@ -614,13 +639,96 @@ func GetStorage(
 	return u, s, nil
 }
 
+// PrintResolvedCredentialsSource prints where the credentials of the storage in
+// the flags are resolved from.
+func PrintResolvedCredentialsSource(ctx context.Context, flags *pflag.FlagSet, w io.Writer) error {
+	cfg := &Config{}
+	var err error
+	if cfg.Storage, err = flags.GetString(flagStorage); err != nil {
+		return errors.Trace(err)
+	}
+	if cfg.NoCreds, err = flags.GetBool(flagNoCreds); err != nil {
+		return errors.Trace(err)
+	}
+	if err = cfg.BackendOptions.ParseFromFlags(flags); err != nil {
+		return errors.Trace(err)
+	}
+	u, err := storage.ParseBackend(cfg.Storage, &cfg.BackendOptions)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return storage.PrintResolvedCredentialsSource(ctx, w, u, storageOpts(cfg))
+}
+
 func storageOpts(cfg *Config) *storage.ExternalStorageOptions {
 	return &storage.ExternalStorageOptions{
 		NoCredentials:   cfg.NoCreds,
 		SendCredentials: cfg.SendCreds,
+		Azure:           cfg.Azblob.CredentialOptions(),
+		KeyRing:         cfg.KeyRing,
 	}
 }
 
+// hasCrypter returns whether the crypter is given to encrypt the backup.
+func (cfg *Config) hasCrypter() bool {
+	switch cfg.CipherInfo.CipherType {
+	case encryptionpb.EncryptionMethod_UNKNOWN, encryptionpb.EncryptionMethod_PLAINTEXT:
+		return false
+	default:
+		return true
+	}
+}
+
+// generateDataKey generates a random key for TiKV to encrypt the SST files if the backup
+// is encrypted by a key ring and no crypter is given. The key is saved in the storage,
+// which is encrypted by the key ring. It fails if the storage already has a data key, since
+// overwriting it makes the files of the previous backup undecryptable.
+func (cfg *Config) generateDataKey(ctx context.Context, s storage.ExternalStorage) error {
+	if cfg.KeyRing == nil || cfg.hasCrypter() {
+		return nil
+	}
+	exists, err := s.FileExists(ctx, metautil.DataKeyFile)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if exists {
+		return errors.Annotatef(berrors.ErrInvalidArgument, "data key file exists in %v, "+
+			"there is another backup in the path already, please specify a correct backup directory!",
+			s.URI()+"/"+metautil.DataKeyFile)
+	}
+	key := make([]byte, crypterAES256KeyLen)
+	if _, err := rand.Read(key); err != nil {
+		return errors.Trace(err)
+	}
+	if err := s.WriteFile(ctx, metautil.DataKeyFile, key); err != nil {
+		return errors.Annotate(err, "save data key failed")
+	}
+	cfg.CipherInfo.CipherType = encryptionpb.EncryptionMethod_AES256_CTR
+	cfg.CipherInfo.CipherKey = key
+	return nil
+}
+
+// loadDataKey loads the key to decrypt the SST files saved by generateDataKey.
+func (cfg *Config) loadDataKey(ctx context.Context, s storage.ExternalStorage) error {
+	if cfg.KeyRing == nil || cfg.hasCrypter() {
+		return nil
+	}
+	exists, err := s.FileExists(ctx, metautil.DataKeyFile)
+	if err != nil || !exists {
+		return errors.Trace(err)
+	}
+	key, err := s.ReadFile(ctx, metautil.DataKeyFile)
+	if err != nil {
+		return errors.Annotate(err, "load data key failed")
+	}
+	if len(key) != crypterAES256KeyLen {
+		return errors.Annotatef(berrors.ErrInvalidArgument, "invalid data key length %d", len(key))
+	}
+	cfg.CipherInfo.CipherType = encryptionpb.EncryptionMethod_AES256_CTR
+	cfg.CipherInfo.CipherKey = key
+	return nil
+}
+
 // ReadBackupMeta reads the backupmeta file from the storage.
 func ReadBackupMeta(
 	ctx context.Context,
@@ -631,6 +739,9 @@ func ReadBackupMeta(
 	if err != nil {
 		return nil, nil, nil, errors.Trace(err)
 	}
+	if err = cfg.loadDataKey(ctx, s); err != nil {
+		return nil, nil, nil, errors.Trace(err)
+	}
 	metaData, err := s.ReadFile(ctx, fileName)
 	if err != nil {
 		if gcsObjectNotFound(err) {
@@ -3,12 +3,18 @@
 package task
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	backup "github.com/pingcap/kvproto/pkg/brpb"
 	"github.com/pingcap/kvproto/pkg/encryptionpb"
+	"github.com/pingcap/tidb/br/pkg/metautil"
+	"github.com/pingcap/tidb/br/pkg/storage"
 	"github.com/pingcap/tidb/config"
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
@@ -157,3 +163,47 @@ func TestCheckCipherKey(t *testing.T) {
 		}
 	}
 }
+
+func TestDataKey(t *testing.T) {
+	ctx := context.Background()
+	keyRingPath := filepath.Join(t.TempDir(), "keyring")
+	err := os.WriteFile(keyRingPath, []byte("k1 "+strings.Repeat("ab", crypterAES256KeyLen)+"\n"), 0o600)
+	require.NoError(t, err)
+	keyRing, err := storage.ParseKeyRing(keyRingPath)
+	require.NoError(t, err)
+	local, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	s := storage.WithEncryption(local, keyRing)
+
+	backupCfg := &Config{KeyRing: keyRing}
+	require.NoError(t, backupCfg.generateDataKey(ctx, s))
+	require.Equal(t, encryptionpb.EncryptionMethod_AES256_CTR, backupCfg.CipherInfo.CipherType)
+	require.Len(t, backupCfg.CipherInfo.CipherKey, crypterAES256KeyLen)
+
+	restoreCfg := &Config{KeyRing: keyRing}
+	require.NoError(t, restoreCfg.loadDataKey(ctx, s))
+	require.Equal(t, backupCfg.CipherInfo, restoreCfg.CipherInfo)
+
+	// the data key is encrypted by the key ring.
+	raw, err := local.ReadFile(ctx, metautil.DataKeyFile)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), string(backupCfg.CipherInfo.CipherKey))
+
+	// the data key of another backup is never overwritten.
+	err = (&Config{KeyRing: keyRing}).generateDataKey(ctx, s)
+	require.ErrorContains(t, err, "data key file exists")
+
+	// the given crypter takes precedence.
+	cipher := backup.CipherInfo{
+		CipherType: encryptionpb.EncryptionMethod_AES128_CTR,
+		CipherKey:  []byte("0123456789abcdef"),
+	}
+	cfg := &Config{KeyRing: keyRing, CipherInfo: cipher}
+	require.NoError(t, cfg.loadDataKey(ctx, s))
+	require.Equal(t, cipher, cfg.CipherInfo)
+
+	// no key ring.
+	cfg = &Config{}
+	require.NoError(t, cfg.generateDataKey(ctx, s))
+	require.False(t, cfg.hasCrypter())
+}
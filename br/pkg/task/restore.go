@@ -26,7 +26,10 @@ import (
 	"github.com/pingcap/tidb/br/pkg/version"
 	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/charset"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/mathutil"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -35,8 +38,10 @@ import (
 )
 
 const (
-	flagOnline   = "online"
-	flagNoSchema = "no-schema"
+	flagOnline         = "online"
+	flagNoSchema       = "no-schema"
+	flagIngestManifest = "ingest-manifest"
+	flagTablePriority  = "table-priority"
 
 	// FlagMergeRegionSizeBytes is the flag name of merge small regions by size
 	FlagMergeRegionSizeBytes = "merge-region-size-bytes"
@@ -51,6 +56,10 @@ const (
 	// FlagWithPlacementPolicy corresponds to tidb config with-tidb-placement-mode
 	// current only support STRICT or IGNORE, the default is STRICT according to tidb.
 	FlagWithPlacementPolicy = "with-tidb-placement-mode"
+	// FlagCharsetMapping and FlagCollationMapping replace the charsets and collations of the
+	// databases created in restore, e.g. `utf8mb3_unicode_ci=utf8mb4_unicode_ci`.
+	FlagCharsetMapping   = "charset-mapping"
+	FlagCollationMapping = "collation-mapping"
 
 	// FlagStreamStartTS and FlagStreamRestoreTS is used for log restore timestamp range.
 	FlagStreamStartTS   = "start-ts"
@@ -87,6 +96,11 @@ type RestoreCommonConfig struct {
 
 	// determines whether enable restore sys table on default, see fullClusterRestore in restore/client.go
 	WithSysTable bool `json:"with-sys-table" toml:"with-sys-table"`
+	// WithPlanBindings determines whether to restore the SQL bindings and the plan-related global variables.
+	WithPlanBindings bool `json:"with-plan-bindings" toml:"with-plan-bindings"`
+	// PauseByKeyRange determines whether to pause the schedulers only on the key ranges of the
+	// restored tables with a lease, instead of removing the schedulers of the whole cluster.
+	PauseByKeyRange bool `json:"pause-by-key-range" toml:"pause-by-key-range"`
 }
 
 // adjust adjusts the abnormal config value in the current config.
@@ -116,6 +130,10 @@ func DefineRestoreCommonFlags(flags *pflag.FlagSet) {
 	flags.Uint(FlagDdlBatchSize, defaultFlagDdlBatchSize,
 		"batch size for ddl to create a batch of tabes once.")
 	flags.Bool(flagWithSysTable, false, "whether restore system privilege tables on default setting")
+	flags.Bool(flagWithPlanBindings, false, "whether restore the SQL bindings and the plan-related global variables")
+	flags.Bool(flagPauseByKeyRange, false,
+		"(experimental) only pause the schedulers on the key ranges of the restored tables, "+
+			"which are resumed automatically after a lease if br exits unexpectedly")
 	_ = flags.MarkHidden(FlagMergeRegionSizeBytes)
 	_ = flags.MarkHidden(FlagMergeRegionKeyCount)
 	_ = flags.MarkHidden(FlagPDConcurrency)
@@ -144,6 +162,18 @@ func (cfg *RestoreCommonConfig) ParseFromFlags(flags *pflag.FlagSet) error {
 			return errors.Trace(err)
 		}
 	}
+	if flags.Lookup(flagWithPlanBindings) != nil {
+		cfg.WithPlanBindings, err = flags.GetBool(flagWithPlanBindings)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if flags.Lookup(flagPauseByKeyRange) != nil {
+		cfg.PauseByKeyRange, err = flags.GetBool(flagPauseByKeyRange)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return errors.Trace(err)
 }
 
@@ -160,6 +190,18 @@ type RestoreConfig struct {
 
 	WithPlacementPolicy string `json:"with-tidb-placement-mode" toml:"with-tidb-placement-mode"`
 
+	// CharsetMapping and CollationMapping are the rules to replace the charsets and collations
+	// of the created databases, which are not supported by the target cluster.
+	CharsetMapping   map[string]string `json:"charset-mapping" toml:"charset-mapping"`
+	CollationMapping map[string]string `json:"collation-mapping" toml:"collation-mapping"`
+
+	// IngestManifest records the ingested files in PD, so a retried restore of the same backup
+	// skips them.
+	IngestManifest bool `json:"ingest-manifest" toml:"ingest-manifest"`
+	// TablePriority is the weights of the tables, the keys are table filter rules. The tables with
+	// larger weights are restored first, see restore.TablePriorities.
+	TablePriority map[string]string `json:"table-priority" toml:"table-priority"`
+
 	// FullBackupStorage is used to  run `restore full` before `restore log`.
 	// if it is empty, directly take restoring log justly.
 	FullBackupStorage string `json:"full-backup-storage" toml:"full-backup-storage"`
@@ -176,6 +218,16 @@ func DefineRestoreFlags(flags *pflag.FlagSet) {
 	// Do not expose this flag
 	_ = flags.MarkHidden(flagNoSchema)
 	flags.String(FlagWithPlacementPolicy, "STRICT", "correspond to tidb global/session variable with-tidb-placement-mode")
+	flags.StringToString(FlagCharsetMapping, nil,
+		"replace the charsets of the created databases, e.g. 'utf8mb3=utf8'")
+	flags.StringToString(FlagCollationMapping, nil,
+		"replace the collations of the created databases, e.g. 'utf8mb3_unicode_ci=utf8mb4_unicode_ci'.\n"+
+			"the databases which the tables or ddl jobs belong to are created if they don't exist in the cluster")
+	flags.Bool(flagIngestManifest, true,
+		"record the ingested files in PD, so the files ingested before a failure are skipped when the restore is retried")
+	flags.StringToString(flagTablePriority, nil,
+		"the weights of the tables, e.g. 'db1.orders=10,db2.*=5'. the tables with larger weights are restored first,\n"+
+			"and take larger shares of the restore concurrency. the other tables have weight 1")
 
 	DefineRestoreCommonFlags(flags)
 }
@@ -228,6 +280,9 @@ func (cfg *RestoreConfig) ParseFromFlags(flags *pflag.FlagSet) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if cfg.WithPlanBindings {
+		cfg.TableFilter = restore.WithPlanBindingTables(cfg.TableFilter)
+	}
 
 	if cfg.Config.Concurrency == 0 {
 		cfg.Config.Concurrency = defaultRestoreConcurrency
@@ -249,6 +304,48 @@ func (cfg *RestoreConfig) ParseFromFlags(flags *pflag.FlagSet) error {
 	if err != nil {
 		return errors.Annotatef(err, "failed to get flag %s", FlagWithPlacementPolicy)
 	}
+	cfg.CharsetMapping, err = flags.GetStringToString(FlagCharsetMapping)
+	if err != nil {
+		return errors.Annotatef(err, "failed to get flag %s", FlagCharsetMapping)
+	}
+	cfg.CollationMapping, err = flags.GetStringToString(FlagCollationMapping)
+	if err != nil {
+		return errors.Annotatef(err, "failed to get flag %s", FlagCollationMapping)
+	}
+	cfg.IngestManifest, err = flags.GetBool(flagIngestManifest)
+	if err != nil {
+		return errors.Annotatef(err, "failed to get flag %s", flagIngestManifest)
+	}
+	cfg.TablePriority, err = flags.GetStringToString(flagTablePriority)
+	if err != nil {
+		return errors.Annotatef(err, "failed to get flag %s", flagTablePriority)
+	}
+	if _, err = restore.ParseTablePriorities(cfg.TablePriority); err != nil {
+		return errors.Trace(err)
+	}
+	return cfg.checkCharsetMapping()
+}
+
+func isSupportedCharset(cs string) bool {
+	_, err := charset.GetCharsetInfo(cs)
+	return err == nil
+}
+
+// checkCharsetMapping checks the replacements of the charset and collation mapping are
+// supported by TiDB, so the restore doesn't fail after the data is downloaded.
+func (cfg *RestoreConfig) checkCharsetMapping() error {
+	for from, to := range cfg.CharsetMapping {
+		if !isSupportedCharset(to) {
+			return errors.Annotatef(berrors.ErrInvalidArgument,
+				"the charset %s of --%s %s=%s is not supported", to, FlagCharsetMapping, from, to)
+		}
+	}
+	for from, to := range cfg.CollationMapping {
+		if coll, err := charset.GetCollationByName(to); err != nil || !isSupportedCharset(coll.CharsetName) {
+			return errors.Annotatef(berrors.ErrInvalidArgument,
+				"the collation %s of --%s %s=%s is not supported", to, FlagCollationMapping, from, to)
+		}
+	}
 	return nil
 }
 
@@ -297,7 +394,9 @@ func configureRestoreClient(ctx context.Context, client *restore.Client, cfg *Re
 	client.SetSwitchModeInterval(cfg.SwitchModeInterval)
 	client.SetBatchDdlSize(cfg.DdlBatchSize)
 	client.SetPlacementPolicyMode(cfg.WithPlacementPolicy)
+	client.SetCharsetMapping(cfg.CharsetMapping, cfg.CollationMapping)
 	client.SetWithSysTable(cfg.WithSysTable)
+	client.SetWithPlanBindings(cfg.WithPlanBindings)
 
 	err := client.LoadRestoreStores(ctx)
 	if err != nil {
@@ -404,6 +503,11 @@ func RunRestore(c context.Context, g glue.Glue, cmdName string, cfg *RestoreConf
 	ctx, cancel := context.WithCancel(c)
 	defer cancel()
 
+	priorities, err := restore.ParseTablePriorities(cfg.TablePriority)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	if span := opentracing.SpanFromContext(ctx); span != nil && span.Tracer() != nil {
 		span1 := span.Tracer().StartSpan("task.RunRestore", opentracing.ChildOf(span.Context()))
 		defer span1.Finish()
@@ -470,6 +574,17 @@ func RunRestore(c context.Context, g glue.Glue, cmdName string, cfg *RestoreConf
 	if err = CheckRestoreDBAndTable(client, cfg); err != nil {
 		return err
 	}
+	var ingestManifest *restore.IngestManifest
+	if cfg.IngestManifest {
+		if etcdCli := mgr.GetDomain().GetEtcdClient(); etcdCli != nil {
+			if ingestManifest, err = restore.NewIngestManifest(ctx, etcdCli, backupMeta); err != nil {
+				return errors.Trace(err)
+			}
+			client.SetIngestManifest(ingestManifest)
+		} else {
+			log.Warn("the etcd of PD is unavailable, the ingested files won't be recorded")
+		}
+	}
 	files, tables, dbs := filterRestoreFiles(client, cfg)
 	if len(dbs) == 0 && len(tables) != 0 {
 		return errors.Annotate(berrors.ErrRestoreInvalidBackup, "contain tables but no databases")
@@ -573,6 +688,9 @@ func RunRestore(c context.Context, g glue.Glue, cmdName string, cfg *RestoreConf
 		}
 	}
 
+	if priorities != nil {
+		priorities.SortTables(tables)
+	}
 	// We make bigger errCh so we won't block on multi-part failed.
 	errCh := make(chan error, 32)
 	tableStream := client.GoCreateTables(ctx, mgr.GetDomain(), tables, newTS, errCh)
@@ -591,6 +709,16 @@ func RunRestore(c context.Context, g glue.Glue, cmdName string, cfg *RestoreConf
 		})
 	}
 
+	pauseByKeyRange := cfg.PauseByKeyRange && !client.IsOnline()
+	if pauseByKeyRange && !mgr.CanPauseSchedulerByKeyRange() {
+		log.Warn("the cluster doesn't support pausing schedulers by key range, fallback to remove the schedulers")
+		pauseByKeyRange = false
+	}
+	var resumeKeyRangeSchedulers pdutil.UndoFunc
+	if pauseByKeyRange {
+		tableStream, resumeKeyRangeSchedulers = pauseSchedulersByTables(ctx, mgr, tableStream, errCh)
+	}
+
 	tableFileMap := restore.MapTableToFiles(files)
 	log.Debug("mapped table to files", zap.Any("result map", tableFileMap))
 
@@ -601,8 +729,14 @@ func RunRestore(c context.Context, g glue.Glue, cmdName string, cfg *RestoreConf
 	summary.CollectInt("restore ranges", rangeSize)
 	log.Info("range and file prepared", zap.Int("file count", len(files)), zap.Int("range count", rangeSize))
 
-	restoreSchedulers, err := restorePreWork(ctx, client, mgr, true)
+	restoreSchedulers, err := restorePreWork(ctx, client, mgr, true, !pauseByKeyRange)
+	if pauseByKeyRange {
+		restoreSchedulers = resumeKeyRangeSchedulers
+	}
 	if err != nil {
+		if pauseByKeyRange {
+			_ = resumeKeyRangeSchedulers(ctx)
+		}
 		return errors.Trace(err)
 	}
 	// Always run the post-work even on error, so we don't stuck in the import
@@ -640,6 +774,7 @@ func RunRestore(c context.Context, g glue.Glue, cmdName string, cfg *RestoreConf
 	manager := restore.NewBRContextManager(client)
 	batcher, afterRestoreStream := restore.NewBatcher(ctx, sender, manager, errCh)
 	batcher.SetThreshold(batchSize)
+	batcher.SetTablePriorities(priorities)
 	batcher.EnableAutoCommit(ctx, cfg.BatchFlushInterval)
 	go restoreTableStream(ctx, rangeStream, batcher, errCh)
 
@@ -687,6 +822,12 @@ func RunRestore(c context.Context, g glue.Glue, cmdName string, cfg *RestoreConf
 	// So leave it out of the pipeline for easier implementation.
 	client.RestoreSystemSchemas(ctx, cfg.TableFilter)
 
+	if ingestManifest != nil {
+		if err = ingestManifest.Clear(ctx); err != nil {
+			log.Warn("failed to clear the ingest manifest", logutil.ShortError(err))
+		}
+	}
+
 	// Set task summary to success status.
 	summary.SetSuccessStatus(true)
 	return nil
@@ -749,7 +890,9 @@ func filterRestoreFiles(
 
 // restorePreWork executes some prepare work before restore.
 // TODO make this function returns a restore post work.
-func restorePreWork(ctx context.Context, client *restore.Client, mgr *conn.Mgr, switchToImport bool) (pdutil.UndoFunc, error) {
+func restorePreWork(
+	ctx context.Context, client *restore.Client, mgr *conn.Mgr, switchToImport, removeSchedulers bool,
+) (pdutil.UndoFunc, error) {
 	if client.IsOnline() {
 		return pdutil.Nop, nil
 	}
@@ -759,9 +902,46 @@ func restorePreWork(ctx context.Context, client *restore.Client, mgr *conn.Mgr,
 		client.SwitchToImportMode(ctx)
 	}
 
+	if !removeSchedulers {
+		return pdutil.Nop, nil
+	}
 	return mgr.RemoveSchedulers(ctx)
 }
 
+// pauseSchedulersByTables pauses the schedulers on the key ranges of the tables passing through
+// the stream, so the schedulers of the rest of the cluster keep working. The returned UndoFunc
+// resumes the paused schedulers.
+func pauseSchedulersByTables(
+	ctx context.Context, mgr *conn.Mgr, tableStream <-chan restore.CreatedTable, errCh chan<- error,
+) (<-chan restore.CreatedTable, pdutil.UndoFunc) {
+	pauseCtx, cancel := context.WithCancel(ctx)
+	pauser := mgr.NewKeyRangePauser(pauseCtx)
+	outCh := util.ChanMap(tableStream, func(t restore.CreatedTable) restore.CreatedTable {
+		tableIDs := []int64{t.Table.ID}
+		if partitions := t.Table.GetPartitionInfo(); partitions != nil {
+			for _, def := range partitions.Definitions {
+				tableIDs = append(tableIDs, def.ID)
+			}
+		}
+		keyRanges := make([]kv.KeyRange, 0, len(tableIDs))
+		for _, id := range tableIDs {
+			keyRanges = append(keyRanges, kv.KeyRange{
+				StartKey: codec.EncodeBytes([]byte{}, tablecodec.EncodeTablePrefix(id)),
+				EndKey:   codec.EncodeBytes([]byte{}, tablecodec.EncodeTablePrefix(id+1)),
+			})
+		}
+		if err := pauser.AddKeyRanges(ctx, keyRanges...); err != nil {
+			errCh <- errors.Annotatef(err, "failed to pause schedulers for table %s", t.Table.Name)
+		}
+		return t
+	})
+	return outCh, func(context.Context) error {
+		cancel()
+		<-pauser.Done()
+		return nil
+	}
+}
+
 // restorePostWork executes some post work after restore.
 // TODO: aggregate all lifetime manage methods into batcher's context manager field.
 func restorePostWork(
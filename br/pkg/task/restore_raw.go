@@ -151,7 +151,7 @@ func RunRestoreRaw(c context.Context, g glue.Glue, cmdName string, cfg *RestoreR
 		return errors.Trace(err)
 	}
 
-	restoreSchedulers, err := restorePreWork(ctx, client, mgr, true)
+	restoreSchedulers, err := restorePreWork(ctx, client, mgr, true, true)
 	if err != nil {
 		return errors.Trace(err)
 	}
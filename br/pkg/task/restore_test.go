@@ -35,6 +35,29 @@ func TestRestoreConfigAdjust(t *testing.T) {
 	require.Equal(t, conn.DefaultMergeRegionSizeBytes, cfg.MergeSmallRegionSizeBytes)
 }
 
+func TestCheckCharsetMapping(t *testing.T) {
+	cfg := &RestoreConfig{
+		CharsetMapping:   map[string]string{"utf8mb3": "utf8"},
+		CollationMapping: map[string]string{"utf8mb3_unicode_ci": "utf8mb4_unicode_ci"},
+	}
+	require.NoError(t, cfg.checkCharsetMapping())
+
+	cfg.CollationMapping["utf8mb3_general_ci"] = "utf8mb3_general_ci"
+	err := cfg.checkCharsetMapping()
+	require.ErrorContains(t, err, "the collation utf8mb3_general_ci of --collation-mapping")
+	delete(cfg.CollationMapping, "utf8mb3_general_ci")
+
+	// the collations of the unsupported charsets are rejected too.
+	cfg.CollationMapping["latin2_bin"] = "latin2_general_ci"
+	err = cfg.checkCharsetMapping()
+	require.ErrorContains(t, err, "the collation latin2_general_ci of --collation-mapping")
+	delete(cfg.CollationMapping, "latin2_bin")
+
+	cfg.CharsetMapping["latin2"] = "latin2"
+	err = cfg.checkCharsetMapping()
+	require.ErrorContains(t, err, "the charset latin2 of --charset-mapping")
+}
+
 type mockPDClient struct {
 	pd.Client
 }
@@ -78,6 +78,8 @@ var (
 	StreamMetadata = "log metadata"
 	StreamCtl      = "log ctl"
 
+	StreamUpdateFilter = "log update-filter"
+
 	skipSummaryCommandList = map[string]struct{}{
 		StreamStatus:   {},
 		StreamTruncate: {},
@@ -98,6 +100,8 @@ var StreamCommandMap = map[string]func(c context.Context, g glue.Glue, cmdName s
 	StreamTruncate: RunStreamTruncate,
 	StreamMetadata: RunStreamMetadata,
 	StreamCtl:      RunStreamAdvancer,
+
+	StreamUpdateFilter: RunStreamUpdateFilter,
 }
 
 // StreamConfig specifies the configure about backup stream
@@ -132,6 +136,7 @@ func (cfg *StreamConfig) makeStorage(ctx context.Context) (storage.ExternalStora
 	opts := storage.ExternalStorageOptions{
 		NoCredentials:   cfg.NoCreds,
 		SendCredentials: cfg.SendCreds,
+		Azure:           cfg.Azblob.CredentialOptions(),
 	}
 	storage, err := storage.New(ctx, u, &opts)
 	if err != nil {
@@ -314,6 +319,7 @@ func NewStreamMgr(ctx context.Context, cfg *StreamConfig, g glue.Glue, isStreamS
 		opts := storage.ExternalStorageOptions{
 			NoCredentials:   cfg.NoCreds,
 			SendCredentials: cfg.SendCreds,
+			Azure:           cfg.Azblob.CredentialOptions(),
 		}
 		client, err := backup.NewBackupClient(ctx, mgr)
 		if err != nil {
@@ -382,12 +388,14 @@ func (s *streamMgr) setGCSafePoint(ctx context.Context, sp utils.BRServiceSafePo
 	return nil
 }
 
-func (s *streamMgr) buildObserveRanges(ctx context.Context) ([]kv.KeyRange, error) {
+// buildObserveRanges builds the ranges to observe by the table filter, with the tables
+// existing at the snapshot of ts.
+func (s *streamMgr) buildObserveRanges(ctx context.Context, ts uint64) ([]kv.KeyRange, error) {
 	dRanges, err := stream.BuildObserveDataRanges(
 		s.mgr.GetStorage(),
 		s.cfg.FilterStr,
 		s.cfg.TableFilter,
-		s.cfg.StartTS,
+		ts,
 	)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -522,7 +530,7 @@ func RunStreamStart(
 		return errors.Trace(err)
 	}
 
-	ranges, err := streamMgr.buildObserveRanges(ctx)
+	ranges, err := streamMgr.buildObserveRanges(ctx, cfg.StartTS)
 	if err != nil {
 		return errors.Trace(err)
 	} else if len(ranges) == 0 {
@@ -763,6 +771,99 @@ func RunStreamResume(
 	return nil
 }
 
+// RunStreamUpdateFilter replaces the table filter of a stream task. The task is paused during the
+// update, and it observes the tables by the new filter from its global checkpoint after resumed.
+func RunStreamUpdateFilter(
+	c context.Context,
+	g glue.Glue,
+	cmdName string,
+	cfg *StreamConfig,
+) error {
+	ctx, cancelFn := context.WithCancel(c)
+	defer cancelFn()
+
+	if span := opentracing.SpanFromContext(ctx); span != nil && span.Tracer() != nil {
+		span1 := span.Tracer().StartSpan(
+			"task.RunStreamUpdateFilter",
+			opentracing.ChildOf(span.Context()),
+		)
+		defer span1.Finish()
+		ctx = opentracing.ContextWithSpan(ctx, span1)
+	}
+
+	streamMgr, err := NewStreamMgr(ctx, cfg, g, false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer streamMgr.close()
+
+	cli := streamhelper.NewMetaDataClient(streamMgr.mgr.GetDomain().GetEtcdClient())
+	ti, isPaused, err := cli.GetTaskWithPauseStatus(ctx, cfg.TaskName)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	globalCheckPointTS, err := ti.GetGlobalCheckPointTS(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	currentTS, err := streamMgr.mgr.GetTS(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ranges, err := streamMgr.buildObserveRanges(ctx, currentTS)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	if !isPaused {
+		// the task is paused, so that TiKV re-registers it from the global checkpoint with the
+		// new ranges after it is resumed, and no change after the checkpoint is lost.
+		if err = streamMgr.setGCSafePoint(
+			ctx,
+			utils.BRServiceSafePoint{
+				ID:       buildPauseSafePointName(ti.Info.Name),
+				TTL:      utils.DefaultStreamPauseSafePointTTL,
+				BackupTS: globalCheckPointTS - 1,
+			},
+		); err != nil {
+			return errors.Trace(err)
+		}
+		if err = cli.PauseTask(ctx, cfg.TaskName); err != nil {
+			return errors.Trace(err)
+		}
+		defer func() {
+			if err := cli.ResumeTask(ctx, cfg.TaskName); err != nil {
+				log.Warn("failed to resume the task after updating the table filter, please resume it manually",
+					zap.String("task", cfg.TaskName), logutil.ShortError(err))
+				return
+			}
+			if err := streamMgr.setGCSafePoint(ctx,
+				utils.BRServiceSafePoint{
+					ID:       buildPauseSafePointName(ti.Info.Name),
+					TTL:      0,
+					BackupTS: globalCheckPointTS,
+				},
+			); err != nil {
+				log.Warn("failed to remove safe point",
+					zap.Uint64("safe-point", globalCheckPointTS), zap.String("error", err.Error()))
+			}
+		}()
+	}
+
+	oldFilter := ti.Info.TableFilter
+	if err = cli.UpdateTaskFilter(ctx, ti, cfg.FilterStr, ranges); err != nil {
+		return errors.Trace(err)
+	}
+	log.Info("the table filter of the task is updated, the newly included tables are only "+
+		"recoverable after the effective ts",
+		zap.String("task", cfg.TaskName),
+		zap.Strings("old-filter", oldFilter),
+		zap.Strings("new-filter", cfg.FilterStr),
+		zap.Uint64("effective-ts", globalCheckPointTS))
+	summary.Log(cmdName, logutil.StreamBackupTaskInfo(&ti.Info), zap.Uint64("effective-ts", globalCheckPointTS))
+	return nil
+}
+
 func RunStreamAdvancer(c context.Context, g glue.Glue, cmdName string, cfg *StreamConfig) error {
 	ctx, cancel := context.WithCancel(c)
 	defer cancel()
@@ -1086,7 +1187,7 @@ func restoreStream(
 	}
 	client.SetCurrentTS(currentTS)
 
-	restoreSchedulers, err := restorePreWork(ctx, client, mgr, false)
+	restoreSchedulers, err := restorePreWork(ctx, client, mgr, false, true)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -1227,6 +1328,7 @@ func createRestoreClient(ctx context.Context, g glue.Glue, cfg *RestoreConfig, m
 	opts := storage.ExternalStorageOptions{
 		NoCredentials:   cfg.NoCreds,
 		SendCredentials: cfg.SendCreds,
+		Azure:           cfg.Azblob.CredentialOptions(),
 	}
 	if err = client.SetStorage(ctx, u, &opts); err != nil {
 		return nil, errors.Trace(err)
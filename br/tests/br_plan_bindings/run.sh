@@ -0,0 +1,52 @@
+#!/bin/sh
+#
+# Copyright 2022 PingCAP, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+set -eux
+DB="$TEST_NAME"
+backup_dir="$TEST_DIR/$TEST_NAME"
+
+run_sql "CREATE DATABASE $DB;"
+run_sql "CREATE TABLE $DB.t(a int, b int, index ia(a), index ib(b));"
+run_sql "INSERT INTO $DB.t VALUES (1, 1), (2, 2);"
+run_sql "CREATE GLOBAL BINDING FOR SELECT * FROM $DB.t WHERE a = 1 USING SELECT * FROM $DB.t USE INDEX(ib) WHERE a = 1;"
+run_sql "SET GLOBAL tidb_opt_insubq_to_join_and_agg = OFF;"
+run_sql "SET GLOBAL tidb_enable_index_merge = OFF;"
+run_sql "SET GLOBAL tidb_gc_max_wait_time = 3600;"
+
+# only the user table is backed up explicitly, the bindings are captured by the flag.
+run_br --pd "$PD_ADDR" backup full -f "$DB.*" --with-plan-bindings -s "local://$backup_dir"
+
+run_sql "DROP DATABASE $DB;"
+run_sql "DROP GLOBAL BINDING FOR SELECT * FROM $DB.t WHERE a = 1;"
+run_sql "SET GLOBAL tidb_opt_insubq_to_join_and_agg = ON;"
+run_sql "SET GLOBAL tidb_enable_index_merge = ON;"
+run_sql "SET GLOBAL tidb_gc_max_wait_time = 86400;"
+
+run_br --pd "$PD_ADDR" restore full -s "local://$backup_dir" --with-plan-bindings
+
+run_sql "SELECT count(*) FROM $DB.t;" | grep "count(\*): 2"
+run_sql "SELECT count(*) FROM mysql.bind_info WHERE bind_sql LIKE '%USE INDEX(\`ib\`)%' AND status = 'enabled';" | grep "count(\*): 1"
+run_sql "SHOW GLOBAL VARIABLES LIKE 'tidb_opt_insubq_to_join_and_agg';" | grep "Value: OFF"
+run_sql "SHOW GLOBAL VARIABLES LIKE 'tidb_enable_index_merge';" | grep "Value: OFF"
+# the global variables irrelevant to the plans are not restored.
+run_sql "SHOW GLOBAL VARIABLES LIKE 'tidb_gc_max_wait_time';" | grep "Value: 86400"
+
+# restoring again doesn't duplicate the bindings.
+run_sql "DROP DATABASE $DB;"
+run_br --pd "$PD_ADDR" restore full -s "local://$backup_dir" --with-plan-bindings
+run_sql "SELECT count(*) FROM mysql.bind_info WHERE bind_sql LIKE '%USE INDEX(\`ib\`)%';" | grep "count(\*): 1"
+
+run_sql "DROP DATABASE $DB;"
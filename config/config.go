@@ -173,6 +173,10 @@ type Config struct {
 	TokenLimit       uint   `toml:"token-limit" json:"token-limit"`
 	TempDir          string `toml:"temp-dir" json:"temp-dir"`
 	TempStoragePath  string `toml:"tmp-storage-path" json:"tmp-storage-path"`
+	// TempStorageURL is the URL of an external storage to spill the temporary results to.
+	// The TempStorageQuota is ignored if it's set, and the TempStoragePath is
+	// still used by the components other than the executors.
+	TempStorageURL string `toml:"tmp-storage-url" json:"tmp-storage-url"`
 	// TempStorageQuota describe the temporary storage Quota during query exector when TiDBEnableTmpStorageOnOOM is enabled
 	// If the quota exceed the capacity of the TempStoragePath, the tidb-server would exit with fatal error
 	TempStorageQuota           int64                   `toml:"tmp-storage-quota" json:"tmp-storage-quota"` // Bytes
@@ -189,6 +193,7 @@ type Config struct {
 	PreparedPlanCache          PreparedPlanCache       `toml:"prepared-plan-cache" json:"prepared-plan-cache"`
 	OpenTracing                OpenTracing             `toml:"opentracing" json:"opentracing"`
 	ProxyProtocol              ProxyProtocol           `toml:"proxy-protocol" json:"proxy-protocol"`
+	AdminPort                  AdminPort               `toml:"admin-port" json:"admin-port"`
 	PDClient                   tikvcfg.PDClient        `toml:"pd-client" json:"pd-client"`
 	TiKVClient                 tikvcfg.TiKVClient      `toml:"tikv-client" json:"tikv-client"`
 	Binlog                     Binlog                  `toml:"binlog" json:"binlog"`
@@ -647,6 +652,10 @@ type Performance struct {
 	StatsLoadConcurrency     uint   `toml:"stats-load-concurrency" json:"stats-load-concurrency"`
 	StatsLoadQueueSize       uint   `toml:"stats-load-queue-size" json:"stats-load-queue-size"`
 	EnableStatsCacheMemQuota bool   `toml:"enable-stats-cache-mem-quota" json:"enable-stats-cache-mem-quota"`
+	// InfoSchemaLazyLoadCapacity is the max number of user databases whose tables are kept in
+	// the information schema cache, the tables are loaded on the first access. 0 disables it,
+	// all the tables are loaded when the information schema is fully loaded.
+	InfoSchemaLazyLoadCapacity uint `toml:"infoschema-lazy-load-capacity" json:"infoschema-lazy-load-capacity"`
 	// The following items are deprecated. We need to keep them here temporarily
 	// to support the upgrade process. They can be removed in future.
 
@@ -712,6 +721,38 @@ type ProxyProtocol struct {
 	HeaderTimeout uint `toml:"header-timeout" json:"header-timeout"`
 }
 
+// AdminPort is the section of an additional MySQL protocol port for the administrators,
+// which is exempt from the connection limits.
+type AdminPort struct {
+	// Port is the port to listen on, 0 means disabling the admin port.
+	Port uint `toml:"port" json:"port"`
+	// Host is the host to listen on, the host of the server is used if it's empty.
+	Host string `toml:"host" json:"host"`
+	// Users are the accounts allowed to connect through the admin port, like "root@127.0.0.1".
+	// The host supports the wildcards '%' and '_' the same as the accounts, and a user without
+	// "@host" is allowed to connect from any host.
+	Users []string `toml:"users" json:"users"`
+}
+
+// Valid checks if this config is valid.
+func (a *AdminPort) Valid(port uint) error {
+	if a.Port == 0 {
+		return nil
+	}
+	if a.Port == port {
+		return fmt.Errorf("admin-port.port (%d) should be different from port", a.Port)
+	}
+	if len(a.Users) == 0 {
+		return fmt.Errorf("admin-port.users should not be empty when the admin port is enabled")
+	}
+	for _, user := range a.Users {
+		if name, _, _ := strings.Cut(user, "@"); name == "" {
+			return fmt.Errorf("invalid admin-port.users %q", user)
+		}
+	}
+	return nil
+}
+
 // Binlog is the config for binlog.
 type Binlog struct {
 	Enable bool `toml:"enable" json:"enable"`
@@ -914,6 +955,11 @@ var defaultConf = Config{
 		Networks:      "",
 		HeaderTimeout: 5,
 	},
+	AdminPort: AdminPort{
+		Port:  0,
+		Host:  "",
+		Users: []string{},
+	},
 	PreparedPlanCache: PreparedPlanCache{
 		Enabled:          true,
 		Capacity:         100,
@@ -1222,6 +1268,10 @@ func (c *Config) Valid() error {
 		return fmt.Errorf("table-column-limit should be [%d, %d]", DefIndexLimit, DefMaxOfTableColumnCountLimit)
 	}
 
+	if err := c.AdminPort.Valid(c.Port); err != nil {
+		return err
+	}
+
 	// txn-local-latches
 	if err := c.TxnLocalLatches.Valid(); err != nil {
 		return err
@@ -1004,6 +1004,20 @@ func TestTxnTotalSizeLimitValid(t *testing.T) {
 	}
 }
 
+func TestAdminPortValid(t *testing.T) {
+	conf := NewConfig()
+	require.NoError(t, conf.Valid())
+
+	conf.AdminPort.Port = 4001
+	require.EqualError(t, conf.Valid(), "admin-port.users should not be empty when the admin port is enabled")
+	conf.AdminPort.Users = []string{"root@127.0.0.1", "@%"}
+	require.EqualError(t, conf.Valid(), `invalid admin-port.users "@%"`)
+	conf.AdminPort.Users = []string{"root@127.0.0.1", "admin"}
+	require.NoError(t, conf.Valid())
+	conf.AdminPort.Port = conf.Port
+	require.EqualError(t, conf.Valid(), "admin-port.port (4000) should be different from port")
+}
+
 func TestConflictInstanceConfig(t *testing.T) {
 	var expectedNewName string
 	conf := new(Config)
@@ -353,6 +353,10 @@ func onSetDefaultValue(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, _ er
 }
 
 func needChangeColumnData(oldCol, newCol *model.ColumnInfo) bool {
+	// The lazy default values can't be told from NULLs once the column is nullable, so they are written into the rows.
+	if oldCol.HasLazyOriginDefault() && !mysql.HasNotNullFlag(newCol.GetFlag()) {
+		return true
+	}
 	toUnsigned := mysql.HasUnsignedFlag(newCol.GetFlag())
 	originUnsigned := mysql.HasUnsignedFlag(oldCol.GetFlag())
 	needTruncationOrToggleSign := func() bool {
@@ -557,6 +561,8 @@ func (w *worker) onModifyColumn(d *ddlCtx, t *meta.Meta, job *model.Job) (ver in
 		changingCol = modifyInfo.newCol.Clone()
 		changingCol.Name = newColName
 		changingCol.ChangeStateInfo = &model.ChangeStateInfo{DependencyColumnOffset: oldCol.Offset}
+		// All the rows are written with the values of the changing column by the reorganization.
+		changingCol.OriginDefaultFunc, changingCol.OriginDefaultSeed = "", 0
 
 		originDefVal, err := GetOriginDefaultValueForModifyColumn(newContext(d.store), changingCol, oldCol)
 		if err != nil {
@@ -2421,3 +2421,29 @@ func TestColumnTypeChangeTimestampToInt(t *testing.T) {
 	tk.MustExec("alter table t add index idx1(id, c1);")
 	tk.MustExec("admin check table t")
 }
+
+func TestColumnTypeChangeWithReorgPriority(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (c1 int, c2 int)")
+	tk.MustExec("insert into t values (1, 1)")
+
+	tbl := external.GetTableByName(t, tk, "test", "t")
+	hook := &ddl.TestDDLCallback{Do: dom}
+	var jobPriority int
+	hook.OnJobRunBeforeExported = func(job *model.Job) {
+		if job.TableID == tbl.Meta().ID && job.Type == model.ActionModifyColumn {
+			jobPriority = job.Priority
+		}
+	}
+	dom.DDL().SetHook(hook)
+
+	tk.MustExec("set @@tidb_ddl_reorg_priority = 'PRIORITY_HIGH'")
+	tk.MustExec("alter table t modify column c2 varchar(10)")
+	require.Equal(t, kv.PriorityHigh, jobPriority)
+
+	tk.MustExec("set @@tidb_ddl_reorg_priority = 'PRIORITY_LOW'")
+	tk.MustExec("alter table t modify column c1 varchar(10)")
+	require.Equal(t, kv.PriorityLow, jobPriority)
+}
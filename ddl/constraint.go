@@ -0,0 +1,485 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/format"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/dbterror"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+// constraintChecker checks whether the expression of a CHECK constraint only refers to the
+// deterministic built-in functions and the columns of the table.
+type constraintChecker struct {
+	name    string
+	tblName model.CIStr
+	err     error
+}
+
+func (c *constraintChecker) Enter(inNode ast.Node) (outNode ast.Node, skipChildren bool) {
+	switch node := inNode.(type) {
+	case *ast.FuncCallExpr:
+		_, isBlocked := expression.IllegalFunctions4GeneratedColumns[node.FnName.L]
+		if isBlocked || !expression.IsFunctionSupported(node.FnName.L) {
+			c.err = dbterror.ErrCheckConstraintNamedFunctionIsNotAllowed.GenWithStackByArgs(c.name, node.FnName.O)
+			return inNode, true
+		}
+	case *ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.ValuesExpr, *ast.AggregateFuncExpr, *ast.WindowFuncExpr:
+		c.err = dbterror.ErrCheckConstraintFunctionIsNotAllowed.GenWithStackByArgs(c.name)
+		return inNode, true
+	case *ast.VariableExpr:
+		c.err = dbterror.ErrCheckConstraintVariables.GenWithStackByArgs(c.name)
+		return inNode, true
+	case *ast.ColumnName:
+		// The constraint is stored without the qualifiers, so it still works after the table is renamed.
+		if node.Schema.L != "" || (node.Table.L != "" && node.Table.L != c.tblName.L) {
+			c.err = dbterror.ErrCheckConstraintRefersUnknownColumn.GenWithStackByArgs(c.name, node.String())
+			return inNode, true
+		}
+		node.Table = model.CIStr{}
+	}
+	return inNode, false
+}
+
+func (c *constraintChecker) Leave(inNode ast.Node) (node ast.Node, ok bool) {
+	return inNode, c.err == nil
+}
+
+// buildConstraintInfo builds the ConstraintInfo of the CHECK constraint on the table, the
+// constraint is checked against the public columns of the table.
+func buildConstraintInfo(ctx sessionctx.Context, tblInfo *model.TableInfo, constr *ast.Constraint, state model.SchemaState) (*model.ConstraintInfo, error) {
+	var name model.CIStr
+	if constr.Name == "" {
+		name = generateConstraintName(tblInfo)
+	} else {
+		name = model.NewCIStr(constr.Name)
+		if err := checkTooLongIndex(name); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if tblInfo.FindConstraintInfoByName(name.L) != nil {
+			return nil, dbterror.ErrCheckConstraintDupName.GenWithStackByArgs(name.O)
+		}
+	}
+
+	checker := &constraintChecker{name: name.O, tblName: tblInfo.Name}
+	constr.Expr.Accept(checker)
+	if checker.err != nil {
+		return nil, errors.Trace(checker.err)
+	}
+
+	colNames := FindColumnNamesInExpr(constr.Expr)
+	dependedCols := make([]model.CIStr, 0, len(colNames))
+	dependedColSet := make(map[string]struct{}, len(colNames))
+	for _, colName := range colNames {
+		col := model.FindColumnInfo(tblInfo.Cols(), colName.Name.L)
+		if col == nil {
+			return nil, dbterror.ErrCheckConstraintRefersUnknownColumn.GenWithStackByArgs(name.O, colName.Name.O)
+		}
+		if mysql.HasAutoIncrementFlag(col.GetFlag()) {
+			return nil, dbterror.ErrCheckConstraintRefersAutoIncrementColumn.GenWithStackByArgs(name.O)
+		}
+		if constr.InColumn && col.Name.L != strings.ToLower(constr.InColumnName) {
+			return nil, dbterror.ErrColumnCheckConstraintReferencesOtherColumn.GenWithStackByArgs(name.O)
+		}
+		if _, ok := dependedColSet[col.Name.L]; !ok {
+			dependedColSet[col.Name.L] = struct{}{}
+			dependedCols = append(dependedCols, col.Name)
+		}
+	}
+
+	var sb strings.Builder
+	restoreFlags := format.RestoreStringSingleQuotes | format.RestoreKeyWordLowercase | format.RestoreNameBackQuotes |
+		format.RestoreSpacesAroundBinaryOperation
+	if err := constr.Expr.Restore(format.NewRestoreCtx(restoreFlags, &sb)); err != nil {
+		return nil, errors.Trace(err)
+	}
+	expr, err := expression.ParseSimpleExprWithTableInfo(ctx, sb.String(), tblInfo)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !mysql.HasIsBooleanFlag(expr.GetType().GetFlag()) {
+		return nil, dbterror.ErrNonBooleanExprForCheckConstraint.GenWithStackByArgs(name.O)
+	}
+
+	return &model.ConstraintInfo{
+		Name:           name,
+		Table:          tblInfo.Name,
+		ConstraintCols: dependedCols,
+		Enforced:       constr.Enforced,
+		InColumn:       constr.InColumn,
+		ExprString:     sb.String(),
+		State:          state,
+	}, nil
+}
+
+// generateConstraintName generates the name of the CHECK constraint in the form of
+// `<table>_chk_<n>` like MySQL.
+func generateConstraintName(tblInfo *model.TableInfo) model.CIStr {
+	for i := 1; ; i++ {
+		name := fmt.Sprintf("%s_chk_%d", tblInfo.Name.O, i)
+		if tblInfo.FindConstraintInfoByName(name) == nil {
+			return model.NewCIStr(name)
+		}
+	}
+}
+
+func allocateConstraintID(tblInfo *model.TableInfo) int64 {
+	tblInfo.MaxConstraintID++
+	return tblInfo.MaxConstraintID
+}
+
+// findDependentCheckConstraint returns the CHECK constraint using the column.
+func findDependentCheckConstraint(tblInfo *model.TableInfo, colName model.CIStr) *model.ConstraintInfo {
+	for _, constr := range tblInfo.Constraints {
+		for _, col := range constr.ConstraintCols {
+			if col.L == colName.L {
+				return constr
+			}
+		}
+	}
+	return nil
+}
+
+func removeConstraintInfo(tblInfo *model.TableInfo, name model.CIStr) {
+	constraints := tblInfo.Constraints[:0]
+	for _, constr := range tblInfo.Constraints {
+		if constr.Name.L != name.L {
+			constraints = append(constraints, constr)
+		}
+	}
+	tblInfo.Constraints = constraints
+}
+
+func (w *worker) onAddCheckConstraint(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, err error) {
+	dbInfo, tblInfo, constrInfoInJob, err := checkAddCheckConstraint(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if job.IsRollingback() {
+		return rollingbackAddCheckConstraint(d, t, job, tblInfo, constrInfoInJob)
+	}
+
+	constrInfo := tblInfo.FindConstraintInfoByName(constrInfoInJob.Name.L)
+	if constrInfo == nil {
+		constrInfo = constrInfoInJob
+		constrInfo.ID = allocateConstraintID(tblInfo)
+		tblInfo.Constraints = append(tblInfo.Constraints, constrInfo)
+	}
+
+	originalState := constrInfo.State
+	switch constrInfo.State {
+	case model.StateNone:
+		// none -> write only
+		// The constraint is enforced on the written rows first, so no violated row is written
+		// after the existing rows are validated.
+		job.SchemaState = model.StateWriteOnly
+		constrInfo.State = model.StateWriteOnly
+		ver, err = updateVersionAndTableInfoWithCheck(d, t, job, tblInfo, originalState != constrInfo.State)
+	case model.StateWriteOnly:
+		// write only -> public
+		if constrInfo.Enforced {
+			err = w.verifyRemainRecordsForCheckConstraint(dbInfo, tblInfo, constrInfo)
+			if err != nil {
+				if dbterror.ErrCheckConstraintIsViolated.Equal(err) {
+					job.State = model.JobStateRollingback
+				}
+				return ver, errors.Trace(err)
+			}
+			constrInfo.ValidatedTS = t.StartTS
+		}
+		constrInfo.State = model.StatePublic
+		ver, err = updateVersionAndTableInfo(d, t, job, tblInfo, originalState != constrInfo.State)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		job.FinishTableJob(model.JobStateDone, model.StatePublic, ver, tblInfo)
+	default:
+		err = dbterror.ErrInvalidDDLState.GenWithStackByArgs("check constraint", constrInfo.State)
+	}
+	return ver, errors.Trace(err)
+}
+
+func checkAddCheckConstraint(t *meta.Meta, job *model.Job) (*model.DBInfo, *model.TableInfo, *model.ConstraintInfo, error) {
+	schemaID := job.SchemaID
+	dbInfo, err := checkSchemaExistAndCancelNotExistJob(t, job)
+	if err != nil {
+		return nil, nil, nil, errors.Trace(err)
+	}
+	tblInfo, err := GetTableInfoAndCancelFaultJob(t, job, schemaID)
+	if err != nil {
+		return nil, nil, nil, errors.Trace(err)
+	}
+	constrInfo := &model.ConstraintInfo{}
+	err = job.DecodeArgs(constrInfo)
+	if err != nil {
+		job.State = model.JobStateCancelled
+		return nil, nil, nil, errors.Trace(err)
+	}
+	// The constraint may be added by another job before this job starts.
+	existing := tblInfo.FindConstraintInfoByName(constrInfo.Name.L)
+	if existing != nil && existing.ID != constrInfo.ID && job.SchemaState == model.StateNone {
+		job.State = model.JobStateCancelled
+		return nil, nil, nil, dbterror.ErrCheckConstraintDupName.GenWithStackByArgs(constrInfo.Name.O)
+	}
+	for _, colName := range constrInfo.ConstraintCols {
+		if model.FindColumnInfo(tblInfo.Cols(), colName.L) == nil {
+			job.State = model.JobStateCancelled
+			return nil, nil, nil, dbterror.ErrCheckConstraintRefersUnknownColumn.GenWithStackByArgs(constrInfo.Name.O, colName.O)
+		}
+	}
+	return dbInfo, tblInfo, constrInfo, nil
+}
+
+func rollingbackAddCheckConstraint(d *ddlCtx, t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, constrInfo *model.ConstraintInfo) (ver int64, err error) {
+	removeConstraintInfo(tblInfo, constrInfo.Name)
+	ver, err = updateVersionAndTableInfo(d, t, job, tblInfo, true)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.FinishTableJob(model.JobStateRollbackDone, model.StateNone, ver, tblInfo)
+	return ver, nil
+}
+
+func onDropCheckConstraint(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	tblInfo, err := GetTableInfoAndCancelFaultJob(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	var constrName model.CIStr
+	if err = job.DecodeArgs(&constrName); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	constrInfo := tblInfo.FindConstraintInfoByName(constrName.L)
+	if constrInfo == nil {
+		job.State = model.JobStateCancelled
+		return ver, dbterror.ErrCheckConstraintNotFound.GenWithStackByArgs(constrName.O)
+	}
+
+	switch constrInfo.State {
+	case model.StatePublic:
+		// Dropping a constraint only makes the writing less strict, so it's dropped at once.
+		// public -> none
+		removeConstraintInfo(tblInfo, constrName)
+		ver, err = updateVersionAndTableInfo(d, t, job, tblInfo, true)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		job.FinishTableJob(model.JobStateDone, model.StateNone, ver, tblInfo)
+		return ver, nil
+	default:
+		return ver, dbterror.ErrInvalidDDLState.GenWithStackByArgs("check constraint", constrInfo.State)
+	}
+}
+
+func (w *worker) onAlterCheckConstraint(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, err error) {
+	dbInfo, err := checkSchemaExistAndCancelNotExistJob(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	tblInfo, err := GetTableInfoAndCancelFaultJob(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	var (
+		constrName  model.CIStr
+		enforced    bool
+		wasEnforced bool
+	)
+	if err = job.DecodeArgs(&constrName, &enforced, &wasEnforced); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+	constrInfo := tblInfo.FindConstraintInfoByName(constrName.L)
+	if constrInfo == nil {
+		job.State = model.JobStateCancelled
+		return ver, dbterror.ErrCheckConstraintNotFound.GenWithStackByArgs(constrName.O)
+	}
+	if job.IsRollingback() {
+		constrInfo.Enforced = wasEnforced
+		constrInfo.ValidatedTS = 0
+		constrInfo.State = model.StatePublic
+		ver, err = updateVersionAndTableInfo(d, t, job, tblInfo, true)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		job.FinishTableJob(model.JobStateRollbackDone, model.StatePublic, ver, tblInfo)
+		return ver, nil
+	}
+
+	// Not enforcing a constraint only makes the writing less strict, so it's done at once.
+	if !enforced {
+		constrInfo.Enforced = false
+		constrInfo.ValidatedTS = 0
+		ver, err = updateVersionAndTableInfo(d, t, job, tblInfo, true)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		job.FinishTableJob(model.JobStateDone, model.StatePublic, ver, tblInfo)
+		return ver, nil
+	}
+
+	switch constrInfo.State {
+	case model.StatePublic:
+		// public -> write only
+		// Enforce the constraint on the written rows before validating the existing rows.
+		constrInfo.Enforced = true
+		constrInfo.State = model.StateWriteOnly
+		job.SchemaState = model.StateWriteOnly
+		ver, err = updateVersionAndTableInfoWithCheck(d, t, job, tblInfo, true)
+	case model.StateWriteOnly:
+		// write only -> public
+		err = w.verifyRemainRecordsForCheckConstraint(dbInfo, tblInfo, constrInfo)
+		if err != nil {
+			if dbterror.ErrCheckConstraintIsViolated.Equal(err) {
+				job.State = model.JobStateRollingback
+			}
+			return ver, errors.Trace(err)
+		}
+		constrInfo.ValidatedTS = t.StartTS
+		constrInfo.State = model.StatePublic
+		ver, err = updateVersionAndTableInfo(d, t, job, tblInfo, true)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		job.FinishTableJob(model.JobStateDone, model.StatePublic, ver, tblInfo)
+	default:
+		err = dbterror.ErrInvalidDDLState.GenWithStackByArgs("check constraint", constrInfo.State)
+	}
+	return ver, errors.Trace(err)
+}
+
+// verifyRemainRecordsForCheckConstraint checks whether the existing rows of the table satisfy
+// the CHECK constraint.
+func (w *worker) verifyRemainRecordsForCheckConstraint(dbInfo *model.DBInfo, tblInfo *model.TableInfo, constrInfo *model.ConstraintInfo) error {
+	sctx, err := w.sessPool.get()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer w.sessPool.put(sctx)
+
+	// The expression is restored from the AST, escape the '%' in it for the SQL formatting.
+	sql := "select 1 from %n.%n where not (" + strings.ReplaceAll(constrInfo.ExprString, "%", "%%") + ") limit 1"
+	//nolint:forcetypeassert
+	rows, _, err := sctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(w.ctx, nil, sql, dbInfo.Name.L, tblInfo.Name.L)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(rows) != 0 {
+		return dbterror.ErrCheckConstraintIsViolated.GenWithStackByArgs(constrInfo.Name.O)
+	}
+	return nil
+}
+
+func (d *ddl) CreateCheckConstraint(ctx sessionctx.Context, ti ast.Ident, constr *ast.Constraint) error {
+	schema, t, err := d.getSchemaAndTableByIdent(ctx, ti)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if constr.Name != "" && t.Meta().FindConstraintInfoByName(constr.Name) != nil {
+		return dbterror.ErrCheckConstraintDupName.GenWithStackByArgs(constr.Name)
+	}
+	constrInfo, err := buildConstraintInfo(ctx, t.Meta(), constr, model.StateNone)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		SchemaName: schema.Name.L,
+		TableName:  t.Meta().Name.L,
+		Type:       model.ActionAddCheckConstraint,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{constrInfo},
+	}
+	err = d.DoDDLJob(ctx, job)
+	err = d.callHookOnChanged(job, err)
+	return errors.Trace(err)
+}
+
+func (d *ddl) DropCheckConstraint(ctx sessionctx.Context, ti ast.Ident, constrName model.CIStr) error {
+	schema, t, err := d.getSchemaAndTableByIdent(ctx, ti)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if t.Meta().FindConstraintInfoByName(constrName.L) == nil {
+		return dbterror.ErrCheckConstraintNotFound.GenWithStackByArgs(constrName.O)
+	}
+
+	job := &model.Job{
+		SchemaID:    schema.ID,
+		TableID:     t.Meta().ID,
+		SchemaName:  schema.Name.L,
+		SchemaState: model.StatePublic,
+		TableName:   t.Meta().Name.L,
+		Type:        model.ActionDropCheckConstraint,
+		BinlogInfo:  &model.HistoryInfo{},
+		Args:        []interface{}{constrName},
+	}
+	err = d.DoDDLJob(ctx, job)
+	err = d.callHookOnChanged(job, err)
+	return errors.Trace(err)
+}
+
+func (d *ddl) AlterCheckConstraint(ctx sessionctx.Context, ti ast.Ident, constrName model.CIStr, enforced bool) error {
+	schema, t, err := d.getSchemaAndTableByIdent(ctx, ti)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	constrInfo := t.Meta().FindConstraintInfoByName(constrName.L)
+	if constrInfo == nil {
+		return dbterror.ErrCheckConstraintNotFound.GenWithStackByArgs(constrName.O)
+	}
+	// Enforcing an enforced constraint validates the existing rows again, which may be written
+	// while tidb_enable_check_constraint is off.
+	if !enforced && !constrInfo.Enforced {
+		return nil
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		SchemaName: schema.Name.L,
+		TableName:  t.Meta().Name.L,
+		Type:       model.ActionAlterCheckConstraint,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{constrName, enforced, constrInfo.Enforced},
+	}
+	err = d.DoDDLJob(ctx, job)
+	err = d.callHookOnChanged(job, err)
+	return errors.Trace(err)
+}
+
+// checkConstraintsEnabled reports whether the CHECK constraints are enforced, otherwise they're
+// parsed and ignored with a warning.
+func checkConstraintsEnabled(ctx sessionctx.Context, clause string) bool {
+	if variable.EnableCheckConstraint.Load() {
+		return true
+	}
+	ctx.GetSessionVars().StmtCtx.AppendWarning(dbterror.ErrUnsupportedConstraintCheck.GenWithStackByArgs(clause))
+	return false
+}
@@ -2134,6 +2134,78 @@ func TestDefaultColumnWithUUID(t *testing.T) {
 			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin"))
 }
 
+func TestAddColumnWithLazyDefault(t *testing.T) {
+	store := testkit.CreateMockStoreWithSchemaLease(t, testLease)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t (a int primary key, b int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3)")
+	tk.MustGetErrCode("alter table t add column c varchar(36) not null default (uuid())", errno.ErrBinlogUnsafeSystemFunction)
+	tk.MustExec("set @@global.tidb_enable_lazy_column_default = 1")
+	defer tk.MustExec("set @@global.tidb_enable_lazy_column_default = 0")
+	tk.MustExec("alter table t add column c varchar(36) not null default (uuid())")
+	tk.MustExec("alter table t add column d double not null default (rand())")
+
+	// The existing rows get distinct values, which don't change until the rows are written.
+	rows := tk.MustQuery("select a, c, d from t order by a").Rows()
+	uuids := make(map[string]struct{}, len(rows))
+	for _, row := range rows {
+		uuids[row[1].(string)] = struct{}{}
+		require.Len(t, row[1].(string), 36)
+		d, err := strconv.ParseFloat(row[2].(string), 64)
+		require.NoError(t, err)
+		require.True(t, 0 <= d && d < 1)
+	}
+	require.Len(t, uuids, 3)
+	tk.MustQuery("select a, c, d from t order by a").Check(rows)
+	tk.MustQuery("select a, c, d from t where a = 2").Check(rows[1:2])
+	tk.MustQuery("select a, c, d from t where a in (1, 3) order by a").Check([][]interface{}{rows[0], rows[2]})
+	tk.MustQuery(fmt.Sprintf("select a from t where c = '%s'", rows[2][1])).Check(testkit.Rows("3"))
+	// The function evaluating the lazy values is internal.
+	tk.MustGetErrCode("select tidb_lazy_default(c, a, 'uuid', 1) from t", errno.ErrSpDoesNotExist)
+
+	// The values are kept when the rows are written.
+	tk.MustExec("insert into t(a, b) values (4, 4)")
+	tk.MustExec("update t set b = 10 where a = 1")
+	tk.MustExec("insert into t(a, b) values (2, 0) on duplicate key update b = 20")
+	tk.MustQuery("select c, d from t where a <= 3 order by a").Check([][]interface{}{rows[0][1:], rows[1][1:], rows[2][1:]})
+	require.Len(t, tk.MustQuery("select distinct c from t").Rows(), 4)
+
+	// The index is backfilled with the lazy values.
+	tk.MustExec("alter table t add index idx(c)")
+	tk.MustExec("admin check table t")
+	tk.MustQuery(fmt.Sprintf("select a from t use index(idx) where c = '%s'", rows[2][1])).Check(testkit.Rows("3"))
+
+	// The lazy values are written into the rows when the column becomes nullable.
+	tk.MustExec("alter table t modify column c varchar(36) null")
+	tk.MustExec("admin check table t")
+	tk.MustQuery("select a, c from t where a <= 3 order by a").Check([][]interface{}{rows[0][:2], rows[1][:2], rows[2][:2]})
+
+	// The tables with the row IDs as the handles.
+	tk.MustExec("create table t1 (a int)")
+	tk.MustExec("insert into t1 values (1), (2)")
+	tk.MustExec("alter table t1 add column b varchar(40) not null default (uuid())")
+	require.Len(t, tk.MustQuery("select distinct b from t1").Rows(), 2)
+	tk.MustExec("alter table t1 add column c double(10, 4) not null default (rand())")
+	rows = tk.MustQuery("select a, b, c from t1 order by a").Rows()
+	tk.MustExec("update t1 set a = a + 10")
+	tk.MustQuery("select a - 10, b, c from t1 order by a").Check(rows)
+	tk.MustExec("delete from t1 where a = 11")
+	require.Len(t, tk.MustQuery("select b from t1").Rows(), 1)
+
+	tk.MustGetErrCode("alter table t1 add column d varchar(36) default (uuid())", errno.ErrBinlogUnsafeSystemFunction)
+	tk.MustGetErrCode("alter table t1 add column d int not null default (uuid())", errno.ErrBinlogUnsafeSystemFunction)
+	tk.MustGetErrCode("alter table t1 add column d int not null default (rand())", errno.ErrBinlogUnsafeSystemFunction)
+	tk.MustGetErrCode("alter table t1 add column d varchar(10) not null default (uuid())", errno.ErrDataTooLong)
+	tk.MustExec("drop table t1")
+	tk.MustExec("create table t1 (a varchar(10) primary key clustered)")
+	tk.MustGetErrCode("alter table t1 add column c varchar(36) not null default (uuid())", errno.ErrBinlogUnsafeSystemFunction)
+	tk.MustExec("drop table t1")
+	tk.MustExec("create table t1 (a int) partition by hash(a) partitions 2")
+	tk.MustGetErrCode("alter table t1 add column c varchar(36) not null default (uuid())", errno.ErrBinlogUnsafeSystemFunction)
+}
+
 func TestChangingDBCharset(t *testing.T) {
 	store := testkit.CreateMockStore(t, mockstore.WithDDLChecker())
 
@@ -4000,3 +4072,106 @@ func TestDDLLastInfo(t *testing.T) {
 	tk.MustExec("drop table t, t2")
 	tk.MustQuery("select json_extract(@@tidb_last_ddl_info, '$.query'), json_extract(@@tidb_last_ddl_info, '$.seq_num')").Check(testkit.Rows(fmt.Sprintf("\"drop table t, t2\" %d", firstSequence+3)))
 }
+
+func TestCheckConstraint(t *testing.T) {
+	store := testkit.CreateMockStoreWithSchemaLease(t, testLease)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+
+	// The constraints are ignored unless they're enabled.
+	tk.MustExec("create table t (a int check (a > 0))")
+	tk.MustQuery("show warnings").Check(testkit.Rows("Warning 8231 CONSTRAINT CHECK is not supported"))
+	tk.MustExec("insert into t values (-1)")
+	tk.MustExec("drop table t")
+
+	tk.MustExec("set @@global.tidb_enable_check_constraint = 1")
+	defer tk.MustExec("set @@global.tidb_enable_check_constraint = 0")
+	tk.MustExec("create table t (a int check (a > 0), b int not null, c varchar(10), constraint c_b check (b between 1 and 10), check (c like 'x%'))")
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  `b` int(11) NOT NULL,\n" +
+		"  `c` varchar(10) DEFAULT NULL,\n" +
+		"  CONSTRAINT `c_b` CHECK (`b` between 1 and 10),\n" +
+		"  CONSTRAINT `t_chk_1` CHECK (`c` like _utf8mb4'x%'),\n" +
+		"  CONSTRAINT `t_chk_2` CHECK (`a` > 0)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin"))
+	tk.MustExec("insert into t values (1, 1, 'x1'), (null, 2, null)")
+	tk.MustGetErrMsg("insert into t values (0, 1, 'x')", "[table:3819]Check constraint 't_chk_2' is violated.")
+	tk.MustGetErrMsg("insert into t values (1, 11, 'x')", "[table:3819]Check constraint 'c_b' is violated.")
+	tk.MustGetErrMsg("update t set c = 'y' where b = 1", "[table:3819]Check constraint 't_chk_1' is violated.")
+	tk.MustExec("update t set a = 2 where b = 1")
+	tk.MustQuery("select a, b, c from t order by b").Check(testkit.Rows("2 1 x1", "<nil> 2 <nil>"))
+
+	// The predicates contradicting the constraints build a table dual after the existing rows are validated.
+	require.False(t, tk.HasPlan("select * from t where b = 20", "TableDual"))
+	tk.MustExec("alter table t alter check c_b enforced")
+	tk.MustExec("alter table t alter check t_chk_2 enforced")
+	tk.MustQuery("explain format = 'brief' select * from t where b = 20").Check(testkit.Rows("TableDual 0.00 root  rows:0"))
+	tk.MustQuery("explain format = 'brief' select * from t where b > 10").Check(testkit.Rows("TableDual 0.00 root  rows:0"))
+	tk.MustQuery("select * from t where a < 0").Check(testkit.Rows())
+	tk.MustQuery("select * from t where a is null").Check(testkit.Rows("<nil> 2 <nil>"))
+	// The plans pruned by the constraints aren't cached, since they're wrong once the constraints are disabled.
+	tk.MustExec("prepare st from 'select a, b, c from t where b = 20'")
+	tk.MustQuery("execute st").Check(testkit.Rows())
+	tk.MustQuery("execute st").Check(testkit.Rows())
+	tk.MustQuery("select @@last_plan_from_cache").Check(testkit.Rows("0"))
+
+	// The rows written while the constraints are disabled are returned until they're validated again.
+	enabledTS := tk.MustQuery("select @@global.tidb_check_constraint_enabled_ts").Rows()[0][0].(string)
+	tk.MustExec("set @@global.tidb_enable_check_constraint = 1")
+	tk.MustQuery("select @@global.tidb_check_constraint_enabled_ts").Check(testkit.Rows(enabledTS))
+	tk.MustExec("set @@global.tidb_enable_check_constraint = 0")
+	tk.MustExec("insert into t values (1, 20, 'x')")
+	tk.MustQuery("execute st").Check(testkit.Rows("1 20 x"))
+	tk.MustExec("set @@global.tidb_enable_check_constraint = 1")
+	tk.MustQuery("select @@global.tidb_check_constraint_enabled_ts > " + enabledTS).Check(testkit.Rows("1"))
+	tk.MustQuery("select a, b, c from t where b = 20").Check(testkit.Rows("1 20 x"))
+	tk.MustGetErrMsg("alter table t alter check c_b enforced", "[ddl:3819]Check constraint 'c_b' is violated.")
+	tk.MustGetErrMsg("insert into t values (1, 20, 'x')", "[table:3819]Check constraint 'c_b' is violated.")
+	tk.MustQuery("select a, b, c from t where b = 20").Check(testkit.Rows("1 20 x"))
+	tk.MustExec("delete from t where b = 20")
+
+	// The existing rows are validated when the constraint is added or enforced.
+	tk.MustGetErrMsg("alter table t add constraint c_c check (b = 1)", "[ddl:3819]Check constraint 'c_c' is violated.")
+	tk.MustExec("alter table t add constraint c_a check (a < 10)")
+	tk.MustGetErrMsg("insert into t values (10, 1, 'x')", "[table:3819]Check constraint 'c_a' is violated.")
+	tk.MustExec("alter table t alter check c_b not enforced")
+	tk.MustExec("insert into t values (3, 20, 'x3')")
+	tk.MustGetErrMsg("alter table t alter check c_b enforced", "[ddl:3819]Check constraint 'c_b' is violated.")
+	tk.MustExec("delete from t where b = 20")
+	tk.MustExec("alter table t alter check c_b enforced")
+	tk.MustGetErrMsg("insert into t values (3, 20, 'x3')", "[table:3819]Check constraint 'c_b' is violated.")
+	tk.MustExec("alter table t drop check c_a")
+	tk.MustExec("insert into t values (10, 1, 'x')")
+	tk.MustGetErrCode("alter table t drop check c_a", errno.ErrCheckConstraintNotFound)
+
+	// The columns used by the constraints can't be dropped or renamed.
+	tk.MustGetErrCode("alter table t drop column b", errno.ErrDependentByCheckConstraint)
+	tk.MustGetErrCode("alter table t rename column b to b1", errno.ErrDependentByCheckConstraint)
+	tk.MustGetErrCode("alter table t change column b b1 int", errno.ErrDependentByCheckConstraint)
+
+	tk.MustGetErrCode("alter table t add constraint c_b check (b > 0)", errno.ErrCheckConstraintDupName)
+	tk.MustGetErrCode("create table t1 (a int check (a))", errno.ErrNonBooleanExprForCheckConstraint)
+	tk.MustGetErrCode("create table t1 (a int check (a > b), b int)", errno.ErrColumnCheckConstraintReferencesOtherColumn)
+	tk.MustGetErrCode("create table t1 (a int, check (d > 0))", errno.ErrCheckConstraintRefersUnknownColumn)
+	tk.MustGetErrCode("create table t1 (a int auto_increment primary key, check (a > 0))", errno.ErrCheckConstraintRefersAutoIncrementColumn)
+	tk.MustGetErrCode("create table t1 (a int, check (a > rand()))", errno.ErrCheckConstraintNamedFunctionIsNotAllowed)
+	tk.MustGetErrCode("create table t1 (a int, check (a > (select 1)))", errno.ErrCheckConstraintFunctionIsNotAllowed)
+	tk.MustGetErrCode("create table t1 (a int, check (a > @a))", errno.ErrCheckConstraintVariables)
+
+	// The constraints are copied by create table like.
+	tk.MustExec("create table t1 like t")
+	tk.MustGetErrMsg("insert into t1 values (1, 11, 'x')", "[table:3819]Check constraint 'c_b' is violated.")
+
+	// The constraints on the partitioned tables are checked too.
+	tk.MustExec("drop table t1")
+	tk.MustExec("create table t1 (a int, check (a < 100)) partition by hash(a) partitions 4")
+	tk.MustExec("insert into t1 values (1), (2)")
+	tk.MustGetErrMsg("insert into t1 values (100)", "[table:3819]Check constraint 't1_chk_1' is violated.")
+	tk.MustGetErrMsg("update t1 set a = a + 100", "[table:3819]Check constraint 't1_chk_1' is violated.")
+
+	// The constraints aren't checked after they're disabled.
+	tk.MustExec("set @@global.tidb_enable_check_constraint = 0")
+	tk.MustExec("insert into t1 values (100)")
+}
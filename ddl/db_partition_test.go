@@ -4298,6 +4298,77 @@ func TestCreateAndAlterIntervalPartition(t *testing.T) {
 	tk.MustExec(`drop table t`)
 }
 
+func TestIntervalPartitionAutoCreate(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+
+	tk.MustExec("create table t (id int, d date) partition by range columns (d) INTERVAL (1 MONTH) FIRST PARTITION LESS THAN ('2023-01-01') LAST PARTITION LESS THAN ('2023-03-01') NULL PARTITION AUTO CREATE RETENTION (2 MONTH)")
+	showCreate := "t CREATE TABLE `t` (\n" +
+		"  `id` int(11) DEFAULT NULL,\n" +
+		"  `d` date DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin\n" +
+		"PARTITION BY RANGE COLUMNS(`d`) /*T![auto_interval] INTERVAL (1 MONTH) AUTO CREATE RETENTION (2 MONTH) */\n"
+	tk.MustQuery("show create table t").Check(testkit.Rows(showCreate +
+		"(PARTITION `P_NULL` VALUES LESS THAN ('0000-01-01'),\n" +
+		" PARTITION `P_LT_2023-01-01` VALUES LESS THAN ('2023-01-01'),\n" +
+		" PARTITION `P_LT_2023-02-01` VALUES LESS THAN ('2023-02-01'),\n" +
+		" PARTITION `P_LT_2023-03-01` VALUES LESS THAN ('2023-03-01'))"))
+	tk.MustExec("insert into t values (1, '2022-12-31'), (2, '2023-01-31'), (3, '2023-02-28')")
+	// The partitions are created by the time, not by the inserted rows.
+	tk.MustGetErrCode("insert into t values (5, '2023-03-01')", errno.ErrNoPartitionForGivenValue)
+
+	// Nothing to do before the LAST partition gets close.
+	ddl.MaintainIntervalPartitions(dom.DDL(), tk.Session(), time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC))
+	tk.MustQuery("select count(*) from information_schema.partitions where table_schema = 'test' and table_name = 't'").Check(testkit.Rows("4"))
+
+	ddl.MaintainIntervalPartitions(dom.DDL(), tk.Session(), time.Date(2023, 4, 15, 0, 0, 0, 0, time.UTC))
+	tk.MustQuery("show create table t").Check(testkit.Rows(showCreate +
+		"(PARTITION `P_NULL` VALUES LESS THAN ('0000-01-01'),\n" +
+		" PARTITION `P_LT_2023-03-01` VALUES LESS THAN ('2023-03-01'),\n" +
+		" PARTITION `P_LT_2023-04-01` VALUES LESS THAN ('2023-04-01'),\n" +
+		" PARTITION `P_LT_2023-05-01` VALUES LESS THAN ('2023-05-01'),\n" +
+		" PARTITION `P_LT_2023-06-01` VALUES LESS THAN ('2023-06-01'))"))
+	tk.MustQuery("select id from t").Check(testkit.Rows("3"))
+	tk.MustExec("insert into t values (4, '2023-05-31')")
+
+	// The output of SHOW CREATE TABLE keeps the AUTO CREATE options.
+	createSQL := tk.MustQuery("show create table t").Rows()[0][1].(string)
+	tk.MustExec("drop table t")
+	tk.MustExec(createSQL)
+	tk.MustQuery("show create table t").Check(testkit.Rows(showCreate +
+		"(PARTITION `P_NULL` VALUES LESS THAN ('0000-01-01'),\n" +
+		" PARTITION `P_LT_2023-03-01` VALUES LESS THAN ('2023-03-01'),\n" +
+		" PARTITION `P_LT_2023-04-01` VALUES LESS THAN ('2023-04-01'),\n" +
+		" PARTITION `P_LT_2023-05-01` VALUES LESS THAN ('2023-05-01'),\n" +
+		" PARTITION `P_LT_2023-06-01` VALUES LESS THAN ('2023-06-01'))"))
+	ddl.MaintainIntervalPartitions(dom.DDL(), tk.Session(), time.Date(2023, 5, 20, 0, 0, 0, 0, time.UTC))
+	tk.MustQuery("select partition_name from information_schema.partitions where table_schema = 'test' and table_name = 't'").Check(testkit.Rows(
+		"P_NULL", "P_LT_2023-04-01", "P_LT_2023-05-01", "P_LT_2023-06-01", "P_LT_2023-07-01"))
+
+	// The partitions are created by the time in the global time_zone, it's 2023-06-30 06:00 in +10:00.
+	tk.MustExec("set @@global.time_zone = '+10:00'")
+	defer tk.MustExec("set @@global.time_zone = default")
+	ddl.MaintainIntervalPartitions(dom.DDL(), tk.Session(), time.Date(2023, 6, 29, 20, 0, 0, 0, time.UTC))
+	tk.MustQuery("select partition_name from information_schema.partitions where table_schema = 'test' and table_name = 't'").Check(testkit.Rows(
+		"P_NULL", "P_LT_2023-05-01", "P_LT_2023-06-01", "P_LT_2023-07-01", "P_LT_2023-08-01", "P_LT_2023-09-01"))
+	// The partitions are created further ahead with a longer horizon.
+	tk.MustExec("set @@global.tidb_interval_partition_horizon = '1440h'")
+	defer tk.MustExec("set @@global.tidb_interval_partition_horizon = default")
+	ddl.MaintainIntervalPartitions(dom.DDL(), tk.Session(), time.Date(2023, 6, 29, 20, 0, 0, 0, time.UTC))
+	tk.MustQuery("select partition_name from information_schema.partitions where table_schema = 'test' and table_name = 't'").Check(testkit.Rows(
+		"P_NULL", "P_LT_2023-05-01", "P_LT_2023-06-01", "P_LT_2023-07-01", "P_LT_2023-08-01", "P_LT_2023-09-01", "P_LT_2023-10-01"))
+
+	tk.MustGetErrMsg("create table t2 (id int) partition by range (id) INTERVAL (10) FIRST PARTITION LESS THAN (10) LAST PARTITION LESS THAN (30) AUTO CREATE",
+		"[ddl:8200]Unsupported INTERVAL partitioning, AUTO CREATE only supports RANGE COLUMNS with Date or Datetime type")
+	tk.MustGetErrMsg("create table t2 (d datetime) partition by range columns (d) INTERVAL (10) FIRST PARTITION LESS THAN ('2023-01-01') LAST PARTITION LESS THAN ('2023-01-02') AUTO CREATE",
+		"[ddl:8200]Unsupported INTERVAL partitioning, AUTO CREATE requires YEAR, QUARTER, MONTH, WEEK, DAY, HOUR, MINUTE or SECOND as time unit")
+	tk.MustGetErrMsg("create table t2 (d datetime) partition by range columns (d) INTERVAL (1 DAY) FIRST PARTITION LESS THAN ('2023-01-01') LAST PARTITION LESS THAN ('2023-01-03') MAXVALUE PARTITION AUTO CREATE",
+		"[ddl:8200]Unsupported INTERVAL partitioning, AUTO CREATE is not supported with MAXVALUE partition")
+	tk.MustGetErrMsg("create table t2 (d datetime) partition by range columns (d) INTERVAL (1 DAY) FIRST PARTITION LESS THAN ('2023-01-01') LAST PARTITION LESS THAN ('2023-01-03') AUTO CREATE RETENTION (0 DAY)",
+		"[ddl:8200]Unsupported INTERVAL, should be a positive number")
+}
+
 func TestPartitionTableWithAnsiQuotes(t *testing.T) {
 	store := testkit.CreateMockStore(t)
 	tk := testkit.NewTestKit(t, store)
@@ -423,7 +423,7 @@ func (dc *ddlCtx) getReorgCtx(job *model.Job) *reorgCtx {
 }
 
 func (dc *ddlCtx) newReorgCtx(r *reorgInfo) *reorgCtx {
-	rc := &reorgCtx{}
+	rc := &reorgCtx{startTime: time.Now()}
 	rc.doneCh = make(chan error, 1)
 	// initial reorgCtx
 	rc.setRowCount(r.Job.GetRowCount())
@@ -657,6 +657,10 @@ func (d *ddl) Start(ctxPool *pools.ResourcePool) error {
 	})
 
 	d.delRangeMgr = d.newDeleteRangeManager(ctxPool == nil)
+	if ctxPool != nil {
+		d.wg.Run(d.gcDDLJobTimings)
+		d.wg.Run(d.maintainIntervalPartitionsLoop)
+	}
 
 	d.prepareWorkers4ConcurrencyDDL()
 	d.prepareWorkers4legacyDDL()
@@ -1105,7 +1109,13 @@ func (d *ddl) startCleanDeadTableLock() {
 			if !d.ownerManager.IsOwner() {
 				continue
 			}
-			deadLockTables, err := d.tableLockCkr.GetDeadLockedTables(d.ctx, d.infoCache.GetLatest().AllSchemas())
+			// the table locks are in the table briefs, so the lazy schemas are not loaded.
+			var schemas []*model.DBInfo
+			infoschema.IterateSchemaTableInfos(d.infoCache.GetLatest(), func(db *model.DBInfo, tblInfos []*model.TableInfo) bool {
+				schemas = append(schemas, &model.DBInfo{ID: db.ID, Name: db.Name, Tables: tblInfos})
+				return true
+			})
+			deadLockTables, err := d.tableLockCkr.GetDeadLockedTables(d.ctx, schemas)
 			if err != nil {
 				logutil.BgLogger().Info("[ddl] get dead table lock failed.", zap.Error(err))
 				continue
@@ -21,6 +21,8 @@ package ddl
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/binary"
 	"fmt"
 	"math"
 	"strconv"
@@ -88,7 +90,10 @@ const (
 )
 
 func (d *ddl) CreateSchema(ctx sessionctx.Context, stmt *ast.CreateDatabaseStmt) (err error) {
-	var placementPolicyRef *model.PolicyRefInfo
+	var (
+		placementPolicyRef *model.PolicyRefInfo
+		readRouting        string
+	)
 	sessionVars := ctx.GetSessionVars()
 
 	// If no charset and/or collation is specified use collation_server and character_set_server
@@ -118,6 +123,8 @@ func (d *ddl) CreateSchema(ctx sessionctx.Context, stmt *ast.CreateDatabaseStmt)
 			placementPolicyRef = &model.PolicyRefInfo{
 				Name: model.NewCIStr(val.Value),
 			}
+		case ast.DatabaseOptionReadRouting:
+			readRouting = val.Value
 		}
 	}
 
@@ -148,6 +155,7 @@ func (d *ddl) CreateSchema(ctx sessionctx.Context, stmt *ast.CreateDatabaseStmt)
 	dbInfo.Charset = chs
 	dbInfo.Collate = coll
 	dbInfo.PlacementPolicyRef = placementPolicyRef
+	dbInfo.ReadRouting = readRouting
 
 	onExist := OnExistError
 	if stmt.IfNotExists {
@@ -273,6 +281,31 @@ func (d *ddl) ModifySchemaDefaultPlacement(ctx sessionctx.Context, stmt *ast.Alt
 	return errors.Trace(err)
 }
 
+// ModifySchemaReadRouting changes the read routing policy of the schema.
+func (d *ddl) ModifySchemaReadRouting(ctx sessionctx.Context, stmt *ast.AlterDatabaseStmt, readRouting string) (err error) {
+	dbName := stmt.Name
+	is := d.GetInfoSchemaWithInterceptor(ctx)
+	dbInfo, ok := is.SchemaByName(dbName)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenWithStackByArgs(dbName.O)
+	}
+	if dbInfo.ReadRouting == readRouting {
+		return nil
+	}
+
+	// Do the DDL job.
+	job := &model.Job{
+		SchemaID:   dbInfo.ID,
+		SchemaName: dbInfo.Name.L,
+		Type:       model.ActionModifySchemaReadRouting,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{readRouting},
+	}
+	err = d.DoDDLJob(ctx, job)
+	err = d.callHookOnChanged(job, err)
+	return errors.Trace(err)
+}
+
 // getPendingTiFlashTableCount counts unavailable TiFlash replica by iterating all tables in infoCache.
 func (d *ddl) getPendingTiFlashTableCount(sctx sessionctx.Context, originVersion int64, pendingCount uint32) (int64, uint32) {
 	is := d.GetInfoSchemaWithInterceptor(sctx)
@@ -525,10 +558,10 @@ func checkMultiSchemaSpecs(_sctx sessionctx.Context, specs []*ast.DatabaseOption
 func (d *ddl) AlterSchema(sctx sessionctx.Context, stmt *ast.AlterDatabaseStmt) (err error) {
 	// Resolve target charset and collation from options.
 	var (
-		toCharset, toCollate                                         string
-		isAlterCharsetAndCollate, isAlterPlacement, isTiFlashReplica bool
-		placementPolicyRef                                           *model.PolicyRefInfo
-		tiflashReplica                                               *ast.TiFlashReplicaSpec
+		toCharset, toCollate, readRouting                                                string
+		isAlterCharsetAndCollate, isAlterPlacement, isTiFlashReplica, isAlterReadRouting bool
+		placementPolicyRef                                                               *model.PolicyRefInfo
+		tiflashReplica                                                                   *ast.TiFlashReplicaSpec
 	)
 
 	err = checkMultiSchemaSpecs(sctx, stmt.Options)
@@ -563,6 +596,9 @@ func (d *ddl) AlterSchema(sctx sessionctx.Context, stmt *ast.AlterDatabaseStmt)
 		case ast.DatabaseSetTiFlashReplica:
 			tiflashReplica = val.TiFlashReplica
 			isTiFlashReplica = true
+		case ast.DatabaseOptionReadRouting:
+			readRouting = val.Value
+			isAlterReadRouting = true
 		}
 	}
 
@@ -581,6 +617,11 @@ func (d *ddl) AlterSchema(sctx sessionctx.Context, stmt *ast.AlterDatabaseStmt)
 			return err
 		}
 	}
+	if isAlterReadRouting {
+		if err = d.ModifySchemaReadRouting(sctx, stmt, readRouting); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -673,6 +714,11 @@ func setColumnFlagWithConstraint(colMap map[string]*table.Column, v *ast.Constra
 			if !ok {
 				continue
 			}
+			if isClusteredUniqueKey(v) {
+				// The clustered unique key is stored as the primary index.
+				c.AddFlag(mysql.PriKeyFlag)
+				continue
+			}
 			if i == 0 {
 				// Only the first column can be set
 				// if unique index has multi columns,
@@ -1118,7 +1164,18 @@ func columnDefToCol(ctx sessionctx.Context, offset int, colDef *ast.ColumnDef, o
 			case ast.ColumnOptionFulltext:
 				ctx.GetSessionVars().StmtCtx.AppendWarning(dbterror.ErrTableCantHandleFt.GenWithStackByArgs())
 			case ast.ColumnOptionCheck:
-				ctx.GetSessionVars().StmtCtx.AppendWarning(dbterror.ErrUnsupportedConstraintCheck.GenWithStackByArgs("CONSTRAINT CHECK"))
+				if !checkConstraintsEnabled(ctx, "CONSTRAINT CHECK") {
+					continue
+				}
+				constraint := &ast.Constraint{
+					Tp:           ast.ConstraintCheck,
+					Name:         v.ConstraintName,
+					Expr:         v.Expr,
+					Enforced:     v.Enforced,
+					InColumn:     true,
+					InColumnName: colDef.Name.Name.O,
+				}
+				constraints = append(constraints, constraint)
 			}
 		}
 	}
@@ -1792,6 +1849,18 @@ func BuildTableInfo(
 		tblColumns = append(tblColumns, table.ToColumn(v.ToInfo()))
 	}
 	for _, constr := range constraints {
+		// Check clustered on non-primary key.
+		if constr.Option != nil && constr.Option.PrimaryKeyTp != model.PrimaryKeyTypeDefault &&
+			constr.Tp != ast.ConstraintPrimaryKey {
+			if !isClusteredUniqueKey(constr) {
+				return nil, dbterror.ErrUnsupportedClusteredSecondaryKey
+			}
+			if err := checkClusteredUniqueKey(tbInfo, constraints, constr); err != nil {
+				return nil, err
+			}
+			tbInfo.IsCommonHandle = true
+			tbInfo.CommonHandleVersion = 1
+		}
 		// Build hidden columns if necessary.
 		hiddenCols, err := buildHiddenColumnInfoWithCheck(ctx, constr.Keys, model.NewCIStr(constr.Name), tbInfo, tblColumns)
 		if err != nil {
@@ -1804,11 +1873,6 @@ func BuildTableInfo(
 			tbInfo.Columns = append(tbInfo.Columns, hiddenCol)
 			tblColumns = append(tblColumns, table.ToColumn(hiddenCol))
 		}
-		// Check clustered on non-primary key.
-		if constr.Option != nil && constr.Option.PrimaryKeyTp != model.PrimaryKeyTypeDefault &&
-			constr.Tp != ast.ConstraintPrimaryKey {
-			return nil, dbterror.ErrUnsupportedClusteredSecondaryKey
-		}
 		if constr.Tp == ast.ConstraintForeignKey {
 			for _, fk := range tbInfo.ForeignKeys {
 				if fk.Name.L == strings.ToLower(constr.Name) {
@@ -1854,7 +1918,16 @@ func BuildTableInfo(
 			continue
 		}
 		if constr.Tp == ast.ConstraintCheck {
-			ctx.GetSessionVars().StmtCtx.AppendWarning(dbterror.ErrUnsupportedConstraintCheck.GenWithStackByArgs("CONSTRAINT CHECK"))
+			// The column-level CHECK constraints are only returned when they're enabled.
+			if !constr.InColumn && !checkConstraintsEnabled(ctx, "CONSTRAINT CHECK") {
+				continue
+			}
+			constrInfo, err := buildConstraintInfo(ctx, tbInfo, constr, model.StatePublic)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			constrInfo.ID = allocateConstraintID(tbInfo)
+			tbInfo.Constraints = append(tbInfo.Constraints, constrInfo)
 			continue
 		}
 
@@ -1871,6 +1944,8 @@ func BuildTableInfo(
 			indexName = mysql.PrimaryKeyName
 		case ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
 			unique = true
+			// The clustered unique key is the primary index of the table, but keeps its own name.
+			primary = isClusteredUniqueKey(constr)
 		}
 
 		// build index info.
@@ -1920,6 +1995,43 @@ func indexColumnsLen(cols []*model.ColumnInfo, idxCols []*model.IndexColumn) (co
 	return
 }
 
+// isClusteredUniqueKey checks whether the constraint is a unique key with the CLUSTERED option.
+func isClusteredUniqueKey(constr *ast.Constraint) bool {
+	switch constr.Tp {
+	case ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+		return constr.Option != nil && constr.Option.PrimaryKeyTp == model.PrimaryKeyTypeClustered
+	}
+	return false
+}
+
+// checkClusteredUniqueKey checks whether the unique key can be used to cluster the rows of the table.
+func checkClusteredUniqueKey(tbInfo *model.TableInfo, constraints []*ast.Constraint, uk *ast.Constraint) error {
+	for _, constr := range constraints {
+		if constr == uk {
+			continue
+		}
+		if constr.Tp == ast.ConstraintPrimaryKey {
+			return dbterror.ErrUnsupportedClusteredSecondaryKey.GenWithStack("CLUSTERED unique key is not supported on the table with a primary key")
+		}
+		if isClusteredUniqueKey(constr) {
+			return dbterror.ErrUnsupportedClusteredSecondaryKey.GenWithStack("Only one CLUSTERED unique key is allowed")
+		}
+	}
+	for _, key := range uk.Keys {
+		if key.Expr != nil {
+			return dbterror.ErrUnsupportedClusteredSecondaryKey.GenWithStack("CLUSTERED unique key on expression is not supported")
+		}
+		col := model.FindColumnInfo(tbInfo.Columns, key.Column.Name.L)
+		if col == nil {
+			return dbterror.ErrKeyColumnDoesNotExits.GenWithStackByArgs(key.Column.Name)
+		}
+		if !mysql.HasNotNullFlag(col.GetFlag()) {
+			return dbterror.ErrUnsupportedClusteredSecondaryKey.GenWithStack("All parts of a CLUSTERED unique key must be NOT NULL")
+		}
+	}
+	return nil
+}
+
 func isSingleIntPK(constr *ast.Constraint, lastCol *model.ColumnInfo) bool {
 	if len(constr.Keys) != 1 {
 		return false
@@ -2071,8 +2183,19 @@ func BuildTableInfoWithLike(ctx sessionctx.Context, ident ast.Ident, referTblInf
 			newIndices = append(newIndices, idx)
 		}
 	}
+	newConstraints := make([]*model.ConstraintInfo, 0, len(tblInfo.Constraints))
+	for _, constr := range tblInfo.Constraints {
+		if constr.State == model.StatePublic {
+			newConstr := constr.Clone()
+			newConstr.Table = ident.Name
+			// The rows of the new table are never validated against the constraints.
+			newConstr.ValidatedTS = 0
+			newConstraints = append(newConstraints, newConstr)
+		}
+	}
 	tblInfo.Columns = newColumns
 	tblInfo.Indices = newIndices
+	tblInfo.Constraints = newConstraints
 	tblInfo.Name = ident.Name
 	tblInfo.AutoIncID = 0
 	tblInfo.ForeignKeys = nil
@@ -2885,6 +3008,8 @@ func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) err
 			tbInfo.AutoIdCache = int64(op.UintValue)
 		case ast.TableOptionAutoRandomBase:
 			tbInfo.AutoRandID = int64(op.UintValue)
+		case ast.TableOptionCoprocessorCache:
+			tbInfo.CoprCacheDisabled = op.UintValue == 0
 		case ast.TableOptionComment:
 			tbInfo.Comment = op.StrValue
 		case ast.TableOptionCompression:
@@ -2911,6 +3036,9 @@ func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) err
 			}
 		}
 	}
+	if err := handleFederatedTableOptions(options, tbInfo); err != nil {
+		return err
+	}
 	shardingBits := shardingBits(tbInfo)
 	if tbInfo.PreSplitRegions > shardingBits {
 		tbInfo.PreSplitRegions = shardingBits
@@ -2918,6 +3046,38 @@ func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) err
 	return nil
 }
 
+// handleFederatedTableOptions sets the federated info of the table created with
+// `ENGINE=FEDERATED CONNECTION='server_name/table_name'`.
+func handleFederatedTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) error {
+	var (
+		isFederated bool
+		connection  string
+	)
+	for _, op := range options {
+		switch op.Tp {
+		case ast.TableOptionEngine:
+			isFederated = strings.EqualFold(op.StrValue, "federated")
+		case ast.TableOptionConnection:
+			connection = op.StrValue
+		}
+	}
+	if !isFederated {
+		return nil
+	}
+	if tbInfo.TempTableType != model.TempTableNone {
+		return errors.Trace(dbterror.ErrOptOnTemporaryTable.GenWithStackByArgs("engine federated"))
+	}
+	server, table, ok := strings.Cut(connection, "/")
+	if !ok || len(server) == 0 || len(table) == 0 || strings.Contains(table, "/") {
+		return errors.Trace(dbterror.ErrForeignDataStringInvalidCantCreate.GenWithStackByArgs(connection))
+	}
+	tbInfo.Federated = &model.FederatedInfo{
+		Server: server,
+		Table:  table,
+	}
+	return nil
+}
+
 func shardingBits(tblInfo *model.TableInfo) uint64 {
 	if tblInfo.ShardRowIDBits > 0 {
 		return tblInfo.ShardRowIDBits
@@ -3173,7 +3333,9 @@ func (d *ddl) AlterTable(ctx context.Context, sctx sessionctx.Context, stmt *ast
 			case ast.ConstraintFulltext:
 				sctx.GetSessionVars().StmtCtx.AppendWarning(dbterror.ErrTableCantHandleFt)
 			case ast.ConstraintCheck:
-				sctx.GetSessionVars().StmtCtx.AppendWarning(dbterror.ErrUnsupportedConstraintCheck.GenWithStackByArgs("ADD CONSTRAINT CHECK"))
+				if checkConstraintsEnabled(sctx, "ADD CONSTRAINT CHECK") {
+					err = d.CreateCheckConstraint(sctx, ident, constr)
+				}
 			default:
 				// Nothing to do now.
 			}
@@ -3214,6 +3376,8 @@ func (d *ddl) AlterTable(ctx context.Context, sctx sessionctx.Context, stmt *ast
 					err = d.AlterTableAutoIDCache(sctx, ident, int64(opt.UintValue))
 				case ast.TableOptionAutoRandomBase:
 					err = d.RebaseAutoID(sctx, ident, int64(opt.UintValue), autoid.AutoRandomType, opt.BoolValue)
+				case ast.TableOptionCoprocessorCache:
+					err = d.AlterTableCoprCache(sctx, ident, opt.UintValue == 0)
 				case ast.TableOptionComment:
 					spec.Comment = opt.StrValue
 					err = d.AlterTableComment(sctx, ident, spec)
@@ -3255,9 +3419,13 @@ func (d *ddl) AlterTable(ctx context.Context, sctx sessionctx.Context, stmt *ast
 		case ast.AlterTableIndexInvisible:
 			err = d.AlterIndexVisibility(sctx, ident, spec.IndexName, spec.Visibility)
 		case ast.AlterTableAlterCheck:
-			sctx.GetSessionVars().StmtCtx.AppendWarning(dbterror.ErrUnsupportedConstraintCheck.GenWithStackByArgs("ALTER CHECK"))
+			if checkConstraintsEnabled(sctx, "ALTER CHECK") {
+				err = d.AlterCheckConstraint(sctx, ident, model.NewCIStr(spec.Constraint.Name), spec.Constraint.Enforced)
+			}
 		case ast.AlterTableDropCheck:
-			sctx.GetSessionVars().StmtCtx.AppendWarning(dbterror.ErrUnsupportedConstraintCheck.GenWithStackByArgs("DROP CHECK"))
+			if checkConstraintsEnabled(sctx, "DROP CHECK") {
+				err = d.DropCheckConstraint(sctx, ident, model.NewCIStr(spec.Constraint.Name))
+			}
 		case ast.AlterTableWithValidation:
 			sctx.GetSessionVars().StmtCtx.AppendWarning(dbterror.ErrUnsupportedAlterTableWithValidation)
 		case ast.AlterTableWithoutValidation:
@@ -3459,6 +3627,7 @@ func checkAndCreateNewColumn(ctx sessionctx.Context, ti ast.Ident, schema *model
 
 // CreateNewColumn creates a new column according to the column information.
 func CreateNewColumn(ctx sessionctx.Context, ti ast.Ident, schema *model.DBInfo, spec *ast.AlterTableSpec, t table.Table, specNewColumn *ast.ColumnDef) (*table.Column, error) {
+	var lazyDefaultFunc string
 	// If new column is a generated column, do validation.
 	// NOTE: we do check whether the column refers other generated
 	// columns occurring later in a table, but we don't handle the col offset.
@@ -3505,7 +3674,8 @@ func CreateNewColumn(ctx sessionctx.Context, ti ast.Ident, schema *model.DBInfo,
 					}
 					return nil, errors.Trace(dbterror.ErrAddColumnWithSequenceAsDefault.GenWithStackByArgs(specNewColumn.Name.Name.O))
 				case ast.Rand, ast.UUID:
-					return nil, errors.Trace(dbterror.ErrBinlogUnsafeSystemFunction.GenWithStackByArgs())
+					// The values of the existing rows are evaluated lazily, see setLazyOriginDefault.
+					lazyDefaultFunc = f.FnName.L
 				}
 			}
 		}
@@ -3520,7 +3690,7 @@ func CreateNewColumn(ctx sessionctx.Context, ti ast.Ident, schema *model.DBInfo,
 	}
 	// Ignore table constraints now, they will be checked later.
 	// We use length(t.Cols()) as the default offset firstly, we will change the column's offset later.
-	col, _, err := buildColumnAndConstraint(
+	col, cts, err := buildColumnAndConstraint(
 		ctx,
 		len(t.Cols()),
 		specNewColumn,
@@ -3531,6 +3701,16 @@ func CreateNewColumn(ctx sessionctx.Context, ti ast.Ident, schema *model.DBInfo,
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	for _, constr := range cts {
+		if constr.Tp == ast.ConstraintCheck {
+			ctx.GetSessionVars().StmtCtx.AppendWarning(dbterror.ErrUnsupportedConstraintCheck.GenWithStackByArgs("ADD COLUMN CHECK"))
+		}
+	}
+
+	if len(lazyDefaultFunc) != 0 {
+		err = setLazyOriginDefault(ctx, t.Meta(), col.ToInfo(), lazyDefaultFunc)
+		return col, errors.Trace(err)
+	}
 
 	originDefVal, err := generateOriginDefaultValue(col.ToInfo(), ctx)
 	if err != nil {
@@ -3541,6 +3721,50 @@ func CreateNewColumn(ctx sessionctx.Context, ti ast.Ident, schema *model.DBInfo,
 	return col, err
 }
 
+// setLazyOriginDefault makes the values of the column added with a non-constant default function evaluated lazily for
+// the existing rows, so the column is added instantly without rewriting the rows. The value of a row is a pseudo random
+// function of the handle of the row and a random seed of the column, which needs the column to be NOT NULL to tell the
+// rows existing before from the others, and the handles to be unique in the table.
+// Otherwise, the column can't be added like MySQL, and the reason is returned as a warning.
+func setLazyOriginDefault(ctx sessionctx.Context, tblInfo *model.TableInfo, col *model.ColumnInfo, funcName string) error {
+	unsupported := func(reason string) error {
+		ctx.GetSessionVars().StmtCtx.AppendWarning(errors.Errorf("the default value %s() of column '%s' can't be evaluated lazily when %s", funcName, col.Name, reason))
+		return dbterror.ErrBinlogUnsafeSystemFunction.GenWithStackByArgs()
+	}
+	switch {
+	case !variable.EnableLazyColumnDefault.Load():
+		return unsupported(variable.TiDBEnableLazyColumnDefault + " is off")
+	case !mysql.HasNotNullFlag(col.GetFlag()):
+		return unsupported("the column is nullable")
+	case tblInfo.GetPartitionInfo() != nil:
+		return unsupported("the table is partitioned")
+	case tblInfo.IsCommonHandle:
+		return unsupported("the table has a clustered index")
+	}
+	expectedTp := types.ETString
+	if funcName == ast.Rand {
+		expectedTp = types.ETReal
+	}
+	if col.FieldType.EvalType() != expectedTp {
+		return unsupported(fmt.Sprintf("the column type is %s", col.FieldType.String()))
+	}
+	var seed [8]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return errors.Trace(err)
+	}
+	// Check the values fit the column.
+	d, err := expression.LazyDefaultValue(funcName, binary.BigEndian.Uint64(seed[:]), 0)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err = table.CastValue(ctx, d, col, true, false); err != nil {
+		return errors.Trace(err)
+	}
+	col.OriginDefaultFunc = funcName
+	col.OriginDefaultSeed = binary.BigEndian.Uint64(seed[:])
+	return nil
+}
+
 // AddColumn will add a new column to the table.
 func (d *ddl) AddColumn(ctx sessionctx.Context, ti ast.Ident, spec *ast.AlterTableSpec) error {
 	specNewColumn := spec.NewColumns[0]
@@ -3922,7 +4146,9 @@ func checkTableDefCompatible(source *model.TableInfo, target *model.TableInfo) e
 		if sourceCol.Name.L != targetCol.Name.L ||
 			sourceCol.Hidden != targetCol.Hidden ||
 			!checkFieldTypeCompatible(&sourceCol.FieldType, &targetCol.FieldType) ||
-			sourceCol.GeneratedExprString != targetCol.GeneratedExprString {
+			sourceCol.GeneratedExprString != targetCol.GeneratedExprString ||
+			// The lazy default values of the rows depend on the column.
+			sourceCol.HasLazyOriginDefault() != targetCol.HasLazyOriginDefault() {
 			return errors.Trace(dbterror.ErrTablesDifferentMetadata)
 		}
 		if sourceCol.State != model.StatePublic ||
@@ -4393,6 +4619,9 @@ func GetModifiableColumnJob(
 		if c != nil {
 			return nil, infoschema.ErrColumnExists.GenWithStackByArgs(newColName)
 		}
+		if constrInfo := findDependentCheckConstraint(t.Meta(), originalColName); constrInfo != nil {
+			return nil, dbterror.ErrDependentByCheckConstraint.GenWithStackByArgs(constrInfo.Name.O, originalColName.O)
+		}
 	}
 
 	// Constraints in the new column means adding new constraints. Errors should thrown,
@@ -4419,6 +4648,8 @@ func GetModifiableColumnJob(
 		State:                 col.State,
 		OriginDefaultValue:    col.OriginDefaultValue,
 		OriginDefaultValueBit: col.OriginDefaultValueBit,
+		OriginDefaultFunc:     col.OriginDefaultFunc,
+		OriginDefaultSeed:     col.OriginDefaultSeed,
 		FieldType:             *specNewColumn.Tp,
 		Name:                  newColName,
 		Version:               col.Version,
@@ -4537,8 +4768,9 @@ func GetModifiableColumnJob(
 			WarningsCount: make(map[errors.ErrorID]int64),
 			Location:      &model.TimeZoneLocation{Name: tzName, Offset: tzOffset},
 		},
-		CtxVars: []interface{}{needChangeColData},
-		Args:    []interface{}{&newCol.ColumnInfo, originalColName, spec.Position, modifyColumnTp, newAutoRandBits},
+		CtxVars:  []interface{}{needChangeColData},
+		Args:     []interface{}{&newCol.ColumnInfo, originalColName, spec.Position, modifyColumnTp, newAutoRandBits},
+		Priority: sctx.GetSessionVars().DDLReorgPriority,
 	}
 	return job, nil
 }
@@ -4760,6 +4992,9 @@ func (d *ddl) RenameColumn(ctx sessionctx.Context, ident ast.Ident, spec *ast.Al
 	if fkInfo := GetColumnForeignKeyInfo(oldColName.L, tbl.Meta().ForeignKeys); fkInfo != nil {
 		return dbterror.ErrFKIncompatibleColumns.GenWithStackByArgs(oldColName, fkInfo.Name)
 	}
+	if constrInfo := findDependentCheckConstraint(tbl.Meta(), oldColName); constrInfo != nil {
+		return dbterror.ErrDependentByCheckConstraint.GenWithStackByArgs(constrInfo.Name.O, oldColName.O)
+	}
 
 	// Check generated expression.
 	for _, col := range allCols {
@@ -4794,7 +5029,8 @@ func (d *ddl) RenameColumn(ctx sessionctx.Context, ident ast.Ident, spec *ast.Al
 			WarningsCount: make(map[errors.ErrorID]int64),
 			Location:      &model.TimeZoneLocation{Name: tzName, Offset: tzOffset},
 		},
-		Args: []interface{}{&newCol, oldColName, spec.Position, 0, 0},
+		Args:     []interface{}{&newCol, oldColName, spec.Position, 0, 0},
+		Priority: ctx.GetSessionVars().DDLReorgPriority,
 	}
 	err = d.DoDDLJob(ctx, job)
 	err = d.callHookOnChanged(job, err)
@@ -4942,6 +5178,28 @@ func (d *ddl) AlterTableAutoIDCache(ctx sessionctx.Context, ident ast.Ident, new
 	return errors.Trace(err)
 }
 
+// AlterTableCoprCache enables or disables the coprocessor cache of the table.
+func (d *ddl) AlterTableCoprCache(ctx sessionctx.Context, ident ast.Ident, disabled bool) error {
+	schema, tb, err := d.getSchemaAndTableByIdent(ctx, ident)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tb.Meta().ID,
+		SchemaName: schema.Name.L,
+		TableName:  tb.Meta().Name.L,
+		Type:       model.ActionModifyTableCoprCache,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{disabled},
+	}
+
+	err = d.DoDDLJob(ctx, job)
+	err = d.callHookOnChanged(job, err)
+	return errors.Trace(err)
+}
+
 // AlterTableCharsetAndCollate changes the table charset and collate.
 func (d *ddl) AlterTableCharsetAndCollate(ctx sessionctx.Context, ident ast.Ident, toCharset, toCollate string, needsOverwriteCols bool) error {
 	// use the last one.
@@ -5951,12 +6209,26 @@ func (d *ddl) createIndex(ctx sessionctx.Context, ti ast.Ident, keyType ast.Inde
 		return err
 	}
 
+	// The hypothetical indexes of the session share the names with the real indexes.
+	if _, ok := ctx.GetSessionVars().HypoIndexes[t.Meta().ID][indexName.L]; ok {
+		err = dbterror.ErrDupKeyName.GenWithStack("hypothetical index already exist %s", indexName)
+		if ifNotExists {
+			ctx.GetSessionVars().StmtCtx.AppendNote(err)
+			return nil
+		}
+		return err
+	}
+
 	if err = checkTooLongIndex(indexName); err != nil {
 		return errors.Trace(err)
 	}
 
 	tblInfo := t.Meta()
 
+	if indexOption != nil && indexOption.Tp == model.IndexTypeHypothetical {
+		return createHypoIndex(ctx, tblInfo, indexName, unique, indexPartSpecifications, indexOption)
+	}
+
 	// Build hidden columns if necessary.
 	hiddenCols, err := buildHiddenColumnInfoWithCheck(ctx, indexPartSpecifications, indexName, t.Meta(), t.Cols())
 	if err != nil {
@@ -6029,6 +6301,66 @@ func (d *ddl) createIndex(ctx sessionctx.Context, ti ast.Ident, keyType ast.Inde
 	return errors.Trace(err)
 }
 
+// createHypoIndex registers a hypothetical index in the current session. No DDL job is submitted,
+// the index is only visible to the optimizer in EXPLAIN statements. The index is bound to the table
+// ID, so it doesn't apply to another table created with the same name.
+func createHypoIndex(ctx sessionctx.Context, tblInfo *model.TableInfo, indexName model.CIStr, unique bool,
+	indexPartSpecifications []*ast.IndexPartSpecification, indexOption *ast.IndexOption) error {
+	for _, spec := range indexPartSpecifications {
+		if spec.Expr != nil {
+			return dbterror.ErrUnsupportedIndexType.GenWithStack("expression index can't be hypothetical")
+		}
+	}
+	sessVars := ctx.GetSessionVars()
+	tblHypoIndexes := sessVars.HypoIndexes[tblInfo.ID]
+	indexColumns, err := buildIndexColumns(ctx, tblInfo.Columns, indexPartSpecifications)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	// Hypothetical indexes take IDs from their own space, so they never conflict with the real ones.
+	indexID := model.HypoIndexIDBase
+	for _, idx := range tblHypoIndexes {
+		if idx.ID >= indexID {
+			indexID = idx.ID + 1
+		}
+	}
+	idxInfo := &model.IndexInfo{
+		ID:      indexID,
+		Name:    indexName,
+		Table:   tblInfo.Name,
+		Columns: indexColumns,
+		State:   model.StatePublic,
+		Unique:  unique,
+		Comment: indexOption.Comment,
+		Tp:      model.IndexTypeHypothetical,
+	}
+
+	if sessVars.HypoIndexes == nil {
+		sessVars.HypoIndexes = make(map[int64]map[string]*model.IndexInfo)
+	}
+	if tblHypoIndexes == nil {
+		tblHypoIndexes = make(map[string]*model.IndexInfo)
+		sessVars.HypoIndexes[tblInfo.ID] = tblHypoIndexes
+	}
+	tblHypoIndexes[indexName.L] = idxInfo
+	return nil
+}
+
+// dropHypoIndex removes a hypothetical index from the current session, it returns false if the index doesn't exist.
+func dropHypoIndex(ctx sessionctx.Context, tableID int64, indexName model.CIStr) bool {
+	hypoIndexes := ctx.GetSessionVars().HypoIndexes
+	tblHypoIndexes := hypoIndexes[tableID]
+	if _, ok := tblHypoIndexes[indexName.L]; !ok {
+		return false
+	}
+	delete(tblHypoIndexes, indexName.L)
+	if len(tblHypoIndexes) == 0 {
+		delete(hypoIndexes, tableID)
+	}
+	return true
+}
+
 func buildFKInfo(fkName model.CIStr, keys []*ast.IndexPartSpecification, refer *ast.ReferenceDef, cols []*table.Column, tbInfo *model.TableInfo) (*model.FKInfo, error) {
 	if len(keys) != len(refer.IndexPartSpecifications) {
 		return nil, infoschema.ErrForeignKeyNotMatch.GenWithStackByArgs("foreign key without name")
@@ -6198,6 +6530,10 @@ func (d *ddl) dropIndex(ctx sessionctx.Context, ti ast.Ident, indexName model.CI
 		return errors.Trace(dbterror.ErrOptOnCacheTable.GenWithStackByArgs("Drop Index"))
 	}
 
+	if dropHypoIndex(ctx, t.Meta().ID, indexName) {
+		return nil
+	}
+
 	indexInfo := t.Meta().FindIndexByName(indexName.L)
 
 	isPK, err := CheckIsDropPrimaryKey(indexName, indexInfo, t)
@@ -6265,6 +6601,9 @@ func CheckIsDropPrimaryKey(indexName model.CIStr, indexInfo *model.IndexInfo, t
 			return isPK, dbterror.ErrUnsupportedModifyPrimaryKey.GenWithStack("Unsupported drop primary key when the table is using clustered index")
 		}
 	}
+	if indexInfo != nil && indexInfo.IsClusteredUniqueKey() {
+		return isPK, dbterror.ErrUnsupportedModifyPrimaryKey.GenWithStack("Unsupported drop the clustered unique key")
+	}
 
 	return isPK, nil
 }
@@ -6290,6 +6629,9 @@ func isDroppableColumn(tblInfo *model.TableInfo, colName model.CIStr) error {
 	if fkInfo := GetColumnForeignKeyInfo(colName.L, tblInfo.ForeignKeys); fkInfo != nil {
 		return dbterror.ErrFkColumnCannotDrop.GenWithStackByArgs(colName, fkInfo.Name)
 	}
+	if constrInfo := findDependentCheckConstraint(tblInfo, colName); constrInfo != nil {
+		return dbterror.ErrDependentByCheckConstraint.GenWithStackByArgs(constrInfo.Name.O, colName.O)
+	}
 	return nil
 }
 
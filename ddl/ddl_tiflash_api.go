@@ -380,13 +380,12 @@ func (d *ddl) pollTiFlashReplicaStatus(ctx sessionctx.Context, pollTiFlashContex
 	var tableList = make([]TiFlashReplicaStatus, 0)
 
 	// Collect TiFlash Replica info, for every table.
-	for _, db := range schema.AllSchemas() {
-		tbls := schema.SchemaTables(db.Name)
-		for _, tbl := range tbls {
-			tblInfo := tbl.Meta()
+	infoschema.IterateSchemaTableInfos(schema, func(_ *model.DBInfo, tblInfos []*model.TableInfo) bool {
+		for _, tblInfo := range tblInfos {
 			LoadTiFlashReplicaInfo(tblInfo, &tableList)
 		}
-	}
+		return true
+	})
 
 	for _, tb := range tableList {
 		// For every region in each table, if it has one replica, we reckon it ready.
@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/tidb/ddl/util"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/metrics"
@@ -110,6 +111,11 @@ type JobContext struct {
 	cacheNormalizedSQL string
 	cacheDigest        *parser.Digest
 	tp                 string
+
+	// below fields are the timings of the job phases run by this owner,
+	// which are recorded in mysql.tidb_ddl_job_timing when the job is finished.
+	schemaSyncDuration time.Duration
+	backfillDuration   time.Duration
 }
 
 // NewJobContext returns a new ddl job context.
@@ -585,6 +591,12 @@ func (w *worker) finishDDLJob(t *meta.Meta, job *model.Job) (err error) {
 		updateRawArgs = false
 	}
 	w.writeDDLSeqNum(job)
+	if w.concurrentDDL {
+		// The table doesn't exist when the DDL jobs of bootstrapping are finished.
+		if err := addDDLJobTiming2Table(w.sess, job, w.jobContext(job)); err != nil && !infoschema.ErrTableNotExists.Equal(err) {
+			logutil.Logger(w.logCtx).Info("[ddl] failed to add DDL job timing to table", zap.Error(err))
+		}
+	}
 	w.removeJobCtx(job)
 	err = AddHistoryDDLJob(w.sess, t, job, updateRawArgs, w.concurrentDDL)
 	return errors.Trace(err)
@@ -1002,9 +1014,9 @@ func skipWriteBinlog(job *model.Job) bool {
 	// it's used to update table's TiFlash replica available status.
 	case model.ActionUpdateTiFlashReplicaStatus:
 		return true
-	// Don't sync 'alter table cache|nocache' to other tools.
+	// Don't sync 'alter table cache|nocache|coprocessor_cache' to other tools.
 	// It's internal to the current cluster.
-	case model.ActionAlterCacheTable, model.ActionAlterNoCacheTable:
+	case model.ActionAlterCacheTable, model.ActionAlterNoCacheTable, model.ActionModifyTableCoprCache:
 		return true
 	}
 
@@ -1158,6 +1170,8 @@ func (w *worker) runDDLJob(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64,
 		ver, err = onDropSchema(d, t, job)
 	case model.ActionModifySchemaDefaultPlacement:
 		ver, err = onModifySchemaDefaultPlacement(d, t, job)
+	case model.ActionModifySchemaReadRouting:
+		ver, err = onModifySchemaReadRouting(d, t, job)
 	case model.ActionCreateTable:
 		ver, err = onCreateTable(d, t, job)
 	case model.ActionCreateTables:
@@ -1194,6 +1208,12 @@ func (w *worker) runDDLJob(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64,
 		ver, err = onCreateForeignKey(d, t, job)
 	case model.ActionDropForeignKey:
 		ver, err = onDropForeignKey(d, t, job)
+	case model.ActionAddCheckConstraint:
+		ver, err = w.onAddCheckConstraint(d, t, job)
+	case model.ActionDropCheckConstraint:
+		ver, err = onDropCheckConstraint(d, t, job)
+	case model.ActionAlterCheckConstraint:
+		ver, err = w.onAlterCheckConstraint(d, t, job)
 	case model.ActionTruncateTable:
 		ver, err = onTruncateTable(d, t, job)
 	case model.ActionRebaseAutoID:
@@ -1208,6 +1228,8 @@ func (w *worker) runDDLJob(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64,
 		ver, err = onModifyTableComment(d, t, job)
 	case model.ActionModifyTableAutoIdCache:
 		ver, err = onModifyTableAutoIDCache(d, t, job)
+	case model.ActionModifyTableCoprCache:
+		ver, err = onModifyTableCoprCache(d, t, job)
 	case model.ActionAddTablePartition:
 		ver, err = w.onAddTablePartition(d, t, job)
 	case model.ActionModifyTableCharsetAndCollate:
@@ -1301,6 +1323,7 @@ func (w *worker) waitSchemaChanged(ctx context.Context, d *ddlCtx, waitTime time
 	var err error
 	defer func() {
 		metrics.DDLWorkerHistogram.WithLabelValues(metrics.WorkerWaitSchemaChanged, job.Type.String(), metrics.RetLabel(err)).Observe(time.Since(timeStart).Seconds())
+		w.jobContext(job).schemaSyncDuration += time.Since(timeStart)
 	}()
 
 	if latestSchemaVersion == 0 {
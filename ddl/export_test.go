@@ -14,6 +14,16 @@
 
 package ddl
 
+import (
+	"time"
+
+	"github.com/pingcap/tidb/sessionctx"
+)
+
 func SetBatchInsertDeleteRangeSize(i int) {
 	batchInsertDeleteRangeSize = i
 }
+
+func MaintainIntervalPartitions(d DDL, sctx sessionctx.Context, now time.Time) {
+	d.(*ddl).maintainIntervalPartitions(sctx, now)
+}
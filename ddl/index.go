@@ -270,6 +270,9 @@ func BuildIndexInfo(
 	}
 
 	if indexOption != nil {
+		if indexOption.Tp == model.IndexTypeHypothetical {
+			return nil, dbterror.ErrUnsupportedIndexType.GenWithStack("hypothetical index can only be created by CREATE INDEX or ALTER TABLE ADD INDEX")
+		}
 		idxInfo.Comment = indexOption.Comment
 		if indexOption.Visibility == ast.IndexVisibilityInvisible {
 			idxInfo.Invisible = true
@@ -1077,7 +1080,7 @@ func (w *baseIndexWorker) getIndexRecord(idxInfo *model.IndexInfo, handle kv.Han
 			idxVal[j] = idxColumnVal
 			continue
 		}
-		idxColumnVal, err = tables.GetColDefaultValue(w.sessCtx, col, w.defaultVals)
+		idxColumnVal, err = tables.GetColDefaultValue(w.sessCtx, col, handle, w.defaultVals)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
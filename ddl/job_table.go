@@ -32,6 +32,7 @@ import (
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/sqlexec"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 	"golang.org/x/exp/slices"
@@ -568,3 +569,63 @@ func runInTxn(se *session, f func(*session) error) (err error) {
 	}
 	return errors.Trace(se.commit())
 }
+
+// addDDLJobTiming2Table records the timings of the phases of the finished job into
+// mysql.tidb_ddl_job_timing. The timings are collected in memory, so the phases run
+// by the previous DDL owners are not counted.
+func addDDLJobTiming2Table(sess *session, job *model.Job, jobCtx *JobContext) error {
+	if variable.DDLJobTimingRetention.Load() <= 0 {
+		return nil
+	}
+	createTime := model.TSConvert2Time(job.StartTS)
+	endTime := model.TSConvert2Time(job.BinlogInfo.FinishedTS)
+	var startTime interface{}
+	var queueDuration time.Duration
+	// RealStartTS is 0 if the job is cancelled before it runs.
+	if job.RealStartTS > 0 {
+		realStartTime := model.TSConvert2Time(job.RealStartTS)
+		startTime, queueDuration = realStartTime, realStartTime.Sub(createTime)
+	}
+	sql, err := sqlexec.EscapeSQL("insert ignore into mysql.tidb_ddl_job_timing(job_id, job_type, db_name, table_name, state, "+
+		"create_time, start_time, end_time, queue_duration, schema_sync_duration, backfill_duration, backfill_rows) "+
+		"values (%?, %?, %?, %?, %?, %?, %?, %?, %?, %?, %?, %?)",
+		job.ID, job.Type.String(), job.SchemaName, job.TableName, job.State.String(),
+		createTime, startTime, endTime, queueDuration.Seconds(), jobCtx.schemaSyncDuration.Seconds(),
+		jobCtx.backfillDuration.Seconds(), job.GetRowCount())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = sess.execute(context.Background(), sql, "insert_job_timing")
+	return errors.Trace(err)
+}
+
+// gcDDLJobTimings deletes the job timings older than tidb_ddl_job_timing_retention
+// from mysql.tidb_ddl_job_timing on the DDL owner periodically.
+func (d *ddl) gcDDLJobTimings() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-d.ctx.Done():
+			return
+		}
+		retention := variable.DDLJobTimingRetention.Load()
+		if retention <= 0 || !d.isOwner() {
+			continue
+		}
+		se, err := d.sessPool.get()
+		if err != nil {
+			logutil.BgLogger().Warn("[ddl] gc DDL job timings get session failed", zap.Error(err))
+			continue
+		}
+		sql, err := sqlexec.EscapeSQL("delete from mysql.tidb_ddl_job_timing where end_time < %?", time.Now().Add(-retention))
+		if err == nil {
+			_, err = newSession(se).execute(d.ctx, sql, "gc_job_timing")
+		}
+		d.sessPool.put(se)
+		if err != nil {
+			logutil.BgLogger().Warn("[ddl] gc DDL job timings failed", zap.Error(err))
+		}
+	}
+}
@@ -291,3 +291,28 @@ func TestConcurrentDDLSwitch(t *testing.T) {
 		}
 	}
 }
+
+func TestDDLJobTiming(t *testing.T) {
+	if !variable.EnableConcurrentDDL.Load() {
+		t.Skipf("test requires concurrent ddl")
+	}
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3)")
+	tk.MustExec("alter table t add index idx(b)")
+
+	rows := tk.MustQuery("select job_type, db_name, table_name, state, start_time is not null, end_time >= create_time, " +
+		"queue_duration >= 0, schema_sync_duration >= 0, backfill_duration > 0, backfill_rows " +
+		"from mysql.tidb_ddl_job_timing where db_name = 'test' order by job_id").Rows()
+	require.Equal(t, [][]interface{}{
+		{"create table", "test", "t", "synced", "1", "1", "1", "1", "0", "0"},
+		{"add index", "test", "t", "synced", "1", "1", "1", "1", "1", "3"},
+	}, rows)
+
+	tk.MustExec("set @@global.tidb_ddl_job_timing_retention = 0")
+	defer tk.MustExec("set @@global.tidb_ddl_job_timing_retention = default")
+	tk.MustExec("drop table t")
+	tk.MustQuery("select count(*) from mysql.tidb_ddl_job_timing where job_type = 'drop table'").Check(testkit.Rows("0"))
+}
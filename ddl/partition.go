@@ -41,6 +41,7 @@ import (
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/parser/tidb"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
@@ -483,7 +484,7 @@ func buildTablePartitionInfo(ctx sessionctx.Context, s *ast.PartitionOptions, tb
 
 	tbInfo.Partition.Definitions = defs
 
-	if s.Interval != nil {
+	if s.Interval != nil && s.Interval.FirstRangeEnd != nil {
 		// Syntactic sugar for INTERVAL partitioning
 		// Generate the resulting CREATE TABLE as the query string
 		query, ok := ctx.Value(sessionctx.QueryString).(string)
@@ -492,9 +493,16 @@ func buildTablePartitionInfo(ctx sessionctx.Context, s *ast.PartitionOptions, tb
 			var buf bytes.Buffer
 			AppendPartitionDefs(tbInfo.Partition, &buf, sqlMode)
 
+			var intervalBuf bytes.Buffer
+			AppendPartitionIntervalInfo(tbInfo.Partition, &intervalBuf)
+			if intervalBuf.Len() > 0 {
+				// Keep the AUTO CREATE options, they are not syntactic sugar.
+				intervalBuf.WriteString(" ")
+			}
+
 			syntacticSugar := s.Interval.OriginalText()
 			syntacticStart := s.Interval.OriginTextPosition()
-			newQuery := query[:syntacticStart] + "(" + buf.String() + ")" + query[syntacticStart+len(syntacticSugar):]
+			newQuery := query[:syntacticStart] + strings.TrimPrefix(intervalBuf.String(), " ") + "(" + buf.String() + ")" + query[syntacticStart+len(syntacticSugar):]
 			ctx.SetValue(sessionctx.QueryString, newQuery)
 		}
 	}
@@ -585,6 +593,14 @@ func getPartitionIntervalFromTable(ctx sessionctx.Context, tbInfo *model.TableIn
 			return nil
 		}
 		interval.LastRangeEnd = &lastExpr
+	} else if autoInterval := tbInfo.Partition.AutoInterval; autoInterval != nil {
+		// No need to guess, the interval was stored when the table was created.
+		interval.IntervalExpr.Expr = ast.NewValueExpr(autoInterval.Interval, "", "")
+		interval.IntervalExpr.TimeUnit = timeUnitFromString(autoInterval.Unit)
+		firstExpr = ast.NewValueExpr(firstPartLessThan, "", "")
+		lastExpr = ast.NewValueExpr(lastPartLessThan, "", "")
+		interval.FirstRangeEnd = &firstExpr
+		interval.LastRangeEnd = &lastExpr
 	} else { // types.ETDatetime
 		exprStr := fmt.Sprintf("TIMESTAMPDIFF(SECOND, '%s', '%s')", firstPartLessThan, lastPartLessThan)
 		exprs, err := expression.ParseSimpleExprsWithNames(ctx, exprStr, nil, nil)
@@ -766,12 +782,27 @@ func generatePartitionDefinitionsFromInterval(ctx sessionctx.Context, partOption
 			return dbterror.ErrGeneralUnsupportedDDL.GenWithStackByArgs("INTERVAL partitioning, only supports Date, Datetime and INT types")
 		}
 	}
+	if partOptions.Interval.AutoCreate {
+		if partCol == nil || partCol.FieldType.EvalType() != types.ETDatetime {
+			return dbterror.ErrGeneralUnsupportedDDL.GenWithStackByArgs("INTERVAL partitioning, AUTO CREATE only supports RANGE COLUMNS with Date or Datetime type")
+		}
+		autoInterval, err := buildPartitionIntervalInfo(ctx, partOptions.Interval)
+		if err != nil {
+			return err
+		}
+		tbInfo.Partition.AutoInterval = autoInterval
+	}
 	// Allow given partition definitions, but check it later!
 	definedPartDefs := partOptions.Definitions
-	partOptions.Definitions = make([]*ast.PartitionDefinition, 0, 1)
 	if partOptions.Interval.FirstRangeEnd == nil || partOptions.Interval.LastRangeEnd == nil {
+		if partOptions.Interval.AutoCreate && len(definedPartDefs) > 0 {
+			// The partitions are listed explicitly, like in the output of SHOW CREATE TABLE,
+			// they will be checked against the interval when the next partition is created.
+			return nil
+		}
 		return dbterror.ErrGeneralUnsupportedDDL.GenWithStackByArgs("INTERVAL partitioning, currently requires FIRST and LAST partitions to be defined")
 	}
+	partOptions.Definitions = make([]*ast.PartitionDefinition, 0, 1)
 	switch partOptions.Interval.IntervalExpr.TimeUnit {
 	case ast.TimeUnitInvalid, ast.TimeUnitYear, ast.TimeUnitQuarter, ast.TimeUnitMonth, ast.TimeUnitWeek, ast.TimeUnitDay, ast.TimeUnitHour, ast.TimeUnitDayMinute, ast.TimeUnitSecond:
 	default:
@@ -856,6 +887,62 @@ func generatePartitionDefinitionsFromInterval(ctx sessionctx.Context, partOption
 	return nil
 }
 
+// buildPartitionIntervalInfo checks the INTERVAL ... AUTO CREATE options and converts them to the model.
+func buildPartitionIntervalInfo(ctx sessionctx.Context, interval *ast.PartitionInterval) (*model.PartitionIntervalInfo, error) {
+	if timeUnitFromString(interval.IntervalExpr.TimeUnit.String()) == ast.TimeUnitInvalid {
+		return nil, dbterror.ErrGeneralUnsupportedDDL.GenWithStackByArgs("INTERVAL partitioning, AUTO CREATE requires YEAR, QUARTER, MONTH, WEEK, DAY, HOUR, MINUTE or SECOND as time unit")
+	}
+	if interval.MaxValPart {
+		return nil, dbterror.ErrGeneralUnsupportedDDL.GenWithStackByArgs("INTERVAL partitioning, AUTO CREATE is not supported with MAXVALUE partition")
+	}
+	n, err := evalPositiveIntervalValue(ctx, interval.IntervalExpr.Expr)
+	if err != nil {
+		return nil, err
+	}
+	info := &model.PartitionIntervalInfo{
+		Interval: n,
+		Unit:     interval.IntervalExpr.TimeUnit.String(),
+	}
+	if interval.Retention != nil {
+		if timeUnitFromString(interval.Retention.TimeUnit.String()) == ast.TimeUnitInvalid {
+			return nil, dbterror.ErrGeneralUnsupportedDDL.GenWithStackByArgs("INTERVAL partitioning, RETENTION requires YEAR, QUARTER, MONTH, WEEK, DAY, HOUR, MINUTE or SECOND as time unit")
+		}
+		n, err = evalPositiveIntervalValue(ctx, interval.Retention.Expr)
+		if err != nil {
+			return nil, err
+		}
+		info.Retention = n
+		info.RetentionUnit = interval.Retention.TimeUnit.String()
+	}
+	return info, nil
+}
+
+func evalPositiveIntervalValue(ctx sessionctx.Context, expr ast.ExprNode) (int64, error) {
+	val, err := expression.EvalAstExpr(ctx, expr)
+	if err != nil {
+		return 0, err
+	}
+	if val.Kind() != types.KindInt64 && val.Kind() != types.KindUint64 {
+		return 0, dbterror.ErrGeneralUnsupportedDDL.GenWithStackByArgs("INTERVAL, should be a positive number")
+	}
+	n := val.GetInt64()
+	if n < 1 {
+		return 0, dbterror.ErrGeneralUnsupportedDDL.GenWithStackByArgs("INTERVAL, should be a positive number")
+	}
+	return n, nil
+}
+
+// timeUnitFromString is the reverse of ast.TimeUnitType.String for the units allowed in INTERVAL partitioning.
+func timeUnitFromString(s string) ast.TimeUnitType {
+	for _, unit := range []ast.TimeUnitType{ast.TimeUnitYear, ast.TimeUnitQuarter, ast.TimeUnitMonth,
+		ast.TimeUnitWeek, ast.TimeUnitDay, ast.TimeUnitHour, ast.TimeUnitMinute, ast.TimeUnitSecond} {
+		if unit.String() == s {
+			return unit
+		}
+	}
+	return ast.TimeUnitInvalid
+}
+
 func astIntValueExprFromStr(s string, unsigned bool) (ast.ExprNode, error) {
 	if unsigned {
 		u, err := strconv.ParseUint(s, 10, 64)
@@ -2739,6 +2826,20 @@ func checkNoTimestampArgs(tbInfo *model.TableInfo, exprs ...ast.ExprNode) error
 // AppendPartitionDefs generates a list of partition definitions needed for SHOW CREATE TABLE (in executor/show.go)
 // as well as needed for generating the ADD PARTITION query for INTERVAL partitioning of ALTER TABLE t LAST PARTITION
 // and generating the CREATE TABLE query from CREATE TABLE ... INTERVAL
+// AppendPartitionIntervalInfo is used in SHOW CREATE TABLE as well as generation the SQL syntax
+// for the INTERVAL ... AUTO CREATE options of the partitioned table.
+func AppendPartitionIntervalInfo(partitionInfo *model.PartitionInfo, buf *bytes.Buffer) {
+	autoInterval := partitionInfo.AutoInterval
+	if autoInterval == nil {
+		return
+	}
+	fmt.Fprintf(buf, " /*T![%s] INTERVAL (%d %s) AUTO CREATE", tidb.FeatureIDAutoInterval, autoInterval.Interval, autoInterval.Unit)
+	if autoInterval.Retention > 0 {
+		fmt.Fprintf(buf, " RETENTION (%d %s)", autoInterval.Retention, autoInterval.RetentionUnit)
+	}
+	buf.WriteString(" */")
+}
+
 func AppendPartitionDefs(partitionInfo *model.PartitionInfo, buf *bytes.Buffer, sqlMode mysql.SQLMode) {
 	for i, def := range partitionInfo.Definitions {
 		if i > 0 {
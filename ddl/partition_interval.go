@@ -0,0 +1,238 @@
+// Copyright 2023 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/types"
+	driver "github.com/pingcap/tidb/types/parser_driver"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/dbterror"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"go.uber.org/zap"
+)
+
+// intervalPartitionCheckInterval is how often the DDL owner checks the tables
+// partitioned by INTERVAL ... AUTO CREATE.
+var intervalPartitionCheckInterval = 5 * time.Minute
+
+// maintainIntervalPartitionsLoop creates the partitions needed by the tables partitioned by
+// INTERVAL ... AUTO CREATE in advance, and drops the partitions out of their RETENTION.
+//
+// The partitions are created by the wall clock of the DDL owner, not by the inserted rows:
+// every intervalPartitionCheckInterval, the LAST partition is extended to cover at least
+// tidb_interval_partition_horizon ahead of now, in the time zone of the global time_zone.
+// Inserting a row that doesn't fit in the existing partitions, for example one dated beyond
+// that horizon, still fails with ErrNoPartitionForGivenValue and doesn't create any partition.
+// The horizon should be generous enough to cover the skew of the clocks generating the rows
+// and the time the owner may be unavailable.
+func (d *ddl) maintainIntervalPartitionsLoop() {
+	ticker := time.NewTicker(intervalPartitionCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-d.ctx.Done():
+			return
+		}
+		if !d.isOwner() {
+			continue
+		}
+		se, err := d.sessPool.get()
+		if err != nil {
+			logutil.BgLogger().Warn("[ddl] maintain interval partitions get session failed", zap.Error(err))
+			continue
+		}
+		d.maintainIntervalPartitions(se, time.Now())
+		d.sessPool.put(se)
+	}
+}
+
+// maintainIntervalPartitions checks all the tables partitioned by INTERVAL ... AUTO CREATE as of now.
+// A failure on one table is only logged, so that the other tables are still checked.
+func (d *ddl) maintainIntervalPartitions(sctx sessionctx.Context, now time.Time) {
+	is := d.infoCache.GetLatest()
+	for _, id := range infoschema.IntervalPartitionTableIDs(is) {
+		tbl, ok := is.TableByID(id)
+		if !ok {
+			continue
+		}
+		tblInfo := tbl.Meta()
+		pi := tblInfo.GetPartitionInfo()
+		if pi == nil || pi.AutoInterval == nil {
+			continue
+		}
+		if len(pi.AddingDefinitions) > 0 || len(pi.DroppingDefinitions) > 0 {
+			// Another partition DDL is running, check it next time.
+			continue
+		}
+		db, ok := is.SchemaByTable(tblInfo)
+		if !ok {
+			continue
+		}
+		if err := maintainIntervalPartition(d.ctx, sctx, db.Name, tblInfo, now); err != nil {
+			logutil.BgLogger().Warn("[ddl] maintain interval partitions failed",
+				zap.String("schema", db.Name.O), zap.String("table", tblInfo.Name.O), zap.Error(err))
+		}
+	}
+}
+
+func maintainIntervalPartition(ctx context.Context, sctx sessionctx.Context, dbName model.CIStr, tblInfo *model.TableInfo, now time.Time) error {
+	interval := getPartitionIntervalFromTable(sctx, tblInfo)
+	if interval == nil {
+		return dbterror.ErrGeneralUnsupportedDDL.GenWithStackByArgs("AUTO CREATE, partitions do not match the INTERVAL any more")
+	}
+	autoInterval := tblInfo.Partition.AutoInterval
+	exec := sctx.(sqlexec.SQLExecutor)
+	ctx = kv.WithInternalSourceType(ctx, kv.InternalTxnDDL)
+
+	// The bounds are compared with the time in the global time_zone, so that they don't depend on
+	// the local time zone of the owner.
+	loc, err := globalTimeZone(sctx)
+	if err != nil {
+		return err
+	}
+	now = now.In(loc)
+
+	// The LAST partition should cover the time until the horizon, the rows beyond it are
+	// rejected until a later check creates their partitions.
+	nowStr := now.Format(types.TimeFormat)
+	horizonDur := variable.IntervalPartitionHorizon.Load()
+	if horizonDur < intervalPartitionCheckInterval {
+		horizonDur = intervalPartitionCheckInterval
+	}
+	_, horizon, err := evalIntervalPartitionBound(sctx, now.Add(horizonDur).Format(types.TimeFormat), autoInterval.Interval, autoInterval.Unit)
+	if err != nil {
+		return err
+	}
+	lastStr := driver.UnwrapFromSingleQuotes(tblInfo.Partition.Definitions[len(tblInfo.Partition.Definitions)-1].LessThan[0])
+	last, err := parseIntervalPartitionBound(sctx, lastStr)
+	if err != nil {
+		return err
+	}
+	if last.Compare(horizon) <= 0 {
+		var newLastStr string
+		for i := int64(1); ; i++ {
+			if i > mysql.PartitionCountLimit {
+				return errors.Trace(dbterror.ErrTooManyPartitions)
+			}
+			s, newLast, err := evalIntervalPartitionBound(sctx, lastStr, i*autoInterval.Interval, autoInterval.Unit)
+			if err != nil {
+				return err
+			}
+			if newLast.Compare(horizon) > 0 {
+				newLastStr = s
+				break
+			}
+		}
+		_, err = exec.ExecuteInternal(ctx, "ALTER TABLE %n.%n LAST PARTITION LESS THAN (%?)", dbName.O, tblInfo.Name.O, newLastStr)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		logutil.BgLogger().Info("[ddl] interval partitions created", zap.String("schema", dbName.O),
+			zap.String("table", tblInfo.Name.O), zap.String("now", nowStr), zap.String("lastPartitionLessThan", newLastStr))
+	}
+
+	if autoInterval.Retention <= 0 {
+		return nil
+	}
+	// Drop the partitions that only contain rows older than the RETENTION.
+	_, cutoff, err := evalIntervalPartitionBound(sctx, nowStr, -autoInterval.Retention, autoInterval.RetentionUnit)
+	if err != nil {
+		return err
+	}
+	startIdx := 0
+	if interval.NullPart {
+		startIdx = 1
+	}
+	defs := tblInfo.Partition.Definitions
+	firstIdx := startIdx
+	for ; firstIdx < len(defs)-1; firstIdx++ {
+		lessThan, err := parseIntervalPartitionBound(sctx, driver.UnwrapFromSingleQuotes(defs[firstIdx].LessThan[0]))
+		if err != nil {
+			return err
+		}
+		if lessThan.Compare(cutoff) > 0 {
+			break
+		}
+	}
+	if firstIdx == startIdx {
+		return nil
+	}
+	firstStr := driver.UnwrapFromSingleQuotes(defs[firstIdx].LessThan[0])
+	_, err = exec.ExecuteInternal(ctx, "ALTER TABLE %n.%n FIRST PARTITION LESS THAN (%?)", dbName.O, tblInfo.Name.O, firstStr)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	logutil.BgLogger().Info("[ddl] interval partitions dropped", zap.String("schema", dbName.O),
+		zap.String("table", tblInfo.Name.O), zap.String("now", nowStr), zap.String("firstPartitionLessThan", firstStr))
+	return nil
+}
+
+// globalTimeZone returns the location of the global time_zone.
+func globalTimeZone(sctx sessionctx.Context) (*time.Location, error) {
+	sessVars := sctx.GetSessionVars()
+	tz, err := sessVars.GlobalVarsAccessor.GetGlobalSysVar(variable.TimeZone)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The location is parsed by setting the time zone of the session, which is restored after that.
+	oldTZ := sessVars.TimeZone
+	defer func() {
+		sessVars.TimeZone = oldTZ
+	}()
+	if err = sessVars.SetSystemVar(variable.TimeZone, tz); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return sessVars.Location(), nil
+}
+
+// evalIntervalPartitionBound evaluates DATE_ADD(base, INTERVAL n unit), the same way as the partitions are generated.
+// The result is returned both as the string to be used in LESS THAN and as the parsed time.
+func evalIntervalPartitionBound(sctx sessionctx.Context, base string, n int64, unit string) (string, types.Time, error) {
+	exprs, err := expression.ParseSimpleExprsWithNames(sctx, fmt.Sprintf("DATE_ADD('%s', INTERVAL %d %s)", base, n, unit), nil, nil)
+	if err != nil {
+		return "", types.ZeroTime, err
+	}
+	val, err := exprs[0].Eval(chunk.Row{})
+	if err != nil {
+		return "", types.ZeroTime, err
+	}
+	if val.IsNull() {
+		return "", types.ZeroTime, dbterror.ErrGeneralUnsupportedDDL.GenWithStackByArgs(fmt.Sprintf("INTERVAL partitioning, invalid partition value '%s'", base))
+	}
+	s, err := val.ToString()
+	if err != nil {
+		return "", types.ZeroTime, err
+	}
+	t, err := parseIntervalPartitionBound(sctx, s)
+	return s, t, err
+}
+
+func parseIntervalPartitionBound(sctx sessionctx.Context, s string) (types.Time, error) {
+	return types.ParseTime(sctx.GetSessionVars().StmtCtx, s, mysql.TypeDatetime, types.DefaultFsp)
+}
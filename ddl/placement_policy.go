@@ -327,20 +327,21 @@ func checkPlacementPolicyNotInUse(d *ddlCtx, t *meta.Meta, policy *model.PolicyI
 }
 
 // CheckPlacementPolicyNotInUseFromInfoSchema export for test.
-func CheckPlacementPolicyNotInUseFromInfoSchema(is infoschema.InfoSchema, policy *model.PolicyInfo) error {
-	for _, dbInfo := range is.AllSchemas() {
+func CheckPlacementPolicyNotInUseFromInfoSchema(is infoschema.InfoSchema, policy *model.PolicyInfo) (err error) {
+	infoschema.IterateSchemaTableInfos(is, func(dbInfo *model.DBInfo, tblInfos []*model.TableInfo) bool {
 		if ref := dbInfo.PlacementPolicyRef; ref != nil && ref.ID == policy.ID {
-			return dbterror.ErrPlacementPolicyInUse.GenWithStackByArgs(policy.Name)
+			err = dbterror.ErrPlacementPolicyInUse.GenWithStackByArgs(policy.Name)
+			return false
 		}
 
-		for _, tbl := range is.SchemaTables(dbInfo.Name) {
-			tblInfo := tbl.Meta()
-			if err := checkPlacementPolicyNotUsedByTable(tblInfo, policy); err != nil {
-				return err
+		for _, tblInfo := range tblInfos {
+			if err = checkPlacementPolicyNotUsedByTable(tblInfo, policy); err != nil {
+				return false
 			}
 		}
-	}
-	return nil
+		return true
+	})
+	return err
 }
 
 func getPlacementPolicyDependedObjectsIDs(t *meta.Meta, policy *model.PolicyInfo) (dbIDs, partIDs []int64, tblInfos []*model.TableInfo, err error) {
@@ -68,6 +68,8 @@ type reorgCtx struct {
 	// element is used to record the current element in the reorg process, it can be
 	// accessed by reorg-worker and daemon-worker concurrently.
 	element atomic.Value
+	// startTime is when the backfilling goroutine starts.
+	startTime time.Time
 
 	mu struct {
 		sync.Mutex
@@ -240,6 +242,7 @@ func (w *worker) runReorgJob(rh *reorgHandler, reorgInfo *reorgInfo, tblInfo *mo
 		rowCount, _, _ := rc.getRowCountAndKey()
 		logutil.BgLogger().Info("[ddl] run reorg job done", zap.Int64("handled rows", rowCount))
 		job.SetRowCount(rowCount)
+		w.jobContext(job).backfillDuration += time.Since(rc.startTime)
 
 		// Update a job's warnings.
 		w.mergeWarningsIntoJob(job)
@@ -347,6 +347,17 @@ func cancelOnlyNotHandledJob(job *model.Job, initialState model.SchemaState) (ve
 	return ver, nil
 }
 
+// cancelCheckConstraintJob cancels the job of enforcing a CHECK constraint. The job which has
+// enforced the constraint on the written rows is rolled back to drop it.
+func cancelCheckConstraintJob(job *model.Job) (ver int64, err error) {
+	if job.SchemaState != model.StateWriteOnly {
+		job.State = model.JobStateCancelled
+		return ver, dbterror.ErrCancelledDDLJob
+	}
+	job.State = model.JobStateRollingback
+	return ver, dbterror.ErrCancelledDDLJob
+}
+
 func rollingbackTruncateTable(t *meta.Meta, job *model.Job) (ver int64, err error) {
 	_, err = GetTableInfoAndCancelFaultJob(t, job, job.SchemaID)
 	if err != nil {
@@ -381,14 +392,17 @@ func convertJob2RollbackJob(w *worker, d *ddlCtx, t *meta.Meta, job *model.Job)
 		ver, err = rollingbackTruncateTable(t, job)
 	case model.ActionModifyColumn:
 		ver, err = rollingbackModifyColumn(w, d, t, job)
-	case model.ActionDropForeignKey:
+	case model.ActionDropForeignKey, model.ActionDropCheckConstraint:
 		ver, err = cancelOnlyNotHandledJob(job, model.StatePublic)
+	case model.ActionAddCheckConstraint, model.ActionAlterCheckConstraint:
+		ver, err = cancelCheckConstraintJob(job)
 	case model.ActionRebaseAutoID, model.ActionShardRowID, model.ActionAddForeignKey,
 		model.ActionRenameTable, model.ActionRenameTables,
 		model.ActionModifyTableCharsetAndCollate, model.ActionTruncateTablePartition,
 		model.ActionModifySchemaCharsetAndCollate, model.ActionRepairTable,
-		model.ActionModifyTableAutoIdCache, model.ActionAlterIndexVisibility,
-		model.ActionExchangeTablePartition, model.ActionModifySchemaDefaultPlacement:
+		model.ActionModifyTableAutoIdCache, model.ActionModifyTableCoprCache, model.ActionAlterIndexVisibility,
+		model.ActionExchangeTablePartition, model.ActionModifySchemaDefaultPlacement,
+		model.ActionModifySchemaReadRouting:
 		ver, err = cancelOnlyNotHandledJob(job, model.StateNone)
 	case model.ActionMultiSchemaChange:
 		err = rollingBackMultiSchemaChange(job)
@@ -177,6 +177,35 @@ func onModifySchemaDefaultPlacement(d *ddlCtx, t *meta.Meta, job *model.Job) (ve
 	return ver, nil
 }
 
+func onModifySchemaReadRouting(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var readRouting string
+	if err := job.DecodeArgs(&readRouting); err != nil {
+		job.State = model.JobStateCancelled
+		return ver, errors.Trace(err)
+	}
+
+	dbInfo, err := checkSchemaExistAndCancelNotExistJob(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	if dbInfo.ReadRouting == readRouting {
+		job.FinishDBJob(model.JobStateDone, model.StatePublic, ver, dbInfo)
+		return ver, nil
+	}
+
+	dbInfo.ReadRouting = readRouting
+
+	if err = t.UpdateDatabase(dbInfo); err != nil {
+		return ver, errors.Trace(err)
+	}
+	if ver, err = updateSchemaVersion(d, t, job); err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.FinishDBJob(model.JobStateDone, model.StatePublic, ver, dbInfo)
+	return ver, nil
+}
+
 func onDropSchema(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	dbInfo, err := checkSchemaExistAndCancelNotExistJob(t, job)
 	if err != nil {
@@ -35,6 +35,7 @@ import (
 	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/model"
 	field_types "github.com/pingcap/tidb/parser/types"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
@@ -312,6 +313,9 @@ func onDropTableOrView(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, _ er
 				return ver, errors.Trace(err)
 			}
 		} else {
+			if err = addToRecycleBin(t, job, tblInfo); err != nil {
+				return ver, errors.Trace(err)
+			}
 			if err = t.DropTableOrView(job.SchemaID, job.TableID); err != nil {
 				return ver, errors.Trace(err)
 			}
@@ -333,6 +337,27 @@ func onDropTableOrView(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, _ er
 	return ver, errors.Trace(err)
 }
 
+// addToRecycleBin keeps the dropped table in the recycle bin if it's enabled,
+// so the table can be recovered until it's expired or purged, no matter where
+// the GC safe point is.
+func addToRecycleBin(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo) error {
+	if variable.RecycleBinRetention.Load() <= 0 || tblInfo.IsView() || tblInfo.TempTableType != model.TempTableNone {
+		return nil
+	}
+	autoIDs, err := t.GetAutoIDAccessors(job.SchemaID, job.TableID).Get()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return t.AddRecycleBinEntry(&meta.RecycleBinEntry{
+		JobID:      job.ID,
+		SchemaID:   job.SchemaID,
+		SchemaName: job.SchemaName,
+		TableInfo:  tblInfo,
+		AutoIDs:    autoIDs,
+		DropTS:     t.StartTS,
+	})
+}
+
 const (
 	recoverTableCheckFlagNone int64 = iota
 	recoverTableCheckFlagEnableGC
@@ -420,12 +445,24 @@ func (w *worker) onRecoverTable(d *ddlCtx, t *meta.Meta, job *model.Job) (ver in
 				return ver, errors.Errorf("disable gc failed, try again later. err: %v", err)
 			}
 		}
-		// check GC safe point
-		err = checkSafePoint(w, snapshotTS)
+		// The data of the tables in the recycle bin isn't deleted by GC, so
+		// they can be recovered even if the safe point has passed the drop.
+		binEntry, err := t.GetRecycleBinEntry(dropJobID)
 		if err != nil {
-			job.State = model.JobStateCancelled
 			return ver, errors.Trace(err)
 		}
+		if binEntry != nil {
+			if err = t.DeleteRecycleBinEntry(dropJobID); err != nil {
+				return ver, errors.Trace(err)
+			}
+		} else {
+			// check GC safe point
+			err = checkSafePoint(w, snapshotTS)
+			if err != nil {
+				job.State = model.JobStateCancelled
+				return ver, errors.Trace(err)
+			}
+		}
 		// Remove dropped table DDL job from gc_delete_range table.
 		var tids []int64
 		if tblInfo.GetPartitionInfo() != nil {
@@ -440,7 +477,7 @@ func (w *worker) onRecoverTable(d *ddlCtx, t *meta.Meta, job *model.Job) (ver in
 			return ver, errors.Wrapf(err, "failed to get old label rules from PD")
 		}
 
-		err = w.delRangeManager.removeFromGCDeleteRange(w.ctx, dropJobID, tids)
+		err = w.delRangeManager.removeFromGCDeleteRange(w.ctx, dropJobID)
 		if err != nil {
 			return ver, errors.Trace(err)
 		}
@@ -839,6 +876,27 @@ func onModifyTableAutoIDCache(d *ddlCtx, t *meta.Meta, job *model.Job) (int64, e
 	return ver, nil
 }
 
+func onModifyTableCoprCache(d *ddlCtx, t *meta.Meta, job *model.Job) (int64, error) {
+	var disabled bool
+	if err := job.DecodeArgs(&disabled); err != nil {
+		job.State = model.JobStateCancelled
+		return 0, errors.Trace(err)
+	}
+
+	tblInfo, err := GetTableInfoAndCancelFaultJob(t, job, job.SchemaID)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+
+	tblInfo.CoprCacheDisabled = disabled
+	ver, err := updateVersionAndTableInfo(d, t, job, tblInfo, true)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.FinishTableJob(model.JobStateDone, model.StatePublic, ver, tblInfo)
+	return ver, nil
+}
+
 func (w *worker) onShardRowID(d *ddlCtx, t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	var shardRowIDBits uint64
 	err := job.DecodeArgs(&shardRowIDBits)
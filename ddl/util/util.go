@@ -23,6 +23,7 @@ import (
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
@@ -36,15 +37,15 @@ import (
 )
 
 const (
-	deleteRangesTable            = `gc_delete_range`
-	doneDeleteRangesTable        = `gc_delete_range_done`
-	loadDeleteRangeSQL           = `SELECT HIGH_PRIORITY job_id, element_id, start_key, end_key FROM mysql.%n WHERE ts < %?`
-	recordDoneDeletedRangeSQL    = `INSERT IGNORE INTO mysql.gc_delete_range_done SELECT * FROM mysql.gc_delete_range WHERE job_id = %? AND element_id = %?`
-	completeDeleteRangeSQL       = `DELETE FROM mysql.gc_delete_range WHERE job_id = %? AND element_id = %?`
-	completeDeleteMultiRangesSQL = `DELETE FROM mysql.gc_delete_range WHERE job_id = %? AND element_id in (` // + idList + ")"
-	updateDeleteRangeSQL         = `UPDATE mysql.gc_delete_range SET start_key = %? WHERE job_id = %? AND element_id = %? AND start_key = %?`
-	deleteDoneRecordSQL          = `DELETE FROM mysql.gc_delete_range_done WHERE job_id = %? AND element_id = %?`
-	loadGlobalVars               = `SELECT HIGH_PRIORITY variable_name, variable_value from mysql.global_variables where variable_name in (` // + nameList + ")"
+	deleteRangesTable          = `gc_delete_range`
+	doneDeleteRangesTable      = `gc_delete_range_done`
+	loadDeleteRangeSQL         = `SELECT HIGH_PRIORITY job_id, element_id, start_key, end_key FROM mysql.%n WHERE ts < %?`
+	recordDoneDeletedRangeSQL  = `INSERT IGNORE INTO mysql.gc_delete_range_done SELECT * FROM mysql.gc_delete_range WHERE job_id = %? AND element_id = %?`
+	completeDeleteRangeSQL     = `DELETE FROM mysql.gc_delete_range WHERE job_id = %? AND element_id = %?`
+	completeDeleteJobRangesSQL = `DELETE FROM mysql.gc_delete_range WHERE job_id = %?`
+	updateDeleteRangeSQL       = `UPDATE mysql.gc_delete_range SET start_key = %? WHERE job_id = %? AND element_id = %? AND start_key = %?`
+	deleteDoneRecordSQL        = `DELETE FROM mysql.gc_delete_range_done WHERE job_id = %? AND element_id = %?`
+	loadGlobalVars             = `SELECT HIGH_PRIORITY variable_name, variable_value from mysql.global_variables where variable_name in (` // + nameList + ")"
 	// KeyOpDefaultTimeout is the default timeout for each key operation.
 	KeyOpDefaultTimeout = 2 * time.Second
 	// KeyOpRetryInterval is the interval between two key operations.
@@ -68,9 +69,33 @@ func (t DelRangeTask) Range() (kv.Key, kv.Key) {
 	return t.StartKey, t.EndKey
 }
 
-// LoadDeleteRanges loads delete range tasks from gc_delete_range table.
+// LoadDeleteRanges loads delete range tasks from gc_delete_range table. The
+// ranges of the dropped tables in the recycle bin are skipped, they're loaded
+// after the tables are expired or purged from the recycle bin.
 func LoadDeleteRanges(ctx context.Context, sctx sessionctx.Context, safePoint uint64) (ranges []DelRangeTask, _ error) {
-	return loadDeleteRangesFromTable(ctx, sctx, deleteRangesTable, safePoint)
+	ranges, err := loadDeleteRangesFromTable(ctx, sctx, deleteRangesTable, safePoint)
+	if err != nil || len(ranges) == 0 {
+		return ranges, err
+	}
+	var entries []*meta.RecycleBinEntry
+	err = kv.RunInNewTxn(ctx, sctx.GetStore(), false, func(ctx context.Context, txn kv.Transaction) error {
+		entries, err = meta.NewMeta(txn).ListRecycleBin()
+		return err
+	})
+	if err != nil || len(entries) == 0 {
+		return ranges, errors.Trace(err)
+	}
+	inBin := make(map[int64]struct{}, len(entries))
+	for _, entry := range entries {
+		inBin[entry.JobID] = struct{}{}
+	}
+	due := ranges[:0]
+	for _, r := range ranges {
+		if _, ok := inBin[r.JobID]; !ok {
+			due = append(due, r)
+		}
+	}
+	return due, nil
 }
 
 // LoadDoneDeleteRanges loads deleted ranges from gc_delete_range_done table.
@@ -146,21 +171,10 @@ func RemoveFromGCDeleteRange(sctx sessionctx.Context, jobID, elementID int64) er
 	return errors.Trace(err)
 }
 
-// RemoveMultiFromGCDeleteRange is exported for ddl pkg to use.
-func RemoveMultiFromGCDeleteRange(ctx context.Context, sctx sessionctx.Context, jobID int64, elementIDs []int64) error {
-	var buf strings.Builder
-	buf.WriteString(completeDeleteMultiRangesSQL)
-	paramIDs := make([]interface{}, 0, 1+len(elementIDs))
-	paramIDs = append(paramIDs, jobID)
-	for i, elementID := range elementIDs {
-		if i > 0 {
-			buf.WriteString(", ")
-		}
-		buf.WriteString("%?")
-		paramIDs = append(paramIDs, elementID)
-	}
-	buf.WriteString(")")
-	_, err := sctx.(sqlexec.SQLExecutor).ExecuteInternal(ctx, buf.String(), paramIDs...)
+// RemoveJobFromGCDeleteRange removes all the delete ranges of the job. The
+// element IDs of the ranges are allocated by the job, which aren't the table IDs.
+func RemoveJobFromGCDeleteRange(ctx context.Context, sctx sessionctx.Context, jobID int64) error {
+	_, err := sctx.(sqlexec.SQLExecutor).ExecuteInternal(ctx, completeDeleteJobRangesSQL, jobID)
 	return errors.Trace(err)
 }
 
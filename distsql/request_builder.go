@@ -71,6 +71,7 @@ func (builder *RequestBuilder) Build() (*kv.Request, error) {
 	if err != nil {
 		builder.err = err
 	}
+	builder.disableCoprCacheIfNeeded()
 	return &builder.Request, builder.err
 }
 
@@ -147,6 +148,7 @@ func (builder *RequestBuilder) SetDAGRequest(dag *tipb.DAGRequest) *RequestBuild
 		builder.Request.Cacheable = true
 		builder.Request.Data, builder.err = dag.Marshal()
 	}
+	builder.Request.Paging.AggPushDown = hasAggregation(dag)
 	// When the DAG is just simple scan and small limit, set concurrency to 1 would be sufficient.
 	if len(dag.Executors) == 2 && dag.Executors[1].GetLimit() != nil {
 		limit := dag.Executors[1].GetLimit()
@@ -157,6 +159,15 @@ func (builder *RequestBuilder) SetDAGRequest(dag *tipb.DAGRequest) *RequestBuild
 	return builder
 }
 
+func hasAggregation(dag *tipb.DAGRequest) bool {
+	for _, exec := range dag.Executors {
+		if exec.Tp == tipb.ExecType_TypeAggregation || exec.Tp == tipb.ExecType_TypeStreamAgg {
+			return true
+		}
+	}
+	return false
+}
+
 // SetAnalyzeRequest sets the request type to "ReqTypeAnalyze" and construct request data.
 func (builder *RequestBuilder) SetAnalyzeRequest(ana *tipb.AnalyzeReq, isoLevel kv.IsoLevel) *RequestBuilder {
 	if builder.err == nil {
@@ -267,9 +278,11 @@ func (builder *RequestBuilder) SetFromSessionVars(sv *variable.SessionVars) *Req
 		builder.SetPaging(sv.EnablePaging)
 		builder.Request.Paging.MinPagingSize = uint64(sv.MinPagingSize)
 		builder.Request.Paging.MaxPagingSize = uint64(sv.MaxPagingSize)
+		builder.Request.Paging.MaxAggPagingSize = uint64(sv.MaxAggPagingSize)
 	}
 	builder.RequestSource.RequestSourceInternal = sv.InRestrictedSQL
 	builder.RequestSource.RequestSourceType = sv.RequestSourceType
+	builder.Request.ResultBufferLimit = sv.ResultBufferLimit
 	return builder
 }
 
@@ -351,6 +364,30 @@ func (builder *RequestBuilder) verifyTxnScope() error {
 	return nil
 }
 
+// disableCoprCacheIfNeeded makes the request not cacheable if it reads a table
+// whose coprocessor cache is disabled.
+func (builder *RequestBuilder) disableCoprCacheIfNeeded() {
+	if !builder.Request.Cacheable || builder.is == nil {
+		return
+	}
+	visitPhysicalTableID := make(map[int64]struct{})
+	for _, keyRange := range builder.Request.KeyRanges {
+		tableID := tablecodec.DecodeTableID(keyRange.StartKey)
+		if _, ok := visitPhysicalTableID[tableID]; ok || tableID <= 0 {
+			continue
+		}
+		visitPhysicalTableID[tableID] = struct{}{}
+		tbl, ok := builder.is.TableByID(tableID)
+		if !ok {
+			tbl, _, _ = builder.is.FindTableByPartitionID(tableID)
+		}
+		if tbl != nil && tbl.Meta().CoprCacheDisabled {
+			builder.Request.Cacheable = false
+			return
+		}
+	}
+}
+
 // SetTxnScope sets request TxnScope
 func (builder *RequestBuilder) SetTxnScope(scope string) *RequestBuilder {
 	builder.TxnScope = scope
@@ -276,6 +276,7 @@ func TestRequestBuilder1(t *testing.T) {
 	}
 	expect.Paging.MinPagingSize = paging.MinPagingSize
 	expect.Paging.MaxPagingSize = paging.MaxPagingSize
+	expect.Paging.MaxAggPagingSize = paging.MaxPagingSize
 	actual.ResourceGroupTagger = nil
 	require.Equal(t, expect, actual)
 }
@@ -359,6 +360,7 @@ func TestRequestBuilder2(t *testing.T) {
 	}
 	expect.Paging.MinPagingSize = paging.MinPagingSize
 	expect.Paging.MaxPagingSize = paging.MaxPagingSize
+	expect.Paging.MaxAggPagingSize = paging.MaxPagingSize
 	actual.ResourceGroupTagger = nil
 	require.Equal(t, expect, actual)
 }
@@ -408,6 +410,7 @@ func TestRequestBuilder3(t *testing.T) {
 	}
 	expect.Paging.MinPagingSize = paging.MinPagingSize
 	expect.Paging.MaxPagingSize = paging.MaxPagingSize
+	expect.Paging.MaxAggPagingSize = paging.MaxPagingSize
 	actual.ResourceGroupTagger = nil
 	require.Equal(t, expect, actual)
 }
@@ -456,6 +459,7 @@ func TestRequestBuilder4(t *testing.T) {
 	}
 	expect.Paging.MinPagingSize = paging.MinPagingSize
 	expect.Paging.MaxPagingSize = paging.MaxPagingSize
+	expect.Paging.MaxAggPagingSize = paging.MaxPagingSize
 	actual.ResourceGroupTagger = nil
 	require.Equal(t, expect, actual)
 }
@@ -566,6 +570,7 @@ func TestRequestBuilder7(t *testing.T) {
 			}
 			expect.Paging.MinPagingSize = paging.MinPagingSize
 			expect.Paging.MaxPagingSize = paging.MaxPagingSize
+			expect.Paging.MaxAggPagingSize = paging.MaxPagingSize
 			actual.ResourceGroupTagger = nil
 			require.Equal(t, expect, actual)
 		})
@@ -591,6 +596,7 @@ func TestRequestBuilder8(t *testing.T) {
 	}
 	expect.Paging.MinPagingSize = paging.MinPagingSize
 	expect.Paging.MaxPagingSize = paging.MaxPagingSize
+	expect.Paging.MaxAggPagingSize = paging.MaxPagingSize
 	actual.ResourceGroupTagger = nil
 	require.Equal(t, expect, actual)
 }
@@ -702,3 +708,22 @@ func newTestFb() *statistics.QueryFeedback {
 	}
 	return fb
 }
+
+func TestRequestBuilderAggPushDown(t *testing.T) {
+	dag := &tipb.DAGRequest{
+		Executors: []*tipb.Executor{{Tp: tipb.ExecType_TypeTableScan}, {Tp: tipb.ExecType_TypeAggregation}},
+	}
+	actual, err := (&RequestBuilder{}).SetDAGRequest(dag).Build()
+	require.NoError(t, err)
+	require.True(t, actual.Paging.AggPushDown)
+
+	dag.Executors[1].Tp = tipb.ExecType_TypeStreamAgg
+	actual, err = (&RequestBuilder{}).SetDAGRequest(dag).Build()
+	require.NoError(t, err)
+	require.True(t, actual.Paging.AggPushDown)
+
+	dag.Executors = dag.Executors[:1]
+	actual, err = (&RequestBuilder{}).SetDAGRequest(dag).Build()
+	require.NoError(t, err)
+	require.False(t, actual.Paging.AggPushDown)
+}
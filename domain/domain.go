@@ -142,6 +142,9 @@ func (do *Domain) loadInfoSchema(startTS uint64) (infoschema.InfoSchema, bool, i
 	}
 
 	if is := do.infoCache.GetByVersion(neededSchemaVersion); is != nil {
+		// The schema is not changed since the infoschema is loaded, so its lazy schemas can be
+		// loaded from the newer snapshot.
+		infoschema.AdvanceLazyLoadTS(is, startTS)
 		return is, true, 0, nil, nil
 	}
 
@@ -160,6 +163,7 @@ func (do *Domain) loadInfoSchema(startTS uint64) (infoschema.InfoSchema, bool, i
 	if currentSchemaVersion != 0 && neededSchemaVersion > currentSchemaVersion && neededSchemaVersion-currentSchemaVersion < 100 {
 		is, relatedChanges, err := do.tryLoadSchemaDiffs(m, currentSchemaVersion, neededSchemaVersion)
 		if err == nil {
+			infoschema.AdvanceLazyLoadTS(is, startTS)
 			do.infoCache.Insert(is, startTS)
 			logutil.BgLogger().Info("diff load InfoSchema success",
 				zap.Int64("currentSchemaVersion", currentSchemaVersion),
@@ -173,7 +177,15 @@ func (do *Domain) loadInfoSchema(startTS uint64) (infoschema.InfoSchema, bool, i
 		logutil.BgLogger().Error("failed to load schema diff", zap.Error(err))
 	}
 
-	schemas, err := do.fetchAllSchemasWithTables(m)
+	var (
+		schemas []*model.DBInfo
+		lazy    *infoschema.LazySchemas
+	)
+	if capacity := config.GetGlobalConfig().Performance.InfoSchemaLazyLoadCapacity; capacity > 0 {
+		schemas, lazy, err = do.fetchSchemasForLazyLoad(m, startTS, capacity)
+	} else {
+		schemas, err = do.fetchAllSchemasWithTables(m)
+	}
 	if err != nil {
 		return nil, false, currentSchemaVersion, nil, err
 	}
@@ -187,6 +199,9 @@ func (do *Domain) loadInfoSchema(startTS uint64) (infoschema.InfoSchema, bool, i
 	if err != nil {
 		return nil, false, currentSchemaVersion, nil, err
 	}
+	if lazy != nil {
+		newISBuilder.WithLazySchemas(lazy)
+	}
 	logutil.BgLogger().Info("full load InfoSchema success",
 		zap.Int64("currentSchemaVersion", currentSchemaVersion),
 		zap.Int64("neededSchemaVersion", neededSchemaVersion),
@@ -259,32 +274,84 @@ func (do *Domain) fetchSchemasWithTables(schemas []*model.DBInfo, m *meta.Meta,
 			// schema is not public, can't be used outside.
 			continue
 		}
-		tables, err := m.ListTables(di.ID)
+		tables, err := fetchTables(m, di)
 		if err != nil {
 			done <- err
 			return
 		}
-		// If TreatOldVersionUTF8AsUTF8MB4 was enable, need to convert the old version schema UTF8 charset to UTF8MB4.
-		if config.GetGlobalConfig().TreatOldVersionUTF8AsUTF8MB4 {
-			for _, tbInfo := range tables {
-				infoschema.ConvertOldVersionUTF8ToUTF8MB4IfNeed(tbInfo)
-			}
+		di.Tables = tables
+	}
+	done <- nil
+}
+
+// fetchTables fetches the public tables of the schema.
+func fetchTables(m *meta.Meta, di *model.DBInfo) ([]*model.TableInfo, error) {
+	tables, err := m.ListTables(di.ID)
+	if err != nil {
+		return nil, err
+	}
+	// If TreatOldVersionUTF8AsUTF8MB4 was enable, need to convert the old version schema UTF8 charset to UTF8MB4.
+	if config.GetGlobalConfig().TreatOldVersionUTF8AsUTF8MB4 {
+		for _, tbInfo := range tables {
+			infoschema.ConvertOldVersionUTF8ToUTF8MB4IfNeed(tbInfo)
 		}
-		di.Tables = make([]*model.TableInfo, 0, len(tables))
-		for _, tbl := range tables {
-			if tbl.State != model.StatePublic {
-				// schema is not public, can't be used outside.
-				continue
+	}
+	publicTables := make([]*model.TableInfo, 0, len(tables))
+	for _, tbl := range tables {
+		if tbl.State != model.StatePublic {
+			// schema is not public, can't be used outside.
+			continue
+		}
+		infoschema.ConvertCharsetCollateToLowerCaseIfNeed(tbl)
+		// Check whether the table is in repair mode.
+		if domainutil.RepairInfo.InRepairMode() && domainutil.RepairInfo.CheckAndFetchRepairedTable(di, tbl) {
+			continue
+		}
+		publicTables = append(publicTables, tbl)
+	}
+	return publicTables, nil
+}
+
+// fetchSchemasForLazyLoad fetches the tables of the system schemas, and only the table briefs of
+// the user schemas, whose tables are loaded by the infoschema on the first access.
+func (do *Domain) fetchSchemasForLazyLoad(m *meta.Meta, startTS uint64, capacity uint) ([]*model.DBInfo, *infoschema.LazySchemas, error) {
+	allSchemas, err := m.ListDatabases()
+	if err != nil {
+		return nil, nil, err
+	}
+	schemas := make([]*model.DBInfo, 0, len(allSchemas))
+	lazy := &infoschema.LazySchemas{
+		TableBriefs: make(map[int64][]*model.TableInfo),
+		SnapshotTS:  startTS,
+		Capacity:    capacity,
+		FetchTables: fetchTables,
+	}
+	for _, di := range allSchemas {
+		if di.State != model.StatePublic {
+			// schema is not public, can't be used outside.
+			continue
+		}
+		if util.IsMemOrSysDB(di.Name.L) {
+			if di.Tables, err = fetchTables(m, di); err != nil {
+				return nil, nil, err
 			}
-			infoschema.ConvertCharsetCollateToLowerCaseIfNeed(tbl)
-			// Check whether the table is in repair mode.
-			if domainutil.RepairInfo.InRepairMode() && domainutil.RepairInfo.CheckAndFetchRepairedTable(di, tbl) {
-				continue
+			schemas = append(schemas, di)
+			continue
+		}
+		briefs, err := m.ListTableBriefs(di.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		publicBriefs := make([]*model.TableInfo, 0, len(briefs))
+		for _, brief := range briefs {
+			if brief.State == model.StatePublic {
+				publicBriefs = append(publicBriefs, brief)
 			}
-			di.Tables = append(di.Tables, tbl)
 		}
+		lazy.TableBriefs[di.ID] = publicBriefs
+		lazy.DBInfos = append(lazy.DBInfos, di)
 	}
-	done <- nil
+	return schemas, lazy, nil
 }
 
 // tryLoadSchemaDiffs tries to only load latest schema changes.
@@ -17,17 +17,27 @@ package domain
 import (
 	"time"
 
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/util/logutil"
 	"github.com/tikv/client-go/v2/tikv"
 	"github.com/tikv/client-go/v2/txnkv/transaction"
 	atomicutil "go.uber.org/atomic"
+	"go.uber.org/zap"
 )
 
 // SchemaChecker is used for checking schema-validity.
 type SchemaChecker struct {
 	SchemaValidator
+	do              *Domain
 	schemaVer       int64
 	relatedTableIDs []int64
+	// maxVersionLag is the max number of schema versions schemaVer can fall
+	// behind the latest one, which are checked by reading the schema diffs if
+	// the change history of SchemaValidator doesn't cover them.
+	maxVersionLag int64
 }
 
 type intSchemaVer int64
@@ -47,11 +57,19 @@ var (
 func NewSchemaChecker(do *Domain, schemaVer int64, relatedTableIDs []int64) *SchemaChecker {
 	return &SchemaChecker{
 		SchemaValidator: do.SchemaValidator,
+		do:              do,
 		schemaVer:       schemaVer,
 		relatedTableIDs: relatedTableIDs,
 	}
 }
 
+// SetMaxVersionLag sets the max number of schema versions the checked schema
+// version can fall behind the latest one when the change history doesn't cover
+// them. 0 means the check fails in this case.
+func (s *SchemaChecker) SetMaxVersionLag(lag int64) {
+	s.maxVersionLag = lag
+}
+
 // Check checks the validity of the schema version.
 func (s *SchemaChecker) Check(txnTS uint64) (*transaction.RelatedSchemaChange, error) {
 	return s.CheckBySchemaVer(txnTS, intSchemaVer(s.schemaVer))
@@ -67,6 +85,16 @@ func (s *SchemaChecker) CheckBySchemaVer(txnTS uint64, startSchemaVer tikv.Schem
 		case ResultSucc:
 			return nil, nil
 		case ResultFail:
+			// the related tables aren't known to be changed, maybe the change
+			// history is too short, check the schema diffs instead.
+			if relatedChange == nil && s.relatedTableIDs != nil && s.maxVersionLag > 0 {
+				changed, err := s.relatedTablesChangedByDiffs(startSchemaVer.SchemaMetaVersion())
+				if err != nil {
+					logutil.BgLogger().Warn("check the schema diffs failed", zap.Int64("schemaVer", startSchemaVer.SchemaMetaVersion()), zap.Error(err))
+				} else if !changed {
+					return nil, nil
+				}
+			}
 			metrics.SchemaLeaseErrorCounter.WithLabelValues("changed").Inc()
 			return relatedChange, ErrInfoSchemaChanged
 		case ResultUnknown:
@@ -76,3 +104,59 @@ func (s *SchemaChecker) CheckBySchemaVer(txnTS uint64, startSchemaVer tikv.Schem
 	metrics.SchemaLeaseErrorCounter.WithLabelValues("outdated").Inc()
 	return nil, ErrInfoSchemaExpired
 }
+
+// relatedTablesChangedByDiffs reads the schema diffs from schemaVer to the
+// latest schema version, and checks whether the related tables are changed by
+// them. The tables are checked by their logical table IDs, so that a change
+// on any partition of a partitioned table changes all its partitions. It
+// returns true if there're more than maxVersionLag schema versions to check.
+func (s *SchemaChecker) relatedTablesChangedByDiffs(schemaVer int64) (bool, error) {
+	is := s.do.InfoSchema()
+	latestVer := is.SchemaMetaVersion()
+	if latestVer-schemaVer > s.maxVersionLag {
+		return true, nil
+	}
+	relatedIDs := make(map[int64]struct{}, len(s.relatedTableIDs))
+	for _, id := range s.relatedTableIDs {
+		if _, ok := is.TableByID(id); ok {
+			relatedIDs[id] = struct{}{}
+			continue
+		}
+		tbl, _, _ := is.FindTableByPartitionID(id)
+		if tbl == nil {
+			// the table or the partition is dropped.
+			return true, nil
+		}
+		relatedIDs[tbl.Meta().ID] = struct{}{}
+	}
+
+	ver, err := s.do.Store().CurrentVersion(kv.GlobalTxnScope)
+	if err != nil {
+		return true, err
+	}
+	m := meta.NewSnapshotMeta(s.do.Store().GetSnapshot(ver))
+	for v := schemaVer + 1; v <= latestVer; v++ {
+		diff, err := m.GetSchemaDiff(v)
+		if err != nil {
+			return true, err
+		}
+		if diff == nil || canSkipSchemaCheckerDDL(diff.Type) {
+			continue
+		}
+		switch diff.Type {
+		case model.ActionModifySchemaDefaultPlacement, model.ActionAlterPlacementPolicy, model.ActionDropPlacementPolicy:
+			// they may change the placement of any table.
+			return true, nil
+		}
+		tableIDs := []int64{diff.TableID, diff.OldTableID}
+		for _, opt := range diff.AffectedOpts {
+			tableIDs = append(tableIDs, opt.TableID, opt.OldTableID)
+		}
+		for _, id := range tableIDs {
+			if _, ok := relatedIDs[id]; ok {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -220,4 +220,9 @@ type SlowQueryInfo struct {
 	Digest     string
 	Internal   bool
 	Succ       bool
+	PlanDigest string
+	// BindSQL is the SQL of the binding used to build the plan, empty if no binding is used.
+	BindSQL string
+	// StatsInfo is the versions of the statistics used by the plan, e.g. "t1:435234525,t2:pseudo".
+	StatsInfo string
 }
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/pingcap/tidb/br/pkg/storage"
 	"github.com/pingcap/tidb/dumpling/cli"
 	"github.com/pingcap/tidb/dumpling/export"
 	"github.com/prometheus/client_golang/prometheus"
@@ -58,6 +59,13 @@ func main() {
 		fmt.Printf("\nmeet some unparsed arguments, please check again: %+v\n", pflag.Args())
 		os.Exit(1)
 	}
+	if printSource, _ := pflag.CommandLine.GetBool(storage.FlagPrintResolvedCredentialsSource); printSource {
+		if err := conf.PrintResolvedCredentialsSource(context.Background(), os.Stdout); err != nil {
+			fmt.Printf("\nresolve the credentials failed: %s\n", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
 
 	registry := conf.PromRegistry
 	registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
@@ -0,0 +1,140 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package export
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tcontext "github.com/pingcap/tidb/dumpling/context"
+	"github.com/pingcap/tidb/dumpling/log"
+	"go.uber.org/zap"
+)
+
+const (
+	adjustConcurrencyTick = 30 * time.Second
+	// the concurrency is halved when the CPU usage of the busiest TiDB server is higher than
+	// highTiDBCPUUsage, and increased by one when it's lower than lowTiDBCPUUsage.
+	highTiDBCPUUsage = 0.8
+	lowTiDBCPUUsage  = 0.5
+)
+
+// concurrencyController limits the number of the writers which are dumping at the same time.
+// A nil *concurrencyController doesn't limit anything.
+type concurrencyController struct {
+	mu      sync.Mutex
+	limit   int
+	max     int
+	running int
+	// changed is closed and renewed when a writer may be able to run
+	changed chan struct{}
+}
+
+func newConcurrencyController(max int) *concurrencyController {
+	return &concurrencyController{
+		limit:   max,
+		max:     max,
+		changed: make(chan struct{}),
+	}
+}
+
+// acquire blocks until the writer is allowed to run, it returns false if ctx is done.
+func (c *concurrencyController) acquire(ctx context.Context) bool {
+	if c == nil {
+		return true
+	}
+	for {
+		c.mu.Lock()
+		if c.running < c.limit {
+			c.running++
+			c.mu.Unlock()
+			return true
+		}
+		changed := c.changed
+		c.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return false
+		case <-changed:
+		}
+	}
+}
+
+func (c *concurrencyController) release() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.running--
+	c.notifyLocked()
+	c.mu.Unlock()
+}
+
+func (c *concurrencyController) getLimit() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.limit
+}
+
+// setLimit sets the limit in [1, max]. The running writers are not interrupted when the limit is decreased.
+func (c *concurrencyController) setLimit(limit int) {
+	if limit < 1 {
+		limit = 1
+	} else if limit > c.max {
+		limit = c.max
+	}
+	c.mu.Lock()
+	c.limit = limit
+	c.notifyLocked()
+	c.mu.Unlock()
+}
+
+func (c *concurrencyController) notifyLocked() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// nextConcurrencyLimit decreases the concurrency multiplicatively and increases it additively.
+func nextConcurrencyLimit(limit, max int, cpuUsage float64) int {
+	switch {
+	case cpuUsage >= highTiDBCPUUsage:
+		limit /= 2
+	case cpuUsage < lowTiDBCPUUsage:
+		limit++
+	}
+	if limit < 1 {
+		return 1
+	}
+	if limit > max {
+		return max
+	}
+	return limit
+}
+
+func (d *Dumper) runAdjustConcurrency(tctx *tcontext.Context, ctrl *concurrencyController) {
+	ticker := time.NewTicker(adjustConcurrencyTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-tctx.Done():
+			tctx.L().Debug("stopping adjust concurrency")
+			return
+		case <-ticker.C:
+			cpuUsage, err := getTiDBCPUUsage(tctx, d.dbHandle)
+			if err != nil {
+				tctx.L().Info("cannot get the CPU usage of TiDB servers, skip adjusting concurrency", log.ShortError(err))
+				continue
+			}
+			oldLimit := ctrl.getLimit()
+			newLimit := nextConcurrencyLimit(oldLimit, ctrl.max, cpuUsage)
+			if newLimit != oldLimit {
+				ctrl.setLimit(newLimit)
+				tctx.L().Info("adjust the concurrency of dumping",
+					zap.Float64("TiDB CPU usage", cpuUsage),
+					zap.Int("old concurrency", oldLimit),
+					zap.Int("new concurrency", newLimit))
+			}
+		}
+	}
+}
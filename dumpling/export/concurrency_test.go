@@ -0,0 +1,63 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package export
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextConcurrencyLimit(t *testing.T) {
+	testCases := []struct {
+		limit    int
+		cpuUsage float64
+		expected int
+	}{
+		{8, 0.9, 4},
+		{1, 0.9, 1},
+		{4, 0.6, 4},
+		{4, 0.1, 5},
+		{8, 0.1, 8},
+	}
+	for _, testCase := range testCases {
+		require.Equal(t, testCase.expected, nextConcurrencyLimit(testCase.limit, 8, testCase.cpuUsage))
+	}
+}
+
+func TestConcurrencyController(t *testing.T) {
+	// nil controller doesn't limit anything
+	var nilCtrl *concurrencyController
+	require.True(t, nilCtrl.acquire(context.Background()))
+	nilCtrl.release()
+
+	ctrl := newConcurrencyController(4)
+	ctrl.setLimit(0)
+	require.Equal(t, 1, ctrl.getLimit())
+	ctrl.setLimit(10)
+	require.Equal(t, 4, ctrl.getLimit())
+
+	ctrl.setLimit(1)
+	require.True(t, ctrl.acquire(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.False(t, ctrl.acquire(ctx))
+
+	acquired := make(chan struct{})
+	go func() {
+		ctrl.acquire(context.Background())
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		require.FailNow(t, "should be blocked by the limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+	// increasing the limit wakes up the blocked writer
+	ctrl.setLimit(2)
+	<-acquired
+	ctrl.release()
+	ctrl.release()
+}
@@ -7,13 +7,16 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/coreos/go-semver/semver"
 	"github.com/docker/go-units"
 	"github.com/go-sql-driver/mysql"
@@ -25,6 +28,7 @@ import (
 	filter "github.com/pingcap/tidb/util/table-filter"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/pflag"
+	"github.com/xitongsys/parquet-go/parquet"
 	"go.uber.org/zap"
 )
 
@@ -51,6 +55,7 @@ const (
 	flagStatusAddr               = "status-addr"
 	flagRows                     = "rows"
 	flagWhere                    = "where"
+	flagWhereConfig              = "where-config"
 	flagEscapeBackslash          = "escape-backslash"
 	flagFiletype                 = "filetype"
 	flagNoHeader                 = "no-header"
@@ -68,11 +73,16 @@ const (
 	flagCsvSeparator             = "csv-separator"
 	flagCsvDelimiter             = "csv-delimiter"
 	flagOutputFilenameTemplate   = "output-filename-template"
+	flagOutputLayout             = "output-layout"
 	flagCompleteInsert           = "complete-insert"
 	flagParams                   = "params"
 	flagReadTimeout              = "read-timeout"
 	flagTransactionalConsistency = "transactional-consistency"
 	flagCompress                 = "compress"
+	flagReadFromTiFlash          = "read-from-tiflash"
+	flagAdaptiveThreads          = "adaptive-threads"
+	flagParquetCompress          = "parquet-compress"
+	flagParquetRowGroupSize      = "parquet-row-group-size"
 
 	// FlagHelp represents the help flag
 	FlagHelp = "help"
@@ -95,7 +105,10 @@ type Config struct {
 	EscapeBackslash          bool
 	DumpEmptyDatabase        bool
 	PosAfterConnect          bool
+	ReadFromTiFlash          bool
+	AdaptiveThreads          bool
 	CompressType             storage.CompressType
+	ParquetCompressType      parquet.CompressionCodec
 
 	Host     string
 	Port     int
@@ -126,15 +139,18 @@ type Config struct {
 
 	TableFilter         filter.Filter `json:"-"`
 	Where               string
+	TableWheres         []TableWhere
 	FileType            string
 	ServerInfo          version.ServerInfo
 	Logger              *zap.Logger        `json:"-"`
 	OutputFileTemplate  *template.Template `json:"-"`
+	OutputLayout        string
 	Rows                uint64
 	ReadTimeout         time.Duration
 	TiDBMemQuotaQuery   uint64
 	FileSize            uint64
 	StatementSize       uint64
+	ParquetRowGroupSize uint64
 	SessionParams       map[string]interface{}
 	Tables              DatabaseTables
 	CollationCompatible string
@@ -165,6 +181,8 @@ func DefaultConfig() *Config {
 		StatusAddr:          ":8281",
 		FileSize:            UnspecifiedSize,
 		StatementSize:       DefaultStatementSize,
+		ParquetRowGroupSize: DefaultParquetRowGroupSize,
+		ParquetCompressType: parquet.CompressionCodec_SNAPPY,
 		OutputDirPath:       ".",
 		ServerInfo:          ServerInfoUnknown,
 		SortByPk:            true,
@@ -248,8 +266,9 @@ func (*Config) DefineFlags(flags *pflag.FlagSet) {
 	flags.Uint64P(flagRows, "r", UnspecifiedSize, "If specified, dumpling will split table into chunks and concurrently dump them to different files to improve efficiency. For TiDB v3.0+, specify this will make dumpling split table with each file one TiDB region(no matter how many rows is).\n"+
 		"If not specified, dumpling will dump table without inner-concurrency which could be relatively slow. default unlimited")
 	flags.String(flagWhere, "", "Dump only selected records")
+	flags.String(flagWhereConfig, "", "The path of a TOML or JSON file which specifies the WHERE conditions of the tables, the tables not matched use --where")
 	flags.Bool(flagEscapeBackslash, true, "use backslash to escape special characters")
-	flags.String(flagFiletype, "", "The type of export file (sql/csv)")
+	flags.String(flagFiletype, "", "The type of export file (sql/csv/parquet)")
 	flags.Bool(flagNoHeader, false, "whether not to dump CSV table header")
 	flags.BoolP(flagNoSchemas, "m", false, "Do not dump table schemas with the data")
 	flags.BoolP(flagNoData, "d", false, "Do not dump table data")
@@ -266,6 +285,7 @@ func (*Config) DefineFlags(flags *pflag.FlagSet) {
 	flags.String(flagCsvSeparator, ",", "The separator for csv files, default ','")
 	flags.String(flagCsvDelimiter, "\"", "The delimiter for values in csv files, default '\"'")
 	flags.String(flagOutputFilenameTemplate, "", "The output filename template (without file extension)")
+	flags.String(flagOutputLayout, "", "The directory of the output files of each table, e.g. '{schema}/{table}/', supports the placeholders {schema} and {table}")
 	flags.Bool(flagCompleteInsert, false, "Use complete INSERT statements that include column names")
 	flags.StringToString(flagParams, nil, `Extra session variables used while dumping, accepted format: --params "character_set_client=latin1,character_set_connection=latin1"`)
 	flags.Bool(FlagHelp, false, "Print help message and quit")
@@ -274,6 +294,10 @@ func (*Config) DefineFlags(flags *pflag.FlagSet) {
 	flags.Bool(flagTransactionalConsistency, true, "Only support transactional consistency")
 	_ = flags.MarkHidden(flagTransactionalConsistency)
 	flags.StringP(flagCompress, "c", "", "Compress output file type, support 'gzip', 'no-compression' now")
+	flags.String(flagParquetCompress, "snappy", "The compression codec of parquet files, support 'snappy', 'gzip', 'zstd', 'no-compression'")
+	flags.String(flagParquetRowGroupSize, "128MiB", "The uncompressed size of a row group in parquet files, default '128MiB'")
+	flags.Bool(flagReadFromTiFlash, false, "Read the table data from the TiFlash replicas, the tables without available TiFlash replicas are read from TiKV. Valid only when consistency=snapshot")
+	flags.Bool(flagAdaptiveThreads, false, "Adjust the number of dumping threads between 1 and --threads according to the CPU usage of the TiDB servers. Valid only for TiDB")
 }
 
 // ParseFromFlags parses dumpling's export.Config from flags
@@ -428,6 +452,14 @@ func (conf *Config) ParseFromFlags(flags *pflag.FlagSet) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	conf.ReadFromTiFlash, err = flags.GetBool(flagReadFromTiFlash)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	conf.AdaptiveThreads, err = flags.GetBool(flagAdaptiveThreads)
+	if err != nil {
+		return errors.Trace(err)
+	}
 
 	if conf.Threads <= 0 {
 		return errors.Errorf("--threads is set to %d. It should be greater than 0", conf.Threads)
@@ -480,6 +512,21 @@ func (conf *Config) ParseFromFlags(flags *pflag.FlagSet) error {
 		conf.TableFilter = filter.CaseInsensitive(conf.TableFilter)
 	}
 
+	whereConfigPath, err := flags.GetString(flagWhereConfig)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if whereConfigPath != "" {
+		data, err := ioutil.ReadFile(whereConfigPath)
+		if err != nil {
+			return errors.Annotatef(err, "failed to read where config (--where-config '%s')", whereConfigPath)
+		}
+		conf.TableWheres, err = ParseTableWheres(data, strings.EqualFold(filepath.Ext(whereConfigPath), ".json"), caseSensitive)
+		if err != nil {
+			return errors.Annotatef(err, "failed to parse where config (--where-config '%s')", whereConfigPath)
+		}
+	}
+
 	conf.FileSize, err = ParseFileSize(fileSizeStr)
 	if err != nil {
 		return errors.Trace(err)
@@ -494,6 +541,11 @@ func (conf *Config) ParseFromFlags(flags *pflag.FlagSet) error {
 	}
 	conf.OutputFileTemplate = tmpl
 
+	conf.OutputLayout, err = flags.GetString(flagOutputLayout)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	compressType, err := flags.GetString(flagCompress)
 	if err != nil {
 		return errors.Trace(err)
@@ -502,6 +554,22 @@ func (conf *Config) ParseFromFlags(flags *pflag.FlagSet) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	parquetCompressType, err := flags.GetString(flagParquetCompress)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	conf.ParquetCompressType, err = ParseParquetCompressType(parquetCompressType)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	parquetRowGroupSizeStr, err := flags.GetString(flagParquetRowGroupSize)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	conf.ParquetRowGroupSize, err = ParseParquetRowGroupSize(parquetRowGroupSizeStr)
+	if err != nil {
+		return errors.Trace(err)
+	}
 
 	for k, v := range params {
 		conf.SessionParams[k] = v
@@ -583,6 +651,90 @@ func ParseCompressType(compressType string) (storage.CompressType, error) {
 	}
 }
 
+// ParseParquetCompressType parses compressType string to the compression codec of parquet files
+func ParseParquetCompressType(compressType string) (parquet.CompressionCodec, error) {
+	switch strings.ToLower(compressType) {
+	case "", "snappy":
+		return parquet.CompressionCodec_SNAPPY, nil
+	case "gzip", "gz":
+		return parquet.CompressionCodec_GZIP, nil
+	case "zstd":
+		return parquet.CompressionCodec_ZSTD, nil
+	case "no-compression", "uncompressed":
+		return parquet.CompressionCodec_UNCOMPRESSED, nil
+	default:
+		return parquet.CompressionCodec_UNCOMPRESSED, errors.Errorf("unknown parquet compress type %s", compressType)
+	}
+}
+
+// ParseParquetRowGroupSize parses the row group size of parquet files, e.g. "64MiB"
+func ParseParquetRowGroupSize(rowGroupSizeStr string) (uint64, error) {
+	if len(rowGroupSizeStr) == 0 {
+		return DefaultParquetRowGroupSize, nil
+	}
+	size, err := units.RAMInBytes(rowGroupSizeStr)
+	if err != nil || size <= 0 {
+		return 0, errors.Errorf("failed to parse parquet row group size (--parquet-row-group-size '%s')", rowGroupSizeStr)
+	}
+	return uint64(size), nil
+}
+
+// TableWhere is the WHERE condition used to dump the tables matched by Filter instead of --where.
+type TableWhere struct {
+	Filter filter.Filter `json:"-"`
+	Where  string
+}
+
+// ParseTableWheres parses the content of --where-config. The file lists the WHERE conditions of the table patterns, e.g.
+//
+//	[[rules]]
+//	tables = ["shop.orders", "shop.order_items_*"]
+//	where = "created_at >= NOW() - INTERVAL 30 DAY"
+//
+// When a table matches several rules, the first one is used.
+func ParseTableWheres(data []byte, isJSON bool, caseSensitive bool) ([]TableWhere, error) {
+	var whereConfig struct {
+		Rules []struct {
+			Tables []string `toml:"tables" json:"tables"`
+			Where  string   `toml:"where" json:"where"`
+		} `toml:"rules" json:"rules"`
+	}
+	var err error
+	if isJSON {
+		err = json.Unmarshal(data, &whereConfig)
+	} else {
+		_, err = toml.Decode(string(data), &whereConfig)
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tableWheres := make([]TableWhere, 0, len(whereConfig.Rules))
+	for i, rule := range whereConfig.Rules {
+		if len(rule.Tables) == 0 || strings.TrimSpace(rule.Where) == "" {
+			return nil, errors.Errorf("rule #%d should specify both tables and where", i+1)
+		}
+		f, err := filter.Parse(rule.Tables)
+		if err != nil {
+			return nil, errors.Annotatef(err, "rule #%d", i+1)
+		}
+		if !caseSensitive {
+			f = filter.CaseInsensitive(f)
+		}
+		tableWheres = append(tableWheres, TableWhere{Filter: f, Where: rule.Where})
+	}
+	return tableWheres, nil
+}
+
+// getWhere returns the WHERE condition used to dump the table.
+func (conf *Config) getWhere(db, tbl string) string {
+	for _, tableWhere := range conf.TableWheres {
+		if tableWhere.Filter.MatchTable(db, tbl) {
+			return tableWhere.Where
+		}
+	}
+	return conf.Where
+}
+
 func (conf *Config) createExternalStorage(ctx context.Context) (storage.ExternalStorage, error) {
 	if conf.ExtStorage != nil {
 		return conf.ExtStorage, nil
@@ -596,11 +748,23 @@ func (conf *Config) createExternalStorage(ctx context.Context) (storage.External
 	return storage.New(ctx, b, &storage.ExternalStorageOptions{})
 }
 
+// PrintResolvedCredentialsSource prints where the credentials of the output
+// storage are resolved from.
+func (conf *Config) PrintResolvedCredentialsSource(ctx context.Context, w io.Writer) error {
+	b, err := storage.ParseBackend(conf.OutputDirPath, &conf.BackendOptions)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return storage.PrintResolvedCredentialsSource(ctx, w, b, &storage.ExternalStorageOptions{})
+}
+
 const (
 	// UnspecifiedSize means the filesize/statement-size is unspecified
 	UnspecifiedSize = 0
 	// DefaultStatementSize is the default statement size
 	DefaultStatementSize = 1000000
+	// DefaultParquetRowGroupSize is the default uncompressed size of a row group in parquet files
+	DefaultParquetRowGroupSize = 128 * units.MiB
 	// TiDBMemQuotaQueryName is the session variable TiDBMemQuotaQuery's name in TiDB
 	TiDBMemQuotaQueryName = "tidb_mem_quota_query"
 	// DefaultTableFilter is the default exclude table filter. It will exclude all system databases
@@ -678,6 +842,18 @@ func validateSpecifiedSQL(conf *Config) error {
 	if conf.SQL != "" && conf.Where != "" {
 		return errors.New("can't specify both --sql and --where at the same time. Please try to combine them into --sql")
 	}
+	if conf.SQL != "" && len(conf.TableWheres) > 0 {
+		return errors.New("can't specify both --sql and --where-config at the same time. Please try to combine them into --sql")
+	}
+	return nil
+}
+
+func adjustOutputLayout(conf *Config) error {
+	layout, err := ParseOutputLayout(conf.OutputLayout)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	conf.OutputLayout = layout
 	return nil
 }
 
@@ -695,6 +871,10 @@ func adjustFileFormat(conf *Config) error {
 			return errors.Errorf("unsupported config.FileType '%s' when we specify --sql, please unset --filetype or set it to 'csv'", conf.FileType)
 		}
 	case FileFormatCSVString:
+	case FileFormatParquetString:
+		if conf.CompressType != storage.NoCompression {
+			return errors.Errorf("unsupported config.FileType '%s' when we specify --compress, please use --parquet-compress instead", conf.FileType)
+		}
 	default:
 		return errors.Errorf("unknown config.FileType '%s'", conf.FileType)
 	}
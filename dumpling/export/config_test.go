@@ -9,6 +9,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/version"
 	tcontext "github.com/pingcap/tidb/dumpling/context"
 	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go/parquet"
 )
 
 func TestCreateExternalStorage(t *testing.T) {
@@ -51,3 +52,71 @@ func TestGetConfTables(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, expectedDBTables, actualDBTables)
 }
+
+func TestParseParquetOptions(t *testing.T) {
+	codec, err := ParseParquetCompressType("")
+	require.NoError(t, err)
+	require.Equal(t, parquet.CompressionCodec_SNAPPY, codec)
+	codec, err = ParseParquetCompressType("ZSTD")
+	require.NoError(t, err)
+	require.Equal(t, parquet.CompressionCodec_ZSTD, codec)
+	codec, err = ParseParquetCompressType("no-compression")
+	require.NoError(t, err)
+	require.Equal(t, parquet.CompressionCodec_UNCOMPRESSED, codec)
+	_, err = ParseParquetCompressType("lzo")
+	require.EqualError(t, err, "unknown parquet compress type lzo")
+
+	size, err := ParseParquetRowGroupSize("")
+	require.NoError(t, err)
+	require.Equal(t, uint64(DefaultParquetRowGroupSize), size)
+	size, err = ParseParquetRowGroupSize("64MiB")
+	require.NoError(t, err)
+	require.Equal(t, uint64(64<<20), size)
+	_, err = ParseParquetRowGroupSize("abc")
+	require.Error(t, err)
+}
+
+func TestParseTableWheres(t *testing.T) {
+	tomlConfig := `
+[[rules]]
+tables = ["shop.orders", "shop.order_items_*"]
+where = "created_at >= '2023-01-01'"
+
+[[rules]]
+tables = ["shop.*"]
+where = "id < 100"
+`
+	jsonConfig := `{"rules": [
+	{"tables": ["shop.orders", "shop.order_items_*"], "where": "created_at >= '2023-01-01'"},
+	{"tables": ["shop.*"], "where": "id < 100"}
+]}`
+	for _, tc := range []struct {
+		data   string
+		isJSON bool
+	}{{tomlConfig, false}, {jsonConfig, true}} {
+		tableWheres, err := ParseTableWheres([]byte(tc.data), tc.isJSON, false)
+		require.NoError(t, err)
+		require.Len(t, tableWheres, 2)
+
+		conf := defaultConfigForTest(t)
+		conf.Where = "deleted = 0"
+		conf.TableWheres = tableWheres
+		require.Equal(t, "created_at >= '2023-01-01'", conf.getWhere("shop", "orders"))
+		require.Equal(t, "created_at >= '2023-01-01'", conf.getWhere("SHOP", "order_items_2023"))
+		require.Equal(t, "id < 100", conf.getWhere("shop", "users"))
+		require.Equal(t, "deleted = 0", conf.getWhere("crm", "orders"))
+	}
+
+	_, err := ParseTableWheres([]byte(`[[rules]]
+tables = ["shop.orders"]
+`), false, false)
+	require.EqualError(t, err, "rule #1 should specify both tables and where")
+	_, err = ParseTableWheres([]byte(`{"rules": [{"tables": ["shop"], "where": "id < 100"}]}`), true, false)
+	require.ErrorContains(t, err, "rule #1")
+
+	conf := defaultConfigForTest(t)
+	conf.SQL = "select * from shop.orders"
+	conf.TableWheres, err = ParseTableWheres([]byte(jsonConfig), true, false)
+	require.NoError(t, err)
+	require.EqualError(t, validateSpecifiedSQL(conf), "can't specify both --sql and --where-config at the same time. Please try to combine them into --sql")
+}
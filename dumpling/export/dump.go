@@ -55,6 +55,8 @@ type Dumper struct {
 	selectTiDBTableRegionFunc     func(tctx *tcontext.Context, conn *BaseConn, meta TableMeta) (pkFields []string, pkVals [][]string, err error)
 	totalTables                   int64
 	charsetAndDefaultCollationMap map[string]string
+	// concurrencyCtrl is not nil when the number of threads is adjusted by the TiDB load
+	concurrencyCtrl *concurrencyController
 }
 
 // NewDumper returns a new Dumper
@@ -93,6 +95,7 @@ func NewDumper(ctx context.Context, conf *Config) (*Dumper, error) {
 	err = adjustConfig(conf,
 		registerTLSConfig,
 		validateSpecifiedSQL,
+		adjustOutputLayout,
 		adjustFileFormat)
 	if err != nil {
 		return nil, err
@@ -169,6 +172,7 @@ func (d *Dumper) Dump() (dumpErr error) {
 		_ = metaConn.Close()
 	}()
 	m.recordStartTime(time.Now())
+	m.recordRowOrder(conf.SortByPk)
 	// for consistency lock, we can write snapshot info after all tables are locked.
 	// the binlog pos may changed because there is still possible write between we lock tables and write master status.
 	// but for the locked tables doing replication that starts from metadata is safe.
@@ -223,6 +227,14 @@ func (d *Dumper) Dump() (dumpErr error) {
 		return conn, nil
 	}
 
+	if conf.AdaptiveThreads {
+		if conf.ServerInfo.ServerType == version.ServerTypeTiDB {
+			d.concurrencyCtrl = newConcurrencyController(conf.Threads)
+		} else {
+			tctx.L().Warn("--adaptive-threads is only supported for TiDB, the number of threads won't be adjusted")
+		}
+	}
+
 	taskChan := make(chan Task, defaultDumpThreads)
 	AddGauge(d.metrics.taskChannelCapacity, defaultDumpThreads)
 	wg, writingCtx := errgroup.WithContext(tctx)
@@ -259,6 +271,11 @@ func (d *Dumper) Dump() (dumpErr error) {
 	logProgressCtx, logProgressCancel := tctx.WithCancel()
 	go d.runLogProgress(logProgressCtx)
 	defer logProgressCancel()
+	if d.concurrencyCtrl != nil {
+		adjustConcurrencyCtx, adjustConcurrencyCancel := tctx.WithCancel()
+		go d.runAdjustConcurrency(adjustConcurrencyCtx, d.concurrencyCtrl)
+		defer adjustConcurrencyCancel()
+	}
 
 	tableDataStartTime := time.Now()
 
@@ -305,6 +322,7 @@ func (d *Dumper) startWriters(tctx *tcontext.Context, wg *errgroup.Group, taskCh
 		}
 		writer := NewWriter(tctx, int64(i), conf, conn, d.extStore, d.metrics)
 		writer.rebuildConnFn = rebuildConnFn
+		writer.concurrencyCtrl = d.concurrencyCtrl
 		writer.setFinishTableCallBack(func(task Task) {
 			if _, ok := task.(*TaskTableData); ok {
 				IncCounter(d.metrics.finishedTablesCounter)
@@ -692,10 +710,18 @@ func (d *Dumper) concurrentDumpTable(tctx *tcontext.Context, conn *BaseConn, met
 		if err2 := errors.Cause(err); err2 == nil || err2 == context.DeadlineExceeded || err2 == context.Canceled {
 			return err
 		} else if err2 != errEmptyHandleVals {
-			tctx.L().Info("fallback to concurrent dump tables using rows due to some problem. This won't influence the whole dump process",
+			tctx.L().Info("fallback to concurrent dump tables using statistics due to some problem. This won't influence the whole dump process",
 				zap.String("database", db), zap.String("table", tbl), log.ShortError(err))
 		}
 	}
+	if conf.ServerInfo.ServerType == version.ServerTypeTiDB {
+		err := d.concurrentDumpTiDBTablesByStats(tctx, conn, meta, taskChan)
+		if err2 := errors.Cause(err); err2 == nil || err2 == context.DeadlineExceeded || err2 == context.Canceled {
+			return err
+		}
+		tctx.L().Info("fallback to concurrent dump tables using rows due to some problem. This won't influence the whole dump process",
+			zap.String("database", db), zap.String("table", tbl), log.ShortError(err))
+	}
 
 	orderByClause, err := buildOrderByClause(tctx, conf, conn, db, tbl, meta.HasImplicitRowID())
 	if err != nil {
@@ -745,17 +771,17 @@ func (d *Dumper) concurrentDumpTable(tctx *tcontext.Context, conn *BaseConn, met
 		totalChunks = new(big.Int).Sub(max, min).Uint64() + 1
 	}
 
-	selectField, selectLen := meta.SelectedField(), meta.SelectedLen()
+	selectField, selectLen := selectFieldWithHint(meta), meta.SelectedLen()
 
 	chunkIndex := 0
 	nullValueCondition := ""
-	if conf.Where == "" {
+	if conf.getWhere(db, tbl) == "" {
 		nullValueCondition = fmt.Sprintf("`%s` IS NULL OR ", escapeString(field))
 	}
 	for max.Cmp(cutoff) >= 0 {
 		nextCutOff := new(big.Int).Add(cutoff, bigEstimatedStep)
 		where := fmt.Sprintf("%s(`%s` >= %d AND `%s` < %d)", nullValueCondition, escapeString(field), cutoff, escapeString(field), nextCutOff)
-		query := buildSelectQuery(db, tbl, selectField, "", buildWhereCondition(conf, where), orderByClause)
+		query := buildSelectQuery(db, tbl, selectField, "", buildWhereCondition(conf, db, tbl, where), orderByClause)
 		if len(nullValueCondition) > 0 {
 			nullValueCondition = ""
 		}
@@ -786,8 +812,8 @@ func (d *Dumper) selectMinAndMaxIntValue(tctx *tcontext.Context, conn *BaseConn,
 	conf, zero := d.conf, &big.Int{}
 	query := fmt.Sprintf("SELECT MIN(`%s`),MAX(`%s`) FROM `%s`.`%s`",
 		escapeString(field), escapeString(field), escapeString(db), escapeString(tbl))
-	if conf.Where != "" {
-		query = fmt.Sprintf("%s WHERE %s", query, conf.Where)
+	if where := conf.getWhere(db, tbl); where != "" {
+		query = fmt.Sprintf("%s WHERE %s", query, where)
 	}
 	tctx.L().Debug("split chunks", zap.String("query", query))
 
@@ -853,6 +879,18 @@ func (d *Dumper) concurrentDumpTiDBTables(tctx *tcontext.Context, conn *BaseConn
 	return d.sendConcurrentDumpTiDBTasks(tctx, meta, taskChan, handleColNames, handleVals, "", 0, len(handleVals)+1)
 }
 
+// concurrentDumpTiDBTablesByStats splits the table by the histogram of the int handle column,
+// it's used when the region boundaries can't split the table, e.g. the whole table is in one region.
+func (d *Dumper) concurrentDumpTiDBTablesByStats(tctx *tcontext.Context, conn *BaseConn, meta TableMeta, taskChan chan<- Task) error {
+	tctx.L().Debug("dumping TiDB tables with STATS_BUCKETS",
+		zap.String("database", meta.DatabaseName()), zap.String("table", meta.TableName()))
+	handleColNames, handleVals, err := selectTiDBTableStatsSplitPoints(tctx, conn, meta, d.conf.Rows)
+	if err != nil {
+		return err
+	}
+	return d.sendConcurrentDumpTiDBTasks(tctx, meta, taskChan, handleColNames, handleVals, "", 0, len(handleVals)+1)
+}
+
 func (d *Dumper) concurrentDumpTiDBPartitionTables(tctx *tcontext.Context, conn *BaseConn, meta TableMeta, taskChan chan<- Task, partitions []string) error {
 	db, tbl := meta.DatabaseName(), meta.TableName()
 	tctx.L().Debug("dumping TiDB tables with TABLE REGIONS for partition table",
@@ -897,12 +935,12 @@ func (d *Dumper) sendConcurrentDumpTiDBTasks(tctx *tcontext.Context,
 		return d.dumpWholeTableDirectly(tctx, meta, taskChan, partition, buildOrderByClauseString(handleColNames), startChunkIdx, totalChunk)
 	}
 	conf := d.conf
-	selectField, selectLen := meta.SelectedField(), meta.SelectedLen()
+	selectField, selectLen := selectFieldWithHint(meta), meta.SelectedLen()
 	where := buildWhereClauses(handleColNames, handleVals)
 	orderByClause := buildOrderByClauseString(handleColNames)
 
 	for i, w := range where {
-		query := buildSelectQuery(db, tbl, selectField, partition, buildWhereCondition(conf, w), orderByClause)
+		query := buildSelectQuery(db, tbl, selectField, partition, buildWhereCondition(conf, db, tbl, w), orderByClause)
 		task := NewTaskTableData(meta, newTableData(query, selectLen, false), i+startChunkIdx, totalChunk)
 		ctxDone := d.sendTaskToChan(tctx, task, taskChan)
 		if ctxDone {
@@ -1076,6 +1114,43 @@ func selectTiDBPartitionRegion(tctx *tcontext.Context, conn *BaseConn, dbName, t
 	return pkVals, nil
 }
 
+// selectTiDBTableStatsSplitPoints picks the upper bounds of the histogram buckets of the int handle column
+// as the split points, so that every chunk contains about `rows` rows.
+func selectTiDBTableStatsSplitPoints(tctx *tcontext.Context, conn *BaseConn, meta TableMeta, rows uint64) (pkFields []string, pkVals [][]string, err error) {
+	dbName, tableName := meta.DatabaseName(), meta.TableName()
+	if meta.HasImplicitRowID() {
+		return nil, nil, errors.Errorf("table `%s`.`%s` has no statistics on the implicit row id", escapeString(dbName), escapeString(tableName))
+	}
+	pkFields, _, err = selectTiDBRowKeyFields(tctx, conn, meta, checkTiDBTableRegionPkFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	const statsBucketsSQL = "SHOW STATS_BUCKETS WHERE Db_name = ? AND Table_name = ? AND Partition_name = '' AND Column_name = ? AND Is_index = 0"
+	buckets, err := conn.QuerySQLWithColumns(tctx, []string{"COUNT", "UPPER_BOUND"}, statsBucketsSQL, dbName, tableName, pkFields[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	// the count of the bucket is the accumulated count, the last bucket is skipped
+	// because its upper bound is the max value of the column.
+	var lastCount uint64
+	for i := 0; i < len(buckets)-1; i++ {
+		count, err := strconv.ParseUint(buckets[i][0], 10, 64)
+		if err != nil {
+			return nil, nil, errors.Annotatef(err, "invalid count %s of bucket %d", buckets[i][0], i)
+		}
+		if count-lastCount < rows {
+			continue
+		}
+		pkVals = append(pkVals, []string{buckets[i][1]})
+		lastCount = count
+	}
+	if len(pkVals) == 0 {
+		return nil, nil, errors.Annotatef(errEmptyHandleVals, "table: `%s`.`%s`", escapeString(dbName), escapeString(tableName))
+	}
+	return pkFields, pkVals, nil
+}
+
 func extractTiDBRowIDFromDecodedKey(indexField, key string) (string, error) {
 	if p := strings.Index(key, indexField); p != -1 {
 		p += len(indexField)
@@ -1143,12 +1218,22 @@ func dumpTableMeta(tctx *tcontext.Context, conf *Config, conn *BaseConn, db stri
 	var (
 		colTypes         []*sql.ColumnType
 		hasImplicitRowID bool
+		readFromTiFlash  bool
 	)
 	if conf.ServerInfo.ServerType == version.ServerTypeTiDB {
 		hasImplicitRowID, err = SelectTiDBRowID(tctx, conn, db, tbl)
 		if err != nil {
 			tctx.L().Info("check implicit rowID failed", zap.String("database", db), zap.String("table", tbl), log.ShortError(err))
 		}
+		if conf.ReadFromTiFlash && table.Type == TableTypeBase {
+			var checkErr error
+			readFromTiFlash, checkErr = CheckTiFlashReplica(tctx, conn, db, tbl)
+			if checkErr != nil {
+				tctx.L().Info("check tiflash replica failed, will read from tikv", zap.String("database", db), zap.String("table", tbl), log.ShortError(checkErr))
+			} else if !readFromTiFlash {
+				tctx.L().Info("no available tiflash replica, will read from tikv", zap.String("database", db), zap.String("table", tbl))
+			}
+		}
 	}
 
 	// If all columns are generated
@@ -1171,6 +1256,7 @@ func dumpTableMeta(tctx *tcontext.Context, conf *Config, conn *BaseConn, db stri
 		selectedField:    selectField,
 		selectedLen:      selectLen,
 		hasImplicitRowID: hasImplicitRowID,
+		readFromTiFlash:  readFromTiFlash,
 		specCmts: []string{
 			"/*!40101 SET NAMES binary*/;",
 		},
@@ -1369,6 +1455,9 @@ func validateResolveAutoConsistency(d *Dumper) error {
 	if conf.Consistency != ConsistencyTypeSnapshot && conf.Snapshot != "" {
 		return errors.Errorf("can't specify --snapshot when --consistency isn't snapshot, resolved consistency: %s", conf.Consistency)
 	}
+	if conf.Consistency != ConsistencyTypeSnapshot && conf.ReadFromTiFlash {
+		return errors.Errorf("can't specify --read-from-tiflash when --consistency isn't snapshot, resolved consistency: %s", conf.Consistency)
+	}
 	return nil
 }
 
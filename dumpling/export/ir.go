@@ -27,6 +27,7 @@ type TableMeta interface {
 	ColumnCount() uint
 	ColumnTypes() []string
 	ColumnNames() []string
+	ColumnDecimalSize(i int) (precision, scale int64, ok bool)
 	SelectedField() string
 	SelectedLen() int
 	SpecialComments() StringIter
@@ -34,6 +35,7 @@ type TableMeta interface {
 	ShowCreateView() string
 	AvgRowLength() uint64
 	HasImplicitRowID() bool
+	ReadFromTiFlash() bool
 }
 
 // SQLRowIter is the iterator on a collection of sql.Row.
@@ -256,6 +256,7 @@ type tableMeta struct {
 	showCreateView   string
 	avgRowLength     uint64
 	hasImplicitRowID bool
+	readFromTiFlash  bool
 }
 
 func (tm *tableMeta) ColumnTypes() []string {
@@ -274,6 +275,10 @@ func (tm *tableMeta) ColumnNames() []string {
 	return colNames
 }
 
+func (tm *tableMeta) ColumnDecimalSize(i int) (precision, scale int64, ok bool) {
+	return tm.colTypes[i].DecimalSize()
+}
+
 func (tm *tableMeta) DatabaseName() string {
 	return tm.database
 }
@@ -314,6 +319,10 @@ func (tm *tableMeta) HasImplicitRowID() bool {
 	return tm.hasImplicitRowID
 }
 
+func (tm *tableMeta) ReadFromTiFlash() bool {
+	return tm.readFromTiFlash
+}
+
 type metaData struct {
 	target   string
 	metaSQL  string
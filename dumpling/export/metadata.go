@@ -52,6 +52,10 @@ func (m *globalMetadata) recordStartTime(t time.Time) {
 	m.buffer.WriteString("Started dump at: " + t.Format(metadataTimeLayout) + "\n")
 }
 
+func (m *globalMetadata) recordRowOrder(sortByPk bool) {
+	fmt.Fprintf(&m.buffer, "Order by primary key: %t\n", sortByPk)
+}
+
 func (m *globalMetadata) recordFinishTime(t time.Time) {
 	m.buffer.Write(m.afterConnBuffer.Bytes())
 	m.buffer.WriteString("Finished dump at: " + t.Format(metadataTimeLayout) + "\n")
@@ -313,3 +313,9 @@ func createStorage(t *testing.T) storage.ExternalStorage {
 	testLoc, _ := storage.Create(context.Background(), backend, true)
 	return testLoc
 }
+
+func TestRecordRowOrder(t *testing.T) {
+	m := newGlobalMetadata(tcontext.Background(), createStorage(t), "")
+	m.recordRowOrder(false)
+	require.Equal(t, "Order by primary key: false\n", m.buffer.String())
+}
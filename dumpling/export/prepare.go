@@ -59,6 +59,9 @@ const (
 
 	// DefaultAnonymousOutputFileTemplateText is the default anonymous output file templateText for dumpling's table data file name
 	DefaultAnonymousOutputFileTemplateText = "result.{{.Index}}"
+
+	outputLayoutSchema = "{schema}"
+	outputLayoutTable  = "{table}"
 )
 
 var (
@@ -67,15 +70,56 @@ var (
 	DefaultOutputFileTemplate = template.Must(template.New("data").
 					Option("missingkey=error").
 					Funcs(template.FuncMap{
-			"fn": func(input string) string {
-				return filenameEscapeRegexp.ReplaceAllStringFunc(input, func(match string) string {
-					return fmt.Sprintf("%%%02X%s", match[0], match[1:])
-				})
-			},
+			"fn": escapeFileName,
 		}).
 		Parse(defaultOutputFileTemplateBase))
 )
 
+func escapeFileName(input string) string {
+	return filenameEscapeRegexp.ReplaceAllStringFunc(input, func(match string) string {
+		return fmt.Sprintf("%%%02X%s", match[0], match[1:])
+	})
+}
+
+// ParseOutputLayout checks the output layout, which is the directory of the files of each
+// table relative to the output directory, e.g. "{schema}/{table}/". Only "{schema}" and
+// "{table}" are allowed as the placeholders. The returned layout always ends with '/' unless
+// it's empty.
+func ParseOutputLayout(layout string) (string, error) {
+	if len(layout) == 0 {
+		return "", nil
+	}
+	if strings.HasPrefix(layout, "/") {
+		return "", errors.Errorf("output layout '%s' must be relative to the output directory", layout)
+	}
+	if !strings.HasSuffix(layout, "/") {
+		layout += "/"
+	}
+	for _, dir := range strings.Split(strings.TrimSuffix(layout, "/"), "/") {
+		rest := strings.ReplaceAll(strings.ReplaceAll(dir, outputLayoutSchema, ""), outputLayoutTable, "")
+		if len(dir) == 0 || dir == "." || dir == ".." || strings.ContainsAny(rest, "{}") {
+			return "", errors.Errorf("invalid directory '%s' in output layout '%s', only {schema} and {table} are supported as placeholders", dir, layout)
+		}
+	}
+	return layout, nil
+}
+
+// renderOutputLayout returns the directory of the files of the table in the output layout.
+// If table is empty, it returns the deepest directory not depending on the table, where the
+// files of the database itself are put.
+func renderOutputLayout(layout, db, table string) string {
+	if len(db) == 0 && len(table) == 0 {
+		// the result of --sql is not in any table.
+		return ""
+	}
+	if len(table) == 0 {
+		if idx := strings.Index(layout, outputLayoutTable); idx >= 0 {
+			layout = layout[:strings.LastIndexByte(layout[:idx], '/')+1]
+		}
+	}
+	return strings.NewReplacer(outputLayoutSchema, escapeFileName(db), outputLayoutTable, escapeFileName(table)).Replace(layout)
+}
+
 // ParseOutputFileTemplate parses template from the specified text
 func ParseOutputFileTemplate(text string) (*template.Template, error) {
 	return template.Must(DefaultOutputFileTemplate.Clone()).Parse(text)
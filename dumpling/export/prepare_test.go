@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pingcap/tidb/br/pkg/storage"
 	tcontext "github.com/pingcap/tidb/dumpling/context"
 	"github.com/stretchr/testify/require"
 )
@@ -293,10 +294,48 @@ func TestConfigValidation(t *testing.T) {
 	require.NoError(t, adjustFileFormat(conf))
 	require.Equal(t, FileFormatSQLTextString, conf.FileType)
 
+	conf.FileType = FileFormatParquetString
+	require.NoError(t, adjustFileFormat(conf))
+
+	conf.CompressType = storage.Gzip
+	err = adjustFileFormat(conf)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "please use --parquet-compress instead")
+	conf.CompressType = storage.NoCompression
+
 	conf.FileType = "rand_str"
 	require.EqualError(t, adjustFileFormat(conf), "unknown config.FileType 'rand_str'")
 }
 
+func TestOutputLayout(t *testing.T) {
+	for layout, expected := range map[string]string{
+		"":                       "",
+		"{schema}/{table}":       "{schema}/{table}/",
+		"{schema}/{table}/":      "{schema}/{table}/",
+		"dump/{schema}.{table}/": "dump/{schema}.{table}/",
+	} {
+		res, err := ParseOutputLayout(layout)
+		require.NoError(t, err, layout)
+		require.Equal(t, expected, res, layout)
+	}
+	for _, layout := range []string{"/{schema}", "{schema}//{table}", "../{schema}", "{db}/{table}", "{schema}/{table"} {
+		_, err := ParseOutputLayout(layout)
+		require.Error(t, err, layout)
+	}
+
+	conf := defaultConfigForTest(t)
+	conf.OutputLayout = "{schema}/{table}"
+	require.NoError(t, adjustOutputLayout(conf))
+	require.Equal(t, "{schema}/{table}/", conf.OutputLayout)
+
+	require.Equal(t, "db/t/", renderOutputLayout(conf.OutputLayout, "db", "t"))
+	require.Equal(t, "db/", renderOutputLayout(conf.OutputLayout, "db", ""))
+	require.Equal(t, "", renderOutputLayout(conf.OutputLayout, "", ""))
+	require.Equal(t, "a%2Fb/%2E%2E/", renderOutputLayout(conf.OutputLayout, "a/b", ".."))
+	require.Equal(t, "dump/", renderOutputLayout("dump/{schema}.{table}/", "db", ""))
+	require.Equal(t, "", renderOutputLayout("", "db", "t"))
+}
+
 func TestValidateResolveAutoConsistency(t *testing.T) {
 	conf1 := defaultConfigForTest(t)
 	d := &Dumper{conf: conf1}
@@ -327,4 +366,11 @@ func TestValidateResolveAutoConsistency(t *testing.T) {
 			require.EqualError(t, validateResolveAutoConsistency(d), fmt.Sprintf("can't specify --snapshot when --consistency isn't snapshot, resolved consistency: %s", conf.Consistency))
 		}
 	}
+
+	conf.Snapshot = ""
+	conf.ReadFromTiFlash = true
+	conf.Consistency = ConsistencyTypeSnapshot
+	require.NoError(t, validateResolveAutoConsistency(d))
+	conf.Consistency = ConsistencyTypeFlush
+	require.EqualError(t, validateResolveAutoConsistency(d), "can't specify --read-from-tiflash when --consistency isn't snapshot, resolved consistency: flush")
 }
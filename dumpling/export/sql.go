@@ -394,8 +394,8 @@ func SelectVersion(db *sql.DB) (string, error) {
 // SelectAllFromTable dumps data serialized from a specified table
 func SelectAllFromTable(conf *Config, meta TableMeta, partition, orderByClause string) TableDataIR {
 	database, table := meta.DatabaseName(), meta.TableName()
-	selectedField, selectLen := meta.SelectedField(), meta.SelectedLen()
-	query := buildSelectQuery(database, table, selectedField, partition, buildWhereCondition(conf, ""), orderByClause)
+	selectedField, selectLen := selectFieldWithHint(meta), meta.SelectedLen()
+	query := buildSelectQuery(database, table, selectedField, partition, buildWhereCondition(conf, database, table, ""), orderByClause)
 
 	return &tableData{
 		query:  query,
@@ -436,6 +436,20 @@ func buildSelectQuery(database, table, fields, partition, where, orderByClause s
 	return query.String()
 }
 
+// selectFieldWithHint returns the selected fields of the table, which are prefixed with the
+// optimizer hint to read the table from the TiFlash replicas if it's required.
+func selectFieldWithHint(meta TableMeta) string {
+	selectField := meta.SelectedField()
+	if !meta.ReadFromTiFlash() {
+		return selectField
+	}
+	if selectField == "" {
+		selectField = "''"
+	}
+	return fmt.Sprintf("/*+ READ_FROM_STORAGE(TIFLASH[`%s`.`%s`]) */ %s",
+		escapeString(meta.DatabaseName()), escapeString(meta.TableName()), selectField)
+}
+
 func buildOrderByClause(tctx *tcontext.Context, conf *Config, db *BaseConn, database, table string, hasImplicitRowID bool) (string, error) { // revive:disable-line:flag-parameter
 	if !conf.SortByPk {
 		return "", nil
@@ -469,6 +483,22 @@ func SelectTiDBRowID(tctx *tcontext.Context, db *BaseConn, database, table strin
 	return hasImplictRowID, err
 }
 
+// CheckTiFlashReplica checks whether the table has available TiFlash replicas
+func CheckTiFlashReplica(tctx *tcontext.Context, db *BaseConn, database, table string) (bool, error) {
+	const query = "SELECT COUNT(1) FROM INFORMATION_SCHEMA.TIFLASH_REPLICA WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND AVAILABLE = 1"
+	var count int
+	handleOneRow := func(rows *sql.Rows) error {
+		return rows.Scan(&count)
+	}
+	err := db.QuerySQL(tctx, handleOneRow, func() {
+		count = 0
+	}, query, database, table)
+	if err != nil {
+		return false, errors.Annotatef(err, "sql: %s", query)
+	}
+	return count > 0, nil
+}
+
 // GetSuitableRows gets suitable rows for each table
 func GetSuitableRows(avgRowLength uint64) uint64 {
 	const (
@@ -735,6 +765,33 @@ func GetSpecifiedColumnValuesAndClose(rows *sql.Rows, columnName ...string) ([][
 	return strs, errors.Trace(rows.Err())
 }
 
+// getTiDBCPUUsage gets the CPU usage of the busiest TiDB server
+func getTiDBCPUUsage(tctx *tcontext.Context, db *sql.DB) (float64, error) {
+	const query = "SELECT VALUE FROM INFORMATION_SCHEMA.CLUSTER_LOAD WHERE TYPE = 'tidb' AND DEVICE_TYPE = 'cpu' AND DEVICE_NAME = 'usage' AND NAME = 'idle'"
+	rows, err := db.QueryContext(tctx, query)
+	if err != nil {
+		return 0, errors.Annotatef(err, "sql: %s", query)
+	}
+	idles, err := GetSpecifiedColumnValueAndClose(rows, "VALUE")
+	if err != nil {
+		return 0, errors.Annotatef(err, "sql: %s", query)
+	}
+	if len(idles) == 0 {
+		return 0, errors.Errorf("no CPU usage of TiDB servers found, sql: %s", query)
+	}
+	minIdle := 1.0
+	for _, s := range idles {
+		idle, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, errors.Annotatef(err, "invalid CPU idle %s, sql: %s", s, query)
+		}
+		if idle < minIdle {
+			minIdle = idle
+		}
+	}
+	return 1 - minIdle, nil
+}
+
 // GetPdAddrs gets PD address from TiDB
 func GetPdAddrs(tctx *tcontext.Context, db *sql.DB) ([]string, error) {
 	const query = "SELECT * FROM information_schema.cluster_info where type = 'pd';"
@@ -1176,9 +1233,9 @@ func estimateCount(tctx *tcontext.Context, dbName, tableName string, db *BaseCon
 		query = fmt.Sprintf("EXPLAIN SELECT `%s` FROM `%s`.`%s`", escapeString(field), escapeString(dbName), escapeString(tableName))
 	}
 
-	if conf.Where != "" {
+	if where := conf.getWhere(dbName, tableName); where != "" {
 		query += " WHERE "
-		query += conf.Where
+		query += where
 	}
 
 	estRows := detectEstimateRows(tctx, db, query, []string{"rows", "estRows", "count"})
@@ -1275,19 +1332,20 @@ func parseSnapshotToTSO(pool *sql.DB, snapshot string) (uint64, error) {
 	return (uint64(tso.Int64) << 18) * 1000, nil
 }
 
-func buildWhereCondition(conf *Config, where string) string {
+func buildWhereCondition(conf *Config, db, tbl, where string) string {
 	var query strings.Builder
 	separator := "WHERE"
 	leftBracket := " "
 	rightBracket := " "
-	if conf.Where != "" && where != "" {
+	tableWhere := conf.getWhere(db, tbl)
+	if tableWhere != "" && where != "" {
 		leftBracket = " ("
 		rightBracket = ") "
 	}
-	if conf.Where != "" {
+	if tableWhere != "" {
 		query.WriteString(separator)
 		query.WriteString(leftBracket)
-		query.WriteString(conf.Where)
+		query.WriteString(tableWhere)
 		query.WriteString(rightBracket)
 		separator = "AND"
 	}
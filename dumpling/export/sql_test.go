@@ -522,6 +522,41 @@ func TestSelectTiDBRowID(t *testing.T) {
 	require.False(t, hasImplicitRowID)
 }
 
+func TestCheckTiFlashReplica(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	tctx := tcontext.Background().WithLogger(appLogger)
+	baseConn := newBaseConn(conn, true, nil)
+
+	query := "SELECT COUNT\\(1\\) FROM INFORMATION_SCHEMA.TIFLASH_REPLICA WHERE TABLE_SCHEMA = \\? AND TABLE_NAME = \\? AND AVAILABLE = 1"
+	mock.ExpectQuery(query).WithArgs("test", "t").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(1)"}).AddRow(1))
+	hasReplica, err := CheckTiFlashReplica(tctx, baseConn, "test", "t")
+	require.NoError(t, err)
+	require.True(t, hasReplica)
+
+	mock.ExpectQuery(query).WithArgs("test", "t").
+		WillReturnRows(sqlmock.NewRows([]string{"COUNT(1)"}).AddRow(0))
+	hasReplica, err = CheckTiFlashReplica(tctx, baseConn, "test", "t")
+	require.NoError(t, err)
+	require.False(t, hasReplica)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	meta := &tableMeta{database: "test", table: "t`1", selectedField: "`a`,`b`"}
+	require.Equal(t, "`a`,`b`", selectFieldWithHint(meta))
+	meta.readFromTiFlash = true
+	require.Equal(t, "/*+ READ_FROM_STORAGE(TIFLASH[`test`.`t``1`]) */ `a`,`b`", selectFieldWithHint(meta))
+	meta.selectedField = ""
+	require.Equal(t, "SELECT /*+ READ_FROM_STORAGE(TIFLASH[`test`.`t``1`]) */ '' FROM `test`.`t``1`",
+		buildSelectQuery("test", "t`1", selectFieldWithHint(meta), "", "", ""))
+}
+
 func TestBuildTableSampleQueries(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -797,6 +832,7 @@ func TestBuildTableSampleQueries(t *testing.T) {
 			// special case, no enough value to split chunks
 			if len(handleVals) == 0 {
 				if !testCase.hasTiDBRowID {
+					expectStatsBuckets(mock, handleColNames, handleColTypes)
 					rows = sqlmock.NewRows(showIndexHeaders)
 					for i, handleColName := range handleColNames {
 						rows.AddRow(table, 0, "PRIMARY", i, handleColName, "A", 0, nil, nil, "", "BTREE", "", "")
@@ -834,7 +870,7 @@ func TestBuildTableSampleQueries(t *testing.T) {
 			}
 
 			for i, w := range testCase.expectedWhereClauses {
-				query := buildSelectQuery(database, table, selectFields, "", buildWhereCondition(d.conf, w), orderByClause)
+				query := buildSelectQuery(database, table, selectFields, "", buildWhereCondition(d.conf, database, table, w), orderByClause)
 				checkQuery(i, query)
 			}
 		}
@@ -929,9 +965,15 @@ func TestBuildWhereCondition(t *testing.T) {
 	}
 	for _, testCase := range testCases {
 		conf.Where = testCase.confWhere
-		where := buildWhereCondition(conf, testCase.chunkWhere)
+		where := buildWhereCondition(conf, "test", "t", testCase.chunkWhere)
 		require.Equal(t, testCase.expectedWhere, where)
 	}
+
+	// The WHERE condition from --where-config takes the place of --where.
+	conf.Where = "a >= 1000000"
+	conf.TableWheres, _ = ParseTableWheres([]byte(`{"rules": [{"tables": ["test.t"], "where": "b = 1"}]}`), true, false)
+	require.Equal(t, "WHERE (b = 1) AND (`a`>1) ", buildWhereCondition(conf, "test", "t", "`a`>1"))
+	require.Equal(t, "WHERE a >= 1000000 ", buildWhereCondition(conf, "test", "t2", ""))
 }
 
 func TestBuildRegionQueriesWithoutPartition(t *testing.T) {
@@ -1072,6 +1114,7 @@ func TestBuildRegionQueriesWithoutPartition(t *testing.T) {
 		orderByClause := buildOrderByClauseString(handleColNames)
 		// special case, no enough value to split chunks
 		if !testCase.hasTiDBRowID && len(regionResults) <= 1 {
+			expectStatsBuckets(mock, handleColNames, handleColTypes)
 			rows = sqlmock.NewRows(showIndexHeaders)
 			for i, handleColName := range handleColNames {
 				rows.AddRow(table, 0, "PRIMARY", i, handleColName, "A", 0, nil, nil, "", "BTREE", "", "")
@@ -1083,7 +1126,7 @@ func TestBuildRegionQueriesWithoutPartition(t *testing.T) {
 		require.NoError(t, mock.ExpectationsWereMet())
 
 		for i, w := range testCase.expectedWhereClauses {
-			query := buildSelectQuery(database, table, "*", "", buildWhereCondition(d.conf, w), orderByClause)
+			query := buildSelectQuery(database, table, "*", "", buildWhereCondition(d.conf, database, table, w), orderByClause)
 			task := <-taskChan
 			taskTableData, ok := task.(*TaskTableData)
 			require.True(t, ok)
@@ -1095,6 +1138,60 @@ func TestBuildRegionQueriesWithoutPartition(t *testing.T) {
 	}
 }
 
+// expectStatsBuckets expects the queries of splitting the table by the statistics,
+// the histogram only has one bucket so the table can't be split.
+func expectStatsBuckets(mock sqlmock.Sqlmock, handleColNames, handleColTypes []string) {
+	rows := sqlmock.NewRows(showIndexHeaders)
+	for i, handleColName := range handleColNames {
+		rows.AddRow(table, 0, "PRIMARY", i, handleColName, "A", 0, nil, nil, "", "BTREE", "", "")
+	}
+	mock.ExpectQuery(fmt.Sprintf("SHOW INDEX FROM `%s`.`%s`", database, table)).WillReturnRows(rows)
+	if checkTiDBTableRegionPkFields(handleColNames, handleColTypes) == nil {
+		mock.ExpectQuery("SHOW STATS_BUCKETS").WithArgs(database, table, handleColNames[0]).
+			WillReturnRows(sqlmock.NewRows([]string{"Count", "Upper_Bound"}).AddRow(100, 100))
+	}
+}
+
+func TestSelectTiDBTableStatsSplitPoints(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	baseConn := newBaseConn(conn, true, nil)
+	tctx := tcontext.Background().WithLogger(appLogger)
+
+	meta := &mockTableIR{
+		dbName:   database,
+		tblName:  table,
+		colNames: []string{"a"},
+		colTypes: []string{"BIGINT"},
+	}
+	mock.ExpectQuery(fmt.Sprintf("SHOW INDEX FROM `%s`.`%s`", database, table)).
+		WillReturnRows(sqlmock.NewRows(showIndexHeaders).AddRow(table, 0, "PRIMARY", 0, "a", "A", 0, nil, nil, "", "BTREE", "", ""))
+	rows := sqlmock.NewRows([]string{"Db_name", "Table_name", "Partition_name", "Column_name", "Is_index", "Bucket_id", "Count", "Repeats", "Lower_Bound", "Upper_Bound", "Ndv"}).
+		AddRow(database, table, "", "a", 0, 0, 60, 1, "1", "60", 0).
+		AddRow(database, table, "", "a", 0, 1, 120, 1, "61", "120", 0).
+		AddRow(database, table, "", "a", 0, 2, 200, 1, "121", "230", 0).
+		AddRow(database, table, "", "a", 0, 3, 240, 1, "231", "300", 0).
+		AddRow(database, table, "", "a", 0, 4, 300, 1, "301", "360", 0)
+	mock.ExpectQuery("SHOW STATS_BUCKETS").WithArgs(database, table, "a").WillReturnRows(rows)
+	pkFields, pkVals, err := selectTiDBTableStatsSplitPoints(tctx, baseConn, meta, 100)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, pkFields)
+	// the last bucket is never used as the split point
+	require.Equal(t, [][]string{{"120"}, {"300"}}, pkVals)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// the table with implicit row id has no statistics on the handle
+	meta.hasImplicitRowID = true
+	_, _, err = selectTiDBTableStatsSplitPoints(tctx, baseConn, meta, 100)
+	require.Error(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestBuildRegionQueriesWithPartitions(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -1285,7 +1382,7 @@ func TestBuildRegionQueriesWithPartitions(t *testing.T) {
 		chunkIdx := 0
 		for i, partition := range partitions {
 			for _, w := range testCase.expectedWhereClauses[i] {
-				query := buildSelectQuery(database, table, "*", partition, buildWhereCondition(d.conf, w), orderByClause)
+				query := buildSelectQuery(database, table, "*", partition, buildWhereCondition(d.conf, database, table, w), orderByClause)
 				task := <-taskChan
 				taskTableData, ok := task.(*TaskTableData)
 				require.True(t, ok)
@@ -1593,7 +1690,7 @@ func TestBuildVersion3RegionQueries(t *testing.T) {
 
 		chunkIdx := 0
 		for _, w := range testCase.expectedWhereClauses {
-			query := buildSelectQuery(database, table, "*", "", buildWhereCondition(d.conf, w), orderByClause)
+			query := buildSelectQuery(database, table, "*", "", buildWhereCondition(d.conf, database, table, w), orderByClause)
 			task := <-taskChan
 			taskTableData, ok := task.(*TaskTableData)
 			require.True(t, ok)
@@ -66,6 +66,7 @@ type mockTableIR struct {
 	specCmt          []string
 	colTypes         []string
 	colNames         []string
+	colDecimalSizes  [][2]int64
 	escapeBackSlash  bool
 	hasImplicitRowID bool
 	rowErr           error
@@ -93,6 +94,10 @@ func (m *mockTableIR) HasImplicitRowID() bool {
 	return m.hasImplicitRowID
 }
 
+func (m *mockTableIR) ReadFromTiFlash() bool {
+	return false
+}
+
 func (m *mockTableIR) Start(_ *tcontext.Context, conn *sql.Conn) error {
 	return nil
 }
@@ -121,6 +126,13 @@ func (m *mockTableIR) ColumnNames() []string {
 	return m.colNames
 }
 
+func (m *mockTableIR) ColumnDecimalSize(i int) (precision, scale int64, ok bool) {
+	if i >= len(m.colDecimalSizes) {
+		return 0, 0, false
+	}
+	return m.colDecimalSizes[i][0], m.colDecimalSizes[i][1], true
+}
+
 func (m *mockTableIR) SelectedField() string {
 	return m.selectedField
 }
@@ -28,6 +28,7 @@ type Writer struct {
 	metrics    *metrics
 
 	receivedTaskCount int
+	concurrencyCtrl   *concurrencyController
 
 	rebuildConnFn       func(*sql.Conn, bool) (*sql.Conn, error)
 	finishTaskCallBack  func(Task)
@@ -58,6 +59,8 @@ func NewWriter(
 		sw.fileFmt = FileFormatSQLText
 	case FileFormatCSVString:
 		sw.fileFmt = FileFormatCSV
+	case FileFormatParquetString:
+		sw.fileFmt = FileFormatParquet
 	}
 	return sw
 }
@@ -80,17 +83,25 @@ func countTotalTask(writers []*Writer) int {
 
 func (w *Writer) run(taskStream <-chan Task) error {
 	for {
+		if !w.concurrencyCtrl.acquire(w.tctx) {
+			w.tctx.L().Info("context has been done, the writer will exit",
+				zap.Int64("writer ID", w.id))
+			return nil
+		}
 		select {
 		case <-w.tctx.Done():
+			w.concurrencyCtrl.release()
 			w.tctx.L().Info("context has been done, the writer will exit",
 				zap.Int64("writer ID", w.id))
 			return nil
 		case task, ok := <-taskStream:
 			if !ok {
+				w.concurrencyCtrl.release()
 				return nil
 			}
 			w.receivedTaskCount++
 			err := w.handleTask(task)
+			w.concurrencyCtrl.release()
 			if err != nil {
 				return err
 			}
@@ -143,6 +154,7 @@ func (w *Writer) WriteDatabaseMeta(db, createSQL string) error {
 	if err != nil {
 		return err
 	}
+	fileName = renderOutputLayout(conf.OutputLayout, db, "") + fileName
 	return writeMetaToFile(tctx, db, createSQL, w.extStorage, fileName+".sql", conf.CompressType)
 }
 
@@ -153,6 +165,7 @@ func (w *Writer) WriteTableMeta(db, table, createSQL string) error {
 	if err != nil {
 		return err
 	}
+	fileName = renderOutputLayout(conf.OutputLayout, db, table) + fileName
 	return writeMetaToFile(tctx, db, createSQL, w.extStorage, fileName+".sql", conf.CompressType)
 }
 
@@ -167,6 +180,8 @@ func (w *Writer) WriteViewMeta(db, view, createTableSQL, createViewSQL string) e
 	if err != nil {
 		return err
 	}
+	dir := renderOutputLayout(conf.OutputLayout, db, view)
+	fileNameTable, fileNameView = dir+fileNameTable, dir+fileNameView
 	err = writeMetaToFile(tctx, db, createTableSQL, w.extStorage, fileNameTable+".sql", conf.CompressType)
 	if err != nil {
 		return err
@@ -181,6 +196,7 @@ func (w *Writer) WriteSequenceMeta(db, sequence, createSQL string) error {
 	if err != nil {
 		return err
 	}
+	fileName = renderOutputLayout(conf.OutputLayout, db, sequence) + fileName
 	return writeMetaToFile(tctx, db, createSQL, w.extStorage, fileName+".sql", conf.CompressType)
 }
 
@@ -227,10 +243,12 @@ func (w *Writer) WriteTableData(meta TableMeta, ir TableDataIR, currentChunk int
 func (w *Writer) tryToWriteTableData(tctx *tcontext.Context, meta TableMeta, ir TableDataIR, curChkIdx int) error {
 	conf, format := w.conf, w.fileFmt
 	namer := newOutputFileNamer(meta, curChkIdx, conf.Rows != UnspecifiedSize, conf.FileSize != UnspecifiedSize)
+	dir := renderOutputLayout(conf.OutputLayout, meta.DatabaseName(), meta.TableName())
 	fileName, err := namer.NextName(conf.OutputFileTemplate, w.fileFmt.Extension())
 	if err != nil {
 		return err
 	}
+	fileName = dir + fileName
 
 	somethingIsWritten := false
 	for {
@@ -259,6 +277,7 @@ func (w *Writer) tryToWriteTableData(tctx *tcontext.Context, meta TableMeta, ir
 		if err != nil {
 			return err
 		}
+		fileName = dir + fileName
 	}
 	if !somethingIsWritten {
 		tctx.L().Info("no data written in table chunk",
@@ -0,0 +1,409 @@
+// Copyright 2022 PingCAP, Inc. Licensed under Apache-2.0.
+
+package export
+
+import (
+	"bytes"
+	"database/sql"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/br/pkg/summary"
+	tcontext "github.com/pingcap/tidb/dumpling/context"
+	"github.com/pingcap/tidb/dumpling/log"
+	"github.com/xitongsys/parquet-go/marshal"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+	"go.uber.org/zap"
+)
+
+// parquetWriterConcurrency is the number of goroutines used to encode the pages of a row group
+const parquetWriterConcurrency = 2
+
+type parquetValueKind int
+
+const (
+	parquetValueString parquetValueKind = iota
+	parquetValueBytes
+	parquetValueInt64
+	parquetValueFloat
+	parquetValueDouble
+	parquetValueDecimal
+	parquetValueDate
+	parquetValueTimestamp
+)
+
+// parquetColumn describes how the values of a column are converted to parquet values.
+type parquetColumn struct {
+	name  string
+	kind  parquetValueKind
+	scale int
+}
+
+// buildParquetSchema builds the parquet schema of the selected columns. The mapping is:
+//
+//	integers           -> INT64
+//	FLOAT/DOUBLE       -> FLOAT/DOUBLE
+//	DECIMAL(p, s)      -> BYTE_ARRAY annotated as DECIMAL(p, s)
+//	DATE               -> INT32 annotated as DATE
+//	DATETIME/TIMESTAMP -> INT64 annotated as TIMESTAMP(MICROS), not adjusted to UTC
+//	JSON               -> BYTE_ARRAY annotated as JSON
+//	binary types       -> BYTE_ARRAY
+//	others             -> BYTE_ARRAY annotated as UTF8
+//
+// TIME is written as UTF8 because MySQL TIME is an interval that can be negative or exceed one day,
+// which can't be represented by the parquet TIME type.
+func buildParquetSchema(meta TableMeta) ([]*parquet.SchemaElement, []parquetColumn) {
+	colNames, colTypes := meta.ColumnNames(), meta.ColumnTypes()
+	root := parquet.NewSchemaElement()
+	root.Name = "schema"
+	numChildren := int32(len(colTypes))
+	root.NumChildren = &numChildren
+	root.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_REQUIRED)
+
+	elements := make([]*parquet.SchemaElement, 0, len(colTypes)+1)
+	elements = append(elements, root)
+	columns := make([]parquetColumn, 0, len(colTypes))
+	for i, colType := range colTypes {
+		el := parquet.NewSchemaElement()
+		el.Name = colNames[i]
+		el.RepetitionType = parquet.FieldRepetitionTypePtr(parquet.FieldRepetitionType_OPTIONAL)
+		col := parquetColumn{name: colNames[i]}
+
+		switch colType {
+		case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "YEAR",
+			"INT1", "INT2", "INT3", "INT8", "SQL_TSI_YEAR":
+			col.kind = parquetValueInt64
+			el.Type = parquet.TypePtr(parquet.Type_INT64)
+		case "FLOAT":
+			col.kind = parquetValueFloat
+			el.Type = parquet.TypePtr(parquet.Type_FLOAT)
+		case "DOUBLE", "REAL", "DOUBLE PRECISION":
+			col.kind = parquetValueDouble
+			el.Type = parquet.TypePtr(parquet.Type_DOUBLE)
+		case "DECIMAL", "NUMERIC", "FIXED":
+			precision, scale, ok := meta.ColumnDecimalSize(i)
+			if !ok || precision <= 0 || scale < 0 || precision > math.MaxInt32 {
+				col.kind = parquetValueString
+				el.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+				el.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
+				el.LogicalType = &parquet.LogicalType{STRING: parquet.NewStringType()}
+				break
+			}
+			col.kind = parquetValueDecimal
+			col.scale = int(scale)
+			p, s := int32(precision), int32(scale)
+			el.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+			el.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_DECIMAL)
+			el.Precision, el.Scale = &p, &s
+			el.LogicalType = &parquet.LogicalType{DECIMAL: &parquet.DecimalType{Precision: p, Scale: s}}
+		case "DATE":
+			col.kind = parquetValueDate
+			el.Type = parquet.TypePtr(parquet.Type_INT32)
+			el.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_DATE)
+			el.LogicalType = &parquet.LogicalType{DATE: parquet.NewDateType()}
+		case "DATETIME", "TIMESTAMP":
+			col.kind = parquetValueTimestamp
+			el.Type = parquet.TypePtr(parquet.Type_INT64)
+			el.LogicalType = &parquet.LogicalType{TIMESTAMP: &parquet.TimestampType{
+				IsAdjustedToUTC: false,
+				Unit:            &parquet.TimeUnit{MICROS: parquet.NewMicroSeconds()},
+			}}
+		case "JSON":
+			col.kind = parquetValueString
+			el.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+			el.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_JSON)
+			el.LogicalType = &parquet.LogicalType{JSON: parquet.NewJsonType()}
+		default:
+			if _, ok := dataTypeBin[colType]; ok {
+				col.kind = parquetValueBytes
+				el.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+				break
+			}
+			col.kind = parquetValueString
+			el.Type = parquet.TypePtr(parquet.Type_BYTE_ARRAY)
+			el.ConvertedType = parquet.ConvertedTypePtr(parquet.ConvertedType_UTF8)
+			el.LogicalType = &parquet.LogicalType{STRING: parquet.NewStringType()}
+		}
+		elements = append(elements, el)
+		columns = append(columns, col)
+	}
+	return elements, columns
+}
+
+// toParquetValue converts the raw value of a column returned by the database to the parquet value.
+func (c *parquetColumn) toParquetValue(raw sql.RawBytes) (interface{}, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	s := string(raw)
+	switch c.kind {
+	case parquetValueInt64:
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, errors.Annotatef(err, "column %s can't be written as parquet INT64", c.name)
+		}
+		return v, nil
+	case parquetValueFloat:
+		v, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return nil, errors.Annotatef(err, "column %s can't be written as parquet FLOAT", c.name)
+		}
+		return float32(v), nil
+	case parquetValueDouble:
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, errors.Annotatef(err, "column %s can't be written as parquet DOUBLE", c.name)
+		}
+		return v, nil
+	case parquetValueDecimal:
+		v, err := decimalToParquetBinary(s, c.scale)
+		if err != nil {
+			return nil, errors.Annotatef(err, "column %s can't be written as parquet DECIMAL", c.name)
+		}
+		return v, nil
+	case parquetValueDate:
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, errors.Annotatef(err, "column %s can't be written as parquet DATE", c.name)
+		}
+		return int32(t.Unix() / 86400), nil
+	case parquetValueTimestamp:
+		t, err := time.Parse("2006-01-02 15:04:05.999999999", s)
+		if err != nil {
+			return nil, errors.Annotatef(err, "column %s can't be written as parquet TIMESTAMP", c.name)
+		}
+		return t.UnixMicro(), nil
+	default:
+		return s, nil
+	}
+}
+
+// decimalToParquetBinary converts a decimal string to the big-endian two's complement
+// representation of its unscaled value, which is the BYTE_ARRAY encoding of parquet DECIMAL.
+func decimalToParquetBinary(s string, scale int) (string, error) {
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	intPart, fracPart := s, ""
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		intPart, fracPart = s[:dot], s[dot+1:]
+	}
+	if len(fracPart) > scale {
+		fracPart = fracPart[:scale]
+	} else {
+		fracPart += strings.Repeat("0", scale-len(fracPart))
+	}
+	unscaled, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return "", errors.Errorf("invalid decimal value '%s'", s)
+	}
+	if negative {
+		unscaled.Neg(unscaled)
+	}
+
+	if unscaled.Sign() >= 0 {
+		// keep a leading zero bit as the sign bit
+		b := make([]byte, unscaled.BitLen()/8+1)
+		unscaled.FillBytes(b)
+		return string(b), nil
+	}
+	// the magnitude of -v-1 decides the minimal length of a negative value v
+	length := new(big.Int).Not(unscaled).BitLen()/8 + 1
+	b := make([]byte, length)
+	// two's complement of a negative value is 2^(8*length) + value
+	complement := new(big.Int).Lsh(big.NewInt(1), uint(length*8))
+	complement.Add(complement, unscaled)
+	complement.FillBytes(b)
+	return string(b), nil
+}
+
+// parquetFileWriter adapts the writerPipe to source.ParquetFile. The parquet writer only calls Write on it.
+type parquetFileWriter struct {
+	tctx *tcontext.Context
+	wp   *writerPipe
+	bf   *bytes.Buffer
+}
+
+func (f *parquetFileWriter) Write(p []byte) (int, error) {
+	f.bf.Write(p)
+	if f.bf.Len() >= lengthLimit {
+		if err := f.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (f *parquetFileWriter) flush() error {
+	if f.bf.Len() == 0 {
+		return nil
+	}
+	select {
+	case <-f.tctx.Done():
+		return f.tctx.Err()
+	case err := <-f.wp.errCh:
+		return err
+	case f.wp.input <- f.bf:
+		f.bf = pool.Get().(*bytes.Buffer)
+		if bfCap := f.bf.Cap(); bfCap < lengthLimit {
+			f.bf.Grow(lengthLimit - bfCap)
+		}
+		return nil
+	}
+}
+
+func (*parquetFileWriter) Read([]byte) (int, error) {
+	return 0, errors.New("parquetFileWriter doesn't support read")
+}
+
+func (*parquetFileWriter) Seek(int64, int) (int64, error) {
+	return 0, errors.New("parquetFileWriter doesn't support seek")
+}
+
+func (*parquetFileWriter) Close() error {
+	return nil
+}
+
+func (*parquetFileWriter) Open(string) (source.ParquetFile, error) {
+	return nil, errors.New("parquetFileWriter doesn't support open")
+}
+
+func (*parquetFileWriter) Create(string) (source.ParquetFile, error) {
+	return nil, errors.New("parquetFileWriter doesn't support create")
+}
+
+// parquetRowReceiver receives the raw values of a row
+type parquetRowReceiver struct {
+	values []sql.RawBytes
+}
+
+// BindAddress implements RowReceiver.BindAddress
+func (r *parquetRowReceiver) BindAddress(args []interface{}) {
+	for i := range args {
+		args[i] = &r.values[i]
+	}
+}
+
+// WriteInsertInParquet writes TableDataIR to a storage.ExternalFileWriter in parquet type
+func WriteInsertInParquet(
+	pCtx *tcontext.Context,
+	cfg *Config,
+	meta TableMeta,
+	tblIR TableDataIR,
+	w storage.ExternalFileWriter,
+	metrics *metrics,
+) (n uint64, err error) {
+	fileRowIter := tblIR.Rows()
+	if !fileRowIter.HasNext() {
+		return 0, fileRowIter.Error()
+	}
+	if meta.SelectedField() == "" || len(meta.ColumnTypes()) == 0 {
+		return 0, errors.Errorf("can't dump table %s.%s in parquet format because no column is selected",
+			meta.DatabaseName(), meta.TableName())
+	}
+
+	wp := newWriterPipe(w, cfg.FileSize, UnspecifiedSize, metrics, cfg.Labels)
+
+	// use context.Background here to make sure writerPipe can deplete all the chunks in pipeline
+	ctx, cancel := tcontext.Background().WithLogger(pCtx.L()).WithCancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		wp.Run(ctx)
+		wg.Done()
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	var (
+		counter     uint64
+		lastCounter uint64
+		lastSize    uint64
+	)
+
+	defer func() {
+		if err != nil {
+			pCtx.L().Warn("fail to dumping table(chunk), will revert some metrics and start a retry if possible",
+				zap.String("database", meta.DatabaseName()),
+				zap.String("table", meta.TableName()),
+				zap.Uint64("finished rows", lastCounter),
+				zap.Uint64("finished size", wp.finishedFileSize),
+				log.ShortError(err))
+			SubGauge(metrics.finishedRowsGauge, float64(lastCounter))
+			SubGauge(metrics.finishedSizeGauge, float64(wp.finishedFileSize))
+		} else {
+			pCtx.L().Debug("finish dumping table(chunk)",
+				zap.String("database", meta.DatabaseName()),
+				zap.String("table", meta.TableName()),
+				zap.Uint64("finished rows", counter),
+				zap.Uint64("finished size", wp.finishedFileSize))
+			summary.CollectSuccessUnit(summary.TotalBytes, 1, wp.finishedFileSize)
+			summary.CollectSuccessUnit("total rows", 1, counter)
+		}
+	}()
+
+	schema, columns := buildParquetSchema(meta)
+	pf := &parquetFileWriter{tctx: pCtx, wp: wp, bf: pool.Get().(*bytes.Buffer)}
+	pw, err := writer.NewParquetWriter(pf, schema, parquetWriterConcurrency)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	pw.MarshalFunc = marshal.MarshalCSV
+	pw.RowGroupSize = int64(cfg.ParquetRowGroupSize)
+	pw.CompressionType = cfg.ParquetCompressType
+
+	row := &parquetRowReceiver{values: make([]sql.RawBytes, len(columns))}
+	for fileRowIter.HasNext() {
+		if err = fileRowIter.Decode(row); err != nil {
+			return counter, errors.Trace(err)
+		}
+		rec := make([]interface{}, len(columns))
+		for i := range columns {
+			if rec[i], err = columns[i].toParquetValue(row.values[i]); err != nil {
+				return counter, err
+			}
+			// parquet files are written by row groups, so we estimate the file size by the raw size of values
+			wp.currentFileSize += uint64(len(row.values[i]))
+		}
+		if err = pw.Write(rec); err != nil {
+			return counter, errors.Trace(err)
+		}
+		counter++
+		if wp.currentFileSize-lastSize >= lengthLimit {
+			AddGauge(metrics.finishedRowsGauge, float64(counter-lastCounter))
+			lastCounter = counter
+			lastSize = wp.currentFileSize
+		}
+
+		fileRowIter.Next()
+		if wp.ShouldSwitchFile() {
+			break
+		}
+	}
+	if err = fileRowIter.Error(); err != nil {
+		return counter, errors.Trace(err)
+	}
+
+	if err = pw.WriteStop(); err != nil {
+		return counter, errors.Trace(err)
+	}
+	if err = pf.flush(); err != nil {
+		return counter, errors.Trace(err)
+	}
+	close(wp.input)
+	<-wp.closed
+	AddGauge(metrics.finishedRowsGauge, float64(counter-lastCounter))
+	lastCounter = counter
+	return counter, wp.Error()
+}
+
+var _ source.ParquetFile = (*parquetFileWriter)(nil)
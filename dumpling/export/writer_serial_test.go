@@ -233,6 +233,8 @@ func TestSQLDataTypes(t *testing.T) {
 		{"CHAR", "char1", `'char1'`},
 		{"INT", 12345, `12345`},
 		{"BINARY", 1234, "x'31323334'"},
+		{"VARBINARY", []byte("a'\\\x00\xff"), "x'61275c00ff'"},
+		{"BLOB", []byte("\xe4\xb8\xad\n"), "x'e4b8ad0a'"},
 	}
 
 	for _, datum := range data {
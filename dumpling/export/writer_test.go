@@ -15,6 +15,9 @@ import (
 	tcontext "github.com/pingcap/tidb/dumpling/context"
 	"github.com/pingcap/tidb/util/promutil"
 	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
 )
 
 func TestWriteDatabaseMeta(t *testing.T) {
@@ -139,6 +142,122 @@ func TestWriteTableData(t *testing.T) {
 	require.Equal(t, expected, string(bytes))
 }
 
+func TestWriteTableDataInParquet(t *testing.T) {
+	dir := t.TempDir()
+	config := defaultConfigForTest(t)
+	config.OutputDirPath = dir
+	config.FileType = FileFormatParquetString
+
+	writer := createTestWriter(config, t)
+
+	data := [][]driver.Value{
+		{"1", "bob", "-12.34", "2022-01-02", "2022-01-02 03:04:05.123456", "-01:02:03", `{"a": 1}`, nil},
+		{"2", nil, "1234567890123456789.01", "1969-12-31", "1970-01-01 00:00:00", "838:59:59", nil, "\x00\x01"},
+	}
+	colTypes := []string{"BIGINT", "VARCHAR", "DECIMAL", "DATE", "DATETIME", "TIME", "JSON", "BLOB"}
+	tableIR := newMockTableIR("test", "t", data, nil, colTypes)
+	tableIR.colNames = []string{"id", "name", "amount", "d", "dt", "tm", "j", "b"}
+	tableIR.colDecimalSizes = [][2]int64{{0, 0}, {0, 0}, {21, 2}}
+	err := writer.WriteTableData(tableIR, tableIR, 0)
+	require.NoError(t, err)
+
+	fr, err := local.NewLocalFileReader(path.Join(dir, "test.t.000000000.parquet"))
+	require.NoError(t, err)
+	pr, err := reader.NewParquetReader(fr, nil, 1)
+	require.NoError(t, err)
+	defer func() {
+		pr.ReadStop()
+		require.NoError(t, fr.Close())
+	}()
+	require.Equal(t, int64(2), pr.GetNumRows())
+
+	schema := pr.Footer.Schema[1:]
+	require.Len(t, schema, len(colTypes))
+	for i, name := range tableIR.colNames {
+		require.Equal(t, name, pr.SchemaHandler.GetExName(i+1))
+	}
+	require.Equal(t, parquet.Type_INT64, schema[0].GetType())
+	require.Equal(t, parquet.ConvertedType_UTF8, schema[1].GetConvertedType())
+	require.Equal(t, parquet.ConvertedType_DECIMAL, schema[2].GetConvertedType())
+	require.Equal(t, int32(21), schema[2].GetPrecision())
+	require.Equal(t, int32(2), schema[2].GetScale())
+	require.Equal(t, parquet.ConvertedType_DATE, schema[3].GetConvertedType())
+	require.NotNil(t, schema[4].GetLogicalType().GetTIMESTAMP())
+	require.False(t, schema[4].GetLogicalType().GetTIMESTAMP().IsAdjustedToUTC)
+	require.Equal(t, parquet.ConvertedType_UTF8, schema[5].GetConvertedType())
+	require.Equal(t, parquet.ConvertedType_JSON, schema[6].GetConvertedType())
+	require.Equal(t, parquet.Type_BYTE_ARRAY, schema[7].GetType())
+	require.False(t, schema[7].IsSetConvertedType())
+
+	expected := [][]interface{}{
+		{int64(1), int64(2)},
+		{"bob", nil},
+		{string([]byte{0xfb, 0x2e}), string([]byte{0x06, 0xb1, 0x4e, 0x9f, 0x81, 0x2f, 0x36, 0x6c, 0x35})},
+		{int32(18994), int32(-1)},
+		{int64(1641092645123456), int64(0)},
+		{"-01:02:03", "838:59:59"},
+		{`{"a": 1}`, nil},
+		{nil, "\x00\x01"},
+	}
+	for i, values := range expected {
+		actual, _, _, err := pr.ReadColumnByIndex(int64(i), 2)
+		require.NoError(t, err)
+		require.Equal(t, values, actual, "column %d", i)
+	}
+}
+
+func TestDecimalToParquetBinary(t *testing.T) {
+	cases := []struct {
+		value    string
+		scale    int
+		expected []byte
+	}{
+		{"0", 0, []byte{0x00}},
+		{"127", 0, []byte{0x7f}},
+		{"128", 0, []byte{0x00, 0x80}},
+		{"-1", 0, []byte{0xff}},
+		{"-128", 0, []byte{0x80}},
+		{"-129", 0, []byte{0xff, 0x7f}},
+		{"1.5", 2, []byte{0x00, 0x96}},
+		{"-0.01", 2, []byte{0xff}},
+		{"12.345", 2, []byte{0x04, 0xd2}},
+	}
+	for _, c := range cases {
+		actual, err := decimalToParquetBinary(c.value, c.scale)
+		require.NoError(t, err)
+		require.Equal(t, string(c.expected), actual, c.value)
+	}
+	_, err := decimalToParquetBinary("abc", 0)
+	require.Error(t, err)
+}
+
+func TestWriteWithOutputLayout(t *testing.T) {
+	dir := t.TempDir()
+	config := defaultConfigForTest(t)
+	config.OutputDirPath = dir
+	config.OutputLayout = "{schema}/{table}/"
+
+	writer := createTestWriter(config, t)
+
+	require.NoError(t, writer.WriteDatabaseMeta("test", "CREATE DATABASE `test`"))
+	require.NoError(t, writer.WriteTableMeta("test", "employee", "CREATE TABLE employee (a INT)"))
+	require.NoError(t, writer.WriteViewMeta("test", "v", "CREATE TABLE v (a INT)", "CREATE VIEW v AS SELECT 1"))
+	data := [][]driver.Value{{"1"}, {"2"}}
+	tableIR := newMockTableIR("test", "employee", data, nil, []string{"INT"})
+	require.NoError(t, writer.WriteTableData(tableIR, tableIR, 0))
+
+	for _, p := range []string{
+		"test/test-schema-create.sql",
+		"test/employee/test.employee-schema.sql",
+		"test/employee/test.employee.000000000.sql",
+		"test/v/test.v-schema.sql",
+		"test/v/test.v-schema-view.sql",
+	} {
+		_, err := os.Stat(path.Join(dir, p))
+		require.NoError(t, err, p)
+	}
+}
+
 func TestWriteTableDataWithFileSize(t *testing.T) {
 	dir := t.TempDir()
 	config := defaultConfigForTest(t)
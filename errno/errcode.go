@@ -906,12 +906,23 @@ const (
 	ErrDefValGeneratedNamedFunctionIsNotAllowed              = 3770
 	ErrFKIncompatibleColumns                                 = 3780
 	ErrFunctionalIndexRowValueIsNotAllowed                   = 3800
+	ErrNonBooleanExprForCheckConstraint                      = 3812
+	ErrColumnCheckConstraintReferencesOtherColumn            = 3813
+	ErrCheckConstraintNamedFunctionIsNotAllowed              = 3814
+	ErrCheckConstraintFunctionIsNotAllowed                   = 3815
+	ErrCheckConstraintVariables                              = 3816
+	ErrCheckConstraintRefersAutoIncrementColumn              = 3818
+	ErrCheckConstraintViolated                               = 3819
+	ErrCheckConstraintRefersUnknownColumn                    = 3820
+	ErrCheckConstraintNotFound                               = 3821
+	ErrCheckConstraintDupName                                = 3822
 	ErrDependentByFunctionalIndex                            = 3837
 	ErrCannotConvertString                                   = 3854
 	ErrInvalidJSONValueForFuncIndex                          = 3903
 	ErrJSONValueOutOfRangeForFuncIndex                       = 3904
 	ErrFunctionalIndexDataIsTooLong                          = 3907
 	ErrFunctionalIndexNotApplicable                          = 3909
+	ErrDependentByCheckConstraint                            = 3959
 	ErrDynamicPrivilegeNotRegistered                         = 3929
 	ErrTableWithoutPrimaryKey                                = 3750
 	// MariaDB errors.
@@ -1029,6 +1040,7 @@ const (
 	ErrGettingNoopVariable                 = 8145
 	ErrCannotMigrateSession                = 8146
 	ErrLazyUniquenessCheckFailure          = 8147
+	ErrStmtTimeoutExceeded                 = 8148
 
 	// Error codes used by TiDB ddl package
 	ErrUnsupportedDDLOperation            = 8200
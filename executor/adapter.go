@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"runtime/trace"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -67,6 +68,7 @@ import (
 	"github.com/tikv/client-go/v2/util"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/exp/slices"
 )
 
 // metrics option
@@ -151,6 +153,7 @@ func (a *recordSet) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 
 	err = a.stmt.next(ctx, a.executor, req)
 	if err != nil {
+		err = StmtTimeoutError(a.stmt.Ctx, err)
 		a.lastErr = err
 		return err
 	}
@@ -481,6 +484,8 @@ func (a *ExecStmt) Exec(ctx context.Context) (_ sqlexec.RecordSet, err error) {
 				// Use SecureText to avoid leak password information.
 				sql = ss.SecureText()
 			}
+		} else if secureText, ok := ast.ExternalTableSecureText(a.StmtNode); ok {
+			sql = secureText
 		}
 		maxExecutionTime := getMaxExecutionTime(sctx)
 		// Update processinfo, ShowProcess() will use it.
@@ -603,6 +608,15 @@ func getMaxExecutionTime(sctx sessionctx.Context) uint64 {
 	return sctx.GetSessionVars().MaxExecutionTime
 }
 
+// StmtTimeoutError returns ErrStmtTimeoutExceeded in place of the error if the statement is
+// killed because of exceeding tidb_statement_timeout.
+func StmtTimeoutError(sctx sessionctx.Context, err error) error {
+	if err != nil && sctx.GetSessionVars().StmtCtx.StmtTimeoutExceeded() {
+		return ErrStmtTimeoutExceeded.GenWithStackByArgs()
+	}
+	return err
+}
+
 type chunkRowRecordSet struct {
 	rows     []chunk.Row
 	idx      int
@@ -1175,6 +1189,11 @@ func (a *ExecStmt) FinishExecuteStmt(txnTS uint64, err error, hasMoreResults boo
 		// but we set it again in case we missed some code paths.
 		sessVars.StmtCtx.SetPlan(a.Plan)
 	}
+	if succ && a.Plan != nil && sessVars.EnableCardinalityFeedback && !sessVars.InRestrictedSQL &&
+		!sessVars.FoundInPlanCache && sessVars.StmtCtx.RuntimeStatsColl != nil {
+		// The estimations of the cached plans may be corrected by the older factors.
+		collectCardinalityFeedback(a.Plan, sessVars.StmtCtx.RuntimeStatsColl)
+	}
 	// `LowSlowQuery` and `SummaryStmt` must be called before recording `PrevStmt`.
 	a.LogSlowQuery(txnTS, succ, hasMoreResults)
 	a.SummaryStmt(succ)
@@ -1370,10 +1389,40 @@ func (a *ExecStmt) LogSlowQuery(txnTS uint64, succ bool, hasMoreResults bool) {
 			TableIDs:   tableIDs,
 			IndexNames: indexNames,
 			Internal:   sessVars.InRestrictedSQL,
+			PlanDigest: planDigest.String(),
+			BindSQL:    stmtCtx.BindSQL,
+			StatsInfo:  formatStatsInfos(statsInfos),
 		})
 	}
 }
 
+// formatStatsInfos formats the versions of the statistics used by the plan like the
+// `Stats` field of the slow log, with the tables sorted by name.
+func formatStatsInfos(statsInfos map[string]uint64) string {
+	if len(statsInfos) == 0 {
+		return ""
+	}
+	tables := make([]string, 0, len(statsInfos))
+	for tbl := range statsInfos {
+		tables = append(tables, tbl)
+	}
+	slices.Sort(tables)
+	var buf strings.Builder
+	for i, tbl := range tables {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(tbl)
+		buf.WriteByte(':')
+		if v := statsInfos[tbl]; v == 0 {
+			buf.WriteString("pseudo")
+		} else {
+			buf.WriteString(strconv.FormatUint(v, 10))
+		}
+	}
+	return buf.String()
+}
+
 // GetResultRowsCount gets the count of the statement result rows.
 func GetResultRowsCount(stmtCtx *stmtctx.StatementContext, p plannercore.Plan) int64 {
 	runtimeStatsColl := stmtCtx.RuntimeStatsColl
@@ -1566,6 +1615,10 @@ func (a *ExecStmt) SummaryStmt(succ bool) {
 	}
 
 	resultRows := GetResultRowsCount(stmtCtx, a.Plan)
+	var tableScanKeys map[string]int64
+	if stmtCtx.StmtType == "Select" && execDetail.ScanDetail != nil {
+		tableScanKeys = getTableScanKeys(stmtCtx, a.Plan, execDetail.ScanDetail.TotalKeys)
+	}
 
 	stmtExecInfo := &stmtsummary.StmtExecInfo{
 		SchemaName:          strings.ToLower(sessVars.CurrentDB),
@@ -1599,6 +1652,7 @@ func (a *ExecStmt) SummaryStmt(succ bool) {
 		ResultRows:          resultRows,
 		TiKVExecDetails:     tikvExecDetail,
 		Prepared:            a.isPreparedStmt,
+		TableScanKeys:       tableScanKeys,
 	}
 	if a.retryCount > 0 {
 		stmtExecInfo.ExecRetryTime = costTime - sessVars.DurationParse - sessVars.DurationCompile - time.Since(a.retryStartTime)
@@ -1606,6 +1660,63 @@ func (a *ExecStmt) SummaryStmt(succ bool) {
 	stmtsummary.StmtSummaryByDigestMap.AddStatement(stmtExecInfo)
 }
 
+// getTableScanKeys returns the keys scanned from each table by the statement, keyed by "db.table" in lower case.
+// All the keys are scanned from the table if the statement reads only one table, otherwise they are taken from
+// the runtime stats of the readers, and are unknown if the runtime stats are not collected.
+func getTableScanKeys(stmtCtx *stmtctx.StatementContext, p plannercore.Plan, totalKeys int64) map[string]int64 {
+	if len(stmtCtx.Tables) == 1 {
+		tbl := stmtCtx.Tables[0]
+		return map[string]int64{strings.ToLower(tbl.DB) + "." + strings.ToLower(tbl.Table): totalKeys}
+	}
+	physicalPlan, ok := p.(plannercore.PhysicalPlan)
+	if !ok || stmtCtx.RuntimeStatsColl == nil {
+		return nil
+	}
+	keys := make(map[string]int64)
+	collectTableScanKeys(physicalPlan, stmtCtx.RuntimeStatsColl, keys)
+	return keys
+}
+
+func collectTableScanKeys(p plannercore.PhysicalPlan, coll *execdetails.RuntimeStatsColl, keys map[string]int64) {
+	switch x := p.(type) {
+	case *plannercore.PhysicalTableReader:
+		collectCopScanKeys(x.TablePlans, coll, keys)
+	case *plannercore.PhysicalIndexReader:
+		collectCopScanKeys(x.IndexPlans, coll, keys)
+	case *plannercore.PhysicalIndexLookUpReader:
+		collectCopScanKeys(x.IndexPlans, coll, keys)
+		collectCopScanKeys(x.TablePlans, coll, keys)
+	case *plannercore.PhysicalIndexMergeReader:
+		for _, partialPlans := range x.PartialPlans {
+			collectCopScanKeys(partialPlans, coll, keys)
+		}
+		collectCopScanKeys(x.TablePlans, coll, keys)
+	}
+	for _, child := range p.Children() {
+		collectTableScanKeys(child, coll, keys)
+	}
+}
+
+// collectCopScanKeys collects the keys scanned by the flattened pushed-down plans, whose first plan is the scan
+// and whose last plan is the root, where the scan details are recorded.
+func collectCopScanKeys(plans []plannercore.PhysicalPlan, coll *execdetails.RuntimeStatsColl, keys map[string]int64) {
+	if len(plans) == 0 {
+		return
+	}
+	var dbName, tblName string
+	switch scan := plans[0].(type) {
+	case *plannercore.PhysicalTableScan:
+		dbName, tblName = scan.DBName.L, scan.Table.Name.L
+	case *plannercore.PhysicalIndexScan:
+		dbName, tblName = scan.DBName.L, scan.Table.Name.L
+	default:
+		return
+	}
+	if copStats := coll.GetCopStats(plans[len(plans)-1].ID()); copStats != nil && copStats.GetScanDetail() != nil {
+		keys[dbName+"."+tblName] += copStats.GetScanDetail().TotalKeys
+	}
+}
+
 // GetTextToLog return the query text to log.
 func (a *ExecStmt) GetTextToLog() string {
 	var sql string
@@ -1614,6 +1725,8 @@ func (a *ExecStmt) GetTextToLog() string {
 		sql, _ = sessVars.StmtCtx.SQLDigest()
 	} else if sensitiveStmt, ok := a.StmtNode.(ast.SensitiveStmtNode); ok {
 		sql = sensitiveStmt.SecureText()
+	} else if secureText, ok := ast.ExternalTableSecureText(a.StmtNode); ok {
+		sql = secureText + sessVars.PreparedParams.String()
 	} else {
 		sql = sessVars.StmtCtx.OriginalSQL + sessVars.PreparedParams.String()
 	}
@@ -0,0 +1,141 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/statistics/handle"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+// statsFileExt is the extension of the stats files written by ADMIN EXPORT STATS. The stats
+// of each table are dumped as the JSON of `/stats/dump` in `<db>.<table>.json`.
+const statsFileExt = ".json"
+
+// statsFileName returns the name of the stats file of the table. The dots in the names are
+// escaped as well, so the files of different tables never collide.
+func statsFileName(db, tbl string) string {
+	escape := func(name string) string {
+		return strings.ReplaceAll(url.PathEscape(name), ".", "%2E")
+	}
+	return escape(db) + "." + escape(tbl) + statsFileExt
+}
+
+func openStatsStorage(ctx context.Context, rawURL string) (storage.ExternalStorage, error) {
+	backend, err := storage.ParseBackend(rawURL, nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	store, err := storage.New(ctx, backend, &storage.ExternalStorageOptions{})
+	return store, errors.Trace(err)
+}
+
+// executeAdminExportStats dumps the stats of all the user tables to the external storage,
+// so that they can be imported into another cluster restored from the same data.
+func (e *SimpleExec) executeAdminExportStats(ctx context.Context, s *ast.AdminStmt) error {
+	h := domain.GetDomain(e.ctx).StatsHandle()
+	if h == nil {
+		return errors.New("Export Stats: handle is nil")
+	}
+	store, err := openStatsStorage(ctx, s.Storage)
+	if err != nil {
+		return err
+	}
+	is := e.ctx.GetInfoSchema().(infoschema.InfoSchema)
+	tableCount := 0
+	for _, db := range is.AllSchemas() {
+		if util.IsMemOrSysDB(db.Name.L) {
+			continue
+		}
+		for _, tbl := range db.Tables {
+			if tbl.IsView() || tbl.IsSequence() || tbl.TempTableType != model.TempTableNone {
+				continue
+			}
+			jsonTbl, err := h.DumpStatsToJSON(db.Name.O, tbl, nil)
+			if err != nil {
+				return err
+			}
+			if jsonTbl == nil || (tbl.GetPartitionInfo() != nil && len(jsonTbl.Partitions) == 0) {
+				continue
+			}
+			data, err := json.Marshal(jsonTbl)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if err := store.WriteFile(ctx, statsFileName(db.Name.L, tbl.Name.L), data); err != nil {
+				return errors.Trace(err)
+			}
+			tableCount++
+		}
+	}
+	logutil.Logger(ctx).Info("export stats finished", zap.String("storage", store.URI()), zap.Int("tables", tableCount))
+	return nil
+}
+
+// executeAdminImportStats loads the stats files written by ADMIN EXPORT STATS. The tables are
+// matched by their names, and the tables which don't exist in this cluster are skipped with
+// warnings.
+func (e *SimpleExec) executeAdminImportStats(ctx context.Context, s *ast.AdminStmt) error {
+	h := domain.GetDomain(e.ctx).StatsHandle()
+	if h == nil {
+		return errors.New("Import Stats: handle is nil")
+	}
+	store, err := openStatsStorage(ctx, s.Storage)
+	if err != nil {
+		return err
+	}
+	is := e.ctx.GetInfoSchema().(infoschema.InfoSchema)
+	sc := e.ctx.GetSessionVars().StmtCtx
+	tableCount := 0
+	err = store.WalkDir(ctx, &storage.WalkOption{}, func(name string, _ int64) error {
+		if !strings.HasSuffix(name, statsFileExt) {
+			return nil
+		}
+		data, err := store.ReadFile(ctx, name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		jsonTbl := &handle.JSONTable{}
+		if err := json.Unmarshal(data, jsonTbl); err != nil {
+			return errors.Annotatef(err, "failed to parse the stats file '%s'", name)
+		}
+		if err := h.LoadStatsFromJSON(is, jsonTbl); err != nil {
+			if infoschema.ErrTableNotExists.Equal(err) {
+				sc.AppendWarning(err)
+				return nil
+			}
+			return errors.Annotatef(err, "failed to import the stats file '%s'", name)
+		}
+		tableCount++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logutil.Logger(ctx).Info("import stats finished", zap.String("storage", store.URI()), zap.Int("tables", tableCount))
+	return nil
+}
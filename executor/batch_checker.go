@@ -63,12 +63,21 @@ func getKeysNeedCheck(ctx context.Context, sctx sessionctx.Context, t table.Tabl
 		}
 	}
 	toBeCheckRows := make([]toBeCheckedRow, 0, len(rows))
+	tblHandleCols, pkIdxInfo := getHandleCols(t)
 
-	var (
-		tblHandleCols []*table.Column
-		pkIdxInfo     *model.IndexInfo
-	)
-	// Get handle column if PK is handle.
+	var err error
+	for _, row := range rows {
+		toBeCheckRows, err = getKeysNeedCheckOneRow(sctx, t, row, nUnique, tblHandleCols, pkIdxInfo, toBeCheckRows)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return toBeCheckRows, nil
+}
+
+// getHandleCols gets the columns of the handle, they're empty if the table uses
+// _tidb_rowid. The primary index is returned for the common handle.
+func getHandleCols(t table.Table) (tblHandleCols []*table.Column, pkIdxInfo *model.IndexInfo) {
 	if t.Meta().PKIsHandle {
 		for _, col := range t.Cols() {
 			if col.IsPKHandleColumn(t.Meta()) {
@@ -82,15 +91,7 @@ func getKeysNeedCheck(ctx context.Context, sctx sessionctx.Context, t table.Tabl
 			tblHandleCols = append(tblHandleCols, t.Cols()[idxCol.Offset])
 		}
 	}
-
-	var err error
-	for _, row := range rows {
-		toBeCheckRows, err = getKeysNeedCheckOneRow(sctx, t, row, nUnique, tblHandleCols, pkIdxInfo, toBeCheckRows)
-		if err != nil {
-			return nil, err
-		}
-	}
-	return toBeCheckRows, nil
+	return tblHandleCols, pkIdxInfo
 }
 
 func getKeysNeedCheckOneRow(ctx sessionctx.Context, t table.Table, row []types.Datum, nUnique int, handleCols []*table.Column,
@@ -141,7 +142,7 @@ func getKeysNeedCheckOneRow(ctx sessionctx.Context, t table.Table, row []types.D
 		}
 		handleKey = &keyValueWithDupInfo{
 			newKey: tablecodec.EncodeRecordKey(t.RecordPrefix(), handle),
-			dupErr: kv.ErrKeyExists.FastGenByArgs(stringutil.MemoizeStr(fn), "PRIMARY"),
+			dupErr: kv.ErrKeyExists.FastGenByArgs(stringutil.MemoizeStr(fn), tables.HandleKeyName(t.Meta())),
 		}
 	}
 
@@ -781,6 +781,7 @@ func (b *executorBuilder) buildShow(v *plannercore.PhysicalShow) Executor {
 		IfNotExists:           v.IfNotExists,
 		GlobalScope:           v.GlobalScope,
 		Extended:              v.Extended,
+		SnapshotTS:            v.SnapshotTS,
 		Extractor:             v.Extractor,
 	}
 	if e.Tp == ast.ShowMasterStatus {
@@ -872,6 +873,7 @@ func (b *executorBuilder) buildInsert(v *plannercore.Insert) Executor {
 	insert := &InsertExec{
 		InsertValues: ivs,
 		OnDuplicate:  append(v.OnDuplicate, v.GenCols.OnDuplicates...),
+		UseIngest:    v.UseIngest,
 	}
 	return insert
 }
@@ -1096,9 +1098,14 @@ func (b *executorBuilder) buildSelectInto(v *plannercore.SelectInto) Executor {
 	if b.err != nil {
 		return nil
 	}
+	names := make([]string, 0, len(v.TargetNames))
+	for _, name := range v.TargetNames {
+		names = append(names, name.ColName.O)
+	}
 	return &SelectIntoExec{
 		baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID(), child),
 		intoOpt:      v.IntoOpt,
+		names:        names,
 	}
 }
 
@@ -1626,6 +1633,36 @@ func (b *executorBuilder) getSnapshot() (kv.Snapshot, error) {
 }
 
 func (b *executorBuilder) buildMemTable(v *plannercore.PhysicalMemTable) Executor {
+	if extractor, ok := v.Extractor.(*plannercore.ExternalTableExtractor); ok {
+		conditions := make([]expression.Expression, 0, len(extractor.PushedConditions))
+		for _, cond := range extractor.PushedConditions {
+			cond, err := cond.Clone().ResolveIndices(v.Schema())
+			if err != nil {
+				b.err = err
+				return nil
+			}
+			conditions = append(conditions, cond)
+		}
+		return &MemTableReaderExec{
+			baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()),
+			table:        v.Table,
+			retriever: &externalTableRetriever{
+				extractor:  extractor,
+				columns:    v.Columns,
+				conditions: conditions,
+			},
+		}
+	}
+	if extractor, ok := v.Extractor.(*plannercore.FederatedTableExtractor); ok {
+		return &MemTableReaderExec{
+			baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()),
+			table:        v.Table,
+			retriever: &federatedTableRetriever{
+				extractor: extractor,
+				columns:   v.Columns,
+			},
+		}
+	}
 	switch v.DBName.L {
 	case util.MetricSchemaName.L:
 		return &MemTableReaderExec{
@@ -1780,6 +1817,11 @@ func (b *executorBuilder) buildMemTable(v *plannercore.PhysicalMemTable) Executo
 			strings.ToLower(infoschema.TablePlacementPolicies),
 			strings.ToLower(infoschema.TableTrxSummary),
 			strings.ToLower(infoschema.TableVariablesInfo),
+			strings.ToLower(infoschema.TableTiFlashReplicaRecommendations),
+			strings.ToLower(infoschema.TableCoprocessorCacheStats),
+			strings.ToLower(infoschema.ClusterTableCoprocessorCacheStats),
+			strings.ToLower(infoschema.TableCardinalityFeedback),
+			strings.ToLower(infoschema.ClusterTableCardinalityFeedback),
 			strings.ToLower(infoschema.ClusterTableTrxSummary):
 			return &MemTableReaderExec{
 				baseExecutor: newBaseExecutor(b.ctx, v.Schema(), v.ID()),
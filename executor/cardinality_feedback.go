@@ -0,0 +1,78 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/util/execdetails"
+)
+
+// collectCardinalityFeedback feeds the actual row counts of the table readers of an executed plan back to the
+// correction factors of the filters on the tables. Only the readers which are fully consumed once are collected, so the
+// actual row counts are the results of the filters.
+func collectCardinalityFeedback(p plannercore.Plan, coll *execdetails.RuntimeStatsColl) {
+	switch x := p.(type) {
+	case *plannercore.Insert:
+		p = x.SelectPlan
+	case *plannercore.Update:
+		p = x.SelectPlan
+	case *plannercore.Delete:
+		p = x.SelectPlan
+	}
+	if pp, ok := p.(plannercore.PhysicalPlan); ok && pp != nil {
+		collectReaderFeedback(pp, coll)
+	}
+}
+
+func collectReaderFeedback(p plannercore.PhysicalPlan, coll *execdetails.RuntimeStatsColl) {
+	switch x := p.(type) {
+	case *plannercore.PhysicalLimit, *plannercore.PhysicalMergeJoin:
+		// The children may be closed before they are exhausted.
+		return
+	case *plannercore.PhysicalApply:
+		// The inner child is executed once per outer row.
+		collectReaderFeedback(x.Children()[1-x.InnerChildIdx], coll)
+		return
+	case *plannercore.PhysicalIndexJoin:
+		collectReaderFeedback(x.Children()[1-x.InnerChildIdx], coll)
+		return
+	case *plannercore.PhysicalIndexHashJoin:
+		collectReaderFeedback(x.Children()[1-x.InnerChildIdx], coll)
+		return
+	case *plannercore.PhysicalIndexMergeJoin:
+		collectReaderFeedback(x.Children()[1-x.InnerChildIdx], coll)
+		return
+	case *plannercore.PhysicalHashJoin:
+		// The probe side isn't read if the build side is empty.
+		for _, child := range x.Children() {
+			if !coll.ExistsRootStats(child.ID()) || coll.GetRootStats(child.ID()).GetActRows() == 0 {
+				return
+			}
+		}
+	}
+	if tbl, conds := plannercore.FilteredTableOfReader(p); tbl != nil {
+		// The readers are collected along with the selection of the filters which can't be pushed down above them, the
+		// estimations of which are corrected, see copTask.handleRootTaskConds.
+		if len(conds) > 0 && coll.ExistsRootStats(p.ID()) {
+			statistics.RecordCardinalityFeedback(tbl.ID, statistics.FilterColumnIDs(conds), p.Stats().StatsVersion,
+				p.StatsCount(), float64(coll.GetRootStats(p.ID()).GetActRows()))
+		}
+		return
+	}
+	for _, child := range p.Children() {
+		collectReaderFeedback(child, coll)
+	}
+}
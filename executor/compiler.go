@@ -20,8 +20,11 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/metrics"
 	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/planner"
 	plannercore "github.com/pingcap/tidb/planner/core"
@@ -98,6 +101,8 @@ func (c *Compiler) Compile(ctx context.Context, stmtNode ast.StmtNode) (*ExecStm
 		return nil, err
 	}
 
+	setReadRouting(c.Ctx, is)
+
 	failpoint.Inject("assertStmtCtxIsStaleness", func(val failpoint.Value) {
 		staleread.AssertStmtStaleness(c.Ctx, val.(bool))
 	})
@@ -139,6 +144,40 @@ func (c *Compiler) Compile(ctx context.Context, stmtNode ast.StmtNode) (*ExecStm
 	return stmt, nil
 }
 
+// setReadRouting chooses the replicas to read by the read routing policy of the
+// databases accessed by the statement, which takes effect only if all of them
+// have the same policy and tidb_replica_read is left as the default 'leader',
+// so an explicit replica read setting of the session is always respected.
+func setReadRouting(sctx sessionctx.Context, is infoschema.InfoSchema) {
+	sessVars := sctx.GetSessionVars()
+	if sessVars.GetReplicaRead() != kv.ReplicaReadLeader {
+		return
+	}
+	stmtCtx := sessVars.StmtCtx
+	var policy string
+	for i, tbl := range stmtCtx.Tables {
+		dbInfo, ok := is.SchemaByName(model.NewCIStr(tbl.DB))
+		if !ok || (i > 0 && dbInfo.ReadRouting != policy) {
+			return
+		}
+		policy = dbInfo.ReadRouting
+	}
+
+	var replicaRead kv.ReplicaReadType
+	switch policy {
+	case model.ReadRoutingPreferLocalZone, model.ReadRoutingNearestFollower:
+		// Both read from the replicas in the same zone by the labels of the
+		// stores, the same as tidb_replica_read = 'closest-replicas'.
+		replicaRead = kv.ReplicaReadClosest
+	case model.ReadRoutingPrimaryOnly:
+		replicaRead = kv.ReplicaReadLeader
+	default:
+		return
+	}
+	stmtCtx.HasReadRouting = true
+	stmtCtx.ReadRouting = byte(replicaRead)
+}
+
 // needLowerPriority checks whether it's needed to lower the execution priority
 // of a query.
 // If the estimated output row count of any operator in the physical plan tree
@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
@@ -171,6 +172,8 @@ func (e *DDLExec) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 		err = e.executeRecoverTable(x)
 	case *ast.FlashBackTableStmt:
 		err = e.executeFlashbackTable(x)
+	case *ast.PurgeTableStmt:
+		err = e.executePurgeTable(x)
 	case *ast.FlashBackClusterStmt:
 		err = e.executeFlashBackCluster(ctx, x)
 	case *ast.RenameTableStmt:
@@ -247,8 +250,21 @@ func (e *DDLExec) executeAlterDatabase(s *ast.AlterDatabaseStmt) error {
 }
 
 func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
-	err := domain.GetDomain(e.ctx).DDL().CreateTable(e.ctx, s)
-	return err
+	dom := domain.GetDomain(e.ctx)
+	existed := dom.InfoSchema().TableExists(s.Table.Schema, s.Table.Name)
+	err := dom.DDL().CreateTable(e.ctx, s)
+	if err != nil || existed {
+		return err
+	}
+	// record the created table, so the rows of INSERT ... SELECT can be ingested into it.
+	if tbl, err := dom.InfoSchema().TableByName(s.Table.Schema, s.Table.Name); err == nil {
+		sessVars := e.ctx.GetSessionVars()
+		if sessVars.CreatedTables == nil {
+			sessVars.CreatedTables = make(map[int64]struct{})
+		}
+		sessVars.CreatedTables[tbl.Meta().ID] = struct{}{}
+	}
+	return nil
 }
 
 func (e *DDLExec) createSessionTemporaryTable(s *ast.CreateTableStmt) error {
@@ -375,8 +391,18 @@ func (e *DDLExec) executeAlterTable(ctx context.Context, s *ast.AlterTableStmt)
 // is used to recover the table that deleted by mistake.
 func (e *DDLExec) executeRecoverTable(s *ast.RecoverTableStmt) error {
 	dom := domain.GetDomain(e.ctx)
+	recoverInfo, err := e.getRecoverInfoFromRecycleBin(s.JobID, s.Table)
+	if err != nil {
+		return err
+	}
+	if recoverInfo != nil {
+		if tbl, ok := dom.InfoSchema().TableByID(recoverInfo.TableInfo.ID); ok {
+			return infoschema.ErrTableExists.GenWithStack("Table '%-.192s' already been recover to '%-.192s', can't be recover repeatedly", recoverInfo.OldTableName, tbl.Meta().Name.O)
+		}
+		return dom.DDL().RecoverTable(e.ctx, recoverInfo)
+	}
+
 	var job *model.Job
-	var err error
 	var tblInfo *model.TableInfo
 	if s.JobID != 0 {
 		job, tblInfo, err = e.getRecoverTableByJobID(s, dom)
@@ -401,7 +427,7 @@ func (e *DDLExec) executeRecoverTable(s *ast.RecoverTableStmt) error {
 		return err
 	}
 
-	recoverInfo := &ddl.RecoverInfo{
+	recoverInfo = &ddl.RecoverInfo{
 		SchemaID:      job.SchemaID,
 		TableInfo:     tblInfo,
 		DropJobID:     job.ID,
@@ -415,6 +441,46 @@ func (e *DDLExec) executeRecoverTable(s *ast.RecoverTableStmt) error {
 	return err
 }
 
+// getRecoverInfoFromRecycleBin gets the dropped table from the recycle bin by
+// the drop job ID, or the last dropped table of the name if the job ID is 0. It
+// returns nil if the table isn't in the recycle bin.
+func (e *DDLExec) getRecoverInfoFromRecycleBin(jobID int64, tableName *ast.TableName) (*ddl.RecoverInfo, error) {
+	txn, err := e.ctx.Txn(true)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := meta.NewMeta(txn).ListRecycleBin()
+	if err != nil {
+		return nil, err
+	}
+	var schemaName string
+	if jobID == 0 {
+		schemaName = tableName.Schema.L
+		if schemaName == "" {
+			schemaName = strings.ToLower(e.ctx.GetSessionVars().CurrentDB)
+		}
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if jobID != 0 && entry.JobID != jobID {
+			continue
+		}
+		if jobID == 0 && (entry.SchemaName != schemaName || entry.TableInfo.Name.L != tableName.Name.L) {
+			continue
+		}
+		return &ddl.RecoverInfo{
+			SchemaID:      entry.SchemaID,
+			TableInfo:     entry.TableInfo,
+			DropJobID:     entry.JobID,
+			SnapshotTS:    entry.DropTS,
+			AutoIDs:       entry.AutoIDs,
+			OldSchemaName: entry.SchemaName,
+			OldTableName:  entry.TableInfo.Name.L,
+		}, nil
+	}
+	return nil, nil
+}
+
 func (e *DDLExec) getRecoverTableByJobID(s *ast.RecoverTableStmt, dom *domain.Domain) (*model.Job, *model.TableInfo, error) {
 	se, err := e.getSysSession()
 	if err != nil {
@@ -546,6 +612,20 @@ func (e *DDLExec) executeFlashBackCluster(ctx context.Context, s *ast.FlashBackC
 }
 
 func (e *DDLExec) executeFlashbackTable(s *ast.FlashBackTableStmt) error {
+	recoverInfo, err := e.getRecoverInfoFromRecycleBin(0, s.Table)
+	if err != nil {
+		return err
+	}
+	if recoverInfo != nil {
+		if len(s.NewName) != 0 {
+			recoverInfo.TableInfo.Name = model.NewCIStr(s.NewName)
+		}
+		if tbl, ok := domain.GetDomain(e.ctx).InfoSchema().TableByID(recoverInfo.TableInfo.ID); ok {
+			return infoschema.ErrTableExists.GenWithStack("Table '%-.192s' already been flashback to '%-.192s', can't be flashback repeatedly", s.Table.Name.O, tbl.Meta().Name.O)
+		}
+		return domain.GetDomain(e.ctx).DDL().RecoverTable(e.ctx, recoverInfo)
+	}
+
 	job, tblInfo, err := e.getRecoverTableByTableName(s.Table)
 	if err != nil {
 		return err
@@ -569,7 +649,7 @@ func (e *DDLExec) executeFlashbackTable(s *ast.FlashBackTableStmt) error {
 		return err
 	}
 
-	recoverInfo := &ddl.RecoverInfo{
+	recoverInfo = &ddl.RecoverInfo{
 		SchemaID:      job.SchemaID,
 		TableInfo:     tblInfo,
 		DropJobID:     job.ID,
@@ -583,6 +663,26 @@ func (e *DDLExec) executeFlashbackTable(s *ast.FlashBackTableStmt) error {
 	return err
 }
 
+// executePurgeTable removes the last dropped table of the name from the recycle
+// bin, then the data of the table is deleted by the next GC.
+func (e *DDLExec) executePurgeTable(s *ast.PurgeTableStmt) error {
+	ctx := kv.WithInternalSourceType(context.Background(), kv.InternalTxnDDL)
+	return kv.RunInNewTxn(ctx, e.ctx.GetStore(), true, func(ctx context.Context, txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		entries, err := m.ListRecycleBin()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for i := len(entries) - 1; i >= 0; i-- {
+			entry := entries[i]
+			if entry.SchemaName == s.Table.Schema.L && entry.TableInfo.Name.L == s.Table.Name.L {
+				return m.DeleteRecycleBinEntry(entry.JobID)
+			}
+		}
+		return errors.Errorf("Can't find dropped table: %v in recycle bin", s.Table.Name)
+	})
+}
+
 func (e *DDLExec) executeLockTables(s *ast.LockTablesStmt) error {
 	if !config.TableLockEnabled() {
 		e.ctx.GetSessionVars().StmtCtx.AppendWarning(ErrFuncNotEnabled.GenWithStackByArgs("LOCK TABLES", "enable-table-lock"))
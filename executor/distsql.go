@@ -39,6 +39,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util"
@@ -1240,6 +1241,13 @@ func (w *tableWorker) compareData(ctx context.Context, task *lookupTableTask, ta
 			vals = vals[:0]
 			for i, col := range w.idxTblCols {
 				vals = append(vals, row.GetDatum(i, &col.FieldType))
+				// The lazy default values are read as NULLs, but they are stored in the index.
+				if vals[i].IsNull() && col.HasLazyOriginDefault() {
+					vals[i], err = tables.GetColDefaultValue(w.idxLookup.ctx, col, handle, nil)
+					if err != nil {
+						return err
+					}
+				}
 			}
 			tablecodec.TruncateIndexValues(tblInfo, w.idxLookup.index, vals)
 			sctx := w.idxLookup.ctx.GetSessionVars().StmtCtx
@@ -50,6 +50,7 @@ var (
 	ErrRoleNotGranted                = dbterror.ClassPrivilege.NewStd(mysql.ErrRoleNotGranted)
 	ErrDeadlock                      = dbterror.ClassExecutor.NewStd(mysql.ErrLockDeadlock)
 	ErrQueryInterrupted              = dbterror.ClassExecutor.NewStd(mysql.ErrQueryInterrupted)
+	ErrStmtTimeoutExceeded           = dbterror.ClassExecutor.NewStd(mysql.ErrStmtTimeoutExceeded)
 	ErrDynamicPrivilegeNotRegistered = dbterror.ClassExecutor.NewStd(mysql.ErrDynamicPrivilegeNotRegistered)
 	ErrIllegalPrivilegeLevel         = dbterror.ClassExecutor.NewStd(mysql.ErrIllegalPrivilegeLevel)
 	ErrInvalidSplitRegionRanges      = dbterror.ClassExecutor.NewStd(mysql.ErrInvalidSplitRegionRanges)
@@ -57,6 +58,11 @@ var (
 	ErrInstanceScope                 = dbterror.ClassExecutor.NewStd(mysql.ErrInstanceScope)
 	ErrSettingNoopVariable           = dbterror.ClassExecutor.NewStd(mysql.ErrSettingNoopVariable)
 	ErrLazyUniquenessCheckFailure    = dbterror.ClassExecutor.NewStd(mysql.ErrLazyUniquenessCheckFailure)
+	ErrForeignServerExists           = dbterror.ClassExecutor.NewStd(mysql.ErrForeignServerExists)
+	ErrForeignServerDoesntExist      = dbterror.ClassExecutor.NewStd(mysql.ErrForeignServerDoesntExist)
+	ErrQueryOnForeignDataSource      = dbterror.ClassExecutor.NewStd(mysql.ErrQueryOnForeignDataSource)
+	ErrConnectToForeignDataSource    = dbterror.ClassExecutor.NewStd(mysql.ErrConnectToForeignDataSource)
+	ErrFileExists                    = dbterror.ClassExecutor.NewStd(mysql.ErrFileExists)
 
 	ErrBRIEBackupFailed      = dbterror.ClassExecutor.NewStd(mysql.ErrBRIEBackupFailed)
 	ErrBRIERestoreFailed     = dbterror.ClassExecutor.NewStd(mysql.ErrBRIERestoreFailed)
@@ -40,6 +40,7 @@ import (
 	"github.com/pingcap/tidb/util/memory"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/ranger"
+	"github.com/pingcap/tidb/util/stmtsummary"
 	"github.com/pingcap/tidb/util/tableutil"
 	"github.com/stretchr/testify/require"
 )
@@ -353,6 +354,30 @@ func TestFilterTemporaryTableKeys(t *testing.T) {
 	require.Len(t, res, 1)
 }
 
+func TestRecommendTiFlashReplica(t *testing.T) {
+	stats := &stmtsummary.TableScanStats{ExecCount: 10, SumTotalKeys: 5000}
+	rec := recommendTiFlashReplica(stats, 0, 0)
+	require.False(t, rec.recommended)
+	require.Equal(t, "the table is empty or not analyzed", rec.reason)
+
+	// 10 statements scan 5 full tables in total.
+	rec = recommendTiFlashReplica(stats, 1000, 1<<20)
+	require.True(t, rec.recommended)
+	require.Equal(t, 0.5, rec.scanRatio)
+	require.Equal(t, 5.0, rec.score)
+	require.Equal(t, "the statements scan 50% of the table on average", rec.reason)
+
+	// The score is lower for larger replicas.
+	rec = recommendTiFlashReplica(stats, 1000, 4<<30)
+	require.True(t, rec.recommended)
+	require.Equal(t, 1.25, rec.score)
+
+	rec = recommendTiFlashReplica(stats, 100000, 1<<20)
+	require.False(t, rec.recommended)
+	require.Equal(t, 0.005, rec.scanRatio)
+	require.Equal(t, "the statements only scan a small part of the table", rec.reason)
+}
+
 func TestLoadDataWithDifferentEscapeChar(t *testing.T) {
 	tests := []struct {
 		input      string
@@ -142,6 +142,32 @@ func TestLoadStats(t *testing.T) {
 	require.Error(t, tk.ExecToErr("load stats ./xxx.json"))
 }
 
+func TestAdminExportImportStats(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, b int, index idx_a(a))")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3)")
+	tk.MustExec("create table t_dropped (a int)")
+	tk.MustExec("insert into t_dropped values (1)")
+	tk.MustExec("analyze table t, t_dropped")
+
+	dir := t.TempDir()
+	tk.MustExec(fmt.Sprintf("admin export stats to 'local://%s'", dir))
+	require.FileExists(t, filepath.Join(dir, "test.t.json"))
+	require.FileExists(t, filepath.Join(dir, "test.t_dropped.json"))
+
+	tk.MustExec("drop table t_dropped")
+	tk.MustExec("drop stats t")
+	require.NoError(t, dom.StatsHandle().Update(dom.InfoSchema()))
+	tk.MustQuery("show stats_histograms where table_name = 't'").Check(testkit.Rows())
+
+	tk.MustExec(fmt.Sprintf("admin import stats from 'local://%s'", dir))
+	require.Len(t, tk.Session().GetSessionVars().StmtCtx.GetWarnings(), 1)
+	require.Len(t, tk.MustQuery("show stats_histograms where table_name = 't'").Rows(), 3)
+	tk.MustQuery("show stats_meta where table_name = 't'").CheckAt([]int{5}, testkit.Rows("3"))
+}
+
 func TestPlanReplayer(t *testing.T) {
 	require.NoError(t, failpoint.Enable("github.com/pingcap/tidb/infoschema/mockTiFlashStoreCount", `return(true)`))
 	defer func() {
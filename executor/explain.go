@@ -15,12 +15,16 @@
 package executor
 
 import (
+	"bufio"
 	"context"
+	"encoding/csv"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	rpprof "runtime/pprof"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -31,6 +35,7 @@ import (
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/mathutil"
 	"github.com/pingcap/tidb/util/memory"
+	"github.com/xitongsys/parquet-go/writer"
 	"go.uber.org/zap"
 )
 
@@ -71,6 +76,12 @@ func (e *ExplainExec) Next(ctx context.Context, req *chunk.Chunk) error {
 		if err != nil {
 			return err
 		}
+		if e.explain.Outfile != "" {
+			return e.dumpToOutfile(ctx)
+		}
+	}
+	if e.explain.Outfile != "" {
+		return nil
 	}
 
 	req.GrowAndReset(e.maxChunkSize)
@@ -88,6 +99,68 @@ func (e *ExplainExec) Next(ctx context.Context, req *chunk.Chunk) error {
 	return nil
 }
 
+// dumpToOutfile writes the result of `EXPLAIN ... INTO OUTFILE`. The file is written as
+// a Parquet or CSV file with header according to the extension of the file name, otherwise
+// the fields are separated by tabs, e.g. the JSON plan is written as it is.
+func (e *ExplainExec) dumpToOutfile(ctx context.Context) (err error) {
+	f, err := createOutfile(ctx, e.explain.Outfile)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	defer func() {
+		if err1 := w.Flush(); err == nil {
+			err = errors.Trace(err1)
+		}
+		if err1 := f.Close(); err == nil {
+			err = errors.Trace(err1)
+		}
+	}()
+
+	fields := e.explain.OutfileFields
+	switch outfileExt(e.explain.Outfile) {
+	case ".parquet":
+		md := make([]string, 0, len(fields))
+		for _, field := range fields {
+			// The names of the Parquet columns can't contain spaces.
+			name := strings.ReplaceAll(field, " ", "_")
+			md = append(md, fmt.Sprintf("name=%s, type=UTF8, repetitiontype=OPTIONAL", name))
+		}
+		pw, err := writer.NewCSVWriterFromWriter(md, w, 1)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		rec := make([]*string, len(fields))
+		for _, row := range e.rows {
+			for i := range row {
+				rec[i] = &row[i]
+			}
+			if err := pw.WriteString(rec); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		if err := pw.WriteStop(); err != nil {
+			return errors.Trace(err)
+		}
+	case ".csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(fields); err != nil {
+			return errors.Trace(err)
+		}
+		if err := cw.WriteAll(e.rows); err != nil {
+			return errors.Trace(err)
+		}
+	default:
+		for _, row := range e.rows {
+			if _, err := w.WriteString(strings.Join(row, "\t") + "\n"); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	e.ctx.GetSessionVars().StmtCtx.AddAffectedRows(uint64(len(e.rows)))
+	return nil
+}
+
 func (e *ExplainExec) executeAnalyzeExec(ctx context.Context) (err error) {
 	if e.analyzeExec != nil && !e.executed {
 		defer func() {
@@ -16,7 +16,10 @@ package executor_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -25,6 +28,7 @@ import (
 
 	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/mysql"
 	plannercore "github.com/pingcap/tidb/planner/core"
 	"github.com/pingcap/tidb/session"
 	"github.com/pingcap/tidb/testkit"
@@ -494,3 +498,37 @@ func TestIssue35105(t *testing.T) {
 	require.Error(t, tk.ExecToErr("explain analyze insert into t values (1), (2), (3)"))
 	tk.MustQuery("select * from t").Check(testkit.Rows("2"))
 }
+
+func TestExplainIntoOutfile(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, b int, key(a))")
+	tk.MustExec("insert into t values (1, 1), (2, 2)")
+	dir := t.TempDir()
+
+	jsonFile := filepath.Join(dir, "plan.json")
+	tk.MustExec(fmt.Sprintf("explain analyze format = 'json' into outfile '%s' select * from t where a > 1", jsonFile))
+	content, err := os.ReadFile(jsonFile)
+	require.NoError(t, err)
+	var plans []*plannercore.ExplainInfoForEncode
+	require.NoError(t, json.Unmarshal(content, &plans))
+	require.Len(t, plans, 1)
+	require.Equal(t, "root", plans[0].TaskType)
+	require.Equal(t, "1", plans[0].ActRows)
+	require.NotEmpty(t, plans[0].SubOperators)
+	tk.MustGetErrCode(fmt.Sprintf("explain format = 'json' into outfile '%s' select * from t", jsonFile), mysql.ErrFileExists)
+
+	csvFile := filepath.Join(dir, "plan.csv")
+	tk.MustExec(fmt.Sprintf("explain format = 'brief' into outfile '%s' select * from t", csvFile))
+	content, err = os.ReadFile(csvFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Equal(t, "id,estRows,task,access object,operator info", lines[0])
+	require.Len(t, lines, 3)
+
+	// export the statements summary for offline analysis.
+	tk.MustExec(fmt.Sprintf("select digest_text, exec_count from information_schema.statements_summary into outfile 'local://%s/summary.parquet'", filepath.ToSlash(dir)))
+	require.Greater(t, tk.Session().AffectedRows(), uint64(0))
+	tk.MustQuery(fmt.Sprintf("select count(*) > 0 from external('local://%s/summary.parquet') t where exec_count > 0", filepath.ToSlash(dir))).Check(testkit.Rows("1"))
+}
@@ -0,0 +1,187 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/parser/model"
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+)
+
+const externalTableBatchSize = 1024
+
+// externalTableRetriever reads the rows of the files matched by the EXTERNAL table
+// function one by one, and filters them by the pushed down conditions.
+type externalTableRetriever struct {
+	dummyCloser
+	extractor *plannercore.ExternalTableExtractor
+	columns   []*model.ColumnInfo
+	// conditions are resolved against the output columns.
+	conditions []expression.Expression
+
+	store   storage.ExternalStorage
+	files   []plannercore.ExternalFile
+	fileIdx int
+	parser  mydump.Parser
+	// offsets maps the output columns to the columns of the current file, -1 means
+	// the column is absent in the file and is filled with NULL.
+	offsets   []int
+	isDrained bool
+}
+
+func (e *externalTableRetriever) retrieve(ctx context.Context, sctx sessionctx.Context) ([][]types.Datum, error) {
+	if e.isDrained {
+		return nil, nil
+	}
+	if e.store == nil {
+		store, err := e.extractor.OpenStorage(ctx)
+		if err != nil {
+			e.isDrained = true
+			return nil, err
+		}
+		e.store = store
+		// The files are listed when the statement is executed rather than when the plan
+		// is built, so that EXPLAIN doesn't list them.
+		files, err := e.extractor.ListFiles(ctx, store)
+		if err != nil {
+			e.isDrained = true
+			return nil, err
+		}
+		e.files = files
+	}
+
+	sc := sctx.GetSessionVars().StmtCtx
+	mutableRow := chunk.MutRowFromTypes(e.fieldTypes())
+	rows := make([][]types.Datum, 0, externalTableBatchSize)
+	for len(rows) < externalTableBatchSize {
+		if e.parser == nil {
+			if e.fileIdx >= len(e.files) {
+				e.isDrained = true
+				break
+			}
+			if err := e.openFile(ctx, sctx); err != nil {
+				e.isDrained = true
+				return nil, err
+			}
+		}
+
+		err := e.parser.ReadRow()
+		if errors.Cause(err) == io.EOF {
+			if err := e.closeFile(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			e.isDrained = true
+			return nil, errors.Annotatef(err, "read file '%s'", e.files[e.fileIdx].Path)
+		}
+
+		parsed := e.parser.LastRow().Row
+		row := make([]types.Datum, len(e.columns))
+		for i, col := range e.columns {
+			offset := e.offsets[i]
+			if offset < 0 || offset >= len(parsed) || parsed[offset].IsNull() {
+				continue
+			}
+			row[i], err = parsed[offset].ConvertTo(sc, &col.FieldType)
+			if err != nil {
+				e.isDrained = true
+				return nil, err
+			}
+		}
+		e.parser.RecycleRow(e.parser.LastRow())
+
+		if len(e.conditions) > 0 {
+			mutableRow.SetDatums(row...)
+			matched, _, err := expression.EvalBool(sctx, e.conditions, mutableRow.ToRow())
+			if err != nil {
+				e.isDrained = true
+				return nil, err
+			}
+			if !matched {
+				continue
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// openFile opens the parser of the current file and maps the output columns to
+// the columns of the file by their names.
+func (e *externalTableRetriever) openFile(ctx context.Context, sctx sessionctx.Context) error {
+	parser, err := e.extractor.OpenParser(ctx, e.store, e.files[e.fileIdx])
+	if err != nil {
+		return err
+	}
+	columnIndex := make(map[string]int)
+	for i, name := range parser.Columns() {
+		columnIndex[strings.ToLower(name)] = i
+	}
+	e.offsets = e.offsets[:0]
+	for _, col := range e.columns {
+		offset, ok := columnIndex[col.Name.L]
+		if !ok {
+			offset = -1
+		}
+		e.offsets = append(e.offsets, offset)
+	}
+	if parquetParser, ok := parser.(*mydump.ParquetParser); ok {
+		names := make([]string, 0, len(e.columns))
+		for _, col := range e.columns {
+			names = append(names, col.Name.L)
+		}
+		parquetParser.SetReadColumns(names)
+		if err := parquetParser.SetRowFilter(e.extractor.ParquetRowFilter(sctx.GetSessionVars().Location(), parquetParser)); err != nil {
+			_ = parser.Close()
+			return err
+		}
+	}
+	e.parser = parser
+	return nil
+}
+
+func (e *externalTableRetriever) closeFile() error {
+	parser := e.parser
+	e.parser = nil
+	e.fileIdx++
+	return parser.Close()
+}
+
+func (e *externalTableRetriever) fieldTypes() []*types.FieldType {
+	fieldTypes := make([]*types.FieldType, 0, len(e.columns))
+	for _, col := range e.columns {
+		fieldTypes = append(fieldTypes, &col.FieldType)
+	}
+	return fieldTypes
+}
+
+func (e *externalTableRetriever) close() error {
+	if e.parser != nil {
+		return e.closeFile()
+	}
+	return nil
+}
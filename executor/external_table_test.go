@@ -0,0 +1,141 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/testkit"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/sem"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+func TestExternalTable(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil))
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.csv"), []byte("id,name\n1,a\n2,b\n"), 0o644))
+	// the columns are matched by names, and the missing columns are NULL.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.csv"), []byte("ID\n3\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "c.txt"), []byte("x\n"), 0o644))
+	location := fmt.Sprintf("local://%s/*.csv", filepath.ToSlash(dir))
+
+	tk.MustQuery(fmt.Sprintf("select * from external('%s') t order by id", location)).Check(testkit.Rows("1 a", "2 b", "3 <nil>"))
+	tk.MustQuery(fmt.Sprintf("select t.name from external('%s', format => 'csv') as t where t.id > 1 and t.id < 3", location)).Check(testkit.Rows("b"))
+	tk.MustQuery(fmt.Sprintf("select count(*) from external('%s') where name is null", location)).Check(testkit.Rows("1"))
+	rows := tk.MustQuery(fmt.Sprintf("explain format = 'brief' select name from external('%s') t where id = '1'", location)).Rows()
+	require.Equal(t, "format:csv, pattern:*.csv, pushed down filter:eq(Column#1, \"1\")", rows[len(rows)-1][4])
+
+	// the files are listed by the executor, so the files added after the plan is built are read.
+	tk.MustExec(fmt.Sprintf("prepare st from 'select count(*) from external(\"%s\")'", location))
+	tk.MustQuery("execute st").Check(testkit.Rows("3"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "d.csv"), []byte("id\n4\n"), 0o644))
+	tk.MustQuery("execute st").Check(testkit.Rows("4"))
+	require.NoError(t, os.Remove(filepath.Join(dir, "d.csv")))
+
+	// the credentials in the location are redacted in the process list and the logs.
+	tk.MustGetErrCode("select * from external('s3://bucket/*.json?secret-access-key=123') t", int(core.ErrNotSupportedYet.Code()))
+	require.Equal(t, "SELECT * FROM EXTERNAL('s3://bucket/*.json?secret-access-key=xxxxxx') AS `t`", tk.Session().ShowProcess().Info)
+
+	tk.MustGetErrMsg(fmt.Sprintf("select * from external('local://%s/*.json')", filepath.ToSlash(dir)),
+		"[planner:1235]This version of TiDB doesn't yet support 'EXTERNAL files of format 'json''")
+	tk.MustGetErrCode(fmt.Sprintf("select * from external('%s', header => 'true')", location), int(core.ErrWrongArguments.Code()))
+	tk.MustGetErrCode(fmt.Sprintf("select * from external('local://%s/*.parquet')", filepath.ToSlash(dir)), int(core.ErrWrongArguments.Code()))
+
+	tk.MustExec("create user 'external_user'@'%'")
+	userTk := testkit.NewTestKit(t, store)
+	require.NoError(t, userTk.Session().Auth(&auth.UserIdentity{Username: "external_user", Hostname: "%"}, nil, nil))
+	userTk.MustGetErrCode(fmt.Sprintf("select * from external('%s')", location), int(core.ErrSpecificAccessDenied.Code()))
+	tk.MustExec("grant file on *.* to 'external_user'@'%'")
+	userTk.MustQuery(fmt.Sprintf("select id from external('%s') t order by id", location)).Check(testkit.Rows("1", "2", "3"))
+
+	// the files in the local storage of the server can't be read when SEM is enabled.
+	sem.Enable()
+	defer sem.Disable()
+	tk.MustGetErrCode(fmt.Sprintf("select * from external('%s')", location), int(core.ErrNotSupportedWithSem.Code()))
+	tk.MustGetErrCode(fmt.Sprintf("select * from external('%s')", filepath.Join(dir, "*.csv")), int(core.ErrNotSupportedWithSem.Code()))
+	tk.MustGetErrCode("select * from external('hdfs://host/path/*.csv')", int(core.ErrNotSupportedWithSem.Code()))
+}
+
+func TestExternalTableParquet(t *testing.T) {
+	type Test struct {
+		A int32 `parquet:"name=a, type=INT32"`
+		D int32 `parquet:"name=d, type=DATE"`
+	}
+	dir := t.TempDir()
+	pf, err := local.NewLocalFileWriter(filepath.Join(dir, "a.parquet"))
+	require.NoError(t, err)
+	w, err := writer.NewParquetWriter(pf, new(Test), 2)
+	require.NoError(t, err)
+	// 10 row groups with 10 rows each
+	for i := 0; i < 100; i++ {
+		require.NoError(t, w.Write(&Test{A: int32(i), D: int32(i)}))
+		if i%10 == 9 {
+			require.NoError(t, w.Flush(true))
+		}
+	}
+	require.NoError(t, w.WriteStop())
+	require.NoError(t, pf.Close())
+
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil))
+	location := fmt.Sprintf("local://%s/*.parquet", filepath.ToSlash(dir))
+	tk.MustQuery(fmt.Sprintf("select count(*), min(a), max(a) from external('%s') where a >= 75 and a < 85", location)).Check(testkit.Rows("10 75 84"))
+	tk.MustQuery(fmt.Sprintf("select a from external('%s') t where 95 < t.a order by a", location)).Check(testkit.Rows("96", "97", "98", "99"))
+	tk.MustQuery(fmt.Sprintf("select a from external('%s') where a > 9.5 and a < 11", location)).Check(testkit.Rows("10"))
+	tk.MustQuery(fmt.Sprintf("select a from external('%s') where d = '1970-01-31'", location)).Check(testkit.Rows("30"))
+	tk.MustQuery(fmt.Sprintf("select count(*) from external('%s') where a > 10 and a < 20 or a = 50", location)).Check(testkit.Rows("10"))
+
+	// only the conditions converted to the types in the file without loss are used.
+	localStore, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+	extractor := &core.ExternalTableExtractor{
+		SourceType: mydump.SourceTypeParquet,
+		RowGroupConds: []config.RowFilterCond{
+			{Column: "a", Op: opcode.GE, Value: types.NewIntDatum(75)},
+			{Column: "a", Op: opcode.GT, Value: types.NewFloat64Datum(9.5)},
+			{Column: "a", Op: opcode.LT, Value: types.NewFloat64Datum(85)},
+			{Column: "x", Op: opcode.EQ, Value: types.NewIntDatum(1)},
+		},
+	}
+	parser, err := extractor.OpenParser(context.Background(), localStore, core.ExternalFile{Path: "a.parquet"})
+	require.NoError(t, err)
+	defer parser.Close()
+	// the values are converted in the session time zone.
+	loc := time.FixedZone("UTC+8", 8*60*60)
+	rowFilter := extractor.ParquetRowFilter(loc, parser.(*mydump.ParquetParser))
+	require.Equal(t, []config.RowFilterCond{
+		{Column: "a", Op: opcode.GE, Value: types.NewIntDatum(75)},
+		{Column: "a", Op: opcode.LT, Value: types.NewFloat64Datum(85)},
+	}, rowFilter.Conds())
+	require.Equal(t, loc, rowFilter.TimeZone())
+}
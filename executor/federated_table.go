@@ -0,0 +1,247 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/model"
+	parser_mysql "github.com/pingcap/tidb/parser/mysql"
+	plannercore "github.com/pingcap/tidb/planner/core"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"go.uber.org/zap"
+)
+
+const (
+	federatedTableBatchSize = 1024
+	federatedDefaultPort    = 3306
+	federatedConnectTimeout = 10 * time.Second
+)
+
+// federatedTableRetriever reads the rows of a federated table from the remote server
+// with the pushed down conditions.
+type federatedTableRetriever struct {
+	dummyCloser
+	extractor *plannercore.FederatedTableExtractor
+	columns   []*model.ColumnInfo
+
+	db        *sql.DB
+	rows      *sql.Rows
+	isDrained bool
+}
+
+func (e *federatedTableRetriever) retrieve(ctx context.Context, sctx sessionctx.Context) ([][]types.Datum, error) {
+	if e.isDrained {
+		return nil, nil
+	}
+	if e.rows == nil {
+		if err := e.query(ctx, sctx); err != nil {
+			e.isDrained = true
+			return nil, err
+		}
+	}
+
+	sc := sctx.GetSessionVars().StmtCtx
+	values := make([]sql.RawBytes, len(e.columns))
+	dest := make([]interface{}, len(e.columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	rows := make([][]types.Datum, 0, federatedTableBatchSize)
+	for len(rows) < federatedTableBatchSize {
+		if !e.rows.Next() {
+			e.isDrained = true
+			if err := e.rows.Err(); err != nil {
+				return nil, e.remoteError(err)
+			}
+			break
+		}
+		if err := e.rows.Scan(dest...); err != nil {
+			e.isDrained = true
+			return nil, e.remoteError(err)
+		}
+		row := make([]types.Datum, len(e.columns))
+		for i, col := range e.columns {
+			if values[i] == nil {
+				continue
+			}
+			d := types.NewStringDatum(string(values[i]))
+			var err error
+			row[i], err = d.ConvertTo(sc, &col.FieldType)
+			if err == nil && col.GetType() == parser_mysql.TypeTimestamp && row[i].Kind() == types.KindMysqlTime {
+				t := row[i].GetMysqlTime()
+				err = t.ConvertTimeZone(time.UTC, sc.TimeZone)
+				row[i].SetMysqlTime(t)
+			}
+			if err != nil {
+				e.isDrained = true
+				return nil, err
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// query connects to the remote server and sends the query of the used columns.
+func (e *federatedTableRetriever) query(ctx context.Context, sctx sessionctx.Context) error {
+	cfg, err := e.connectionConfig(ctx, sctx)
+	if err != nil {
+		return err
+	}
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.db = sql.OpenDB(connector)
+
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	for i, col := range e.columns {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sqlexec.MustFormatSQL(&sb, "%n", col.Name.O)
+	}
+	sqlexec.MustFormatSQL(&sb, " FROM %n", e.extractor.Table)
+	if len(e.extractor.PushedConditions) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(e.extractor.PushedConditions, " AND "))
+	}
+	e.rows, err = e.db.QueryContext(ctx, sb.String())
+	if err != nil {
+		return e.remoteError(err)
+	}
+	return nil
+}
+
+// connectionConfig reads the server created by CREATE SERVER from mysql.servers.
+func (e *federatedTableRetriever) connectionConfig(ctx context.Context, sctx sessionctx.Context) (*mysql.Config, error) {
+	ctx = kv.WithInternalSourceType(ctx, kv.InternalTxnOthers)
+	exec := sctx.(sqlexec.RestrictedSQLExecutor)
+	rows, _, err := exec.ExecRestrictedSQL(ctx, nil,
+		`SELECT Host, Db, Username, Password, Port, Socket FROM %n.%n WHERE Server_name=%?`,
+		parser_mysql.SystemDB, parser_mysql.ServersTable, strings.ToLower(e.extractor.Server))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrForeignServerDoesntExist.GenWithStackByArgs(e.extractor.Server)
+	}
+	row := rows[0]
+	cfg := mysql.NewConfig()
+	cfg.DBName = row.GetString(1)
+	cfg.User = row.GetString(2)
+	cfg.Passwd = row.GetString(3)
+	cfg.Timeout = federatedConnectTimeout
+	// The TIMESTAMP values are sent and read in this time zone, see retrieve and
+	// FederatedTableExtractor.
+	cfg.Params = map[string]string{"time_zone": "'" + plannercore.FederatedTimeZone + "'"}
+	socket := row.GetString(5)
+	// The allowed hosts may be changed after the server is created.
+	host, err := federatedAllowedAddr(ctx, row.GetString(0), socket)
+	if err != nil {
+		return nil, err
+	}
+	if len(socket) > 0 {
+		cfg.Net = "unix"
+		cfg.Addr = socket
+	} else {
+		port := row.GetInt64(4)
+		if port == 0 {
+			port = federatedDefaultPort
+		}
+		cfg.Net = "tcp"
+		cfg.Addr = net.JoinHostPort(host, strconv.FormatInt(port, 10))
+	}
+	return cfg, nil
+}
+
+// federatedAllowedAddr checks the host of a server against tidb_federated_allowed_hosts, and
+// returns the address to connect to. If the host is allowed by an IP or CIDR, the resolved IP
+// is returned so that the host can't be resolved to another address when connecting. The
+// sockets are only allowed if tidb_federated_allowed_hosts is empty.
+func federatedAllowedAddr(ctx context.Context, host, socket string) (string, error) {
+	allowed := variable.FederatedAllowedHosts.Load()
+	if len(allowed) == 0 {
+		return host, nil
+	}
+	if len(socket) > 0 {
+		return "", ErrConnectToForeignDataSource.GenWithStackByArgs("socket is not allowed")
+	}
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(allowed, ",") {
+		if strings.EqualFold(entry, host) {
+			return host, nil
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		} else if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			nets = append(nets, ipNet)
+		}
+	}
+	var ips []net.IP
+	if ip := net.ParseIP(host); ip != nil {
+		ips = append(ips, ip)
+	} else if len(nets) > 0 {
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return "", ErrConnectToForeignDataSource.GenWithStackByArgs(err.Error())
+		}
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+	for _, ip := range ips {
+		for _, ipNet := range nets {
+			if ipNet.Contains(ip) {
+				return ip.String(), nil
+			}
+		}
+	}
+	return "", ErrConnectToForeignDataSource.GenWithStackByArgs(fmt.Sprintf("host '%s' is not allowed", host))
+}
+
+func (e *federatedTableRetriever) remoteError(err error) error {
+	logutil.BgLogger().Warn("failed to query the remote server of federated table",
+		zap.String("server", e.extractor.Server), zap.String("table", e.extractor.Table), zap.Error(err))
+	return ErrQueryOnForeignDataSource.GenWithStackByArgs(err.Error())
+}
+
+func (e *federatedTableRetriever) close() error {
+	var firstErr error
+	if e.rows != nil {
+		firstErr = e.rows.Close()
+	}
+	if e.db != nil {
+		if err := e.db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -21,9 +21,13 @@ import (
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/model"
+	plannercore "github.com/pingcap/tidb/planner/core"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/sqlexec"
 )
 
 // IndexAdviseExec represents a index advise executor.
@@ -123,13 +127,83 @@ func (e *IndexAdviseInfo) GetIndexAdvice(ctx context.Context, data []byte) error
 	if err := e.prepareInfo(data); err != nil {
 		return err
 	}
+	e.Result = &IndexAdvice{}
 	// TODO: Finish the index advise process. It will be done in another PR.
+	return e.evalHypoIndexes(ctx)
+}
+
+// evalHypoIndexes explains the statements of the workload with the hypothetical indexes of the session,
+// and counts the statements whose plans use each of them.
+func (e *IndexAdviseInfo) evalHypoIndexes(ctx context.Context) error {
+	if len(e.Ctx.GetSessionVars().HypoIndexes) == 0 {
+		return nil
+	}
+	exec, ok := e.Ctx.(sqlexec.SQLExecutor)
+	if !ok {
+		return errors.New("Index Advise: can't explain the statements in the session")
+	}
+	e.Result.HypoIndexUsage = make(map[int64]map[string]int)
+	for _, stmtNodes := range e.StmtNodes {
+		for _, stmtNode := range stmtNodes {
+			switch stmtNode.(type) {
+			case *ast.SelectStmt, *ast.SetOprStmt, *ast.UpdateStmt, *ast.DeleteStmt:
+			default:
+				continue
+			}
+			// The hypothetical indexes are only visible to the optimizer when explaining a statement.
+			rs, err := exec.ExecuteStmt(ctx, &ast.ExplainStmt{Stmt: stmtNode, Format: types.ExplainFormatBrief})
+			if err != nil {
+				return err
+			}
+			explain, ok := e.Ctx.GetSessionVars().StmtCtx.GetPlan().(*plannercore.Explain)
+			if rs != nil {
+				if err = rs.Close(); err != nil {
+					return err
+				}
+			}
+			if ok {
+				e.Result.addHypoIndexUsage(explain.TargetPlan)
+			}
+		}
+	}
 	return nil
 }
 
 // IndexAdvice represents the index advice.
 type IndexAdvice struct {
 	// TODO: Define index advice data structure and implements the ResultSet interface. It will be done in another PR
+
+	// HypoIndexUsage is the number of the statements of the workload using the hypothetical indexes
+	// of the session, by the table IDs and the index names.
+	HypoIndexUsage map[int64]map[string]int
+}
+
+func (a *IndexAdvice) addHypoIndexUsage(p plannercore.Plan) {
+	flat := plannercore.FlattenPhysicalPlan(p, false)
+	if flat == nil {
+		return
+	}
+	used := make(map[int64]map[string]struct{})
+	for _, tree := range append([]plannercore.FlatPlanTree{flat.Main}, flat.CTEs...) {
+		for _, op := range tree {
+			scan, ok := op.Origin.(*plannercore.PhysicalIndexScan)
+			if !ok || scan.Index.Tp != model.IndexTypeHypothetical {
+				continue
+			}
+			if used[scan.Table.ID] == nil {
+				used[scan.Table.ID] = make(map[string]struct{})
+			}
+			used[scan.Table.ID][scan.Index.Name.L] = struct{}{}
+		}
+	}
+	for tblID, names := range used {
+		if a.HypoIndexUsage[tblID] == nil {
+			a.HypoIndexUsage[tblID] = make(map[string]int)
+		}
+		for name := range names {
+			a.HypoIndexUsage[tblID][name]++
+		}
+	}
 }
 
 // IndexAdviseVarKeyType is a dummy type to avoid naming collision in context.
@@ -15,10 +15,14 @@
 package executor_test
 
 import (
+	"context"
 	"os"
 	"testing"
 
 	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/testkit"
 	"github.com/stretchr/testify/require"
@@ -68,3 +72,31 @@ func TestIndexAdvise(t *testing.T) {
 	require.Equal(t, uint64(4), ia.MaxIndexNum.PerTable)
 	require.Equal(t, uint64(5), ia.MaxIndexNum.PerDB)
 }
+
+func TestIndexAdviseHypoIndexes(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t(a int, b int, c int)")
+	tk.MustExec("create index h_a on t(a) type hypothetical")
+	tk.MustExec("create index h_b on t(b) type hypothetical")
+	tk.MustExec("create index h_c on t(c) type hypothetical")
+
+	ia := &executor.IndexAdviseInfo{
+		MaxMinutes: 1,
+		LinesInfo:  &ast.LinesClause{Terminated: ";"},
+		Ctx:        tk.Session(),
+	}
+	workload := "select * from t where a = 1;" +
+		"select * from t where a = 2 and b > 1;" +
+		"update t set c = 1 where b = 1;" +
+		"insert into t values (1, 2, 3);" +
+		"select * from t"
+	require.NoError(t, ia.GetIndexAdvice(context.Background(), []byte(workload)))
+	is := tk.Session().GetInfoSchema().(infoschema.InfoSchema)
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	require.NoError(t, err)
+	require.Equal(t, map[int64]map[string]int{tbl.Meta().ID: {"h_a": 2, "h_b": 1}}, ia.Result.HypoIndexUsage)
+	// The workload isn't executed.
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("0"))
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -50,6 +51,8 @@ import (
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessiontxn"
+	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/store/copr"
 	"github.com/pingcap/tidb/store/helper"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
@@ -99,9 +102,11 @@ func (e *memtableRetriever) retrieve(ctx context.Context, sctx sessionctx.Contex
 	// Cache the ret full rows in schemataRetriever
 	if !e.initialized {
 		is := sctx.GetInfoSchema().(infoschema.InfoSchema)
-		dbs := is.AllSchemas()
+		dbs, err := infoschema.AllSchemasWithError(is)
+		if err != nil {
+			return nil, err
+		}
 		slices.SortFunc(dbs, model.LessDBInfo)
-		var err error
 		switch e.table.Name.O {
 		case infoschema.TableSchemata:
 			e.setDataFromSchemata(sctx, dbs)
@@ -174,6 +179,16 @@ func (e *memtableRetriever) retrieve(ctx context.Context, sctx sessionctx.Contex
 			err = e.setDataForClusterTrxSummary(sctx)
 		case infoschema.TableVariablesInfo:
 			err = e.setDataForVariablesInfo(sctx)
+		case infoschema.TableTiFlashReplicaRecommendations:
+			err = e.setDataForTiFlashReplicaRecommendations(ctx, sctx, is)
+		case infoschema.TableCoprocessorCacheStats:
+			e.setDataForCoprocessorCacheStats(sctx, is)
+		case infoschema.ClusterTableCoprocessorCacheStats:
+			err = e.setDataForClusterCoprocessorCacheStats(sctx, is)
+		case infoschema.TableCardinalityFeedback:
+			e.setDataForCardinalityFeedback(sctx, is)
+		case infoschema.ClusterTableCardinalityFeedback:
+			err = e.setDataForClusterCardinalityFeedback(sctx, is)
 		}
 		if err != nil {
 			return nil, err
@@ -2252,6 +2267,224 @@ func (e *memtableRetriever) dataForTableTiFlashReplica(ctx sessionctx.Context, s
 	e.rows = rows
 }
 
+const (
+	// tiflashRecommendMinScanRatio is the minimal average ratio of the table rows scanned by one statement
+	// to recommend a TiFlash replica. Point gets and small range scans don't benefit from a columnar replica.
+	tiflashRecommendMinScanRatio = 0.1
+	tiflashRecommendSizeUnit     = float64(1 << 30)
+)
+
+// tiflashReplicaRecommendation is the cost and benefit estimation of adding a TiFlash replica to a table.
+type tiflashReplicaRecommendation struct {
+	scanRatio   float64
+	score       float64
+	recommended bool
+	reason      string
+}
+
+// recommendTiFlashReplica estimates the benefit of a TiFlash replica by the number of full table scans
+// served by TiKV, and the cost by the size of the replica.
+func recommendTiFlashReplica(stats *stmtsummary.TableScanStats, rowCount, replicaSize uint64) tiflashReplicaRecommendation {
+	var rec tiflashReplicaRecommendation
+	if rowCount == 0 {
+		rec.reason = "the table is empty or not analyzed"
+		return rec
+	}
+	if stats.ExecCount > 0 {
+		rec.scanRatio = float64(stats.SumTotalKeys) / float64(stats.ExecCount) / float64(rowCount)
+	}
+	fullScans := float64(stats.SumTotalKeys) / float64(rowCount)
+	rec.score = fullScans / math.Max(float64(replicaSize)/tiflashRecommendSizeUnit, 1)
+	if rec.scanRatio < tiflashRecommendMinScanRatio {
+		rec.reason = "the statements only scan a small part of the table"
+		return rec
+	}
+	rec.recommended = true
+	rec.reason = fmt.Sprintf("the statements scan %.0f%% of the table on average", math.Min(rec.scanRatio, 1)*100)
+	return rec
+}
+
+// setDataForTiFlashReplicaRecommendations reports the recommendations only. Applying them automatically,
+// e.g. within a storage budget, is out of scope, they are applied by the operator with
+// ALTER TABLE ... SET TIFLASH REPLICA.
+func (e *memtableRetriever) setDataForTiFlashReplicaRecommendations(ctx context.Context, sctx sessionctx.Context, is infoschema.InfoSchema) error {
+	if !hasPriv(sctx, mysql.ProcessPriv) {
+		return plannercore.ErrSpecificAccessDenied.GenWithStackByArgs("PROCESS")
+	}
+	tableRowsMap, colLengthMap, err := tableStatsCache.get(ctx, sctx)
+	if err != nil {
+		return err
+	}
+	checker := privilege.GetPrivilegeManager(sctx)
+
+	type recommendationRow struct {
+		score float64
+		row   []types.Datum
+	}
+	var recommendations []recommendationRow
+	for _, stats := range stmtsummary.StmtSummaryByDigestMap.GetTableScanStats() {
+		if util.IsMemOrSysDB(stats.Schema) {
+			continue
+		}
+		tbl, err := is.TableByName(model.NewCIStr(stats.Schema), model.NewCIStr(stats.Table))
+		if err != nil {
+			// The table may have been dropped.
+			continue
+		}
+		table := tbl.Meta()
+		if table.IsView() || table.IsSequence() || table.TempTableType != model.TempTableNone || table.TiFlashReplica != nil {
+			continue
+		}
+		if checker != nil && !checker.RequestVerification(sctx.GetSessionVars().ActiveRoles, stats.Schema, table.Name.L, "", mysql.AllPrivMask) {
+			continue
+		}
+
+		var rowCount, replicaSize uint64
+		if table.GetPartitionInfo() == nil {
+			rowCount = tableRowsMap[table.ID]
+			replicaSize, _ = getDataAndIndexLength(table, table.ID, rowCount, colLengthMap)
+		} else {
+			for _, pi := range table.GetPartitionInfo().Definitions {
+				rowCount += tableRowsMap[pi.ID]
+				parDataLen, _ := getDataAndIndexLength(table, pi.ID, tableRowsMap[pi.ID], colLengthMap)
+				replicaSize += parDataLen
+			}
+		}
+		var avgLatency uint64
+		if stats.ExecCount > 0 {
+			avgLatency = uint64(stats.SumLatency) / uint64(stats.ExecCount)
+		}
+		rec := recommendTiFlashReplica(stats, rowCount, replicaSize)
+		recommendations = append(recommendations, recommendationRow{
+			score: rec.score,
+			row: types.MakeDatums(
+				stats.Schema,       // TABLE_SCHEMA
+				table.Name.O,       // TABLE_NAME
+				table.ID,           // TABLE_ID
+				stats.ExecCount,    // EXEC_COUNT
+				stats.SumTotalKeys, // SUM_SCAN_KEYS
+				avgLatency,         // AVG_LATENCY
+				rowCount,           // TABLE_ROWS
+				replicaSize,        // ESTIMATED_REPLICA_SIZE
+				rec.scanRatio,      // AVG_SCAN_RATIO
+				rec.score,          // BENEFIT_SCORE
+				rec.recommended,    // RECOMMENDED
+				rec.reason,         // REASON
+			),
+		})
+	}
+	// Show the most beneficial tables first.
+	slices.SortStableFunc(recommendations, func(i, j recommendationRow) bool {
+		return i.score > j.score
+	})
+	rows := make([][]types.Datum, 0, len(recommendations))
+	for _, rec := range recommendations {
+		rows = append(rows, rec.row)
+	}
+	e.rows = rows
+	return nil
+}
+
+func (e *memtableRetriever) setDataForCoprocessorCacheStats(sctx sessionctx.Context, is infoschema.InfoSchema) {
+	checker := privilege.GetPrivilegeManager(sctx)
+	var rows [][]types.Datum
+	for _, stats := range copr.GetCoprCacheTableStats() {
+		var partitionName string
+		tbl, db, def := is.FindTableByPartitionID(stats.PhysicalID)
+		if tbl != nil {
+			partitionName = def.Name.O
+		} else if tbl, _ = is.TableByID(stats.PhysicalID); tbl != nil {
+			db, _ = is.SchemaByTable(tbl.Meta())
+		}
+		if tbl == nil || db == nil {
+			// The table may have been dropped.
+			continue
+		}
+		table := tbl.Meta()
+		if checker != nil && !checker.RequestVerification(sctx.GetSessionVars().ActiveRoles, db.Name.L, table.Name.L, "", mysql.AllPrivMask) {
+			continue
+		}
+		var hitRatio float64
+		if stats.Requests > 0 {
+			hitRatio = float64(stats.Hits) / float64(stats.Requests)
+		}
+		rows = append(rows, types.MakeDatums(
+			db.Name.O,                // TABLE_SCHEMA
+			table.Name.O,             // TABLE_NAME
+			partitionName,            // PARTITION_NAME
+			stats.PhysicalID,         // TIDB_TABLE_ID
+			!table.CoprCacheDisabled, // CACHE_ENABLED
+			stats.Requests,           // REQUESTS
+			stats.Hits,               // HITS
+			hitRatio,                 // HIT_RATIO
+			stats.Admissions,         // ADMISSIONS
+		))
+	}
+	e.rows = rows
+}
+
+func (e *memtableRetriever) setDataForClusterCoprocessorCacheStats(sctx sessionctx.Context, is infoschema.InfoSchema) error {
+	e.setDataForCoprocessorCacheStats(sctx, is)
+	rows, err := infoschema.AppendHostInfoToRows(sctx, e.rows)
+	if err != nil {
+		return err
+	}
+	e.rows = rows
+	return nil
+}
+
+func (e *memtableRetriever) setDataForCardinalityFeedback(sctx sessionctx.Context, is infoschema.InfoSchema) {
+	checker := privilege.GetPrivilegeManager(sctx)
+	loc := sctx.GetSessionVars().Location()
+	var rows [][]types.Datum
+	for _, fb := range statistics.GetCardinalityFeedback() {
+		tbl, ok := is.TableByID(fb.TableID)
+		if !ok {
+			// The table may have been dropped.
+			continue
+		}
+		db, ok := is.SchemaByTable(tbl.Meta())
+		if !ok {
+			continue
+		}
+		table := tbl.Meta()
+		if checker != nil && !checker.RequestVerification(sctx.GetSessionVars().ActiveRoles, db.Name.L, table.Name.L, "", mysql.AllPrivMask) {
+			continue
+		}
+		colNames := make([]string, 0, len(fb.ColumnIDs))
+		for _, id := range fb.ColumnIDs {
+			if col := model.FindColumnInfoByID(table.Columns, id); col != nil {
+				colNames = append(colNames, col.Name.O)
+			} else if id == model.ExtraHandleID {
+				colNames = append(colNames, model.ExtraHandleName.O)
+			}
+		}
+		rows = append(rows, types.MakeDatums(
+			db.Name.O,                   // TABLE_SCHEMA
+			table.Name.O,                // TABLE_NAME
+			fb.TableID,                  // TIDB_TABLE_ID
+			strings.Join(colNames, ","), // COLUMN_NAMES
+			fb.StatsVersion,             // STATS_VERSION
+			fb.Factor,                   // CORRECTION_FACTOR
+			fb.Samples,                  // SAMPLES
+			fb.LastEstRows,              // LAST_EST_ROWS
+			fb.LastActRows,              // LAST_ACT_ROWS
+			types.NewTime(types.FromGoTime(fb.LastUpdate.In(loc)), mysql.TypeDatetime, types.DefaultFsp), // LAST_UPDATE_TIME
+		))
+	}
+	e.rows = rows
+}
+
+func (e *memtableRetriever) setDataForClusterCardinalityFeedback(sctx sessionctx.Context, is infoschema.InfoSchema) error {
+	e.setDataForCardinalityFeedback(sctx, is)
+	rows, err := infoschema.AppendHostInfoToRows(sctx, e.rows)
+	if err != nil {
+		return err
+	}
+	e.rows = rows
+	return nil
+}
+
 func (e *memtableRetriever) setDataForStatementsSummaryEvicted(ctx sessionctx.Context) error {
 	if !hasPriv(ctx, mysql.ProcessPriv) {
 		return plannercore.ErrSpecificAccessDenied.GenWithStackByArgs("PROCESS")
@@ -2871,7 +3104,10 @@ func (e *hugeMemTableRetriever) retrieve(ctx context.Context, sctx sessionctx.Co
 
 	if !e.initialized {
 		is := sctx.GetInfoSchema().(infoschema.InfoSchema)
-		dbs := is.AllSchemas()
+		dbs, err := infoschema.AllSchemasWithError(is)
+		if err != nil {
+			return nil, err
+		}
 		slices.SortFunc(dbs, func(i, j *model.DBInfo) bool {
 			return i.Name.L < j.Name.L
 		})
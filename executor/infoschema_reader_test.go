@@ -28,11 +28,16 @@ import (
 	"github.com/pingcap/tidb/parser/auth"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/statistics/handle"
 	"github.com/pingcap/tidb/testkit"
+	"github.com/pingcap/tidb/util/execdetails"
+	"github.com/pingcap/tidb/util/stmtsummary"
 	"github.com/pingcap/tidb/util/stringutil"
 	"github.com/stretchr/testify/require"
+	"github.com/tikv/client-go/v2/util"
 )
 
 func TestInspectionTables(t *testing.T) {
@@ -636,6 +641,175 @@ func TestForTableTiFlashReplica(t *testing.T) {
 	tk.MustQuery("select TABLE_SCHEMA,TABLE_NAME,REPLICA_COUNT,LOCATION_LABELS,AVAILABLE,PROGRESS from information_schema.tiflash_replica").Check(testkit.Rows("test t 2 a,b 1 1"))
 }
 
+func TestTiFlashReplicaRecommendations(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	oldExpiryTime := executor.TableStatsCacheExpiry
+	executor.TableStatsCacheExpiry = 0
+	defer func() { executor.TableStatsCacheExpiry = oldExpiryTime }()
+	h := dom.StatsHandle()
+	tk := testkit.NewTestKit(t, store)
+	require.NoError(t, tk.Session().Auth(&auth.UserIdentity{Username: "root", Hostname: "%"}, nil, nil))
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, b varchar(10))")
+	tk.MustExec("create table t1 (a int)")
+	require.NoError(t, h.HandleDDLEvent(<-h.DDLEventCh()))
+	require.NoError(t, h.HandleDDLEvent(<-h.DDLEventCh()))
+	tk.MustExec("insert into t values (1, 'a'), (2, 'b'), (3, 'c')")
+	require.NoError(t, h.DumpStatsDeltaToKV(handle.DumpAll))
+	require.NoError(t, h.Update(dom.InfoSchema()))
+
+	tk.MustExec("set global tidb_enable_stmt_summary = 0")
+	tk.MustExec("set global tidb_enable_stmt_summary = 1")
+	tk.MustQuery("select * from t where a > 1")
+	tk.MustQuery("select count(*) from t")
+	tk.MustQuery("select * from t1")
+	tk.MustExec("update t set b = 'd' where a = 1")
+	tk.MustQuery("select table_name, exec_count, table_rows, estimated_replica_size, recommended, reason from information_schema.tiflash_replica_recommendations where table_schema = 'test' order by table_name").Check(testkit.Rows(
+		"t 2 3 30 0 the statements only scan a small part of the table",
+		"t1 1 0 0 0 the table is empty or not analyzed",
+	))
+
+	// The mock store doesn't report the scanned keys, so the statements scanning the tables are added
+	// to the statement summary directly.
+	addSelectStmt := func(digest string, tables []stmtctx.TableEntry, tableScanKeys map[string]int64) {
+		var totalKeys int64
+		for _, keys := range tableScanKeys {
+			totalKeys += keys
+		}
+		stmtsummary.StmtSummaryByDigestMap.AddStatement(&stmtsummary.StmtExecInfo{
+			SchemaName:    "test",
+			NormalizedSQL: digest,
+			Digest:        digest,
+			PlanGenerator: func() (string, string) { return "", "" },
+			User:          "root",
+			TotalLatency:  time.Millisecond,
+			StmtCtx:       &stmtctx.StatementContext{StmtType: "Select", Tables: tables},
+			CopTasks:      &stmtctx.CopTasksDetails{},
+			ExecDetail:    &execdetails.ExecDetails{ScanDetail: &util.ScanDetail{TotalKeys: totalKeys}},
+			StartTime:     time.Now(),
+			Succeed:       true,
+			TableScanKeys: tableScanKeys,
+		})
+	}
+	// The full table scans make the table cross the threshold.
+	addSelectStmt("full_scan", []stmtctx.TableEntry{{DB: "test", Table: "t"}}, map[string]int64{"test.t": 3})
+	addSelectStmt("full_scan", []stmtctx.TableEntry{{DB: "test", Table: "t"}}, map[string]int64{"test.t": 3})
+	tk.MustQuery("select table_name, exec_count, sum_scan_keys, avg_scan_ratio, recommended, reason from information_schema.tiflash_replica_recommendations where table_schema = 'test' order by table_name").Check(testkit.Rows(
+		"t 4 6 0.5 1 the statements scan 50% of the table on average",
+		"t1 1 0 0 0 the table is empty or not analyzed",
+	))
+	// The keys scanned by a join are split by the tables.
+	addSelectStmt("join", []stmtctx.TableEntry{{DB: "test", Table: "t"}, {DB: "test", Table: "t1"}}, map[string]int64{"test.t": 3, "test.t1": 2})
+	tk.MustQuery("select table_name, exec_count, sum_scan_keys, avg_scan_ratio, recommended, reason from information_schema.tiflash_replica_recommendations where table_schema = 'test' order by table_name").Check(testkit.Rows(
+		"t 5 9 0.6 1 the statements scan 60% of the table on average",
+		"t1 2 2 0 0 the table is empty or not analyzed",
+	))
+}
+
+func TestCoprocessorCacheStats(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, b int) coprocessor_cache = 0")
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  `b` int(11) DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin /*T![copr_cache] COPROCESSOR_CACHE=0 */"))
+	tk.MustExec("insert into t values (1, 1), (2, 2)")
+	tk.MustQuery("select sum(a) from t").Check(testkit.Rows("3"))
+	tk.MustQuery("select * from information_schema.coprocessor_cache_stats where table_schema = 'test' and table_name = 't'").Check(testkit.Rows())
+
+	tk.MustExec("alter table t coprocessor_cache = 1")
+	tk.MustQuery("select sum(a) from t").Check(testkit.Rows("3"))
+	tk.MustQuery("select sum(b) from t").Check(testkit.Rows("3"))
+	tk.MustQuery("select table_name, partition_name, cache_enabled, requests from information_schema.coprocessor_cache_stats where table_schema = 'test'").Check(testkit.Rows(
+		"t  1 2",
+	))
+
+	tk.MustExec("alter table t coprocessor_cache = 0")
+	tk.MustQuery("select sum(a) from t").Check(testkit.Rows("3"))
+	tk.MustQuery("select table_name, cache_enabled, requests from information_schema.coprocessor_cache_stats where table_schema = 'test'").Check(testkit.Rows(
+		"t 0 2",
+	))
+
+	tk.MustExec("create table tp (a int) partition by hash(a) partitions 2")
+	tk.MustExec("insert into tp values (1), (2)")
+	tk.MustQuery("select sum(a) from tp partition (p0)").Check(testkit.Rows("2"))
+	tk.MustQuery("select sum(a) from tp partition (p1)").Check(testkit.Rows("1"))
+	tk.MustQuery("select table_name, partition_name, cache_enabled, requests, hits, hit_ratio from information_schema.coprocessor_cache_stats where table_name = 'tp' order by partition_name").Check(testkit.Rows(
+		"tp p0 1 1 0 0",
+		"tp p1 1 1 0 0",
+	))
+}
+
+func TestCardinalityFeedback(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	statistics.ResetCardinalityFeedback()
+	defer statistics.ResetCardinalityFeedback()
+	tk.MustExec("use test")
+	tk.MustExec("create table t (a int, b int)")
+	values := make([]string, 0, 200)
+	for i := 0; i < 200; i++ {
+		values = append(values, fmt.Sprintf("(1, %d)", i))
+	}
+	tk.MustExec("insert into t values " + strings.Join(values, ","))
+
+	// the pseudo stats underestimate the rows of a = 1.
+	require.Len(t, tk.MustQuery("select * from t where a = 1").Rows(), 200)
+	tk.MustQuery("select * from information_schema.cardinality_feedback where table_name = 't'").Check(testkit.Rows())
+
+	tk.MustExec("set @@tidb_opt_enable_cardinality_feedback = 1")
+	estRowsOf := func(cond string) float64 {
+		rows := tk.MustQuery("explain format = 'brief' select * from t where " + cond).Rows()
+		est, err := strconv.ParseFloat(rows[0][1].(string), 64)
+		require.NoError(t, err)
+		return est
+	}
+	estRows := func() float64 { return estRowsOf("a = 1") }
+	before := estRows()
+	require.Len(t, tk.MustQuery("select * from t where a = 1").Rows(), 200)
+	tk.MustQuery("select table_schema, table_name, column_names, samples, last_act_rows from information_schema.cardinality_feedback").Check(testkit.Rows(
+		"test t a 1 200",
+	))
+	tk.MustQuery("select correction_factor > 1 from information_schema.cardinality_feedback where table_name = 't'").Check(testkit.Rows("1"))
+	after := estRows()
+	require.Greater(t, after, before)
+
+	// the reader under a limit may not be exhausted.
+	tk.MustQuery("select * from t where a = 1 limit 1")
+	tk.MustQuery("select samples from information_schema.cardinality_feedback where table_name = 't'").Check(testkit.Rows("1"))
+
+	// the estimation approaches the actual row count.
+	for i := 0; i < 20; i++ {
+		tk.MustQuery("select * from t where a = 1")
+	}
+	require.InDelta(t, 200, estRows(), 20)
+
+	// the filters on the other columns aren't corrected.
+	require.Equal(t, before, estRowsOf("b = 1"))
+
+	// the filters kept in the root selection are corrected along with the pushed down ones.
+	tk.MustExec("insert into mysql.expr_pushdown_blacklist values('gt', 'tikv', '')")
+	tk.MustExec("admin reload expr_pushdown_blacklist")
+	defer func() {
+		tk.MustExec("delete from mysql.expr_pushdown_blacklist")
+		tk.MustExec("admin reload expr_pushdown_blacklist")
+	}()
+	plan := tk.MustQuery("explain format = 'brief' select * from t where a = 1 and b > -1").Rows()
+	require.True(t, strings.HasPrefix(plan[0][0].(string), "Selection"))
+	require.Equal(t, "root", plan[0][2])
+	before = estRowsOf("a = 1 and b > -1")
+	require.Len(t, tk.MustQuery("select * from t where a = 1 and b > -1").Rows(), 200)
+	tk.MustQuery("select samples, last_act_rows from information_schema.cardinality_feedback where column_names = 'a,b'").Check(testkit.Rows(
+		"1 200",
+	))
+	require.Greater(t, estRowsOf("a = 1 and b > -1"), before)
+
+	tk.MustExec("set @@tidb_opt_enable_cardinality_feedback = 0")
+	require.Equal(t, before, estRowsOf("a = 1 and b > -1"))
+}
+
 func TestSequences(t *testing.T) {
 	store := testkit.CreateMockStore(t)
 	tk := testkit.NewTestKit(t, store)
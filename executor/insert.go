@@ -46,6 +46,8 @@ type InsertExec struct {
 	row4Update     []types.Datum
 
 	Priority mysql.PriorityEnum
+	// UseIngest indicates the rows of INSERT ... SELECT are ingested into the table if it's empty.
+	UseIngest bool
 }
 
 func (e *InsertExec) exec(ctx context.Context, rows [][]types.Datum) error {
@@ -308,6 +310,15 @@ func (e *InsertExec) Next(ctx context.Context, req *chunk.Chunk) error {
 	}
 
 	if len(e.children) > 0 && e.children[0] != nil {
+		if e.UseIngest {
+			ingester, err := e.newIngester(ctx)
+			if err != nil {
+				return err
+			}
+			if ingester != nil {
+				return e.insertRowsByIngest(ctx, ingester)
+			}
+		}
 		return insertRowsFromSelect(ctx, e)
 	}
 	return insertRows(ctx, e)
@@ -35,6 +35,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessiontxn"
+	derr "github.com/pingcap/tidb/store/driver/error"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
@@ -45,6 +46,7 @@ import (
 	"github.com/pingcap/tidb/util/execdetails"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/memory"
+	"github.com/tikv/client-go/v2/tikv"
 	"github.com/tikv/client-go/v2/txnkv/txnsnapshot"
 	"go.uber.org/zap"
 )
@@ -91,6 +93,8 @@ type InsertValues struct {
 	rowLen int
 
 	stats *InsertRuntimeStat
+	// splitRegions records the regions which the batches are split by.
+	splitRegions map[uint64]struct{}
 
 	// isLoadData indicates whatever current goroutine is use for generating batch data. LoadData use two goroutines. One for generate batch data,
 	// The other one for commit task, which will invalid txn.
@@ -234,6 +238,7 @@ func insertRows(ctx context.Context, base insertCommon) (err error) {
 	sessVars := e.ctx.GetSessionVars()
 	batchSize := sessVars.DMLBatchSize
 	batchInsert := sessVars.BatchInsert && !sessVars.InTxn() && variable.EnableBatchDML.Load() && batchSize > 0
+	splitByRegion := batchInsert && sessVars.BatchInsertSplitByRegion
 
 	e.lazyFillAutoID = true
 	evalRowFunc := e.fastEvalRow
@@ -260,7 +265,7 @@ func insertRows(ctx context.Context, base insertCommon) (err error) {
 			if err != nil {
 				return err
 			}
-			if err = base.exec(ctx, rows); err != nil {
+			if err = e.execBatch(ctx, base, rows, splitByRegion); err != nil {
 				return err
 			}
 			rows = rows[:0]
@@ -280,7 +285,7 @@ func insertRows(ctx context.Context, base insertCommon) (err error) {
 	if err != nil {
 		return err
 	}
-	err = base.exec(ctx, rows)
+	err = e.execBatch(ctx, base, rows, splitByRegion)
 	if err != nil {
 		return err
 	}
@@ -528,6 +533,96 @@ func insertRowsFromSelect(ctx context.Context, base insertCommon) error {
 	return nil
 }
 
+// execBatch executes a batch of rows. If splitByRegion is true, the rows are
+// grouped by the regions they're written to, and every group is committed in
+// its own transaction except the last one, which is committed by the caller.
+func (e *InsertValues) execBatch(ctx context.Context, base insertCommon, rows [][]types.Datum, splitByRegion bool) error {
+	if !splitByRegion {
+		return base.exec(ctx, rows)
+	}
+	groups, err := e.groupRowsByRegion(ctx, rows)
+	if err != nil {
+		return err
+	}
+	for i, group := range groups {
+		if i > 0 {
+			if err := e.doBatchInsert(ctx); err != nil {
+				return err
+			}
+		}
+		if err := base.exec(ctx, group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupRowsByRegion groups the rows by the regions which their records are
+// written to, and the rows keep their order in each group. The rows are not
+// grouped if their handles are unknown before being added, i.e. the table uses
+// _tidb_rowid, or the store has no regions. The regions are taken from the region
+// cache, so the grouping may follow the stale regions until the cache is updated.
+func (e *InsertValues) groupRowsByRegion(ctx context.Context, rows [][]types.Datum) ([][][]types.Datum, error) {
+	store, ok := e.ctx.GetStore().(tikv.Storage)
+	handleCols, pkIdxInfo := getHandleCols(e.Table)
+	if !ok || len(rows) <= 1 || len(handleCols) == 0 {
+		return [][][]types.Datum{rows}, nil
+	}
+	sc := e.ctx.GetSessionVars().StmtCtx
+	cache := store.GetRegionCache()
+	bo := tikv.NewBackofferWithVars(ctx, 500, nil)
+	var (
+		loc      *tikv.KeyLocation
+		groups   [][][]types.Datum
+		groupIdx = make(map[uint64]int)
+	)
+	for _, row := range rows {
+		t := e.Table
+		if p, ok := t.(table.PartitionedTable); ok {
+			var err error
+			if t, err = p.GetPartitionByRow(e.ctx, row); err != nil {
+				// leave the error to be handled when the rows are added.
+				return [][][]types.Datum{rows}, nil
+			}
+		}
+		var handle kv.Handle
+		if pkIdxInfo != nil {
+			var err error
+			if handle, err = buildHandleFromDatumRow(sc, row, handleCols, pkIdxInfo); err != nil {
+				return nil, err
+			}
+		} else {
+			handle = kv.IntHandle(row[handleCols[0].Offset].GetInt64())
+		}
+		key := tablecodec.EncodeRecordKey(t.RecordPrefix(), handle)
+		if loc == nil || !loc.Contains(key) {
+			var err error
+			if loc, err = cache.LocateKey(bo, key); err != nil {
+				return nil, derr.ToTiDBErr(err)
+			}
+		}
+		regionID := loc.Region.GetID()
+		idx, ok := groupIdx[regionID]
+		if !ok {
+			idx = len(groups)
+			groupIdx[regionID] = idx
+			groups = append(groups, nil)
+		}
+		groups[idx] = append(groups[idx], row)
+	}
+	if e.collectRuntimeStatsEnabled() {
+		if e.splitRegions == nil {
+			e.splitRegions = make(map[uint64]struct{})
+		}
+		for regionID := range groupIdx {
+			e.splitRegions[regionID] = struct{}{}
+		}
+		e.stats.SplitRegions = len(e.splitRegions)
+		e.stats.SplitBatches += len(groups)
+	}
+	return groups, nil
+}
+
 func (e *InsertValues) doBatchInsert(ctx context.Context) error {
 	txn, err := e.ctx.Txn(false)
 	if err != nil {
@@ -1275,9 +1370,25 @@ type InsertRuntimeStat struct {
 	*autoid.AllocatorRuntimeStats
 	CheckInsertTime time.Duration
 	Prefetch        time.Duration
+	// SplitRegions and SplitBatches are the number of the regions and the batches
+	// which the rows are split into by tidb_batch_insert_split_by_region.
+	SplitRegions int
+	SplitBatches int
 }
 
 func (e *InsertRuntimeStat) String() string {
+	str := e.string()
+	if e.SplitBatches == 0 {
+		return str
+	}
+	split := fmt.Sprintf("region_split: {regions: %d, batches: %d}", e.SplitRegions, e.SplitBatches)
+	if len(str) == 0 {
+		return split
+	}
+	return str + ", " + split
+}
+
+func (e *InsertRuntimeStat) string() string {
 	buf := bytes.NewBuffer(make([]byte, 0, 32))
 	var allocatorStatsStr string
 	if e.AllocatorRuntimeStats != nil {
@@ -1334,6 +1445,8 @@ func (e *InsertRuntimeStat) Clone() execdetails.RuntimeStats {
 	newRs := &InsertRuntimeStat{
 		CheckInsertTime: e.CheckInsertTime,
 		Prefetch:        e.Prefetch,
+		SplitRegions:    e.SplitRegions,
+		SplitBatches:    e.SplitBatches,
 	}
 	if e.SnapshotRuntimeStats != nil {
 		snapshotStats := e.SnapshotRuntimeStats.Clone()
@@ -1380,6 +1493,8 @@ func (e *InsertRuntimeStat) Merge(other execdetails.RuntimeStats) {
 	}
 	e.Prefetch += tmp.Prefetch
 	e.CheckInsertTime += tmp.CheckInsertTime
+	e.SplitRegions += tmp.SplitRegions
+	e.SplitBatches += tmp.SplitBatches
 }
 
 // Tp implements the RuntimeStats interface.
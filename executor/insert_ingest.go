@@ -0,0 +1,201 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/failpoint"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/format"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+// InsertIngester ingests the rows of INSERT ... SELECT into an empty table as sorted KV
+// files, instead of writing them in the transaction.
+type InsertIngester interface {
+	// Write encodes the rows, which are in the order of the table columns, and writes them.
+	Write(ctx context.Context, rows [][]types.Datum) error
+	// Finish imports the written rows into the table.
+	Finish(ctx context.Context) error
+	// Close releases the resources of the ingester, the rows not imported are discarded.
+	Close(ctx context.Context)
+}
+
+// InsertIngesterBuilder creates the InsertIngester of the table. It returns nil if the rows
+// can't be ingested, in which case they are inserted in the transaction.
+type InsertIngesterBuilder func(ctx context.Context, sctx sessionctx.Context, tbl table.Table) (InsertIngester, error)
+
+var insertIngesterBuilder InsertIngesterBuilder
+
+// RegisterInsertIngesterBuilder registers the builder of the InsertIngester. It must be called
+// before the server starts, the rows of INSERT ... SELECT are never ingested if it's not called.
+func RegisterInsertIngesterBuilder(builder InsertIngesterBuilder) {
+	insertIngesterBuilder = builder
+}
+
+// newIngester creates the ingester of the INSERT ... SELECT if the rows can be ingested
+// into the table. The rows are ingested only if the statement is autocommit and the table
+// is empty, since they overwrite the existing rows and can't be rolled back. The table must
+// also be created by the session, and it's locked with the WRITE lock until the ingest
+// finishes, so the rows written and the DDL executed by the other sessions are rejected
+// instead of being overwritten by the ingested rows. Otherwise, nil is returned and the
+// rows are inserted in the transaction.
+func (e *InsertExec) newIngester(ctx context.Context) (InsertIngester, error) {
+	sessVars := e.ctx.GetSessionVars()
+	if sessVars.InTxn() || !sessVars.IsAutocommit() || sessVars.BinlogClient != nil {
+		return nil, nil
+	}
+	if _, ok := sessVars.CreatedTables[e.Table.Meta().ID]; !ok {
+		return nil, nil
+	}
+	// the session's own table locks would be released by locking the table.
+	if !config.TableLockEnabled() || e.ctx.HasLockedTables() {
+		return nil, nil
+	}
+	locked, err := e.lockIngestTable()
+	if err != nil || !locked {
+		return nil, err
+	}
+	ingester, err := e.newIngesterWithLock(ctx)
+	if err != nil || ingester == nil {
+		e.unlockIngestTable()
+		return nil, err
+	}
+	return ingester, nil
+}
+
+func (e *InsertExec) newIngesterWithLock(ctx context.Context) (InsertIngester, error) {
+	empty, err := e.isTableEmpty()
+	if err != nil || !empty {
+		return nil, err
+	}
+	failpoint.Inject("mockInsertIngestError", func() {
+		failpoint.Return(nil, errors.New("mock insert ingest error"))
+	})
+	if insertIngesterBuilder == nil {
+		return nil, nil
+	}
+	return insertIngesterBuilder(ctx, e.ctx, e.Table)
+}
+
+// lockIngestTable locks the table with the WRITE lock. It returns false if the table is
+// locked by the other sessions, in which case the rows are inserted in the transaction.
+func (e *InsertExec) lockIngestTable() (bool, error) {
+	is := e.ctx.GetInfoSchema().(infoschema.InfoSchema)
+	db, ok := is.SchemaByTable(e.Table.Meta())
+	if !ok {
+		return false, nil
+	}
+	stmt := &ast.LockTablesStmt{TableLocks: []ast.TableLock{{
+		Table: &ast.TableName{Schema: db.Name, Name: e.Table.Meta().Name},
+		Type:  model.TableLockWrite,
+	}}}
+	// the query of the DDL job is the LOCK TABLES instead of the INSERT.
+	var sb strings.Builder
+	if err := stmt.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		return false, errors.Trace(err)
+	}
+	query := e.ctx.Value(sessionctx.QueryString)
+	e.ctx.SetValue(sessionctx.QueryString, sb.String())
+	defer e.ctx.SetValue(sessionctx.QueryString, query)
+	err := domain.GetDomain(e.ctx).DDL().LockTables(e.ctx, stmt)
+	if infoschema.ErrTableLocked.Equal(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (e *InsertExec) unlockIngestTable() {
+	lockedTables := e.ctx.GetAllTableLocks()
+	if err := domain.GetDomain(e.ctx).DDL().UnlockTables(e.ctx, lockedTables); err != nil {
+		logutil.BgLogger().Warn("unlock the table of the insert ingest failed",
+			zap.Uint64("conn", e.ctx.GetSessionVars().ConnectionID), zap.Error(err))
+	}
+}
+
+// isTableEmpty checks the table in the latest snapshot, since the rows may be written by
+// the other sessions after the statement starts and before the table is locked.
+func (e *InsertExec) isTableEmpty() (bool, error) {
+	store := e.ctx.GetStore()
+	ver, err := store.CurrentVersion(kv.GlobalTxnScope)
+	if err != nil {
+		return false, err
+	}
+	prefix := tablecodec.GenTableRecordPrefix(e.Table.Meta().ID)
+	it, err := store.GetSnapshot(ver).Iter(prefix, prefix.PrefixNext())
+	if err != nil {
+		return false, err
+	}
+	defer it.Close()
+	return !it.Valid() || !it.Key().HasPrefix(prefix), nil
+}
+
+// insertRowsByIngest reads the rows of the SELECT and ingests them into the table.
+func (e *InsertExec) insertRowsByIngest(ctx context.Context, ingester InsertIngester) error {
+	defer e.unlockIngestTable()
+	defer ingester.Close(ctx)
+	selectExec := e.children[0]
+	fields := retTypes(selectExec)
+	chk := newFirstChunk(selectExec)
+	iter := chunk.NewIterator4Chunk(chk)
+	rows := make([][]types.Datum, 0, chk.Capacity())
+	e.ctx.GetTxnWriteThroughputSLI().SetInvalid()
+	for {
+		if err := Next(ctx, selectExec, chk); err != nil {
+			return err
+		}
+		if chk.NumRows() == 0 {
+			break
+		}
+		chkMemUsage := chk.MemoryUsage()
+		e.memTracker.Consume(chkMemUsage)
+		for innerChunkRow := iter.Begin(); innerChunkRow != iter.End(); innerChunkRow = iter.Next() {
+			innerRow := innerChunkRow.GetDatumRow(fields)
+			e.rowCount++
+			row, err := e.getRow(ctx, innerRow)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, row)
+		}
+		if err := ingester.Write(ctx, rows); err != nil {
+			return err
+		}
+		rows = rows[:0]
+		e.memTracker.Consume(-chkMemUsage)
+	}
+	if err := ingester.Finish(ctx); err != nil {
+		return err
+	}
+
+	sessVars := e.ctx.GetSessionVars()
+	sessVars.StmtCtx.AddAffectedRows(e.rowCount)
+	sessVars.TxnCtx.UpdateDeltaForTable(e.Table.Meta().ID, int64(e.rowCount), int64(e.rowCount), map[int64]int64{})
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/pingcap/failpoint"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/errno"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/meta/autoid"
@@ -1532,6 +1533,45 @@ func TestInsertRuntimeStat(t *testing.T) {
 	require.Equal(t, stats.Clone().String(), stats.String())
 	stats.Merge(stats.Clone())
 	require.Equal(t, "prepare: 6s, check_insert: {total_time: 4s, mem_insert_time: 2s, prefetch: 2s}", stats.String())
+	stats.SplitRegions, stats.SplitBatches = 2, 3
+	require.Equal(t, "prepare: 6s, check_insert: {total_time: 4s, mem_insert_time: 2s, prefetch: 2s}, region_split: {regions: 2, batches: 3}", stats.String())
+}
+
+func TestBatchInsertSplitByRegion(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("set global tidb_enable_batch_dml = 1")
+	defer tk.MustExec("set global tidb_enable_batch_dml = 0")
+	tk.MustExec("create table t (a int primary key, b int)")
+	tk.MustQuery("split table t by (1000), (2000), (3000)").Check(testkit.Rows("3 1"))
+	tk.MustExec("set @@tidb_batch_insert = 1, @@tidb_dml_batch_size = 8, @@tidb_batch_insert_split_by_region = 1")
+	// load the split regions into the region cache.
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("0"))
+
+	// every batch of 8 rows writes all the 4 regions.
+	values := make([]string, 0, 40)
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 4; j++ {
+			values = append(values, fmt.Sprintf("(%d, %d)", j*1000+i, i))
+		}
+	}
+	rows := tk.MustQuery("explain analyze insert into t values " + strings.Join(values, ", ")).Rows()
+	require.Contains(t, rows[0][5], "region_split: {regions: 4, batches: 20}")
+	tk.MustQuery("select count(*), sum(a), sum(b) from t").Check(testkit.Rows("40 60180 180"))
+
+	// the rows with unknown handles are not split.
+	tk.MustExec("create table t2 (a int, b int)")
+	rows = tk.MustQuery("explain analyze insert into t2 values " + strings.Join(values, ", ")).Rows()
+	require.NotContains(t, rows[0][5], "region_split")
+	tk.MustQuery("select count(*) from t2").Check(testkit.Rows("40"))
+
+	tk.MustExec("set @@tidb_batch_insert_split_by_region = 0")
+	tk.MustExec("truncate table t")
+	tk.MustQuery("split table t by (1000), (2000), (3000)").Check(testkit.Rows("3 1"))
+	rows = tk.MustQuery("explain analyze insert into t values " + strings.Join(values, ", ")).Rows()
+	require.NotContains(t, rows[0][5], "region_split")
+	tk.MustQuery("select count(*), sum(a), sum(b) from t").Check(testkit.Rows("40 60180 180"))
 }
 
 func TestDuplicateEntryMessage(t *testing.T) {
@@ -1940,3 +1980,81 @@ func TestIssue32213(t *testing.T) {
 	tk.MustQuery("select cast(test.t1.c1 as decimal(5, 3)) from test.t1").Check(testkit.Rows("99.999"))
 	tk.MustQuery("select cast(test.t1.c1 as decimal(6, 3)) from test.t1").Check(testkit.Rows("100.000"))
 }
+
+func TestInsertSelectIngest(t *testing.T) {
+	defer config.RestoreFunc()()
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.EnableTableLock = true
+	})
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table s(a int, b int)")
+	tk.MustExec("insert into s values (1, 10), (2, 20), (3, 30)")
+	tk.MustExec("create table t(a int, b int, key(b))")
+	tk.MustExec("create table u(a int, b int, unique key(b))")
+
+	require.NoError(t, failpoint.Enable("github.com/pingcap/tidb/executor/mockInsertIngestError", "return"))
+	// the ingest is disabled by default.
+	tk.MustExec("insert into t select * from s")
+	tk.MustExec("delete from t")
+
+	tk.MustExec("set @@tidb_insert_select_ingest_threshold = 1")
+	tk.MustGetErrMsg("insert into t select * from s", "mock insert ingest error")
+	// the rows are checked against the unique keys.
+	tk.MustExec("insert into u select * from s")
+	tk.MustExec("insert ignore into t select * from s where a = 1")
+	// the table is not empty.
+	tk.MustExec("insert into t select * from s where a > 1")
+	tk.MustQuery("select * from t order by a").Check(testkit.Rows("1 10", "2 20", "3 30"))
+	tk.MustExec("delete from t")
+	// the rows can't be rolled back.
+	tk.MustExec("begin")
+	tk.MustExec("insert into t select * from s")
+	tk.MustExec("rollback")
+	// the table isn't created by the session.
+	tk2 := testkit.NewTestKit(t, store)
+	tk2.MustExec("use test")
+	tk2.MustExec("set @@tidb_insert_select_ingest_threshold = 1")
+	tk2.MustExec("insert into t select * from s")
+	tk2.MustExec("delete from t")
+	tk2.MustExec("truncate table t")
+	tk.MustExec("insert into t select * from s")
+	tk.MustExec("drop table t")
+	tk2.MustExec("create table t(a int, b int, key(b))")
+	tk.MustExec("insert into t select * from s")
+	tk.MustExec("drop table t")
+	tk.MustExec("create table t(a int, b int, key(b))")
+	tk.MustExec("set @@tidb_insert_select_ingest_threshold = 100000")
+	tk.MustExec("insert into t select * from s")
+	tk.MustExec("delete from t")
+	tk.MustExec("set @@tidb_insert_select_ingest_threshold = 1")
+
+	// the table is locked during the ingest, and unlocked after it.
+	tk.MustGetErrMsg("insert into t select * from s", "mock insert ingest error")
+	require.False(t, tk.Session().HasLockedTables())
+	tk2.MustExec("insert into t select * from s")
+	tk2.MustExec("delete from t")
+	// the table locks of the session would be released by the ingest.
+	tk.MustExec("lock tables t write, s read")
+	tk.MustExec("insert into t select * from s")
+	require.True(t, tk.Session().HasLockedTables())
+	tk.MustExec("unlock tables")
+	tk.MustExec("delete from t")
+	// the ingest isn't isolated without the table locks.
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.EnableTableLock = false
+	})
+	tk.MustExec("insert into t select * from s")
+	tk.MustExec("delete from t")
+	config.UpdateGlobal(func(conf *config.Config) {
+		conf.EnableTableLock = true
+	})
+
+	// the rows are inserted in the transaction if the store isn't backed by PD.
+	require.NoError(t, failpoint.Disable("github.com/pingcap/tidb/executor/mockInsertIngestError"))
+	tk.MustExec("set @@tidb_insert_select_ingest_threshold = 1")
+	tk.MustExec("insert into t select * from s")
+	tk.MustQuery("select * from t order by a").Check(testkit.Rows("1 10", "2 20", "3 30"))
+	tk.MustQuery("select a from t use index(b) where b = 20").Check(testkit.Rows("2"))
+}
@@ -121,6 +121,63 @@ func TestRecoverTable(t *testing.T) {
 	require.False(t, gcEnable)
 }
 
+func TestRecycleBin(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("set global tidb_recycle_bin_retention = '1h'")
+	defer tk.MustExec("set global tidb_recycle_bin_retention = default")
+
+	// the emulator GC is enabled, the data of the tables in the recycle bin isn't deleted.
+	require.True(t, ddlutil.IsEmulatorGCEnable())
+	tk.MustExec("create table t_bin (a int)")
+	tk.MustExec("insert into t_bin values (1),(2),(3)")
+	tk.MustExec("drop table t_bin")
+	tk.MustExec("create table t_bin2 (a int)")
+	tk.MustExec("drop table t_bin2")
+	// the drop of the view isn't kept.
+	tk.MustExec("create view v_bin as select 1")
+	tk.MustExec("drop view v_bin")
+
+	rows := tk.MustQuery("show recycle bin").Rows()
+	require.Len(t, rows, 2)
+	require.Equal(t, []interface{}{"test", "t_bin"}, rows[0][:2])
+	require.Equal(t, []interface{}{"test", "t_bin2"}, rows[1][:2])
+	dropTime, err := time.ParseInLocation("2006-01-02 15:04:05", rows[0][4].(string), time.Local)
+	require.NoError(t, err)
+	expireTime, err := time.ParseInLocation("2006-01-02 15:04:05", rows[0][5].(string), time.Local)
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, expireTime.Sub(dropTime))
+	require.Len(t, tk.MustQuery("show recycle bin like 'test'").Rows(), 2)
+	tk.MustQuery("show recycle bin where table_name = 't_bin2'").CheckAt([]int{0, 1}, testkit.Rows("test t_bin2"))
+
+	// the tables in the recycle bin are recovered without the GC safe point.
+	tk.MustExec("delete from mysql.tidb where variable_name = 'tikv_gc_safe_point'")
+	tk.MustExec("recover table t_bin")
+	tk.MustQuery("select * from t_bin").Check(testkit.Rows("1", "2", "3"))
+	tk.MustQuery("show recycle bin").CheckAt([]int{1}, testkit.Rows("t_bin2"))
+
+	tk.MustExec("drop table t_bin")
+	tk.MustExec("flashback table t_bin to t_bin3")
+	tk.MustQuery("select * from t_bin3").Check(testkit.Rows("1", "2", "3"))
+
+	tk.MustExec("purge table t_bin2")
+	tk.MustQuery("show recycle bin").Check(testkit.Rows())
+	tk.MustGetErrMsg("purge table t_bin2", "Can't find dropped table: t_bin2 in recycle bin")
+	tk.MustContainErrMsg("recover table t_bin2", "can not get 'tikv_gc_safe_point'")
+
+	// only the tables with privileges are shown, and PURGE TABLE requires the DROP privilege.
+	tk.MustExec("drop table t_bin3")
+	tk.MustExec("create user 'bin_user'@'%'")
+	userTK := testkit.NewTestKit(t, store)
+	require.NoError(t, userTK.Session().Auth(&auth.UserIdentity{Username: "bin_user", Hostname: "%"}, nil, nil))
+	userTK.MustQuery("show recycle bin").Check(testkit.Rows())
+	tk.MustExec("grant select on test.* to 'bin_user'@'%'")
+	userTK.MustQuery("show recycle bin").CheckAt([]int{1}, testkit.Rows("t_bin3"))
+	userTK.MustExec("use test")
+	require.True(t, core.ErrTableaccessDenied.Equal(userTK.ExecToErr("purge table t_bin3")))
+}
+
 func TestFlashbackTable(t *testing.T) {
 	require.NoError(t, failpoint.Enable("github.com/pingcap/tidb/meta/autoid/mockAutoIDChange", `return(true)`))
 	defer func() {
@@ -16,6 +16,8 @@ package executor_test
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"testing"
 
@@ -159,9 +161,81 @@ func TestTableSampleInvalid(t *testing.T) {
 	tk.MustGetErrCode("select * from information_schema.tables tablesample regions();", errno.ErrInvalidTableSample)
 
 	tk.MustGetErrCode("select a from t tablesample system();", errno.ErrInvalidTableSample)
-	tk.MustGetErrCode("select a from t tablesample bernoulli(10 percent);", errno.ErrInvalidTableSample)
+	tk.MustGetErrCode("select a from t tablesample bernoulli(10 rows);", errno.ErrInvalidTableSample)
+	tk.MustGetErrCode("select a from t tablesample bernoulli(101 percent);", errno.ErrInvalidTableSample)
+	tk.MustGetErrCode("select a from t tablesample bernoulli(-1 percent);", errno.ErrInvalidTableSample)
+	tk.MustGetErrCode("select a from t tablesample bernoulli('abc');", errno.ErrInvalidTableSample)
 	tk.MustGetErrCode("select a from t as t1 tablesample regions(), t as t2 tablesample system();", errno.ErrInvalidTableSample)
 	tk.MustGetErrCode("select a from t tablesample ();", errno.ErrInvalidTableSample)
+	tk.MustGetErrCode("select a from t tablesample (10 percent);", errno.ErrInvalidTableSample)
+
+	tk.MustExec("prepare stmt from 'select a from t tablesample bernoulli(?) repeatable(?)';")
+	tk.MustExec("set @p = 200, @s = 1;")
+	tk.MustGetErrCode("execute stmt using @p, @s;", errno.ErrInvalidTableSample)
+	tk.MustExec("set @p = 10, @s = null;")
+	tk.MustGetErrCode("execute stmt using @p, @s;", errno.ErrInvalidTableSample)
+}
+
+func TestTableSamplePercent(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := createSampleTestkit(t, store)
+	tk.MustExec("create table t (a int, b varchar(255));")
+	values := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		values = append(values, fmt.Sprintf("(%d, '%d')", i, i))
+	}
+	tk.MustExec("insert into t values " + strings.Join(values, ", "))
+
+	tk.MustQuery("select count(*) from t tablesample bernoulli(100 percent);").Check(testkit.Rows("1000"))
+	tk.MustQuery("select count(*) from t tablesample bernoulli(0 percent);").Check(testkit.Rows("0"))
+	tk.MustQuery("select count(*) from t tablesample system(100);").Check(testkit.Rows("1000"))
+
+	// the same seed samples the same rows.
+	rows := tk.MustQuery("select a from t tablesample bernoulli(10 percent) repeatable(42) order by a;").Rows()
+	require.Greater(t, len(rows), 50)
+	require.Less(t, len(rows), 150)
+	tk.MustQuery("select a from t tablesample bernoulli(10 percent) repeatable(42) order by a;").Check(rows)
+	tk.MustQuery("select a from t tablesample bernoulli(10.0) repeatable('42') order by a;").Check(rows)
+	// the sample grows with the percentage.
+	more := tk.MustQuery("select a from t tablesample bernoulli(20 percent) repeatable(42) order by a;").Rows()
+	require.Greater(t, len(more), len(rows))
+	// the filter is applied to the uncommitted changes too.
+	tk.MustExec("begin;")
+	tk.MustExec("delete from t where a < 500;")
+	var expected [][]interface{}
+	for _, row := range rows {
+		if a, _ := strconv.Atoi(row[0].(string)); a >= 500 {
+			expected = append(expected, row)
+		}
+	}
+	tk.MustQuery("select a from t tablesample bernoulli(10 percent) repeatable(42) order by a;").Check(expected)
+	tk.MustExec("rollback;")
+
+	tk.MustExec("prepare stmt from 'select a from t tablesample bernoulli(?) repeatable(?) order by a';")
+	tk.MustExec("set @p = 10, @s = 42;")
+	tk.MustQuery("execute stmt using @p, @s;").Check(rows)
+	tk.MustExec("set @p = 20;")
+	tk.MustQuery("execute stmt using @p, @s;").Check(more)
+
+	// the SYSTEM method samples the blocks of consecutive row IDs.
+	tk.MustExec("create table t1 (a bigint primary key);")
+	values = values[:0]
+	for i := 0; i < 1024*20; i += 64 {
+		values = append(values, fmt.Sprintf("(%d)", i))
+	}
+	tk.MustExec("insert into t1 values " + strings.Join(values, ", "))
+	for _, row := range tk.MustQuery("select a div 1024, count(*) from t1 tablesample system(50) repeatable(1) group by a div 1024;").Rows() {
+		require.Equal(t, "16", row[1])
+	}
+
+	// the tables with the clustered index are sampled by the primary key.
+	tk.MustExec("create table t2 (a varchar(10), b int, primary key (a, b) clustered);")
+	tk.MustExec("insert into t2 select b, a from t;")
+	tk.MustQuery("select count(*) from t2 tablesample bernoulli(100 percent) repeatable(1);").Check(testkit.Rows("1000"))
+	rows = tk.MustQuery("select b from t2 tablesample bernoulli(10 percent) repeatable(1) order by b;").Rows()
+	require.Greater(t, len(rows), 50)
+	require.Less(t, len(rows), 150)
+	tk.MustQuery("select b from t2 tablesample bernoulli(10 percent) repeatable(1) order by b;").Check(rows)
 }
 
 func TestTableSampleWithTiDBRowID(t *testing.T) {
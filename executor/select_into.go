@@ -18,31 +18,41 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"math"
+	"net/url"
 	"os"
+	"path"
 	"strconv"
+	"strings"
 
 	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/storage"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/xitongsys/parquet-go/writer"
 )
 
 // SelectIntoExec represents a SelectInto executor.
 type SelectIntoExec struct {
 	baseExecutor
 	intoOpt *ast.SelectIntoOption
+	// names are the names of the result columns, which are used by the Parquet files.
+	names []string
 
-	lineBuf   []byte
-	realBuf   []byte
-	fieldBuf  []byte
-	escapeBuf []byte
-	enclosed  bool
-	writer    *bufio.Writer
-	dstFile   *os.File
-	chk       *chunk.Chunk
-	started   bool
+	lineBuf       []byte
+	realBuf       []byte
+	fieldBuf      []byte
+	escapeBuf     []byte
+	enclosed      bool
+	writer        *bufio.Writer
+	dstFile       io.WriteCloser
+	parquetWriter *writer.CSVWriter
+	chk           *chunk.Chunk
+	started       bool
 }
 
 // Open implements the Executor Open interface.
@@ -52,14 +62,19 @@ func (s *SelectIntoExec) Open(ctx context.Context) error {
 		return errors.New("unsupported SelectInto type")
 	}
 
-	// MySQL-compatible behavior: allow files to be group-readable
-	f, err := os.OpenFile(s.intoOpt.FileName, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0640) // #nosec G302
+	f, err := createOutfile(ctx, s.intoOpt.FileName)
 	if err != nil {
-		return errors.Trace(err)
+		return err
 	}
 	s.started = true
 	s.dstFile = f
 	s.writer = bufio.NewWriter(s.dstFile)
+	if outfileExt(s.intoOpt.FileName) == ".parquet" {
+		s.parquetWriter, err = writer.NewCSVWriterFromWriter(s.parquetSchema(), s.writer, 1)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 	s.chk = newFirstChunk(s.children[0])
 	s.lineBuf = make([]byte, 0, 1024)
 	s.fieldBuf = make([]byte, 0, 64)
@@ -67,6 +82,71 @@ func (s *SelectIntoExec) Open(ctx context.Context) error {
 	return s.baseExecutor.Open(ctx)
 }
 
+// createOutfile creates the file of SELECT ... INTO OUTFILE and EXPLAIN ... INTO OUTFILE.
+// The file name is a local path, or an external storage URL like 's3://bucket/dir/file.csv'.
+// The file must not exist.
+func createOutfile(ctx context.Context, fileName string) (io.WriteCloser, error) {
+	u, err := url.Parse(fileName)
+	// The single letter schemes are the drive letters of Windows paths.
+	if err != nil || len(u.Scheme) <= 1 {
+		// MySQL-compatible behavior: allow files to be group-readable
+		f, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0640) // #nosec G302
+		if os.IsExist(err) {
+			return nil, ErrFileExists.GenWithStackByArgs(fileName)
+		}
+		return f, errors.Trace(err)
+	}
+
+	dir, name := path.Split(u.Path)
+	if len(name) == 0 {
+		return nil, errors.Errorf("the file name is missing in '%s'", fileName)
+	}
+	u.Path = dir
+	backend, err := storage.ParseBackend(u.String(), nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	store, err := storage.New(ctx, backend, &storage.ExternalStorageOptions{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	exists, err := store.FileExists(ctx, name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if exists {
+		return nil, ErrFileExists.GenWithStackByArgs(fileName)
+	}
+	w, err := store.Create(ctx, name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &externalOutfile{ctx: ctx, writer: w}, nil
+}
+
+// outfileExt returns the lower case extension of the outfile, which determines the
+// format of the file, e.g. the result is written as a Parquet file if it's ".parquet".
+func outfileExt(fileName string) string {
+	if u, err := url.Parse(fileName); err == nil && len(u.Scheme) > 1 {
+		fileName = u.Path
+	}
+	return strings.ToLower(path.Ext(fileName))
+}
+
+// externalOutfile adapts ExternalFileWriter to io.WriteCloser.
+type externalOutfile struct {
+	ctx    context.Context
+	writer storage.ExternalFileWriter
+}
+
+func (f *externalOutfile) Write(p []byte) (int, error) {
+	return f.writer.Write(f.ctx, p)
+}
+
+func (f *externalOutfile) Close() error {
+	return f.writer.Close(f.ctx)
+}
+
 // Next implements the Executor Next interface.
 func (s *SelectIntoExec) Next(ctx context.Context, req *chunk.Chunk) error {
 	for {
@@ -76,6 +156,12 @@ func (s *SelectIntoExec) Next(ctx context.Context, req *chunk.Chunk) error {
 		if s.chk.NumRows() == 0 {
 			break
 		}
+		if s.parquetWriter != nil {
+			if err := s.dumpToParquet(); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := s.dumpToOutfile(); err != nil {
 			return err
 		}
@@ -216,15 +302,93 @@ func (s *SelectIntoExec) dumpToOutfile() error {
 	return nil
 }
 
+// parquetSchema returns the schema of the Parquet file. The integers and the floating
+// numbers are stored as INT64 and DOUBLE, and the other values are stored as strings.
+func (s *SelectIntoExec) parquetSchema() []string {
+	cols := s.children[0].Schema().Columns
+	md := make([]string, 0, len(cols))
+	for i, col := range cols {
+		name := fmt.Sprintf("col%d", i)
+		if i < len(s.names) && len(s.names[i]) > 0 {
+			name = s.names[i]
+		}
+		var tp string
+		switch {
+		case isParquetInt64(col.GetType()):
+			tp = "type=INT64"
+		case col.GetType().GetType() == mysql.TypeFloat, col.GetType().GetType() == mysql.TypeDouble:
+			tp = "type=DOUBLE"
+		default:
+			tp = "type=UTF8"
+		}
+		md = append(md, fmt.Sprintf("name=%s, %s, repetitiontype=OPTIONAL", name, tp))
+	}
+	return md
+}
+
+// isParquetInt64 reports whether values of the field type fit into a parquet
+// INT64 column. Unsigned BIGINT values are written as strings to avoid overflow.
+func isParquetInt64(ft *types.FieldType) bool {
+	switch ft.GetType() {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeYear:
+		return true
+	case mysql.TypeLonglong:
+		return !mysql.HasUnsignedFlag(ft.GetFlag())
+	}
+	return false
+}
+
+func (s *SelectIntoExec) dumpToParquet() error {
+	cols := s.children[0].Schema().Columns
+	for i := 0; i < s.chk.NumRows(); i++ {
+		row := s.chk.GetRow(i)
+		// The writer buffers the records until the row group is flushed, so
+		// each row needs its own slice.
+		rec := make([]interface{}, len(cols))
+		for j, col := range cols {
+			if row.IsNull(j) {
+				rec[j] = nil
+				continue
+			}
+			switch {
+			case isParquetInt64(col.GetType()):
+				rec[j] = row.GetInt64(j)
+			case col.GetType().GetType() == mysql.TypeFloat:
+				rec[j] = float64(row.GetFloat32(j))
+			case col.GetType().GetType() == mysql.TypeDouble:
+				rec[j] = row.GetFloat64(j)
+			default:
+				d := row.GetDatum(j, col.GetType())
+				str, err := d.ToString()
+				if err != nil {
+					return errors.Trace(err)
+				}
+				rec[j] = str
+			}
+		}
+		if err := s.parquetWriter.Write(rec); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	s.ctx.GetSessionVars().StmtCtx.AddAffectedRows(uint64(s.chk.NumRows()))
+	return nil
+}
+
 // Close implements the Executor Close interface.
 func (s *SelectIntoExec) Close() error {
 	if !s.started {
 		return nil
 	}
+	var err0 error
+	if s.parquetWriter != nil {
+		err0 = s.parquetWriter.WriteStop()
+	}
 	err1 := s.writer.Flush()
 	err2 := s.dstFile.Close()
 	err3 := s.baseExecutor.Close()
-	if err1 != nil {
+	if err0 != nil {
+		return errors.Trace(err0)
+	} else if err1 != nil {
 		return errors.Trace(err1)
 	} else if err2 != nil {
 		return errors.Trace(err2)
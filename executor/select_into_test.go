@@ -290,3 +290,23 @@ func TestYearType(t *testing.T) {
 	tk.MustExec(fmt.Sprintf("select * from t into outfile '%v' fields terminated by ',' optionally enclosed by '\"' lines terminated by '\\n';", outfile))
 	cmpAndRm("2010\n2011\n2012\n2030\n", outfile, t)
 }
+
+func TestSelectIntoExternalStorage(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("create table t (id bigint unsigned, f float, name varchar(20), d datetime)")
+	tk.MustExec("insert into t values (1, 1.5, 'a', '2022-08-01 00:00:00'), (18446744073709551615, null, 'b,c', null)")
+
+	dir := t.TempDir()
+	csvFile := fmt.Sprintf("local://%s/t.csv", filepath.ToSlash(dir))
+	tk.MustExec(fmt.Sprintf("select * from t order by id into outfile '%s' fields terminated by ',' enclosed by '\"'", csvFile))
+	cmpAndRm("\"1\",\"1.5\",\"a\",\"2022-08-01 00:00:00\"\n\"18446744073709551615\",\\N,\"b,c\",\\N\n", filepath.Join(dir, "t.csv"), t)
+
+	parquetFile := fmt.Sprintf("local://%s/t.parquet", filepath.ToSlash(dir))
+	tk.MustExec(fmt.Sprintf("select id, f, name, d as dt from t order by id into outfile '%s'", parquetFile))
+	require.Equal(t, uint64(2), tk.Session().AffectedRows())
+	tk.MustGetErrCode(fmt.Sprintf("select * from t into outfile '%s'", parquetFile), mysql.ErrFileExists)
+	tk.MustQuery(fmt.Sprintf("select name, dt from external('local://%s/*.parquet') t where f is null or f > 1 order by name", filepath.ToSlash(dir))).
+		Check(testkit.Rows("a 2022-08-01 00:00:00", "b,c <nil>"))
+}
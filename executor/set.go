@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/mysql"
@@ -130,6 +131,10 @@ func (e *SetExecutor) setSysVariable(ctx context.Context, name string, v *expres
 		if err != nil {
 			return err
 		}
+		err = e.setCheckConstraintEnabledTS(name, valStr, sessionVars)
+		if err != nil {
+			return err
+		}
 		err = sessionVars.GlobalVarsAccessor.SetGlobalSysVar(name, valStr)
 		if err != nil {
 			return err
@@ -214,6 +219,23 @@ func (e *SetExecutor) setSysVariable(ctx context.Context, name string, v *expres
 	return nil
 }
 
+// setCheckConstraintEnabledTS persists a TSO as the TS when the CHECK constraints are enabled before
+// turning tidb_enable_check_constraint on, since the rows written before it may violate the constraints.
+func (e *SetExecutor) setCheckConstraintEnabledTS(name, valStr string, sessionVars *variable.SessionVars) error {
+	if name != variable.TiDBEnableCheckConstraint || !variable.TiDBOptOn(valStr) {
+		return nil
+	}
+	oldVal, err := sessionVars.GlobalVarsAccessor.GetGlobalSysVar(name)
+	if err != nil || variable.TiDBOptOn(oldVal) {
+		return err
+	}
+	ver, err := e.ctx.GetStore().CurrentVersion(kv.GlobalTxnScope)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return sessionVars.GlobalVarsAccessor.SetGlobalSysVar(variable.TiDBCheckConstraintEnabledTS, strconv.FormatUint(ver.Ver, 10))
+}
+
 func (e *SetExecutor) checkPDClientDynamicOption(name string, sessionVars *variable.SessionVars) error {
 	if name != variable.TiDBTSOClientBatchMaxWaitTime &&
 		name != variable.TiDBEnableTSOFollowerProxy {
@@ -33,6 +33,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/parser/ast"
@@ -97,9 +98,10 @@ type ShowExec struct {
 	cursor int
 
 	Full        bool
-	IfNotExists bool // Used for `show create database if not exists`
-	GlobalScope bool // GlobalScope is used by show variables
-	Extended    bool // Used for `show extended columns from ...`
+	IfNotExists bool   // Used for `show create database if not exists`
+	GlobalScope bool   // GlobalScope is used by show variables
+	Extended    bool   // Used for `show extended columns from ...`
+	SnapshotTS  uint64 // Used for `show create table ... as of timestamp ...`
 }
 
 type showTableRegionRowItem struct {
@@ -247,6 +249,8 @@ func (e *ShowExec) fetchAll(ctx context.Context) error {
 		return e.fetchShowPlacementForPartition(ctx)
 	case ast.ShowSessionStates:
 		return e.fetchShowSessionStates(ctx)
+	case ast.ShowRecycleBin:
+		return e.fetchShowRecycleBin(ctx)
 	}
 	return nil
 }
@@ -486,7 +490,10 @@ func (e *ShowExec) fetchShowTables() error {
 		return ErrBadDB.GenWithStackByArgs(e.DBName)
 	}
 	// sort for tables
-	schemaTables := e.is.SchemaTables(e.DBName)
+	schemaTables, err := infoschema.SchemaTablesWithError(e.is, e.DBName)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	tableNames := make([]string, 0, len(schemaTables))
 	activeRoles := e.ctx.GetSessionVars().ActiveRoles
 	var (
@@ -1056,7 +1063,7 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 	}
 
 	for i, idxInfo := range publicIndices {
-		if idxInfo.Primary {
+		if idxInfo.Primary && !idxInfo.IsClusteredUniqueKey() {
 			buf.WriteString("  PRIMARY KEY ")
 		} else if idxInfo.Unique {
 			fmt.Fprintf(buf, "  UNIQUE KEY %s ", stringutil.Escape(idxInfo.Name.O, sqlMode))
@@ -1119,9 +1126,23 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 		}
 	}
 
+	for _, constr := range tableInfo.Constraints {
+		if constr.State != model.StatePublic {
+			continue
+		}
+		fmt.Fprintf(buf, ",\n  CONSTRAINT %s CHECK (%s)", stringutil.Escape(constr.Name.O, sqlMode), constr.ExprString)
+		if !constr.Enforced {
+			buf.WriteString(" /*!80016 NOT ENFORCED */")
+		}
+	}
+
 	buf.WriteString("\n")
 
-	buf.WriteString(") ENGINE=InnoDB")
+	if tableInfo.IsFederated() {
+		buf.WriteString(") ENGINE=FEDERATED")
+	} else {
+		buf.WriteString(") ENGINE=InnoDB")
+	}
 	// We need to explicitly set the default charset and collation
 	// to make it work on MySQL server which has default collate utf8_general_ci.
 	if len(tblCollate) == 0 || tblCollate == "binary" {
@@ -1133,6 +1154,10 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 		fmt.Fprintf(buf, " DEFAULT CHARSET=%s COLLATE=%s", tblCharset, tblCollate)
 	}
 
+	if tableInfo.IsFederated() {
+		fmt.Fprintf(buf, " CONNECTION='%s'", format.OutputFormat(tableInfo.Federated.Server+"/"+tableInfo.Federated.Table))
+	}
+
 	// Displayed if the compression typed is set.
 	if len(tableInfo.Compression) != 0 {
 		fmt.Fprintf(buf, " COMPRESSION='%s'", tableInfo.Compression)
@@ -1167,6 +1192,10 @@ func ConstructResultOfShowCreateTable(ctx sessionctx.Context, tableInfo *model.T
 		}
 	}
 
+	if tableInfo.CoprCacheDisabled {
+		buf.WriteString(" /*T![copr_cache] COPROCESSOR_CACHE=0 */")
+	}
+
 	if tableInfo.ShardRowIDBits > 0 {
 		fmt.Fprintf(buf, " /*T! SHARD_ROW_ID_BITS=%d ", tableInfo.ShardRowIDBits)
 		if tableInfo.PreSplitRegions > 0 {
@@ -1267,15 +1296,36 @@ func (e *ShowExec) fetchShowClusterConfigs(ctx context.Context) error {
 }
 
 func (e *ShowExec) fetchShowCreateTable() error {
+	if e.SnapshotTS != 0 {
+		return e.fetchShowCreateTableAsOf()
+	}
 	tb, err := e.getTable()
 	if err != nil {
 		return errors.Trace(err)
 	}
+	return e.appendCreateTable(tb.Meta(), tb.Allocators(e.ctx))
+}
+
+// fetchShowCreateTableAsOf shows the definition of the table in the historical schema
+// at SnapshotTS, which is validated against the GC safe point by the planner.
+func (e *ShowExec) fetchShowCreateTableAsOf() error {
+	is, err := domain.GetDomain(e.ctx).GetSnapshotInfoSchema(e.SnapshotTS)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	tb, err := is.TableByName(e.Table.Schema, e.Table.Name)
+	if err != nil {
+		return err
+	}
+	// The auto IDs allocated at the snapshot are unknown, so the AUTO_INCREMENT and
+	// AUTO_RANDOM_BASE are not shown.
+	return e.appendCreateTable(tb.Meta(), nil)
+}
 
-	tableInfo := tb.Meta()
+func (e *ShowExec) appendCreateTable(tableInfo *model.TableInfo, allocators autoid.Allocators) error {
 	var buf bytes.Buffer
 	// TODO: let the result more like MySQL.
-	if err = ConstructResultOfShowCreateTable(e.ctx, tableInfo, tb.Allocators(e.ctx), &buf); err != nil {
+	if err := ConstructResultOfShowCreateTable(e.ctx, tableInfo, allocators, &buf); err != nil {
 		return err
 	}
 	if tableInfo.IsView() {
@@ -1364,7 +1414,9 @@ func appendPartitionInfo(partitionInfo *model.PartitionInfo, buf *bytes.Buffer,
 				buf.WriteString(",")
 			}
 		}
-		buf.WriteString(")\n(")
+		buf.WriteString(")")
+		ddl.AppendPartitionIntervalInfo(partitionInfo, buf)
+		buf.WriteString("\n(")
 	} else {
 		fmt.Fprintf(buf, "\nPARTITION BY %s (%s)\n(", partitionInfo.Type.String(), partitionInfo.Expr)
 	}
@@ -1408,6 +1460,9 @@ func ConstructResultOfShowCreateDatabase(ctx sessionctx.Context, dbInfo *model.D
 		// add placement ref info here
 		fmt.Fprintf(buf, " /*T![placement] PLACEMENT POLICY=%s */", stringutil.Escape(dbInfo.PlacementPolicyRef.Name.O, sqlMode))
 	}
+	if dbInfo.ReadRouting != "" {
+		fmt.Fprintf(buf, " /*T![read_routing] READ_ROUTING='%s' */", dbInfo.ReadRouting)
+	}
 	return nil
 }
 
@@ -2051,3 +2106,35 @@ func runWithSystemSession(ctx context.Context, sctx sessionctx.Context, fn func(
 	defer b.releaseSysSession(ctx, sysCtx)
 	return fn(sysCtx)
 }
+
+// fetchShowRecycleBin shows the dropped tables in the recycle bin which the user has any privilege on.
+func (e *ShowExec) fetchShowRecycleBin(ctx context.Context) error {
+	var entries []*meta.RecycleBinEntry
+	ctx = kv.WithInternalSourceType(ctx, kv.InternalTxnOthers)
+	err := kv.RunInNewTxn(ctx, e.ctx.GetStore(), false, func(ctx context.Context, txn kv.Transaction) error {
+		var err error
+		entries, err = meta.NewMeta(txn).ListRecycleBin()
+		return err
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	checker := privilege.GetPrivilegeManager(e.ctx)
+	activeRoles := e.ctx.GetSessionVars().ActiveRoles
+	loc := e.ctx.GetSessionVars().Location()
+	retention := variable.RecycleBinRetention.Load()
+	for _, entry := range entries {
+		tblName := entry.TableInfo.Name.O
+		if checker != nil && !checker.RequestVerification(activeRoles, entry.SchemaName, tblName, "", mysql.AllPrivMask) {
+			continue
+		}
+		dropTime := ts2Time(entry.DropTS, loc)
+		expireTime := types.NewTime(types.FromGoTime(model.TSConvert2Time(entry.DropTS).Add(retention).In(loc)), mysql.TypeDatetime, types.DefaultFsp)
+		var size interface{}
+		if entry.Size > 0 {
+			size = entry.Size
+		}
+		e.appendRow([]interface{}{entry.SchemaName, tblName, entry.TableInfo.ID, entry.JobID, dropTime, expireTime, size})
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tidb/executor"
@@ -474,6 +475,46 @@ func TestShowCreateTable(t *testing.T) {
 		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin"))
 }
 
+func TestShowCreateTableAsOf(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+
+	// For mocktikv, safe point is not initialized, we manually insert it for snapshot to use.
+	safePointName := "tikv_gc_safe_point"
+	safePointValue := "20060102-15:04:05 -0700"
+	safePointComment := "All versions after safe point can be accessed. (DO NOT EDIT)"
+	updateSafePoint := fmt.Sprintf(`INSERT INTO mysql.tidb VALUES ('%[1]s', '%[2]s', '%[3]s')
+	ON DUPLICATE KEY
+	UPDATE variable_value = '%[2]s', comment = '%[3]s'`, safePointName, safePointValue, safePointComment)
+	tk.MustExec(updateSafePoint)
+
+	tk.MustExec("create table t(a int auto_increment primary key, b int)")
+	tk.MustExec("insert into t(b) values (1)")
+	time.Sleep(time.Millisecond)
+	snapshotTime := time.Now().Format("2006-01-02 15:04:05.999999")
+	time.Sleep(time.Millisecond)
+	tk.MustExec("alter table t drop column b")
+	tk.MustExec("rename table t to t1")
+
+	tk.MustQuery("show create table t as of timestamp '" + snapshotTime + "'").Check(testkit.RowsWithSep("|", ""+
+		"t CREATE TABLE `t` (\n"+
+		"  `a` int(11) NOT NULL AUTO_INCREMENT,\n"+
+		"  `b` int(11) DEFAULT NULL,\n"+
+		"  PRIMARY KEY (`a`) /*T![clustered_index] CLUSTERED */\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin"))
+	tk.MustGetErrCode("show create table t", mysql.ErrNoSuchTable)
+	err := tk.QueryToErr("show create table t1 as of timestamp '" + snapshotTime + "'")
+	require.True(t, infoschema.ErrTableNotExists.Equal(err), err)
+
+	// The snapshot before the GC safe point can't be read.
+	err = tk.ExecToErr("show create table t as of timestamp '2006-01-01 15:04:05'")
+	require.ErrorContains(t, err, "snapshot is older than GC safe point")
+	// The snapshot can't be in the future.
+	err = tk.ExecToErr("show create table t as of timestamp '2038-01-18 03:14:07'")
+	require.ErrorContains(t, err, "cannot set read timestamp to a future time")
+}
+
 func TestShowCreateTablePlacement(t *testing.T) {
 	store := testkit.CreateMockStore(t)
 	tk := testkit.NewTestKit(t, store)
@@ -1203,6 +1244,25 @@ func TestShowSlow(t *testing.T) {
 	tk.MustQuery(`admin show slow top 3`)
 	tk.MustQuery(`admin show slow top internal 3`)
 	tk.MustQuery(`admin show slow top all 3`)
+
+	// The plan digest, the binding and the stats versions of the slow query are shown.
+	tk.MustExec("use test")
+	tk.MustExec("create table t_show_slow (a int, b int, index idx_a(a))")
+	tk.MustExec("create global binding for select * from t_show_slow where a = 1 using select * from t_show_slow use index(idx_a) where a = 1")
+	tk.MustExec("set @@tidb_slow_log_threshold = 0")
+	defer tk.MustExec("set @@tidb_slow_log_threshold = default")
+	tk.MustQuery("select * from t_show_slow where a = 1")
+	require.Eventually(t, func() bool {
+		for _, row := range tk.MustQuery("admin show slow recent 20").Rows() {
+			if row[0] != "select * from t_show_slow where a = 1" {
+				continue
+			}
+			return len(row[13].(string)) == 64 &&
+				row[14] == "SELECT * FROM `test`.`t_show_slow` USE INDEX (`idx_a`) WHERE `a` = 1" &&
+				row[15] == "t_show_slow:pseudo"
+		}
+		return false
+	}, 5*time.Second, 100*time.Millisecond)
 }
 
 func TestShowCreateTableAutoRandom(t *testing.T) {
@@ -149,6 +149,10 @@ func (e *SimpleExec) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 		err = e.executeAlterUser(ctx, x)
 	case *ast.DropUserStmt:
 		err = e.executeDropUser(ctx, x)
+	case *ast.CreateServerStmt:
+		err = e.executeCreateServer(ctx, x)
+	case *ast.DropServerStmt:
+		err = e.executeDropServer(ctx, x)
 	case *ast.RenameUserStmt:
 		err = e.executeRenameUser(x)
 	case *ast.SetPwdStmt:
@@ -171,7 +175,7 @@ func (e *SimpleExec) Next(ctx context.Context, req *chunk.Chunk) (err error) {
 	case *ast.ShutdownStmt:
 		err = e.executeShutdown(x)
 	case *ast.AdminStmt:
-		err = e.executeAdmin(x)
+		err = e.executeAdmin(ctx, x)
 	}
 	e.done = true
 	return err
@@ -1423,6 +1427,85 @@ func (e *SimpleExec) executeDropUser(ctx context.Context, s *ast.DropUserStmt) e
 	return domain.GetDomain(e.ctx).NotifyUpdatePrivilege()
 }
 
+func (e *SimpleExec) executeCreateServer(ctx context.Context, s *ast.CreateServerStmt) error {
+	if sem.IsEnabled() {
+		// The federated tables would connect to any host from the TiDB servers.
+		return ErrNotSupportedWithSem.GenWithStackByArgs("CREATE SERVER")
+	}
+	ctx = kv.WithInternalSourceType(ctx, kv.InternalTxnOthers)
+	name := strings.ToLower(s.Name)
+	exists, err := serverExists(ctx, e.ctx, name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		err := ErrForeignServerExists.GenWithStackByArgs(s.Name)
+		if !s.IfNotExists {
+			return err
+		}
+		e.ctx.GetSessionVars().StmtCtx.AppendNote(err)
+		return nil
+	}
+
+	var host, db, user, password, socket, owner string
+	var port uint64
+	for _, option := range s.Options {
+		switch option.Name {
+		case ast.ServerOptionHost:
+			host = option.StrValue
+		case ast.ServerOptionDatabase:
+			db = option.StrValue
+		case ast.ServerOptionUser:
+			user = option.StrValue
+		case ast.ServerOptionPassword:
+			password = option.StrValue
+		case ast.ServerOptionSocket:
+			socket = option.StrValue
+		case ast.ServerOptionOwner:
+			owner = option.StrValue
+		case ast.ServerOptionPort:
+			port = option.UintValue
+		}
+	}
+	if _, err := federatedAllowedAddr(ctx, host, socket); err != nil {
+		return err
+	}
+	exec := e.ctx.(sqlexec.RestrictedSQLExecutor)
+	_, _, err = exec.ExecRestrictedSQL(ctx, nil,
+		`INSERT INTO %n.%n (Server_name, Host, Db, Username, Password, Port, Socket, Wrapper, Owner) VALUES (%?, %?, %?, %?, %?, %?, %?, %?, %?)`,
+		mysql.SystemDB, mysql.ServersTable, name, host, db, user, password, port, socket, strings.ToLower(s.Wrapper), owner)
+	return err
+}
+
+func (e *SimpleExec) executeDropServer(ctx context.Context, s *ast.DropServerStmt) error {
+	ctx = kv.WithInternalSourceType(ctx, kv.InternalTxnOthers)
+	name := strings.ToLower(s.Name)
+	exists, err := serverExists(ctx, e.ctx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err := ErrForeignServerDoesntExist.GenWithStackByArgs(s.Name)
+		if !s.IfExists {
+			return err
+		}
+		e.ctx.GetSessionVars().StmtCtx.AppendNote(err)
+		return nil
+	}
+	exec := e.ctx.(sqlexec.RestrictedSQLExecutor)
+	_, _, err = exec.ExecRestrictedSQL(ctx, nil, `DELETE FROM %n.%n WHERE Server_name=%?`, mysql.SystemDB, mysql.ServersTable, name)
+	return err
+}
+
+func serverExists(ctx context.Context, sctx sessionctx.Context, name string) (bool, error) {
+	exec := sctx.(sqlexec.RestrictedSQLExecutor)
+	rows, _, err := exec.ExecRestrictedSQL(ctx, nil, `SELECT 1 FROM %n.%n WHERE Server_name=%?`, mysql.SystemDB, mysql.ServersTable, name)
+	if err != nil {
+		return false, err
+	}
+	return len(rows) > 0, nil
+}
+
 func userExists(ctx context.Context, sctx sessionctx.Context, name string, host string) (bool, error) {
 	exec := sctx.(sqlexec.RestrictedSQLExecutor)
 	ctx = kv.WithInternalSourceType(ctx, kv.InternalTxnPrivilege)
@@ -1514,6 +1597,13 @@ func (e *SimpleExec) executeSetPwd(ctx context.Context, s *ast.SetPwdStmt) error
 }
 
 func (e *SimpleExec) executeKillStmt(ctx context.Context, s *ast.KillStmt) error {
+	if s.Digest != "" {
+		if e.IsFromRemote {
+			e.executeRemoteKillDigest(s)
+			return nil
+		}
+		return e.executeKillDigest(ctx, s.Digest)
+	}
 	if !config.GetGlobalConfig().EnableGlobalKill {
 		conf := config.GetGlobalConfig()
 		if s.TiDBExtension || conf.CompatibleKillQuery {
@@ -1557,7 +1647,7 @@ func (e *SimpleExec) executeKillStmt(ctx context.Context, s *ast.KillStmt) error
 	}
 
 	if connID.ServerID != sm.ServerID() {
-		if err := killRemoteConn(ctx, e.ctx, &connID, s.Query); err != nil {
+		if err := killRemoteConn(ctx, e.ctx, &connID, s.Query, ""); err != nil {
 			err1 := errors.New("KILL remote connection failed: " + err.Error())
 			e.ctx.GetSessionVars().StmtCtx.AppendWarning(err1)
 		}
@@ -1568,7 +1658,126 @@ func (e *SimpleExec) executeKillStmt(ctx context.Context, s *ast.KillStmt) error
 	return nil
 }
 
-func killRemoteConn(ctx context.Context, sctx sessionctx.Context, connID *util.GlobalConnID, query bool) error {
+// executeKillDigest terminates the running statements of the given SQL digest or plan digest on all
+// the tidb-servers. The statements of the other tidb-servers are found by CLUSTER_PROCESSLIST and killed
+// by global kill. A statement is only killed if it's still of the digest when the kill arrives, so the
+// following statements of the connections are not affected.
+func (e *SimpleExec) executeKillDigest(ctx context.Context, digest string) error {
+	sm := e.ctx.GetSessionManager()
+	if sm == nil {
+		return nil
+	}
+	sc := e.ctx.GetSessionVars().StmtCtx
+	selfConnID := e.ctx.GetSessionVars().ConnectionID
+	localConnIDs := make(map[uint64]struct{})
+	for connID, pi := range sm.ShowProcessList() {
+		if connID == selfConnID || !stmtMatchesDigest(pi, digest) || !e.canKillStmtOf(pi.User, pi.Host) {
+			continue
+		}
+		if killStmtOfDigest(sm, connID, digest) {
+			localConnIDs[connID] = struct{}{}
+		}
+	}
+	killed := len(localConnIDs)
+	defer func() {
+		logutil.BgLogger().Info("killed statements by digest", zap.String("digest", digest), zap.Int("count", killed))
+		sc.AddAffectedRows(uint64(killed))
+	}()
+
+	if !config.GetGlobalConfig().EnableGlobalKill {
+		sc.AppendWarning(errors.New("Only the statements on the current TiDB are killed because global kill is disabled"))
+		return nil
+	}
+	// The plan digests are not in CLUSTER_PROCESSLIST, so all the running statements are candidates,
+	// and they are checked against the digest on their own tidb-servers.
+	internalCtx := kv.WithInternalSourceType(ctx, kv.InternalTxnOthers)
+	exec := e.ctx.(sqlexec.RestrictedSQLExecutor)
+	rows, _, err := exec.ExecRestrictedSQL(internalCtx, nil,
+		"SELECT ID, USER, HOST FROM %n.%n WHERE COMMAND != 'Sleep'", util.InformationSchemaName.O, infoschema.ClusterTableProcesslist)
+	if err != nil {
+		sc.AppendWarning(errors.New("Failed to get the statements on the other TiDBs: " + err.Error()))
+		return nil
+	}
+	for _, row := range rows {
+		id := row.GetUint64(0)
+		if _, ok := localConnIDs[id]; ok || id == selfConnID || !e.canKillStmtOf(row.GetString(1), row.GetString(2)) {
+			continue
+		}
+		connID, isTruncated, err := util.ParseGlobalConnID(id)
+		if err != nil || isTruncated || connID.ServerID == sm.ServerID() {
+			continue
+		}
+		if err := killRemoteConn(ctx, e.ctx, &connID, true, digest); err != nil {
+			sc.AppendWarning(errors.New("KILL remote connection failed: " + err.Error()))
+			continue
+		}
+		killed++
+	}
+	return nil
+}
+
+// executeRemoteKillDigest handles the kill sent by KILL DIGEST on another tidb-server. The statement
+// is killed only if the connection is still running a statement of the digest.
+func (e *SimpleExec) executeRemoteKillDigest(s *ast.KillStmt) {
+	sm := e.ctx.GetSessionManager()
+	if sm == nil {
+		return
+	}
+	killed := killStmtOfDigest(sm, s.ConnectionID, s.Digest)
+	logutil.BgLogger().Info("Killing statement by digest in current instance redirected from remote TiDB", zap.Uint64("connID", s.ConnectionID),
+		zap.String("digest", s.Digest), zap.Bool("killed", killed), zap.String("sourceAddr", e.ctx.GetSessionVars().SourceAddr.IP.String()))
+}
+
+// killStmtOfDigest kills the statement running on the connection if it's of the digest.
+func killStmtOfDigest(sm util.SessionManager, connID uint64, digest string) bool {
+	pi, ok := sm.GetProcessInfo(connID)
+	if !ok || !stmtMatchesDigest(pi, digest) {
+		return false
+	}
+	sm.Kill(connID, true)
+	return true
+}
+
+// stmtMatchesDigest checks whether the connection is running a statement of the SQL digest or the plan digest.
+// The process info keeps the digest and the plan of the last statement after it finishes, so the sleeping
+// connections never match.
+func stmtMatchesDigest(pi *util.ProcessInfo, digest string) bool {
+	if pi.Command == mysql.ComSleep {
+		return false
+	}
+	if pi.Digest == digest {
+		return true
+	}
+	if pi.StmtCtx != nil {
+		if _, planDigest := pi.StmtCtx.GetPlanDigest(); planDigest != nil {
+			return planDigest.String() == digest
+		}
+	}
+	p, ok := pi.Plan.(core.Plan)
+	if !ok {
+		return false
+	}
+	_, planDigest := core.NormalizePlan(p)
+	return planDigest != nil && planDigest.String() == digest
+}
+
+// canKillStmtOf checks whether the statements of the user can be killed by KILL DIGEST.
+// Like killing a connection by its ID, the statements of the RESTRICTED_USER_ADMIN users
+// can only be killed with RESTRICTED_CONNECTION_ADMIN when SEM is enabled.
+func (e *SimpleExec) canKillStmtOf(user, host string) bool {
+	if !sem.IsEnabled() {
+		return true
+	}
+	checker := privilege.GetPrivilegeManager(e.ctx)
+	if checker == nil || !checker.RequestDynamicVerificationWithUser("RESTRICTED_USER_ADMIN", false, &auth.UserIdentity{Username: user, Hostname: host}) {
+		return true
+	}
+	return checker.RequestDynamicVerification(e.ctx.GetSessionVars().ActiveRoles, "RESTRICTED_CONNECTION_ADMIN", false)
+}
+
+// killRemoteConn kills the connection or its statement on another tidb-server. If the digest is not empty,
+// the statement is killed only if it's still of the digest when the kill arrives.
+func killRemoteConn(ctx context.Context, sctx sessionctx.Context, connID *util.GlobalConnID, query bool, digest string) error {
 	if connID.ServerID == 0 {
 		return errors.New("Unexpected ZERO ServerID. Please file a bug to the TiDB Team")
 	}
@@ -1577,6 +1786,10 @@ func killRemoteConn(ctx context.Context, sctx sessionctx.Context, connID *util.G
 		Tp:   tipb.ExecType_TypeKill,
 		Kill: &tipb.Kill{ConnID: connID.ID(), Query: query},
 	}
+	if digest != "" {
+		// tipb.Kill has no field for the digest, so it's carried by the executor ID.
+		killExec.ExecutorId = &digest
+	}
 
 	dagReq := &tipb.DAGRequest{}
 	dagReq.TimeZoneName, dagReq.TimeZoneOffset = timeutil.Zone(sctx.GetSessionVars().Location())
@@ -1681,7 +1894,8 @@ func (e *SimpleExec) autoNewTxn() bool {
 	// Data definition language (DDL) statements that define or modify database objects.
 	// (handled in DDL package)
 	// Statements that implicitly use or modify tables in the mysql database.
-	case *ast.CreateUserStmt, *ast.AlterUserStmt, *ast.DropUserStmt, *ast.RenameUserStmt, *ast.RevokeRoleStmt, *ast.GrantRoleStmt:
+	case *ast.CreateUserStmt, *ast.AlterUserStmt, *ast.DropUserStmt, *ast.RenameUserStmt, *ast.RevokeRoleStmt, *ast.GrantRoleStmt,
+		*ast.CreateServerStmt, *ast.DropServerStmt:
 		return true
 	// Transaction-control and locking statements.  BEGIN, LOCK TABLES, SET autocommit = 1 (if the value is not already 1), START TRANSACTION, UNLOCK TABLES.
 	// (handled in other place)
@@ -1744,12 +1958,16 @@ func (e *SimpleExec) executeSetSessionStates(ctx context.Context, s *ast.SetSess
 	return e.ctx.DecodeSessionStates(ctx, e.ctx, &sessionStates)
 }
 
-func (e *SimpleExec) executeAdmin(s *ast.AdminStmt) error {
+func (e *SimpleExec) executeAdmin(ctx context.Context, s *ast.AdminStmt) error {
 	switch s.Tp {
 	case ast.AdminReloadStatistics:
 		return e.executeAdminReloadStatistics(s)
 	case ast.AdminFlushPlanCache:
 		return e.executeAdminFlushPlanCache(s)
+	case ast.AdminExportStats:
+		return e.executeAdminExportStats(ctx, s)
+	case ast.AdminImportStats:
+		return e.executeAdminImportStats(ctx, s)
 	}
 	return nil
 }
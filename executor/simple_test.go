@@ -15,14 +15,29 @@
 package executor_test
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/pingcap/kvproto/pkg/coprocessor"
 	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/parser/auth"
+	"github.com/pingcap/tidb/parser/mysql"
+	plannercore "github.com/pingcap/tidb/planner/core"
 	"github.com/pingcap/tidb/server"
+	"github.com/pingcap/tidb/session"
 	"github.com/pingcap/tidb/testkit"
 	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/sem"
+	"github.com/pingcap/tipb/go-tipb"
+	"github.com/stretchr/testify/require"
 )
 
 func TestKillStmt(t *testing.T) {
@@ -76,3 +91,192 @@ func TestKillStmt(t *testing.T) {
 
 	// remote kill is tested in `tests/globalkilltest`
 }
+
+func TestKillDigest(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	sv := server.CreateMockServer(t, store)
+	sv.SetDomain(dom)
+	defer sv.Close()
+
+	conn1 := server.CreateMockConn(t, sv)
+	tk := testkit.NewTestKitWithSession(t, store, conn1.Context().Session)
+	conn2 := server.CreateMockConn(t, sv)
+	tk2 := testkit.NewTestKitWithSession(t, store, conn2.Context().Session)
+	// the statements are dispatched as COM_QUERY, otherwise the connection is taken as sleeping.
+	tk2.Session().SetCommandValue(mysql.ComQuery)
+
+	originCfg := config.GetGlobalConfig()
+	newCfg := *originCfg
+	newCfg.EnableGlobalKill = false
+	config.StoreGlobalConfig(&newCfg)
+	defer func() {
+		config.StoreGlobalConfig(originCfg)
+	}()
+
+	_, digest := parser.NormalizeDigest("select sleep(100)")
+	tk.MustExec("kill digest '" + digest.String() + "'")
+	require.Equal(t, uint64(0), tk.Session().AffectedRows())
+
+	done := make(chan [][]interface{})
+	go func() {
+		done <- tk2.MustQuery("select sleep(100)").Rows()
+	}()
+	require.Eventually(t, func() bool {
+		pi, ok := sv.GetProcessInfo(conn2.ID())
+		return ok && pi.Digest == digest.String()
+	}, 10*time.Second, 10*time.Millisecond)
+
+	tk.MustExec("kill tidb digest '" + digest.String() + "'")
+	require.Equal(t, uint64(1), tk.Session().AffectedRows())
+	tk.MustQuery("show warnings").Check(testkit.Rows("Warning 1105 Only the statements on the current TiDB are killed because global kill is disabled"))
+	select {
+	case rows := <-done:
+		// sleep returns 1 when it's interrupted.
+		require.Equal(t, [][]interface{}{{"1"}}, rows)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "the statement is not killed")
+	}
+
+	tk.MustExec("create user 'kill_digest'@'%'")
+	conn3 := server.CreateMockConn(t, sv)
+	tk3 := testkit.NewTestKitWithSession(t, store, conn3.Context().Session)
+	require.NoError(t, tk3.Session().Auth(&auth.UserIdentity{Username: "kill_digest", Hostname: "%"}, nil, nil))
+	tk3.MustGetErrMsg("kill digest '"+digest.String()+"'", "[planner:1227]Access denied; you need (at least one of) the SUPER or CONNECTION_ADMIN privilege(s) for this operation")
+	tk.MustExec("grant connection_admin on *.* to 'kill_digest'@'%'")
+	tk3.MustExec("kill digest '" + digest.String() + "'")
+
+	// the statements of the RESTRICTED_USER_ADMIN users are skipped under SEM
+	// unless RESTRICTED_CONNECTION_ADMIN is granted.
+	tk.MustExec("grant restricted_user_admin on *.* to 'root'@'%'")
+	sem.Enable()
+	defer sem.Disable()
+	// the statement may be interrupted before sleep starts, so only check it's finished.
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		if rs, err := tk2.Exec("select sleep(100)"); err == nil {
+			_, _ = session.GetRows4Test(context.Background(), tk2.Session(), rs)
+			require.NoError(t, rs.Close())
+		}
+	}()
+	require.Eventually(t, func() bool {
+		pi, ok := sv.GetProcessInfo(conn2.ID())
+		return ok && pi.Digest == digest.String()
+	}, 10*time.Second, 10*time.Millisecond)
+	tk3.MustExec("kill digest '" + digest.String() + "'")
+	require.Equal(t, uint64(0), tk3.Session().AffectedRows())
+	sem.Disable()
+	tk.MustExec("grant restricted_connection_admin on *.* to 'kill_digest'@'%'")
+	sem.Enable()
+	tk3.MustExec("kill digest '" + digest.String() + "'")
+	require.Equal(t, uint64(1), tk3.Session().AffectedRows())
+	select {
+	case <-finished:
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "the statement is not killed")
+	}
+}
+
+func TestKillPlanDigest(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	sv := server.CreateMockServer(t, store)
+	sv.SetDomain(dom)
+	defer sv.Close()
+
+	conn1 := server.CreateMockConn(t, sv)
+	tk := testkit.NewTestKitWithSession(t, store, conn1.Context().Session)
+	conn2 := server.CreateMockConn(t, sv)
+	tk2 := testkit.NewTestKitWithSession(t, store, conn2.Context().Session)
+	// the statements are dispatched as COM_QUERY, otherwise the connection is taken as sleeping.
+	tk2.Session().SetCommandValue(mysql.ComQuery)
+
+	originCfg := config.GetGlobalConfig()
+	newCfg := *originCfg
+	newCfg.EnableGlobalKill = false
+	config.StoreGlobalConfig(&newCfg)
+	defer func() {
+		config.StoreGlobalConfig(originCfg)
+	}()
+
+	_, digest := parser.NormalizeDigest("select sleep(100)")
+	done := make(chan [][]interface{})
+	go func() {
+		done <- tk2.MustQuery("select sleep(100)").Rows()
+	}()
+	var pi *util.ProcessInfo
+	require.Eventually(t, func() bool {
+		var ok bool
+		pi, ok = sv.GetProcessInfo(conn2.ID())
+		return ok && pi.Digest == digest.String() && pi.Plan != nil
+	}, 10*time.Second, 10*time.Millisecond)
+	_, planDigest := plannercore.NormalizePlan(pi.Plan.(plannercore.Plan))
+
+	tk.MustExec("kill tidb digest '" + planDigest.String() + "'")
+	require.Equal(t, uint64(1), tk.Session().AffectedRows())
+	select {
+	case rows := <-done:
+		// sleep returns 1 when it's interrupted.
+		require.Equal(t, [][]interface{}{{"1"}}, rows)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "the statement is not killed")
+	}
+
+	// the connection which has finished the statement is not killed.
+	tk2.Session().SetProcessInfo("", time.Now(), mysql.ComSleep, 0)
+	tk.MustExec("kill tidb digest '" + digest.String() + "'")
+	require.Equal(t, uint64(0), tk.Session().AffectedRows())
+	tk.MustExec("kill tidb digest '" + planDigest.String() + "'")
+	require.Equal(t, uint64(0), tk.Session().AffectedRows())
+}
+
+func TestRemoteKillDigest(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+	sv := server.CreateMockServer(t, store)
+	sv.SetDomain(dom)
+	defer sv.Close()
+
+	conn1 := server.CreateMockConn(t, sv)
+	tk := testkit.NewTestKitWithSession(t, store, conn1.Context().Session)
+	conn2 := server.CreateMockConn(t, sv)
+	tk2 := testkit.NewTestKitWithSession(t, store, conn2.Context().Session)
+	// the statements are dispatched as COM_QUERY, otherwise the connection is taken as sleeping.
+	tk2.Session().SetCommandValue(mysql.ComQuery)
+
+	_, digest := parser.NormalizeDigest("select sleep(100)")
+	remoteKill := func(digest string) {
+		dag := &tipb.DAGRequest{
+			Executors: []*tipb.Executor{{
+				Tp:         tipb.ExecType_TypeKill,
+				Kill:       &tipb.Kill{ConnID: conn2.ID(), Query: true},
+				ExecutorId: &digest,
+			}},
+		}
+		data, err := proto.Marshal(dag)
+		require.NoError(t, err)
+		resp := executor.NewCoprocessorDAGHandler(tk.Session()).HandleRequest(context.Background(), &coprocessor.Request{Tp: kv.ReqTypeDAG, Data: data})
+		require.Empty(t, resp.OtherError)
+	}
+
+	done := make(chan [][]interface{})
+	go func() {
+		done <- tk2.MustQuery("select sleep(100)").Rows()
+	}()
+	require.Eventually(t, func() bool {
+		pi, ok := sv.GetProcessInfo(conn2.ID())
+		return ok && pi.Digest == digest.String()
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// the kill is ignored if the connection is running a statement of another digest.
+	_, otherDigest := parser.NormalizeDigest("select 1")
+	remoteKill(otherDigest.String())
+	require.Equal(t, uint32(0), atomic.LoadUint32(&tk2.Session().GetSessionVars().Killed))
+
+	remoteKill(digest.String())
+	select {
+	case rows := <-done:
+		// sleep returns 1 when it's interrupted.
+		require.Equal(t, [][]interface{}{{"1"}}, rows)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "the statement is not killed")
+	}
+}
@@ -16,6 +16,7 @@ package expression
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
 	"math"
@@ -27,6 +28,8 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/sessionctx"
@@ -64,6 +67,7 @@ var (
 	_ functionClass = &binToUUIDFunctionClass{}
 	_ functionClass = &isUUIDFunctionClass{}
 	_ functionClass = &tidbShardFunctionClass{}
+	_ functionClass = &tidbLazyDefaultFunctionClass{}
 )
 
 var (
@@ -100,6 +104,8 @@ var (
 	_ builtinFunc = &builtinNameConstStringSig{}
 	_ builtinFunc = &builtinNameConstJSONSig{}
 	_ builtinFunc = &builtinTidbShardSig{}
+	_ builtinFunc = &builtinTiDBLazyDefaultStringSig{}
+	_ builtinFunc = &builtinTiDBLazyDefaultRealSig{}
 )
 
 const (
@@ -1467,3 +1473,147 @@ func (b *builtinTidbShardSig) evalInt(row chunk.Row) (int64, bool, error) {
 	hashed = hashed % tidbShardBucketCount
 	return int64(hashed), false, nil
 }
+
+// LazyDefaultValue evaluates the non-constant default function of a column added instantly for a row existing before
+// the column is added. The value is a pseudo random function of the seed of the column and the handle of the row, so
+// the rows get distinct values as if the function was evaluated for each of them, and the value of a row never changes
+// until the row is written.
+func LazyDefaultValue(funcName string, seed uint64, handle int64) (types.Datum, error) {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], seed)
+	binary.BigEndian.PutUint64(buf[8:], uint64(handle))
+	switch funcName {
+	case ast.UUID:
+		return types.NewStringDatum(uuid.NewSHA1(uuid.Nil, buf[:]).String()), nil
+	case ast.Rand:
+		sum := sha256.Sum256(buf[:])
+		// Use the high 53 bits as the mantissa, so the value is uniform in [0, 1).
+		return types.NewFloat64Datum(float64(binary.BigEndian.Uint64(sum[:8])>>11) / (1 << 53)), nil
+	}
+	return types.Datum{}, errors.Errorf("unsupported lazy default function %s", funcName)
+}
+
+// InternalFuncLazyDefault evaluates the lazy default value of a column for the rows existing before the column is
+// added, it's built by the planner only and isn't exposed to the users.
+const InternalFuncLazyDefault = "tidb_lazy_default"
+
+// CheckFuncCallable returns an error if the function is internal, which can't be called by the users.
+func CheckFuncCallable(ctx sessionctx.Context, funcName string) error {
+	if funcName != InternalFuncLazyDefault {
+		return nil
+	}
+	return errFunctionNotExists.GenWithStackByArgs("FUNCTION", ctx.GetSessionVars().CurrentDB+"."+funcName)
+}
+
+// BuildLazyDefaultFunction builds the tidb_lazy_default function, the args are the column, the handle, the name of the
+// default function and the seed.
+func BuildLazyDefaultFunction(ctx sessionctx.Context, args ...Expression) (Expression, error) {
+	fc := &tidbLazyDefaultFunctionClass{baseFunctionClass{InternalFuncLazyDefault, 4, 4}}
+	// The args are rewritten by getFunction, so they're copied.
+	funcArgs := make([]Expression, len(args))
+	copy(funcArgs, args)
+	f, err := fc.getFunction(ctx, funcArgs)
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarFunction{
+		FuncName: model.NewCIStr(InternalFuncLazyDefault),
+		RetType:  f.getRetTp(),
+		Function: f,
+	}, nil
+}
+
+type tidbLazyDefaultFunctionClass struct {
+	baseFunctionClass
+}
+
+func (c *tidbLazyDefaultFunctionClass) getFunction(ctx sessionctx.Context, args []Expression) (builtinFunc, error) {
+	if err := c.verifyArgs(args); err != nil {
+		return nil, err
+	}
+	// The function returns the value of the column if it's not NULL, so its type is the type of the column.
+	bf, err := newBaseBuiltinFunc(ctx, c.funcName, args, args[0].GetType().Clone())
+	if err != nil {
+		return nil, err
+	}
+	bf.args[1] = WrapWithCastAsInt(ctx, args[1])
+	bf.args[2] = WrapWithCastAsString(ctx, args[2])
+	bf.args[3] = WrapWithCastAsInt(ctx, args[3])
+	switch bf.tp.EvalType() {
+	case types.ETString:
+		return &builtinTiDBLazyDefaultStringSig{bf}, nil
+	case types.ETReal:
+		return &builtinTiDBLazyDefaultRealSig{bf}, nil
+	}
+	return nil, errIncorrectArgs.GenWithStackByArgs(c.funcName)
+}
+
+// evalLazyDefault evaluates the lazy default value of the row, the args are the column, the handle, the name of the
+// default function and the seed.
+func evalLazyDefault(b *baseBuiltinFunc, row chunk.Row) (types.Datum, bool, error) {
+	handle, isNull, err := b.args[1].EvalInt(b.ctx, row)
+	if isNull || err != nil {
+		return types.Datum{}, true, err
+	}
+	funcName, isNull, err := b.args[2].EvalString(b.ctx, row)
+	if isNull || err != nil {
+		return types.Datum{}, true, err
+	}
+	seed, isNull, err := b.args[3].EvalInt(b.ctx, row)
+	if isNull || err != nil {
+		return types.Datum{}, true, err
+	}
+	d, err := LazyDefaultValue(funcName, uint64(seed), handle)
+	if err != nil {
+		return types.Datum{}, true, err
+	}
+	// Convert the value to the column like the values written into the rows.
+	d, err = d.ConvertTo(b.ctx.GetSessionVars().StmtCtx, b.tp)
+	return d, err != nil, err
+}
+
+type builtinTiDBLazyDefaultStringSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinTiDBLazyDefaultStringSig) Clone() builtinFunc {
+	newSig := &builtinTiDBLazyDefaultStringSig{}
+	newSig.cloneFrom(&b.baseBuiltinFunc)
+	return newSig
+}
+
+// evalString evals tidb_lazy_default(col, handle, func_name, seed).
+func (b *builtinTiDBLazyDefaultStringSig) evalString(row chunk.Row) (string, bool, error) {
+	val, isNull, err := b.args[0].EvalString(b.ctx, row)
+	if !isNull || err != nil {
+		return val, isNull, err
+	}
+	d, isNull, err := evalLazyDefault(&b.baseBuiltinFunc, row)
+	if isNull || err != nil {
+		return "", true, err
+	}
+	return d.GetString(), false, nil
+}
+
+type builtinTiDBLazyDefaultRealSig struct {
+	baseBuiltinFunc
+}
+
+func (b *builtinTiDBLazyDefaultRealSig) Clone() builtinFunc {
+	newSig := &builtinTiDBLazyDefaultRealSig{}
+	newSig.cloneFrom(&b.baseBuiltinFunc)
+	return newSig
+}
+
+// evalReal evals tidb_lazy_default(col, handle, func_name, seed).
+func (b *builtinTiDBLazyDefaultRealSig) evalReal(row chunk.Row) (float64, bool, error) {
+	val, isNull, err := b.args[0].EvalReal(b.ctx, row)
+	if !isNull || err != nil {
+		return val, isNull, err
+	}
+	d, isNull, err := evalLazyDefault(&b.baseBuiltinFunc, row)
+	if isNull || err != nil {
+		return 0, true, err
+	}
+	return d.GetFloat64(), false, nil
+}
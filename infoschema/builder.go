@@ -111,6 +111,14 @@ func (b *bundleInfoBuilder) updateInfoSchemaBundles(is *infoSchema) {
 			b.updateTableBundles(is, tbl.Meta().ID)
 		}
 	}
+	// the bundles of the lazy schemas are built from the briefs, without loading the tables.
+	if is.lazy != nil {
+		for id := range is.lazy.dbs {
+			for _, brief := range is.lazy.tableBriefs[id] {
+				b.updateTableBundles(is, brief.ID)
+			}
+		}
+	}
 }
 
 func (b *bundleInfoBuilder) completeUpdateTables(is *infoSchema) {
@@ -118,9 +126,8 @@ func (b *bundleInfoBuilder) completeUpdateTables(is *infoSchema) {
 		return
 	}
 
-	for _, tbls := range is.schemaMap {
-		for _, tbl := range tbls.tables {
-			tblInfo := tbl.Meta()
+	IterateSchemaTableInfos(is, func(_ *model.DBInfo, tblInfos []*model.TableInfo) bool {
+		for _, tblInfo := range tblInfos {
 			if tblInfo.PlacementPolicyRef != nil {
 				if _, ok := b.updatePolicies[tblInfo.PlacementPolicyRef.ID]; ok {
 					b.markTableBundleShouldUpdate(tblInfo.ID)
@@ -135,18 +142,19 @@ func (b *bundleInfoBuilder) completeUpdateTables(is *infoSchema) {
 				}
 			}
 		}
-	}
+		return true
+	})
 }
 
 func (b *bundleInfoBuilder) updateTableBundles(is *infoSchema, tableID int64) {
-	tbl, ok := is.TableByID(tableID)
+	tblInfo, ok := is.tableInfoByID(tableID)
 	if !ok {
 		b.deleteBundle(is, tableID)
 		return
 	}
 
 	getter := &policyGetter{is: is}
-	bundle, err := placement.NewTableBundle(getter, tbl.Meta())
+	bundle, err := placement.NewTableBundle(getter, tblInfo)
 	if err != nil {
 		logutil.BgLogger().Error("create table bundle failed", zap.Error(err))
 	} else if bundle != nil {
@@ -155,11 +163,11 @@ func (b *bundleInfoBuilder) updateTableBundles(is *infoSchema, tableID int64) {
 		b.deleteBundle(is, tableID)
 	}
 
-	if tbl.Meta().Partition == nil {
+	if tblInfo.Partition == nil {
 		return
 	}
 
-	for _, par := range tbl.Meta().Partition.Definitions {
+	for _, par := range tblInfo.Partition.Definitions {
 		bundle, err = placement.NewPartitionBundle(getter, par)
 		if err != nil {
 			logutil.BgLogger().Error("create partition bundle failed",
@@ -194,6 +202,9 @@ type Builder struct {
 // Return the detail updated table IDs that are produced from SchemaDiff and an error.
 func (b *Builder) ApplyDiff(m *meta.Meta, diff *model.SchemaDiff) ([]int64, error) {
 	b.is.schemaMetaVersion = diff.Version
+	if err := b.loadLazySchemasForDiff(diff); err != nil {
+		return nil, errors.Trace(err)
+	}
 	switch diff.Type {
 	case model.ActionCreateSchema:
 		return nil, b.applyCreateSchema(m, diff)
@@ -203,6 +214,8 @@ func (b *Builder) ApplyDiff(m *meta.Meta, diff *model.SchemaDiff) ([]int64, erro
 		return nil, b.applyModifySchemaCharsetAndCollate(m, diff)
 	case model.ActionModifySchemaDefaultPlacement:
 		return nil, b.applyModifySchemaDefaultPlacement(m, diff)
+	case model.ActionModifySchemaReadRouting:
+		return nil, b.applyModifySchemaReadRouting(m, diff)
 	case model.ActionCreatePlacementPolicy:
 		return nil, b.applyCreatePolicy(m, diff)
 	case model.ActionDropPlacementPolicy:
@@ -573,6 +586,22 @@ func (b *Builder) applyModifySchemaDefaultPlacement(m *meta.Meta, diff *model.Sc
 	return nil
 }
 
+func (b *Builder) applyModifySchemaReadRouting(m *meta.Meta, diff *model.SchemaDiff) error {
+	di, err := m.GetDatabase(diff.SchemaID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if di == nil {
+		// This should never happen.
+		return ErrDatabaseNotExists.GenWithStackByArgs(
+			fmt.Sprintf("(Schema ID %d)", diff.SchemaID),
+		)
+	}
+	newDbInfo := b.getSchemaAndCopyIfNecessary(di.Name.L)
+	newDbInfo.ReadRouting = di.ReadRouting
+	return nil
+}
+
 func (b *Builder) applyDropPolicy(PolicyID int64) []int64 {
 	po, ok := b.is.PolicyByID(PolicyID)
 	if !ok {
@@ -700,6 +729,9 @@ func (b *Builder) applyCreateTable(m *meta.Meta, dbInfo *model.DBInfo, tableID i
 	if tblInfo.TempTableType != model.TempTableNone {
 		b.addTemporaryTable(tableID)
 	}
+	if isIntervalPartitionTable(tblInfo) {
+		b.addIntervalTable(tableID)
+	}
 
 	newTbl, ok := b.is.TableByID(tableID)
 	if ok {
@@ -758,6 +790,9 @@ func (b *Builder) applyDropTable(dbInfo *model.DBInfo, tableID int64, affected [
 	if b.is.temporaryTableIDs != nil {
 		delete(b.is.temporaryTableIDs, tableID)
 	}
+	if b.is.intervalTableIDs != nil {
+		delete(b.is.intervalTableIDs, tableID)
+	}
 
 	// The old DBInfo still holds a reference to old table info, we need to remove it.
 	for i, tblInfo := range dbInfo.Tables {
@@ -787,6 +822,10 @@ func (b *Builder) InitWithOldInfoSchema(oldSchema InfoSchema) *Builder {
 	b.copyBundlesMap(oldIS)
 	b.copyPoliciesMap(oldIS)
 	b.copyTemporaryTableIDsMap(oldIS)
+	b.copyIntervalTableIDsMap(oldIS)
+	if oldIS.lazy != nil {
+		b.is.lazy = oldIS.lazy.clone()
+	}
 
 	copy(b.is.sortedTablesBuckets, oldIS.sortedTablesBuckets)
 	return b
@@ -825,6 +864,17 @@ func (b *Builder) copyTemporaryTableIDsMap(oldIS *infoSchema) {
 	}
 }
 
+func (b *Builder) copyIntervalTableIDsMap(oldIS *infoSchema) {
+	if len(oldIS.intervalTableIDs) == 0 {
+		b.is.intervalTableIDs = nil
+		return
+	}
+	b.is.intervalTableIDs = make(map[int64]struct{}, len(oldIS.intervalTableIDs))
+	for tblID := range oldIS.intervalTableIDs {
+		b.is.intervalTableIDs[tblID] = struct{}{}
+	}
+}
+
 // getSchemaAndCopyIfNecessary creates a new schemaTables instance when a table in the database has changed.
 // It also does modifications on the new one because old schemaTables must be read-only.
 // And it will only copy the changed database once in the lifespan of the Builder.
@@ -879,6 +929,71 @@ func (b *Builder) InitWithDBInfos(dbInfos []*model.DBInfo, policies []*model.Pol
 	return b, nil
 }
 
+// WithLazySchemas sets the schemas whose tables are loaded on the first access.
+func (b *Builder) WithLazySchemas(lazy *LazySchemas) *Builder {
+	b.is.lazy = newLazySchemas(lazy, b.tableFromMeta, b.store)
+	return b
+}
+
+// loadLazySchemasForDiff loads the lazy schemas changed by the diff into the new InfoSchema,
+// since the diff is applied on the tables of them. They're loaded from the snapshot of the
+// old InfoSchema, which is the state before the diff.
+func (b *Builder) loadLazySchemasForDiff(diff *model.SchemaDiff) error {
+	if b.is.lazy == nil {
+		return nil
+	}
+	schemaIDs := []int64{diff.SchemaID, diff.OldSchemaID}
+	for _, opt := range diff.AffectedOpts {
+		schemaIDs = append(schemaIDs, opt.SchemaID, opt.OldSchemaID)
+	}
+	for _, id := range schemaIDs {
+		di, ok := b.is.lazy.dbs[id]
+		if !ok {
+			continue
+		}
+		loaded, err := b.is.lazy.loadWithRetry(di)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		b.is.lazy.delete(di)
+
+		// The loaded tables are shared with the old InfoSchemas, copy them before changing.
+		newSchemaTables := &schemaTables{
+			dbInfo: loaded.dbInfo.Copy(),
+			tables: make(map[string]table.Table, len(loaded.tables)),
+		}
+		for k, v := range loaded.tables {
+			newSchemaTables.tables[k] = v
+		}
+		b.is.schemaMap[di.Name.L] = newSchemaTables
+		b.dirtyDB[di.Name.L] = true
+
+		dirtyBuckets := make(map[int]struct{})
+		for _, tbl := range loaded.tables {
+			tblInfo := tbl.Meta()
+			bucketIdx := tableBucketIdx(tblInfo.ID)
+			if _, ok := dirtyBuckets[bucketIdx]; !ok {
+				b.copySortedTablesBucket(bucketIdx)
+				dirtyBuckets[bucketIdx] = struct{}{}
+			}
+			b.is.sortedTablesBuckets[bucketIdx] = append(b.is.sortedTablesBuckets[bucketIdx], tbl)
+			if tblInfo.TempTableType != model.TempTableNone {
+				b.addTemporaryTable(tblInfo.ID)
+			}
+			if isIntervalPartitionTable(tblInfo) {
+				b.addIntervalTable(tblInfo.ID)
+			}
+			b.markTableBundleShouldUpdate(tblInfo.ID)
+		}
+		for bucketIdx := range dirtyBuckets {
+			slices.SortFunc(b.is.sortedTablesBuckets[bucketIdx], func(i, j table.Table) bool {
+				return i.Meta().ID < j.Meta().ID
+			})
+		}
+	}
+	return nil
+}
+
 func (b *Builder) tableFromMeta(alloc autoid.Allocators, tblInfo *model.TableInfo) (table.Table, error) {
 	ret, err := tables.TableFromMeta(alloc, tblInfo)
 	if err != nil {
@@ -918,9 +1033,13 @@ func (b *Builder) createSchemaTablesForDB(di *model.DBInfo, tableFromMeta tableF
 		schTbls.tables[t.Name.L] = tbl
 		sortedTbls := b.is.sortedTablesBuckets[tableBucketIdx(t.ID)]
 		b.is.sortedTablesBuckets[tableBucketIdx(t.ID)] = append(sortedTbls, tbl)
-		if tblInfo := tbl.Meta(); tblInfo.TempTableType != model.TempTableNone {
+		tblInfo := tbl.Meta()
+		if tblInfo.TempTableType != model.TempTableNone {
 			b.addTemporaryTable(tblInfo.ID)
 		}
+		if isIntervalPartitionTable(tblInfo) {
+			b.addIntervalTable(tblInfo.ID)
+		}
 	}
 	return nil
 }
@@ -932,6 +1051,13 @@ func (b *Builder) addTemporaryTable(tblID int64) {
 	b.is.temporaryTableIDs[tblID] = struct{}{}
 }
 
+func (b *Builder) addIntervalTable(tblID int64) {
+	if b.is.intervalTableIDs == nil {
+		b.is.intervalTableIDs = make(map[int64]struct{})
+	}
+	b.is.intervalTableIDs[tblID] = struct{}{}
+}
+
 type virtualTableDriver struct {
 	*model.DBInfo
 	TableFromMeta tableFromMetaFunc
@@ -46,6 +46,10 @@ const (
 	ClusterTableDeadlocks = "CLUSTER_DEADLOCKS"
 	// ClusterTableDeadlocks is the string constant of cluster transaction summary table.
 	ClusterTableTrxSummary = "CLUSTER_TRX_SUMMARY"
+	// ClusterTableCoprocessorCacheStats is the string constant of cluster coprocessor cache stats table.
+	ClusterTableCoprocessorCacheStats = "CLUSTER_COPROCESSOR_CACHE_STATS"
+	// ClusterTableCardinalityFeedback is the string constant of cluster cardinality feedback table.
+	ClusterTableCardinalityFeedback = "CLUSTER_CARDINALITY_FEEDBACK"
 )
 
 // memTableToClusterTables means add memory table to cluster table.
@@ -58,6 +62,8 @@ var memTableToClusterTables = map[string]string{
 	TableTiDBTrx:                  ClusterTableTiDBTrx,
 	TableDeadlocks:                ClusterTableDeadlocks,
 	TableTrxSummary:               ClusterTableTrxSummary,
+	TableCoprocessorCacheStats:    ClusterTableCoprocessorCacheStats,
+	TableCardinalityFeedback:      ClusterTableCardinalityFeedback,
 }
 
 func init() {
@@ -19,6 +19,7 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/ddl/placement"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/parser/model"
@@ -26,7 +27,9 @@ import (
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/mock"
+	"go.uber.org/zap"
 	"golang.org/x/exp/slices"
 )
 
@@ -98,9 +101,15 @@ type infoSchema struct {
 
 	// temporaryTables stores the temporary table ids
 	temporaryTableIDs map[int64]struct{}
+	// intervalTableIDs stores the ids of the tables partitioned by INTERVAL ... AUTO CREATE
+	intervalTableIDs map[int64]struct{}
 
 	// schemaMetaVersion is the version of schema, and we should check version when change schema.
 	schemaMetaVersion int64
+
+	// lazy stores the schemas whose tables are loaded on the first access, it's nil if all
+	// the tables are loaded by the full load.
+	lazy *lazySchemas
 }
 
 // MockInfoSchema only serves for test.
@@ -160,8 +169,221 @@ func MockInfoSchemaWithSchemaVer(tbList []*model.TableInfo, schemaVer int64) Inf
 
 var _ InfoSchema = (*infoSchema)(nil)
 
+// schemaTablesByName returns the tables of the schema, and loads them if the schema is lazy.
+// The schema is treated as not existing if it fails to be loaded, the callers that can return
+// an error should use loadSchemaTablesByName.
+func (is *infoSchema) schemaTablesByName(name string) (*schemaTables, bool) {
+	v, ok, err := is.loadSchemaTablesByName(name)
+	if err != nil {
+		logutil.BgLogger().Error("load lazy schema failed", zap.String("schema", name), zap.Error(err))
+		return nil, false
+	}
+	return v, ok
+}
+
+// loadSchemaTablesByName is like schemaTablesByName but returns the error of loading the
+// lazy schema instead of treating it as not existing.
+func (is *infoSchema) loadSchemaTablesByName(name string) (*schemaTables, bool, error) {
+	if tbNames, ok := is.schemaMap[name]; ok {
+		return tbNames, true, nil
+	}
+	if is.lazy == nil {
+		return nil, false, nil
+	}
+	di, ok := is.lazy.schemaByName(name)
+	if !ok {
+		return nil, false, nil
+	}
+	v, err := is.lazy.loadWithRetry(di)
+	if err != nil {
+		return nil, false, err
+	}
+	return v.schemaTables, true, nil
+}
+
+// schemaTablesByID is like schemaTablesByName but finds the schema by its ID.
+func (is *infoSchema) schemaTablesByID(id int64) (*schemaTables, bool) {
+	for _, v := range is.schemaMap {
+		if v.dbInfo.ID == id {
+			return v, true
+		}
+	}
+	if is.lazy == nil {
+		return nil, false
+	}
+	di, ok := is.lazy.dbs[id]
+	if !ok {
+		return nil, false
+	}
+	v, ok := is.lazy.loadOrLog(di)
+	if !ok {
+		return nil, false
+	}
+	return v.schemaTables, true
+}
+
+// allSchemaTables calls fn for the tables of every schema until it returns false, the lazy
+// schemas are loaded one by one, and the ones failing to be loaded are skipped.
+func (is *infoSchema) allSchemaTables(fn func(*schemaTables) bool) {
+	for _, v := range is.schemaMap {
+		if !fn(v) {
+			return
+		}
+	}
+	if is.lazy == nil {
+		return
+	}
+	for _, di := range is.lazy.dbs {
+		if v, ok := is.lazy.loadOrLog(di); ok && !fn(v.schemaTables) {
+			return
+		}
+	}
+}
+
+// allSchemaTablesWithError is like allSchemaTables but stops at the first lazy schema that
+// fails to be loaded and returns the error.
+func (is *infoSchema) allSchemaTablesWithError(fn func(*schemaTables) bool) error {
+	for _, v := range is.schemaMap {
+		if !fn(v) {
+			return nil
+		}
+	}
+	if is.lazy == nil {
+		return nil
+	}
+	for _, di := range is.lazy.dbs {
+		v, err := is.lazy.loadWithRetry(di)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !fn(v.schemaTables) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// tableInfoByID returns the info of the table without loading the lazy schemas, the info of
+// the table in a lazy schema is its brief, see meta.ListTableBriefs.
+func (is *infoSchema) tableInfoByID(id int64) (*model.TableInfo, bool) {
+	slice := is.sortedTablesBuckets[tableBucketIdx(id)]
+	if idx := slice.searchTable(id); idx != -1 {
+		return slice[idx].Meta(), true
+	}
+	if is.lazy == nil {
+		return nil, false
+	}
+	return is.lazy.tableBriefByID(id)
+}
+
+// unwrapInfoSchema returns the infoSchema under the InfoSchema, it returns nil if there is none.
+func unwrapInfoSchema(is InfoSchema) *infoSchema {
+	if ext, ok := is.(*SessionExtendedInfoSchema); ok {
+		is = ext.InfoSchema
+	}
+	i, _ := is.(*infoSchema)
+	return i
+}
+
+// SchemaTablesWithError is like InfoSchema.SchemaTables, but returns the error of loading the
+// tables of a lazy schema instead of treating the schema as not existing.
+func SchemaTablesWithError(is InfoSchema, schema model.CIStr) ([]table.Table, error) {
+	i := unwrapInfoSchema(is)
+	if i == nil || i.lazy == nil {
+		return is.SchemaTables(schema), nil
+	}
+	schemaTables, ok, err := i.loadSchemaTablesByName(schema.L)
+	if err != nil || !ok {
+		return nil, err
+	}
+	tables := make([]table.Table, 0, len(schemaTables.tables))
+	for _, tbl := range schemaTables.tables {
+		tables = append(tables, tbl)
+	}
+	return tables, nil
+}
+
+// AllSchemasWithError is like InfoSchema.AllSchemas, but returns the error of loading the
+// tables of a lazy schema instead of skipping the schema.
+func AllSchemasWithError(is InfoSchema) ([]*model.DBInfo, error) {
+	i := unwrapInfoSchema(is)
+	if i == nil || i.lazy == nil {
+		return is.AllSchemas(), nil
+	}
+	var schemas []*model.DBInfo
+	err := i.allSchemaTablesWithError(func(v *schemaTables) bool {
+		schemas = append(schemas, v.dbInfo)
+		return true
+	})
+	return schemas, err
+}
+
+// IterateSchemaTableInfos calls fn with the table infos of every schema until it returns false.
+// Unlike AllSchemas, it never loads the tables of the lazy schemas, whose table infos are the
+// briefs, see meta.ListTableBriefs. It's used by the background jobs scanning all the tables,
+// which should get the table by TableByID if they need more than the brief.
+func IterateSchemaTableInfos(is InfoSchema, fn func(db *model.DBInfo, tblInfos []*model.TableInfo) bool) {
+	i := unwrapInfoSchema(is)
+	if i == nil {
+		for _, db := range is.AllSchemas() {
+			if !fn(db, db.Tables) {
+				return
+			}
+		}
+		return
+	}
+	for _, v := range i.schemaMap {
+		if !fn(v.dbInfo, v.dbInfo.Tables) {
+			return
+		}
+	}
+	if i.lazy == nil {
+		return
+	}
+	for id, di := range i.lazy.dbs {
+		if !fn(di, i.lazy.tableBriefs[id]) {
+			return
+		}
+	}
+}
+
+// IntervalPartitionTableIDs returns the IDs of the tables partitioned by INTERVAL ... AUTO CREATE,
+// without loading the tables of the lazy schemas.
+func IntervalPartitionTableIDs(is InfoSchema) []int64 {
+	i := unwrapInfoSchema(is)
+	if i == nil {
+		var ids []int64
+		for _, db := range is.AllSchemas() {
+			for _, tblInfo := range db.Tables {
+				if isIntervalPartitionTable(tblInfo) {
+					ids = append(ids, tblInfo.ID)
+				}
+			}
+		}
+		return ids
+	}
+	ids := make([]int64, 0, len(i.intervalTableIDs))
+	for id := range i.intervalTableIDs {
+		ids = append(ids, id)
+	}
+	if i.lazy != nil {
+		for id, schemaID := range i.lazy.intervalTableIDs {
+			// The schemas changed since the full load are not lazy any more, their tables are in intervalTableIDs.
+			if _, ok := i.lazy.dbs[schemaID]; ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+func isIntervalPartitionTable(tblInfo *model.TableInfo) bool {
+	pi := tblInfo.GetPartitionInfo()
+	return pi != nil && pi.AutoInterval != nil
+}
+
 func (is *infoSchema) SchemaByName(schema model.CIStr) (val *model.DBInfo, ok bool) {
-	tableNames, ok := is.schemaMap[schema.L]
+	tableNames, ok := is.schemaTablesByName(schema.L)
 	if !ok {
 		return
 	}
@@ -173,12 +395,22 @@ func (is *infoSchema) SchemaMetaVersion() int64 {
 }
 
 func (is *infoSchema) SchemaExists(schema model.CIStr) bool {
-	_, ok := is.schemaMap[schema.L]
-	return ok
+	if _, ok := is.schemaMap[schema.L]; ok {
+		return true
+	}
+	if is.lazy != nil {
+		_, ok := is.lazy.schemaByName(schema.L)
+		return ok
+	}
+	return false
 }
 
 func (is *infoSchema) TableByName(schema, table model.CIStr) (t table.Table, err error) {
-	if tbNames, ok := is.schemaMap[schema.L]; ok {
+	tbNames, ok, err := is.loadSchemaTablesByName(schema.L)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
 		if t, ok = tbNames.tables[table.L]; ok {
 			return
 		}
@@ -187,7 +419,7 @@ func (is *infoSchema) TableByName(schema, table model.CIStr) (t table.Table, err
 }
 
 func (is *infoSchema) TableIsView(schema, table model.CIStr) bool {
-	if tbNames, ok := is.schemaMap[schema.L]; ok {
+	if tbNames, ok := is.schemaTablesByName(schema.L); ok {
 		if t, ok := tbNames.tables[table.L]; ok {
 			return t.Meta().IsView()
 		}
@@ -196,7 +428,7 @@ func (is *infoSchema) TableIsView(schema, table model.CIStr) bool {
 }
 
 func (is *infoSchema) TableIsSequence(schema, table model.CIStr) bool {
-	if tbNames, ok := is.schemaMap[schema.L]; ok {
+	if tbNames, ok := is.schemaTablesByName(schema.L); ok {
 		if t, ok := tbNames.tables[table.L]; ok {
 			return t.Meta().IsSequence()
 		}
@@ -205,7 +437,7 @@ func (is *infoSchema) TableIsSequence(schema, table model.CIStr) bool {
 }
 
 func (is *infoSchema) TableExists(schema, table model.CIStr) bool {
-	if tbNames, ok := is.schemaMap[schema.L]; ok {
+	if tbNames, ok := is.schemaTablesByName(schema.L); ok {
 		if _, ok = tbNames.tables[table.L]; ok {
 			return true
 		}
@@ -224,12 +456,11 @@ func (is *infoSchema) PolicyByID(id int64) (val *model.PolicyInfo, ok bool) {
 }
 
 func (is *infoSchema) SchemaByID(id int64) (val *model.DBInfo, ok bool) {
-	for _, v := range is.schemaMap {
-		if v.dbInfo.ID == id {
-			return v.dbInfo, true
-		}
+	tableNames, ok := is.schemaTablesByID(id)
+	if !ok {
+		return nil, false
 	}
-	return nil, false
+	return tableNames.dbInfo, true
 }
 
 func (is *infoSchema) SchemaByTable(tableInfo *model.TableInfo) (val *model.DBInfo, ok bool) {
@@ -243,16 +474,43 @@ func (is *infoSchema) SchemaByTable(tableInfo *model.TableInfo) (val *model.DBIn
 			}
 		}
 	}
+	if v, ok := is.lazySchemaTablesByTableID(tableInfo.ID); ok {
+		if tbl, ok := v.tables[tableInfo.Name.L]; ok && tbl.Meta().ID == tableInfo.ID {
+			return v.dbInfo, true
+		}
+	}
 	return nil, false
 }
 
+// lazySchemaTablesByTableID returns the tables of the lazy schema that the table or
+// partition belongs to.
+func (is *infoSchema) lazySchemaTablesByTableID(id int64) (*loadedSchema, bool) {
+	if is.lazy == nil {
+		return nil, false
+	}
+	schemaID, ok := is.lazy.schemaIDByPhysicalID(id)
+	if !ok {
+		return nil, false
+	}
+	di, ok := is.lazy.dbs[schemaID]
+	if !ok {
+		return nil, false
+	}
+	return is.lazy.loadOrLog(di)
+}
+
 func (is *infoSchema) TableByID(id int64) (val table.Table, ok bool) {
 	slice := is.sortedTablesBuckets[tableBucketIdx(id)]
 	idx := slice.searchTable(id)
-	if idx == -1 {
+	if idx != -1 {
+		return slice[idx], true
+	}
+	tbNames, ok := is.lazySchemaTablesByTableID(id)
+	if !ok {
 		return nil, false
 	}
-	return slice[idx], true
+	val, ok = tbNames.byID[id]
+	return
 }
 
 func (is *infoSchema) AllocByID(id int64) (autoid.Allocators, bool) {
@@ -267,18 +525,24 @@ func (is *infoSchema) AllSchemaNames() (names []string) {
 	for _, v := range is.schemaMap {
 		names = append(names, v.dbInfo.Name.O)
 	}
+	if is.lazy != nil {
+		for _, di := range is.lazy.dbs {
+			names = append(names, di.Name.O)
+		}
+	}
 	return
 }
 
 func (is *infoSchema) AllSchemas() (schemas []*model.DBInfo) {
-	for _, v := range is.schemaMap {
+	is.allSchemaTables(func(v *schemaTables) bool {
 		schemas = append(schemas, v.dbInfo)
-	}
+		return true
+	})
 	return
 }
 
 func (is *infoSchema) SchemaTables(schema model.CIStr) (tables []table.Table) {
-	schemaTables, ok := is.schemaMap[schema.L]
+	schemaTables, ok := is.schemaTablesByName(schema.L)
 	if !ok {
 		return
 	}
@@ -290,32 +554,46 @@ func (is *infoSchema) SchemaTables(schema model.CIStr) (tables []table.Table) {
 
 // FindTableByPartitionID finds the partition-table info by the partitionID.
 // FindTableByPartitionID will traverse all the tables to find the partitionID partition in which partition-table.
+// The partitions of the lazy schemas are known without loading them, only the one holding the partition is loaded.
 func (is *infoSchema) FindTableByPartitionID(partitionID int64) (table.Table, *model.DBInfo, *model.PartitionDefinition) {
 	for _, v := range is.schemaMap {
-		for _, tbl := range v.tables {
-			pi := tbl.Meta().GetPartitionInfo()
-			if pi == nil {
-				continue
-			}
-			for _, p := range pi.Definitions {
-				if p.ID == partitionID {
-					return tbl, v.dbInfo, &p
-				}
-			}
+		if tbl, p := findTableByPartitionID(v, partitionID); tbl != nil {
+			return tbl, v.dbInfo, p
+		}
+	}
+	if v, ok := is.lazySchemaTablesByTableID(partitionID); ok {
+		if tbl, p := findTableByPartitionID(v.schemaTables, partitionID); tbl != nil {
+			return tbl, v.dbInfo, p
 		}
 	}
 	return nil, nil, nil
 }
 
+func findTableByPartitionID(v *schemaTables, partitionID int64) (table.Table, *model.PartitionDefinition) {
+	for _, tbl := range v.tables {
+		pi := tbl.Meta().GetPartitionInfo()
+		if pi == nil {
+			continue
+		}
+		for _, p := range pi.Definitions {
+			if p.ID == partitionID {
+				return tbl, &p
+			}
+		}
+	}
+	return nil, nil
+}
+
 // HasTemporaryTable returns whether information schema has temporary table
 func (is *infoSchema) HasTemporaryTable() bool {
-	return len(is.temporaryTableIDs) != 0
+	return len(is.temporaryTableIDs) != 0 || (is.lazy != nil && is.lazy.hasTemporaryTable)
 }
 
 func (is *infoSchema) Clone() (result []*model.DBInfo) {
-	for _, v := range is.schemaMap {
+	is.allSchemaTables(func(v *schemaTables) bool {
 		result = append(result, v.dbInfo.Clone())
-	}
+		return true
+	})
 	return
 }
 
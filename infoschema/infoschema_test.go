@@ -296,6 +296,9 @@ func TestInfoTables(t *testing.T) {
 		"DEADLOCKS",
 		"PLACEMENT_POLICIES",
 		"TRX_SUMMARY",
+		"TIFLASH_REPLICA_RECOMMENDATIONS",
+		"COPROCESSOR_CACHE_STATS",
+		"CARDINALITY_FEEDBACK",
 	}
 	for _, tbl := range infoTables {
 		tb, err1 := is.TableByName(util.InformationSchemaName, model.NewCIStr(tbl))
@@ -543,6 +546,148 @@ func TestBuildBundle(t *testing.T) {
 	assertBundle(is2, p1.ID, p1Bundle)
 }
 
+func TestLazySchemas(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("create placement policy p1 followers=1")
+	tk.MustExec("create database db1")
+	tk.MustExec("create database db2")
+	tk.MustExec("create table db1.t1(a int) placement policy p1")
+	tk.MustExec("create table db2.t2(a int) partition by hash(a) partitions 2")
+	tk.MustExec("create database db3")
+	tk.MustExec("create table db3.t3(d date) partition by range columns (d) interval (1 month) first partition less than ('2023-01-01') last partition less than ('2023-03-01') auto create")
+
+	ver, err := store.CurrentVersion(kv.GlobalTxnScope)
+	require.NoError(t, err)
+	m := meta.NewSnapshotMeta(store.GetSnapshot(ver))
+	schemaVer, err := m.GetSchemaVersion()
+	require.NoError(t, err)
+	dbs, err := m.ListDatabases()
+	require.NoError(t, err)
+	policies, err := m.ListPolicies()
+	require.NoError(t, err)
+	// fetchFailures is the number of the following fetches that fail.
+	fetchFailures := 0
+	// fetches counts the fetches of the tables.
+	fetches := 0
+	lazy := &infoschema.LazySchemas{
+		TableBriefs: make(map[int64][]*model.TableInfo),
+		SnapshotTS:  ver.Ver,
+		Capacity:    1,
+		FetchTables: func(m *meta.Meta, di *model.DBInfo) ([]*model.TableInfo, error) {
+			fetches++
+			if fetchFailures > 0 {
+				fetchFailures--
+				return nil, errors.New("mock fetch tables error")
+			}
+			return m.ListTables(di.ID)
+		},
+	}
+	tableIDs := make(map[string]int64)
+	for _, di := range dbs {
+		if di.Name.L != "db1" && di.Name.L != "db2" && di.Name.L != "db3" {
+			continue
+		}
+		briefs, err := m.ListTableBriefs(di.ID)
+		require.NoError(t, err)
+		require.Len(t, briefs, 1)
+		tableIDs[di.Name.L] = briefs[0].ID
+		lazy.TableBriefs[di.ID] = briefs
+		lazy.DBInfos = append(lazy.DBInfos, di)
+	}
+	builder, err := infoschema.NewBuilder(store, nil).InitWithDBInfos(nil, policies, schemaVer)
+	require.NoError(t, err)
+	is := builder.WithLazySchemas(lazy).Build()
+
+	// the bundles and the table briefs are known without loading the tables.
+	bundle, ok := is.PlacementBundleByPhysicalTableID(tableIDs["db1"])
+	require.True(t, ok)
+	require.Len(t, is.AllPlacementBundles(), 1)
+	require.Equal(t, bundle, is.AllPlacementBundles()[0])
+	partitions := 0
+	infoschema.IterateSchemaTableInfos(is, func(db *model.DBInfo, tblInfos []*model.TableInfo) bool {
+		if db.Name.L == "db2" {
+			require.Len(t, tblInfos, 1)
+			partitions = len(tblInfos[0].Partition.Definitions)
+		}
+		return true
+	})
+	require.Equal(t, 2, partitions)
+	require.Equal(t, []int64{tableIDs["db3"]}, infoschema.IntervalPartitionTableIDs(is))
+	require.Equal(t, 0, fetches)
+
+	db1, db2 := model.NewCIStr("db1"), model.NewCIStr("db2")
+	require.True(t, is.SchemaExists(db1))
+	require.Subset(t, is.AllSchemaNames(), []string{"db1", "db2"})
+	t1, err := is.TableByName(db1, model.NewCIStr("t1"))
+	require.NoError(t, err)
+	require.False(t, is.TableExists(db1, model.NewCIStr("t2")))
+	// db2 evicts db1 since the capacity is 1, and db1 is loaded again on the next access.
+	t2, ok := is.TableByID(tableIDs["db2"])
+	require.True(t, ok)
+	require.Equal(t, "t2", t2.Meta().Name.L)
+	tbl, ok := is.TableByID(t1.Meta().ID)
+	require.True(t, ok)
+	require.Equal(t, "t1", tbl.Meta().Name.L)
+	// the allocators are kept across the evictions.
+	require.Same(t, t1.Allocators(nil).Get(autoid.RowIDAllocType), tbl.Allocators(nil).Get(autoid.RowIDAllocType))
+	// only the schema holding the partition is loaded.
+	partition := t2.Meta().Partition.Definitions[1]
+	fetches = 0
+	tbl, db, def := is.FindTableByPartitionID(partition.ID)
+	require.Equal(t, t2.Meta().ID, tbl.Meta().ID)
+	require.Equal(t, "db2", db.Name.L)
+	require.Equal(t, partition.ID, def.ID)
+	require.Equal(t, 1, fetches)
+	tbl, _, _ = is.FindTableByPartitionID(tableIDs["db2"] + 1000)
+	require.Nil(t, tbl)
+	require.Equal(t, 1, fetches)
+	db, ok = is.SchemaByTable(t2.Meta())
+	require.True(t, ok)
+	require.Equal(t, "db2", db.Name.L)
+
+	// the transient errors of loading the schema are retried.
+	fetchFailures = 1
+	require.True(t, is.TableExists(db1, model.NewCIStr("t1")))
+	// the error is returned if the schema still fails to be loaded.
+	_, ok = is.TableByID(tableIDs["db2"])
+	require.True(t, ok)
+	fetchFailures = 3
+	_, err = is.TableByName(db1, model.NewCIStr("t1"))
+	require.ErrorContains(t, err, "mock fetch tables error")
+	require.False(t, infoschema.ErrTableNotExists.Equal(err))
+	fetchFailures = 3
+	_, err = infoschema.SchemaTablesWithError(is, db1)
+	require.ErrorContains(t, err, "mock fetch tables error")
+	fetchFailures = 3
+	_, err = infoschema.AllSchemasWithError(is)
+	require.ErrorContains(t, err, "mock fetch tables error")
+	tables, err := infoschema.SchemaTablesWithError(is, db1)
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+
+	// The diff loads the changed lazy schema before applying on it.
+	tk.MustExec("create table db1.t3(a int)")
+	ver, err = store.CurrentVersion(kv.GlobalTxnScope)
+	require.NoError(t, err)
+	m = meta.NewSnapshotMeta(store.GetSnapshot(ver))
+	schemaVer, err = m.GetSchemaVersion()
+	require.NoError(t, err)
+	diff, err := m.GetSchemaDiff(schemaVer)
+	require.NoError(t, err)
+	builder = infoschema.NewBuilder(store, nil).InitWithOldInfoSchema(is)
+	builder.SetDeltaUpdateBundles()
+	_, err = builder.ApplyDiff(m, diff)
+	require.NoError(t, err)
+	is2 := builder.Build()
+	require.True(t, is2.TableExists(db1, model.NewCIStr("t1")))
+	require.True(t, is2.TableExists(db1, model.NewCIStr("t3")))
+	require.False(t, is.TableExists(db1, model.NewCIStr("t3")))
+	require.True(t, is2.TableExists(db2, model.NewCIStr("t2")))
+	require.Equal(t, []int64{tableIDs["db3"]}, infoschema.IntervalPartitionTableIDs(is2))
+}
+
 func TestLocalTemporaryTables(t *testing.T) {
 	store, err := mockstore.NewMockStore()
 	require.NoError(t, err)
@@ -0,0 +1,301 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infoschema
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/meta/autoid"
+	"github.com/pingcap/tidb/metrics"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util"
+	"github.com/pingcap/tidb/util/kvcache"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// loadLazySchemaRetryCnt is the max times to load the tables of a lazy schema, the loading
+	// may fail by the transient errors of reading the snapshot.
+	loadLazySchemaRetryCnt = 3
+	// loadLazySchemaBackoff is the backoff in milliseconds between the retries of the loading.
+	loadLazySchemaBackoff = 50
+)
+
+var (
+	getLazyCounter = metrics.InfoCacheCounters.WithLabelValues("get", "lazy")
+	hitLazyCounter = metrics.InfoCacheCounters.WithLabelValues("hit", "lazy")
+)
+
+// LazySchemas describes the schemas whose tables are not loaded by the full load of the
+// InfoSchema. The tables of such a schema are loaded from the snapshot on the first access,
+// and at most Capacity schemas are kept in memory, the least recently used ones are evicted
+// and loaded again when they are accessed next time.
+type LazySchemas struct {
+	// DBInfos are the lazily loaded schemas, their Tables are not filled.
+	DBInfos []*model.DBInfo
+	// TableBriefs are the brief table infos of the schemas in DBInfos by the schema IDs, which
+	// are fetched by meta.ListTableBriefs. They're used to find the tables and partitions, and
+	// to build the placement bundles, without loading the tables.
+	TableBriefs map[int64][]*model.TableInfo
+	// SnapshotTS is the snapshot to load the tables from.
+	SnapshotTS uint64
+	// Capacity is the max number of the lazily loaded schemas kept in memory.
+	Capacity uint
+	// FetchTables fetches the public tables of the schema.
+	FetchTables func(m *meta.Meta, di *model.DBInfo) ([]*model.TableInfo, error)
+}
+
+type lazySchemaKey int64
+
+func (k lazySchemaKey) Hash() []byte {
+	return []byte(strconv.FormatInt(int64(k), 10))
+}
+
+// loadedSchema is a loaded lazy schema.
+type loadedSchema struct {
+	*schemaTables
+	byID map[int64]table.Table
+}
+
+// lazyCache is shared by all the InfoSchemas built from the same full load. The cached
+// tables of a schema are only used by the InfoSchemas in which the schema has not changed
+// since the full load, so they all see the same tables.
+type lazyCache struct {
+	store         kv.Storage
+	tableFromMeta tableFromMetaFunc
+	fetchTables   func(m *meta.Meta, di *model.DBInfo) ([]*model.TableInfo, error)
+	// tableBriefs, tableIDs, partitionIDs, intervalTableIDs and hasTemporaryTable are read-only once
+	// the cache is built, tableIDs, partitionIDs and intervalTableIDs map the IDs of the tables, the
+	// partitions and the tables partitioned by INTERVAL ... AUTO CREATE to the IDs of their schemas.
+	tableBriefs      map[int64][]*model.TableInfo
+	tableIDs         map[int64]int64
+	partitionIDs     map[int64]int64
+	intervalTableIDs map[int64]int64
+	// hasTemporaryTable indicates whether any lazy schema has temporary tables.
+	hasTemporaryTable bool
+
+	mu  sync.Mutex
+	lru *kvcache.SimpleLRUCache
+	// allocators are the auto ID allocators of the loaded tables by their IDs. They are kept
+	// when the schemas are evicted, so the cached auto ID ranges are not dropped when the
+	// tables are loaded again.
+	allocators map[int64]autoid.Allocators
+}
+
+// lazySchemas holds the schemas of an InfoSchema that are not loaded yet. A schema is moved
+// out of it once a schema diff changes it, so the snapshot is always valid for the left ones.
+type lazySchemas struct {
+	*lazyCache
+	snapshotTS uint64
+	// dbs and dbNames are read-only once the InfoSchema is built.
+	dbs     map[int64]*model.DBInfo
+	dbNames map[string]int64
+}
+
+func newLazySchemas(lazy *LazySchemas, tableFromMeta tableFromMetaFunc, store kv.Storage) *lazySchemas {
+	l := &lazySchemas{
+		lazyCache: &lazyCache{
+			store:            store,
+			tableFromMeta:    tableFromMeta,
+			fetchTables:      lazy.FetchTables,
+			tableBriefs:      lazy.TableBriefs,
+			tableIDs:         make(map[int64]int64),
+			partitionIDs:     make(map[int64]int64),
+			intervalTableIDs: make(map[int64]int64),
+			lru:              kvcache.NewSimpleLRUCache(lazy.Capacity, 0, 0),
+			allocators:       make(map[int64]autoid.Allocators),
+		},
+		snapshotTS: lazy.SnapshotTS,
+		dbs:        make(map[int64]*model.DBInfo, len(lazy.DBInfos)),
+		dbNames:    make(map[string]int64, len(lazy.DBInfos)),
+	}
+	for _, di := range lazy.DBInfos {
+		l.dbs[di.ID] = di
+		l.dbNames[di.Name.L] = di.ID
+		for _, brief := range lazy.TableBriefs[di.ID] {
+			l.tableIDs[brief.ID] = di.ID
+			if brief.TempTableType != model.TempTableNone {
+				l.hasTemporaryTable = true
+			}
+			if pi := brief.GetPartitionInfo(); pi != nil {
+				for _, def := range pi.Definitions {
+					l.partitionIDs[def.ID] = di.ID
+				}
+				if pi.AutoInterval != nil {
+					l.intervalTableIDs[brief.ID] = di.ID
+				}
+			}
+		}
+	}
+	return l
+}
+
+func (l *lazySchemas) clone() *lazySchemas {
+	newL := &lazySchemas{
+		lazyCache:  l.lazyCache,
+		snapshotTS: atomic.LoadUint64(&l.snapshotTS),
+		dbs:        make(map[int64]*model.DBInfo, len(l.dbs)),
+		dbNames:    make(map[string]int64, len(l.dbNames)),
+	}
+	for id, di := range l.dbs {
+		newL.dbs[id] = di
+	}
+	for name, id := range l.dbNames {
+		newL.dbNames[name] = id
+	}
+	return newL
+}
+
+func (l *lazySchemas) delete(di *model.DBInfo) {
+	delete(l.dbs, di.ID)
+	delete(l.dbNames, di.Name.L)
+}
+
+func (l *lazySchemas) schemaByName(name string) (*model.DBInfo, bool) {
+	id, ok := l.dbNames[name]
+	if !ok {
+		return nil, false
+	}
+	return l.dbs[id], true
+}
+
+// schemaIDByPhysicalID returns the ID of the schema that the table or partition belongs to.
+func (l *lazySchemas) schemaIDByPhysicalID(id int64) (int64, bool) {
+	if schemaID, ok := l.tableIDs[id]; ok {
+		return schemaID, true
+	}
+	schemaID, ok := l.partitionIDs[id]
+	return schemaID, ok
+}
+
+// tableBriefByID returns the brief info of the table in the lazy schemas.
+func (l *lazySchemas) tableBriefByID(id int64) (*model.TableInfo, bool) {
+	schemaID, ok := l.tableIDs[id]
+	if !ok {
+		return nil, false
+	}
+	if _, ok := l.dbs[schemaID]; !ok {
+		return nil, false
+	}
+	for _, brief := range l.tableBriefs[schemaID] {
+		if brief.ID == id {
+			return brief, true
+		}
+	}
+	return nil, false
+}
+
+// load returns the tables of the lazy schema, loads them if they are not in the cache.
+func (l *lazySchemas) load(di *model.DBInfo) (*loadedSchema, error) {
+	getLazyCounter.Inc()
+	key := lazySchemaKey(di.ID)
+	l.mu.Lock()
+	if v, ok := l.lru.Get(key); ok {
+		l.mu.Unlock()
+		hitLazyCounter.Inc()
+		return v.(*loadedSchema), nil
+	}
+	l.mu.Unlock()
+
+	snapshot := l.store.GetSnapshot(kv.NewVersion(atomic.LoadUint64(&l.snapshotTS)))
+	tblInfos, err := l.fetchTables(meta.NewSnapshotMeta(snapshot), di)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	dbInfo := di.Copy()
+	dbInfo.Tables = tblInfos
+	schTbls := &loadedSchema{
+		schemaTables: &schemaTables{
+			dbInfo: dbInfo,
+			tables: make(map[string]table.Table, len(tblInfos)),
+		},
+		byID: make(map[int64]table.Table, len(tblInfos)),
+	}
+	allocsByID := make(map[int64]autoid.Allocators, len(tblInfos))
+	l.mu.Lock()
+	for _, t := range tblInfos {
+		allocs, ok := l.allocators[t.ID]
+		if !ok {
+			allocs = autoid.NewAllocatorsFromTblInfo(l.store, di.ID, t)
+			l.allocators[t.ID] = allocs
+		}
+		allocsByID[t.ID] = allocs
+	}
+	l.mu.Unlock()
+	for _, t := range tblInfos {
+		tbl, err := l.tableFromMeta(allocsByID[t.ID], t)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Build table `%s`.`%s` schema failed", di.Name.O, t.Name.O))
+		}
+		schTbls.tables[t.Name.L] = tbl
+		schTbls.byID[t.ID] = tbl
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// Another session may have loaded the schema concurrently, keep the cached one so
+	// all the sessions share the same auto ID allocators.
+	if v, ok := l.lru.Get(key); ok {
+		return v.(*loadedSchema), nil
+	}
+	l.lru.Put(key, schTbls)
+	return schTbls, nil
+}
+
+// loadWithRetry is like load but retries on the errors.
+func (l *lazySchemas) loadWithRetry(di *model.DBInfo) (schTbls *loadedSchema, err error) {
+	err = util.RunWithRetry(loadLazySchemaRetryCnt, loadLazySchemaBackoff, func() (bool, error) {
+		var err error
+		schTbls, err = l.load(di)
+		return true, err
+	})
+	return schTbls, err
+}
+
+// loadOrLog is like loadWithRetry but treats the schema as not existing if it still fails
+// to load the tables, it's used by the methods of InfoSchema that can't return an error.
+// The callers that can return an error should use SchemaTablesWithError or AllSchemasWithError.
+func (l *lazySchemas) loadOrLog(di *model.DBInfo) (*loadedSchema, bool) {
+	schTbls, err := l.loadWithRetry(di)
+	if err != nil {
+		logutil.BgLogger().Error("load lazy schema failed", zap.String("schema", di.Name.O), zap.Error(err))
+		return nil, false
+	}
+	return schTbls, true
+}
+
+// AdvanceLazyLoadTS advances the snapshot that the lazy schemas of the InfoSchema are loaded
+// from, so they are not loaded from a garbage collected snapshot. The caller must make sure
+// the InfoSchema is the one of the snapshot ts.
+func AdvanceLazyLoadTS(is InfoSchema, ts uint64) {
+	i := unwrapInfoSchema(is)
+	if i == nil || i.lazy == nil {
+		return
+	}
+	for {
+		old := atomic.LoadUint64(&i.lazy.snapshotTS)
+		if ts <= old || atomic.CompareAndSwapUint64(&i.lazy.snapshotTS, old, ts) {
+			return
+		}
+	}
+}
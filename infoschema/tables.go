@@ -184,6 +184,12 @@ const (
 	TableTrxSummary = "TRX_SUMMARY"
 	// TableVariablesInfo is the string constant of variables_info table.
 	TableVariablesInfo = "VARIABLES_INFO"
+	// TableTiFlashReplicaRecommendations is the string constant of tiflash replica recommendations table.
+	TableTiFlashReplicaRecommendations = "TIFLASH_REPLICA_RECOMMENDATIONS"
+	// TableCoprocessorCacheStats is the string constant of coprocessor cache stats table.
+	TableCoprocessorCacheStats = "COPROCESSOR_CACHE_STATS"
+	// TableCardinalityFeedback is the string constant of cardinality feedback table.
+	TableCardinalityFeedback = "CARDINALITY_FEEDBACK"
 )
 
 const (
@@ -285,6 +291,11 @@ var tableIDMap = map[string]int64{
 	TableTrxSummary:                      autoid.InformationSchemaDBID + 80,
 	ClusterTableTrxSummary:               autoid.InformationSchemaDBID + 81,
 	TableVariablesInfo:                   autoid.InformationSchemaDBID + 82,
+	TableTiFlashReplicaRecommendations:   autoid.InformationSchemaDBID + 83,
+	TableCoprocessorCacheStats:           autoid.InformationSchemaDBID + 84,
+	ClusterTableCoprocessorCacheStats:    autoid.InformationSchemaDBID + 85,
+	TableCardinalityFeedback:             autoid.InformationSchemaDBID + 86,
+	ClusterTableCardinalityFeedback:      autoid.InformationSchemaDBID + 87,
 }
 
 // columnInfo represents the basic column information of all kinds of INFORMATION_SCHEMA tables
@@ -1129,6 +1140,46 @@ var tableTableTiFlashReplicaCols = []columnInfo{
 	{name: "PROGRESS", tp: mysql.TypeDouble, size: 22},
 }
 
+var tableTiFlashReplicaRecommendationsCols = []columnInfo{
+	{name: "TABLE_SCHEMA", tp: mysql.TypeVarchar, size: 64},
+	{name: "TABLE_NAME", tp: mysql.TypeVarchar, size: 64},
+	{name: "TABLE_ID", tp: mysql.TypeLonglong, size: 21},
+	{name: "EXEC_COUNT", tp: mysql.TypeLonglong, size: 20, flag: mysql.NotNullFlag, comment: "Count of executed select statements reading the table"},
+	{name: "SUM_SCAN_KEYS", tp: mysql.TypeLonglong, size: 20, flag: mysql.NotNullFlag, comment: "Sum of keys scanned from TiKV by these statements"},
+	{name: "AVG_LATENCY", tp: mysql.TypeLonglong, size: 20, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Average latency of these statements"},
+	{name: "TABLE_ROWS", tp: mysql.TypeLonglong, size: 21},
+	{name: "ESTIMATED_REPLICA_SIZE", tp: mysql.TypeLonglong, size: 21, comment: "Estimated size in bytes of one TiFlash replica"},
+	{name: "AVG_SCAN_RATIO", tp: mysql.TypeDouble, size: 22, comment: "Average ratio of the table rows scanned by one statement"},
+	{name: "BENEFIT_SCORE", tp: mysql.TypeDouble, size: 22, comment: "Count of full table scans served by TiKV per GiB of the replica"},
+	{name: "RECOMMENDED", tp: mysql.TypeTiny, size: 1},
+	{name: "REASON", tp: mysql.TypeVarchar, size: 256},
+}
+
+var tableCoprocessorCacheStatsCols = []columnInfo{
+	{name: "TABLE_SCHEMA", tp: mysql.TypeVarchar, size: 64},
+	{name: "TABLE_NAME", tp: mysql.TypeVarchar, size: 64},
+	{name: "PARTITION_NAME", tp: mysql.TypeVarchar, size: 64},
+	{name: "TIDB_TABLE_ID", tp: mysql.TypeLonglong, size: 21, comment: "ID of the table or the partition"},
+	{name: "CACHE_ENABLED", tp: mysql.TypeTiny, size: 1},
+	{name: "REQUESTS", tp: mysql.TypeLonglong, size: 20, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Count of cacheable coprocessor requests"},
+	{name: "HITS", tp: mysql.TypeLonglong, size: 20, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Count of requests served by the cache"},
+	{name: "HIT_RATIO", tp: mysql.TypeDouble, size: 22},
+	{name: "ADMISSIONS", tp: mysql.TypeLonglong, size: 20, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Count of responses put into the cache"},
+}
+
+var tableCardinalityFeedbackCols = []columnInfo{
+	{name: "TABLE_SCHEMA", tp: mysql.TypeVarchar, size: 64},
+	{name: "TABLE_NAME", tp: mysql.TypeVarchar, size: 64},
+	{name: "TIDB_TABLE_ID", tp: mysql.TypeLonglong, size: 21},
+	{name: "COLUMN_NAMES", tp: mysql.TypeVarchar, size: 256, comment: "Columns filtered, separated by commas"},
+	{name: "STATS_VERSION", tp: mysql.TypeLonglong, size: 20, flag: mysql.UnsignedFlag, comment: "Version of the stats the estimations are based on"},
+	{name: "CORRECTION_FACTOR", tp: mysql.TypeDouble, size: 22, comment: "Factor multiplied to the selectivity of the filters on the columns"},
+	{name: "SAMPLES", tp: mysql.TypeLonglong, size: 20, flag: mysql.NotNullFlag | mysql.UnsignedFlag, comment: "Count of the executed plans fed back"},
+	{name: "LAST_EST_ROWS", tp: mysql.TypeDouble, size: 22},
+	{name: "LAST_ACT_ROWS", tp: mysql.TypeDouble, size: 22},
+	{name: "LAST_UPDATE_TIME", tp: mysql.TypeDatetime, size: 19},
+}
+
 var tableInspectionResultCols = []columnInfo{
 	{name: "RULE", tp: mysql.TypeVarchar, size: 64},
 	{name: "ITEM", tp: mysql.TypeVarchar, size: 64},
@@ -1960,6 +2011,9 @@ var tableNameToColumns = map[string][]columnInfo{
 	TablePlacementPolicies:                  tablePlacementPoliciesCols,
 	TableTrxSummary:                         tableTrxSummaryCols,
 	TableVariablesInfo:                      tableVariablesInfoCols,
+	TableTiFlashReplicaRecommendations:      tableTiFlashReplicaRecommendationsCols,
+	TableCoprocessorCacheStats:              tableCoprocessorCacheStatsCols,
+	TableCardinalityFeedback:                tableCardinalityFeedbackCols,
 }
 
 func createInfoSchemaTable(_ autoid.Allocators, meta *model.TableInfo) (table.Table, error) {
@@ -378,9 +378,17 @@ type Request struct {
 		MinPagingSize uint64
 		// MaxPagingSize is used when Paging is true.
 		MaxPagingSize uint64
+		// MaxAggPagingSize bounds MaxPagingSize when AggPushDown is true.
+		MaxAggPagingSize uint64
+		// AggPushDown indicates the request contains pushed-down aggregation, the partial results
+		// of every page are merged by TiDB.
+		AggPushDown bool
 	}
 	// RequestSource indicates whether the request is an internal request.
 	RequestSource util.RequestSource
+	// ResultBufferLimit is the ceiling in bytes of the responses buffered and not read yet.
+	// No more tasks are sent until the buffered responses are read below it. 0 means no ceiling.
+	ResultBufferLimit int64
 }
 
 // CoprRequestAdjuster is used to check and adjust a copr request according to specific rules.
@@ -161,6 +161,8 @@ const (
 	InternalTxnTools = "tools"
 	// InternalTxnBR is the type of BR usage.
 	InternalTxnBR = InternalTxnTools
+	// InternalTxnLightning is the type of the import traffic of lightning.
+	InternalTxnLightning = "lightning"
 	// InternalTxnTrace handles the trace statement.
 	InternalTxnTrace = "Trace"
 )
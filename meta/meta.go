@@ -79,6 +79,7 @@ var (
 	mDDLTableVersion    = []byte("DDLTableVersion")
 	mConcurrentDDL      = []byte("concurrentDDL")
 	mInFlashbackCluster = []byte("InFlashbackCluster")
+	mRecycleBin         = []byte("RecycleBin")
 )
 
 const (
@@ -795,6 +796,57 @@ func (m *Meta) ListTables(dbID int64) ([]*model.TableInfo, error) {
 	return tables, nil
 }
 
+// tableBrief is the part of model.TableInfo decoded by ListTableBriefs, the other fields,
+// like the columns and the indices, are skipped without being decoded.
+type tableBrief struct {
+	ID                 int64                     `json:"id"`
+	Name               model.CIStr               `json:"name"`
+	State              model.SchemaState         `json:"state"`
+	Partition          *model.PartitionInfo      `json:"partition"`
+	Lock               *model.TableLockInfo      `json:"Lock"`
+	TiFlashReplica     *model.TiFlashReplicaInfo `json:"tiflash_replica"`
+	TempTableType      model.TempTableType       `json:"temp_table_type"`
+	PlacementPolicyRef *model.PolicyRefInfo      `json:"policy_ref_info"`
+}
+
+// ListTableBriefs shows the brief infos of all tables in database. Only the ID, Name, State,
+// Partition, Lock, TiFlashReplica, TempTableType and PlacementPolicyRef of the table infos
+// are filled.
+func (m *Meta) ListTableBriefs(dbID int64) ([]*model.TableInfo, error) {
+	dbKey := m.dbKey(dbID)
+	if err := m.checkDBExists(dbKey); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	res, err := m.txn.HGetAll(dbKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	tables := make([]*model.TableInfo, 0, len(res)/2)
+	for _, r := range res {
+		// only handle table meta
+		if !IsTableKey(r.Field) {
+			continue
+		}
+		brief := &tableBrief{}
+		if err := json.Unmarshal(r.Value, brief); err != nil {
+			return nil, errors.Trace(err)
+		}
+		tables = append(tables, &model.TableInfo{
+			ID:                 brief.ID,
+			Name:               brief.Name,
+			State:              brief.State,
+			Partition:          brief.Partition,
+			Lock:               brief.Lock,
+			TiFlashReplica:     brief.TiFlashReplica,
+			TempTableType:      brief.TempTableType,
+			PlacementPolicyRef: brief.PlacementPolicyRef,
+		})
+	}
+	return tables, nil
+}
+
 // ListDatabases shows all databases.
 func (m *Meta) ListDatabases() ([]*model.DBInfo, error) {
 	res, err := m.txn.HGetAll(mDBs)
@@ -1225,6 +1277,67 @@ func (i *HLastJobIterator) GetLastJobs(num int, jobs []*model.Job) ([]*model.Job
 	return jobs, nil
 }
 
+// RecycleBinEntry is a dropped table kept in the recycle bin. The meta of the
+// table is gone after it's dropped, so the entry keeps what's needed to recover
+// it, and the data of the table isn't deleted until the entry is removed.
+type RecycleBinEntry struct {
+	// JobID is the ID of the job which drops the table.
+	JobID      int64            `json:"job_id"`
+	SchemaID   int64            `json:"schema_id"`
+	SchemaName string           `json:"schema_name"`
+	TableInfo  *model.TableInfo `json:"table_info"`
+	AutoIDs    AutoIDGroup      `json:"auto_ids"`
+	// DropTS is the start TS of the job which drops the table.
+	DropTS uint64 `json:"drop_ts"`
+	// Size is the approximate size of the table in bytes, 0 if it's unknown yet.
+	Size int64 `json:"size"`
+}
+
+// AddRecycleBinEntry puts the dropped table into the recycle bin, or updates the entry.
+func (m *Meta) AddRecycleBinEntry(entry *RecycleBinEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(m.txn.HSet(mRecycleBin, m.jobIDKey(entry.JobID), data))
+}
+
+// GetRecycleBinEntry gets the recycle bin entry of the drop job, it returns nil
+// if the table isn't in the recycle bin.
+func (m *Meta) GetRecycleBinEntry(jobID int64) (*RecycleBinEntry, error) {
+	value, err := m.txn.HGet(mRecycleBin, m.jobIDKey(jobID))
+	if err != nil || value == nil {
+		return nil, errors.Trace(err)
+	}
+	entry := &RecycleBinEntry{}
+	err = json.Unmarshal(value, entry)
+	return entry, errors.Trace(err)
+}
+
+// DeleteRecycleBinEntry removes the recycle bin entry of the drop job, the data
+// of the table is deleted by the GC afterwards.
+func (m *Meta) DeleteRecycleBinEntry(jobID int64) error {
+	return errors.Trace(m.txn.HDel(mRecycleBin, m.jobIDKey(jobID)))
+}
+
+// ListRecycleBin lists the tables in the recycle bin, ordered by the drop job ID.
+func (m *Meta) ListRecycleBin() ([]*RecycleBinEntry, error) {
+	res, err := m.txn.HGetAll(mRecycleBin)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	entries := make([]*RecycleBinEntry, 0, len(res))
+	for _, r := range res {
+		entry := &RecycleBinEntry{}
+		if err := json.Unmarshal(r.Value, entry); err != nil {
+			return nil, errors.Trace(err)
+		}
+		entries = append(entries, entry)
+	}
+	// the fields are big-endian job IDs, so HGetAll returns them in order.
+	return entries, nil
+}
+
 // GetBootstrapVersion returns the version of the server which bootstrap the store.
 // If the store is not bootstraped, the version will be zero.
 func (m *Meta) GetBootstrapVersion() (int64, error) {
@@ -104,6 +104,53 @@ func TestPlacementPolicy(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestRecycleBin(t *testing.T) {
+	store, err := mockstore.NewMockStore()
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, store.Close())
+	}()
+
+	txn, err := store.Begin()
+	require.NoError(t, err)
+	m := meta.NewMeta(txn)
+
+	entry, err := m.GetRecycleBinEntry(1)
+	require.NoError(t, err)
+	require.Nil(t, entry)
+
+	entries := make([]*meta.RecycleBinEntry, 0, 3)
+	// the entries are listed in the order of the job ID, not the adding order.
+	for _, jobID := range []int64{300, 2, 10} {
+		entry := &meta.RecycleBinEntry{
+			JobID:      jobID,
+			SchemaID:   1,
+			SchemaName: "test",
+			TableInfo:  &model.TableInfo{ID: jobID + 1, Name: model.NewCIStr("t")},
+			AutoIDs:    meta.AutoIDGroup{RowID: 100, RandomID: 5},
+			DropTS:     uint64(jobID),
+		}
+		require.NoError(t, m.AddRecycleBinEntry(entry))
+		entries = append(entries, entry)
+	}
+	list, err := m.ListRecycleBin()
+	require.NoError(t, err)
+	require.Equal(t, []*meta.RecycleBinEntry{entries[1], entries[2], entries[0]}, list)
+
+	entry, err = m.GetRecycleBinEntry(10)
+	require.NoError(t, err)
+	require.Equal(t, entries[2], entry)
+
+	require.NoError(t, m.DeleteRecycleBinEntry(10))
+	entry, err = m.GetRecycleBinEntry(10)
+	require.NoError(t, err)
+	require.Nil(t, entry)
+	list, err = m.ListRecycleBin()
+	require.NoError(t, err)
+	require.Equal(t, []*meta.RecycleBinEntry{entries[1], entries[0]}, list)
+	require.NoError(t, txn.Rollback())
+}
+
 func TestBackupAndRestoreAutoIDs(t *testing.T) {
 	store, err := mockstore.NewMockStore()
 	require.NoError(t, err)
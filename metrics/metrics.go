@@ -188,6 +188,7 @@ func RegisterMetrics() {
 	prometheus.MustRegister(PDAPIExecutionHistogram)
 	prometheus.MustRegister(PDAPIRequestCounter)
 	prometheus.MustRegister(CPUProfileCounter)
+	prometheus.MustRegister(ResultSetBackpressureHistogram)
 	prometheus.MustRegister(ReadFromTableCacheCounter)
 	prometheus.MustRegister(LoadTableCacheDurationHistogram)
 	prometheus.MustRegister(NonTransactionalDeleteCount)
@@ -279,6 +279,15 @@ var (
 			Help:      "Counter of cpu profiling",
 		})
 
+	ResultSetBackpressureHistogram = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "tidb",
+			Subsystem: "server",
+			Name:      "result_set_backpressure_seconds",
+			Help:      "Bucketed histogram of the time (s) the coprocessor tasks wait for the buffered responses to be read.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 22), // 0.5ms ~ 1048s
+		})
+
 	LoadTableCacheDurationHistogram = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
 			Namespace: "tidb",
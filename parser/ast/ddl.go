@@ -74,6 +74,7 @@ const (
 	DatabaseOptionCollate
 	DatabaseOptionEncryption
 	DatabaseSetTiFlashReplica
+	DatabaseOptionReadRouting
 	DatabaseOptionPlacementPolicy = DatabaseOptionType(PlacementOptionPolicy)
 )
 
@@ -107,6 +108,17 @@ func (n *DatabaseOption) Restore(ctx *format.RestoreCtx) error {
 			StrValue:  n.Value,
 		}
 		return placementOpt.Restore(ctx)
+	case DatabaseOptionReadRouting:
+		_ = ctx.WriteWithSpecialComments(tidb.FeatureIDReadRouting, func() error {
+			ctx.WriteKeyWord("READ_ROUTING ")
+			ctx.WritePlain("= ")
+			if n.Value == "" {
+				ctx.WriteKeyWord("DEFAULT")
+			} else {
+				ctx.WriteString(n.Value)
+			}
+			return nil
+		})
 	case DatabaseSetTiFlashReplica:
 		ctx.WriteKeyWord("SET TIFLASH REPLICA ")
 		ctx.WritePlainf("%d", n.TiFlashReplica.Count)
@@ -2066,6 +2078,7 @@ const (
 	TableOptionTableCheckSum
 	TableOptionUnion
 	TableOptionEncryption
+	TableOptionCoprocessorCache
 	TableOptionPlacementPolicy = TableOptionType(PlacementOptionPolicy)
 	TableOptionStatsBuckets    = TableOptionType(StatsOptionBuckets)
 	TableOptionStatsTopN       = TableOptionType(StatsOptionTopN)
@@ -2168,6 +2181,13 @@ func (n *TableOption) Restore(ctx *format.RestoreCtx) error {
 			ctx.WritePlainf("%d", n.UintValue)
 			return nil
 		})
+	case TableOptionCoprocessorCache:
+		_ = ctx.WriteWithSpecialComments(tidb.FeatureIDCoprocessorCache, func() error {
+			ctx.WriteKeyWord("COPROCESSOR_CACHE ")
+			ctx.WritePlain("= ")
+			ctx.WritePlainf("%d", n.UintValue)
+			return nil
+		})
 	case TableOptionAutoRandomBase:
 		if n.BoolValue {
 			_ = ctx.WriteWithSpecialComments(tidb.FeatureIDForceAutoInc, func() error {
@@ -3733,6 +3753,11 @@ type PartitionInterval struct {
 	LastRangeEnd  *ExprNode
 	MaxValPart    bool
 	NullPart      bool
+	// AutoCreate is set by AUTO CREATE, new partitions are created automatically as the time goes,
+	// not as the rows beyond the last partition are inserted.
+	AutoCreate bool
+	// Retention is set by RETENTION (expr unit), the partitions older than it are dropped automatically.
+	Retention *PartitionIntervalExpr
 }
 
 // PartitionMethod describes how partitions or subpartitions are constructed.
@@ -3842,6 +3867,17 @@ func (n *PartitionMethod) Restore(ctx *format.RestoreCtx) error {
 		if n.Interval.MaxValPart {
 			ctx.WritePlain(" MAXVALUE PARTITION")
 		}
+		if n.Interval.AutoCreate {
+			ctx.WriteKeyWord(" AUTO CREATE")
+		}
+		if n.Interval.Retention != nil {
+			ctx.WriteKeyWord(" RETENTION ")
+			ctx.WritePlain("(")
+			n.Interval.Retention.Expr.Restore(ctx)
+			ctx.WritePlain(" ")
+			ctx.WriteKeyWord(n.Interval.Retention.TimeUnit.String())
+			ctx.WritePlain(")")
+		}
 	}
 
 	return nil
@@ -4103,6 +4139,39 @@ func (n *FlashBackTableStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// PurgeTableStmt is a statement to remove a dropped table from the recycle bin,
+// so its data is deleted by the next GC.
+type PurgeTableStmt struct {
+	ddlNode
+
+	Table *TableName
+}
+
+// Restore implements Node interface.
+func (n *PurgeTableStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("PURGE TABLE ")
+	if err := n.Table.Restore(ctx); err != nil {
+		return errors.Annotate(err, "An error occurred while splicing PurgeTableStmt Table")
+	}
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *PurgeTableStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+
+	n = newNode.(*PurgeTableStmt)
+	node, ok := n.Table.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Table = node.(*TableName)
+	return v.Leave(n)
+}
+
 type AttributesSpec struct {
 	node
 
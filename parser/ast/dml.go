@@ -14,6 +14,8 @@
 package ast
 
 import (
+	"strings"
+
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb/parser/auth"
 	"github.com/pingcap/tidb/parser/format"
@@ -596,6 +598,90 @@ func (n *TableSource) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// ExternalTableSource represents the EXTERNAL table function, which reads the files
+// in the external storage as a table, e.g.
+// `EXTERNAL('s3://bucket/path/*.parquet', FORMAT => 'parquet')`.
+type ExternalTableSource struct {
+	node
+
+	// Location is the URL of the files, the file name may contain wildcards.
+	Location string
+	// Options are the named arguments of the table function.
+	Options []*ExternalTableOption
+}
+
+// ExternalTableOption is a named argument of the EXTERNAL table function.
+type ExternalTableOption struct {
+	// Name is the upper-case name of the argument.
+	Name  string
+	Value string
+}
+
+func (*ExternalTableSource) resultSet() {}
+
+// Restore implements Node interface.
+func (n *ExternalTableSource) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("EXTERNAL")
+	ctx.WritePlain("(")
+	ctx.WriteString(n.Location)
+	for _, opt := range n.Options {
+		ctx.WritePlain(", ")
+		ctx.WriteKeyWord(opt.Name)
+		ctx.WritePlain(" => ")
+		ctx.WriteString(opt.Value)
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *ExternalTableSource) Accept(v Visitor) (Node, bool) {
+	newNode, _ := v.Enter(n)
+	return v.Leave(newNode)
+}
+
+type externalTableSourceCollector struct {
+	sources []*ExternalTableSource
+}
+
+func (c *externalTableSourceCollector) Enter(n Node) (Node, bool) {
+	if source, ok := n.(*ExternalTableSource); ok {
+		c.sources = append(c.sources, source)
+	}
+	return n, false
+}
+
+func (c *externalTableSourceCollector) Leave(n Node) (Node, bool) {
+	return n, true
+}
+
+// ExternalTableSecureText returns the text of the statement with the credentials in the
+// locations of the EXTERNAL table functions redacted, like the storage URLs of BR and
+// IMPORT. It returns false if the statement doesn't read any EXTERNAL table function.
+func ExternalTableSecureText(node StmtNode) (string, bool) {
+	collector := &externalTableSourceCollector{}
+	node.Accept(collector)
+	if len(collector.sources) == 0 {
+		return "", false
+	}
+	locations := make([]string, 0, len(collector.sources))
+	for _, source := range collector.sources {
+		locations = append(locations, source.Location)
+		source.Location = redactS3Credentials(source.Location)
+	}
+	defer func() {
+		for i, source := range collector.sources {
+			source.Location = locations[i]
+		}
+	}()
+	var sb strings.Builder
+	if err := node.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb)); err != nil {
+		// Never fall back to the original text.
+		return "", true
+	}
+	return sb.String(), true
+}
+
 // SelectLockType is the lock type for SelectStmt.
 type SelectLockType int
 
@@ -2679,6 +2765,7 @@ const (
 	ShowPlacementForPartition
 	ShowPlacementLabels
 	ShowSessionStates
+	ShowRecycleBin
 )
 
 const (
@@ -2711,6 +2798,7 @@ type ShowStmt struct {
 	Roles       []*auth.RoleIdentity // Used for show grants .. using
 	IfNotExists bool                 // Used for `show create database if not exists`
 	Extended    bool                 // Used for `show extended columns from ...`
+	AsOf        *AsOfClause          // Used for `show create table ... as of timestamp ...`
 
 	CountWarningsOrErrors bool // Used for showing count(*) warnings | errors
 
@@ -2767,6 +2855,12 @@ func (n *ShowStmt) Restore(ctx *format.RestoreCtx) error {
 		if err := n.Table.Restore(ctx); err != nil {
 			return errors.Annotate(err, "An error occurred while restore ShowStmt.Table")
 		}
+		if n.AsOf != nil {
+			ctx.WritePlain(" ")
+			if err := n.AsOf.Restore(ctx); err != nil {
+				return errors.Annotate(err, "An error occurred while restore ShowStmt.AsOf")
+			}
+		}
 	case ShowCreateView:
 		ctx.WriteKeyWord("CREATE VIEW ")
 		if err := n.Table.Restore(ctx); err != nil {
@@ -3032,6 +3126,8 @@ func (n *ShowStmt) Restore(ctx *format.RestoreCtx) error {
 			ctx.WriteKeyWord("PLACEMENT LABELS")
 		case ShowSessionStates:
 			ctx.WriteKeyWord("SESSION_STATES")
+		case ShowRecycleBin:
+			ctx.WriteKeyWord("RECYCLE BIN")
 		default:
 			return errors.New("Unknown ShowStmt type")
 		}
@@ -3076,6 +3172,13 @@ func (n *ShowStmt) Accept(v Visitor) (Node, bool) {
 		}
 		n.Where = node.(ExprNode)
 	}
+	if n.AsOf != nil {
+		node, ok := n.AsOf.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.AsOf = node.(*AsOfClause)
+	}
 	return v.Leave(n)
 }
 
@@ -34,6 +34,8 @@ var (
 	_ StmtNode = &BinlogStmt{}
 	_ StmtNode = &CommitStmt{}
 	_ StmtNode = &CreateUserStmt{}
+	_ StmtNode = &CreateServerStmt{}
+	_ StmtNode = &DropServerStmt{}
 	_ StmtNode = &DeallocateStmt{}
 	_ StmtNode = &DoStmt{}
 	_ StmtNode = &ExecuteStmt{}
@@ -210,6 +212,9 @@ type ExplainStmt struct {
 	Stmt    StmtNode
 	Format  string
 	Analyze bool
+	// Outfile is the file the result is written into, it's set by
+	// `EXPLAIN FORMAT = ... INTO OUTFILE 'file_name' stmt`.
+	Outfile string
 }
 
 // Restore implements Node interface.
@@ -231,12 +236,17 @@ func (n *ExplainStmt) Restore(ctx *format.RestoreCtx) error {
 	if n.Analyze {
 		ctx.WriteKeyWord("ANALYZE ")
 	}
-	if !n.Analyze || strings.ToLower(n.Format) != "row" {
+	if !n.Analyze || strings.ToLower(n.Format) != "row" || n.Outfile != "" {
 		ctx.WriteKeyWord("FORMAT ")
 		ctx.WritePlain("= ")
 		ctx.WriteString(n.Format)
 		ctx.WritePlain(" ")
 	}
+	if n.Outfile != "" {
+		ctx.WriteKeyWord("INTO OUTFILE ")
+		ctx.WriteString(n.Outfile)
+		ctx.WritePlain(" ")
+	}
 	if err := n.Stmt.Restore(ctx); err != nil {
 		return errors.Annotate(err, "An error occurred while restore ExplainStmt.Stmt")
 	}
@@ -924,6 +934,10 @@ type KillStmt struct {
 	// So, "KILL TIDB" grammar is introduced, and it REQUIRES DIRECT client -> TiDB TOPOLOGY.
 	// TODO: The standard KILL grammar will be supported once we have global connectionID.
 	TiDBExtension bool
+	// Digest is set by "KILL DIGEST 'digest'", which terminates the running statements of the given
+	// SQL digest or plan digest on all the tidb-servers. ConnectionID is ignored then, except for the
+	// kill sent to another tidb-server, which only terminates the statement of the connection.
+	Digest string
 }
 
 // Restore implements Node interface.
@@ -932,6 +946,11 @@ func (n *KillStmt) Restore(ctx *format.RestoreCtx) error {
 	if n.TiDBExtension {
 		ctx.WriteKeyWord(" TIDB")
 	}
+	if n.Digest != "" {
+		ctx.WriteKeyWord(" DIGEST ")
+		ctx.WriteString(n.Digest)
+		return nil
+	}
 	if n.Query {
 		ctx.WriteKeyWord(" QUERY")
 	}
@@ -1716,6 +1735,136 @@ func (n *DropUserStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// Server options of CREATE SERVER statement.
+const (
+	ServerOptionHost     = "HOST"
+	ServerOptionDatabase = "DATABASE"
+	ServerOptionUser     = "USER"
+	ServerOptionPassword = "PASSWORD"
+	ServerOptionSocket   = "SOCKET"
+	ServerOptionOwner    = "OWNER"
+	ServerOptionPort     = "PORT"
+)
+
+// ServerOption is an option of CREATE SERVER statement. PORT has a number value,
+// and the others have string values.
+type ServerOption struct {
+	Name      string
+	StrValue  string
+	UintValue uint64
+}
+
+// Restore implements Node interface.
+func (n *ServerOption) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord(n.Name)
+	ctx.WritePlain(" ")
+	if n.Name == ServerOptionPort {
+		ctx.WritePlainf("%d", n.UintValue)
+	} else {
+		ctx.WriteString(n.StrValue)
+	}
+	return nil
+}
+
+// CreateServerStmt defines a remote server which federated tables read from.
+// See https://dev.mysql.com/doc/refman/8.0/en/create-server.html
+type CreateServerStmt struct {
+	stmtNode
+
+	IfNotExists bool
+	Name        string
+	Wrapper     string
+	Options     []*ServerOption
+}
+
+// Restore implements Node interface.
+func (n *CreateServerStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("CREATE SERVER ")
+	if n.IfNotExists {
+		ctx.WriteKeyWord("IF NOT EXISTS ")
+	}
+	ctx.WriteName(n.Name)
+	ctx.WriteKeyWord(" FOREIGN DATA WRAPPER ")
+	ctx.WriteName(n.Wrapper)
+	ctx.WriteKeyWord(" OPTIONS ")
+	ctx.WritePlain("(")
+	for i, option := range n.Options {
+		if i != 0 {
+			ctx.WritePlain(", ")
+		}
+		if err := option.Restore(ctx); err != nil {
+			return errors.Annotatef(err, "An error occurred while restore CreateServerStmt.Options[%d]", i)
+		}
+	}
+	ctx.WritePlain(")")
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *CreateServerStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateServerStmt)
+	return v.Leave(n)
+}
+
+// SecureText implements SensitiveStatement interface.
+func (n *CreateServerStmt) SecureText() string {
+	var buf bytes.Buffer
+	buf.WriteString("create server ")
+	buf.WriteString(n.Name)
+	buf.WriteString(" foreign data wrapper ")
+	buf.WriteString(n.Wrapper)
+	buf.WriteString(" options (")
+	for i, option := range n.Options {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(strings.ToLower(option.Name))
+		switch option.Name {
+		case ServerOptionPort:
+			fmt.Fprintf(&buf, " %d", option.UintValue)
+		case ServerOptionPassword:
+			buf.WriteString(" 'xxxxxx'")
+		default:
+			fmt.Fprintf(&buf, " '%s'", option.StrValue)
+		}
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+// DropServerStmt drops a remote server.
+// See https://dev.mysql.com/doc/refman/8.0/en/drop-server.html
+type DropServerStmt struct {
+	stmtNode
+
+	IfExists bool
+	Name     string
+}
+
+// Restore implements Node interface.
+func (n *DropServerStmt) Restore(ctx *format.RestoreCtx) error {
+	ctx.WriteKeyWord("DROP SERVER ")
+	if n.IfExists {
+		ctx.WriteKeyWord("IF EXISTS ")
+	}
+	ctx.WriteName(n.Name)
+	return nil
+}
+
+// Accept implements Node Accept interface.
+func (n *DropServerStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DropServerStmt)
+	return v.Leave(n)
+}
+
 // CreateBindingStmt creates sql binding hint.
 type CreateBindingStmt struct {
 	stmtNode
@@ -2053,6 +2202,8 @@ const (
 	AdminResetTelemetryID
 	AdminReloadStatistics
 	AdminFlushPlanCache
+	AdminExportStats
+	AdminImportStats
 )
 
 // HandleRange represents a range where handle value >= Begin and < End.
@@ -2148,6 +2299,8 @@ type AdminStmt struct {
 	Where          ExprNode
 	StatementScope StatementScope
 	LimitSimple    LimitSimple
+	// Storage is the external storage URL of ADMIN EXPORT STATS and ADMIN IMPORT STATS.
+	Storage string
 }
 
 // Restore implements Node interface.
@@ -2296,12 +2449,50 @@ func (n *AdminStmt) Restore(ctx *format.RestoreCtx) error {
 		} else if n.StatementScope == StatementScopeGlobal {
 			ctx.WriteKeyWord("FLUSH GLOBAL PLAN_CACHE")
 		}
+	case AdminExportStats:
+		ctx.WriteKeyWord("EXPORT STATS TO ")
+		ctx.WriteString(n.Storage)
+	case AdminImportStats:
+		ctx.WriteKeyWord("IMPORT STATS FROM ")
+		ctx.WriteString(n.Storage)
 	default:
 		return errors.New("Unsupported AdminStmt type")
 	}
 	return nil
 }
 
+// SecureText implements SensitiveStmtNode. The credentials in the storage URL of
+// ADMIN EXPORT STATS and ADMIN IMPORT STATS are redacted.
+func (n *AdminStmt) SecureText() string {
+	if n.Tp != AdminExportStats && n.Tp != AdminImportStats {
+		return n.Text()
+	}
+	redactedStmt := &AdminStmt{
+		Tp:      n.Tp,
+		Storage: redactS3Credentials(n.Storage),
+	}
+	var sb strings.Builder
+	_ = redactedStmt.Restore(format.NewRestoreCtx(format.DefaultRestoreFlags, &sb))
+	return sb.String()
+}
+
+// redactS3Credentials hides the access keys in the query of an S3 storage URL.
+func redactS3Credentials(storage string) string {
+	u, err := url.Parse(storage)
+	if err != nil || u.Scheme != "s3" {
+		return storage
+	}
+	query := u.Query()
+	for key := range query {
+		switch strings.ToLower(strings.ReplaceAll(key, "_", "-")) {
+		case "access-key", "secret-access-key":
+			query[key] = []string{"xxxxxx"}
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
 // Accept implements Node Accept interface.
 func (n *AdminStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
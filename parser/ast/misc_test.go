@@ -342,6 +342,39 @@ func TestBRIESecureText(t *testing.T) {
 	}
 }
 
+func TestAdminStatsSecureText(t *testing.T) {
+	p := parser.New()
+	node, err := p.ParseOneStmt("admin export stats to 's3://bucket/stats?secret-access-key=123'", "", "")
+	require.NoError(t, err)
+	require.Equal(t, "ADMIN EXPORT STATS TO 's3://bucket/stats?secret-access-key=xxxxxx'", node.(ast.SensitiveStmtNode).SecureText())
+
+	node, err = p.ParseOneStmt("admin import stats from 'local:///tmp/stats'", "", "")
+	require.NoError(t, err)
+	require.Equal(t, "ADMIN IMPORT STATS FROM 'local:///tmp/stats'", node.(ast.SensitiveStmtNode).SecureText())
+
+	sql := "admin  check table t"
+	node, err = p.ParseOneStmt(sql, "", "")
+	require.NoError(t, err)
+	require.Equal(t, sql, node.(ast.SensitiveStmtNode).SecureText())
+}
+
+func TestExternalTableSecureText(t *testing.T) {
+	p := parser.New()
+	sql := "select * from external('s3://bucket/*.csv?secret-access-key=123', format => 'csv') t join external('local:///tmp/*.csv') using (a)"
+	node, err := p.ParseOneStmt(sql, "", "")
+	require.NoError(t, err)
+	secured, ok := ast.ExternalTableSecureText(node)
+	require.True(t, ok)
+	require.Equal(t, "SELECT * FROM EXTERNAL('s3://bucket/*.csv?secret-access-key=xxxxxx', FORMAT => 'csv') AS `t` JOIN EXTERNAL('local:///tmp/*.csv') USING (`a`)", secured)
+	// the statement is unchanged.
+	require.Equal(t, "s3://bucket/*.csv?secret-access-key=123", node.(*ast.SelectStmt).From.TableRefs.Left.(*ast.TableSource).Source.(*ast.ExternalTableSource).Location)
+
+	node, err = p.ParseOneStmt("select * from t", "", "")
+	require.NoError(t, err)
+	_, ok = ast.ExternalTableSecureText(node)
+	require.False(t, ok)
+}
+
 func TestCompactTableStmtRestore(t *testing.T) {
 	testCases := []NodeRestoreTestCase{
 		{"alter table abc compact tiflash replica", "ALTER TABLE `abc` COMPACT TIFLASH REPLICA"},
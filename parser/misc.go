@@ -103,6 +103,7 @@ func init() {
 	initTokenString("&^", andnot)
 	initTokenString(":=", assignmentEq)
 	initTokenString("<=>", nulleq)
+	initTokenString("=>", eqGt)
 	initTokenString(">=", ge)
 	initTokenString("<=", le)
 	initTokenString("!=", neq)
@@ -166,6 +167,7 @@ var tokenMap = map[string]int{
 	"AUTO_INCREMENT":           autoIncrement,
 	"AUTO_RANDOM":              autoRandom,
 	"AUTO_RANDOM_BASE":         autoRandomBase,
+	"AUTO":                     auto,
 	"AVG_ROW_LENGTH":           avgRowLength,
 	"AVG":                      avg,
 	"BACKEND":                  backend,
@@ -174,6 +176,7 @@ var tokenMap = map[string]int{
 	"BEGIN":                    begin,
 	"BETWEEN":                  between,
 	"BERNOULLI":                bernoulli,
+	"BIN":                      bin,
 	"BIGINT":                   bigIntType,
 	"BINARY":                   binaryType,
 	"BINDING":                  binding,
@@ -243,6 +246,7 @@ var tokenMap = map[string]int{
 	"CONSTRAINTS":              constraints,
 	"CONTEXT":                  context,
 	"CONVERT":                  convert,
+	"COPROCESSOR_CACHE":        coprocessorCache,
 	"COPY":                     copyKwd,
 	"CORRELATION":              correlation,
 	"CPU":                      cpu,
@@ -288,6 +292,7 @@ var tokenMap = map[string]int{
 	"DEPTH":                    depth,
 	"DESC":                     desc,
 	"DESCRIBE":                 describe,
+	"DIGEST":                   digest,
 	"DIRECTORY":                directory,
 	"DISABLE":                  disable,
 	"DISABLED":                 disabled,
@@ -331,9 +336,11 @@ var tokenMap = map[string]int{
 	"EXISTS":                   exists,
 	"EXPANSION":                expansion,
 	"EXPIRE":                   expire,
+	"EXPORT":                   export,
 	"EXPLAIN":                  explain,
 	"EXPR_PUSHDOWN_BLACKLIST":  exprPushdownBlacklist,
 	"EXTENDED":                 extended,
+	"EXTERNAL":                 external,
 	"EXTRACT":                  extract,
 	"FALSE":                    falseKwd,
 	"FAULTS":                   faultsSym,
@@ -376,6 +383,7 @@ var tokenMap = map[string]int{
 	"HOUR_MINUTE":              hourMinute,
 	"HOUR_SECOND":              hourSecond,
 	"HOUR":                     hour,
+	"HYPOTHETICAL":             hypothetical,
 	"IDENTIFIED":               identified,
 	"IF":                       ifKwd,
 	"IGNORE":                   ignore,
@@ -520,6 +528,7 @@ var tokenMap = map[string]int{
 	"OPTION":                   option,
 	"OPTIONAL":                 optional,
 	"OPTIONALLY":               optionally,
+	"OPTIONS":                  options,
 	"OR":                       or,
 	"ORDER":                    order,
 	"OUTER":                    outer,
@@ -566,10 +575,12 @@ var tokenMap = map[string]int{
 	"RANGE":                    rangeKwd,
 	"RATE_LIMIT":               rateLimit,
 	"READ":                     read,
+	"READ_ROUTING":             readRouting,
 	"REAL":                     realType,
 	"REBUILD":                  rebuild,
 	"RECENT":                   recent,
 	"RECOVER":                  recover,
+	"RECYCLE":                  recycle,
 	"RECURSIVE":                recursive,
 	"REDUNDANT":                redundant,
 	"REFERENCES":               references,
@@ -610,6 +621,7 @@ var tokenMap = map[string]int{
 	"ROWS":                     rows,
 	"RTREE":                    rtree,
 	"RESUME":                   resume,
+	"RETENTION":                retention,
 	"RUN":                      run,
 	"RUNNING":                  running,
 	"S3":                       s3,
@@ -632,6 +644,7 @@ var tokenMap = map[string]int{
 	"SEQUENCE":                 sequence,
 	"SERIAL":                   serial,
 	"SERIALIZABLE":             serializable,
+	"SERVER":                   server,
 	"SESSION":                  session,
 	"SESSION_STATES":           sessionStates,
 	"SET":                      set,
@@ -799,6 +812,7 @@ var tokenMap = map[string]int{
 	"WIDTH":                    width,
 	"WITH":                     with,
 	"WITHOUT":                  without,
+	"WRAPPER":                  wrapper,
 	"WRITE":                    write,
 	"X509":                     x509,
 	"XOR":                      xor,
@@ -97,6 +97,8 @@ const (
 	ActionCreateTables                  ActionType = 60
 	ActionMultiSchemaChange             ActionType = 61
 	ActionFlashbackCluster              ActionType = 62
+	ActionModifyTableCoprCache          ActionType = 63
+	ActionModifySchemaReadRouting       ActionType = 64
 )
 
 var actionMap = map[ActionType]string{
@@ -158,6 +160,8 @@ var actionMap = map[ActionType]string{
 	ActionAlterTableStatsOptions:        "alter table statistics options",
 	ActionMultiSchemaChange:             "alter table multi-schema change",
 	ActionFlashbackCluster:              "flashback cluster",
+	ActionModifyTableCoprCache:          "modify table coprocessor cache",
+	ActionModifySchemaReadRouting:       "modify schema read routing",
 
 	// `ActionAlterTableAlterPartition` is removed and will never be used.
 	// Just left a tombstone here for compatibility.
@@ -767,13 +771,14 @@ func (job *Job) IsRollbackable() bool {
 	case ActionAddTablePartition:
 		return job.SchemaState == StateNone || job.SchemaState == StateReplicaOnly
 	case ActionDropColumn, ActionDropSchema, ActionDropTable, ActionDropSequence,
-		ActionDropForeignKey, ActionDropTablePartition:
+		ActionDropForeignKey, ActionDropTablePartition, ActionDropCheckConstraint:
 		return job.SchemaState == StatePublic
 	case ActionRebaseAutoID, ActionShardRowID,
 		ActionTruncateTable, ActionAddForeignKey, ActionRenameTable,
 		ActionModifyTableCharsetAndCollate, ActionTruncateTablePartition,
 		ActionModifySchemaCharsetAndCollate, ActionRepairTable,
-		ActionModifyTableAutoIdCache, ActionModifySchemaDefaultPlacement:
+		ActionModifyTableAutoIdCache, ActionModifySchemaDefaultPlacement,
+		ActionModifySchemaReadRouting:
 		return job.SchemaState == StateNone
 	case ActionMultiSchemaChange:
 		return job.MultiSchemaInfo.Revertible
@@ -104,8 +104,13 @@ type ColumnInfo struct {
 	Offset                int         `json:"offset"`
 	OriginDefaultValue    interface{} `json:"origin_default"`
 	OriginDefaultValueBit []byte      `json:"origin_default_bit"`
-	DefaultValue          interface{} `json:"default"`
-	DefaultValueBit       []byte      `json:"default_bit"`
+	// OriginDefaultFunc is the non-constant function, e.g. uuid(), used as the default value when the column is added.
+	// The values of the rows existing before are evaluated lazily from OriginDefaultSeed and the handles of the rows
+	// instead of OriginDefaultValue, see expression.LazyDefaultValue.
+	OriginDefaultFunc string      `json:"origin_default_func,omitempty"`
+	OriginDefaultSeed uint64      `json:"origin_default_seed,omitempty"`
+	DefaultValue      interface{} `json:"default"`
+	DefaultValueBit   []byte      `json:"default_bit"`
 	// DefaultIsExpr is indicates the default value string is expr.
 	DefaultIsExpr       bool                `json:"default_is_expr"`
 	GeneratedExprString string              `json:"generated_expr_string"`
@@ -226,6 +231,11 @@ func (c *ColumnInfo) IsGenerated() bool {
 	return len(c.GeneratedExprString) != 0
 }
 
+// HasLazyOriginDefault returns true if the values of the column in the rows existing before it's added are evaluated lazily.
+func (c *ColumnInfo) HasLazyOriginDefault() bool {
+	return len(c.OriginDefaultFunc) != 0
+}
+
 // SetOriginDefaultValue sets the origin default value.
 // For mysql.TypeBit type, the default value storage format must be a string.
 // Other value such as int must convert to string format first.
@@ -466,6 +476,14 @@ type TableInfo struct {
 	StatsOptions *StatsOptions `json:"stats_options"`
 
 	ExchangePartitionInfo *ExchangePartitionInfo `json:"exchange_partition_info"`
+
+	// Federated is set if the table is created with ENGINE=FEDERATED, whose rows are
+	// read from a table of a remote server.
+	Federated *FederatedInfo `json:"federated,omitempty"`
+
+	// CoprCacheDisabled is set if the coprocessor responses of the table are not
+	// cached, since the cache is thrashed by frequently updated tables.
+	CoprCacheDisabled bool `json:"copr_cache_disabled,omitempty"`
 }
 
 // TableCacheStatusType is the type of the table cache status
@@ -662,6 +680,13 @@ func (t *TableInfo) Clone() *TableInfo {
 		nt.Indices[i] = t.Indices[i].Clone()
 	}
 
+	if t.Constraints != nil {
+		nt.Constraints = make([]*ConstraintInfo, len(t.Constraints))
+		for i := range t.Constraints {
+			nt.Constraints[i] = t.Constraints[i].Clone()
+		}
+	}
+
 	for i := range t.ForeignKeys {
 		nt.ForeignKeys[i] = t.ForeignKeys[i].Clone()
 	}
@@ -709,6 +734,16 @@ func (t *TableInfo) IsAutoIncColUnsigned() bool {
 	return mysql.HasUnsignedFlag(col.GetFlag())
 }
 
+// HasLazyOriginDefaultColumns indicates whether a table contains columns whose origin default values are evaluated lazily.
+func (t *TableInfo) HasLazyOriginDefaultColumns() bool {
+	for _, col := range t.Columns {
+		if col.HasLazyOriginDefault() {
+			return true
+		}
+	}
+	return false
+}
+
 // ContainsAutoRandomBits indicates whether a table contains auto_random column.
 func (t *TableInfo) ContainsAutoRandomBits() bool {
 	return t.AutoRandomBits != 0
@@ -927,6 +962,11 @@ func (t *TableInfo) IsSequence() bool {
 	return t.Sequence != nil
 }
 
+// IsFederated checks if TableInfo is a federated table.
+func (t *TableInfo) IsFederated() bool {
+	return t.Federated != nil
+}
+
 // IsBaseTable checks to see the table is neither a view or a sequence.
 func (t *TableInfo) IsBaseTable() bool {
 	return t.Sequence == nil && t.View == nil
@@ -1080,6 +1120,14 @@ type ExchangePartitionInfo struct {
 	ExchangePartitionDefID int64 `json:"exchange_partition_def_id"`
 }
 
+// FederatedInfo provides the remote table of a federated table.
+type FederatedInfo struct {
+	// Server is the name of the server created by CREATE SERVER.
+	Server string `json:"server"`
+	// Table is the name of the remote table in the database of the server.
+	Table string `json:"table"`
+}
+
 // PartitionInfo provides table partition info.
 type PartitionInfo struct {
 	Type    PartitionType `json:"type"`
@@ -1098,6 +1146,20 @@ type PartitionInfo struct {
 	DroppingDefinitions []PartitionDefinition `json:"dropping_definitions"`
 	States              []PartitionState      `json:"states"`
 	Num                 uint64                `json:"num"`
+	// AutoInterval is set when the table is created with INTERVAL ... AUTO CREATE,
+	// the owner then creates new partitions ahead of its current time and drops
+	// expired ones in background. Inserting rows beyond the last partition doesn't
+	// create partitions.
+	AutoInterval *PartitionIntervalInfo `json:"auto_interval,omitempty"`
+}
+
+// PartitionIntervalInfo records the INTERVAL ... AUTO CREATE options of a range partitioned table.
+type PartitionIntervalInfo struct {
+	Interval int64  `json:"interval"`
+	Unit     string `json:"unit"`
+	// Retention is zero if expired partitions are never dropped automatically.
+	Retention     int64  `json:"retention"`
+	RetentionUnit string `json:"retention_unit"`
 }
 
 // Clone clones itself.
@@ -1121,6 +1183,11 @@ func (pi *PartitionInfo) Clone() *PartitionInfo {
 		newPi.DroppingDefinitions[i] = pi.DroppingDefinitions[i].Clone()
 	}
 
+	if pi.AutoInterval != nil {
+		autoInterval := *pi.AutoInterval
+		newPi.AutoInterval = &autoInterval
+	}
+
 	return &newPi
 }
 
@@ -1271,6 +1338,8 @@ func (t IndexType) String() string {
 		return "HASH"
 	case IndexTypeRtree:
 		return "RTREE"
+	case IndexTypeHypothetical:
+		return "HYPOTHETICAL"
 	default:
 		return ""
 	}
@@ -1282,8 +1351,15 @@ const (
 	IndexTypeBtree
 	IndexTypeHash
 	IndexTypeRtree
+	// IndexTypeHypothetical is only visible to the optimizer in EXPLAIN, it is never built.
+	IndexTypeHypothetical
 )
 
+// HypoIndexIDBase is the first ID of the hypothetical indexes. The real indexes never take
+// IDs from this space, so a hypothetical index is never mistaken for a real one, e.g. when
+// looking up the statistics of an index by its ID.
+const HypoIndexIDBase int64 = 1 << 62
+
 // IndexInfo provides meta data describing a DB index.
 // It corresponds to the statement `CREATE INDEX Name ON Table (Column);`
 // See https://dev.mysql.com/doc/refman/5.7/en/create-index.html
@@ -1321,6 +1397,12 @@ func (index *IndexInfo) HasPrefixIndex() bool {
 	return false
 }
 
+// IsClusteredUniqueKey returns whether the index is a unique key chosen as the clustered index of the table.
+// Such an index is stored as the primary index of the table, but keeps its own name.
+func (index *IndexInfo) IsClusteredUniqueKey() bool {
+	return index.Primary && index.Name.L != strings.ToLower(mysql.PrimaryKeyName)
+}
+
 // HasColumnInIndexColumns checks whether the index contains the column with the specified ID.
 func (index *IndexInfo) HasColumnInIndexColumns(tblInfo *TableInfo, colID int64) bool {
 	for _, ic := range index.Columns {
@@ -1357,6 +1439,9 @@ type ConstraintInfo struct {
 	InColumn       bool        `json:"in_column"` // Indicate whether the constraint is column type check.
 	ExprString     string      `json:"expr_string"`
 	State          SchemaState `json:"state"`
+	// ValidatedTS is the TS when the existing rows were validated against the enforced constraint,
+	// it's 0 if they aren't validated since the constraint is enforced.
+	ValidatedTS uint64 `json:"validated_ts,omitempty"`
 }
 
 // Clone clones ConstraintInfo.
@@ -1430,6 +1515,30 @@ type DBInfo struct {
 	Tables             []*TableInfo   `json:"-"` // Tables in the DB.
 	State              SchemaState    `json:"state"`
 	PlacementPolicyRef *PolicyRefInfo `json:"policy_ref_info"`
+	// ReadRouting is the read routing policy of the tables in the DB, which
+	// is used if tidb_replica_read of the session is the default.
+	ReadRouting string `json:"read_routing,omitempty"`
+}
+
+// Read routing policies of DB.
+const (
+	// ReadRoutingPreferLocalZone reads from the replicas in the same zone
+	// as TiDB, and falls back to the other replicas.
+	ReadRoutingPreferLocalZone = "prefer-local-zone"
+	// ReadRoutingPrimaryOnly only reads from the leaders.
+	ReadRoutingPrimaryOnly = "primary-only"
+	// ReadRoutingNearestFollower reads from the nearest replicas by the
+	// labels of the stores.
+	ReadRoutingNearestFollower = "nearest-follower"
+)
+
+// IsValidReadRoutingPolicy checks whether policy is a valid read routing policy.
+func IsValidReadRoutingPolicy(policy string) bool {
+	switch policy {
+	case ReadRoutingPreferLocalZone, ReadRoutingPrimaryOnly, ReadRoutingNearestFollower:
+		return true
+	}
+	return false
 }
 
 // Clone clones DBInfo.
@@ -549,6 +549,8 @@ func TestString(t *testing.T) {
 		{ActionAlterTablePlacement, "alter table placement"},
 		{ActionAlterTablePartitionPlacement, "alter table partition placement"},
 		{ActionAlterNoCacheTable, "alter table nocache"},
+		{ActionModifyTableCoprCache, "modify table coprocessor cache"},
+		{ActionModifySchemaReadRouting, "modify schema read routing"},
 	}
 
 	for _, v := range acts {
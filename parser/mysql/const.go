@@ -204,6 +204,8 @@ const (
 	RoleEdgeTable = "role_edges"
 	// DefaultRoleTable is the table contain default active role info
 	DefaultRoleTable = "default_roles"
+	// ServersTable is the table contains the servers created by CREATE SERVER.
+	ServersTable = "servers"
 )
 
 // MySQL type maximum length.
@@ -305,6 +305,7 @@ import (
 	autoIdCache           "AUTO_ID_CACHE"
 	autoIncrement         "AUTO_INCREMENT"
 	autoRandom            "AUTO_RANDOM"
+	auto                  "AUTO"
 	autoRandomBase        "AUTO_RANDOM_BASE"
 	avg                   "AVG"
 	avgRowLength          "AVG_ROW_LENGTH"
@@ -313,6 +314,7 @@ import (
 	backups               "BACKUPS"
 	begin                 "BEGIN"
 	bernoulli             "BERNOULLI"
+	bin                   "BIN"
 	binding               "BINDING"
 	bindingCache          "BINDING_CACHE"
 	bindings              "BINDINGS"
@@ -351,6 +353,7 @@ import (
 	consistency           "CONSISTENCY"
 	consistent            "CONSISTENT"
 	context               "CONTEXT"
+	coprocessorCache      "COPROCESSOR_CACHE"
 	cpu                   "CPU"
 	csvBackslashEscape    "CSV_BACKSLASH_ESCAPE"
 	csvDelimiter          "CSV_DELIMITER"
@@ -370,6 +373,7 @@ import (
 	deallocate            "DEALLOCATE"
 	definer               "DEFINER"
 	delayKeyWrite         "DELAY_KEY_WRITE"
+	digest                "DIGEST"
 	directory             "DIRECTORY"
 	disable               "DISABLE"
 	disabled              "DISABLED"
@@ -396,7 +400,9 @@ import (
 	execute               "EXECUTE"
 	expansion             "EXPANSION"
 	expire                "EXPIRE"
+	export                "EXPORT"
 	extended              "EXTENDED"
+	external              "EXTERNAL"
 	faultsSym             "FAULTS"
 	fields                "FIELDS"
 	file                  "FILE"
@@ -416,6 +422,7 @@ import (
 	history               "HISTORY"
 	hosts                 "HOSTS"
 	hour                  "HOUR"
+	hypothetical          "HYPOTHETICAL"
 	identified            "IDENTIFIED"
 	identSQLErrors        "ERRORS"
 	importKwd             "IMPORT"
@@ -487,6 +494,7 @@ import (
 	only                  "ONLY"
 	open                  "OPEN"
 	optional              "OPTIONAL"
+	options               "OPTIONS"
 	packKeys              "PACK_KEYS"
 	pageSym               "PAGE"
 	parser                "PARSER"
@@ -516,8 +524,10 @@ import (
 	query                 "QUERY"
 	quick                 "QUICK"
 	rateLimit             "RATE_LIMIT"
+	readRouting           "READ_ROUTING"
 	rebuild               "REBUILD"
 	recover               "RECOVER"
+	recycle               "RECYCLE"
 	redundant             "REDUNDANT"
 	reload                "RELOAD"
 	remove                "REMOVE"
@@ -533,6 +543,7 @@ import (
 	restore               "RESTORE"
 	restores              "RESTORES"
 	resume                "RESUME"
+	retention             "RETENTION"
 	reverse               "REVERSE"
 	role                  "ROLE"
 	rollback              "ROLLBACK"
@@ -552,6 +563,7 @@ import (
 	sequence              "SEQUENCE"
 	serial                "SERIAL"
 	serializable          "SERIALIZABLE"
+	server                "SERVER"
 	session               "SESSION"
 	setval                "SETVAL"
 	shardRowIDBits        "SHARD_ROW_ID_BITS"
@@ -624,6 +636,7 @@ import (
 	week                  "WEEK"
 	weightString          "WEIGHT_STRING"
 	without               "WITHOUT"
+	wrapper               "WRAPPER"
 	x509                  "X509"
 	yearType              "YEAR"
 	wait                  "WAIT"
@@ -806,6 +819,7 @@ import (
 	andnot       "&^"
 	assignmentEq ":="
 	eq           "="
+	eqGt         "=>"
 	ge           ">="
 	le           "<="
 	jss          "->"
@@ -875,6 +889,7 @@ import (
 	CreateBindingStmt          "CREATE BINDING  statement"
 	CreatePolicyStmt           "CREATE PLACEMENT POLICY statement"
 	CreateSequenceStmt         "CREATE SEQUENCE statement"
+	CreateServerStmt           "CREATE SERVER statement"
 	CreateStatisticsStmt       "CREATE STATISTICS statement"
 	DoStmt                     "Do statement"
 	DropDatabaseStmt           "DROP DATABASE statement"
@@ -884,6 +899,7 @@ import (
 	DropStatsStmt              "DROP STATS statement"
 	DropTableStmt              "DROP TABLE statement"
 	DropSequenceStmt           "DROP SEQUENCE statement"
+	DropServerStmt             "DROP SERVER statement"
 	DropUserStmt               "DROP USER"
 	DropRoleStmt               "DROP ROLE"
 	DropViewStmt               "DROP VIEW statement"
@@ -914,6 +930,7 @@ import (
 	PlanReplayerStmt           "Plan replayer statement"
 	PreparedStmt               "PreparedStmt"
 	PurgeImportStmt            "PURGE IMPORT statement that removes a IMPORT task record"
+	PurgeTableStmt             "PURGE TABLE statement that removes a dropped table from the recycle bin"
 	SelectStmt                 "SELECT statement"
 	SelectStmtWithClause       "common table expression SELECT statement"
 	RenameTableStmt            "rename table statement"
@@ -1022,6 +1039,7 @@ import (
 	ExtendedPriv                           "Extended privileges like LOAD FROM S3 or dynamic privileges"
 	MaxValueOrExpressionList               "maxvalue or expression list"
 	ExpressionListOpt                      "expression list opt"
+	ExternalTableOptionListOpt             "external table function options"
 	FetchFirstOpt                          "Fetch First/Next Option"
 	FuncDatetimePrecListOpt                "Function datetime precision list opt"
 	FuncDatetimePrecList                   "Function datetime precision list"
@@ -1115,6 +1133,7 @@ import (
 	PartitionDefinitionList                "Partition definition list"
 	PartitionDefinitionListOpt             "Partition definition list option"
 	PartitionIntervalOpt                   "Partition interval option"
+	AutoCreatePartOpt                      "Auto create partition option"
 	PartitionKeyAlgorithmOpt               "ALGORITHM = n option for KEY partition"
 	PartitionMethod                        "Partition method"
 	PartitionOpt                           "Partition option"
@@ -1170,6 +1189,8 @@ import (
 	SelectStmtIntoOption                   "SELECT statement into clause"
 	SequenceOption                         "Create sequence option"
 	SequenceOptionList                     "Create sequence option list"
+	ServerOption                           "Create server option"
+	ServerOptionList                       "Create server option list"
 	SetRoleOpt                             "Set role options"
 	SetDefaultRoleOpt                      "Set default role options"
 	SetOpr                                 "Set operator contain UNION, EXCEPT and INTERSECT"
@@ -1403,6 +1424,7 @@ import (
 	DBName                          "Database Name"
 	PolicyName                      "Placement Policy Name"
 	ExplainFormatType               "explain format type"
+	ExplainIntoOutfile              "explain into outfile"
 	FieldAsName                     "Field alias name"
 	FieldAsNameOpt                  "Field alias name opt"
 	FieldTerminator                 "Field terminator"
@@ -1423,6 +1445,7 @@ import (
 %precedence empty
 %precedence as
 %precedence placement
+%precedence readRouting
 %precedence lowerThanSelectOpt
 %precedence sqlBufferResult
 %precedence sqlBigResult
@@ -3784,6 +3807,7 @@ AlterDatabaseStmt:
  *      [DEFAULT] CHARACTER SET [=] charset_name
  *    | [DEFAULT] COLLATE [=] collation_name
  *    | [DEFAULT] ENCRYPTION [=] {'Y' | 'N'}
+ *    | [DEFAULT] READ_ROUTING [=] {'policy_name' | DEFAULT}
  *******************************************************************/
 CreateDatabaseStmt:
 	"CREATE" DatabaseSym IfNotExists DBName DatabaseOptionListOpt
@@ -3845,6 +3869,19 @@ DatabaseOption:
 			TiFlashReplica: tiflashReplicaSpec,
 		}
 	}
+|	DefaultKwdOpt "READ_ROUTING" EqOpt stringLit
+	{
+		policy := strings.ToLower($4)
+		if !model.IsValidReadRoutingPolicy(policy) {
+			yylex.AppendError(yylex.Errorf("The value of READ_ROUTING must be one of ['prefer-local-zone'|'primary-only'|'nearest-follower']."))
+			return 1
+		}
+		$$ = &ast.DatabaseOption{Tp: ast.DatabaseOptionReadRouting, Value: policy}
+	}
+|	DefaultKwdOpt "READ_ROUTING" EqOpt "DEFAULT"
+	{
+		$$ = &ast.DatabaseOption{Tp: ast.DatabaseOptionReadRouting, Value: ""}
+	}
 
 DatabaseOptionListOpt:
 	{
@@ -4054,7 +4091,7 @@ PartitionIntervalOpt:
 	{
 		$$ = nil
 	}
-|	"INTERVAL" '(' IntervalExpr ')' FirstAndLastPartOpt NullPartOpt MaxValPartOpt
+|	"INTERVAL" '(' IntervalExpr ')' FirstAndLastPartOpt NullPartOpt MaxValPartOpt AutoCreatePartOpt
 	{
 		partitionInterval := &ast.PartitionInterval{
 			IntervalExpr:  $3.(ast.PartitionIntervalExpr),
@@ -4062,6 +4099,8 @@ PartitionIntervalOpt:
 			LastRangeEnd:  $5.(ast.PartitionInterval).LastRangeEnd,
 			NullPart:      $6.(bool),
 			MaxValPart:    $7.(bool),
+			AutoCreate:    $8.(ast.PartitionInterval).AutoCreate,
+			Retention:     $8.(ast.PartitionInterval).Retention,
 		}
 		startOffset := parser.yyVAL.offset
 		endOffset := parser.yylval.offset
@@ -4099,6 +4138,22 @@ MaxValPartOpt:
 		$$ = true
 	}
 
+AutoCreatePartOpt:
+	{
+		$$ = ast.PartitionInterval{}
+	}
+|	"AUTO" "CREATE"
+	{
+		$$ = ast.PartitionInterval{AutoCreate: true}
+	}
+|	"AUTO" "CREATE" "RETENTION" '(' BitExpr TimeUnit ')'
+	{
+		$$ = ast.PartitionInterval{
+			AutoCreate: true,
+			Retention:  &ast.PartitionIntervalExpr{Expr: $5, TimeUnit: $6.(ast.TimeUnitType)},
+		}
+	}
+
 FirstAndLastPartOpt:
 	{
 		$$ = ast.PartitionInterval{} // First/LastRangeEnd defaults to nil
@@ -4871,6 +4926,22 @@ ExplainStmt:
 			Format: $4,
 		}
 	}
+|	ExplainSym "FORMAT" "=" stringLit ExplainIntoOutfile ExplainableStmt
+	{
+		$$ = &ast.ExplainStmt{
+			Stmt:    $6,
+			Format:  $4,
+			Outfile: $5,
+		}
+	}
+|	ExplainSym "FORMAT" "=" ExplainFormatType ExplainIntoOutfile ExplainableStmt
+	{
+		$$ = &ast.ExplainStmt{
+			Stmt:    $6,
+			Format:  $4,
+			Outfile: $5,
+		}
+	}
 |	ExplainSym "ANALYZE" ExplainableStmt
 	{
 		$$ = &ast.ExplainStmt{
@@ -4895,6 +4966,30 @@ ExplainStmt:
 			Analyze: true,
 		}
 	}
+|	ExplainSym "ANALYZE" "FORMAT" "=" ExplainFormatType ExplainIntoOutfile ExplainableStmt
+	{
+		$$ = &ast.ExplainStmt{
+			Stmt:    $7,
+			Format:  $5,
+			Analyze: true,
+			Outfile: $6,
+		}
+	}
+|	ExplainSym "ANALYZE" "FORMAT" "=" stringLit ExplainIntoOutfile ExplainableStmt
+	{
+		$$ = &ast.ExplainStmt{
+			Stmt:    $7,
+			Format:  $5,
+			Analyze: true,
+			Outfile: $6,
+		}
+	}
+
+ExplainIntoOutfile:
+	"INTO" "OUTFILE" stringLit
+	{
+		$$ = $3
+	}
 
 ExplainFormatType:
 	"TRADITIONAL"
@@ -5242,6 +5337,12 @@ PurgeImportStmt:
 		$$ = &ast.PurgeImportStmt{TaskID: getUint64FromNUM($3)}
 	}
 
+PurgeTableStmt:
+	"PURGE" "TABLE" TableName
+	{
+		$$ = &ast.PurgeTableStmt{Table: $3.(*ast.TableName)}
+	}
+
 /*******************************************************************
  * import statements
  *
@@ -5973,6 +6074,10 @@ IndexTypeName:
 	{
 		$$ = model.IndexTypeRtree
 	}
+|	"HYPOTHETICAL"
+	{
+		$$ = model.IndexTypeHypothetical
+	}
 
 IndexInvisible:
 	"VISIBLE"
@@ -6046,6 +6151,7 @@ UnReservedKeyword:
 |	"EVOLVE"
 |	"EXECUTE"
 |	"EXTENDED"
+|	"EXTERNAL"
 |	"FIELDS"
 |	"FILE"
 |	"FIRST"
@@ -6136,6 +6242,7 @@ UnReservedKeyword:
 |	"ONLY"
 |	"SERIAL"
 |	"SERIALIZABLE"
+|	"SERVER"
 |	"LEVEL"
 |	"VARIABLES"
 |	"SQL_CACHE"
@@ -6152,6 +6259,7 @@ UnReservedKeyword:
 |	"BINLOG"
 |	"FUNCTION"
 |	"VIEW"
+|	"BIN"
 |	"BINDING"
 |	"BINDINGS"
 |	"MODIFY"
@@ -6201,12 +6309,14 @@ UnReservedKeyword:
 |	"SECURITY"
 |	"CASCADED"
 |	"RECOVER"
+|	"RECYCLE"
 |	"CIPHER"
 |	"SUBJECT"
 |	"ISSUER"
 |	"X509"
 |	"NEVER"
 |	"EXPIRE"
+|	"EXPORT"
 |	"ACCOUNT"
 |	"INCREMENTAL"
 |	"CPU"
@@ -6222,9 +6332,13 @@ UnReservedKeyword:
 |	"SOURCE"
 |	"TRADITIONAL"
 |	"SQL_BUFFER_RESULT"
+|	"DIGEST"
+|	"COPROCESSOR_CACHE"
+|	"READ_ROUTING"
 |	"DIRECTORY"
 |	"HISTOGRAM"
 |	"HISTORY"
+|	"HYPOTHETICAL"
 |	"LIST"
 |	"NODEGROUP"
 |	"SYSTEM_TIME"
@@ -6240,6 +6354,7 @@ UnReservedKeyword:
 |	"SECONDARY_UNLOAD"
 |	"VALIDATION"
 |	"WITHOUT"
+|	"WRAPPER"
 |	"RTREE"
 |	"EXCHANGE"
 |	"COLUMN_FORMAT"
@@ -6251,6 +6366,8 @@ UnReservedKeyword:
 |	"UNICODE"
 |	"AUTO_RANDOM"
 |	"AUTO_RANDOM_BASE"
+|	"AUTO"
+|	"RETENTION"
 |	"SQL_TSI_DAY"
 |	"SQL_TSI_HOUR"
 |	"SQL_TSI_MINUTE"
@@ -6323,6 +6440,7 @@ UnReservedKeyword:
 |	"RESUME"
 |	"OFF"
 |	"OPTIONAL"
+|	"OPTIONS"
 |	"REQUIRED"
 |	"PURGE"
 |	"SKIP"
@@ -7220,6 +7338,7 @@ BuggyDefaultFalseDistinctOpt:
 
 FunctionNameConflict:
 	"ASCII"
+|	"BIN"
 |	"CHARSET"
 |	"COALESCE"
 |	"COLLATION"
@@ -8422,26 +8541,18 @@ TableSampleOpt:
 	}
 |	"TABLESAMPLE" TableSampleMethodOpt '(' Expression TableSampleUnitOpt ')' RepeatableOpt
 	{
-		var repSeed ast.ExprNode
-		if $7 != nil {
-			repSeed = ast.NewValueExpr($7, parser.charset, parser.collation)
-		}
 		$$ = &ast.TableSample{
 			SampleMethod:     $2.(ast.SampleMethodType),
-			Expr:             ast.NewValueExpr($4, parser.charset, parser.collation),
+			Expr:             $4,
 			SampleClauseUnit: $5.(ast.SampleClauseUnitType),
-			RepeatableSeed:   repSeed,
+			RepeatableSeed:   $7,
 		}
 	}
 |	"TABLESAMPLE" TableSampleMethodOpt '(' ')' RepeatableOpt
 	{
-		var repSeed ast.ExprNode
-		if $5 != nil {
-			repSeed = ast.NewValueExpr($5, parser.charset, parser.collation)
-		}
 		$$ = &ast.TableSample{
 			SampleMethod:   $2.(ast.SampleMethodType),
-			RepeatableSeed: repSeed,
+			RepeatableSeed: $5,
 		}
 	}
 
@@ -9040,6 +9151,22 @@ TableFactor:
 		j.ExplicitParens = true
 		$$ = $2
 	}
+|	"EXTERNAL" '(' stringLit ExternalTableOptionListOpt ')' TableAsNameOpt
+	{
+		source := &ast.ExternalTableSource{Location: $3, Options: $4.([]*ast.ExternalTableOption)}
+		$$ = &ast.TableSource{Source: source, AsName: $6.(model.CIStr)}
+	}
+
+ExternalTableOptionListOpt:
+	/* empty */
+	{
+		$$ = []*ast.ExternalTableOption{}
+	}
+|	ExternalTableOptionListOpt ',' Identifier "=>" stringLit
+	{
+		opt := &ast.ExternalTableOption{Name: strings.ToUpper($3), Value: $5}
+		$$ = append($1.([]*ast.ExternalTableOption), opt)
+	}
 
 PartitionNameListOpt:
 	/* empty */
@@ -10499,6 +10626,20 @@ AdminStmt:
 			StatementScope: $3.(ast.StatementScope),
 		}
 	}
+|	"ADMIN" "EXPORT" "STATS" "TO" stringLit
+	{
+		$$ = &ast.AdminStmt{
+			Tp:      ast.AdminExportStats,
+			Storage: $5,
+		}
+	}
+|	"ADMIN" "IMPORT" "STATS" "FROM" stringLit
+	{
+		$$ = &ast.AdminStmt{
+			Tp:      ast.AdminImportStats,
+			Storage: $5,
+		}
+	}
 
 AdminShowSlow:
 	"RECENT" NUM
@@ -10573,12 +10714,16 @@ ShowStmt:
 		}
 		$$ = stmt
 	}
-|	"SHOW" "CREATE" "TABLE" TableName
+|	"SHOW" "CREATE" "TABLE" TableName AsOfClauseOpt
 	{
-		$$ = &ast.ShowStmt{
+		stmt := &ast.ShowStmt{
 			Tp:    ast.ShowCreateTable,
 			Table: $4.(*ast.TableName),
 		}
+		if $5 != nil {
+			stmt.AsOf = $5.(*ast.AsOfClause)
+		}
+		$$ = stmt
 	}
 |	"SHOW" "CREATE" "VIEW" TableName
 	{
@@ -11068,6 +11213,10 @@ ShowTargetFilterable:
 	{
 		$$ = &ast.ShowStmt{Tp: ast.ShowPlacementLabels}
 	}
+|	"RECYCLE" "BIN"
+	{
+		$$ = &ast.ShowStmt{Tp: ast.ShowRecycleBin}
+	}
 
 ShowLikeOrWhereOpt:
 	{
@@ -11301,6 +11450,7 @@ Statement:
 |	CreateBindingStmt
 |	CreatePolicyStmt
 |	CreateSequenceStmt
+|	CreateServerStmt
 |	CreateStatisticsStmt
 |	DoStmt
 |	DropDatabaseStmt
@@ -11309,6 +11459,7 @@ Statement:
 |	DropTableStmt
 |	DropPolicyStmt
 |	DropSequenceStmt
+|	DropServerStmt
 |	DropViewStmt
 |	DropUserStmt
 |	DropRoleStmt
@@ -11330,6 +11481,7 @@ Statement:
 |	PlanReplayerStmt
 |	PreparedStmt
 |	PurgeImportStmt
+|	PurgeTableStmt
 |	RollbackStmt
 |	RenameTableStmt
 |	RenameUserStmt
@@ -11535,6 +11687,15 @@ TableOption:
 	{
 		$$ = &ast.TableOption{Tp: ast.TableOptionAutoIdCache, UintValue: $3.(uint64)}
 	}
+|	"COPROCESSOR_CACHE" EqOpt LengthNum
+	{
+		n := $3.(uint64)
+		if n != 0 && n != 1 {
+			yylex.AppendError(yylex.Errorf("The value of COPROCESSOR_CACHE must be one of [0|1]."))
+			return 1
+		}
+		$$ = &ast.TableOption{Tp: ast.TableOptionCoprocessorCache, UintValue: n}
+	}
 |	ForceOpt "AUTO_RANDOM_BASE" EqOpt LengthNum
 	{
 		$$ = &ast.TableOption{Tp: ast.TableOptionAutoRandomBase, UintValue: $4.(uint64), BoolValue: $1.(bool)}
@@ -13812,6 +13973,14 @@ KillStmt:
 			TiDBExtension: $1.(bool),
 		}
 	}
+|	KillOrKillTiDB "DIGEST" stringLit
+	{
+		$$ = &ast.KillStmt{
+			Query:         true,
+			Digest:        $3,
+			TiDBExtension: $1.(bool),
+		}
+	}
 
 KillOrKillTiDB:
 	"KILL"
@@ -13999,6 +14168,80 @@ DropSequenceStmt:
 		}
 	}
 
+/********************************************************************************************
+ *
+ *  Create Server Statement
+ *
+ *  Example:
+ *	CREATE SERVER [IF NOT EXISTS] server_name
+ *	FOREIGN DATA WRAPPER wrapper_name
+ *	OPTIONS (option [, option] ...)
+ *
+ *	option: {
+ *	    HOST character-literal
+ *	  | DATABASE character-literal
+ *	  | USER character-literal
+ *	  | PASSWORD character-literal
+ *	  | SOCKET character-literal
+ *	  | OWNER character-literal
+ *	  | PORT numeric-literal
+ *	}
+ ********************************************************************************************/
+CreateServerStmt:
+	"CREATE" "SERVER" IfNotExists Identifier "FOREIGN" "DATA" "WRAPPER" Identifier "OPTIONS" '(' ServerOptionList ')'
+	{
+		$$ = &ast.CreateServerStmt{
+			IfNotExists: $3.(bool),
+			Name:        $4,
+			Wrapper:     $8,
+			Options:     $11.([]*ast.ServerOption),
+		}
+	}
+
+ServerOptionList:
+	ServerOption
+	{
+		$$ = []*ast.ServerOption{$1.(*ast.ServerOption)}
+	}
+|	ServerOptionList ',' ServerOption
+	{
+		$$ = append($1.([]*ast.ServerOption), $3.(*ast.ServerOption))
+	}
+
+ServerOption:
+	Identifier stringLit
+	{
+		name := strings.ToUpper($1)
+		switch name {
+		case ast.ServerOptionHost, ast.ServerOptionUser, ast.ServerOptionPassword, ast.ServerOptionSocket, ast.ServerOptionOwner:
+		default:
+			yylex.AppendError(yylex.Errorf("Unknown server option '%s'", $1))
+			return 1
+		}
+		$$ = &ast.ServerOption{Name: name, StrValue: $2}
+	}
+|	"DATABASE" stringLit
+	{
+		$$ = &ast.ServerOption{Name: ast.ServerOptionDatabase, StrValue: $2}
+	}
+|	Identifier LengthNum
+	{
+		if !strings.EqualFold($1, ast.ServerOptionPort) {
+			yylex.AppendError(yylex.Errorf("Unknown server option '%s'", $1))
+			return 1
+		}
+		$$ = &ast.ServerOption{Name: ast.ServerOptionPort, UintValue: $2.(uint64)}
+	}
+
+DropServerStmt:
+	"DROP" "SERVER" IfExists Identifier
+	{
+		$$ = &ast.DropServerStmt{
+			IfExists: $3.(bool),
+			Name:     $4,
+		}
+	}
+
 /********************************************************************************************
  *
  *  Alter Sequence Statement
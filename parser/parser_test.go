@@ -828,6 +828,12 @@ func TestDMLStmt(t *testing.T) {
 		{"admin flush session plan_cache", true, "ADMIN FLUSH SESSION PLAN_CACHE"},
 		// We do not support the global level. We will check it in the later.
 		{"admin flush global plan_cache", true, "ADMIN FLUSH GLOBAL PLAN_CACHE"},
+		// Test for 'admin export/import stats'
+		{"admin export stats to 's3://bucket/stats/'", true, "ADMIN EXPORT STATS TO 's3://bucket/stats/'"},
+		{"admin import stats from 'local:///tmp/stats'", true, "ADMIN IMPORT STATS FROM 'local:///tmp/stats'"},
+		{"admin export stats 's3://bucket/stats/'", false, ""},
+		{"admin import stats to 's3://bucket/stats/'", false, ""},
+		{"create table export (export int)", true, "CREATE TABLE `export` (`export` INT)"},
 
 		// for on duplicate key update
 		{"INSERT INTO t (a,b,c) VALUES (1,2,3),(4,5,6) ON DUPLICATE KEY UPDATE c=VALUES(a)+VALUES(b);", true, "INSERT INTO `t` (`a`,`b`,`c`) VALUES (1,2,3),(4,5,6) ON DUPLICATE KEY UPDATE `c`=VALUES(`a`)+VALUES(`b`)"},
@@ -1044,6 +1050,12 @@ AAAAAAAAAAAA5gm5Mg==
 		// for show/set session_states
 		{"SHOW SESSION_STATES", true, "SHOW SESSION_STATES"},
 		{"SET SESSION_STATES 'x'", true, "SET SESSION_STATES 'x'"},
+
+		// for show recycle bin
+		{"SHOW RECYCLE BIN", true, "SHOW RECYCLE BIN"},
+		{"SHOW RECYCLE BIN LIKE 't%'", true, "SHOW RECYCLE BIN LIKE _UTF8MB4't%'"},
+		{"SHOW RECYCLE BIN WHERE table_name='t'", true, "SHOW RECYCLE BIN WHERE `table_name`=_UTF8MB4't'"},
+		{"SHOW RECYCLE", false, ""},
 		{"SET SESSION_STATES", false, ""},
 		{"SET SESSION_STATES 1", false, ""},
 		{"SET SESSION_STATES now()", false, ""},
@@ -1120,6 +1132,8 @@ func TestDBAStmt(t *testing.T) {
 		// for show create table
 		{"show create table test.t", true, "SHOW CREATE TABLE `test`.`t`"},
 		{"show create table t", true, "SHOW CREATE TABLE `t`"},
+		{"show create table t as of timestamp '2022-08-01 10:00:00'", true, "SHOW CREATE TABLE `t` AS OF TIMESTAMP _UTF8MB4'2022-08-01 10:00:00'"},
+		{"show create table test.t as of timestamp now() - interval 1 hour", true, "SHOW CREATE TABLE `test`.`t` AS OF TIMESTAMP DATE_SUB(NOW(), INTERVAL 1 HOUR)"},
 		// for show create view
 		{"show create view test.t", true, "SHOW CREATE VIEW `test`.`t`"},
 		{"show create view t", true, "SHOW CREATE VIEW `t`"},
@@ -2611,6 +2625,15 @@ func TestDDL(t *testing.T) {
 		{"create database xxx default encryption 'Y'", true, "CREATE DATABASE `xxx` ENCRYPTION = 'Y'"},
 		{"create database xxx encryption = N", false, ""},
 
+		// for read_routing
+		{"create database xxx read_routing = 'primary-only'", true, "CREATE DATABASE `xxx` READ_ROUTING = 'primary-only'"},
+		{"create database xxx default read_routing 'Prefer-Local-Zone'", true, "CREATE DATABASE `xxx` READ_ROUTING = 'prefer-local-zone'"},
+		{"create database xxx read_routing = 'leader'", false, ""},
+		{"alter database xxx read_routing = 'nearest-follower'", true, "ALTER DATABASE `xxx` READ_ROUTING = 'nearest-follower'"},
+		{"alter database xxx read_routing = default", true, "ALTER DATABASE `xxx` READ_ROUTING = DEFAULT"},
+		{"alter database read_routing = 'primary-only'", true, "ALTER DATABASE READ_ROUTING = 'primary-only'"},
+		{"create database read_routing", true, "CREATE DATABASE `read_routing`"},
+
 		{"create schema xxx", true, "CREATE DATABASE `xxx`"},
 		{"create schema if exists xxx", false, ""},
 		{"create schema if not exists xxx", true, "CREATE DATABASE IF NOT EXISTS `xxx`"},
@@ -3102,6 +3125,9 @@ func TestDDL(t *testing.T) {
 		{"CREATE UNIQUE INDEX ident ON d_n.t_n ( ident , ident ASC ) TYPE BTREE", true, "CREATE UNIQUE INDEX `ident` ON `d_n`.`t_n` (`ident`, `ident`) USING BTREE"},
 		{"CREATE UNIQUE INDEX ident ON d_n.t_n ( ident , ident ASC ) TYPE HASH", true, "CREATE UNIQUE INDEX `ident` ON `d_n`.`t_n` (`ident`, `ident`) USING HASH"},
 		{"CREATE UNIQUE INDEX ident ON d_n.t_n ( ident , ident ASC ) TYPE RTREE", true, "CREATE UNIQUE INDEX `ident` ON `d_n`.`t_n` (`ident`, `ident`) USING RTREE"},
+		{"CREATE INDEX ident ON d_n.t_n ( ident , ident ASC ) TYPE HYPOTHETICAL", true, "CREATE INDEX `ident` ON `d_n`.`t_n` (`ident`, `ident`) USING HYPOTHETICAL"},
+		{"CREATE INDEX ident TYPE HYPOTHETICAL ON d_n.t_n ( ident )", true, "CREATE INDEX `ident` ON `d_n`.`t_n` (`ident`) USING HYPOTHETICAL"},
+		{"CREATE INDEX hypothetical ON t (hypothetical)", true, "CREATE INDEX `hypothetical` ON `t` (`hypothetical`)"},
 		{"CREATE UNIQUE INDEX ident TYPE BTREE ON d_n.t_n ( ident , ident ASC )", true, "CREATE UNIQUE INDEX `ident` ON `d_n`.`t_n` (`ident`, `ident`) USING BTREE"},
 		{"CREATE UNIQUE INDEX ident USING BTREE ON d_n.t_n ( ident , ident ASC )", true, "CREATE UNIQUE INDEX `ident` ON `d_n`.`t_n` (`ident`, `ident`) USING BTREE"},
 		{"CREATE SPATIAL INDEX idx ON t (a)", true, "CREATE SPATIAL INDEX `idx` ON `t` (`a`)"},
@@ -3234,6 +3260,12 @@ func TestDDL(t *testing.T) {
 		{"flashback table t", true, "FLASHBACK TABLE `t`"},
 		{"flashback table t TO t1", true, "FLASHBACK TABLE `t` TO `t1`"},
 
+		// for purge table.
+		{"purge table t", true, "PURGE TABLE `t`"},
+		{"purge table db.t", true, "PURGE TABLE `db`.`t`"},
+		{"purge table", false, ""},
+		{"select recycle, bin from recycle", true, "SELECT `recycle`,`bin` FROM `recycle`"},
+
 		// for flashback cluster
 		{"flashback cluster as of timestamp '2021-05-26 16:45:26'", true, "FLASHBACK CLUSTER AS OF TIMESTAMP '2021-05-26 16:45:26'"},
 		{"flashback cluster as of timestamp TIDB_BOUNDED_STALENESS(DATE_SUB(NOW(), INTERVAL 3 SECOND), NOW())", false, ""},
@@ -3505,6 +3537,13 @@ func TestDDL(t *testing.T) {
 		{"create table t (a int auto_increment key) auto_id_cache 10", true, "CREATE TABLE `t` (`a` INT AUTO_INCREMENT PRIMARY KEY) AUTO_ID_CACHE = 10"},
 		{"create table t (a bigint, b varchar(255)) auto_id_cache 50", true, "CREATE TABLE `t` (`a` BIGINT,`b` VARCHAR(255)) AUTO_ID_CACHE = 50"},
 
+		// for coprocessor_cache
+		{"create table t (a int) coprocessor_cache=0", true, "CREATE TABLE `t` (`a` INT) COPROCESSOR_CACHE = 0"},
+		{"create table t (a int) coprocessor_cache 1", true, "CREATE TABLE `t` (`a` INT) COPROCESSOR_CACHE = 1"},
+		{"create table t (a int) coprocessor_cache=2", false, ""},
+		{"alter table t coprocessor_cache = 0", true, "ALTER TABLE `t` COPROCESSOR_CACHE = 0"},
+		{"create table coprocessor_cache (coprocessor_cache int)", true, "CREATE TABLE `coprocessor_cache` (`coprocessor_cache` INT)"},
+
 		// for auto_random_id
 		{"create table t (a bigint auto_random(3) primary key) auto_random_base = 10", true, "CREATE TABLE `t` (`a` BIGINT AUTO_RANDOM(3) PRIMARY KEY) AUTO_RANDOM_BASE = 10"},
 		{"create table t (a bigint primary key auto_random(4), b varchar(100)) auto_random_base 200", true, "CREATE TABLE `t` (`a` BIGINT PRIMARY KEY AUTO_RANDOM(4),`b` VARCHAR(100)) AUTO_RANDOM_BASE = 200"},
@@ -4893,6 +4932,13 @@ func TestExplain(t *testing.T) {
 		{"EXPLAIN FORMAT = 'VERBOSE' SELECT 1", true, "EXPLAIN FORMAT = 'VERBOSE' SELECT 1"},
 		{"EXPLAIN FORMAT = VERBOSE SELECT 1", true, "EXPLAIN FORMAT = 'VERBOSE' SELECT 1"},
 		{"EXPLAIN SELECT 1", true, "EXPLAIN FORMAT = 'row' SELECT 1"},
+		{"EXPLAIN FORMAT = 'json' INTO OUTFILE '/tmp/plan.json' SELECT 1", true, "EXPLAIN FORMAT = 'json' INTO OUTFILE '/tmp/plan.json' SELECT 1"},
+		{"EXPLAIN FORMAT = JSON INTO OUTFILE 's3://bucket/plan.json' SELECT 1", true, "EXPLAIN FORMAT = 'JSON' INTO OUTFILE 's3://bucket/plan.json' SELECT 1"},
+		{"EXPLAIN ANALYZE FORMAT = 'row' INTO OUTFILE '/tmp/plan.txt' SELECT 1", true, "EXPLAIN ANALYZE FORMAT = 'row' INTO OUTFILE '/tmp/plan.txt' SELECT 1"},
+		{"EXPLAIN ANALYZE FORMAT = JSON INTO OUTFILE '/tmp/plan.json' DELETE FROM t", true, "EXPLAIN ANALYZE FORMAT = 'JSON' INTO OUTFILE '/tmp/plan.json' DELETE FROM `t`"},
+		{"EXPLAIN FORMAT = 'json' SELECT 1 INTO OUTFILE '/tmp/result.txt'", true, "EXPLAIN FORMAT = 'json' SELECT 1 INTO OUTFILE '/tmp/result.txt'"},
+		{"EXPLAIN INTO OUTFILE '/tmp/plan.txt' SELECT 1", false, ""},
+		{"EXPLAIN FORMAT = 'json' INTO OUTFILE '/tmp/plan.json' FOR CONNECTION 1", false, ""},
 		{"EXPLAIN FOR CONNECTION 1", true, "EXPLAIN FORMAT = 'row' FOR CONNECTION 1"},
 		{"EXPLAIN FOR connection 42", true, "EXPLAIN FORMAT = 'row' FOR CONNECTION 42"},
 		{"EXPLAIN FORMAT = 'dot' FOR CONNECTION 1", true, "EXPLAIN FORMAT = 'dot' FOR CONNECTION 1"},
@@ -5220,6 +5266,10 @@ func TestSessionManage(t *testing.T) {
 		{"kill tidb 23123", true, "KILL TIDB 23123"},
 		{"kill tidb connection 23123", true, "KILL TIDB 23123"},
 		{"kill tidb query 23123", true, "KILL TIDB QUERY 23123"},
+		{"kill digest 'e5796985ccafe2f71126ed6c0ac939ffa015a8c0744a24b7aee6d587103fd2f7'", true, "KILL DIGEST 'e5796985ccafe2f71126ed6c0ac939ffa015a8c0744a24b7aee6d587103fd2f7'"},
+		{"kill tidb digest 'abc'", true, "KILL TIDB DIGEST 'abc'"},
+		{"kill digest abc", false, ""},
+		{"kill query digest 'abc'", false, ""},
 		{"show processlist", true, "SHOW PROCESSLIST"},
 		{"show full processlist", true, "SHOW FULL PROCESSLIST"},
 		{"shutdown", true, "SHUTDOWN"},
@@ -5444,6 +5494,44 @@ func TestTableSample(t *testing.T) {
 	}
 }
 
+func TestExternalTableSource(t *testing.T) {
+	table := []testCase{
+		{"select * from external('s3://bucket/path/*.parquet')", true, "SELECT * FROM EXTERNAL('s3://bucket/path/*.parquet')"},
+		{"select * from external('s3://bucket/path/*.parquet', format => 'parquet') as t where a > 1", true, "SELECT * FROM EXTERNAL('s3://bucket/path/*.parquet', FORMAT => 'parquet') AS `t` WHERE `a`>1"},
+		{"select a from EXTERNAL('local:///tmp/a.csv', format=>'csv') t join t1 on t.a = t1.a", true, "SELECT `a` FROM EXTERNAL('local:///tmp/a.csv', FORMAT => 'csv') AS `t` JOIN `t1` ON `t`.`a`=`t1`.`a`"},
+		{"create table external (external int)", true, "CREATE TABLE `external` (`external` INT)"},
+		{"select * from external", true, "SELECT * FROM `external`"},
+		{"select * from external(s3)", false, ""},
+		{"select * from external('a.csv', format = 'csv')", false, ""},
+		{"select * from external('a.csv' format => 'csv')", false, ""},
+	}
+	RunTest(t, table, false)
+}
+
+func TestServer(t *testing.T) {
+	table := []testCase{
+		{"create server s foreign data wrapper mysql options (host '127.0.0.1', port 3306, user 'root', password 'pwd', database 'test')", true, "CREATE SERVER `s` FOREIGN DATA WRAPPER `mysql` OPTIONS (HOST '127.0.0.1', PORT 3306, USER 'root', PASSWORD 'pwd', DATABASE 'test')"},
+		{"CREATE SERVER IF NOT EXISTS `s` FOREIGN DATA WRAPPER mysql OPTIONS (SOCKET '/tmp/mysql.sock', OWNER 'o')", true, "CREATE SERVER IF NOT EXISTS `s` FOREIGN DATA WRAPPER `mysql` OPTIONS (SOCKET '/tmp/mysql.sock', OWNER 'o')"},
+		{"create server s foreign data wrapper mysql options ()", false, ""},
+		{"create server s foreign data wrapper mysql options (port '3306')", false, ""},
+		{"create server s foreign data wrapper mysql options (host 1)", false, ""},
+		{"create server s foreign data wrapper mysql options (hosts 'h')", false, ""},
+		{"create server s options (host 'h')", false, ""},
+		{"drop server s", true, "DROP SERVER `s`"},
+		{"drop server if exists s", true, "DROP SERVER IF EXISTS `s`"},
+		{"drop server s, s1", false, ""},
+		{"create table t (a int) engine = federated connection = 's/t'", true, "CREATE TABLE `t` (`a` INT) ENGINE = federated CONNECTION = 's/t'"},
+
+		// test server, wrapper and options are not reserved keywords
+		{"create table server (wrapper int, options int)", true, "CREATE TABLE `server` (`wrapper` INT,`options` INT)"},
+	}
+	RunTest(t, table, false)
+
+	stmt, err := parser.New().ParseOneStmt("create server s foreign data wrapper mysql options (host 'h', password 'pwd')", "", "")
+	require.NoError(t, err)
+	require.Equal(t, "create server s foreign data wrapper mysql options (host 'h', password 'xxxxxx')", stmt.(ast.SensitiveStmtNode).SecureText())
+}
+
 func TestGeneratedColumn(t *testing.T) {
 	tests := []struct {
 		input string
@@ -6874,6 +6962,12 @@ func TestIntervalPartition(t *testing.T) {
 		{"CREATE TABLE t (c1 int, c2 date) PARTITION BY RANGE (c1) (partition p1 values less than (22))", true, "CREATE TABLE `t` (`c1` INT,`c2` DATE) PARTITION BY RANGE (`c1`) (PARTITION `p1` VALUES LESS THAN (22))"},
 		{`CREATE TABLE t (c1 int, c2 date) PARTITION BY RANGE COLUMNS (c2) INTERVAL (1 year) first partition less than ("2022-02-01")`, false, ""},
 		{`CREATE TABLE t (c1 int, c2 datetime) PARTITION BY RANGE COLUMNS (c2) INTERVAL (1 day) first partition less than ("2022-01-02") last partition less than ("2022-06-01") NULL PARTITION MAXVALUE PARTITION`, true, "CREATE TABLE `t` (`c1` INT,`c2` DATETIME) PARTITION BY RANGE COLUMNS (`c2`) INTERVAL (1 DAY) FIRST PARTITION LESS THAN (_UTF8MB4'2022-01-02') LAST PARTITION LESS THAN (_UTF8MB4'2022-06-01') NULL PARTITION MAXVALUE PARTITION"},
+		{`CREATE TABLE t (c1 int, c2 date) PARTITION BY RANGE COLUMNS (c2) INTERVAL (1 MONTH) first partition less than ('2022-01-01') last partition less than ('2022-06-01') auto create`, true, "CREATE TABLE `t` (`c1` INT,`c2` DATE) PARTITION BY RANGE COLUMNS (`c2`) INTERVAL (1 MONTH) FIRST PARTITION LESS THAN (_UTF8MB4'2022-01-01') LAST PARTITION LESS THAN (_UTF8MB4'2022-06-01') AUTO CREATE"},
+		{"CREATE TABLE `t` (`c1` INT,`c2` DATE) PARTITION BY RANGE COLUMNS (`c2`) INTERVAL (1 MONTH) FIRST PARTITION LESS THAN (_UTF8MB4'2022-01-01') LAST PARTITION LESS THAN (_UTF8MB4'2022-06-01') NULL PARTITION AUTO CREATE RETENTION (12 MONTH)", true, "CREATE TABLE `t` (`c1` INT,`c2` DATE) PARTITION BY RANGE COLUMNS (`c2`) INTERVAL (1 MONTH) FIRST PARTITION LESS THAN (_UTF8MB4'2022-01-01') LAST PARTITION LESS THAN (_UTF8MB4'2022-06-01') NULL PARTITION AUTO CREATE RETENTION (12 MONTH)"},
+		{`CREATE TABLE t (c1 int, c2 date) PARTITION BY RANGE COLUMNS (c2) INTERVAL (1 MONTH) auto create (partition p0 values less than ('2022-01-01'))`, true, "CREATE TABLE `t` (`c1` INT,`c2` DATE) PARTITION BY RANGE COLUMNS (`c2`) INTERVAL (1 MONTH) AUTO CREATE (PARTITION `p0` VALUES LESS THAN (_UTF8MB4'2022-01-01'))"},
+		{`CREATE TABLE t (c1 int, c2 date) PARTITION BY RANGE COLUMNS (c2) INTERVAL (1 MONTH) auto create retention (12)`, false, ""},
+		{`CREATE TABLE t (c1 int, c2 date) PARTITION BY RANGE COLUMNS (c2) INTERVAL (1 MONTH) retention (12 month)`, false, ""},
+		{"CREATE TABLE auto (retention int)", true, "CREATE TABLE `auto` (`retention` INT)"},
 		{`ALTER TABLE t LAST PARTITION LESS THAN (1000)`, true, "ALTER TABLE `t` LAST PARTITION LESS THAN (1000)"},
 		{`ALTER TABLE t REORGANIZE MAX PARTITION INTO NEW LAST PARTITION LESS THAN (1000)`, false, ""},
 		{`ALTER TABLE t REORGANIZE MAX PARTITION INTO LAST PARTITION LESS THAN (1000)`, false, ""},
@@ -28,15 +28,24 @@ const (
 	FeatureIDForceAutoInc = "force_inc"
 	// FeatureIDPlacement is the `placement rule` feature.
 	FeatureIDPlacement = "placement"
+	// FeatureIDCoprocessorCache is the `coprocessor_cache` table option.
+	FeatureIDCoprocessorCache = "copr_cache"
+	// FeatureIDReadRouting is the `read_routing` database option.
+	FeatureIDReadRouting = "read_routing"
+	// FeatureIDAutoInterval is the `INTERVAL ... AUTO CREATE` partition option.
+	FeatureIDAutoInterval = "auto_interval"
 )
 
 var featureIDs = map[string]struct{}{
-	FeatureIDAutoRandom:     {},
-	FeatureIDAutoIDCache:    {},
-	FeatureIDAutoRandomBase: {},
-	FeatureIDClusteredIndex: {},
-	FeatureIDForceAutoInc:   {},
-	FeatureIDPlacement:      {},
+	FeatureIDAutoRandom:       {},
+	FeatureIDAutoIDCache:      {},
+	FeatureIDAutoRandomBase:   {},
+	FeatureIDClusteredIndex:   {},
+	FeatureIDForceAutoInc:     {},
+	FeatureIDPlacement:        {},
+	FeatureIDCoprocessorCache: {},
+	FeatureIDReadRouting:      {},
+	FeatureIDAutoInterval:     {},
 }
 
 // CanParseFeature is used to check if a feature can be parsed.
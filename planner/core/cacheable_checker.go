@@ -122,6 +122,10 @@ func (checker *cacheableChecker) Enter(in ast.Node) (out ast.Node, skipChildren
 				return in, true
 			}
 		}
+	case *ast.ExternalTableSource:
+		// The files in the external storage may change between executions.
+		checker.cacheable = false
+		return in, true
 	case *ast.FrameBound:
 		if _, ok := node.Expr.(*driver.ParamMarkerExpr); ok {
 			checker.cacheable = false
@@ -16,6 +16,7 @@ package core
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -323,6 +324,10 @@ type Insert struct {
 	AllAssignmentsAreConstant bool
 
 	RowLen int
+
+	// UseIngest indicates the rows of INSERT ... SELECT are ingested into the table as sorted
+	// KV files if the table is empty when the statement is executed.
+	UseIngest bool
 }
 
 // Update represents Update plan.
@@ -492,7 +497,10 @@ type SelectInto struct {
 	baseSchemaProducer
 
 	TargetPlan Plan
-	IntoOpt    *ast.SelectIntoOption
+	// TargetNames are the output names of TargetPlan, which are used as the
+	// column names of the self-describing file formats like Parquet.
+	TargetNames types.NameSlice
+	IntoOpt     *ast.SelectIntoOption
 }
 
 // Explain represents a explain plan.
@@ -504,6 +512,10 @@ type Explain struct {
 	Analyze          bool
 	ExecStmt         ast.StmtNode
 	RuntimeStatsColl *execdetails.RuntimeStatsColl
+	// Outfile is the file the result is written into, no rows are returned if it's set.
+	Outfile string
+	// OutfileFields are the names of the fields written into Outfile.
+	OutfileFields []string
 
 	Rows        [][]string
 	ExplainRows [][]string
@@ -547,9 +559,17 @@ func (e *Explain) prepareSchema() error {
 		fieldNames = []string{"hint"}
 	case format == types.ExplainFormatBinary:
 		fieldNames = []string{"binary plan"}
+	case format == types.ExplainFormatJSON:
+		fieldNames = []string{"json plan"}
 	default:
 		return errors.Errorf("explain format '%s' is not supported now", e.Format)
 	}
+	if e.Outfile != "" {
+		e.OutfileFields = fieldNames
+		e.SetSchema(expression.NewSchema())
+		e.names = nil
+		return nil
+	}
 
 	cwn := &columnsWithNames{
 		cols:  make([]*expression.Column, 0, len(fieldNames)),
@@ -608,6 +628,13 @@ func (e *Explain) RenderResult() error {
 		flat := FlattenPhysicalPlan(e.TargetPlan, false)
 		str := BinaryPlanStrFromFlatPlan(e.ctx, flat)
 		e.Rows = append(e.Rows, []string{str})
+	case types.ExplainFormatJSON:
+		flat := FlattenPhysicalPlan(e.TargetPlan, true)
+		str, err := e.explainFlatPlanInJSONFormat(flat)
+		if err != nil {
+			return err
+		}
+		e.Rows = append(e.Rows, []string{str})
 	default:
 		return errors.Errorf("explain format '%s' is not supported now", e.Format)
 	}
@@ -646,6 +673,63 @@ func (e *Explain) explainFlatOpInRowFormat(flatOp *FlatOperator) {
 	}
 }
 
+// ExplainInfoForEncode is the operator of the plan explained in JSON format.
+type ExplainInfoForEncode struct {
+	ID           string                  `json:"id"`
+	EstRows      string                  `json:"estRows"`
+	ActRows      string                  `json:"actRows,omitempty"`
+	TaskType     string                  `json:"taskType"`
+	AccessObject string                  `json:"accessObject,omitempty"`
+	ExecuteInfo  string                  `json:"executeInfo,omitempty"`
+	OperatorInfo string                  `json:"operatorInfo,omitempty"`
+	EstCost      string                  `json:"estCost,omitempty"`
+	MemoryInfo   string                  `json:"memoryInfo,omitempty"`
+	DiskInfo     string                  `json:"diskInfo,omitempty"`
+	SubOperators []*ExplainInfoForEncode `json:"subOperators,omitempty"`
+}
+
+// explainFlatPlanInJSONFormat encodes the operator trees of the main query and the CTEs
+// as a JSON array.
+func (e *Explain) explainFlatPlanInJSONFormat(flat *FlatPhysicalPlan) (string, error) {
+	encodes := make([]*ExplainInfoForEncode, 0, 1)
+	if flat != nil && len(flat.Main) > 0 && !flat.InExplain {
+		if info := e.explainOpInJSONFormat(flat.Main, 0); info != nil {
+			encodes = append(encodes, info)
+		}
+		for _, cte := range flat.CTEs {
+			if info := e.explainOpInJSONFormat(cte, 0); info != nil {
+				encodes = append(encodes, info)
+			}
+		}
+	}
+	str, err := json.MarshalIndent(encodes, "", "    ")
+	return string(str), errors.Trace(err)
+}
+
+func (e *Explain) explainOpInJSONFormat(flat FlatPlanTree, idx int) *ExplainInfoForEncode {
+	flatOp := flat[idx]
+	p := flatOp.Origin
+	if p.ExplainID().String() == "_0" {
+		return nil
+	}
+	info := &ExplainInfoForEncode{ID: p.ExplainID().String() + flatOp.Label.String()}
+	if flatOp.IsRoot {
+		info.TaskType = "root"
+	} else {
+		info.TaskType = flatOp.ReqType.Name() + "[" + flatOp.StoreType.Name() + "]"
+	}
+	info.EstRows, info.EstCost, info.AccessObject, info.OperatorInfo = e.getOperatorInfo(p, info.ID)
+	if e.Analyze || e.RuntimeStatsColl != nil {
+		info.ActRows, info.ExecuteInfo, info.MemoryInfo, info.DiskInfo = getRuntimeInfoStr(e.ctx, p, e.RuntimeStatsColl)
+	}
+	for _, childIdx := range flatOp.ChildrenIdx {
+		if child := e.explainOpInJSONFormat(flat, childIdx); child != nil {
+			info.SubOperators = append(info.SubOperators, child)
+		}
+	}
+	return info
+}
+
 func getRuntimeInfoStr(ctx sessionctx.Context, p Plan, runtimeStatsColl *execdetails.RuntimeStatsColl) (actRows, analyzeInfo, memoryInfo, diskInfo string) {
 	if runtimeStatsColl == nil {
 		runtimeStatsColl = ctx.GetSessionVars().StmtCtx.RuntimeStatsColl
@@ -1909,6 +1909,10 @@ func (er *expressionRewriter) funcCallToExpression(v *ast.FuncCallExpr) {
 	if er.err != nil {
 		return
 	}
+	er.err = expression.CheckFuncCallable(er.sctx, v.FnName.L)
+	if er.err != nil {
+		return
+	}
 
 	if er.rewriteFuncCall(v) {
 		return
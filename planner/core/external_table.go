@@ -0,0 +1,395 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/lightning/config"
+	"github.com/pingcap/tidb/br/pkg/lightning/mydump"
+	"github.com/pingcap/tidb/br/pkg/lightning/worker"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/collate"
+	"github.com/pingcap/tidb/util/sem"
+	"golang.org/x/exp/slices"
+)
+
+const externalTableDefaultName = "external"
+
+// ExternalFile is a file read by the EXTERNAL table function.
+type ExternalFile struct {
+	// Path is the path of the file relative to the storage.
+	Path string
+	Size int64
+}
+
+// ExternalTableExtractor is the extractor of the tables built by the EXTERNAL table
+// function, which reads the files in the external storage without importing them, e.g.
+// SELECT * FROM EXTERNAL('s3://bucket/path/*.parquet', FORMAT => 'parquet') WHERE a > 1
+//
+// Only the columns of the first listed file are read when building the plan, the files
+// are listed and read by the executor.
+//
+// The predicates are pushed down to the files reader, so the rows are filtered right
+// after they are parsed, and only the used columns are read from the Parquet files.
+// The row groups of the Parquet files are skipped by their statistics if they can't
+// satisfy the comparisons between a column and a constant.
+type ExternalTableExtractor struct {
+	// StorageURL is the URL of the directory containing the files.
+	StorageURL string
+	// Pattern matches the names of the files in the directory.
+	Pattern    string
+	SourceType mydump.SourceType
+	// PushedConditions are evaluated on the rows parsed from the files.
+	PushedConditions []expression.Expression
+	// RowGroupConds are the comparisons between a column and a constant among the
+	// PushedConditions, which are used to skip the Parquet row groups.
+	RowGroupConds []config.RowFilterCond
+}
+
+// Extract implements the MemTablePredicateExtractor Extract interface.
+func (e *ExternalTableExtractor) Extract(_ sessionctx.Context,
+	schema *expression.Schema,
+	names []*types.FieldName,
+	predicates []expression.Expression,
+) (remained []expression.Expression) {
+	for _, expr := range predicates {
+		// The values of the correlated columns are unknown to the files reader.
+		if len(expression.ExtractCorColumns(expr)) > 0 {
+			remained = append(remained, expr)
+			continue
+		}
+		e.PushedConditions = append(e.PushedConditions, expr)
+		if cond, ok := extractRowGroupCond(schema, names, expr); ok {
+			e.RowGroupConds = append(e.RowGroupConds, cond)
+		}
+	}
+	return remained
+}
+
+// extractRowGroupCond converts the comparison between a numeric or time column and
+// a constant of the same kind to the condition of the Parquet row filter. The other
+// types are not converted, since the row filter doesn't compare them the same way as
+// the expressions, e.g. it ignores the collations.
+func extractRowGroupCond(schema *expression.Schema, names []*types.FieldName, expr expression.Expression) (config.RowFilterCond, bool) {
+	sf, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return config.RowFilterCond{}, false
+	}
+	var op opcode.Op
+	switch sf.FuncName.L {
+	case ast.EQ:
+		op = opcode.EQ
+	case ast.NE:
+		op = opcode.NE
+	case ast.LT:
+		op = opcode.LT
+	case ast.LE:
+		op = opcode.LE
+	case ast.GT:
+		op = opcode.GT
+	case ast.GE:
+		op = opcode.GE
+	default:
+		return config.RowFilterCond{}, false
+	}
+	args := sf.GetArgs()
+	col, ok1 := args[0].(*expression.Column)
+	con, ok2 := args[1].(*expression.Constant)
+	if !ok1 || !ok2 {
+		// try `constant op column`
+		col, ok1 = args[1].(*expression.Column)
+		con, ok2 = args[0].(*expression.Constant)
+		if !ok1 || !ok2 {
+			return config.RowFilterCond{}, false
+		}
+		op = reverseRowGroupCondOp(op)
+	}
+	if con.DeferredExpr != nil || con.ParamMarker != nil {
+		return config.RowFilterCond{}, false
+	}
+	switch col.GetType().EvalType() {
+	case types.ETInt, types.ETReal, types.ETDecimal, types.ETDatetime, types.ETTimestamp, types.ETDuration:
+	default:
+		return config.RowFilterCond{}, false
+	}
+	switch con.Value.Kind() {
+	case types.KindInt64, types.KindUint64, types.KindFloat32, types.KindFloat64,
+		types.KindMysqlDecimal, types.KindMysqlTime, types.KindMysqlDuration:
+	default:
+		return config.RowFilterCond{}, false
+	}
+	idx := schema.ColumnIndex(col)
+	if idx < 0 {
+		return config.RowFilterCond{}, false
+	}
+	return config.RowFilterCond{Column: names[idx].ColName.L, Op: op, Value: con.Value}, true
+}
+
+func reverseRowGroupCondOp(op opcode.Op) opcode.Op {
+	switch op {
+	case opcode.LT:
+		return opcode.GT
+	case opcode.LE:
+		return opcode.GE
+	case opcode.GT:
+		return opcode.LT
+	case opcode.GE:
+		return opcode.LE
+	default:
+		return op
+	}
+}
+
+// ParquetRowFilter returns the row filter of the Parquet file to skip its row groups.
+// The conditions on the columns absent in the file, or whose constants can't be
+// converted to the types of the columns in the file without loss, are left out.
+// The constants are converted in the session time zone `loc`, like the pushed down
+// conditions. It returns nil if there is no condition.
+func (e *ExternalTableExtractor) ParquetRowFilter(loc *time.Location, parser *mydump.ParquetParser) *config.RowFilter {
+	columns, fts := parser.Columns(), parser.ColumnTypes()
+	sc := &stmtctx.StatementContext{TimeZone: loc}
+	conds := make([]config.RowFilterCond, 0, len(e.RowGroupConds))
+	for _, cond := range e.RowGroupConds {
+		colIdx := slices.Index(columns, cond.Column)
+		if colIdx < 0 {
+			continue
+		}
+		converted, err := cond.Value.ConvertTo(sc, fts[colIdx])
+		if err != nil {
+			continue
+		}
+		if cmp, err := converted.Compare(sc, &cond.Value, collate.GetBinaryCollator()); err != nil || cmp != 0 {
+			continue
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 0 {
+		return nil
+	}
+	return config.NewRowFilter(conds, loc)
+}
+
+func (e *ExternalTableExtractor) explainInfo(_ *PhysicalMemTable) string {
+	r := new(bytes.Buffer)
+	r.WriteString(fmt.Sprintf("format:%s, pattern:%s", e.SourceType, e.Pattern))
+	if len(e.PushedConditions) > 0 {
+		r.WriteString(fmt.Sprintf(", pushed down filter:%s", expression.SortedExplainExpressionList(e.PushedConditions)))
+	}
+	return r.String()
+}
+
+// OpenStorage opens the external storage containing the files.
+func (e *ExternalTableExtractor) OpenStorage(ctx context.Context) (storage.ExternalStorage, error) {
+	backend, err := storage.ParseBackend(e.StorageURL, nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	store, err := storage.New(ctx, backend, &storage.ExternalStorageOptions{})
+	return store, errors.Trace(err)
+}
+
+// errExternalFileFound stops listing the files once the first one is found.
+var errExternalFileFound = errors.New("external file found")
+
+// ListFiles lists the files matching the pattern in the order of their paths. The files
+// in the sub-directories are not matched by the pattern.
+func (e *ExternalTableExtractor) ListFiles(ctx context.Context, store storage.ExternalStorage) ([]ExternalFile, error) {
+	var files []ExternalFile
+	err := store.WalkDir(ctx, &storage.WalkOption{}, func(name string, size int64) error {
+		if matched, _ := path.Match(e.Pattern, name); matched {
+			files = append(files, ExternalFile{Path: name, Size: size})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	slices.SortFunc(files, func(i, j ExternalFile) bool {
+		return i.Path < j.Path
+	})
+	return files, nil
+}
+
+// firstFile returns the first file matching the pattern. The storages list the files
+// in the order of their paths, so it stops listing once a file is found.
+func (e *ExternalTableExtractor) firstFile(ctx context.Context, store storage.ExternalStorage) (ExternalFile, bool, error) {
+	var file ExternalFile
+	err := store.WalkDir(ctx, &storage.WalkOption{}, func(name string, size int64) error {
+		if matched, _ := path.Match(e.Pattern, name); matched {
+			file = ExternalFile{Path: name, Size: size}
+			return errExternalFileFound
+		}
+		return nil
+	})
+	if errors.Cause(err) == errExternalFileFound {
+		return file, true, nil
+	}
+	return file, false, errors.Trace(err)
+}
+
+// OpenParser opens the parser of the file. The column names of the file are available
+// from the parser when it returns.
+func (e *ExternalTableExtractor) OpenParser(ctx context.Context, store storage.ExternalStorage, file ExternalFile) (mydump.Parser, error) {
+	switch e.SourceType {
+	case mydump.SourceTypeParquet:
+		reader, err := mydump.OpenParquetReader(ctx, store, file.Path, file.Size)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		parser, err := mydump.NewParquetParser(ctx, store, reader, file.Path)
+		return parser, errors.Trace(err)
+	default:
+		reader, err := store.Open(ctx, file.Path)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		cfg := config.NewConfig().Mydumper
+		parser, err := mydump.NewCSVParser(ctx, &cfg.CSV, reader, int64(cfg.ReadBlockSize), worker.NewPool(ctx, 1, "external"), false, nil)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := parser.ReadColumns(); err != nil {
+			_ = parser.Close()
+			return nil, errors.Trace(err)
+		}
+		return parser, nil
+	}
+}
+
+// parseExternalTableSource parses the arguments of the EXTERNAL table function.
+func parseExternalTableSource(source *ast.ExternalTableSource) (storageURL string, pattern string, tp mydump.SourceType, err error) {
+	idx := strings.LastIndexByte(source.Location, '/')
+	if idx < 0 {
+		return "", "", 0, ErrWrongArguments.GenWithStackByArgs("EXTERNAL")
+	}
+	storageURL, pattern = source.Location[:idx], source.Location[idx+1:]
+	if _, err := path.Match(pattern, ""); err != nil || len(pattern) == 0 {
+		return "", "", 0, ErrWrongArguments.GenWithStackByArgs("EXTERNAL")
+	}
+
+	format := strings.TrimPrefix(path.Ext(pattern), ".")
+	for _, opt := range source.Options {
+		switch opt.Name {
+		case "FORMAT":
+			format = opt.Value
+		default:
+			return "", "", 0, ErrWrongArguments.GenWithStackByArgs("EXTERNAL")
+		}
+	}
+	switch strings.ToLower(format) {
+	case mydump.TypeCSV:
+		tp = mydump.SourceTypeCSV
+	case mydump.TypeParquet:
+		tp = mydump.SourceTypeParquet
+	default:
+		return "", "", 0, ErrNotSupportedYet.GenWithStackByArgs(fmt.Sprintf("EXTERNAL files of format '%s'", format))
+	}
+	return storageURL, pattern, tp, nil
+}
+
+// buildExternalTable builds the table of the EXTERNAL table function. The columns are
+// taken from the first file matching the location, which is the only file opened here.
+func (b *PlanBuilder) buildExternalTable(ctx context.Context, source *ast.ExternalTableSource, asName model.CIStr) (LogicalPlan, error) {
+	// The privilege is checked before listing the files, so that the users without it
+	// can't probe the storage by the errors.
+	if pm := privilege.GetPrivilegeManager(b.ctx); pm != nil &&
+		!pm.RequestVerification(b.ctx.GetSessionVars().ActiveRoles, "", "", "", mysql.FilePriv) {
+		return nil, ErrSpecificAccessDenied.GenWithStackByArgs("FILE")
+	}
+	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.FilePriv, "", "", "", ErrSpecificAccessDenied.GenWithStackByArgs("FILE"))
+
+	storageURL, pattern, tp, err := parseExternalTableSource(source)
+	if err != nil {
+		return nil, err
+	}
+	if sem.IsEnabled() {
+		u, err := storage.ParseRawURL(storageURL)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		switch u.Scheme {
+		case "hdfs":
+			// Storage is not permitted to be hdfs when SEM is enabled.
+			return nil, ErrNotSupportedWithSem.GenWithStackByArgs("hdfs storage")
+		case "local", "file", "":
+			// Storage is not permitted to be local when SEM is enabled.
+			return nil, ErrNotSupportedWithSem.GenWithStackByArgs("local storage")
+		}
+	}
+	extractor := &ExternalTableExtractor{StorageURL: storageURL, Pattern: pattern, SourceType: tp}
+	store, err := extractor.OpenStorage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	file, ok, err := extractor.firstFile(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrWrongArguments.GenWithStack("no file matches '%s' in EXTERNAL", pattern)
+	}
+
+	parser, err := extractor.OpenParser(ctx, store, file)
+	if err != nil {
+		return nil, err
+	}
+	defer parser.Close()
+	var fieldTypes []*types.FieldType
+	if parquetParser, ok := parser.(*mydump.ParquetParser); ok {
+		fieldTypes = parquetParser.ColumnTypes()
+	}
+
+	tblName := asName
+	if tblName.L == "" {
+		tblName = model.NewCIStr(externalTableDefaultName)
+	}
+	tableInfo := &model.TableInfo{Name: tblName, State: model.StatePublic}
+	for i, name := range parser.Columns() {
+		ft := types.NewFieldTypeWithCollation(mysql.TypeLongBlob, mysql.UTF8MB4DefaultCollation, mysql.MaxLongBlobWidth)
+		if fieldTypes != nil {
+			ft = fieldTypes[i]
+		}
+		tableInfo.Columns = append(tableInfo.Columns, &model.ColumnInfo{
+			ID:        int64(i + 1),
+			Name:      model.NewCIStr(name),
+			Offset:    i,
+			FieldType: *ft,
+			State:     model.StatePublic,
+		})
+	}
+
+	p, err := b.buildMemTable(ctx, model.CIStr{}, tableInfo)
+	if err != nil {
+		return nil, err
+	}
+	p.(*LogicalMemTable).Extractor = extractor
+	return p, nil
+}
@@ -0,0 +1,281 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/sqlexec"
+)
+
+// FederatedTableExtractor is the extractor of the federated tables, which are created by
+// `ENGINE=FEDERATED CONNECTION='server_name/table_name'` and read from a remote MySQL server.
+//
+// The simple predicates on the columns are converted to SQL and pushed down to the remote
+// server, e.g. `a > 1`, `b IN (1, 2)` and `c IS NULL`. All the predicates are still
+// evaluated by TiDB, so the pushed down ones only reduce the rows sent back.
+type FederatedTableExtractor struct {
+	// Server is the name of the server created by CREATE SERVER.
+	Server string
+	// Table is the name of the remote table.
+	Table string
+	// PushedConditions are the SQL conditions sent to the remote server.
+	PushedConditions []string
+}
+
+// Extract implements the MemTablePredicateExtractor Extract interface.
+func (e *FederatedTableExtractor) Extract(sctx sessionctx.Context,
+	schema *expression.Schema,
+	names []*types.FieldName,
+	predicates []expression.Expression,
+) (remained []expression.Expression) {
+	c := &federatedConditionBuilder{schema: schema, names: names, loc: sctx.GetSessionVars().Location()}
+	e.PushedConditions = e.PushedConditions[:0]
+	for _, expr := range predicates {
+		if cond, ok := c.conditionSQL(expr); ok {
+			e.PushedConditions = append(e.PushedConditions, cond)
+		}
+	}
+	// The remote server may compare the values in a different way, e.g. with another
+	// collation, so the predicates are always evaluated again.
+	return predicates
+}
+
+func (e *FederatedTableExtractor) explainInfo(_ *PhysicalMemTable) string {
+	r := new(bytes.Buffer)
+	r.WriteString(fmt.Sprintf("server:%s, table:%s", e.Server, e.Table))
+	if len(e.PushedConditions) > 0 {
+		r.WriteString(fmt.Sprintf(", pushed down filter:%s", strings.Join(e.PushedConditions, " and ")))
+	}
+	return r.String()
+}
+
+var federatedCompareOps = map[string]string{
+	ast.EQ: "=",
+	ast.NE: "!=",
+	ast.LT: "<",
+	ast.LE: "<=",
+	ast.GT: ">",
+	ast.GE: ">=",
+}
+
+// federatedReversedOps maps the operators to the ones with swapped operands.
+var federatedReversedOps = map[string]string{
+	ast.EQ: ast.EQ,
+	ast.NE: ast.NE,
+	ast.LT: ast.GT,
+	ast.LE: ast.GE,
+	ast.GT: ast.LT,
+	ast.GE: ast.LE,
+}
+
+// FederatedTimeZone is the time zone of the connections to the remote servers. The TIMESTAMP
+// values are converted between it and the session time zone.
+const FederatedTimeZone = "+00:00"
+
+// federatedConditionBuilder converts the predicates on the columns of a federated table to
+// the SQL conditions for the remote server.
+type federatedConditionBuilder struct {
+	schema *expression.Schema
+	names  []*types.FieldName
+	// loc is the session time zone, in which the TIMESTAMP constants are.
+	loc *time.Location
+}
+
+// conditionSQL converts the predicate to a SQL condition for the remote server,
+// it returns false if the predicate can't be converted.
+func (c *federatedConditionBuilder) conditionSQL(expr expression.Expression) (string, bool) {
+	fn, ok := expr.(*expression.ScalarFunction)
+	if !ok {
+		return "", false
+	}
+	args := fn.GetArgs()
+	switch fn.FuncName.L {
+	case ast.EQ, ast.NE, ast.LT, ast.LE, ast.GT, ast.GE:
+		name := fn.FuncName.L
+		col, ok := args[0].(*expression.Column)
+		value := args[1]
+		if !ok {
+			col, ok = args[1].(*expression.Column)
+			value = args[0]
+			name = federatedReversedOps[name]
+		}
+		if !ok {
+			return "", false
+		}
+		colName, ok := c.columnName(col)
+		if !ok {
+			return "", false
+		}
+		valueSQL, ok := c.valueSQL(col, value)
+		if !ok {
+			return "", false
+		}
+		return sqlexec.MustEscapeSQL("%n ", colName) + federatedCompareOps[name] + " " + valueSQL, true
+	case ast.In:
+		col, ok := args[0].(*expression.Column)
+		if !ok {
+			return "", false
+		}
+		colName, ok := c.columnName(col)
+		if !ok {
+			return "", false
+		}
+		values := make([]string, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			valueSQL, ok := c.valueSQL(col, arg)
+			if !ok {
+				return "", false
+			}
+			values = append(values, valueSQL)
+		}
+		return sqlexec.MustEscapeSQL("%n IN (", colName) + strings.Join(values, ", ") + ")", true
+	case ast.IsNull:
+		col, ok := args[0].(*expression.Column)
+		if !ok {
+			return "", false
+		}
+		colName, ok := c.columnName(col)
+		if !ok {
+			return "", false
+		}
+		return sqlexec.MustEscapeSQL("%n IS NULL", colName), true
+	case ast.UnaryNot:
+		if isNull, ok := args[0].(*expression.ScalarFunction); ok && isNull.FuncName.L == ast.IsNull {
+			if cond, ok := c.conditionSQL(isNull); ok {
+				return "NOT " + cond, true
+			}
+		}
+		return "", false
+	case ast.LogicOr:
+		left, ok := c.conditionSQL(args[0])
+		if !ok {
+			return "", false
+		}
+		right, ok := c.conditionSQL(args[1])
+		if !ok {
+			return "", false
+		}
+		return "(" + left + " OR " + right + ")", true
+	case ast.LogicAnd:
+		// The predicates are evaluated again, so it's fine to push down a weaker condition
+		// if only one side can be converted.
+		left, leftOK := c.conditionSQL(args[0])
+		right, rightOK := c.conditionSQL(args[1])
+		switch {
+		case leftOK && rightOK:
+			return "(" + left + " AND " + right + ")", true
+		case leftOK:
+			return left, true
+		case rightOK:
+			return right, true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+func (c *federatedConditionBuilder) columnName(col *expression.Column) (string, bool) {
+	idx := c.schema.ColumnIndex(col)
+	if idx < 0 || idx >= len(c.names) {
+		return "", false
+	}
+	return c.names[idx].OrigColName.O, true
+}
+
+// valueSQL converts the constant compared with the column to a SQL literal.
+// Only the values of the integer, DOUBLE, DECIMAL, DATETIME, TIMESTAMP, DATE and TIME columns
+// are converted. The strings are compared with the collations which may be different in the
+// remote server, and the FLOAT values may be rounded differently.
+func (c *federatedConditionBuilder) valueSQL(col *expression.Column, expr expression.Expression) (string, bool) {
+	con, ok := expr.(*expression.Constant)
+	// The constants of the cached plans are changed by the parameters.
+	if !ok || con.DeferredExpr != nil || con.ParamMarker != nil {
+		return "", false
+	}
+	evalType, conEvalType := col.RetType.EvalType(), con.GetType().EvalType()
+	// The DATETIME and TIMESTAMP values are in the same format.
+	if evalType == types.ETTimestamp {
+		evalType = types.ETDatetime
+	}
+	if conEvalType == types.ETTimestamp {
+		conEvalType = types.ETDatetime
+	}
+	if tp := col.RetType.GetType(); tp == mysql.TypeBit || tp == mysql.TypeFloat || conEvalType != evalType {
+		return "", false
+	}
+	d := con.Value
+	switch evalType {
+	case types.ETInt:
+		switch d.Kind() {
+		case types.KindInt64:
+			return strconv.FormatInt(d.GetInt64(), 10), true
+		case types.KindUint64:
+			return strconv.FormatUint(d.GetUint64(), 10), true
+		}
+	case types.ETReal:
+		switch d.Kind() {
+		case types.KindFloat32, types.KindFloat64:
+			return strconv.FormatFloat(d.GetFloat64(), 'g', -1, 64), true
+		}
+	case types.ETDecimal:
+		if d.Kind() == types.KindMysqlDecimal {
+			return d.GetMysqlDecimal().String(), true
+		}
+	case types.ETDatetime:
+		if d.Kind() == types.KindMysqlTime {
+			t := d.GetMysqlTime()
+			if col.RetType.GetType() == mysql.TypeTimestamp {
+				// The remote connection uses FederatedTimeZone.
+				if err := t.ConvertTimeZone(c.loc, time.UTC); err != nil {
+					return "", false
+				}
+			}
+			return sqlexec.MustEscapeSQL("%?", t.String()), true
+		}
+	case types.ETDuration:
+		if d.Kind() == types.KindMysqlDuration {
+			return sqlexec.MustEscapeSQL("%?", d.GetMysqlDuration().String()), true
+		}
+	}
+	return "", false
+}
+
+// buildFederatedTable builds the federated table as a memory table whose rows are read
+// from the remote server.
+func (b *PlanBuilder) buildFederatedTable(ctx context.Context, dbName model.CIStr, tableInfo *model.TableInfo) (LogicalPlan, error) {
+	memTableInfo := *tableInfo
+	memTableInfo.Columns = tableInfo.Cols()
+	p, err := b.buildMemTable(ctx, dbName, &memTableInfo)
+	if err != nil {
+		return nil, err
+	}
+	p.(*LogicalMemTable).Extractor = &FederatedTableExtractor{
+		Server: tableInfo.Federated.Server,
+		Table:  tableInfo.Federated.Table,
+	}
+	return p, nil
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/domain"
+	"github.com/pingcap/tidb/errno"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/parser/auth"
@@ -1691,6 +1692,79 @@ func TestInvisibleIndex(t *testing.T) {
 	tk.MustExec("admin check index t i_a")
 }
 
+func TestHypotheticalIndex(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int, b int, index i_b(b))")
+	tk.MustExec("insert into t values (1,2), (3,4)")
+
+	tk.MustExec("create index h_a on t(a) type hypothetical")
+	tk.MustGetErrCode("create index h_a on t(a) type hypothetical", mysql.ErrDupKeyName)
+	tk.MustExec("create index if not exists h_a on t(a) type hypothetical")
+	tk.MustGetErrCode("create index h_e on t((a + 1)) type hypothetical", errno.ErrUnsupportedDDLOperation)
+
+	// The hypothetical index is never built, it is only visible to EXPLAIN.
+	is := tk.Session().GetInfoSchema().(infoschema.InfoSchema)
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	require.NoError(t, err)
+	hypoIndexes := tk.Session().GetSessionVars().HypoIndexes[tbl.Meta().ID]
+	require.Len(t, hypoIndexes, 1)
+	// It takes an ID which is never taken by the real indexes.
+	require.Equal(t, model.HypoIndexIDBase, hypoIndexes["h_a"].ID)
+	tk.MustExec("create index h_b on t(b) type hypothetical")
+	require.Equal(t, model.HypoIndexIDBase+1, hypoIndexes["h_b"].ID)
+	tk.MustExec("create index i_a on t(a)")
+	require.True(t, tk.MustUseIndex("select /*+ use_index(t, h_a) */ * from t where a = 1", "h_a"))
+	tk.MustExec("drop index i_a on t")
+	tk.MustExec("drop index h_b on t")
+	tk.MustQuery("show index from t where key_name = 'h_a'").Check(testkit.Rows())
+	require.True(t, tk.MustUseIndex("select * from t where a = 1", "h_a"))
+	require.True(t, tk.MustUseIndex("select /*+ use_index(t, h_a) */ * from t where b = 2", "h_a"))
+	tk.MustQuery("select * from t where a = 1").Check(testkit.Rows("1 2"))
+	rs := tk.MustQuery("explain analyze select * from t where a = 1")
+	require.False(t, tk.MustUseIndex4ExplainFor(rs, "h_a"))
+
+	// Hypothetical indexes are local to the session.
+	tk2 := testkit.NewTestKit(t, store)
+	tk2.MustExec("use test")
+	require.False(t, tk2.MustUseIndex("select * from t where a = 1", "h_a"))
+
+	// The hypothetical indexes are bound to the table rather than its name.
+	tk.MustExec("rename table t to t1")
+	require.True(t, tk.MustUseIndex("select * from t1 where a = 1", "h_a"))
+	tk.MustExec("rename table t1 to t")
+	tk.MustExec("drop index h_a on t")
+	require.False(t, tk.MustUseIndex("select * from t where a = 1", "h_a"))
+	tk.MustGetErrCode("drop index h_a on t", mysql.ErrCantDropFieldOrKey)
+	tk.MustExec("create index h_a on t(a) type hypothetical")
+	tk.MustExec("drop table t")
+	tk.MustExec("create table t(a int, b int)")
+	require.False(t, tk.MustUseIndex("select * from t where a = 1", "h_a"))
+	tk.MustGetErrCode("create table t2(a int, index h_a(a) type hypothetical)", errno.ErrUnsupportedDDLOperation)
+
+	// The real and the hypothetical indexes share the names.
+	tk.MustExec("create index h_a on t(a) type hypothetical")
+	tk.MustGetErrCode("create index h_a on t(a)", mysql.ErrDupKeyName)
+	tk.MustExec("create index i_a on t(a)")
+	tk.MustGetErrCode("create index i_a on t(a) type hypothetical", mysql.ErrDupKeyName)
+
+	// The columns are resolved by their names, and the index is dropped once any of them is gone.
+	tk.MustExec("create index h_b on t(b) type hypothetical")
+	tk.MustExec("alter table t add column c int first")
+	require.True(t, tk.MustUseIndex("select * from t where b = 2", "h_b(b)"))
+	tk.MustExec("alter table t drop column b")
+	require.False(t, tk.MustUseIndex("select * from t where c = 2", "h_b"))
+	is = tk.Session().GetInfoSchema().(infoschema.InfoSchema)
+	tbl, err = is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	require.NoError(t, err)
+	hypoIndexes = tk.Session().GetSessionVars().HypoIndexes[tbl.Meta().ID]
+	require.Len(t, hypoIndexes, 1)
+	require.Contains(t, hypoIndexes, "h_a")
+}
+
 // for issue #14822
 func TestIndexJoinTableRange(t *testing.T) {
 	store := testkit.CreateMockStore(t)
@@ -7181,3 +7255,44 @@ func TestEnableTiFlashReadForWriteStmt(t *testing.T) {
 	rs = tk.MustQuery("explain update t set a=a+1 where b in (select a from t2 where t.a > t2.a)").Rows()
 	checkMpp(rs)
 }
+
+func TestTableSamplePercentPushDown(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int)")
+
+	// Create virtual tiflash replica info.
+	dom := domain.GetDomain(tk.Session())
+	is := dom.InfoSchema()
+	db, exists := is.SchemaByName(model.NewCIStr("test"))
+	require.True(t, exists)
+	for _, tblInfo := range db.Tables {
+		if tblInfo.Name.L == "t" {
+			tblInfo.TiFlashReplica = &model.TiFlashReplicaInfo{
+				Count:     1,
+				Available: true,
+			}
+		}
+	}
+
+	tk.MustExec("set @@session.tidb_isolation_read_engines = 'tikv'")
+	tk.MustQuery("explain format = 'brief' select a from t tablesample bernoulli(10 percent) repeatable(42)").Check(testkit.Rows(
+		"Projection 8000.00 root  test.t.a",
+		"└─TableReader 8000.00 root  data:Selection",
+		"  └─Selection 8000.00 cop[tikv]  lt(mod(crc32(concat_ws(\",\", \"42\", cast(test.t._tidb_rowid, var_string(20)))), 1000000), 100000)",
+		"    └─TableFullScan 10000.00 cop[tikv] table:t keep order:false, stats:pseudo"))
+	tk.MustQuery("explain format = 'brief' select a from t tablesample system(12.5) repeatable('abc')").Check(testkit.Rows(
+		"Projection 8000.00 root  test.t.a",
+		"└─TableReader 8000.00 root  data:Selection",
+		"  └─Selection 8000.00 cop[tikv]  lt(mod(crc32(concat_ws(\",\", \"abc\", cast(rightshift(test.t._tidb_rowid, 10), var_string(20)))), 1000000), 125000)",
+		"    └─TableFullScan 10000.00 cop[tikv] table:t keep order:false, stats:pseudo"))
+	tk.MustExec("set @@session.tidb_isolation_read_engines = 'tiflash'")
+	tk.MustQuery("explain format = 'brief' select a from t tablesample bernoulli(10 percent) repeatable(42)").Check(testkit.Rows(
+		"TableReader 8000.00 root  data:ExchangeSender",
+		"└─ExchangeSender 8000.00 mpp[tiflash]  ExchangeType: PassThrough",
+		"  └─Projection 8000.00 mpp[tiflash]  test.t.a",
+		"    └─Selection 8000.00 mpp[tiflash]  lt(mod(crc32(concat_ws(\",\", \"42\", cast(test.t._tidb_rowid, var_string(20)))), 1000000), 100000)",
+		"      └─TableFullScan 10000.00 mpp[tiflash] table:t keep order:false, stats:pseudo"))
+}
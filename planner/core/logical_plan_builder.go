@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"math"
 	"math/bits"
+	"math/rand"
 	"sort"
 	"strconv"
 	"strings"
@@ -368,6 +369,9 @@ func (b *PlanBuilder) buildResultSetNode(ctx context.Context, node ast.ResultSet
 		case *ast.TableName:
 			p, err = b.buildDataSource(ctx, v, &x.AsName)
 			isTableName = true
+		case *ast.ExternalTableSource:
+			p, err = b.buildExternalTable(ctx, v, x.AsName)
+			isTableName = true
 		default:
 			err = ErrUnsupportedType.GenWithStackByArgs(v)
 		}
@@ -4349,6 +4353,13 @@ func (b *PlanBuilder) buildDataSource(ctx context.Context, tn *ast.TableName, as
 		return b.buildMemTable(ctx, dbName, tableInfo)
 	}
 
+	if tableInfo.IsFederated() {
+		if tn.TableSample != nil {
+			return nil, expression.ErrInvalidTableSample.GenWithStackByArgs("Unsupported TABLESAMPLE in federated tables")
+		}
+		return b.buildFederatedTable(ctx, dbName, tableInfo)
+	}
+
 	tblName := *asName
 	if tblName.L == "" {
 		tblName = tn.Name
@@ -4545,8 +4556,10 @@ func (b *PlanBuilder) buildDataSource(ctx context.Context, tn *ast.TableName, as
 	ds.SetSchema(schema)
 	ds.names = names
 	ds.setPreferredStoreType(b.TableHints())
-	ds.SampleInfo = NewTableSampleInfo(tn.TableSample, schema.Clone(), b.partitionedTable)
-	b.isSampling = ds.SampleInfo != nil
+	if tn.TableSample != nil && tn.TableSample.SampleMethod == ast.SampleMethodTypeTiDBRegion {
+		ds.SampleInfo = NewTableSampleInfo(tn.TableSample, schema.Clone(), b.partitionedTable)
+		b.isSampling = true
+	}
 
 	for i, colExpr := range ds.Schema().Columns {
 		var expr expression.Expression
@@ -4602,6 +4615,20 @@ func (b *PlanBuilder) buildDataSource(ctx context.Context, tn *ast.TableName, as
 		result = us
 	}
 
+	if tn.TableSample != nil && tn.TableSample.SampleMethod != ast.SampleMethodTypeTiDBRegion {
+		result, err = b.buildTableSampleSelection(ctx, result, tn.TableSample, handleCols)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if tableInfo.HasLazyOriginDefaultColumns() {
+		result, err = b.buildLazyDefaultProjection(result, columns, handleCols)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Adding ExtraPhysTblIDCol for SelectLock (SELECT FOR UPDATE) is done when building SelectLock
 
 	if sessionVars.StmtCtx.TblInfo2UnionScan == nil {
@@ -4612,6 +4639,158 @@ func (b *PlanBuilder) buildDataSource(ctx context.Context, tn *ast.TableName, as
 	return result, nil
 }
 
+// buildLazyDefaultProjection builds a projection evaluating the lazy default values of the columns for the rows existing
+// before the columns are added, which are read as NULLs from the storage. The other columns are passed through, so the
+// handle columns of the table are kept.
+func (b *PlanBuilder) buildLazyDefaultProjection(p LogicalPlan, columns []*table.Column, handleCols HandleCols) (LogicalPlan, error) {
+	if !handleCols.IsInt() {
+		return nil, ErrInternal.GenWithStack("the lazy default values need an int handle")
+	}
+	handleCol := handleCols.GetCol(0)
+	schema := expression.NewSchema(make([]*expression.Column, 0, p.Schema().Len())...)
+	exprs := make([]expression.Expression, 0, p.Schema().Len())
+	for i, col := range p.Schema().Columns {
+		if i >= len(columns) || !columns[i].HasLazyOriginDefault() {
+			schema.Append(col)
+			exprs = append(exprs, col)
+			continue
+		}
+		expr, err := expression.BuildLazyDefaultFunction(b.ctx, col, handleCol,
+			expression.DatumToConstant(types.NewStringDatum(columns[i].OriginDefaultFunc), mysql.TypeVarString, 0),
+			expression.DatumToConstant(types.NewIntDatum(int64(columns[i].OriginDefaultSeed)), mysql.TypeLonglong, 0))
+		if err != nil {
+			return nil, err
+		}
+		newCol := col.Clone().(*expression.Column)
+		newCol.UniqueID = b.ctx.GetSessionVars().AllocPlanColumnID()
+		schema.Append(newCol)
+		exprs = append(exprs, expr)
+	}
+	proj := LogicalProjection{Exprs: exprs}.Init(b.ctx, b.getSelectOffset())
+	proj.SetChildren(p)
+	proj.SetSchema(schema)
+	proj.names = p.OutputNames()
+	return proj, nil
+}
+
+const (
+	// tableSampleScale is the precision of the sampling percentage, i.e. 0.0001%.
+	tableSampleScale = 1000000
+	// tableSampleBlockBits is the number of the low bits of the int handle ignored
+	// by the SYSTEM sampling, which samples the blocks of 1024 consecutive handles.
+	tableSampleBlockBits = 10
+)
+
+// buildTableSampleSelection builds the filter of the BERNOULLI and SYSTEM
+// sampling methods on top of the data source. A row is sampled when the CRC32 of
+// the seed and its handle falls into the sampling percentage, so the filter can
+// be pushed down to both TiKV and TiFlash, and the same seed always samples the
+// same rows. The SYSTEM method hashes the blocks of consecutive int handles, or
+// the first column of the clustered index, instead of the whole handle.
+func (b *PlanBuilder) buildTableSampleSelection(ctx context.Context, p LogicalPlan, ts *ast.TableSample, handleCols HandleCols) (LogicalPlan, error) {
+	sc := b.ctx.GetSessionVars().StmtCtx
+	// The filter is built from the values of the parameters or a random seed.
+	sc.SkipPlanCache = true
+	// The plan built when preparing the statement only provides the schema.
+	for _, arg := range []ast.ExprNode{ts.Expr, ts.RepeatableSeed} {
+		if param, ok := arg.(*driver.ParamMarkerExpr); ok && !param.InExecute {
+			return p, nil
+		}
+	}
+	percentDatum, err := b.evalTableSampleArg(ctx, ts.Expr, p, "The sampling percentage")
+	if err != nil {
+		return nil, err
+	}
+	percent, err := percentDatum.ToFloat64(sc)
+	if err != nil || percent < 0 || percent > 100 {
+		return nil, expression.ErrInvalidTableSample.GenWithStackByArgs("The sampling percentage must be between 0 and 100")
+	}
+	var seed string
+	if ts.RepeatableSeed != nil {
+		seedDatum, err := b.evalTableSampleArg(ctx, ts.RepeatableSeed, p, "The REPEATABLE seed")
+		if err != nil {
+			return nil, err
+		}
+		if seed, err = seedDatum.ToString(); err != nil {
+			return nil, err
+		}
+	} else {
+		seed = strconv.FormatUint(uint64(rand.Uint32()), 10) // #nosec G404
+	}
+
+	args := []expression.Expression{newTableSampleStrConst(","), newTableSampleStrConst(seed)}
+	if handleCols.IsInt() {
+		var handle expression.Expression = handleCols.GetCol(0)
+		if ts.SampleMethod == ast.SampleMethodTypeSystem {
+			handle, err = expression.NewFunction(b.ctx, ast.RightShift, types.NewFieldType(mysql.TypeLonglong), handle,
+				expression.DatumToConstant(types.NewIntDatum(tableSampleBlockBits), mysql.TypeLonglong, 0))
+			if err != nil {
+				return nil, err
+			}
+		}
+		args = append(args, handle)
+	} else {
+		n := handleCols.NumCols()
+		if ts.SampleMethod == ast.SampleMethodTypeSystem {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			args = append(args, handleCols.GetCol(i))
+		}
+	}
+	hashInput, err := expression.NewFunction(b.ctx, ast.ConcatWS, types.NewFieldType(mysql.TypeVarString), args...)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := expression.NewFunction(b.ctx, ast.CRC32, types.NewFieldType(mysql.TypeLonglong), hashInput)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := expression.NewFunction(b.ctx, ast.Mod, types.NewFieldType(mysql.TypeLonglong), hash,
+		expression.DatumToConstant(types.NewIntDatum(tableSampleScale), mysql.TypeLonglong, 0))
+	if err != nil {
+		return nil, err
+	}
+	threshold := int64(math.Round(percent * tableSampleScale / 100))
+	cond, err := expression.NewFunction(b.ctx, ast.LT, types.NewFieldType(mysql.TypeTiny), bucket,
+		expression.DatumToConstant(types.NewIntDatum(threshold), mysql.TypeLonglong, 0))
+	if err != nil {
+		return nil, err
+	}
+	b.optFlag |= flagPredicatePushDown
+	sel := LogicalSelection{Conditions: []expression.Expression{cond}}.Init(b.ctx, b.getSelectOffset())
+	sel.SetChildren(p)
+	return sel, nil
+}
+
+// evalTableSampleArg evaluates the argument of the TABLESAMPLE clause, which
+// must be a constant other than NULL.
+func (b *PlanBuilder) evalTableSampleArg(ctx context.Context, arg ast.ExprNode, p LogicalPlan, name string) (types.Datum, error) {
+	expr, _, err := b.rewrite(ctx, arg, p, nil, true)
+	if err != nil {
+		return types.Datum{}, err
+	}
+	if _, ok := expr.(*expression.Constant); !ok {
+		return types.Datum{}, expression.ErrInvalidTableSample.GenWithStackByArgs(name + " must be a constant")
+	}
+	d, err := expr.Eval(chunk.Row{})
+	if err != nil {
+		return types.Datum{}, err
+	}
+	if d.IsNull() {
+		return types.Datum{}, expression.ErrInvalidTableSample.GenWithStackByArgs(name + " must not be NULL")
+	}
+	return d, nil
+}
+
+func newTableSampleStrConst(s string) *expression.Constant {
+	tp := types.NewFieldType(mysql.TypeVarString)
+	tp.SetCharset(charset.CharsetBin)
+	tp.SetCollate(charset.CollationBin)
+	tp.SetFlen(len(s))
+	return &expression.Constant{Value: types.NewStringDatum(s), RetType: tp}
+}
+
 // ExtractFD implements the LogicalPlan interface.
 func (ds *DataSource) ExtractFD() *fd.FDSet {
 	// FD in datasource (leaf node) can be cached and reused.
@@ -5416,7 +5595,7 @@ func (b *PlanBuilder) buildUpdateLists(ctx context.Context, tableList []*ast.Tab
 		foundListItem := false
 		for _, tl := range tableList {
 			if (tl.Schema.L == "" || tl.Schema.L == name.DBName.L) && (tl.Name.L == name.TblName.L) {
-				if isCTE(tl) || tl.TableInfo.IsView() || tl.TableInfo.IsSequence() {
+				if isCTE(tl) || tl.TableInfo.IsView() || tl.TableInfo.IsSequence() || tl.TableInfo.IsFederated() {
 					return nil, nil, false, ErrNonUpdatableTable.GenWithStackByArgs(name.TblName.O, "UPDATE")
 				}
 				foundListItem = true
@@ -5439,7 +5618,7 @@ func (b *PlanBuilder) buildUpdateLists(ctx context.Context, tableList []*ast.Tab
 	// And, fill virtualAssignments here; that's for generated columns.
 	virtualAssignments := make([]*ast.Assignment, 0)
 	for _, tn := range tableList {
-		if isCTE(tn) || tn.TableInfo.IsView() || tn.TableInfo.IsSequence() {
+		if isCTE(tn) || tn.TableInfo.IsView() || tn.TableInfo.IsSequence() || tn.TableInfo.IsFederated() {
 			continue
 		}
 
@@ -5735,6 +5914,9 @@ func (b *PlanBuilder) buildDelete(ctx context.Context, ds *ast.DeleteStmt) (Plan
 			if tn.TableInfo.IsSequence() {
 				return nil, errors.Errorf("delete sequence %s is not supported now", tn.Name.O)
 			}
+			if tn.TableInfo.IsFederated() {
+				return nil, errors.Errorf("delete federated table %s is not supported now", tn.Name.O)
+			}
 			if sessionVars.User != nil {
 				authErr = ErrTableaccessDenied.FastGenByArgs("DELETE", sessionVars.User.AuthUsername, sessionVars.User.AuthHostname, tb.Name.L)
 			}
@@ -5754,6 +5936,9 @@ func (b *PlanBuilder) buildDelete(ctx context.Context, ds *ast.DeleteStmt) (Plan
 			if v.TableInfo.IsSequence() {
 				return nil, errors.Errorf("delete sequence %s is not supported now", v.Name.O)
 			}
+			if v.TableInfo.IsFederated() {
+				return nil, errors.Errorf("delete federated table %s is not supported now", v.Name.O)
+			}
 			dbName := v.Schema.L
 			if dbName == "" {
 				dbName = b.ctx.GetSessionVars().CurrentDB
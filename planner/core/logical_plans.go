@@ -1864,9 +1864,10 @@ type ShowContents struct {
 	CountWarningsOrErrors bool // Used for showing count(*) warnings | errors
 
 	Full        bool
-	IfNotExists bool // Used for `show create database if not exists`.
-	GlobalScope bool // Used by show variables.
-	Extended    bool // Used for `show extended columns from ...`
+	IfNotExists bool   // Used for `show create database if not exists`.
+	GlobalScope bool   // Used by show variables.
+	Extended    bool   // Used for `show extended columns from ...`
+	SnapshotTS  uint64 // Used for `show create table ... as of timestamp ...`.
 }
 
 // LogicalShow represents a show plan.
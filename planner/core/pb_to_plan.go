@@ -254,6 +254,8 @@ func (b *PBPlanBuilder) pbToKill(e *tipb.Executor) (PhysicalPlan, error) {
 	node := &ast.KillStmt{
 		ConnectionID: e.Kill.ConnID,
 		Query:        e.Kill.Query,
+		// The executor ID carries the digest of the kill sent by KILL DIGEST.
+		Digest: e.GetExecutorId(),
 	}
 	simple := Simple{Statement: node, IsFromRemote: true}
 	return &PhysicalSimpleWrapper{Inner: simple}, nil
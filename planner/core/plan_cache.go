@@ -125,6 +125,9 @@ func GetPlanFromSessionPlanCache(ctx context.Context, sctx sessionctx.Context,
 	stmtCtx := sessVars.StmtCtx
 	stmtAst := stmt.PreparedAst
 	stmtCtx.UseCache = stmtAst.UseCache
+	// The cached plans are not built again, so the accessed tables are got
+	// from the visit info of the prepared statement.
+	stmtCtx.Tables = getDBTableInfo(stmt.VisitInfos)
 
 	var bindSQL string
 	var ignorePlanCache = false
@@ -241,6 +244,7 @@ func getGeneralPlan(sctx sessionctx.Context, isGeneralPlanCache bool, cacheKey k
 		// When the `len(bindSQL) > 0`, it means we use the binding.
 		// So we need to record this.
 		sessVars.FoundInBinding = true
+		sessVars.StmtCtx.BindSQL = bindSQL
 	}
 	if metrics.ResettablePlanCacheCounterFortTest {
 		metrics.PlanCacheCounter.WithLabelValues("prepare").Inc()
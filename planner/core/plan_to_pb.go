@@ -606,6 +606,11 @@ func SetPBColumnsDefaultValue(ctx sessionctx.Context, pbColumns []*tipb.ColumnIn
 		if c.IsGenerated() && !c.GeneratedStored {
 			pbColumns[i].DefaultVal = []byte{codec.NilFlag}
 		}
+		// The same for the columns with lazy default values, they are evaluated by TiDB from the handles.
+		if c.HasLazyOriginDefault() {
+			pbColumns[i].DefaultVal = []byte{codec.NilFlag}
+			continue
+		}
 		if c.GetOriginDefaultValue() == nil {
 			continue
 		}
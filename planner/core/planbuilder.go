@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -56,6 +57,7 @@ import (
 	"github.com/pingcap/tidb/util/chunk"
 	"github.com/pingcap/tidb/util/dbterror"
 	"github.com/pingcap/tidb/util/execdetails"
+	"github.com/pingcap/tidb/util/gcutil"
 	"github.com/pingcap/tidb/util/hint"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/mathutil"
@@ -590,6 +592,10 @@ func (b *PlanBuilder) GetIsForUpdateRead() bool {
 
 // GetDBTableInfo gets the accessed dbs and tables info.
 func (b *PlanBuilder) GetDBTableInfo() []stmtctx.TableEntry {
+	return getDBTableInfo(b.visitInfo)
+}
+
+func getDBTableInfo(visitInfos []visitInfo) []stmtctx.TableEntry {
 	var tables []stmtctx.TableEntry
 	existsFunc := func(tbls []stmtctx.TableEntry, tbl *stmtctx.TableEntry) bool {
 		for _, t := range tbls {
@@ -599,7 +605,7 @@ func (b *PlanBuilder) GetDBTableInfo() []stmtctx.TableEntry {
 		}
 		return false
 	}
-	for _, v := range b.visitInfo {
+	for _, v := range visitInfos {
 		tbl := &stmtctx.TableEntry{DB: v.db, Table: v.table}
 		if !existsFunc(tables, tbl) {
 			tables = append(tables, *tbl)
@@ -765,7 +771,7 @@ func (b *PlanBuilder) Build(ctx context.Context, node ast.Node) (Plan, error) {
 		*ast.BeginStmt, *ast.CommitStmt, *ast.SavepointStmt, *ast.ReleaseSavepointStmt, *ast.RollbackStmt, *ast.CreateUserStmt, *ast.SetPwdStmt, *ast.AlterInstanceStmt,
 		*ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt, *ast.KillStmt, *ast.DropStatsStmt,
 		*ast.GrantRoleStmt, *ast.RevokeRoleStmt, *ast.SetRoleStmt, *ast.SetDefaultRoleStmt, *ast.ShutdownStmt,
-		*ast.RenameUserStmt, *ast.NonTransactionalDeleteStmt, *ast.SetSessionStatesStmt:
+		*ast.RenameUserStmt, *ast.NonTransactionalDeleteStmt, *ast.SetSessionStatesStmt, *ast.CreateServerStmt, *ast.DropServerStmt:
 		return b.buildSimple(ctx, node.(ast.StmtNode))
 	case ast.DDLNode:
 		return b.buildDDL(ctx, x)
@@ -1133,6 +1139,20 @@ func getLatestIndexInfo(ctx sessionctx.Context, id int64, startVer int64) (map[i
 	return latestIndexes, true, nil
 }
 
+// resolveHypoIndex returns a copy of the hypothetical index with the offsets of the columns resolved
+// by their names in the table, it returns false if any of the columns doesn't exist any more.
+func resolveHypoIndex(tblInfo *model.TableInfo, index *model.IndexInfo) (*model.IndexInfo, bool) {
+	resolved := index.Clone()
+	for _, idxCol := range resolved.Columns {
+		col := model.FindColumnInfo(tblInfo.Columns, idxCol.Name.L)
+		if col == nil || col.State != model.StatePublic {
+			return nil, false
+		}
+		idxCol.Offset = col.Offset
+	}
+	return resolved, true
+}
+
 func getPossibleAccessPaths(ctx sessionctx.Context, tableHints *tableHintInfo, indexHints []*ast.IndexHint, tbl table.Table, dbName, tblName model.CIStr, check bool, _ int64) ([]*util.AccessPath, error) {
 	tblInfo := tbl.Meta()
 	publicPaths := make([]*util.AccessPath, 0, len(tblInfo.Indices)+2)
@@ -1148,6 +1168,9 @@ func getPossibleAccessPaths(ctx sessionctx.Context, tableHints *tableHintInfo, i
 		ctx.GetSessionVars().RaiseWarningWhenMPPEnforced("MPP mode may be blocked because there aren't tiflash replicas of table `" + tblInfo.Name.O + "`.")
 	} else if !tblInfo.TiFlashReplica.Available {
 		ctx.GetSessionVars().RaiseWarningWhenMPPEnforced("MPP mode may be blocked because tiflash replicas of table `" + tblInfo.Name.O + "` not ready.")
+	} else if tblInfo.HasLazyOriginDefaultColumns() {
+		// TiFlash doesn't know the lazy default values, which are evaluated by TiDB from the NULLs read from TiKV.
+		ctx.GetSessionVars().RaiseWarningWhenMPPEnforced("MPP mode may be blocked because table `" + tblInfo.Name.O + "` has columns with lazy default values.")
 	} else {
 		publicPaths = append(publicPaths, genTiFlashPath(tblInfo))
 	}
@@ -1183,6 +1206,36 @@ func getPossibleAccessPaths(ctx sessionctx.Context, tableHints *tableHintInfo, i
 		}
 	}
 
+	// Hypothetical indexes are only visible to the optimizer when explaining a statement.
+	if stmtCtx := ctx.GetSessionVars().StmtCtx; stmtCtx.InExplainStmt && !stmtCtx.InExplainAnalyzeStmt {
+		if hypoIndexes := ctx.GetSessionVars().HypoIndexes[tblInfo.ID]; len(hypoIndexes) > 0 {
+			hypoPaths := make([]*util.AccessPath, 0, len(hypoIndexes))
+			for name, index := range hypoIndexes {
+				// The columns may be added or dropped since the index is created, so the offsets are
+				// resolved by the names again, and the index is dropped once any of its columns is gone.
+				resolved, ok := resolveHypoIndex(tblInfo, index)
+				if !ok {
+					delete(hypoIndexes, name)
+					continue
+				}
+				// A real index with the same name may be added by another session.
+				if tblInfo.FindIndexByName(name) != nil {
+					continue
+				}
+				hypoPaths = append(hypoPaths, &util.AccessPath{Index: resolved})
+			}
+			if len(hypoIndexes) == 0 {
+				delete(ctx.GetSessionVars().HypoIndexes, tblInfo.ID)
+			}
+			sort.Slice(hypoPaths, func(i, j int) bool {
+				return hypoPaths[i].Index.ID < hypoPaths[j].Index.ID
+			})
+			publicPaths = append(publicPaths, hypoPaths...)
+			// The plan depends on the session-local hypothetical indexes, so it can't be shared.
+			stmtCtx.SkipPlanCache = true
+		}
+	}
+
 	hasScanHint, hasUseOrForce := false, false
 	available := make([]*util.AccessPath, 0, len(publicPaths))
 	ignored := make([]*util.AccessPath, 0, len(publicPaths))
@@ -1486,6 +1539,8 @@ func (b *PlanBuilder) buildAdmin(ctx context.Context, as *ast.AdminStmt) (Plan,
 		return &Simple{Statement: as}, nil
 	case ast.AdminFlushPlanCache:
 		return &Simple{Statement: as}, nil
+	case ast.AdminExportStats, ast.AdminImportStats:
+		ret = &Simple{Statement: as}
 	default:
 		return nil, ErrUnsupportedType.GenWithStack("Unsupported ast.AdminStmt(%T) for buildAdmin", as)
 	}
@@ -2873,7 +2928,7 @@ func buildShowSlowSchema() (*expression.Schema, types.NameSlice) {
 	timestampSize, _ := mysql.GetDefaultFieldLengthAndDecimal(mysql.TypeTimestamp)
 	durationSize, _ := mysql.GetDefaultFieldLengthAndDecimal(mysql.TypeDuration)
 
-	schema := newColumnsWithNames(11)
+	schema := newColumnsWithNames(16)
 	schema.Append(buildColumnWithName("", "SQL", mysql.TypeVarchar, 4096))
 	schema.Append(buildColumnWithName("", "START", mysql.TypeTimestamp, timestampSize))
 	schema.Append(buildColumnWithName("", "DURATION", mysql.TypeDuration, durationSize))
@@ -2887,6 +2942,9 @@ func buildShowSlowSchema() (*expression.Schema, types.NameSlice) {
 	schema.Append(buildColumnWithName("", "INDEX_IDS", mysql.TypeVarchar, 256))
 	schema.Append(buildColumnWithName("", "INTERNAL", mysql.TypeTiny, tinySize))
 	schema.Append(buildColumnWithName("", "DIGEST", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "PLAN_DIGEST", mysql.TypeVarchar, 64))
+	schema.Append(buildColumnWithName("", "BINDING", mysql.TypeVarchar, 4096))
+	schema.Append(buildColumnWithName("", "STATS", mysql.TypeVarchar, 512))
 	return schema.col2Schema(), schema.names
 }
 
@@ -2999,6 +3057,22 @@ func (b *PlanBuilder) buildShow(ctx context.Context, show *ast.ShowStmt) (Plan,
 			Extended:              show.Extended,
 		},
 	}.Init(b.ctx)
+	if show.AsOf != nil {
+		if show.Table.Schema.L == "" {
+			return nil, ErrNoDB
+		}
+		snapshotTS, err := staleread.CalculateAsOfTsExpr(b.ctx, show.AsOf)
+		if err != nil {
+			return nil, err
+		}
+		if err := sessionctx.ValidateSnapshotReadTS(ctx, b.ctx, snapshotTS); err != nil {
+			return nil, err
+		}
+		if err := gcutil.ValidateSnapshot(b.ctx, snapshotTS); err != nil {
+			return nil, err
+		}
+		p.SnapshotTS = snapshotTS
+	}
 	isView := false
 	isSequence := false
 	// It depends on ShowPredicateExtractor now
@@ -3141,6 +3215,15 @@ func (b *PlanBuilder) buildSimple(ctx context.Context, node ast.StmtNode) (Plan,
 	case *ast.AlterInstanceStmt:
 		err := ErrSpecificAccessDenied.GenWithStack("SUPER")
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "", err)
+	case *ast.CreateServerStmt:
+		if !strings.EqualFold(raw.Wrapper, "mysql") {
+			return nil, ErrNotSupportedYet.GenWithStackByArgs(fmt.Sprintf("foreign data wrapper '%s'", raw.Wrapper))
+		}
+		err := ErrSpecificAccessDenied.GenWithStackByArgs("SUPER")
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "", err)
+	case *ast.DropServerStmt:
+		err := ErrSpecificAccessDenied.GenWithStackByArgs("SUPER")
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "", err)
 	case *ast.RenameUserStmt:
 		err := ErrSpecificAccessDenied.GenWithStackByArgs("CREATE USER")
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.CreateUserPriv, "", "", "", err)
@@ -3179,8 +3262,13 @@ func (b *PlanBuilder) buildSimple(ctx context.Context, node ast.StmtNode) (Plan,
 		// All users can kill their own connections regardless.
 		// If you have the SUPER privilege, you can kill all threads and statements unless SEM is enabled.
 		// In which case you require RESTRICTED_CONNECTION_ADMIN to kill connections that belong to RESTRICTED_USER_ADMIN users.
+		// KILL DIGEST kills the statements of all the users, so it always requires SUPER or CONNECTION_ADMIN.
+		// The statements of RESTRICTED_USER_ADMIN users are skipped by the executor unless you have RESTRICTED_CONNECTION_ADMIN.
 		sm := b.ctx.GetSessionManager()
-		if sm != nil {
+		if raw.Digest != "" {
+			err := ErrSpecificAccessDenied.GenWithStackByArgs("SUPER or CONNECTION_ADMIN")
+			b.visitInfo = appendDynamicVisitInfo(b.visitInfo, "CONNECTION_ADMIN", false, err)
+		} else if sm != nil {
 			if pi, ok := sm.GetProcessInfo(raw.ConnectionID); ok {
 				loginUser := b.ctx.GetSessionVars().User
 				if pi.User != loginUser.Username {
@@ -3447,6 +3535,13 @@ func (b *PlanBuilder) buildInsert(ctx context.Context, insert *ast.InsertStmt) (
 		}
 		return nil, err
 	}
+	if tableInfo.IsFederated() {
+		err := errors.Errorf("insert into federated table %s is not supported now", tableInfo.Name.O)
+		if insert.IsReplace {
+			err = errors.Errorf("replace into federated table %s is not supported now", tableInfo.Name.O)
+		}
+		return nil, err
+	}
 	// Build Schema with DBName otherwise ColumnRef with DBName cannot match any Column in Schema.
 	schema, names, err := expression.TableInfo2SchemaAndNames(b.ctx, tn.Schema, tableInfo)
 	if err != nil {
@@ -3558,11 +3653,50 @@ func (b *PlanBuilder) buildInsert(ctx context.Context, insert *ast.InsertStmt) (
 	if err != nil {
 		return nil, err
 	}
+	insertPlan.UseIngest = b.canInsertByIngest(insert, insertPlan)
 
 	err = insertPlan.ResolveIndices()
 	return insertPlan, err
 }
 
+// canInsertByIngest checks whether the rows of INSERT ... SELECT can be ingested into the
+// table as sorted KV files, which requires the SELECT is estimated to return at least
+// tidb_insert_select_ingest_threshold rows, and no row needs to be checked against the
+// constraints, since the ingested rows overwrite the existing ones silently.
+func (b *PlanBuilder) canInsertByIngest(insert *ast.InsertStmt, insertPlan *Insert) bool {
+	threshold := b.ctx.GetSessionVars().InsertSelectIngestThreshold
+	if threshold <= 0 || insertPlan.SelectPlan == nil {
+		return false
+	}
+	if insert.IsReplace || insert.IgnoreErr || len(insert.OnDuplicate) > 0 {
+		return false
+	}
+	tblInfo := insertPlan.Table.Meta()
+	if tblInfo.TempTableType != model.TempTableNone || tblInfo.TableCacheStatusType != model.TableCacheStatusDisable ||
+		tblInfo.GetPartitionInfo() != nil || len(tblInfo.ForeignKeys) > 0 {
+		return false
+	}
+	for _, idx := range tblInfo.Indices {
+		// the unique keys include the clustered primary key of the common handle.
+		if idx.Unique {
+			return false
+		}
+	}
+	if tblInfo.PKIsHandle {
+		// the int handle is unique only if it's always allocated.
+		pk := tblInfo.GetPkColInfo()
+		if pk == nil || (!mysql.HasAutoIncrementFlag(pk.GetFlag()) && !tblInfo.ContainsAutoRandomBits()) || len(insert.Columns) == 0 {
+			return false
+		}
+		for _, col := range insert.Columns {
+			if col.Name.L == pk.Name.L {
+				return false
+			}
+		}
+	}
+	return insertPlan.SelectPlan.statsInfo().RowCount >= float64(threshold)
+}
+
 func (p *Insert) resolveOnDuplicate(onDup []*ast.Assignment, tblInfo *model.TableInfo, yield func(ast.ExprNode) (expression.Expression, error)) (map[string]struct{}, error) {
 	onDupColSet := make(map[string]struct{}, len(onDup))
 	colMap := make(map[string]*table.Column, len(p.Table.Cols()))
@@ -3959,6 +4093,9 @@ func (b *PlanBuilder) buildLoadData(ctx context.Context, ld *ast.LoadDataStmt) (
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.DeletePriv, p.Table.Schema.O, p.Table.Name.O, "", deleteErr)
 	}
 	tableInfo := p.Table.TableInfo
+	if tableInfo.IsFederated() {
+		return nil, errors.Errorf("load data into federated table %s is not supported now", tableInfo.Name.O)
+	}
 	tableInPlan, ok := b.is.TableByID(tableInfo.ID)
 	if !ok {
 		db := b.ctx.GetSessionVars().CurrentDB
@@ -4478,6 +4615,12 @@ func (b *PlanBuilder) buildDDL(ctx context.Context, node ast.DDLNode) (Plan, err
 	case *ast.RecoverTableStmt, *ast.FlashBackTableStmt:
 		// Recover table command can only be executed by administrator.
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "", nil)
+	case *ast.PurgeTableStmt:
+		if b.ctx.GetSessionVars().User != nil {
+			authErr = ErrTableaccessDenied.GenWithStackByArgs("DROP", b.ctx.GetSessionVars().User.AuthUsername,
+				b.ctx.GetSessionVars().User.AuthHostname, v.Table.Name.L)
+		}
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.DropPriv, v.Table.Schema.L, v.Table.Name.L, "", authErr)
 	case *ast.LockTablesStmt, *ast.UnlockTablesStmt:
 		// TODO: add Lock Table privilege check.
 	case *ast.CleanupTableLockStmt:
@@ -4561,7 +4704,7 @@ func (b *PlanBuilder) buildTrace(trace *ast.TraceStmt) (Plan, error) {
 	return p, nil
 }
 
-func (b *PlanBuilder) buildExplainPlan(targetPlan Plan, format string, explainRows [][]string, analyze bool, execStmt ast.StmtNode, runtimeStats *execdetails.RuntimeStatsColl) (Plan, error) {
+func (b *PlanBuilder) buildExplainPlan(targetPlan Plan, format string, explainRows [][]string, analyze bool, execStmt ast.StmtNode, runtimeStats *execdetails.RuntimeStatsColl, outfile string) (Plan, error) {
 	p := &Explain{
 		TargetPlan:       targetPlan,
 		Format:           format,
@@ -4569,6 +4712,7 @@ func (b *PlanBuilder) buildExplainPlan(targetPlan Plan, format string, explainRo
 		ExecStmt:         execStmt,
 		ExplainRows:      explainRows,
 		RuntimeStatsColl: runtimeStats,
+		Outfile:          outfile,
 	}
 	p.ctx = b.ctx
 	return p, p.prepareSchema()
@@ -4597,19 +4741,25 @@ func (b *PlanBuilder) buildExplainFor(explainFor *ast.ExplainForStmt) (Plan, err
 	if explainFor.Format == types.ExplainFormatROW {
 		explainRows = processInfo.PlanExplainRows
 	}
-	return b.buildExplainPlan(targetPlan, explainFor.Format, explainRows, false, nil, processInfo.RuntimeStatsColl)
+	return b.buildExplainPlan(targetPlan, explainFor.Format, explainRows, false, nil, processInfo.RuntimeStatsColl, "")
 }
 
 func (b *PlanBuilder) buildExplain(ctx context.Context, explain *ast.ExplainStmt) (Plan, error) {
 	if show, ok := explain.Stmt.(*ast.ShowStmt); ok {
 		return b.buildShow(ctx, show)
 	}
+	if explain.Outfile != "" {
+		if sem.IsEnabled() {
+			return nil, ErrNotSupportedWithSem.GenWithStackByArgs("EXPLAIN INTO")
+		}
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.FilePriv, "", "", "", ErrSpecificAccessDenied.GenWithStackByArgs("FILE"))
+	}
 	targetPlan, _, err := OptimizeAstNode(ctx, b.ctx, explain.Stmt, b.is)
 	if err != nil {
 		return nil, err
 	}
 
-	return b.buildExplainPlan(targetPlan, explain.Format, nil, explain.Analyze, explain.Stmt, nil)
+	return b.buildExplainPlan(targetPlan, explain.Format, nil, explain.Analyze, explain.Stmt, nil, explain.Outfile)
 }
 
 func (b *PlanBuilder) buildSelectInto(ctx context.Context, sel *ast.SelectStmt) (Plan, error) {
@@ -4618,14 +4768,15 @@ func (b *PlanBuilder) buildSelectInto(ctx context.Context, sel *ast.SelectStmt)
 	}
 	selectIntoInfo := sel.SelectIntoOpt
 	sel.SelectIntoOpt = nil
-	targetPlan, _, err := OptimizeAstNode(ctx, b.ctx, sel, b.is)
+	targetPlan, names, err := OptimizeAstNode(ctx, b.ctx, sel, b.is)
 	if err != nil {
 		return nil, err
 	}
 	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.FilePriv, "", "", "", ErrSpecificAccessDenied.GenWithStackByArgs("FILE"))
 	return &SelectInto{
-		TargetPlan: targetPlan,
-		IntoOpt:    selectIntoInfo,
+		TargetPlan:  targetPlan,
+		TargetNames: names,
+		IntoOpt:     selectIntoInfo,
 	}, nil
 }
 
@@ -4865,6 +5016,9 @@ func buildShowSchema(s *ast.ShowStmt, isView bool, isSequence bool) (schema *exp
 	case ast.ShowSessionStates:
 		names = []string{"Session_states", "Session_token"}
 		ftypes = []byte{mysql.TypeJSON, mysql.TypeJSON}
+	case ast.ShowRecycleBin:
+		names = []string{"Db_name", "Table_name", "Table_id", "Job_id", "Drop_time", "Expire_time", "Size"}
+		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLonglong, mysql.TypeLonglong, mysql.TypeDatetime, mysql.TypeDatetime, mysql.TypeLonglong}
 	}
 
 	schema = expression.NewSchema(make([]*expression.Column, 0, len(names))...)
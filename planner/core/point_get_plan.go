@@ -786,7 +786,8 @@ func tryWhereIn2BatchPointGet(ctx sessionctx.Context, selStmt *ast.SelectStmt) *
 		return nil
 	}
 	tbl := tblName.TableInfo
-	if tbl == nil {
+	// The rows of federated tables are read from the remote server.
+	if tbl == nil || tbl.IsFederated() {
 		return nil
 	}
 	// Skip the optimization with partition selection.
@@ -795,7 +796,7 @@ func tryWhereIn2BatchPointGet(ctx sessionctx.Context, selStmt *ast.SelectStmt) *
 	}
 
 	for _, col := range tbl.Columns {
-		if col.IsGenerated() || col.State != model.StatePublic {
+		if col.IsGenerated() || col.HasLazyOriginDefault() || col.State != model.StatePublic {
 			return nil
 		}
 	}
@@ -882,14 +883,15 @@ func tryPointGetPlan(ctx sessionctx.Context, selStmt *ast.SelectStmt, check bool
 		return nil
 	}
 	tbl := tblName.TableInfo
-	if tbl == nil {
+	// The rows of federated tables are read from the remote server.
+	if tbl == nil || tbl.IsFederated() {
 		return nil
 	}
 	pi := tbl.GetPartitionInfo()
 
 	for _, col := range tbl.Columns {
-		// Do not handle generated columns.
-		if col.IsGenerated() {
+		// Do not handle generated columns and the columns with lazy default values.
+		if col.IsGenerated() || col.HasLazyOriginDefault() {
 			return nil
 		}
 		// Only handle tables that all columns are public.
@@ -216,6 +216,10 @@ func (p *preprocessor) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
 		p.flag |= inCreateOrDropTable
 		p.stmtTp = TypeDrop
 		p.checkDropTableGrammar(node)
+	case *ast.PurgeTableStmt:
+		// The table to purge has been dropped.
+		p.flag |= inCreateOrDropTable
+		p.stmtTp = TypeDrop
 	case *ast.RenameTableStmt:
 		p.stmtTp = TypeRename
 		p.flag |= inCreateOrDropTable
@@ -240,6 +244,14 @@ func (p *preprocessor) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
 		p.stmtTp = TypeShow
 		p.showTp = node.Tp
 		p.resolveShowStmt(node)
+		if node.AsOf != nil {
+			// The table of `show create table ... as of timestamp` may not exist in the latest schema,
+			// so skip check table name here, it's resolved in the historical schema when executing.
+			if node.Table != nil && node.Table.Schema.L == "" {
+				node.Table.Schema = model.NewCIStr(node.DBName)
+			}
+			return in, true
+		}
 	case *ast.SetOprSelectList:
 		p.checkSetOprSelectList(node)
 	case *ast.DeleteTableList:
@@ -296,11 +308,7 @@ func (p *preprocessor) Enter(in ast.Node) (out ast.Node, skipChildren bool) {
 			p.err = dbterror.ErrDerivedMustHaveAlias.GenWithStackByArgs()
 		}
 		if v, ok := node.Source.(*ast.TableName); ok && v.TableSample != nil {
-			switch v.TableSample.SampleMethod {
-			case ast.SampleMethodTypeTiDBRegion:
-			default:
-				p.err = expression.ErrInvalidTableSample.GenWithStackByArgs("Only supports REGIONS sampling method")
-			}
+			p.checkTableSample(v.TableSample)
 		}
 	case *ast.GroupByClause:
 		p.checkGroupBy(node)
@@ -488,7 +496,7 @@ func (p *preprocessor) Leave(in ast.Node) (out ast.Node, ok bool) {
 		p.checkContainDotColumn(x)
 	case *ast.CreateViewStmt:
 		p.flag &= ^inCreateOrDropTable
-	case *ast.DropTableStmt, *ast.AlterTableStmt, *ast.RenameTableStmt:
+	case *ast.DropTableStmt, *ast.AlterTableStmt, *ast.RenameTableStmt, *ast.PurgeTableStmt:
 		p.flag &= ^inCreateOrDropTable
 	case *driver.ParamMarkerExpr:
 		if p.flag&inPrepare == 0 {
@@ -1046,6 +1054,38 @@ func (p *preprocessor) checkCreateIndexGrammar(stmt *ast.CreateIndexStmt) {
 	p.err = checkIndexInfo(stmt.IndexName, stmt.IndexPartSpecifications)
 }
 
+func (p *preprocessor) checkTableSample(ts *ast.TableSample) {
+	switch ts.SampleMethod {
+	case ast.SampleMethodTypeTiDBRegion:
+		return
+	case ast.SampleMethodTypeBernoulli, ast.SampleMethodTypeSystem:
+	default:
+		p.err = expression.ErrInvalidTableSample.GenWithStackByArgs("Only supports REGIONS, BERNOULLI and SYSTEM sampling methods")
+		return
+	}
+	if ts.Expr == nil {
+		p.err = expression.ErrInvalidTableSample.GenWithStackByArgs("The sampling percentage is missing")
+		return
+	}
+	if ts.SampleClauseUnit == ast.SampleClauseUnitTypeRow {
+		p.err = expression.ErrInvalidTableSample.GenWithStackByArgs("Only supports sampling by PERCENT")
+		return
+	}
+	// The other expressions are evaluated and checked when the plan is built.
+	if x, ok := ts.Expr.(*driver.ValueExpr); ok {
+		switch x.Kind() {
+		case types.KindInt64, types.KindUint64, types.KindFloat32, types.KindFloat64, types.KindMysqlDecimal:
+		default:
+			p.err = expression.ErrInvalidTableSample.GenWithStackByArgs("The sampling percentage must be a number")
+			return
+		}
+		percent, err := x.ToFloat64(p.ctx.GetSessionVars().StmtCtx)
+		if err != nil || percent < 0 || percent > 100 {
+			p.err = expression.ErrInvalidTableSample.GenWithStackByArgs("The sampling percentage must be between 0 and 100")
+		}
+	}
+}
+
 func (p *preprocessor) checkGroupBy(stmt *ast.GroupByClause) {
 	noopFuncsMode := p.ctx.GetSessionVars().NoopFuncsMode
 	for _, item := range stmt.Items {
@@ -274,8 +274,12 @@ func TestValidator(t *testing.T) {
 		// TABLESAMPLE
 		{"select * from t tablesample bernoulli();", false, expression.ErrInvalidTableSample},
 		{"select * from t tablesample bernoulli(10 rows);", false, expression.ErrInvalidTableSample},
-		{"select * from t tablesample bernoulli(23 percent) repeatable (23);", false, expression.ErrInvalidTableSample},
+		{"select * from t tablesample bernoulli(23 percent) repeatable (23);", false, nil},
+		{"select * from t tablesample bernoulli(123 percent);", false, expression.ErrInvalidTableSample},
 		{"select * from t tablesample system() repeatable (10);", false, expression.ErrInvalidTableSample},
+		{"select * from t tablesample system(10.5);", false, nil},
+		{"select * from t tablesample (10 percent);", false, expression.ErrInvalidTableSample},
+		{"select * from t tablesample bernoulli(?) repeatable (?);", true, nil},
 	}
 
 	store := testkit.CreateMockStore(t)
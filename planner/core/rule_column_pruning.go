@@ -357,20 +357,29 @@ func (ds *DataSource) PruneColumns(parentUsedCols []*expression.Column, opt *log
 
 // PruneColumns implements LogicalPlan interface.
 func (p *LogicalMemTable) PruneColumns(parentUsedCols []*expression.Column, opt *logicalOptimizeOp) error {
-	switch p.TableInfo.Name.O {
-	case infoschema.TableStatementsSummary,
-		infoschema.TableStatementsSummaryHistory,
-		infoschema.TableSlowQuery,
-		infoschema.ClusterTableStatementsSummary,
-		infoschema.ClusterTableStatementsSummaryHistory,
-		infoschema.ClusterTableSlowLog,
-		infoschema.TableTiDBTrx,
-		infoschema.ClusterTableTiDBTrx,
-		infoschema.TableDataLockWaits,
-		infoschema.TableDeadlocks,
-		infoschema.ClusterTableDeadlocks:
+	switch extractor := p.Extractor.(type) {
+	case *ExternalTableExtractor:
+		// The columns used by the pushed down predicates need to be read from the files.
+		parentUsedCols = expression.ExtractColumnsFromExpressions(parentUsedCols, extractor.PushedConditions, nil)
+	case *FederatedTableExtractor:
+		// Only the columns left below are selected from the remote server. The pushed down
+		// conditions are sent as SQL, so their columns needn't be read unless used above.
 	default:
-		return nil
+		switch p.TableInfo.Name.O {
+		case infoschema.TableStatementsSummary,
+			infoschema.TableStatementsSummaryHistory,
+			infoschema.TableSlowQuery,
+			infoschema.ClusterTableStatementsSummary,
+			infoschema.ClusterTableStatementsSummaryHistory,
+			infoschema.ClusterTableSlowLog,
+			infoschema.TableTiDBTrx,
+			infoschema.ClusterTableTiDBTrx,
+			infoschema.TableDataLockWaits,
+			infoschema.TableDeadlocks,
+			infoschema.ClusterTableDeadlocks:
+		default:
+			return nil
+		}
 	}
 	prunedColumns := make([]*expression.Column, 0)
 	used := expression.GetUsedList(parentUsedCols, p.schema)
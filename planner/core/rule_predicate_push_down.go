@@ -22,9 +22,12 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/planner/util"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/logutil"
 	"github.com/pingcap/tidb/util/ranger"
@@ -134,6 +137,12 @@ func (p *LogicalUnionScan) PredicatePushDown(predicates []expression.Expression,
 func (ds *DataSource) PredicatePushDown(predicates []expression.Expression, opt *logicalOptimizeOp) ([]expression.Expression, LogicalPlan) {
 	predicates = expression.PropagateConstant(ds.ctx, predicates)
 	predicates = DeleteTrueExprs(ds, predicates)
+	if ds.contradictsCheckConstraints(predicates) {
+		// No row of the table satisfies the predicates, the parent selection turns into a table dual.
+		// The plan depends on tidb_enable_check_constraint, which isn't in the key of the plan cache.
+		ds.ctx.GetSessionVars().StmtCtx.SkipPlanCache = true
+		return []expression.Expression{expression.NewZero()}, ds
+	}
 	// Add tidb_shard() prefix to the condtion for shard index in some scenarios
 	// TODO: remove it to the place building logical plan
 	predicates = ds.AddPrefix4ShardIndexes(ds.ctx, predicates)
@@ -143,6 +152,108 @@ func (ds *DataSource) PredicatePushDown(predicates []expression.Expression, opt
 	return predicates, ds
 }
 
+// contradictsCheckConstraints reports whether the predicates contradict the enforced CHECK
+// constraints of the table. The constants equal to the columns are substituted into the
+// constraints, and the ranges of the NOT NULL columns are intersected with the constraints on them.
+// Only the constraints whose existing rows are validated after this instance sees the constraints
+// enabled are used, since the rows written while they're disabled may violate them.
+func (ds *DataSource) contradictsCheckConstraints(predicates []expression.Expression) bool {
+	if len(predicates) == 0 || len(ds.tableInfo.Constraints) == 0 || !variable.EnableCheckConstraint.Load() {
+		return false
+	}
+	if expression.MaybeOverOptimized4PlanCache(ds.ctx, predicates) {
+		return false
+	}
+	constrTbl, ok := ds.table.(table.CheckConstraintTable)
+	if !ok {
+		return false
+	}
+	// The output names aren't pruned with the columns, so they're rebuilt from the columns.
+	names := make(types.NameSlice, 0, len(ds.Columns))
+	for _, col := range ds.Columns {
+		names = append(names, &types.FieldName{ColName: col.Name})
+	}
+	enabledTS := variable.CheckConstraintEnabledTS.Load()
+	constrExprs := make([]expression.Expression, 0, len(ds.tableInfo.Constraints))
+	for _, constr := range ds.tableInfo.Constraints {
+		if !constr.Enforced || constr.State != model.StatePublic || constr.ValidatedTS <= enabledTS {
+			continue
+		}
+		astExpr := constrTbl.CheckConstraintExpr(constr.ID)
+		if astExpr == nil {
+			continue
+		}
+		// The constraints on the pruned columns can't be built, they're skipped.
+		expr, err := expression.RewriteSimpleExprWithNames(ds.ctx, astExpr, ds.schema, names)
+		if err != nil {
+			continue
+		}
+		constrExprs = append(constrExprs, expr)
+	}
+	if len(constrExprs) == 0 {
+		return false
+	}
+
+	conds := make([]expression.Expression, 0, len(predicates)+len(constrExprs))
+	conds = append(conds, predicates...)
+	conds = append(conds, constrExprs...)
+	sc := ds.ctx.GetSessionVars().StmtCtx
+	// A predicate evaluated to NULL filters all rows, while a constraint evaluated to NULL is
+	// satisfied, so the constant conditions built from both contradict only if they're false.
+	for _, pred := range predicates {
+		if con, ok := pred.(*expression.Constant); ok {
+			if isTrue, err := con.Value.ToBool(sc); con.Value.IsNull() || (err == nil && isTrue == 0) {
+				return true
+			}
+		}
+	}
+	for _, cond := range expression.PropagateConstant(ds.ctx, conds) {
+		if con, ok := cond.(*expression.Constant); ok && !con.Value.IsNull() {
+			if isTrue, err := con.Value.ToBool(sc); err == nil && isTrue == 0 {
+				return true
+			}
+		}
+	}
+
+	// A NULL column makes the constraint on it NULL rather than false, so only the NOT NULL
+	// columns are checked by their ranges.
+	for _, constrExpr := range constrExprs {
+		cols := expression.ExtractColumns(constrExpr)
+		if len(cols) == 0 || !mysql.HasNotNullFlag(cols[0].RetType.GetFlag()) {
+			continue
+		}
+		col := cols[0]
+		colConds := []expression.Expression{constrExpr}
+		for _, c := range cols[1:] {
+			if !c.Equal(ds.ctx, col) {
+				colConds = nil
+				break
+			}
+		}
+		if colConds == nil {
+			continue
+		}
+		for _, pred := range predicates {
+			predCols := expression.ExtractColumns(pred)
+			onCol := len(predCols) > 0
+			for _, c := range predCols {
+				onCol = onCol && c.Equal(ds.ctx, col)
+			}
+			if onCol {
+				colConds = append(colConds, pred)
+			}
+		}
+		if len(colConds) == 1 {
+			continue
+		}
+		ranges, err := ranger.BuildColumnRange(colConds, ds.ctx, col.RetType, types.UnspecifiedLength)
+		if err == nil && len(ranges) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // PredicatePushDown implements LogicalPlan PredicatePushDown interface.
 func (p *LogicalTableDual) PredicatePushDown(predicates []expression.Expression, _ *logicalOptimizeOp) ([]expression.Expression, LogicalPlan) {
 	return predicates, p
@@ -250,6 +250,11 @@ func (ds *DataSource) deriveStatsByFilter(conds expression.CNFExprs, filledPaths
 		logutil.BgLogger().Debug("something wrong happened, use the default selectivity", zap.Error(err))
 		selectivity = SelectionFactor
 	}
+	if ds.ctx.GetSessionVars().EnableCardinalityFeedback && len(conds) > 0 {
+		// Correct the systematic misestimates learned from the executed plans, see executor.collectCardinalityFeedback.
+		factor := statistics.CardinalityCorrectionFactor(ds.tableInfo.ID, statistics.FilterColumnIDs(conds), ds.tableStats.StatsVersion)
+		selectivity = math.Min(selectivity*factor, 1)
+	}
 	stats := ds.tableStats.Scale(selectivity)
 	if ds.ctx.GetSessionVars().OptimizerSelectivityLevel >= 1 {
 		stats.HistColl = stats.HistColl.NewHistCollBySelectivity(ds.ctx, nodes)
@@ -257,6 +262,47 @@ func (ds *DataSource) deriveStatsByFilter(conds expression.CNFExprs, filledPaths
 	return stats
 }
 
+// FilteredTableOfReader returns the table read by the reader p and the filters on it, which identify the cardinality
+// feedback of p. If p is the selection of the filters which can't be pushed down above a reader, they're included so the
+// filters are all the ones on the DataSource. The readers doing more than scanning and filtering the table are ignored.
+func FilteredTableOfReader(p PhysicalPlan) (tbl *model.TableInfo, conds []expression.Expression) {
+	var rootConds []expression.Expression
+	if sel, ok := p.(*PhysicalSelection); ok && sel.fromDataSource {
+		rootConds = sel.Conditions
+		p = sel.children[0]
+	}
+	if proj, ok := p.(*PhysicalProjection); ok {
+		// The projection may remove the expanded virtual columns, see copTask.convertToRootTaskImpl.
+		p = proj.children[0]
+	}
+	var copPlans []PhysicalPlan
+	switch x := p.(type) {
+	case *PhysicalTableReader:
+		copPlans = x.TablePlans
+	case *PhysicalIndexReader:
+		copPlans = x.IndexPlans
+	case *PhysicalIndexLookUpReader:
+		copPlans = append(append(copPlans, x.IndexPlans...), x.TablePlans...)
+	default:
+		return nil, nil
+	}
+	for _, p := range copPlans {
+		switch x := p.(type) {
+		case *PhysicalTableScan:
+			tbl = x.Table
+			conds = append(conds, x.AccessCondition...)
+		case *PhysicalIndexScan:
+			tbl = x.Table
+			conds = append(conds, x.AccessCondition...)
+		case *PhysicalSelection:
+			conds = append(conds, x.Conditions...)
+		default:
+			return nil, nil
+		}
+	}
+	return tbl, append(conds, rootConds...)
+}
+
 // We bind logic of derivePathStats and tryHeuristics together. When some path matches the heuristic rule, we don't need
 // to derive stats of subsequent paths. In this way we can save unnecessary computation of derivePathStats.
 func (ds *DataSource) derivePathStatsAndTryHeuristics() error {
@@ -811,6 +811,14 @@ func (t *copTask) handleRootTaskConds(ctx sessionctx.Context, newTask *rootTask)
 			logutil.BgLogger().Debug("calculate selectivity failed, use selection factor", zap.Error(err))
 			selectivity = SelectionFactor
 		}
+		if ctx.GetSessionVars().EnableCardinalityFeedback {
+			// The feedback of the selection is of all the filters on the DataSource, see FilteredTableOfReader.
+			if tbl, conds := FilteredTableOfReader(newTask.p); tbl != nil {
+				conds = append(conds, t.rootTaskConds...)
+				factor := statistics.CardinalityCorrectionFactor(tbl.ID, statistics.FilterColumnIDs(conds), newTask.p.statsInfo().StatsVersion)
+				selectivity = math.Min(selectivity*factor, 1)
+			}
+		}
 		sel := PhysicalSelection{Conditions: t.rootTaskConds}.Init(ctx, newTask.p.statsInfo().Scale(selectivity), newTask.p.SelectBlockOffset())
 		sel.fromDataSource = true
 		sel.SetChildren(newTask.p)
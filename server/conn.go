@@ -86,6 +86,7 @@ import (
 	"github.com/pingcap/tidb/util/execdetails"
 	"github.com/pingcap/tidb/util/hack"
 	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/mathutil"
 	"github.com/pingcap/tidb/util/memory"
 	tlsutil "github.com/pingcap/tidb/util/tls"
 	topsqlstate "github.com/pingcap/tidb/util/topsql/state"
@@ -204,6 +205,8 @@ type clientConn struct {
 	lastActive    time.Time         // last active time
 	authPlugin    string            // default authentication plugin
 	isUnixSocket  bool              // connection is Unix Socket file
+	isAdminPort   bool              // connection is from the admin port
+	connCountErr  error             // error of the connection limits, which is ignored once the admin port user is authenticated
 	rsEncoder     *resultEncoder    // rsEncoder is used to encode the string result to different charsets.
 	inputDecoder  *inputDecoder     // inputDecoder is used to decode the different charsets of incoming strings to utf-8.
 	socketCredUID uint32            // UID from the other end of the Unix Socket
@@ -772,10 +775,13 @@ func (cc *clientConn) openSession() error {
 	cc.setCtx(ctx)
 
 	err = cc.server.checkConnectionCount()
-	if err != nil {
-		return err
+	if err != nil && cc.isAdminPort {
+		// the connections from the admin port are exempt from the connection limits only after
+		// the user is allowed and authenticated, see openSessionAndDoAuth.
+		cc.connCountErr = err
+		return nil
 	}
-	return nil
+	return err
 }
 
 func (cc *clientConn) openSessionAndDoAuth(authData []byte, authPlugin string) error {
@@ -799,6 +805,13 @@ func (cc *clientConn) openSessionAndDoAuth(authData []byte, authPlugin string) e
 	if !cc.isUnixSocket && authPlugin == mysql.AuthSocket {
 		return errAccessDeniedNoPassword.FastGenByArgs(cc.user, host)
 	}
+	if cc.isAdminPort && !cc.server.checkAdminPortUser(cc.user, host) {
+		// the users not allowed to use the admin port are limited as the other connections.
+		if cc.connCountErr != nil {
+			return cc.connCountErr
+		}
+		return errAccessDenied.FastGenByArgs(cc.user, host, hasPassword)
+	}
 
 	userIdentity := &auth.UserIdentity{Username: cc.user, Hostname: host}
 	if authPlugin == mysql.AuthTiDBSessionToken {
@@ -812,6 +825,7 @@ func (cc *clientConn) openSessionAndDoAuth(authData []byte, authPlugin string) e
 	} else if err = cc.ctx.Auth(userIdentity, authData, cc.salt); err != nil {
 		return err
 	}
+	cc.connCountErr = nil
 	cc.ctx.SetPort(port)
 	if cc.dbname != "" {
 		err = cc.useDB(context.Background(), cc.dbname)
@@ -1279,14 +1293,20 @@ func (cc *clientConn) dispatch(ctx context.Context, data []byte) error {
 			pprof.SetGoroutineLabels(ctx)
 		}
 	}
-	token := cc.server.getToken()
+	// the connections from the admin port are exempt from the token limit.
+	var token *Token
+	if !cc.isAdminPort {
+		token = cc.server.getToken()
+	}
 	defer func() {
 		// if handleChangeUser failed, cc.ctx may be nil
 		if ctx := cc.getCtx(); ctx != nil {
 			ctx.SetProcessInfo("", t, mysql.ComSleep, 0)
 		}
 
-		cc.server.releaseToken(token)
+		if token != nil {
+			cc.server.releaseToken(token)
+		}
 		span.Finish()
 		cc.lastActive = time.Now()
 	}()
@@ -2188,7 +2208,10 @@ func (cc *clientConn) writeChunks(ctx context.Context, rs ResultSet, binary bool
 		//nolint:forcetypeassert
 		stmtDetail = stmtDetailRaw.(*execdetails.StmtExecDetails)
 	}
+	pacer := newResultPacer(cc.ctx.GetSessionVars())
+	defer pacer.close()
 	for {
+		pacer.pace(req)
 		failpoint.Inject("fetchNextErr", func(value failpoint.Value) {
 			//nolint:forcetypeassert
 			switch value.(string) {
@@ -2206,6 +2229,7 @@ func (cc *clientConn) writeChunks(ctx context.Context, rs ResultSet, binary bool
 			return firstNext, err
 		}
 		firstNext = false
+		pacer.track(req)
 		if !gotColumnInfo {
 			// We need to call Next before we get columns.
 			// Otherwise, we will get incorrect columns info.
@@ -2268,6 +2292,63 @@ func (cc *clientConn) writeChunks(ctx context.Context, rs ResultSet, binary bool
 	return false, err
 }
 
+// resultPacer paces the result set streaming by the speed the client reads it. Writing the
+// rows blocks when the client reads slowly, then the result chunk is held and the coprocessor
+// responses are buffered. The coprocessor iterators stop sending tasks once their buffered
+// responses exceed tidb_result_buffer_limit, see kv.Request.ResultBufferLimit, and the pacer
+// limits the rows fetched by each Next so the result chunk, tracked by the statement memory
+// tracker, fits in the limit too.
+type resultPacer struct {
+	limit        int64
+	maxChunkSize int
+	memTracker   *memory.Tracker
+	rows         int
+}
+
+func newResultPacer(vars *variable.SessionVars) *resultPacer {
+	p := &resultPacer{
+		limit:        vars.ResultBufferLimit,
+		maxChunkSize: vars.MaxChunkSize,
+	}
+	if p.limit > 0 && vars.StmtCtx.MemTracker != nil {
+		p.memTracker = memory.NewTracker(memory.LabelForResultChunk, -1)
+		p.memTracker.AttachTo(vars.StmtCtx.MemTracker)
+	}
+	return p
+}
+
+// initialRowSizeEstimate is the row size assumed before any chunk is fetched. It's
+// conservative so the first chunk doesn't exceed the limit by far.
+const initialRowSizeEstimate = 1024
+
+// pace sets the rows required by the next Next according to the memory of the rows fetched last time.
+func (p *resultPacer) pace(req *chunk.Chunk) {
+	if p.limit <= 0 {
+		return
+	}
+	rowSize := int64(initialRowSizeEstimate)
+	if p.memTracker != nil && p.rows > 0 {
+		rowSize = mathutil.Max(p.memTracker.BytesConsumed()/int64(p.rows), 1)
+	}
+	required := mathutil.Clamp(p.limit/rowSize, 1, int64(p.maxChunkSize))
+	req.SetRequiredRows(int(required), p.maxChunkSize)
+}
+
+// track records the memory of the result chunk fetched by Next.
+func (p *resultPacer) track(req *chunk.Chunk) {
+	if p.memTracker == nil {
+		return
+	}
+	p.rows = req.NumRows()
+	p.memTracker.ReplaceBytesUsed(req.MemoryUsage())
+}
+
+func (p *resultPacer) close() {
+	if p.memTracker != nil {
+		p.memTracker.Detach()
+	}
+}
+
 // writeChunksWithFetchSize writes data from a Chunk, which filled data by a ResultSet, into a connection.
 // binary specifies the way to dump data. It throws any error while dumping data.
 // serverStatus, a flag bit represents server information.
@@ -31,6 +31,7 @@ import (
 
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/parser/auth"
@@ -41,9 +42,11 @@ import (
 	"github.com/pingcap/tidb/store/mockstore/unistore"
 	"github.com/pingcap/tidb/testkit"
 	"github.com/pingcap/tidb/testkit/external"
+	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/arena"
 	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/memory"
 	"github.com/stretchr/testify/require"
 	tikverr "github.com/tikv/client-go/v2/error"
 	"github.com/tikv/client-go/v2/testutils"
@@ -729,6 +732,139 @@ func TestConnExecutionTimeout(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestConnStatementTimeout(t *testing.T) {
+	store, dom := testkit.CreateMockStoreAndDomain(t)
+
+	// There is no underlying netCon, use failpoint to avoid panic
+	require.NoError(t, failpoint.Enable("github.com/pingcap/tidb/server/FakeClientConn", "return(1)"))
+	defer func() {
+		require.NoError(t, failpoint.Disable("github.com/pingcap/tidb/server/FakeClientConn"))
+	}()
+	tk := testkit.NewTestKit(t, store)
+
+	connID := uint64(1)
+	tk.Session().SetConnectionID(connID)
+	tc := &TiDBContext{
+		Session: tk.Session(),
+		stmts:   make(map[int]*TiDBStatement),
+	}
+	cc := &clientConn{
+		connectionID: connID,
+		server: &Server{
+			capability: defaultCapability,
+		},
+		alloc:      arena.NewAllocator(32 * 1024),
+		chunkAlloc: chunk.NewAllocator(),
+	}
+	cc.setCtx(tc)
+	srv := &Server{
+		clients: map[uint64]*clientConn{
+			connID: cc,
+		},
+		dom: dom,
+	}
+	handle := dom.ExpensiveQueryHandle().SetSessionManager(srv)
+	go handle.Run()
+
+	tk.MustExec("use test")
+	tk.MustExec("create table t (id int primary key, v int)")
+	tk.MustExec("insert into t values (1, 1)")
+
+	tk2 := testkit.NewTestKit(t, store)
+	tk2.MustExec("use test")
+	tk2.MustExec("begin pessimistic")
+	tk2.MustExec("update t set v = 2 where id = 1")
+
+	// max_execution_time doesn't cover the statements waiting for the locks, but
+	// tidb_statement_timeout does.
+	tk.MustExec("set @@innodb_lock_wait_timeout = 10")
+	tk.MustExec("set @@tidb_statement_timeout = 500")
+	start := time.Now()
+	err := cc.handleQuery(context.Background(), "update t set v = 3 where id = 1")
+	require.Error(t, err)
+	require.True(t, executor.ErrStmtTimeoutExceeded.Equal(err), "%v", err)
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	tk2.MustExec("rollback")
+	// the killed flag is reset by dispatch() before handling the next command.
+	atomic.StoreUint32(&tk.Session().GetSessionVars().Killed, 0)
+	tk.MustExec("set @@tidb_statement_timeout = 0")
+	require.NoError(t, cc.handleQuery(context.Background(), "update t set v = 3 where id = 1"))
+	tk.MustQuery("select v from t where id = 1").Check(testkit.Rows("3"))
+}
+
+func TestResultBufferLimit(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+
+	var out bytes.Buffer
+	tk := testkit.NewTestKit(t, store)
+	cc := &clientConn{
+		connectionID: 1,
+		server: &Server{
+			capability: defaultCapability,
+		},
+		pkt: &packetIO{
+			bufWriter: bufio.NewWriter(&out),
+		},
+		alloc:      arena.NewAllocator(32 * 1024),
+		chunkAlloc: chunk.NewAllocator(),
+		capability: mysql.ClientProtocol41 | mysql.ClientDeprecateEOF,
+	}
+	cc.setCtx(&TiDBContext{Session: tk.Session(), stmts: make(map[int]*TiDBStatement)})
+
+	tk.MustExec("use test")
+	tk.MustExec("create table t (id int primary key, v varchar(64))")
+	for i := 0; i < 100; i++ {
+		tk.MustExec(fmt.Sprintf("insert into t values (%d, '%s')", i, strings.Repeat("x", 32)))
+	}
+
+	cc.pkt.sequence = 0
+	require.NoError(t, cc.handleQuery(context.Background(), "select * from t"))
+	require.NoError(t, cc.flush(context.Background()))
+	expected := append([]byte(nil), out.Bytes()...)
+
+	// the result set is the same when it's fetched by small chunks.
+	out.Reset()
+	tk.MustExec("set @@tidb_result_buffer_limit = 512")
+	cc.pkt.sequence = 0
+	require.NoError(t, cc.handleQuery(context.Background(), "select * from t"))
+	require.NoError(t, cc.flush(context.Background()))
+	require.Equal(t, expected, out.Bytes())
+}
+
+func TestResultPacer(t *testing.T) {
+	vars := variable.NewSessionVars()
+	vars.ResultBufferLimit = 512
+	vars.StmtCtx.MemTracker = memory.NewTracker(memory.LabelForSQLText, -1)
+
+	pacer := newResultPacer(vars)
+	req := chunk.NewChunkWithCapacity([]*types.FieldType{types.NewFieldType(mysql.TypeLonglong)}, 32)
+	// the first chunk is paced by the initial row size estimate.
+	pacer.pace(req)
+	require.Equal(t, 1, req.RequiredRows())
+
+	for i := 0; i < 32; i++ {
+		req.AppendInt64(0, int64(i))
+	}
+	pacer.track(req)
+	require.Equal(t, req.MemoryUsage(), vars.StmtCtx.MemTracker.BytesConsumed())
+	pacer.pace(req)
+	require.Equal(t, int(512/(req.MemoryUsage()/32)), req.RequiredRows())
+
+	pacer.close()
+	require.Zero(t, vars.StmtCtx.MemTracker.BytesConsumed())
+
+	// the rows aren't limited without the limit.
+	vars.ResultBufferLimit = 0
+	pacer = newResultPacer(vars)
+	req.SetRequiredRows(1, vars.MaxChunkSize)
+	pacer.pace(req)
+	require.Equal(t, 1, req.RequiredRows())
+	pacer.track(req)
+	require.Zero(t, vars.StmtCtx.MemTracker.BytesConsumed())
+	pacer.close()
+}
+
 func TestShutDown(t *testing.T) {
 	store := testkit.CreateMockStore(t)
 
@@ -1422,3 +1558,24 @@ func TestOkEof(t *testing.T) {
 	require.Equal(t, mysql.EOFHeader, outBuffer.Bytes()[4])
 	require.Equal(t, []byte{0x7, 0x0, 0x0, 0x1, 0xfe, 0x0, 0x0, 0x2, 0x0, 0x0, 0x0}, outBuffer.Bytes())
 }
+
+func TestCheckAdminPortUser(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.AdminPort.Users = []string{"root@127.0.0.1", "admin@10.0.%", "ops"}
+	srv := &Server{cfg: cfg}
+	tests := []struct {
+		user    string
+		host    string
+		allowed bool
+	}{
+		{"root", "127.0.0.1", true},
+		{"root", "10.0.0.1", false},
+		{"admin", "10.0.1.2", true},
+		{"admin", "10.1.0.1", false},
+		{"ops", "192.168.1.1", true},
+		{"guest", "127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.allowed, srv.checkAdminPortUser(tt.user, tt.host), "%s@%s", tt.user, tt.host)
+	}
+}
@@ -41,6 +41,7 @@ import (
 	_ "net/http/pprof" // #nosec G108
 	"os"
 	"os/user"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -63,6 +64,7 @@ import (
 	"github.com/pingcap/tidb/util/dbterror"
 	"github.com/pingcap/tidb/util/fastrand"
 	"github.com/pingcap/tidb/util/logutil"
+	"github.com/pingcap/tidb/util/stringutil"
 	"github.com/pingcap/tidb/util/sys/linux"
 	"github.com/pingcap/tidb/util/timeutil"
 	"go.uber.org/zap"
@@ -121,6 +123,7 @@ type Server struct {
 	driver            IDriver
 	listener          net.Listener
 	socket            net.Listener
+	adminListener     net.Listener // the listener of the admin port, nil if it's disabled
 	rwlock            sync.RWMutex
 	concurrentLimiter *TokenLimiter
 	clients           map[uint64]*clientConn
@@ -240,12 +243,12 @@ func NewServer(cfg *config.Config, driver IDriver) (*Server, error) {
 		s.capability |= mysql.ClientSSL
 	}
 
+	tcpProto := "tcp"
+	if s.cfg.EnableTCP4Only {
+		tcpProto = "tcp4"
+	}
 	if s.cfg.Host != "" && (s.cfg.Port != 0 || RunInGoTest) {
 		addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
-		tcpProto := "tcp"
-		if s.cfg.EnableTCP4Only {
-			tcpProto = "tcp4"
-		}
 		if s.listener, err = net.Listen(tcpProto, addr); err != nil {
 			return nil, errors.Trace(err)
 		}
@@ -255,6 +258,18 @@ func NewServer(cfg *config.Config, driver IDriver) (*Server, error) {
 		}
 	}
 
+	if s.cfg.AdminPort.Port != 0 {
+		host := s.cfg.AdminPort.Host
+		if host == "" {
+			host = s.cfg.Host
+		}
+		addr := fmt.Sprintf("%s:%d", host, s.cfg.AdminPort.Port)
+		if s.adminListener, err = net.Listen(tcpProto, addr); err != nil {
+			return nil, errors.Trace(err)
+		}
+		logutil.BgLogger().Info("server is running MySQL protocol for administrators", zap.String("addr", addr))
+	}
+
 	if s.cfg.Socket != "" {
 		if err := cleanupStaleSocket(s.cfg.Socket); err != nil {
 			return nil, errors.Trace(err)
@@ -366,16 +381,18 @@ func (s *Server) Run() error {
 	// If error should be reported and exit the server it can be sent on this
 	// channel. Otherwise, end with sending a nil error to signal "done"
 	errChan := make(chan error)
-	go s.startNetworkListener(s.listener, false, errChan)
-	go s.startNetworkListener(s.socket, true, errChan)
-	err := <-errChan
-	if err != nil {
-		return err
+	go s.startNetworkListener(s.listener, false, false, errChan)
+	go s.startNetworkListener(s.socket, true, false, errChan)
+	go s.startNetworkListener(s.adminListener, false, true, errChan)
+	for i := 0; i < 3; i++ {
+		if err := <-errChan; err != nil {
+			return err
+		}
 	}
-	return <-errChan
+	return nil
 }
 
-func (s *Server) startNetworkListener(listener net.Listener, isUnixSocket bool, errChan chan error) {
+func (s *Server) startNetworkListener(listener net.Listener, isUnixSocket, isAdminPort bool, errChan chan error) {
 	if listener == nil {
 		errChan <- nil
 		return
@@ -406,6 +423,7 @@ func (s *Server) startNetworkListener(listener net.Listener, isUnixSocket bool,
 		}
 
 		clientConn := s.newConn(conn)
+		clientConn.isAdminPort = isAdminPort
 		if isUnixSocket {
 			uc, ok := conn.(*net.UnixConn)
 			if !ok {
@@ -485,6 +503,11 @@ func (s *Server) Close() {
 		terror.Log(errors.Trace(err))
 		s.socket = nil
 	}
+	if s.adminListener != nil {
+		err := s.adminListener.Close()
+		terror.Log(errors.Trace(err))
+		s.adminListener = nil
+	}
 	if s.statusServer != nil {
 		err := s.statusServer.Close()
 		terror.Log(errors.Trace(err))
@@ -592,7 +615,7 @@ func (cc *clientConn) connectInfo() *variable.ConnectionInfo {
 		ClientIP:          cc.peerHost,
 		ClientPort:        cc.peerPort,
 		ServerID:          1,
-		ServerPort:        int(cc.server.cfg.Port),
+		ServerPort:        cc.serverPort(),
 		User:              cc.user,
 		ServerOSLoginUser: osUser,
 		OSVersion:         osVersion,
@@ -604,6 +627,31 @@ func (cc *clientConn) connectInfo() *variable.ConnectionInfo {
 	return connInfo
 }
 
+// checkAdminPortUser returns whether the user is allowed to connect from the host through the admin port.
+func (s *Server) checkAdminPortUser(user, host string) bool {
+	for _, allowed := range s.cfg.AdminPort.Users {
+		name, hostPattern, hasHost := strings.Cut(allowed, "@")
+		if name != user {
+			continue
+		}
+		if !hasHost {
+			return true
+		}
+		patChars, patTypes := stringutil.CompilePatternBytes(strings.ToLower(hostPattern), '\\')
+		if stringutil.DoMatchBytes(strings.ToLower(host), patChars, patTypes) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cc *clientConn) serverPort() int {
+	if cc.isAdminPort {
+		return int(cc.server.cfg.AdminPort.Port)
+	}
+	return int(cc.server.cfg.Port)
+}
+
 func (s *Server) checkConnectionCount() error {
 	// When the value of Instance.MaxConnections is 0, the number of connections is unlimited.
 	if int(s.cfg.Instance.MaxConnections) == 0 {
@@ -612,6 +660,14 @@ func (s *Server) checkConnectionCount() error {
 
 	s.rwlock.RLock()
 	conns := len(s.clients)
+	if s.cfg.AdminPort.Port != 0 {
+		// the connections from the admin port don't take up the quota.
+		for _, cc := range s.clients {
+			if cc.isAdminPort {
+				conns--
+			}
+		}
+	}
 	s.rwlock.RUnlock()
 
 	if conns >= int(s.cfg.Instance.MaxConnections) {
@@ -26,6 +26,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"math/big"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -53,6 +54,7 @@ import (
 	"github.com/pingcap/tidb/util/cpuprofile"
 	"github.com/pingcap/tidb/util/plancodec"
 	"github.com/pingcap/tidb/util/resourcegrouptag"
+	"github.com/pingcap/tidb/util/sem"
 	"github.com/pingcap/tidb/util/topsql"
 	"github.com/pingcap/tidb/util/topsql/collector"
 	mockTopSQLTraceCPU "github.com/pingcap/tidb/util/topsql/collector/mock"
@@ -597,6 +599,78 @@ func TestSocketAndIp(t *testing.T) {
 }
 
 // TestOnlySocket for server configuration without network interface for mysql clients
+func TestAdminPort(t *testing.T) {
+	// pick a free port for the admin port
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	adminPort := getPortFromTCPAddr(l.Addr())
+	require.NoError(t, l.Close())
+
+	cli := newTestServerClient()
+	cfg := newTestConfig()
+	cfg.Port = cli.port
+	cfg.Status.ReportStatus = false
+	cfg.Instance.MaxConnections = 1
+	cfg.AdminPort.Port = adminPort
+	cfg.AdminPort.Users = []string{"root@127.0.0.1"}
+
+	ts := createTidbTestSuite(t)
+
+	server, err := NewServer(cfg, ts.tidbdrv)
+	require.NoError(t, err)
+	cli.port = getPortFromTCPAddr(server.listener.Addr())
+	go func() {
+		err := server.Run()
+		require.NoError(t, err)
+	}()
+	cli.waitUntilServerCanConnect()
+	defer server.Close()
+
+	adminAddr := func(config *mysql.Config) {
+		config.Addr = fmt.Sprintf("127.0.0.1:%d", adminPort)
+	}
+	cli.runTests(t, adminAddr, func(dbt *testkit.DBTestKit) {
+		dbt.MustExec("CREATE USER 'guest'@'%'")
+	})
+
+	// occupy the only connection of the normal port, the connection of waitUntilServerCanConnect
+	// may be not closed yet.
+	db, err := sql.Open("mysql", cli.getDSN())
+	require.NoError(t, err)
+	var conn *sql.Conn
+	require.Eventually(t, func() bool {
+		conn, err = db.Conn(context.Background())
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+	db2, err := sql.Open("mysql", cli.getDSN())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db2.Close())
+	}()
+	require.ErrorContains(t, db2.Ping(), "Too many connections")
+
+	// the admin port is exempt from max_connections
+	cli.runTests(t, adminAddr, func(dbt *testkit.DBTestKit) {
+		dbt.MustQuery("SELECT 1")
+	})
+
+	// the users not in admin-port.users aren't exempt from max_connections, and are denied
+	db3, err := sql.Open("mysql", cli.getDSN(adminAddr, func(config *mysql.Config) {
+		config.User = "guest"
+	}))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db3.Close())
+	}()
+	require.ErrorContains(t, db3.Ping(), "Too many connections")
+	require.NoError(t, conn.Close())
+	require.NoError(t, db.Close())
+	require.Eventually(t, func() bool {
+		err = db3.Ping()
+		return err != nil && strings.Contains(err.Error(), "Access denied for user 'guest'@'127.0.0.1'")
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
 func TestOnlySocket(t *testing.T) {
 	tempDir := t.TempDir()
 	socketFile := tempDir + "/tidbtest.sock" // Unix Socket does not work on Windows, so '/' should be OK
@@ -1103,6 +1177,87 @@ func TestInitConnect(t *testing.T) {
 	ts.runTestInitConnect(t)
 }
 
+func TestFederatedTable(t *testing.T) {
+	ts := createTidbTestSuite(t)
+	// The TIMESTAMP values are converted from and to the session time zone.
+	ts.runTests(t, func(config *mysql.Config) {
+		config.Params = map[string]string{"time_zone": "'+08:00'"}
+	}, func(dbt *testkit.DBTestKit) {
+		dbt.MustExec("create database remote")
+		dbt.MustExec("create table remote.src (id int primary key, v decimal(10, 2), name varchar(20), d datetime, ts timestamp null)")
+		dbt.MustExec("insert into remote.src values (1, 1.50, 'a', '2022-01-01 00:00:00', '2022-01-01 08:00:00'), (2, null, 'b', '2022-02-01 00:00:00', null), (3, 3.25, null, null, null)")
+
+		sem.Enable()
+		_, err := dbt.GetDB().Exec(fmt.Sprintf("create server srv foreign data wrapper mysql options (host '127.0.0.1', port %d, user 'root', database 'remote')", ts.port))
+		require.EqualError(t, err, "Error 8132: Feature 'CREATE SERVER' is not supported when security enhanced mode is enabled")
+		sem.Disable()
+		dbt.MustExec("set global tidb_federated_allowed_hosts = 'localhost, 10.0.0.0/8'")
+		_, err = dbt.GetDB().Exec(fmt.Sprintf("create server srv foreign data wrapper mysql options (host '127.0.0.1', port %d, user 'root', database 'remote')", ts.port))
+		require.EqualError(t, err, "Error 1429: Unable to connect to foreign data source: host '127.0.0.1' is not allowed")
+		_, err = dbt.GetDB().Exec("create server srv foreign data wrapper mysql options (socket '/tmp/tidb.sock', user 'root')")
+		require.EqualError(t, err, "Error 1429: Unable to connect to foreign data source: socket is not allowed")
+		dbt.MustExec("set global tidb_federated_allowed_hosts = '127.0.0.0/8'")
+		dbt.MustExec(fmt.Sprintf("create server srv foreign data wrapper mysql options (host '127.0.0.1', port %d, user 'root', database 'remote')", ts.port))
+
+		_, err = dbt.GetDB().Exec("create server srv foreign data wrapper mysql options (host '127.0.0.1')")
+		require.EqualError(t, err, "Error 1476: The foreign server, srv, you are trying to create already exists.")
+		_, err = dbt.GetDB().Exec("create table fed_bad (id int) engine=federated connection='srv'")
+		require.EqualError(t, err, "Error 1432: Can't create federated table. The data source connection string 'srv' is not in the correct format")
+
+		dbt.MustExec("create table fed (id int primary key, v decimal(10, 2), name varchar(20), d datetime, ts timestamp null) engine=federated connection='srv/src'")
+		rows := dbt.MustQuery("show create table fed")
+		require.True(t, rows.Next())
+		var name, createTable string
+		require.NoError(t, rows.Scan(&name, &createTable))
+		require.Contains(t, createTable, "ENGINE=FEDERATED DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin CONNECTION='srv/src'")
+		require.NoError(t, rows.Close())
+
+		ts.checkRows(t, dbt.MustQuery("select * from fed order by id"),
+			"1 1.50 a 2022-01-01 00:00:00 2022-01-01 08:00:00", "2 <nil> b 2022-02-01 00:00:00 <nil>", "3 3.25 <nil> <nil> <nil>")
+		ts.checkRows(t, dbt.MustQuery("select id from fed where ts = '2022-01-01 08:00:00'"), "1")
+		ts.checkRows(t, dbt.MustQuery("select id from fed where id = 2"), "2")
+		ts.checkRows(t, dbt.MustQuery("select name from fed where id > 1 and v is null"), "b")
+		ts.checkRows(t, dbt.MustQuery("select id from fed where d >= '2022-01-15' or id in (3)"), "2", "3")
+		ts.checkRows(t, dbt.MustQuery("select id from fed where name = 'a'"), "1")
+		ts.checkRows(t, dbt.MustQuery("select count(*) from fed join remote.src using (id) where fed.v > 2"), "1")
+
+		rows = dbt.MustQuery("explain format = 'brief' select id from fed where id > 1 and name = 'b' or id in (1, 2)")
+		result := strings.Join(ts.Rows(t, rows), "\n")
+		require.Contains(t, result, "server:srv, table:src, pushed down filter:(`id` > 1 OR `id` IN (1, 2))")
+		rows = dbt.MustQuery("explain format = 'brief' select id from fed where ts >= '2022-01-01 08:00:00'")
+		result = strings.Join(ts.Rows(t, rows), "\n")
+		require.Contains(t, result, "server:srv, table:src, pushed down filter:`ts` >= '2022-01-01 00:00:00.000000'")
+
+		// Only the used columns are read from the remote table.
+		dbt.MustExec("alter table remote.src drop column name")
+		ts.checkRows(t, dbt.MustQuery("select id from fed where v > 1 order by id"), "1", "3")
+		_, err = dbt.GetDB().Query("select name from fed")
+		require.Error(t, err)
+
+		// The allowed hosts are checked again when connecting.
+		dbt.MustExec("set global tidb_federated_allowed_hosts = 'localhost'")
+		_, err = dbt.GetDB().Query("select id from fed")
+		require.EqualError(t, err, "Error 1429: Unable to connect to foreign data source: host '127.0.0.1' is not allowed")
+		dbt.MustExec("set global tidb_federated_allowed_hosts = ''")
+
+		_, err = dbt.GetDB().Exec("insert into fed values (4, 4, 'd', null)")
+		require.EqualError(t, err, "Error 1105: insert into federated table fed is not supported now")
+		_, err = dbt.GetDB().Exec("update fed set v = 1")
+		require.EqualError(t, err, "Error 1288: The target table fed of the UPDATE is not updatable")
+		_, err = dbt.GetDB().Exec("delete from fed where id = 1")
+		require.EqualError(t, err, "Error 1105: delete federated table fed is not supported now")
+
+		dbt.MustExec("drop server srv")
+		_, err = dbt.GetDB().Query("select * from fed")
+		require.EqualError(t, err, "Error 1477: The foreign server name you are trying to reference does not exist. Data source :  srv")
+		dbt.MustExec("drop server if exists srv")
+		_, err = dbt.GetDB().Exec("drop server srv")
+		require.EqualError(t, err, "Error 1477: The foreign server name you are trying to reference does not exist. Data source :  srv")
+		_, err = dbt.GetDB().Exec("create server srv2 foreign data wrapper postgresql options (host '127.0.0.1')")
+		require.EqualError(t, err, "Error 1235: This version of TiDB doesn't yet support 'foreign data wrapper 'postgresql''")
+	})
+}
+
 func TestSumAvg(t *testing.T) {
 	ts := createTidbTestSuite(t)
 	ts.runTestSumAvg(t)
@@ -424,6 +424,36 @@ const (
 	CreateAdvisoryLocks = `CREATE TABLE IF NOT EXISTS mysql.advisory_locks (
 		lock_name VARCHAR(64) NOT NULL PRIMARY KEY
 	);`
+	// CreateServersTable stores the servers created by CREATE SERVER, which are used by federated tables.
+	CreateServersTable = `CREATE TABLE IF NOT EXISTS mysql.servers (
+		Server_name CHAR(64) NOT NULL DEFAULT '',
+		Host CHAR(255) NOT NULL DEFAULT '',
+		Db CHAR(64) NOT NULL DEFAULT '',
+		Username CHAR(64) NOT NULL DEFAULT '',
+		Password CHAR(64) NOT NULL DEFAULT '',
+		Port INT(4) NOT NULL DEFAULT '0',
+		Socket CHAR(64) NOT NULL DEFAULT '',
+		Wrapper CHAR(64) NOT NULL DEFAULT '',
+		Owner CHAR(64) NOT NULL DEFAULT '',
+		PRIMARY KEY (Server_name)
+	);`
+	// CreateDDLJobTimingTable stores the timings of the phases of the finished DDL jobs.
+	CreateDDLJobTimingTable = `CREATE TABLE IF NOT EXISTS mysql.tidb_ddl_job_timing (
+		job_id BIGINT(64) NOT NULL,
+		job_type VARCHAR(64) NOT NULL,
+		db_name CHAR(64) NOT NULL DEFAULT '',
+		table_name CHAR(64) NOT NULL DEFAULT '',
+		state VARCHAR(64) NOT NULL,
+		create_time DATETIME(6) NOT NULL comment 'time when the job is submitted',
+		start_time DATETIME(6) comment 'time when the job starts to run',
+		end_time DATETIME(6) NOT NULL,
+		queue_duration DOUBLE NOT NULL DEFAULT 0 comment 'seconds waited in the DDL job queue',
+		schema_sync_duration DOUBLE NOT NULL DEFAULT 0 comment 'seconds waited for all the TiDB instances to load the new schema',
+		backfill_duration DOUBLE NOT NULL DEFAULT 0 comment 'seconds spent on backfilling the data',
+		backfill_rows BIGINT(64) NOT NULL DEFAULT 0,
+		PRIMARY KEY (job_id),
+		KEY (end_time)
+	);`
 )
 
 // bootstrap initiates system DB for a store.
@@ -627,11 +657,15 @@ const (
 	version92 = 92
 	// version93 converts oom-use-tmp-storage to a sysvar
 	version93 = 93
+	// version94 adds the table mysql.servers
+	version94 = 94
+	// version95 adds the table mysql.tidb_ddl_job_timing
+	version95 = 95
 )
 
 // currentBootstrapVersion is defined as a variable, so we can modify its value for testing.
 // please make sure this is the largest version
-var currentBootstrapVersion int64 = version93
+var currentBootstrapVersion int64 = version95
 
 // DDL owner key's expired time is ManagerSessionTTL seconds, we should wait the time and give more time to have a chance to finish it.
 var internalSQLTimeout = owner.ManagerSessionTTL + 15
@@ -730,6 +764,8 @@ var (
 		upgradeToVer90,
 		upgradeToVer91,
 		upgradeToVer93,
+		upgradeToVer94,
+		upgradeToVer95,
 	}
 )
 
@@ -1918,6 +1954,20 @@ func upgradeToVer93(s Session, ver int64) {
 	importConfigOption(s, "oom-use-tmp-storage", variable.TiDBEnableTmpStorageOnOOM, valStr)
 }
 
+func upgradeToVer94(s Session, ver int64) {
+	if ver >= version94 {
+		return
+	}
+	doReentrantDDL(s, CreateServersTable)
+}
+
+func upgradeToVer95(s Session, ver int64) {
+	if ver >= version95 {
+		return
+	}
+	doReentrantDDL(s, CreateDDLJobTimingTable)
+}
+
 func writeOOMAction(s Session) {
 	comment := "oom-action is `log` by default in v3.0.x, `cancel` by default in v4.0.11+"
 	mustExecute(s, `INSERT HIGH_PRIORITY INTO %n.%n VALUES (%?, %?, %?) ON DUPLICATE KEY UPDATE VARIABLE_VALUE= %?`,
@@ -2012,6 +2062,10 @@ func doDDLWorks(s Session) {
 	mustExecute(s, CreateAnalyzeJobs)
 	// Create advisory_locks table.
 	mustExecute(s, CreateAdvisoryLocks)
+	// Create servers table.
+	mustExecute(s, CreateServersTable)
+	// Create tidb_ddl_job_timing table.
+	mustExecute(s, CreateDDLJobTimingTable)
 }
 
 // inTestSuite checks if we are bootstrapping in the context of tests.
@@ -575,9 +575,9 @@ func TestClusteredIndexSyntax(t *testing.T) {
 	tk.MustGetErrCode("create table t (a varchar(255) clustered);", errno.ErrParse)
 
 	errMsg := "[ddl:8200]CLUSTERED/NONCLUSTERED keyword is only supported for primary key"
-	tk.MustGetErrMsg("create table t (a varchar(255), unique key(a) clustered);", errMsg)
+	tk.MustGetErrMsg("create table t (a varchar(255), unique key(a) clustered);", "[ddl:8200]All parts of a CLUSTERED unique key must be NOT NULL")
 	tk.MustGetErrMsg("create table t (a varchar(255), unique key(a) nonclustered);", errMsg)
-	tk.MustGetErrMsg("create table t (a varchar(255), unique index(a) clustered);", errMsg)
+	tk.MustGetErrMsg("create table t (a varchar(255), unique index(a) clustered);", "[ddl:8200]All parts of a CLUSTERED unique key must be NOT NULL")
 	tk.MustGetErrMsg("create table t (a varchar(255), unique index(a) nonclustered);", errMsg)
 	tk.MustGetErrMsg("create table t (a varchar(255), key(a) clustered);", errMsg)
 	tk.MustGetErrMsg("create table t (a varchar(255), key(a) nonclustered);", errMsg)
@@ -587,6 +587,52 @@ func TestClusteredIndexSyntax(t *testing.T) {
 	tk.MustGetErrMsg("create table t (a varchar(255), b decimal(5, 4), primary key (a, b) clustered, key (b) nonclustered)", errMsg)
 }
 
+func TestClusteredUniqueKey(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+	tk := createTestKit(t, store)
+
+	tk.MustExec("create table t (id bigint, order_no varchar(32) not null, shop_id int not null, amount int, unique key uk_order (order_no, shop_id) clustered, key idx_id (id))")
+	tk.MustQuery("show create table t").Check(testkit.Rows("t CREATE TABLE `t` (\n" +
+		"  `id` bigint(20) DEFAULT NULL,\n" +
+		"  `order_no` varchar(32) NOT NULL,\n" +
+		"  `shop_id` int(11) NOT NULL,\n" +
+		"  `amount` int(11) DEFAULT NULL,\n" +
+		"  UNIQUE KEY `uk_order` (`order_no`,`shop_id`) /*T![clustered_index] CLUSTERED */,\n" +
+		"  KEY `idx_id` (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin"))
+	tk.MustQuery("select tidb_pk_type from information_schema.tables where table_schema = 'test' and table_name = 't'").Check(testkit.Rows("CLUSTERED"))
+	tk.MustQuery("show index from t").CheckAt([]int{1, 2, 4, 15}, testkit.Rows(
+		"0 uk_order order_no YES", "0 uk_order shop_id YES", "1 idx_id id NO"))
+
+	tk.MustExec("insert into t values (1, 'a001', 1, 10), (2, 'a001', 2, 20), (3, 'a002', 1, 30)")
+	tk.MustGetErrMsg("insert into t values (4, 'a001', 1, 40)", "[kv:1062]Duplicate entry 'a001-1' for key 'uk_order'")
+	tk.MustQuery("select id from t where order_no = 'a001' and shop_id = 2").Check(testkit.Rows("2"))
+	require.True(t, tk.HasPlan("select id from t where order_no = 'a001' and shop_id = 2", "Point_Get"))
+	tk.MustQuery("select amount from t where order_no = 'a001' order by shop_id").Check(testkit.Rows("10", "20"))
+	require.True(t, tk.HasPlan("select amount from t where order_no = 'a001' order by shop_id", "TableRangeScan"))
+	tk.MustQuery("select order_no, shop_id from t use index(idx_id) where id = 3").Check(testkit.Rows("a002 1"))
+	tk.MustExec("update t set shop_id = 3 where id = 3")
+	tk.MustExec("delete from t where order_no = 'a001' and shop_id = 1")
+	tk.MustQuery("select * from t order by id").Check(testkit.Rows("2 a001 2 20", "3 a002 3 30"))
+	tk.MustExec("admin check table t")
+
+	tk.MustGetErrMsg("alter table t drop index uk_order", "[ddl:8200]Unsupported drop the clustered unique key")
+	tk.MustGetErrMsg("alter table t modify order_no varchar(32) null", "[ddl:1171]All parts of a PRIMARY KEY must be NOT NULL; if you need NULL in a key, use UNIQUE instead")
+
+	// A single integer column is also clustered as a common handle, so that the key keeps its name.
+	tk.MustExec("create table t1 (a int not null, b int, unique key uk_a (a) clustered)")
+	tk.MustExec("insert into t1 values (1, 1), (2, 2)")
+	tk.MustQuery("select b from t1 where a = 2").Check(testkit.Rows("2"))
+	tk.MustExec("admin check table t1")
+
+	tk.MustGetErrMsg("create table t2 (a int primary key, b int not null, unique key (b) clustered)",
+		"[ddl:8200]CLUSTERED unique key is not supported on the table with a primary key")
+	tk.MustGetErrMsg("create table t2 (a int not null, b int not null, unique key (a) clustered, unique key (b) clustered)",
+		"[ddl:8200]Only one CLUSTERED unique key is allowed")
+	tk.MustGetErrMsg("create table t2 (a int not null, unique key ((a + 1)) clustered)",
+		"[ddl:8200]CLUSTERED unique key on expression is not supported")
+}
+
 func TestPrefixClusteredIndexAddIndexAndRecover(t *testing.T) {
 	store := testkit.CreateMockStore(t)
 
@@ -105,6 +105,60 @@ func TestCommitWhenSchemaChanged(t *testing.T) {
 	require.True(t, terror.ErrorEqual(err, plannercore.ErrWrongValueCountOnRow), fmt.Sprintf("err %v", err))
 }
 
+func TestCommitWithSchemaVersionLag(t *testing.T) {
+	store := createMockStoreForSchemaTest(t)
+
+	setTxnTk := testkit.NewTestKit(t, store)
+	setTxnTk.MustExec("set global tidb_txn_mode=''")
+	tk1 := testkit.NewTestKit(t, store)
+	tk2 := testkit.NewTestKit(t, store)
+
+	tk1.MustExec("use test")
+	tk2.MustExec("use test")
+	tk2.MustExec("set @@tidb_txn_mode = 'optimistic'")
+	tk1.MustExec("create table t (a int, b int)")
+	tk1.MustExec("create table tp (a int) partition by hash(a) partitions 2")
+	dom := domain.GetDomain(tk1.Session())
+
+	// commitAfterDDLs commits the insert of tk2 after running the DDLs, and the
+	// schema change history of the DDLs is lost.
+	commitAfterDDLs := func(insert string, ddls ...string) error {
+		tk2.MustExec("begin")
+		tk2.MustExec(insert)
+		for _, ddl := range ddls {
+			tk1.MustExec(ddl)
+		}
+		dom.SchemaValidator.Reset()
+		require.NoError(t, dom.Reload())
+		return tk2.ExecToErr("commit")
+	}
+
+	tk2.MustQuery("select @@tidb_max_schema_version_lag").Check(testkit.Rows("0"))
+	err := commitAfterDDLs("insert into t values (1, 1)", "create table t1 (id int)")
+	require.True(t, terror.ErrorEqual(err, domain.ErrInfoSchemaChanged), fmt.Sprintf("err %v", err))
+
+	// the unrelated schema changes are tolerated.
+	tk2.MustExec("set @@tidb_max_schema_version_lag = 10")
+	require.NoError(t, commitAfterDDLs("insert into t values (2, 2)", "create table t2 (id int)"))
+	require.NoError(t, commitAfterDDLs("insert into tp values (2)", "alter table t1 add index idx(id)"))
+	tk2.MustQuery("select * from t").Check(testkit.Rows("2 2"))
+	tk2.MustQuery("select * from tp").Check(testkit.Rows("2"))
+
+	// the related schema changes are not.
+	err = commitAfterDDLs("insert into t values (3, 3)", "alter table t add index idx(a)")
+	require.True(t, terror.ErrorEqual(err, domain.ErrInfoSchemaChanged), fmt.Sprintf("err %v", err))
+	err = commitAfterDDLs("insert into tp values (3)", "alter table tp truncate partition p1")
+	require.True(t, terror.ErrorEqual(err, domain.ErrInfoSchemaChanged), fmt.Sprintf("err %v", err))
+
+	// the schema falls behind too much.
+	tk2.MustExec("set @@tidb_max_schema_version_lag = 1")
+	err = commitAfterDDLs("insert into t values (4, 4)", "create table t3 (id int)")
+	require.NoError(t, err)
+	err = commitAfterDDLs("insert into t values (5, 5)", "create table t4 (id int)", "create table t5 (id int)")
+	require.True(t, terror.ErrorEqual(err, domain.ErrInfoSchemaChanged), fmt.Sprintf("err %v", err))
+	tk2.MustQuery("select * from t").Check(testkit.Rows("2 2", "4 4"))
+}
+
 func TestRetrySchemaChangeForEmptyChange(t *testing.T) {
 	store := createMockStoreForSchemaTest(t)
 
@@ -641,7 +641,9 @@ func (s *session) doCommit(ctx context.Context) error {
 		physicalTableIDs = append(physicalTableIDs, id)
 	}
 	// Set this option for 2 phase commit to validate schema lease.
-	s.txn.SetOption(kv.SchemaChecker, domain.NewSchemaChecker(domain.GetDomain(s), s.GetInfoSchema().SchemaMetaVersion(), physicalTableIDs))
+	schemaChecker := domain.NewSchemaChecker(domain.GetDomain(s), s.GetInfoSchema().SchemaMetaVersion(), physicalTableIDs)
+	schemaChecker.SetMaxVersionLag(sessVars.MaxSchemaVersionLag)
+	s.txn.SetOption(kv.SchemaChecker, schemaChecker)
 	s.txn.SetOption(kv.InfoSchema, s.sessionVars.TxnCtx.InfoSchema)
 	s.txn.SetOption(kv.CommitHook, func(info string, _ error) { s.sessionVars.LastTxnInfo = info })
 	if sessVars.EnableAmendPessimisticTxn {
@@ -1240,6 +1242,10 @@ func createSessionFunc(store kv.Storage) pools.Factory {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		err = se.sessionVars.SetSystemVar(variable.TiDBStatementTimeout, "0")
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
 		err = se.sessionVars.SetSystemVar(variable.MaxAllowedPacket, strconv.FormatUint(variable.DefMaxAllowedPacket, 10))
 		if err != nil {
 			return nil, errors.Trace(err)
@@ -1270,6 +1276,10 @@ func createSessionWithDomainFunc(store kv.Storage) func(*domain.Domain) (pools.R
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		err = se.sessionVars.SetSystemVar(variable.TiDBStatementTimeout, "0")
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
 		se.sessionVars.CommonGlobalLoaded = true
 		se.sessionVars.InRestrictedSQL = true
 		// Internal session uses default format to prevent memory leak problem.
@@ -1473,6 +1483,7 @@ func (s *session) SetProcessInfo(sql string, t time.Time, command byte, maxExecu
 		StatsInfo:        plannercore.GetStatsInfo,
 		MaxExecutionTime: maxExecutionTime,
 		RedactSQL:        s.sessionVars.EnableRedactLog,
+		StatementTimeout: s.sessionVars.StatementTimeout,
 	}
 	oldPi := s.ShowProcess()
 	if p == nil {
@@ -2001,7 +2012,11 @@ func (s *session) ExecuteStmt(ctx context.Context, stmtNode ast.StmtNode) (sqlex
 
 	// Uncorrelated subqueries will execute once when building plan, so we reset process info before building plan.
 	cmd32 := atomic.LoadUint32(&s.GetSessionVars().CommandValue)
-	s.SetProcessInfo(stmtNode.Text(), time.Now(), byte(cmd32), 0)
+	processInfoSQL := stmtNode.Text()
+	if secureText, ok := ast.ExternalTableSecureText(stmtNode); ok {
+		processInfoSQL = secureText
+	}
+	s.SetProcessInfo(processInfoSQL, time.Now(), byte(cmd32), 0)
 	s.txn.onStmtStart(digest.String())
 	defer s.txn.onStmtEnd()
 
@@ -2067,6 +2082,7 @@ func (s *session) ExecuteStmt(ctx context.Context, stmtNode ast.StmtNode) (sqlex
 	}
 
 	if err != nil {
+		err = executor.StmtTimeoutError(s, err)
 		if !errIsNoisy(err) {
 			logutil.Logger(ctx).Warn("run statement failed",
 				zap.Int64("schemaVersion", s.GetInfoSchema().SchemaMetaVersion()),
@@ -2247,12 +2263,12 @@ type execStmtResult struct {
 func (rs *execStmtResult) Close() error {
 	se := rs.se
 	if err := rs.RecordSet.Close(); err != nil {
-		return finishStmt(context.Background(), se, err, rs.sql)
+		return executor.StmtTimeoutError(se, finishStmt(context.Background(), se, err, rs.sql))
 	}
 	if err := resetCTEStorageMap(se); err != nil {
-		return finishStmt(context.Background(), se, err, rs.sql)
+		return executor.StmtTimeoutError(se, finishStmt(context.Background(), se, err, rs.sql))
 	}
-	return finishStmt(context.Background(), se, nil, rs.sql)
+	return executor.StmtTimeoutError(se, finishStmt(context.Background(), se, nil, rs.sql))
 }
 
 func resetCTEStorageMap(se *session) error {
@@ -3098,7 +3114,7 @@ func logStmt(execStmt *executor.ExecStmt, s *session) {
 	case *ast.CreateUserStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.SetPwdStmt, *ast.GrantStmt,
 		*ast.RevokeStmt, *ast.AlterTableStmt, *ast.CreateDatabaseStmt, *ast.CreateIndexStmt, *ast.CreateTableStmt,
 		*ast.DropDatabaseStmt, *ast.DropIndexStmt, *ast.DropTableStmt, *ast.RenameTableStmt, *ast.TruncateTableStmt,
-		*ast.RenameUserStmt:
+		*ast.RenameUserStmt, *ast.CreateServerStmt, *ast.DropServerStmt:
 		user := vars.User
 		schemaVersion := s.GetInfoSchema().SchemaMetaVersion()
 		if ss, ok := execStmt.StmtNode.(ast.SensitiveStmtNode); ok {
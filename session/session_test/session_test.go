@@ -2304,6 +2304,61 @@ func TestReplicaRead(t *testing.T) {
 	require.Equal(t, kv.ReplicaReadLeader, tk.Session().GetSessionVars().GetReplicaRead())
 }
 
+func TestReadRouting(t *testing.T) {
+	store := testkit.CreateMockStore(t)
+
+	tk := testkit.NewTestKit(t, store)
+	tk.MustExec("create database db1 read_routing = 'nearest-follower'")
+	tk.MustExec("create database db2")
+	tk.MustExec("create table db1.t (a int primary key, b int)")
+	tk.MustExec("create table db2.t (a int primary key, b int)")
+	tk.MustQuery("show create database db1").Check(testkit.Rows(
+		"db1 CREATE DATABASE `db1` /*!40100 DEFAULT CHARACTER SET utf8mb4 */ /*T![read_routing] READ_ROUTING='nearest-follower' */"))
+
+	// the policy of the database is used when the session variable is the default.
+	tk.MustQuery("select * from db1.t")
+	require.Equal(t, kv.ReplicaReadClosest, tk.Session().GetSessionVars().GetReplicaRead())
+	tk.MustQuery("select * from db1.t where a = 1")
+	require.Equal(t, kv.ReplicaReadClosest, tk.Session().GetSessionVars().GetReplicaRead())
+	tk.MustQuery("select * from db2.t")
+	require.Equal(t, kv.ReplicaReadLeader, tk.Session().GetSessionVars().GetReplicaRead())
+	tk.MustQuery("select * from db1.t join db2.t")
+	require.Equal(t, kv.ReplicaReadLeader, tk.Session().GetSessionVars().GetReplicaRead())
+
+	// the hint is preferred.
+	tk.MustExec("alter database db1 read_routing = 'prefer-local-zone'")
+	tk.MustQuery("select * from db1.t")
+	require.Equal(t, kv.ReplicaReadClosest, tk.Session().GetSessionVars().GetReplicaRead())
+	tk.MustQuery("select /*+ read_consistent_replica() */ * from db1.t")
+	require.Equal(t, kv.ReplicaReadFollower, tk.Session().GetSessionVars().GetReplicaRead())
+
+	// the session variable set explicitly is preferred.
+	tk.MustExec("set @@tidb_replica_read = 'follower'")
+	tk.MustQuery("select * from db1.t")
+	require.Equal(t, kv.ReplicaReadFollower, tk.Session().GetSessionVars().GetReplicaRead())
+	tk.MustExec("alter database db1 read_routing = 'primary-only'")
+	tk.MustQuery("select * from db1.t")
+	require.Equal(t, kv.ReplicaReadFollower, tk.Session().GetSessionVars().GetReplicaRead())
+
+	// the plans got from the plan cache are also routed.
+	tk.MustExec("alter database db1 read_routing = 'nearest-follower'")
+	tk.MustExec("set @@tidb_replica_read = 'leader'")
+	tk.MustExec("prepare stmt from 'select * from db1.t where a = ?'")
+	tk.MustExec("set @a = 1")
+	for i := 0; i < 2; i++ {
+		tk.MustQuery("execute stmt using @a")
+		require.Equal(t, kv.ReplicaReadClosest, tk.Session().GetSessionVars().GetReplicaRead())
+	}
+
+	tk.MustExec("alter database db1 read_routing = default")
+	tk.MustQuery("show create database db1").Check(testkit.Rows(
+		"db1 CREATE DATABASE `db1` /*!40100 DEFAULT CHARACTER SET utf8mb4 */"))
+	tk.MustQuery("select * from db1.t")
+	require.Equal(t, kv.ReplicaReadLeader, tk.Session().GetSessionVars().GetReplicaRead())
+	err := tk.ExecToErr("alter database db1 read_routing = 'leader'")
+	require.ErrorContains(t, err, "The value of READ_ROUTING must be one of")
+}
+
 func TestIsolationRead(t *testing.T) {
 	store := testkit.CreateMockStore(t)
 
@@ -117,6 +117,7 @@ type StatementContext struct {
 	InSelectStmt           bool
 	InLoadDataStmt         bool
 	InExplainStmt          bool
+	InExplainAnalyzeStmt   bool
 	InCreateOrAlterStmt    bool
 	InSetSessionStatesStmt bool
 	InPreparedPlanBuilding bool
@@ -231,6 +232,7 @@ type StatementContext struct {
 	Tables                []TableEntry
 	PointExec             bool  // for point update cached execution, Constant expression need to set "paramMarker"
 	lockWaitStartTime     int64 // LockWaitStartTime stores the pessimistic lock wait start time
+	stmtTimeoutExceeded   int32 // stmtTimeoutExceeded is set if the statement is killed by tidb_statement_timeout
 	PessimisticLockWaited int32
 	LockKeysDuration      int64
 	LockKeysCount         int32
@@ -254,6 +256,11 @@ type StatementContext struct {
 	// If the statement read from table cache, this flag is set.
 	ReadFromTableCache bool
 
+	// HasReadRouting is set if the replicas of the statement are chosen by the
+	// read routing policy of the databases it accesses, which is ReadRouting.
+	HasReadRouting bool
+	ReadRouting    byte
+
 	// cache is used to reduce object allocation.
 	cache struct {
 		execdetails.RuntimeStatsColl
@@ -983,6 +990,16 @@ func (sc *StatementContext) GetLockWaitStartTime() time.Time {
 	return time.Unix(0, startTime)
 }
 
+// SetStmtTimeoutExceeded marks the statement as killed because of exceeding tidb_statement_timeout.
+func (sc *StatementContext) SetStmtTimeoutExceeded() {
+	atomic.StoreInt32(&sc.stmtTimeoutExceeded, 1)
+}
+
+// StmtTimeoutExceeded returns whether the statement is killed because of exceeding tidb_statement_timeout.
+func (sc *StatementContext) StmtTimeoutExceeded() bool {
+	return atomic.LoadInt32(&sc.stmtTimeoutExceeded) == 1
+}
+
 // CopTasksDetails collects some useful information of cop-tasks during execution.
 type CopTasksDetails struct {
 	NumCopTasks int
@@ -847,6 +847,17 @@ type SessionVars struct {
 	// BatchInsert indicates if we should split insert data into multiple batches.
 	BatchInsert bool
 
+	// BatchInsertSplitByRegion indicates if we should split the batches of BatchInsert by the regions of the rows.
+	BatchInsertSplitByRegion bool
+
+	// InsertSelectIngestThreshold is the minimum estimated row count of INSERT ... SELECT to write an empty
+	// table by ingesting the sorted KV files, 0 disables it.
+	InsertSelectIngestThreshold int64
+
+	// CreatedTables records the IDs of the tables created by the session. The rows of INSERT ... SELECT
+	// are only ingested into these tables, which are locked by the session during the ingest.
+	CreatedTables map[int64]struct{}
+
 	// BatchDelete indicates if we should split delete data into multiple batches.
 	BatchDelete bool
 
@@ -942,6 +953,16 @@ type SessionVars struct {
 	// See https://dev.mysql.com/doc/refman/5.7/en/server-system-variables.html#sysvar_max_execution_time
 	MaxExecutionTime uint64
 
+	// StatementTimeout is the timeout of a statement in milliseconds. Unlike MaxExecutionTime,
+	// it applies to all statements and also covers committing the transaction and waiting
+	// for the DDL jobs. If the value is 0, timeouts are not enabled.
+	StatementTimeout uint64
+
+	// ResultBufferLimit is the ceiling in bytes of the coprocessor responses buffered for
+	// a request and of the result chunk fetched for the client, so a slow client doesn't
+	// make the result set pile up in memory. If the value is 0, there is no ceiling.
+	ResultBufferLimit int64
+
 	// Killed is a flag to indicate that this query is killed.
 	Killed uint32
 
@@ -995,6 +1016,10 @@ type SessionVars struct {
 	// preferRangeScan allows optimizer to always prefer range scan over table scan.
 	preferRangeScan bool
 
+	// EnableCardinalityFeedback enables correcting the estimated row counts by the actual row counts of the
+	// executed plans.
+	EnableCardinalityFeedback bool
+
 	// EnableIndexMerge enables the generation of IndexMergePath.
 	enableIndexMerge bool
 
@@ -1047,6 +1072,10 @@ type SessionVars struct {
 	// OptimizerUseInvisibleIndexes indicates whether optimizer can use invisible index
 	OptimizerUseInvisibleIndexes bool
 
+	// HypoIndexes are the hypothetical indexes created in this session, they are only visible to the optimizer
+	// in EXPLAIN statements and are never built. The map is tableID -> indexName -> IndexInfo.
+	HypoIndexes map[int64]map[string]*model.IndexInfo
+
 	// SelectLimit limits the max counts of select statement's output
 	SelectLimit uint64
 
@@ -1143,6 +1172,10 @@ type SessionVars struct {
 	// ReadStaleness indicates the staleness duration for the following query
 	ReadStaleness time.Duration
 
+	// MaxSchemaVersionLag is the max number of schema versions the schema of a transaction can fall behind the
+	// latest one when committing, if the schema change history doesn't cover them.
+	MaxSchemaVersionLag int64
+
 	// cached is used to optimze the object allocation.
 	cached struct {
 		curr int8
@@ -1551,6 +1584,7 @@ func NewSessionVars() *SessionVars {
 		MaxChunkSize:       DefMaxChunkSize,
 		MinPagingSize:      DefMinPagingSize,
 		MaxPagingSize:      DefMaxPagingSize,
+		MaxAggPagingSize:   DefMaxAggPagingSize,
 	}
 	vars.DMLBatchSize = DefDMLBatchSize
 	vars.AllowBatchCop = DefTiDBAllowBatchCop
@@ -1633,11 +1667,14 @@ func (s *SessionVars) SetEnablePseudoForOutdatedStats(val bool) {
 	s.EnablePseudoForOutdatedStats = val
 }
 
-// GetReplicaRead get ReplicaRead from sql hints and SessionVars.replicaRead.
+// GetReplicaRead get ReplicaRead from sql hints, the read routing policy of the databases and SessionVars.replicaRead.
 func (s *SessionVars) GetReplicaRead() kv.ReplicaReadType {
 	if s.StmtCtx.HasReplicaReadHint {
 		return kv.ReplicaReadType(s.StmtCtx.ReplicaRead)
 	}
+	if s.StmtCtx.HasReadRouting {
+		return kv.ReplicaReadType(s.StmtCtx.ReadRouting)
+	}
 	return s.replicaRead
 }
 
@@ -2417,6 +2454,10 @@ type BatchSize struct {
 
 	// MinPagingSize defines the max size used by the coprocessor paging protocol.
 	MaxPagingSize int
+
+	// MaxAggPagingSize defines the max size used by the coprocessor paging protocol when the aggregation is pushed down.
+	// It bounds the partial aggregated groups which are kept by the coprocessor in one page.
+	MaxAggPagingSize int
 }
 
 const (
@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"net"
 	"runtime"
 	"strconv"
 	"strings"
@@ -184,6 +185,10 @@ var defaultSysVars = []*SysVar{
 		s.BatchInsert = TiDBOptOn(val)
 		return nil
 	}},
+	{Scope: ScopeSession, Name: TiDBBatchInsertSplitByRegion, Value: BoolToOnOff(DefBatchInsertSplitByRegion), Type: TypeBool, SetSession: func(s *SessionVars, val string) error {
+		s.BatchInsertSplitByRegion = TiDBOptOn(val)
+		return nil
+	}},
 	{Scope: ScopeSession, Name: TiDBBatchDelete, Value: BoolToOnOff(DefBatchDelete), Type: TypeBool, SetSession: func(s *SessionVars, val string) error {
 		s.BatchDelete = TiDBOptOn(val)
 		return nil
@@ -326,6 +331,10 @@ var defaultSysVars = []*SysVar{
 			return nil
 		},
 	},
+	{Scope: ScopeSession, Name: TiDBMaxSchemaVersionLag, Value: strconv.Itoa(DefTiDBMaxSchemaVersionLag), Type: TypeUnsigned, MinValue: 0, MaxValue: 16384, SetSession: func(s *SessionVars, val string) error {
+		s.MaxSchemaVersionLag = TidbOptInt64(val, DefTiDBMaxSchemaVersionLag)
+		return nil
+	}},
 	{Scope: ScopeSession, Name: TiDBLastDDLInfo, Value: "", ReadOnly: true, GetSession: func(s *SessionVars) (string, error) {
 		info, err := json.Marshal(s.LastDDLInfo)
 		if err != nil {
@@ -640,6 +649,92 @@ var defaultSysVars = []*SysVar{
 		GCMaxWaitTime.Store(TidbOptInt64(val, DefTiDBGCMaxWaitTime))
 		return nil
 	}},
+	{Scope: ScopeGlobal, Name: TiDBRecycleBinRetention, Value: time.Duration(DefTiDBRecycleBinRetention).String(), Type: TypeDuration, MinValue: 0, MaxValue: uint64(time.Hour * 24 * 365), SetGlobal: func(s *SessionVars, val string) error {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		RecycleBinRetention.Store(d)
+		return nil
+	}},
+	{Scope: ScopeGlobal, Name: TiDBRecycleBinQuota, Value: strconv.Itoa(DefTiDBRecycleBinQuota), Type: TypeUnsigned, MinValue: 0, MaxValue: math.MaxInt64, SetGlobal: func(s *SessionVars, val string) error {
+		RecycleBinQuota.Store(uint64(TidbOptInt64(val, DefTiDBRecycleBinQuota)))
+		return nil
+	}},
+	{Scope: ScopeGlobal, Name: TiDBDDLJobTimingRetention, Value: time.Duration(DefTiDBDDLJobTimingRetention).String(), Type: TypeDuration, MinValue: 0, MaxValue: uint64(time.Hour * 24 * 365), SetGlobal: func(s *SessionVars, val string) error {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		DDLJobTimingRetention.Store(d)
+		return nil
+	}},
+	{Scope: ScopeGlobal, Name: TiDBIntervalPartitionHorizon, Value: time.Duration(DefTiDBIntervalPartitionHorizon).String(), Type: TypeDuration, MinValue: int64(time.Minute * 5), MaxValue: uint64(time.Hour * 24 * 365), SetGlobal: func(s *SessionVars, val string) error {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		IntervalPartitionHorizon.Store(d)
+		return nil
+	}},
+	{Scope: ScopeGlobal, Name: TiDBFederatedAllowedHosts, Value: DefTiDBFederatedAllowedHosts, Type: TypeStr,
+		Validation: func(vars *SessionVars, normalizedValue string, originalValue string, scope ScopeFlag) (string, error) {
+			if len(normalizedValue) == 0 {
+				return normalizedValue, nil
+			}
+			hosts := strings.Split(normalizedValue, ",")
+			for i, host := range hosts {
+				hosts[i] = strings.TrimSpace(host)
+				if len(hosts[i]) == 0 {
+					return normalizedValue, ErrWrongValueForVar.GenWithStackByArgs(TiDBFederatedAllowedHosts, originalValue)
+				}
+				if strings.Contains(hosts[i], "/") {
+					if _, _, err := net.ParseCIDR(hosts[i]); err != nil {
+						return normalizedValue, ErrWrongValueForVar.GenWithStackByArgs(TiDBFederatedAllowedHosts, originalValue)
+					}
+				}
+			}
+			return strings.Join(hosts, ","), nil
+		},
+		GetGlobal: func(s *SessionVars) (string, error) {
+			return FederatedAllowedHosts.Load(), nil
+		},
+		SetGlobal: func(s *SessionVars, val string) error {
+			FederatedAllowedHosts.Store(val)
+			return nil
+		},
+	},
+	{Scope: ScopeGlobal, Name: TiDBEnableCheckConstraint, Value: BoolToOnOff(DefTiDBEnableCheckConstraint), Type: TypeBool,
+		GetGlobal: func(s *SessionVars) (string, error) {
+			return BoolToOnOff(EnableCheckConstraint.Load()), nil
+		},
+		SetGlobal: func(s *SessionVars, val string) error {
+			EnableCheckConstraint.Store(TiDBOptOn(val))
+			return nil
+		},
+	},
+	{Scope: ScopeGlobal, Name: TiDBCheckConstraintEnabledTS, Value: "0", Type: TypeUnsigned, MinValue: 0, MaxValue: math.MaxUint64, Hidden: true,
+		GetGlobal: func(s *SessionVars) (string, error) {
+			return strconv.FormatUint(CheckConstraintEnabledTS.Load(), 10), nil
+		},
+		SetGlobal: func(s *SessionVars, val string) error {
+			ts, err := strconv.ParseUint(val, 10, 64)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			CheckConstraintEnabledTS.Store(ts)
+			return nil
+		},
+	},
+	{Scope: ScopeGlobal, Name: TiDBEnableLazyColumnDefault, Value: BoolToOnOff(DefTiDBEnableLazyColumnDefault), Type: TypeBool,
+		GetGlobal: func(s *SessionVars) (string, error) {
+			return BoolToOnOff(EnableLazyColumnDefault.Load()), nil
+		},
+		SetGlobal: func(s *SessionVars, val string) error {
+			EnableLazyColumnDefault.Store(TiDBOptOn(val))
+			return nil
+		},
+	},
 	{Scope: ScopeGlobal, Name: TiDBTableCacheLease, Value: strconv.Itoa(DefTiDBTableCacheLease), Type: TypeUnsigned, MinValue: 1, MaxValue: 10, SetGlobal: func(s *SessionVars, sVal string) error {
 		var val int64
 		val, err := strconv.ParseInt(sVal, 10, 64)
@@ -1108,6 +1203,10 @@ var defaultSysVars = []*SysVar{
 		s.BroadcastJoinThresholdSize = TidbOptInt64(val, DefBroadcastJoinThresholdSize)
 		return nil
 	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBInsertSelectIngestThreshold, Value: strconv.Itoa(DefTiDBInsertSelectIngestThreshold), Type: TypeInt, MinValue: 0, MaxValue: math.MaxInt64, SetSession: func(s *SessionVars, val string) error {
+		s.InsertSelectIngestThreshold = TidbOptInt64(val, DefTiDBInsertSelectIngestThreshold)
+		return nil
+	}},
 	{Scope: ScopeGlobal | ScopeSession, Name: TiDBBuildStatsConcurrency, Value: strconv.Itoa(DefBuildStatsConcurrency), Type: TypeInt, MinValue: 1, MaxValue: MaxConfigurableConcurrency},
 	{Scope: ScopeGlobal | ScopeSession, Name: TiDBOptCartesianBCJ, Value: strconv.Itoa(DefOptCartesianBCJ), Type: TypeInt, MinValue: 0, MaxValue: 2, SetSession: func(s *SessionVars, val string) error {
 		s.AllowCartesianBCJ = TidbOptInt(val, DefOptCartesianBCJ)
@@ -1133,6 +1232,10 @@ var defaultSysVars = []*SysVar{
 		s.SetAllowPreferRangeScan(TiDBOptOn(val))
 		return nil
 	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBOptEnableCardinalityFeedback, Value: BoolToOnOff(DefOptEnableCardinalityFeedback), Type: TypeBool, SetSession: func(s *SessionVars, val string) error {
+		s.EnableCardinalityFeedback = TiDBOptOn(val)
+		return nil
+	}},
 	{Scope: ScopeGlobal | ScopeSession, Name: TiDBOptLimitPushDownThreshold, Value: strconv.Itoa(DefOptLimitPushDownThreshold), Type: TypeUnsigned, MinValue: 0, MaxValue: math.MaxInt32, SetSession: func(s *SessionVars, val string) error {
 		s.LimitPushDownThreshold = TidbOptInt64(val, DefOptLimitPushDownThreshold)
 		return nil
@@ -1745,6 +1848,10 @@ var defaultSysVars = []*SysVar{
 		s.MaxPagingSize = tidbOptPositiveInt32(val, DefMaxPagingSize)
 		return nil
 	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBMaxAggPagingSize, Value: strconv.Itoa(DefMaxAggPagingSize), Type: TypeUnsigned, MinValue: 1, MaxValue: math.MaxInt64, SetSession: func(s *SessionVars, val string) error {
+		s.MaxAggPagingSize = tidbOptPositiveInt32(val, DefMaxAggPagingSize)
+		return nil
+	}},
 	{Scope: ScopeSession, Name: TiDBMemoryDebugModeMinHeapInUse, Value: strconv.Itoa(0), Type: TypeInt, MinValue: math.MinInt64, MaxValue: math.MaxInt64, SetSession: func(s *SessionVars, val string) error {
 		s.MemoryDebugModeMinHeapInUse = TidbOptInt64(val, 0)
 		return nil
@@ -1795,6 +1902,14 @@ var defaultSysVars = []*SysVar{
 		s.EnableTiFlashReadForWriteStmt = TiDBOptOn(val)
 		return nil
 	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBStatementTimeout, Value: strconv.Itoa(DefTiDBStatementTimeout), Type: TypeUnsigned, MinValue: 0, MaxValue: math.MaxInt32, SetSession: func(s *SessionVars, val string) error {
+		s.StatementTimeout = uint64(tidbOptPositiveInt32(val, DefTiDBStatementTimeout))
+		return nil
+	}},
+	{Scope: ScopeGlobal | ScopeSession, Name: TiDBResultBufferLimit, Value: strconv.Itoa(DefTiDBResultBufferLimit), Type: TypeUnsigned, MinValue: 0, MaxValue: math.MaxInt64, SetSession: func(s *SessionVars, val string) error {
+		s.ResultBufferLimit = TidbOptInt64(val, DefTiDBResultBufferLimit)
+		return nil
+	}},
 }
 
 // FeedbackProbability points to the FeedbackProbability in statistics package.
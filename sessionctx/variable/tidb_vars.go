@@ -16,6 +16,7 @@ package variable
 
 import (
 	"math"
+	"time"
 
 	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/parser/mysql"
@@ -97,6 +98,15 @@ const (
 	// insert data into multiple batches and use a single txn for each batch. This will be helpful when inserting large data.
 	TiDBBatchInsert = "tidb_batch_insert"
 
+	// TiDBBatchInsertSplitByRegion is used to split the batches of tidb_batch_insert further by the regions of
+	// the rows, so every batch only writes one region and the consecutive batches write different regions.
+	TiDBBatchInsertSplitByRegion = "tidb_batch_insert_split_by_region"
+
+	// TiDBInsertSelectIngestThreshold is the minimum estimated row count of the SELECT of an INSERT ... SELECT, above
+	// which the rows are written into an empty target table by ingesting the sorted KV files instead of transactional
+	// writes. 0 disables it.
+	TiDBInsertSelectIngestThreshold = "tidb_insert_select_ingest_threshold"
+
 	// TiDBBatchDelete is used to enable/disable auto-split delete data. If set this option on, delete executor will automatically
 	// split data into multiple batches and use a single txn for each batch. This will be helpful when deleting large data.
 	TiDBBatchDelete = "tidb_batch_delete"
@@ -237,6 +247,12 @@ const (
 
 	// TiDBEnableTiFlashReadForWriteStmt indicates whether to enable TiFlash to read for write statements.
 	TiDBEnableTiFlashReadForWriteStmt = "tidb_enable_tiflash_read_for_write_stmt"
+
+	// TiDBMaxSchemaVersionLag is the max number of schema versions the schema of a transaction can fall behind
+	// the latest one when committing, if the schema change history doesn't cover them. Within the lag, the schema
+	// diffs are read to check whether the tables of the transaction are changed, instead of reporting that
+	// the information schema is changed.
+	TiDBMaxSchemaVersionLag = "tidb_max_schema_version_lag"
 )
 
 // TiDB system variable names that both in session and global scope.
@@ -257,6 +273,10 @@ const (
 	// TiDBOptPreferRangeScan is used to enable/disable the optimizer to always prefer range scan over table scan, ignoring their costs.
 	TiDBOptPreferRangeScan = "tidb_opt_prefer_range_scan"
 
+	// TiDBOptEnableCardinalityFeedback is used to enable/disable correcting the estimated row counts of the filters on
+	// the tables by the actual row counts of the executed plans.
+	TiDBOptEnableCardinalityFeedback = "tidb_opt_enable_cardinality_feedback"
+
 	// TiDBOptEnableCorrelationAdjustment is used to indicates if enable correlation adjustment.
 	TiDBOptEnableCorrelationAdjustment = "tidb_opt_enable_correlation_adjustment"
 
@@ -386,6 +406,9 @@ const (
 	// TiDBMaxPagingSize is used to control the max paging size in the coprocessor paging protocol.
 	TiDBMaxPagingSize = "tidb_max_paging_size"
 
+	// TiDBMaxAggPagingSize is used to control the max paging size of the coprocessor requests with pushed-down aggregation.
+	TiDBMaxAggPagingSize = "tidb_max_agg_paging_size"
+
 	// TiDBEnableCascadesPlanner is used to control whether to enable the cascades planner.
 	TiDBEnableCascadesPlanner = "tidb_enable_cascades_planner"
 
@@ -736,6 +759,14 @@ const (
 
 	// TiDBConstraintCheckInPlacePessimistic controls whether to skip certain kinds of pessimistic locks.
 	TiDBConstraintCheckInPlacePessimistic = "tidb_constraint_check_in_place_pessimistic"
+
+	// TiDBStatementTimeout is the timeout of a statement in milliseconds, including
+	// committing the transaction and waiting for the DDL jobs, 0 disables it.
+	TiDBStatementTimeout = "tidb_statement_timeout"
+
+	// TiDBResultBufferLimit is the ceiling in bytes of the coprocessor responses and the result
+	// chunk buffered before TiDB waits for them to be consumed, 0 disables it.
+	TiDBResultBufferLimit = "tidb_result_buffer_limit"
 )
 
 // TiDB vars that have only global scope
@@ -753,6 +784,28 @@ const (
 	TiDBGCScanLockMode = "tidb_gc_scan_lock_mode"
 	// TiDBGCMaxWaitTime sets max time for gc advances the safepoint delayed by active transactions
 	TiDBGCMaxWaitTime = "tidb_gc_max_wait_time"
+	// TiDBRecycleBinRetention sets how long the dropped tables are kept in the recycle bin, 0 disables the recycle bin.
+	TiDBRecycleBinRetention = "tidb_recycle_bin_retention"
+	// TiDBRecycleBinQuota sets the max total size in bytes of the tables in the recycle bin, 0 means no limit.
+	TiDBRecycleBinQuota = "tidb_recycle_bin_quota"
+	// TiDBDDLJobTimingRetention sets how long the timings of the finished DDL jobs are kept in
+	// mysql.tidb_ddl_job_timing, 0 disables recording them.
+	TiDBDDLJobTimingRetention = "tidb_ddl_job_timing_retention"
+	// TiDBIntervalPartitionHorizon sets how far ahead of now the partitions of the tables partitioned by
+	// INTERVAL ... AUTO CREATE are created.
+	TiDBIntervalPartitionHorizon = "tidb_interval_partition_horizon"
+	// TiDBFederatedAllowedHosts is the comma separated host names, IPs and CIDRs of the remote servers that
+	// the federated tables are allowed to connect to, the empty value allows all the hosts.
+	TiDBFederatedAllowedHosts = "tidb_federated_allowed_hosts"
+	// TiDBEnableCheckConstraint enables the enforcement of the CHECK constraints, which are parsed and ignored if disabled.
+	TiDBEnableCheckConstraint = "tidb_enable_check_constraint"
+	// TiDBCheckConstraintEnabledTS is the TSO when tidb_enable_check_constraint is turned on. It's set by TiDB
+	// along with tidb_enable_check_constraint, so that the instances agree on which constraints are validated after it.
+	TiDBCheckConstraintEnabledTS = "tidb_check_constraint_enabled_ts"
+	// TiDBEnableLazyColumnDefault enables adding the NOT NULL columns with the default values of uuid() or rand()
+	// instantly, whose values of the existing rows are evaluated lazily. The downstreams decoding the rows by the
+	// table schema, e.g. TiCDC, can't evaluate the values, so it's disabled by default.
+	TiDBEnableLazyColumnDefault = "tidb_enable_lazy_column_default"
 	// TiDBEnableEnhancedSecurity restricts SUPER users from certain operations.
 	TiDBEnableEnhancedSecurity = "tidb_enable_enhanced_security"
 	// TiDBEnableHistoricalStats enables the historical statistics feature (default off)
@@ -866,13 +919,17 @@ const (
 	DefOptConcurrencyFactorV2                      = 3.0
 	DefOptInSubqToJoinAndAgg                       = true
 	DefOptPreferRangeScan                          = false
+	DefOptEnableCardinalityFeedback                = false
 	DefBatchInsert                                 = false
+	DefBatchInsertSplitByRegion                    = false
+	DefTiDBInsertSelectIngestThreshold             = 0
 	DefBatchDelete                                 = false
 	DefBatchCommit                                 = false
 	DefCurretTS                                    = 0
 	DefInitChunkSize                               = 32
 	DefMinPagingSize                               = int(paging.MinPagingSize)
 	DefMaxPagingSize                               = int(paging.MaxPagingSize)
+	DefMaxAggPagingSize                            = int(paging.MaxPagingSize)
 	DefMaxChunkSize                                = 1024
 	DefDMLBatchSize                                = 0
 	DefMaxPreparedStmtCount                        = -1
@@ -904,6 +961,7 @@ const (
 	DefTiDBDDLReorgBatchSize                       = 256
 	DefTiDBDDLErrorCountLimit                      = 512
 	DefTiDBMaxDeltaSchemaCount                     = 1024
+	DefTiDBMaxSchemaVersionLag                     = 0
 	DefTiDBPlacementMode                           = PlacementModeStrict
 	DefTiDBEnableAutoIncrementInGenerated          = false
 	DefTiDBHashAggPartialConcurrency               = ConcurrencyUnset
@@ -992,6 +1050,13 @@ const (
 	DefTiDBSkewDistinctAgg                         = false
 	DefTiDBReadStaleness                           = 0
 	DefTiDBGCMaxWaitTime                           = 24 * 60 * 60
+	DefTiDBRecycleBinRetention                     = 0
+	DefTiDBRecycleBinQuota                         = 0
+	DefTiDBDDLJobTimingRetention                   = 30 * 24 * time.Hour
+	DefTiDBIntervalPartitionHorizon                = 24 * time.Hour
+	DefTiDBFederatedAllowedHosts                   = ""
+	DefTiDBEnableCheckConstraint                   = false
+	DefTiDBEnableLazyColumnDefault                 = false
 	DefMaxAllowedPacket                     uint64 = 67108864
 	DefTiDBEnableBatchDML                          = false
 	DefTiDBMemQuotaQuery                           = 1073741824 // 1GB
@@ -1032,6 +1097,8 @@ const (
 	MinDDLReorgBatchSize                     int32  = 32
 	MinExpensiveQueryTimeThreshold           uint64 = 10 // 10s
 	DefTiDBConstraintCheckInPlacePessimistic        = true
+	DefTiDBStatementTimeout                         = 0
+	DefTiDBResultBufferLimit                        = 0
 )
 
 // Process global variables.
@@ -1066,6 +1133,14 @@ var (
 	StatsLoadPseudoTimeout             = atomic.NewBool(DefTiDBStatsLoadPseudoTimeout)
 	MemQuotaBindingCache               = atomic.NewInt64(DefTiDBMemQuotaBindingCache)
 	GCMaxWaitTime                      = atomic.NewInt64(DefTiDBGCMaxWaitTime)
+	RecycleBinRetention                = atomic.NewDuration(DefTiDBRecycleBinRetention)
+	RecycleBinQuota                    = atomic.NewUint64(DefTiDBRecycleBinQuota)
+	DDLJobTimingRetention              = atomic.NewDuration(DefTiDBDDLJobTimingRetention)
+	IntervalPartitionHorizon           = atomic.NewDuration(DefTiDBIntervalPartitionHorizon)
+	FederatedAllowedHosts              = atomic.NewString(DefTiDBFederatedAllowedHosts)
+	EnableCheckConstraint              = atomic.NewBool(DefTiDBEnableCheckConstraint)
+	CheckConstraintEnabledTS           = atomic.NewUint64(0)
+	EnableLazyColumnDefault            = atomic.NewBool(DefTiDBEnableLazyColumnDefault)
 	StatsCacheMemQuota                 = atomic.NewInt64(DefTiDBStatsCacheMemQuota)
 	OOMAction                          = atomic.NewString(DefTiDBMemOOMAction)
 	MaxAutoAnalyzeTime                 = atomic.NewInt64(DefTiDBMaxAutoAnalyzeTime)
@@ -455,6 +455,14 @@ func TestVarsutil(t *testing.T) {
 	err = v.SetSystemVar(TiDBMaxPagingSize, "45678")
 	require.NoError(t, err)
 	require.Equal(t, v.MaxPagingSize, 45678)
+
+	val, err = v.GetSessionOrGlobalSystemVar(TiDBMaxAggPagingSize)
+	require.NoError(t, err)
+	require.Equal(t, strconv.Itoa(DefMaxAggPagingSize), val)
+
+	err = v.SetSystemVar(TiDBMaxAggPagingSize, "4096")
+	require.NoError(t, err)
+	require.Equal(t, v.MaxAggPagingSize, 4096)
 }
 
 func TestValidate(t *testing.T) {
@@ -0,0 +1,162 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pingcap/tidb/expression"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// MaxCardinalityCorrection bounds the correction factors to [1/MaxCardinalityCorrection, MaxCardinalityCorrection].
+	MaxCardinalityCorrection = 100.0
+	// cardinalityFeedbackMinRows is the minimum of the larger one of the estimated and the actual row count of a
+	// feedback, the misestimates of the small results are ignored because they barely change the plans.
+	cardinalityFeedbackMinRows = 64
+	// cardinalityFeedbackRate is the weight of a new feedback, so a single outlier only moves the factor a bit, but a
+	// systematic misestimate is corrected in a few executions.
+	cardinalityFeedbackRate = 0.3
+	// maxCardinalityFeedbackEntries bounds the memory of the feedback, the filters beyond it are not corrected.
+	maxCardinalityFeedbackEntries = 4096
+)
+
+// CardinalityFeedback is the correction of the estimated row counts of the filters on the columns of a table, which is
+// learned from the actual row counts of the executed plans under the stats of the table.
+type CardinalityFeedback struct {
+	TableID int64
+	// ColumnIDs are the IDs of the columns filtered, the filters on different columns are corrected separately since
+	// the misestimates of them are usually different.
+	ColumnIDs []int64
+	// StatsVersion is the version of the stats the estimations are based on, the feedback is dropped when the stats
+	// are updated since the misestimates may be fixed by the new stats.
+	StatsVersion uint64
+	// Factor is multiplied to the selectivity of the filters.
+	Factor      float64
+	Samples     uint64
+	LastEstRows float64
+	LastActRows float64
+	LastUpdate  time.Time
+}
+
+type cardinalityFeedbackKey struct {
+	tableID int64
+	columns string
+}
+
+func newCardinalityFeedbackKey(tableID int64, colIDs []int64) cardinalityFeedbackKey {
+	var sb strings.Builder
+	for i, id := range colIDs {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatInt(id, 10))
+	}
+	return cardinalityFeedbackKey{tableID: tableID, columns: sb.String()}
+}
+
+var cardinalityFeedback = struct {
+	sync.RWMutex
+	entries map[cardinalityFeedbackKey]*CardinalityFeedback
+}{entries: make(map[cardinalityFeedbackKey]*CardinalityFeedback)}
+
+// FilterColumnIDs returns the sorted IDs of the columns used by the filters, which identify the feedback of the
+// filters along with the table.
+func FilterColumnIDs(conds []expression.Expression) []int64 {
+	cols := expression.ExtractColumnsFromExpressions(nil, conds, nil)
+	colIDs := make([]int64, 0, len(cols))
+	for _, col := range cols {
+		if col.ID != 0 && !slices.Contains(colIDs, col.ID) {
+			colIDs = append(colIDs, col.ID)
+		}
+	}
+	slices.Sort(colIDs)
+	return colIDs
+}
+
+// RecordCardinalityFeedback feeds the estimated and the actual row count of the filters on the columns of a table back
+// to their correction factor. The estimation has been corrected by the current factor, so the error is applied to the
+// factor multiplicatively, unless the factor is learned under another version of the stats.
+func RecordCardinalityFeedback(tableID int64, colIDs []int64, statsVersion uint64, estRows, actRows float64) {
+	if math.Max(estRows, actRows) < cardinalityFeedbackMinRows {
+		return
+	}
+	ratio := math.Max(actRows, 1) / math.Max(estRows, 1)
+	key := newCardinalityFeedbackKey(tableID, colIDs)
+	cardinalityFeedback.Lock()
+	defer cardinalityFeedback.Unlock()
+	fb, ok := cardinalityFeedback.entries[key]
+	if !ok {
+		if len(cardinalityFeedback.entries) >= maxCardinalityFeedbackEntries {
+			return
+		}
+		fb = &CardinalityFeedback{TableID: tableID, ColumnIDs: slices.Clone(colIDs)}
+		cardinalityFeedback.entries[key] = fb
+	}
+	if !ok || fb.StatsVersion != statsVersion {
+		// The estimation isn't corrected by the factor of the other stats, see CardinalityCorrectionFactor.
+		fb.StatsVersion = statsVersion
+		fb.Factor = 1
+		fb.Samples = 0
+	}
+	factor := fb.Factor * math.Pow(ratio, cardinalityFeedbackRate)
+	fb.Factor = math.Min(math.Max(factor, 1/MaxCardinalityCorrection), MaxCardinalityCorrection)
+	fb.Samples++
+	fb.LastEstRows = estRows
+	fb.LastActRows = actRows
+	fb.LastUpdate = time.Now()
+}
+
+// CardinalityCorrectionFactor returns the correction factor of the selectivity of the filters on the columns of the
+// table under the version of the stats, 1 means no correction.
+func CardinalityCorrectionFactor(tableID int64, colIDs []int64, statsVersion uint64) float64 {
+	key := newCardinalityFeedbackKey(tableID, colIDs)
+	cardinalityFeedback.RLock()
+	defer cardinalityFeedback.RUnlock()
+	if fb, ok := cardinalityFeedback.entries[key]; ok && fb.StatsVersion == statsVersion {
+		return fb.Factor
+	}
+	return 1
+}
+
+// GetCardinalityFeedback returns all the feedback, ordered by the table IDs and the column IDs.
+func GetCardinalityFeedback() []CardinalityFeedback {
+	cardinalityFeedback.RLock()
+	feedback := make([]CardinalityFeedback, 0, len(cardinalityFeedback.entries))
+	for _, fb := range cardinalityFeedback.entries {
+		feedback = append(feedback, *fb)
+	}
+	cardinalityFeedback.RUnlock()
+	sort.Slice(feedback, func(i, j int) bool {
+		if feedback[i].TableID != feedback[j].TableID {
+			return feedback[i].TableID < feedback[j].TableID
+		}
+		return slices.Compare(feedback[i].ColumnIDs, feedback[j].ColumnIDs) < 0
+	})
+	return feedback
+}
+
+// ResetCardinalityFeedback drops all the feedback.
+func ResetCardinalityFeedback() {
+	cardinalityFeedback.Lock()
+	cardinalityFeedback.entries = make(map[cardinalityFeedbackKey]*CardinalityFeedback)
+	cardinalityFeedback.Unlock()
+}
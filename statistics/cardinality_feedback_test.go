@@ -0,0 +1,69 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardinalityFeedback(t *testing.T) {
+	ResetCardinalityFeedback()
+	defer ResetCardinalityFeedback()
+	cols := []int64{1}
+
+	// the small results are ignored.
+	RecordCardinalityFeedback(1, cols, 1, 10, 50)
+	require.Equal(t, 1.0, CardinalityCorrectionFactor(1, cols, 1))
+	require.Empty(t, GetCardinalityFeedback())
+
+	// a systematic underestimation converges to the actual row count.
+	actRows := 10000.0
+	for i := 0; i < 30; i++ {
+		estRows := 1000 * CardinalityCorrectionFactor(1, cols, 1)
+		RecordCardinalityFeedback(1, cols, 1, estRows, actRows)
+	}
+	require.InDelta(t, 10.0, CardinalityCorrectionFactor(1, cols, 1), 0.1)
+
+	// the filters on the other columns aren't corrected.
+	require.Equal(t, 1.0, CardinalityCorrectionFactor(1, []int64{2}, 1))
+	require.Equal(t, 1.0, CardinalityCorrectionFactor(1, []int64{1, 2}, 1))
+
+	// the factors are bounded.
+	for i := 0; i < 100; i++ {
+		RecordCardinalityFeedback(2, cols, 1, 1e8, 0)
+	}
+	require.Equal(t, 1/MaxCardinalityCorrection, CardinalityCorrectionFactor(2, cols, 1))
+
+	feedback := GetCardinalityFeedback()
+	require.Len(t, feedback, 2)
+	require.Equal(t, int64(1), feedback[0].TableID)
+	require.Equal(t, cols, feedback[0].ColumnIDs)
+	require.Equal(t, uint64(30), feedback[0].Samples)
+	require.Equal(t, actRows, feedback[0].LastActRows)
+	require.Equal(t, int64(2), feedback[1].TableID)
+	require.Equal(t, uint64(100), feedback[1].Samples)
+
+	// the factors learned under the old stats are dropped.
+	require.Equal(t, 1.0, CardinalityCorrectionFactor(1, cols, 2))
+	RecordCardinalityFeedback(1, cols, 2, 1000, 2000)
+	require.InDelta(t, math.Pow(2, cardinalityFeedbackRate), CardinalityCorrectionFactor(1, cols, 2), 1e-9)
+	require.Equal(t, 1.0, CardinalityCorrectionFactor(1, cols, 1))
+	feedback = GetCardinalityFeedback()
+	require.Equal(t, uint64(2), feedback[0].StatsVersion)
+	require.Equal(t, uint64(1), feedback[0].Samples)
+}
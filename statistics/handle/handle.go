@@ -567,9 +567,8 @@ func (h *Handle) getTableByPhysicalID(is infoschema.InfoSchema, physicalID int64
 
 func buildPartitionID2TableID(is infoschema.InfoSchema) map[int64]int64 {
 	mapper := make(map[int64]int64)
-	for _, db := range is.AllSchemas() {
-		tbls := db.Tables
-		for _, tbl := range tbls {
+	infoschema.IterateSchemaTableInfos(is, func(_ *model.DBInfo, tblInfos []*model.TableInfo) bool {
+		for _, tbl := range tblInfos {
 			pi := tbl.GetPartitionInfo()
 			if pi == nil {
 				continue
@@ -578,7 +577,8 @@ func buildPartitionID2TableID(is infoschema.InfoSchema) map[int64]int64 {
 				mapper[def.ID] = tbl.ID
 			}
 		}
-	}
+		return true
+	})
 	return mapper
 }
 
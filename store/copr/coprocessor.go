@@ -131,6 +131,7 @@ func (c *CopClient) Send(ctx context.Context, req *kv.Request, variables interfa
 		memTracker:      req.MemTracker,
 		replicaReadSeed: c.replicaReadSeed,
 		rpcCancel:       tikv.NewRPCanceller(),
+		respBuffer:      newRespBuffer(req.ResultBufferLimit),
 	}
 	it.tasks = tasks
 	if it.concurrency > len(tasks) {
@@ -333,6 +334,8 @@ type copIterator struct {
 	committedLocks util.TSSet
 
 	actionOnExceed *rateLimitAction
+
+	respBuffer *respBuffer
 }
 
 // copIteratorWorker receives tasks from copIteratorTaskSender, handles tasks and sends the copResponse to respChan.
@@ -347,6 +350,9 @@ type copIteratorWorker struct {
 	kvclient *txnsnapshot.ClientHelper
 
 	memTracker *memory.Tracker
+	// actionOnExceed is used to shrink the paging size when the memory usage exceeds the quota.
+	actionOnExceed *rateLimitAction
+	respBuffer     *respBuffer
 
 	replicaReadSeed uint32
 
@@ -355,12 +361,13 @@ type copIteratorWorker struct {
 
 // copIteratorTaskSender sends tasks to taskCh then wait for the workers to exit.
 type copIteratorTaskSender struct {
-	taskCh   chan<- *copTask
-	wg       *sync.WaitGroup
-	tasks    []*copTask
-	finishCh <-chan struct{}
-	respChan chan<- *copResponse
-	sendRate *util.RateLimit
+	taskCh     chan<- *copTask
+	wg         *sync.WaitGroup
+	tasks      []*copTask
+	finishCh   <-chan struct{}
+	respChan   chan<- *copResponse
+	sendRate   *util.RateLimit
+	respBuffer *respBuffer
 }
 
 type copResponse struct {
@@ -370,6 +377,8 @@ type copResponse struct {
 	err      error
 	respSize int64
 	respTime time.Duration
+	// bufferedSize is the size counted by the respBuffer.
+	bufferedSize int64
 }
 
 const sizeofExecDetails = int(unsafe.Sizeof(execdetails.ExecDetails{}))
@@ -470,17 +479,20 @@ func (it *copIterator) open(ctx context.Context, enabledRateLimitAction, enableC
 			vars:                       it.vars,
 			kvclient:                   txnsnapshot.NewClientHelper(it.store.store, &it.resolvedLocks, &it.committedLocks, false),
 			memTracker:                 it.memTracker,
+			actionOnExceed:             it.actionOnExceed,
+			respBuffer:                 it.respBuffer,
 			replicaReadSeed:            it.replicaReadSeed,
 			enableCollectExecutionInfo: enableCollectExecutionInfo,
 		}
 		go worker.run(ctx)
 	}
 	taskSender := &copIteratorTaskSender{
-		taskCh:   taskCh,
-		wg:       &it.wg,
-		tasks:    it.tasks,
-		finishCh: it.finishCh,
-		sendRate: it.sendRate,
+		taskCh:     taskCh,
+		wg:         &it.wg,
+		tasks:      it.tasks,
+		finishCh:   it.finishCh,
+		sendRate:   it.sendRate,
+		respBuffer: it.respBuffer,
 	}
 	taskSender.respChan = it.respChan
 	it.actionOnExceed.setEnabled(enabledRateLimitAction)
@@ -505,6 +517,12 @@ func (sender *copIteratorTaskSender) run() {
 		if exit {
 			break
 		}
+		// Don't send more tasks while the buffered responses exceed the result buffer limit,
+		// so a slow consumer, e.g. a client reading the result set slowly, doesn't make them pile up.
+		exit = sender.respBuffer.wait(sender.finishCh)
+		if exit {
+			break
+		}
 		exit = sender.sendToTaskCh(t)
 		if exit {
 			break
@@ -525,6 +543,9 @@ func (it *copIterator) recvFromRespCh(ctx context.Context, respCh <-chan *copRes
 	for {
 		select {
 		case resp, ok = <-respCh:
+			if resp != nil {
+				it.respBuffer.release(resp)
+			}
 			if it.memTracker != nil && resp != nil {
 				consumed := resp.MemSize()
 				failpoint.Inject("testRateLimitActionMockConsumeAndAssert", func(val failpoint.Value) {
@@ -578,6 +599,9 @@ func (worker *copIteratorWorker) sendToRespCh(resp *copResponse, respCh chan<- *
 		})
 		worker.memTracker.Consume(consumed)
 	}
+	if checkOOM {
+		worker.respBuffer.add(resp)
+	}
 	select {
 	case respCh <- resp:
 	case <-worker.finishCh:
@@ -896,10 +920,26 @@ func (worker *copIteratorWorker) handleCopPagingResult(bo *Backoffer, rpcCtx *ti
 		return nil, nil
 	}
 
-	task.pagingSize = paging.GrowPagingSize(task.pagingSize, worker.req.Paging.MaxPagingSize)
+	task.pagingSize = nextPagingSize(task.pagingSize, worker.req, worker.actionOnExceed.isExceeded())
 	return []*copTask{task}, nil
 }
 
+// nextPagingSize calculates the paging size of the next page. The remained ranges are resumed from the range
+// returned by the last page, so the paging size is halved when the memory usage exceeds the quota, and the
+// coprocessor returns the partial results, e.g. the partial aggregated groups, in smaller pages.
+func nextPagingSize(size uint64, req *kv.Request, memExceeded bool) uint64 {
+	if memExceeded {
+		return mathutil.Max(size/2, req.Paging.MinPagingSize, 1)
+	}
+	size = paging.GrowPagingSize(size, req.Paging.MaxPagingSize)
+	// the coprocessor keeps the partial aggregated groups of one page in memory,
+	// limit the paging size to avoid OOM for the high cardinality aggregation.
+	if req.Paging.AggPushDown && req.Paging.MaxAggPagingSize > 0 && size > req.Paging.MaxAggPagingSize {
+		size = req.Paging.MaxAggPagingSize
+	}
+	return size
+}
+
 // handleCopResponse checks coprocessor Response for region split and lock,
 // returns more tasks when that happens, or handles the response if no error.
 // if we're handling coprocessor paging response, lastRange is the range of last
@@ -1010,7 +1050,9 @@ func (worker *copIteratorWorker) handleCopResponse(bo *Backoffer, rpcCtx *tikv.R
 			}
 		}
 		resp.detail.CoprCacheHit = true
+		recordCoprCacheStats(task.ranges.At(0).StartKey, true, false)
 	} else {
+		admitted := false
 		// Cache not hit or cache hit but not valid: update the cache if the response can be cached.
 		if cacheKey != nil && resp.pbResp.CanBeCached && resp.pbResp.CacheLastVersion > 0 {
 			if resp.detail != nil {
@@ -1030,10 +1072,13 @@ func (worker *copIteratorWorker) handleCopResponse(bo *Backoffer, rpcCtx *tikv.R
 						newCacheValue.PageEnd = append([]byte{}, r.GetEnd()...)
 					}
 
-					worker.store.coprCache.Set(cacheKey, &newCacheValue)
+					admitted = worker.store.coprCache.Set(cacheKey, &newCacheValue)
 				}
 			}
 		}
+		if cacheKey != nil {
+			recordCoprCacheStats(task.ranges.At(0).StartKey, false, admitted)
+		}
 	}
 	worker.sendToRespCh(resp, ch, true)
 	return nil, nil
@@ -1213,6 +1258,64 @@ func (it copErrorResponse) Close() error {
 	return nil
 }
 
+// respBuffer counts the bytes of the responses buffered and not read yet. Once they exceed the limit,
+// the sender waits for the responses to be read before sending more tasks.
+type respBuffer struct {
+	limit   int64
+	bytes   int64
+	drained chan struct{}
+}
+
+// newRespBuffer returns nil if there is no limit, and the methods of a nil respBuffer do nothing.
+func newRespBuffer(limit int64) *respBuffer {
+	if limit <= 0 {
+		return nil
+	}
+	return &respBuffer{
+		limit:   limit,
+		drained: make(chan struct{}, 1),
+	}
+}
+
+func (b *respBuffer) add(resp *copResponse) {
+	if b == nil {
+		return
+	}
+	resp.bufferedSize = resp.MemSize()
+	atomic.AddInt64(&b.bytes, resp.bufferedSize)
+}
+
+func (b *respBuffer) release(resp *copResponse) {
+	if b == nil || resp.bufferedSize == 0 {
+		return
+	}
+	if atomic.AddInt64(&b.bytes, -resp.bufferedSize) < b.limit {
+		select {
+		case b.drained <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// wait blocks until the buffered responses are below the limit. It returns true if the iterator is closed.
+func (b *respBuffer) wait(finishCh <-chan struct{}) (exit bool) {
+	if b == nil || atomic.LoadInt64(&b.bytes) < b.limit {
+		return false
+	}
+	start := time.Now()
+	defer func() {
+		tidbmetrics.ResultSetBackpressureHistogram.Observe(time.Since(start).Seconds())
+	}()
+	for atomic.LoadInt64(&b.bytes) >= b.limit {
+		select {
+		case <-b.drained:
+		case <-finishCh:
+			return true
+		}
+	}
+	return false
+}
+
 // rateLimitAction an OOM Action which is used to control the token if OOM triggered. The token number should be
 // set on initial. Each time the Action is triggered, one token would be destroyed. If the count of the token is less
 // than 2, the action would be delegated to the fallback action.
@@ -1320,6 +1423,16 @@ func (e *rateLimitAction) destroyTokenIfNeeded(returnToken func()) {
 	e.cond.once = sync.Once{}
 }
 
+// isExceeded checks whether the memory usage exceeds the quota and no token is destroyed for it yet.
+func (e *rateLimitAction) isExceeded() bool {
+	if e == nil || !e.isEnabled() {
+		return false
+	}
+	e.conditionLock()
+	defer e.conditionUnlock()
+	return e.cond.exceeded
+}
+
 func (e *rateLimitAction) conditionLock() {
 	e.cond.Lock()
 }
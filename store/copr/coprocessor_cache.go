@@ -19,12 +19,17 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"sort"
+	"sync"
 	"time"
 	"unsafe"
 
 	"github.com/dgraph-io/ristretto"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/kvproto/pkg/coprocessor"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/kvcache"
 	"github.com/tikv/client-go/v2/config"
 )
 
@@ -214,3 +219,90 @@ func (c *coprCache) Set(key []byte, value *coprCacheValue) bool {
 	value.Key = key
 	return c.cache.Set(key, value, int64(value.Len()))
 }
+
+// CoprCacheTableStats is the statistics of the coprocessor cache of a physical table.
+type CoprCacheTableStats struct {
+	// PhysicalID is the ID of the table or the partition.
+	PhysicalID int64
+	// Requests is the number of cacheable requests.
+	Requests uint64
+	// Hits is the number of requests whose responses are served by the cache.
+	Hits uint64
+	// Admissions is the number of responses put into the cache.
+	Admissions uint64
+}
+
+type coprCacheTableCounter struct {
+	requests   uint64
+	hits       uint64
+	admissions uint64
+}
+
+// coprCacheTableKey is the physical table ID used as the key of coprCacheTableCounters.
+type coprCacheTableKey int64
+
+func (k coprCacheTableKey) Hash() []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(k))
+	return buf[:]
+}
+
+// maxCoprCacheTableCounters is the number of the physical tables whose statistics
+// are kept, the least recently read tables are evicted beyond it so the counters
+// of the dropped tables and partitions do not accumulate.
+const maxCoprCacheTableCounters = 4096
+
+var coprCacheTableCounters = struct {
+	sync.Mutex
+	// lru maps the physical table ID to its *coprCacheTableCounter.
+	lru *kvcache.SimpleLRUCache
+}{lru: kvcache.NewSimpleLRUCache(maxCoprCacheTableCounters, 0, 0)}
+
+// recordCoprCacheStats records a response of a cacheable request reading startKey.
+// A task is counted for the table of its first range, the regions rarely span
+// tables since they are split by tables.
+func recordCoprCacheStats(startKey kv.Key, hit, admitted bool) {
+	physicalID := tablecodec.DecodeTableID(startKey)
+	if physicalID == 0 {
+		return
+	}
+	key := coprCacheTableKey(physicalID)
+	coprCacheTableCounters.Lock()
+	defer coprCacheTableCounters.Unlock()
+	v, ok := coprCacheTableCounters.lru.Get(key)
+	if !ok {
+		v = &coprCacheTableCounter{}
+		coprCacheTableCounters.lru.Put(key, v)
+	}
+	counter := v.(*coprCacheTableCounter)
+	counter.requests++
+	if hit {
+		counter.hits++
+	}
+	if admitted {
+		counter.admissions++
+	}
+}
+
+// GetCoprCacheTableStats returns the statistics of the coprocessor cache of the
+// recently read physical tables since the TiDB starts, ordered by the physical ID.
+func GetCoprCacheTableStats() []CoprCacheTableStats {
+	coprCacheTableCounters.Lock()
+	keys := coprCacheTableCounters.lru.Keys()
+	values := coprCacheTableCounters.lru.Values()
+	stats := make([]CoprCacheTableStats, 0, len(keys))
+	for i, key := range keys {
+		counter := values[i].(*coprCacheTableCounter)
+		stats = append(stats, CoprCacheTableStats{
+			PhysicalID: int64(key.(coprCacheTableKey)),
+			Requests:   counter.requests,
+			Hits:       counter.hits,
+			Admissions: counter.admissions,
+		})
+	}
+	coprCacheTableCounters.Unlock()
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].PhysicalID < stats[j].PhysicalID
+	})
+	return stats
+}
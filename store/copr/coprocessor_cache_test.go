@@ -20,6 +20,7 @@ import (
 
 	"github.com/pingcap/kvproto/pkg/coprocessor"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/client-go/v2/config"
 )
@@ -208,3 +209,32 @@ func TestIssue24118(t *testing.T) {
 	_, err := newCoprCache(&config.CoprocessorCache{AdmissionMinProcessMs: 5, AdmissionMaxResultMB: 1, CapacityMB: -1})
 	require.EqualError(t, err, "Capacity must be > 0 to enable the cache")
 }
+
+func TestTableStats(t *testing.T) {
+	recordCoprCacheStats(tablecodec.EncodeTablePrefix(1002), false, true)
+	recordCoprCacheStats(tablecodec.GenTableRecordPrefix(1002), true, false)
+	recordCoprCacheStats(tablecodec.EncodeTableIndexPrefix(1001, 1), false, false)
+	recordCoprCacheStats(kv.Key("not a table key"), true, false)
+
+	var stats []CoprCacheTableStats
+	for _, s := range GetCoprCacheTableStats() {
+		if s.PhysicalID == 1001 || s.PhysicalID == 1002 {
+			stats = append(stats, s)
+		}
+	}
+	require.Equal(t, []CoprCacheTableStats{
+		{PhysicalID: 1001, Requests: 1},
+		{PhysicalID: 1002, Requests: 2, Hits: 1, Admissions: 1},
+	}, stats)
+}
+
+func TestTableStatsEviction(t *testing.T) {
+	const base = 2000000
+	for i := int64(0); i <= maxCoprCacheTableCounters; i++ {
+		recordCoprCacheStats(tablecodec.EncodeTablePrefix(base+i), false, false)
+	}
+	stats := GetCoprCacheTableStats()
+	require.Len(t, stats, maxCoprCacheTableCounters)
+	require.Equal(t, int64(base+1), stats[0].PhysicalID)
+	require.Equal(t, int64(base+maxCoprCacheTableCounters), stats[len(stats)-1].PhysicalID)
+}
@@ -17,6 +17,7 @@ package copr
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/pingcap/kvproto/pkg/coprocessor"
 	"github.com/pingcap/tidb/kv"
@@ -521,6 +522,27 @@ func TestBuildPagingTasks(t *testing.T) {
 	require.Equal(t, tasks[0].pagingSize, paging.MinPagingSize)
 }
 
+func TestNextPagingSize(t *testing.T) {
+	req := &kv.Request{}
+	req.Paging.Enable = true
+	req.Paging.MinPagingSize = paging.MinPagingSize
+	req.Paging.MaxPagingSize = paging.MaxPagingSize
+	req.Paging.MaxAggPagingSize = 1000
+	require.Equal(t, 2*paging.MinPagingSize, nextPagingSize(paging.MinPagingSize, req, false))
+	require.Equal(t, uint64(paging.MaxPagingSize), nextPagingSize(paging.MaxPagingSize, req, false))
+
+	// the paging size of the aggregation pushdown is limited
+	req.Paging.AggPushDown = true
+	require.Equal(t, uint64(1000), nextPagingSize(800, req, false))
+	require.Equal(t, uint64(400), nextPagingSize(200, req, false))
+
+	// the paging size is halved but not less than the min paging size when the memory exceeds the quota
+	require.Equal(t, uint64(400), nextPagingSize(800, req, true))
+	require.Equal(t, paging.MinPagingSize, nextPagingSize(200, req, true))
+	req.Paging.MinPagingSize = 0
+	require.Equal(t, uint64(1), nextPagingSize(1, req, true))
+}
+
 func toCopRange(r kv.KeyRange) *coprocessor.KeyRange {
 	coprRange := coprocessor.KeyRange{}
 	coprRange.Start = r.StartKey
@@ -655,3 +677,31 @@ func TestCalculateRemain(t *testing.T) {
 		rangeEqual(t, toRange(remain), "a", "c", "e", "g")
 	}
 }
+
+func TestRespBuffer(t *testing.T) {
+	require.Nil(t, newRespBuffer(0))
+	var nilBuffer *respBuffer
+	nilBuffer.add(&copResponse{})
+	require.False(t, nilBuffer.wait(nil))
+
+	buffer := newRespBuffer(100)
+	resp := &copResponse{pbResp: &coprocessor.Response{Data: make([]byte, 200)}}
+	buffer.add(resp)
+	finishCh := make(chan struct{})
+	done := make(chan bool)
+	go func() {
+		done <- buffer.wait(finishCh)
+	}()
+	select {
+	case <-done:
+		require.FailNow(t, "the sender doesn't wait for the buffered responses")
+	case <-time.After(100 * time.Millisecond):
+	}
+	buffer.release(resp)
+	require.False(t, <-done)
+
+	// the sender exits if the iterator is closed while waiting.
+	buffer.add(resp)
+	close(finishCh)
+	require.True(t, buffer.wait(finishCh))
+}
@@ -28,6 +28,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/docker/go-units"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/errorpb"
@@ -40,12 +41,14 @@ import (
 	"github.com/pingcap/tidb/ddl/util"
 	"github.com/pingcap/tidb/domain/infosync"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/metrics"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/session"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/store/helper"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/dbterror"
@@ -663,6 +666,13 @@ func (w *GCWorker) runGCJob(ctx context.Context, safePoint uint64, concurrency i
 	// Sleep to wait for all other tidb instances update their safepoint cache.
 	time.Sleep(gcSafePointCacheInterval)
 
+	err = w.purgeRecycleBin(ctx)
+	if err != nil {
+		// the tables are purged in the next round, it doesn't block the GC.
+		logutil.Logger(ctx).Warn("[gc worker] purge recycle bin returns an error",
+			zap.String("uuid", w.uuid),
+			zap.Error(err))
+	}
 	err = w.deleteRanges(ctx, safePoint, concurrency)
 	if err != nil {
 		logutil.Logger(ctx).Error("[gc worker] delete range returns an error",
@@ -773,6 +783,103 @@ func (w *GCWorker) deleteRanges(ctx context.Context, safePoint uint64, concurren
 	return nil
 }
 
+// purgeRecycleBin removes the dropped tables which are expired or beyond the
+// quota from the recycle bin, then their data is deleted by deleteRanges.
+func (w *GCWorker) purgeRecycleBin(ctx context.Context) error {
+	now, err := w.getOracleTime()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	retention := variable.RecycleBinRetention.Load()
+	quota := variable.RecycleBinQuota.Load()
+	ctx = kv.WithInternalSourceType(ctx, kv.InternalTxnGC)
+	return kv.RunInNewTxn(ctx, w.store, true, func(ctx context.Context, txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		entries, err := m.ListRecycleBin()
+		if err != nil || len(entries) == 0 {
+			return errors.Trace(err)
+		}
+		if quota > 0 {
+			w.fillRecycleBinEntrySizes(ctx, m, entries)
+		}
+		for _, entry := range recycleBinEntriesToPurge(entries, now, retention, quota) {
+			if err := m.DeleteRecycleBinEntry(entry.JobID); err != nil {
+				return errors.Trace(err)
+			}
+			logutil.Logger(ctx).Info("[gc worker] purge table from recycle bin",
+				zap.String("uuid", w.uuid),
+				zap.Int64("jobID", entry.JobID),
+				zap.String("schema", entry.SchemaName),
+				zap.String("table", entry.TableInfo.Name.O),
+				zap.Int64("size", entry.Size))
+		}
+		return nil
+	})
+}
+
+// fillRecycleBinEntrySizes gets the unknown sizes of the tables from PD. The
+// sizes are kept in the recycle bin as the data of the tables doesn't change.
+func (w *GCWorker) fillRecycleBinEntrySizes(ctx context.Context, m *meta.Meta, entries []*meta.RecycleBinEntry) {
+	store, ok := w.store.(helper.Storage)
+	if !ok {
+		return
+	}
+	h := helper.NewHelper(store)
+	for _, entry := range entries {
+		if entry.Size > 0 {
+			continue
+		}
+		ids := []int64{entry.TableInfo.ID}
+		if pi := entry.TableInfo.GetPartitionInfo(); pi != nil {
+			ids = ids[:0]
+			for _, def := range pi.Definitions {
+				ids = append(ids, def.ID)
+			}
+		}
+		var size int64
+		for _, id := range ids {
+			var stats helper.PDRegionStats
+			if err := h.GetPDRegionStats(id, &stats, false); err != nil {
+				logutil.Logger(ctx).Warn("[gc worker] failed to get the size of the table in recycle bin",
+					zap.String("uuid", w.uuid),
+					zap.Int64("tableID", id),
+					zap.Error(err))
+				return
+			}
+			// the storage size from PD is in MiB.
+			size += stats.StorageSize * units.MiB
+		}
+		entry.Size = size
+		if err := m.AddRecycleBinEntry(entry); err != nil {
+			logutil.Logger(ctx).Warn("[gc worker] failed to save the size of the table in recycle bin",
+				zap.String("uuid", w.uuid),
+				zap.Int64("jobID", entry.JobID),
+				zap.Error(err))
+			return
+		}
+	}
+}
+
+// recycleBinEntriesToPurge returns the entries which are expired, and the oldest
+// entries which make the total size beyond the quota. The entries are ordered
+// from the oldest to the newest.
+func recycleBinEntriesToPurge(entries []*meta.RecycleBinEntry, now time.Time, retention time.Duration, quota uint64) []*meta.RecycleBinEntry {
+	var purged []*meta.RecycleBinEntry
+	var total uint64
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if model.TSConvert2Time(entry.DropTS).Add(retention).Before(now) {
+			purged = append(purged, entry)
+			continue
+		}
+		total += uint64(entry.Size)
+		if quota > 0 && total > quota {
+			purged = append(purged, entry)
+		}
+	}
+	return purged
+}
+
 // redoDeleteRanges checks all deleted ranges whose ts is at least `lifetime + 24h` ago. See TiKV RFC #2.
 // `concurrency` specifies the concurrency to send NotifyDeleteRange.
 func (w *GCWorker) redoDeleteRanges(ctx context.Context, safePoint uint64, concurrency int) error {
@@ -36,8 +36,10 @@ import (
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/domain/infosync"
 	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/session"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/mockstore"
 	"github.com/stretchr/testify/require"
 	"github.com/tikv/client-go/v2/oracle"
@@ -813,6 +815,91 @@ Loop:
 	}
 }
 
+func TestRecycleBinEntriesToPurge(t *testing.T) {
+	now := time.Unix(100000, 0)
+	newEntry := func(jobID int64, dropTime time.Time, size int64) *meta.RecycleBinEntry {
+		return &meta.RecycleBinEntry{JobID: jobID, DropTS: oracle.GoTimeToTS(dropTime), Size: size}
+	}
+	entries := []*meta.RecycleBinEntry{
+		newEntry(1, now.Add(-3*time.Hour), 100),
+		newEntry(2, now.Add(-2*time.Hour), 100),
+		newEntry(3, now.Add(-30*time.Minute), 300),
+		newEntry(4, now.Add(-10*time.Minute), 0),
+		newEntry(5, now.Add(-time.Minute), 200),
+	}
+	jobIDs := func(entries []*meta.RecycleBinEntry) []int64 {
+		ids := make([]int64, 0, len(entries))
+		for _, entry := range entries {
+			ids = append(ids, entry.JobID)
+		}
+		return ids
+	}
+	require.Empty(t, recycleBinEntriesToPurge(entries, now, 4*time.Hour, 0))
+	require.Equal(t, []int64{2, 1}, jobIDs(recycleBinEntriesToPurge(entries, now, time.Hour, 0)))
+	// the newest tables are kept within the quota.
+	require.Empty(t, recycleBinEntriesToPurge(entries, now, 4*time.Hour, 700))
+	require.Equal(t, []int64{1}, jobIDs(recycleBinEntriesToPurge(entries, now, 4*time.Hour, 600)))
+	require.Equal(t, []int64{3, 2, 1}, jobIDs(recycleBinEntriesToPurge(entries, now, 4*time.Hour, 400)))
+	// the expired tables are not counted in the quota.
+	require.Equal(t, []int64{1}, jobIDs(recycleBinEntriesToPurge(entries, now, 150*time.Minute, 600)))
+	require.Equal(t, []int64{5, 4, 3, 2, 1}, jobIDs(recycleBinEntriesToPurge(entries, now, 4*time.Hour, 100)))
+	// the recycle bin is disabled.
+	require.Len(t, recycleBinEntriesToPurge(entries, now, 0, 0), 5)
+}
+
+func TestPurgeRecycleBin(t *testing.T) {
+	s := createGCWorkerSuite(t)
+	defer variable.RecycleBinRetention.Store(variable.DefTiDBRecycleBinRetention)
+
+	now, err := s.gcWorker.getOracleTime()
+	require.NoError(t, err)
+	err = kv.RunInNewTxn(gcContext(), s.store, false, func(ctx context.Context, txn kv.Transaction) error {
+		m := meta.NewMeta(txn)
+		for i, dropTime := range []time.Time{now.Add(-2 * time.Hour), now.Add(-time.Minute)} {
+			err := m.AddRecycleBinEntry(&meta.RecycleBinEntry{
+				JobID:     int64(i + 1),
+				TableInfo: &model.TableInfo{ID: int64(i + 10), Name: model.NewCIStr(fmt.Sprintf("t%d", i))},
+				DropTS:    oracle.GoTimeToTS(dropTime),
+				Size:      100,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	se := createSession(s.gcWorker.store)
+	defer se.Close()
+	_, err = se.Execute(gcContext(), `INSERT INTO mysql.gc_delete_range VALUES
+("1", "1", "31", "32", "10"),
+("2", "1", "33", "34", "10"),
+("3", "1", "35", "36", "10")`)
+	require.NoError(t, err)
+	// the ranges of the tables in the recycle bin are skipped.
+	ranges, err := util.LoadDeleteRanges(gcContext(), se, math.MaxInt64)
+	require.NoError(t, err)
+	require.Len(t, ranges, 1)
+	require.Equal(t, int64(3), ranges[0].JobID)
+
+	variable.RecycleBinRetention.Store(time.Hour)
+	require.NoError(t, s.gcWorker.purgeRecycleBin(gcContext()))
+	ranges, err = util.LoadDeleteRanges(gcContext(), se, math.MaxInt64)
+	require.NoError(t, err)
+	require.Len(t, ranges, 2)
+	require.Equal(t, int64(1), ranges[0].JobID)
+	require.Equal(t, int64(3), ranges[1].JobID)
+
+	err = kv.RunInNewTxn(gcContext(), s.store, false, func(ctx context.Context, txn kv.Transaction) error {
+		entries, err := meta.NewMeta(txn).ListRecycleBin()
+		require.Len(t, entries, 1)
+		require.Equal(t, int64(2), entries[0].JobID)
+		return err
+	})
+	require.NoError(t, err)
+}
+
 func TestLeaderTick(t *testing.T) {
 	s := createGCWorkerSuite(t)
 
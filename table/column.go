@@ -487,6 +487,10 @@ func CheckNotNull(cols []*Column, row []types.Datum) error {
 
 // GetColOriginDefaultValue gets default value of the column from original default value.
 func GetColOriginDefaultValue(ctx sessionctx.Context, col *model.ColumnInfo) (types.Datum, error) {
+	// The lazy default values depend on the rows, they are read as NULLs and evaluated later from the handles.
+	if col.HasLazyOriginDefault() {
+		return types.Datum{}, nil
+	}
 	return getColDefaultValue(ctx, col, col.GetOriginDefaultValue())
 }
 
@@ -26,6 +26,7 @@ import (
 	mysql "github.com/pingcap/tidb/errno"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta/autoid"
+	"github.com/pingcap/tidb/parser/ast"
 	"github.com/pingcap/tidb/parser/model"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/types"
@@ -92,6 +93,8 @@ var (
 	ErrTruncatedWrongValueForField = dbterror.ClassTable.NewStd(mysql.ErrTruncatedWrongValueForField)
 	// ErrUnknownPartition returns unknown partition error.
 	ErrUnknownPartition = dbterror.ClassTable.NewStd(mysql.ErrUnknownPartition)
+	// ErrCheckConstraintViolated returns when a row violates a check constraint.
+	ErrCheckConstraintViolated = dbterror.ClassTable.NewStd(mysql.ErrCheckConstraintViolated)
 	// ErrNoPartitionForGivenValue returns table has no partition for value.
 	ErrNoPartitionForGivenValue = dbterror.ClassTable.NewStd(mysql.ErrNoPartitionForGivenValue)
 	// ErrLockOrActiveTransaction returns when execute unsupported statement in a lock session or an active transaction.
@@ -252,6 +255,13 @@ var TableFromMeta func(allocators autoid.Allocators, tblInfo *model.TableInfo) (
 // MockTableFromMeta only serves for test.
 var MockTableFromMeta func(tableInfo *model.TableInfo) Table
 
+// CheckConstraintTable is a Table whose enforced CHECK constraints are built once it's loaded.
+type CheckConstraintTable interface {
+	// CheckConstraintExpr returns the parsed expression of the enforced CHECK constraint, or nil if the
+	// constraint isn't enforced. The expression is shared by the sessions and mustn't be modified.
+	CheckConstraintExpr(id int64) ast.ExprNode
+}
+
 // CachedTable is a Table, and it has a UpdateLockForRead() method
 // UpdateLockForRead() according to the reasons for not meeting the read conditions, update the lock information,
 // And at the same time reload data from the original table.
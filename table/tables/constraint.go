@@ -0,0 +1,90 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tables
+
+import (
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/parser/ast"
+	"github.com/pingcap/tidb/parser/model"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/chunk"
+	"github.com/pingcap/tidb/util/generatedexpr"
+	"github.com/pingcap/tidb/util/mock"
+)
+
+// checkConstraint is a CHECK constraint with its expression built on the columns of the table.
+type checkConstraint struct {
+	*model.ConstraintInfo
+	astExpr ast.ExprNode
+	expr    expression.Expression
+}
+
+// loadCheckConstraints builds the CHECK constraints enforced on the written rows, i.e. the enforced
+// ones in the write-only or the public state.
+func loadCheckConstraints(tblInfo *model.TableInfo) ([]*checkConstraint, error) {
+	var ctx sessionctx.Context
+	constraints := make([]*checkConstraint, 0, len(tblInfo.Constraints))
+	for _, conInfo := range tblInfo.Constraints {
+		if !conInfo.Enforced || (conInfo.State != model.StateWriteOnly && conInfo.State != model.StatePublic) {
+			continue
+		}
+		if ctx == nil {
+			ctx = mock.NewContext()
+		}
+		astExpr, err := generatedexpr.ParseExpression(conInfo.ExprString)
+		if err != nil {
+			return nil, errors.Annotatef(err, "failed to parse check constraint %s", conInfo.Name.O)
+		}
+		expr, err := expression.RewriteSimpleExprWithTableInfo(ctx, tblInfo, astExpr)
+		if err != nil {
+			return nil, errors.Annotatef(err, "failed to build check constraint %s", conInfo.Name.O)
+		}
+		constraints = append(constraints, &checkConstraint{ConstraintInfo: conInfo, astExpr: astExpr, expr: expr})
+	}
+	return constraints, nil
+}
+
+// CheckConstraintExpr implements table.CheckConstraintTable.
+func (t *TableCommon) CheckConstraintExpr(id int64) ast.ExprNode {
+	for _, con := range t.constraints {
+		if con.ID == id {
+			return con.astExpr
+		}
+	}
+	return nil
+}
+
+// CheckRowConstraint checks whether the row satisfies the enforced CHECK constraints of the table.
+// A constraint is satisfied unless its expression is evaluated to false, so NULL satisfies it.
+func (t *TableCommon) CheckRowConstraint(sctx sessionctx.Context, row []types.Datum) error {
+	if len(t.constraints) == 0 || !variable.EnableCheckConstraint.Load() {
+		return nil
+	}
+	chkRow := chunk.MutRowFromDatums(row).ToRow()
+	for _, con := range t.constraints {
+		val, isNull, err := con.expr.EvalInt(sctx, chkRow)
+		if err != nil {
+			return err
+		}
+		if !isNull && val == 0 {
+			return table.ErrCheckConstraintViolated.FastGenByArgs(con.Name.O)
+		}
+	}
+	return nil
+}
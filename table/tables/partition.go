@@ -107,6 +107,7 @@ func newPartitionedTable(tbl *TableCommon, tblInfo *model.TableInfo) (table.Tabl
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		t.constraints = tbl.constraints
 		partitions[p.ID] = &t
 	}
 	ret.partitions = partitions
@@ -29,6 +29,7 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/failpoint"
+	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/meta/autoid"
@@ -69,6 +70,7 @@ type TableCommon struct {
 	meta                            *model.TableInfo
 	allocs                          autoid.Allocators
 	sequence                        *sequenceCommon
+	constraints                     []*checkConstraint
 
 	// recordPrefix and indexPrefix are generated using physicalTableID.
 	recordPrefix kv.Key
@@ -149,6 +151,11 @@ func TableFromMeta(allocs autoid.Allocators, tblInfo *model.TableInfo) (table.Ta
 
 	var t TableCommon
 	initTableCommon(&t, tblInfo, tblInfo.ID, columns, allocs)
+	constraints, err := loadCheckConstraints(tblInfo)
+	if err != nil {
+		return nil, err
+	}
+	t.constraints = constraints
 	if tblInfo.GetPartitionInfo() == nil {
 		if err := initTableIndices(&t); err != nil {
 			return nil, err
@@ -334,6 +341,9 @@ func (t *TableCommon) UpdateRecord(ctx context.Context, sctx sessionctx.Context,
 	if err != nil {
 		return err
 	}
+	if err = t.CheckRowConstraint(sctx, newData); err != nil {
+		return err
+	}
 
 	memBuffer := txn.GetMemBuffer()
 	sh := memBuffer.Staging()
@@ -557,6 +567,16 @@ func FindPrimaryIndex(tblInfo *model.TableInfo) *model.IndexInfo {
 	return pkIdx
 }
 
+// HandleKeyName returns the key name used in the duplicate entry error of the handle.
+func HandleKeyName(tblInfo *model.TableInfo) string {
+	if tblInfo.IsCommonHandle {
+		if pkIdx := FindPrimaryIndex(tblInfo); pkIdx != nil && pkIdx.IsClusteredUniqueKey() {
+			return pkIdx.Name.String()
+		}
+	}
+	return "PRIMARY"
+}
+
 // CommonAddRecordCtx is used in `AddRecord` to avoid memory malloc for some temp slices.
 // This is useful in lightning parse row data to key-values pairs. This can gain upto 5%  performance
 // improvement in lightning's local mode.
@@ -676,6 +696,9 @@ func (t *TableCommon) AddRecord(sctx sessionctx.Context, r []types.Datum, opts .
 	for _, fn := range opts {
 		fn.ApplyOn(&opt)
 	}
+	if err = t.CheckRowConstraint(sctx, r); err != nil {
+		return nil, err
+	}
 
 	if m := t.Meta(); m.TempTableType != model.TempTableNone {
 		if tmpTable := addTemporaryTable(sctx, m); tmpTable != nil {
@@ -840,7 +863,7 @@ func (t *TableCommon) AddRecord(sctx sessionctx.Context, r []types.Datum, opts .
 		}
 		if err == nil {
 			handleStr := getDuplicateErrorHandleString(t, recordID, r)
-			return recordID, kv.ErrKeyExists.FastGenByArgs(handleStr, "PRIMARY")
+			return recordID, kv.ErrKeyExists.FastGenByArgs(handleStr, HandleKeyName(t.meta))
 		} else if !kv.ErrNotExist.Equal(err) {
 			return recordID, err
 		}
@@ -1075,9 +1098,9 @@ func DecodeRawRowData(ctx sessionctx.Context, meta *model.TableInfo, h kv.Handle
 			continue
 		}
 		if col.ChangeStateInfo != nil {
-			v[i], _, err = GetChangingColVal(ctx, cols, col, rowMap, defaultVals)
+			v[i], _, err = GetChangingColVal(ctx, cols, col, h, rowMap, defaultVals)
 		} else {
-			v[i], err = GetColDefaultValue(ctx, col, defaultVals)
+			v[i], err = GetColDefaultValue(ctx, col, h, defaultVals)
 		}
 		if err != nil {
 			return nil, rowMap, err
@@ -1092,9 +1115,17 @@ func DecodeRawRowData(ctx sessionctx.Context, meta *model.TableInfo, h kv.Handle
 // old row : a-b-[nil]
 // new row : a-b-[a'/default]
 // Thus the writable new row is corresponding to Write-Only constraints.
-func GetChangingColVal(ctx sessionctx.Context, cols []*table.Column, col *table.Column, rowMap map[int64]types.Datum, defaultVals []types.Datum) (_ types.Datum, isDefaultVal bool, err error) {
+func GetChangingColVal(ctx sessionctx.Context, cols []*table.Column, col *table.Column, h kv.Handle, rowMap map[int64]types.Datum, defaultVals []types.Datum) (_ types.Datum, isDefaultVal bool, err error) {
 	relativeCol := cols[col.ChangeStateInfo.DependencyColumnOffset]
 	idxColumnVal, ok := rowMap[relativeCol.ID]
+	if !ok && relativeCol.HasLazyOriginDefault() {
+		// The origin default value of the changing column can't represent the lazy values of the relative column.
+		idxColumnVal, err = getColLazyDefaultValue(ctx, relativeCol, h)
+		if err != nil {
+			return idxColumnVal, false, errors.Trace(err)
+		}
+		ok = true
+	}
 	if ok {
 		idxColumnVal, err = table.CastValue(ctx, idxColumnVal, col.ColumnInfo, false, false)
 		// TODO: Consider sql_mode and the error msg(encounter this error check whether to rollback).
@@ -1104,7 +1135,7 @@ func GetChangingColVal(ctx sessionctx.Context, cols []*table.Column, col *table.
 		return idxColumnVal, false, nil
 	}
 
-	idxColumnVal, err = GetColDefaultValue(ctx, col, defaultVals)
+	idxColumnVal, err = GetColDefaultValue(ctx, col, h, defaultVals)
 	if err != nil {
 		return idxColumnVal, false, errors.Trace(err)
 	}
@@ -1417,7 +1448,7 @@ func IterRecords(t table.Table, ctx sessionctx.Context, cols []*table.Column,
 				data[col.Offset] = rowMap[col.ID]
 				continue
 			}
-			data[col.Offset], err = GetColDefaultValue(ctx, col, defaultVals)
+			data[col.Offset], err = GetColDefaultValue(ctx, col, handle, defaultVals)
 			if err != nil {
 				return err
 			}
@@ -1454,10 +1485,13 @@ func tryDecodeColumnFromCommonHandle(col *table.Column, handle kv.Handle, pkIds
 	return types.Datum{}, nil
 }
 
-// GetColDefaultValue gets a column default value.
+// GetColDefaultValue gets a column default value of the row of handle h.
 // The defaultVals is used to avoid calculating the default value multiple times.
-func GetColDefaultValue(ctx sessionctx.Context, col *table.Column, defaultVals []types.Datum) (
+func GetColDefaultValue(ctx sessionctx.Context, col *table.Column, h kv.Handle, defaultVals []types.Datum) (
 	colVal types.Datum, err error) {
+	if col.HasLazyOriginDefault() {
+		return getColLazyDefaultValue(ctx, col, h)
+	}
 	if col.GetOriginDefaultValue() == nil && mysql.HasNotNullFlag(col.GetFlag()) {
 		return colVal, errors.New("Miss column")
 	}
@@ -1474,6 +1508,18 @@ func GetColDefaultValue(ctx sessionctx.Context, col *table.Column, defaultVals [
 	return colVal, nil
 }
 
+// getColLazyDefaultValue gets the lazily evaluated default value of the column in the row of handle h.
+func getColLazyDefaultValue(ctx sessionctx.Context, col *table.Column, h kv.Handle) (types.Datum, error) {
+	if h == nil || !h.IsInt() {
+		return types.Datum{}, errors.Errorf("the lazy default value of column %s needs an int handle", col.Name.O)
+	}
+	d, err := expression.LazyDefaultValue(col.OriginDefaultFunc, col.OriginDefaultSeed, h.IntValue())
+	if err != nil {
+		return d, err
+	}
+	return table.CastValue(ctx, d, col.ToInfo(), false, false)
+}
+
 // AllocHandle allocate a new handle.
 // A statement could reserve some ID in the statement context, try those ones first.
 func AllocHandle(ctx context.Context, sctx sessionctx.Context, t table.Table) (kv.Handle, error) {
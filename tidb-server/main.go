@@ -19,6 +19,7 @@ import (
 	"flag"
 	"fmt"
 	"io/fs"
+	"net"
 	"os"
 	"runtime"
 	"strconv"
@@ -32,6 +33,7 @@ import (
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/log"
 	"github.com/pingcap/tidb/bindinfo"
+	"github.com/pingcap/tidb/br/pkg/lightning/insertingest"
 	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/domain"
@@ -67,6 +69,7 @@ import (
 	"github.com/pingcap/tidb/util/printer"
 	"github.com/pingcap/tidb/util/sem"
 	"github.com/pingcap/tidb/util/signal"
+	"github.com/pingcap/tidb/util/spill"
 	"github.com/pingcap/tidb/util/sys/linux"
 	storageSys "github.com/pingcap/tidb/util/sys/storage"
 	"github.com/pingcap/tidb/util/systimemon"
@@ -181,11 +184,20 @@ func main() {
 	}
 	registerStores()
 	registerMetrics()
+	insertingest.Register()
 	if variable.EnableTmpStorageOnOOM.Load() {
+		// The tmp-storage-path is still used by the others such as the memory
+		// usage alarm records when the results are spilled to tmp-storage-url.
 		config.GetGlobalConfig().UpdateTempStoragePath()
 		err := disk.InitializeTempDir()
 		terror.MustNil(err)
-		checkTempStorageQuota()
+		if cfg := config.GetGlobalConfig(); len(cfg.TempStorageURL) > 0 {
+			instance := net.JoinHostPort(cfg.AdvertiseAddress, strconv.Itoa(int(cfg.Port)))
+			err := spill.Setup(context.Background(), cfg.TempStorageURL, instance)
+			terror.MustNil(err)
+		} else {
+			checkTempStorageQuota()
+		}
 	}
 	setupLog()
 	err := cpuprofile.StartCPUProfiler()
@@ -16,7 +16,6 @@ package chunk
 
 import (
 	"io"
-	"os"
 	"strconv"
 
 	errors2 "github.com/pingcap/errors"
@@ -43,7 +42,7 @@ type ListInDisk struct {
 
 // diskFileReaderWriter represents a Reader and a Writer for the temporary disk file.
 type diskFileReaderWriter struct {
-	disk *os.File
+	disk disk.TempFile
 	w    io.WriteCloser
 	// offWrite is the current offset for writing.
 	offWrite int64
@@ -56,7 +55,7 @@ type diskFileReaderWriter struct {
 }
 
 func (l *diskFileReaderWriter) initWithFileName(fileName string) (err error) {
-	l.disk, err = os.CreateTemp(config.GetGlobalConfig().TempStoragePath, fileName)
+	l.disk, err = disk.CreateTempFile(fileName)
 	if err != nil {
 		return errors2.Trace(err)
 	}
@@ -110,10 +109,6 @@ func NewListInDisk(fieldTypes []*types.FieldType) *ListInDisk {
 }
 
 func (l *ListInDisk) initDiskFile() (err error) {
-	err = disk.CheckAndInitTempDir()
-	if err != nil {
-		return
-	}
 	err = l.dataFile.initWithFileName(defaultChunkListInDiskPath + strconv.Itoa(l.diskTracker.Label()))
 	if err != nil {
 		return
@@ -232,11 +227,9 @@ func (l *ListInDisk) Close() error {
 	if l.dataFile.disk != nil {
 		l.diskTracker.Consume(-l.diskTracker.BytesConsumed())
 		terror.Call(l.dataFile.disk.Close)
-		terror.Log(os.Remove(l.dataFile.disk.Name()))
 	}
 	if l.offsetFile.disk != nil {
 		terror.Call(l.offsetFile.disk.Close)
-		terror.Log(os.Remove(l.offsetFile.disk.Name()))
 	}
 	return nil
 }
@@ -316,6 +316,8 @@ var (
 	ErrTableOptionUnionUnsupported = ClassDDL.NewStd(mysql.ErrTableOptionUnionUnsupported)
 	// ErrTableOptionInsertMethodUnsupported is returned when create/alter table with insert method option.
 	ErrTableOptionInsertMethodUnsupported = ClassDDL.NewStd(mysql.ErrTableOptionInsertMethodUnsupported)
+	// ErrForeignDataStringInvalidCantCreate is returned when create a federated table with an invalid connection string.
+	ErrForeignDataStringInvalidCantCreate = ClassDDL.NewStd(mysql.ErrForeignDataStringInvalidCantCreate)
 
 	// ErrInvalidPlacementPolicyCheck is returned when txn_scope and commit data changing do not meet the placement policy
 	ErrInvalidPlacementPolicyCheck = ClassDDL.NewStd(mysql.ErrPlacementPolicyCheck)
@@ -367,6 +369,28 @@ var (
 	ErrDependentByFunctionalIndex = ClassDDL.NewStd(mysql.ErrDependentByFunctionalIndex)
 	// ErrFunctionalIndexOnBlob when the expression of expression index returns blob or text.
 	ErrFunctionalIndexOnBlob = ClassDDL.NewStd(mysql.ErrFunctionalIndexOnBlob)
+	// ErrNonBooleanExprForCheckConstraint returns when the expression of a check constraint isn't boolean.
+	ErrNonBooleanExprForCheckConstraint = ClassDDL.NewStd(mysql.ErrNonBooleanExprForCheckConstraint)
+	// ErrColumnCheckConstraintReferencesOtherColumn returns when a column check constraint references other columns.
+	ErrColumnCheckConstraintReferencesOtherColumn = ClassDDL.NewStd(mysql.ErrColumnCheckConstraintReferencesOtherColumn)
+	// ErrCheckConstraintNamedFunctionIsNotAllowed returns when a check constraint uses a non-deterministic function.
+	ErrCheckConstraintNamedFunctionIsNotAllowed = ClassDDL.NewStd(mysql.ErrCheckConstraintNamedFunctionIsNotAllowed)
+	// ErrCheckConstraintFunctionIsNotAllowed returns when a check constraint uses a subquery or a stored function.
+	ErrCheckConstraintFunctionIsNotAllowed = ClassDDL.NewStd(mysql.ErrCheckConstraintFunctionIsNotAllowed)
+	// ErrCheckConstraintVariables returns when a check constraint refers to a variable.
+	ErrCheckConstraintVariables = ClassDDL.NewStd(mysql.ErrCheckConstraintVariables)
+	// ErrCheckConstraintRefersAutoIncrementColumn returns when a check constraint refers to an auto-increment column.
+	ErrCheckConstraintRefersAutoIncrementColumn = ClassDDL.NewStd(mysql.ErrCheckConstraintRefersAutoIncrementColumn)
+	// ErrCheckConstraintIsViolated returns when the existing rows violate the check constraint.
+	ErrCheckConstraintIsViolated = ClassDDL.NewStd(mysql.ErrCheckConstraintViolated)
+	// ErrCheckConstraintRefersUnknownColumn returns when a check constraint refers to a non-existing column.
+	ErrCheckConstraintRefersUnknownColumn = ClassDDL.NewStd(mysql.ErrCheckConstraintRefersUnknownColumn)
+	// ErrCheckConstraintNotFound returns when the check constraint to alter or drop doesn't exist.
+	ErrCheckConstraintNotFound = ClassDDL.NewStd(mysql.ErrCheckConstraintNotFound)
+	// ErrCheckConstraintDupName returns when the name of a check constraint is duplicated.
+	ErrCheckConstraintDupName = ClassDDL.NewStd(mysql.ErrCheckConstraintDupName)
+	// ErrDependentByCheckConstraint returns when the dropped or renamed column is used by a check constraint.
+	ErrDependentByCheckConstraint = ClassDDL.NewStd(mysql.ErrDependentByCheckConstraint)
 
 	// ErrUnsupportedAlterTableSpec means we don't support this alter table specification (i.e. unknown)
 	ErrUnsupportedAlterTableSpec = ClassDDL.NewStdErr(mysql.ErrUnsupportedDDLOperation, parser_mysql.Message(fmt.Sprintf(mysql.MySQLErrName[mysql.ErrUnsupportedDDLOperation].Raw, "Unsupported/unknown ALTER TABLE specification"), nil))
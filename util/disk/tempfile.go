@@ -0,0 +1,72 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disk
+
+import (
+	"io"
+	"os"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/config"
+	"github.com/pingcap/tidb/parser/terror"
+)
+
+// TempFile is a temporary file which the executors spill the data to.
+type TempFile interface {
+	io.Writer
+	io.ReaderAt
+	// Name returns the name of the file.
+	Name() string
+	// Close closes the file and removes it.
+	Close() error
+}
+
+// TempFileCreator creates a temporary file whose name begins with pattern.
+type TempFileCreator func(pattern string) (TempFile, error)
+
+var tempFileCreator TempFileCreator
+
+// SetTempFileCreator makes the temporary files created by creator instead of
+// in the tmp-storage-path. It should be called before any data is spilled.
+func SetTempFileCreator(creator TempFileCreator) {
+	tempFileCreator = creator
+}
+
+// CreateTempFile creates a temporary file to spill the data.
+func CreateTempFile(pattern string) (TempFile, error) {
+	if tempFileCreator != nil {
+		return tempFileCreator(pattern)
+	}
+	if err := CheckAndInitTempDir(); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(config.GetGlobalConfig().TempStoragePath, pattern)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &localTempFile{f}, nil
+}
+
+// localTempFile is a temporary file in the tmp-storage-path.
+type localTempFile struct {
+	*os.File
+}
+
+// Close implements the TempFile interface.
+func (f *localTempFile) Close() error {
+	err := f.File.Close()
+	terror.Log(os.Remove(f.Name()))
+	return err
+}
@@ -372,6 +372,11 @@ type CopRuntimeStats struct {
 	storeType string
 }
 
+// GetScanDetail returns the scan detail of the coprocessor tasks.
+func (crs *CopRuntimeStats) GetScanDetail() *util.ScanDetail {
+	return crs.scanDetail
+}
+
 // RecordOneCopTask records a specific cop tasks's execution detail.
 func (crs *CopRuntimeStats) RecordOneCopTask(address string, summary *tipb.ExecutorExecutionSummary) {
 	crs.Lock()
@@ -71,6 +71,14 @@ func (eqh *Handle) Run() {
 					logExpensiveQuery(costTime, info)
 					info.ExceedExpensiveTimeThresh = true
 				}
+				if info.StatementTimeout > 0 && costTime > time.Duration(info.StatementTimeout)*time.Millisecond {
+					logutil.BgLogger().Warn("statement timeout, kill it", zap.Duration("costTime", costTime),
+						zap.Duration("statementTimeout", time.Duration(info.StatementTimeout)*time.Millisecond), zap.String("processInfo", info.String()))
+					if info.StmtCtx != nil {
+						info.StmtCtx.SetStmtTimeoutExceeded()
+					}
+					sm.Kill(info.ID, true)
+				}
 				if info.MaxExecutionTime > 0 && costTime > time.Duration(info.MaxExecutionTime)*time.Millisecond {
 					logutil.BgLogger().Warn("execution timeout, kill it", zap.Duration("costTime", costTime),
 						zap.Duration("maxExecutionTime", time.Duration(info.MaxExecutionTime)*time.Millisecond), zap.String("processInfo", info.String()))
@@ -705,6 +705,8 @@ const (
 	LabelForAnalyzeMemory int = -24
 	// LabelForGlobalAnalyzeMemory represents the label of the global memory of all analyze jobs
 	LabelForGlobalAnalyzeMemory int = -25
+	// LabelForResultChunk represents the label of the result chunk written to the client
+	LabelForResultChunk int = -26
 )
 
 // MetricsTypes is used to get label for metrics
@@ -52,6 +52,9 @@ type ProcessInfo struct {
 	Command                   byte
 	ExceedExpensiveTimeThresh bool
 	RedactSQL                 bool
+	// StatementTimeout is the timeout for any statement including its commit, in milliseconds.
+	// If the statement takes too long, kill it with ErrStmtTimeoutExceeded.
+	StatementTimeout uint64
 }
 
 // ToRowForShow returns []interface{} for the row data of "SHOW [FULL] PROCESSLIST".
@@ -147,6 +147,24 @@ func (r *RouteTable) Route(schema, table string) (targetSchema string, targetTab
 	return targetSchema, targetTable, nil
 }
 
+// Match returns whether the table is routed by any rule. Only the schema rules
+// are considered if the table name is empty, which is the same as Route.
+func (r *RouteTable) Match(schema, table string) bool {
+	curTable := &filter.Table{
+		Schema: schema,
+		Name:   table,
+	}
+	for _, filterWrapper := range r.filters {
+		if table == "" && filterWrapper.typ == TblFilter {
+			continue
+		}
+		if filterWrapper.filter.Match(curTable) {
+			return true
+		}
+	}
+	return false
+}
+
 // AllRules is to get all rules
 func (r *RouteTable) AllRules() ([]router.TableRule, []router.TableRule) {
 	var (
@@ -377,3 +377,26 @@ func TestDupMatch(t *testing.T) {
 		require.Regexp(t, ".*matches more than one rule.*", err.Error())
 	}
 }
+
+func TestMatch(t *testing.T) {
+	rules := []*router.TableRule{
+		{
+			SchemaPattern: "test*",
+			TargetSchema:  "dtest",
+		},
+		{
+			SchemaPattern: "~^db[0-9]$",
+			TablePattern:  "tbl*",
+			TargetSchema:  "db",
+			TargetTable:   "tbl",
+		},
+	}
+	r, err := NewRegExprRouter(false, rules)
+	require.NoError(t, err)
+	require.True(t, r.Match("test1", ""))
+	require.True(t, r.Match("TEST1", "t"))
+	require.True(t, r.Match("db1", "tbl1"))
+	require.False(t, r.Match("db1", "t1"))
+	require.False(t, r.Match("db1", ""))
+	require.False(t, r.Match("db", "tbl1"))
+}
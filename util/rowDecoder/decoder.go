@@ -103,10 +103,10 @@ func (rd *RowDecoder) DecodeAndEvalRowWithMap(ctx sessionctx.Context, handle kv.
 			continue
 		}
 		if dCol.Col.ChangeStateInfo != nil {
-			val, _, err = tables.GetChangingColVal(ctx, rd.cols, dCol.Col, row, rd.defaultVals)
+			val, _, err = tables.GetChangingColVal(ctx, rd.cols, dCol.Col, handle, row, rd.defaultVals)
 		} else {
 			// Get the default value of the column in the generated column expression.
-			val, err = tables.GetColDefaultValue(ctx, dCol.Col, rd.defaultVals)
+			val, err = tables.GetColDefaultValue(ctx, dCol.Col, handle, rd.defaultVals)
 		}
 		if err != nil {
 			return nil, err
@@ -160,7 +160,7 @@ func (rd *RowDecoder) DecodeTheExistedColumnMap(ctx sessionctx.Context, handle k
 			continue
 		}
 		// Get the default value of the column in the generated column expression.
-		val, err = tables.GetColDefaultValue(ctx, dCol.Col, rd.defaultVals)
+		val, err = tables.GetColDefaultValue(ctx, dCol.Col, handle, rd.defaultVals)
 		if err != nil {
 			return nil, err
 		}
@@ -32,6 +32,7 @@ const (
 	optRuleBlacklist      = "opt_rule_blacklist"
 	tidb                  = "tidb"
 	globalVariables       = "global_variables"
+	servers               = "servers"
 	informationSchema     = "information_schema"
 	clusterConfig         = "cluster_config"
 	clusterHardware       = "cluster_hardware"
@@ -105,7 +106,7 @@ func IsInvisibleTable(dbLowerName, tblLowerName string) bool {
 	switch dbLowerName {
 	case mysql.SystemDB:
 		switch tblLowerName {
-		case exprPushdownBlacklist, gcDeleteRange, gcDeleteRangeDone, optRuleBlacklist, tidb, globalVariables:
+		case exprPushdownBlacklist, gcDeleteRange, gcDeleteRangeDone, optRuleBlacklist, tidb, globalVariables, servers:
 			return true
 		}
 	case informationSchema:
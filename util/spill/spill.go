@@ -0,0 +1,264 @@
+// Copyright 2022 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package spill makes the executors spill the temporary results to an
+// external storage such as S3, GCS or Azure Blob storage instead of the local
+// disk, which may be small or missing on the serverless nodes.
+package spill
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tidb/util/disk"
+	"github.com/pingcap/tidb/util/logutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultSegmentSize is the size of each object uploaded for a file.
+	defaultSegmentSize = 4 << 20
+	// cachedSegments is the number of segments cached for reading a file.
+	cachedSegments = 2
+	// staleFileTTL is how long the files left by other instances are kept,
+	// they may be still in use if they are newer.
+	staleFileTTL = 24 * time.Hour
+	// nameSep separates the instance, the run and the file in the object names.
+	nameSep = "_"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^0-9A-Za-z.\-]`)
+
+// Storage creates the temporary files in an external storage. A file is
+// stored as the objects named "<instance>_<run>_<file>_<segment>", so that
+// the files left by the crashed processes can be found and removed.
+type Storage struct {
+	store       storage.ExternalStorage
+	instance    string
+	run         string
+	segmentSize int
+	seq         uint64
+}
+
+// Setup makes the executors spill to the external storage at rawURL.
+// instance identifies this TiDB among the ones sharing the storage.
+func Setup(ctx context.Context, rawURL string, instance string) error {
+	backend, err := storage.ParseBackend(rawURL, nil)
+	if err != nil {
+		return err
+	}
+	store, err := storage.New(ctx, backend, &storage.ExternalStorageOptions{})
+	if err != nil {
+		return err
+	}
+	s := NewStorage(store, instance)
+	go func() {
+		if err := s.Cleanup(ctx, staleFileTTL); err != nil {
+			logutil.BgLogger().Warn("failed to clean up the spilled files", zap.String("url", redactURL(rawURL)), zap.Error(err))
+		}
+	}()
+	disk.SetTempFileCreator(s.CreateTempFile)
+	return nil
+}
+
+// redactURL removes the user info and the query, which may hold the
+// credentials, from the storage URL to be logged.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "?"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	return u.String()
+}
+
+// NewStorage creates a Storage for instance on store.
+func NewStorage(store storage.ExternalStorage, instance string) *Storage {
+	return &Storage{
+		store:       store,
+		instance:    sanitizeName(instance),
+		run:         strconv.FormatInt(time.Now().UnixNano(), 36),
+		segmentSize: defaultSegmentSize,
+	}
+}
+
+func sanitizeName(name string) string {
+	return invalidNameChars.ReplaceAllString(name, "-")
+}
+
+// CreateTempFile implements disk.TempFileCreator.
+func (s *Storage) CreateTempFile(pattern string) (disk.TempFile, error) {
+	seq := atomic.AddUint64(&s.seq, 1)
+	name := strings.Join([]string{s.instance, s.run, sanitizeName(pattern) + "." + strconv.FormatUint(seq, 10)}, nameSep)
+	return &tempFile{s: s, name: name}, nil
+}
+
+// Cleanup removes the files left by the previous runs of this instance, and
+// the files of the other instances which are not modified in ttl.
+func (s *Storage) Cleanup(ctx context.Context, ttl time.Duration) error {
+	var stale []string
+	now := time.Now()
+	err := storage.WalkDirWithModTime(ctx, s.store, nil, func(path string, _ int64, modTime time.Time) error {
+		parts := strings.SplitN(path, nameSep, 3)
+		if len(parts) != 3 {
+			return nil
+		}
+		switch {
+		case parts[0] == s.instance:
+			if parts[1] != s.run {
+				stale = append(stale, path)
+			}
+		case !modTime.IsZero() && now.Sub(modTime) > ttl:
+			stale = append(stale, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, path := range stale {
+		if err := s.store.DeleteFile(ctx, path); err != nil {
+			return err
+		}
+	}
+	if len(stale) > 0 {
+		logutil.BgLogger().Info("cleaned up the spilled files", zap.Int("count", len(stale)))
+	}
+	return nil
+}
+
+type segment struct {
+	idx  int
+	data []byte
+}
+
+// tempFile is a temporary file in the external storage. The written data is
+// buffered in memory and uploaded once a segment is filled, the segments are
+// immutable after uploading.
+type tempFile struct {
+	s    *Storage
+	name string
+
+	mu       sync.Mutex
+	size     int64
+	uploaded int
+	tail     []byte
+	// cache holds the recently read segments, the latest one is the last.
+	cache []segment
+}
+
+func (f *tempFile) segmentName(idx int) string {
+	return f.name + nameSep + strconv.Itoa(idx)
+}
+
+// Name implements the disk.TempFile interface.
+func (f *tempFile) Name() string {
+	return f.name
+}
+
+// Write implements the disk.TempFile interface. The data is kept in the file
+// only after the segments filled by it are uploaded, so a failed write leaves
+// the file with the bytes written before it.
+func (f *tempFile) Write(p []byte) (n int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tailLen := len(f.tail)
+	data := append(f.tail[:tailLen:tailLen], p...)
+	for len(data) >= f.s.segmentSize {
+		if err := f.s.store.WriteFile(context.Background(), f.segmentName(f.uploaded), data[:f.s.segmentSize]); err != nil {
+			return n, errors.Trace(err)
+		}
+		written := f.s.segmentSize - tailLen
+		f.uploaded++
+		f.tail = nil
+		f.size += int64(written)
+		n += written
+		tailLen = 0
+		data = data[f.s.segmentSize:]
+	}
+	f.tail = append([]byte(nil), data...)
+	f.size += int64(len(data) - tailLen)
+	return len(p), nil
+}
+
+// ReadAt implements the disk.TempFile interface.
+func (f *tempFile) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.Errorf("negative offset %d", off)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	segSize := int64(f.s.segmentSize)
+	for n < len(p) && off < f.size {
+		idx := int(off / segSize)
+		var data []byte
+		if idx == f.uploaded {
+			data = f.tail
+		} else if data, err = f.readSegment(idx); err != nil {
+			return n, err
+		}
+		copied := copy(p[n:], data[off-int64(idx)*segSize:])
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *tempFile) readSegment(idx int) ([]byte, error) {
+	for _, seg := range f.cache {
+		if seg.idx == idx {
+			return seg.data, nil
+		}
+	}
+	data, err := f.s.store.ReadFile(context.Background(), f.segmentName(idx))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(data) != f.s.segmentSize {
+		return nil, errors.Errorf("the spilled file %s is broken, segment %d has %d bytes", f.name, idx, len(data))
+	}
+	if len(f.cache) == cachedSegments {
+		f.cache = f.cache[1:]
+	}
+	f.cache = append(f.cache, segment{idx: idx, data: data})
+	return data, nil
+}
+
+// Close implements the disk.TempFile interface. It removes the uploaded
+// segments.
+func (f *tempFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var firstErr error
+	for idx := 0; idx < f.uploaded; idx++ {
+		if err := f.s.store.DeleteFile(context.Background(), f.segmentName(idx)); err != nil && firstErr == nil {
+			firstErr = errors.Annotatef(err, "failed to remove the spilled file %s", f.name)
+		}
+	}
+	f.uploaded, f.size, f.tail, f.cache = 0, 0, nil, nil
+	return firstErr
+}
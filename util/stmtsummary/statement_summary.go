@@ -138,14 +138,16 @@ type stmtSummaryByDigestElement struct {
 	maxCopWaitTime       time.Duration
 	maxCopWaitAddress    string
 	// TiKV
-	sumProcessTime               time.Duration
-	maxProcessTime               time.Duration
-	sumWaitTime                  time.Duration
-	maxWaitTime                  time.Duration
-	sumBackoffTime               time.Duration
-	maxBackoffTime               time.Duration
-	sumTotalKeys                 int64
-	maxTotalKeys                 int64
+	sumProcessTime time.Duration
+	maxProcessTime time.Duration
+	sumWaitTime    time.Duration
+	maxWaitTime    time.Duration
+	sumBackoffTime time.Duration
+	maxBackoffTime time.Duration
+	sumTotalKeys   int64
+	maxTotalKeys   int64
+	// tableScanKeys is the sum of the keys scanned from each table.
+	tableScanKeys                map[string]int64
 	sumProcessedKeys             int64
 	maxProcessedKeys             int64
 	sumRocksdbDeleteSkippedCount uint64
@@ -244,6 +246,8 @@ type StmtExecInfo struct {
 	ResultRows      int64
 	TiKVExecDetails util.ExecDetails
 	Prepared        bool
+	// TableScanKeys is the keys scanned from each table, keyed by "db.table" in lower case.
+	TableScanKeys map[string]int64
 }
 
 // newStmtSummaryByDigestMap creates an empty stmtSummaryByDigestMap.
@@ -420,6 +424,75 @@ func (ssMap *stmtSummaryByDigestMap) GetMoreThanCntBindableStmt(cnt int64) []*Bi
 	return stmts
 }
 
+// TableScanStats is the scan statistics of a table, which are aggregated from statement summaries.
+type TableScanStats struct {
+	Schema       string
+	Table        string
+	ExecCount    int64
+	SumTotalKeys int64
+	SumLatency   time.Duration
+}
+
+// GetTableScanStats aggregates the statistics of users' select SQLs in all intervals by the tables they read.
+// The keys scanned by a statement reading several tables are split by the tables they are scanned from.
+func (ssMap *stmtSummaryByDigestMap) GetTableScanStats() []*TableScanStats {
+	ssMap.Lock()
+	values := ssMap.summaryMap.Values()
+	ssMap.Unlock()
+
+	statsMap := make(map[string]*TableScanStats)
+	for _, value := range values {
+		ssbd := value.(*stmtSummaryByDigest)
+		func() {
+			ssbd.Lock()
+			defer ssbd.Unlock()
+			if !ssbd.initialized || ssbd.isInternal || ssbd.stmtType != "Select" || len(ssbd.tableNames) == 0 {
+				return
+			}
+			names := strings.Split(ssbd.tableNames, ",")
+			var execCount int64
+			var sumLatency time.Duration
+			tableScanKeys := make(map[string]int64, len(names))
+			for elem := ssbd.history.Front(); elem != nil; elem = elem.Next() {
+				ssElement := elem.Value.(*stmtSummaryByDigestElement)
+				ssElement.Lock()
+				execCount += ssElement.execCount
+				sumLatency += ssElement.sumLatency
+				for name, keys := range ssElement.tableScanKeys {
+					tableScanKeys[name] += keys
+				}
+				ssElement.Unlock()
+			}
+			for _, name := range names {
+				stats, ok := statsMap[name]
+				if !ok {
+					dbName, tblName, found := strings.Cut(name, ".")
+					if !found {
+						continue
+					}
+					stats = &TableScanStats{Schema: dbName, Table: tblName}
+					statsMap[name] = stats
+				}
+				stats.ExecCount += execCount
+				stats.SumTotalKeys += tableScanKeys[name]
+				stats.SumLatency += sumLatency
+			}
+		}()
+	}
+
+	result := make([]*TableScanStats, 0, len(statsMap))
+	for _, stats := range statsMap {
+		result = append(result, stats)
+	}
+	slices.SortFunc(result, func(i, j *TableScanStats) bool {
+		if i.Schema != j.Schema {
+			return i.Schema < j.Schema
+		}
+		return i.Table < j.Table
+	})
+	return result
+}
+
 // SetEnabled enables or disables statement summary
 func (ssMap *stmtSummaryByDigestMap) SetEnabled(value bool) error {
 	// `optEnabled` and `ssMap` don't need to be strictly atomically updated.
@@ -716,6 +789,12 @@ func (ssElement *stmtSummaryByDigestElement) add(sei *StmtExecInfo, intervalSeco
 		ssElement.maxBackoffTime = sei.ExecDetail.BackoffTime
 	}
 
+	for name, keys := range sei.TableScanKeys {
+		if ssElement.tableScanKeys == nil {
+			ssElement.tableScanKeys = make(map[string]int64, len(sei.TableScanKeys))
+		}
+		ssElement.tableScanKeys[name] += keys
+	}
 	if sei.ExecDetail.ScanDetail != nil {
 		ssElement.sumTotalKeys += sei.ExecDetail.ScanDetail.TotalKeys
 		if sei.ExecDetail.ScanDetail.TotalKeys > ssElement.maxTotalKeys {
@@ -1169,6 +1169,39 @@ func TestGetMoreThanCntBindableStmt(t *testing.T) {
 	require.Equal(t, 1, len(stmts))
 }
 
+func TestGetTableScanStats(t *testing.T) {
+	ssMap := newStmtSummaryByDigestMap()
+
+	// The keys are split by the tables they are scanned from.
+	stmtExecInfo1 := generateAnyExecInfo()
+	stmtExecInfo1.TableScanKeys = map[string]int64{"db1.tb1": 800, "db2.tb2": 200}
+	ssMap.AddStatement(stmtExecInfo1)
+	ssMap.AddStatement(stmtExecInfo1)
+
+	stmtExecInfo2 := generateAnyExecInfo()
+	stmtExecInfo2.Digest = "digest2"
+	stmtExecInfo2.StmtCtx.Tables = []stmtctx.TableEntry{{DB: "db1", Table: "TB1"}}
+	stmtExecInfo2.TableScanKeys = map[string]int64{"db1.tb1": 1000}
+	ssMap.AddStatement(stmtExecInfo2)
+
+	// The keys of the tables are unknown.
+	stmtExecInfo3 := generateAnyExecInfo()
+	stmtExecInfo3.Digest = "digest3"
+	ssMap.AddStatement(stmtExecInfo3)
+
+	// Only select statements are counted.
+	stmtExecInfo4 := generateAnyExecInfo()
+	stmtExecInfo4.Digest = "digest4"
+	stmtExecInfo4.StmtCtx.StmtType = "Update"
+	stmtExecInfo4.TableScanKeys = map[string]int64{"db1.tb1": 800, "db2.tb2": 200}
+	ssMap.AddStatement(stmtExecInfo4)
+
+	stats := ssMap.GetTableScanStats()
+	require.Len(t, stats, 2)
+	require.Equal(t, &TableScanStats{Schema: "db1", Table: "tb1", ExecCount: 4, SumTotalKeys: 2600, SumLatency: 40000}, stats[0])
+	require.Equal(t, &TableScanStats{Schema: "db2", Table: "tb2", ExecCount: 3, SumTotalKeys: 400, SumLatency: 30000}, stats[1])
+}
+
 // Test `formatBackoffTypes`.
 func TestFormatBackoffTypes(t *testing.T) {
 	backoffMap := make(map[string]int)